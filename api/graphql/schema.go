@@ -0,0 +1,10 @@
+// Package graphql holds the GraphQL schema of the inventory, served at
+// /api/graphql next to the REST API of api/v1.
+package graphql
+
+import _ "embed"
+
+// Schema is the SDL of the inventory schema.
+//
+//go:embed schema.graphql
+var Schema string
@@ -2,3 +2,5 @@ package v1
 
 //go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.3.0 --config=types.gen.cfg openapi.yaml
 //go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.3.0  --config=spec.gen.cfg openapi.yaml
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.3.0 --config=client.gen.cfg openapi.yaml
+//go:generate go run ./internal/enumgen -spec openapi.yaml -out enums.gen.go
@@ -2,20 +2,70 @@ package v1
 
 import (
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/util"
 )
 
-func (a *AgentStatus) FromModel(m models.AgentStatus) {
-	switch m.Console.Current {
-	case models.ConsoleStatusConnected:
-		a.ConsoleConnection = AgentStatusConsoleConnection("connected")
-	case models.ConsoleStatusDisconnected:
-		a.ConsoleConnection = AgentStatusConsoleConnection("disconnected")
+// enum is the value of the generated API enum E for the model state s, or
+// fallback when the spec has no such value.
+func enum[E interface {
+	~string
+	Valid() bool
+}, S ~string](s S, fallback E) E {
+	if e := E(s); e.Valid() {
+		return e
 	}
+	return fallback
+}
+
+func (a *AgentStatus) FromModel(m models.AgentStatus) {
+	a.ConsoleConnection = enum(m.Console.Current, a.ConsoleConnection)
 	if m.Console.Error != nil {
 		err := m.Console.Error.Error()
 		a.Error = &err
 	}
 	a.Mode = AgentStatusMode(m.Console.Target)
+	if m.Update.State != "" {
+		update := NewAgentUpdate(m.Update)
+		a.Update = &update
+	}
+	if m.HA != nil {
+		ha := NewAgentHA(*m.HA)
+		a.Ha = &ha
+	}
+}
+
+// NewAgentHA converts a models.HAStatus to an API AgentHA.
+func NewAgentHA(m models.HAStatus) AgentHA {
+	ha := AgentHA{
+		Role:     enum(m.Role, AgentHARoleStandby),
+		Identity: m.Identity,
+	}
+	if m.Leader != "" {
+		ha.Leader = &m.Leader
+	}
+	if !m.RenewedAt.IsZero() {
+		ha.RenewedAt = &m.RenewedAt
+	}
+	return ha
+}
+
+// NewAgentUpdate converts a models.UpdateStatus to an API AgentUpdate.
+func NewAgentUpdate(m models.UpdateStatus) AgentUpdate {
+	u := AgentUpdate{
+		State:          enum(m.State, AgentUpdateStateFailed),
+		CurrentVersion: m.CurrentVersion,
+	}
+	if m.AvailableVersion != "" {
+		u.AvailableVersion = &m.AvailableVersion
+	}
+	if !m.CheckedAt.IsZero() {
+		u.CheckedAt = &m.CheckedAt
+	}
+	if m.Error != nil {
+		err := m.Error.Error()
+		u.Error = &err
+	}
+	return u
 }
 
 // NewVMFromSummary converts a models.VMSummary to an API VM.
@@ -35,24 +85,7 @@ func NewVMFromSummary(vm models.VMSummary) VM {
 func NewCollectorStatus(status models.CollectorStatus) CollectorStatus {
 	var c CollectorStatus
 
-	switch status.State {
-	case models.CollectorStateReady:
-		c.Status = CollectorStatusStatusReady
-	case models.CollectorStateConnecting:
-		c.Status = CollectorStatusStatusConnecting
-	case models.CollectorStateConnected:
-		c.Status = CollectorStatusStatusConnected
-	case models.CollectorStateCollecting:
-		c.Status = CollectorStatusStatusCollecting
-	case models.CollectorStateCollected:
-		c.Status = CollectorStatusStatusCollected
-	case models.CollectorStateError:
-		c.Status = CollectorStatusStatusError
-	case models.CollectorStateParsing:
-		c.Status = CollectorStatusStatusParsing
-	default:
-		c.Status = "unknown state"
-	}
+	c.Status = enum(status.State, CollectorStatusStatus("unknown state"))
 
 	if status.Error != nil {
 		e := status.Error.Error()
@@ -71,6 +104,25 @@ func NewCollectorStatusWithError(status models.CollectorStatus, err error) Colle
 	return c
 }
 
+// NewSource converts a models.SourceStatus to an API Source.
+func NewSource(m models.SourceStatus) Source {
+	source := Source{
+		Id:                m.ID,
+		Name:              m.Name,
+		Primary:           m.Primary,
+		Collector:         NewCollectorStatus(m.Collector),
+		ConsoleConnection: enum(m.Console.Current, SourceConsoleConnectionDisconnected),
+	}
+	if !m.CreatedAt.IsZero() {
+		source.CreatedAt = &m.CreatedAt
+	}
+	if m.Console.Error != nil {
+		err := m.Console.Error.Error()
+		source.Error = &err
+	}
+	return source
+}
+
 func NewVMDetailsFromModel(vm models.VM) VMDetails {
 	details := VMDetails{
 		Id:              vm.ID,
@@ -90,6 +142,15 @@ func NewVMDetailsFromModel(vm models.VM) VMDetails {
 	if vm.Firmware != "" {
 		details.Firmware = &vm.Firmware
 	}
+	if vm.HardwareVersion != "" {
+		details.HardwareVersion = &vm.HardwareVersion
+	}
+	if vm.CpuSockets > 0 {
+		details.CpuSockets = &vm.CpuSockets
+	}
+	if len(vm.NumaNodeAffinity) > 0 {
+		details.NumaNodeAffinity = &vm.NumaNodeAffinity
+	}
 	if vm.Host != "" {
 		details.Host = &vm.Host
 	}
@@ -124,13 +185,19 @@ func NewVMDetailsFromModel(vm models.VM) VMDetails {
 		details.ToolsRunningStatus = &vm.ToolsRunningStatus
 	}
 
+	details.CpuHotAddEnabled = &vm.CpuHotAddEnabled
+	details.CpuHotRemoveEnabled = &vm.CpuHotRemoveEnabled
+	details.MemoryHotAddEnabled = &vm.MemoryHotAddEnabled
 	details.IsTemplate = &vm.IsTemplate
 	details.FaultToleranceEnabled = &vm.FaultToleranceEnabled
 	details.NestedHVEnabled = &vm.NestedHVEnabled
+	details.Encrypted = &vm.Encrypted
+	details.TpmEnabled = &vm.TpmEnabled
+	details.SecureBoot = &vm.SecureBoot
 
 	for _, d := range vm.Disks {
-		// Convert MiB to bytes (parser returns capacity in MiB)
-		capacityBytes := d.Capacity * 1024 * 1024
+		// The parser returns the capacity in MiB
+		capacityBytes := util.MiBToBytes(d.Capacity)
 		disk := VMDisk{
 			File:     &d.File,
 			Capacity: &capacityBytes,
@@ -143,6 +210,12 @@ func NewVMDetailsFromModel(vm models.VM) VMDetails {
 			key := d.Key
 			disk.Key = &key
 		}
+		if d.ChainDepth > 0 {
+			depth := d.ChainDepth
+			linkedClone := d.LinkedClone
+			disk.ChainDepth = &depth
+			disk.LinkedClone = &linkedClone
+		}
 		details.Disks = append(details.Disks, disk)
 	}
 
@@ -165,24 +238,7 @@ func NewVMDetailsFromModel(vm models.VM) VMDetails {
 func NewInspectorStatus(status models.InspectorStatus) InspectorStatus {
 	var c InspectorStatus
 
-	switch status.State {
-	case models.InspectorStateReady:
-		c.State = InspectorStatusStateReady
-	case models.InspectorStateInitiating:
-		c.State = InspectorStatusStateInitiating
-	case models.InspectorStateRunning:
-		c.State = InspectorStatusStateRunning
-	case models.InspectorStateCanceling:
-		c.State = InspectorStatusStateCanceling
-	case models.InspectorStateCanceled:
-		c.State = InspectorStatusStateCanceled
-	case models.InspectorStateCompleted:
-		c.State = InspectorStatusStateCompleted
-	case models.InspectorStateError:
-		c.State = InspectorStatusStateError
-	default:
-		c.State = InspectorStatusStateReady
-	}
+	c.State = enum(status.State, InspectorStatusStateReady)
 
 	if status.Error != nil {
 		e := status.Error.Error()
@@ -192,22 +248,35 @@ func NewInspectorStatus(status models.InspectorStatus) InspectorStatus {
 	return c
 }
 
+// NewConsoleLogin converts a models.DeviceLogin to an API ConsoleLogin.
+func NewConsoleLogin(login models.DeviceLogin) ConsoleLogin {
+	var c ConsoleLogin
+
+	c.State = enum(login.State, ConsoleLoginStateIdle)
+
+	if login.UserCode != "" {
+		c.UserCode = &login.UserCode
+	}
+	if login.VerificationURI != "" {
+		c.VerificationUri = &login.VerificationURI
+	}
+	if login.VerificationURIComplete != "" {
+		c.VerificationUriComplete = &login.VerificationURIComplete
+	}
+	if !login.ExpiresAt.IsZero() {
+		c.ExpiresAt = &login.ExpiresAt
+	}
+	if login.Error != nil {
+		e := login.Error.Error()
+		c.Error = &e
+	}
+
+	return c
+}
+
 func NewInspectionStatus(status models.InspectionStatus) VmInspectionStatus {
 	var c VmInspectionStatus
-	switch status.State.Value() {
-	case models.InspectionStatePending.Value():
-		c.State = VmInspectionStatusStatePending
-	case models.InspectionStateRunning.Value():
-		c.State = VmInspectionStatusStateRunning
-	case models.InspectionStateCanceled.Value():
-		c.State = VmInspectionStatusStateCanceled
-	case models.InspectionStateCompleted.Value():
-		c.State = VmInspectionStatusStateCompleted
-	case models.InspectionStateError.Value():
-		c.State = VmInspectionStatusStateError
-	default:
-		c.State = VmInspectionStatusStateNotFound
-	}
+	c.State = enum(status.State, VmInspectionStatusStateNotFound)
 
 	if status.Error != nil {
 		err := status.Error.Error()
@@ -216,3 +285,457 @@ func NewInspectionStatus(status models.InspectionStatus) VmInspectionStatus {
 
 	return c
 }
+
+// NewClusterRule converts a models.ClusterRule to an API ClusterRule.
+func NewClusterRule(rule models.ClusterRule) ClusterRule {
+	r := ClusterRule{
+		Name:      rule.Name,
+		Type:      ClusterRuleType(rule.Type),
+		Enabled:   rule.Enabled,
+		Mandatory: rule.Mandatory,
+		Vms:       rule.VMs,
+	}
+	if r.Vms == nil {
+		r.Vms = []string{}
+	}
+	if rule.VMGroup != "" {
+		r.VmGroup = &rule.VMGroup
+	}
+	if rule.HostGroup != "" {
+		r.HostGroup = &rule.HostGroup
+		r.Hosts = &rule.Hosts
+	}
+	return r
+}
+
+// NewVCenterEvent converts a models.VCenterEvent to an API VCenterEvent.
+func NewVCenterEvent(e models.VCenterEvent) VCenterEvent {
+	ev := VCenterEvent{
+		Kind:      VCenterEventKind(e.Kind),
+		Type:      e.Type,
+		Severity:  VCenterEventSeverity(e.Severity),
+		CreatedAt: e.CreatedAt,
+	}
+	if e.VMID != "" {
+		ev.VmId = &e.VMID
+	}
+	if e.HostID != "" {
+		ev.HostId = &e.HostID
+	}
+	if e.Message != "" {
+		ev.Message = &e.Message
+	}
+	return ev
+}
+
+// NewHost converts a models.Host to an API Host.
+func NewHost(h models.Host) Host {
+	host := Host{
+		Id:         h.ID,
+		Cluster:    h.Cluster,
+		CpuCores:   h.CPUCores,
+		CpuSockets: h.CPUSockets,
+		MemoryMB:   h.MemoryMB,
+	}
+	if h.Vendor != "" {
+		host.Vendor = &h.Vendor
+	}
+	if h.Model != "" {
+		host.Model = &h.Model
+	}
+	return host
+}
+
+// NewDatastore converts a models.Datastore to an API Datastore.
+func NewDatastore(d models.Datastore) Datastore {
+	ds := Datastore{
+		Id:                      d.ID,
+		TotalCapacityGB:         d.TotalCapacityGB,
+		FreeCapacityGB:          d.FreeCapacityGB,
+		HardwareAcceleratedMove: d.HardwareAcceleratedMove,
+		HostIds:                 d.HostIDs,
+	}
+	if ds.HostIds == nil {
+		ds.HostIds = []string{}
+	}
+	if d.Type != "" {
+		ds.Type = &d.Type
+	}
+	if d.ProtocolType != "" {
+		ds.ProtocolType = &d.ProtocolType
+	}
+	return ds
+}
+
+// NewNetwork converts a models.Network to an API Network.
+func NewNetwork(n models.Network) Network {
+	network := Network{
+		Name:    n.Name,
+		Type:    NetworkType(n.Type),
+		VmCount: n.VMCount,
+	}
+	if n.DVSwitch != "" {
+		network.Dvswitch = &n.DVSwitch
+	}
+	if n.VlanID != "" {
+		network.VlanId = &n.VlanID
+	}
+	return network
+}
+
+// NewDatastoreStats converts a models.DatastoreStats to an API DatastoreStats.
+func NewDatastoreStats(st models.DatastoreStats) DatastoreStats {
+	return DatastoreStats{
+		Id:             st.ID,
+		Name:           st.Name,
+		ReadKBps:       st.ReadKBps,
+		WriteKBps:      st.WriteKBps,
+		ReadLatencyMs:  st.ReadLatencyMs,
+		WriteLatencyMs: st.WriteLatencyMs,
+		SampledAt:      st.SampledAt,
+	}
+}
+
+// NewAgentEvent converts a models.AgentEvent to an API AgentEvent.
+func NewAgentEvent(e models.AgentEvent) AgentEvent {
+	ev := AgentEvent{
+		Type:      string(e.Type),
+		Message:   e.Message,
+		CreatedAt: e.Time,
+	}
+	if len(e.Details) > 0 {
+		ev.Details = &e.Details
+	}
+	return ev
+}
+
+// NewPagination returns the Pagination of a models.Page.
+func NewPagination[T any](p models.Page[T]) Pagination {
+	return Pagination{
+		Total:     p.Total,
+		Page:      p.Number(),
+		PageSize:  p.Size(),
+		PageCount: p.Count(),
+	}
+}
+
+// NewVMListResponse converts a page of VM summaries to an API VMListResponse.
+func NewVMListResponse(p models.Page[models.VMSummary]) VMListResponse {
+	pg := NewPagination(p)
+	return VMListResponse{
+		Total:     pg.Total,
+		Page:      pg.Page,
+		PageSize:  pg.PageSize,
+		PageCount: pg.PageCount,
+		Vms:       models.MapPage(p, NewVMFromSummary).Items,
+	}
+}
+
+// NewHostListResponse converts a page of hosts to an API HostListResponse.
+func NewHostListResponse(p models.Page[models.Host]) HostListResponse {
+	pg := NewPagination(p)
+	return HostListResponse{
+		Total:     pg.Total,
+		Page:      pg.Page,
+		PageSize:  pg.PageSize,
+		PageCount: pg.PageCount,
+		Hosts:     models.MapPage(p, NewHost).Items,
+	}
+}
+
+// NewDatastoreListResponse converts a page of datastores to an API DatastoreListResponse.
+func NewDatastoreListResponse(p models.Page[models.Datastore]) DatastoreListResponse {
+	pg := NewPagination(p)
+	return DatastoreListResponse{
+		Total:      pg.Total,
+		Page:       pg.Page,
+		PageSize:   pg.PageSize,
+		PageCount:  pg.PageCount,
+		Datastores: models.MapPage(p, NewDatastore).Items,
+	}
+}
+
+// NewNetworkListResponse converts a page of networks to an API NetworkListResponse.
+func NewNetworkListResponse(p models.Page[models.Network]) NetworkListResponse {
+	pg := NewPagination(p)
+	return NetworkListResponse{
+		Total:     pg.Total,
+		Page:      pg.Page,
+		PageSize:  pg.PageSize,
+		PageCount: pg.PageCount,
+		Networks:  models.MapPage(p, NewNetwork).Items,
+	}
+}
+
+// NewAgentEventListResponse converts a page of agent events to an API AgentEventListResponse.
+func NewAgentEventListResponse(p models.Page[models.AgentEvent]) AgentEventListResponse {
+	pg := NewPagination(p)
+	return AgentEventListResponse{
+		Total:     pg.Total,
+		Page:      pg.Page,
+		PageSize:  pg.PageSize,
+		PageCount: pg.PageCount,
+		Events:    models.MapPage(p, NewAgentEvent).Items,
+	}
+}
+
+// NewJob converts a models.Job to an API Job.
+func NewJob(j models.Job) Job {
+	job := Job{
+		Id:         j.ID,
+		Kind:       enum(j.Kind, JobKindCollection),
+		State:      enum(j.State, JobStateFailed),
+		Progress:   j.Progress,
+		CreatedAt:  j.CreatedAt,
+		UpdatedAt:  j.UpdatedAt,
+		FinishedAt: j.FinishedAt,
+	}
+	if j.Phase != "" {
+		job.Phase = &j.Phase
+	}
+	if j.Error != "" {
+		job.Error = &j.Error
+	}
+	return job
+}
+
+// NewJobListResponse converts a page of jobs to an API JobListResponse.
+func NewJobListResponse(p models.Page[models.Job]) JobListResponse {
+	pg := NewPagination(p)
+	return JobListResponse{
+		Total:     pg.Total,
+		Page:      pg.Page,
+		PageSize:  pg.PageSize,
+		PageCount: pg.PageCount,
+		Jobs:      models.MapPage(p, NewJob).Items,
+	}
+}
+
+// NewPlan converts a models.Plan to an API Plan, the durations in seconds.
+func NewPlan(p models.Plan) Plan {
+	plan := Plan{
+		Id:                       p.ID,
+		Name:                     p.Name,
+		CreatedAt:                p.CreatedAt,
+		ThroughputMBps:           float32(p.ThroughputMBps),
+		ThroughputSource:         enum(p.ThroughputSource, PlanThroughputSourceDefault),
+		Waves:                    make([]PlanWave, 0, len(p.Waves)),
+		Resources:                newPlanResources(p.Resources),
+		EstimatedTransferSeconds: int64(p.EstimatedTransfer.Seconds()),
+	}
+	for _, w := range p.Waves {
+		wave := PlanWave{
+			Name:                     w.Name,
+			Vms:                      make([]PlanVM, 0, len(w.VMs)),
+			Resources:                newPlanResources(w.Resources),
+			EstimatedTransferSeconds: int64(w.EstimatedTransfer.Seconds()),
+		}
+		for _, vm := range w.VMs {
+			wave.Vms = append(wave.Vms, PlanVM{
+				Order:                    vm.Order,
+				Id:                       vm.ID,
+				Name:                     vm.Name,
+				Cluster:                  vm.Cluster,
+				PowerState:               vm.PowerState,
+				Cpus:                     vm.CPUs,
+				MemoryMB:                 vm.MemoryMB,
+				DiskMB:                   vm.DiskMB,
+				EstimatedTransferSeconds: int64(vm.EstimatedTransfer.Seconds()),
+				CriticalConcerns:         vm.CriticalConcerns,
+			})
+		}
+		plan.Waves = append(plan.Waves, wave)
+	}
+	return plan
+}
+
+func newPlanResources(r models.PlanResources) PlanResources {
+	return PlanResources{Vms: r.VMs, Cpus: r.CPUs, MemoryMB: r.MemoryMB, StorageMB: r.StorageMB}
+}
+
+// NewBenchmark converts a models.Benchmark to an API Benchmark, the durations in
+// milliseconds.
+func NewBenchmark(b models.Benchmark) Benchmark {
+	bench := Benchmark{
+		Id:             b.ID,
+		CreatedAt:      b.CreatedAt,
+		ThroughputMBps: float32(b.ThroughputMBps()),
+		Datastores:     make([]ThroughputMeasure, 0, len(b.Datastores)),
+	}
+	for _, d := range b.Datastores {
+		bench.Datastores = append(bench.Datastores, newThroughputMeasure(d))
+	}
+	if b.Network != nil {
+		network := newThroughputMeasure(*b.Network)
+		bench.Network = &network
+	}
+	return bench
+}
+
+func newThroughputMeasure(m models.ThroughputMeasure) ThroughputMeasure {
+	measure := ThroughputMeasure{
+		Name:       m.Name,
+		Bytes:      m.Bytes,
+		DurationMs: m.Duration.Milliseconds(),
+		Mbps:       float32(m.MBps),
+	}
+	if m.Error != "" {
+		measure.Error = &m.Error
+	}
+	return measure
+}
+
+// NewPolicyDecision converts a models.PolicyDecision to an API PolicyDecision.
+func NewPolicyDecision(d models.PolicyDecision) PolicyDecision {
+	decision := PolicyDecision{
+		VmId:       d.VMID,
+		Decision:   enum(d.Decision, PolicyDecisionDecisionPassed),
+		DurationUs: d.Duration.Microseconds(),
+		CreatedAt:  d.Time,
+	}
+	if d.VMName != "" {
+		decision.VmName = &d.VMName
+	}
+	if d.Rule != "" {
+		decision.Rule = &d.Rule
+	}
+	return decision
+}
+
+// NewPolicyDecisionListResponse converts a page of policy decisions to an API PolicyDecisionListResponse.
+func NewPolicyDecisionListResponse(p models.Page[models.PolicyDecision]) PolicyDecisionListResponse {
+	pg := NewPagination(p)
+	return PolicyDecisionListResponse{
+		Total:     pg.Total,
+		Page:      pg.Page,
+		PageSize:  pg.PageSize,
+		PageCount: pg.PageCount,
+		Decisions: models.MapPage(p, NewPolicyDecision).Items,
+	}
+}
+
+// NewPolicyEvaluation converts a models.PolicyEvaluation to an API PolicyEvaluation.
+func NewPolicyEvaluation(e models.PolicyEvaluation) PolicyEvaluation {
+	p := PolicyEvaluation{Concerns: make([]Concern, 0, len(e.Concerns))}
+	if e.VMID != "" {
+		p.VmId = &e.VMID
+	}
+	if e.VMName != "" {
+		p.VmName = &e.VMName
+	}
+	for _, c := range e.Concerns {
+		concern := Concern{
+			Id:         c.ID,
+			Label:      c.Label,
+			Category:   c.Category,
+			Assessment: c.Assessment,
+		}
+		if c.Severity != "" {
+			concern.Severity = &c.Severity
+		}
+		if c.Remediation != "" {
+			concern.Remediation = &c.Remediation
+		}
+		if c.DocsURL != "" {
+			concern.DocsUrl = &c.DocsURL
+		}
+		p.Concerns = append(p.Concerns, concern)
+	}
+	return p
+}
+
+// NewConcernDetail converts a models.ConcernDetail to an API ConcernDetail.
+func NewConcernDetail(d models.ConcernDetail) ConcernDetail {
+	c := ConcernDetail{
+		Id:       d.ID,
+		Label:    d.Label,
+		Category: d.Category,
+		Severity: d.Severity,
+		VmCount:  d.VMCount,
+	}
+	if d.Title != "" {
+		c.Title = &d.Title
+	}
+	if d.Remediation != "" {
+		c.Remediation = &d.Remediation
+	}
+	if d.DocsURL != "" {
+		c.DocsUrl = &d.DocsURL
+	}
+	return c
+}
+
+// NewCustomPolicy converts a models.CustomPolicy to an API CustomPolicy.
+func NewCustomPolicy(p models.CustomPolicy) CustomPolicy {
+	return CustomPolicy{
+		Name:       p.Name,
+		Size:       p.Size,
+		ModifiedAt: p.ModifiedAt,
+	}
+}
+
+// NewPolicySet converts a models.PolicySet to an API PolicySet.
+func NewPolicySet(m models.PolicySet) PolicySet {
+	set := PolicySet{
+		Fingerprint: m.Fingerprint,
+		Policies:    make([]PolicyFile, 0, len(m.Files)),
+	}
+	if !m.LoadedAt.IsZero() {
+		set.LoadedAt = &m.LoadedAt
+	}
+	if m.Bundle != nil {
+		set.Bundle = &PolicyBundle{
+			Url:         m.Bundle.URL,
+			Revision:    m.Bundle.Revision,
+			InstalledAt: m.Bundle.InstalledAt,
+		}
+	}
+	for _, f := range m.Files {
+		set.Policies = append(set.Policies, PolicyFile{
+			Name:   f.Name,
+			Source: enum(f.Source, PolicyFileSourceBuiltin),
+			Hash:   f.Hash,
+		})
+	}
+	if m.LoadError != "" {
+		set.LoadError = &m.LoadError
+	}
+	return set
+}
+
+// NewPolicyTestReport converts a models.PolicyTestReport to an API PolicyTestReport.
+func NewPolicyTestReport(r models.PolicyTestReport) PolicyTestReport {
+	report := PolicyTestReport{
+		Passed:  r.Passed,
+		Failed:  r.Failed,
+		Skipped: r.Skipped,
+		Results: make([]PolicyTestResult, 0, len(r.Results)),
+	}
+	for _, t := range r.Results {
+		result := PolicyTestResult{
+			File:       t.File,
+			Package:    t.Package,
+			Name:       t.Name,
+			Status:     enum(t.Status, PolicyTestResultStatusError),
+			DurationMs: t.Duration.Milliseconds(),
+		}
+		if t.Error != "" {
+			result.Error = &t.Error
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// NewAssessmentImportResult converts an imported models.AssessmentBundle to an
+// API AssessmentImportResult.
+func NewAssessmentImportResult(b models.AssessmentBundle) AssessmentImportResult {
+	return AssessmentImportResult{
+		AgentId:      b.Manifest.AgentID,
+		SourceId:     b.Manifest.SourceID,
+		AgentVersion: b.Manifest.AgentVersion,
+		CreatedAt:    b.Manifest.CreatedAt,
+		CollectedAt:  b.Manifest.CollectedAt,
+		Fingerprint:  b.Fingerprint,
+	}
+}
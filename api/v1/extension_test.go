@@ -3,6 +3,7 @@ package v1_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -153,6 +154,131 @@ var _ = Describe("NewCollectorStatusWithError", func() {
 	})
 })
 
+var _ = Describe("NewConsoleLogin", func() {
+	It("should map a pending login with its code", func() {
+		expiresAt := time.Now().Add(10 * time.Minute)
+		login := v1.NewConsoleLogin(models.DeviceLogin{
+			State:           models.DeviceLoginStatePending,
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://sso.example.com/device",
+			ExpiresAt:       expiresAt,
+		})
+		Expect(login.State).To(Equal(v1.ConsoleLoginStatePending))
+		Expect(*login.UserCode).To(Equal("ABCD-EFGH"))
+		Expect(*login.VerificationUri).To(Equal("https://sso.example.com/device"))
+		Expect(login.VerificationUriComplete).To(BeNil())
+		Expect(*login.ExpiresAt).To(Equal(expiresAt))
+	})
+
+	It("should map a failed login with its error", func() {
+		login := v1.NewConsoleLogin(models.DeviceLogin{
+			State: models.DeviceLoginStateFailed,
+			Error: errors.New("access denied"),
+		})
+		Expect(login.State).To(Equal(v1.ConsoleLoginStateFailed))
+		Expect(*login.Error).To(Equal("access denied"))
+		Expect(login.ExpiresAt).To(BeNil())
+	})
+
+	It("should default to idle", func() {
+		login := v1.NewConsoleLogin(models.DeviceLogin{})
+		Expect(login.State).To(Equal(v1.ConsoleLoginStateIdle))
+	})
+})
+
+var _ = Describe("NewSource", func() {
+	It("should map a connected source", func() {
+		createdAt := time.Now().UTC()
+		source := v1.NewSource(models.SourceStatus{
+			Source:    models.Source{ID: "source-1", Name: "vcenter-2", CreatedAt: createdAt},
+			Collector: models.CollectorStatus{State: models.CollectorStateCollecting},
+			Console:   models.ConsoleStatus{Current: models.ConsoleStatusConnected},
+		})
+		Expect(source.Id).To(Equal("source-1"))
+		Expect(source.Name).To(Equal("vcenter-2"))
+		Expect(source.Primary).To(BeFalse())
+		Expect(*source.CreatedAt).To(Equal(createdAt))
+		Expect(source.Collector.Status).To(Equal(v1.CollectorStatusStatusCollecting))
+		Expect(source.ConsoleConnection).To(Equal(v1.SourceConsoleConnectionConnected))
+		Expect(source.Error).To(BeNil())
+	})
+
+	It("should include the console error", func() {
+		source := v1.NewSource(models.SourceStatus{
+			Source:  models.Source{ID: "source-1"},
+			Primary: true,
+			Console: models.ConsoleStatus{Current: models.ConsoleStatusDisconnected, Error: errors.New("console unreachable")},
+		})
+		Expect(source.Primary).To(BeTrue())
+		Expect(source.CreatedAt).To(BeNil())
+		Expect(source.ConsoleConnection).To(Equal(v1.SourceConsoleConnectionDisconnected))
+		Expect(*source.Error).To(Equal("console unreachable"))
+	})
+})
+
+var _ = Describe("NewJob", func() {
+	It("should map a running job", func() {
+		createdAt := time.Now().UTC()
+		job := v1.NewJob(models.Job{
+			ID:        "job-1",
+			Kind:      models.JobKindCollection,
+			State:     models.JobStateRunning,
+			Phase:     "collecting",
+			Progress:  33,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+		Expect(job.Id).To(Equal("job-1"))
+		Expect(job.Kind).To(Equal(v1.JobKindCollection))
+		Expect(job.State).To(Equal(v1.JobStateRunning))
+		Expect(*job.Phase).To(Equal("collecting"))
+		Expect(job.Progress).To(Equal(33))
+		Expect(job.Error).To(BeNil())
+		Expect(job.FinishedAt).To(BeNil())
+	})
+
+	It("should map a failed job with its error", func() {
+		finishedAt := time.Now().UTC()
+		job := v1.NewJob(models.Job{
+			ID:         "job-1",
+			Kind:       models.JobKindInspection,
+			State:      models.JobStateFailed,
+			Error:      "connection refused",
+			FinishedAt: &finishedAt,
+		})
+		Expect(job.State).To(Equal(v1.JobStateFailed))
+		Expect(job.Phase).To(BeNil())
+		Expect(*job.Error).To(Equal("connection refused"))
+		Expect(*job.FinishedAt).To(Equal(finishedAt))
+	})
+})
+
+var _ = Describe("NewPlan", func() {
+	It("should map the waves with the estimates in seconds", func() {
+		plan := v1.NewPlan(models.Plan{
+			ID:               "plan-1",
+			Name:             "cutover",
+			ThroughputMBps:   100,
+			ThroughputSource: models.PlanThroughputRequest,
+			Waves: []models.PlanWave{{
+				Name:              "wave-1",
+				VMs:               []models.PlanVM{{Order: 1, ID: "vm-1", DiskMB: 1500, EstimatedTransfer: 15 * time.Second}},
+				Resources:         models.PlanResources{VMs: 1, StorageMB: 1500},
+				EstimatedTransfer: 15 * time.Second,
+			}},
+			Resources:         models.PlanResources{VMs: 1, StorageMB: 1500},
+			EstimatedTransfer: 15 * time.Second,
+		})
+		Expect(plan.Id).To(Equal("plan-1"))
+		Expect(plan.ThroughputSource).To(Equal(v1.PlanThroughputSourceRequest))
+		Expect(plan.EstimatedTransferSeconds).To(BeEquivalentTo(15))
+		Expect(plan.Waves).To(HaveLen(1))
+		Expect(plan.Waves[0].Vms[0].Id).To(Equal("vm-1"))
+		Expect(plan.Waves[0].Vms[0].EstimatedTransferSeconds).To(BeEquivalentTo(15))
+		Expect(plan.Resources.StorageMB).To(BeEquivalentTo(1500))
+	})
+})
+
 var _ = Describe("NewVMDetailsFromModel", func() {
 	It("should convert required fields", func() {
 		vm := models.VM{
@@ -237,6 +363,37 @@ var _ = Describe("NewVMDetailsFromModel", func() {
 		Expect(details.ToolsRunningStatus).To(BeNil())
 	})
 
+	It("should convert the CPU topology, hot add and hardware version", func() {
+		vm := models.VM{
+			ID:                  "vm-topology",
+			Name:                "Topology VM",
+			CpuCount:            8,
+			CpuSockets:          2,
+			CoresPerSocket:      4,
+			CpuHotAddEnabled:    true,
+			MemoryHotAddEnabled: true,
+			NumaNodeAffinity:    []string{"0"},
+			HardwareVersion:     "vmx-19",
+		}
+
+		details := v1.NewVMDetailsFromModel(vm)
+
+		Expect(*details.CpuSockets).To(Equal(int32(2)))
+		Expect(*details.CpuHotAddEnabled).To(BeTrue())
+		Expect(*details.CpuHotRemoveEnabled).To(BeFalse())
+		Expect(*details.MemoryHotAddEnabled).To(BeTrue())
+		Expect(*details.NumaNodeAffinity).To(Equal([]string{"0"}))
+		Expect(*details.HardwareVersion).To(Equal("vmx-19"))
+	})
+
+	It("should not include unknown sockets, NUMA nodes and hardware version", func() {
+		details := v1.NewVMDetailsFromModel(models.VM{ID: "vm-sparse", Name: "Sparse VM"})
+
+		Expect(details.CpuSockets).To(BeNil())
+		Expect(details.NumaNodeAffinity).To(BeNil())
+		Expect(details.HardwareVersion).To(BeNil())
+	})
+
 	It("should include StorageUsed when greater than zero", func() {
 		vm := models.VM{
 			ID:              "vm-storage",
@@ -408,3 +565,116 @@ var _ = Describe("NewVMDetailsFromModel", func() {
 		Expect(details.Issues).To(BeNil())
 	})
 })
+
+var _ = Describe("NewHost", func() {
+	It("should omit the unknown vendor and model", func() {
+		host := v1.NewHost(models.Host{ID: "host-1", Cluster: "cluster-a", CPUCores: 16, CPUSockets: 2, MemoryMB: 65536})
+
+		Expect(host.Id).To(Equal("host-1"))
+		Expect(host.CpuCores).To(Equal(16))
+		Expect(host.Vendor).To(BeNil())
+		Expect(host.Model).To(BeNil())
+	})
+
+	It("should map the vendor and model", func() {
+		host := v1.NewHost(models.Host{ID: "host-1", Vendor: "Dell", Model: "R740"})
+
+		Expect(host.Vendor).To(HaveValue(Equal("Dell")))
+		Expect(host.Model).To(HaveValue(Equal("R740")))
+	})
+})
+
+var _ = Describe("NewDatastore", func() {
+	It("should map the datastore", func() {
+		ds := v1.NewDatastore(models.Datastore{
+			ID: "naa.1", Type: "VMFS", ProtocolType: "iSCSI",
+			TotalCapacityGB: 100, FreeCapacityGB: 40, HostIDs: []string{"host-1", "host-2"},
+		})
+
+		Expect(ds.Id).To(Equal("naa.1"))
+		Expect(ds.Type).To(HaveValue(Equal("VMFS")))
+		Expect(ds.ProtocolType).To(HaveValue(Equal("iSCSI")))
+		Expect(ds.FreeCapacityGB).To(Equal(40.0))
+		Expect(ds.HostIds).To(Equal([]string{"host-1", "host-2"}))
+	})
+
+	It("should send an empty list of hosts rather than null", func() {
+		ds := v1.NewDatastore(models.Datastore{ID: "nfs-01"})
+
+		Expect(ds.HostIds).NotTo(BeNil())
+		Expect(ds.HostIds).To(BeEmpty())
+		Expect(ds.ProtocolType).To(BeNil())
+	})
+})
+
+var _ = Describe("NewNetwork", func() {
+	It("should map a port group with its switch and VLAN", func() {
+		n := v1.NewNetwork(models.Network{Name: "pg-100", Type: models.NetworkTypeDistributed, DVSwitch: "dvs-1", VlanID: "100", VMCount: 3})
+
+		Expect(n.Type).To(Equal(v1.NetworkTypeDistributed))
+		Expect(n.Dvswitch).To(HaveValue(Equal("dvs-1")))
+		Expect(n.VlanId).To(HaveValue(Equal("100")))
+		Expect(n.VmCount).To(Equal(3))
+	})
+
+	It("should omit the switch and VLAN of a switch", func() {
+		n := v1.NewNetwork(models.Network{Name: "dvs-1", Type: models.NetworkTypeDVSwitch})
+
+		Expect(n.Type).To(Equal(v1.NetworkTypeDvswitch))
+		Expect(n.Dvswitch).To(BeNil())
+		Expect(n.VlanId).To(BeNil())
+	})
+})
+
+var _ = Describe("state enums", func() {
+	It("should map every collector state to its API status", func() {
+		for _, state := range models.CollectorStates {
+			status := v1.NewCollectorStatus(models.CollectorStatus{State: state})
+			Expect(status.Status.Valid()).To(BeTrue(), "collector state %q is missing from the spec", state)
+			Expect(string(status.Status)).To(Equal(string(state)))
+		}
+		Expect(v1.CollectorStatusStatusValues).To(HaveLen(len(models.CollectorStates)))
+	})
+
+	It("should map every inspector state to its API state", func() {
+		for _, state := range models.InspectorStates {
+			status := v1.NewInspectorStatus(models.InspectorStatus{State: state})
+			Expect(status.State.Valid()).To(BeTrue(), "inspector state %q is missing from the spec", state)
+			Expect(string(status.State)).To(Equal(string(state)))
+		}
+		Expect(v1.InspectorStatusStateValues).To(HaveLen(len(models.InspectorStates)))
+	})
+
+	It("should map every inspection state to its API state", func() {
+		for _, state := range models.InspectionStates {
+			status := v1.NewInspectionStatus(models.InspectionStatus{State: state})
+			Expect(status.State.Valid()).To(BeTrue(), "inspection state %q is missing from the spec", state)
+			Expect(string(status.State)).To(Equal(string(state)))
+		}
+		Expect(v1.VmInspectionStatusStateValues).To(HaveLen(len(models.InspectionStates)))
+	})
+
+	It("should map every device login state to its API state", func() {
+		for _, state := range models.DeviceLoginStates {
+			login := v1.NewConsoleLogin(models.DeviceLogin{State: state})
+			Expect(login.State.Valid()).To(BeTrue(), "device login state %q is missing from the spec", state)
+			Expect(string(login.State)).To(Equal(string(state)))
+		}
+		Expect(v1.ConsoleLoginStateValues).To(HaveLen(len(models.DeviceLoginStates)))
+	})
+
+	It("should map every console status to its API connection", func() {
+		for _, current := range models.ConsoleStatuses {
+			var status v1.AgentStatus
+			status.FromModel(models.AgentStatus{Console: models.ConsoleStatus{Current: current}})
+			Expect(status.ConsoleConnection.Valid()).To(BeTrue(), "console status %q is missing from the spec", current)
+			Expect(string(status.ConsoleConnection)).To(Equal(string(current)))
+		}
+		Expect(v1.AgentStatusConsoleConnectionValues).To(HaveLen(len(models.ConsoleStatuses)))
+	})
+
+	It("should not accept a value outside the spec", func() {
+		Expect(v1.CollectorStatusStatus("unknown").Valid()).To(BeFalse())
+		Expect(v1.VmInspectionStatusState("").Valid()).To(BeFalse())
+	})
+})
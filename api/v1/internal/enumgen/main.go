@@ -0,0 +1,118 @@
+// Command enumgen generates the list of values and a Valid method for each
+// string enum of the object properties of the OpenAPI spec, next to the
+// types oapi-codegen generates for them.
+//
+// The converters of api/v1 cast the model states to these types and check
+// them with Valid, so a state the spec lacks is caught rather than falling
+// through to a default.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+type spec struct {
+	Components struct {
+		Schemas map[string]schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type schema struct {
+	Type       string            `yaml:"type"`
+	Enum       []string          `yaml:"enum"`
+	Properties map[string]schema `yaml:"properties"`
+}
+
+type enum struct {
+	Type   string
+	Values []string
+}
+
+var tmpl = template.Must(template.New("enums").Parse(`// Package v1 provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by enumgen from {{ .Spec }}. DO NOT EDIT.
+package v1
+{{ range .Enums }}
+// {{ .Type }}Values are the values of {{ .Type }}, in the order of the spec.
+var {{ .Type }}Values = []{{ .Type }}{ {{- range .Values }}
+	{{ printf "%q" . }},{{ end }}
+}
+
+// Valid tells whether e is one of {{ .Type }}Values.
+func (e {{ .Type }}) Valid() bool {
+	switch e {
+	case {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ printf "%q" $v }}{{ end }}:
+		return true
+	default:
+		return false
+	}
+}
+{{ end }}`))
+
+func main() {
+	specPath := flag.String("spec", "openapi.yaml", "path of the OpenAPI spec")
+	out := flag.String("out", "enums.gen.go", "path of the generated file")
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("enumgen: ")
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("failed to read spec: %v", err)
+	}
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		log.Fatalf("failed to parse spec: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]any{"Spec": *specPath, "Enums": enums(s)}); err != nil {
+		log.Fatalf("failed to render enums: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("failed to format enums: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("failed to write enums: %v", err)
+	}
+}
+
+// enums collects the string enums of the schema properties, named the way
+// oapi-codegen names their types: schema name followed by property name.
+func enums(s spec) []enum {
+	var result []enum
+	for name, sc := range s.Components.Schemas {
+		for prop, p := range sc.Properties {
+			if p.Type != "string" || len(p.Enum) == 0 {
+				continue
+			}
+			result = append(result, enum{Type: name + camelCase(prop), Values: p.Enum})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+	return result
+}
+
+func camelCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
@@ -19,6 +19,21 @@ type ServerInterface interface {
 	// Change agent mode
 	// (POST /agent)
 	SetAgentMode(c *gin.Context)
+	// Export the assessment as a signed bundle
+	// (GET /assessment/export)
+	ExportAssessment(c *gin.Context)
+	// Import an assessment bundle and send it to the console
+	// (POST /assessment/import)
+	ImportAssessment(c *gin.Context, params ImportAssessmentParams)
+	// Get the latest throughput benchmark
+	// (GET /benchmark)
+	GetBenchmark(c *gin.Context)
+	// Start a throughput benchmark
+	// (POST /benchmark)
+	StartBenchmark(c *gin.Context)
+	// Get the DRS affinity and anti-affinity rules of a cluster
+	// (GET /clusters/{name}/rules)
+	GetClusterRules(c *gin.Context, name string)
 	// Stop collection
 	// (DELETE /collector)
 	StopCollector(c *gin.Context)
@@ -28,9 +43,102 @@ type ServerInterface interface {
 	// Start inventory collection
 	// (POST /collector)
 	StartCollector(c *gin.Context)
+	// Get a concern with the metadata of its policy
+	// (GET /concerns/{id})
+	GetConcern(c *gin.Context, id string)
+	// Get the status of the device login of the console token
+	// (GET /console/login)
+	GetConsoleLogin(c *gin.Context)
+	// Start a device login to obtain the console token
+	// (POST /console/login)
+	StartConsoleLogin(c *gin.Context)
+	// Get the datastores of the inventory
+	// (GET /datastores)
+	GetDatastores(c *gin.Context, params GetDatastoresParams)
+	// Get the recent read/write throughput and latency of the datastores
+	// (GET /datastores/stats)
+	GetDatastoreStats(c *gin.Context)
+	// Get the lifecycle events of the agent
+	// (GET /events)
+	GetEvents(c *gin.Context, params GetEventsParams)
+	// Get the ESXi hosts of the inventory
+	// (GET /hosts)
+	GetHosts(c *gin.Context, params GetHostsParams)
 	// Get collected inventory
 	// (GET /inventory)
 	GetInventory(c *gin.Context)
+	// List the jobs of the agent
+	// (GET /jobs)
+	ListJobs(c *gin.Context, params ListJobsParams)
+	// Cancel a running job
+	// (DELETE /jobs/{id})
+	CancelJob(c *gin.Context, id string)
+	// Get a job
+	// (GET /jobs/{id})
+	GetJob(c *gin.Context, id string)
+	// Get the distributed switches and port groups of the inventory
+	// (GET /networks)
+	GetNetworks(c *gin.Context, params GetNetworksParams)
+	// List the migration plans
+	// (GET /plans)
+	ListPlans(c *gin.Context)
+	// Generate a migration plan
+	// (POST /plans)
+	GeneratePlan(c *gin.Context)
+	// Delete a migration plan
+	// (DELETE /plans/{id})
+	DeletePlan(c *gin.Context, id string)
+	// Get a migration plan
+	// (GET /plans/{id})
+	GetPlan(c *gin.Context, id string)
+	// Export a migration plan as a JSON file
+	// (GET /plans/{id}/export)
+	ExportPlan(c *gin.Context, id string)
+	// Export a migration plan as Forklift CRs
+	// (GET /plans/{id}/forklift)
+	ExportPlanForklift(c *gin.Context, id string, params ExportPlanForkliftParams)
+	// List the policies in use
+	// (GET /policies)
+	ListPolicies(c *gin.Context)
+	// List the custom policies
+	// (GET /policies/custom)
+	ListCustomPolicies(c *gin.Context)
+	// Delete a custom policy
+	// (DELETE /policies/custom/{name})
+	DeleteCustomPolicy(c *gin.Context, name string)
+	// Upload a custom policy
+	// (PUT /policies/custom/{name})
+	PutCustomPolicy(c *gin.Context, name string)
+	// Get the lifecycle events of the agent
+	// (GET /policies/decisions)
+	GetPolicyDecisions(c *gin.Context, params GetPolicyDecisionsParams)
+	// Evaluate the loaded policies against a VM
+	// (POST /policies/evaluate)
+	EvaluatePolicies(c *gin.Context)
+	// Run the rego tests of the policies
+	// (POST /policies/test)
+	RunPolicyTests(c *gin.Context)
+	// List the sources of the agent
+	// (GET /sources)
+	ListSources(c *gin.Context)
+	// Add a source, collected from its own vCenter
+	// (POST /sources)
+	AddSource(c *gin.Context)
+	// Remove a source with its inventory
+	// (DELETE /sources/{id})
+	RemoveSource(c *gin.Context, id string)
+	// Stop the collection of a source
+	// (DELETE /sources/{id}/collector)
+	StopSourceCollector(c *gin.Context, id string)
+	// Get the collector status of a source
+	// (GET /sources/{id}/collector)
+	GetSourceCollectorStatus(c *gin.Context, id string)
+	// Start the inventory collection of a source
+	// (POST /sources/{id}/collector)
+	StartSourceCollector(c *gin.Context, id string)
+	// Get the collected inventory of a source
+	// (GET /sources/{id}/inventory)
+	GetSourceInventory(c *gin.Context, id string)
 	// Upload VDDK tarball
 	// (POST /vddk)
 	PostVddk(c *gin.Context)
@@ -55,12 +163,18 @@ type ServerInterface interface {
 	// Get details about a vm
 	// (GET /vms/{id})
 	GetVM(c *gin.Context, id string)
+	// Get recent vCenter events and triggered alarms of a VM and its host
+	// (GET /vms/{id}/events)
+	GetVMEvents(c *gin.Context, id string)
 	// Remove VM from inspection queue
 	// (DELETE /vms/{id}/inspector)
 	RemoveVMFromInspection(c *gin.Context, id string)
 	// Get inspection status for a specific VM
 	// (GET /vms/{id}/inspector)
 	GetVMInspectionStatus(c *gin.Context, id string)
+	// Stream agent and collector status over a WebSocket
+	// (GET /ws)
+	GetStatusStream(c *gin.Context)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -98,6 +212,95 @@ func (siw *ServerInterfaceWrapper) SetAgentMode(c *gin.Context) {
 	siw.Handler.SetAgentMode(c)
 }
 
+// ExportAssessment operation middleware
+func (siw *ServerInterfaceWrapper) ExportAssessment(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportAssessment(c)
+}
+
+// ImportAssessment operation middleware
+func (siw *ServerInterfaceWrapper) ImportAssessment(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportAssessmentParams
+
+	// ------------- Optional query parameter "fingerprint" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "fingerprint", c.Request.URL.Query(), &params.Fingerprint)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter fingerprint: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ImportAssessment(c, params)
+}
+
+// GetBenchmark operation middleware
+func (siw *ServerInterfaceWrapper) GetBenchmark(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetBenchmark(c)
+}
+
+// StartBenchmark operation middleware
+func (siw *ServerInterfaceWrapper) StartBenchmark(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StartBenchmark(c)
+}
+
+// GetClusterRules operation middleware
+func (siw *ServerInterfaceWrapper) GetClusterRules(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetClusterRules(c, name)
+}
+
 // StopCollector operation middleware
 func (siw *ServerInterfaceWrapper) StopCollector(c *gin.Context) {
 
@@ -108,11 +311,605 @@ func (siw *ServerInterfaceWrapper) StopCollector(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.StopCollector(c)
+	siw.Handler.StopCollector(c)
+}
+
+// GetCollectorStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetCollectorStatus(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetCollectorStatus(c)
+}
+
+// StartCollector operation middleware
+func (siw *ServerInterfaceWrapper) StartCollector(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StartCollector(c)
+}
+
+// GetConcern operation middleware
+func (siw *ServerInterfaceWrapper) GetConcern(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetConcern(c, id)
+}
+
+// GetConsoleLogin operation middleware
+func (siw *ServerInterfaceWrapper) GetConsoleLogin(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetConsoleLogin(c)
+}
+
+// StartConsoleLogin operation middleware
+func (siw *ServerInterfaceWrapper) StartConsoleLogin(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StartConsoleLogin(c)
+}
+
+// GetDatastores operation middleware
+func (siw *ServerInterfaceWrapper) GetDatastores(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDatastoresParams
+
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", c.Request.URL.Query(), &params.Cluster)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cluster: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetDatastores(c, params)
+}
+
+// GetDatastoreStats operation middleware
+func (siw *ServerInterfaceWrapper) GetDatastoreStats(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetDatastoreStats(c)
+}
+
+// GetEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetEvents(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetEventsParams
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", c.Request.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter type: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", c.Request.URL.Query(), &params.Since)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter since: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetEvents(c, params)
+}
+
+// GetHosts operation middleware
+func (siw *ServerInterfaceWrapper) GetHosts(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetHostsParams
+
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", c.Request.URL.Query(), &params.Cluster)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter cluster: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetHosts(c, params)
+}
+
+// GetInventory operation middleware
+func (siw *ServerInterfaceWrapper) GetInventory(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetInventory(c)
+}
+
+// ListJobs operation middleware
+func (siw *ServerInterfaceWrapper) ListJobs(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListJobsParams
+
+	// ------------- Optional query parameter "kind" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "kind", c.Request.URL.Query(), &params.Kind)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter kind: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "state" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "state", c.Request.URL.Query(), &params.State)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter state: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListJobs(c, params)
+}
+
+// CancelJob operation middleware
+func (siw *ServerInterfaceWrapper) CancelJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CancelJob(c, id)
+}
+
+// GetJob operation middleware
+func (siw *ServerInterfaceWrapper) GetJob(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetJob(c, id)
+}
+
+// GetNetworks operation middleware
+func (siw *ServerInterfaceWrapper) GetNetworks(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetNetworksParams
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetNetworks(c, params)
+}
+
+// ListPlans operation middleware
+func (siw *ServerInterfaceWrapper) ListPlans(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListPlans(c)
+}
+
+// GeneratePlan operation middleware
+func (siw *ServerInterfaceWrapper) GeneratePlan(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GeneratePlan(c)
+}
+
+// DeletePlan operation middleware
+func (siw *ServerInterfaceWrapper) DeletePlan(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeletePlan(c, id)
+}
+
+// GetPlan operation middleware
+func (siw *ServerInterfaceWrapper) GetPlan(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetPlan(c, id)
+}
+
+// ExportPlan operation middleware
+func (siw *ServerInterfaceWrapper) ExportPlan(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportPlan(c, id)
+}
+
+// ExportPlanForklift operation middleware
+func (siw *ServerInterfaceWrapper) ExportPlanForklift(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportPlanForkliftParams
+
+	// ------------- Optional query parameter "namespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "namespace", c.Request.URL.Query(), &params.Namespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter namespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sourceProvider" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sourceProvider", c.Request.URL.Query(), &params.SourceProvider)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceProvider: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "destinationProvider" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "destinationProvider", c.Request.URL.Query(), &params.DestinationProvider)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter destinationProvider: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "targetNamespace" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "targetNamespace", c.Request.URL.Query(), &params.TargetNamespace)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter targetNamespace: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "storageClass" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "storageClass", c.Request.URL.Query(), &params.StorageClass)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter storageClass: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "network" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "network", c.Request.URL.Query(), &params.Network)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter network: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "storage" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "storage", c.Request.URL.Query(), &params.Storage)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter storage: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "warm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "warm", c.Request.URL.Query(), &params.Warm)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter warm: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportPlanForklift(c, id, params)
+}
+
+// ListPolicies operation middleware
+func (siw *ServerInterfaceWrapper) ListPolicies(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListPolicies(c)
+}
+
+// ListCustomPolicies operation middleware
+func (siw *ServerInterfaceWrapper) ListCustomPolicies(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListCustomPolicies(c)
 }
 
-// GetCollectorStatus operation middleware
-func (siw *ServerInterfaceWrapper) GetCollectorStatus(c *gin.Context) {
+// DeleteCustomPolicy operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCustomPolicy(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -121,11 +918,22 @@ func (siw *ServerInterfaceWrapper) GetCollectorStatus(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetCollectorStatus(c)
+	siw.Handler.DeleteCustomPolicy(c, name)
 }
 
-// StartCollector operation middleware
-func (siw *ServerInterfaceWrapper) StartCollector(c *gin.Context) {
+// PutCustomPolicy operation middleware
+func (siw *ServerInterfaceWrapper) PutCustomPolicy(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -134,11 +942,64 @@ func (siw *ServerInterfaceWrapper) StartCollector(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.StartCollector(c)
+	siw.Handler.PutCustomPolicy(c, name)
 }
 
-// GetInventory operation middleware
-func (siw *ServerInterfaceWrapper) GetInventory(c *gin.Context) {
+// GetPolicyDecisions operation middleware
+func (siw *ServerInterfaceWrapper) GetPolicyDecisions(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPolicyDecisionsParams
+
+	// ------------- Optional query parameter "vmId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "vmId", c.Request.URL.Query(), &params.VmId)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter vmId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "rule" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rule", c.Request.URL.Query(), &params.Rule)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter rule: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "decision" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "decision", c.Request.URL.Query(), &params.Decision)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter decision: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", c.Request.URL.Query(), &params.Since)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter since: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", c.Request.URL.Query(), &params.Page)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter page: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", c.Request.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter pageSize: %w", err), http.StatusBadRequest)
+		return
+	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
@@ -147,7 +1008,179 @@ func (siw *ServerInterfaceWrapper) GetInventory(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.GetInventory(c)
+	siw.Handler.GetPolicyDecisions(c, params)
+}
+
+// EvaluatePolicies operation middleware
+func (siw *ServerInterfaceWrapper) EvaluatePolicies(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.EvaluatePolicies(c)
+}
+
+// RunPolicyTests operation middleware
+func (siw *ServerInterfaceWrapper) RunPolicyTests(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RunPolicyTests(c)
+}
+
+// ListSources operation middleware
+func (siw *ServerInterfaceWrapper) ListSources(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListSources(c)
+}
+
+// AddSource operation middleware
+func (siw *ServerInterfaceWrapper) AddSource(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddSource(c)
+}
+
+// RemoveSource operation middleware
+func (siw *ServerInterfaceWrapper) RemoveSource(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RemoveSource(c, id)
+}
+
+// StopSourceCollector operation middleware
+func (siw *ServerInterfaceWrapper) StopSourceCollector(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StopSourceCollector(c, id)
+}
+
+// GetSourceCollectorStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetSourceCollectorStatus(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetSourceCollectorStatus(c, id)
+}
+
+// StartSourceCollector operation middleware
+func (siw *ServerInterfaceWrapper) StartSourceCollector(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StartSourceCollector(c, id)
+}
+
+// GetSourceInventory operation middleware
+func (siw *ServerInterfaceWrapper) GetSourceInventory(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetSourceInventory(c, id)
 }
 
 // PostVddk operation middleware
@@ -240,6 +1273,22 @@ func (siw *ServerInterfaceWrapper) GetVMs(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "encrypted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "encrypted", c.Request.URL.Query(), &params.Encrypted)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter encrypted: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "severity" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "severity", c.Request.URL.Query(), &params.Severity)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter severity: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	// ------------- Optional query parameter "sort" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "sort", c.Request.URL.Query(), &params.Sort)
@@ -350,6 +1399,30 @@ func (siw *ServerInterfaceWrapper) GetVM(c *gin.Context) {
 	siw.Handler.GetVM(c, id)
 }
 
+// GetVMEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetVMEvents(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetVMEvents(c, id)
+}
+
 // RemoveVMFromInspection operation middleware
 func (siw *ServerInterfaceWrapper) RemoveVMFromInspection(c *gin.Context) {
 
@@ -398,6 +1471,19 @@ func (siw *ServerInterfaceWrapper) GetVMInspectionStatus(c *gin.Context) {
 	siw.Handler.GetVMInspectionStatus(c, id)
 }
 
+// GetStatusStream operation middleware
+func (siw *ServerInterfaceWrapper) GetStatusStream(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetStatusStream(c)
+}
+
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
 	BaseURL      string
@@ -427,10 +1513,46 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 
 	router.GET(options.BaseURL+"/agent", wrapper.GetAgentStatus)
 	router.POST(options.BaseURL+"/agent", wrapper.SetAgentMode)
+	router.GET(options.BaseURL+"/assessment/export", wrapper.ExportAssessment)
+	router.POST(options.BaseURL+"/assessment/import", wrapper.ImportAssessment)
+	router.GET(options.BaseURL+"/benchmark", wrapper.GetBenchmark)
+	router.POST(options.BaseURL+"/benchmark", wrapper.StartBenchmark)
+	router.GET(options.BaseURL+"/clusters/:name/rules", wrapper.GetClusterRules)
 	router.DELETE(options.BaseURL+"/collector", wrapper.StopCollector)
 	router.GET(options.BaseURL+"/collector", wrapper.GetCollectorStatus)
 	router.POST(options.BaseURL+"/collector", wrapper.StartCollector)
+	router.GET(options.BaseURL+"/concerns/:id", wrapper.GetConcern)
+	router.GET(options.BaseURL+"/console/login", wrapper.GetConsoleLogin)
+	router.POST(options.BaseURL+"/console/login", wrapper.StartConsoleLogin)
+	router.GET(options.BaseURL+"/datastores", wrapper.GetDatastores)
+	router.GET(options.BaseURL+"/datastores/stats", wrapper.GetDatastoreStats)
+	router.GET(options.BaseURL+"/events", wrapper.GetEvents)
+	router.GET(options.BaseURL+"/hosts", wrapper.GetHosts)
 	router.GET(options.BaseURL+"/inventory", wrapper.GetInventory)
+	router.GET(options.BaseURL+"/jobs", wrapper.ListJobs)
+	router.DELETE(options.BaseURL+"/jobs/:id", wrapper.CancelJob)
+	router.GET(options.BaseURL+"/jobs/:id", wrapper.GetJob)
+	router.GET(options.BaseURL+"/networks", wrapper.GetNetworks)
+	router.GET(options.BaseURL+"/plans", wrapper.ListPlans)
+	router.POST(options.BaseURL+"/plans", wrapper.GeneratePlan)
+	router.DELETE(options.BaseURL+"/plans/:id", wrapper.DeletePlan)
+	router.GET(options.BaseURL+"/plans/:id", wrapper.GetPlan)
+	router.GET(options.BaseURL+"/plans/:id/export", wrapper.ExportPlan)
+	router.GET(options.BaseURL+"/plans/:id/forklift", wrapper.ExportPlanForklift)
+	router.GET(options.BaseURL+"/policies", wrapper.ListPolicies)
+	router.GET(options.BaseURL+"/policies/custom", wrapper.ListCustomPolicies)
+	router.DELETE(options.BaseURL+"/policies/custom/:name", wrapper.DeleteCustomPolicy)
+	router.PUT(options.BaseURL+"/policies/custom/:name", wrapper.PutCustomPolicy)
+	router.GET(options.BaseURL+"/policies/decisions", wrapper.GetPolicyDecisions)
+	router.POST(options.BaseURL+"/policies/evaluate", wrapper.EvaluatePolicies)
+	router.POST(options.BaseURL+"/policies/test", wrapper.RunPolicyTests)
+	router.GET(options.BaseURL+"/sources", wrapper.ListSources)
+	router.POST(options.BaseURL+"/sources", wrapper.AddSource)
+	router.DELETE(options.BaseURL+"/sources/:id", wrapper.RemoveSource)
+	router.DELETE(options.BaseURL+"/sources/:id/collector", wrapper.StopSourceCollector)
+	router.GET(options.BaseURL+"/sources/:id/collector", wrapper.GetSourceCollectorStatus)
+	router.POST(options.BaseURL+"/sources/:id/collector", wrapper.StartSourceCollector)
+	router.GET(options.BaseURL+"/sources/:id/inventory", wrapper.GetSourceInventory)
 	router.POST(options.BaseURL+"/vddk", wrapper.PostVddk)
 	router.GET(options.BaseURL+"/version", wrapper.GetVersion)
 	router.GET(options.BaseURL+"/vms", wrapper.GetVMs)
@@ -439,6 +1561,8 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 	router.PATCH(options.BaseURL+"/vms/inspector", wrapper.AddVMsToInspection)
 	router.POST(options.BaseURL+"/vms/inspector", wrapper.StartInspection)
 	router.GET(options.BaseURL+"/vms/:id", wrapper.GetVM)
+	router.GET(options.BaseURL+"/vms/:id/events", wrapper.GetVMEvents)
 	router.DELETE(options.BaseURL+"/vms/:id/inspector", wrapper.RemoveVMFromInspection)
 	router.GET(options.BaseURL+"/vms/:id/inspector", wrapper.GetVMInspectionStatus)
+	router.GET(options.BaseURL+"/ws", wrapper.GetStatusStream)
 }
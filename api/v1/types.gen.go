@@ -3,6 +3,16 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.3.0 DO NOT EDIT.
 package v1
 
+import (
+	"time"
+)
+
+// Defines values for AgentHARole.
+const (
+	AgentHARoleLeader  AgentHARole = "leader"
+	AgentHARoleStandby AgentHARole = "standby"
+)
+
 // Defines values for AgentModeRequestMode.
 const (
 	AgentModeRequestModeConnected    AgentModeRequestMode = "connected"
@@ -21,6 +31,30 @@ const (
 	AgentStatusModeDisconnected AgentStatusMode = "disconnected"
 )
 
+// Defines values for AgentUpdateState.
+const (
+	AgentUpdateStateDisabled    AgentUpdateState = "disabled"
+	AgentUpdateStateDownloading AgentUpdateState = "downloading"
+	AgentUpdateStateFailed      AgentUpdateState = "failed"
+	AgentUpdateStateInstalled   AgentUpdateState = "installed"
+	AgentUpdateStateUpToDate    AgentUpdateState = "up-to-date"
+)
+
+// Defines values for ClusterRuleType.
+const (
+	ClusterRuleTypeVmAffinity         ClusterRuleType = "vm-affinity"
+	ClusterRuleTypeVmAntiAffinity     ClusterRuleType = "vm-anti-affinity"
+	ClusterRuleTypeVmHostAffinity     ClusterRuleType = "vm-host-affinity"
+	ClusterRuleTypeVmHostAntiAffinity ClusterRuleType = "vm-host-anti-affinity"
+)
+
+// Defines values for CollectorStartRequestProvider.
+const (
+	CollectorStartRequestProviderHyperv  CollectorStartRequestProvider = "hyperv"
+	CollectorStartRequestProviderOvirt   CollectorStartRequestProvider = "ovirt"
+	CollectorStartRequestProviderVsphere CollectorStartRequestProvider = "vsphere"
+)
+
 // Defines values for CollectorStatusStatus.
 const (
 	CollectorStatusStatusCollected  CollectorStatusStatus = "collected"
@@ -32,6 +66,14 @@ const (
 	CollectorStatusStatusReady      CollectorStatusStatus = "ready"
 )
 
+// Defines values for ConsoleLoginState.
+const (
+	ConsoleLoginStateFailed    ConsoleLoginState = "failed"
+	ConsoleLoginStateIdle      ConsoleLoginState = "idle"
+	ConsoleLoginStatePending   ConsoleLoginState = "pending"
+	ConsoleLoginStateSucceeded ConsoleLoginState = "succeeded"
+)
+
 // Defines values for InspectorStatusState.
 const (
 	InspectorStatusStateCanceled   InspectorStatusState = "canceled"
@@ -43,6 +85,76 @@ const (
 	InspectorStatusStateRunning    InspectorStatusState = "running"
 )
 
+// Defines values for JobKind.
+const (
+	JobKindAssessmentExport JobKind = "assessment-export"
+	JobKindBenchmark        JobKind = "benchmark"
+	JobKindCollection       JobKind = "collection"
+	JobKindInspection       JobKind = "inspection"
+	JobKindSupportBundle    JobKind = "support-bundle"
+)
+
+// Defines values for JobState.
+const (
+	JobStateCanceled  JobState = "canceled"
+	JobStateCompleted JobState = "completed"
+	JobStateFailed    JobState = "failed"
+	JobStateRunning   JobState = "running"
+)
+
+// Defines values for NetworkType.
+const (
+	NetworkTypeDistributed NetworkType = "distributed"
+	NetworkTypeDvswitch    NetworkType = "dvswitch"
+)
+
+// Defines values for PlanThroughputSource.
+const (
+	PlanThroughputSourceBenchmark PlanThroughputSource = "benchmark"
+	PlanThroughputSourceDefault   PlanThroughputSource = "default"
+	PlanThroughputSourceRequest   PlanThroughputSource = "request"
+)
+
+// Defines values for PolicyDecisionDecision.
+const (
+	PolicyDecisionDecisionFlagged PolicyDecisionDecision = "flagged"
+	PolicyDecisionDecisionPassed  PolicyDecisionDecision = "passed"
+)
+
+// Defines values for PolicyFileSource.
+const (
+	PolicyFileSourceBuiltin PolicyFileSource = "builtin"
+	PolicyFileSourceConsole PolicyFileSource = "console"
+	PolicyFileSourceCustom  PolicyFileSource = "custom"
+)
+
+// Defines values for PolicyTestResultStatus.
+const (
+	PolicyTestResultStatusError PolicyTestResultStatus = "error"
+	PolicyTestResultStatusFail  PolicyTestResultStatus = "fail"
+	PolicyTestResultStatusPass  PolicyTestResultStatus = "pass"
+	PolicyTestResultStatusSkip  PolicyTestResultStatus = "skip"
+)
+
+// Defines values for SourceConsoleConnection.
+const (
+	SourceConsoleConnectionConnected    SourceConsoleConnection = "connected"
+	SourceConsoleConnectionDisconnected SourceConsoleConnection = "disconnected"
+)
+
+// Defines values for VCenterEventKind.
+const (
+	VCenterEventKindAlarm VCenterEventKind = "alarm"
+	VCenterEventKindEvent VCenterEventKind = "event"
+)
+
+// Defines values for VCenterEventSeverity.
+const (
+	VCenterEventSeverityError   VCenterEventSeverity = "error"
+	VCenterEventSeverityInfo    VCenterEventSeverity = "info"
+	VCenterEventSeverityWarning VCenterEventSeverity = "warning"
+)
+
 // Defines values for VmInspectionStatusState.
 const (
 	VmInspectionStatusStateCanceled  VmInspectionStatusState = "canceled"
@@ -53,6 +165,53 @@ const (
 	VmInspectionStatusStateRunning   VmInspectionStatusState = "running"
 )
 
+// AgentEvent defines model for AgentEvent.
+type AgentEvent struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Details Details of the event, such as the error or the VM ID
+	Details *map[string]string `json:"details,omitempty"`
+	Message string             `json:"message"`
+
+	// Type Event type, e.g. collection.started or console.dispatch_failed
+	Type string `json:"type"`
+}
+
+// AgentEventListResponse defines model for AgentEventListResponse.
+type AgentEventListResponse struct {
+	Events []AgentEvent `json:"events"`
+
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
+// AgentHA Leader election of an agent of a high availability pair, absent for a single agent
+type AgentHA struct {
+	// Identity Name of the agent in the lease
+	Identity string `json:"identity"`
+
+	// Leader Agent holding the lease, absent when none does
+	Leader *string `json:"leader,omitempty"`
+
+	// RenewedAt Last renewal of the lease by the leader
+	RenewedAt *time.Time `json:"renewedAt,omitempty"`
+
+	// Role The standby rejects the mutating requests with 503 STANDBY
+	Role AgentHARole `json:"role"`
+}
+
+// AgentHARole The standby rejects the mutating requests with 503 STANDBY
+type AgentHARole string
+
 // AgentModeRequest defines model for AgentModeRequest.
 type AgentModeRequest struct {
 	Mode AgentModeRequestMode `json:"mode"`
@@ -69,8 +228,14 @@ type AgentStatus struct {
 	// Error Connection error description
 	Error *string `json:"error,omitempty"`
 
+	// Ha Leader election of an agent of a high availability pair, absent for a single agent
+	Ha *AgentHA `json:"ha,omitempty"`
+
 	// Mode Target mode for the agent
 	Mode AgentStatusMode `json:"mode"`
+
+	// Update Self-update of the agent from its update channel on the console
+	Update *AgentUpdate `json:"update,omitempty"`
 }
 
 // AgentStatusConsoleConnection Current console connection status
@@ -79,15 +244,120 @@ type AgentStatusConsoleConnection string
 // AgentStatusMode Target mode for the agent
 type AgentStatusMode string
 
+// AgentUpdate Self-update of the agent from its update channel on the console
+type AgentUpdate struct {
+	// AvailableVersion Version of the channel newer than the current one
+	AvailableVersion *string `json:"availableVersion,omitempty"`
+
+	// CheckedAt Time of the last check of the channel
+	CheckedAt      *time.Time `json:"checkedAt,omitempty"`
+	CurrentVersion string     `json:"currentVersion"`
+
+	// Error Failure of the last check or update
+	Error *string `json:"error,omitempty"`
+
+	// State installed means the agent restarts into the available version
+	State AgentUpdateState `json:"state"`
+}
+
+// AgentUpdateState installed means the agent restarts into the available version
+type AgentUpdateState string
+
+// AssessmentImportResult Manifest of an imported assessment bundle
+type AssessmentImportResult struct {
+	// AgentId ID of the agent which exported the bundle
+	AgentId      string `json:"agentId"`
+	AgentVersion string `json:"agentVersion"`
+
+	// CollectedAt Time of the collection of the inventory
+	CollectedAt time.Time `json:"collectedAt"`
+
+	// CreatedAt Time of the export
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Fingerprint SHA-256 fingerprint of the key which signed the bundle
+	Fingerprint string `json:"fingerprint"`
+
+	// SourceId ID of the source of the inventory
+	SourceId string `json:"sourceId"`
+}
+
+// Benchmark defines model for Benchmark.
+type Benchmark struct {
+	CreatedAt  time.Time           `json:"createdAt"`
+	Datastores []ThroughputMeasure `json:"datastores"`
+	Id         string              `json:"id"`
+	Network    *ThroughputMeasure  `json:"network,omitempty"`
+
+	// ThroughputMBps Rate of the plans in MB/s, the one of the slowest datastore bounded by the network
+	ThroughputMBps float32 `json:"throughputMBps"`
+}
+
+// BenchmarkRequest defines model for BenchmarkRequest.
+type BenchmarkRequest struct {
+	VcenterCredentials VcenterCredentials `json:"VcenterCredentials"`
+
+	// Datastores Datastores to measure, all the ones of the vCenter when empty
+	Datastores *[]string `json:"datastores,omitempty"`
+
+	// DurationSeconds Duration of each measure, 10 when not set
+	DurationSeconds *int `json:"durationSeconds,omitempty"`
+
+	// Target tcp:// or http(s):// endpoint receiving the data of the network measure, not measured when empty
+	Target *string `json:"target,omitempty"`
+}
+
+// BenchmarkStarted defines model for BenchmarkStarted.
+type BenchmarkStarted struct {
+	// JobId Id of the benchmark job
+	JobId string `json:"jobId"`
+}
+
+// ClusterRule defines model for ClusterRule.
+type ClusterRule struct {
+	// Enabled Whether the rule is enabled
+	Enabled bool `json:"enabled"`
+
+	// HostGroup Name of the host group (VM-host rules only)
+	HostGroup *string `json:"hostGroup,omitempty"`
+
+	// Hosts IDs of the hosts in the host group (VM-host rules only)
+	Hosts *[]string `json:"hosts,omitempty"`
+
+	// Mandatory Whether the rule must be satisfied (required) or is only a preference
+	Mandatory bool `json:"mandatory"`
+
+	// Name Rule name
+	Name string `json:"name"`
+
+	// Type Kind of the rule. VM-host rules bind a VM group to a host group.
+	Type ClusterRuleType `json:"type"`
+
+	// VmGroup Name of the VM group (VM-host rules only)
+	VmGroup *string `json:"vmGroup,omitempty"`
+
+	// Vms IDs of the VMs the rule applies to
+	Vms []string `json:"vms"`
+}
+
+// ClusterRuleType Kind of the rule. VM-host rules bind a VM group to a host group.
+type ClusterRuleType string
+
 // CollectorStartRequest defines model for CollectorStartRequest.
 type CollectorStartRequest struct {
 	Password string `json:"password"`
 
-	// Url vCenter URL
+	// Provider Kind of hypervisor manager at the URL
+	Provider *CollectorStartRequestProvider `json:"provider,omitempty"`
+
+	// Url vCenter URL, the WinRM URL of a Hyper-V host or the URL of an oVirt engine
 	Url      string `json:"url"`
 	Username string `json:"username"`
 }
 
+// CollectorStartRequestProvider Kind of hypervisor manager at the URL
+type CollectorStartRequestProvider string
+
 // CollectorStatus defines model for CollectorStatus.
 type CollectorStatus struct {
 	// Error Error message when status is error
@@ -98,6 +368,141 @@ type CollectorStatus struct {
 // CollectorStatusStatus defines model for CollectorStatus.Status.
 type CollectorStatusStatus string
 
+// Concern defines model for Concern.
+type Concern struct {
+	Assessment string `json:"assessment"`
+
+	// Category Critical, Warning or Information
+	Category string  `json:"category"`
+	DocsUrl  *string `json:"docsUrl,omitempty"`
+
+	// Id ID of the rule raising the concern
+	Id          string  `json:"id"`
+	Label       string  `json:"label"`
+	Remediation *string `json:"remediation,omitempty"`
+
+	// Severity Severity of the policy metadata, else the category in lowercase
+	Severity *string `json:"severity,omitempty"`
+}
+
+// ConcernDetail defines model for ConcernDetail.
+type ConcernDetail struct {
+	Category string `json:"category"`
+
+	// DocsUrl Link to the documentation of the concern
+	DocsUrl *string `json:"docsUrl,omitempty"`
+	Id      string  `json:"id"`
+	Label   string  `json:"label"`
+
+	// Remediation How to address the concern
+	Remediation *string `json:"remediation,omitempty"`
+	Severity    string  `json:"severity"`
+
+	// Title Title of the policy rule
+	Title *string `json:"title,omitempty"`
+
+	// VmCount Number of VMs flagged by the concern
+	VmCount int `json:"vmCount"`
+}
+
+// ConsoleLogin defines model for ConsoleLogin.
+type ConsoleLogin struct {
+	// Error Why the login failed
+	Error *string `json:"error,omitempty"`
+
+	// ExpiresAt Expiry of the code while pending, of the token once succeeded
+	ExpiresAt *time.Time        `json:"expiresAt,omitempty"`
+	State     ConsoleLoginState `json:"state"`
+
+	// UserCode Code to enter at verificationUri, while pending
+	UserCode *string `json:"userCode,omitempty"`
+
+	// VerificationUri Page of the identity provider where the user enters the code
+	VerificationUri *string `json:"verificationUri,omitempty"`
+
+	// VerificationUriComplete Page of the identity provider with the code filled in
+	VerificationUriComplete *string `json:"verificationUriComplete,omitempty"`
+}
+
+// ConsoleLoginState defines model for ConsoleLogin.State.
+type ConsoleLoginState string
+
+// CustomPolicy defines model for CustomPolicy.
+type CustomPolicy struct {
+	ModifiedAt time.Time `json:"modifiedAt"`
+
+	// Name File name of the policy
+	Name string `json:"name"`
+
+	// Size Size of the policy in bytes
+	Size int64 `json:"size"`
+}
+
+// Datastore defines model for Datastore.
+type Datastore struct {
+	// FreeCapacityGB Free capacity in GB
+	FreeCapacityGB float64 `json:"freeCapacityGB"`
+
+	// HardwareAcceleratedMove Whether the datastore supports VAAI hardware accelerated move
+	HardwareAcceleratedMove bool `json:"hardwareAcceleratedMove"`
+
+	// HostIds Managed object IDs of the hosts mounting the datastore
+	HostIds []string `json:"hostIds"`
+
+	// Id Disk address of the datastore, or its name when it has none
+	Id string `json:"id"`
+
+	// ProtocolType Storage protocol (iSCSI, FC...), omitted when unknown
+	ProtocolType *string `json:"protocolType,omitempty"`
+
+	// TotalCapacityGB Capacity in GB
+	TotalCapacityGB float64 `json:"totalCapacityGB"`
+
+	// Type Datastore type (VMFS, NFS, vsan...), omitted when unknown
+	Type *string `json:"type,omitempty"`
+}
+
+// DatastoreListResponse defines model for DatastoreListResponse.
+type DatastoreListResponse struct {
+	Datastores []Datastore `json:"datastores"`
+
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
+// DatastoreStats defines model for DatastoreStats.
+type DatastoreStats struct {
+	// Id Datastore ID
+	Id string `json:"id"`
+
+	// Name Datastore name
+	Name string `json:"name"`
+
+	// ReadKBps Read throughput in KB/s, summed over all the hosts
+	ReadKBps int64 `json:"readKBps"`
+
+	// ReadLatencyMs Read latency in milliseconds, worst host
+	ReadLatencyMs int64 `json:"readLatencyMs"`
+
+	// SampledAt When the statistics were sampled
+	SampledAt time.Time `json:"sampledAt"`
+
+	// WriteKBps Write throughput in KB/s, summed over all the hosts
+	WriteKBps int64 `json:"writeKBps"`
+
+	// WriteLatencyMs Write latency in milliseconds, worst host
+	WriteLatencyMs int64 `json:"writeLatencyMs"`
+}
+
 // GuestNetwork defines model for GuestNetwork.
 type GuestNetwork struct {
 	// Device Name of the network device inside the guest OS
@@ -116,6 +521,47 @@ type GuestNetwork struct {
 	PrefixLength *int32 `json:"prefixLength,omitempty"`
 }
 
+// Host defines model for Host.
+type Host struct {
+	// Cluster Cluster of the host
+	Cluster string `json:"cluster"`
+
+	// CpuCores Number of CPU cores
+	CpuCores int `json:"cpuCores"`
+
+	// CpuSockets Number of CPU sockets
+	CpuSockets int `json:"cpuSockets"`
+
+	// Id Host managed object ID
+	Id string `json:"id"`
+
+	// MemoryMB Memory in MB
+	MemoryMB int `json:"memoryMB"`
+
+	// Model Hardware model, omitted when unknown
+	Model *string `json:"model,omitempty"`
+
+	// Vendor Hardware vendor, omitted when unknown
+	Vendor *string `json:"vendor,omitempty"`
+}
+
+// HostListResponse defines model for HostListResponse.
+type HostListResponse struct {
+	Hosts []Host `json:"hosts"`
+
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
 // InspectorStartRequest defines model for InspectorStartRequest.
 type InspectorStartRequest struct {
 	VcenterCredentials VcenterCredentials `json:"VcenterCredentials"`
@@ -136,6 +582,398 @@ type InspectorStatus struct {
 // InspectorStatusState Inspector state
 type InspectorStatusState string
 
+// Job Long-running operation of the agent
+type Job struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Error Error of a failed job
+	Error      *string    `json:"error,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Id         string     `json:"id"`
+	Kind       JobKind    `json:"kind"`
+
+	// Phase Current phase of the operation, e.g. the collector state
+	Phase *string `json:"phase,omitempty"`
+
+	// Progress Progress of the operation in percent
+	Progress  int       `json:"progress"`
+	State     JobState  `json:"state"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// JobKind defines model for Job.Kind.
+type JobKind string
+
+// JobState defines model for Job.State.
+type JobState string
+
+// JobListResponse defines model for JobListResponse.
+type JobListResponse struct {
+	Jobs []Job `json:"jobs"`
+
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
+// Network defines model for Network.
+type Network struct {
+	// Dvswitch Switch of a port group
+	Dvswitch *string `json:"dvswitch,omitempty"`
+
+	// Name Network name
+	Name string `json:"name"`
+
+	// Type A distributed switch, or a distributed port group
+	Type NetworkType `json:"type"`
+
+	// VlanId VLAN of a port group
+	VlanId *string `json:"vlanId,omitempty"`
+
+	// VmCount Number of VM NICs attached to the network
+	VmCount int `json:"vmCount"`
+}
+
+// NetworkType A distributed switch, or a distributed port group
+type NetworkType string
+
+// NetworkListResponse defines model for NetworkListResponse.
+type NetworkListResponse struct {
+	Networks []Network `json:"networks"`
+
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
+// Pagination Where a page stands in its listing, shared by the list responses
+type Pagination struct {
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
+// Plan Migration plan of VMs in waves
+type Plan struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// EstimatedTransferSeconds Time the disks of every VM take to transfer
+	EstimatedTransferSeconds int64  `json:"estimatedTransferSeconds"`
+	Id                       string `json:"id"`
+	Name                     string `json:"name"`
+
+	// Resources Resources the target needs for VMs
+	Resources PlanResources `json:"resources"`
+
+	// ThroughputMBps Transfer rate of the estimates in MB/s
+	ThroughputMBps float32 `json:"throughputMBps"`
+
+	// ThroughputSource Where the transfer rate comes from
+	ThroughputSource PlanThroughputSource `json:"throughputSource"`
+	Waves            []PlanWave           `json:"waves"`
+}
+
+// PlanThroughputSource Where the transfer rate comes from
+type PlanThroughputSource string
+
+// PlanList defines model for PlanList.
+type PlanList struct {
+	Plans []Plan `json:"plans"`
+}
+
+// PlanRequest defines model for PlanRequest.
+type PlanRequest struct {
+	Name *string `json:"name,omitempty"`
+
+	// ThroughputMBps Transfer rate of the estimates in MB/s, the one of the latest benchmark when not set, else 100
+	ThroughputMBps *float32 `json:"throughputMBps,omitempty"`
+
+	// Waves Waves of VMs, migrated in this order
+	Waves []PlanWaveRequest `json:"waves"`
+}
+
+// PlanResources Resources the target needs for VMs
+type PlanResources struct {
+	Cpus      int64 `json:"cpus"`
+	MemoryMB  int64 `json:"memoryMB"`
+	StorageMB int64 `json:"storageMB"`
+	Vms       int   `json:"vms"`
+}
+
+// PlanVM defines model for PlanVM.
+type PlanVM struct {
+	Cluster string `json:"cluster"`
+	Cpus    int32  `json:"cpus"`
+
+	// CriticalConcerns Number of concerns blocking the migration of the VM
+	CriticalConcerns         int    `json:"criticalConcerns"`
+	DiskMB                   int64  `json:"diskMB"`
+	EstimatedTransferSeconds int64  `json:"estimatedTransferSeconds"`
+	Id                       string `json:"id"`
+	MemoryMB                 int32  `json:"memoryMB"`
+	Name                     string `json:"name"`
+
+	// Order Position of the VM in the plan, from 1
+	Order      int    `json:"order"`
+	PowerState string `json:"powerState"`
+}
+
+// PlanWave defines model for PlanWave.
+type PlanWave struct {
+	EstimatedTransferSeconds int64  `json:"estimatedTransferSeconds"`
+	Name                     string `json:"name"`
+
+	// Resources Resources the target needs for VMs
+	Resources PlanResources `json:"resources"`
+
+	// Vms VMs of the wave, in migration order
+	Vms []PlanVM `json:"vms"`
+}
+
+// PlanWaveRequest defines model for PlanWaveRequest.
+type PlanWaveRequest struct {
+	// Name Name of the wave, wave-N when not set
+	Name *string `json:"name,omitempty"`
+
+	// Vms IDs of the VMs of the wave
+	Vms []string `json:"vms"`
+}
+
+// PolicyBundle Policy bundle of the console installed in the policies folder
+type PolicyBundle struct {
+	InstalledAt time.Time `json:"installedAt"`
+
+	// Revision Revision of the bundle manifest, else its ETag
+	Revision string `json:"revision"`
+	Url      string `json:"url"`
+}
+
+// PolicyDecision defines model for PolicyDecision.
+type PolicyDecision struct {
+	CreatedAt time.Time              `json:"createdAt"`
+	Decision  PolicyDecisionDecision `json:"decision"`
+
+	// DurationUs Duration of the evaluation of the VM, in microseconds
+	DurationUs int64 `json:"durationUs"`
+
+	// Rule ID of the concern raised, absent when passed
+	Rule   *string `json:"rule,omitempty"`
+	VmId   string  `json:"vmId"`
+	VmName *string `json:"vmName,omitempty"`
+}
+
+// PolicyDecisionDecision defines model for PolicyDecision.Decision.
+type PolicyDecisionDecision string
+
+// PolicyDecisionListResponse defines model for PolicyDecisionListResponse.
+type PolicyDecisionListResponse struct {
+	Decisions []PolicyDecision `json:"decisions"`
+
+	// Page Current page number
+	Page int `json:"page"`
+
+	// PageCount Total number of pages
+	PageCount int `json:"pageCount"`
+
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
+	Total int `json:"total"`
+}
+
+// PolicyEvaluation defines model for PolicyEvaluation.
+type PolicyEvaluation struct {
+	Concerns []Concern `json:"concerns"`
+
+	// VmId ID of the VM evaluated
+	VmId *string `json:"vmId,omitempty"`
+
+	// VmName Name of the VM evaluated
+	VmName *string `json:"vmName,omitempty"`
+}
+
+// PolicyEvaluationRequest Either vmId or vm must be set
+type PolicyEvaluationRequest struct {
+	// Vm VM document, as the policies receive it
+	Vm *map[string]interface{} `json:"vm,omitempty"`
+
+	// VmId ID of a VM of the inventory
+	VmId *string `json:"vmId,omitempty"`
+}
+
+// PolicyFile defines model for PolicyFile.
+type PolicyFile struct {
+	// Hash SHA-256 of the content, hex encoded
+	Hash string `json:"hash"`
+
+	// Name File name, relative to the policies folder
+	Name   string           `json:"name"`
+	Source PolicyFileSource `json:"source"`
+}
+
+// PolicyFileSource defines model for PolicyFile.Source.
+type PolicyFileSource string
+
+// PolicySet defines model for PolicySet.
+type PolicySet struct {
+	// Bundle Policy bundle of the console installed in the policies folder
+	Bundle *PolicyBundle `json:"bundle,omitempty"`
+
+	// Fingerprint Hash of all the policy files, changing with any of them
+	Fingerprint string `json:"fingerprint"`
+
+	// LoadError Error of the last reload, the previous policies being kept in use
+	LoadError *string `json:"loadError,omitempty"`
+
+	// LoadedAt When the policies in use were compiled
+	LoadedAt *time.Time   `json:"loadedAt,omitempty"`
+	Policies []PolicyFile `json:"policies"`
+}
+
+// PolicyTestReport defines model for PolicyTestReport.
+type PolicyTestReport struct {
+	// Failed Number of tests failed or in error
+	Failed  int                `json:"failed"`
+	Passed  int                `json:"passed"`
+	Results []PolicyTestResult `json:"results"`
+	Skipped int                `json:"skipped"`
+}
+
+// PolicyTestRequest defines model for PolicyTestRequest.
+type PolicyTestRequest struct {
+	// Policies Candidate custom policies and tests, rego by file name
+	Policies *map[string]string `json:"policies,omitempty"`
+}
+
+// PolicyTestResult defines model for PolicyTestResult.
+type PolicyTestResult struct {
+	DurationMs int64 `json:"durationMs"`
+
+	// Error Error of a test in error
+	Error *string `json:"error,omitempty"`
+
+	// File Test file, relative to the policies folder
+	File string `json:"file"`
+
+	// Name Name of the test rule
+	Name    string                 `json:"name"`
+	Package string                 `json:"package"`
+	Status  PolicyTestResultStatus `json:"status"`
+}
+
+// PolicyTestResultStatus defines model for PolicyTestResult.Status.
+type PolicyTestResultStatus string
+
+// Source A source of the console, collected from its own vCenter
+type Source struct {
+	Collector CollectorStatus `json:"collector"`
+
+	// ConsoleConnection Current console connection status of the source
+	ConsoleConnection SourceConsoleConnection `json:"console_connection"`
+	CreatedAt         *time.Time              `json:"createdAt,omitempty"`
+
+	// Error Console error of the source
+	Error *string `json:"error,omitempty"`
+
+	// Id Source UUID given by the console
+	Id   string `json:"id"`
+	Name string `json:"name"`
+
+	// Primary The source of the agent's source id, which cannot be removed
+	Primary bool `json:"primary"`
+}
+
+// SourceConsoleConnection Current console connection status of the source
+type SourceConsoleConnection string
+
+// SourceCreateRequest defines model for SourceCreateRequest.
+type SourceCreateRequest struct {
+	// Id Source UUID given by the console
+	Id   string  `json:"id"`
+	Name *string `json:"name,omitempty"`
+}
+
+// SourceList defines model for SourceList.
+type SourceList struct {
+	Sources []Source `json:"sources"`
+}
+
+// StatusUpdate defines model for StatusUpdate.
+type StatusUpdate struct {
+	Agent     AgentStatus     `json:"agent"`
+	Collector CollectorStatus `json:"collector"`
+}
+
+// ThroughputMeasure defines model for ThroughputMeasure.
+type ThroughputMeasure struct {
+	// Bytes Bytes transferred
+	Bytes int64 `json:"bytes"`
+
+	// DurationMs Duration of the transfer in milliseconds
+	DurationMs int64 `json:"durationMs"`
+
+	// Error Why the measure failed
+	Error *string `json:"error,omitempty"`
+
+	// Mbps Throughput in MB/s
+	Mbps float32 `json:"mbps"`
+
+	// Name Datastore or network target measured
+	Name string `json:"name"`
+}
+
+// VCenterEvent defines model for VCenterEvent.
+type VCenterEvent struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// HostId ID of the host the event relates to
+	HostId *string `json:"hostId,omitempty"`
+
+	// Kind Whether this is an event from the vCenter event log or a currently triggered alarm
+	Kind VCenterEventKind `json:"kind"`
+
+	// Message Message as formatted by vCenter
+	Message  *string              `json:"message,omitempty"`
+	Severity VCenterEventSeverity `json:"severity"`
+
+	// Type Event type (e.g. VmRestartedOnAlternateHostEvent) or alarm name
+	Type string `json:"type"`
+
+	// VmId ID of the VM the event relates to
+	VmId *string `json:"vmId,omitempty"`
+}
+
+// VCenterEventKind Whether this is an event from the vCenter event log or a currently triggered alarm
+type VCenterEventKind string
+
+// VCenterEventSeverity defines model for VCenterEvent.Severity.
+type VCenterEventSeverity string
+
 // VM defines model for VM.
 type VM struct {
 	// Cluster Cluster name
@@ -178,6 +1016,15 @@ type VMDetails struct {
 	// CpuCount Total number of virtual CPUs allocated to the VM
 	CpuCount int32 `json:"cpuCount"`
 
+	// CpuHotAddEnabled Whether virtual CPUs can be added while the VM is running
+	CpuHotAddEnabled *bool `json:"cpuHotAddEnabled,omitempty"`
+
+	// CpuHotRemoveEnabled Whether virtual CPUs can be removed while the VM is running
+	CpuHotRemoveEnabled *bool `json:"cpuHotRemoveEnabled,omitempty"`
+
+	// CpuSockets Number of virtual CPU sockets
+	CpuSockets *int32 `json:"cpuSockets,omitempty"`
+
 	// Datacenter Name of the datacenter containing the VM
 	Datacenter *string `json:"datacenter,omitempty"`
 
@@ -187,6 +1034,9 @@ type VMDetails struct {
 	// Disks List of virtual disks attached to the VM
 	Disks []VMDisk `json:"disks"`
 
+	// Encrypted Whether the VM is encrypted with vSphere VM encryption
+	Encrypted *bool `json:"encrypted,omitempty"`
+
 	// FaultToleranceEnabled Whether VMware Fault Tolerance is enabled, which maintains a live shadow VM for instant failover
 	FaultToleranceEnabled *bool `json:"faultToleranceEnabled,omitempty"`
 
@@ -205,6 +1055,9 @@ type VMDetails struct {
 	// GuestNetworks Network configuration inside the guest OS as reported by VMware Tools
 	GuestNetworks *[]GuestNetwork `json:"guestNetworks,omitempty"`
 
+	// HardwareVersion Virtual hardware version of the VM (e.g., vmx-19)
+	HardwareVersion *string `json:"hardwareVersion,omitempty"`
+
 	// Host Reference to the ESXi host where the VM is running
 	Host *string `json:"host,omitempty"`
 
@@ -224,6 +1077,9 @@ type VMDetails struct {
 	// Issues List of issue identifiers affecting this VM
 	Issues *[]string `json:"issues,omitempty"`
 
+	// MemoryHotAddEnabled Whether memory can be added while the VM is running
+	MemoryHotAddEnabled *bool `json:"memoryHotAddEnabled,omitempty"`
+
 	// MemoryMB Amount of memory allocated to the VM in megabytes
 	MemoryMB int32 `json:"memoryMB"`
 
@@ -236,9 +1092,15 @@ type VMDetails struct {
 	// Nics List of virtual network interface cards attached to the VM
 	Nics []VMNIC `json:"nics"`
 
+	// NumaNodeAffinity NUMA nodes the VM is pinned to, empty when unknown
+	NumaNodeAffinity *[]string `json:"numaNodeAffinity,omitempty"`
+
 	// PowerState Current power state of the VM (poweredOn, poweredOff, or suspended)
 	PowerState string `json:"powerState"`
 
+	// SecureBoot Whether UEFI secure boot is enabled for the VM
+	SecureBoot *bool `json:"secureBoot,omitempty"`
+
 	// StorageUsed Total storage space consumed by the VM in bytes
 	StorageUsed *int64 `json:"storageUsed,omitempty"`
 
@@ -248,6 +1110,9 @@ type VMDetails struct {
 	// ToolsStatus Installation status of VMware Tools (toolsNotInstalled, toolsNotRunning, toolsOld, toolsOk)
 	ToolsStatus *string `json:"toolsStatus,omitempty"`
 
+	// TpmEnabled Whether the VM has a virtual TPM device
+	TpmEnabled *bool `json:"tpmEnabled,omitempty"`
+
 	// Uuid Universally unique identifier assigned by vCenter
 	Uuid *string `json:"uuid,omitempty"`
 }
@@ -266,12 +1131,18 @@ type VMDisk struct {
 	// Capacity Disk capacity in bytes
 	Capacity *int64 `json:"capacity,omitempty"`
 
+	// ChainDepth Number of files in the disk backing chain, 1 for a disk without delta disks
+	ChainDepth *int `json:"chainDepth,omitempty"`
+
 	// File Path to the VMDK file in the datastore
 	File *string `json:"file,omitempty"`
 
 	// Key Unique key identifying this disk within the VM
 	Key *int32 `json:"key,omitempty"`
 
+	// LinkedClone Whether the disk is rooted on a base disk owned by another VM (linked clone)
+	LinkedClone *bool `json:"linkedClone,omitempty"`
+
 	// Mode Disk mode (e.g., persistent, independent_persistent, independent_nonpersistent)
 	Mode *string `json:"mode,omitempty"`
 
@@ -293,7 +1164,10 @@ type VMListResponse struct {
 	// PageCount Total number of pages
 	PageCount int `json:"pageCount"`
 
-	// Total Total number of VMs matching the filter
+	// PageSize Number of items per page
+	PageSize int `json:"pageSize"`
+
+	// Total Total number of items matching the filter
 	Total int  `json:"total"`
 	Vms   []VM `json:"vms"`
 }
@@ -343,6 +1217,132 @@ type VmInspectionStatus struct {
 // VmInspectionStatusState Current inspection state
 type VmInspectionStatusState string
 
+// Page defines model for Page.
+type Page = int
+
+// PageSize defines model for PageSize.
+type PageSize = int
+
+// ImportAssessmentParams defines parameters for ImportAssessment.
+type ImportAssessmentParams struct {
+	// Fingerprint Fingerprint of the key which must have signed the bundle, one of the trusted keys of the agent
+	Fingerprint *string `form:"fingerprint,omitempty" json:"fingerprint,omitempty"`
+}
+
+// GetDatastoresParams defines parameters for GetDatastores.
+type GetDatastoresParams struct {
+	// Cluster Only the datastores mounted by the hosts of this cluster
+	Cluster *string `form:"cluster,omitempty" json:"cluster,omitempty"`
+
+	// Page Page number for pagination
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// GetEventsParams defines parameters for GetEvents.
+type GetEventsParams struct {
+	// Type Filter by event types (OR logic)
+	Type *[]string `form:"type,omitempty" json:"type,omitempty"`
+
+	// Since Only return the events recorded at or after this time
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// Page Page number for pagination
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// GetHostsParams defines parameters for GetHosts.
+type GetHostsParams struct {
+	// Cluster Only the hosts of this cluster
+	Cluster *string `form:"cluster,omitempty" json:"cluster,omitempty"`
+
+	// Page Page number for pagination
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// ListJobsParams defines parameters for ListJobs.
+type ListJobsParams struct {
+	// Kind Filter by job kinds (OR logic)
+	Kind *[]string `form:"kind,omitempty" json:"kind,omitempty"`
+
+	// State Filter by job states (OR logic)
+	State *[]string `form:"state,omitempty" json:"state,omitempty"`
+
+	// Page Page number for pagination
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// GetNetworksParams defines parameters for GetNetworks.
+type GetNetworksParams struct {
+	// Page Page number for pagination
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
+// ExportPlanForkliftParams defines parameters for ExportPlanForklift.
+type ExportPlanForkliftParams struct {
+	// Namespace Namespace of the CRs, openshift-mtv by default
+	Namespace *string `form:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// SourceProvider Forklift Provider of the collected source, in the namespace of the CRs
+	SourceProvider *string `form:"sourceProvider,omitempty" json:"sourceProvider,omitempty"`
+
+	// DestinationProvider Forklift Provider of the target cluster, host by default
+	DestinationProvider *string `form:"destinationProvider,omitempty" json:"destinationProvider,omitempty"`
+
+	// TargetNamespace Namespace the VMs are migrated to
+	TargetNamespace *string `form:"targetNamespace,omitempty" json:"targetNamespace,omitempty"`
+
+	// StorageClass Storage class of the datastores not mapped by storage
+	StorageClass *string `form:"storageClass,omitempty" json:"storageClass,omitempty"`
+
+	// Network Destination of a source network, as <network ID>=pod, =ignored or =<namespace>/<name> of a NetworkAttachmentDefinition. The other networks go to the pod network
+	Network *[]string `form:"network,omitempty" json:"network,omitempty"`
+
+	// Storage Storage class of a datastore, as <datastore name>=<storage class>
+	Storage *[]string `form:"storage,omitempty" json:"storage,omitempty"`
+
+	// Warm Warm migrations, the disks being copied while the VMs run
+	Warm *bool `form:"warm,omitempty" json:"warm,omitempty"`
+}
+
+// PutCustomPolicyTextBody defines parameters for PutCustomPolicy.
+type PutCustomPolicyTextBody = string
+
+// GetPolicyDecisionsParams defines parameters for GetPolicyDecisions.
+type GetPolicyDecisionsParams struct {
+	// VmId Only the decisions of this VM
+	VmId *string `form:"vmId,omitempty" json:"vmId,omitempty"`
+
+	// Rule Only the decisions of this rule (concern ID)
+	Rule *string `form:"rule,omitempty" json:"rule,omitempty"`
+
+	// Decision Only the decisions of this type: flagged or passed
+	Decision *string `form:"decision,omitempty" json:"decision,omitempty"`
+
+	// Since Only return the decisions recorded at or after this time
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// Page Page number for pagination
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+}
+
 // GetVMsParams defines parameters for GetVMs.
 type GetVMsParams struct {
 	// MinIssues Filter VMs with at least this many issues
@@ -366,22 +1366,49 @@ type GetVMsParams struct {
 	// Status Filter by status (OR logic - matches VMs with any of the specified statuses)
 	Status *[]string `form:"status,omitempty" json:"status,omitempty"`
 
+	// Encrypted Filter by whether vSphere VM encryption is enabled
+	Encrypted *bool `form:"encrypted,omitempty" json:"encrypted,omitempty"`
+
+	// Severity Filter VMs with at least one concern of the severities (OR logic). The severity of a concern is the one of its policy metadata, else its category in lowercase.
+	Severity *[]string `form:"severity,omitempty" json:"severity,omitempty"`
+
 	// Sort Sort fields with direction (e.g., "name:asc" or "cluster:desc,name:asc"). Valid fields are name, vCenterState, cluster, diskSize, memory, issues.
 	Sort *[]string `form:"sort,omitempty" json:"sort,omitempty"`
 
 	// Page Page number for pagination
-	Page *int `form:"page,omitempty" json:"page,omitempty"`
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
 
-	// PageSize Number of items per page
-	PageSize *int `form:"pageSize,omitempty" json:"pageSize,omitempty"`
+	// PageSize Number of items per page, 20 by default and 100 at most
+	PageSize *PageSize `form:"pageSize,omitempty" json:"pageSize,omitempty"`
 }
 
 // SetAgentModeJSONRequestBody defines body for SetAgentMode for application/json ContentType.
 type SetAgentModeJSONRequestBody = AgentModeRequest
 
+// StartBenchmarkJSONRequestBody defines body for StartBenchmark for application/json ContentType.
+type StartBenchmarkJSONRequestBody = BenchmarkRequest
+
 // StartCollectorJSONRequestBody defines body for StartCollector for application/json ContentType.
 type StartCollectorJSONRequestBody = CollectorStartRequest
 
+// GeneratePlanJSONRequestBody defines body for GeneratePlan for application/json ContentType.
+type GeneratePlanJSONRequestBody = PlanRequest
+
+// PutCustomPolicyTextRequestBody defines body for PutCustomPolicy for text/plain ContentType.
+type PutCustomPolicyTextRequestBody = PutCustomPolicyTextBody
+
+// EvaluatePoliciesJSONRequestBody defines body for EvaluatePolicies for application/json ContentType.
+type EvaluatePoliciesJSONRequestBody = PolicyEvaluationRequest
+
+// RunPolicyTestsJSONRequestBody defines body for RunPolicyTests for application/json ContentType.
+type RunPolicyTestsJSONRequestBody = PolicyTestRequest
+
+// AddSourceJSONRequestBody defines body for AddSource for application/json ContentType.
+type AddSourceJSONRequestBody = SourceCreateRequest
+
+// StartSourceCollectorJSONRequestBody defines body for StartSourceCollector for application/json ContentType.
+type StartSourceCollectorJSONRequestBody = CollectorStartRequest
+
 // AddVMsToInspectionJSONRequestBody defines body for AddVMsToInspection for application/json ContentType.
 type AddVMsToInspectionJSONRequestBody = VMIdArray
 
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+)
+
+// NewAssessmentCommand returns the commands verifying the assessment bundles
+// exported by the agents of air-gapped environments and pushing them to the
+// console without a connected agent.
+func NewAssessmentCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "assessment",
+		Short: "Verify and push the assessment bundles exported by the agents",
+	}
+	cmd.AddCommand(newAssessmentVerifyCommand(), newAssessmentPushCommand())
+	return cmd
+}
+
+func newAssessmentVerifyCommand() *cobra.Command {
+	var fingerprint string
+	cmd := &cobra.Command{
+		Use:   "verify <bundle>",
+		Short: "Verify the signature and the content of an assessment bundle",
+		Args:  cobra.ExactArgs(1),
+		Example: `  # Verify a bundle was exported by the agent of a known key
+  agent assessment verify assessment.tar.gz --fingerprint 3f5c...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bundle, err := readAssessmentBundle(args[0], fingerprint)
+			if err != nil {
+				return err
+			}
+			return printAssessmentBundle(cmd.OutOrStdout(), bundle)
+		},
+	}
+	cmd.Flags().StringVar(&fingerprint, "fingerprint", "", "fingerprint of the key which must have signed the bundle")
+	_ = cmd.MarkFlagRequired("fingerprint")
+	return cmd
+}
+
+func newAssessmentPushCommand() *cobra.Command {
+	var fingerprint, consoleURL, jwtFile string
+	cmd := &cobra.Command{
+		Use:   "push <bundle>",
+		Short: "Verify an assessment bundle and send its inventory to the console",
+		Args:  cobra.ExactArgs(1),
+		Example: `  # Push a bundle carried out of an air-gapped environment
+  agent assessment push assessment.tar.gz --console-url https://console.redhat.com --jwt-file /path/to/jwt --fingerprint 3f5c...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bundle, err := readAssessmentBundle(args[0], fingerprint)
+			if err != nil {
+				return err
+			}
+
+			jwt, err := os.ReadFile(jwtFile)
+			if err != nil {
+				return fmt.Errorf("failed to read the jwt: %w", err)
+			}
+			client, err := console.NewConsoleClient(consoleURL, strings.TrimSpace(string(jwt)))
+			if err != nil {
+				return fmt.Errorf("failed to create console client: %w", err)
+			}
+			if err := services.SendAssessment(cmd.Context(), client, bundle); err != nil {
+				return fmt.Errorf("failed to push the assessment: %w", err)
+			}
+
+			return printAssessmentBundle(cmd.OutOrStdout(), bundle)
+		},
+	}
+	cmd.Flags().StringVar(&fingerprint, "fingerprint", "", "fingerprint of the key which must have signed the bundle")
+	cmd.Flags().StringVar(&consoleURL, "console-url", "", "URL of the console receiving the inventory")
+	cmd.Flags().StringVar(&jwtFile, "jwt-file", "", "path of the jwt authenticating to the console")
+	_ = cmd.MarkFlagRequired("fingerprint")
+	_ = cmd.MarkFlagRequired("console-url")
+	_ = cmd.MarkFlagRequired("jwt-file")
+	return cmd
+}
+
+// readAssessmentBundle reads and verifies the assessment bundle at path, signed
+// by the key of fingerprint.
+func readAssessmentBundle(path, fingerprint string) (*models.AssessmentBundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return services.ReadAssessmentBundle(f, []string{fingerprint})
+}
+
+// printAssessmentBundle writes the manifest of a verified bundle to w.
+func printAssessmentBundle(w io.Writer, bundle *models.AssessmentBundle) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "agent:\t%s\n", bundle.Manifest.AgentID)
+	fmt.Fprintf(tw, "source:\t%s\n", bundle.Manifest.SourceID)
+	fmt.Fprintf(tw, "agent version:\t%s\n", bundle.Manifest.AgentVersion)
+	fmt.Fprintf(tw, "collected at:\t%s\n", bundle.Manifest.CollectedAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "exported at:\t%s\n", bundle.Manifest.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "fingerprint:\t%s\n", bundle.Fingerprint)
+	return tw.Flush()
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// reloadConfiguration returns a copy of cfg with the configuration file and the
+// AMA_ environment variables loaded again. Reloadable settings given by a flag
+// keep their value, like at startup.
+func reloadConfiguration(flags *pflag.FlagSet, cfg *config.Configuration) (*config.Configuration, error) {
+	next := cfg.Clone()
+	if next.ConfigFile != "" {
+		if err := config.LoadFromFile(next.ConfigFile, next); err != nil {
+			return nil, err
+		}
+	}
+	if err := config.LoadFromEnv(next); err != nil {
+		return nil, err
+	}
+
+	if flags.Changed("log-level") {
+		next.LogLevel = cfg.LogLevel
+	}
+	if flags.Changed("console-update-interval") {
+		next.Agent.UpdateInterval = cfg.Agent.UpdateInterval
+	}
+	if flags.Changed("console-url") {
+		next.Console.URL = cfg.Console.URL
+	}
+
+	if err := validateConfiguration(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// remotePullTimeout bounds the pull of the remote configuration at startup.
+const remotePullTimeout = 10 * time.Second
+
+// applyRemoteConfiguration sets the remote configuration on cfg and validates
+// the result.
+func applyRemoteConfiguration(cfg *config.Configuration, remote config.Remote) error {
+	if err := config.ApplyRemote(cfg, remote); err != nil {
+		return err
+	}
+	if err := validateConfiguration(cfg); err != nil {
+		return fmt.Errorf("invalid remote configuration: %w", err)
+	}
+	return nil
+}
+
+// reloadTargets receive the reloadable settings of a running agent.
+type reloadTargets struct {
+	consoleSrv *services.Console
+	sourcesSrv *services.Sources
+	remoteSrv  *services.RemoteConfig   // nil without remote configuration
+	channelSrv *services.CommandChannel // nil without command channel
+	bundleSrv  *services.PolicyBundle
+	updateSrv  *services.Updater
+	assessSrv  *services.AssessmentService
+	features   *config.FeatureGate
+}
+
+// applyReloadable propagates the reloadable settings that changed to the
+// logger, the services and the feature gate. A running collection is not
+// affected.
+func applyReloadable(prev, next config.Reloadable, token *console.Token, proxy config.Proxy, targets reloadTargets) error {
+	if next.LogLevel != prev.LogLevel {
+		if err := logger.SetLevel(next.LogLevel); err != nil {
+			return fmt.Errorf("invalid log level %q: %w", next.LogLevel, err)
+		}
+	}
+
+	if !maps.Equal(next.LogLevels, prev.LogLevels) {
+		if err := logger.SetComponentLevels(next.LogLevels); err != nil {
+			return err
+		}
+	}
+
+	if next.ConsoleURL != prev.ConsoleURL {
+		client, err := console.NewConsoleClient(next.ConsoleURL, token.Get(), console.WithProxy(proxy.ProxyFunc(config.ProxyTargetConsole)))
+		if err != nil {
+			return fmt.Errorf("failed to create console client: %w", err)
+		}
+		client.WithToken(token)
+		targets.consoleSrv.SetClient(client)
+		targets.sourcesSrv.SetClient(client)
+		if targets.remoteSrv != nil {
+			targets.remoteSrv.SetClient(client)
+		}
+		if targets.channelSrv != nil {
+			targets.channelSrv.SetClient(client)
+		}
+		targets.bundleSrv.SetClient(client)
+		targets.updateSrv.SetClient(client)
+		targets.assessSrv.SetClient(client)
+	}
+
+	if next.UpdateInterval != prev.UpdateInterval {
+		targets.consoleSrv.SetUpdateInterval(next.UpdateInterval)
+		targets.sourcesSrv.SetUpdateInterval(next.UpdateInterval)
+	}
+
+	if !maps.Equal(next.Features, prev.Features) {
+		targets.features.Set(next.Features)
+	}
+
+	if next.PolicyBundleURL != prev.PolicyBundleURL {
+		targets.bundleSrv.SetURL(next.PolicyBundleURL)
+	}
+
+	if next.UpdateChannelURL != prev.UpdateChannelURL {
+		targets.updateSrv.SetURL(next.UpdateChannelURL)
+	}
+
+	zap.S().Infow("reloadable configuration applied",
+		"log_level", next.LogLevel,
+		"log_levels", next.LogLevels,
+		"update_interval", next.UpdateInterval,
+		"console_url", next.ConsoleURL,
+		"features", next.Features,
+		"policy_bundle_url", next.PolicyBundleURL,
+		"update_channel_url", next.UpdateChannelURL,
+	)
+	return nil
+}
+
+// rotateToken makes the console clients send the jwt read from the rotated
+// file at path, logging its expiry. A jwt already sent, such as the one of a
+// device login seen again in its file, is ignored.
+func rotateToken(token *console.Token, jwt, path string) {
+	if token.Get() == jwt {
+		return
+	}
+	token.Set(jwt)
+	if expiry, ok := token.Expiry(); ok {
+		zap.S().Infow("agent jwt rotated", "file", path, "expires_at", expiry)
+		return
+	}
+	zap.S().Infow("agent jwt rotated", "file", path)
+}
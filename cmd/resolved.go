@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+// markFlagSources records the flags set, on the command line or through their
+// AGENT_ environment variables, as the source of the keys they are bound to.
+func markFlagSources(flags *pflag.FlagSet, cfg *config.Configuration) {
+	flags.Visit(func(f *pflag.Flag) {
+		if addr, ok := flagAddr(f.Value); ok {
+			cfg.MarkSource(addr, config.SourceFlag)
+		}
+	})
+}
+
+// flagAddr returns the address of the variable bound to a flag. pflag values
+// are a pointer to it, or for lists a pointer to a struct whose first field
+// points to it.
+func flagAddr(value pflag.Value) (uintptr, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer {
+		return 0, false
+	}
+	if e := v.Elem(); e.Kind() == reflect.Struct {
+		if e.NumField() == 0 || e.Field(0).Kind() != reflect.Pointer {
+			return 0, false
+		}
+		return e.Field(0).Pointer(), true
+	}
+	return v.Pointer(), true
+}
+
+// printResolvedConfig writes the resolved configuration as a table of keys,
+// values and sources.
+func printResolvedConfig(w io.Writer, cfg *config.Configuration) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, v := range cfg.ResolvedConfig() {
+		value := fmt.Sprint(v.Value)
+		if list, ok := v.Value.([]string); ok {
+			value = strings.Join(list, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Key, value, v.Source)
+	}
+	return tw.Flush()
+}
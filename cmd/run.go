@@ -2,14 +2,19 @@ package cmd
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,11 +26,10 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/go-extras/cobraflags"
 
-	"github.com/kubev2v/migration-planner/pkg/opa"
-
 	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
@@ -33,12 +37,26 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/server"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
 	collectorv1 "github.com/kubev2v/assisted-migration-agent/pkg/collector"
 	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/keyring"
+	"github.com/kubev2v/assisted-migration-agent/pkg/lease"
+	"github.com/kubev2v/assisted-migration-agent/pkg/lifecycle"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/pkg/reporting"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+	"github.com/kubev2v/assisted-migration-agent/pkg/sso"
+	"github.com/kubev2v/assisted-migration-agent/pkg/update"
 )
 
+// recentLogEntries is the number of log entries kept for the support bundle.
+const recentLogEntries = 2000
+
 func NewRunCommand(cfg *config.Configuration) *cobra.Command {
+	var printConfig bool
 	runCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run agent",
@@ -52,21 +70,64 @@ func NewRunCommand(cfg *config.Configuration) *cobra.Command {
   # Run agent in production mode
   agent run --agent-id 550e8400-e29b-41d4-a716-446655440000 --source-id 6ba7b810-9dad-11d1-80b4-00c04fd430c8 --server-mode prod --server-statics-folder /var/www/statics`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfiguration(cmd.Flags(), cfg); err != nil {
+				return err
+			}
+			// printed before the validation to debug an invalid configuration
+			if printConfig {
+				if err := printResolvedConfig(cmd.OutOrStdout(), cfg); err != nil {
+					return err
+				}
+			}
 			return validateConfiguration(cfg)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if printConfig {
+				return nil
+			}
+
+			// the logger was built before the flags and the configuration file were read
+			if err := logger.SetLevel(cfg.LogLevel); err != nil {
+				return fmt.Errorf("invalid log level %q: %w", cfg.LogLevel, err)
+			}
+			if err := logger.SetComponentLevels(cfg.LogLevels); err != nil {
+				return err
+			}
+			appLogger := zap.L()
+			if cfg.LogFile != "" {
+				logFile, err := logger.NewRotatingFile(cfg.LogFile, int64(cfg.LogFileMaxSize), cfg.LogFileMaxAge, cfg.LogFileMaxBackups)
+				if err != nil {
+					return fmt.Errorf("failed to open log file: %w", err)
+				}
+				defer func() { _ = logFile.Close() }()
+				appLogger = logger.WithFile(appLogger, cfg.LogFormat, logFile)
+			}
+			// the last logs are kept in memory for the support bundle
+			recentLogs := logger.NewLogBuffer(recentLogEntries)
+			undoLogger := zap.ReplaceGlobals(logger.WithFile(appLogger, cfg.LogFormat, recentLogs))
+			defer undoLogger()
+
+			// the proxy URLs may carry a password
+			proxy := cfg.Proxy.Redacted()
 			zap.S().Infow("using configuration",
 				"agent", helpers.Flatten(cfg.Agent.DebugMap()),
 				"server", helpers.Flatten(cfg.Server.DebugMap()),
 				"console", helpers.Flatten(cfg.Console.DebugMap()),
 				"auth", helpers.Flatten(cfg.Auth.DebugMap()),
+				"tracing", helpers.Flatten(cfg.Tracing.DebugMap()),
+				"proxy", helpers.Flatten(proxy.DebugMap()),
 			)
 
-			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGQUIT)
+			shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Agent.Version)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 			wg := sync.WaitGroup{}
 			wg.Add(1)
 
-			store, err := initStore(cfg)
+			store, policies, err := initStore(cfg)
 			if err != nil {
 				return err
 			}
@@ -76,60 +137,281 @@ func NewRunCommand(cfg *config.Configuration) *cobra.Command {
 				return err
 			}
 			zap.S().Info("database initialized successfully")
+			if cfg.Agent.QueryExplainThreshold > 0 {
+				store.ExplainSlowQueries(cfg.Agent.QueryExplainThreshold)
+				zap.S().Warnw("explaining slow database queries, they run twice", "threshold", cfg.Agent.QueryExplainThreshold)
+			}
+
+			// the panics, fatal console errors and failed collections are dropped unless a webhook receives them
+			var (
+				errorReporter models.ErrorReporter = models.NopErrorReporter{}
+				errorWebhook  *reporting.Webhook
+			)
+			if cfg.Agent.ErrorWebhookURL != "" {
+				errorWebhook = reporting.NewWebhook(cfg.Agent.ErrorWebhookURL, cfg.Agent, reporting.WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetConsole)))
+				errorReporter = errorWebhook
+			}
 
 			// init scheduler
-			sched := scheduler.NewScheduler(cfg.Agent.NumWorkers)
+			sched := scheduler.NewScheduler(cfg.Agent.NumWorkers, scheduler.WithPanicHandler(func(recovered any, stack []byte) {
+				errorReporter.Report(context.Background(), models.ErrorReport{
+					Kind:    models.ErrorReportPanic,
+					Time:    time.Now().UTC(),
+					Message: fmt.Sprintf("worker panicked: %v", recovered),
+					Details: map[string]string{"stack": string(stack)},
+				})
+			}))
 
-			// read jwt token for agent
+			// the jwt was resolved while loading the configuration, we assume it is valid at this point
 			jwt := ""
 			if cfg.Auth.Enabled {
-				data, err := os.ReadFile(cfg.Auth.JWTFilePath)
-				if err != nil {
-					return fmt.Errorf("failed to read agent's jwt: %w", err)
-				}
-				if len(data) == 0 {
-					return errors.New("failed to read agent's jwt. the JWT is empty")
-				}
-				jwt = strings.TrimSpace(string(data)) // we assume the jwt is valid at this point
+				jwt = cfg.Auth.JWT
 			}
+			// shared by the console clients, swapped when the jwt file is rotated
+			token := console.NewToken(jwt)
 
 			// init console client
-			consoleClient, err := console.NewConsoleClient(cfg.Console.URL, jwt)
+			consoleClient, err := console.NewConsoleClient(cfg.Console.URL, jwt, console.WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetConsole)))
 			if err != nil {
 				return fmt.Errorf("failed to create console client: %w", err)
 			}
+			consoleClient.WithToken(token)
+
+			// an agent deployed without ids registers once, the next starts reading them from the store
+			if cfg.Agent.ID == "" {
+				registration, err := services.NewRegistrationService(consoleClient, store, cfg.Auth.ProvisioningToken, cfg.Agent.Version).Register(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to register the agent: %w", err)
+				}
+				cfg.Agent.ID = registration.AgentID
+				cfg.Agent.SourceID = registration.SourceID
+			}
+
+			// pull the remote configuration before the services read cfg. local, the
+			// configuration without it, is the base of the reloads
+			local := cfg.Clone()
+			var (
+				remoteSrv *services.RemoteConfig
+				watcher   *config.Watcher
+			)
+			if cfg.Console.RemoteConfigEnabled {
+				remoteSrv = services.NewRemoteConfigService(consoleClient, uuid.MustParse(cfg.Agent.ID), cfg.Console.RemoteConfigInterval,
+					func(remote config.Remote) error {
+						if watcher != nil {
+							return watcher.Reload()
+						}
+						// first pull, before the services are created
+						next := cfg.Clone()
+						if err := applyRemoteConfiguration(next, remote); err != nil {
+							return err
+						}
+						*cfg = *next
+						return nil
+					},
+				)
+				pullCtx, cancelPull := context.WithTimeout(ctx, remotePullTimeout)
+				err := remoteSrv.Fetch(pullCtx)
+				cancelPull()
+				if err != nil {
+					zap.S().Warnw("starting without the remote configuration", "error", err)
+				}
+			}
+			features := config.NewFeatureGate(cfg.Features)
 
 			// create collector service
-			workBuilder := collectorv1.NewWorkBuilder(store, cfg.Agent.DataFolder, cfg.Agent.OpaPoliciesFolder)
-			collectorSrv := services.NewCollectorService(sched, store, workBuilder)
+			workBuilder := collectorv1.NewWorkBuilder(store, cfg.Agent.DataFolder, cfg.Agent.OpaPoliciesFolder).
+				WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetVCenter))
+			// the vcenter credentials only leave the memory to resume a collection after a restart
+			var credsSrv *services.CredentialsService
+			if cfg.Agent.ResumeCollection {
+				secrets, err := initSecrets(cfg, store)
+				if err != nil {
+					return fmt.Errorf("failed to initialize the secret store: %w", err)
+				}
+				credsSrv = services.NewCredentialsService(secrets)
+			}
+			eventSrv := services.NewEventService(store)
+			errorReportingSrv := services.NewErrorReportingService(eventSrv, errorReporter)
+			// the collections, inspections and benchmarks are followed as jobs, those of the previous run ended with it
+			jobSrv := services.NewJobService(store)
+			if err := jobSrv.Recover(ctx); err != nil {
+				return fmt.Errorf("failed to recover the jobs: %w", err)
+			}
+			collectorSrv := services.NewCollectorService(sched, store, workBuilder).
+				WithCredentialsService(credsSrv).
+				WithEventService(eventSrv).
+				WithJobService(jobSrv)
 
 			// create inspector service
-			inspectorSrv := services.NewInspectorService(sched, store).WithBuilder(models.UnimplementedInspectorWorkBuilder{})
+			inspectorSrv := services.NewInspectorService(sched, store).
+				WithBuilder(models.UnimplementedInspectorWorkBuilder{}).
+				WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetVCenter)).
+				WithEventService(eventSrv).
+				WithJobService(jobSrv)
+			jobSrv.WithCanceler(models.JobKindCollection, func(context.Context) error {
+				collectorSrv.Stop()
+				return nil
+			}).WithCanceler(models.JobKindInspection, inspectorSrv.Stop)
+
+			benchmarkSrv := services.NewBenchmarkService(store).
+				WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetVCenter)).
+				WithJobService(jobSrv)
+			jobSrv.WithCanceler(models.JobKindBenchmark, benchmarkSrv.Stop)
+
+			policySrv := services.NewPolicyService(store, policies, collectorSrv)
+			decisionSrv := services.NewPolicyDecisionService(store)
+			policies.OnDecision(decisionSrv.Record)
+			// the bundle of the console replaces the policies of the folder
+			bundleSrv := services.NewPolicyBundleService(consoleClient, cfg.Agent.OpaPoliciesFolder, cfg.Agent.PolicyBundleURL,
+				cfg.Console.RemoteConfigInterval, policySrv.Reload)
+			// an update of the console is installed over the executable, then the agent stops to restart into it
+			executable, err := agentExecutable()
+			if err != nil {
+				return err
+			}
+			var updateKey ed25519.PublicKey
+			if cfg.Agent.UpdatePublicKey != "" {
+				if updateKey, err = update.ParsePublicKey(cfg.Agent.UpdatePublicKey); err != nil {
+					return err
+				}
+			}
+			var updated atomic.Bool
+			updateSrv := services.NewUpdateService(consoleClient, cfg.Agent.UpdateChannelURL, updateKey, executable, cfg.Agent.Version,
+				cfg.Console.RemoteConfigInterval, func() {
+					updated.Store(true)
+					cancel()
+				})
 
 			consoleSrv, err := services.NewConsoleService(cfg.Agent, sched, consoleClient, collectorSrv, store)
 			if err != nil {
 				return fmt.Errorf("failed to create console service: %w", err)
 			}
+			consoleSrv.WithEventService(eventSrv)
+			// in a high availability pair only the leader collects and dispatches, the standby serves its store read-only
+			election, err := newLeaderElection(cfg)
+			if err != nil {
+				return err
+			}
+			if election != nil {
+				election.WithEventService(eventSrv)
+			}
+			consoleSrv.WithLeaderElection(election)
 			inventorySrv := services.NewInventoryService(store)
 			vmSrv := services.NewVMService(store)
+			clusterSrv := services.NewClusterService(store)
+			datastoreSrv := services.NewDatastoreService(store)
+			infraSrv := services.NewInfrastructureService(store)
+			adminSrv := services.NewAdminService(store).WithScheduler(sched)
+			apiKeySrv := services.NewAPIKeyService(store)
+			auditSrv := services.NewAuditService(store)
+			// the sources added through the api are collected into databases of their own
+			sourcesSrv := services.NewSourcesService(store, models.Source{ID: cfg.Agent.SourceID, Name: "primary"},
+				&services.SourceServices{Store: store, Collector: collectorSrv, Console: consoleSrv, Inventory: inventorySrv},
+				newSourceFactory(cfg, policies, sched, consoleClient, consoleSrv, election))
+			if err := sourcesSrv.Start(ctx); err != nil {
+				return fmt.Errorf("failed to start the sources: %w", err)
+			}
+			var channelSrv *services.CommandChannel
+			if cfg.Console.CommandChannelEnabled {
+				channelSrv = newCommandChannel(cfg, consoleClient, consoleSrv, collectorSrv, sourcesSrv, jobSrv, remoteSrv)
+			}
+			graphSrv, err := services.NewInventoryGraphService(store)
+			if err != nil {
+				return fmt.Errorf("failed to load the graphql schema: %w", err)
+			}
+			assessmentKey, err := initAssessmentKey(cfg)
+			if err != nil {
+				return err
+			}
+			assessmentSrv := services.NewAssessmentService(store, assessmentKey, cfg.Agent.ID, cfg.Agent.SourceID, cfg.Agent.Version,
+				consoleSrv, consoleClient).
+				WithTrustedKeys(cfg.Agent.AssessmentTrustedKeys).
+				WithJobService(jobSrv)
+			supportSrv := services.NewSupportBundleService(*cfg, store, sched, consoleSrv, collectorSrv, inspectorSrv).
+				WithLogs(recentLogs).
+				WithJobService(jobSrv)
 
 			// init handlers
-			h := handlers.New(*cfg, consoleSrv, collectorSrv, inventorySrv, vmSrv, inspectorSrv)
+			h := handlers.New(*cfg, consoleSrv, collectorSrv, inventorySrv, vmSrv, inspectorSrv).
+				WithFeatureGate(features).
+				WithClusterService(clusterSrv).
+				WithDatastoreService(datastoreSrv).
+				WithInfrastructureService(infraSrv).
+				WithAdminService(adminSrv).
+				WithAPIKeyService(apiKeySrv).
+				WithAuditService(auditSrv).
+				WithEventService(eventSrv).
+				WithSupportBundleService(supportSrv).
+				WithPolicyService(policySrv).
+				WithPolicyDecisionService(decisionSrv).
+				WithUpdateService(updateSrv).
+				WithSourcesService(sourcesSrv).
+				WithJobService(jobSrv).
+				WithInventoryGraphService(graphSrv).
+				WithAssessmentService(assessmentSrv).
+				WithPlanService(services.NewPlanService(store)).
+				WithBenchmarkService(benchmarkSrv)
+
+			// the jwt of a device login is written to the jwt file and sent right away
+			var loginSrv *services.DeviceLogin
+			if cfg.Auth.DeviceLoginEnabled {
+				ssoClient := sso.NewClient(cfg.Auth.SSOURL, cfg.Auth.SSOClientID, sso.WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetConsole)))
+				loginSrv = services.NewDeviceLoginService(ssoClient, cfg.Auth.JWTFilePath, func(jwt string) {
+					rotateToken(token, jwt, cfg.Auth.JWTFilePath)
+				})
+				h.WithConsoleLoginService(loginSrv)
+			}
 
 			srv, err := server.NewServer(cfg, func(router *gin.RouterGroup) {
-				v1.RegisterHandlers(router, h)
+				v1.RegisterHandlersWithOptions(router, h, v1.GinServerOptions{ErrorHandler: handlers.ParamErrorHandler})
 			})
 			if err != nil {
 				zap.S().Errorw("failed to create http server", "error", err)
 				return err
 			}
+			srv.WithAPIKeys(apiKeySrv).
+				WithAuditLog(auditSrv.Record).
+				WithErrorReporter(errorReporter)
+			if cfg.Auth.MaxLoginFailures > 0 {
+				srv.WithLoginThrottle(services.NewLoginThrottleService(store, cfg.Auth.MaxLoginFailures, cfg.Auth.LoginLockout))
+			}
+			if election != nil {
+				h.WithHAService(election)
+				srv.WithStandby(func() (bool, string) {
+					status := election.Status()
+					return status.Role == models.HARoleStandby, status.Leader
+				})
+				// the command channel follows the terms, the leader stops its work as it steps down
+				if channelSrv != nil {
+					election.OnElected(channelSrv.Run)
+				}
+				election.OnDemoted(func() {
+					stopLeaderWork(sourcesSrv, inspectorSrv, benchmarkSrv)
+				})
+			}
+			if credsSrv != nil {
+				h.WithCredentialsService(credsSrv)
+			}
+			h.RegisterAdminRoutes(srv.AdminRouter())
+			srv.MountGraphQL(h.GraphQL)
 
 			go func() {
 				defer func() {
 					wg.Done()
 					cancel()
 				}()
-				zap.S().Infof("Starting HTTP server on port %d", cfg.Server.HTTPPort)
+				if cfg.Server.UnixSocketPath != "" {
+					zap.S().Infof("Starting HTTP server on unix socket %s", cfg.Server.UnixSocketPath)
+				}
+				if !cfg.Server.UnixSocketOnly {
+					zap.S().Infof("Starting HTTP server on port %d", cfg.Server.HTTPPort)
+				}
+				if cfg.Server.AdminPort != 0 {
+					zap.S().Infof("Starting admin HTTP server on port %d", cfg.Server.AdminPort)
+				}
+				if cfg.Server.AdminUnixSocketPath != "" {
+					zap.S().Infof("Starting admin HTTP server on unix socket %s", cfg.Server.AdminUnixSocketPath)
+				}
 
 				if err := srv.Start(ctx); err != nil {
 					if !errors.Is(err, http.ErrServerClosed) {
@@ -138,39 +420,170 @@ func NewRunCommand(cfg *config.Configuration) *cobra.Command {
 				}
 			}()
 
-			go func() {
-				<-ctx.Done()
-				stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-				defer cancel()
-				srv.Stop(stopCtx)
-			}()
-
-			<-ctx.Done()
-			wg.Wait()
+			watcher = config.NewWatcher(cfg.ConfigFile, cfg.Reloadable(),
+				func() (*config.Configuration, error) {
+					next, err := reloadConfiguration(cmd.Flags(), local)
+					if err != nil || remoteSrv == nil {
+						return next, err
+					}
+					if remote, ok := remoteSrv.Current(); ok {
+						if err := applyRemoteConfiguration(next, remote); err != nil {
+							return nil, err
+						}
+					}
+					return next, nil
+				},
+				func(prev, next config.Reloadable) error {
+					return applyReloadable(prev, next, token, cfg.Proxy, reloadTargets{
+						consoleSrv: consoleSrv,
+						sourcesSrv: sourcesSrv,
+						remoteSrv:  remoteSrv,
+						channelSrv: channelSrv,
+						bundleSrv:  bundleSrv,
+						updateSrv:  updateSrv,
+						assessSrv:  assessmentSrv,
+						features:   features,
+					})
+				},
+			)
+			// systemd restarts a hung agent: the watchdog pings it while the database and the server answer
+			watchdog := services.NewWatchdogService(
+				services.HealthCheck{Name: "database", Check: store.Ping},
+				services.HealthCheck{Name: "server", Check: srv.Ping},
+			)
+			// the background services stop with ctx, the shutdown waits for them
+			lc := lifecycle.NewManager(cfg.Agent.ShutdownTimeout)
+			lc.Go(func() { watchdog.Run(ctx) })
+			lc.Go(func() { watcher.Run(ctx) })
+			lc.Go(func() { policySrv.Run(ctx) })
+			lc.Go(func() { decisionSrv.Run(ctx) })
+			lc.Go(func() { bundleSrv.Run(ctx) })
+			lc.Go(func() { updateSrv.Run(ctx) })
+			lc.Go(func() { errorReportingSrv.Run(ctx) })
+			if remoteSrv != nil {
+				lc.Go(func() { remoteSrv.Run(ctx) })
+			}
+			if channelSrv != nil && election == nil {
+				lc.Go(func() { channelSrv.Run(ctx) })
+			}
+			if election != nil {
+				lc.Go(func() { election.Run(ctx) })
+			}
+			// the collection left unfinished by the previous run, on the leader of a pair
+			if election != nil {
+				election.OnElected(collectorSrv.Resume)
+			} else {
+				collectorSrv.Resume(ctx)
+			}
+			if cfg.JWTFromFile() {
+				lc.Go(func() {
+					config.WatchSecretFile(ctx, cfg.Auth.JWTFilePath, jwt, func(next string) {
+						rotateToken(token, next, cfg.Auth.JWTFilePath)
+					})
+				})
+			}
 
-			zap.S().Info("server shutdown")
+			// reload the serving certificate, the reloadable settings and the policies on SIGHUP
+			hupCh := make(chan os.Signal, 1)
+			signal.Notify(hupCh, syscall.SIGHUP)
+			lc.Go(func() {
+				defer signal.Stop(hupCh)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-hupCh:
+						if err := watcher.Reload(); err != nil {
+							zap.S().Errorw("failed to reload configuration", "error", err)
+						}
+						if err := policySrv.Reload(ctx); err != nil {
+							zap.S().Errorw("failed to reload policies", "error", err)
+						}
+						if err := srv.ReloadCertificates(); err != nil {
+							zap.S().Errorw("failed to reload server certificates", "error", err)
+							continue
+						}
+						zap.S().Info("server certificates reloaded")
+					}
+				}
+			})
 
-			consoleSrv.Stop()
-			collectorSrv.Stop()
-			_ = inspectorSrv.Stop(context.Background())
-			sched.Close()
-			store.Close()
+			// each stage stops what the next ones need to stop cleanly: no request
+			// comes in once the server is stopped, no work is scheduled once the
+			// console loop, the collection and the inspection are, and the services
+			// flush to the database before it is checkpointed and closed
+			lc.Add("http server", func(ctx context.Context) error {
+				srv.Stop(ctx)
+				wg.Wait()
+				return nil
+			}).Add("console", func(context.Context) error {
+				consoleSrv.Stop()
+				return nil
+			}).Add("collection", func(context.Context) error {
+				collectorSrv.Stop()
+				return nil
+			}).Add("inspection", func(ctx context.Context) error {
+				if err := inspectorSrv.Stop(ctx); err != nil && !srvErrors.IsInspectorNotRunningError(err) {
+					return err
+				}
+				return nil
+			})
+			if loginSrv != nil {
+				lc.Add("device login", func(context.Context) error {
+					loginSrv.Stop()
+					return nil
+				})
+			}
+			lc.Add("sources", func(context.Context) error {
+				return sourcesSrv.Stop()
+			})
+			lc.Add("background services", lc.Wait).
+				Add("scheduler", func(context.Context) error {
+					sched.Close()
+					return nil
+				}).
+				Add("database", func(context.Context) error {
+					if err := store.Checkpoint(); err != nil {
+						zap.S().Warnw("failed to checkpoint the database", "error", err)
+					}
+					return store.Close()
+				})
+			if errorWebhook != nil {
+				lc.Add("error webhook", func(context.Context) error {
+					errorWebhook.Close()
+					return nil
+				})
+			}
+			// flush the spans of the shutdown
+			lc.Add("tracing", shutdownTracing)
 
-			zap.S().Info("services and scheduler closed")
+			<-ctx.Done()
+			zap.S().Infow("shutting down", "timeout", cfg.Agent.ShutdownTimeout)
+			if err := lc.Shutdown(context.Background()); err != nil {
+				zap.S().Errorw("shutdown incomplete", "error", err)
+			}
 
+			if updated.Load() {
+				return restartIntoUpdate(executable, config.UpdateRestartType(cfg.Agent.UpdateRestart))
+			}
 			return nil
 		},
 	}
 
-	registerFlags(runCmd, cfg)
+	registerFlags(runCmd, cfg, &printConfig)
 	cobraflags.CobraOnInitialize("AGENT", runCmd)
 
 	return runCmd
 }
 
-func registerFlags(cmd *cobra.Command, config *config.Configuration) {
+func registerFlags(cmd *cobra.Command, config *config.Configuration, printConfig *bool) {
 	nfs := cobrautil.NewNamedFlagSets(cmd)
 
+	generalFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("General"))
+	generalFlagSet.StringVar(&config.ConfigFile, "config-file", config.ConfigFile, "YAML or TOML (.toml) configuration file. Flags and environment variables take precedence over its values")
+	generalFlagSet.StringVar(&config.Profile, "profile", config.Profile, "Defaults of a deployment: dev (HTTP, no authentication, debug logs) or prod (HTTPS, authentication, info logs). Other settings override them")
+	generalFlagSet.BoolVar(printConfig, "print-config", false, "Print the resolved configuration with the source of each value, then exit")
+
 	serverFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Server"))
 	registerServerFlags(serverFlagSet, config)
 
@@ -183,16 +596,76 @@ func registerFlags(cmd *cobra.Command, config *config.Configuration) {
 	consoleFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Console"))
 	registerConsoleFlags(consoleFlagSet, config)
 
+	tracingFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Tracing"))
+	registerTracingFlags(tracingFlagSet, config)
+
 	nfs.AddFlagSets(cmd)
 }
 
-func validateConfiguration(cfg *config.Configuration) error {
-	if err := validateUUID(cfg.Agent.ID, "agent-id"); err != nil {
+// loadConfiguration loads cfg.ConfigFile, when set, and then the AMA_ environment
+// variables onto cfg. Flags set on the command line or through their AGENT_
+// environment variables keep precedence over both. The selected profile fills
+// the settings none of them set.
+func loadConfiguration(flags *pflag.FlagSet, cfg *config.Configuration) error {
+	var restore []func() error
+	flags.Visit(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			v := sv.GetSlice()
+			restore = append(restore, func() error { return sv.Replace(v) })
+			return
+		}
+		v := f.Value.String()
+		restore = append(restore, func() error { return f.Value.Set(v) })
+	})
+
+	if cfg.ConfigFile != "" {
+		if err := config.LoadFromFile(cfg.ConfigFile, cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := config.LoadFromEnv(cfg); err != nil {
 		return err
 	}
-	if err := validateUUID(cfg.Agent.SourceID, "source-id"); err != nil {
+
+	for _, r := range restore {
+		if err := r(); err != nil {
+			return err
+		}
+	}
+	markFlagSources(flags, cfg)
+
+	if err := config.ApplyProfile(cfg); err != nil {
 		return err
 	}
+	return config.ResolveSecrets(cfg)
+}
+
+// Ranges of the durations and sizes accepted by validateConfiguration.
+const (
+	minUpdateInterval  = time.Second
+	maxUpdateInterval  = 24 * time.Hour
+	minServerTimeout   = time.Second
+	maxServerTimeout   = 24 * time.Hour
+	minRequestBodySize = config.KiB
+	maxRequestBodySize = 4 * config.GiB
+	minRemoteInterval  = time.Minute
+	maxRemoteInterval  = 24 * time.Hour
+	// minLocalTokenLength keeps the local API token hard to guess
+	minLocalTokenLength = 16
+)
+
+func validateConfiguration(cfg *config.Configuration) error {
+	// without ids, the agent registers with the provisioning token to get them
+	registering := cfg.Agent.ID == "" && cfg.Agent.SourceID == "" && cfg.Auth.ProvisioningToken != ""
+	if !registering {
+		if err := validateUUID(cfg.Agent.ID, "agent-id"); err != nil {
+			return err
+		}
+		if err := validateUUID(cfg.Agent.SourceID, "source-id"); err != nil {
+			return err
+		}
+	}
 
 	switch models.AgentMode(cfg.Agent.Mode) {
 	case models.AgentModeConnected, models.AgentModeDisconnected:
@@ -200,6 +673,22 @@ func validateConfiguration(cfg *config.Configuration) error {
 		return fmt.Errorf("invalid mode %q: must be %q or %q", cfg.Agent.Mode, models.AgentModeConnected, models.AgentModeDisconnected)
 	}
 
+	switch config.SecretBackendType(cfg.Agent.SecretBackend) {
+	case config.SecretBackendDatabase:
+	case config.SecretBackendFile:
+		if cfg.Agent.KeyringFolder == "" {
+			return errors.New("keyring-folder must be set when secret-backend is file")
+		}
+	default:
+		return fmt.Errorf("invalid secret backend %q: must be %q or %q", cfg.Agent.SecretBackend, config.SecretBackendDatabase, config.SecretBackendFile)
+	}
+
+	switch config.UpdateRestartType(cfg.Agent.UpdateRestart) {
+	case config.UpdateRestartExec, config.UpdateRestartExit:
+	default:
+		return fmt.Errorf("invalid update-restart %q: must be %q or %q", cfg.Agent.UpdateRestart, config.UpdateRestartExec, config.UpdateRestartExit)
+	}
+
 	switch config.ServerModeType(cfg.Server.ServerMode) {
 	case config.ServerModeProd, config.ServerModeDev:
 	default:
@@ -210,22 +699,241 @@ func validateConfiguration(cfg *config.Configuration) error {
 		return errors.New("statics folder must be set when server mode is production")
 	}
 
+	if (cfg.Server.TLSCertFile == "") != (cfg.Server.TLSKeyFile == "") {
+		return errors.New("server-tls-cert-file and server-tls-key-file must be set together")
+	}
+
+	if cfg.Server.UnixSocketOnly {
+		if cfg.Server.UnixSocketPath == "" {
+			return errors.New("server-unix-socket-path must be set when server-unix-socket-only is enabled")
+		}
+		if len(cfg.Server.ACMEDomains) > 0 {
+			return errors.New("server-acme-domains cannot be used with server-unix-socket-only")
+		}
+	}
+
+	if cfg.Server.ClientCAFile != "" && config.ServerModeType(cfg.Server.ServerMode) != config.ServerModeProd {
+		return errors.New("server-client-ca-file requires server mode prod")
+	}
+
+	if len(cfg.Server.ACMEDomains) > 0 {
+		if cfg.Server.TLSCertFile != "" {
+			return errors.New("server-acme-domains cannot be used together with server-tls-cert-file")
+		}
+		if config.ServerModeType(cfg.Server.ServerMode) != config.ServerModeProd {
+			return errors.New("server-acme-domains requires server mode prod")
+		}
+		if cfg.Server.ACMEHTTPPort < 1 || cfg.Server.ACMEHTTPPort > 65535 {
+			return fmt.Errorf("invalid acme-http-port %d: must be between 1 and 65535", cfg.Server.ACMEHTTPPort)
+		}
+	}
+
+	if cfg.Server.RateLimitRPS < 0 {
+		return fmt.Errorf("invalid rate-limit-rps %v: must not be negative", cfg.Server.RateLimitRPS)
+	}
+
+	if cfg.Server.RateLimitRPS > 0 && cfg.Server.RateLimitBurst < 1 {
+		return fmt.Errorf("invalid rate-limit-burst %d: must be at least 1", cfg.Server.RateLimitBurst)
+	}
+
+	if cfg.Server.CompressionMinSize < 0 {
+		return fmt.Errorf("invalid compression-min-size %d: must not be negative", cfg.Server.CompressionMinSize)
+	}
+
+	if cfg.Server.ReadHeaderTimeout < 0 || cfg.Server.ReadTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 {
+		return errors.New("server timeouts must not be negative")
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server-read-header-timeout", cfg.Server.ReadHeaderTimeout},
+		{"server-read-timeout", cfg.Server.ReadTimeout},
+		{"server-write-timeout", cfg.Server.WriteTimeout},
+		{"server-idle-timeout", cfg.Server.IdleTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value != 0 && (t.value < minServerTimeout || t.value > maxServerTimeout) {
+			return fmt.Errorf("invalid %s %s: must be 0 or between %s and %s", t.name, t.value, minServerTimeout, maxServerTimeout)
+		}
+	}
+
+	if cfg.Server.MaxRequestBodySize < 0 {
+		return fmt.Errorf("invalid max-request-body-size %d: must not be negative", cfg.Server.MaxRequestBodySize)
+	}
+	if cfg.Server.MaxRequestBodySize != 0 && (cfg.Server.MaxRequestBodySize < minRequestBodySize || cfg.Server.MaxRequestBodySize > maxRequestBodySize) {
+		return fmt.Errorf("invalid max-request-body-size %s: must be 0 or between %s and %s", cfg.Server.MaxRequestBodySize, minRequestBodySize, maxRequestBodySize)
+	}
+
+	if cfg.Agent.QueryExplainThreshold < 0 {
+		return fmt.Errorf("invalid query-explain-threshold %s: must not be negative", cfg.Agent.QueryExplainThreshold)
+	}
+
+	if cfg.Agent.ErrorWebhookURL != "" {
+		u, err := url.Parse(cfg.Agent.ErrorWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("invalid error-webhook-url: must be an http or https URL")
+		}
+	}
+
+	if cfg.LogFileMaxSize < 0 || cfg.LogFileMaxAge < 0 || cfg.LogFileMaxBackups < 0 {
+		return errors.New("log file rotation settings must not be negative")
+	}
+
+	if cfg.Server.AccessLogMaxSize < 0 || cfg.Server.AccessLogMaxAge < 0 || cfg.Server.AccessLogMaxBackups < 0 {
+		return errors.New("server access log rotation settings must not be negative")
+	}
+
 	if cfg.Server.HTTPPort < 1 || cfg.Server.HTTPPort > 65535 {
 		return fmt.Errorf("invalid http-port %d: must be between 1 and 65535", cfg.Server.HTTPPort)
 	}
 
+	if cfg.Server.AdminPort != 0 {
+		if cfg.Server.AdminPort < 1 || cfg.Server.AdminPort > 65535 {
+			return fmt.Errorf("invalid admin-port %d: must be between 1 and 65535", cfg.Server.AdminPort)
+		}
+		if cfg.Server.AdminPort == cfg.Server.HTTPPort {
+			return errors.New("server-admin-port must differ from server-http-port")
+		}
+	}
+
+	if cfg.Server.AdminUnixSocketPath != "" && cfg.Server.AdminUnixSocketPath == cfg.Server.UnixSocketPath {
+		return errors.New("server-admin-unix-socket-path must differ from server-unix-socket-path")
+	}
+
+	proxies := []struct {
+		name, value string
+		allowDirect bool
+	}{
+		{"proxy.httpProxy", cfg.Proxy.HTTPProxy, false},
+		{"proxy.httpsProxy", cfg.Proxy.HTTPSProxy, false},
+		{"proxy.consoleProxy", cfg.Proxy.ConsoleProxy, true},
+		{"proxy.vcenterProxy", cfg.Proxy.VCenterProxy, true},
+	}
+	for _, p := range proxies {
+		if p.allowDirect && p.value == config.ProxyDirect {
+			continue
+		}
+		if err := validateProxyURL(p.value, p.name); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Tracing.Endpoint != "" {
+		u, err := url.Parse(cfg.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid tracing-endpoint %q: must be an http or https URL", cfg.Tracing.Endpoint)
+		}
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("invalid tracing-sample-ratio %g: must be between 0 and 1", cfg.Tracing.SampleRatio)
+	}
+
+	if err := cfg.ValidateFeatures(); err != nil {
+		return err
+	}
+
+	for component, logLevel := range cfg.LogLevels {
+		if _, err := zapcore.ParseLevel(logLevel); err != nil {
+			return fmt.Errorf("invalid log level %q for %q in log-levels", logLevel, component)
+		}
+	}
+
+	if cfg.Agent.UpdateInterval < minUpdateInterval || cfg.Agent.UpdateInterval > maxUpdateInterval {
+		return fmt.Errorf("invalid console-update-interval %s: must be between %s and %s", cfg.Agent.UpdateInterval, minUpdateInterval, maxUpdateInterval)
+	}
+
+	if cfg.Console.RemoteConfigEnabled && (cfg.Console.RemoteConfigInterval < minRemoteInterval || cfg.Console.RemoteConfigInterval > maxRemoteInterval) {
+		return fmt.Errorf("invalid console-remote-config-interval %s: must be between %s and %s", cfg.Console.RemoteConfigInterval, minRemoteInterval, maxRemoteInterval)
+	}
+
+	if cfg.Agent.PolicyBundleURL != "" {
+		if u, err := url.Parse(cfg.Agent.PolicyBundleURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid policy-bundle-url %q: must be an http or https URL", cfg.Agent.PolicyBundleURL)
+		}
+	}
+
+	if cfg.Agent.UpdatePublicKey != "" {
+		if _, err := update.ParsePublicKey(cfg.Agent.UpdatePublicKey); err != nil {
+			return err
+		}
+	}
+	if cfg.Agent.UpdateChannelURL != "" {
+		if u, err := url.Parse(cfg.Agent.UpdateChannelURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid update-channel-url %q: must be an http or https URL", cfg.Agent.UpdateChannelURL)
+		}
+		if cfg.Agent.UpdatePublicKey == "" {
+			return errors.New("update-public-key must be set to verify the updates of update-channel-url")
+		}
+	}
+
+	if cfg.Agent.HALeaseFile != "" && cfg.Agent.HALeaseDuration < 3*time.Second {
+		return fmt.Errorf("invalid ha-lease-duration %s: must be at least 3s", cfg.Agent.HALeaseDuration)
+	}
+
+	if cfg.Agent.ShutdownTimeout <= 0 {
+		return fmt.Errorf("invalid shutdown-timeout %s: must be positive", cfg.Agent.ShutdownTimeout)
+	}
+
 	if cfg.Agent.NumWorkers < 1 {
 		return fmt.Errorf("invalid num-workers %d: must be at least 1", cfg.Agent.NumWorkers)
 	}
 
-	if cfg.Auth.Enabled && cfg.Auth.JWTFilePath == "" {
+	if cfg.Auth.Enabled && cfg.Auth.JWTFilePath == "" && cfg.Auth.JWT == "" {
 		return errors.New("authentication-jwt-filepath must be set when authentication is enabled")
 	}
 
+	if cfg.Auth.LocalToken != "" && len(cfg.Auth.LocalToken) < minLocalTokenLength {
+		return fmt.Errorf("invalid local api token: must be at least %d characters", minLocalTokenLength)
+	}
+
+	if cfg.Auth.JWKSURL != "" {
+		if u, err := url.Parse(cfg.Auth.JWKSURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid authentication-jwks-url %q: must be an http or https URL", cfg.Auth.JWKSURL)
+		}
+		if cfg.Auth.Issuer == "" || cfg.Auth.Audience == "" {
+			return errors.New("authentication-issuer and authentication-audience must be set when authentication-jwks-url is set")
+		}
+		if cfg.Auth.RoleClaim == "" {
+			return errors.New("authentication-role-claim must be set when authentication-jwks-url is set")
+		}
+	} else if cfg.Auth.Issuer != "" || cfg.Auth.Audience != "" {
+		return errors.New("authentication-issuer and authentication-audience require authentication-jwks-url")
+	}
+
+	for _, p := range cfg.Auth.ExemptPaths {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("invalid authentication-exempt-paths entry %q: must be an absolute path", p)
+		}
+	}
+
+	if cfg.Auth.MaxLoginFailures < 0 {
+		return errors.New("authentication-max-login-failures must not be negative")
+	}
+	if cfg.Auth.MaxLoginFailures > 0 && cfg.Auth.LoginLockout <= 0 {
+		return errors.New("authentication-login-lockout must be positive when authentication-max-login-failures is set")
+	}
+
+	if cfg.Auth.DeviceLoginEnabled {
+		if !cfg.Auth.Enabled {
+			return errors.New("authentication-device-login requires authentication-enabled")
+		}
+		if cfg.Auth.JWTFilePath == "" {
+			return errors.New("authentication-jwt-filepath must be set to persist the jwt of authentication-device-login")
+		}
+		if u, err := url.Parse(cfg.Auth.SSOURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid authentication-sso-url %q: must be an http or https URL", cfg.Auth.SSOURL)
+		}
+		if cfg.Auth.SSOClientID == "" {
+			return errors.New("authentication-sso-client-id must be set when authentication-device-login is enabled")
+		}
+	}
+
 	return nil
 }
 
-func initStore(cfg *config.Configuration) (*store.Store, error) {
+func initStore(cfg *config.Configuration) (*store.Store, *policy.Policies, error) {
 	// init store
 	dbPath := filepath.Join(cfg.Agent.DataFolder, "agent.duckdb")
 	if cfg.Agent.DataFolder == "" {
@@ -235,16 +943,247 @@ func initStore(cfg *config.Configuration) (*store.Store, error) {
 	db, err := store.NewDB(dbPath)
 	if err != nil {
 		zap.S().Errorw("failed to initialize database", "error", err)
-		return nil, err
+		return nil, nil, err
 	}
 
-	opaValidator, err := opa.NewValidatorFromDir(cfg.Agent.OpaPoliciesFolder)
+	// the policies are reloaded when their files change
+	policies, err := policy.NewFromDir(cfg.Agent.OpaPoliciesFolder)
 	if err != nil {
 		zap.S().Errorw("failed to initialize OPA validator", "error", err)
-		return nil, err
+		return nil, nil, err
+	}
+
+	return store.NewStore(db, policies), policies, nil
+}
+
+// newLeaderElection returns the election of the high availability pair of the
+// agent, nil when Agent.HALeaseFile is not set. The agent is named by its
+// hostname unless Agent.HAIdentity is set.
+func newLeaderElection(cfg *config.Configuration) (*services.LeaderElection, error) {
+	if cfg.Agent.HALeaseFile == "" {
+		return nil, nil
+	}
+	identity := cfg.Agent.HAIdentity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to name the agent in the lease, set ha-identity: %w", err)
+		}
+		identity = hostname
+	}
+	zap.S().Infow("high availability enabled", "lease_file", cfg.Agent.HALeaseFile, "identity", identity, "lease_duration", cfg.Agent.HALeaseDuration)
+	return services.NewLeaderElection(lease.NewFile(cfg.Agent.HALeaseFile), identity, cfg.Agent.HALeaseDuration), nil
+}
+
+// stopLeaderWork stops the collections, inspection and benchmark of a leader
+// stepping down, its successor starting them again.
+func stopLeaderWork(sourcesSrv *services.Sources, inspectorSrv *services.InspectorService, benchmarkSrv *services.BenchmarkService) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, source := range sourcesSrv.List() {
+		if err := sourcesSrv.StopCollector(source.ID); err != nil {
+			zap.S().Warnw("failed to stop the collection", "source_id", source.ID, "error", err)
+		}
+	}
+	if err := inspectorSrv.Stop(ctx); err != nil && !srvErrors.IsInspectorNotRunningError(err) {
+		zap.S().Warnw("failed to stop the inspection", "error", err)
+	}
+	if err := benchmarkSrv.Stop(ctx); err != nil {
+		zap.S().Warnw("failed to stop the benchmark", "error", err)
+	}
+}
+
+// newSourceFactory returns the factory of the sources added through the api:
+// each one is collected into <data-folder>/sources/<id>.duckdb and reports to
+// the console with its id, in the mode of the agent. Without a data folder
+// their databases are in memory as well.
+func newSourceFactory(cfg *config.Configuration, policies *policy.Policies, sched *scheduler.Scheduler, client *console.Client, primary *services.Console,
+	election *services.LeaderElection) services.SourceFactory {
+	return func(source models.Source) (*services.SourceServices, error) {
+		dbPath := ":memory:"
+		if cfg.Agent.DataFolder != "" {
+			dir := filepath.Join(cfg.Agent.DataFolder, "sources")
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				return nil, err
+			}
+			dbPath = filepath.Join(dir, source.ID+".duckdb")
+		}
+		db, err := store.NewDB(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		st := store.NewStore(db, policies)
+		if err := st.Migrate(context.Background()); err != nil {
+			return nil, errors.Join(err, st.Close())
+		}
+
+		workBuilder := collectorv1.NewWorkBuilder(st, cfg.Agent.DataFolder, cfg.Agent.OpaPoliciesFolder).
+			WithProxy(cfg.Proxy.ProxyFunc(config.ProxyTargetVCenter))
+		collectorSrv := services.NewCollectorService(sched, st, workBuilder)
+
+		agentCfg := cfg.Agent
+		agentCfg.SourceID = source.ID
+		agentCfg.Mode = string(primary.Status().Target)
+		consoleSrv, err := services.NewConsoleService(agentCfg, sched, client, collectorSrv, st)
+		if err != nil {
+			return nil, errors.Join(err, st.Close())
+		}
+		consoleSrv.WithLeaderElection(election)
+
+		return &services.SourceServices{
+			Store:     st,
+			Collector: collectorSrv,
+			Console:   consoleSrv,
+			Inventory: services.NewInventoryService(st),
+			Close: func(remove bool) error {
+				consoleSrv.Stop()
+				collectorSrv.Stop()
+				if !remove {
+					if err := st.Checkpoint(); err != nil {
+						zap.S().Warnw("failed to checkpoint the source database", "source_id", source.ID, "error", err)
+					}
+					return st.Close()
+				}
+				if err := st.Close(); err != nil || dbPath == ":memory:" {
+					return err
+				}
+				for _, path := range []string{dbPath, dbPath + ".wal"} {
+					if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+						return err
+					}
+				}
+				return nil
+			},
+		}, nil
+	}
+}
+
+// newCommandChannel returns the command channel of the agent, the console
+// pushing through it the commands otherwise given to the local api. The
+// configuration is pulled on command only with remoteSrv.
+func newCommandChannel(cfg *config.Configuration, client *console.Client, consoleSrv *services.Console, collectorSrv *services.CollectorService,
+	sourcesSrv *services.Sources, jobSrv *services.JobService, remoteSrv *services.RemoteConfig) *services.CommandChannel {
+	status := func() console.ChannelStatus {
+		consoleStatus := consoleSrv.Status()
+		collectorStatus := collectorSrv.GetStatus()
+		s := console.ChannelStatus{
+			Mode:            string(consoleStatus.Target),
+			ConsoleStatus:   string(consoleStatus.Current),
+			CollectorStatus: string(collectorStatus.State),
+			Version:         cfg.Agent.Version,
+		}
+		if collectorStatus.Error != nil {
+			s.CollectorError = collectorStatus.Error.Error()
+		}
+		return s
+	}
+
+	channel := services.NewCommandChannelService(client, uuid.MustParse(cfg.Agent.ID), status).
+		WithHandler(console.CommandSetMode, func(ctx context.Context, args json.RawMessage) error {
+			var req struct {
+				Mode models.AgentMode `json:"mode"`
+			}
+			if err := json.Unmarshal(args, &req); err != nil {
+				return fmt.Errorf("invalid arguments: %w", err)
+			}
+			if req.Mode != models.AgentModeConnected && req.Mode != models.AgentModeDisconnected {
+				return fmt.Errorf("invalid mode %q: must be 'connected' or 'disconnected'", req.Mode)
+			}
+			if err := consoleSrv.SetMode(ctx, req.Mode); err != nil {
+				return err
+			}
+			return sourcesSrv.SetMode(ctx, req.Mode)
+		}).
+		WithHandler(console.CommandDispatch, func(context.Context, json.RawMessage) error {
+			consoleSrv.DispatchNow()
+			sourcesSrv.DispatchNow()
+			return nil
+		}).
+		WithHandler(console.CommandStopCollection, func(context.Context, json.RawMessage) error {
+			collectorSrv.Stop()
+			return nil
+		}).
+		WithHandler(console.CommandCancelJob, func(ctx context.Context, args json.RawMessage) error {
+			var req struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(args, &req); err != nil {
+				return fmt.Errorf("invalid arguments: %w", err)
+			}
+			_, err := jobSrv.Cancel(ctx, req.ID)
+			return err
+		})
+	if remoteSrv != nil {
+		channel.WithHandler(console.CommandPullConfiguration, func(ctx context.Context, _ json.RawMessage) error {
+			return remoteSrv.Fetch(ctx)
+		})
+	}
+	return channel
+}
+
+// agentExecutable returns the path of the binary of the agent, the one replaced
+// by the updates, following the symlinks to it.
+func agentExecutable() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the agent executable: %w", err)
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+// restartIntoUpdate starts the updated binary at executable once the agent is
+// stopped: in place of the process with exec, or by exiting with a failure
+// status so systemd restarts the unit, Restart=on-failure being required.
+func restartIntoUpdate(executable string, restart config.UpdateRestartType) error {
+	if restart == config.UpdateRestartExit {
+		return errors.New("agent updated, exiting to be restarted")
+	}
+	zap.S().Infow("restarting into the update", "executable", executable)
+	if err := syscall.Exec(executable, os.Args, os.Environ()); err != nil {
+		return fmt.Errorf("failed to restart into the update: %w", err)
+	}
+	return nil
+}
+
+// initSecrets returns the secret store of cfg.Agent.SecretBackend.
+func initSecrets(cfg *config.Configuration, st *store.Store) (models.SecretStore, error) {
+	if config.SecretBackendType(cfg.Agent.SecretBackend) == config.SecretBackendFile {
+		return keyring.NewFile(cfg.Agent.KeyringFolder)
+	}
+
+	keyPath := cfg.Agent.SecretKeyFilePath
+	if keyPath == "" && cfg.Agent.DataFolder != "" {
+		keyPath = filepath.Join(cfg.Agent.DataFolder, "secrets.key")
+	}
+	// an in-memory database loses its secrets on exit, so can its key
+	if keyPath == "" {
+		key := make([]byte, store.SecretKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return st.Secrets(key)
+	}
+
+	key, err := keyring.LoadOrCreateKey(keyPath, store.SecretKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the secret key: %w", err)
+	}
+	return st.Secrets(key)
+}
+
+// initAssessmentKey returns the key signing the assessment bundles, kept in the
+// data folder for the importers to recognize its fingerprint across restarts.
+func initAssessmentKey(cfg *config.Configuration) (ed25519.PrivateKey, error) {
+	if cfg.Agent.DataFolder == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
 	}
 
-	return store.NewStore(db, opaValidator), nil
+	seed, err := keyring.LoadOrCreateKey(filepath.Join(cfg.Agent.DataFolder, "assessment.key"), ed25519.SeedSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the assessment key: %w", err)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
 }
 
 func validateUUID(value, name string) error {
@@ -257,29 +1196,114 @@ func validateUUID(value, name string) error {
 	return nil
 }
 
+// validateProxyURL checks that value is empty or an http, https or socks5 URL.
+func validateProxyURL(value, name string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid %s %q: must be an http, https or socks5 URL", name, value)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid %s %q: missing host", name, value)
+	}
+	return nil
+}
+
 func registerServerFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
 	flagSet.IntVar(&config.Server.HTTPPort, "server-http-port", config.Server.HTTPPort, "Port on which the HTTP server is listening")
 	flagSet.StringVar(&config.Server.StaticsFolder, "server-statics-folder", config.Server.StaticsFolder, "Path to statics folder")
 	flagSet.StringVar(&config.Server.ServerMode, "server-mode", config.Server.ServerMode, "Server mode: either prod or dev. If prod the statics folder must be set")
+	flagSet.DurationVar(&config.Server.ReadHeaderTimeout, "server-read-header-timeout", config.Server.ReadHeaderTimeout, "Maximum duration for reading request headers. 0 means no timeout")
+	flagSet.DurationVar(&config.Server.ReadTimeout, "server-read-timeout", config.Server.ReadTimeout, "Maximum duration for reading an entire request, including the body. 0 means no timeout")
+	flagSet.DurationVar(&config.Server.WriteTimeout, "server-write-timeout", config.Server.WriteTimeout, "Maximum duration before timing out writes of a response. 0 means no timeout")
+	flagSet.DurationVar(&config.Server.IdleTimeout, "server-idle-timeout", config.Server.IdleTimeout, "Maximum time to wait for the next request on keep-alive connections. 0 means no timeout")
+	flagSet.Var(&config.Server.MaxRequestBodySize, "server-max-request-body-size", "Maximum size of a request body, e.g. 64MiB. 0 means unlimited")
+	flagSet.StringVar(&config.Server.AccessLogFile, "server-access-log-file", config.Server.AccessLogFile, "File receiving a JSON access log line per request, separate from the application logs. Disabled when empty")
+	flagSet.Var(&config.Server.AccessLogMaxSize, "server-access-log-max-size", "Size at which the access log is rotated, e.g. 100MiB. 0 disables rotation")
+	flagSet.DurationVar(&config.Server.AccessLogMaxAge, "server-access-log-max-age", config.Server.AccessLogMaxAge, "Age after which rotated access logs are removed. 0 keeps them")
+	flagSet.IntVar(&config.Server.AccessLogMaxBackups, "server-access-log-max-backups", config.Server.AccessLogMaxBackups, "Number of rotated access logs to keep. 0 keeps them all")
+	flagSet.BoolVar(&config.Server.PprofEnabled, "server-pprof-enabled", config.Server.PprofEnabled, "Expose the pprof profiling endpoints under /debug/pprof. Requires a client certificate when server-client-ca-file is set")
+	flagSet.BoolVar(&config.Server.MetricsEnabled, "server-metrics-enabled", config.Server.MetricsEnabled, "Expose the Prometheus metrics under /metrics. Requires a client certificate when server-client-ca-file is set")
+	flagSet.IntVar(&config.Server.AdminPort, "server-admin-port", config.Server.AdminPort, "Port of a separate listener serving the admin endpoints (/admin, /debug/pprof). 0 keeps them on the API port")
+	flagSet.StringVar(&config.Server.AdminUnixSocketPath, "server-admin-unix-socket-path", config.Server.AdminUnixSocketPath, "Path of a unix socket serving the admin endpoints over plain HTTP")
+	flagSet.StringVar(&config.Server.UnixSocketPath, "server-unix-socket-path", config.Server.UnixSocketPath, "Path of a unix socket serving the API over plain HTTP, in addition to the TCP port")
+	flagSet.BoolVar(&config.Server.UnixSocketOnly, "server-unix-socket-only", config.Server.UnixSocketOnly, "Only listen on the unix socket, without opening the TCP port")
+	flagSet.StringVar(&config.Server.TLSCertFile, "server-tls-cert-file", config.Server.TLSCertFile, "Path to the PEM encoded TLS certificate served in prod mode. A self-signed certificate is generated when not set")
+	flagSet.StringVar(&config.Server.TLSKeyFile, "server-tls-key-file", config.Server.TLSKeyFile, "Path to the PEM encoded private key of the TLS certificate")
+	flagSet.StringVar(&config.Server.ClientCAFile, "server-client-ca-file", config.Server.ClientCAFile, "Path to a PEM bundle of CAs. When set, /api requests must present a client certificate signed by one of them")
+	flagSet.StringSliceVar(&config.Server.ACMEDomains, "server-acme-domains", config.Server.ACMEDomains, "Public domain names of the agent. When set in prod mode, the certificate is obtained through ACME (e.g. Let's Encrypt)")
+	flagSet.StringVar(&config.Server.ACMEEmail, "server-acme-email", config.Server.ACMEEmail, "Contact email registered with the ACME account")
+	flagSet.StringVar(&config.Server.ACMEDirectoryURL, "server-acme-directory-url", config.Server.ACMEDirectoryURL, "ACME directory URL. Defaults to Let's Encrypt production")
+	flagSet.IntVar(&config.Server.ACMEHTTPPort, "server-acme-http-port", config.Server.ACMEHTTPPort, "Port answering the ACME HTTP-01 challenges and redirecting to HTTPS")
+	flagSet.StringSliceVar(&config.Server.CORSAllowedOrigins, "server-cors-allowed-origins", config.Server.CORSAllowedOrigins, "Origins allowed to call the API from a browser (\"*\" for any). CORS is disabled when empty")
+	flagSet.StringSliceVar(&config.Server.CORSAllowedMethods, "server-cors-allowed-methods", config.Server.CORSAllowedMethods, "Methods allowed in CORS requests. Defaults to all the common methods")
+	flagSet.Float64Var(&config.Server.RateLimitRPS, "server-rate-limit-rps", config.Server.RateLimitRPS, "Requests per second allowed per client IP on the API. 0 disables rate limiting")
+	flagSet.IntVar(&config.Server.RateLimitBurst, "server-rate-limit-burst", config.Server.RateLimitBurst, "Burst of requests allowed per client IP above the rate limit")
+	flagSet.StringSliceVar(&config.Server.RateLimitExemptPaths, "server-rate-limit-exempt-paths", config.Server.RateLimitExemptPaths, "API paths never rate limited, such as status and health endpoints")
+	flagSet.StringSliceVar(&config.Server.TrustedProxies, "server-trusted-proxies", config.Server.TrustedProxies, "Addresses or CIDRs of the reverse proxies trusted to name the client in X-Forwarded-For. None by default")
+	flagSet.BoolVar(&config.Server.CompressionEnabled, "server-compression-enabled", config.Server.CompressionEnabled, "Gzip JSON responses for clients accepting it")
+	flagSet.IntVar(&config.Server.CompressionMinSize, "server-compression-min-size", config.Server.CompressionMinSize, "Minimum size in bytes of a response to be compressed")
+	flagSet.StringSliceVar(&config.Server.CORSAllowedHeaders, "server-cors-allowed-headers", config.Server.CORSAllowedHeaders, "Headers allowed in CORS requests. Defaults to Origin, Content-Length, Content-Type, Authorization, X-API-Key and X-Request-ID")
 }
 
 func registerAuthenticationFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
 	flagSet.BoolVar(&config.Auth.Enabled, "authentication-enabled", config.Auth.Enabled, "Enable authentication when connecting to console")
 	flagSet.StringVar(&config.Auth.JWTFilePath, "authentication-jwt-filepath", config.Auth.JWTFilePath, "Path of the jwt file")
+	flagSet.StringVar(&config.Auth.LocalTokenFilePath, "authentication-local-token-filepath", config.Auth.LocalTokenFilePath, "Path of the file holding the token required by the requests of the local API")
+	flagSet.StringVar(&config.Auth.ProvisioningTokenFilePath, "authentication-provisioning-token-filepath", config.Auth.ProvisioningTokenFilePath, "Path of the file holding the token registering with the console an agent deployed without agent-id and source-id")
+	flagSet.StringVar(&config.Auth.JWKSURL, "authentication-jwks-url", config.Auth.JWKSURL, "JWKS URL of the identity provider whose JWTs are accepted by the local API")
+	flagSet.StringVar(&config.Auth.Issuer, "authentication-issuer", config.Auth.Issuer, "Issuer required in the JWTs accepted by the local API, required with authentication-jwks-url")
+	flagSet.StringVar(&config.Auth.Audience, "authentication-audience", config.Auth.Audience, "Audience required in the JWTs accepted by the local API, required with authentication-jwks-url")
+	flagSet.StringVar(&config.Auth.RoleClaim, "authentication-role-claim", config.Auth.RoleClaim, "JWT claim granting the operator role, a dotted path for nested claims")
+	flagSet.StringSliceVar(&config.Auth.ExemptPaths, "authentication-exempt-paths", config.Auth.ExemptPaths, "Local API paths served without credentials, such as probes, exact or prefixes ending with *")
+	flagSet.IntVar(&config.Auth.MaxLoginFailures, "authentication-max-login-failures", config.Auth.MaxLoginFailures, "Invalid credentials after which a client IP is locked out of the local API, each failure before doubling its wait. 0 disables throttling")
+	flagSet.DurationVar(&config.Auth.LoginLockout, "authentication-login-lockout", config.Auth.LoginLockout, "Duration of the lockout after authentication-max-login-failures")
+	flagSet.BoolVar(&config.Auth.DeviceLoginEnabled, "authentication-device-login", config.Auth.DeviceLoginEnabled, "Obtain the agent's jwt by logging in with the OAuth device flow, written to authentication-jwt-filepath")
+	flagSet.StringVar(&config.Auth.SSOURL, "authentication-sso-url", config.Auth.SSOURL, "Keycloak realm URL of the device login")
+	flagSet.StringVar(&config.Auth.SSOClientID, "authentication-sso-client-id", config.Auth.SSOClientID, "OAuth client of the device login")
 }
 
 func registerAgentFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
 	flagSet.StringVar(&config.Agent.Mode, "mode", config.Agent.Mode, "Agent mode: connected or disconnected")
 	flagSet.StringVar(&config.Agent.OpaPoliciesFolder, "opa-policies-folder", config.Agent.OpaPoliciesFolder, "Path to the OPA policies folder")
+	flagSet.BoolVar(&config.Agent.ResumeCollection, "resume-collection", config.Agent.ResumeCollection, "Store the vCenter credentials of a collection in the secret backend until it ends, to start it again after a restart. Otherwise they stay in memory")
+	flagSet.StringVar(&config.Agent.SecretBackend, "secret-backend", config.Agent.SecretBackend, "Store of the secrets of resume-collection, the vCenter credentials: database (encrypted) or file")
+	flagSet.StringVar(&config.Agent.SecretKeyFilePath, "secret-key-filepath", config.Agent.SecretKeyFilePath, "Path of the key encrypting the secrets in the database, generated when missing. Defaults to secrets.key of the data folder")
+	flagSet.StringVar(&config.Agent.KeyringFolder, "keyring-folder", config.Agent.KeyringFolder, "Folder holding a file per secret with the file secret backend")
 	flagSet.StringVar(&config.Agent.ID, "agent-id", config.Agent.ID, "Unique identifier (UUID) for this agent")
 	flagSet.StringVar(&config.Agent.SourceID, "source-id", config.Agent.SourceID, "Source identifier (UUID) for this agent")
 	flagSet.StringVar(&config.Agent.Version, "version", config.Agent.Version, "Agent version to report to console")
 	flagSet.IntVar(&config.Agent.NumWorkers, "num-workers", config.Agent.NumWorkers, "Number of scheduler workers")
 	flagSet.StringVar(&config.Agent.DataFolder, "data-folder", config.Agent.DataFolder, "Path to the persistent data folder")
 	flagSet.BoolVar(&config.Agent.LegacyStatusEnabled, "legacy-status-enabled", config.Agent.LegacyStatusEnabled, "Use agent's legacy status like waiting-for-credentials")
+	flagSet.DurationVar(&config.Agent.QueryExplainThreshold, "query-explain-threshold", config.Agent.QueryExplainThreshold, "Log the EXPLAIN ANALYZE plan of the database list queries slower than this, running them twice. 0 disables it")
+	flagSet.DurationVar(&config.Agent.ShutdownTimeout, "shutdown-timeout", config.Agent.ShutdownTimeout, "Deadline of the graceful shutdown, past which the stopping components are abandoned")
+	flagSet.StringVar(&config.Agent.UpdatePublicKey, "update-public-key", config.Agent.UpdatePublicKey, "Base64 ed25519 public key verifying the updates of the update channel")
+	flagSet.StringVar(&config.Agent.UpdateRestart, "update-restart", config.Agent.UpdateRestart, "How the agent restarts into an installed update: exec to replace its process, exit to let systemd restart it")
+	flagSet.StringVar(&config.Agent.HALeaseFile, "ha-lease-file", config.Agent.HALeaseFile, "File holding the lease of the leader of a high availability pair, on a storage both agents mount such as an NFS export: a pair requires that shared storage. Disabled when empty")
+	flagSet.DurationVar(&config.Agent.HALeaseDuration, "ha-lease-duration", config.Agent.HALeaseDuration, "Duration of the lease of the leader, renewed every third of it: the standby takes over a lease unchanged for this long")
+	flagSet.StringVar(&config.Agent.HAIdentity, "ha-identity", config.Agent.HAIdentity, "Name of the agent in the lease, distinct within the pair. Defaults to the hostname")
+	flagSet.StringSliceVar(&config.Agent.AssessmentTrustedKeys, "assessment-trusted-keys", config.Agent.AssessmentTrustedKeys, "Fingerprints of the keys whose assessment bundles are imported. No bundle is imported when empty")
+	flagSet.StringVar(&config.Agent.ErrorWebhookURL, "error-webhook-url", config.Agent.ErrorWebhookURL, "URL receiving a JSON POST for each panic, fatal console error and failed collection of the agent. Disabled when empty")
 }
 
 func registerConsoleFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
 	flagSet.StringVar(&config.Console.URL, "console-url", config.Console.URL, "URL of console.redhat.com")
 	flagSet.DurationVar(&config.Agent.UpdateInterval, "console-update-interval", config.Agent.UpdateInterval, "Interval for console status updates")
+	flagSet.BoolVar(&config.Console.RemoteConfigEnabled, "console-remote-config-enabled", config.Console.RemoteConfigEnabled, "Pull the update interval, features, policy bundle URL and update channel URL from the console")
+	flagSet.DurationVar(&config.Console.RemoteConfigInterval, "console-remote-config-interval", config.Console.RemoteConfigInterval, "Interval between pulls of the remote configuration")
+	flagSet.BoolVar(&config.Console.CommandChannelEnabled, "console-command-channel-enabled", config.Console.CommandChannelEnabled, "Keep a websocket open to the console in connected mode to receive its commands and send the status as it changes")
+}
+
+func registerTracingFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
+	flagSet.StringVar(&config.Tracing.Endpoint, "tracing-endpoint", config.Tracing.Endpoint, "OTLP/HTTP URL of the collector receiving the traces, e.g. http://localhost:4318. Tracing is off when empty")
+	flagSet.Float64Var(&config.Tracing.SampleRatio, "tracing-sample-ratio", config.Tracing.SampleRatio, "Fraction of the traces recorded, between 0 and 1")
+	flagSet.StringVar(&config.Tracing.ServiceName, "tracing-service-name", config.Tracing.ServiceName, "Service name of the exported spans")
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -356,6 +357,38 @@ var _ = Describe("Run Command", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("agent-id must be a valid UUID"))
 			})
+
+			// Given no agent-id and source-id but a provisioning token
+			// When we validate the configuration
+			// Then validation should pass as the agent registers to get them
+			It("should pass without ids when a provisioning token is set", func() {
+				// Arrange
+				cfg.Agent.ID = ""
+				cfg.Agent.SourceID = ""
+				cfg.Auth.ProvisioningToken = "provisioning-token"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given a provisioning token with only the source-id
+			// When we validate the configuration
+			// Then it should fail as the registration gives both ids
+			It("should fail when only one id is set with a provisioning token", func() {
+				// Arrange
+				cfg.Agent.ID = ""
+				cfg.Auth.ProvisioningToken = "provisioning-token"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("agent-id cannot be empty"))
+			})
 		})
 
 		Context("source-id validation", func() {
@@ -497,13 +530,14 @@ var _ = Describe("Run Command", func() {
 			})
 		})
 
-		Context("http-port validation", func() {
-			// Given a valid port number
+		Context("tls files validation", func() {
+			// Given both the TLS certificate and key files
 			// When we validate the configuration
 			// Then validation should pass
-			It("should accept valid port", func() {
+			It("should accept certificate and key files together", func() {
 				// Arrange
-				cfg.Server.HTTPPort = 8080
+				cfg.Server.TLSCertFile = "/etc/agent/tls.crt"
+				cfg.Server.TLSKeyFile = "/etc/agent/tls.key"
 
 				// Act
 				err := validateConfiguration(cfg)
@@ -512,56 +546,81 @@ var _ = Describe("Run Command", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			// Given port 0
+			// Given a TLS certificate file without its key file
 			// When we validate the configuration
 			// Then it should fail with appropriate error
-			It("should fail with port 0", func() {
+			It("should fail with certificate file only", func() {
 				// Arrange
-				cfg.Server.HTTPPort = 0
+				cfg.Server.TLSCertFile = "/etc/agent/tls.crt"
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("invalid http-port"))
+				Expect(err.Error()).To(ContainSubstring("must be set together"))
 			})
+		})
 
-			// Given a port greater than 65535
+		Context("unix socket validation", func() {
+			// Given unix socket only mode with a socket path
+			// When we validate the configuration
+			// Then validation should pass
+			It("should accept unix socket only mode with a path", func() {
+				// Arrange
+				cfg.Server.UnixSocketPath = "/run/agent/agent.sock"
+				cfg.Server.UnixSocketOnly = true
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given unix socket only mode without a socket path
 			// When we validate the configuration
 			// Then it should fail with appropriate error
-			It("should fail with port > 65535", func() {
+			It("should fail with unix socket only mode without a path", func() {
 				// Arrange
-				cfg.Server.HTTPPort = 70000
+				cfg.Server.UnixSocketOnly = true
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("invalid http-port"))
+				Expect(err.Error()).To(ContainSubstring("server-unix-socket-path must be set"))
 			})
+		})
 
-			// Given port 1 (minimum valid)
+		Context("client-ca-file validation", func() {
+			// Given a client CA file in dev mode
 			// When we validate the configuration
-			// Then validation should pass
-			It("should accept port 1", func() {
+			// Then it should fail with appropriate error
+			It("should fail with client CA file in dev mode", func() {
 				// Arrange
-				cfg.Server.HTTPPort = 1
+				cfg.Server.ServerMode = "dev"
+				cfg.Server.ClientCAFile = "/etc/agent/client-ca.crt"
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
-				Expect(err).ToNot(HaveOccurred())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("server-client-ca-file requires server mode prod"))
 			})
+		})
 
-			// Given port 65535 (maximum valid)
+		Context("acme validation", func() {
+			// Given ACME domains in prod mode
 			// When we validate the configuration
 			// Then validation should pass
-			It("should accept port 65535", func() {
+			It("should accept acme domains in prod mode", func() {
 				// Arrange
-				cfg.Server.HTTPPort = 65535
+				cfg.Server.ServerMode = "prod"
+				cfg.Server.StaticsFolder = "/var/www/statics"
+				cfg.Server.ACMEDomains = []string{"agent.example.com"}
 
 				// Act
 				err := validateConfiguration(cfg)
@@ -569,15 +628,50 @@ var _ = Describe("Run Command", func() {
 				// Assert
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			// Given ACME domains in dev mode
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with acme domains in dev mode", func() {
+				// Arrange
+				cfg.Server.ServerMode = "dev"
+				cfg.Server.ACMEDomains = []string{"agent.example.com"}
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("requires server mode prod"))
+			})
+
+			// Given ACME domains together with certificate files
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with acme domains and certificate files", func() {
+				// Arrange
+				cfg.Server.ServerMode = "prod"
+				cfg.Server.StaticsFolder = "/var/www/statics"
+				cfg.Server.ACMEDomains = []string{"agent.example.com"}
+				cfg.Server.TLSCertFile = "/etc/agent/tls.crt"
+				cfg.Server.TLSKeyFile = "/etc/agent/tls.key"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cannot be used together"))
+			})
 		})
 
-		Context("num-workers validation", func() {
-			// Given a valid num-workers value
+		Context("rate limit validation", func() {
+			// Given rate limiting disabled
 			// When we validate the configuration
 			// Then validation should pass
-			It("should accept valid num-workers", func() {
+			It("should accept rps 0", func() {
 				// Arrange
-				cfg.Agent.NumWorkers = 5
+				cfg.Server.RateLimitRPS = 0
 
 				// Act
 				err := validateConfiguration(cfg)
@@ -586,60 +680,124 @@ var _ = Describe("Run Command", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			// Given num-workers set to 0
+			// Given a negative rate
 			// When we validate the configuration
 			// Then it should fail with appropriate error
-			It("should fail with num-workers = 0", func() {
+			It("should fail with negative rps", func() {
 				// Arrange
-				cfg.Agent.NumWorkers = 0
+				cfg.Server.RateLimitRPS = -1
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("invalid num-workers"))
+				Expect(err.Error()).To(ContainSubstring("invalid rate-limit-rps"))
 			})
 
-			// Given a negative num-workers value
+			// Given rate limiting enabled with a burst of 0
 			// When we validate the configuration
 			// Then it should fail with appropriate error
-			It("should fail with negative num-workers", func() {
+			It("should fail with burst 0", func() {
 				// Arrange
-				cfg.Agent.NumWorkers = -1
+				cfg.Server.RateLimitRPS = 10
+				cfg.Server.RateLimitBurst = 0
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("invalid num-workers"))
+				Expect(err.Error()).To(ContainSubstring("invalid rate-limit-burst"))
 			})
 		})
 
-		Context("authentication validation", func() {
-			// Given authentication is disabled
+		Context("compression validation", func() {
+			// Given a negative compression threshold
 			// When we validate the configuration
-			// Then validation should pass
-			It("should pass when authentication disabled", func() {
+			// Then it should fail with appropriate error
+			It("should fail with negative min size", func() {
 				// Arrange
-				cfg.Auth.Enabled = false
-				cfg.Auth.JWTFilePath = ""
+				cfg.Server.CompressionMinSize = -1
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
-				Expect(err).ToNot(HaveOccurred())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid compression-min-size"))
 			})
+		})
 
-			// Given authentication is enabled with jwt path
+		Context("timeouts and body size validation", func() {
+			// Given a negative timeout
 			// When we validate the configuration
-			// Then validation should pass
-			It("should pass when authentication enabled with jwt path", func() {
+			// Then it should fail with appropriate error
+			It("should fail with a negative timeout", func() {
 				// Arrange
-				cfg.Auth.Enabled = true
-				cfg.Auth.JWTFilePath = "/path/to/jwt"
+				cfg.Server.ReadTimeout = -time.Second
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("timeouts must not be negative"))
+			})
+
+			// Given a negative max request body size
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a negative max request body size", func() {
+				// Arrange
+				cfg.Server.MaxRequestBodySize = -1
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid max-request-body-size"))
+			})
+
+			// Given a read timeout below a second
+			// When we validate the configuration
+			// Then it should fail with the accepted range
+			It("should fail with a sub-second timeout", func() {
+				// Arrange
+				cfg.Server.ReadTimeout = 5 * time.Millisecond
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid server-read-timeout 5ms: must be 0 or between 1s and 24h0m0s"))
+			})
+
+			// Given a max request body size of a few bytes
+			// When we validate the configuration
+			// Then it should fail with the accepted range
+			It("should fail with a tiny max request body size", func() {
+				// Arrange
+				cfg.Server.MaxRequestBodySize = 10
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid max-request-body-size 10: must be 0 or between 1KiB and 4GiB"))
+			})
+
+			// Given no timeouts and an unlimited body size
+			// When we validate the configuration
+			// Then it should succeed
+			It("should accept zero timeouts and body size", func() {
+				// Arrange
+				cfg.Server.ReadTimeout = 0
+				cfg.Server.IdleTimeout = 0
+				cfg.Server.MaxRequestBodySize = 0
 
 				// Act
 				err := validateConfiguration(cfg)
@@ -647,22 +805,1135 @@ var _ = Describe("Run Command", func() {
 				// Assert
 				Expect(err).ToNot(HaveOccurred())
 			})
+		})
 
-			// Given authentication is enabled without jwt path
+		Context("access log validation", func() {
+			// Given a negative access log max size
 			// When we validate the configuration
 			// Then it should fail with appropriate error
-			It("should fail when authentication enabled without jwt path", func() {
+			It("should fail with a negative max size", func() {
 				// Arrange
-				cfg.Auth.Enabled = true
-				cfg.Auth.JWTFilePath = ""
+				cfg.Server.AccessLogMaxSize = -1
 
 				// Act
 				err := validateConfiguration(cfg)
 
 				// Assert
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("authentication-jwt-filepath must be set"))
+				Expect(err.Error()).To(ContainSubstring("access log rotation settings must not be negative"))
+			})
+
+			// Given a negative access log max backups
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with negative max backups", func() {
+				// Arrange
+				cfg.Server.AccessLogMaxBackups = -1
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("access log rotation settings must not be negative"))
+			})
+		})
+
+		Context("log-levels validation", func() {
+			// Given an invalid component log level
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an invalid level", func() {
+				// Arrange
+				cfg.LogLevels = map[string]string{"store": "verbose"}
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`invalid log level "verbose" for "store"`))
+			})
+		})
+
+		Context("query explain threshold validation", func() {
+			// Given a negative query explain threshold
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a negative threshold", func() {
+				// Arrange
+				cfg.Agent.QueryExplainThreshold = -time.Second
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid query-explain-threshold"))
+			})
+		})
+
+		Context("error webhook validation", func() {
+			// Given an error webhook URL that is not an http URL
+			// When we validate the configuration
+			// Then it should fail without printing the URL
+			It("should fail with an invalid URL", func() {
+				// Arrange
+				cfg.Agent.ErrorWebhookURL = "hooks.example.com/secret-token"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid error-webhook-url"))
+				Expect(err.Error()).NotTo(ContainSubstring("secret-token"))
+			})
+
+			// Given an https error webhook URL
+			// When we validate the configuration
+			// Then it should succeed
+			It("should accept an https URL", func() {
+				// Arrange
+				cfg.Agent.ErrorWebhookURL = "https://hooks.example.com/agent-errors"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("log file validation", func() {
+			// Given a negative log file max size
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with negative max size", func() {
+				// Arrange
+				cfg.LogFileMaxSize = -1
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("log file rotation settings must not be negative"))
+			})
+
+			// Given a negative log file max age
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with negative max age", func() {
+				// Arrange
+				cfg.LogFileMaxAge = -time.Hour
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("log file rotation settings must not be negative"))
+			})
+		})
+
+		Context("remote configuration validation", func() {
+			// Given remote configuration pulled every few seconds
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a remote config interval below a minute", func() {
+				// Arrange
+				cfg.Console.RemoteConfigEnabled = true
+				cfg.Console.RemoteConfigInterval = 5 * time.Second
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid console-remote-config-interval"))
 			})
+
+			// Given a policy bundle URL that is not http or https
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an invalid policy bundle URL", func() {
+				// Arrange
+				cfg.Agent.PolicyBundleURL = "file:///etc/bundle.tar.gz"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid policy-bundle-url"))
+			})
+		})
+
+		Context("shutdown validation", func() {
+			// Given a shutdown timeout of zero
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a zero shutdown timeout", func() {
+				// Arrange
+				cfg.Agent.ShutdownTimeout = 0
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid shutdown-timeout"))
+			})
+		})
+
+		Context("high availability validation", func() {
+			// Given a lease renewed more often than the file storage can follow
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a too short lease", func() {
+				// Arrange
+				cfg.Agent.HALeaseFile = "/mnt/shared/agent.lease"
+				cfg.Agent.HALeaseDuration = time.Second
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid ha-lease-duration"))
+			})
+
+			// Given a short duration without high availability
+			// When we validate the configuration
+			// Then the duration should be ignored
+			It("should ignore the lease duration without lease file", func() {
+				// Arrange
+				cfg.Agent.HALeaseDuration = time.Second
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("update validation", func() {
+			// Given an update channel without the key verifying its updates
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an update channel without public key", func() {
+				// Arrange
+				cfg.Agent.UpdateChannelURL = "https://console.example.com/api/v1/agents/1/update"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("update-public-key must be set"))
+			})
+
+			// Given a public key that is not a base64 ed25519 key
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an invalid public key", func() {
+				// Arrange
+				cfg.Agent.UpdatePublicKey = "not-a-key"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid update public key"))
+			})
+
+			// Given an unknown restart method
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an invalid update restart", func() {
+				// Arrange
+				cfg.Agent.UpdateRestart = "reboot"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid update-restart"))
+			})
+		})
+
+		Context("features validation", func() {
+			// Given an unknown feature
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an unknown feature", func() {
+				// Arrange
+				cfg.Features = map[string]bool{"inspektor": true}
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`unknown feature "inspektor"`))
+			})
+		})
+
+		Context("proxy validation", func() {
+			// Given a proxy URL with an unsupported scheme
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an unsupported scheme", func() {
+				// Arrange
+				cfg.Proxy.HTTPSProxy = "ftp://proxy.example.com:3128"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid proxy.httpsProxy"))
+			})
+
+			// Given a proxy URL without a host
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail without a host", func() {
+				// Arrange
+				cfg.Proxy.ConsoleProxy = "http://"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("missing host"))
+			})
+
+			// Given direct overrides and a socks5 proxy
+			// When we validate the configuration
+			// Then it should succeed
+			It("should accept direct overrides", func() {
+				// Arrange
+				cfg.Proxy.HTTPProxy = "socks5://proxy.example.com:1080"
+				cfg.Proxy.ConsoleProxy = config.ProxyDirect
+				cfg.Proxy.VCenterProxy = config.ProxyDirect
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given direct as the default HTTP proxy
+			// When we validate the configuration
+			// Then it should fail since only the overrides accept it
+			It("should reject direct as the default proxy", func() {
+				// Arrange
+				cfg.Proxy.HTTPProxy = config.ProxyDirect
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid proxy.httpProxy"))
+			})
+		})
+
+		Context("tracing validation", func() {
+			// Given a tracing endpoint that is not an http URL
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with an invalid endpoint", func() {
+				// Arrange
+				cfg.Tracing.Endpoint = "localhost:4318"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid tracing-endpoint"))
+			})
+
+			// Given a sample ratio above 1
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a sample ratio out of range", func() {
+				// Arrange
+				cfg.Tracing.SampleRatio = 1.5
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid tracing-sample-ratio"))
+			})
+
+			// Given an OTLP/HTTP endpoint and a sample ratio
+			// When we validate the configuration
+			// Then it should succeed
+			It("should accept an http endpoint", func() {
+				// Arrange
+				cfg.Tracing.Endpoint = "http://otel-collector:4318"
+				cfg.Tracing.SampleRatio = 0.25
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("console-update-interval validation", func() {
+			// Given a zero console update interval
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with a zero interval", func() {
+				// Arrange
+				cfg.Agent.UpdateInterval = 0
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid console-update-interval"))
+			})
+
+			// Given a console update interval of several days
+			// When we validate the configuration
+			// Then it should fail with the accepted range
+			It("should fail with an interval above a day", func() {
+				// Arrange
+				cfg.Agent.UpdateInterval = 72 * time.Hour
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("must be between 1s and 24h0m0s"))
+			})
+		})
+
+		Context("admin listener validation", func() {
+			// Given an admin port greater than 65535
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with admin port > 65535", func() {
+				// Arrange
+				cfg.Server.AdminPort = 70000
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid admin-port"))
+			})
+
+			// Given an admin port equal to the API port
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail when the admin port is the API port", func() {
+				// Arrange
+				cfg.Server.AdminPort = cfg.Server.HTTPPort
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("must differ from server-http-port"))
+			})
+
+			// Given an admin unix socket equal to the API unix socket
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail when the admin socket is the API socket", func() {
+				// Arrange
+				cfg.Server.UnixSocketPath = "/run/agent.sock"
+				cfg.Server.AdminUnixSocketPath = "/run/agent.sock"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("must differ from server-unix-socket-path"))
+			})
+
+			// Given a distinct admin port and socket
+			// When we validate the configuration
+			// Then validation should pass
+			It("should accept a distinct admin port and socket", func() {
+				// Arrange
+				cfg.Server.AdminPort = cfg.Server.HTTPPort + 1
+				cfg.Server.AdminUnixSocketPath = "/run/agent-admin.sock"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("http-port validation", func() {
+			// Given a valid port number
+			// When we validate the configuration
+			// Then validation should pass
+			It("should accept valid port", func() {
+				// Arrange
+				cfg.Server.HTTPPort = 8080
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given port 0
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with port 0", func() {
+				// Arrange
+				cfg.Server.HTTPPort = 0
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid http-port"))
+			})
+
+			// Given a port greater than 65535
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with port > 65535", func() {
+				// Arrange
+				cfg.Server.HTTPPort = 70000
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid http-port"))
+			})
+
+			// Given port 1 (minimum valid)
+			// When we validate the configuration
+			// Then validation should pass
+			It("should accept port 1", func() {
+				// Arrange
+				cfg.Server.HTTPPort = 1
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given port 65535 (maximum valid)
+			// When we validate the configuration
+			// Then validation should pass
+			It("should accept port 65535", func() {
+				// Arrange
+				cfg.Server.HTTPPort = 65535
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("num-workers validation", func() {
+			// Given a valid num-workers value
+			// When we validate the configuration
+			// Then validation should pass
+			It("should accept valid num-workers", func() {
+				// Arrange
+				cfg.Agent.NumWorkers = 5
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given num-workers set to 0
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with num-workers = 0", func() {
+				// Arrange
+				cfg.Agent.NumWorkers = 0
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid num-workers"))
+			})
+
+			// Given a negative num-workers value
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail with negative num-workers", func() {
+				// Arrange
+				cfg.Agent.NumWorkers = -1
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid num-workers"))
+			})
+		})
+
+		Context("authentication validation", func() {
+			// Given authentication is disabled
+			// When we validate the configuration
+			// Then validation should pass
+			It("should pass when authentication disabled", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.JWTFilePath = ""
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given authentication is enabled with jwt path
+			// When we validate the configuration
+			// Then validation should pass
+			It("should pass when authentication enabled with jwt path", func() {
+				// Arrange
+				cfg.Auth.Enabled = true
+				cfg.Auth.JWTFilePath = "/path/to/jwt"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given authentication is enabled with a jwt given directly
+			// When we validate the configuration
+			// Then validation should pass
+			It("should pass when authentication enabled with a jwt", func() {
+				// Arrange
+				cfg.Auth.Enabled = true
+				cfg.Auth.JWTFilePath = ""
+				cfg.Auth.JWT = "token"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given authentication is enabled without jwt path
+			// When we validate the configuration
+			// Then it should fail with appropriate error
+			It("should fail when authentication enabled without jwt path", func() {
+				// Arrange
+				cfg.Auth.Enabled = true
+				cfg.Auth.JWTFilePath = ""
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("authentication-jwt-filepath must be set"))
+			})
+
+			// Given a local api token shorter than 16 characters
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with a short local token", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.LocalToken = "short"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("invalid local api token: must be at least 16 characters"))
+			})
+
+			// Given a JWKS URL that is not an http URL
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with an invalid jwks url", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.JWKSURL = "ftp://sso.example.com/certs"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError(`invalid authentication-jwks-url "ftp://sso.example.com/certs": must be an http or https URL`))
+			})
+
+			// Given an audience without a JWKS URL
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with an audience without jwks url", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.Audience = "migration-planner"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("authentication-issuer and authentication-audience require authentication-jwks-url"))
+			})
+
+			// Given a JWKS URL without an audience
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with a jwks url without audience", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.JWKSURL = "https://sso.example.com/certs"
+				cfg.Auth.Issuer = "https://sso.example.com"
+				cfg.Auth.RoleClaim = "roles"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("authentication-issuer and authentication-audience must be set when authentication-jwks-url is set"))
+			})
+
+			// Given a JWKS URL without an issuer
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with a jwks url without issuer", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.JWKSURL = "https://sso.example.com/certs"
+				cfg.Auth.Audience = "migration-planner"
+				cfg.Auth.RoleClaim = "roles"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("authentication-issuer and authentication-audience must be set when authentication-jwks-url is set"))
+			})
+
+			// Given a JWKS URL with an issuer and an audience
+			// When we validate the configuration
+			// Then validation should pass
+			It("should pass with a jwks url", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.JWKSURL = "https://sso.example.com/certs"
+				cfg.Auth.Issuer = "https://sso.example.com"
+				cfg.Auth.Audience = "migration-planner"
+				cfg.Auth.RoleClaim = "roles"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given a local api token of 16 characters
+			// When we validate the configuration
+			// Then validation should pass
+			It("should pass with a long enough local token", func() {
+				// Arrange
+				cfg.Auth.Enabled = false
+				cfg.Auth.LocalToken = "0123456789abcdef"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			// Given an exempt path that is not absolute
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with a relative exempt path", func() {
+				// Arrange
+				cfg.Auth.ExemptPaths = []string{"/api/v1/version", "api/v1/agent"}
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError(`invalid authentication-exempt-paths entry "api/v1/agent": must be an absolute path`))
+			})
+
+			// Given the file secret backend without a keyring folder
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with the file secret backend without keyring folder", func() {
+				// Arrange
+				cfg.Agent.SecretBackend = "file"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("keyring-folder must be set when secret-backend is file"))
+			})
+
+			// Given an unknown secret backend
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with an unknown secret backend", func() {
+				// Arrange
+				cfg.Agent.SecretBackend = "vault"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError(`invalid secret backend "vault": must be "database" or "file"`))
+			})
+
+			// Given login throttling without a lockout duration
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with login throttling without lockout", func() {
+				// Arrange
+				cfg.Auth.MaxLoginFailures = 5
+				cfg.Auth.LoginLockout = 0
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("authentication-login-lockout must be positive when authentication-max-login-failures is set"))
+			})
+
+			// Given device login without a jwt path
+			// When we validate the configuration
+			// Then it should fail as the jwt cannot be persisted
+			It("should fail with device login without jwt path", func() {
+				// Arrange
+				cfg.Auth.Enabled = true
+				cfg.Auth.JWT = "token"
+				cfg.Auth.JWTFilePath = ""
+				cfg.Auth.DeviceLoginEnabled = true
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError("authentication-jwt-filepath must be set to persist the jwt of authentication-device-login"))
+			})
+
+			// Given device login with an invalid sso url
+			// When we validate the configuration
+			// Then it should fail
+			It("should fail with an invalid sso url", func() {
+				// Arrange
+				cfg.Auth.Enabled = true
+				cfg.Auth.JWTFilePath = "/path/to/jwt"
+				cfg.Auth.DeviceLoginEnabled = true
+				cfg.Auth.SSOURL = "sso.example.com"
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).To(MatchError(`invalid authentication-sso-url "sso.example.com": must be an http or https URL`))
+			})
+
+			// Given device login with a jwt path and the default sso settings
+			// When we validate the configuration
+			// Then validation should pass
+			It("should pass with device login", func() {
+				// Arrange
+				cfg.Auth.Enabled = true
+				cfg.Auth.JWTFilePath = "/path/to/jwt"
+				cfg.Auth.DeviceLoginEnabled = true
+
+				// Act
+				err := validateConfiguration(cfg)
+
+				// Assert
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Configuration File", func() {
+		var configFile string
+
+		BeforeEach(func() {
+			configFile = filepath.Join(GinkgoT().TempDir(), "agent.yaml")
+			Expect(os.WriteFile(configFile, []byte(`
+server:
+  httpPort: 9100
+  staticsFolder: /file/statics
+  corsAllowedOrigins: [http://file.example.com]
+agent:
+  numWorkers: 7
+`), 0o600)).To(Succeed())
+		})
+
+		// Given a configuration file and flags on the command line
+		// When we load the file
+		// Then the file values should be applied and the flags should win over them
+		It("should apply the file with flags taking precedence", func() {
+			// Arrange
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{
+				"--config-file", configFile,
+				"--server-http-port", "9200",
+				"--server-cors-allowed-origins", "http://flag.example.com",
+			})).To(Succeed())
+
+			// Act
+			err := loadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Server.HTTPPort).To(Equal(9200))
+			Expect(cfg.Server.CORSAllowedOrigins).To(Equal([]string{"http://flag.example.com"}))
+			Expect(cfg.Server.StaticsFolder).To(Equal("/file/statics"))
+			Expect(cfg.Agent.NumWorkers).To(Equal(7))
+			Expect(cfg.Server.ReadTimeout).To(Equal(5 * time.Minute))
+		})
+
+		// Given a configuration file, AMA_ variables and a flag
+		// When we load the configuration
+		// Then the variables should win over the file and the flag over both
+		It("should apply the environment over the file", func() {
+			// Arrange
+			GinkgoT().Setenv("AMA_AGENT_NUM_WORKERS", "9")
+			GinkgoT().Setenv("AMA_SERVER_HTTP_PORT", "9300")
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{
+				"--config-file", configFile,
+				"--server-http-port", "9200",
+			})).To(Succeed())
+
+			// Act
+			err := loadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Agent.NumWorkers).To(Equal(9))
+			Expect(cfg.Server.HTTPPort).To(Equal(9200))
+			Expect(cfg.Server.StaticsFolder).To(Equal("/file/statics"))
+		})
+
+		// Given authentication enabled with a JWT file path
+		// When we load the configuration
+		// Then the JWT should be read from the file
+		It("should resolve the agent's jwt", func() {
+			// Arrange
+			jwtFile := filepath.Join(GinkgoT().TempDir(), "jwt")
+			Expect(os.WriteFile(jwtFile, []byte("token\n"), 0o600)).To(Succeed())
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{
+				"--config-file", configFile,
+				"--authentication-enabled",
+				"--authentication-jwt-filepath", jwtFile,
+			})).To(Succeed())
+
+			// Act
+			err := loadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.JWT).To(Equal("token"))
+		})
+
+		// Given a configuration file, an AMA_ variable and flags
+		// When we print the resolved configuration
+		// Then each key should be printed with its source
+		It("should print the source of each value", func() {
+			// Arrange
+			GinkgoT().Setenv("AMA_AGENT_NUM_WORKERS", "9")
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{
+				"--config-file", configFile,
+				"--server-http-port", "9200",
+				"--server-cors-allowed-origins", "http://flag.example.com",
+			})).To(Succeed())
+			Expect(loadConfiguration(cmd.Flags(), cfg)).To(Succeed())
+			out := new(strings.Builder)
+
+			// Act
+			err := printResolvedConfig(out, cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out.String()).To(MatchRegexp(`server\.httpPort +9200 +flag\n`))
+			Expect(out.String()).To(MatchRegexp(`server\.corsAllowedOrigins +http://flag\.example\.com +flag\n`))
+			Expect(out.String()).To(MatchRegexp(`server\.staticsFolder +/file/statics +file\n`))
+			Expect(out.String()).To(MatchRegexp(`agent\.numWorkers +9 +env\n`))
+			Expect(out.String()).To(MatchRegexp(`server\.readTimeout +5m0s +default\n`))
+		})
+
+		// Given the prod profile and flags on the command line
+		// When we load the configuration
+		// Then the profile should fill the settings not set by the file or the flags
+		It("should apply the profile under the file and the flags", func() {
+			// Arrange
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{
+				"--config-file", configFile,
+				"--profile", "prod",
+				"--authentication-enabled=false",
+			})).To(Succeed())
+
+			// Act
+			err := loadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Server.ServerMode).To(Equal("prod"))
+			Expect(cfg.Server.StaticsFolder).To(Equal("/file/statics"))
+			Expect(cfg.Auth.Enabled).To(BeFalse())
+			Expect(cfg.LogLevel).To(Equal("info"))
+		})
+
+		// Given a configuration file that does not exist
+		// When we load it
+		// Then it should fail
+		It("should fail when the file does not exist", func() {
+			// Arrange
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{"--config-file", configFile + ".missing"})).To(Succeed())
+
+			// Act
+			err := loadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("failed to read config file")))
+		})
+	})
+
+	Describe("Configuration Reload", func() {
+		var configFile string
+
+		BeforeEach(func() {
+			cfg.Agent.ID = "550e8400-e29b-41d4-a716-446655440000"
+			cfg.Agent.SourceID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+			cfg.Auth.Enabled = false
+			configFile = filepath.Join(GinkgoT().TempDir(), "agent.yaml")
+			Expect(os.WriteFile(configFile, []byte(`
+logLevel: debug
+agent:
+  updateInterval: 10s
+console:
+  url: https://file.example.com
+`), 0o600)).To(Succeed())
+			cfg.ConfigFile = configFile
+		})
+
+		// Given a configuration file with new reloadable settings
+		// When we reload the configuration
+		// Then the new settings should be returned and cfg left untouched
+		It("should return the settings of the file", func() {
+			// Arrange
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{"--config-file", configFile})).To(Succeed())
+
+			// Act
+			next, err := reloadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.Reloadable()).To(Equal(config.Reloadable{
+				LogLevel:       "debug",
+				UpdateInterval: 10 * time.Second,
+				ConsoleURL:     "https://file.example.com",
+			}))
+			Expect(cfg.Console.URL).To(Equal("http://localhost:7443"))
+		})
+
+		// Given reloadable settings given by a flag
+		// When we reload the configuration
+		// Then the flag values should win over the file
+		It("should keep the values given by a flag", func() {
+			// Arrange
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{
+				"--config-file", configFile,
+				"--console-update-interval", "1m",
+			})).To(Succeed())
+
+			// Act
+			next, err := reloadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(next.Agent.UpdateInterval).To(Equal(time.Minute))
+			Expect(next.LogLevel).To(Equal("debug"))
+			Expect(next.Console.URL).To(Equal("https://file.example.com"))
+		})
+
+		// Given a configuration file with an invalid update interval
+		// When we reload the configuration
+		// Then it should fail validation
+		It("should fail when the new configuration is invalid", func() {
+			// Arrange
+			Expect(os.WriteFile(configFile, []byte("agent:\n  updateInterval: 0s\n"), 0o600)).To(Succeed())
+			cmd := NewRunCommand(cfg)
+			Expect(cmd.ParseFlags([]string{"--config-file", configFile})).To(Succeed())
+
+			// Act
+			_, err := reloadConfiguration(cmd.Flags(), cfg)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("invalid console-update-interval")))
+		})
+
+		// Given a remote configuration with a valid update interval and an unknown feature
+		// When we apply each of them
+		// Then the valid one should be set and the other rejected by the validation
+		It("should validate the remote configuration", func() {
+			// Arrange
+			valid := cfg.Clone()
+			invalid := cfg.Clone()
+
+			// Act
+			validErr := applyRemoteConfiguration(valid, config.Remote{UpdateInterval: "45s"})
+			invalidErr := applyRemoteConfiguration(invalid, config.Remote{Features: map[string]bool{"inspektor": true}})
+
+			// Assert
+			Expect(validErr).ToNot(HaveOccurred())
+			Expect(valid.Agent.UpdateInterval).To(Equal(45 * time.Second))
+			Expect(invalidErr).To(MatchError(ContainSubstring(`invalid remote configuration: unknown feature "inspektor"`)))
 		})
 	})
 })
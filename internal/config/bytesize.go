@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes, written as a number of bytes (1048576) or with
+// a decimal or binary unit (1MB, 512MiB).
+type ByteSize int64
+
+const (
+	KiB ByteSize = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+	TiB
+)
+
+// byteUnits are the units accepted by ParseByteSize, matched case-insensitively.
+var byteUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+}
+
+// ParseByteSize parses a non-negative whole number of bytes followed by an
+// optional unit. Fractions, negative sizes and unknown units are rejected.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i == -1 {
+		i = len(s)
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid size %q: must start with a number", s)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, strings.TrimSpace(s[i:]))
+	}
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil || n > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("invalid size %q: out of range", s)
+	}
+	return ByteSize(n) * unit, nil
+}
+
+// String formats b with the largest binary unit dividing it, 64MiB for
+// 67108864, and in bytes otherwise.
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		size ByteSize
+		name string
+	}{{TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"}} {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
+
+// UnmarshalText parses the sizes of the configuration file, the environment
+// and the defaults.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// Set implements pflag.Value for the size flags.
+func (b *ByteSize) Set(s string) error {
+	return b.UnmarshalText([]byte(s))
+}
+
+// Type implements pflag.Value.
+func (b *ByteSize) Type() string {
+	return "byteSize"
+}
@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("ByteSize", func() {
+	// Given sizes in bytes and with decimal and binary units
+	// When we parse them
+	// Then they should be converted to bytes
+	It("parses sizes with units", func() {
+		// Arrange
+		sizes := map[string]config.ByteSize{
+			"1024":    1024,
+			"512B":    512,
+			"512MiB":  512 * config.MiB,
+			"2MB":     2000000,
+			" 1 gib ": config.GiB,
+			"0":       0,
+		}
+
+		for input, expected := range sizes {
+			// Act
+			size, err := config.ParseByteSize(input)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred(), input)
+			Expect(size).To(Equal(expected), input)
+		}
+	})
+
+	// Given empty, negative, fractional, misspelled and huge sizes
+	// When we parse them
+	// Then they should be rejected
+	It("rejects invalid sizes", func() {
+		// Arrange
+		sizes := map[string]string{
+			"":           "must start with a number",
+			"-1MiB":      "must start with a number",
+			"1.5GiB":     "unknown unit",
+			"10MiBs":     "unknown unit",
+			"9999999TiB": "out of range",
+		}
+
+		for input, message := range sizes {
+			// Act
+			_, err := config.ParseByteSize(input)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring(message)), input)
+		}
+	})
+
+	// Given sizes of whole and partial binary units
+	// When we format them
+	// Then whole units should use the largest unit and others bytes
+	It("formats sizes with the largest binary unit", func() {
+		// Assert
+		Expect((64 * config.MiB).String()).To(Equal("64MiB"))
+		Expect((1536 * config.KiB).String()).To(Equal("1536KiB"))
+		Expect(config.ByteSize(1000).String()).To(Equal("1000"))
+		Expect(config.ByteSize(0).String()).To(Equal("0"))
+	})
+
+	// Given the default configuration
+	// When we read the sizes
+	// Then the unit defaults should be parsed
+	It("parses the defaults", func() {
+		// Act
+		cfg := config.NewConfigurationWithOptionsAndDefaults()
+
+		// Assert
+		Expect(cfg.Server.MaxRequestBodySize).To(Equal(64 * config.MiB))
+		Expect(cfg.Server.AccessLogMaxSize).To(Equal(100 * config.MiB))
+	})
+})
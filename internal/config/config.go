@@ -9,42 +9,219 @@ const (
 	ServerModeDev  ServerModeType = "dev"
 )
 
-//go:generate go run github.com/ecordell/optgen -output zz_generated.configuration.go . Configuration Server Agent Console Authentication
+// SecretBackendType is where the agent keeps its secrets, such as the vCenter credentials.
+type SecretBackendType string
+
+const (
+	// SecretBackendDatabase encrypts the secrets in the database with the key of SecretKeyFilePath
+	SecretBackendDatabase SecretBackendType = "database"
+	// SecretBackendFile keeps each secret in a file of KeyringFolder, out of the database
+	SecretBackendFile SecretBackendType = "file"
+)
+
+// UpdateRestartType is how the agent restarts into an installed update.
+type UpdateRestartType string
+
+const (
+	// UpdateRestartExec replaces the process of the agent with the updated binary
+	UpdateRestartExec UpdateRestartType = "exec"
+	// UpdateRestartExit exits with a failure status so the service manager restarts the agent
+	UpdateRestartExit UpdateRestartType = "exit"
+)
+
+//go:generate go run github.com/ecordell/optgen -output zz_generated.configuration.go . Configuration Server Agent Console Authentication Proxy Tracing
 type Configuration struct {
-	Server  Server         `debugmap:"visible"`
-	Agent   Agent          `debugmap:"visible"`
-	Auth    Authentication `debugmap:"visible"`
-	Console Console        `debugmap:"visible"`
+	Server  Server         `yaml:"server" debugmap:"visible"`
+	Agent   Agent          `yaml:"agent" debugmap:"visible"`
+	Auth    Authentication `yaml:"auth" debugmap:"visible"`
+	Console Console        `yaml:"console" debugmap:"visible"`
+	Proxy   Proxy          `yaml:"proxy" debugmap:"visible"`
+	Tracing Tracing        `yaml:"tracing" debugmap:"visible"`
+
+	// Features enables experimental subsystems by name, e.g. inspector: true. See IsEnabled
+	Features map[string]bool `yaml:"features" debugmap:"visible"`
 
 	// Log
-	LogFormat string `debugmap:"visible"`
-	LogLevel  string `debugmap:"visible"`
+	LogFormat string `yaml:"logFormat" debugmap:"visible"`
+	LogLevel  string `yaml:"logLevel" debugmap:"visible"`
+	// LogLevels overrides LogLevel per component (named logger), e.g. store: debug
+	LogLevels map[string]string `yaml:"logLevels" debugmap:"visible"`
+	// LogFile also receives the application logs, rotated by size and age. Disabled when empty
+	LogFile           string        `yaml:"logFile" debugmap:"visible"`
+	LogFileMaxSize    ByteSize      `yaml:"logFileMaxSize" debugmap:"visible" default:"100MiB"`
+	LogFileMaxAge     time.Duration `yaml:"logFileMaxAge" debugmap:"visible" default:"168h"`
+	LogFileMaxBackups int           `yaml:"logFileMaxBackups" debugmap:"visible" default:"5"`
+
+	// Profile selects the dev or prod defaults applied by ApplyProfile
+	Profile string `yaml:"profile" debugmap:"visible"`
+
+	// ConfigFile is the YAML or TOML file loaded by LoadFromFile, flags override its values
+	ConfigFile string `yaml:"-" debugmap:"visible"`
+
+	// sources records the keys not set by the defaults, see ResolvedConfig
+	sources map[string]Source
 }
 
 type Server struct {
-	ServerMode    string `debugmap:"visible" default:"dev"`
-	HTTPPort      int    `debugmap:"visible" default:"8000"`
-	StaticsFolder string `debugmap:"visible"`
+	ServerMode    string `yaml:"mode" debugmap:"visible" default:"dev"`
+	HTTPPort      int    `yaml:"httpPort" debugmap:"visible" default:"8000"`
+	StaticsFolder string `yaml:"staticsFolder" debugmap:"visible"`
+	// UnixSocketPath serves the API over plain HTTP on a unix socket, next to the TCP port or instead of it
+	UnixSocketPath string `yaml:"unixSocketPath" debugmap:"visible"`
+	UnixSocketOnly bool   `yaml:"unixSocketOnly" debugmap:"visible"`
+	TLSCertFile    string `yaml:"tlsCertFile" debugmap:"visible"`
+	TLSKeyFile     string `yaml:"tlsKeyFile" debugmap:"visible"`
+	// ClientCAFile enables mTLS on /api routes: clients must present a certificate signed by one of its CAs
+	ClientCAFile string `yaml:"clientCAFile" debugmap:"visible"`
+	// ACME provisioning, enabled when ACMEDomains is not empty
+	ACMEDomains      []string `yaml:"acmeDomains" debugmap:"visible"`
+	ACMEEmail        string   `yaml:"acmeEmail" debugmap:"visible"`
+	ACMEDirectoryURL string   `yaml:"acmeDirectoryURL" debugmap:"visible"`
+	ACMEHTTPPort     int      `yaml:"acmeHTTPPort" debugmap:"visible" default:"80"`
+	// CORS on /api, enabled when CORSAllowedOrigins is not empty
+	CORSAllowedOrigins []string `yaml:"corsAllowedOrigins" debugmap:"visible"`
+	CORSAllowedMethods []string `yaml:"corsAllowedMethods" debugmap:"visible"`
+	CORSAllowedHeaders []string `yaml:"corsAllowedHeaders" debugmap:"visible"`
+	// Per client IP rate limiting of /api, disabled when RateLimitRPS is 0
+	RateLimitRPS         float64  `yaml:"rateLimitRPS" debugmap:"visible" default:"20"`
+	RateLimitBurst       int      `yaml:"rateLimitBurst" debugmap:"visible" default:"50"`
+	RateLimitExemptPaths []string `yaml:"rateLimitExemptPaths" debugmap:"visible" default:"[\"/api/v1/agent\",\"/api/v1/version\"]"`
+	// TrustedProxies are the addresses or CIDRs of the reverse proxies whose X-Forwarded-For names the client
+	// of the rate limiter and the login throttle. None by default, the client being the peer of the connection
+	TrustedProxies []string `yaml:"trustedProxies" debugmap:"visible"`
+	// Gzip compression of JSON responses of at least CompressionMinSize bytes
+	CompressionEnabled bool `yaml:"compressionEnabled" debugmap:"visible" default:"true"`
+	CompressionMinSize int  `yaml:"compressionMinSize" debugmap:"visible" default:"1024"`
+	// Connection timeouts (0 means no timeout) and request body limit (0 means unlimited)
+	ReadHeaderTimeout  time.Duration `yaml:"readHeaderTimeout" debugmap:"visible" default:"10s"`
+	ReadTimeout        time.Duration `yaml:"readTimeout" debugmap:"visible" default:"5m"`
+	WriteTimeout       time.Duration `yaml:"writeTimeout" debugmap:"visible" default:"5m"`
+	IdleTimeout        time.Duration `yaml:"idleTimeout" debugmap:"visible" default:"2m"`
+	MaxRequestBodySize ByteSize      `yaml:"maxRequestBodySize" debugmap:"visible" default:"64MiB"`
+	// PprofEnabled mounts the net/http/pprof handlers under /debug/pprof
+	PprofEnabled bool `yaml:"pprofEnabled" debugmap:"visible" default:"false"`
+	// MetricsEnabled serves the Prometheus metrics of the agent under /metrics
+	MetricsEnabled bool `yaml:"metricsEnabled" debugmap:"visible" default:"true"`
+	// JSON access log written to AccessLogFile, rotated by size and age. Disabled when AccessLogFile is empty
+	AccessLogFile       string        `yaml:"accessLogFile" debugmap:"visible"`
+	AccessLogMaxSize    ByteSize      `yaml:"accessLogMaxSize" debugmap:"visible" default:"100MiB"`
+	AccessLogMaxAge     time.Duration `yaml:"accessLogMaxAge" debugmap:"visible" default:"168h"`
+	AccessLogMaxBackups int           `yaml:"accessLogMaxBackups" debugmap:"visible" default:"5"`
+	// Admin listener serving /admin and /debug/pprof apart from the API, disabled when both are empty
+	AdminPort           int    `yaml:"adminPort" debugmap:"visible"`
+	AdminUnixSocketPath string `yaml:"adminUnixSocketPath" debugmap:"visible"`
 }
 
 type Agent struct {
-	Mode                string        `debugmap:"visible" default:"disconnected"`
-	ID                  string        `debugmap:"visible"`
-	SourceID            string        `debugmap:"visible"`
-	Version             string        `debugmap:"visible" default:"v0.0.0"`
-	GitCommit           string        `debugmap:"visible" default:"unknown"`
-	NumWorkers          int           `debugmap:"visible" default:"3"`
-	DataFolder          string        `debugmap:"visible"`
-	OpaPoliciesFolder   string        `debugmap:"visible"`
-	UpdateInterval      time.Duration `debugmap:"visible" default:"5s"`
-	LegacyStatusEnabled bool          `debugmap:"visible" default:"true"`
+	Mode                string        `yaml:"mode" debugmap:"visible" default:"disconnected"`
+	ID                  string        `yaml:"id" debugmap:"visible"`
+	SourceID            string        `yaml:"sourceID" debugmap:"visible"`
+	Version             string        `yaml:"-" debugmap:"visible" default:"v0.0.0"`
+	GitCommit           string        `yaml:"-" debugmap:"visible" default:"unknown"`
+	NumWorkers          int           `yaml:"numWorkers" debugmap:"visible" default:"3"`
+	DataFolder          string        `yaml:"dataFolder" debugmap:"visible"`
+	OpaPoliciesFolder   string        `yaml:"opaPoliciesFolder" debugmap:"visible"`
+	UpdateInterval      time.Duration `yaml:"updateInterval" debugmap:"visible" default:"5s"`
+	LegacyStatusEnabled bool          `yaml:"legacyStatusEnabled" debugmap:"visible" default:"true"`
+	// PolicyBundleURL locates the OPA policy bundle of the agent, usually set by the remote configuration
+	PolicyBundleURL string `yaml:"policyBundleURL" debugmap:"visible"`
+	// UpdateChannelURL locates the update manifest of the agent, usually set by the remote configuration. The
+	// binaries it points to are installed once verified with UpdatePublicKey, a base64 ed25519 public key, then
+	// the agent restarts into them: UpdateRestart is exec to replace the process, exit to let systemd restart it
+	UpdateChannelURL string `yaml:"updateChannelURL" debugmap:"visible"`
+	UpdatePublicKey  string `yaml:"updatePublicKey" debugmap:"visible"`
+	UpdateRestart    string `yaml:"updateRestart" debugmap:"visible" default:"exec"`
+	// ResumeCollection keeps the vCenter credentials of a collection in SecretBackend until it completes or
+	// fails, to start it again after a restart. Otherwise the credentials only live in memory
+	ResumeCollection bool `yaml:"resumeCollection" debugmap:"visible"`
+	// SecretBackend keeps the secrets of the agent, database or file. The key of the database backend is
+	// read from SecretKeyFilePath, by default secrets.key of DataFolder, and generated on first use
+	SecretBackend     string `yaml:"secretBackend" debugmap:"visible" default:"database"`
+	SecretKeyFilePath string `yaml:"secretKeyFilePath" debugmap:"visible"`
+	KeyringFolder     string `yaml:"keyringFolder" debugmap:"visible"`
+	// ShutdownTimeout bounds the graceful shutdown, the components still stopping at its end being abandoned
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" debugmap:"visible" default:"30s"`
+	// QueryExplainThreshold logs the EXPLAIN ANALYZE plan of the list queries slower than it, disabled when 0
+	QueryExplainThreshold time.Duration `yaml:"queryExplainThreshold" debugmap:"visible"`
+	// ErrorWebhookURL receives the panics, fatal console errors and failed collections of the agent, disabled
+	// when empty. It is redacted as such URLs often carry a token
+	ErrorWebhookURL string `yaml:"errorWebhookURL" debugmap:"hidden"`
+	// HALeaseFile pairs two agents of the same source in high availability, disabled when empty: the file,
+	// on a storage both mount, holds the lease of the leader, renewed every third of HALeaseDuration. The
+	// lease is only kept in a file, a pair requires that shared storage.
+	// HAIdentity names the agent in the lease, by default its hostname
+	HALeaseFile     string        `yaml:"haLeaseFile" debugmap:"visible"`
+	HALeaseDuration time.Duration `yaml:"haLeaseDuration" debugmap:"visible" default:"15s"`
+	HAIdentity      string        `yaml:"haIdentity" debugmap:"visible"`
+	// AssessmentTrustedKeys are the fingerprints of the keys whose assessment bundles the agent imports,
+	// none being imported when empty
+	AssessmentTrustedKeys []string `yaml:"assessmentTrustedKeys" debugmap:"visible"`
 }
 
 type Console struct {
-	URL string `debugmap:"visible" default:"http://localhost:7443"`
+	URL string `yaml:"url" debugmap:"visible" default:"http://localhost:7443"`
+	// Remote configuration pulled from the console at startup and every RemoteConfigInterval, see ApplyRemote
+	RemoteConfigEnabled  bool          `yaml:"remoteConfigEnabled" debugmap:"visible" default:"false"`
+	RemoteConfigInterval time.Duration `yaml:"remoteConfigInterval" debugmap:"visible" default:"15m"`
+	// CommandChannelEnabled keeps a websocket open to the console in connected mode, through which it pushes
+	// commands and receives the status of the agent as it changes
+	CommandChannelEnabled bool `yaml:"commandChannelEnabled" debugmap:"visible" default:"false"`
 }
 
 type Authentication struct {
-	Enabled     bool   `debugmap:"visible" default:"true"`
-	JWTFilePath string `debugmap:"visible"`
+	Enabled     bool   `yaml:"enabled" debugmap:"visible" default:"true"`
+	JWTFilePath string `yaml:"jwtFilePath" debugmap:"visible"`
+	// JWT is the agent's token. ResolveSecrets reads it from JWTFilePath when not set directly
+	JWT string `yaml:"jwt" debugmap:"hidden"`
+	// LocalToken is the bearer token required by the requests of the local API, disabled when empty.
+	// ResolveSecrets reads it from LocalTokenFilePath when not set directly
+	LocalToken         string `yaml:"localToken" debugmap:"hidden"`
+	LocalTokenFilePath string `yaml:"localTokenFilePath" debugmap:"visible"`
+	// ProvisioningToken registers with the console an agent deployed without Agent.ID and Agent.SourceID.
+	// ResolveSecrets reads it from ProvisioningTokenFilePath when not set directly
+	ProvisioningToken         string `yaml:"provisioningToken" debugmap:"hidden"`
+	ProvisioningTokenFilePath string `yaml:"provisioningTokenFilePath" debugmap:"visible"`
+	// JWKSURL enables the JWTs as bearer tokens of the local API, signed by a key it serves.
+	// Issuer and Audience are required with it and checked in every token, a
+	// shared identity provider issuing tokens for many clients
+	JWKSURL  string `yaml:"jwksURL" debugmap:"visible"`
+	Issuer   string `yaml:"issuer" debugmap:"visible"`
+	Audience string `yaml:"audience" debugmap:"visible"`
+	// RoleClaim is the claim, or dotted path to a nested claim, granting the operator role when it names it
+	RoleClaim string `yaml:"roleClaim" debugmap:"visible" default:"roles"`
+	// ExemptPaths of /api, /admin, /metrics and /debug/pprof are served without credentials when the local
+	// API requires them, exact paths or prefixes ending with *
+	ExemptPaths []string `yaml:"exemptPaths" debugmap:"visible" default:"[\"/api/v1/agent\",\"/api/v1/version\",\"/metrics\"]"`
+	// MaxLoginFailures is the number of invalid credentials after which a client IP is locked out of the local
+	// API for LoginLockout, each failure before doubling its wait from one second. Disabled when 0
+	MaxLoginFailures int           `yaml:"maxLoginFailures" debugmap:"visible" default:"10"`
+	LoginLockout     time.Duration `yaml:"loginLockout" debugmap:"visible" default:"15m"`
+	// DeviceLoginEnabled lets the user log in to SSOURL with the OAuth device flow of SSOClientID
+	// to obtain the agent's JWT, written to JWTFilePath
+	DeviceLoginEnabled bool   `yaml:"deviceLoginEnabled" debugmap:"visible" default:"false"`
+	SSOURL             string `yaml:"ssoURL" debugmap:"visible" default:"https://sso.redhat.com/auth/realms/redhat-external"`
+	SSOClientID        string `yaml:"ssoClientID" debugmap:"visible" default:"ocm-cli"`
+}
+
+// Proxy routes the requests of the agent to the console and to vCenter. When
+// HTTPProxy, HTTPSProxy and NoProxy are empty the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables are used.
+type Proxy struct {
+	HTTPProxy  string   `yaml:"httpProxy" debugmap:"visible"`
+	HTTPSProxy string   `yaml:"httpsProxy" debugmap:"visible"`
+	NoProxy    []string `yaml:"noProxy" debugmap:"visible"`
+	// Per target overrides: a proxy URL, or ProxyDirect to bypass the proxy
+	ConsoleProxy string `yaml:"consoleProxy" debugmap:"visible"`
+	VCenterProxy string `yaml:"vcenterProxy" debugmap:"visible"`
+}
+
+// Tracing exports OpenTelemetry traces of the API requests, the store queries
+// and the vCenter and console calls to an OTLP/HTTP collector. Tracing is off
+// when Endpoint is empty.
+type Tracing struct {
+	// Endpoint is the OTLP/HTTP URL of the collector, e.g. http://localhost:4318
+	Endpoint string `yaml:"endpoint" debugmap:"visible"`
+	// SampleRatio is the fraction of the new traces recorded, between 0 and 1
+	SampleRatio float64 `yaml:"sampleRatio" debugmap:"visible" default:"1"`
+	ServiceName string  `yaml:"serviceName" debugmap:"visible" default:"assisted-migration-agent"`
 }
@@ -10,8 +10,14 @@
 //	├── Agent          - Agent behavior and identity
 //	├── Console        - Console.redhat.com connection
 //	├── Auth           - Authentication settings
+//	├── Proxy          - Outbound proxies to the console and vCenter
+//	├── Tracing        - OpenTelemetry traces export
+//	├── Features       - Experimental subsystems enabled by name
+//	├── Profile        - dev or prod defaults
 //	├── LogFormat      - Logging format
-//	└── LogLevel       - Logging verbosity
+//	├── LogLevel       - Logging verbosity
+//	├── LogLevels      - Logging verbosity per component (named logger)
+//	└── LogFile...     - Rotated file receiving the logs next to stdout
 //
 // # Server Configuration
 //
@@ -21,6 +27,41 @@
 //	│ ServerMode       │ "dev"   │ Server mode: "prod" or "dev"           │
 //	│ HTTPPort         │ 8000    │ HTTP server listen port                │
 //	│ StaticsFolder    │ ""      │ Path to static files for UI            │
+//	│ UnixSocketPath   │ ""      │ Unix socket serving the API over HTTP  │
+//	│ UnixSocketOnly   │ false   │ Do not listen on HTTPPort              │
+//	│ TLSCertFile      │ ""      │ PEM certificate served in prod mode    │
+//	│ TLSKeyFile       │ ""      │ PEM private key of TLSCertFile         │
+//	│ ClientCAFile     │ ""      │ CAs of client certificates for /api    │
+//	│ ACMEDomains      │ []      │ Domains to get ACME certificates for   │
+//	│ ACMEEmail        │ ""      │ ACME account contact email             │
+//	│ ACMEDirectoryURL │ ""      │ ACME directory (Let's Encrypt if "")   │
+//	│ ACMEHTTPPort     │ 80      │ HTTP-01 challenge and redirect port    │
+//	│ CORSAllowed...   │ []      │ CORS origins, methods and headers      │
+//	│ TrustedProxies   │ []      │ Proxies whose X-Forwarded-For is read  │
+//	│ RateLimitRPS     │ 20      │ API requests/s per client IP (0 = off) │
+//	│ RateLimitBurst   │ 50      │ Burst above RateLimitRPS               │
+//	│ RateLimit...     │ agent,  │ API paths never rate limited           │
+//	│   ExemptPaths    │ version │                                        │
+//	│ Compression...   │ true    │ Gzip JSON responses                    │
+//	│   Enabled        │         │                                        │
+//	│ Compression...   │ 1024    │ Minimum response size to compress      │
+//	│   MinSize        │         │                                        │
+//	│ ReadHeaderTimeout│ 10s     │ Max time to read request headers       │
+//	│ ReadTimeout      │ 5m      │ Max time to read a whole request       │
+//	│ WriteTimeout     │ 5m      │ Max time to write a response           │
+//	│ IdleTimeout      │ 2m      │ Keep-alive idle connection timeout     │
+//	│ MaxRequest...    │ 64MiB   │ Max request body size (0 = unlimited)  │
+//	│   BodySize       │         │                                        │
+//	│ AccessLogFile    │ ""      │ JSON access log file (off if "")       │
+//	│ AccessLogMaxSize │ 100MiB  │ Rotate the access log at this size     │
+//	│ AccessLogMaxAge  │ 168h    │ Remove rotated access logs after       │
+//	│ AccessLog...     │ 5       │ Rotated access logs kept               │
+//	│   MaxBackups     │         │                                        │
+//	│ PprofEnabled     │ false   │ Expose /debug/pprof                    │
+//	│ MetricsEnabled   │ true    │ Expose the Prometheus /metrics         │
+//	│ AdminPort        │ 0       │ Admin listener port (0 = API port)     │
+//	│ AdminUnix...     │ ""      │ Unix socket of the admin listener      │
+//	│   SocketPath     │         │                                        │
 //	└──────────────────┴─────────┴────────────────────────────────────────┘
 //
 // Server modes:
@@ -41,28 +82,84 @@
 //	│ OpaPoliciesFolder   │ ""             │ Path to OPA policy files             │
 //	│ UpdateInterval      │ 5s             │ Console update frequency             │
 //	│ LegacyStatusEnabled │ true           │ Use v1 agent status values           │
+//	│ PolicyBundleURL     │ ""             │ OPA policy bundle, usually remote    │
+//	│ UpdateChannelURL    │ ""             │ Update manifest, usually remote      │
+//	│ UpdatePublicKey     │ ""             │ ed25519 key verifying the updates    │
+//	│ UpdateRestart       │ "exec"         │ Restart into an update: exec or exit │
+//	│ ResumeCollection    │ false          │ Store vCenter creds to resume        │
+//	│ SecretBackend       │ "database"     │ Secret store: database or file       │
+//	│ SecretKeyFilePath   │ (1)            │ Key of the encrypted secrets table   │
+//	│ KeyringFolder       │ ""             │ Folder of the file secret store      │
+//	│ ShutdownTimeout     │ 30s            │ Deadline of the graceful shutdown    │
+//	│ QueryExplainThr...  │ 0              │ Log plans of slower list queries     │
+//	│ ErrorWebhookURL     │ ""             │ Receiver of the error reports (2)    │
+//	│ AssessmentTrust...  │ []             │ Keys signing imported assessments    │
+//	│ HALeaseFile         │ ""             │ Shared lease file, disabled if empty │
+//	│ HALeaseDuration     │ 15s            │ Lease duration, at least 3s          │
+//	│ HAIdentity          │ hostname       │ Name of the agent in the lease       │
 //	└─────────────────────┴────────────────┴──────────────────────────────────────┘
 //
+// (1) secrets.key of DataFolder, generated on first use. Without DataFolder
+// the database is in memory and so is the key.
+//
+// (2) Hidden from the DebugMap, such URLs often carrying a token.
+//
 // Agent modes:
 //   - connected: Agent sends updates to console.redhat.com
 //   - disconnected: Agent operates in standalone mode
 //
 // # Console Configuration
 //
-//	┌───────┬─────────────────────────┬────────────────────────────────────────┐
-//	│ Field │ Default                 │ Description                            │
-//	├───────┼─────────────────────────┼────────────────────────────────────────┤
-//	│ URL   │ "http://localhost:7443" │ Console API base URL                   │
-//	└───────┴─────────────────────────┴────────────────────────────────────────┘
+//	┌───────────────────────┬─────────────────────────┬─────────────────────────────────┐
+//	│ Field                 │ Default                 │ Description                     │
+//	├───────────────────────┼─────────────────────────┼─────────────────────────────────┤
+//	│ URL                   │ "http://localhost:7443" │ Console API base URL            │
+//	│ RemoteConfigEnabled   │ false                   │ Pull the Remote configuration   │
+//	│ RemoteConfigInterval  │ 15m                     │ Interval between Remote pulls   │
+//	│ CommandChannelEnabled │ false                   │ Open the command channel        │
+//	└───────────────────────┴─────────────────────────┴─────────────────────────────────┘
 //
 // # Authentication Configuration
 //
-//	┌─────────────┬─────────┬────────────────────────────────────────┐
-//	│ Field       │ Default │ Description                            │
-//	├─────────────┼─────────┼────────────────────────────────────────┤
-//	│ Enabled     │ true    │ Enable JWT authentication              │
-//	│ JWTFilePath │ ""      │ Path to JWT token file                 │
-//	└─────────────┴─────────┴────────────────────────────────────────┘
+//	┌───────────────────────────┬─────────┬──────────────────────────────────────────┐
+//	│ Field                     │ Default │ Description                              │
+//	├───────────────────────────┼─────────┼──────────────────────────────────────────┤
+//	│ Enabled                   │ true    │ Enable JWT authentication                │
+//	│ JWTFilePath               │ ""      │ Path to JWT token file                   │
+//	│ JWT                       │ ""      │ JWT token, read from JWTFilePath         │
+//	│ LocalTokenFilePath        │ ""      │ Path to the local API token file         │
+//	│ LocalToken                │ ""      │ Local API token, read from the file      │
+//	│ ProvisioningTokenFilePath │ ""      │ Path to the provisioning token file      │
+//	│ ProvisioningToken         │ ""      │ Registers an agent without ids           │
+//	│ JWKSURL                   │ ""      │ Keys of the JWTs accepted by local API   │
+//	│ Issuer                    │ ""      │ Issuer required in those JWTs            │
+//	│ Audience                  │ ""      │ Audience required in those JWTs          │
+//	│ RoleClaim                 │ roles   │ Claim granting the operator role         │
+//	│ ExemptPaths               │ (2)     │ Local API paths served without creds     │
+//	│ MaxLoginFailures          │ 10      │ Failures locking a client IP out         │
+//	│ LoginLockout              │ 15m     │ Duration of the lockout                  │
+//	│ DeviceLoginEnabled        │ false   │ Obtain the JWT with a device login       │
+//	│ SSOURL                    │ (1)     │ Keycloak realm of the device login       │
+//	│ SSOClientID               │ ocm-cli │ OAuth client of the device login         │
+//	└───────────────────────────┴─────────┴──────────────────────────────────────────┘
+//
+// (1) https://sso.redhat.com/auth/realms/redhat-external. A device login
+// writes the JWT to JWTFilePath, which may not exist before the first login.
+//
+// (2) /api/v1/agent, /api/v1/version and /metrics, so the probes and the
+// scrapes of an orchestrator do not need credentials. A trailing * matches every path with that prefix.
+//
+// # Secrets
+//
+// Secret fields are tagged `debugmap:"hidden"`, so DebugMap leaves them out.
+// Besides its AMA_ variable, each one is read from the file named by the
+// variable with a _FILE suffix (AMA_AUTH_JWT_FILE), e.g. a mounted secret;
+// setting both fails the load. ResolveSecrets then reads the JWT from
+// JWTFilePath, the local API token from LocalTokenFilePath and the provisioning
+// token from ProvisioningTokenFilePath when they were not given. When
+// JWTFromFile, WatchSecretFile polls JWTFilePath so a rotated JWT replaces the
+// one sent to the console without a restart. TLS keys are never held in the
+// configuration: the server reads them from TLSKeyFile.
 //
 // # Code Generation
 //
@@ -100,6 +197,212 @@
 //	    config.WithHTTPPort(9000),
 //	)
 //
+// # Configuration File
+//
+// LoadFromFile reads a YAML file, or a TOML one when its extension is .toml,
+// onto an existing Configuration, usually the one holding the defaults. Keys
+// come from the yaml struct tags (server.httpPort, agent.dataFolder...),
+// durations are written as "30s" or "5m", and keys absent from the file keep
+// their current value. Unknown keys fail the load, with their line for YAML:
+//
+//	invalid config file agent.yaml: yaml: unmarshal errors:
+//	  line 2: field httpPrt not found in type config.Server
+//
+// Agent.Version and Agent.GitCommit are build information and cannot be set
+// from a file. The run command loads ConfigFile before validation and then
+// re-applies the flags and environment variables that were set, so they take
+// precedence over the file.
+//
+// # Environment Variables
+//
+// LoadFromEnv applies the EnvPrefix (AMA_) environment variables over a
+// Configuration. Names are derived from the yaml keys in SCREAMING_SNAKE_CASE,
+// section first:
+//
+//	┌────────────────────────────────┬──────────────────────────┐
+//	│ Variable                       │ Field                    │
+//	├────────────────────────────────┼──────────────────────────┤
+//	│ AMA_SERVER_HTTP_PORT           │ Server.HTTPPort          │
+//	│ AMA_SERVER_CLIENT_CA_FILE      │ Server.ClientCAFile      │
+//	│ AMA_SERVER_READ_TIMEOUT=30s    │ Server.ReadTimeout       │
+//	│ AMA_AGENT_MODE                 │ Agent.Mode               │
+//	│ AMA_AUTH_ENABLED=false         │ Auth.Enabled             │
+//	│ AMA_LOG_LEVEL                  │ LogLevel                 │
+//	└────────────────────────────────┴──────────────────────────┘
+//
+// Lists are comma separated. A value that does not parse fails the load with
+// the variable name. The run command applies them after ConfigFile and before
+// re-applying the flags, whose own AGENT_ variables are bound by cobraflags.
+//
+// # Durations and Sizes
+//
+// Durations are parsed by time.ParseDuration and need a unit: a YAML or TOML
+// number is an error rather than nanoseconds. Sizes are ByteSize values, a
+// number of bytes or a number with a unit parsed by ParseByteSize:
+//
+//	maxRequestBodySize: 512MiB   # KiB, MiB, GiB, TiB: powers of 1024
+//	accessLogMaxSize: 100MB      # KB, MB, GB, TB: powers of 1000
+//
+// The run command rejects the durations and sizes outside of their range.
+//
+// # Profiles
+//
+// Profile selects defaults bundled for a deployment. ApplyProfile sets them
+// on the keys no other source set, after the file, the environment and the flags:
+//
+//	┌──────────────────────┬─────────┬─────────────┐
+//	│ Key                  │ dev     │ prod        │
+//	├──────────────────────┼─────────┼─────────────┤
+//	│ server.mode          │ dev     │ prod        │
+//	│ server.staticsFolder │         │ /app/static │
+//	│ auth.enabled         │ false   │ true        │
+//	│ logLevel             │ debug   │ info        │
+//	└──────────────────────┴─────────┴─────────────┘
+//
+// Precedence, from lowest to highest: defaults, profile, ConfigFile, AMA_
+// variables, flags.
+//
+// # Resolved Configuration
+//
+// LoadFromFile and LoadFromEnv record the keys they set, and MarkSource the
+// ones set by a flag (the run command matches the variable bound to each flag
+// set). ResolvedConfig then lists every key with its value and source:
+//
+//	┌──────────────────────┬─────────────┬─────────┐
+//	│ Key                  │ Value       │ Source  │
+//	├──────────────────────┼─────────────┼─────────┤
+//	│ server.httpPort      │ 9200        │ flag    │
+//	│ server.staticsFolder │ /www        │ file    │
+//	│ agent.numWorkers     │ 9           │ env     │
+//	│ server.readTimeout   │ 5m0s        │ default │
+//	│ auth.jwt             │ (sensitive) │ env     │
+//	└──────────────────────┴─────────────┴─────────┘
+//
+// Secrets are rendered like in DebugMap. It backs `agent run --print-config`
+// and GET /admin/config.
+//
+// # Proxy Configuration
+//
+// Proxy routes the requests of the console client and of the vCenter clients
+// (credentials check, inventory extras, inspection). Proxy.ProxyFunc gives the
+// proxy function of the transport of each target:
+//
+//	┌──────────────┬─────────┬─────────────────────────────────────────────┐
+//	│ Field        │ Default │ Description                                 │
+//	├──────────────┼─────────┼─────────────────────────────────────────────┤
+//	│ HTTPProxy    │ ""      │ Proxy of http:// requests                   │
+//	│ HTTPSProxy   │ ""      │ Proxy of https:// requests                  │
+//	│ NoProxy      │ []      │ Hosts, domains and CIDRs reached directly   │
+//	│ ConsoleProxy │ ""      │ Proxy of the console, "direct" for none     │
+//	│ VCenterProxy │ ""      │ Proxy of the vCenter, "direct" for none     │
+//	└──────────────┴─────────┴─────────────────────────────────────────────┘
+//
+// A target override wins over HTTPProxy, HTTPSProxy and NoProxy. With none of
+// them set, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables apply.
+// Proxy.Redacted masks the passwords of the proxy URLs before they are
+// logged, as ResolvedConfig does for every URL it returns.
+//
+// # Tracing Configuration
+//
+// Tracing exports the OpenTelemetry spans of the agent (API requests, store
+// queries, vCenter and console calls, collection phases and VM inspections)
+// to an OTLP/HTTP collector, see internal/tracing:
+//
+//	┌─────────────┬──────────────────────────┬──────────────────────────────┐
+//	│ Field       │ Default                  │ Description                  │
+//	├─────────────┼──────────────────────────┼──────────────────────────────┤
+//	│ Endpoint    │ ""                       │ Collector URL (off if "")    │
+//	│ SampleRatio │ 1                        │ Fraction of traces recorded  │
+//	│ ServiceName │ assisted-migration-agent │ service.name of the spans    │
+//	└─────────────┴──────────────────────────┴──────────────────────────────┘
+//
+// # Features
+//
+// Features enables experimental subsystems, which ship disabled so they can be
+// turned on per deployment. IsEnabled reports whether one is enabled and
+// ValidateFeatures rejects unknown names:
+//
+//	┌───────────────────────┬───────────────────────────────────────────┐
+//	│ Feature               │ Gates                                     │
+//	├───────────────────────┼───────────────────────────────────────────┤
+//	│ inspector             │ The /vms/inspector endpoints              │
+//	│ graphqlAPI            │ The /api/graphql endpoint                 │
+//	│ benchmark             │ The /benchmark endpoints                  │
+//	│ incrementalCollection │ Reserved, no effect yet                   │
+//	│ grpcAPI               │ Reserved, no effect yet                   │
+//	└───────────────────────┴───────────────────────────────────────────┘
+//
+// In AMA_FEATURES the pairs are comma separated: inspector=true. A running
+// agent checks them through a FeatureGate, updated by the Hot Reload.
+//
+// # Remote Configuration
+//
+// With Console.RemoteConfigEnabled the agent pulls a Remote from the console at
+// startup and every Console.RemoteConfigInterval. ApplyRemote sets it over
+// every source but the flags, recorded as SourceRemote:
+//
+//	┌──────────────────┬────────────────────────┬──────────────────────┐
+//	│ Remote field     │ Key                    │ Applied              │
+//	├──────────────────┼────────────────────────┼──────────────────────┤
+//	│ UpdateInterval   │ agent.updateInterval   │ Replaces the value   │
+//	│ Features         │ features               │ Merged per feature   │
+//	│ PolicyBundleURL  │ agent.policyBundleURL  │ Replaces the value   │
+//	│ UpdateChannelURL │ agent.updateChannelURL │ Replaces the value   │
+//	└──────────────────┴────────────────────────┴──────────────────────┘
+//
+// The UpdatePublicKey is never remote: a console could otherwise sign the
+// binaries it serves with a key of its choice.
+//
+// A changed Remote goes through the Watcher reload, on top of the reloaded
+// file and environment.
+//
+// # Log Levels
+//
+// LogLevels maps a component to the level of its named loggers, LogLevel
+// applying to the others. A component is a logger name (console_service) or
+// its first part (console for console_service, store for store.query):
+//
+//	logLevel: info
+//	logLevels:
+//	  store: debug    # SQL queries of the QueryInterceptor
+//	  console: warn
+//
+// In AMA_LOG_LEVELS the pairs are comma separated: store=debug,console=warn.
+//
+// # Log File
+//
+// When LogFile is set, the logs written to stdout are also written to it, in
+// LogFormat and filtered by the same levels. Like the server access log, the
+// file is rotated to LogFile.<timestamp> once it reaches LogFileMaxSize and the
+// rotated files are pruned by LogFileMaxAge and LogFileMaxBackups:
+//
+//	logFile: /var/log/agent/agent.log
+//	logFileMaxSize: 50MiB
+//	logFileMaxBackups: 3
+//
+// # Hot Reload
+//
+// A Watcher reloads the configuration on SIGHUP and when ConfigFile changes,
+// polling its modification time. Only the Reloadable settings are applied:
+//
+//	┌────────────────────────┬──────────────────────────────────────┐
+//	│ Setting                │ Applied to                           │
+//	├────────────────────────┼──────────────────────────────────────┤
+//	│ LogLevel               │ logger.SetLevel                      │
+//	│ LogLevels              │ logger.SetComponentLevels            │
+//	│ Agent.UpdateInterval   │ Console.SetUpdateInterval            │
+//	│ Console.URL            │ Console.SetClient with a new client  │
+//	│ Features               │ FeatureGate.Set                      │
+//	│ Agent.PolicyBundleURL  │ PolicyBundle.SetURL                  │
+//	│ Agent.UpdateChannelURL │ Updater.SetURL                       │
+//	└────────────────────────┴──────────────────────────────────────┘
+//
+// A configuration that fails to load or validate keeps the settings in use.
+//
+// The policies of OpaPoliciesFolder are reloaded the same way, on SIGHUP and
+// when their files change, by services.PolicyService. The folder itself needs
+// a restart.
+//
 // # Debug Logging
 //
 // All fields are tagged with `debugmap:"visible"` allowing safe logging
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EnvPrefix prefixes the environment variables read by LoadFromEnv.
+const EnvPrefix = "AMA"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LoadFromEnv applies the AMA_ environment variables set over cfg. Variable
+// names are built from the yaml keys of the fields: server.httpPort is read from
+// AMA_SERVER_HTTP_PORT, agent.mode from AMA_AGENT_MODE. Durations use the
+// time.ParseDuration syntax, booleans the strconv.ParseBool one, lists are
+// comma separated and maps are comma separated key=value pairs. Secret fields are also read from the file named by the
+// variable with a _FILE suffix, AMA_AUTH_JWT_FILE for auth.jwt.
+func LoadFromEnv(cfg *Configuration) error {
+	return cfg.loadFromEnv(reflect.ValueOf(cfg).Elem(), EnvPrefix, "")
+}
+
+func (c *Configuration) loadFromEnv(v reflect.Value, prefix, keyPrefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := yamlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+
+		name := prefix + "_" + envName(key)
+		if keyPrefix != "" {
+			key = keyPrefix + "." + key
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := c.loadFromEnv(field, name, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isSecret(t.Field(i)) {
+			loaded, err := loadSecretFromEnv(field, name)
+			if err != nil {
+				return err
+			}
+			if loaded {
+				c.setSource(key, SourceEnv)
+				continue
+			}
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("invalid %s=%q: %w", name, value, err)
+		}
+		c.setSource(key, SourceEnv)
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Map:
+		items := reflect.MakeMap(field.Type())
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%q must be formatted as key=value", item)
+			}
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setField(elem, strings.TrimSpace(v)); err != nil {
+				return fmt.Errorf("%q: %w", item, err)
+			}
+			items.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)).Convert(field.Type().Key()), elem)
+		}
+		field.Set(items)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// envName turns a camelCase yaml key into its SCREAMING_SNAKE_CASE form,
+// keeping acronyms together: clientCAFile becomes CLIENT_CA_FILE.
+func envName(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
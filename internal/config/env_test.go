@@ -0,0 +1,130 @@
+package config_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("LoadFromEnv", func() {
+	var cfg *config.Configuration
+
+	BeforeEach(func() {
+		cfg = config.NewConfigurationWithOptionsAndDefaults()
+	})
+
+	// Given AMA_ variables of every supported type
+	// When we load the environment
+	// Then the matching fields should be parsed and set
+	It("applies the prefixed variables", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_SERVER_HTTP_PORT", "9443")
+		GinkgoT().Setenv("AMA_SERVER_MODE", "prod")
+		GinkgoT().Setenv("AMA_SERVER_CLIENT_CA_FILE", "/etc/agent/ca.pem")
+		GinkgoT().Setenv("AMA_SERVER_ACME_HTTP_PORT", "8080")
+		GinkgoT().Setenv("AMA_SERVER_RATE_LIMIT_RPS", "2.5")
+		GinkgoT().Setenv("AMA_SERVER_MAX_REQUEST_BODY_SIZE", "1024")
+		GinkgoT().Setenv("AMA_SERVER_READ_TIMEOUT", "45s")
+		GinkgoT().Setenv("AMA_SERVER_CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+		GinkgoT().Setenv("AMA_AGENT_MODE", "connected")
+		GinkgoT().Setenv("AMA_AGENT_SOURCE_ID", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		GinkgoT().Setenv("AMA_AGENT_LEGACY_STATUS_ENABLED", "false")
+		GinkgoT().Setenv("AMA_AUTH_JWT_FILE_PATH", "/etc/agent/jwt")
+		GinkgoT().Setenv("AMA_CONSOLE_URL", "https://console.example.com")
+		GinkgoT().Setenv("AMA_LOG_LEVEL", "warn")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.HTTPPort).To(Equal(9443))
+		Expect(cfg.Server.ServerMode).To(Equal("prod"))
+		Expect(cfg.Server.ClientCAFile).To(Equal("/etc/agent/ca.pem"))
+		Expect(cfg.Server.ACMEHTTPPort).To(Equal(8080))
+		Expect(cfg.Server.RateLimitRPS).To(Equal(2.5))
+		Expect(cfg.Server.MaxRequestBodySize).To(Equal(config.ByteSize(1024)))
+		Expect(cfg.Server.ReadTimeout).To(Equal(45 * time.Second))
+		Expect(cfg.Server.CORSAllowedOrigins).To(Equal([]string{"https://a.example.com", "https://b.example.com"}))
+		Expect(cfg.Agent.Mode).To(Equal("connected"))
+		Expect(cfg.Agent.SourceID).To(Equal("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+		Expect(cfg.Agent.LegacyStatusEnabled).To(BeFalse())
+		Expect(cfg.Auth.JWTFilePath).To(Equal("/etc/agent/jwt"))
+		Expect(cfg.Console.URL).To(Equal("https://console.example.com"))
+		Expect(cfg.LogLevel).To(Equal("warn"))
+	})
+
+	// Given no AMA_ variable
+	// When we load the environment
+	// Then the configuration should keep its values
+	It("keeps the current values of unset variables", func() {
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.HTTPPort).To(Equal(8000))
+		Expect(cfg.Server.WriteTimeout).To(Equal(5 * time.Minute))
+	})
+
+	// Given a variable that does not parse as its field type
+	// When we load the environment
+	// Then it should fail naming the variable
+	It("fails on invalid values", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_SERVER_IDLE_TIMEOUT", "soon")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("AMA_SERVER_IDLE_TIMEOUT")))
+	})
+
+	// Given AMA_LOG_LEVELS with key=value pairs
+	// When we load the environment
+	// Then the component log levels should be set
+	It("parses maps", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_LOG_LEVELS", "store=debug, console=warn")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.LogLevels).To(Equal(map[string]string{"store": "debug", "console": "warn"}))
+	})
+
+	// Given AMA_LOG_LEVELS with a pair missing its value
+	// When we load the environment
+	// Then it should fail naming the variable
+	It("fails on malformed maps", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_LOG_LEVELS", "store")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("AMA_LOG_LEVELS")))
+	})
+
+	// Given a variable for the build version
+	// When we load the environment
+	// Then it should be ignored
+	It("does not read build information", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_AGENT_VERSION", "v9.9.9")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Agent.Version).To(Equal("v0.0.0"))
+	})
+})
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Experimental subsystems gated by Features. They ship disabled.
+const (
+	FeatureInspector  = "inspector"
+	FeatureGraphQLAPI = "graphqlAPI"
+	FeatureBenchmark  = "benchmark"
+	// FeatureIncrementalCollection and FeatureGRPCAPI are reserved for the
+	// subsystems in development and have no effect yet.
+	FeatureIncrementalCollection = "incrementalCollection"
+	FeatureGRPCAPI               = "grpcAPI"
+)
+
+var knownFeatures = []string{FeatureInspector, FeatureGraphQLAPI, FeatureBenchmark, FeatureIncrementalCollection, FeatureGRPCAPI}
+
+// IsEnabled reports whether feature is enabled. Features absent from
+// c.Features are disabled.
+func (c *Configuration) IsEnabled(feature string) bool {
+	return c.Features[feature]
+}
+
+// ValidateFeatures returns an error for the first unknown feature of
+// c.Features.
+func (c *Configuration) ValidateFeatures() error {
+	names := make([]string, 0, len(c.Features))
+	for name := range c.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(knownFeatures, name) {
+			return fmt.Errorf("unknown feature %q: must be one of %v", name, knownFeatures)
+		}
+	}
+	return nil
+}
+
+// FeatureGate holds the features enabled in a running agent, replaced when the
+// configuration is reloaded.
+type FeatureGate struct {
+	mu       sync.RWMutex
+	features map[string]bool
+}
+
+// NewFeatureGate returns a gate enabling features.
+func NewFeatureGate(features map[string]bool) *FeatureGate {
+	return &FeatureGate{features: maps.Clone(features)}
+}
+
+// IsEnabled reports whether feature is enabled.
+func (g *FeatureGate) IsEnabled(feature string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.features[feature]
+}
+
+// Set replaces the enabled features.
+func (g *FeatureGate) Set(features map[string]bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.features = maps.Clone(features)
+}
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("Features", func() {
+	var cfg *config.Configuration
+
+	BeforeEach(func() {
+		cfg = config.NewConfigurationWithOptionsAndDefaults()
+	})
+
+	// Given the default configuration
+	// When we check the experimental features
+	// Then they should all be disabled
+	It("disables every feature by default", func() {
+		// Act
+		inspector := cfg.IsEnabled(config.FeatureInspector)
+		grpc := cfg.IsEnabled(config.FeatureGRPCAPI)
+
+		// Assert
+		Expect(inspector).To(BeFalse())
+		Expect(grpc).To(BeFalse())
+	})
+
+	// Given AMA_FEATURES enabling the inspector
+	// When we load the environment
+	// Then only the inspector should be enabled
+	It("enables the features set in the environment", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_FEATURES", "inspector=true,grpcAPI=false")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.IsEnabled(config.FeatureInspector)).To(BeTrue())
+		Expect(cfg.IsEnabled(config.FeatureGRPCAPI)).To(BeFalse())
+		Expect(cfg.IsEnabled(config.FeatureIncrementalCollection)).To(BeFalse())
+	})
+
+	// Given AMA_FEATURES with a value that is not a boolean
+	// When we load the environment
+	// Then it should fail with the variable name
+	It("rejects values that are not booleans", func() {
+		// Arrange
+		GinkgoT().Setenv("AMA_FEATURES", "inspector=yes please")
+
+		// Act
+		err := config.LoadFromEnv(cfg)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("invalid AMA_FEATURES")))
+	})
+
+	// Given an unknown feature
+	// When we validate the features
+	// Then it should fail with the feature name
+	It("rejects unknown features", func() {
+		// Arrange
+		cfg.Features = map[string]bool{"inspector": true, "inspektor": true}
+
+		// Act
+		err := cfg.ValidateFeatures()
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring(`unknown feature "inspektor"`)))
+	})
+})
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlLine matches the line prefix of yaml errors, meaningless for TOML files
+// which are decoded through an intermediate YAML document.
+var yamlLine = regexp.MustCompile(`line \d+: `)
+
+// LoadFromFile reads the configuration file at path onto cfg. The file is TOML
+// when its extension is .toml and YAML otherwise, with the keys of the yaml
+// struct tags. Keys absent from the file keep their value in cfg, maps set in
+// the file replace the ones of cfg and unknown keys are an error.
+func LoadFromFile(path string, cfg *Configuration) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	isTOML := strings.EqualFold(filepath.Ext(path), ".toml")
+	if isTOML {
+		if data, err = tomlToYAML(data); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return invalidFile(path, err, isTOML)
+	}
+
+	// maps in the file replace the ones in cfg instead of being merged into them
+	var keys []string
+	cfg.walk(func(key string, _ reflect.StructField, value reflect.Value) bool {
+		if _, ok := lookupKey(doc, key); ok {
+			keys = append(keys, key)
+			if value.Kind() == reflect.Map {
+				value.Set(reflect.Zero(value.Type()))
+			}
+		}
+		return true
+	})
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return invalidFile(path, err, isTOML)
+	}
+
+	for _, key := range keys {
+		cfg.setSource(key, SourceFile)
+	}
+
+	return nil
+}
+
+func invalidFile(path string, err error, isTOML bool) error {
+	msg := err.Error()
+	if isTOML {
+		msg = yamlLine.ReplaceAllString(msg, "")
+	}
+	return fmt.Errorf("invalid config file %s: %s", path, msg)
+}
+
+// lookupKey returns the value of the dotted key in doc.
+func lookupKey(doc map[string]any, key string) (any, bool) {
+	section, rest, nested := strings.Cut(key, ".")
+	v, ok := doc[section]
+	if !ok || !nested {
+		return v, ok
+	}
+	sub, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupKey(sub, rest)
+}
+
+func tomlToYAML(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
@@ -0,0 +1,210 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("LoadFromFile", func() {
+	var (
+		dir string
+		cfg *config.Configuration
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		cfg = config.NewConfigurationWithOptionsAndDefaults()
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	// Given a YAML file setting some keys of every section
+	// When we load it
+	// Then those keys should be set and the others keep their defaults
+	It("loads a YAML file onto the defaults", func() {
+		// Arrange
+		path := writeFile("agent.yaml", `
+server:
+  mode: prod
+  httpPort: 8443
+  readTimeout: 30s
+  maxRequestBodySize: 512MiB
+  accessLogMaxSize: 1048576
+  rateLimitExemptPaths:
+    - /api/v1/health
+agent:
+  mode: connected
+  dataFolder: /var/lib/agent
+auth:
+  enabled: false
+console:
+  url: https://console.example.com
+logLevel: info
+`)
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.ServerMode).To(Equal("prod"))
+		Expect(cfg.Server.HTTPPort).To(Equal(8443))
+		Expect(cfg.Server.ReadTimeout).To(Equal(30 * time.Second))
+		Expect(cfg.Server.RateLimitExemptPaths).To(Equal([]string{"/api/v1/health"}))
+		Expect(cfg.Server.WriteTimeout).To(Equal(5 * time.Minute))
+		Expect(cfg.Server.MaxRequestBodySize).To(Equal(512 * config.MiB))
+		Expect(cfg.Server.AccessLogMaxSize).To(Equal(config.MiB))
+		Expect(cfg.Agent.Mode).To(Equal("connected"))
+		Expect(cfg.Agent.DataFolder).To(Equal("/var/lib/agent"))
+		Expect(cfg.Agent.NumWorkers).To(Equal(3))
+		Expect(cfg.Auth.Enabled).To(BeFalse())
+		Expect(cfg.Console.URL).To(Equal("https://console.example.com"))
+		Expect(cfg.LogLevel).To(Equal("info"))
+	})
+
+	// Given a TOML file
+	// When we load it
+	// Then its keys should be set like the YAML ones
+	It("loads a TOML file", func() {
+		// Arrange
+		path := writeFile("agent.toml", `
+logLevel = "warn"
+
+[server]
+httpPort = 9000
+idleTimeout = "1m"
+corsAllowedOrigins = ["https://ui.example.com"]
+
+[agent]
+numWorkers = 5
+`)
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.LogLevel).To(Equal("warn"))
+		Expect(cfg.Server.HTTPPort).To(Equal(9000))
+		Expect(cfg.Server.IdleTimeout).To(Equal(time.Minute))
+		Expect(cfg.Server.CORSAllowedOrigins).To(Equal([]string{"https://ui.example.com"}))
+		Expect(cfg.Agent.NumWorkers).To(Equal(5))
+	})
+
+	// Given a YAML file with a misspelled key
+	// When we load it
+	// Then it should fail naming the key and its line
+	It("rejects unknown YAML keys", func() {
+		// Arrange
+		path := writeFile("agent.yaml", "server:\n  httpPrt: 9000\n")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("line 2"))
+		Expect(err.Error()).To(ContainSubstring("field httpPrt not found"))
+	})
+
+	// Given a YAML file with a duration without unit
+	// When we load it
+	// Then it should fail instead of reading nanoseconds
+	It("rejects durations without unit", func() {
+		// Arrange
+		path := writeFile("agent.yaml", "server:\n  readTimeout: 30\n")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(cfg.Server.ReadTimeout).To(Equal(5 * time.Minute))
+	})
+
+	// Given a YAML file with a size in an unknown unit
+	// When we load it
+	// Then it should fail naming the unit
+	It("rejects sizes with an unknown unit", func() {
+		// Arrange
+		path := writeFile("agent.yaml", "server:\n  maxRequestBodySize: 64M\n")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring(`unknown unit "M"`)))
+	})
+
+	// Given a TOML file with an unknown section
+	// When we load it
+	// Then it should fail naming the key
+	It("rejects unknown TOML keys", func() {
+		// Arrange
+		path := writeFile("agent.toml", "[database]\npath = \"/tmp\"\n")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("field database not found"))
+		Expect(err.Error()).ToNot(ContainSubstring("line"))
+	})
+
+	// Given a file setting the build version
+	// When we load it
+	// Then it should be rejected, the version is not configurable
+	It("does not accept build information", func() {
+		// Arrange
+		path := writeFile("agent.yaml", "agent:\n  version: v9.9.9\n")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(cfg.Agent.Version).To(Equal("v0.0.0"))
+	})
+
+	// Given an empty file
+	// When we load it
+	// Then the configuration should be left untouched
+	It("accepts an empty file", func() {
+		// Arrange
+		path := writeFile("agent.yaml", "")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.HTTPPort).To(Equal(8000))
+	})
+
+	// Given a configuration with component log levels
+	// When we load a file setting other component log levels
+	// Then the levels of the file should replace the previous ones
+	It("replaces maps instead of merging them", func() {
+		// Arrange
+		cfg.LogLevels = map[string]string{"http": "debug"}
+		path := writeFile("agent.yaml", "logLevels:\n  store: debug\n  console: warn\n")
+
+		// Act
+		err := config.LoadFromFile(path, cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.LogLevels).To(Equal(map[string]string{"store": "debug", "console": "warn"}))
+	})
+})
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type ProfileType string
+
+const (
+	ProfileDev  ProfileType = "dev"
+	ProfileProd ProfileType = "prod"
+)
+
+// profiles holds the values of each profile by configuration key, written like
+// the AMA_ environment variables.
+var profiles = map[ProfileType]map[string]string{
+	// plain HTTP without authentication and verbose logs, for a local agent
+	ProfileDev: {
+		"server.mode":  string(ServerModeDev),
+		"auth.enabled": "false",
+		"logLevel":     "debug",
+	},
+	// HTTPS serving the UI of the container image, authenticated to the console
+	ProfileProd: {
+		"server.mode":          string(ServerModeProd),
+		"server.staticsFolder": "/app/static",
+		"auth.enabled":         "true",
+		"logLevel":             "info",
+	},
+}
+
+// ApplyProfile sets the values of the cfg.Profile profile on the keys that
+// still have their default value: the configuration file, the environment and
+// the flags keep precedence over the profile. It does nothing when no profile
+// is selected.
+func ApplyProfile(cfg *Configuration) error {
+	if cfg.Profile == "" {
+		return nil
+	}
+
+	values, ok := profiles[ProfileType(cfg.Profile)]
+	if !ok {
+		return fmt.Errorf("invalid profile %q: must be %q or %q", cfg.Profile, ProfileDev, ProfileProd)
+	}
+
+	var err error
+	cfg.walk(func(key string, _ reflect.StructField, field reflect.Value) bool {
+		value, ok := values[key]
+		if !ok {
+			return true
+		}
+		if _, set := cfg.sources[key]; set {
+			return true
+		}
+		if err = setField(field, value); err != nil {
+			err = fmt.Errorf("invalid %s=%q in profile %s: %w", key, value, cfg.Profile, err)
+			return false
+		}
+		cfg.setSource(key, SourceProfile)
+		return true
+	})
+	return err
+}
@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("ApplyProfile", func() {
+	var cfg *config.Configuration
+
+	BeforeEach(func() {
+		cfg = config.NewConfigurationWithOptionsAndDefaults()
+	})
+
+	// Given the prod profile
+	// When we apply it over the defaults
+	// Then HTTPS, authentication and info logs should be set
+	It("applies the prod defaults", func() {
+		// Arrange
+		cfg.Profile = "prod"
+		cfg.Auth.Enabled = false
+
+		// Act
+		err := config.ApplyProfile(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.ServerMode).To(Equal("prod"))
+		Expect(cfg.Server.StaticsFolder).To(Equal("/app/static"))
+		Expect(cfg.Auth.Enabled).To(BeTrue())
+		Expect(cfg.LogLevel).To(Equal("info"))
+	})
+
+	// Given the dev profile
+	// When we apply it over the defaults
+	// Then HTTP, no authentication and debug logs should be set
+	It("applies the dev defaults", func() {
+		// Arrange
+		cfg.Profile = "dev"
+
+		// Act
+		err := config.ApplyProfile(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.ServerMode).To(Equal("dev"))
+		Expect(cfg.Auth.Enabled).To(BeFalse())
+		Expect(cfg.LogLevel).To(Equal("debug"))
+	})
+
+	// Given the prod profile and settings from a file and the environment
+	// When we apply it
+	// Then the file and the environment should win over the profile
+	It("keeps the values not set by the defaults", func() {
+		// Arrange
+		path := filepath.Join(GinkgoT().TempDir(), "agent.yaml")
+		Expect(os.WriteFile(path, []byte("profile: prod\nlogLevel: warn\n"), 0o600)).To(Succeed())
+		GinkgoT().Setenv("AMA_AUTH_ENABLED", "false")
+		Expect(config.LoadFromFile(path, cfg)).To(Succeed())
+		Expect(config.LoadFromEnv(cfg)).To(Succeed())
+
+		// Act
+		err := config.ApplyProfile(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.LogLevel).To(Equal("warn"))
+		Expect(cfg.Auth.Enabled).To(BeFalse())
+		Expect(cfg.Server.ServerMode).To(Equal("prod"))
+
+		sources := map[string]config.Source{}
+		for _, v := range cfg.ResolvedConfig() {
+			sources[v.Key] = v.Source
+		}
+		Expect(sources).To(HaveKeyWithValue("logLevel", config.SourceFile))
+		Expect(sources).To(HaveKeyWithValue("auth.enabled", config.SourceEnv))
+		Expect(sources).To(HaveKeyWithValue("server.mode", config.SourceProfile))
+	})
+
+	// Given no profile
+	// When we apply it
+	// Then the configuration should be left untouched
+	It("does nothing without a profile", func() {
+		// Act
+		err := config.ApplyProfile(cfg)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Server.ServerMode).To(Equal("dev"))
+		Expect(cfg.Auth.Enabled).To(BeTrue())
+	})
+
+	// Given an unknown profile
+	// When we apply it
+	// Then it should fail
+	It("rejects unknown profiles", func() {
+		// Arrange
+		cfg.Profile = "staging"
+
+		// Act
+		err := config.ApplyProfile(cfg)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring(`invalid profile "staging"`)))
+	})
+})
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyDirect as a proxy override bypasses the proxy for its target.
+const ProxyDirect = "direct"
+
+type ProxyTarget string
+
+const (
+	ProxyTargetConsole ProxyTarget = "console"
+	ProxyTargetVCenter ProxyTarget = "vcenter"
+)
+
+// ProxyFunc returns the proxy function of an http.Transport sending requests
+// to target. The override of the target wins over HTTPProxy, HTTPSProxy and
+// NoProxy, which win over the environment variables.
+func (p Proxy) ProxyFunc(target ProxyTarget) func(*http.Request) (*url.URL, error) {
+	override := p.ConsoleProxy
+	if target == ProxyTargetVCenter {
+		override = p.VCenterProxy
+	}
+
+	switch {
+	case override == ProxyDirect:
+		return func(*http.Request) (*url.URL, error) { return nil, nil }
+	case override != "":
+		u, err := url.Parse(override)
+		return func(*http.Request) (*url.URL, error) { return u, err }
+	case p.HTTPProxy == "" && p.HTTPSProxy == "" && len(p.NoProxy) == 0:
+		return http.ProxyFromEnvironment
+	}
+
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  p.HTTPProxy,
+		HTTPSProxy: p.HTTPSProxy,
+		NoProxy:    strings.Join(p.NoProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// Redacted returns p with the passwords of its proxy URLs redacted, to be
+// logged or shown.
+func (p Proxy) Redacted() Proxy {
+	p.HTTPProxy = redactURL(p.HTTPProxy)
+	p.HTTPSProxy = redactURL(p.HTTPSProxy)
+	p.ConsoleProxy = redactURL(p.ConsoleProxy)
+	p.VCenterProxy = redactURL(p.VCenterProxy)
+	return p
+}
+
+// redactURL returns s with the password of its userinfo redacted when s is a
+// URL carrying one. Like the proxy settings, s may omit its scheme.
+func redactURL(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	scheme := ""
+	if !strings.Contains(s, "://") {
+		scheme = "http://"
+	}
+	u, err := url.Parse(scheme + s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return strings.TrimPrefix(u.Redacted(), scheme)
+}
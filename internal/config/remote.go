@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// Remote holds the settings pulled from the console when
+// Console.RemoteConfigEnabled is set. Empty fields keep the local value.
+type Remote struct {
+	// UpdateInterval is a duration such as 30s
+	UpdateInterval   string          `json:"updateInterval,omitempty"`
+	Features         map[string]bool `json:"features,omitempty"`
+	PolicyBundleURL  string          `json:"policyBundleURL,omitempty"`
+	UpdateChannelURL string          `json:"updateChannelURL,omitempty"`
+}
+
+// ApplyRemote sets the values of remote on cfg, over the defaults, the profile,
+// the configuration file and the environment. Keys set by a flag keep their
+// value. Remote features are merged into the local ones.
+func ApplyRemote(cfg *Configuration, remote Remote) error {
+	values := map[string]string{
+		"agent.updateInterval":   remote.UpdateInterval,
+		"agent.policyBundleURL":  remote.PolicyBundleURL,
+		"agent.updateChannelURL": remote.UpdateChannelURL,
+	}
+
+	var err error
+	cfg.walk(func(key string, _ reflect.StructField, field reflect.Value) bool {
+		value := values[key]
+		if value == "" || cfg.sources[key] == SourceFlag {
+			return true
+		}
+		if err = setField(field, value); err != nil {
+			err = fmt.Errorf("invalid remote %s=%q: %w", key, value, err)
+			return false
+		}
+		cfg.setSource(key, SourceRemote)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(remote.Features) > 0 {
+		features := maps.Clone(cfg.Features)
+		if features == nil {
+			features = make(map[string]bool)
+		}
+		maps.Copy(features, remote.Features)
+		cfg.Features = features
+		cfg.setSource("features", SourceRemote)
+	}
+	return nil
+}
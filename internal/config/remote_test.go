@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("ApplyRemote", func() {
+	var cfg *config.Configuration
+
+	BeforeEach(func() {
+		cfg = config.NewConfigurationWithOptionsAndDefaults()
+	})
+
+	sourceOf := func(key string) config.Source {
+		for _, v := range cfg.ResolvedConfig() {
+			if v.Key == key {
+				return v.Source
+			}
+		}
+		Fail("missing key " + key)
+		return ""
+	}
+
+	// Given a remote update interval and policy bundle URL
+	// When we apply them
+	// Then they should be set with the remote source
+	It("sets the remote values", func() {
+		// Arrange
+		remote := config.Remote{UpdateInterval: "30s", PolicyBundleURL: "https://console.example.com/bundles/1"}
+
+		// Act
+		err := config.ApplyRemote(cfg, remote)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Agent.UpdateInterval).To(Equal(30 * time.Second))
+		Expect(cfg.Agent.PolicyBundleURL).To(Equal("https://console.example.com/bundles/1"))
+		Expect(sourceOf("agent.updateInterval")).To(Equal(config.SourceRemote))
+		Expect(sourceOf("agent.policyBundleURL")).To(Equal(config.SourceRemote))
+	})
+
+	// Given an update interval set by a flag
+	// When we apply a remote update interval
+	// Then the flag value should be kept
+	It("keeps the values set by a flag", func() {
+		// Arrange
+		cfg.Agent.UpdateInterval = time.Minute
+		cfg.MarkSource(reflect.ValueOf(&cfg.Agent.UpdateInterval).Pointer(), config.SourceFlag)
+
+		// Act
+		err := config.ApplyRemote(cfg, config.Remote{UpdateInterval: "30s"})
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Agent.UpdateInterval).To(Equal(time.Minute))
+		Expect(sourceOf("agent.updateInterval")).To(Equal(config.SourceFlag))
+	})
+
+	// Given local features and remote ones
+	// When we apply the remote features
+	// Then they should be merged, the remote ones winning
+	It("merges the remote features", func() {
+		// Arrange
+		cfg.Features = map[string]bool{config.FeatureInspector: true, config.FeatureGRPCAPI: true}
+		local := cfg.Features
+
+		// Act
+		err := config.ApplyRemote(cfg, config.Remote{Features: map[string]bool{config.FeatureGRPCAPI: false, config.FeatureIncrementalCollection: true}})
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.Features).To(Equal(map[string]bool{
+			config.FeatureInspector:             true,
+			config.FeatureGRPCAPI:               false,
+			config.FeatureIncrementalCollection: true,
+		}))
+		Expect(local[config.FeatureGRPCAPI]).To(BeTrue())
+	})
+
+	// Given a remote update interval without unit
+	// When we apply it
+	// Then it should fail naming the key
+	It("rejects invalid remote values", func() {
+		// Act
+		err := config.ApplyRemote(cfg, config.Remote{UpdateInterval: "30"})
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring(`invalid remote agent.updateInterval="30"`)))
+	})
+})
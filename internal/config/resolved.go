@@ -0,0 +1,131 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ecordell/optgen/helpers"
+)
+
+// Source is where the value of a configuration key comes from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceProfile Source = "profile"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceRemote  Source = "remote"
+)
+
+// ResolvedValue is a configuration key with its final value and source.
+type ResolvedValue struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source Source `json:"source"`
+}
+
+// ResolvedConfig returns every configuration key, in the dotted form of the
+// configuration file (server.httpPort), with its value and the source that set
+// it last. Durations are rendered as strings, secrets are never returned and
+// the passwords of URLs, such as those of the proxies, are redacted.
+func (c *Configuration) ResolvedConfig() []ResolvedValue {
+	var values []ResolvedValue
+	c.walk(func(key string, field reflect.StructField, value reflect.Value) bool {
+		src, ok := c.sources[key]
+		if !ok {
+			src = SourceDefault
+		}
+		values = append(values, ResolvedValue{Key: key, Value: resolvedValue(field, value), Source: src})
+		return true
+	})
+	return values
+}
+
+// MarkSource records src as the source of the field at addr, the address of a
+// field of c such as the variable bound to a flag. It returns false when addr
+// is not the address of a configuration key of c.
+func (c *Configuration) MarkSource(addr uintptr, src Source) bool {
+	found := false
+	c.walk(func(key string, _ reflect.StructField, value reflect.Value) bool {
+		if value.UnsafeAddr() != addr {
+			return true
+		}
+		c.setSource(key, src)
+		found = true
+		return false
+	})
+	return found
+}
+
+// Clone returns a copy of c that can be loaded again without changing c.
+func (c *Configuration) Clone() *Configuration {
+	next := *c
+	next.LogLevels = maps.Clone(c.LogLevels)
+	next.Features = maps.Clone(c.Features)
+	next.sources = maps.Clone(c.sources)
+	return &next
+}
+
+func (c *Configuration) setSource(key string, src Source) {
+	if c.sources == nil {
+		c.sources = make(map[string]Source)
+	}
+	c.sources[key] = src
+}
+
+// walk calls fn with the dotted key, the struct field and the value of each
+// configuration key of c, until fn returns false.
+func (c *Configuration) walk(fn func(key string, field reflect.StructField, value reflect.Value) bool) {
+	walkFields(reflect.ValueOf(c).Elem(), "", fn)
+}
+
+func walkFields(v reflect.Value, prefix string, fn func(string, reflect.StructField, reflect.Value) bool) bool {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := yamlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if field := v.Field(i); field.Kind() == reflect.Struct {
+			if !walkFields(field, key, fn) {
+				return false
+			}
+		} else if !fn(key, t.Field(i), field) {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlKey returns the key of the field in the configuration file, or an empty
+// string when the field is not read from it.
+func yamlKey(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+func resolvedValue(field reflect.StructField, value reflect.Value) any {
+	if isSecret(field) {
+		return helpers.SensitiveDebugValue(value.Interface())
+	}
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case ByteSize:
+		return v.String()
+	case string:
+		return redactURL(v)
+	}
+	return value.Interface()
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// secretFileSuffix names the variable holding the path of a file with the
+// value of a secret field: AMA_AUTH_JWT_FILE for AMA_AUTH_JWT.
+const secretFileSuffix = "_FILE"
+
+// isSecret reports whether the field is a secret, i.e. tagged debugmap:"hidden"
+// so DebugMap leaves it out.
+func isSecret(field reflect.StructField) bool {
+	return field.Tag.Get("debugmap") == "hidden"
+}
+
+// loadSecretFromEnv sets field from the file named by name_FILE and reports
+// whether that variable is set. Giving both the value and the file is an error,
+// so one does not silently win over the other.
+func loadSecretFromEnv(field reflect.Value, name string) (bool, error) {
+	path, ok := os.LookupEnv(name + secretFileSuffix)
+	if !ok {
+		return false, nil
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return true, fmt.Errorf("%s and %s%s cannot be set together", name, name, secretFileSuffix)
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return true, fmt.Errorf("invalid %s%s: %w", name, secretFileSuffix, err)
+	}
+	field.SetString(value)
+	return true, nil
+}
+
+// ResolveSecrets reads the secrets given as a file path in cfg. The agent's JWT
+// is read from Auth.JWTFilePath when authentication is enabled and Auth.JWT is
+// not already set, the local API token from Auth.LocalTokenFilePath when
+// Auth.LocalToken is not already set, and likewise the provisioning token from
+// Auth.ProvisioningTokenFilePath. With Auth.DeviceLoginEnabled the JWT file
+// may not exist yet: it is written by the first login.
+func ResolveSecrets(cfg *Configuration) error {
+	if cfg.Auth.Enabled && cfg.Auth.JWT == "" && cfg.Auth.JWTFilePath != "" {
+		jwt, err := readSecretFile(cfg.Auth.JWTFilePath)
+		if cfg.Auth.DeviceLoginEnabled && errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read agent's jwt: %w", err)
+		}
+		cfg.Auth.JWT = jwt
+	}
+
+	if cfg.Auth.LocalToken == "" && cfg.Auth.LocalTokenFilePath != "" {
+		token, err := readSecretFile(cfg.Auth.LocalTokenFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read local api token: %w", err)
+		}
+		cfg.Auth.LocalToken = token
+	}
+
+	if cfg.Auth.ProvisioningToken == "" && cfg.Auth.ProvisioningTokenFilePath != "" {
+		token, err := readSecretFile(cfg.Auth.ProvisioningTokenFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read provisioning token: %w", err)
+		}
+		cfg.Auth.ProvisioningToken = token
+	}
+	return nil
+}
+
+// JWTFromFile reports whether the agent's JWT is read from Auth.JWTFilePath
+// rather than given directly, so that a rotated file can replace it.
+func (c *Configuration) JWTFromFile() bool {
+	_, direct := c.sources["auth.jwt"]
+	return c.Auth.Enabled && c.Auth.JWTFilePath != "" && !direct
+}
+
+// WatchSecretFile calls onChange with the secret of the file at path, read
+// like ResolveSecrets does, whenever the file modification time changes and
+// the secret differs from current, until ctx is done. The file is first read
+// at the first tick, so a change made before the watch started is not missed.
+// Polling follows symlinks, so secrets swapped by a Secret update are seen. A
+// file that cannot be read is logged and read again at its next change.
+func WatchSecretFile(ctx context.Context, path, current string, onChange func(secret string)) {
+	var modTime time.Time
+
+	tick := time.NewTicker(watchInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Equal(modTime) {
+			continue
+		}
+		modTime = info.ModTime()
+
+		secret, err := readSecretFile(path)
+		if err != nil {
+			zap.S().Named("config").Errorw("failed to read secret file", "file", path, "error", err)
+			continue
+		}
+		if secret == current {
+			continue
+		}
+		current = secret
+		onChange(secret)
+	}
+}
+
+// readSecretFile returns the content of the file at path without the surrounding
+// whitespace, like the trailing newline of a mounted secret.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", errors.New("the file is empty")
+	}
+	return value, nil
+}
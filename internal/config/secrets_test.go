@@ -0,0 +1,292 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ecordell/optgen/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("Secrets", func() {
+	const token = "eyJhbGciOiJSUzI1NiJ9.secret-token"
+
+	var (
+		cfg       *config.Configuration
+		tokenFile string
+	)
+
+	BeforeEach(func() {
+		cfg = config.NewConfigurationWithOptionsAndDefaults()
+		tokenFile = filepath.Join(GinkgoT().TempDir(), "jwt")
+		Expect(os.WriteFile(tokenFile, []byte(token+"\n"), 0o600)).To(Succeed())
+	})
+
+	Context("LoadFromEnv", func() {
+		// Given AMA_AUTH_JWT_FILE naming a file with the token
+		// When we load the environment
+		// Then the JWT should be the trimmed content of the file
+		It("reads a secret from the file named by the _FILE variable", func() {
+			// Arrange
+			GinkgoT().Setenv("AMA_AUTH_JWT_FILE", tokenFile)
+
+			// Act
+			err := config.LoadFromEnv(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.JWT).To(Equal(token))
+		})
+
+		// Given both AMA_AUTH_JWT and AMA_AUTH_JWT_FILE
+		// When we load the environment
+		// Then it should fail instead of picking one
+		It("fails when the value and the file are both set", func() {
+			// Arrange
+			GinkgoT().Setenv("AMA_AUTH_JWT", token)
+			GinkgoT().Setenv("AMA_AUTH_JWT_FILE", tokenFile)
+
+			// Act
+			err := config.LoadFromEnv(cfg)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("AMA_AUTH_JWT and AMA_AUTH_JWT_FILE cannot be set together")))
+		})
+
+		// Given AMA_AUTH_JWT_FILE naming an empty file
+		// When we load the environment
+		// Then it should fail with the variable name
+		It("fails on an empty secret file", func() {
+			// Arrange
+			Expect(os.WriteFile(tokenFile, []byte("\n"), 0o600)).To(Succeed())
+			GinkgoT().Setenv("AMA_AUTH_JWT_FILE", tokenFile)
+
+			// Act
+			err := config.LoadFromEnv(cfg)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("invalid AMA_AUTH_JWT_FILE")))
+		})
+
+		// Given a _FILE variable for a field that is not a secret
+		// When we load the environment
+		// Then it should be ignored
+		It("ignores _FILE variables of other fields", func() {
+			// Arrange
+			GinkgoT().Setenv("AMA_CONSOLE_URL_FILE", tokenFile)
+
+			// Act
+			err := config.LoadFromEnv(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Console.URL).To(Equal("http://localhost:7443"))
+		})
+	})
+
+	Context("ResolveSecrets", func() {
+		// Given authentication enabled with a JWT file path
+		// When we resolve the secrets
+		// Then the JWT should be read from the file
+		It("reads the JWT from JWTFilePath", func() {
+			// Arrange
+			cfg.Auth.JWTFilePath = tokenFile
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.JWT).To(Equal(token))
+		})
+
+		// Given a JWT already set and a JWT file path
+		// When we resolve the secrets
+		// Then the JWT set should be kept
+		It("keeps a JWT set directly", func() {
+			// Arrange
+			cfg.Auth.JWT = "direct"
+			cfg.Auth.JWTFilePath = tokenFile
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.JWT).To(Equal("direct"))
+		})
+
+		// Given a JWT file path that does not exist
+		// When we resolve the secrets
+		// Then it should fail
+		It("fails when the JWT file cannot be read", func() {
+			// Arrange
+			cfg.Auth.JWTFilePath = tokenFile + ".missing"
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("failed to read agent's jwt")))
+		})
+
+		// Given device login enabled with a JWT file that does not exist yet
+		// When we resolve the secrets
+		// Then it should succeed without a JWT
+		It("accepts a missing JWT file with device login", func() {
+			// Arrange
+			cfg.Auth.DeviceLoginEnabled = true
+			cfg.Auth.JWTFilePath = tokenFile + ".missing"
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.JWT).To(BeEmpty())
+		})
+
+		// Given authentication disabled with a JWT file path
+		// When we resolve the secrets
+		// Then the file should not be read
+		It("does not read the JWT when authentication is disabled", func() {
+			// Arrange
+			cfg.Auth.Enabled = false
+			cfg.Auth.JWTFilePath = tokenFile + ".missing"
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.JWT).To(BeEmpty())
+		})
+
+		// Given authentication disabled with a local token file path
+		// When we resolve the secrets
+		// Then the local token should still be read from the file
+		It("reads the local token from LocalTokenFilePath", func() {
+			// Arrange
+			cfg.Auth.Enabled = false
+			cfg.Auth.LocalTokenFilePath = tokenFile
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.LocalToken).To(Equal(token))
+		})
+
+		// Given a local token file path that does not exist
+		// When we resolve the secrets
+		// Then it should fail
+		It("fails when the local token file cannot be read", func() {
+			// Arrange
+			cfg.Auth.LocalTokenFilePath = tokenFile + ".missing"
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("failed to read local api token")))
+		})
+
+		// Given a provisioning token file path
+		// When we resolve the secrets
+		// Then the provisioning token should be read from the file
+		It("reads the provisioning token from ProvisioningTokenFilePath", func() {
+			// Arrange
+			cfg.Auth.ProvisioningTokenFilePath = tokenFile
+
+			// Act
+			err := config.ResolveSecrets(cfg)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Auth.ProvisioningToken).To(Equal(token))
+		})
+	})
+
+	Context("JWTFromFile", func() {
+		// Given authentication enabled with a JWT file path
+		// When the JWT is read from the file or given directly
+		// Then only the JWT read from the file should be watched
+		It("reports whether the JWT comes from JWTFilePath", func() {
+			// Arrange
+			cfg.Auth.JWTFilePath = tokenFile
+			Expect(config.ResolveSecrets(cfg)).To(Succeed())
+			GinkgoT().Setenv("AMA_AUTH_JWT", "direct")
+			direct := config.NewConfigurationWithOptionsAndDefaults()
+			direct.Auth.JWTFilePath = tokenFile
+			Expect(config.LoadFromEnv(direct)).To(Succeed())
+
+			// Act
+			fromFile := cfg.JWTFromFile()
+			directFromFile := direct.JWTFromFile()
+
+			// Assert
+			Expect(fromFile).To(BeTrue())
+			Expect(directFromFile).To(BeFalse())
+		})
+	})
+
+	Context("WatchSecretFile", func() {
+		// Given a watched secret file
+		// When the file is rewritten with a new secret
+		// Then onChange should receive the new secret
+		It("calls onChange with the rotated secret", func() {
+			// Arrange
+			var (
+				mu      sync.Mutex
+				secrets []string
+			)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go config.WatchSecretFile(ctx, tokenFile, token, func(secret string) {
+				mu.Lock()
+				defer mu.Unlock()
+				secrets = append(secrets, secret)
+			})
+			received := func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				return append([]string(nil), secrets...)
+			}
+
+			// Act
+			Expect(os.WriteFile(tokenFile, []byte("rotated-token\n"), 0o600)).To(Succeed())
+			Expect(os.Chtimes(tokenFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute))).To(Succeed())
+
+			// Assert
+			Eventually(received).WithTimeout(6 * time.Second).Should(Equal([]string{"rotated-token"}))
+		})
+	})
+
+	Context("DebugMap", func() {
+		// Given a resolved JWT
+		// When we build the debug maps
+		// Then none of them should contain the token
+		It("never emits the resolved secrets", func() {
+			// Arrange
+			cfg.Auth.JWTFilePath = tokenFile
+			Expect(config.ResolveSecrets(cfg)).To(Succeed())
+
+			// Act
+			auth := helpers.Flatten(cfg.Auth.DebugMap())
+			all := fmt.Sprintf("%v %v", cfg.DebugMap(), auth)
+
+			// Assert
+			Expect(auth).To(HaveKeyWithValue("JWTFilePath", tokenFile))
+			Expect(auth).ToNot(HaveKey("JWT"))
+			Expect(all).ToNot(ContainSubstring(token))
+		})
+	})
+})
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// watchInterval is how often the configuration file is checked for changes.
+// Polling follows symlinks, so files swapped by a ConfigMap update are seen.
+const watchInterval = 2 * time.Second
+
+// Reloadable holds the settings applied without restarting the agent.
+type Reloadable struct {
+	LogLevel       string
+	LogLevels      map[string]string
+	UpdateInterval time.Duration
+	ConsoleURL     string
+	Features       map[string]bool
+	// PolicyBundleURL is usually changed by the remote configuration
+	PolicyBundleURL string
+	// UpdateChannelURL is usually changed by the remote configuration
+	UpdateChannelURL string
+}
+
+// Reloadable returns the settings of c that can be reloaded.
+func (c *Configuration) Reloadable() Reloadable {
+	return Reloadable{
+		LogLevel:         c.LogLevel,
+		LogLevels:        maps.Clone(c.LogLevels),
+		UpdateInterval:   c.Agent.UpdateInterval,
+		ConsoleURL:       c.Console.URL,
+		Features:         maps.Clone(c.Features),
+		PolicyBundleURL:  c.Agent.PolicyBundleURL,
+		UpdateChannelURL: c.Agent.UpdateChannelURL,
+	}
+}
+
+// Watcher reloads the configuration on Reload, typically called on SIGHUP,
+// and when the configuration file changes on disk. load builds the new
+// configuration; apply receives the reloadable settings before and after
+// the reload when they differ. Other settings still need a restart.
+type Watcher struct {
+	path  string
+	load  func() (*Configuration, error)
+	apply func(prev, next Reloadable) error
+
+	mu      sync.Mutex
+	current Reloadable
+	modTime time.Time
+}
+
+// NewWatcher returns a watcher of the file at path, which may be empty to only
+// reload on Reload. current holds the settings in use.
+func NewWatcher(path string, current Reloadable, load func() (*Configuration, error), apply func(prev, next Reloadable) error) *Watcher {
+	w := &Watcher{
+		path:    path,
+		load:    load,
+		apply:   apply,
+		current: current,
+	}
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			w.modTime = info.ModTime()
+		}
+	}
+	return w
+}
+
+// Reload loads the configuration and applies the reloadable settings that changed.
+// The settings in use are kept when loading or applying fails.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg, err := w.load()
+	if err != nil {
+		return err
+	}
+
+	next := cfg.Reloadable()
+	if reflect.DeepEqual(next, w.current) {
+		return nil
+	}
+
+	if err := w.apply(w.current, next); err != nil {
+		return err
+	}
+	w.current = next
+	return nil
+}
+
+// Run reloads the configuration whenever the file modification time changes,
+// until ctx is done. It returns immediately when the watcher has no file.
+func (w *Watcher) Run(ctx context.Context) {
+	if w.path == "" {
+		return
+	}
+
+	tick := time.NewTicker(watchInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+
+		info, err := os.Stat(w.path)
+		if err != nil || info.ModTime().Equal(w.modTime) {
+			continue
+		}
+		w.modTime = info.ModTime()
+
+		if err := w.Reload(); err != nil {
+			zap.S().Named("config").Errorw("failed to reload configuration", "file", w.path, "error", err)
+			continue
+		}
+		zap.S().Named("config").Infow("configuration reloaded", "file", w.path)
+	}
+}
@@ -0,0 +1,176 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		mu      sync.Mutex
+		loaded  *config.Configuration
+		loadErr error
+		applied []config.Reloadable
+		current config.Reloadable
+	)
+
+	load := func() (*config.Configuration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		cfg := *loaded
+		return &cfg, nil
+	}
+
+	apply := func(prev, next config.Reloadable) error {
+		mu.Lock()
+		defer mu.Unlock()
+		applied = append(applied, next)
+		return nil
+	}
+
+	appliedSettings := func() []config.Reloadable {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]config.Reloadable(nil), applied...)
+	}
+
+	BeforeEach(func() {
+		loaded = config.NewConfigurationWithOptionsAndDefaults()
+		loaded.LogLevel = "info"
+		loadErr = nil
+		applied = nil
+		current = loaded.Reloadable()
+	})
+
+	// Given a configuration whose reloadable settings did not change
+	// When we reload it
+	// Then nothing should be applied
+	It("does not apply unchanged settings", func() {
+		// Arrange
+		w := config.NewWatcher("", current, load, apply)
+
+		// Act
+		err := w.Reload()
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(appliedSettings()).To(BeEmpty())
+	})
+
+	// Given a configuration with a new log level and update interval
+	// When we reload it twice
+	// Then the new settings should be applied once
+	It("applies the changed settings", func() {
+		// Arrange
+		w := config.NewWatcher("", current, load, apply)
+		loaded.LogLevel = "debug"
+		loaded.Agent.UpdateInterval = time.Minute
+
+		// Act
+		Expect(w.Reload()).To(Succeed())
+		err := w.Reload()
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(appliedSettings()).To(Equal([]config.Reloadable{{
+			LogLevel:       "debug",
+			UpdateInterval: time.Minute,
+			ConsoleURL:     loaded.Console.URL,
+		}}))
+	})
+
+	// Given a configuration with new component log levels
+	// When we reload it
+	// Then the new levels should be applied
+	It("applies changed component log levels", func() {
+		// Arrange
+		w := config.NewWatcher("", current, load, apply)
+		loaded.LogLevels = map[string]string{"store": "debug"}
+
+		// Act
+		err := w.Reload()
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(appliedSettings()).To(HaveLen(1))
+		Expect(appliedSettings()[0].LogLevels).To(Equal(map[string]string{"store": "debug"}))
+	})
+
+	// Given a configuration that fails to load
+	// When we reload it
+	// Then the error should be returned and nothing applied
+	It("keeps the current settings when loading fails", func() {
+		// Arrange
+		w := config.NewWatcher("", current, load, apply)
+		loadErr = errors.New("bad file")
+
+		// Act
+		err := w.Reload()
+
+		// Assert
+		Expect(err).To(MatchError("bad file"))
+		Expect(appliedSettings()).To(BeEmpty())
+	})
+
+	// Given a configuration whose settings fail to apply
+	// When we reload it again after the failure
+	// Then the settings should be applied again
+	It("retries the settings that failed to apply", func() {
+		// Arrange
+		attempts := 0
+		w := config.NewWatcher("", current, load, func(prev, next config.Reloadable) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("apply failed")
+			}
+			return nil
+		})
+		loaded.Console.URL = "https://console.example.com"
+
+		// Act
+		firstErr := w.Reload()
+		err := w.Reload()
+
+		// Assert
+		Expect(firstErr).To(MatchError("apply failed"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+	})
+
+	// Given a watched configuration file
+	// When the file is modified
+	// Then the configuration should be reloaded
+	It("reloads when the file changes", func() {
+		// Arrange
+		path := filepath.Join(GinkgoT().TempDir(), "agent.yaml")
+		Expect(os.WriteFile(path, []byte("logLevel: info\n"), 0o600)).To(Succeed())
+		w := config.NewWatcher(path, current, load, apply)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Run(ctx)
+
+		// Act
+		mu.Lock()
+		loaded.LogLevel = "warn"
+		mu.Unlock()
+		Expect(os.WriteFile(path, []byte("logLevel: warn\n"), 0o600)).To(Succeed())
+		Expect(os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute))).To(Succeed())
+
+		// Assert
+		Eventually(appliedSettings).WithTimeout(6 * time.Second).Should(HaveLen(1))
+		Expect(appliedSettings()[0].LogLevel).To(Equal("warn"))
+	})
+})
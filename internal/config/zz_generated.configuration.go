@@ -36,8 +36,19 @@ func (c *Configuration) ToOption() ConfigurationOption {
 		to.Agent = c.Agent
 		to.Auth = c.Auth
 		to.Console = c.Console
+		to.Proxy = c.Proxy
+		to.Tracing = c.Tracing
+		to.Features = c.Features
 		to.LogFormat = c.LogFormat
 		to.LogLevel = c.LogLevel
+		to.LogLevels = c.LogLevels
+		to.LogFile = c.LogFile
+		to.LogFileMaxSize = c.LogFileMaxSize
+		to.LogFileMaxAge = c.LogFileMaxAge
+		to.LogFileMaxBackups = c.LogFileMaxBackups
+		to.Profile = c.Profile
+		to.ConfigFile = c.ConfigFile
+		to.sources = c.sources
 	}
 }
 
@@ -48,8 +59,18 @@ func (c *Configuration) DebugMap() map[string]any {
 	debugMap["Agent"] = helpers.DebugValue(c.Agent, false)
 	debugMap["Auth"] = helpers.DebugValue(c.Auth, false)
 	debugMap["Console"] = helpers.DebugValue(c.Console, false)
+	debugMap["Proxy"] = helpers.DebugValue(c.Proxy, false)
+	debugMap["Tracing"] = helpers.DebugValue(c.Tracing, false)
+	debugMap["Features"] = helpers.DebugValue(c.Features, false)
 	debugMap["LogFormat"] = helpers.DebugValue(c.LogFormat, false)
 	debugMap["LogLevel"] = helpers.DebugValue(c.LogLevel, false)
+	debugMap["LogLevels"] = helpers.DebugValue(c.LogLevels, false)
+	debugMap["LogFile"] = helpers.DebugValue(c.LogFile, false)
+	debugMap["LogFileMaxSize"] = helpers.DebugValue(c.LogFileMaxSize, false)
+	debugMap["LogFileMaxAge"] = helpers.DebugValue(c.LogFileMaxAge, false)
+	debugMap["LogFileMaxBackups"] = helpers.DebugValue(c.LogFileMaxBackups, false)
+	debugMap["Profile"] = helpers.DebugValue(c.Profile, false)
+	debugMap["ConfigFile"] = helpers.DebugValue(c.ConfigFile, false)
 	return debugMap
 }
 
@@ -97,6 +118,34 @@ func WithConsole(console Console) ConfigurationOption {
 	}
 }
 
+// WithProxy returns an option that can set Proxy on a Configuration
+func WithProxy(proxy Proxy) ConfigurationOption {
+	return func(c *Configuration) {
+		c.Proxy = proxy
+	}
+}
+
+// WithTracing returns an option that can set Tracing on a Configuration
+func WithTracing(tracing Tracing) ConfigurationOption {
+	return func(c *Configuration) {
+		c.Tracing = tracing
+	}
+}
+
+// WithFeatures returns an option that can append Featuress to Configuration.Features
+func WithFeatures(key string, value bool) ConfigurationOption {
+	return func(c *Configuration) {
+		c.Features[key] = value
+	}
+}
+
+// SetFeatures returns an option that can set Features on a Configuration
+func SetFeatures(features map[string]bool) ConfigurationOption {
+	return func(c *Configuration) {
+		c.Features = features
+	}
+}
+
 // WithLogFormat returns an option that can set LogFormat on a Configuration
 func WithLogFormat(logFormat string) ConfigurationOption {
 	return func(c *Configuration) {
@@ -111,6 +160,62 @@ func WithLogLevel(logLevel string) ConfigurationOption {
 	}
 }
 
+// WithLogLevels returns an option that can append LogLevelss to Configuration.LogLevels
+func WithLogLevels(key string, value string) ConfigurationOption {
+	return func(c *Configuration) {
+		c.LogLevels[key] = value
+	}
+}
+
+// SetLogLevels returns an option that can set LogLevels on a Configuration
+func SetLogLevels(logLevels map[string]string) ConfigurationOption {
+	return func(c *Configuration) {
+		c.LogLevels = logLevels
+	}
+}
+
+// WithLogFile returns an option that can set LogFile on a Configuration
+func WithLogFile(logFile string) ConfigurationOption {
+	return func(c *Configuration) {
+		c.LogFile = logFile
+	}
+}
+
+// WithLogFileMaxSize returns an option that can set LogFileMaxSize on a Configuration
+func WithLogFileMaxSize(logFileMaxSize ByteSize) ConfigurationOption {
+	return func(c *Configuration) {
+		c.LogFileMaxSize = logFileMaxSize
+	}
+}
+
+// WithLogFileMaxAge returns an option that can set LogFileMaxAge on a Configuration
+func WithLogFileMaxAge(logFileMaxAge time.Duration) ConfigurationOption {
+	return func(c *Configuration) {
+		c.LogFileMaxAge = logFileMaxAge
+	}
+}
+
+// WithLogFileMaxBackups returns an option that can set LogFileMaxBackups on a Configuration
+func WithLogFileMaxBackups(logFileMaxBackups int) ConfigurationOption {
+	return func(c *Configuration) {
+		c.LogFileMaxBackups = logFileMaxBackups
+	}
+}
+
+// WithProfile returns an option that can set Profile on a Configuration
+func WithProfile(profile string) ConfigurationOption {
+	return func(c *Configuration) {
+		c.Profile = profile
+	}
+}
+
+// WithConfigFile returns an option that can set ConfigFile on a Configuration
+func WithConfigFile(configFile string) ConfigurationOption {
+	return func(c *Configuration) {
+		c.ConfigFile = configFile
+	}
+}
+
 type ServerOption func(s *Server)
 
 // NewServerWithOptions creates a new Server with the passed in options set
@@ -138,6 +243,37 @@ func (s *Server) ToOption() ServerOption {
 		to.ServerMode = s.ServerMode
 		to.HTTPPort = s.HTTPPort
 		to.StaticsFolder = s.StaticsFolder
+		to.UnixSocketPath = s.UnixSocketPath
+		to.UnixSocketOnly = s.UnixSocketOnly
+		to.TLSCertFile = s.TLSCertFile
+		to.TLSKeyFile = s.TLSKeyFile
+		to.ClientCAFile = s.ClientCAFile
+		to.ACMEDomains = s.ACMEDomains
+		to.ACMEEmail = s.ACMEEmail
+		to.ACMEDirectoryURL = s.ACMEDirectoryURL
+		to.ACMEHTTPPort = s.ACMEHTTPPort
+		to.CORSAllowedOrigins = s.CORSAllowedOrigins
+		to.CORSAllowedMethods = s.CORSAllowedMethods
+		to.CORSAllowedHeaders = s.CORSAllowedHeaders
+		to.RateLimitRPS = s.RateLimitRPS
+		to.RateLimitBurst = s.RateLimitBurst
+		to.RateLimitExemptPaths = s.RateLimitExemptPaths
+		to.TrustedProxies = s.TrustedProxies
+		to.CompressionEnabled = s.CompressionEnabled
+		to.CompressionMinSize = s.CompressionMinSize
+		to.ReadHeaderTimeout = s.ReadHeaderTimeout
+		to.ReadTimeout = s.ReadTimeout
+		to.WriteTimeout = s.WriteTimeout
+		to.IdleTimeout = s.IdleTimeout
+		to.MaxRequestBodySize = s.MaxRequestBodySize
+		to.PprofEnabled = s.PprofEnabled
+		to.MetricsEnabled = s.MetricsEnabled
+		to.AccessLogFile = s.AccessLogFile
+		to.AccessLogMaxSize = s.AccessLogMaxSize
+		to.AccessLogMaxAge = s.AccessLogMaxAge
+		to.AccessLogMaxBackups = s.AccessLogMaxBackups
+		to.AdminPort = s.AdminPort
+		to.AdminUnixSocketPath = s.AdminUnixSocketPath
 	}
 }
 
@@ -147,6 +283,37 @@ func (s *Server) DebugMap() map[string]any {
 	debugMap["ServerMode"] = helpers.DebugValue(s.ServerMode, false)
 	debugMap["HTTPPort"] = helpers.DebugValue(s.HTTPPort, false)
 	debugMap["StaticsFolder"] = helpers.DebugValue(s.StaticsFolder, false)
+	debugMap["UnixSocketPath"] = helpers.DebugValue(s.UnixSocketPath, false)
+	debugMap["UnixSocketOnly"] = helpers.DebugValue(s.UnixSocketOnly, false)
+	debugMap["TLSCertFile"] = helpers.DebugValue(s.TLSCertFile, false)
+	debugMap["TLSKeyFile"] = helpers.DebugValue(s.TLSKeyFile, false)
+	debugMap["ClientCAFile"] = helpers.DebugValue(s.ClientCAFile, false)
+	debugMap["ACMEDomains"] = helpers.DebugValue(s.ACMEDomains, false)
+	debugMap["ACMEEmail"] = helpers.DebugValue(s.ACMEEmail, false)
+	debugMap["ACMEDirectoryURL"] = helpers.DebugValue(s.ACMEDirectoryURL, false)
+	debugMap["ACMEHTTPPort"] = helpers.DebugValue(s.ACMEHTTPPort, false)
+	debugMap["CORSAllowedOrigins"] = helpers.DebugValue(s.CORSAllowedOrigins, false)
+	debugMap["CORSAllowedMethods"] = helpers.DebugValue(s.CORSAllowedMethods, false)
+	debugMap["CORSAllowedHeaders"] = helpers.DebugValue(s.CORSAllowedHeaders, false)
+	debugMap["RateLimitRPS"] = helpers.DebugValue(s.RateLimitRPS, false)
+	debugMap["RateLimitBurst"] = helpers.DebugValue(s.RateLimitBurst, false)
+	debugMap["RateLimitExemptPaths"] = helpers.DebugValue(s.RateLimitExemptPaths, false)
+	debugMap["TrustedProxies"] = helpers.DebugValue(s.TrustedProxies, false)
+	debugMap["CompressionEnabled"] = helpers.DebugValue(s.CompressionEnabled, false)
+	debugMap["CompressionMinSize"] = helpers.DebugValue(s.CompressionMinSize, false)
+	debugMap["ReadHeaderTimeout"] = helpers.DebugValue(s.ReadHeaderTimeout, false)
+	debugMap["ReadTimeout"] = helpers.DebugValue(s.ReadTimeout, false)
+	debugMap["WriteTimeout"] = helpers.DebugValue(s.WriteTimeout, false)
+	debugMap["IdleTimeout"] = helpers.DebugValue(s.IdleTimeout, false)
+	debugMap["MaxRequestBodySize"] = helpers.DebugValue(s.MaxRequestBodySize, false)
+	debugMap["PprofEnabled"] = helpers.DebugValue(s.PprofEnabled, false)
+	debugMap["MetricsEnabled"] = helpers.DebugValue(s.MetricsEnabled, false)
+	debugMap["AccessLogFile"] = helpers.DebugValue(s.AccessLogFile, false)
+	debugMap["AccessLogMaxSize"] = helpers.DebugValue(s.AccessLogMaxSize, false)
+	debugMap["AccessLogMaxAge"] = helpers.DebugValue(s.AccessLogMaxAge, false)
+	debugMap["AccessLogMaxBackups"] = helpers.DebugValue(s.AccessLogMaxBackups, false)
+	debugMap["AdminPort"] = helpers.DebugValue(s.AdminPort, false)
+	debugMap["AdminUnixSocketPath"] = helpers.DebugValue(s.AdminUnixSocketPath, false)
 	return debugMap
 }
 
@@ -187,6 +354,265 @@ func WithStaticsFolder(staticsFolder string) ServerOption {
 	}
 }
 
+// WithUnixSocketPath returns an option that can set UnixSocketPath on a Server
+func WithUnixSocketPath(unixSocketPath string) ServerOption {
+	return func(s *Server) {
+		s.UnixSocketPath = unixSocketPath
+	}
+}
+
+// WithUnixSocketOnly returns an option that can set UnixSocketOnly on a Server
+func WithUnixSocketOnly(unixSocketOnly bool) ServerOption {
+	return func(s *Server) {
+		s.UnixSocketOnly = unixSocketOnly
+	}
+}
+
+// WithTLSCertFile returns an option that can set TLSCertFile on a Server
+func WithTLSCertFile(tLSCertFile string) ServerOption {
+	return func(s *Server) {
+		s.TLSCertFile = tLSCertFile
+	}
+}
+
+// WithTLSKeyFile returns an option that can set TLSKeyFile on a Server
+func WithTLSKeyFile(tLSKeyFile string) ServerOption {
+	return func(s *Server) {
+		s.TLSKeyFile = tLSKeyFile
+	}
+}
+
+// WithClientCAFile returns an option that can set ClientCAFile on a Server
+func WithClientCAFile(clientCAFile string) ServerOption {
+	return func(s *Server) {
+		s.ClientCAFile = clientCAFile
+	}
+}
+
+// WithACMEDomains returns an option that can append ACMEDomainss to Server.ACMEDomains
+func WithACMEDomains(aCMEDomains string) ServerOption {
+	return func(s *Server) {
+		s.ACMEDomains = append(s.ACMEDomains, aCMEDomains)
+	}
+}
+
+// SetACMEDomains returns an option that can set ACMEDomains on a Server
+func SetACMEDomains(aCMEDomains []string) ServerOption {
+	return func(s *Server) {
+		s.ACMEDomains = aCMEDomains
+	}
+}
+
+// WithACMEEmail returns an option that can set ACMEEmail on a Server
+func WithACMEEmail(aCMEEmail string) ServerOption {
+	return func(s *Server) {
+		s.ACMEEmail = aCMEEmail
+	}
+}
+
+// WithACMEDirectoryURL returns an option that can set ACMEDirectoryURL on a Server
+func WithACMEDirectoryURL(aCMEDirectoryURL string) ServerOption {
+	return func(s *Server) {
+		s.ACMEDirectoryURL = aCMEDirectoryURL
+	}
+}
+
+// WithACMEHTTPPort returns an option that can set ACMEHTTPPort on a Server
+func WithACMEHTTPPort(aCMEHTTPPort int) ServerOption {
+	return func(s *Server) {
+		s.ACMEHTTPPort = aCMEHTTPPort
+	}
+}
+
+// WithCORSAllowedOrigins returns an option that can append CORSAllowedOriginss to Server.CORSAllowedOrigins
+func WithCORSAllowedOrigins(cORSAllowedOrigins string) ServerOption {
+	return func(s *Server) {
+		s.CORSAllowedOrigins = append(s.CORSAllowedOrigins, cORSAllowedOrigins)
+	}
+}
+
+// SetCORSAllowedOrigins returns an option that can set CORSAllowedOrigins on a Server
+func SetCORSAllowedOrigins(cORSAllowedOrigins []string) ServerOption {
+	return func(s *Server) {
+		s.CORSAllowedOrigins = cORSAllowedOrigins
+	}
+}
+
+// WithCORSAllowedMethods returns an option that can append CORSAllowedMethodss to Server.CORSAllowedMethods
+func WithCORSAllowedMethods(cORSAllowedMethods string) ServerOption {
+	return func(s *Server) {
+		s.CORSAllowedMethods = append(s.CORSAllowedMethods, cORSAllowedMethods)
+	}
+}
+
+// SetCORSAllowedMethods returns an option that can set CORSAllowedMethods on a Server
+func SetCORSAllowedMethods(cORSAllowedMethods []string) ServerOption {
+	return func(s *Server) {
+		s.CORSAllowedMethods = cORSAllowedMethods
+	}
+}
+
+// WithCORSAllowedHeaders returns an option that can append CORSAllowedHeaderss to Server.CORSAllowedHeaders
+func WithCORSAllowedHeaders(cORSAllowedHeaders string) ServerOption {
+	return func(s *Server) {
+		s.CORSAllowedHeaders = append(s.CORSAllowedHeaders, cORSAllowedHeaders)
+	}
+}
+
+// SetCORSAllowedHeaders returns an option that can set CORSAllowedHeaders on a Server
+func SetCORSAllowedHeaders(cORSAllowedHeaders []string) ServerOption {
+	return func(s *Server) {
+		s.CORSAllowedHeaders = cORSAllowedHeaders
+	}
+}
+
+// WithRateLimitRPS returns an option that can set RateLimitRPS on a Server
+func WithRateLimitRPS(rateLimitRPS float64) ServerOption {
+	return func(s *Server) {
+		s.RateLimitRPS = rateLimitRPS
+	}
+}
+
+// WithRateLimitBurst returns an option that can set RateLimitBurst on a Server
+func WithRateLimitBurst(rateLimitBurst int) ServerOption {
+	return func(s *Server) {
+		s.RateLimitBurst = rateLimitBurst
+	}
+}
+
+// WithRateLimitExemptPaths returns an option that can append RateLimitExemptPathss to Server.RateLimitExemptPaths
+func WithRateLimitExemptPaths(rateLimitExemptPaths string) ServerOption {
+	return func(s *Server) {
+		s.RateLimitExemptPaths = append(s.RateLimitExemptPaths, rateLimitExemptPaths)
+	}
+}
+
+// SetRateLimitExemptPaths returns an option that can set RateLimitExemptPaths on a Server
+func SetRateLimitExemptPaths(rateLimitExemptPaths []string) ServerOption {
+	return func(s *Server) {
+		s.RateLimitExemptPaths = rateLimitExemptPaths
+	}
+}
+
+// WithTrustedProxies returns an option that can append TrustedProxiess to Server.TrustedProxies
+func WithTrustedProxies(trustedProxies string) ServerOption {
+	return func(s *Server) {
+		s.TrustedProxies = append(s.TrustedProxies, trustedProxies)
+	}
+}
+
+// SetTrustedProxies returns an option that can set TrustedProxies on a Server
+func SetTrustedProxies(trustedProxies []string) ServerOption {
+	return func(s *Server) {
+		s.TrustedProxies = trustedProxies
+	}
+}
+
+// WithCompressionEnabled returns an option that can set CompressionEnabled on a Server
+func WithCompressionEnabled(compressionEnabled bool) ServerOption {
+	return func(s *Server) {
+		s.CompressionEnabled = compressionEnabled
+	}
+}
+
+// WithCompressionMinSize returns an option that can set CompressionMinSize on a Server
+func WithCompressionMinSize(compressionMinSize int) ServerOption {
+	return func(s *Server) {
+		s.CompressionMinSize = compressionMinSize
+	}
+}
+
+// WithReadHeaderTimeout returns an option that can set ReadHeaderTimeout on a Server
+func WithReadHeaderTimeout(readHeaderTimeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.ReadHeaderTimeout = readHeaderTimeout
+	}
+}
+
+// WithReadTimeout returns an option that can set ReadTimeout on a Server
+func WithReadTimeout(readTimeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.ReadTimeout = readTimeout
+	}
+}
+
+// WithWriteTimeout returns an option that can set WriteTimeout on a Server
+func WithWriteTimeout(writeTimeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.WriteTimeout = writeTimeout
+	}
+}
+
+// WithIdleTimeout returns an option that can set IdleTimeout on a Server
+func WithIdleTimeout(idleTimeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.IdleTimeout = idleTimeout
+	}
+}
+
+// WithMaxRequestBodySize returns an option that can set MaxRequestBodySize on a Server
+func WithMaxRequestBodySize(maxRequestBodySize ByteSize) ServerOption {
+	return func(s *Server) {
+		s.MaxRequestBodySize = maxRequestBodySize
+	}
+}
+
+// WithPprofEnabled returns an option that can set PprofEnabled on a Server
+func WithPprofEnabled(pprofEnabled bool) ServerOption {
+	return func(s *Server) {
+		s.PprofEnabled = pprofEnabled
+	}
+}
+
+// WithMetricsEnabled returns an option that can set MetricsEnabled on a Server
+func WithMetricsEnabled(metricsEnabled bool) ServerOption {
+	return func(s *Server) {
+		s.MetricsEnabled = metricsEnabled
+	}
+}
+
+// WithAccessLogFile returns an option that can set AccessLogFile on a Server
+func WithAccessLogFile(accessLogFile string) ServerOption {
+	return func(s *Server) {
+		s.AccessLogFile = accessLogFile
+	}
+}
+
+// WithAccessLogMaxSize returns an option that can set AccessLogMaxSize on a Server
+func WithAccessLogMaxSize(accessLogMaxSize ByteSize) ServerOption {
+	return func(s *Server) {
+		s.AccessLogMaxSize = accessLogMaxSize
+	}
+}
+
+// WithAccessLogMaxAge returns an option that can set AccessLogMaxAge on a Server
+func WithAccessLogMaxAge(accessLogMaxAge time.Duration) ServerOption {
+	return func(s *Server) {
+		s.AccessLogMaxAge = accessLogMaxAge
+	}
+}
+
+// WithAccessLogMaxBackups returns an option that can set AccessLogMaxBackups on a Server
+func WithAccessLogMaxBackups(accessLogMaxBackups int) ServerOption {
+	return func(s *Server) {
+		s.AccessLogMaxBackups = accessLogMaxBackups
+	}
+}
+
+// WithAdminPort returns an option that can set AdminPort on a Server
+func WithAdminPort(adminPort int) ServerOption {
+	return func(s *Server) {
+		s.AdminPort = adminPort
+	}
+}
+
+// WithAdminUnixSocketPath returns an option that can set AdminUnixSocketPath on a Server
+func WithAdminUnixSocketPath(adminUnixSocketPath string) ServerOption {
+	return func(s *Server) {
+		s.AdminUnixSocketPath = adminUnixSocketPath
+	}
+}
+
 type AgentOption func(a *Agent)
 
 // NewAgentWithOptions creates a new Agent with the passed in options set
@@ -221,6 +647,21 @@ func (a *Agent) ToOption() AgentOption {
 		to.OpaPoliciesFolder = a.OpaPoliciesFolder
 		to.UpdateInterval = a.UpdateInterval
 		to.LegacyStatusEnabled = a.LegacyStatusEnabled
+		to.PolicyBundleURL = a.PolicyBundleURL
+		to.UpdateChannelURL = a.UpdateChannelURL
+		to.UpdatePublicKey = a.UpdatePublicKey
+		to.UpdateRestart = a.UpdateRestart
+		to.ResumeCollection = a.ResumeCollection
+		to.SecretBackend = a.SecretBackend
+		to.SecretKeyFilePath = a.SecretKeyFilePath
+		to.KeyringFolder = a.KeyringFolder
+		to.ShutdownTimeout = a.ShutdownTimeout
+		to.QueryExplainThreshold = a.QueryExplainThreshold
+		to.ErrorWebhookURL = a.ErrorWebhookURL
+		to.HALeaseFile = a.HALeaseFile
+		to.HALeaseDuration = a.HALeaseDuration
+		to.HAIdentity = a.HAIdentity
+		to.AssessmentTrustedKeys = a.AssessmentTrustedKeys
 	}
 }
 
@@ -237,6 +678,20 @@ func (a *Agent) DebugMap() map[string]any {
 	debugMap["OpaPoliciesFolder"] = helpers.DebugValue(a.OpaPoliciesFolder, false)
 	debugMap["UpdateInterval"] = helpers.DebugValue(a.UpdateInterval, false)
 	debugMap["LegacyStatusEnabled"] = helpers.DebugValue(a.LegacyStatusEnabled, false)
+	debugMap["PolicyBundleURL"] = helpers.DebugValue(a.PolicyBundleURL, false)
+	debugMap["UpdateChannelURL"] = helpers.DebugValue(a.UpdateChannelURL, false)
+	debugMap["UpdatePublicKey"] = helpers.DebugValue(a.UpdatePublicKey, false)
+	debugMap["UpdateRestart"] = helpers.DebugValue(a.UpdateRestart, false)
+	debugMap["ResumeCollection"] = helpers.DebugValue(a.ResumeCollection, false)
+	debugMap["SecretBackend"] = helpers.DebugValue(a.SecretBackend, false)
+	debugMap["SecretKeyFilePath"] = helpers.DebugValue(a.SecretKeyFilePath, false)
+	debugMap["KeyringFolder"] = helpers.DebugValue(a.KeyringFolder, false)
+	debugMap["ShutdownTimeout"] = helpers.DebugValue(a.ShutdownTimeout, false)
+	debugMap["QueryExplainThreshold"] = helpers.DebugValue(a.QueryExplainThreshold, false)
+	debugMap["HALeaseFile"] = helpers.DebugValue(a.HALeaseFile, false)
+	debugMap["HALeaseDuration"] = helpers.DebugValue(a.HALeaseDuration, false)
+	debugMap["HAIdentity"] = helpers.DebugValue(a.HAIdentity, false)
+	debugMap["AssessmentTrustedKeys"] = helpers.DebugValue(a.AssessmentTrustedKeys, false)
 	return debugMap
 }
 
@@ -326,6 +781,118 @@ func WithLegacyStatusEnabled(legacyStatusEnabled bool) AgentOption {
 	}
 }
 
+// WithPolicyBundleURL returns an option that can set PolicyBundleURL on a Agent
+func WithPolicyBundleURL(policyBundleURL string) AgentOption {
+	return func(a *Agent) {
+		a.PolicyBundleURL = policyBundleURL
+	}
+}
+
+// WithUpdateChannelURL returns an option that can set UpdateChannelURL on a Agent
+func WithUpdateChannelURL(updateChannelURL string) AgentOption {
+	return func(a *Agent) {
+		a.UpdateChannelURL = updateChannelURL
+	}
+}
+
+// WithUpdatePublicKey returns an option that can set UpdatePublicKey on a Agent
+func WithUpdatePublicKey(updatePublicKey string) AgentOption {
+	return func(a *Agent) {
+		a.UpdatePublicKey = updatePublicKey
+	}
+}
+
+// WithUpdateRestart returns an option that can set UpdateRestart on a Agent
+func WithUpdateRestart(updateRestart string) AgentOption {
+	return func(a *Agent) {
+		a.UpdateRestart = updateRestart
+	}
+}
+
+// WithResumeCollection returns an option that can set ResumeCollection on a Agent
+func WithResumeCollection(resumeCollection bool) AgentOption {
+	return func(a *Agent) {
+		a.ResumeCollection = resumeCollection
+	}
+}
+
+// WithSecretBackend returns an option that can set SecretBackend on a Agent
+func WithSecretBackend(secretBackend string) AgentOption {
+	return func(a *Agent) {
+		a.SecretBackend = secretBackend
+	}
+}
+
+// WithSecretKeyFilePath returns an option that can set SecretKeyFilePath on a Agent
+func WithSecretKeyFilePath(secretKeyFilePath string) AgentOption {
+	return func(a *Agent) {
+		a.SecretKeyFilePath = secretKeyFilePath
+	}
+}
+
+// WithKeyringFolder returns an option that can set KeyringFolder on a Agent
+func WithKeyringFolder(keyringFolder string) AgentOption {
+	return func(a *Agent) {
+		a.KeyringFolder = keyringFolder
+	}
+}
+
+// WithShutdownTimeout returns an option that can set ShutdownTimeout on a Agent
+func WithShutdownTimeout(shutdownTimeout time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.ShutdownTimeout = shutdownTimeout
+	}
+}
+
+// WithQueryExplainThreshold returns an option that can set QueryExplainThreshold on a Agent
+func WithQueryExplainThreshold(queryExplainThreshold time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.QueryExplainThreshold = queryExplainThreshold
+	}
+}
+
+// WithErrorWebhookURL returns an option that can set ErrorWebhookURL on a Agent
+func WithErrorWebhookURL(errorWebhookURL string) AgentOption {
+	return func(a *Agent) {
+		a.ErrorWebhookURL = errorWebhookURL
+	}
+}
+
+// WithHALeaseFile returns an option that can set HALeaseFile on a Agent
+func WithHALeaseFile(hALeaseFile string) AgentOption {
+	return func(a *Agent) {
+		a.HALeaseFile = hALeaseFile
+	}
+}
+
+// WithHALeaseDuration returns an option that can set HALeaseDuration on a Agent
+func WithHALeaseDuration(hALeaseDuration time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.HALeaseDuration = hALeaseDuration
+	}
+}
+
+// WithHAIdentity returns an option that can set HAIdentity on a Agent
+func WithHAIdentity(hAIdentity string) AgentOption {
+	return func(a *Agent) {
+		a.HAIdentity = hAIdentity
+	}
+}
+
+// WithAssessmentTrustedKeys returns an option that can append AssessmentTrustedKeyss to Agent.AssessmentTrustedKeys
+func WithAssessmentTrustedKeys(assessmentTrustedKeys string) AgentOption {
+	return func(a *Agent) {
+		a.AssessmentTrustedKeys = append(a.AssessmentTrustedKeys, assessmentTrustedKeys)
+	}
+}
+
+// SetAssessmentTrustedKeys returns an option that can set AssessmentTrustedKeys on a Agent
+func SetAssessmentTrustedKeys(assessmentTrustedKeys []string) AgentOption {
+	return func(a *Agent) {
+		a.AssessmentTrustedKeys = assessmentTrustedKeys
+	}
+}
+
 type ConsoleOption func(c *Console)
 
 // NewConsoleWithOptions creates a new Console with the passed in options set
@@ -351,6 +918,9 @@ func NewConsoleWithOptionsAndDefaults(opts ...ConsoleOption) *Console {
 func (c *Console) ToOption() ConsoleOption {
 	return func(to *Console) {
 		to.URL = c.URL
+		to.RemoteConfigEnabled = c.RemoteConfigEnabled
+		to.RemoteConfigInterval = c.RemoteConfigInterval
+		to.CommandChannelEnabled = c.CommandChannelEnabled
 	}
 }
 
@@ -358,6 +928,9 @@ func (c *Console) ToOption() ConsoleOption {
 func (c *Console) DebugMap() map[string]any {
 	debugMap := map[string]any{}
 	debugMap["URL"] = helpers.DebugValue(c.URL, false)
+	debugMap["RemoteConfigEnabled"] = helpers.DebugValue(c.RemoteConfigEnabled, false)
+	debugMap["RemoteConfigInterval"] = helpers.DebugValue(c.RemoteConfigInterval, false)
+	debugMap["CommandChannelEnabled"] = helpers.DebugValue(c.CommandChannelEnabled, false)
 	return debugMap
 }
 
@@ -384,6 +957,27 @@ func WithURL(uRL string) ConsoleOption {
 	}
 }
 
+// WithRemoteConfigEnabled returns an option that can set RemoteConfigEnabled on a Console
+func WithRemoteConfigEnabled(remoteConfigEnabled bool) ConsoleOption {
+	return func(c *Console) {
+		c.RemoteConfigEnabled = remoteConfigEnabled
+	}
+}
+
+// WithRemoteConfigInterval returns an option that can set RemoteConfigInterval on a Console
+func WithRemoteConfigInterval(remoteConfigInterval time.Duration) ConsoleOption {
+	return func(c *Console) {
+		c.RemoteConfigInterval = remoteConfigInterval
+	}
+}
+
+// WithCommandChannelEnabled returns an option that can set CommandChannelEnabled on a Console
+func WithCommandChannelEnabled(commandChannelEnabled bool) ConsoleOption {
+	return func(c *Console) {
+		c.CommandChannelEnabled = commandChannelEnabled
+	}
+}
+
 type AuthenticationOption func(a *Authentication)
 
 // NewAuthenticationWithOptions creates a new Authentication with the passed in options set
@@ -410,6 +1004,21 @@ func (a *Authentication) ToOption() AuthenticationOption {
 	return func(to *Authentication) {
 		to.Enabled = a.Enabled
 		to.JWTFilePath = a.JWTFilePath
+		to.JWT = a.JWT
+		to.LocalToken = a.LocalToken
+		to.LocalTokenFilePath = a.LocalTokenFilePath
+		to.ProvisioningToken = a.ProvisioningToken
+		to.ProvisioningTokenFilePath = a.ProvisioningTokenFilePath
+		to.JWKSURL = a.JWKSURL
+		to.Issuer = a.Issuer
+		to.Audience = a.Audience
+		to.RoleClaim = a.RoleClaim
+		to.ExemptPaths = a.ExemptPaths
+		to.MaxLoginFailures = a.MaxLoginFailures
+		to.LoginLockout = a.LoginLockout
+		to.DeviceLoginEnabled = a.DeviceLoginEnabled
+		to.SSOURL = a.SSOURL
+		to.SSOClientID = a.SSOClientID
 	}
 }
 
@@ -418,6 +1027,18 @@ func (a *Authentication) DebugMap() map[string]any {
 	debugMap := map[string]any{}
 	debugMap["Enabled"] = helpers.DebugValue(a.Enabled, false)
 	debugMap["JWTFilePath"] = helpers.DebugValue(a.JWTFilePath, false)
+	debugMap["LocalTokenFilePath"] = helpers.DebugValue(a.LocalTokenFilePath, false)
+	debugMap["ProvisioningTokenFilePath"] = helpers.DebugValue(a.ProvisioningTokenFilePath, false)
+	debugMap["JWKSURL"] = helpers.DebugValue(a.JWKSURL, false)
+	debugMap["Issuer"] = helpers.DebugValue(a.Issuer, false)
+	debugMap["Audience"] = helpers.DebugValue(a.Audience, false)
+	debugMap["RoleClaim"] = helpers.DebugValue(a.RoleClaim, false)
+	debugMap["ExemptPaths"] = helpers.DebugValue(a.ExemptPaths, false)
+	debugMap["MaxLoginFailures"] = helpers.DebugValue(a.MaxLoginFailures, false)
+	debugMap["LoginLockout"] = helpers.DebugValue(a.LoginLockout, false)
+	debugMap["DeviceLoginEnabled"] = helpers.DebugValue(a.DeviceLoginEnabled, false)
+	debugMap["SSOURL"] = helpers.DebugValue(a.SSOURL, false)
+	debugMap["SSOClientID"] = helpers.DebugValue(a.SSOClientID, false)
 	return debugMap
 }
 
@@ -450,3 +1071,292 @@ func WithJWTFilePath(jWTFilePath string) AuthenticationOption {
 		a.JWTFilePath = jWTFilePath
 	}
 }
+
+// WithJWT returns an option that can set JWT on a Authentication
+func WithJWT(jWT string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.JWT = jWT
+	}
+}
+
+// WithLocalToken returns an option that can set LocalToken on a Authentication
+func WithLocalToken(localToken string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.LocalToken = localToken
+	}
+}
+
+// WithLocalTokenFilePath returns an option that can set LocalTokenFilePath on a Authentication
+func WithLocalTokenFilePath(localTokenFilePath string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.LocalTokenFilePath = localTokenFilePath
+	}
+}
+
+// WithProvisioningToken returns an option that can set ProvisioningToken on a Authentication
+func WithProvisioningToken(provisioningToken string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.ProvisioningToken = provisioningToken
+	}
+}
+
+// WithProvisioningTokenFilePath returns an option that can set ProvisioningTokenFilePath on a Authentication
+func WithProvisioningTokenFilePath(provisioningTokenFilePath string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.ProvisioningTokenFilePath = provisioningTokenFilePath
+	}
+}
+
+// WithJWKSURL returns an option that can set JWKSURL on a Authentication
+func WithJWKSURL(jWKSURL string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.JWKSURL = jWKSURL
+	}
+}
+
+// WithIssuer returns an option that can set Issuer on a Authentication
+func WithIssuer(issuer string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.Issuer = issuer
+	}
+}
+
+// WithAudience returns an option that can set Audience on a Authentication
+func WithAudience(audience string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.Audience = audience
+	}
+}
+
+// WithRoleClaim returns an option that can set RoleClaim on a Authentication
+func WithRoleClaim(roleClaim string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.RoleClaim = roleClaim
+	}
+}
+
+// WithExemptPaths returns an option that can append ExemptPathss to Authentication.ExemptPaths
+func WithExemptPaths(exemptPaths string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.ExemptPaths = append(a.ExemptPaths, exemptPaths)
+	}
+}
+
+// SetExemptPaths returns an option that can set ExemptPaths on a Authentication
+func SetExemptPaths(exemptPaths []string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.ExemptPaths = exemptPaths
+	}
+}
+
+// WithMaxLoginFailures returns an option that can set MaxLoginFailures on a Authentication
+func WithMaxLoginFailures(maxLoginFailures int) AuthenticationOption {
+	return func(a *Authentication) {
+		a.MaxLoginFailures = maxLoginFailures
+	}
+}
+
+// WithLoginLockout returns an option that can set LoginLockout on a Authentication
+func WithLoginLockout(loginLockout time.Duration) AuthenticationOption {
+	return func(a *Authentication) {
+		a.LoginLockout = loginLockout
+	}
+}
+
+// WithDeviceLoginEnabled returns an option that can set DeviceLoginEnabled on a Authentication
+func WithDeviceLoginEnabled(deviceLoginEnabled bool) AuthenticationOption {
+	return func(a *Authentication) {
+		a.DeviceLoginEnabled = deviceLoginEnabled
+	}
+}
+
+// WithSSOURL returns an option that can set SSOURL on a Authentication
+func WithSSOURL(sSOURL string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.SSOURL = sSOURL
+	}
+}
+
+// WithSSOClientID returns an option that can set SSOClientID on a Authentication
+func WithSSOClientID(sSOClientID string) AuthenticationOption {
+	return func(a *Authentication) {
+		a.SSOClientID = sSOClientID
+	}
+}
+
+type ProxyOption func(p *Proxy)
+
+// NewProxyWithOptions creates a new Proxy with the passed in options set
+func NewProxyWithOptions(opts ...ProxyOption) *Proxy {
+	p := &Proxy{}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// NewProxyWithOptionsAndDefaults creates a new Proxy with the passed in options set starting from the defaults
+func NewProxyWithOptionsAndDefaults(opts ...ProxyOption) *Proxy {
+	p := &Proxy{}
+	defaults.MustSet(p)
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// ToOption returns a new ProxyOption that sets the values from the passed in Proxy
+func (p *Proxy) ToOption() ProxyOption {
+	return func(to *Proxy) {
+		to.HTTPProxy = p.HTTPProxy
+		to.HTTPSProxy = p.HTTPSProxy
+		to.NoProxy = p.NoProxy
+		to.ConsoleProxy = p.ConsoleProxy
+		to.VCenterProxy = p.VCenterProxy
+	}
+}
+
+// DebugMap returns a map form of Proxy for debugging
+func (p *Proxy) DebugMap() map[string]any {
+	debugMap := map[string]any{}
+	debugMap["HTTPProxy"] = helpers.DebugValue(p.HTTPProxy, false)
+	debugMap["HTTPSProxy"] = helpers.DebugValue(p.HTTPSProxy, false)
+	debugMap["NoProxy"] = helpers.DebugValue(p.NoProxy, false)
+	debugMap["ConsoleProxy"] = helpers.DebugValue(p.ConsoleProxy, false)
+	debugMap["VCenterProxy"] = helpers.DebugValue(p.VCenterProxy, false)
+	return debugMap
+}
+
+// ProxyWithOptions configures an existing Proxy with the passed in options set
+func ProxyWithOptions(p *Proxy, opts ...ProxyOption) *Proxy {
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// WithOptions configures the receiver Proxy with the passed in options set
+func (p *Proxy) WithOptions(opts ...ProxyOption) *Proxy {
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// WithHTTPProxy returns an option that can set HTTPProxy on a Proxy
+func WithHTTPProxy(hTTPProxy string) ProxyOption {
+	return func(p *Proxy) {
+		p.HTTPProxy = hTTPProxy
+	}
+}
+
+// WithHTTPSProxy returns an option that can set HTTPSProxy on a Proxy
+func WithHTTPSProxy(hTTPSProxy string) ProxyOption {
+	return func(p *Proxy) {
+		p.HTTPSProxy = hTTPSProxy
+	}
+}
+
+// WithNoProxy returns an option that can append NoProxys to Proxy.NoProxy
+func WithNoProxy(noProxy string) ProxyOption {
+	return func(p *Proxy) {
+		p.NoProxy = append(p.NoProxy, noProxy)
+	}
+}
+
+// SetNoProxy returns an option that can set NoProxy on a Proxy
+func SetNoProxy(noProxy []string) ProxyOption {
+	return func(p *Proxy) {
+		p.NoProxy = noProxy
+	}
+}
+
+// WithConsoleProxy returns an option that can set ConsoleProxy on a Proxy
+func WithConsoleProxy(consoleProxy string) ProxyOption {
+	return func(p *Proxy) {
+		p.ConsoleProxy = consoleProxy
+	}
+}
+
+// WithVCenterProxy returns an option that can set VCenterProxy on a Proxy
+func WithVCenterProxy(vCenterProxy string) ProxyOption {
+	return func(p *Proxy) {
+		p.VCenterProxy = vCenterProxy
+	}
+}
+
+type TracingOption func(t *Tracing)
+
+// NewTracingWithOptions creates a new Tracing with the passed in options set
+func NewTracingWithOptions(opts ...TracingOption) *Tracing {
+	t := &Tracing{}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// NewTracingWithOptionsAndDefaults creates a new Tracing with the passed in options set starting from the defaults
+func NewTracingWithOptionsAndDefaults(opts ...TracingOption) *Tracing {
+	t := &Tracing{}
+	defaults.MustSet(t)
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// ToOption returns a new TracingOption that sets the values from the passed in Tracing
+func (t *Tracing) ToOption() TracingOption {
+	return func(to *Tracing) {
+		to.Endpoint = t.Endpoint
+		to.SampleRatio = t.SampleRatio
+		to.ServiceName = t.ServiceName
+	}
+}
+
+// DebugMap returns a map form of Tracing for debugging
+func (t *Tracing) DebugMap() map[string]any {
+	debugMap := map[string]any{}
+	debugMap["Endpoint"] = helpers.DebugValue(t.Endpoint, false)
+	debugMap["SampleRatio"] = helpers.DebugValue(t.SampleRatio, false)
+	debugMap["ServiceName"] = helpers.DebugValue(t.ServiceName, false)
+	return debugMap
+}
+
+// TracingWithOptions configures an existing Tracing with the passed in options set
+func TracingWithOptions(t *Tracing, opts ...TracingOption) *Tracing {
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// WithOptions configures the receiver Tracing with the passed in options set
+func (t *Tracing) WithOptions(opts ...TracingOption) *Tracing {
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// WithEndpoint returns an option that can set Endpoint on a Tracing
+func WithEndpoint(endpoint string) TracingOption {
+	return func(t *Tracing) {
+		t.Endpoint = endpoint
+	}
+}
+
+// WithSampleRatio returns an option that can set SampleRatio on a Tracing
+func WithSampleRatio(sampleRatio float64) TracingOption {
+	return func(t *Tracing) {
+		t.SampleRatio = sampleRatio
+	}
+}
+
+// WithServiceName returns an option that can set ServiceName on a Tracing
+func WithServiceName(serviceName string) TracingOption {
+	return func(t *Tracing) {
+		t.ServiceName = serviceName
+	}
+}
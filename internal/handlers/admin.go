@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// Migration is the status of a schema migration returned by GET /admin/migrations.
+type Migration struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// APIKey is an API key returned by the /admin/apikeys endpoints, without the key itself.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreatedAPIKey is returned by POST /admin/apikeys, the only time Key is shown.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyRequest is the body of POST /admin/apikeys. Role defaults to viewer.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+// AuditEntry is a mutating request of the local API returned by GET /admin/audit.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// AuditListResponse is a page of the audit log returned by GET /admin/audit.
+type AuditListResponse struct {
+	v1.Pagination
+	Entries []AuditEntry `json:"entries"`
+}
+
+// StoredCredentials are the vCenter credentials kept by the agent returned by
+// GET /admin/credentials, without the password.
+type StoredCredentials struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+}
+
+// RuntimeStats is the state of the agent process returned by GET /admin/runtime.
+type RuntimeStats struct {
+	Goroutines int              `json:"goroutines"`
+	Memory     RuntimeMemory    `json:"memory"`
+	GC         RuntimeGC        `json:"gc"`
+	Database   RuntimeDatabase  `json:"database"`
+	Scheduler  RuntimeScheduler `json:"scheduler"`
+	Routes     []RuntimeRoute   `json:"routes"`
+}
+
+// RuntimeMemory is the memory of the agent in bytes.
+type RuntimeMemory struct {
+	HeapAlloc   uint64 `json:"heapAlloc"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapObjects uint64 `json:"heapObjects"`
+	TotalAlloc  uint64 `json:"totalAlloc"`
+	Sys         uint64 `json:"sys"`
+}
+
+// RuntimeGC describes the garbage collections, the recent pauses newest first.
+type RuntimeGC struct {
+	NumGC          uint32     `json:"numGC"`
+	LastGC         *time.Time `json:"lastGC,omitempty"`
+	PauseTotalMs   float64    `json:"pauseTotalMs"`
+	RecentPausesMs []float64  `json:"recentPausesMs"`
+}
+
+// RuntimeDatabase describes the connections of the database pool.
+type RuntimeDatabase struct {
+	OpenConnections int     `json:"openConnections"`
+	InUse           int     `json:"inUse"`
+	Idle            int     `json:"idle"`
+	WaitCount       int64   `json:"waitCount"`
+	WaitDurationMs  float64 `json:"waitDurationMs"`
+}
+
+// RuntimeScheduler is the load of the scheduler.
+type RuntimeScheduler struct {
+	Workers int `json:"workers"`
+	Busy    int `json:"busy"`
+	Queued  int `json:"queued"`
+}
+
+// RuntimeRoute summarizes the requests served by a route since the agent
+// started, all status codes included. The 95th percentile is an upper bound.
+type RuntimeRoute struct {
+	Method        string  `json:"method"`
+	Route         string  `json:"route"`
+	Count         uint64  `json:"count"`
+	MeanLatencyMs float64 `json:"meanLatencyMs"`
+	P95LatencyMs  float64 `json:"p95LatencyMs"`
+	MeanSizeBytes float64 `json:"meanSizeBytes"`
+}
+
+// LogLevels is the log level returned by the /admin/loglevel endpoints, with the
+// levels overriding it per component (named logger).
+type LogLevels struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// SetLogLevelRequest is the body of the PUT /admin/loglevel endpoints.
+type SetLogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// RegisterAdminRoutes registers the admin-only endpoints. They are not part of
+// the public API and are served on the admin listener when one is configured.
+// The API keys are only managed on a dedicated admin listener, never on the
+// port of the API.
+func (h *Handler) RegisterAdminRoutes(router gin.IRoutes) {
+	router.GET("/migrations", h.GetMigrations)
+	router.GET("/config", h.GetResolvedConfig)
+	router.GET("/runtime", h.GetRuntimeStats)
+	router.GET("/loglevel", h.GetLogLevels)
+	router.PUT("/loglevel", h.SetLogLevel)
+	router.PUT("/loglevel/:component", h.SetComponentLogLevel)
+	router.DELETE("/loglevel/:component", h.ResetComponentLogLevel)
+	if h.apiKeySrv != nil && (h.cfg.Server.AdminPort != 0 || h.cfg.Server.AdminUnixSocketPath != "") {
+		router.POST("/apikeys", h.CreateAPIKey)
+		router.GET("/apikeys", h.ListAPIKeys)
+		router.DELETE("/apikeys/:id", h.RevokeAPIKey)
+	}
+	if h.auditSrv != nil {
+		router.GET("/audit", h.ListAuditEntries)
+	}
+	if h.credsSrv != nil {
+		router.GET("/credentials", h.GetStoredCredentials)
+		router.DELETE("/credentials", h.DeleteStoredCredentials)
+	}
+	if h.supportSrv != nil {
+		router.GET("/support-bundle", h.GetSupportBundle)
+	}
+}
+
+// GetMigrations returns the status of the schema migrations
+// (GET /admin/migrations)
+func (h *Handler) GetMigrations(c *gin.Context) {
+	migrations, err := h.adminSrv.Migrations(c.Request.Context())
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to get migrations status", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	resp := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		resp = append(resp, Migration{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   m.AppliedAt != nil,
+			AppliedAt: m.AppliedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetResolvedConfig returns the configuration the agent started with, each key
+// with its value and source, secrets excluded (GET /admin/config)
+func (h *Handler) GetResolvedConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cfg.ResolvedConfig())
+}
+
+// GetRuntimeStats returns the goroutines, memory, garbage collector, database
+// connections and scheduler load of the agent
+// (GET /admin/runtime)
+func (h *Handler) GetRuntimeStats(c *gin.Context) {
+	stats := h.adminSrv.Runtime()
+
+	pauses := make([]float64, 0, len(stats.GC.RecentPauses))
+	for _, p := range stats.GC.RecentPauses {
+		pauses = append(pauses, milliseconds(p))
+	}
+
+	routes := make([]RuntimeRoute, 0, len(stats.Routes))
+	for _, r := range stats.Routes {
+		routes = append(routes, RuntimeRoute{
+			Method:        r.Method,
+			Route:         r.Route,
+			Count:         r.Count,
+			MeanLatencyMs: milliseconds(r.MeanLatency),
+			P95LatencyMs:  milliseconds(r.P95Latency),
+			MeanSizeBytes: r.MeanSize,
+		})
+	}
+
+	c.JSON(http.StatusOK, RuntimeStats{
+		Goroutines: stats.Goroutines,
+		Memory: RuntimeMemory{
+			HeapAlloc:   stats.Memory.HeapAlloc,
+			HeapInuse:   stats.Memory.HeapInuse,
+			HeapObjects: stats.Memory.HeapObjects,
+			TotalAlloc:  stats.Memory.TotalAlloc,
+			Sys:         stats.Memory.Sys,
+		},
+		GC: RuntimeGC{
+			NumGC:          stats.GC.NumGC,
+			LastGC:         stats.GC.LastGC,
+			PauseTotalMs:   milliseconds(stats.GC.PauseTotal),
+			RecentPausesMs: pauses,
+		},
+		Database: RuntimeDatabase{
+			OpenConnections: stats.Database.Open,
+			InUse:           stats.Database.InUse,
+			Idle:            stats.Database.Idle,
+			WaitCount:       stats.Database.WaitCount,
+			WaitDurationMs:  milliseconds(stats.Database.WaitDuration),
+		},
+		Scheduler: RuntimeScheduler{
+			Workers: stats.Scheduler.Workers,
+			Busy:    stats.Scheduler.Busy,
+			Queued:  stats.Scheduler.Queued,
+		},
+		Routes: routes,
+	})
+}
+
+// GetLogLevels returns the log level and the levels of the components
+// (GET /admin/loglevel)
+func (h *Handler) GetLogLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, currentLogLevels())
+}
+
+// SetLogLevel changes the log level of the loggers without a component level
+// until the next restart or configuration reload
+// (PUT /admin/loglevel)
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Level == "" {
+		badRequest(c, "invalid request body: level is required")
+		return
+	}
+	if err := logger.SetLevel(req.Level); err != nil {
+		badRequest(c, fmt.Sprintf("invalid log level %q", req.Level))
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Named("admin_handler").Infow("log level changed", "level", req.Level)
+	c.JSON(http.StatusOK, currentLogLevels())
+}
+
+// SetComponentLogLevel changes the log level of the named loggers of a
+// component until the next restart or configuration reload
+// (PUT /admin/loglevel/{component})
+func (h *Handler) SetComponentLogLevel(c *gin.Context) {
+	component := c.Param("component")
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Level == "" {
+		badRequest(c, "invalid request body: level is required")
+		return
+	}
+	if err := logger.SetComponentLevel(component, req.Level); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Named("admin_handler").Infow("component log level changed", "component", component, "level", req.Level)
+	c.JSON(http.StatusOK, currentLogLevels())
+}
+
+// ResetComponentLogLevel removes the log level of a component, its loggers
+// using the log level again
+// (DELETE /admin/loglevel/{component})
+func (h *Handler) ResetComponentLogLevel(c *gin.Context) {
+	component := c.Param("component")
+	if !logger.ResetComponentLevel(component) {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, fmt.Sprintf("no log level set for %q", component)))
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Named("admin_handler").Infow("component log level reset", "component", component)
+	c.Status(http.StatusNoContent)
+}
+
+// CreateAPIKey generates an API key and returns it once
+// (POST /admin/apikeys)
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request body")
+		return
+	}
+	if req.Name == "" {
+		badRequest(c, "name is required")
+		return
+	}
+	role := models.RoleViewer
+	if req.Role != "" {
+		role = models.Role(req.Role)
+	}
+	if !role.Valid() {
+		badRequest(c, fmt.Sprintf("invalid role %q: must be %s or %s", req.Role, models.RoleViewer, models.RoleOperator))
+		return
+	}
+
+	key, secret, err := h.apiKeySrv.Create(c.Request.Context(), req.Name, role)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to create api key", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatedAPIKey{APIKey: newAPIKey(*key), Key: secret})
+}
+
+// ListAPIKeys returns the API keys, revoked ones included
+// (GET /admin/apikeys)
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeySrv.List(c.Request.Context())
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to list api keys", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	resp := make([]APIKey, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, newAPIKey(k))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeAPIKey revokes an API key
+// (DELETE /admin/apikeys/{id})
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.apiKeySrv.Revoke(c.Request.Context(), id); err != nil {
+		if srvErrors.IsResourceNotFoundError(err) {
+			writeError(c, err)
+			return
+		}
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to revoke api key", "id", id, "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAuditEntries returns a page of the audit log, newest first. The page
+// and pageSize query parameters are those of the list endpoints of the API.
+// (GET /admin/audit)
+func (h *Handler) ListAuditEntries(c *gin.Context) {
+	page, ok := positiveQuery(c, "page")
+	if !ok {
+		return
+	}
+	pageSize, ok := positiveQuery(c, "pageSize")
+	if !ok {
+		return
+	}
+
+	entries, err := h.auditSrv.List(c.Request.Context(), pageCursor(page, pageSize))
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to list audit entries", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditListResponse{
+		Pagination: v1.NewPagination(entries),
+		Entries: models.MapPage(entries, func(e models.AuditEntry) AuditEntry {
+			return AuditEntry{
+				Time:      e.Time,
+				Subject:   e.Subject,
+				Role:      string(e.Role),
+				Method:    e.Method,
+				Path:      e.Path,
+				Status:    e.Status,
+				RequestID: e.RequestID,
+			}
+		}).Items,
+	})
+}
+
+// positiveQuery returns the positive integer of the query parameter name, nil
+// when it is not set. It responds 400 and returns false when it is invalid.
+func positiveQuery(c *gin.Context, name string) (*int, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		badRequest(c, fmt.Sprintf("invalid %s %q: must be a positive integer", name, v))
+		return nil, false
+	}
+	return &n, true
+}
+
+// GetStoredCredentials returns the vCenter URL and username of the stored
+// credentials
+// (GET /admin/credentials)
+func (h *Handler) GetStoredCredentials(c *gin.Context) {
+	creds, err := h.credsSrv.Get(c.Request.Context())
+	if err != nil {
+		if srvErrors.IsResourceNotFoundError(err) {
+			writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "no credentials stored"))
+			return
+		}
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to get stored credentials", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, StoredCredentials{URL: creds.URL, Username: creds.Username})
+}
+
+// DeleteStoredCredentials forgets the stored credentials
+// (DELETE /admin/credentials)
+func (h *Handler) DeleteStoredCredentials(c *gin.Context) {
+	if err := h.credsSrv.Delete(c.Request.Context()); err != nil {
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to delete stored credentials", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSupportBundle streams a tar.gz archive of the redacted configuration, the
+// state of the services, the database and the recent logs
+// (GET /admin/support-bundle)
+func (h *Handler) GetSupportBundle(c *gin.Context) {
+	name := fmt.Sprintf("support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	c.Status(http.StatusOK)
+
+	// the status is sent with the first bytes, a failure can only end the stream
+	if err := h.supportSrv.Write(c.Request.Context(), c.Writer); err != nil {
+		logger.FromContext(c.Request.Context()).Named("admin_handler").Errorw("failed to write support bundle", "error", err)
+		_ = c.Error(err)
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func currentLogLevels() LogLevels {
+	return LogLevels{Level: logger.Level(), Components: logger.ComponentLevels()}
+}
+
+func newAPIKey(k models.APIKey) APIKey {
+	return APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Role:      string(k.Role),
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
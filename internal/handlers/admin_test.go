@@ -0,0 +1,593 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+var _ = Describe("Admin Handlers", func() {
+	var (
+		mockAdmin  *MockAdminService
+		mockAPIKey *MockAPIKeyService
+		mockAudit  *MockAuditService
+		mockCreds  *MockCredentialsService
+		mockBundle *MockSupportBundleService
+		router     *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockAdmin = &MockAdminService{}
+		mockAPIKey = &MockAPIKeyService{}
+		mockAudit = &MockAuditService{}
+		mockCreds = &MockCredentialsService{}
+		mockBundle = &MockSupportBundleService{}
+		handler := handlers.New(config.Configuration{Server: config.Server{AdminPort: 8001}}, nil, nil, nil, nil, nil).
+			WithAdminService(mockAdmin).
+			WithAPIKeyService(mockAPIKey).
+			WithAuditService(mockAudit).
+			WithCredentialsService(mockCreds).
+			WithSupportBundleService(mockBundle)
+		router = gin.New()
+		handler.RegisterAdminRoutes(router.Group("/admin"))
+	})
+
+	Context("GetMigrations", func() {
+		// Given an applied and a pending migration
+		// When we get the migrations status
+		// Then both should be returned with their applied flag
+		It("should return the migrations status", func() {
+			// Arrange
+			appliedAt := time.Now().UTC().Truncate(time.Second)
+			mockAdmin.MigrationsResult = []models.Migration{
+				{Version: 1, Name: "001_initial", AppliedAt: &appliedAt},
+				{Version: 2, Name: "002_vm_security"},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/migrations", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response []handlers.Migration
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].Applied).To(BeTrue())
+			Expect(response[0].AppliedAt.Equal(appliedAt)).To(BeTrue())
+			Expect(response[1].Name).To(Equal("002_vm_security"))
+			Expect(response[1].Applied).To(BeFalse())
+			Expect(response[1].AppliedAt).To(BeNil())
+		})
+
+		// Given the store fails
+		// When we get the migrations status
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockAdmin.MigrationsError = errors.New("db error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/migrations", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("GetRuntimeStats", func() {
+		// Given the runtime stats of the agent
+		// When we get them
+		// Then they should be returned with the durations in milliseconds
+		It("should return the runtime stats", func() {
+			// Arrange
+			mockAdmin.RuntimeResult = models.RuntimeStats{
+				Goroutines: 42,
+				Memory:     models.MemoryStats{HeapAlloc: 1024},
+				GC:         models.GCStats{NumGC: 3, PauseTotal: 3 * time.Millisecond, RecentPauses: []time.Duration{time.Millisecond, 1500 * time.Microsecond}},
+				Database:   models.DatabaseConnections{Open: 1, InUse: 1},
+				Scheduler:  models.SchedulerStats{Workers: 3, Busy: 1, Queued: 2},
+				Routes: []models.RouteStats{
+					{Method: "GET", Route: "/api/v1/vms", Count: 10, MeanLatency: 25 * time.Millisecond, P95Latency: 80 * time.Millisecond, MeanSize: 2048},
+				},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/runtime", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response handlers.RuntimeStats
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Goroutines).To(Equal(42))
+			Expect(response.Memory.HeapAlloc).To(Equal(uint64(1024)))
+			Expect(response.GC.PauseTotalMs).To(Equal(3.0))
+			Expect(response.GC.RecentPausesMs).To(Equal([]float64{1, 1.5}))
+			Expect(response.Database.OpenConnections).To(Equal(1))
+			Expect(response.Scheduler).To(Equal(handlers.RuntimeScheduler{Workers: 3, Busy: 1, Queued: 2}))
+			Expect(response.Routes).To(Equal([]handlers.RuntimeRoute{
+				{Method: "GET", Route: "/api/v1/vms", Count: 10, MeanLatencyMs: 25, P95LatencyMs: 80, MeanSizeBytes: 2048},
+			}))
+		})
+	})
+
+	Context("GetResolvedConfig", func() {
+		// Given a configuration loaded from the environment with a JWT
+		// When we get the resolved configuration
+		// Then each key should come with its source and the JWT should be hidden
+		It("should return the keys with their source", func() {
+			// Arrange
+			GinkgoT().Setenv("AMA_SERVER_HTTP_PORT", "9443")
+			GinkgoT().Setenv("AMA_AUTH_JWT", "secret-token")
+			cfg := config.NewConfigurationWithOptionsAndDefaults()
+			Expect(config.LoadFromEnv(cfg)).To(Succeed())
+			router = gin.New()
+			handlers.New(*cfg, nil, nil, nil, nil, nil).RegisterAdminRoutes(router.Group("/admin"))
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).ToNot(ContainSubstring("secret-token"))
+
+			var response []config.ResolvedValue
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(ContainElements(
+				config.ResolvedValue{Key: "server.httpPort", Value: float64(9443), Source: config.SourceEnv},
+				config.ResolvedValue{Key: "server.readTimeout", Value: "5m0s", Source: config.SourceDefault},
+				config.ResolvedValue{Key: "auth.jwt", Value: "(sensitive)", Source: config.SourceEnv},
+			))
+		})
+	})
+
+	Context("Log levels", func() {
+		BeforeEach(func() {
+			DeferCleanup(func() {
+				Expect(logger.SetLevel("info")).To(Succeed())
+				Expect(logger.SetComponentLevels(nil)).To(Succeed())
+			})
+		})
+
+		// Given a log level and a component level
+		// When we get the log levels
+		// Then both should be returned
+		It("should return the log levels", func() {
+			// Arrange
+			Expect(logger.SetLevel("warn")).To(Succeed())
+			Expect(logger.SetComponentLevels(map[string]string{"store": "debug"})).To(Succeed())
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/loglevel", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response handlers.LogLevels
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(Equal(handlers.LogLevels{Level: "warn", Components: map[string]string{"store": "debug"}}))
+		})
+
+		// Given an info log level
+		// When we set the debug level
+		// Then the loggers should use it
+		It("should change the log level", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(`{"level": "debug"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(logger.Level()).To(Equal("debug"))
+		})
+
+		// Given an unknown level
+		// When we set it
+		// Then 400 should be returned and the level kept
+		It("should reject an invalid log level", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodPut, "/admin/loglevel", strings.NewReader(`{"level": "verbose"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(logger.Level()).To(Equal("info"))
+		})
+
+		// Given no component levels
+		// When we set the level of a component
+		// Then only that component should have it
+		It("should change the log level of a component", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodPut, "/admin/loglevel/collector", strings.NewReader(`{"level": "debug"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(logger.ComponentLevels()).To(Equal(map[string]string{"collector": "debug"}))
+			Expect(logger.Level()).To(Equal("info"))
+		})
+
+		// Given a component level
+		// When we reset it
+		// Then 204 should be returned and a second reset should return 404
+		It("should reset the log level of a component", func() {
+			// Arrange
+			Expect(logger.SetComponentLevels(map[string]string{"store": "debug"})).To(Succeed())
+
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/admin/loglevel/store", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(logger.ComponentLevels()).To(BeEmpty())
+
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/loglevel/store", nil))
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("API keys", func() {
+		// Given a key name
+		// When we create an API key
+		// Then the key should be returned with its metadata
+		It("should create an API key", func() {
+			// Arrange
+			createdAt := time.Now().UTC().Truncate(time.Second)
+			mockAPIKey.CreateResult = &models.APIKey{ID: "key-1", Name: "backup", Prefix: "ama_abcdef", Hash: "hash", CreatedAt: createdAt}
+			mockAPIKey.CreateSecret = "ama_abcdef-secret"
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/admin/apikeys", strings.NewReader(`{"name": "backup"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(mockAPIKey.CreatedName).To(Equal("backup"))
+			Expect(mockAPIKey.CreatedRole).To(Equal(models.RoleViewer))
+			Expect(w.Body.String()).ToNot(ContainSubstring("hash"))
+
+			var response handlers.CreatedAPIKey
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.ID).To(Equal("key-1"))
+			Expect(response.Prefix).To(Equal("ama_abcdef"))
+			Expect(response.Key).To(Equal("ama_abcdef-secret"))
+		})
+
+		// Given a request without a name
+		// When we create an API key
+		// Then 400 should be returned
+		It("should return 400 without a name", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/admin/apikeys", strings.NewReader(`{}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(mockAPIKey.CreatedName).To(BeEmpty())
+		})
+
+		// Given an operator role
+		// When we create an API key
+		// Then the key should be created with that role
+		It("should create an API key with a role", func() {
+			// Arrange
+			mockAPIKey.CreateResult = &models.APIKey{ID: "key-1", Name: "ci", Role: models.RoleOperator}
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/admin/apikeys", strings.NewReader(`{"name": "ci", "role": "operator"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(mockAPIKey.CreatedRole).To(Equal(models.RoleOperator))
+
+			var response handlers.CreatedAPIKey
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Role).To(Equal("operator"))
+		})
+
+		// Given an unknown role
+		// When we create an API key
+		// Then 400 should be returned
+		It("should return 400 for an unknown role", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/admin/apikeys", strings.NewReader(`{"name": "ci", "role": "admin"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(mockAPIKey.CreatedName).To(BeEmpty())
+		})
+
+		// Given an active and a revoked key
+		// When we list the API keys
+		// Then both should be returned without their key
+		It("should list the API keys", func() {
+			// Arrange
+			revokedAt := time.Now().UTC().Truncate(time.Second)
+			mockAPIKey.ListResult = []models.APIKey{
+				{ID: "key-1", Name: "backup", Prefix: "ama_abcdef", Hash: "hash-1"},
+				{ID: "key-2", Name: "old", Prefix: "ama_123456", Hash: "hash-2", RevokedAt: &revokedAt},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/apikeys", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).ToNot(ContainSubstring("hash-"))
+
+			var response []handlers.APIKey
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].RevokedAt).To(BeNil())
+			Expect(response[1].RevokedAt.Equal(revokedAt)).To(BeTrue())
+		})
+
+		// Given an existing key
+		// When we revoke it
+		// Then 204 should be returned
+		It("should revoke an API key", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/admin/apikeys/key-1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(mockAPIKey.RevokedID).To(Equal("key-1"))
+		})
+
+		// Given an unknown key
+		// When we revoke it
+		// Then 404 should be returned
+		It("should return 404 when revoking an unknown key", func() {
+			// Arrange
+			mockAPIKey.RevokeError = srvErrors.NewResourceNotFoundError("api key", "key-9")
+
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/admin/apikeys/key-9", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		// Given admin routes served on the port of the API, without an admin listener
+		// When we create an API key
+		// Then the route should not be found
+		It("should not serve the API keys without an admin listener", func() {
+			// Arrange
+			handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).
+				WithAdminService(mockAdmin).
+				WithAPIKeyService(mockAPIKey)
+			router = gin.New()
+			handler.RegisterAdminRoutes(router.Group("/admin"))
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/admin/apikeys", strings.NewReader(`{"name": "backup"}`))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(mockAPIKey.CreatedName).To(BeEmpty())
+		})
+	})
+
+	Context("ListAuditEntries", func() {
+		// Given an audit entry
+		// When we list the audit log without pagination parameters
+		// Then the entry should be returned in the first page of the default size
+		It("should list the audit entries", func() {
+			// Arrange
+			at := time.Now().UTC().Truncate(time.Second)
+			mockAudit.ListResult = []models.AuditEntry{
+				{Time: at, Subject: "apikey:backup", Role: models.RoleOperator, Method: http.MethodPost, Path: "/api/v1/collector", Status: http.StatusAccepted, RequestID: "req-1"},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockAudit.ListCursor).To(Equal(models.Cursor{Offset: 0, Limit: 20}))
+
+			var response handlers.AuditListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(1))
+			Expect(response.Page).To(Equal(1))
+			Expect(response.PageSize).To(Equal(20))
+			Expect(response.PageCount).To(Equal(1))
+			Expect(response.Entries).To(HaveLen(1))
+			entry := response.Entries[0]
+			Expect(entry.Subject).To(Equal("apikey:backup"))
+			Expect(entry.Role).To(Equal("operator"))
+			Expect(entry.Method).To(Equal(http.MethodPost))
+			Expect(entry.Path).To(Equal("/api/v1/collector"))
+			Expect(entry.Status).To(Equal(http.StatusAccepted))
+			Expect(entry.RequestID).To(Equal("req-1"))
+			Expect(entry.Time.Equal(at)).To(BeTrue())
+		})
+
+		// Given a page and a page size
+		// When we list the audit log
+		// Then the cursor of the page should be passed to the service
+		It("should pass the page", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit?page=3&pageSize=10", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockAudit.ListCursor).To(Equal(models.Cursor{Offset: 20, Limit: 10}))
+
+			var response handlers.AuditListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Page).To(Equal(3))
+			Expect(response.Entries).NotTo(BeNil())
+			Expect(response.Entries).To(BeEmpty())
+		})
+
+		// Given a page size which is not a number
+		// When we list the audit log
+		// Then 400 should be returned
+		It("should return 400 for an invalid page size", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit?pageSize=ten", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		// Given a failing service
+		// When we list the audit log
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockAudit.ListError = errors.New("database error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("Stored credentials", func() {
+		// Given stored credentials
+		// When we get them
+		// Then the URL and username should be returned without the password
+		It("should return the stored credentials without the password", func() {
+			// Arrange
+			mockCreds.GetResult = &models.Credentials{URL: "https://vcenter.example.com", Username: "admin", Password: "secret"}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/credentials", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).NotTo(ContainSubstring("secret"))
+			var response handlers.StoredCredentials
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.URL).To(Equal("https://vcenter.example.com"))
+			Expect(response.Username).To(Equal("admin"))
+		})
+
+		// Given no stored credentials
+		// When we get them
+		// Then 404 should be returned
+		It("should return 404 without stored credentials", func() {
+			// Arrange
+			mockCreds.GetError = srvErrors.NewResourceNotFoundError("secret", "vcenter-credentials")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/credentials", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		// Given stored credentials
+		// When we delete them
+		// Then 204 should be returned
+		It("should delete the stored credentials", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/admin/credentials", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(mockCreds.Deleted).To(BeTrue())
+		})
+	})
+
+	Context("GetSupportBundle", func() {
+		// Given a support bundle
+		// When we get it
+		// Then it should be streamed as a tar.gz attachment
+		It("should stream the support bundle as an attachment", func() {
+			// Arrange
+			mockBundle.Content = "bundle"
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/support-bundle", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/gzip"))
+			Expect(w.Header().Get("Content-Disposition")).To(MatchRegexp(`^attachment; filename="support-bundle-\d{8}T\d{6}Z\.tar\.gz"$`))
+			Expect(w.Body.String()).To(Equal("bundle"))
+		})
+
+		// Given no support bundle service
+		// When we get the support bundle
+		// Then 404 should be returned
+		It("should not register the endpoint without the service", func() {
+			// Arrange
+			handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil)
+			router = gin.New()
+			handler.RegisterAdminRoutes(router.Group("/admin"))
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/admin/support-bundle", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
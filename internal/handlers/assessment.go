@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+const maxAssessmentSize = 256 << 20 // 256Mb
+
+// ExportAssessment returns the assessment of the agent as a signed tar.gz
+// archive, with the fingerprint of its key in X-Assessment-Fingerprint
+// (GET /assessment/export)
+func (h *Handler) ExportAssessment(c *gin.Context) {
+	if h.assessmentUnavailable(c) {
+		return
+	}
+
+	// the bundle is built before the status is sent, to answer 404 before the
+	// first collection
+	var bundle bytes.Buffer
+	if err := h.assessSrv.Export(c.Request.Context(), &bundle); err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("assessment_handler").Errorw("failed to export assessment", "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	name := fmt.Sprintf("assessment-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	c.Header("X-Assessment-Fingerprint", h.assessSrv.Fingerprint())
+	c.Data(http.StatusOK, "application/gzip", bundle.Bytes())
+}
+
+// ImportAssessment verifies an assessment bundle exported by another agent and
+// sends its inventory to the console
+// (POST /assessment/import)
+func (h *Handler) ImportAssessment(c *gin.Context, params v1.ImportAssessmentParams) {
+	if h.assessmentUnavailable(c) {
+		return
+	}
+
+	var fingerprint string
+	if params.Fingerprint != nil {
+		fingerprint = *params.Fingerprint
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAssessmentSize)
+	bundle, err := h.assessSrv.Import(c.Request.Context(), c.Request.Body, fingerprint)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			writeError(c, srvErrors.NewAPIError(srvErrors.CodePayloadTooLarge, err.Error()))
+		case srvErrors.IsInvalidBundleError(err), srvErrors.IsModeConflictError(err):
+			writeError(c, err)
+		default:
+			logger.FromContext(c.Request.Context()).Named("assessment_handler").Errorw("failed to import assessment", "error", err)
+			writeError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewAssessmentImportResult(*bundle))
+}
+
+// assessmentUnavailable responds 404 while the assessment bundles are not
+// enabled and reports whether it did.
+func (h *Handler) assessmentUnavailable(c *gin.Context) bool {
+	if h.assessSrv != nil {
+		return false
+	}
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "assessment bundles are not enabled"))
+	return true
+}
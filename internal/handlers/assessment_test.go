@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Assessment Handlers", func() {
+	var (
+		mockAssessment *MockAssessmentService
+		handler        *handlers.Handler
+		router         *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockAssessment = &MockAssessmentService{Bundle: []byte("bundle")}
+		handler = handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithAssessmentService(mockAssessment)
+		router = gin.New()
+		v1.RegisterHandlersWithOptions(router, handler, v1.GinServerOptions{ErrorHandler: handlers.ParamErrorHandler})
+	})
+
+	Context("ExportAssessment", func() {
+		// Given an agent with a collected inventory
+		// When we export its assessment
+		// Then the bundle should be returned as an attachment with the fingerprint of its key
+		It("should return the bundle", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/assessment/export", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("bundle"))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/gzip"))
+			Expect(w.Header().Get("Content-Disposition")).To(HavePrefix(`attachment; filename="assessment-`))
+			Expect(w.Header().Get("X-Assessment-Fingerprint")).To(Equal("3f5c"))
+		})
+
+		// Given an agent without inventory
+		// When we export its assessment
+		// Then 404 should be returned
+		It("should return 404 before the first collection", func() {
+			// Arrange
+			mockAssessment.ExportError = srvErrors.NewResourceNotFoundError("inventory", "")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/assessment/export", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("ImportAssessment", func() {
+		// Given a valid bundle and the fingerprint of its key
+		// When we import it
+		// Then the manifest of the bundle should be returned
+		It("should import the bundle", func() {
+			// Arrange
+			createdAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+			mockAssessment.Imported = &models.AssessmentBundle{
+				Manifest: models.AssessmentManifest{
+					AgentID:      "agent-1",
+					SourceID:     "source-1",
+					AgentVersion: "v1.2.0",
+					CreatedAt:    createdAt,
+					CollectedAt:  createdAt.Add(-time.Hour),
+				},
+				Fingerprint: "3f5c",
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/assessment/import?fingerprint=3f5c", strings.NewReader("bundle"))
+			req.Header.Set("Content-Type", "application/gzip")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(MatchJSON(`{
+				"agentId": "agent-1",
+				"sourceId": "source-1",
+				"agentVersion": "v1.2.0",
+				"createdAt": "2026-03-01T10:00:00Z",
+				"collectedAt": "2026-03-01T09:00:00Z",
+				"fingerprint": "3f5c"
+			}`))
+			Expect(mockAssessment.LastFingerprint).To(Equal("3f5c"))
+			Expect(mockAssessment.LastBody).To(Equal([]byte("bundle")))
+		})
+
+		// Given a tampered bundle
+		// When we import it
+		// Then 400 INVALID_BUNDLE should be returned
+		It("should return 400 for an invalid bundle", func() {
+			// Arrange
+			mockAssessment.ImportError = srvErrors.NewInvalidBundleError("inventory.json does not match the manifest")
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/assessment/import", strings.NewReader("bundle"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring(string(srvErrors.CodeInvalidBundle)))
+			Expect(mockAssessment.LastFingerprint).To(BeEmpty())
+		})
+
+		// Given an agent in disconnected mode
+		// When we import a bundle
+		// Then 409 should be returned
+		It("should return 409 in disconnected mode", func() {
+			// Arrange
+			mockAssessment.ImportError = srvErrors.NewModeConflictError("the agent must be in connected mode to import an assessment")
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/assessment/import", strings.NewReader("bundle"))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+
+		// Given a bundle larger than 256MB
+		// When we import it
+		// Then 413 should be returned
+		It("should return 413 for a bundle too large", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/assessment/import", bytes.NewReader(make([]byte, 256<<20+1)))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+
+	// Given a handler without assessment service
+	// When we export the assessment
+	// Then 404 should be returned
+	It("should return 404 without assessment service", func() {
+		// Arrange
+		router = gin.New()
+		v1.RegisterHandlersWithOptions(router, handlers.New(config.Configuration{}, nil, nil, nil, nil, nil),
+			v1.GinServerOptions{ErrorHandler: handlers.ParamErrorHandler})
+
+		// Act
+		req := httptest.NewRequest(http.MethodGet, "/assessment/export", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+})
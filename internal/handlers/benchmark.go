@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+// maxBenchmarkSeconds bounds the duration of each measure of a benchmark.
+const maxBenchmarkSeconds = 300
+
+// GetBenchmark returns the latest throughput benchmark
+// (GET /benchmark)
+func (h *Handler) GetBenchmark(c *gin.Context) {
+	if h.benchmarkUnavailable(c) {
+		return
+	}
+
+	bench, err := h.benchSrv.Latest(c.Request.Context())
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("benchmark_handler").Errorw("failed to get benchmark", "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v1.NewBenchmark(*bench))
+}
+
+// StartBenchmark starts a benchmark job measuring the datastores and the
+// network
+// (POST /benchmark)
+func (h *Handler) StartBenchmark(c *gin.Context) {
+	if h.benchmarkUnavailable(c) {
+		return
+	}
+
+	var req v1.BenchmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request body")
+		return
+	}
+	if invalid(c, benchmarkValidator(req)) {
+		return
+	}
+
+	job, err := h.benchSrv.Start(c.Request.Context(), benchmarkRequest(req))
+	if err != nil {
+		if !srvErrors.IsBenchmarkInProgressError(err) && !srvErrors.IsVCenterError(err) {
+			logger.FromContext(c.Request.Context()).Named("benchmark_handler").Errorw("failed to start benchmark", "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, v1.BenchmarkStarted{JobId: job})
+}
+
+// benchmarkValidator checks the credentials, the target and the duration of
+// req.
+func benchmarkValidator(req v1.BenchmarkRequest) *validation.Validator {
+	creds := req.VcenterCredentials
+	v := credentialsValidator(creds.Url, creds.Username, creds.Password)
+	if req.Target != nil && *req.Target != "" {
+		u, err := url.Parse(*req.Target)
+		ok := err == nil && u.Host != "" && (u.Scheme == "tcp" || u.Scheme == "http" || u.Scheme == "https")
+		v.Check("target", ok, "target must be a tcp://, http:// or https:// endpoint")
+	}
+	if req.DurationSeconds != nil {
+		d := *req.DurationSeconds
+		v.Check("durationSeconds", d >= 1 && d <= maxBenchmarkSeconds, "durationSeconds must be between 1 and 300")
+	}
+	return v
+}
+
+func benchmarkRequest(req v1.BenchmarkRequest) models.BenchmarkRequest {
+	r := models.BenchmarkRequest{
+		Credentials: &models.Credentials{
+			URL:      req.VcenterCredentials.Url,
+			Username: req.VcenterCredentials.Username,
+			Password: req.VcenterCredentials.Password,
+		},
+	}
+	if req.Datastores != nil {
+		r.Datastores = *req.Datastores
+	}
+	if req.Target != nil {
+		r.Target = *req.Target
+	}
+	if req.DurationSeconds != nil {
+		r.Duration = time.Duration(*req.DurationSeconds) * time.Second
+	}
+	return r
+}
+
+// benchmarkUnavailable responds 404 while the benchmark feature is disabled or
+// its service unset, and reports whether it did.
+func (h *Handler) benchmarkUnavailable(c *gin.Context) bool {
+	if h.featureDisabled(c, config.FeatureBenchmark) {
+		return true
+	}
+	if h.benchSrv != nil {
+		return false
+	}
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "benchmarks are not served"))
+	return true
+}
@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Benchmark Handlers", func() {
+	var (
+		mockBenchmark *MockBenchmarkService
+		router        *gin.Engine
+	)
+
+	// serve routes the benchmark endpoints of a handler enabling features
+	serve := func(features map[string]bool) {
+		handler := handlers.New(config.Configuration{Features: features}, nil, nil, nil, nil, nil).WithBenchmarkService(mockBenchmark)
+		router = gin.New()
+		router.GET("/benchmark", handler.GetBenchmark)
+		router.POST("/benchmark", handler.StartBenchmark)
+	}
+
+	// start posts body to /benchmark
+	start := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/benchmark", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockBenchmark = &MockBenchmarkService{JobID: "job-1"}
+		serve(map[string]bool{config.FeatureBenchmark: true})
+	})
+
+	Context("StartBenchmark", func() {
+		// Given a request with a target and a duration
+		// When we start a benchmark
+		// Then it should be passed to the service and its job returned
+		It("should start a benchmark", func() {
+			// Act
+			w := start(`{"VcenterCredentials": {"url": "https://vcenter.example.com", "username": "admin", "password": "secret"},
+				"datastores": ["ds-1"], "target": "tcp://10.0.0.12:9000", "durationSeconds": 30}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(mockBenchmark.LastRequest).To(Equal(models.BenchmarkRequest{
+				Credentials: &models.Credentials{URL: "https://vcenter.example.com", Username: "admin", Password: "secret"},
+				Datastores:  []string{"ds-1"},
+				Target:      "tcp://10.0.0.12:9000",
+				Duration:    30 * time.Second,
+			}))
+			var response v1.BenchmarkStarted
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.JobId).To(Equal("job-1"))
+		})
+
+		// Given a target which is not a tcp or http endpoint
+		// When we start a benchmark
+		// Then 400 should be returned
+		It("should return 400 for an invalid target", func() {
+			// Act
+			w := start(`{"VcenterCredentials": {"url": "https://vcenter.example.com", "username": "admin", "password": "secret"}, "target": "ftp://10.0.0.12"}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("target"))
+		})
+
+		// Given a running benchmark
+		// When we start another one
+		// Then 409 should be returned
+		It("should return 409 while a benchmark runs", func() {
+			// Arrange
+			mockBenchmark.StartError = srvErrors.NewBenchmarkInProgressError()
+
+			// Act
+			w := start(`{"VcenterCredentials": {"url": "https://vcenter.example.com", "username": "admin", "password": "secret"}}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+
+		// Given the benchmark feature disabled
+		// When we start a benchmark
+		// Then 404 should be returned
+		It("should return 404 when the feature is disabled", func() {
+			// Arrange
+			serve(nil)
+
+			// Act
+			w := start(`{"VcenterCredentials": {"url": "https://vcenter.example.com", "username": "admin", "password": "secret"}}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring(string(srvErrors.CodeFeatureDisabled)))
+		})
+	})
+
+	Context("GetBenchmark", func() {
+		// Given a benchmark of a datastore and of the network
+		// When we get the latest benchmark
+		// Then its measures and the rate of the plans should be returned
+		It("should return the latest benchmark", func() {
+			// Arrange
+			mockBenchmark.Benchmark = &models.Benchmark{
+				ID:         "job-1",
+				Datastores: []models.ThroughputMeasure{{Name: "ds-1", Bytes: 1 << 30, Duration: 4 * time.Second, MBps: 268.4}},
+				Network:    &models.ThroughputMeasure{Name: "tcp://10.0.0.12:9000", Bytes: 1 << 30, Duration: 9 * time.Second, MBps: 119.3},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/benchmark", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response v1.Benchmark
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.ThroughputMBps).To(BeNumerically("~", 119.3, 0.01))
+			Expect(response.Datastores).To(HaveLen(1))
+			Expect(response.Datastores[0].DurationMs).To(BeEquivalentTo(4000))
+			Expect(response.Network).NotTo(BeNil())
+		})
+
+		// Given no benchmark ran
+		// When we get the latest benchmark
+		// Then 404 should be returned
+		It("should return 404 before the first benchmark", func() {
+			// Arrange
+			mockBenchmark.LatestError = srvErrors.NewResourceNotFoundError("benchmark", "")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/benchmark", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// GetClusterRules returns the DRS rules of a cluster
+// (GET /clusters/{name}/rules)
+func (h *Handler) GetClusterRules(c *gin.Context, name string) {
+	rules, err := h.clusterSrv.ListRules(c.Request.Context(), name)
+	if err != nil {
+		if srvErrors.IsResourceNotFoundError(err) {
+			writeError(c, err)
+			return
+		}
+		logger.FromContext(c.Request.Context()).Named("cluster_handler").Errorw("failed to list cluster rules", "cluster", name, "error", err)
+		writeError(c, err)
+		return
+	}
+
+	apiRules := make([]v1.ClusterRule, 0, len(rules))
+	for _, r := range rules {
+		apiRules = append(apiRules, v1.NewClusterRule(r))
+	}
+
+	c.JSON(http.StatusOK, apiRules)
+}
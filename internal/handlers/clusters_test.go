@@ -0,0 +1,134 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Clusters Handlers", func() {
+	var (
+		mockCluster *MockClusterService
+		router      *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockCluster = &MockClusterService{}
+		handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithClusterService(mockCluster)
+		router = gin.New()
+		router.GET("/clusters/:name/rules", func(c *gin.Context) {
+			handler.GetClusterRules(c, c.Param("name"))
+		})
+	})
+
+	Context("GetClusterRules", func() {
+		// Given a cluster with a VM-VM and a VM-host rule
+		// When we get the cluster rules
+		// Then both rules should be returned with their members
+		It("should return the cluster rules", func() {
+			// Arrange
+			mockCluster.ListRulesResult = []models.ClusterRule{
+				{
+					Cluster:   "cluster-a",
+					Name:      "db-apart",
+					Type:      models.ClusterRuleTypeVMAntiAffinity,
+					Enabled:   true,
+					Mandatory: true,
+					VMs:       []string{"vm-1", "vm-2"},
+				},
+				{
+					Cluster:   "cluster-a",
+					Name:      "web-on-rack1",
+					Type:      models.ClusterRuleTypeVMHostAffinity,
+					Enabled:   true,
+					VMs:       []string{"vm-3"},
+					VMGroup:   "web",
+					Hosts:     []string{"host-1"},
+					HostGroup: "rack1",
+				},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/clusters/cluster-a/rules", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockCluster.LastCluster).To(Equal("cluster-a"))
+
+			var rules []v1.ClusterRule
+			Expect(json.Unmarshal(w.Body.Bytes(), &rules)).To(Succeed())
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[0].Type).To(Equal(v1.ClusterRuleTypeVmAntiAffinity))
+			Expect(rules[0].Mandatory).To(BeTrue())
+			Expect(rules[0].Vms).To(Equal([]string{"vm-1", "vm-2"}))
+			Expect(rules[0].HostGroup).To(BeNil())
+			Expect(rules[1].Type).To(Equal(v1.ClusterRuleTypeVmHostAffinity))
+			Expect(*rules[1].VmGroup).To(Equal("web"))
+			Expect(*rules[1].HostGroup).To(Equal("rack1"))
+			Expect(*rules[1].Hosts).To(Equal([]string{"host-1"}))
+		})
+
+		// Given a cluster without rules
+		// When we get the cluster rules
+		// Then an empty array should be returned
+		It("should return an empty list when there are no rules", func() {
+			// Arrange
+			mockCluster.ListRulesResult = []models.ClusterRule{}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/clusters/cluster-a/rules", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("[]"))
+		})
+
+		// Given an unknown cluster
+		// When we get the cluster rules
+		// Then 404 should be returned
+		It("should return 404 when the cluster does not exist", func() {
+			// Arrange
+			mockCluster.ListRulesError = srvErrors.NewResourceNotFoundError("cluster", "missing")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/clusters/missing/rules", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		// Given the store fails
+		// When we get the cluster rules
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockCluster.ListRulesError = errors.New("db error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/clusters/cluster-a/rules", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
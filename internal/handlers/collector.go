@@ -2,14 +2,14 @@ package handlers
 
 import (
 	"net/http"
-	"net/url"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 
 	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
 )
 
 // GetCollectorStatus returns the collector status
@@ -24,37 +24,24 @@ func (h *Handler) GetCollectorStatus(c *gin.Context) {
 func (h *Handler) StartCollector(c *gin.Context) {
 	var req v1.CollectorStartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		badRequest(c, "invalid request body")
 		return
 	}
 
-	// Validate required fields
-	if req.Url == "" || req.Username == "" || req.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "url, username, and password are required"})
+	if invalid(c, collectorValidator(req)) {
 		return
 	}
 
-	// Validate URL format
-	parsedURL, err := url.Parse(req.Url)
-	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid url format"})
-		return
-	}
-
-	creds := &models.Credentials{
-		URL:      req.Url,
-		Username: req.Username,
-		Password: req.Password,
-	}
+	creds := collectorCredentials(req)
 
 	// Start collection (saves creds, verifies, starts async job)
 	if err := h.collectorSrv.Start(c.Request.Context(), creds); err != nil {
 		if srvErrors.IsCollectionInProgressError(err) {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		zap.S().Named("collector_handler").Errorw("failed to start collector", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		logger.FromContext(c.Request.Context()).Named("collector_handler").Errorw("failed to start collector", "error", err)
+		writeError(c, err)
 		return
 	}
 
@@ -71,3 +58,37 @@ func (h *Handler) StopCollector(c *gin.Context) {
 	status := h.collectorSrv.GetStatus()
 	c.JSON(http.StatusOK, v1.NewCollectorStatus(status))
 }
+
+// credentialsValidator checks the vCenter credentials of the collector and
+// inspector requests.
+func credentialsValidator(u, username, password string) *validation.Validator {
+	return validation.New().
+		Required("url", u).
+		URL("url", u).
+		Required("username", username).
+		Required("password", password)
+}
+
+// collectorValidator checks the credentials and the provider of the collector
+// requests.
+func collectorValidator(req v1.CollectorStartRequest) *validation.Validator {
+	var provider string
+	if req.Provider != nil {
+		provider = string(*req.Provider)
+	}
+	return credentialsValidator(req.Url, req.Username, req.Password).
+		OneOf("provider", provider, string(models.ProviderVSphere), string(models.ProviderHyperV), string(models.ProviderOVirt))
+}
+
+// collectorCredentials returns the credentials of a collector request.
+func collectorCredentials(req v1.CollectorStartRequest) *models.Credentials {
+	creds := &models.Credentials{
+		URL:      req.Url,
+		Username: req.Username,
+		Password: req.Password,
+	}
+	if req.Provider != nil {
+		creds.Provider = models.Provider(*req.Provider)
+	}
+	return creds
+}
@@ -121,12 +121,13 @@ var _ = Describe("Collector Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid request body"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("invalid request body")))
+			Expect(response["error"]).To(HaveKeyWithValue("code", "INVALID_REQUEST"))
 		})
 
 		// Given a request missing the URL field
 		// When we try to start the collector
-		// Then it should return 400 Bad Request
+		// Then it should return 400 Bad Request naming the field
 		It("should return 400 when url is missing", func() {
 			// Arrange
 			body := v1.CollectorStartRequest{
@@ -146,7 +147,32 @@ var _ = Describe("Collector Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("url, username, and password are required"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", "url is required"))
+			Expect(response["error"]).To(HaveKeyWithValue("details", HaveKeyWithValue("fields", ConsistOf(
+				map[string]any{"field": "url", "message": "url is required"},
+			))))
+		})
+
+		// Given a request with only a password
+		// When we try to start the collector
+		// Then it should return 400 Bad Request with an error per missing field
+		It("should return every missing field", func() {
+			// Arrange
+			bodyBytes, _ := json.Marshal(v1.CollectorStartRequest{Password: "secret"})
+			req := httptest.NewRequest(http.MethodPost, "/collector", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			var response map[string]any
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response["error"]).To(HaveKeyWithValue("message", "url is required; username is required"))
+			Expect(response["error"]).To(HaveKeyWithValue("details", HaveKeyWithValue("fields", HaveLen(2))))
 		})
 
 		// Given a request missing the username field
@@ -193,7 +219,7 @@ var _ = Describe("Collector Handlers", func() {
 
 		// Given a request with an invalid URL format
 		// When we try to start the collector
-		// Then it should return 400 Bad Request with invalid url format error
+		// Then it should return 400 Bad Request with an invalid url error
 		It("should return 400 for invalid URL format", func() {
 			// Arrange
 			body := v1.CollectorStartRequest{
@@ -214,7 +240,7 @@ var _ = Describe("Collector Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid url format"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring(`invalid url "not-a-valid-url"`)))
 		})
 
 		// Given a request with valid credentials
@@ -240,9 +266,54 @@ var _ = Describe("Collector Handlers", func() {
 			Expect(mockCollector.StartCallCount).To(Equal(1))
 		})
 
+		// Given a request naming the Hyper-V provider
+		// When we start the collector
+		// Then the credentials should be given to the collector service with their provider
+		It("should start collector with the provider of the request", func() {
+			// Arrange
+			provider := v1.CollectorStartRequestProviderHyperv
+			body := v1.CollectorStartRequest{
+				Url:      "https://hv01.example.com",
+				Username: "admin",
+				Password: "secret",
+				Provider: &provider,
+			}
+			bodyBytes, _ := json.Marshal(body)
+			req := httptest.NewRequest(http.MethodPost, "/collector", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+			Expect(mockCollector.StartCreds.Provider).To(Equal(models.ProviderHyperV))
+			Expect(mockCollector.StartCreds.URL).To(Equal("https://hv01.example.com"))
+		})
+
+		// Given a request naming an unknown provider
+		// When we try to start the collector
+		// Then it should return 400 Bad Request without starting the collector
+		It("should return 400 for an unknown provider", func() {
+			// Arrange
+			body := []byte(`{"url": "https://xen.example.com", "username": "admin", "password": "secret", "provider": "xen"}`)
+			req := httptest.NewRequest(http.MethodPost, "/collector", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("provider"))
+			Expect(mockCollector.StartCallCount).To(Equal(0))
+		})
+
 		// Given a collector that is already running
 		// When we try to start it again
-		// Then it should return 409 Conflict
+		// Then it should return 409 Conflict with a retryable COLLECTION_IN_PROGRESS error
 		It("should return 409 when collection already in progress", func() {
 			// Arrange
 			mockCollector.StartError = srvErrors.NewCollectionInProgressError()
@@ -261,6 +332,13 @@ var _ = Describe("Collector Handlers", func() {
 
 			// Assert
 			Expect(w.Code).To(Equal(http.StatusConflict))
+
+			var response srvErrors.ErrorResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Error).NotTo(BeNil())
+			Expect(response.Error.Code).To(Equal(srvErrors.CodeCollectionInProgress))
+			Expect(response.Error.Message).To(Equal("collection already in progress"))
+			Expect(response.Error.Retryable).To(BeTrue())
 		})
 
 		// Given a collector service that returns an unexpected error
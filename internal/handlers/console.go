@@ -8,16 +8,13 @@ import (
 	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
 )
 
 // GetAgentStatus returns the current agent status
 // (GET /agent)
 func (h *Handler) GetAgentStatus(c *gin.Context) {
-	status := h.consoleSrv.Status()
-	var resp v1.AgentStatus
-	resp.FromModel(models.AgentStatus{Console: status})
-
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusOK, h.agentStatus())
 }
 
 // SetAgentMode changes the agent mode
@@ -25,7 +22,7 @@ func (h *Handler) GetAgentStatus(c *gin.Context) {
 func (h *Handler) SetAgentMode(c *gin.Context) {
 	var req v1.AgentModeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		badRequest(c, "invalid request body")
 		return
 	}
 
@@ -36,22 +33,69 @@ func (h *Handler) SetAgentMode(c *gin.Context) {
 	case v1.AgentModeRequestModeDisconnected:
 		mode = models.AgentModeDisconnected
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode: must be 'connected' or 'disconnected'"})
+		badRequest(c, "invalid mode: must be 'connected' or 'disconnected'")
 		return
 	}
 
 	if err := h.consoleSrv.SetMode(c.Request.Context(), mode); err != nil {
 		if errors.IsModeConflictError(err) {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
+	// the added sources follow the agent, a source whose reporting stopped keeps its mode
+	if h.sourcesSrv != nil {
+		if err := h.sourcesSrv.SetMode(c.Request.Context(), mode); err != nil {
+			logger.FromContext(c.Request.Context()).Named("console_handler").Warnw("failed to change the mode of the sources", "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, h.agentStatus())
+}
 
-	status := h.consoleSrv.Status()
+// agentStatus returns the status of the agent with the optional parts of the
+// services set.
+func (h *Handler) agentStatus() v1.AgentStatus {
+	status := models.AgentStatus{Console: h.consoleSrv.Status()}
+	if h.updateSrv != nil {
+		status.Update = h.updateSrv.Status()
+	}
+	if h.haSrv != nil {
+		ha := h.haSrv.Status()
+		status.HA = &ha
+	}
 	var resp v1.AgentStatus
-	resp.FromModel(models.AgentStatus{Console: status})
+	resp.FromModel(status)
+	return resp
+}
+
+// GetConsoleLogin returns the status of the device login of the console token
+// (GET /console/login)
+func (h *Handler) GetConsoleLogin(c *gin.Context) {
+	if h.loginSrv == nil {
+		writeError(c, errors.NewAPIError(errors.CodeFeatureDisabled, "device login is not enabled"))
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewConsoleLogin(h.loginSrv.Status()))
+}
+
+// StartConsoleLogin starts a device login and returns the code to enter
+// (POST /console/login)
+func (h *Handler) StartConsoleLogin(c *gin.Context) {
+	if h.loginSrv == nil {
+		writeError(c, errors.NewAPIError(errors.CodeFeatureDisabled, "device login is not enabled"))
+		return
+	}
+
+	login, err := h.loginSrv.Start(c.Request.Context())
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("console_handler").Errorw("failed to start device login", "error", err)
+		writeError(c, errors.NewAPIError(errors.CodeUpstreamError, err.Error()))
+		return
+	}
 
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusAccepted, v1.NewConsoleLogin(login))
 }
@@ -6,6 +6,7 @@ import (
 	stderrors "errors"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
@@ -112,6 +113,82 @@ var _ = Describe("Console Handlers", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(response.Error).NotTo(BeNil())
 		})
+
+		// Given no update service
+		// When we request the agent status
+		// Then the update status should be omitted
+		It("should omit the update status without update service", func() {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/agent", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			var response v1.AgentStatus
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Update).To(BeNil())
+		})
+
+		// Given an update service which failed to install a newer version
+		// When we request the agent status
+		// Then the update status should report the versions and the failure
+		It("should include the update status", func() {
+			// Arrange
+			handler.WithUpdateService(&MockUpdateService{StatusResult: models.UpdateStatus{
+				State:            models.UpdateStateFailed,
+				CurrentVersion:   "v2.0.0",
+				AvailableVersion: "v2.1.0",
+				Error:            stderrors.New("update checksum mismatch"),
+			}})
+			req := httptest.NewRequest(http.MethodGet, "/agent", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response v1.AgentStatus
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Update).NotTo(BeNil())
+			Expect(response.Update.State).To(Equal(v1.AgentUpdateStateFailed))
+			Expect(response.Update.CurrentVersion).To(Equal("v2.0.0"))
+			Expect(*response.Update.AvailableVersion).To(Equal("v2.1.0"))
+			Expect(*response.Update.Error).To(Equal("update checksum mismatch"))
+			Expect(response.Update.CheckedAt).To(BeNil())
+			Expect(response.Ha).To(BeNil())
+		})
+
+		// Given the standby of a high availability pair
+		// When we request the agent status
+		// Then the status should report the role and the leader
+		It("should include the role in the pair", func() {
+			// Arrange
+			renewed := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+			handler.WithHAService(&MockHAService{StatusResult: models.HAStatus{
+				Role:      models.HARoleStandby,
+				Identity:  "agent-b",
+				Leader:    "agent-a",
+				RenewedAt: renewed,
+			}})
+			req := httptest.NewRequest(http.MethodGet, "/agent", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var response v1.AgentStatus
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Ha).NotTo(BeNil())
+			Expect(response.Ha.Role).To(Equal(v1.AgentHARoleStandby))
+			Expect(response.Ha.Identity).To(Equal("agent-b"))
+			Expect(*response.Ha.Leader).To(Equal("agent-a"))
+			Expect(response.Ha.RenewedAt.Equal(renewed)).To(BeTrue())
+		})
 	})
 
 	Describe("SetAgentMode", func() {
@@ -133,7 +210,7 @@ var _ = Describe("Console Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid request body"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("invalid request body")))
 		})
 
 		// Given a request with an invalid mode value
@@ -157,7 +234,7 @@ var _ = Describe("Console Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid mode"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("invalid mode")))
 		})
 
 		// Given a valid request to set mode to connected
@@ -244,4 +321,97 @@ var _ = Describe("Console Handlers", func() {
 			Expect(w.Code).To(Equal(http.StatusInternalServerError))
 		})
 	})
+
+	Describe("ConsoleLogin", func() {
+		var mockLogin *MockConsoleLoginService
+
+		BeforeEach(func() {
+			mockLogin = &MockConsoleLoginService{}
+			handler.WithConsoleLoginService(mockLogin)
+			router.GET("/console/login", handler.GetConsoleLogin)
+			router.POST("/console/login", handler.StartConsoleLogin)
+		})
+
+		// Given a device login service
+		// When we start a login
+		// Then it should return 202 with the code to enter
+		It("should start a login", func() {
+			// Arrange
+			mockLogin.StartResult = models.DeviceLogin{
+				State:                   models.DeviceLoginStatePending,
+				UserCode:                "ABCD-EFGH",
+				VerificationURI:         "https://sso.example.com/device",
+				VerificationURIComplete: "https://sso.example.com/device?user_code=ABCD-EFGH",
+			}
+			req := httptest.NewRequest(http.MethodPost, "/console/login", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusAccepted))
+
+			var response v1.ConsoleLogin
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.State).To(Equal(v1.ConsoleLoginStatePending))
+			Expect(*response.UserCode).To(Equal("ABCD-EFGH"))
+			Expect(*response.VerificationUriComplete).To(Equal("https://sso.example.com/device?user_code=ABCD-EFGH"))
+		})
+
+		// Given an identity provider failing to start the login
+		// When we start a login
+		// Then it should return 502
+		It("should return 502 when the login cannot start", func() {
+			// Arrange
+			mockLogin.StartError = stderrors.New("connection refused")
+			req := httptest.NewRequest(http.MethodPost, "/console/login", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadGateway))
+		})
+
+		// Given a succeeded login
+		// When we request the login status
+		// Then it should return succeeded
+		It("should return the login status", func() {
+			// Arrange
+			mockLogin.StatusResult = models.DeviceLogin{State: models.DeviceLoginStateSucceeded}
+			req := httptest.NewRequest(http.MethodGet, "/console/login", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response v1.ConsoleLogin
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.State).To(Equal(v1.ConsoleLoginStateSucceeded))
+			Expect(response.UserCode).To(BeNil())
+		})
+	})
+
+	Describe("ConsoleLogin disabled", func() {
+		// Given no device login service
+		// When we start a login
+		// Then it should return 404
+		It("should return 404", func() {
+			// Arrange
+			router.POST("/console/login", handler.StartConsoleLogin)
+			req := httptest.NewRequest(http.MethodPost, "/console/login", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
 })
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// GetDatastoreStats returns the performance statistics of the datastores
+// (GET /datastores/stats)
+func (h *Handler) GetDatastoreStats(c *gin.Context) {
+	stats, err := h.datastoreSrv.ListStats(c.Request.Context())
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("datastore_handler").Errorw("failed to list datastore stats", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	apiStats := make([]v1.DatastoreStats, 0, len(stats))
+	for _, st := range stats {
+		apiStats = append(apiStats, v1.NewDatastoreStats(st))
+	}
+
+	c.JSON(http.StatusOK, apiStats)
+}
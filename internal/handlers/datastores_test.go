@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+var _ = Describe("Datastores Handlers", func() {
+	var (
+		mockDatastore *MockDatastoreService
+		router        *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockDatastore = &MockDatastoreService{}
+		handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithDatastoreService(mockDatastore)
+		router = gin.New()
+		router.GET("/datastores/stats", handler.GetDatastoreStats)
+	})
+
+	Context("GetDatastoreStats", func() {
+		// Given sampled statistics for a datastore
+		// When we get the datastore stats
+		// Then they should be returned
+		It("should return datastore stats", func() {
+			// Arrange
+			mockDatastore.ListStatsResult = []models.DatastoreStats{
+				{ID: "datastore-1", Name: "iscsi-01", ReadKBps: 1024, WriteKBps: 256, ReadLatencyMs: 2, WriteLatencyMs: 3, SampledAt: time.Now()},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/datastores/stats", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response []v1.DatastoreStats
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Id).To(Equal("datastore-1"))
+			Expect(response[0].ReadKBps).To(Equal(int64(1024)))
+			Expect(response[0].WriteLatencyMs).To(Equal(int64(3)))
+		})
+
+		// Given the store fails
+		// When we get the datastore stats
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockDatastore.ListStatsError = errors.New("db error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/datastores/stats", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -52,6 +52,29 @@
 //	│ POST   │ /agent   │ Set agent mode (connected/disconnected)     │
 //	└────────┴──────────┴─────────────────────────────────────────────┘
 //
+// Console Login Endpoints (console.go):
+//
+//	┌────────┬────────────────┬───────────────────────────────────────┐
+//	│ Method │ Endpoint       │ Description                           │
+//	├────────┼────────────────┼───────────────────────────────────────┤
+//	│ GET    │ /console/login │ Get the device login status           │
+//	│ POST   │ /console/login │ Start a device login                  │
+//	└────────┴────────────────┴───────────────────────────────────────┘
+//
+// Source Endpoints (sources.go):
+//
+//	┌────────┬────────────────────────┬─────────────────────────────────┐
+//	│ Method │ Endpoint               │ Description                     │
+//	├────────┼────────────────────────┼─────────────────────────────────┤
+//	│ GET    │ /sources               │ List the sources of the agent   │
+//	│ POST   │ /sources               │ Add a source                    │
+//	│ DELETE │ /sources/{id}          │ Remove a source                 │
+//	│ GET    │ /sources/{id}/collector│ Get the collector of a source   │
+//	│ POST   │ /sources/{id}/collector│ Start the collection of a source│
+//	│ DELETE │ /sources/{id}/collector│ Stop the collection of a source │
+//	│ GET    │ /sources/{id}/inventory│ Get the inventory of a source   │
+//	└────────┴────────────────────────┴─────────────────────────────────┘
+//
 // Collector Endpoints (collector.go):
 //
 //	┌────────┬─────────────┬──────────────────────────────────────────┐
@@ -77,12 +100,123 @@
 //	├────────┼──────────────────┼───────────────────────────────────────┤
 //	│ GET    │ /vms             │ List VMs with filtering/pagination    │
 //	│ GET    │ /vms/{id}        │ Get VM details                        │
+//	│ GET    │ /vms/{id}/events │ Get recent vCenter events and alarms  │
 //	│ GET    │ /vms/inspector   │ Get inspector status (not implemented)│
 //	│ POST   │ /vms/inspector   │ Start inspection (not implemented)    │
 //	│ PATCH  │ /vms/inspector   │ Add VMs to inspection (not impl.)     │
 //	│ DELETE │ /vms/inspector   │ Remove VMs from inspection (not impl.)│
 //	└────────┴──────────────────┴───────────────────────────────────────┘
 //
+// The inspector endpoints answer 404 unless the inspector feature is enabled,
+// see config.FeatureInspector.
+//
+// Cluster Endpoints (clusters.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /clusters/{name}/rules   │ Get cluster DRS rules         │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Infrastructure Endpoints (infrastructure.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /hosts                   │ List ESXi hosts               │
+//	│ GET    │ /datastores              │ List datastores               │
+//	│ GET    │ /networks                │ List switches and port groups │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Datastore Endpoints (datastores.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /datastores/stats        │ Get datastore perf statistics │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Event Endpoints (events.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /events                  │ Get agent lifecycle events    │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Job Endpoints (jobs.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /jobs                    │ List the jobs with progress   │
+//	│ GET    │ /jobs/{id}               │ Get a job                     │
+//	│ DELETE │ /jobs/{id}               │ Cancel a running job          │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Plan Endpoints (plans.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /plans                   │ List the migration plans      │
+//	│ POST   │ /plans                   │ Generate a migration plan     │
+//	│ GET    │ /plans/{id}              │ Get a migration plan          │
+//	│ DELETE │ /plans/{id}              │ Delete a migration plan       │
+//	│ GET    │ /plans/{id}/export       │ Export a plan as a JSON file  │
+//	│ GET    │ /plans/{id}/forklift     │ Export a plan as Forklift CRs │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Benchmark Endpoints (benchmark.go, feature benchmark):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /benchmark               │ Get the latest benchmark      │
+//	│ POST   │ /benchmark               │ Start a throughput benchmark  │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Status Stream Endpoints (ws.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /ws                      │ WebSocket of status updates   │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// Assessment Endpoints (assessment.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /assessment/export       │ Export the signed assessment  │
+//	│ POST   │ /assessment/import       │ Import an assessment bundle   │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
+// GraphQL Endpoint (graphql.go), mounted with Server.MountGraphQL at
+// /api/graphql rather than under /api/v1:
+//
+//	┌──────────┬──────────────────────────┬─────────────────────────────┐
+//	│ Method   │ Endpoint                 │ Description                 │
+//	├──────────┼──────────────────────────┼─────────────────────────────┤
+//	│ GET/POST │ /api/graphql             │ Query the inventory         │
+//	└──────────┴──────────────────────────┴─────────────────────────────┘
+//
+// Policy Endpoints (policies.go):
+//
+//	┌────────┬──────────────────────────┬───────────────────────────────┐
+//	│ Method │ Endpoint                 │ Description                   │
+//	├────────┼──────────────────────────┼───────────────────────────────┤
+//	│ GET    │ /policies                │ List the policies in use      │
+//	│ POST   │ /policies/evaluate       │ Evaluate the policies on a VM │
+//	│ POST   │ /policies/test           │ Run the rego tests            │
+//	│ GET    │ /policies/decisions      │ Get the policy decision log   │
+//	│ GET    │ /policies/custom         │ List the custom policies      │
+//	│ PUT    │ /policies/custom/{name}  │ Upload a custom policy        │
+//	│ DELETE │ /policies/custom/{name}  │ Delete a custom policy        │
+//	│ GET    │ /concerns/{id}           │ Get a concern and metadata    │
+//	└────────┴──────────────────────────┴───────────────────────────────┘
+//
 // VDDK Endpoints (vddk.go):
 //
 //	┌────────┬──────────────────┬───────────────────────────────────────┐
@@ -98,7 +232,18 @@
 //	{
 //	    "consoleConnection": "connected",  // current connection state
 //	    "mode": "connected",               // target mode
-//	    "error": null                      // optional error message
+//	    "error": null,                     // optional error message
+//	    "update": {                        // optional self-update state
+//	        "state": "up-to-date",         // disabled, up-to-date, downloading, installed, failed
+//	        "currentVersion": "v2.0.0",
+//	        "checkedAt": "2026-01-01T00:00:00Z"
+//	    },
+//	    "ha": {                            // optional, set with WithHAService
+//	        "role": "standby",             // leader or standby
+//	        "identity": "agent-b",         // name of this agent in the lease
+//	        "leader": "agent-a",           // optional holder of the lease
+//	        "renewedAt": "2026-01-01T00:00:00Z"
+//	    }
 //	}
 //
 // POST /agent - Changes agent mode:
@@ -112,6 +257,86 @@
 // Errors:
 //   - 400 Bad Request: Invalid mode value
 //   - 409 Conflict: Mode change blocked after fatal console error
+//   - 503 Service Unavailable: The agent is the standby of its pair (STANDBY),
+//     answered by the server before the handler
+//
+// # Console Login Handler
+//
+// POST /console/login - Starts a device login of the console token, 202:
+//
+//	{
+//	    "state": "pending",
+//	    "userCode": "ABCD-EFGH",
+//	    "verificationUri": "https://sso.redhat.com/.../device",
+//	    "verificationUriComplete": "https://sso.redhat.com/.../device?user_code=ABCD-EFGH",
+//	    "expiresAt": "2026-01-01T10:10:00Z"
+//	}
+//
+// A pending login is returned as is, so every client shows the same code.
+//
+// GET /console/login - Returns the status of the last login: idle, pending,
+// succeeded (expiresAt is the token expiry) or failed (with error).
+//
+// Errors:
+//   - 404 Not Found: Device login is not enabled (no WithConsoleLoginService)
+//   - 502 Bad Gateway: The identity provider could not start the login
+//
+// # Status Stream Handler
+//
+// GET /ws - Upgrades to a WebSocket and pushes a StatusUpdate right away, then
+// whenever the agent status or the collector state changes (the services are
+// polled every 500ms and unchanged snapshots are not sent):
+//
+//	{
+//	    "agent":     { "console_connection": "connected", "mode": "connected" },
+//	    "collector": { "status": "collecting" }
+//	}
+//
+// The connection is kept alive with pings every 30s. Client messages are
+// ignored. Browser origins must be the agent itself or listed in
+// CORSAllowedOrigins, otherwise the handshake fails with 403.
+//
+// # GraphQL Handler
+//
+// GET, POST /api/graphql - Runs a query of the inventory schema
+// (api/graphql/schema.graphql) with the InventoryGraphService, gated by the
+// graphqlAPI feature. A POST sends {"query", "operationName", "variables"},
+// a GET the same as query parameters, variables as JSON. The response is
+// always 200 with {"data", "errors"}, the query errors being GraphQL errors:
+//
+//	{
+//	    "data": { "vm": { "name": "web-1", "concerns": [ { "title": "..." } ] } }
+//	}
+//
+// Errors:
+//   - 400 Bad Request: Invalid JSON, or no query
+//   - 404 Not Found: Feature disabled, or no graph service set
+//     (WithInventoryGraphService)
+//
+// # Assessment Handlers
+//
+// GET /assessment/export - Returns the assessment bundle of the agent, a
+// tar.gz of its inventory, inspections and events with a signed manifest, as
+// an attachment. X-Assessment-Fingerprint is the fingerprint of the signing
+// key.
+//
+// POST /assessment/import - Verifies the tar.gz body, signed by one of the
+// trusted keys of the agent, the one of the optional fingerprint query
+// parameter, and sends its inventory to the console. Returns the manifest:
+//
+//	{
+//	    "agentId": "...", "sourceId": "...", "agentVersion": "v1.2.0",
+//	    "createdAt": "...", "collectedAt": "...", "fingerprint": "3f5c..."
+//	}
+//
+// Errors:
+//   - 400 Bad Request: INVALID_BUNDLE, the archive, its signature or a file
+//     does not match, or the key is not trusted
+//   - 404 Not Found: No inventory to export, or no assessment service set
+//     (WithAssessmentService)
+//   - 409 Conflict: The agent is not in connected mode
+//   - 413 Payload Too Large: Bundle exceeds 256MB
+//   - 502 Bad Gateway: The console rejected the inventory
 //
 // # Collector Handler
 //
@@ -144,6 +369,24 @@
 //
 // DELETE /collector - Stops ongoing collection, returns to ready state.
 //
+// # Sources Handler
+//
+// GET /sources - Lists the sources of the agent, the primary one first, with
+// their collector status and console connection.
+//
+// POST /sources - Adds a source, {"id": "<uuid>", "name": "vcenter-2"}, 201.
+// The agent then reports to the console for it too, in its own mode.
+//
+// DELETE /sources/{id} - Removes a source and deletes its inventory (204).
+//
+// /sources/{id}/collector and GET /sources/{id}/inventory work as /collector
+// and /inventory for the source id; the primary source is served there too.
+//
+// Errors:
+//   - 400 Bad Request: Invalid source id or credentials
+//   - 404 Not Found: Unknown source
+//   - 409 Conflict: Source already managed, or the primary source removed
+//
 // # Inventory Handler
 //
 // GET /inventory - Returns raw inventory JSON.
@@ -167,6 +410,8 @@
 //	│ diskSizeMax    │ int64    │ Maximum disk size in MB                 │
 //	│ memorySizeMin  │ int64    │ Minimum memory in MB                    │
 //	│ memorySizeMax  │ int64    │ Maximum memory in MB                    │
+//	│ encrypted      │ bool     │ Filter by vSphere VM encryption         │
+//	│ severity       │ []string │ Filter by concern severity (OR logic)   │
 //	│ sort           │ []string │ Sort fields (format: "field:direction") │
 //	│ page           │ int      │ Page number (default: 1)                │
 //	│ pageSize       │ int      │ Items per page (default: 20, max: 100)  │
@@ -184,6 +429,7 @@
 //
 //	{
 //	    "page": 1,
+//	    "pageSize": 20,
 //	    "pageCount": 5,
 //	    "total": 100,
 //	    "vms": [
@@ -206,11 +452,265 @@
 //   - Invalid sort field
 //   - Invalid sort direction
 //
-// GET /vms/{id} - Returns detailed VM information.
+// GET /vms/{id} - Returns detailed VM information, including the CPU topology
+// (cpuSockets, coresPerSocket), the CPU and memory hot add flags, the firmware
+// and the hardware version that drive the conversion decisions.
 //
 // Errors:
 //   - 404 Not Found: VM not found
 //
+// GET /vms/{id}/events - Returns the vCenter events and triggered alarms of the
+// VM and of the host it runs on, newest first.
+//
+// Errors:
+//   - 404 Not Found: VM not found
+//
+// # Cluster Handler
+//
+// GET /clusters/{name}/rules - Returns the DRS affinity/anti-affinity rules
+// collected for the cluster. VM-host rules carry the VM and host groups
+// resolved to their members.
+//
+// Errors:
+//   - 404 Not Found: Cluster not in the inventory
+//
+// # Pagination
+//
+// The list endpoints (/vms, /hosts, /datastores, /networks, /events and
+// /admin/audit) take the page and pageSize query parameters (default 1 and 20,
+// pageSize capped to 100) and answer with the same envelope: the items of the
+// page under the name of the listing, next to total, page, pageSize and
+// pageCount (1 for an empty listing). The services return a models.Page, read
+// at the models.Cursor of the parameters, and api/v1 converts it with the
+// New*ListResponse helpers.
+//
+// # Infrastructure Handler
+//
+// GET /hosts - Returns the ESXi hosts of the inventory ordered by ID. Query
+// parameter: cluster, to return only the hosts of that cluster.
+//
+// GET /datastores - Returns the datastores ordered by ID, with the hosts
+// mounting them. Query parameter: cluster, to return only the datastores
+// mounted by the hosts of that cluster.
+//
+// GET /networks - Returns the distributed switches and port groups ordered by
+// name, with the number of VM NICs attached to each.
+//
+// The unknown hardware and storage values are omitted rather than sent as "N/A".
+//
+// Errors:
+//   - 404 Not Found: Cluster not in the inventory
+//
+// # Datastore Handler
+//
+// GET /datastores/stats - Returns the read/write throughput (KB/s, summed over
+// the hosts) and latency (ms, worst host) sampled during the last collection.
+//
+// # Event Handler
+//
+// GET /events - Returns the lifecycle events recorded by the agent, newest
+// first, paginated. Query parameters: type (repeated, any of them) and since
+// (RFC 3339).
+//
+// Errors:
+//   - 400 Bad Request: Invalid since
+//   - 404 Not Found: No event service set (WithEventService)
+//
+// # Job Handler
+//
+// GET /jobs - Returns the jobs of the collections and inspections, newest
+// first, paginated, with their state, phase and progress in percent. Query
+// parameters: kind and state (repeated, any of them). /collector and
+// /vms/inspector report the current operation only; /jobs keeps their history.
+//
+// GET /jobs/{id} - Returns a job.
+//
+// DELETE /jobs/{id} - Cancels the operation of a running job and returns the
+// job, canceled.
+//
+// Errors:
+//   - 400 Bad Request: Unknown kind or state
+//   - 404 Not Found: Unknown job, or no job service set (WithJobService)
+//   - 409 Conflict: Job no longer running (JOB_NOT_RUNNING)
+//
+// # Plan Handler
+//
+// POST /plans - Generates the migration plan of VMs selected in waves, which
+// are migrated in the given order, the VMs of a wave from the smallest disks
+// to the largest. Each VM gets the time its disks take to transfer at the
+// throughput of the request, else the one of the latest benchmark, else
+// 100 MB/s; each wave and the plan get
+// the total and the resources the target needs (VMs, CPUs, memory, storage):
+//
+//	{
+//	    "name": "cutover",
+//	    "throughputMBps": 250,
+//	    "waves": [
+//	        {"name": "apps", "vms": ["vm-1", "vm-2"]},
+//	        {"vms": ["vm-3"]}
+//	    ]
+//	}
+//
+// GET /plans - Lists the plans, newest first.
+//
+// GET /plans/{id} - Returns a plan.
+//
+// DELETE /plans/{id} - Deletes a plan.
+//
+// GET /plans/{id}/export - Returns a plan as an attachment, plan-<id>.json.
+//
+// GET /plans/{id}/forklift - Returns the CRs migrating a plan with Forklift
+// (MTV) as a YAML attachment, forklift-<id>.yaml: a NetworkMap, a StorageMap
+// and a Plan per wave. sourceProvider, targetNamespace and storageClass are
+// required; each network=<network ID>=<destination> maps a network to pod,
+// ignored or a <namespace>/<name> network attachment definition, the others
+// going to pod, and each storage=<datastore>=<class> a datastore to its own
+// storage class.
+//
+// Errors:
+//   - 400 Bad Request: No wave, a wave without VMs, a VM in several waves, or a
+//     throughput that is not positive; a missing Forklift parameter or an
+//     invalid mapping
+//   - 404 Not Found: Unknown plan or VM, or no plan service set (WithPlanService)
+//
+// # Benchmark Handler
+//
+// POST /benchmark - Starts a benchmark job reading the largest disk of each
+// datastore of the vCenter, all of them when none is given, and sending toward
+// the target, a tcp:// listener or an http(s):// URL, when set. Each measure
+// lasts durationSeconds, 10 by default. Returns 202 with the id of the job,
+// canceled by DELETE /jobs/{id}:
+//
+//	{
+//	    "VcenterCredentials": {"url": "https://vcenter.example.com", "username": "admin", "password": "secret"},
+//	    "datastores": ["datastore1"],
+//	    "target": "tcp://10.0.0.12:9000",
+//	    "durationSeconds": 30
+//	}
+//
+// GET /benchmark - Returns the measures of the latest benchmark and the rate
+// the plans use: the one of the slowest datastore, bounded by the network.
+//
+// Errors:
+//   - 400 Bad Request: Invalid credentials, target or duration
+//   - 404 Not Found: Feature disabled, no benchmark ran, or no benchmark service
+//     set (WithBenchmarkService)
+//   - 409 Conflict: Benchmark already in progress (BENCHMARK_IN_PROGRESS)
+//   - 502 Bad Gateway: vCenter unreachable or rejecting the credentials
+//     (VCENTER_ERROR)
+//
+// # Policy Handler
+//
+// GET /policies - Lists the policy files in use, with the SHA-256 of each and
+// its source: builtin (shipped in the folder), console (from the installed
+// bundle) or custom. The fingerprint changes with any file; loadError is the
+// error of the last reload, the previous policies being kept in use:
+//
+//	{
+//	    "fingerprint": "9f86d0...",
+//	    "loadedAt": "2026-01-02T15:04:05Z",
+//	    "bundle": {"url": "...", "revision": "2026.01", "installedAt": "..."},
+//	    "policies": [
+//	        {"name": "cpu.rego", "source": "console", "hash": "2c26b4..."},
+//	        {"name": "custom/naming.rego", "source": "custom", "hash": "fcde2b..."}
+//	    ]
+//	}
+//
+// Errors:
+//   - 404 Not Found: No policies (no WithPolicyService)
+//
+// POST /policies/evaluate - Runs the OPA policies in use against a VM and
+// returns the concerns they raise, without storing them. The VM is one of the
+// inventory, {"vmId": "vm-1"}, or a document as the policies receive it,
+// {"vm": {"name": "web-1", ...}}:
+//
+//	{
+//	    "vmId": "vm-1",
+//	    "vmName": "db-1",
+//	    "concerns": [
+//	        {"id": "vmware.cpu.hotadd", "label": "CPU hot add", "category": "Warning", "assessment": "..."}
+//	    ]
+//	}
+//
+// Errors:
+//   - 400 Bad Request: Neither or both of vmId and vm, or a vm that is not a VM
+//   - 404 Not Found: Unknown VM, or no policies (no WithPolicyService)
+//
+// GET /policies/custom - Lists the custom policies, uploaded by the users:
+// [{"name": "naming.rego", "size": 312, "modifiedAt": "2026-10-01T12:00:00Z"}]
+//
+// PUT /policies/custom/{name} - Uploads the custom policy name, the request
+// body being its rego (text/plain, 1MB at most), and reloads the policies
+// (204). The IDs of the concerns it raises are prefixed with "custom.".
+//
+// DELETE /policies/custom/{name} - Deletes the custom policy name (204).
+//
+// Errors:
+//   - 400 Bad Request: Name not of a .rego file, or a policy that does not
+//     compile with the other custom policies
+//   - 404 Not Found: Unknown custom policy (DELETE)
+//   - 413 Request Entity Too Large: Policy exceeds 1MB
+//
+// POST /policies/test - Runs the rego tests of the policies folder, the
+// test_ rules of its _test.rego files, and of its custom subfolder. The
+// optional body gives candidate custom policies and tests, run along the
+// custom ones without being uploaded:
+//
+//	{"policies": {"naming.rego": "...", "naming_test.rego": "..."}}
+//
+// Response:
+//
+//	{
+//	    "passed": 1,
+//	    "failed": 0,
+//	    "skipped": 0,
+//	    "results": [
+//	        {"file": "custom/naming_test.rego", "package": "data.io.konveyor.forklift.vmware",
+//	         "name": "test_naming", "status": "pass", "durationMs": 2}
+//	    ]
+//	}
+//
+// Errors:
+//   - 400 Bad Request: File name not of a .rego file, or tests that do not
+//     compile
+//   - 404 Not Found: No policies (no WithPolicyService)
+//
+// GET /concerns/{id} - Returns a concern with the metadata of its rule, read
+// from the METADATA annotations of the policies, and the number of VMs it
+// flags. The severity is the one of the metadata, else the category in
+// lowercase; the concerns returned by POST /policies/evaluate carry it too:
+//
+//	{
+//	    "id": "vmware.cpu.hotadd",
+//	    "title": "CPU hot add",
+//	    "label": "CPU hot add enabled",
+//	    "category": "Warning",
+//	    "severity": "warning",
+//	    "remediation": "Disable CPU hot add before the migration",
+//	    "docsUrl": "https://docs.example.com/cpu-hot-add",
+//	    "vmCount": 2
+//	}
+//
+// Errors:
+//   - 404 Not Found: Concern neither raised nor annotated, or no policies
+//
+// GET /policies/decisions - Returns the decision log of the policy
+// evaluations, newest first, paginated: a flagged decision per concern raised
+// for a VM, or a passed one, with the duration of the evaluation. Query
+// parameters: vmId, rule, decision (flagged or passed) and since (RFC 3339).
+//
+//	{
+//	    "decisions": [
+//	        {"vmId": "vm-1", "vmName": "db-1", "rule": "vmware.cpu.hotadd",
+//	         "decision": "flagged", "durationUs": 1520, "createdAt": "..."}
+//	    ],
+//	    "total": 1, "page": 1, "pageSize": 20, "pageCount": 1
+//	}
+//
+// Errors:
+//   - 400 Bad Request: Unknown decision, or invalid since
+//   - 404 Not Found: No decision service set (WithPolicyDecisionService)
+//
 // # VDDK Handler
 //
 // POST /vddk - Uploads a VDDK tarball to the agent's data directory.
@@ -231,25 +731,129 @@
 //
 // The uploaded file is saved as "vddk.tar.gz" in the agent's data directory.
 //
+// # Admin Handler
+//
+// Registered on the /admin group by RegisterAdminRoutes rather than generated
+// from the OpenAPI specification.
+//
+// GET /admin/migrations - Returns the status of the schema migrations.
+//
+// GET /admin/config - Returns the resolved configuration, secrets excluded.
+//
+// GET /admin/runtime - Returns the goroutine count, the heap and process
+// memory in bytes, the garbage collections with their total and last ten
+// pauses in milliseconds, the database pool connections, the scheduler
+// workers, busy workers and queued work, and per route the number of requests
+// with their mean and 95th percentile latency and mean response size.
+//
+// GET /admin/loglevel - Returns the log level and the levels of the components
+// (named loggers): {"level": "info", "components": {"store": "debug"}}.
+//
+// PUT /admin/loglevel - Changes the log level from {"level": "debug"}.
+//
+// PUT /admin/loglevel/{component} - Changes the level of a component, e.g.
+// collector for the collector_service and collector_handler loggers.
+//
+// DELETE /admin/loglevel/{component} - Removes the level of a component, which
+// follows the log level again (204).
+//
+// The changes apply right away and last until the agent restarts or reloads
+// its configuration, which sets the levels of the configuration again.
+//
+// Errors:
+//   - 400 Bad Request: Missing or unknown level (PUT)
+//   - 404 Not Found: No level set for the component (DELETE)
+//
+// POST /admin/apikeys - Creates an API key from {"name": "...", "role": "..."}
+// and returns it with its id and prefix (201). The key is only returned here.
+// The role is viewer (the default) or operator.
+//
+// GET /admin/apikeys - Lists the API keys, revoked ones with their revokedAt.
+//
+// DELETE /admin/apikeys/{id} - Revokes an API key (204).
+//
+// Errors:
+//   - 400 Bad Request: Missing name or unknown role
+//   - 404 Not Found: No such key or key already revoked
+//
+// GET /admin/audit - Lists the most recent mutating requests of /api and
+// /admin, newest first: who sent them (subject and role), method, route,
+// status and request ID, paginated under "entries".
+//
+// Errors:
+//   - 400 Bad Request: Page or pageSize not a positive integer
+//
+// GET /admin/credentials - Returns the url and username of the stored vCenter
+// credentials, never the password.
+//
+// DELETE /admin/credentials - Forgets the stored vCenter credentials (204).
+//
+// Errors:
+//   - 404 Not Found: No credentials stored (GET)
+//
+// GET /admin/support-bundle - Streams support-bundle-<time>.tar.gz with the
+// redacted configuration, the collector, inspector and console states, the
+// migrations, database and scheduler stats, the last console dispatch errors
+// and the recent logs. A part that cannot be collected is reported in its
+// errors.txt.
+//
+// The /admin/apikeys endpoints are only registered with WithAPIKeyService and
+// an admin listener (AdminPort or AdminUnixSocketPath),
+// /admin/audit with WithAuditService, /admin/credentials with
+// WithCredentialsService and /admin/support-bundle with
+// WithSupportBundleService.
+//
 // # Error Handling
 //
-// Handlers use consistent error response format:
+// Handlers, the middlewares and the parameter binding of the generated server
+// (ParamErrorHandler) answer errors with the same body, the APIError of
+// pkg/errors, whose code clients branch on rather than on the message:
 //
-//	{ "error": "error message" }
+//	{
+//	    "error": {
+//	        "code": "COLLECTION_IN_PROGRESS",
+//	        "message": "collection already in progress",
+//	        "details": {...},            // optional, e.g. kind and id of NOT_FOUND
+//	        "retryable": true            // whether the same request may succeed later
+//	    }
+//	}
 //
-// HTTP Status Code Mapping:
+// writeError maps the errors of the services with errors.ToAPIError, the code
+// setting the HTTP status:
+//
+//	┌─────────────────────────────┬────────────────────────┬────────┐
+//	│ Error                       │ Code                   │ Status │
+//	├─────────────────────────────┼────────────────────────┼────────┤
+//	│ Validation error            │ INVALID_REQUEST        │ 400    │
+//	│ InvalidStateError           │ INVALID_STATE          │ 400    │
+//	│ ResourceNotFoundError       │ NOT_FOUND              │ 404    │
+//	│ Disabled feature            │ FEATURE_DISABLED       │ 404    │
+//	│ InspectorNotRunningError    │ INSPECTOR_NOT_RUNNING  │ 404    │
+//	│ CollectionInProgressError   │ COLLECTION_IN_PROGRESS │ 409    │
+//	│ BenchmarkInProgressError    │ BENCHMARK_IN_PROGRESS  │ 409    │
+//	│ ModeConflictError           │ MODE_CONFLICT          │ 409    │
+//	│ SourceConflictError         │ SOURCE_CONFLICT        │ 409    │
+//	│ JobNotRunningError          │ JOB_NOT_RUNNING        │ 409    │
+//	│ MaxBytesError               │ PAYLOAD_TOO_LARGE      │ 413    │
+//	│ Device login failure        │ UPSTREAM_ERROR         │ 502    │
+//	│ Internal error              │ INTERNAL_ERROR         │ 500    │
+//	└─────────────────────────────┴────────────────────────┴────────┘
+//
+// The request bodies and parameters are checked with a pkg/validation
+// Validator collecting every invalid field; invalid answers its errors with
+// INVALID_REQUEST, their messages joined in message and listed in details:
 //
-//	┌─────────────────────────────┬────────┬──────────────────────────────┐
-//	│ Error Type                  │ Status │ When                         │
-//	├─────────────────────────────┼────────┼──────────────────────────────┤
-//	│ Validation error            │ 400    │ Invalid request params       │
-//	│ ResourceNotFoundError       │ 404    │ Resource doesn't exist       │
-//	│ CollectionInProgressError   │ 409    │ Collection already running   │
-//	│ ModeConflictError           │ 409    │ Mode change after fatal err  │
-//	│ MaxBytesError               │ 413    │ Upload exceeds size limit    │
-//	│ Internal error              │ 500    │ Unexpected service errors    │
-//	│ Not implemented             │ 501    │ Inspector endpoints          │
-//	└─────────────────────────────┴────────┴──────────────────────────────┘
+//	{
+//	    "error": {
+//	        "code": "INVALID_REQUEST",
+//	        "message": "url is required; username is required",
+//	        "details": {"fields": [
+//	            {"field": "url", "message": "url is required"},
+//	            {"field": "username", "message": "username is required"}
+//	        ]},
+//	        "retryable": false
+//	    }
+//	}
 //
 // # Model Conversion
 //
@@ -261,6 +865,13 @@
 //   - v1.NewVMDetailsFromModel(models.VM) → v1.VMDetails
 //   - v1.AgentStatus.FromModel(models.AgentStatus)
 //
+// The states are cast to the enum types oapi-codegen generates from the spec.
+// api/v1/enums.gen.go, generated from the same spec by go generate, lists the
+// values of each enum and gives it a Valid method, so the cast is checked
+// rather than switched on. The models list their states (for example
+// models.CollectorStates) and the api/v1 tests fail when one of them is
+// missing from the spec, instead of it silently falling back to a default.
+//
 // # Framework
 //
 // The package uses the Gin web framework. Routes are auto-generated from
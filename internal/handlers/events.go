@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// GetEvents returns the lifecycle events of the agent, newest first
+// (GET /events)
+func (h *Handler) GetEvents(c *gin.Context, params v1.GetEventsParams) {
+	if h.eventSrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "events are not recorded"))
+		return
+	}
+
+	var filter models.AgentEventFilter
+	if params.Type != nil {
+		for _, t := range *params.Type {
+			filter.Types = append(filter.Types, models.AgentEventType(t))
+		}
+	}
+	if params.Since != nil {
+		filter.Since = *params.Since
+	}
+
+	page, err := h.eventSrv.List(c.Request.Context(), filter, pageCursor(params.Page, params.PageSize))
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("event_handler").Errorw("failed to list events", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewAgentEventListResponse(page))
+}
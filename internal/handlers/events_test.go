@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+var _ = Describe("Events Handlers", func() {
+	var (
+		mockEvents *MockEventService
+		handler    *handlers.Handler
+		router     *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockEvents = &MockEventService{}
+		handler = handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithEventService(mockEvents)
+		router = gin.New()
+		router.GET("/events", func(c *gin.Context) {
+			var params v1.GetEventsParams
+			if err := c.ShouldBindQuery(&params); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			handler.GetEvents(c, params)
+		})
+	})
+
+	Context("GetEvents", func() {
+		// Given recorded events
+		// When we get the events
+		// Then they should be returned in the first page of the default size
+		It("should return the events", func() {
+			// Arrange
+			at := time.Now().UTC().Truncate(time.Second)
+			mockEvents.ListResult = []models.AgentEvent{
+				{Type: models.AgentEventCollectionFailed, Time: at, Message: "inventory collection failed", Details: map[string]string{"phase": "connecting"}},
+				{Type: models.AgentEventCollectionStarted, Time: at.Add(-time.Minute), Message: "inventory collection started"},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/events", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockEvents.ListCursor).To(Equal(models.Cursor{Offset: 0, Limit: 20}))
+
+			var response v1.AgentEventListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(2))
+			Expect(response.Page).To(Equal(1))
+			Expect(response.PageCount).To(Equal(1))
+			events := response.Events
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Type).To(Equal("collection.failed"))
+			Expect(events[0].Details).NotTo(BeNil())
+			Expect(*events[0].Details).To(HaveKeyWithValue("phase", "connecting"))
+			Expect(events[0].CreatedAt.Equal(at)).To(BeTrue())
+			Expect(events[1].Details).To(BeNil())
+		})
+
+		// Given type, since and pagination query parameters
+		// When we get the events
+		// Then they should be passed to the service as a filter and a cursor
+		It("should filter the events", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/events?type=collection.failed&type=inspection.failed&since=2026-01-02T15:04:05Z&page=2&pageSize=10", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockEvents.ListFilter.Types).To(Equal([]models.AgentEventType{models.AgentEventCollectionFailed, models.AgentEventInspectionFailed}))
+			Expect(mockEvents.ListFilter.Since.Equal(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC))).To(BeTrue())
+			Expect(mockEvents.ListCursor).To(Equal(models.Cursor{Offset: 10, Limit: 10}))
+		})
+
+		// Given a page size above the maximum
+		// When we get the events
+		// Then the page size should be capped to 100
+		It("should cap the page size", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/events?pageSize=5000", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockEvents.ListCursor.Limit).To(Equal(uint64(100)))
+		})
+
+		// Given the store fails
+		// When we get the events
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockEvents.ListError = errors.New("db error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/events", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		// Given a handler without event service
+		// When we get the events
+		// Then 404 should be returned
+		It("should return 404 when events are not recorded", func() {
+			// Arrange
+			handler = handlers.New(config.Configuration{}, nil, nil, nil, nil, nil)
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/events", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
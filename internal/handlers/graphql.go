@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+)
+
+// GraphQL runs a GraphQL query over the inventory, POSTed as JSON or given in
+// the query, operationName and variables query parameters of a GET
+// (GET, POST /api/graphql)
+func (h *Handler) GraphQL(c *gin.Context) {
+	if h.featureDisabled(c, config.FeatureGraphQLAPI) {
+		return
+	}
+	if h.graphSrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "the inventory is not served over graphql"))
+		return
+	}
+
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if vars := c.Query("variables"); vars != "" {
+			if err := decodeJSON([]byte(vars), &req.Variables); err != nil {
+				badRequest(c, "invalid variables: "+err.Error())
+				return
+			}
+		}
+	} else {
+		body, err := c.GetRawData()
+		if err != nil {
+			badRequest(c, "failed to read the request: "+err.Error())
+			return
+		}
+		if err := decodeJSON(body, &req); err != nil {
+			badRequest(c, "invalid request: "+err.Error())
+			return
+		}
+	}
+	if req.Query == "" {
+		badRequest(c, "query is required")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.graphSrv.Execute(c.Request.Context(), req))
+}
+
+// decodeJSON decodes data into v, keeping the numbers as json.Number for the
+// coercion of the GraphQL variables.
+func decodeJSON(data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
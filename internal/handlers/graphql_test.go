@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+)
+
+var _ = Describe("GraphQL Handler", func() {
+	var (
+		mockGraph *MockInventoryGraphService
+		handler   *handlers.Handler
+		router    *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockGraph = &MockInventoryGraphService{
+			Result: graphql.Response{Data: map[string]any{"vm": map[string]any{"name": "web"}}},
+		}
+		cfg := config.Configuration{Features: map[string]bool{config.FeatureGraphQLAPI: true}}
+		handler = handlers.New(cfg, nil, nil, nil, nil, nil).WithInventoryGraphService(mockGraph)
+		router = gin.New()
+		router.GET("/api/graphql", handler.GraphQL)
+		router.POST("/api/graphql", handler.GraphQL)
+	})
+
+	// Given a posted query with variables
+	// When we serve it
+	// Then the request should be executed and its response returned
+	It("should execute a posted query", func() {
+		// Act
+		body := `{"query": "query($id: ID!) { vm(id: $id) { name } }", "operationName": "", "variables": {"id": "vm-1", "limit": 5}}`
+		req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(MatchJSON(`{"data": {"vm": {"name": "web"}}}`))
+		Expect(mockGraph.LastRequest.Query).To(Equal("query($id: ID!) { vm(id: $id) { name } }"))
+		Expect(mockGraph.LastRequest.Variables).To(Equal(map[string]any{"id": "vm-1", "limit": json.Number("5")}))
+	})
+
+	// Given a query in the query parameters
+	// When we serve it
+	// Then the request should be executed with its variables
+	It("should execute a query of a GET", func() {
+		// Act
+		params := url.Values{
+			"query":         {"query Q($id: ID!) { vm(id: $id) { name } }"},
+			"operationName": {"Q"},
+			"variables":     {`{"id": "vm-1"}`},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/graphql?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(mockGraph.LastRequest.OperationName).To(Equal("Q"))
+		Expect(mockGraph.LastRequest.Variables).To(Equal(map[string]any{"id": "vm-1"}))
+	})
+
+	// Given a request without a query or with invalid JSON
+	// When we serve it
+	// Then 400 should be returned
+	It("should return 400 for invalid requests", func() {
+		for _, body := range []string{`{"variables": {}}`, `{"query": `} {
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		}
+		Expect(mockGraph.LastRequest.Query).To(BeEmpty())
+	})
+
+	// Given the graphqlAPI feature disabled
+	// When we post a query
+	// Then 404 FEATURE_DISABLED should be returned
+	It("should return 404 when the feature is disabled", func() {
+		// Arrange
+		handler.WithFeatureGate(config.NewFeatureGate(nil))
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(`{"query": "{ networks { name } }"}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(w.Body.String()).To(ContainSubstring(string(srvErrors.CodeFeatureDisabled)))
+		Expect(mockGraph.LastRequest.Query).To(BeEmpty())
+	})
+})
@@ -2,10 +2,18 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
 )
 
 // CollectorService defines the interface for collector operations.
@@ -28,8 +36,9 @@ type ConsoleService interface {
 
 // VMService defines the interface for VM operations.
 type VMService interface {
-	List(ctx context.Context, params services.VMListParams) ([]models.VMSummary, int, error)
+	List(ctx context.Context, params services.VMListParams) (models.Page[models.VMSummary], error)
 	Get(ctx context.Context, id string) (*models.VM, error)
+	Events(ctx context.Context, id string) ([]models.VCenterEvent, error)
 }
 
 // InspectorService defines the interface for deep inspector operations.
@@ -42,13 +51,170 @@ type InspectorService interface {
 	Stop(ctx context.Context) error
 }
 
+// ClusterService defines the interface for cluster operations.
+type ClusterService interface {
+	ListRules(ctx context.Context, cluster string) ([]models.ClusterRule, error)
+}
+
+// DatastoreService defines the interface for datastore operations.
+type DatastoreService interface {
+	ListStats(ctx context.Context) ([]models.DatastoreStats, error)
+}
+
+// InfrastructureService defines the interface for the hosts, datastores and networks.
+type InfrastructureService interface {
+	ListHosts(ctx context.Context, cluster string, cursor models.Cursor) (models.Page[models.Host], error)
+	ListDatastores(ctx context.Context, cluster string, cursor models.Cursor) (models.Page[models.Datastore], error)
+	ListNetworks(ctx context.Context, cursor models.Cursor) (models.Page[models.Network], error)
+}
+
+// AdminService defines the interface for admin operations.
+type AdminService interface {
+	Migrations(ctx context.Context) ([]models.Migration, error)
+	Runtime() models.RuntimeStats
+}
+
+// APIKeyService defines the interface for API key operations.
+type APIKeyService interface {
+	Create(ctx context.Context, name string, role models.Role) (*models.APIKey, string, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+// ConsoleLoginService defines the interface for the device login of the console token.
+type ConsoleLoginService interface {
+	Start(ctx context.Context) (models.DeviceLogin, error)
+	Status() models.DeviceLogin
+}
+
+// AuditService defines the interface for audit log operations.
+type AuditService interface {
+	List(ctx context.Context, cursor models.Cursor) (models.Page[models.AuditEntry], error)
+}
+
+// EventService defines the interface for the agent events.
+type EventService interface {
+	List(ctx context.Context, filter models.AgentEventFilter, cursor models.Cursor) (models.Page[models.AgentEvent], error)
+}
+
+// CredentialsService defines the interface for the stored vCenter credentials.
+type CredentialsService interface {
+	Get(ctx context.Context) (*models.Credentials, error)
+	Delete(ctx context.Context) error
+}
+
+// PolicyService defines the interface for the evaluation of the OPA policies
+// and the custom policies.
+type PolicyService interface {
+	Evaluate(ctx context.Context, vmID string, document map[string]any) (*models.PolicyEvaluation, error)
+	ListPolicies(ctx context.Context) (*models.PolicySet, error)
+	ListCustomPolicies(ctx context.Context) ([]models.CustomPolicy, error)
+	PutCustomPolicy(ctx context.Context, name string, content []byte) error
+	DeleteCustomPolicy(ctx context.Context, name string) error
+	GetConcern(ctx context.Context, id string) (*models.ConcernDetail, error)
+	RunTests(ctx context.Context, candidates map[string]string) (*models.PolicyTestReport, error)
+}
+
+// PolicyDecisionService defines the interface for the decision log of the
+// policy evaluations.
+type PolicyDecisionService interface {
+	List(ctx context.Context, filter models.PolicyDecisionFilter, cursor models.Cursor) (models.Page[models.PolicyDecision], error)
+}
+
+// UpdateService defines the interface for the self-update of the agent.
+type UpdateService interface {
+	Status() models.UpdateStatus
+}
+
+// HAService defines the interface for the leader election of an agent of a
+// high availability pair.
+type HAService interface {
+	Status() models.HAStatus
+}
+
+// SourcesService defines the interface for the sources of the agent, each
+// collected from its own vCenter.
+type SourcesService interface {
+	List() []models.SourceStatus
+	Add(ctx context.Context, source models.Source) (models.SourceStatus, error)
+	Remove(ctx context.Context, id string) error
+	SetMode(ctx context.Context, mode models.AgentMode) error
+	CollectorStatus(id string) (models.CollectorStatus, error)
+	StartCollector(ctx context.Context, id string, creds *models.Credentials) error
+	StopCollector(id string) error
+	Inventory(ctx context.Context, id string) (*models.Inventory, error)
+}
+
+// JobService defines the interface for the jobs following the long-running
+// operations of the agent.
+type JobService interface {
+	List(ctx context.Context, filter models.JobFilter, cursor models.Cursor) (models.Page[models.Job], error)
+	Get(ctx context.Context, id string) (*models.Job, error)
+	Cancel(ctx context.Context, id string) (*models.Job, error)
+}
+
+// PlanService defines the interface for the migration plans.
+type PlanService interface {
+	Generate(ctx context.Context, req models.PlanRequest) (*models.Plan, error)
+	Get(ctx context.Context, id string) (*models.Plan, error)
+	List(ctx context.Context) ([]models.Plan, error)
+	Delete(ctx context.Context, id string) error
+	Forklift(ctx context.Context, id string, target models.ForkliftTarget) ([]byte, error)
+}
+
+// BenchmarkService defines the interface for the throughput benchmarks.
+type BenchmarkService interface {
+	Start(ctx context.Context, req models.BenchmarkRequest) (string, error)
+	Latest(ctx context.Context) (*models.Benchmark, error)
+}
+
+// InventoryGraphService defines the interface for the GraphQL queries of the
+// inventory.
+type InventoryGraphService interface {
+	Execute(ctx context.Context, req graphql.Request) graphql.Response
+}
+
+// AssessmentService defines the interface for the export and the import of
+// the signed assessment bundles.
+type AssessmentService interface {
+	Export(ctx context.Context, w io.Writer) error
+	Import(ctx context.Context, r io.Reader, fingerprint string) (*models.AssessmentBundle, error)
+	Fingerprint() string
+}
+
+// SupportBundleService defines the interface for the support bundle.
+type SupportBundleService interface {
+	Write(ctx context.Context, w io.Writer) error
+}
+
 type Handler struct {
 	cfg          config.Configuration
+	features     *config.FeatureGate
 	consoleSrv   ConsoleService
 	collectorSrv CollectorService
 	inventorySrv InventoryService
 	inspectorSrv InspectorService
 	vmSrv        VMService
+	clusterSrv   ClusterService
+	datastoreSrv DatastoreService
+	infraSrv     InfrastructureService
+	adminSrv     AdminService
+	apiKeySrv    APIKeyService
+	auditSrv     AuditService
+	loginSrv     ConsoleLoginService
+	credsSrv     CredentialsService
+	eventSrv     EventService
+	supportSrv   SupportBundleService
+	policySrv    PolicyService
+	decisionSrv  PolicyDecisionService
+	updateSrv    UpdateService
+	haSrv        HAService
+	sourcesSrv   SourcesService
+	jobSrv       JobService
+	graphSrv     InventoryGraphService
+	assessSrv    AssessmentService
+	planSrv      PlanService
+	benchSrv     BenchmarkService
 }
 
 func New(
@@ -61,6 +227,7 @@ func New(
 ) *Handler {
 	return &Handler{
 		cfg:          cfg,
+		features:     config.NewFeatureGate(cfg.Features),
 		consoleSrv:   consoleSrv,
 		collectorSrv: collectorSrv,
 		inventorySrv: inventorySrv,
@@ -68,3 +235,214 @@ func New(
 		inspectorSrv: inspectorSrv,
 	}
 }
+
+// WithClusterService sets the service used by the cluster endpoints.
+func (h *Handler) WithClusterService(clusterSrv ClusterService) *Handler {
+	h.clusterSrv = clusterSrv
+	return h
+}
+
+// WithDatastoreService sets the service used by the datastore endpoints.
+func (h *Handler) WithDatastoreService(datastoreSrv DatastoreService) *Handler {
+	h.datastoreSrv = datastoreSrv
+	return h
+}
+
+// WithInfrastructureService sets the service used by the host, datastore and
+// network endpoints.
+func (h *Handler) WithInfrastructureService(infraSrv InfrastructureService) *Handler {
+	h.infraSrv = infraSrv
+	return h
+}
+
+// WithAdminService sets the service used by the admin endpoints.
+func (h *Handler) WithAdminService(adminSrv AdminService) *Handler {
+	h.adminSrv = adminSrv
+	return h
+}
+
+// WithAPIKeyService sets the service used by the API key admin endpoints,
+// which are only registered when it is set.
+func (h *Handler) WithAPIKeyService(apiKeySrv APIKeyService) *Handler {
+	h.apiKeySrv = apiKeySrv
+	return h
+}
+
+// WithAuditService sets the service used by the audit log admin endpoint,
+// which is only registered when it is set.
+func (h *Handler) WithAuditService(auditSrv AuditService) *Handler {
+	h.auditSrv = auditSrv
+	return h
+}
+
+// WithCredentialsService sets the service used by the stored credentials admin
+// endpoints, which are only registered when it is set.
+func (h *Handler) WithCredentialsService(credsSrv CredentialsService) *Handler {
+	h.credsSrv = credsSrv
+	return h
+}
+
+// WithEventService sets the service of the /events endpoint, which answers
+// 404 until it is set.
+func (h *Handler) WithEventService(eventSrv EventService) *Handler {
+	h.eventSrv = eventSrv
+	return h
+}
+
+// WithSupportBundleService sets the service used by the support bundle admin
+// endpoint, which is only registered when it is set.
+func (h *Handler) WithSupportBundleService(supportSrv SupportBundleService) *Handler {
+	h.supportSrv = supportSrv
+	return h
+}
+
+// WithPolicyService sets the service of the /policies and /concerns endpoints,
+// which answer 404 until it is set.
+func (h *Handler) WithPolicyService(policySrv PolicyService) *Handler {
+	h.policySrv = policySrv
+	return h
+}
+
+// WithPolicyDecisionService sets the service of the /policies/decisions
+// endpoint, which answers 404 while unset.
+func (h *Handler) WithPolicyDecisionService(decisionSrv PolicyDecisionService) *Handler {
+	h.decisionSrv = decisionSrv
+	return h
+}
+
+// WithUpdateService sets the service whose update status is reported by the
+// /agent endpoint, which omits it while unset.
+func (h *Handler) WithUpdateService(updateSrv UpdateService) *Handler {
+	h.updateSrv = updateSrv
+	return h
+}
+
+// WithHAService sets the leader election whose role is reported by the /agent
+// endpoint, which omits it while unset.
+func (h *Handler) WithHAService(haSrv HAService) *Handler {
+	h.haSrv = haSrv
+	return h
+}
+
+// WithSourcesService sets the service of the /sources endpoints, which answer
+// 404 until it is set. The added sources follow the mode changes of /agent.
+func (h *Handler) WithSourcesService(sourcesSrv SourcesService) *Handler {
+	h.sourcesSrv = sourcesSrv
+	return h
+}
+
+// WithJobService sets the service of the /jobs endpoints, which answer 404
+// until it is set.
+func (h *Handler) WithJobService(jobSrv JobService) *Handler {
+	h.jobSrv = jobSrv
+	return h
+}
+
+// WithInventoryGraphService sets the service of the /api/graphql endpoint,
+// which answers 404 until it is set.
+func (h *Handler) WithInventoryGraphService(graphSrv InventoryGraphService) *Handler {
+	h.graphSrv = graphSrv
+	return h
+}
+
+// WithAssessmentService sets the service of the /assessment endpoints, which
+// answer 404 until it is set.
+func (h *Handler) WithAssessmentService(assessSrv AssessmentService) *Handler {
+	h.assessSrv = assessSrv
+	return h
+}
+
+// WithPlanService sets the service of the /plans endpoints, which answer 404
+// until it is set.
+func (h *Handler) WithPlanService(planSrv PlanService) *Handler {
+	h.planSrv = planSrv
+	return h
+}
+
+// WithBenchmarkService sets the service of the /benchmark endpoints, which
+// answer 404 until it is set.
+func (h *Handler) WithBenchmarkService(benchSrv BenchmarkService) *Handler {
+	h.benchSrv = benchSrv
+	return h
+}
+
+// WithConsoleLoginService sets the service of the /console/login endpoints,
+// which answer 404 until it is set.
+func (h *Handler) WithConsoleLoginService(loginSrv ConsoleLoginService) *Handler {
+	h.loginSrv = loginSrv
+	return h
+}
+
+// WithFeatureGate sets the gate of the feature endpoints, shared with the
+// configuration reload. It defaults to the features of the configuration.
+func (h *Handler) WithFeatureGate(features *config.FeatureGate) *Handler {
+	h.features = features
+	return h
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageCursor returns the cursor of the page and pageSize query parameters of
+// a list endpoint: the first page when page is not set, of defaultPageSize
+// items when pageSize is not, and of maxPageSize items at most.
+func pageCursor(page, pageSize *int) models.Cursor {
+	number := 1
+	if page != nil && *page > 0 {
+		number = *page
+	}
+	size := defaultPageSize
+	if pageSize != nil && *pageSize > 0 {
+		size = min(*pageSize, maxPageSize)
+	}
+	return models.NewCursor(number, size)
+}
+
+// writeError responds with the APIError of err, see srvErrors.ToAPIError.
+func writeError(c *gin.Context, err error) {
+	apiErr := srvErrors.ToAPIError(err)
+	c.JSON(apiErr.HTTPStatus(), apiErr.Response())
+}
+
+// badRequest responds 400 with an INVALID_REQUEST error of message.
+func badRequest(c *gin.Context, message string) {
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeInvalidRequest, message))
+}
+
+// invalid responds 400 with an INVALID_REQUEST error listing the field errors
+// of v in its details, and reports whether it did.
+func invalid(c *gin.Context, v *validation.Validator) bool {
+	err := v.Err()
+	if err == nil {
+		return false
+	}
+	var fields validation.Errors
+	errors.As(err, &fields)
+	writeFieldErrors(c, fields)
+	return true
+}
+
+// writeFieldErrors responds 400 with an INVALID_REQUEST error listing fields in
+// its details.
+func writeFieldErrors(c *gin.Context, fields validation.Errors) {
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeInvalidRequest, fields.Error()).
+		WithDetails(map[string]any{"fields": fields}))
+}
+
+// ParamErrorHandler is the ErrorHandler of the generated server, answering the
+// requests with invalid parameters with an INVALID_REQUEST error.
+func ParamErrorHandler(c *gin.Context, err error, _ int) {
+	badRequest(c, err.Error())
+}
+
+// featureDisabled responds 404 to the requests of a disabled feature and
+// reports whether it did.
+func (h *Handler) featureDisabled(c *gin.Context, feature string) bool {
+	if h.features.IsEnabled(feature) {
+		return false
+	}
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeFeatureDisabled, fmt.Sprintf("feature %s is disabled", feature)))
+	return true
+}
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"context"
+	"io"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 func TestHandlers(t *testing.T) {
@@ -16,11 +19,17 @@ func TestHandlers(t *testing.T) {
 	RunSpecs(t, "Handlers Suite")
 }
 
+// the store snapshot is built by the first spec opening it
+var _ = AfterSuite(func() {
+	Expect(storetest.Migrated.Remove()).To(Succeed())
+})
+
 // MockCollectorService is a mock implementation of CollectorService.
 type MockCollectorService struct {
 	StatusResult   models.CollectorStatus
 	StartError     error
 	StartCallCount int
+	StartCreds     *models.Credentials
 	StopCallCount  int
 }
 
@@ -30,6 +39,7 @@ func (m *MockCollectorService) GetStatus() models.CollectorStatus {
 
 func (m *MockCollectorService) Start(ctx context.Context, creds *models.Credentials) error {
 	m.StartCallCount++
+	m.StartCreds = creds
 	return m.StartError
 }
 
@@ -72,18 +82,24 @@ type MockVMService struct {
 	ListError      error
 	GetResult      *models.VM
 	GetError       error
+	EventsResult   []models.VCenterEvent
+	EventsError    error
 	LastListParams services.VMListParams
 }
 
-func (m *MockVMService) List(ctx context.Context, params services.VMListParams) ([]models.VMSummary, int, error) {
+func (m *MockVMService) List(ctx context.Context, params services.VMListParams) (models.Page[models.VMSummary], error) {
 	m.LastListParams = params
-	return m.ListResult, m.ListTotal, m.ListError
+	return models.NewPage(m.ListResult, params.Cursor, m.ListTotal), m.ListError
 }
 
 func (m *MockVMService) Get(ctx context.Context, id string) (*models.VM, error) {
 	return m.GetResult, m.GetError
 }
 
+func (m *MockVMService) Events(ctx context.Context, id string) ([]models.VCenterEvent, error) {
+	return m.EventsResult, m.EventsError
+}
+
 // MockInspectorService is a mock implementation of InspectorService.
 type MockInspectorService struct {
 	StartError                   error
@@ -130,3 +146,440 @@ func (m *MockInspectorService) Stop(ctx context.Context) error {
 	m.StopCallCount++
 	return m.StopError
 }
+
+// MockClusterService is a mock implementation of ClusterService.
+type MockClusterService struct {
+	ListRulesResult []models.ClusterRule
+	ListRulesError  error
+	LastCluster     string
+}
+
+func (m *MockClusterService) ListRules(ctx context.Context, cluster string) ([]models.ClusterRule, error) {
+	m.LastCluster = cluster
+	return m.ListRulesResult, m.ListRulesError
+}
+
+// MockPolicyService is a mock implementation of PolicyService.
+type MockPolicyService struct {
+	EvaluateResult *models.PolicyEvaluation
+	EvaluateError  error
+	LastVMID       string
+	LastDocument   map[string]any
+
+	PolicySet      *models.PolicySet
+	PolicySetError error
+
+	CustomPolicies    []models.CustomPolicy
+	CustomPolicyError error
+	LastName          string
+	LastContent       []byte
+
+	ConcernResult *models.ConcernDetail
+	ConcernError  error
+	LastConcernID string
+
+	TestReport     *models.PolicyTestReport
+	TestError      error
+	LastCandidates map[string]string
+}
+
+func (m *MockPolicyService) Evaluate(ctx context.Context, vmID string, document map[string]any) (*models.PolicyEvaluation, error) {
+	m.LastVMID = vmID
+	m.LastDocument = document
+	return m.EvaluateResult, m.EvaluateError
+}
+
+func (m *MockPolicyService) ListPolicies(ctx context.Context) (*models.PolicySet, error) {
+	return m.PolicySet, m.PolicySetError
+}
+
+func (m *MockPolicyService) ListCustomPolicies(ctx context.Context) ([]models.CustomPolicy, error) {
+	return m.CustomPolicies, m.CustomPolicyError
+}
+
+func (m *MockPolicyService) PutCustomPolicy(ctx context.Context, name string, content []byte) error {
+	m.LastName = name
+	m.LastContent = content
+	return m.CustomPolicyError
+}
+
+func (m *MockPolicyService) DeleteCustomPolicy(ctx context.Context, name string) error {
+	m.LastName = name
+	return m.CustomPolicyError
+}
+
+func (m *MockPolicyService) GetConcern(ctx context.Context, id string) (*models.ConcernDetail, error) {
+	m.LastConcernID = id
+	return m.ConcernResult, m.ConcernError
+}
+
+func (m *MockPolicyService) RunTests(ctx context.Context, candidates map[string]string) (*models.PolicyTestReport, error) {
+	m.LastCandidates = candidates
+	return m.TestReport, m.TestError
+}
+
+// MockDatastoreService is a mock implementation of DatastoreService.
+type MockDatastoreService struct {
+	ListStatsResult []models.DatastoreStats
+	ListStatsError  error
+}
+
+func (m *MockDatastoreService) ListStats(ctx context.Context) ([]models.DatastoreStats, error) {
+	return m.ListStatsResult, m.ListStatsError
+}
+
+// MockInfrastructureService is a mock implementation of InfrastructureService.
+type MockInfrastructureService struct {
+	ListHostsResult      []models.Host
+	ListHostsError       error
+	ListDatastoresResult []models.Datastore
+	ListDatastoresError  error
+	ListNetworksResult   []models.Network
+	ListNetworksError    error
+	LastCluster          string
+	LastCursor           models.Cursor
+}
+
+func (m *MockInfrastructureService) ListHosts(ctx context.Context, cluster string, cursor models.Cursor) (models.Page[models.Host], error) {
+	m.LastCluster, m.LastCursor = cluster, cursor
+	return models.Paginate(m.ListHostsResult, cursor), m.ListHostsError
+}
+
+func (m *MockInfrastructureService) ListDatastores(ctx context.Context, cluster string, cursor models.Cursor) (models.Page[models.Datastore], error) {
+	m.LastCluster, m.LastCursor = cluster, cursor
+	return models.Paginate(m.ListDatastoresResult, cursor), m.ListDatastoresError
+}
+
+func (m *MockInfrastructureService) ListNetworks(ctx context.Context, cursor models.Cursor) (models.Page[models.Network], error) {
+	m.LastCursor = cursor
+	return models.Paginate(m.ListNetworksResult, cursor), m.ListNetworksError
+}
+
+// MockAdminService is a mock implementation of AdminService.
+type MockAdminService struct {
+	MigrationsResult []models.Migration
+	MigrationsError  error
+	RuntimeResult    models.RuntimeStats
+}
+
+func (m *MockAdminService) Migrations(ctx context.Context) ([]models.Migration, error) {
+	return m.MigrationsResult, m.MigrationsError
+}
+
+func (m *MockAdminService) Runtime() models.RuntimeStats {
+	return m.RuntimeResult
+}
+
+// MockAPIKeyService is a mock implementation of APIKeyService.
+type MockAPIKeyService struct {
+	CreateResult *models.APIKey
+	CreateSecret string
+	CreateError  error
+	CreatedName  string
+	CreatedRole  models.Role
+	ListResult   []models.APIKey
+	ListError    error
+	RevokeError  error
+	RevokedID    string
+}
+
+func (m *MockAPIKeyService) Create(ctx context.Context, name string, role models.Role) (*models.APIKey, string, error) {
+	m.CreatedName = name
+	m.CreatedRole = role
+	return m.CreateResult, m.CreateSecret, m.CreateError
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context) ([]models.APIKey, error) {
+	return m.ListResult, m.ListError
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, id string) error {
+	m.RevokedID = id
+	return m.RevokeError
+}
+
+// MockAuditService is a mock implementation of AuditService.
+type MockAuditService struct {
+	ListResult []models.AuditEntry
+	ListError  error
+	ListCursor models.Cursor
+}
+
+func (m *MockAuditService) List(ctx context.Context, cursor models.Cursor) (models.Page[models.AuditEntry], error) {
+	m.ListCursor = cursor
+	return models.Paginate(m.ListResult, cursor), m.ListError
+}
+
+// MockCredentialsService is a mock implementation of CredentialsService.
+type MockCredentialsService struct {
+	GetResult   *models.Credentials
+	GetError    error
+	DeleteError error
+	Deleted     bool
+}
+
+func (m *MockCredentialsService) Get(ctx context.Context) (*models.Credentials, error) {
+	return m.GetResult, m.GetError
+}
+
+func (m *MockCredentialsService) Delete(ctx context.Context) error {
+	m.Deleted = true
+	return m.DeleteError
+}
+
+// MockEventService is a mock implementation of EventService.
+type MockEventService struct {
+	ListResult []models.AgentEvent
+	ListError  error
+	ListFilter models.AgentEventFilter
+	ListCursor models.Cursor
+}
+
+func (m *MockEventService) List(ctx context.Context, filter models.AgentEventFilter, cursor models.Cursor) (models.Page[models.AgentEvent], error) {
+	m.ListFilter = filter
+	m.ListCursor = cursor
+	return models.Paginate(m.ListResult, cursor), m.ListError
+}
+
+// MockPolicyDecisionService is a mock implementation of PolicyDecisionService.
+type MockPolicyDecisionService struct {
+	ListResult []models.PolicyDecision
+	ListError  error
+	ListFilter models.PolicyDecisionFilter
+	ListCursor models.Cursor
+}
+
+func (m *MockPolicyDecisionService) List(ctx context.Context, filter models.PolicyDecisionFilter, cursor models.Cursor) (models.Page[models.PolicyDecision], error) {
+	m.ListFilter = filter
+	m.ListCursor = cursor
+	return models.Paginate(m.ListResult, cursor), m.ListError
+}
+
+// MockUpdateService is a mock implementation of UpdateService.
+type MockUpdateService struct {
+	StatusResult models.UpdateStatus
+}
+
+func (m *MockUpdateService) Status() models.UpdateStatus {
+	return m.StatusResult
+}
+
+// MockHAService is a mock implementation of HAService.
+type MockHAService struct {
+	StatusResult models.HAStatus
+}
+
+func (m *MockHAService) Status() models.HAStatus {
+	return m.StatusResult
+}
+
+// MockSupportBundleService is a mock implementation of SupportBundleService.
+type MockSupportBundleService struct {
+	Content    string
+	WriteError error
+}
+
+func (m *MockSupportBundleService) Write(ctx context.Context, w io.Writer) error {
+	if _, err := io.WriteString(w, m.Content); err != nil {
+		return err
+	}
+	return m.WriteError
+}
+
+// MockConsoleLoginService is a mock implementation of ConsoleLoginService.
+type MockConsoleLoginService struct {
+	StartResult  models.DeviceLogin
+	StartError   error
+	StatusResult models.DeviceLogin
+}
+
+func (m *MockConsoleLoginService) Start(ctx context.Context) (models.DeviceLogin, error) {
+	return m.StartResult, m.StartError
+}
+
+func (m *MockConsoleLoginService) Status() models.DeviceLogin {
+	return m.StatusResult
+}
+
+// MockSourcesService is a mock implementation of SourcesService.
+type MockSourcesService struct {
+	ListResult            []models.SourceStatus
+	AddResult             models.SourceStatus
+	AddError              error
+	LastAdded             models.Source
+	RemoveError           error
+	LastRemoved           string
+	SetModeError          error
+	LastModeSet           models.AgentMode
+	CollectorStatusResult models.CollectorStatus
+	CollectorError        error
+	StartCollectorCount   int
+	InventoryResult       *models.Inventory
+	InventoryError        error
+}
+
+func (m *MockSourcesService) List() []models.SourceStatus {
+	return m.ListResult
+}
+
+func (m *MockSourcesService) Add(ctx context.Context, source models.Source) (models.SourceStatus, error) {
+	m.LastAdded = source
+	return m.AddResult, m.AddError
+}
+
+func (m *MockSourcesService) Remove(ctx context.Context, id string) error {
+	m.LastRemoved = id
+	return m.RemoveError
+}
+
+func (m *MockSourcesService) SetMode(ctx context.Context, mode models.AgentMode) error {
+	m.LastModeSet = mode
+	return m.SetModeError
+}
+
+func (m *MockSourcesService) CollectorStatus(id string) (models.CollectorStatus, error) {
+	return m.CollectorStatusResult, m.CollectorError
+}
+
+func (m *MockSourcesService) StartCollector(ctx context.Context, id string, creds *models.Credentials) error {
+	m.StartCollectorCount++
+	return m.CollectorError
+}
+
+func (m *MockSourcesService) StopCollector(id string) error {
+	return m.CollectorError
+}
+
+func (m *MockSourcesService) Inventory(ctx context.Context, id string) (*models.Inventory, error) {
+	return m.InventoryResult, m.InventoryError
+}
+
+// MockJobService is a mock implementation of JobService.
+type MockJobService struct {
+	ListResult  []models.Job
+	ListError   error
+	ListFilter  models.JobFilter
+	GetResult   *models.Job
+	GetError    error
+	CancelError error
+	LastCancel  string
+}
+
+func (m *MockJobService) List(ctx context.Context, filter models.JobFilter, cursor models.Cursor) (models.Page[models.Job], error) {
+	m.ListFilter = filter
+	return models.Paginate(m.ListResult, cursor), m.ListError
+}
+
+func (m *MockJobService) Get(ctx context.Context, id string) (*models.Job, error) {
+	return m.GetResult, m.GetError
+}
+
+func (m *MockJobService) Cancel(ctx context.Context, id string) (*models.Job, error) {
+	m.LastCancel = id
+	if m.CancelError != nil {
+		return nil, m.CancelError
+	}
+	return m.GetResult, nil
+}
+
+// MockInventoryGraphService is a mock implementation of InventoryGraphService.
+type MockInventoryGraphService struct {
+	Result      graphql.Response
+	LastRequest graphql.Request
+}
+
+func (m *MockInventoryGraphService) Execute(ctx context.Context, req graphql.Request) graphql.Response {
+	m.LastRequest = req
+	return m.Result
+}
+
+// MockAssessmentService is a mock implementation of AssessmentService.
+type MockAssessmentService struct {
+	Bundle          []byte
+	ExportError     error
+	Imported        *models.AssessmentBundle
+	ImportError     error
+	LastFingerprint string
+	LastBody        []byte
+}
+
+func (m *MockAssessmentService) Export(ctx context.Context, w io.Writer) error {
+	if m.ExportError != nil {
+		return m.ExportError
+	}
+	_, err := w.Write(m.Bundle)
+	return err
+}
+
+func (m *MockAssessmentService) Import(ctx context.Context, r io.Reader, fingerprint string) (*models.AssessmentBundle, error) {
+	m.LastFingerprint = fingerprint
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.LastBody = body
+	return m.Imported, m.ImportError
+}
+
+func (m *MockAssessmentService) Fingerprint() string {
+	return "3f5c"
+}
+
+// MockPlanService is a mock implementation of PlanService.
+type MockPlanService struct {
+	Plan          *models.Plan
+	GenerateError error
+	GetError      error
+	DeleteError   error
+	LastRequest   models.PlanRequest
+	// Manifests are the CRs of Forklift, returned with ForkliftError
+	Manifests     []byte
+	ForkliftError error
+	LastTarget    models.ForkliftTarget
+}
+
+func (m *MockPlanService) Generate(ctx context.Context, req models.PlanRequest) (*models.Plan, error) {
+	m.LastRequest = req
+	if m.GenerateError != nil {
+		return nil, m.GenerateError
+	}
+	return m.Plan, nil
+}
+
+func (m *MockPlanService) Get(ctx context.Context, id string) (*models.Plan, error) {
+	return m.Plan, m.GetError
+}
+
+func (m *MockPlanService) List(ctx context.Context) ([]models.Plan, error) {
+	if m.Plan == nil {
+		return nil, nil
+	}
+	return []models.Plan{*m.Plan}, nil
+}
+
+func (m *MockPlanService) Delete(ctx context.Context, id string) error {
+	return m.DeleteError
+}
+
+func (m *MockPlanService) Forklift(ctx context.Context, id string, target models.ForkliftTarget) ([]byte, error) {
+	m.LastTarget = target
+	return m.Manifests, m.ForkliftError
+}
+
+// MockBenchmarkService is a mock implementation of BenchmarkService.
+type MockBenchmarkService struct {
+	Benchmark   *models.Benchmark
+	JobID       string
+	StartError  error
+	LatestError error
+	LastRequest models.BenchmarkRequest
+}
+
+func (m *MockBenchmarkService) Start(ctx context.Context, req models.BenchmarkRequest) (string, error) {
+	m.LastRequest = req
+	return m.JobID, m.StartError
+}
+
+func (m *MockBenchmarkService) Latest(ctx context.Context) (*models.Benchmark, error) {
+	return m.Benchmark, m.LatestError
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// GetHosts returns the ESXi hosts of the inventory
+// (GET /hosts)
+func (h *Handler) GetHosts(c *gin.Context, params v1.GetHostsParams) {
+	var cluster string
+	if params.Cluster != nil {
+		cluster = *params.Cluster
+	}
+	page, err := h.infraSrv.ListHosts(c.Request.Context(), cluster, pageCursor(params.Page, params.PageSize))
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("infrastructure_handler").Errorw("failed to list hosts", "cluster", cluster, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewHostListResponse(page))
+}
+
+// GetDatastores returns the datastores of the inventory
+// (GET /datastores)
+func (h *Handler) GetDatastores(c *gin.Context, params v1.GetDatastoresParams) {
+	var cluster string
+	if params.Cluster != nil {
+		cluster = *params.Cluster
+	}
+	page, err := h.infraSrv.ListDatastores(c.Request.Context(), cluster, pageCursor(params.Page, params.PageSize))
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("infrastructure_handler").Errorw("failed to list datastores", "cluster", cluster, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewDatastoreListResponse(page))
+}
+
+// GetNetworks returns the distributed switches and port groups of the inventory
+// (GET /networks)
+func (h *Handler) GetNetworks(c *gin.Context, params v1.GetNetworksParams) {
+	page, err := h.infraSrv.ListNetworks(c.Request.Context(), pageCursor(params.Page, params.PageSize))
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("infrastructure_handler").Errorw("failed to list networks", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewNetworkListResponse(page))
+}
@@ -0,0 +1,235 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Infrastructure Handlers", func() {
+	var (
+		mockInfra *MockInfrastructureService
+		router    *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockInfra = &MockInfrastructureService{}
+		handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithInfrastructureService(mockInfra)
+		router = gin.New()
+		router.GET("/hosts", func(c *gin.Context) {
+			var params v1.GetHostsParams
+			Expect(c.ShouldBindQuery(&params)).To(Succeed())
+			handler.GetHosts(c, params)
+		})
+		router.GET("/datastores", func(c *gin.Context) {
+			var params v1.GetDatastoresParams
+			Expect(c.ShouldBindQuery(&params)).To(Succeed())
+			handler.GetDatastores(c, params)
+		})
+		router.GET("/networks", func(c *gin.Context) {
+			var params v1.GetNetworksParams
+			Expect(c.ShouldBindQuery(&params)).To(Succeed())
+			handler.GetNetworks(c, params)
+		})
+	})
+
+	Context("GetHosts", func() {
+		// Given a host of a cluster
+		// When we get the hosts of the cluster
+		// Then the host should be returned and the cluster passed to the service
+		It("should return the hosts of the cluster", func() {
+			// Arrange
+			mockInfra.ListHostsResult = []models.Host{
+				{ID: "host-1", Cluster: "cluster-a", CPUCores: 16, CPUSockets: 2, MemoryMB: 65536, Vendor: "Dell"},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/hosts?cluster=cluster-a", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockInfra.LastCluster).To(Equal("cluster-a"))
+
+			var response v1.HostListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(1))
+			Expect(response.Hosts).To(HaveLen(1))
+			Expect(response.Hosts[0].Id).To(Equal("host-1"))
+			Expect(response.Hosts[0].Vendor).To(HaveValue(Equal("Dell")))
+			Expect(response.Hosts[0].Model).To(BeNil())
+		})
+
+		// Given no host in the inventory
+		// When we get the hosts
+		// Then an empty page should be returned
+		It("should return an empty page", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/hosts", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockInfra.LastCluster).To(BeEmpty())
+
+			var response v1.HostListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Hosts).NotTo(BeNil())
+			Expect(response.Hosts).To(BeEmpty())
+			Expect(response.Total).To(BeZero())
+			Expect(response.PageCount).To(Equal(1))
+		})
+
+		// Given a cluster missing from the inventory
+		// When we get its hosts
+		// Then 404 should be returned
+		It("should return 404 for an unknown cluster", func() {
+			// Arrange
+			mockInfra.ListHostsError = srvErrors.NewResourceNotFoundError("cluster", "cluster-x")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/hosts?cluster=cluster-x", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+
+			var response srvErrors.ErrorResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Error.Code).To(Equal(srvErrors.CodeNotFound))
+		})
+	})
+
+	Context("GetDatastores", func() {
+		// Given a datastore mounted by two hosts
+		// When we get the datastores
+		// Then the datastore should be returned with its hosts
+		It("should return the datastores", func() {
+			// Arrange
+			mockInfra.ListDatastoresResult = []models.Datastore{
+				{ID: "naa.1", Type: "VMFS", TotalCapacityGB: 100, FreeCapacityGB: 40, HostIDs: []string{"host-1", "host-2"}},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/datastores", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response v1.DatastoreListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Datastores).To(HaveLen(1))
+			Expect(response.Datastores[0].Id).To(Equal("naa.1"))
+			Expect(response.Datastores[0].HostIds).To(Equal([]string{"host-1", "host-2"}))
+			Expect(response.Datastores[0].ProtocolType).To(BeNil())
+		})
+
+		// Given the store fails
+		// When we get the datastores
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockInfra.ListDatastoresError = errors.New("db error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/datastores?cluster=cluster-a", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+			Expect(mockInfra.LastCluster).To(Equal("cluster-a"))
+		})
+	})
+
+	Context("GetNetworks", func() {
+		// Given a distributed switch and one of its port groups
+		// When we get the networks
+		// Then both should be returned
+		It("should return the networks", func() {
+			// Arrange
+			mockInfra.ListNetworksResult = []models.Network{
+				{Name: "dvs-1", Type: models.NetworkTypeDVSwitch},
+				{Name: "pg-100", Type: models.NetworkTypeDistributed, DVSwitch: "dvs-1", VlanID: "100", VMCount: 2},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/networks", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response v1.NetworkListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Networks).To(HaveLen(2))
+			Expect(response.Networks[0].Type).To(Equal(v1.NetworkTypeDvswitch))
+			Expect(response.Networks[1].Dvswitch).To(HaveValue(Equal("dvs-1")))
+			Expect(response.Networks[1].VmCount).To(Equal(2))
+		})
+
+		// Given three networks
+		// When we get the second page of two networks
+		// Then the last network should be returned with the pagination of the listing
+		It("should return the requested page", func() {
+			// Arrange
+			mockInfra.ListNetworksResult = []models.Network{
+				{Name: "dvs-1", Type: models.NetworkTypeDVSwitch},
+				{Name: "pg-100", Type: models.NetworkTypeDistributed},
+				{Name: "pg-200", Type: models.NetworkTypeDistributed},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/networks?page=2&pageSize=2", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockInfra.LastCursor).To(Equal(models.Cursor{Offset: 2, Limit: 2}))
+
+			var response v1.NetworkListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(3))
+			Expect(response.Page).To(Equal(2))
+			Expect(response.PageSize).To(Equal(2))
+			Expect(response.PageCount).To(Equal(2))
+			Expect(response.Networks).To(HaveLen(1))
+			Expect(response.Networks[0].Name).To(Equal("pg-200"))
+		})
+
+		// Given the store fails
+		// When we get the networks
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockInfra.ListNetworksError = errors.New("db error")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/networks", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
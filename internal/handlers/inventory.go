@@ -4,9 +4,9 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
 )
 
 // GetInventory returns the collected inventory
@@ -15,11 +15,11 @@ func (h *Handler) GetInventory(c *gin.Context) {
 	inv, err := h.inventorySrv.GetInventory(c.Request.Context())
 	if err != nil {
 		if srvErrors.IsResourceNotFoundError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		zap.S().Named("collector_handler").Errorw("failed to get inventory", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		logger.FromContext(c.Request.Context()).Named("collector_handler").Errorw("failed to get inventory", "error", err)
+		writeError(c, err)
 		return
 	}
 
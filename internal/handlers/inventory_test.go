@@ -54,7 +54,7 @@ var _ = Describe("Inventory Handlers", func() {
 
 		// Given no inventory has been collected yet
 		// When we request the inventory
-		// Then it should return 404 Not Found
+		// Then it should return 404 Not Found with a NOT_FOUND error of the inventory
 		It("should return 404 when inventory not found", func() {
 			// Arrange
 			mockInventory.InventoryError = srvErrors.NewResourceNotFoundError("inventory", "")
@@ -71,7 +71,10 @@ var _ = Describe("Inventory Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("inventory not found"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("inventory not found")))
+			Expect(response["error"]).To(HaveKeyWithValue("code", "NOT_FOUND"))
+			Expect(response["error"]).To(HaveKeyWithValue("details", HaveKeyWithValue("kind", "inventory")))
+			Expect(response["error"]).To(HaveKeyWithValue("retryable", false))
 		})
 
 		// Given an internal error occurs when fetching inventory
@@ -93,7 +96,8 @@ var _ = Describe("Inventory Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("database error"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("database error")))
+			Expect(response["error"]).To(HaveKeyWithValue("code", "INTERNAL_ERROR"))
 		})
 	})
 })
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+// ListJobs returns the jobs of the agent, newest first
+// (GET /jobs)
+func (h *Handler) ListJobs(c *gin.Context, params v1.ListJobsParams) {
+	if h.jobsUnavailable(c) {
+		return
+	}
+
+	var filter models.JobFilter
+	v := validation.New()
+	if params.Kind != nil {
+		for _, k := range *params.Kind {
+			v.OneOf("kind", k, string(models.JobKindCollection), string(models.JobKindInspection), string(models.JobKindBenchmark),
+				string(models.JobKindSupportBundle), string(models.JobKindAssessmentExport))
+			filter.Kinds = append(filter.Kinds, models.JobKind(k))
+		}
+	}
+	if params.State != nil {
+		for _, s := range *params.State {
+			v.OneOf("state", s, string(models.JobStateRunning), string(models.JobStateCompleted),
+				string(models.JobStateFailed), string(models.JobStateCanceled))
+			filter.States = append(filter.States, models.JobState(s))
+		}
+	}
+	if invalid(c, v) {
+		return
+	}
+
+	page, err := h.jobSrv.List(c.Request.Context(), filter, pageCursor(params.Page, params.PageSize))
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("job_handler").Errorw("failed to list jobs", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewJobListResponse(page))
+}
+
+// GetJob returns a job
+// (GET /jobs/{id})
+func (h *Handler) GetJob(c *gin.Context, id string) {
+	if h.jobsUnavailable(c) {
+		return
+	}
+
+	job, err := h.jobSrv.Get(c.Request.Context(), id)
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("job_handler").Errorw("failed to get job", "job_id", id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewJob(*job))
+}
+
+// CancelJob cancels a running job and returns it
+// (DELETE /jobs/{id})
+func (h *Handler) CancelJob(c *gin.Context, id string) {
+	if h.jobsUnavailable(c) {
+		return
+	}
+
+	job, err := h.jobSrv.Cancel(c.Request.Context(), id)
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) && !srvErrors.IsJobNotRunningError(err) {
+			logger.FromContext(c.Request.Context()).Named("job_handler").Errorw("failed to cancel job", "job_id", id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewJob(*job))
+}
+
+// jobsUnavailable responds 404 while the jobs are not followed and reports
+// whether it did.
+func (h *Handler) jobsUnavailable(c *gin.Context) bool {
+	if h.jobSrv != nil {
+		return false
+	}
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "jobs are not followed"))
+	return true
+}
@@ -0,0 +1,144 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Jobs Handlers", func() {
+	var (
+		mockJobs *MockJobService
+		router   *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockJobs = &MockJobService{}
+		handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithJobService(mockJobs)
+		router = gin.New()
+		router.GET("/jobs", func(c *gin.Context) {
+			var params v1.ListJobsParams
+			if kinds, ok := c.GetQueryArray("kind"); ok {
+				params.Kind = &kinds
+			}
+			if states, ok := c.GetQueryArray("state"); ok {
+				params.State = &states
+			}
+			handler.ListJobs(c, params)
+		})
+		router.GET("/jobs/:id", func(c *gin.Context) { handler.GetJob(c, c.Param("id")) })
+		router.DELETE("/jobs/:id", func(c *gin.Context) { handler.CancelJob(c, c.Param("id")) })
+	})
+
+	Context("ListJobs", func() {
+		// Given a running collection
+		// When we list the running collections
+		// Then its job should be returned with its progress
+		It("should list the jobs matching the filter", func() {
+			// Arrange
+			mockJobs.ListResult = []models.Job{
+				{ID: "job-1", Kind: models.JobKindCollection, State: models.JobStateRunning, Phase: "collecting", Progress: 33, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/jobs?kind=collection&state=running", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockJobs.ListFilter.Kinds).To(Equal([]models.JobKind{models.JobKindCollection}))
+			Expect(mockJobs.ListFilter.States).To(Equal([]models.JobState{models.JobStateRunning}))
+			var response v1.JobListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(1))
+			Expect(response.Jobs[0].Id).To(Equal("job-1"))
+			Expect(response.Jobs[0].Kind).To(Equal(v1.JobKindCollection))
+			Expect(response.Jobs[0].Progress).To(Equal(33))
+			Expect(*response.Jobs[0].Phase).To(Equal("collecting"))
+		})
+
+		// Given an unknown job kind
+		// When we list the jobs of that kind
+		// Then 400 should be returned
+		It("should return 400 for an unknown kind", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/jobs?kind=backup", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("GetJob", func() {
+		// Given no job with that id
+		// When we get it
+		// Then 404 should be returned
+		It("should return 404 for an unknown job", func() {
+			// Arrange
+			mockJobs.GetError = srvErrors.NewResourceNotFoundError("job", "job-1")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("CancelJob", func() {
+		// Given a running job
+		// When we cancel it
+		// Then it should be returned canceled
+		It("should cancel a running job", func() {
+			// Arrange
+			finished := time.Now()
+			mockJobs.GetResult = &models.Job{ID: "job-1", Kind: models.JobKindInspection, State: models.JobStateCanceled, FinishedAt: &finished}
+
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockJobs.LastCancel).To(Equal("job-1"))
+			var response v1.Job
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.State).To(Equal(v1.JobStateCanceled))
+			Expect(response.FinishedAt).NotTo(BeNil())
+		})
+
+		// Given a finished job
+		// When we cancel it
+		// Then 409 should be returned
+		It("should return 409 for a finished job", func() {
+			// Arrange
+			mockJobs.CancelError = srvErrors.NewJobNotRunningError("job-1", "completed")
+
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+	})
+})
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+// ListPlans returns the migration plans, newest first
+// (GET /plans)
+func (h *Handler) ListPlans(c *gin.Context) {
+	if h.plansUnavailable(c) {
+		return
+	}
+
+	plans, err := h.planSrv.List(c.Request.Context())
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("plan_handler").Errorw("failed to list plans", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	resp := v1.PlanList{Plans: make([]v1.Plan, 0, len(plans))}
+	for _, p := range plans {
+		resp.Plans = append(resp.Plans, v1.NewPlan(p))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GeneratePlan generates the migration plan of VMs selected in waves
+// (POST /plans)
+func (h *Handler) GeneratePlan(c *gin.Context) {
+	if h.plansUnavailable(c) {
+		return
+	}
+
+	var req v1.PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request body")
+		return
+	}
+	if invalid(c, planValidator(req)) {
+		return
+	}
+
+	plan, err := h.planSrv.Generate(c.Request.Context(), planRequest(req))
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("plan_handler").Errorw("failed to generate plan", "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, v1.NewPlan(*plan))
+}
+
+// DeletePlan deletes a migration plan
+// (DELETE /plans/{id})
+func (h *Handler) DeletePlan(c *gin.Context, id string) {
+	if h.plansUnavailable(c) {
+		return
+	}
+
+	if err := h.planSrv.Delete(c.Request.Context(), id); err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("plan_handler").Errorw("failed to delete plan", "plan_id", id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetPlan returns a migration plan
+// (GET /plans/{id})
+func (h *Handler) GetPlan(c *gin.Context, id string) {
+	plan, ok := h.plan(c, id)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, v1.NewPlan(*plan))
+}
+
+// ExportPlan returns a migration plan as an indented JSON attachment
+// (GET /plans/{id}/export)
+func (h *Handler) ExportPlan(c *gin.Context, id string) {
+	plan, ok := h.plan(c, id)
+	if !ok {
+		return
+	}
+
+	data, err := json.MarshalIndent(v1.NewPlan(*plan), "", "  ")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "plan-"+plan.ID+".json"))
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// ExportPlanForklift returns the Forklift CRs migrating a plan as a YAML
+// attachment
+// (GET /plans/{id}/forklift)
+func (h *Handler) ExportPlanForklift(c *gin.Context, id string, params v1.ExportPlanForkliftParams) {
+	if h.plansUnavailable(c) {
+		return
+	}
+
+	target, v := forkliftTarget(params)
+	if invalid(c, v) {
+		return
+	}
+
+	data, err := h.planSrv.Forklift(c.Request.Context(), id, target)
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("plan_handler").Errorw("failed to export plan to forklift", "plan_id", id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "forklift-"+id+".yaml"))
+	c.Data(http.StatusOK, "application/yaml", data)
+}
+
+// plan returns the plan id, or responds with the error getting it.
+func (h *Handler) plan(c *gin.Context, id string) (*models.Plan, bool) {
+	if h.plansUnavailable(c) {
+		return nil, false
+	}
+
+	plan, err := h.planSrv.Get(c.Request.Context(), id)
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("plan_handler").Errorw("failed to get plan", "plan_id", id, "error", err)
+		}
+		writeError(c, err)
+		return nil, false
+	}
+	return plan, true
+}
+
+// planValidator checks the waves of req: each with VMs, a VM in a single wave.
+func planValidator(req v1.PlanRequest) *validation.Validator {
+	v := validation.New().NotEmpty("waves", len(req.Waves))
+	if req.ThroughputMBps != nil {
+		v.Check("throughputMBps", *req.ThroughputMBps > 0, "throughputMBps must be positive")
+	}
+
+	seen := make(map[string]bool)
+	for i, w := range req.Waves {
+		field := fmt.Sprintf("waves[%d].vms", i)
+		v.NotEmpty(field, len(w.Vms))
+		for _, id := range w.Vms {
+			v.Check(field, !seen[id], fmt.Sprintf("VM %s is selected more than once", id))
+			seen[id] = true
+		}
+	}
+	return v
+}
+
+// forkliftTarget converts params, the validator checking the required ones
+// and the mappings, <source>=<destination>.
+func forkliftTarget(params v1.ExportPlanForkliftParams) (models.ForkliftTarget, *validation.Validator) {
+	target := models.ForkliftTarget{
+		StorageClasses: make(map[string]string),
+		Networks:       make(map[string]models.ForkliftNetwork),
+	}
+	if params.Namespace != nil {
+		target.Namespace = *params.Namespace
+	}
+	if params.SourceProvider != nil {
+		target.SourceProvider = *params.SourceProvider
+	}
+	if params.DestinationProvider != nil {
+		target.DestinationProvider = *params.DestinationProvider
+	}
+	if params.TargetNamespace != nil {
+		target.TargetNamespace = *params.TargetNamespace
+	}
+	if params.StorageClass != nil {
+		target.StorageClass = *params.StorageClass
+	}
+	if params.Warm != nil {
+		target.Warm = *params.Warm
+	}
+	v := validation.New().
+		Required("sourceProvider", target.SourceProvider).
+		Required("targetNamespace", target.TargetNamespace).
+		Required("storageClass", target.StorageClass)
+
+	var networks, storages []string
+	if params.Network != nil {
+		networks = *params.Network
+	}
+	if params.Storage != nil {
+		storages = *params.Storage
+	}
+	for _, m := range networks {
+		source, dest, ok := strings.Cut(m, "=")
+		namespace, name, multus := strings.Cut(dest, "/")
+		switch {
+		case !ok || source == "":
+			v.Check("network", false, fmt.Sprintf("network %q must be <network ID>=<destination>", m))
+		case dest == string(models.ForkliftNetworkPod) || dest == string(models.ForkliftNetworkIgnored):
+			target.Networks[source] = models.ForkliftNetwork{Type: models.ForkliftNetworkType(dest)}
+		case multus && namespace != "" && name != "":
+			target.Networks[source] = models.ForkliftNetwork{Type: models.ForkliftNetworkMultus, Namespace: namespace, Name: name}
+		default:
+			v.Check("network", false, fmt.Sprintf("network %q must go to pod, ignored or <namespace>/<name> of a network attachment definition", m))
+		}
+	}
+	for _, m := range storages {
+		datastore, class, ok := strings.Cut(m, "=")
+		v.Check("storage", ok && datastore != "" && class != "", fmt.Sprintf("storage %q must be <datastore name>=<storage class>", m))
+		target.StorageClasses[datastore] = class
+	}
+	return target, v
+}
+
+func planRequest(req v1.PlanRequest) models.PlanRequest {
+	r := models.PlanRequest{Waves: make([]models.PlanWaveRequest, 0, len(req.Waves))}
+	if req.Name != nil {
+		r.Name = *req.Name
+	}
+	if req.ThroughputMBps != nil {
+		r.ThroughputMBps = float64(*req.ThroughputMBps)
+	}
+	for _, w := range req.Waves {
+		wave := models.PlanWaveRequest{VMIDs: w.Vms}
+		if w.Name != nil {
+			wave.Name = *w.Name
+		}
+		r.Waves = append(r.Waves, wave)
+	}
+	return r
+}
+
+// plansUnavailable responds 404 while the plans are not served and reports
+// whether it did.
+func (h *Handler) plansUnavailable(c *gin.Context) bool {
+	if h.planSrv != nil {
+		return false
+	}
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "plans are not served"))
+	return true
+}
@@ -0,0 +1,226 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Plans Handlers", func() {
+	var (
+		mockPlans *MockPlanService
+		router    *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockPlans = &MockPlanService{}
+		handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithPlanService(mockPlans)
+		router = gin.New()
+		router.GET("/plans", handler.ListPlans)
+		router.POST("/plans", handler.GeneratePlan)
+		router.GET("/plans/:id", func(c *gin.Context) { handler.GetPlan(c, c.Param("id")) })
+		router.DELETE("/plans/:id", func(c *gin.Context) { handler.DeletePlan(c, c.Param("id")) })
+		router.GET("/plans/:id/export", func(c *gin.Context) { handler.ExportPlan(c, c.Param("id")) })
+		router.GET("/plans/:id/forklift", func(c *gin.Context) {
+			var params v1.ExportPlanForkliftParams
+			if provider, ok := c.GetQuery("sourceProvider"); ok {
+				params.SourceProvider = &provider
+			}
+			if namespace, ok := c.GetQuery("targetNamespace"); ok {
+				params.TargetNamespace = &namespace
+			}
+			if class, ok := c.GetQuery("storageClass"); ok {
+				params.StorageClass = &class
+			}
+			if networks, ok := c.GetQueryArray("network"); ok {
+				params.Network = &networks
+			}
+			if storages, ok := c.GetQueryArray("storage"); ok {
+				params.Storage = &storages
+			}
+			handler.ExportPlanForklift(c, c.Param("id"), params)
+		})
+	})
+
+	Context("GeneratePlan", func() {
+		// Given VMs selected in two waves
+		// When we generate their plan
+		// Then the waves should be passed to the service and the plan returned
+		It("should generate a plan", func() {
+			// Arrange
+			mockPlans.Plan = &models.Plan{ID: "plan-1", Name: "cutover", ThroughputMBps: 250, ThroughputSource: models.PlanThroughputRequest}
+
+			// Act
+			body := `{"name": "cutover", "throughputMBps": 250, "waves": [{"name": "apps", "vms": ["vm-1", "vm-2"]}, {"vms": ["vm-3"]}]}`
+			req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(mockPlans.LastRequest).To(Equal(models.PlanRequest{
+				Name:           "cutover",
+				ThroughputMBps: 250,
+				Waves: []models.PlanWaveRequest{
+					{Name: "apps", VMIDs: []string{"vm-1", "vm-2"}},
+					{VMIDs: []string{"vm-3"}},
+				},
+			}))
+			var response v1.Plan
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Id).To(Equal("plan-1"))
+			Expect(response.ThroughputSource).To(Equal(v1.PlanThroughputSourceRequest))
+		})
+
+		// Given a VM selected in two waves
+		// When we generate the plan
+		// Then 400 should be returned
+		It("should return 400 for a VM selected twice", func() {
+			// Act
+			body := `{"waves": [{"vms": ["vm-1"]}, {"vms": ["vm-1"]}]}`
+			req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("waves[1].vms"))
+		})
+
+		// Given a VM missing from the inventory
+		// When we generate a plan with it
+		// Then 404 should be returned
+		It("should return 404 for an unknown VM", func() {
+			// Arrange
+			mockPlans.GenerateError = srvErrors.NewResourceNotFoundError("VM", "vm-9")
+
+			// Act
+			req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"waves": [{"vms": ["vm-9"]}]}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("ExportPlan", func() {
+		// Given a plan
+		// When we export it
+		// Then it should be returned as a JSON attachment named after it
+		It("should export the plan as a JSON file", func() {
+			// Arrange
+			mockPlans.Plan = &models.Plan{ID: "plan-1", CreatedAt: time.Now().UTC(), EstimatedTransfer: time.Minute}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/plans/plan-1/export", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="plan-plan-1.json"`))
+			var response v1.Plan
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.EstimatedTransferSeconds).To(BeEquivalentTo(60))
+		})
+	})
+
+	Context("ExportPlanForklift", func() {
+		// Given a plan and the mappings of a network and a datastore
+		// When we export it to Forklift
+		// Then the mappings should be passed to the service and the CRs returned as a YAML attachment
+		It("should export the plan as Forklift CRs", func() {
+			// Arrange
+			mockPlans.Manifests = []byte("kind: NetworkMap\n")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/plans/plan-1/forklift?sourceProvider=vcenter&targetNamespace=apps&storageClass=standard"+
+				"&network=network-10%3Dapps%2Fvlan-10&network=network-11%3Dignored&storage=ds2%3Dfast", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/yaml"))
+			Expect(w.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="forklift-plan-1.yaml"`))
+			Expect(w.Body.String()).To(Equal("kind: NetworkMap\n"))
+			Expect(mockPlans.LastTarget).To(Equal(models.ForkliftTarget{
+				SourceProvider:  "vcenter",
+				TargetNamespace: "apps",
+				StorageClass:    "standard",
+				StorageClasses:  map[string]string{"ds2": "fast"},
+				Networks: map[string]models.ForkliftNetwork{
+					"network-10": {Type: models.ForkliftNetworkMultus, Namespace: "apps", Name: "vlan-10"},
+					"network-11": {Type: models.ForkliftNetworkIgnored},
+				},
+			}))
+		})
+
+		// Given an export without storage class and an invalid network mapping
+		// When we export the plan to Forklift
+		// Then 400 should be returned without calling the service
+		It("should return 400 for invalid parameters", func() {
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/plans/plan-1/forklift?sourceProvider=vcenter&targetNamespace=apps&network=network-10%3Dvlan-10", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("storageClass"))
+			Expect(w.Body.String()).To(ContainSubstring("network-10=vlan-10"))
+			Expect(mockPlans.LastTarget).To(Equal(models.ForkliftTarget{}))
+		})
+
+		// Given no plan with that id
+		// When we export it to Forklift
+		// Then 404 should be returned
+		It("should return 404 for an unknown plan", func() {
+			// Arrange
+			mockPlans.ForkliftError = srvErrors.NewResourceNotFoundError("plan", "plan-1")
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/plans/plan-1/forklift?sourceProvider=vcenter&targetNamespace=apps&storageClass=standard", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("DeletePlan", func() {
+		// Given no plan with that id
+		// When we delete it
+		// Then 404 should be returned
+		It("should return 404 for an unknown plan", func() {
+			// Arrange
+			mockPlans.DeleteError = srvErrors.NewResourceNotFoundError("plan", "plan-1")
+
+			// Act
+			req := httptest.NewRequest(http.MethodDelete, "/plans/plan-1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
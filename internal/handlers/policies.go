@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+// maxCustomPolicySize bounds the size of an uploaded custom policy.
+const maxCustomPolicySize = 1 << 20 // 1Mb
+
+// EvaluatePolicies returns the concerns the policies in use raise for a VM of
+// the inventory or a VM document
+// (POST /policies/evaluate)
+func (h *Handler) EvaluatePolicies(c *gin.Context) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	var req v1.PolicyEvaluationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	vmID := ""
+	if req.VmId != nil {
+		vmID = *req.VmId
+	}
+	var document map[string]any
+	if req.Vm != nil {
+		document = *req.Vm
+	}
+	if invalid(c, validation.New().
+		Check("vmId", vmID != "" || document != nil, "one of vmId or vm is required").
+		Check("vmId", vmID == "" || document == nil, "only one of vmId or vm can be set")) {
+		return
+	}
+
+	evaluation, err := h.policySrv.Evaluate(c.Request.Context(), vmID, document)
+	if err != nil {
+		writePolicyError(c, "failed to evaluate policies", err, "vm", vmID)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewPolicyEvaluation(*evaluation))
+}
+
+// RunPolicyTests runs the rego tests of the policies, along the candidate
+// custom policies and tests of the optional request body
+// (POST /policies/test)
+func (h *Handler) RunPolicyTests(c *gin.Context) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	var req v1.PolicyTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		badRequest(c, err.Error())
+		return
+	}
+
+	var candidates map[string]string
+	if req.Policies != nil {
+		candidates = *req.Policies
+	}
+	report, err := h.policySrv.RunTests(c.Request.Context(), candidates)
+	if err != nil {
+		writePolicyError(c, "failed to run policy tests", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewPolicyTestReport(*report))
+}
+
+// ListPolicies returns the policies in use, their sources and the error of
+// the last reload
+// (GET /policies)
+func (h *Handler) ListPolicies(c *gin.Context) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	set, err := h.policySrv.ListPolicies(c.Request.Context())
+	if err != nil {
+		writePolicyError(c, "failed to list policies", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewPolicySet(*set))
+}
+
+// ListCustomPolicies returns the custom policies
+// (GET /policies/custom)
+func (h *Handler) ListCustomPolicies(c *gin.Context) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	policies, err := h.policySrv.ListCustomPolicies(c.Request.Context())
+	if err != nil {
+		writePolicyError(c, "failed to list custom policies", err)
+		return
+	}
+
+	resp := make([]v1.CustomPolicy, 0, len(policies))
+	for _, p := range policies {
+		resp = append(resp, v1.NewCustomPolicy(p))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// PutCustomPolicy uploads a custom policy, the request body being its rego
+// (PUT /policies/custom/{name})
+func (h *Handler) PutCustomPolicy(c *gin.Context, name string) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCustomPolicySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(c, srvErrors.NewAPIError(srvErrors.CodePayloadTooLarge, err.Error()))
+			return
+		}
+		badRequest(c, err.Error())
+		return
+	}
+
+	if err := h.policySrv.PutCustomPolicy(c.Request.Context(), name, content); err != nil {
+		writePolicyError(c, "failed to upload custom policy", err, "name", name)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteCustomPolicy deletes a custom policy
+// (DELETE /policies/custom/{name})
+func (h *Handler) DeleteCustomPolicy(c *gin.Context, name string) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	if err := h.policySrv.DeleteCustomPolicy(c.Request.Context(), name); err != nil {
+		writePolicyError(c, "failed to delete custom policy", err, "name", name)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetConcern returns a concern with the metadata of its policy
+// (GET /concerns/{id})
+func (h *Handler) GetConcern(c *gin.Context, id string) {
+	if h.policySrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policies are not loaded"))
+		return
+	}
+
+	concern, err := h.policySrv.GetConcern(c.Request.Context(), id)
+	if err != nil {
+		writePolicyError(c, "failed to get concern", err, "id", id)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewConcernDetail(*concern))
+}
+
+// GetPolicyDecisions returns the decision log of the policy evaluations,
+// newest first
+// (GET /policies/decisions)
+func (h *Handler) GetPolicyDecisions(c *gin.Context, params v1.GetPolicyDecisionsParams) {
+	if h.decisionSrv == nil {
+		writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "policy decisions are not recorded"))
+		return
+	}
+
+	var filter models.PolicyDecisionFilter
+	if params.VmId != nil {
+		filter.VMID = *params.VmId
+	}
+	if params.Rule != nil {
+		filter.Rule = *params.Rule
+	}
+	if params.Decision != nil {
+		filter.Decision = models.PolicyDecisionType(*params.Decision)
+	}
+	if params.Since != nil {
+		filter.Since = *params.Since
+	}
+
+	v := validation.New().OneOf("decision", string(filter.Decision), string(models.PolicyDecisionFlagged), string(models.PolicyDecisionPassed))
+	if invalid(c, v) {
+		return
+	}
+
+	page, err := h.decisionSrv.List(c.Request.Context(), filter, pageCursor(params.Page, params.PageSize))
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("policy_handler").Errorw("failed to list policy decisions", "error", err)
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v1.NewPolicyDecisionListResponse(page))
+}
+
+// writePolicyError writes the error of the policy service: the field errors
+// with their details, and the others logged unless the resource was not found.
+func writePolicyError(c *gin.Context, msg string, err error, keysAndValues ...any) {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		writeFieldErrors(c, fields)
+		return
+	}
+	if !srvErrors.IsResourceNotFoundError(err) {
+		logger.FromContext(c.Request.Context()).Named("policy_handler").Errorw(msg, append(keysAndValues, "error", err)...)
+	}
+	writeError(c, err)
+}
@@ -0,0 +1,461 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+var _ = Describe("Policies Handlers", func() {
+	var (
+		mockPolicy *MockPolicyService
+		router     *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockPolicy = &MockPolicyService{}
+		handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithPolicyService(mockPolicy)
+		router = gin.New()
+		router.POST("/policies/evaluate", handler.EvaluatePolicies)
+		router.GET("/policies", handler.ListPolicies)
+		router.GET("/policies/custom", handler.ListCustomPolicies)
+		router.PUT("/policies/custom/:name", func(c *gin.Context) { handler.PutCustomPolicy(c, c.Param("name")) })
+		router.DELETE("/policies/custom/:name", func(c *gin.Context) { handler.DeleteCustomPolicy(c, c.Param("name")) })
+		router.GET("/concerns/:id", func(c *gin.Context) { handler.GetConcern(c, c.Param("id")) })
+		router.POST("/policies/test", handler.RunPolicyTests)
+	})
+
+	evaluate := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/policies/evaluate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Context("EvaluatePolicies", func() {
+		// Given a VM of the inventory flagged by a policy
+		// When we evaluate the policies against its ID
+		// Then its concerns should be returned
+		It("should return the concerns of a VM of the inventory", func() {
+			// Arrange
+			mockPolicy.EvaluateResult = &models.PolicyEvaluation{
+				VMID:   "vm-1",
+				VMName: "db-1",
+				Concerns: []models.Concern{
+					{ID: "vmware.cpu.hotadd", Label: "CPU hot add", Category: "Warning", Assessment: "Hot add is not supported"},
+				},
+			}
+
+			// Act
+			w := evaluate(`{"vmId": "vm-1"}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockPolicy.LastVMID).To(Equal("vm-1"))
+			Expect(mockPolicy.LastDocument).To(BeNil())
+
+			var evaluation v1.PolicyEvaluation
+			Expect(json.Unmarshal(w.Body.Bytes(), &evaluation)).To(Succeed())
+			Expect(*evaluation.VmId).To(Equal("vm-1"))
+			Expect(*evaluation.VmName).To(Equal("db-1"))
+			Expect(evaluation.Concerns).To(Equal([]v1.Concern{
+				{Id: "vmware.cpu.hotadd", Label: "CPU hot add", Category: "Warning", Assessment: "Hot add is not supported"},
+			}))
+		})
+
+		// Given a VM document no policy flags
+		// When we evaluate the policies against it
+		// Then an empty list of concerns should be returned
+		It("should evaluate a VM document", func() {
+			// Arrange
+			mockPolicy.EvaluateResult = &models.PolicyEvaluation{VMName: "web-1"}
+
+			// Act
+			w := evaluate(`{"vm": {"name": "web-1", "cpuCount": 2}}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockPolicy.LastVMID).To(BeEmpty())
+			Expect(mockPolicy.LastDocument).To(HaveKeyWithValue("name", "web-1"))
+			Expect(w.Body.String()).To(ContainSubstring(`"concerns":[]`))
+		})
+
+		// Given a request with neither vmId nor vm, then one with both
+		// When we evaluate the policies
+		// Then both should be rejected
+		It("should require exactly one of vmId or vm", func() {
+			// Act & Assert
+			w := evaluate(`{}`)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("one of vmId or vm is required"))
+
+			w = evaluate(`{"vmId": "vm-1", "vm": {"name": "web-1"}}`)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring("only one of vmId or vm can be set"))
+		})
+
+		// Given a VM document the service cannot decode
+		// When we evaluate the policies against it
+		// Then the field error should be returned
+		It("should reject an invalid VM document", func() {
+			// Arrange
+			mockPolicy.EvaluateError = validation.Errors{{Field: "vm", Message: "invalid vm: wrong type"}}
+
+			// Act
+			w := evaluate(`{"vm": {"name": 5}}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring(`"field":"vm"`))
+		})
+
+		// Given an unknown VM
+		// When we evaluate the policies against it
+		// Then 404 should be returned
+		It("should return 404 for an unknown VM", func() {
+			// Arrange
+			mockPolicy.EvaluateError = srvErrors.NewResourceNotFoundError("vm", "vm-9")
+
+			// Act
+			w := evaluate(`{"vmId": "vm-9"}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		// Given a failing evaluation
+		// When we evaluate the policies
+		// Then 500 should be returned
+		It("should return 500 when the evaluation fails", func() {
+			// Arrange
+			mockPolicy.EvaluateError = errors.New("policy evaluation failed")
+
+			// Act
+			w := evaluate(`{"vmId": "vm-1"}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		// Given no policy service
+		// When we evaluate the policies
+		// Then 404 should be returned
+		It("should return 404 without policies", func() {
+			// Arrange
+			handler := handlers.New(config.Configuration{}, nil, nil, nil, nil, nil)
+			router = gin.New()
+			router.POST("/policies/evaluate", handler.EvaluatePolicies)
+
+			// Act
+			w := evaluate(`{"vmId": "vm-1"}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("ListPolicies", func() {
+		// Given policies from the bundle of the console and a custom one
+		// When we list the policies
+		// Then each file should be returned with its source and hash
+		It("should return the policies in use", func() {
+			// Arrange
+			installedAt := time.Now().UTC().Truncate(time.Second)
+			mockPolicy.PolicySet = &models.PolicySet{
+				Fingerprint: "abc",
+				Bundle:      &models.PolicyBundleState{URL: "https://console/bundle", Revision: "rev-1", InstalledAt: installedAt},
+				Files: []models.PolicyFile{
+					{Name: "cpu.rego", Source: models.PolicySourceConsole, Hash: "h1"},
+					{Name: "custom/naming.rego", Source: models.PolicySourceCustom, Hash: "h2"},
+				},
+			}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/policies", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var set v1.PolicySet
+			Expect(json.Unmarshal(w.Body.Bytes(), &set)).To(Succeed())
+			Expect(set.Fingerprint).To(Equal("abc"))
+			Expect(set.Bundle).NotTo(BeNil())
+			Expect(set.Bundle.Revision).To(Equal("rev-1"))
+			Expect(set.LoadError).To(BeNil())
+			Expect(set.Policies).To(Equal([]v1.PolicyFile{
+				{Name: "cpu.rego", Source: v1.PolicyFileSourceConsole, Hash: "h1"},
+				{Name: "custom/naming.rego", Source: v1.PolicyFileSourceCustom, Hash: "h2"},
+			}))
+		})
+
+		// Given a last reload that failed
+		// When we list the policies
+		// Then its error should be returned
+		It("should return the error of the last reload", func() {
+			// Arrange
+			mockPolicy.PolicySet = &models.PolicySet{Fingerprint: "abc", LoadError: "rego_parse_error"}
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/policies", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var set v1.PolicySet
+			Expect(json.Unmarshal(w.Body.Bytes(), &set)).To(Succeed())
+			Expect(*set.LoadError).To(Equal("rego_parse_error"))
+			Expect(set.Policies).To(BeEmpty())
+		})
+	})
+
+	Context("Custom policies", func() {
+		send := func(method, path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "text/plain")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// Given custom policies
+		// When we list them
+		// Then they should be returned
+		It("should list the custom policies", func() {
+			// Arrange
+			modifiedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+			mockPolicy.CustomPolicies = []models.CustomPolicy{{Name: "naming.rego", Size: 120, ModifiedAt: modifiedAt}}
+
+			// Act
+			w := send(http.MethodGet, "/policies/custom", "")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var policies []v1.CustomPolicy
+			Expect(json.Unmarshal(w.Body.Bytes(), &policies)).To(Succeed())
+			Expect(policies).To(Equal([]v1.CustomPolicy{{Name: "naming.rego", Size: 120, ModifiedAt: modifiedAt}}))
+		})
+
+		// Given no custom policy
+		// When we list them
+		// Then an empty list should be returned
+		It("should return an empty list without custom policies", func() {
+			// Act
+			w := send(http.MethodGet, "/policies/custom", "")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("[]"))
+		})
+
+		// Given a rego policy
+		// When we upload it
+		// Then it should be given to the service
+		It("should upload a custom policy", func() {
+			// Act
+			w := send(http.MethodPut, "/policies/custom/naming.rego", "package io.konveyor.forklift.vmware")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(mockPolicy.LastName).To(Equal("naming.rego"))
+			Expect(string(mockPolicy.LastContent)).To(Equal("package io.konveyor.forklift.vmware"))
+		})
+
+		// Given a policy the service rejects
+		// When we upload it
+		// Then the field error should be returned
+		It("should reject an invalid custom policy", func() {
+			// Arrange
+			mockPolicy.CustomPolicyError = validation.Errors{{Field: "policy", Message: "invalid custom policy naming.rego: parse error"}}
+
+			// Act
+			w := send(http.MethodPut, "/policies/custom/naming.rego", "package")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring(`"field":"policy"`))
+		})
+
+		// Given a policy larger than 1MB
+		// When we upload it
+		// Then 413 should be returned
+		It("should reject a custom policy too large", func() {
+			// Act
+			w := send(http.MethodPut, "/policies/custom/naming.rego", strings.Repeat("#", 1<<20+1))
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(mockPolicy.LastName).To(BeEmpty())
+		})
+
+		// Given a custom policy
+		// When we delete it
+		// Then 204 should be returned
+		It("should delete a custom policy", func() {
+			// Act
+			w := send(http.MethodDelete, "/policies/custom/naming.rego", "")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(mockPolicy.LastName).To(Equal("naming.rego"))
+		})
+
+		// Given an unknown custom policy
+		// When we delete it
+		// Then 404 should be returned
+		It("should return 404 for an unknown custom policy", func() {
+			// Arrange
+			mockPolicy.CustomPolicyError = srvErrors.NewResourceNotFoundError("custom policy", "naming.rego")
+
+			// Act
+			w := send(http.MethodDelete, "/policies/custom/naming.rego", "")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("GetConcern", func() {
+		get := func(id string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/concerns/"+id, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// Given a concern with metadata flagging two VMs
+		// When we get it
+		// Then its metadata and VM count should be returned
+		It("should return the concern with its metadata", func() {
+			// Arrange
+			mockPolicy.ConcernResult = &models.ConcernDetail{
+				ConcernMetadata: models.ConcernMetadata{
+					ID:          "vmware.cpu.hotadd",
+					Title:       "CPU hot add",
+					Severity:    "warning",
+					Category:    "Warning",
+					Remediation: "Disable CPU hot add",
+					DocsURL:     "https://docs.example.com/cpu-hot-add",
+				},
+				Label:   "CPU hot add enabled",
+				VMCount: 2,
+			}
+
+			// Act
+			w := get("vmware.cpu.hotadd")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockPolicy.LastConcernID).To(Equal("vmware.cpu.hotadd"))
+			var concern v1.ConcernDetail
+			Expect(json.Unmarshal(w.Body.Bytes(), &concern)).To(Succeed())
+			Expect(concern.Severity).To(Equal("warning"))
+			Expect(concern.VmCount).To(Equal(2))
+			Expect(concern.Title).NotTo(BeNil())
+			Expect(*concern.Title).To(Equal("CPU hot add"))
+			Expect(concern.DocsUrl).NotTo(BeNil())
+			Expect(*concern.DocsUrl).To(Equal("https://docs.example.com/cpu-hot-add"))
+		})
+
+		// Given an unknown concern
+		// When we get it
+		// Then 404 should be returned
+		It("should return 404 for an unknown concern", func() {
+			// Arrange
+			mockPolicy.ConcernError = srvErrors.NewResourceNotFoundError("concern", "unknown")
+
+			// Act
+			w := get("unknown")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("RunPolicyTests", func() {
+		runTests := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/policies/test", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// Given policies with a passing and a failing test
+		// When we run the tests without a body
+		// Then the report should be returned
+		It("should run the policy tests", func() {
+			// Arrange
+			mockPolicy.TestReport = &models.PolicyTestReport{
+				Passed: 1,
+				Failed: 1,
+				Results: []models.PolicyTestResult{
+					{File: "cpu_test.rego", Name: "test_hotadd", Status: models.PolicyTestPassed, Duration: 2 * time.Millisecond},
+					{File: "cpu_test.rego", Name: "test_no_hotadd", Status: models.PolicyTestFailed},
+				},
+			}
+
+			// Act
+			w := runTests("")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockPolicy.LastCandidates).To(BeNil())
+			var report v1.PolicyTestReport
+			Expect(json.Unmarshal(w.Body.Bytes(), &report)).To(Succeed())
+			Expect(report.Passed).To(Equal(1))
+			Expect(report.Failed).To(Equal(1))
+			Expect(report.Results).To(HaveLen(2))
+			Expect(report.Results[0].Status).To(Equal(v1.PolicyTestResultStatusPass))
+			Expect(report.Results[0].DurationMs).To(Equal(int64(2)))
+			Expect(report.Results[1].Status).To(Equal(v1.PolicyTestResultStatusFail))
+		})
+
+		// Given candidate policies
+		// When we run the tests with them
+		// Then they should be given to the service
+		It("should pass the candidate policies", func() {
+			// Arrange
+			mockPolicy.TestReport = &models.PolicyTestReport{}
+
+			// Act
+			w := runTests(`{"policies": {"naming_test.rego": "package io.konveyor.forklift.vmware"}}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockPolicy.LastCandidates).To(HaveKeyWithValue("naming_test.rego", "package io.konveyor.forklift.vmware"))
+		})
+
+		// Given tests that do not compile
+		// When we run them
+		// Then the field error should be returned
+		It("should reject tests that do not compile", func() {
+			// Arrange
+			mockPolicy.TestError = validation.Errors{{Field: "policies", Message: "invalid policy tests: parse error"}}
+
+			// Act
+			w := runTests(`{"policies": {"naming_test.rego": "package"}}`)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring(`"field":"policies"`))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+var _ = Describe("Policy Decisions Handlers", func() {
+	var (
+		mockDecisions *MockPolicyDecisionService
+		handler       *handlers.Handler
+		router        *gin.Engine
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockDecisions = &MockPolicyDecisionService{}
+		handler = handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithPolicyDecisionService(mockDecisions)
+		router = gin.New()
+		router.GET("/policies/decisions", func(c *gin.Context) {
+			var params v1.GetPolicyDecisionsParams
+			if err := c.ShouldBindQuery(&params); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			handler.GetPolicyDecisions(c, params)
+		})
+	})
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	Context("GetPolicyDecisions", func() {
+		// Given recorded decisions
+		// When we get the decisions
+		// Then they should be returned with their duration in microseconds
+		It("should return the decisions", func() {
+			// Arrange
+			at := time.Now().UTC().Truncate(time.Second)
+			mockDecisions.ListResult = []models.PolicyDecision{
+				{Time: at, VMID: "vm-1", VMName: "db-1", Rule: "test.cpu", Decision: models.PolicyDecisionFlagged, Duration: 1500 * time.Microsecond},
+				{Time: at.Add(-time.Minute), VMID: "vm-2", Decision: models.PolicyDecisionPassed, Duration: time.Millisecond},
+			}
+
+			// Act
+			w := get("/policies/decisions")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockDecisions.ListCursor).To(Equal(models.Cursor{Offset: 0, Limit: 20}))
+
+			var response v1.PolicyDecisionListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(2))
+			decisions := response.Decisions
+			Expect(decisions).To(HaveLen(2))
+			Expect(decisions[0].Decision).To(Equal(v1.PolicyDecisionDecisionFlagged))
+			Expect(*decisions[0].Rule).To(Equal("test.cpu"))
+			Expect(*decisions[0].VmName).To(Equal("db-1"))
+			Expect(decisions[0].DurationUs).To(Equal(int64(1500)))
+			Expect(decisions[0].CreatedAt.Equal(at)).To(BeTrue())
+			Expect(decisions[1].Rule).To(BeNil())
+			Expect(decisions[1].VmName).To(BeNil())
+		})
+
+		// Given filter and pagination query parameters
+		// When we get the decisions
+		// Then they should be passed to the service as a filter and a cursor
+		It("should filter the decisions", func() {
+			// Act
+			w := get("/policies/decisions?vmId=vm-1&rule=test.cpu&decision=flagged&since=2026-01-02T15:04:05Z&page=2&pageSize=10")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(mockDecisions.ListFilter.VMID).To(Equal("vm-1"))
+			Expect(mockDecisions.ListFilter.Rule).To(Equal("test.cpu"))
+			Expect(mockDecisions.ListFilter.Decision).To(Equal(models.PolicyDecisionFlagged))
+			Expect(mockDecisions.ListFilter.Since.Equal(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC))).To(BeTrue())
+			Expect(mockDecisions.ListCursor).To(Equal(models.Cursor{Offset: 10, Limit: 10}))
+		})
+
+		// Given an unknown decision type
+		// When we get the decisions
+		// Then 400 should be returned with the field error
+		It("should reject an unknown decision type", func() {
+			// Act
+			w := get("/policies/decisions?decision=maybe")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(ContainSubstring(`"field":"decision"`))
+		})
+
+		// Given the store fails
+		// When we get the decisions
+		// Then 500 should be returned
+		It("should return 500 for service errors", func() {
+			// Arrange
+			mockDecisions.ListError = errors.New("db error")
+
+			// Act
+			w := get("/policies/decisions")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		// Given a handler without policy decision service
+		// When we get the decisions
+		// Then 404 should be returned
+		It("should return 404 when the decisions are not recorded", func() {
+			// Arrange
+			handler = handlers.New(config.Configuration{}, nil, nil, nil, nil, nil)
+
+			// Act
+			w := get("/policies/decisions")
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
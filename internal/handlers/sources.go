@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+// ListSources returns the sources of the agent, the primary one first
+// (GET /sources)
+func (h *Handler) ListSources(c *gin.Context) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	statuses := h.sourcesSrv.List()
+	resp := v1.SourceList{Sources: make([]v1.Source, 0, len(statuses))}
+	for _, s := range statuses {
+		resp.Sources = append(resp.Sources, v1.NewSource(s))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddSource adds a source, collected from its own vCenter
+// (POST /sources)
+func (h *Handler) AddSource(c *gin.Context) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	var req v1.SourceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request body")
+		return
+	}
+	if invalid(c, validation.New().Required("id", req.Id).UUID("id", req.Id)) {
+		return
+	}
+
+	source := models.Source{ID: req.Id}
+	if req.Name != nil {
+		source.Name = *req.Name
+	}
+	status, err := h.sourcesSrv.Add(c.Request.Context(), source)
+	if err != nil {
+		if !srvErrors.IsSourceConflictError(err) {
+			logger.FromContext(c.Request.Context()).Named("sources_handler").Errorw("failed to add source", "source_id", req.Id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, v1.NewSource(status))
+}
+
+// RemoveSource removes a source with its inventory
+// (DELETE /sources/{id})
+func (h *Handler) RemoveSource(c *gin.Context, id string) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	if err := h.sourcesSrv.Remove(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetSourceCollectorStatus returns the collector status of a source
+// (GET /sources/{id}/collector)
+func (h *Handler) GetSourceCollectorStatus(c *gin.Context, id string) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	status, err := h.sourcesSrv.CollectorStatus(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v1.NewCollectorStatus(status))
+}
+
+// StartSourceCollector starts the inventory collection of a source
+// (POST /sources/{id}/collector)
+func (h *Handler) StartSourceCollector(c *gin.Context, id string) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	var req v1.CollectorStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request body")
+		return
+	}
+	if invalid(c, collectorValidator(req)) {
+		return
+	}
+
+	creds := collectorCredentials(req)
+	if err := h.sourcesSrv.StartCollector(c.Request.Context(), id, creds); err != nil {
+		if !srvErrors.IsCollectionInProgressError(err) && !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("sources_handler").Errorw("failed to start collector", "source_id", id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	status, err := h.sourcesSrv.CollectorStatus(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, v1.NewCollectorStatus(status))
+}
+
+// StopSourceCollector stops the collection of a source
+// (DELETE /sources/{id}/collector)
+func (h *Handler) StopSourceCollector(c *gin.Context, id string) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	if err := h.sourcesSrv.StopCollector(id); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	status, err := h.sourcesSrv.CollectorStatus(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, v1.NewCollectorStatus(status))
+}
+
+// GetSourceInventory returns the collected inventory of a source
+// (GET /sources/{id}/inventory)
+func (h *Handler) GetSourceInventory(c *gin.Context, id string) {
+	if h.sourcesUnavailable(c) {
+		return
+	}
+
+	inv, err := h.sourcesSrv.Inventory(c.Request.Context(), id)
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			logger.FromContext(c.Request.Context()).Named("sources_handler").Errorw("failed to get inventory", "source_id", id, "error", err)
+		}
+		writeError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", inv.Data)
+}
+
+// sourcesUnavailable responds 404 while the agent does not manage several
+// sources and reports whether it did.
+func (h *Handler) sourcesUnavailable(c *gin.Context) bool {
+	if h.sourcesSrv != nil {
+		return false
+	}
+	writeError(c, srvErrors.NewAPIError(srvErrors.CodeNotFound, "sources are not managed"))
+	return true
+}
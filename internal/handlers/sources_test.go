@@ -0,0 +1,209 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("Sources Handlers", func() {
+	const sourceID = "0d1c2b3a-4f5e-4a6b-8c7d-9e0f1a2b3c4d"
+
+	var (
+		mockSources *MockSourcesService
+		router      *gin.Engine
+	)
+
+	route := func(handler *handlers.Handler) {
+		router = gin.New()
+		router.GET("/sources", handler.ListSources)
+		router.POST("/sources", handler.AddSource)
+		router.DELETE("/sources/:id", func(c *gin.Context) { handler.RemoveSource(c, c.Param("id")) })
+		router.POST("/sources/:id/collector", func(c *gin.Context) { handler.StartSourceCollector(c, c.Param("id")) })
+		router.GET("/sources/:id/inventory", func(c *gin.Context) { handler.GetSourceInventory(c, c.Param("id")) })
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockSources = &MockSourcesService{}
+		route(handlers.New(config.Configuration{}, nil, nil, nil, nil, nil).WithSourcesService(mockSources))
+	})
+
+	// Given an agent not managing several sources
+	// When we list the sources
+	// Then 404 should be returned
+	It("should return 404 without the sources service", func() {
+		// Arrange
+		route(handlers.New(config.Configuration{}, nil, nil, nil, nil, nil))
+
+		// Act
+		req := httptest.NewRequest(http.MethodGet, "/sources", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	// Given a primary and an added source
+	// When we list the sources
+	// Then both should be returned with their status
+	It("should list the sources", func() {
+		// Arrange
+		mockSources.ListResult = []models.SourceStatus{
+			{Source: models.Source{ID: "primary-id", Name: "primary"}, Primary: true, Console: models.ConsoleStatus{Current: models.ConsoleStatusConnected}},
+			{Source: models.Source{ID: sourceID, Name: "vcenter-2"}, Collector: models.CollectorStatus{State: models.CollectorStateCollected}},
+		}
+
+		// Act
+		req := httptest.NewRequest(http.MethodGet, "/sources", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var response v1.SourceList
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Sources).To(HaveLen(2))
+		Expect(response.Sources[0].Primary).To(BeTrue())
+		Expect(response.Sources[0].ConsoleConnection).To(Equal(v1.SourceConsoleConnectionConnected))
+		Expect(response.Sources[1].Id).To(Equal(sourceID))
+		Expect(response.Sources[1].Collector.Status).To(Equal(v1.CollectorStatusStatusCollected))
+	})
+
+	// Given a new source
+	// When we add it
+	// Then it should be created with its name
+	It("should add a source", func() {
+		// Arrange
+		name := "vcenter-2"
+		mockSources.AddResult = models.SourceStatus{Source: models.Source{ID: sourceID, Name: name}}
+		body, _ := json.Marshal(v1.SourceCreateRequest{Id: sourceID, Name: &name})
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/sources", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		Expect(mockSources.LastAdded).To(Equal(models.Source{ID: sourceID, Name: name}))
+	})
+
+	// Given a source id which is not a UUID
+	// When we add the source
+	// Then 400 should be returned
+	It("should reject an invalid source id", func() {
+		// Arrange
+		body, _ := json.Marshal(v1.SourceCreateRequest{Id: "vcenter-2"})
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/sources", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(mockSources.LastAdded.ID).To(BeEmpty())
+	})
+
+	// Given a source already managed by the agent
+	// When we add it again
+	// Then 409 should be returned
+	It("should return 409 for a managed source", func() {
+		// Arrange
+		mockSources.AddError = srvErrors.NewSourceConflictError(sourceID, "already managed by the agent")
+		body, _ := json.Marshal(v1.SourceCreateRequest{Id: sourceID})
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/sources", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusConflict))
+	})
+
+	// Given an added source
+	// When we remove it
+	// Then 204 should be returned
+	It("should remove a source", func() {
+		// Act
+		req := httptest.NewRequest(http.MethodDelete, "/sources/"+sourceID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(mockSources.LastRemoved).To(Equal(sourceID))
+	})
+
+	// Given an unknown source
+	// When we remove it
+	// Then 404 should be returned
+	It("should return 404 for an unknown source", func() {
+		// Arrange
+		mockSources.RemoveError = srvErrors.NewResourceNotFoundError("source", sourceID)
+
+		// Act
+		req := httptest.NewRequest(http.MethodDelete, "/sources/"+sourceID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	// Given valid vCenter credentials
+	// When we start the collector of a source
+	// Then 202 should be returned with its status
+	It("should start the collector of a source", func() {
+		// Arrange
+		mockSources.CollectorStatusResult = models.CollectorStatus{State: models.CollectorStateConnecting}
+		body, _ := json.Marshal(v1.CollectorStartRequest{Url: "https://vcenter-2.example.com/sdk", Username: "admin", Password: "secret"})
+
+		// Act
+		req := httptest.NewRequest(http.MethodPost, "/sources/"+sourceID+"/collector", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(mockSources.StartCollectorCount).To(Equal(1))
+		var response v1.CollectorStatus
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Status).To(Equal(v1.CollectorStatusStatusConnecting))
+	})
+
+	// Given a source with a collected inventory
+	// When we get its inventory
+	// Then the inventory should be returned as stored
+	It("should return the inventory of a source", func() {
+		// Arrange
+		mockSources.InventoryResult = &models.Inventory{Data: []byte(`{"vcenter_id":"vcenter-2"}`)}
+
+		// Act
+		req := httptest.NewRequest(http.MethodGet, "/sources/"+sourceID+"/inventory", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal(`{"vcenter_id":"vcenter-2"}`))
+	})
+})
@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 
 	"github.com/gin-gonic/gin"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 )
 
 const (
@@ -23,7 +25,7 @@ func (h *Handler) PostVddk(c *gin.Context) {
 
 	dst, err := os.Create(filepath.Join(h.cfg.Agent.DataFolder, vddkFilename))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 	defer dst.Close()
@@ -33,10 +35,10 @@ func (h *Handler) PostVddk(c *gin.Context) {
 	if err != nil {
 		var maxBytesErr *http.MaxBytesError
 		if errors.As(err, &maxBytesErr) {
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			writeError(c, srvErrors.NewAPIError(srvErrors.CodePayloadTooLarge, err.Error()))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 
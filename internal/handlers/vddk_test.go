@@ -119,7 +119,7 @@ var _ = Describe("PostVddk", func() {
 		var response map[string]any
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(response["error"]).To(ContainSubstring("no such file or directory"))
+		Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("no such file or directory")))
 	})
 
 	// Given an empty request body
@@ -6,60 +6,28 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 
 	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
 )
 
-var validSortFields = map[string]bool{
-	"name":         true,
-	"vCenterState": true,
-	"cluster":      true,
-	"diskSize":     true,
-	"memory":       true,
-	"issues":       true,
-}
-
-const (
-	defaultPageSize = 20
-	maxPageSize     = 100
-)
+var validSortFields = []string{"name", "vCenterState", "cluster", "diskSize", "memory", "issues"}
 
 // GetVMs returns the list of VMs with filtering and pagination
 // (GET /vms)
 func (h *Handler) GetVMs(c *gin.Context, params v1.GetVMsParams) {
-	// Validate disk size range
-	if params.DiskSizeMin != nil && params.DiskSizeMax != nil && *params.DiskSizeMin > *params.DiskSizeMax {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "diskSizeMin cannot be greater than diskSizeMax"})
-		return
-	}
-
-	// Validate memory size range
-	if params.MemorySizeMin != nil && params.MemorySizeMax != nil && *params.MemorySizeMin > *params.MemorySizeMax {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "memorySizeMin cannot be greater than memorySizeMax"})
-		return
-	}
-
-	// Parse pagination
-	page := 1
-	if params.Page != nil && *params.Page > 0 {
-		page = *params.Page
-	}
-	pageSize := defaultPageSize
-	if params.PageSize != nil && *params.PageSize > 0 {
-		pageSize = *params.PageSize
-		if pageSize > maxPageSize {
-			pageSize = maxPageSize
-		}
-	}
+	v := validation.New()
+	validation.Range(v, "diskSizeMin", "diskSizeMax", params.DiskSizeMin, params.DiskSizeMax)
+	validation.Range(v, "memorySizeMin", "memorySizeMax", params.MemorySizeMin, params.MemorySizeMax)
 
 	// Build service params
 	svcParams := services.VMListParams{
-		Limit:  uint64(pageSize),
-		Offset: uint64((page - 1) * pageSize),
+		Cursor: pageCursor(params.Page, params.PageSize),
 	}
 
 	if params.Clusters != nil {
@@ -83,53 +51,41 @@ func (h *Handler) GetVMs(c *gin.Context, params v1.GetVMsParams) {
 	if params.MemorySizeMax != nil {
 		svcParams.MemorySizeMax = params.MemorySizeMax
 	}
+	if params.Encrypted != nil {
+		svcParams.Encrypted = params.Encrypted
+	}
+	if params.Severity != nil {
+		for _, severity := range *params.Severity {
+			svcParams.Severities = append(svcParams.Severities, strings.ToLower(severity))
+		}
+	}
 
 	// Parse and validate sort params
 	if params.Sort != nil {
 		for _, s := range *params.Sort {
-			parts := strings.SplitN(s, ":", 2)
-			if len(parts) != 2 {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort format, expected 'field:direction' (e.g., 'name:asc')"})
-				return
-			}
-			field, direction := parts[0], parts[1]
-			if !validSortFields[field] {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort field: " + field})
-				return
-			}
-			if direction != "asc" && direction != "desc" {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort direction: " + direction + ", must be 'asc' or 'desc'"})
-				return
+			field, direction, ok := strings.Cut(s, ":")
+			v.Check("sort", ok, "invalid sort format, expected 'field:direction' (e.g., 'name:asc')")
+			if !ok {
+				continue
 			}
+			v.OneOf("sort field", field, validSortFields...).
+				OneOf("sort direction", direction, "asc", "desc")
 			svcParams.Sort = append(svcParams.Sort, services.SortField{Field: field, Desc: direction == "desc"})
 		}
 	}
 
-	vms, total, err := h.vmSrv.List(c.Request.Context(), svcParams)
-	if err != nil {
-		zap.S().Named("vm_handler").Errorw("failed to list VMs", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list VMs: %v", err)})
+	if invalid(c, v) {
 		return
 	}
 
-	// Calculate page count
-	pageCount := (total + pageSize - 1) / pageSize
-	if pageCount == 0 {
-		pageCount = 1
-	}
-
-	// Map to API response
-	apiVMs := make([]v1.VM, 0, len(vms))
-	for _, vm := range vms {
-		apiVMs = append(apiVMs, v1.NewVMFromSummary(vm))
+	page, err := h.vmSrv.List(c.Request.Context(), svcParams)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Named("vm_handler").Errorw("failed to list VMs", "error", err)
+		writeError(c, fmt.Errorf("failed to list VMs: %w", err))
+		return
 	}
 
-	c.JSON(http.StatusOK, v1.VMListResponse{
-		Page:      page,
-		PageCount: pageCount,
-		Total:     total,
-		Vms:       apiVMs,
-	})
+	c.JSON(http.StatusOK, v1.NewVMListResponse(page))
 }
 
 // GetVM returns details for a specific VM
@@ -138,27 +94,52 @@ func (h *Handler) GetVM(c *gin.Context, id string) {
 	vm, err := h.vmSrv.Get(c.Request.Context(), id)
 	if err != nil {
 		if srvErrors.IsResourceNotFoundError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		zap.S().Named("vm_handler").Errorw("failed to get VM", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		logger.FromContext(c.Request.Context()).Named("vm_handler").Errorw("failed to get VM", "id", id, "error", err)
+		writeError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, v1.NewVMDetailsFromModel(*vm))
 }
 
+// GetVMEvents returns the recent vCenter events and triggered alarms of a VM and its host
+// (GET /vms/{id}/events)
+func (h *Handler) GetVMEvents(c *gin.Context, id string) {
+	events, err := h.vmSrv.Events(c.Request.Context(), id)
+	if err != nil {
+		if srvErrors.IsResourceNotFoundError(err) {
+			writeError(c, err)
+			return
+		}
+		logger.FromContext(c.Request.Context()).Named("vm_handler").Errorw("failed to get VM events", "id", id, "error", err)
+		writeError(c, err)
+		return
+	}
+
+	apiEvents := make([]v1.VCenterEvent, 0, len(events))
+	for _, e := range events {
+		apiEvents = append(apiEvents, v1.NewVCenterEvent(e))
+	}
+
+	c.JSON(http.StatusOK, apiEvents)
+}
+
 // GetVMInspectionStatus returns the inspection status for a specific VM
 // (GET /vms/{id}/inspector)
 func (h *Handler) GetVMInspectionStatus(c *gin.Context, id string) {
+	if h.featureDisabled(c, config.FeatureInspector) {
+		return
+	}
 	s, err := h.inspectorSrv.GetVmStatus(c.Request.Context(), id)
 	if err != nil {
 		if srvErrors.IsResourceNotFoundError(err) {
 			c.JSON(http.StatusNotFound, v1.VmInspectionStatus{State: v1.VmInspectionStatusStateNotFound})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get VM status: %v", err)})
+		writeError(c, fmt.Errorf("failed to get VM status: %w", err))
 		return
 	}
 
@@ -168,12 +149,15 @@ func (h *Handler) GetVMInspectionStatus(c *gin.Context, id string) {
 // RemoveVMFromInspection removes VM from inspection queue
 // (DELETE /vms/{id}/inspector)
 func (h *Handler) RemoveVMFromInspection(c *gin.Context, id string) {
+	if h.featureDisabled(c, config.FeatureInspector) {
+		return
+	}
 	if err := h.inspectorSrv.CancelVmsInspection(c.Request.Context(), id); err != nil {
 		if srvErrors.IsInspectorNotRunningError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 
@@ -183,7 +167,7 @@ func (h *Handler) RemoveVMFromInspection(c *gin.Context, id string) {
 			c.JSON(http.StatusNotFound, v1.VmInspectionStatus{State: v1.VmInspectionStatusStateNotFound})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get VM status: %v", err)})
+		writeError(c, fmt.Errorf("failed to get VM status: %w", err))
 		return
 	}
 
@@ -193,26 +177,26 @@ func (h *Handler) RemoveVMFromInspection(c *gin.Context, id string) {
 // GetInspectorStatus returns the inspector status
 // (GET /vms/inspector)
 func (h *Handler) GetInspectorStatus(c *gin.Context) {
+	if h.featureDisabled(c, config.FeatureInspector) {
+		return
+	}
 	c.JSON(http.StatusOK, v1.NewInspectorStatus(h.inspectorSrv.GetStatus()))
 }
 
 // StartInspection starts inspection for VMs
 // (POST /vms/inspector)
 func (h *Handler) StartInspection(c *gin.Context) {
-	var req v1.InspectorStartRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if h.featureDisabled(c, config.FeatureInspector) {
 		return
 	}
-
-	// Todo: validate using the openapi spec. do the same for the collector
-	if req.VcenterCredentials.Url == "" || req.VcenterCredentials.Username == "" || req.VcenterCredentials.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "url, username, and password are required"})
+	var req v1.InspectorStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request body")
 		return
 	}
 
-	if len(req.VmIds) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no vms provided"})
+	creds := req.VcenterCredentials
+	if invalid(c, credentialsValidator(creds.Url, creds.Username, creds.Password).NotEmpty("vmIds", len(req.VmIds))) {
 		return
 	}
 
@@ -223,7 +207,7 @@ func (h *Handler) StartInspection(c *gin.Context) {
 	}
 
 	if err := h.inspectorSrv.Start(c.Request.Context(), req.VmIds, cred); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to start inspector: %v", err)})
+		writeError(c, fmt.Errorf("failed to start inspector: %w", err))
 		return
 	}
 
@@ -233,23 +217,25 @@ func (h *Handler) StartInspection(c *gin.Context) {
 // AddVMsToInspection adds more VMs to inspection queue
 // (PATCH /vms/inspector)
 func (h *Handler) AddVMsToInspection(c *gin.Context) {
+	if h.featureDisabled(c, config.FeatureInspector) {
+		return
+	}
 	var vmsMoid v1.VMIdArray
 	if err := c.ShouldBindJSON(&vmsMoid); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		badRequest(c, err.Error())
 		return
 	}
 
-	if len(vmsMoid) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no vms provided"})
+	if invalid(c, validation.New().NotEmpty("vmIds", len(vmsMoid))) {
 		return
 	}
 
 	if err := h.inspectorSrv.Add(c.Request.Context(), vmsMoid); err != nil {
 		if srvErrors.IsInspectorNotRunningError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		badRequest(c, err.Error())
 		return
 	}
 
@@ -260,16 +246,19 @@ func (h *Handler) AddVMsToInspection(c *gin.Context) {
 // StopInspection stops inspector entirely
 // (DELETE /vms/inspector)
 func (h *Handler) StopInspection(c *gin.Context) {
+	if h.featureDisabled(c, config.FeatureInspector) {
+		return
+	}
 	if err := h.inspectorSrv.Stop(c.Request.Context()); err != nil {
 		if srvErrors.IsInspectorNotRunningError(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
 		if srvErrors.IsInvalidStateError(err) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			badRequest(c, err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, err)
 		return
 	}
 
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
@@ -21,6 +22,7 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 var _ = Describe("VMs Handlers", func() {
@@ -35,7 +37,8 @@ var _ = Describe("VMs Handlers", func() {
 		gin.SetMode(gin.TestMode)
 		mockVM = &MockVMService{}
 		mockInspector = &MockInspectorService{}
-		handler = handlers.New(config.Configuration{}, nil, nil, nil, mockVM, mockInspector)
+		cfg := config.Configuration{Features: map[string]bool{config.FeatureInspector: true}}
+		handler = handlers.New(cfg, nil, nil, nil, mockVM, mockInspector)
 		router = gin.New()
 		router.GET("/vms", func(c *gin.Context) {
 			var params v1.GetVMsParams
@@ -48,6 +51,9 @@ var _ = Describe("VMs Handlers", func() {
 		router.GET("/vms/:id", func(c *gin.Context) {
 			handler.GetVM(c, c.Param("id"))
 		})
+		router.GET("/vms/:id/events", func(c *gin.Context) {
+			handler.GetVMEvents(c, c.Param("id"))
+		})
 		router.GET("/vms/inspector", handler.GetInspectorStatus)
 		router.POST("/vms/inspector", handler.StartInspection)
 		router.PATCH("/vms/inspector", handler.AddVMsToInspection)
@@ -132,8 +138,8 @@ var _ = Describe("VMs Handlers", func() {
 
 			// Assert
 			Expect(w.Code).To(Equal(http.StatusOK))
-			Expect(mockVM.LastListParams.Offset).To(Equal(uint64(10)))
-			Expect(mockVM.LastListParams.Limit).To(Equal(uint64(10)))
+			Expect(mockVM.LastListParams.Cursor.Offset).To(Equal(uint64(10)))
+			Expect(mockVM.LastListParams.Cursor.Limit).To(Equal(uint64(10)))
 		})
 
 		// Given a page size larger than the maximum allowed
@@ -152,7 +158,7 @@ var _ = Describe("VMs Handlers", func() {
 
 			// Assert
 			Expect(w.Code).To(Equal(http.StatusOK))
-			Expect(mockVM.LastListParams.Limit).To(Equal(uint64(100)))
+			Expect(mockVM.LastListParams.Cursor.Limit).To(Equal(uint64(100)))
 		})
 
 		// Given a disk size range where min is greater than max
@@ -172,7 +178,7 @@ var _ = Describe("VMs Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("diskSizeMin cannot be greater than diskSizeMax"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("diskSizeMin cannot be greater than diskSizeMax")))
 		})
 
 		// Given a memory size range where min is greater than max
@@ -192,7 +198,31 @@ var _ = Describe("VMs Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("memorySizeMin cannot be greater than memorySizeMax"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("memorySizeMin cannot be greater than memorySizeMax")))
+		})
+
+		// Given an invalid disk size range and an invalid sort direction
+		// When we request the VM list
+		// Then it should return 400 Bad Request with both field errors
+		It("should return every invalid parameter", func() {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/vms?diskSizeMin=1000&diskSizeMax=500&sort=name:up", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			var response map[string]any
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response["error"]).To(HaveKeyWithValue("details", HaveKeyWithValue("fields", ConsistOf(
+				map[string]any{"field": "diskSizeMin", "message": "diskSizeMin cannot be greater than diskSizeMax"},
+				map[string]any{"field": "sort direction", "message": `invalid sort direction "up": must be one of asc, desc`},
+			))))
+			Expect(mockVM.LastListParams.Cursor.Limit).To(BeZero())
 		})
 
 		// Given an invalid sort format
@@ -212,7 +242,7 @@ var _ = Describe("VMs Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid sort format"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("invalid sort format")))
 		})
 
 		// Given an invalid sort field
@@ -232,7 +262,7 @@ var _ = Describe("VMs Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid sort field"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("invalid sort field")))
 		})
 
 		// Given an invalid sort direction
@@ -252,7 +282,7 @@ var _ = Describe("VMs Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("invalid sort direction"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("invalid sort direction")))
 		})
 
 		// Given valid sort parameters
@@ -348,11 +378,104 @@ var _ = Describe("VMs Handlers", func() {
 			var response map[string]any
 			err := json.Unmarshal(w.Body.Bytes(), &response)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(response["error"]).To(ContainSubstring("not found"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("not found")))
+		})
+	})
+
+	Context("GetVMEvents", func() {
+		// Given a VM with an HA restart event
+		// When we request the VM events
+		// Then the events should be returned
+		It("should return VM events", func() {
+			// Arrange
+			mockVM.EventsResult = []models.VCenterEvent{
+				{
+					Kind:      models.VCenterEventKindEvent,
+					Type:      "VmRestartedOnAlternateHostEvent",
+					Severity:  "error",
+					VMID:      "vm-1",
+					Message:   "vm-1 was restarted on host-2",
+					CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/vms/vm-1/events", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response []v1.VCenterEvent
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Kind).To(Equal(v1.VCenterEventKindEvent))
+			Expect(response[0].Severity).To(Equal(v1.VCenterEventSeverityError))
+			Expect(*response[0].VmId).To(Equal("vm-1"))
+			Expect(response[0].HostId).To(BeNil())
+		})
+
+		// Given a VM does not exist
+		// When we request the VM events
+		// Then it should return 404 Not Found
+		It("should return 404 when VM not found", func() {
+			// Arrange
+			mockVM.EventsError = srvErrors.NewResourceNotFoundError("vm", "vm-nonexistent")
+
+			req := httptest.NewRequest(http.MethodGet, "/vms/vm-nonexistent/events", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
 		})
 	})
 
 	Context("Inspector endpoints", func() {
+		// Given the inspector feature is disabled
+		// When we request the inspector status
+		// Then it should return 404 without calling the inspector
+		It("should return 404 when the inspector feature is disabled", func() {
+			// Arrange
+			disabled := handlers.New(config.Configuration{}, nil, nil, nil, mockVM, nil)
+			router = gin.New()
+			router.GET("/vms/inspector", disabled.GetInspectorStatus)
+
+			req := httptest.NewRequest(http.MethodGet, "/vms/inspector", nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+			Expect(w.Body.String()).To(ContainSubstring("feature inspector is disabled"))
+		})
+
+		// Given a feature gate shared with the configuration reload
+		// When the inspector feature is disabled by a reload
+		// Then the inspector endpoints should return 404
+		It("should follow the features of the feature gate", func() {
+			// Arrange
+			gate := config.NewFeatureGate(map[string]bool{config.FeatureInspector: true})
+			handler.WithFeatureGate(gate)
+
+			// Act
+			enabled := httptest.NewRecorder()
+			router.ServeHTTP(enabled, httptest.NewRequest(http.MethodGet, "/vms/inspector", nil))
+			gate.Set(nil)
+			disabled := httptest.NewRecorder()
+			router.ServeHTTP(disabled, httptest.NewRequest(http.MethodGet, "/vms/inspector", nil))
+
+			// Assert
+			Expect(enabled.Code).To(Equal(http.StatusOK))
+			Expect(disabled.Code).To(Equal(http.StatusNotFound))
+		})
+
 		// Given an inspector service
 		// When we request the inspector status
 		// Then it should return the current status
@@ -725,14 +848,12 @@ var _ = Describe("VMs Handlers Integration", func() {
 		gin.SetMode(gin.TestMode)
 
 		var err error
-		db, err = store.NewDB(":memory:")
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		st = store.NewStore(db, test.NewMockValidator())
 
 		// Migrate the store (creates vinfo, vdisk, concerns tables via parser.Init())
-		err = st.Migrate(ctx)
-		Expect(err).NotTo(HaveOccurred())
 
 		// Insert test data
 		err = test.InsertVMs(ctx, db)
@@ -906,6 +1027,42 @@ var _ = Describe("VMs Handlers Integration", func() {
 			}
 		})
 
+		// Given two encrypted VMs
+		// When we filter by encrypted
+		// Then only the encrypted VMs should be returned
+		It("should filter by encryption", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO vm_security ("VM ID", encrypted) VALUES ('vm-001', true), ('vm-002', true), ('vm-003', false)`)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			req := httptest.NewRequest(http.MethodGet, "/vms?encrypted=true", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			var response v1.VMListResponse
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(2))
+			ids := []string{}
+			for _, vm := range response.Vms {
+				ids = append(ids, vm.Id)
+			}
+			Expect(ids).To(ConsistOf("vm-001", "vm-002"))
+
+			// Act
+			req = httptest.NewRequest(http.MethodGet, "/vms?encrypted=false&pageSize=50", nil)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Total).To(Equal(8))
+		})
+
 		It("should sort by name ascending", func() {
 			req := httptest.NewRequest(http.MethodGet, "/vms?sort=name:asc&pageSize=50", nil)
 			w := httptest.NewRecorder()
@@ -1060,7 +1217,7 @@ var _ = Describe("VMs Handlers Integration", func() {
 
 			var response map[string]any
 			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
-			Expect(response["error"]).To(ContainSubstring("not found"))
+			Expect(response["error"]).To(HaveKeyWithValue("message", ContainSubstring("not found")))
 		})
 
 		It("should return VM with disk details", func() {
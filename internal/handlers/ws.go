@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+const (
+	// statusPollInterval is how often the services are checked for status changes.
+	statusPollInterval = 500 * time.Millisecond
+	wsWriteWait        = 10 * time.Second
+	wsPingInterval     = 30 * time.Second
+	// wsPongWait must be greater than wsPingInterval.
+	wsPongWait = 60 * time.Second
+)
+
+// GetStatusStream upgrades to a WebSocket pushing a StatusUpdate on connection
+// and on every change of the agent or collector status
+// (GET /ws)
+func (h *Handler) GetStatusStream(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context()).Named("ws_handler")
+
+	upgrader := websocket.Upgrader{
+		HandshakeTimeout: wsWriteWait,
+		CheckOrigin:      h.checkWebSocketOrigin,
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader already replied with an HTTP error
+		log.Debugw("websocket upgrade failed", "error", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	// read until the client goes away, answering pings and pongs on the way
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	poll := time.NewTicker(statusPollInterval)
+	defer poll.Stop()
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	var last *v1.StatusUpdate
+	for {
+		update := h.statusUpdate()
+		if last == nil || !reflect.DeepEqual(*last, update) {
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(update); err != nil {
+				log.Debugw("failed to write status update", "error", err)
+				return
+			}
+			last = &update
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-poll.C:
+		}
+	}
+}
+
+func (h *Handler) statusUpdate() v1.StatusUpdate {
+	return v1.StatusUpdate{
+		Agent:     h.agentStatus(),
+		Collector: v1.NewCollectorStatus(h.collectorSrv.GetStatus()),
+	}
+}
+
+// checkWebSocketOrigin accepts same-origin requests, requests without an
+// Origin (non-browser clients) and the origins allowed by the CORS configuration.
+func (h *Handler) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range h.cfg.Server.CORSAllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
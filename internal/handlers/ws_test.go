@@ -0,0 +1,143 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/handlers"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// lockedCollectorService lets the test change the collector status while the
+// handler polls it.
+type lockedCollectorService struct {
+	MockCollectorService
+	mu sync.Mutex
+}
+
+func (m *lockedCollectorService) GetStatus() models.CollectorStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.StatusResult
+}
+
+func (m *lockedCollectorService) setState(state models.CollectorStateType) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.StatusResult = models.CollectorStatus{State: state}
+}
+
+var _ = Describe("Status Stream Handler", func() {
+	var (
+		mockConsole   *MockConsoleService
+		mockCollector *lockedCollectorService
+		cfg           config.Configuration
+		ts            *httptest.Server
+		wsURL         string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mockConsole = &MockConsoleService{
+			StatusResult: models.ConsoleStatus{
+				Current: models.ConsoleStatusDisconnected,
+				Target:  models.ConsoleStatusDisconnected,
+			},
+		}
+		mockCollector = &lockedCollectorService{}
+		mockCollector.setState(models.CollectorStateReady)
+		cfg = config.Configuration{}
+	})
+
+	JustBeforeEach(func() {
+		handler := handlers.New(cfg, mockConsole, mockCollector, nil, nil, nil)
+		router := gin.New()
+		router.GET("/ws", handler.GetStatusStream)
+		ts = httptest.NewServer(router)
+		wsURL = "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	readUpdate := func(conn *websocket.Conn) v1.StatusUpdate {
+		var update v1.StatusUpdate
+		Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+		Expect(conn.ReadJSON(&update)).To(Succeed())
+		return update
+	}
+
+	// Given a connected WebSocket client
+	// When the collector state changes
+	// Then the current status and then the change should be pushed
+	It("pushes the current status and its changes", func() {
+		// Arrange
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		// Act
+		first := readUpdate(conn)
+		mockCollector.setState(models.CollectorStateCollecting)
+		second := readUpdate(conn)
+
+		// Assert
+		Expect(first.Agent.ConsoleConnection).To(Equal(v1.AgentStatusConsoleConnectionDisconnected))
+		Expect(first.Collector.Status).To(Equal(v1.CollectorStatusStatusReady))
+		Expect(second.Collector.Status).To(Equal(v1.CollectorStatusStatusCollecting))
+	})
+
+	// Given a plain HTTP request
+	// When we call the stream endpoint
+	// Then 400 should be returned
+	It("rejects requests that are not a WebSocket handshake", func() {
+		// Act
+		resp, err := http.Get(ts.URL + "/ws")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		// Assert
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	// Given a handshake from a foreign origin
+	// When the origin is not allowed by the CORS configuration
+	// Then the upgrade should be refused
+	It("refuses foreign origins", func() {
+		// Act
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": []string{"http://evil.example.com"}})
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+	})
+
+	Context("with allowed CORS origins", func() {
+		BeforeEach(func() {
+			cfg.Server.CORSAllowedOrigins = []string{"http://ui.example.com"}
+		})
+
+		// Given a handshake from an origin allowed by the CORS configuration
+		// When we connect
+		// Then the upgrade should succeed
+		It("accepts the allowed origins", func() {
+			// Act
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": []string{"http://ui.example.com"}})
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			conn.Close()
+		})
+	})
+})
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ama"
+
+// Results of a dispatch, a VM inspection or a collection phase.
+const (
+	ResultSuccess  = "success"
+	ResultError    = "error"
+	ResultCanceled = "canceled"
+)
+
+var (
+	// CollectorPhaseDuration is the duration of each phase of a collection by
+	// phase (the collector state while it runs) and result.
+	CollectorPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "collector",
+		Name:      "phase_duration_seconds",
+		Help:      "Duration of the phases of the inventory collection.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 14),
+	}, []string{"phase", "result"})
+
+	// CollectorFailures counts the collections failed by phase.
+	CollectorFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "collector",
+		Name:      "failures_total",
+		Help:      "Inventory collections failed, by phase.",
+	}, []string{"phase"})
+
+	// ConsoleDispatchDuration is the duration of the updates sent to the console by result.
+	ConsoleDispatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "console",
+		Name:      "dispatch_duration_seconds",
+		Help:      "Duration of the status and inventory updates sent to the console.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	// ConsoleConsecutiveErrors is the number of updates failed since the last successful one.
+	ConsoleConsecutiveErrors = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "console",
+		Name:      "consecutive_errors",
+		Help:      "Updates to the console failed since the last successful one.",
+	})
+
+	// ConsoleBackoff is the time the console service waits before its next
+	// update after an error, 0 when it is not backing off.
+	ConsoleBackoff = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "console",
+		Name:      "backoff_seconds",
+		Help:      "Wait before the next update to the console after an error, 0 when not backing off.",
+	})
+
+	// InspectorVMDuration is the duration of the inspection of each VM by result.
+	InspectorVMDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "inspector",
+		Name:      "vm_duration_seconds",
+		Help:      "Duration of the deep inspection of a VM.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"result"})
+
+	// HTTPRequestDuration is the duration of the requests of the API by method,
+	// route (e.g. /api/v1/vms/:id) and status class (2xx, 4xx, ...).
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the HTTP requests, by route.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"method", "route", "code"})
+
+	// HTTPResponseSize is the size of the response bodies by method and route.
+	HTTPResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "response_size_bytes",
+		Help:      "Size of the HTTP response bodies, by route.",
+		Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+	}, []string{"method", "route"})
+
+	// StoreQueryDuration is the duration of the database queries by operation
+	// (query, query_row or exec) and statement (select, insert, ...).
+	StoreQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of the queries to the agent database.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 10),
+	}, []string{"operation", "statement"})
+)
+
+// Registry holds every metric of the agent, with the Go runtime and process
+// metrics.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		CollectorPhaseDuration,
+		CollectorFailures,
+		ConsoleDispatchDuration,
+		ConsoleConsecutiveErrors,
+		ConsoleBackoff,
+		InspectorVMDuration,
+		HTTPRequestDuration,
+		HTTPResponseSize,
+		StoreQueryDuration,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
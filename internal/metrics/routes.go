@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// UnmatchedRoute is the route label of the requests matching no route, so the
+// labels stay bounded.
+const UnmatchedRoute = "unmatched"
+
+// RouteStats summarizes the requests of a route recorded in HTTPRequestDuration
+// and HTTPResponseSize, all status codes included. P95Latency is the upper
+// bound of the bucket holding the 95th percentile.
+type RouteStats struct {
+	Method      string
+	Route       string
+	Count       uint64
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+	MeanSize    float64
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+// histogram is the sum of the histograms of a route, one per status class.
+type histogram struct {
+	count   uint64
+	sum     float64
+	bounds  []float64
+	buckets []uint64 // cumulative counts
+}
+
+func (h *histogram) add(pb *dto.Histogram) {
+	h.count += pb.GetSampleCount()
+	h.sum += pb.GetSampleSum()
+	if h.bounds == nil {
+		for _, b := range pb.GetBucket() {
+			h.bounds = append(h.bounds, b.GetUpperBound())
+		}
+		h.buckets = make([]uint64, len(h.bounds))
+	}
+	for i, b := range pb.GetBucket() {
+		h.buckets[i] += b.GetCumulativeCount()
+	}
+}
+
+// quantile returns the upper bound of the bucket holding the q quantile, the
+// last bound when it is beyond it.
+func (h *histogram) quantile(q float64) float64 {
+	if len(h.bounds) == 0 {
+		return 0
+	}
+	rank := q * float64(h.count)
+	for i, c := range h.buckets {
+		if float64(c) >= rank {
+			return h.bounds[i]
+		}
+	}
+	return h.bounds[len(h.bounds)-1]
+}
+
+// Routes returns the stats of every route that served a request, ordered by
+// route and method.
+func Routes() []RouteStats {
+	latencies := gather(HTTPRequestDuration)
+	sizes := gather(HTTPResponseSize)
+
+	routes := make([]RouteStats, 0, len(latencies))
+	for key, latency := range latencies {
+		if latency.count == 0 {
+			continue
+		}
+		stats := RouteStats{
+			Method:      key.method,
+			Route:       key.route,
+			Count:       latency.count,
+			MeanLatency: seconds(latency.sum / float64(latency.count)),
+			P95Latency:  seconds(latency.quantile(0.95)),
+		}
+		if size, ok := sizes[key]; ok && size.count > 0 {
+			stats.MeanSize = size.sum / float64(size.count)
+		}
+		routes = append(routes, stats)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Route != routes[j].Route {
+			return routes[i].Route < routes[j].Route
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// gather sums the histograms of vec by method and route.
+func gather(vec *prometheus.HistogramVec) map[routeKey]*histogram {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+
+	histograms := map[routeKey]*histogram{}
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			continue
+		}
+		var key routeKey
+		for _, l := range pb.GetLabel() {
+			switch l.GetName() {
+			case "method":
+				key.method = l.GetValue()
+			case "route":
+				key.route = l.GetValue()
+			}
+		}
+		h, ok := histograms[key]
+		if !ok {
+			h = &histogram{}
+			histograms[key] = h
+		}
+		h.add(pb.GetHistogram())
+	}
+	return histograms
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,60 @@
+package metrics_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+)
+
+var _ = Describe("Routes", func() {
+	BeforeEach(func() {
+		metrics.HTTPRequestDuration.Reset()
+		metrics.HTTPResponseSize.Reset()
+	})
+
+	// Given requests of a route answered with different status classes
+	// When we get the route stats
+	// Then they should be summed into one entry with the mean and 95th percentile
+	It("sums the status classes of a route", func() {
+		// Arrange
+		for range 19 {
+			metrics.HTTPRequestDuration.WithLabelValues("GET", "/api/v1/vms", "2xx").Observe(0.004)
+			metrics.HTTPResponseSize.WithLabelValues("GET", "/api/v1/vms").Observe(1000)
+		}
+		metrics.HTTPRequestDuration.WithLabelValues("GET", "/api/v1/vms", "5xx").Observe(1.5)
+		metrics.HTTPResponseSize.WithLabelValues("GET", "/api/v1/vms").Observe(20)
+
+		// Act
+		routes := metrics.Routes()
+
+		// Assert
+		Expect(routes).To(HaveLen(1))
+		Expect(routes[0].Method).To(Equal("GET"))
+		Expect(routes[0].Route).To(Equal("/api/v1/vms"))
+		Expect(routes[0].Count).To(Equal(uint64(20)))
+		Expect(routes[0].MeanLatency).To(BeNumerically("~", 78800*time.Microsecond, time.Microsecond))
+		Expect(routes[0].P95Latency).To(Equal(5 * time.Millisecond))
+		Expect(routes[0].MeanSize).To(Equal(951.0))
+	})
+
+	// Given requests of several routes
+	// When we get the route stats
+	// Then they should be ordered by route and method
+	It("orders the routes", func() {
+		// Arrange
+		metrics.HTTPRequestDuration.WithLabelValues("PUT", "/api/v1/agent", "2xx").Observe(0.1)
+		metrics.HTTPRequestDuration.WithLabelValues("GET", "/api/v1/vms", "2xx").Observe(0.1)
+		metrics.HTTPRequestDuration.WithLabelValues("GET", "/api/v1/agent", "2xx").Observe(0.1)
+
+		// Act
+		routes := metrics.Routes()
+
+		// Assert
+		Expect(routes).To(HaveLen(3))
+		Expect([]string{routes[0].Method + " " + routes[0].Route, routes[1].Method + " " + routes[1].Route, routes[2].Method + " " + routes[2].Route}).
+			To(Equal([]string{"GET /api/v1/agent", "PUT /api/v1/agent", "GET /api/v1/vms"}))
+	})
+})
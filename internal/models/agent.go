@@ -16,6 +16,12 @@ const (
 	ConsoleStatusConnected    ConsoleStatusType = "connected"
 )
 
+// ConsoleStatuses are the console statuses reported through the API.
+var ConsoleStatuses = []ConsoleStatusType{
+	ConsoleStatusDisconnected,
+	ConsoleStatusConnected,
+}
+
 func ParseConsoleStatusType(s string) (ConsoleStatusType, error) {
 	switch s {
 	case "connected":
@@ -36,4 +42,8 @@ type ConsoleStatus struct {
 type AgentStatus struct {
 	Console   ConsoleStatus
 	Collector CollectorStatus
+	Update    UpdateStatus
+	// HA is the leader election of an agent of a high availability pair, nil
+	// for a single agent
+	HA *HAStatus
 }
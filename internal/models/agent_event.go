@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// AgentEventType names a lifecycle event of the agent.
+type AgentEventType string
+
+const (
+	AgentEventCollectionStarted   AgentEventType = "collection.started"
+	AgentEventCollectionCompleted AgentEventType = "collection.completed"
+	AgentEventCollectionFailed    AgentEventType = "collection.failed"
+	AgentEventCollectionCanceled  AgentEventType = "collection.canceled"
+
+	// the console dispatch events are recorded when the result changes, not on every dispatch
+	AgentEventConsoleDispatchSucceeded AgentEventType = "console.dispatch_succeeded"
+	AgentEventConsoleDispatchFailed    AgentEventType = "console.dispatch_failed"
+	AgentEventConsoleStopped           AgentEventType = "console.stopped"
+	AgentEventModeChanged              AgentEventType = "agent.mode_changed"
+
+	AgentEventInspectionStarted   AgentEventType = "inspection.started"
+	AgentEventInspectionCompleted AgentEventType = "inspection.completed"
+	AgentEventInspectionFailed    AgentEventType = "inspection.failed"
+	AgentEventInspectionCanceled  AgentEventType = "inspection.canceled"
+	AgentEventVMInspectionFailed  AgentEventType = "inspection.vm_failed"
+
+	// the agents of a high availability pair record the start and the end of their leadership
+	AgentEventHAElected AgentEventType = "ha.elected"
+	AgentEventHADemoted AgentEventType = "ha.demoted"
+)
+
+// AgentEvent is a significant lifecycle event of the agent, such as a
+// collection that finished or a change of mode.
+type AgentEvent struct {
+	Type    AgentEventType
+	Time    time.Time
+	Message string
+	Details map[string]string // e.g. the error, the phase or the VM ID
+}
+
+// AgentEventFilter selects the events returned by a query.
+type AgentEventFilter struct {
+	Types []AgentEventType // any type when empty
+	Since time.Time        // no lower bound when zero
+}
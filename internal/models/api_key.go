@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// APIKey is a key local integrations present in the X-API-Key header. The key
+// itself is only known when created; Hash is its SHA-256.
+type APIKey struct {
+	ID        string
+	Name      string
+	Prefix    string // first characters of the key, to tell keys apart
+	Role      Role
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// AssessmentBundleVersion is the version of the assessment bundle format.
+const AssessmentBundleVersion = 1
+
+// AssessmentManifest is manifest.json, the description of an assessment
+// bundle, signed in manifest.sig by the agent which exported it.
+type AssessmentManifest struct {
+	Version      int       `json:"version"`
+	AgentID      string    `json:"agentId"`
+	SourceID     string    `json:"sourceId"`
+	AgentVersion string    `json:"agentVersion"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// CollectedAt is the time of the last collection of the inventory
+	CollectedAt time.Time `json:"collectedAt"`
+	// PublicKey is the base64 encoded ed25519 key verifying manifest.sig
+	PublicKey string           `json:"publicKey"`
+	Files     []AssessmentFile `json:"files"`
+}
+
+// AssessmentFile is a file of an assessment bundle.
+type AssessmentFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// SHA256 is the hex encoded digest of the file
+	SHA256 string `json:"sha256"`
+}
+
+// AssessmentBundle is a verified assessment bundle.
+type AssessmentBundle struct {
+	Manifest AssessmentManifest
+	// Fingerprint is the hex encoded SHA-256 of the key which signed the bundle
+	Fingerprint string
+	Inventory   *Inventory
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AuditEntry records a mutating request of the local API and who sent it.
+type AuditEntry struct {
+	Time      time.Time
+	Subject   string // Principal.Subject, anonymous without authentication
+	Role      Role   // empty without authentication
+	Method    string
+	Path      string // route of the request, e.g. /api/v1/vms/{id}/inspector
+	Status    int
+	RequestID string
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// BenchmarkRequest selects what a benchmark measures.
+type BenchmarkRequest struct {
+	// Credentials are the ones of the vCenter whose datastores are read
+	Credentials *Credentials
+	// Datastores are the names of the datastores read, all of them when empty
+	Datastores []string
+	// Target is the endpoint the network throughput is measured toward,
+	// tcp://host:port or an http(s) URL receiving a POST. Not measured when
+	// empty
+	Target string
+	// Duration bounds each measure
+	Duration time.Duration
+}
+
+// Benchmark is the throughput measured by a benchmark job, used for the
+// estimates of the migration plans.
+type Benchmark struct {
+	// ID is the one of the job of the benchmark
+	ID         string
+	CreatedAt  time.Time
+	Datastores []ThroughputMeasure
+	// Network is the measure toward the target, nil without target
+	Network *ThroughputMeasure
+}
+
+// ThroughputMeasure is the throughput of reading a datastore or of sending
+// toward a target.
+type ThroughputMeasure struct {
+	// Name is the name of the datastore, or the target
+	Name     string
+	Bytes    int64
+	Duration time.Duration
+	MBps     float64
+	// Error is the error of a failed measure
+	Error string
+}
+
+// ThroughputMBps returns the transfer rate a migration can expect: the read
+// rate of the slowest datastore, capped by the network one. 0 when nothing
+// was measured.
+func (b Benchmark) ThroughputMBps() float64 {
+	var rate float64
+	for _, d := range b.Datastores {
+		if d.Error == "" && d.MBps > 0 && (rate == 0 || d.MBps < rate) {
+			rate = d.MBps
+		}
+	}
+	if n := b.Network; n != nil && n.Error == "" && n.MBps > 0 && (rate == 0 || n.MBps < rate) {
+		rate = n.MBps
+	}
+	return rate
+}
+
+// DatastoreReader opens the disks of the datastores of a source, whose read
+// throughput a benchmark measures.
+type DatastoreReader interface {
+	Datastores(ctx context.Context) ([]string, error)
+	Open(ctx context.Context, datastore string) (io.ReadCloser, error)
+	Close(ctx context.Context) error
+}
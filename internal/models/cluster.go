@@ -0,0 +1,32 @@
+package models
+
+// ClusterRuleType represents the kind of DRS rule defined on a cluster.
+type ClusterRuleType string
+
+const (
+	// ClusterRuleTypeVMAffinity - the VMs must run on the same host
+	ClusterRuleTypeVMAffinity ClusterRuleType = "vm-affinity"
+	// ClusterRuleTypeVMAntiAffinity - the VMs must run on different hosts
+	ClusterRuleTypeVMAntiAffinity ClusterRuleType = "vm-anti-affinity"
+	// ClusterRuleTypeVMHostAffinity - the VM group must run on the host group
+	ClusterRuleTypeVMHostAffinity ClusterRuleType = "vm-host-affinity"
+	// ClusterRuleTypeVMHostAntiAffinity - the VM group must not run on the host group
+	ClusterRuleTypeVMHostAntiAffinity ClusterRuleType = "vm-host-anti-affinity"
+)
+
+// ClusterRule is a DRS placement rule. VM-VM rules only carry VMs,
+// VM-host rules carry both groups resolved to their members. ClusterID is the
+// managed object ID of the cluster, Cluster its name, which clusters of
+// different datacenters may share.
+type ClusterRule struct {
+	ClusterID string
+	Cluster   string
+	Name      string
+	Type      ClusterRuleType
+	Enabled   bool
+	Mandatory bool
+	VMs       []string
+	VMGroup   string
+	Hosts     []string
+	HostGroup string
+}
@@ -30,6 +30,17 @@ const (
 	CollectorLegacyStateCollected             CollectorStateType = "up-to-date"
 )
 
+// CollectorStates are the states the collector reports through the API.
+var CollectorStates = []CollectorStateType{
+	CollectorStateReady,
+	CollectorStateConnecting,
+	CollectorStateConnected,
+	CollectorStateCollecting,
+	CollectorStateParsing,
+	CollectorStateCollected,
+	CollectorStateError,
+}
+
 func (c CollectorStateType) ToV1() CollectorStateType {
 	switch c {
 	case CollectorStateReady:
@@ -1,6 +1,7 @@
 package models
 
-// Configuration represents agent configuration stored in the database.
+// Configuration represents agent configuration stored in the database, as a
+// JSON document versioned by the store.
 type Configuration struct {
-	AgentMode AgentMode
+	AgentMode AgentMode `json:"agentMode"`
 }
@@ -0,0 +1,15 @@
+package models
+
+// DatabaseStats describes the agent database: its size on disk, as reported by
+// DuckDB (e.g. "1.5 MiB"), and its tables.
+type DatabaseStats struct {
+	Size    string
+	WALSize string
+	Tables  []TableStats
+}
+
+// TableStats is a table of the agent database with its estimated number of rows.
+type TableStats struct {
+	Name string
+	Rows int64
+}
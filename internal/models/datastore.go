@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DatastoreStats holds the recent performance of a datastore, aggregated over
+// all the hosts that mount it. Throughput is summed, latency is the worst host.
+type DatastoreStats struct {
+	ID             string
+	Name           string
+	ReadKBps       int64
+	WriteKBps      int64
+	ReadLatencyMs  int64
+	WriteLatencyMs int64
+	SampledAt      time.Time
+}
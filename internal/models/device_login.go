@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// DeviceLoginStateType is the state of the device login of the console JWT.
+type DeviceLoginStateType string
+
+const (
+	// DeviceLoginStateIdle - no login was started since the agent started
+	DeviceLoginStateIdle DeviceLoginStateType = "idle"
+	// DeviceLoginStatePending - waiting for the user to enter the code
+	DeviceLoginStatePending DeviceLoginStateType = "pending"
+	// DeviceLoginStateSucceeded - the console JWT was obtained and persisted
+	DeviceLoginStateSucceeded DeviceLoginStateType = "succeeded"
+	// DeviceLoginStateFailed - the code expired, the login was denied or the token could not be refreshed
+	DeviceLoginStateFailed DeviceLoginStateType = "failed"
+)
+
+// DeviceLoginStates are the states of the device login reported through the API.
+var DeviceLoginStates = []DeviceLoginStateType{
+	DeviceLoginStateIdle,
+	DeviceLoginStatePending,
+	DeviceLoginStateSucceeded,
+	DeviceLoginStateFailed,
+}
+
+// DeviceLogin is the status of the device login of the console JWT.
+type DeviceLogin struct {
+	State DeviceLoginStateType
+	// UserCode is entered by the user at VerificationURI, or VerificationURIComplete opened, while pending
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	// ExpiresAt is the expiry of the user code while pending, of the JWT once succeeded
+	ExpiresAt time.Time
+	Error     error
+}
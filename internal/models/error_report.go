@@ -0,0 +1,36 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// ErrorReportKind is what went wrong in an ErrorReport.
+type ErrorReportKind string
+
+const (
+	ErrorReportPanic            ErrorReportKind = "panic"
+	ErrorReportConsoleFatal     ErrorReportKind = "console_fatal"
+	ErrorReportCollectionFailed ErrorReportKind = "collection_failed"
+)
+
+// ErrorReport is an error of the agent reported to an ErrorReporter.
+type ErrorReport struct {
+	Kind    ErrorReportKind
+	Time    time.Time
+	Message string
+	Details map[string]string // e.g. the error, the stack or the phase
+}
+
+// ErrorReporter receives the panics, fatal console errors and failed
+// collections of the agent, e.g. to centralize them for a fleet. Report is
+// called where the error happens and must not block.
+type ErrorReporter interface {
+	Report(ctx context.Context, report ErrorReport)
+}
+
+// NopErrorReporter drops the reports. It is the reporter of the agent unless
+// one is configured.
+type NopErrorReporter struct{}
+
+func (NopErrorReporter) Report(context.Context, ErrorReport) {}
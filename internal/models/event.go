@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// VCenterEventKind tells whether a VCenterEvent comes from the event log or from a triggered alarm.
+type VCenterEventKind string
+
+const (
+	VCenterEventKindEvent VCenterEventKind = "event"
+	VCenterEventKindAlarm VCenterEventKind = "alarm"
+)
+
+// VCenterEvent is a vCenter event or triggered alarm related to a VM or a host.
+type VCenterEvent struct {
+	Kind      VCenterEventKind
+	Type      string // event type (e.g. VmRestartedOnAlternateHostEvent) or alarm name
+	Severity  string // error, warning or info
+	VMID      string
+	HostID    string
+	Message   string
+	CreatedAt time.Time
+}
@@ -0,0 +1,42 @@
+package models
+
+// ForkliftTarget is where the Forklift CRs of a plan migrate its VMs: the
+// providers and namespaces of Forklift, the Migration Toolkit for
+// Virtualization, and the mappings of the networks and datastores.
+type ForkliftTarget struct {
+	// Namespace holds the CRs and the providers, openshift-mtv when empty
+	Namespace string
+	// SourceProvider is the Forklift Provider of the collected source
+	SourceProvider string
+	// DestinationProvider is the Forklift Provider of the target cluster,
+	// host when empty
+	DestinationProvider string
+	// TargetNamespace is the namespace the VMs are migrated to
+	TargetNamespace string
+	// StorageClass is the storage class of the datastores not in StorageClasses
+	StorageClass string
+	// StorageClasses are the storage classes by datastore name
+	StorageClasses map[string]string
+	// Networks are the destinations by network ID, the pod network for the
+	// networks not in it
+	Networks map[string]ForkliftNetwork
+	// Warm copies the disks while the VMs run, cutting over at the end
+	Warm bool
+}
+
+// ForkliftNetworkType is the kind of destination of a network.
+type ForkliftNetworkType string
+
+const (
+	ForkliftNetworkPod     ForkliftNetworkType = "pod"
+	ForkliftNetworkMultus  ForkliftNetworkType = "multus"
+	ForkliftNetworkIgnored ForkliftNetworkType = "ignored"
+)
+
+// ForkliftNetwork is the destination of a network, Namespace and Name naming
+// the NetworkAttachmentDefinition of a multus one.
+type ForkliftNetwork struct {
+	Type      ForkliftNetworkType
+	Namespace string
+	Name      string
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// HARole is the role of an agent of a high availability pair.
+type HARole string
+
+const (
+	// HARoleLeader collects the source and dispatches to the console.
+	HARoleLeader HARole = "leader"
+	// HARoleStandby serves the read-only API until the lease of the leader
+	// expires.
+	HARoleStandby HARole = "standby"
+)
+
+// HAStatus is the state of the leader election of an agent of a pair.
+type HAStatus struct {
+	Role HARole
+	// Identity names the agent in the lease
+	Identity string
+	// Leader is the identity holding the lease, empty when none does
+	Leader string
+	// RenewedAt is the last renewal of the lease by the leader
+	RenewedAt time.Time
+}
+
+// LeaseRecord is the lease shared by the agents of a pair. The zero record is
+// a lease nobody holds.
+type LeaseRecord struct {
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	RenewedAt  time.Time `json:"renewedAt"`
+}
+
+// Lease keeps the LeaseRecord of a pair on a storage both agents reach.
+type Lease interface {
+	// Get returns the record, the zero one when none was written.
+	Get(ctx context.Context) (LeaseRecord, error)
+	// Put replaces the record.
+	Put(ctx context.Context, r LeaseRecord) error
+}
@@ -0,0 +1,42 @@
+package models
+
+// Host is an ESXi host of the inventory.
+type Host struct {
+	ID         string
+	Cluster    string
+	CPUCores   int
+	CPUSockets int
+	MemoryMB   int
+	Vendor     string
+	Model      string
+}
+
+// Datastore is a datastore of the inventory. ID is the disk address of the
+// datastore (e.g. naa.*), or its name when it has none.
+type Datastore struct {
+	ID                      string
+	Type                    string
+	ProtocolType            string
+	TotalCapacityGB         float64
+	FreeCapacityGB          float64
+	HardwareAcceleratedMove bool
+	// HostIDs are the managed object IDs of the hosts mounting the datastore
+	HostIDs []string
+}
+
+type NetworkType string
+
+const (
+	NetworkTypeDVSwitch    NetworkType = "dvswitch"
+	NetworkTypeDistributed NetworkType = "distributed"
+)
+
+// Network is a distributed switch, or a port group of one, of the inventory.
+type Network struct {
+	Name string
+	Type NetworkType
+	// DVSwitch is the switch of a port group, empty for a switch
+	DVSwitch string
+	VlanID   string
+	VMCount  int
+}
@@ -29,6 +29,16 @@ const (
 	InspectionStateNotFound InspectionState = "not_found"
 )
 
+// InspectionStates are the states of a VM inspection reported through the API.
+var InspectionStates = []InspectionState{
+	InspectionStatePending,
+	InspectionStateRunning,
+	InspectionStateCompleted,
+	InspectionStateCanceled,
+	InspectionStateError,
+	InspectionStateNotFound,
+}
+
 func (i InspectionState) Value() string {
 	return string(i)
 }
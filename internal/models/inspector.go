@@ -27,6 +27,17 @@ const (
 	InspectorStateError InspectorState = "error"
 )
 
+// InspectorStates are the states the inspector reports through the API.
+var InspectorStates = []InspectorState{
+	InspectorStateReady,
+	InspectorStateInitiating,
+	InspectorStateRunning,
+	InspectorStateCanceling,
+	InspectorStateCanceled,
+	InspectorStateCompleted,
+	InspectorStateError,
+}
+
 // InspectorStatus holds the current Inspector state and metadata.
 type InspectorStatus struct {
 	State InspectorState
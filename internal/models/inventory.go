@@ -1,6 +1,13 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
 	"time"
 
 	api "github.com/kubev2v/migration-planner/api/v1alpha1"
@@ -19,9 +26,75 @@ type InfrastructureData struct {
 	VmsPerCluster         []int
 }
 
-// Inventory represents inventory data stored in the database.
+// Inventory represents inventory data stored in the database. Data is the
+// JSON document sent to the console; it is decoded on first use of the
+// accessors and kept for the later ones, so an Inventory must not be copied.
 type Inventory struct {
 	Data      []byte
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	once    sync.Once
+	decoded *api.Inventory
+	err     error
+}
+
+// NewInventory returns the inventory holding the JSON document data.
+func NewInventory(data []byte) *Inventory {
+	return &Inventory{Data: data}
+}
+
+// Decoded returns the inventory document. The document is decoded once.
+func (i *Inventory) Decoded() (*api.Inventory, error) {
+	i.once.Do(func() {
+		if len(i.Data) == 0 {
+			i.err = errors.New("inventory is empty")
+			return
+		}
+		var inv api.Inventory
+		if err := json.Unmarshal(i.Data, &inv); err != nil {
+			i.err = fmt.Errorf("failed to decode inventory: %w", err)
+			return
+		}
+		i.decoded = &inv
+	})
+	return i.decoded, i.err
+}
+
+// Validate returns an error when Data is not an inventory document.
+func (i *Inventory) Validate() error {
+	_, err := i.Decoded()
+	return err
+}
+
+// VMCount returns the number of VMs of the vCenter, or the sum of the VMs of
+// the clusters when the inventory has no vCenter totals.
+func (i *Inventory) VMCount() (int, error) {
+	inv, err := i.Decoded()
+	if err != nil {
+		return 0, err
+	}
+	if inv.Vcenter != nil {
+		return inv.Vcenter.Vms.Total, nil
+	}
+	count := 0
+	for _, cluster := range inv.Clusters {
+		count += cluster.Vms.Total
+	}
+	return count, nil
+}
+
+// Clusters returns the names of the clusters of the inventory, sorted.
+func (i *Inventory) Clusters() ([]string, error) {
+	inv, err := i.Decoded()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(inv.Clusters)), nil
+}
+
+// Hash returns the hex SHA-256 digest of Data. Two inventories holding the
+// same document have the same hash, whenever they were saved.
+func (i *Inventory) Hash() string {
+	return fmt.Sprintf("%x", sha256.Sum256(i.Data))
 }
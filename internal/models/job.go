@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// JobKind names the long-running operation a job follows.
+type JobKind string
+
+const (
+	JobKindCollection JobKind = "collection"
+	JobKindInspection JobKind = "inspection"
+	JobKindBenchmark  JobKind = "benchmark"
+	// JobKindSupportBundle follows the download of a support bundle.
+	JobKindSupportBundle JobKind = "support-bundle"
+	// JobKindAssessmentExport follows the download of an assessment bundle.
+	JobKindAssessmentExport JobKind = "assessment-export"
+)
+
+// JobState is the state of a job. A job is created running and ends
+// completed, failed or canceled.
+type JobState string
+
+const (
+	JobStateRunning   JobState = "running"
+	JobStateCompleted JobState = "completed"
+	JobStateFailed    JobState = "failed"
+	JobStateCanceled  JobState = "canceled"
+)
+
+// IsFinal tells whether a job in state s ended.
+func (s JobState) IsFinal() bool {
+	return s != JobStateRunning
+}
+
+// Job is a long-running operation of the agent, such as a collection, with
+// its progress.
+type Job struct {
+	ID       string
+	Kind     JobKind
+	State    JobState
+	Phase    string // e.g. the collector state, empty when the kind has no phases
+	Progress int    // percentage, 0 to 100
+	Error    string // the error a failed job ended with
+
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time // nil while running
+}
+
+// JobFilter selects the jobs returned by a query.
+type JobFilter struct {
+	Kinds  []JobKind  // any kind when empty
+	States []JobState // any state when empty
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// LoginFailures counts the requests of a client IP sent with invalid
+// credentials since its last successful one.
+type LoginFailures struct {
+	IP          string
+	Count       int
+	LastFailure time.Time
+	// BlockedUntil is the time before which the credentials of IP are not checked
+	BlockedUntil time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Migration is a schema migration embedded in the agent, with the time it was
+// applied to the database. AppliedAt is nil while the migration is pending.
+type Migration struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
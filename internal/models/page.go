@@ -0,0 +1,88 @@
+package models
+
+// Cursor is the position of a page in a listing: the number of items before
+// the page and the number of items the page holds at most.
+type Cursor struct {
+	Offset uint64
+	Limit  uint64
+}
+
+// NewCursor returns the cursor of the page number page, from 1, of size items.
+// A page below 1 is the first one.
+func NewCursor(page, size int) Cursor {
+	if page < 1 {
+		page = 1
+	}
+	return Cursor{Offset: uint64((page - 1) * size), Limit: uint64(size)}
+}
+
+// Page returns the number of the page at the cursor, from 1.
+func (c Cursor) Page() int {
+	if c.Limit == 0 {
+		return 1
+	}
+	return int(c.Offset/c.Limit) + 1
+}
+
+// Next returns the cursor of the page following the one at c.
+func (c Cursor) Next() Cursor {
+	return Cursor{Offset: c.Offset + c.Limit, Limit: c.Limit}
+}
+
+// Page is a page of a listing: its items, the cursor it was read at, and the
+// number of items of the whole listing.
+type Page[T any] struct {
+	Items  []T
+	Cursor Cursor
+	Total  int
+}
+
+// NewPage returns the page of items read at cursor, out of total items.
+func NewPage[T any](items []T, cursor Cursor, total int) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, Cursor: cursor, Total: total}
+}
+
+// Paginate returns the page of all, the whole listing, at cursor.
+func Paginate[T any](all []T, cursor Cursor) Page[T] {
+	start := min(cursor.Offset, uint64(len(all)))
+	end := uint64(len(all))
+	if cursor.Limit > 0 {
+		end = min(start+cursor.Limit, end)
+	}
+	return NewPage(all[start:end], cursor, len(all))
+}
+
+// Number returns the number of the page, from 1.
+func (p Page[T]) Number() int {
+	return p.Cursor.Page()
+}
+
+// Size returns the number of items a page holds at most, 0 when unbounded.
+func (p Page[T]) Size() int {
+	return int(p.Cursor.Limit)
+}
+
+// Count returns the number of pages of the listing, 1 when it is empty.
+func (p Page[T]) Count() int {
+	if p.Cursor.Limit == 0 || p.Total == 0 {
+		return 1
+	}
+	return (p.Total + int(p.Cursor.Limit) - 1) / int(p.Cursor.Limit)
+}
+
+// HasNext tells whether there are items after the page.
+func (p Page[T]) HasNext() bool {
+	return p.Cursor.Offset+uint64(len(p.Items)) < uint64(p.Total)
+}
+
+// MapPage returns p with its items converted by f.
+func MapPage[T, U any](p Page[T], f func(T) U) Page[U] {
+	items := make([]U, 0, len(p.Items))
+	for _, item := range p.Items {
+		items = append(items, f(item))
+	}
+	return Page[U]{Items: items, Cursor: p.Cursor, Total: p.Total}
+}
@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// DefaultPlanThroughputMBps is the transfer rate of the plan estimates, in
+// MB/s, when neither the request nor a measurement gives one.
+const DefaultPlanThroughputMBps = 100
+
+// PlanThroughputSource tells where the transfer rate of the estimates of a plan
+// comes from.
+type PlanThroughputSource string
+
+const (
+	PlanThroughputDefault   PlanThroughputSource = "default"
+	PlanThroughputRequest   PlanThroughputSource = "request"
+	PlanThroughputBenchmark PlanThroughputSource = "benchmark"
+)
+
+// PlanRequest selects the VMs of a migration plan, in waves migrated one after
+// the other.
+type PlanRequest struct {
+	Name  string
+	Waves []PlanWaveRequest
+	// ThroughputMBps is the transfer rate of the estimates, in MB/s, the one
+	// of the latest benchmark when 0, else the default one
+	ThroughputMBps float64
+}
+
+// PlanWaveRequest is a wave of a PlanRequest.
+type PlanWaveRequest struct {
+	Name  string
+	VMIDs []string
+}
+
+// Plan is a migration plan: the VMs in the order they are migrated, wave
+// after wave, with the time their disks take to transfer and the resources the
+// target needs for them.
+type Plan struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+
+	ThroughputMBps   float64
+	ThroughputSource PlanThroughputSource
+
+	Waves     []PlanWave
+	Resources PlanResources
+	// EstimatedTransfer is the time the disks of every VM take to transfer
+	EstimatedTransfer time.Duration
+}
+
+// PlanWave is a group of VMs of a plan migrated together.
+type PlanWave struct {
+	Name              string
+	VMs               []PlanVM
+	Resources         PlanResources
+	EstimatedTransfer time.Duration
+}
+
+// PlanVM is a VM of a plan.
+type PlanVM struct {
+	// Order is the position of the VM in the plan, from 1
+	Order      int
+	ID         string
+	Name       string
+	Cluster    string
+	PowerState string
+	CPUs       int32
+	MemoryMB   int32
+	DiskMB     int64
+	// EstimatedTransfer is the time the disks of the VM take to transfer
+	EstimatedTransfer time.Duration
+	// CriticalConcerns is the number of concerns blocking the migration of the VM
+	CriticalConcerns int
+}
+
+// PlanResources are the resources the target needs for VMs.
+type PlanResources struct {
+	VMs       int
+	CPUs      int64
+	MemoryMB  int64
+	StorageMB int64
+}
+
+// Add adds the resources of vm.
+func (r *PlanResources) Add(vm PlanVM) {
+	r.VMs++
+	r.CPUs += int64(vm.CPUs)
+	r.MemoryMB += int64(vm.MemoryMB)
+	r.StorageMB += vm.DiskMB
+}
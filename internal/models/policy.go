@@ -0,0 +1,165 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PolicyBundle is an OPA policy bundle downloaded from the console: a gzipped
+// tarball of .rego files and an optional .manifest giving its revision.
+type PolicyBundle struct {
+	// ETag identifies the bundle in the conditional requests of the next downloads
+	ETag string
+	Data []byte
+}
+
+// PolicyBundleState describes the bundle installed in the policies folder.
+type PolicyBundleState struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag,omitempty"`
+	// Revision is the revision of the bundle manifest, the ETag when it has none
+	Revision    string    `json:"revision"`
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+type PolicySource string
+
+const (
+	// PolicySourceBuiltin - a policy of the folder, shipped with the agent
+	PolicySourceBuiltin PolicySource = "builtin"
+	// PolicySourceConsole - a policy of the bundle downloaded from the console
+	PolicySourceConsole PolicySource = "console"
+	// PolicySourceCustom - a policy uploaded by the users
+	PolicySourceCustom PolicySource = "custom"
+)
+
+// PolicyFile is a .rego file of the policies in use.
+type PolicyFile struct {
+	// Name is the file name, relative to the policies folder
+	Name   string
+	Source PolicySource
+	// Hash is the SHA-256 of the content, hex encoded
+	Hash string
+}
+
+// PolicySet describes the policies in use.
+type PolicySet struct {
+	// Fingerprint is the hash of all the policy files, changing with any of them
+	Fingerprint string
+	LoadedAt    time.Time
+	// Bundle is the bundle of the console installed, nil when none is
+	Bundle *PolicyBundleState
+	Files  []PolicyFile
+	// LoadError is the error of the last reload, the previous policies being
+	// kept in use; empty when it succeeded
+	LoadError string
+}
+
+// Concern is a migration concern a policy raises for a VM, with the metadata
+// of its rule when it has some.
+type Concern struct {
+	ID          string
+	Label       string
+	Category    string
+	Assessment  string
+	Severity    string
+	Remediation string
+	DocsURL     string
+}
+
+// PolicyEvaluation holds the concerns the policies in use raise for a VM.
+type PolicyEvaluation struct {
+	VMID     string
+	VMName   string
+	Concerns []Concern
+}
+
+// CustomPolicy is a policy file uploaded by the users, evaluated along the
+// built-in policies.
+type CustomPolicy struct {
+	Name       string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// ConcernMetadata describes the concern of a policy rule, from its annotations.
+type ConcernMetadata struct {
+	ID          string
+	Title       string
+	Severity    string // critical, warning or information, lowercase
+	Category    string
+	Remediation string
+	DocsURL     string
+}
+
+// ConcernDetail describes a concern of the inventory: its metadata, the label
+// and category the rules give it, and the number of VMs it flags.
+type ConcernDetail struct {
+	ConcernMetadata
+	Label   string
+	VMCount int
+}
+
+// SeverityOf returns the severity of a concern without one in its metadata,
+// from its category.
+func SeverityOf(category string) string {
+	return strings.ToLower(category)
+}
+
+type PolicyTestStatus string
+
+const (
+	PolicyTestPassed  PolicyTestStatus = "pass"
+	PolicyTestFailed  PolicyTestStatus = "fail"
+	PolicyTestError   PolicyTestStatus = "error"
+	PolicyTestSkipped PolicyTestStatus = "skip"
+)
+
+// PolicyTestResult is the result of a rego test, a test_ rule of a _test.rego
+// file of the policies folder.
+type PolicyTestResult struct {
+	// File is the test file, relative to the policies folder
+	File     string
+	Package  string
+	Name     string
+	Status   PolicyTestStatus
+	Error    string
+	Duration time.Duration
+}
+
+// PolicyTestReport holds the results of the rego tests of the policies.
+type PolicyTestReport struct {
+	Passed  int
+	Failed  int // failed, or errored
+	Skipped int
+	Results []PolicyTestResult
+}
+
+type PolicyDecisionType string
+
+const (
+	// PolicyDecisionFlagged - the rule raised a concern for the VM
+	PolicyDecisionFlagged PolicyDecisionType = "flagged"
+	// PolicyDecisionPassed - no rule raised a concern for the VM
+	PolicyDecisionPassed PolicyDecisionType = "passed"
+)
+
+// PolicyDecision is a decision of an evaluation of the policies for a VM: a
+// rule that flagged it, or its pass when none did. The decisions of an
+// evaluation share its duration, the rules being evaluated together.
+type PolicyDecision struct {
+	Time     time.Time
+	VMID     string
+	VMName   string
+	Rule     string // empty when passed
+	Decision PolicyDecisionType
+	Duration time.Duration
+}
+
+// PolicyDecisionFilter selects the decisions returned by a query.
+type PolicyDecisionFilter struct {
+	VMID     string             // any VM when empty
+	Rule     string             // any rule when empty
+	Decision PolicyDecisionType // any decision when empty
+	Since    time.Time          // no lower bound when zero
+}
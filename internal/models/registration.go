@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Registration is the identity the console gave to the agent when it
+// registered with a provisioning token.
+type Registration struct {
+	AgentID      string    `json:"agentId"`
+	SourceID     string    `json:"sourceId"`
+	RegisteredAt time.Time `json:"-"`
+}
+
+// RegistrationRequest describes the agent registering with the console.
+type RegistrationRequest struct {
+	Version  string `json:"version"`
+	Hostname string `json:"hostname"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Role is the access level of a client of the local API.
+type Role string
+
+const (
+	// RoleViewer can read the inventory, the VMs and the statuses.
+	RoleViewer Role = "viewer"
+	// RoleOperator can also start collections and inspections and change the agent mode.
+	RoleOperator Role = "operator"
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return r == RoleViewer || r == RoleOperator
+}
+
+// Allows reports whether r grants the access of required.
+func (r Role) Allows(required Role) bool {
+	return r == RoleOperator || r == required
+}
+
+// Principal is a client authenticated by the local API.
+type Principal struct {
+	// Subject names the client: the sub claim of a JWT, apikey:<name> for an
+	// API key, token for the local token
+	Subject string
+	Role    Role
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// RuntimeStats is a snapshot of the process: goroutines, memory, garbage
+// collector, database connections, scheduler load and HTTP routes.
+type RuntimeStats struct {
+	Goroutines int
+	Memory     MemoryStats
+	GC         GCStats
+	Database   DatabaseConnections
+	Scheduler  SchedulerStats
+	Routes     []RouteStats
+}
+
+// MemoryStats are the heap and process memory in bytes, from runtime.MemStats.
+type MemoryStats struct {
+	HeapAlloc   uint64
+	HeapInuse   uint64
+	HeapObjects uint64
+	TotalAlloc  uint64
+	Sys         uint64
+}
+
+// GCStats describes the garbage collections since the start of the process.
+// RecentPauses are the last pauses, newest first.
+type GCStats struct {
+	NumGC        uint32
+	LastGC       *time.Time
+	PauseTotal   time.Duration
+	RecentPauses []time.Duration
+}
+
+// DatabaseConnections are the connections of the database pool, from sql.DBStats.
+type DatabaseConnections struct {
+	Open         int
+	InUse        int
+	Idle         int
+	WaitCount    int64
+	WaitDuration time.Duration
+}
+
+// SchedulerStats is the load of the scheduler.
+type SchedulerStats struct {
+	Workers int
+	Busy    int
+	Queued  int
+}
+
+// RouteStats summarizes the requests served by a route since the start of the
+// process. P95Latency is an upper bound, from the latency histogram buckets.
+type RouteStats struct {
+	Method      string
+	Route       string
+	Count       uint64
+	MeanLatency time.Duration
+	P95Latency  time.Duration
+	MeanSize    float64
+}
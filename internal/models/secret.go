@@ -0,0 +1,12 @@
+package models
+
+import "context"
+
+// SecretStore keeps the secrets of the agent, such as the vCenter credentials,
+// by name. Get returns a ResourceNotFoundError for an unknown name.
+type SecretStore interface {
+	Get(ctx context.Context, name string) ([]byte, error)
+	Put(ctx context.Context, name string, value []byte) error
+	// Delete removes the secret name. Deleting an unknown name is not an error.
+	Delete(ctx context.Context, name string) error
+}
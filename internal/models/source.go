@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Source is a source of the console managed by the agent, collected from its
+// own vCenter into its own inventory.
+type Source struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// SourceStatus is a source with the state of its collection and of its
+// console dispatch loop.
+type SourceStatus struct {
+	Source
+	// Primary is set for the source of the agent's source id, which cannot be removed
+	Primary   bool
+	Collector CollectorStatus
+	Console   ConsoleStatus
+}
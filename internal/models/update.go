@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// UpdateManifest describes the latest version of the agent on an update
+// channel of the console.
+type UpdateManifest struct {
+	Version string `json:"version"`
+	// URL locates the agent binary, resolved against the console URL when relative
+	URL string `json:"url"`
+	// SHA256 is the hex encoded digest of the binary
+	SHA256 string `json:"sha256"`
+	// Signature is the base64 encoded ed25519 signature of Version, a new line
+	// and SHA256, made with the key of the channel
+	Signature string `json:"signature"`
+}
+
+type UpdateState string
+
+const (
+	// UpdateStateDisabled - no update channel is set
+	UpdateStateDisabled UpdateState = "disabled"
+	// UpdateStateUpToDate - the channel has no newer version
+	UpdateStateUpToDate UpdateState = "up-to-date"
+	// UpdateStateDownloading - a newer version is being downloaded and verified
+	UpdateStateDownloading UpdateState = "downloading"
+	// UpdateStateInstalled - a newer version is installed, the agent restarts into it
+	UpdateStateInstalled UpdateState = "installed"
+	// UpdateStateFailed - the last check or update failed
+	UpdateStateFailed UpdateState = "failed"
+)
+
+// UpdateStatus is the state of the self-update of the agent.
+type UpdateStatus struct {
+	State          UpdateState
+	CurrentVersion string
+	// AvailableVersion is the version of the channel, when newer than the current one
+	AvailableVersion string
+	CheckedAt        time.Time
+	Error            error
+}
@@ -1,8 +1,20 @@
 package models
 
-// Credentials holds vCenter connection credentials.
+// Provider is the kind of hypervisor manager the inventory is collected from.
+type Provider string
+
+const (
+	ProviderVSphere Provider = "vsphere"
+	ProviderHyperV  Provider = "hyperv"
+	ProviderOVirt   Provider = "ovirt"
+)
+
+// Credentials holds the connection credentials of the hypervisor manager,
+// vCenter unless Provider says otherwise.
 type Credentials struct {
 	URL      string
 	Username string
 	Password string
+	// Provider is the kind of the manager at URL, vSphere when empty.
+	Provider Provider
 }
@@ -9,6 +9,7 @@ type VMSummary struct {
 	Memory     int32 // MB
 	DiskSize   int64 // MB (stored as MiB in DB, treated as MB)
 	IssueCount int
+	Encrypted  bool
 	Status     InspectionStatus
 }
 
@@ -24,10 +25,19 @@ type VM struct {
 	Datacenter      string
 	Cluster         string
 
-	CpuCount       int32
-	CoresPerSocket int32
-	CpuAffinity    []int32
-	MemoryMB       int32
+	CpuCount            int32
+	CpuSockets          int32
+	CoresPerSocket      int32
+	CpuAffinity         []int32
+	CpuHotAddEnabled    bool
+	CpuHotRemoveEnabled bool
+	MemoryMB            int32
+	MemoryHotAddEnabled bool
+	// NumaNodeAffinity are the NUMA nodes the VM is pinned to, unknown to
+	// RVTools exports
+	NumaNodeAffinity []string
+	// HardwareVersion is the virtual hardware version, e.g. vmx-19
+	HardwareVersion string
 
 	GuestName string
 	GuestID   string
@@ -41,6 +51,10 @@ type VM struct {
 	FaultToleranceEnabled bool
 	NestedHVEnabled       bool
 
+	Encrypted  bool
+	TpmEnabled bool
+	SecureBoot bool
+
 	ToolsStatus        string
 	ToolsRunningStatus string
 
@@ -50,6 +64,9 @@ type VM struct {
 	GuestNetworks []GuestNetwork
 
 	Issues []string
+	// Concerns are the concerns labelled by Issues, without the metadata of
+	// their rules
+	Concerns []Concern
 
 	InspectionState   string
 	InspectionError   string
@@ -57,13 +74,15 @@ type VM struct {
 }
 
 type Disk struct {
-	Key      int32
-	File     string
-	Capacity int64
-	Shared   bool
-	RDM      bool
-	Bus      string
-	Mode     string
+	Key         int32
+	File        string
+	Capacity    int64
+	Shared      bool
+	RDM         bool
+	Bus         string
+	Mode        string
+	ChainDepth  int
+	LinkedClone bool
 }
 
 type NIC struct {
@@ -83,3 +102,14 @@ type GuestNetwork struct {
 	PrefixLength int32
 	Network      string
 }
+
+// DiskChain describes the backing chain of a virtual disk.
+// Depth is the number of files in the chain, 1 for a disk without delta disks.
+type DiskChain struct {
+	VMID        string
+	DiskKey     int32
+	File        string // top of the chain, the file the VM writes to
+	BaseFile    string
+	Depth       int
+	LinkedClone bool // the base disk lives outside the VM directory
+}
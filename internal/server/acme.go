@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+const (
+	acmeCacheFolder = "acme"
+	// acmeChallengeTimeout bounds requests on the challenge listener, which only serves tiny responses.
+	acmeChallengeTimeout = 10 * time.Second
+)
+
+// newACMEManager returns an autocert manager obtaining certificates for cfg.Server.ACMEDomains.
+// Issued certificates and the account key are cached under the data folder so they survive
+// restarts; without a data folder they are kept in memory only.
+func newACMEManager(cfg *config.Configuration) *autocert.Manager {
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Server.ACMEDomains...),
+		Email:      cfg.Server.ACMEEmail,
+	}
+
+	if cfg.Agent.DataFolder != "" {
+		m.Cache = autocert.DirCache(filepath.Join(cfg.Agent.DataFolder, acmeCacheFolder))
+	} else {
+		zap.S().Named("server").Warn("data-folder not set, ACME certificates will be requested again on every restart")
+	}
+
+	if cfg.Server.ACMEDirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: cfg.Server.ACMEDirectoryURL}
+	}
+
+	return m
+}
+
+// acmeTLSConfig returns the TLS configuration serving the ACME certificates.
+// It also answers TLS-ALPN-01 challenges on the HTTPS port.
+func acmeTLSConfig(m *autocert.Manager) *tls.Config {
+	tlsConfig := m.TLSConfig()
+	tlsConfig.MinVersion = tls.VersionTLS12
+	return tlsConfig
+}
+
+// newACMEChallengeServer returns the plain HTTP server answering HTTP-01 challenges.
+// Any other request is redirected to HTTPS on httpsPort.
+func newACMEChallengeServer(m *autocert.Manager, port, httpsPort int) *http.Server {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target := url.URL{
+			Scheme:   "https",
+			Host:     net.JoinHostPort(host, strconv.Itoa(httpsPort)),
+			Path:     r.URL.Path,
+			RawQuery: r.URL.RawQuery,
+		}
+		http.Redirect(w, r, target.String(), http.StatusFound)
+	})
+
+	return &http.Server{
+		Addr:              fmt.Sprintf("0.0.0.0:%d", port),
+		Handler:           m.HTTPHandler(redirect),
+		ReadHeaderTimeout: acmeChallengeTimeout,
+		ReadTimeout:       acmeChallengeTimeout,
+		WriteTimeout:      acmeChallengeTimeout,
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+const adminPrefix string = "/admin"
+
+// metricsPath serves the Prometheus metrics, on the admin listener when one is configured.
+const metricsPath string = "/metrics"
+
+// adminListener serves the admin engine on its own port and/or unix socket,
+// so that operators can firewall it independently of the API.
+type adminListener struct {
+	srv            *http.Server
+	unixSrv        *http.Server
+	unixSocketPath string
+}
+
+func newAdminListener(cfg config.Server, engine *gin.Engine) *adminListener {
+	a := &adminListener{}
+	if cfg.AdminPort != 0 {
+		a.srv = newHTTPServer(cfg, engine)
+		a.srv.Addr = fmt.Sprintf("0.0.0.0:%d", cfg.AdminPort)
+	}
+	if cfg.AdminUnixSocketPath != "" {
+		a.unixSrv = newHTTPServer(cfg, engine)
+		a.unixSocketPath = cfg.AdminUnixSocketPath
+	}
+	return a
+}
+
+// start starts the admin servers in the background. The TCP server uses TLS
+// when the API server does.
+func (a *adminListener) start() error {
+	if a.unixSrv != nil {
+		l, err := listenUnix(a.unixSocketPath)
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := a.unixSrv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				zap.S().Errorw("admin unix socket server", "error", err)
+			}
+		}()
+	}
+	if a.srv != nil {
+		go func() {
+			var err error
+			if a.srv.TLSConfig != nil {
+				err = a.srv.ListenAndServeTLS("", "")
+			} else {
+				err = a.srv.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				zap.S().Errorw("admin server", "error", err)
+			}
+		}()
+	}
+	return nil
+}
+
+func (a *adminListener) stop(ctx context.Context) {
+	if a.unixSrv != nil {
+		if err := a.unixSrv.Shutdown(ctx); err != nil {
+			zap.S().Errorw("admin unix socket server shutdown", "error", err)
+		}
+	}
+	if a.srv != nil {
+		if err := a.srv.Shutdown(ctx); err != nil {
+			zap.S().Errorw("admin server shutdown", "error", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/server/middlewares"
+)
+
+const (
+	apiPrefix  string = "/api/"
+	apiV1      string = "/api/v1"
+	apiGraphQL string = "/api/graphql"
+)
+
+var apiVersionPrefix = regexp.MustCompile(`^/api/v[0-9]+$`)
+
+// MountAPI mounts a versioned API group, such as /api/v2, next to /api/v1.
+// The group gets the same middleware stack as /api/v1 (request id, logging,
+// client certificates, body size, rate limiting and compression), and
+// registerHandlerFn is typically the RegisterHandlers generated for the
+// version's ServerInterface. It must be called before Start.
+func (r *Server) MountAPI(prefix string, registerHandlerFn func(router *gin.RouterGroup)) error {
+	if !apiVersionPrefix.MatchString(prefix) {
+		return fmt.Errorf("invalid api prefix %q: must be /api/v<N>", prefix)
+	}
+	if _, ok := r.apiVersions[prefix]; ok {
+		return fmt.Errorf("api %s is already mounted", prefix)
+	}
+
+	router := r.engine.Group(prefix, r.apiMiddlewares...)
+	registerHandlerFn(router)
+	r.apiVersions[prefix] = router
+
+	return nil
+}
+
+// MountGraphQL serves handler at /api/graphql, for GET and POST, with the
+// middleware stack of the versioned APIs. Both methods only read, needing the
+// viewer role and not being audited. It must be called before Start.
+func (r *Server) MountGraphQL(handler gin.HandlerFunc) {
+	chain := append([]gin.HandlerFunc{middlewares.ReadOnly()}, slices.Clone(r.apiMiddlewares)...)
+	chain = append(chain, handler)
+	r.engine.GET(apiGraphQL, chain...)
+	r.engine.POST(apiGraphQL, chain...)
+}
@@ -24,7 +24,7 @@
 //	│  │  Recovery (panic recovery with zap logging)             │  │
 //	│  └─────────────────────────────────────────────────────────┘  │
 //	├───────────────────────────────────────────────────────────────┤
-//	│                       Router (/api/v1)                        │
+//	│                  Router (/api/v1, /api/v2...)                 │
 //	│  ┌─────────────────────────────────────────────────────────┐  │
 //	│  │  Handlers (registered via callback)                     │  │
 //	│  └─────────────────────────────────────────────────────────┘  │
@@ -54,6 +54,22 @@
 //
 // The registerHandlerFn callback receives a RouterGroup prefixed with /api/v1.
 //
+// Further API versions are mounted with MountAPI before Start, each from the
+// RegisterHandlers of its own generated ServerInterface:
+//
+//	err := server.MountAPI("/api/v2", func(router *gin.RouterGroup) {
+//	    v2.RegisterHandlers(router, handlerV2)
+//	})
+//
+// Every version gets the same middleware stack, and clients share a single
+// rate limit budget across versions. Breaking changes ship in a new version
+// while the UI keeps using /api/v1.
+//
+// The GraphQL endpoint of the inventory is mounted with MountGraphQL at
+// /api/graphql, for GET and POST, with the same middleware stack:
+//
+//	server.MountGraphQL(handler.GraphQL)
+//
 // Starting:
 //
 //	// Blocks until error or shutdown
@@ -69,7 +85,15 @@
 //
 // # Middleware
 //
-// The server applies two middleware to all API routes:
+// The server applies three middleware to all API routes, plus optional ones
+// enabled by configuration:
+//
+// Request ID Middleware (middlewares.RequestID):
+//   - Reuses a valid X-Request-ID sent by the client or generates a UUID
+//   - Returns it in the X-Request-ID response header
+//   - Carries it in the request context (pkg/logger.WithRequestID) so handler,
+//     service and store logs obtained through logger.FromContext/WithContext
+//     include a request_id field
 //
 // Logger Middleware (middlewares.Logger):
 //   - Logs request start: method, path, query, IP, user-agent, timestamp
@@ -77,11 +101,98 @@
 //   - Errors logged separately if present
 //   - Uses zap structured logging with "http" logger name
 //
+// When AccessLogFile is set, the Logger middleware (middlewares.LoggerWithAccessLog)
+// also writes one JSON line per completed request (request_id, method, path,
+// status, latency, size...) to that file, apart from the application logs. The
+// file is rotated to AccessLogFile.<timestamp> once it reaches AccessLogMaxSize
+// and rotated files are pruned by AccessLogMaxAge and AccessLogMaxBackups
+// (pkg/logger.RotatingFile). /api, /admin and /debug/pprof requests are logged.
+//
 // Recovery Middleware (ginzap.RecoveryWithZap):
 //   - Recovers from panics in handlers
 //   - Logs panic details with stack trace
 //   - Returns 500 Internal Server Error
 //
+// Panic Reporting Middleware (middlewares.ReportPanics):
+//   - Installed on /api and /admin right after the recovery middleware
+//   - Passes the panics, with their route, request ID and stack, to the
+//     models.ErrorReporter set with WithErrorReporter, then panics again for
+//     the recovery middleware
+//
+// Tracing Middleware (middlewares.Tracing):
+//   - Only installed when Tracing.Endpoint is set, on /api and /admin
+//   - Starts a server span per request named after the route ("GET /api/v1/vms"),
+//     continuing the trace of the traceparent header if sent
+//   - The span is carried by the request context, the store queries and the
+//     vCenter and console calls of the handlers are its children
+//
+// CORS Middleware (middlewares.CORS):
+//   - Only installed when CORSAllowedOrigins is set
+//   - Installed on the engine so preflight OPTIONS requests are answered
+//   - Limited to /api/ paths (all API versions)
+//   - Allows the Authorization, X-API-Key and X-Request-ID headers by default
+//     and exposes the X-Request-ID response header
+//
+// Body Size Middleware (middlewares.MaxBodySize):
+//   - Only installed when MaxRequestBodySize is positive
+//   - Returns 413 when Content-Length exceeds the limit
+//   - Wraps the body in http.MaxBytesReader for streamed bodies
+//
+// Client Certificate Middleware (middlewares.RequireClientCertificate):
+//   - Only installed when ClientCAFile is set
+//   - Returns 401 when no verified client certificate was presented
+//
+// Credentials Middleware (middlewares.RequireCredentials):
+//   - Installed on /api, /admin, /metrics and /debug/pprof. Without LocalToken
+//     nor JWKSURL, the requests pass through until an API key exists
+//   - OPTIONS requests and the paths of Auth.ExemptPaths (exact, or prefixes
+//     ending with *) pass through
+//   - Other requests return 401 with WWW-Authenticate: Bearer unless they send
+//     "Authorization: Bearer <LocalToken>", a bearer JWT signed by a key of
+//     JWKSURL (middlewares.JWTValidator), or an X-API-Key accepted by the
+//     keys set with WithAPIKeys
+//   - GET and HEAD, and the routes marked with middlewares.ReadOnly such as
+//     /api/graphql, require the viewer role (models.Role) and the other methods
+//     the operator role: the token grants operator, a JWT the role named by
+//     its RoleClaim, an API key its own role, and a viewer gets 403 on a
+//     mutating request
+//   - Requests sending credentials from a client IP made to wait by the
+//     throttle set with WithLoginThrottle return 429 with Retry-After; the
+//     others report their invalid or valid credentials to it. The client IP
+//     is the peer of the connection, taken from X-Forwarded-For only when the
+//     peer is one of the TrustedProxies
+//   - Requests on a unix socket are exempt, like for client certificates
+//   - The principal of the request (models.Principal) is set in the gin
+//     context, read with middlewares.PrincipalFromContext
+//
+// Audit Middleware (middlewares.Audit):
+//   - Installed on /api and /admin after the credentials middleware
+//   - Passes every mutating request but the ReadOnly routes, once served, to
+//     the recorder set with WithAuditLog: its principal (anonymous without
+//     authentication), method, route, status and request ID
+//   - Requests rejected by the credentials middleware are not recorded
+//
+// Standby Middleware (middlewares.RejectOnStandby):
+//   - Installed on /api after the audit middleware, a no-op until WithStandby
+//     is called
+//   - While the agent is the standby of its high availability pair, mutating
+//     requests but the ReadOnly routes return 503 STANDBY naming the leader;
+//     the reads are served from the store of the standby
+//
+// Rate Limit Middleware (middlewares.RateLimit):
+//   - Only installed when RateLimitRPS is positive
+//   - One token bucket per client IP, idle buckets are dropped after 3 minutes.
+//     X-Forwarded-For only names the client when the peer is one of the
+//     TrustedProxies
+//   - RateLimitExemptPaths (status/health endpoints) are never limited
+//   - Returns 429 with a Retry-After header when the bucket is empty
+//
+// Gzip Middleware (middlewares.Gzip):
+//   - Only installed when CompressionEnabled is set
+//   - Negotiated with Accept-Encoding: gzip
+//   - Buffers the body up to CompressionMinSize before deciding; smaller and
+//     non-JSON responses are sent uncompressed
+//
 // # Static File Serving (Production Only)
 //
 // In production mode, the server serves:
@@ -92,6 +203,13 @@
 //	/any/path     → StaticsFolder/index.html (SPA fallback)
 //	/api/*        → 404 JSON error (if route not found)
 //
+// Files under /assets are fingerprinted by the UI build and served with
+// "Cache-Control: public, max-age=31536000, immutable"; everything else,
+// index.html included, with "no-cache" so browsers revalidate it. All files
+// carry an ETag (If-None-Match answers 304). When the client accepts it, a
+// pre-compressed variant next to the file (name.br, then name.gz) is served
+// with the matching Content-Encoding.
+//
 // # TLS Configuration
 //
 // In production mode, TLS is configured with:
@@ -100,6 +218,74 @@
 //   - 1 year certificate validity
 //   - Certificate generated via pkg/certificates
 //
+// When TLSCertFile and TLSKeyFile are set, the key pair is loaded from those
+// files instead, so certificates can come from the operator's own PKI.
+// ReloadCertificates re-reads the files (the agent calls it on SIGHUP); new
+// connections get the new certificate while a failed reload keeps serving the
+// previous one.
+//
+// When ACMEDomains is set, certificates for those domains are obtained and
+// renewed through ACME (Let's Encrypt unless ACMEDirectoryURL is set) using
+// golang.org/x/crypto/acme/autocert. HTTP-01 challenges are answered by a
+// plain HTTP listener on ACMEHTTPPort, which redirects any other request to
+// HTTPPort over HTTPS; TLS-ALPN-01 challenges are answered on the HTTPS port. Certificates
+// are cached in DataFolder/acme. DNS-01 is not supported.
+//
+// # Client Certificates (mTLS)
+//
+// When ClientCAFile is set, clients may present a certificate during the TLS
+// handshake and it is verified against the CAs in that file. The
+// RequireClientCertificate middleware then rejects /api requests without a
+// verified certificate with 401. Static files stay reachable so the UI can load.
+//
+// # Profiling
+//
+// When PprofEnabled is set, the net/http/pprof handlers are mounted under
+// /debug/pprof (index, cmdline, profile, symbol, trace and the named profiles
+// such as heap and goroutine). The group requires a client certificate like
+// /api when ClientCAFile is set, and its credentials. Disabled by default.
+//
+// # Metrics
+//
+// When MetricsEnabled is set, GET /metrics serves the Prometheus metrics of
+// internal/metrics (collector phases, console dispatches, inspected VMs, store
+// queries, plus the Go and process collectors). It is served next to
+// /debug/pprof and requires a client certificate and credentials the same
+// way, unless /metrics is one of the exempt paths, as by default. Enabled by
+// default.
+//
+// The Logger middleware records the latency and response size of every request
+// in histograms labelled by route (the gin pattern, e.g. /api/v1/vms/:id, or
+// "unmatched"), whether or not /metrics is served; GET /admin/runtime
+// summarizes them per route.
+//
+// # Admin Listener
+//
+// Admin-only endpoints are registered on the /admin group returned by
+// AdminRouter (currently GET /admin/migrations, the schema migrations status,
+// GET /admin/config, the resolved configuration, GET /admin/runtime, the
+// process stats, /admin/loglevel, the runtime log levels, /admin/apikeys, the
+// API keys management, and GET /admin/support-bundle).
+// When AdminPort or AdminUnixSocketPath is set, /admin, /metrics and /debug/pprof are
+// served by a separate engine on that port (HTTPS in prod mode, with the API
+// certificate) and/or unix socket, and are no longer reachable on HTTPPort, so
+// operators can firewall them independently. Otherwise they stay on HTTPPort.
+// The client certificate check applies to /admin like to /api.
+//
+// # Timeouts
+//
+// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout are applied to
+// the TCP and unix socket servers to protect against slow clients (slow-loris).
+// The ACME challenge listener uses fixed short timeouts.
+//
+// # Unix Socket
+//
+// When UnixSocketPath is set, the same routes are also served over plain HTTP on
+// that unix socket, so host-local tools can reach the agent. With UnixSocketOnly
+// the TCP port is not opened at all. The socket is created with mode 0660 and a
+// stale socket from a previous run is replaced. Access control is left to the
+// socket permissions: client certificates are not required on the socket.
+//
 // # Usage Example
 //
 //	cfg := &config.Configuration{
@@ -7,9 +7,10 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
-	"path"
 	"strings"
 	"time"
 
@@ -18,18 +19,56 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/server/middlewares"
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
 	"github.com/kubev2v/assisted-migration-agent/pkg/certificates"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
 )
 
 const (
 	ProductionServer string = "prod"
 	DevServer        string = "dev"
-	apiV1            string = "/api/v1"
 )
 
+// APIKeys are the API keys accepted in place of the local token or a JWT.
+type APIKeys interface {
+	// Validate returns the principal of key, nil when it is not valid.
+	Validate(ctx context.Context, key string) (*models.Principal, error)
+	// Exist reports whether a key not revoked exists.
+	Exist(ctx context.Context) (bool, error)
+}
+
 type Server struct {
-	srv *http.Server
+	engine *gin.Engine
+	srv    *http.Server
+	certs  *certificateLoader
+	// acmeSrv answers the ACME HTTP-01 challenges when certificates are provisioned through ACME.
+	acmeSrv *http.Server
+	// unixSrv serves the same handler over plain HTTP on unixSocketPath.
+	unixSrv        *http.Server
+	unixSocketPath string
+	tcpDisabled    bool
+	// admin serves adminRouter and pprof apart from the API when an admin listener is configured.
+	admin       *adminListener
+	adminRouter *gin.RouterGroup
+	// accessLog is the file of the access log, closed on Stop.
+	accessLog *logger.RotatingFile
+	// apiMiddlewares are shared by all the versioned API groups in apiVersions.
+	apiMiddlewares []gin.HandlerFunc
+	apiVersions    map[string]*gin.RouterGroup
+	// apiKeys validates the X-API-Key of the requests requiring the local token.
+	apiKeys APIKeys
+	// audit records the mutating requests of the API and admin groups.
+	audit middlewares.AuditRecorder
+	// throttle delays the clients sending invalid credentials.
+	throttle middlewares.LoginThrottle
+	// errorReporter receives the panics of the handlers.
+	errorReporter models.ErrorReporter
+	// standby tells whether the agent stands by in a high availability pair.
+	standby middlewares.Standby
 }
 
 func NewServer(cfg *config.Configuration, registerHandlerFn func(router *gin.RouterGroup)) (*Server, error) {
@@ -39,66 +78,441 @@ func NewServer(cfg *config.Configuration, registerHandlerFn func(router *gin.Rou
 	}
 	engine := gin.New()
 	engine.MaxMultipartMemory = 64 << 20 // max 64Mb
+	// the client IP keys the rate limiter and the login throttle, it is only
+	// taken from X-Forwarded-For when set by a trusted proxy
+	if err := engine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	if len(cfg.Server.CORSAllowedOrigins) > 0 {
+		corsMiddleware, err := middlewares.CORS(apiPrefix, cfg.Server.CORSAllowedOrigins, cfg.Server.CORSAllowedMethods, cfg.Server.CORSAllowedHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cors configuration: %w", err)
+		}
+		engine.Use(corsMiddleware)
+	}
+
+	srv := newHTTPServer(cfg.Server, engine)
+	srv.Addr = fmt.Sprintf("0.0.0.0:%d", cfg.Server.HTTPPort)
+	server := &Server{
+		engine:      engine,
+		srv:         srv,
+		tcpDisabled: cfg.Server.UnixSocketOnly,
+		apiVersions: make(map[string]*gin.RouterGroup),
+	}
 
-	srv := &http.Server{
-		Addr:    fmt.Sprintf("0.0.0.0:%d", cfg.Server.HTTPPort),
-		Handler: engine,
+	if cfg.Server.UnixSocketPath != "" {
+		server.unixSocketPath = cfg.Server.UnixSocketPath
+		server.unixSrv = newHTTPServer(cfg.Server, engine)
 	}
 
 	if cfg.Server.ServerMode == ProductionServer {
-		engine.Static("/static", cfg.Server.StaticsFolder)
-		// Serve assets at /assets/ to match HTML references
-		engine.Static("/assets", path.Join(cfg.Server.StaticsFolder, "assets"))
-		engine.StaticFile("/", path.Join(cfg.Server.StaticsFolder, "index.html"))
-		engine.StaticFile("/favicon.ico", path.Join(cfg.Server.StaticsFolder, "favicon.ico"))
+		statics := newStaticFiles(cfg.Server.StaticsFolder)
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			engine.Handle(method, "/static/*filepath", statics.handler("/", cacheRevalidate))
+			// Serve assets at /assets/ to match HTML references, their names are fingerprinted by the build
+			engine.Handle(method, "/assets/*filepath", statics.handler("/assets", cacheImmutable))
+			engine.Handle(method, "/", statics.file("index.html", cacheRevalidate))
+			engine.Handle(method, "/favicon.ico", statics.file("favicon.ico", cacheRevalidate))
+		}
 
+		indexHandler := statics.file("index.html", cacheRevalidate)
 		engine.NoRoute(func(c *gin.Context) {
 			if strings.HasPrefix(c.Request.URL.Path, "/api") {
-				c.JSON(404, gin.H{
-					"error": "API endpoint not found",
-				})
+				apiErr := srvErrors.NewAPIError(srvErrors.CodeNotFound, "API endpoint not found")
+				c.JSON(apiErr.HTTPStatus(), apiErr.Response())
 				return
 			}
-			c.File(path.Join(cfg.Server.StaticsFolder, "index.html"))
+			indexHandler(c)
 		})
 
-		cert, key, err := certificates.GenerateSelfSignedCertificate(time.Now().AddDate(1, 0, 0))
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate server's certificates: %w", err)
+		switch {
+		case len(cfg.Server.ACMEDomains) > 0:
+			m := newACMEManager(cfg)
+			server.acmeSrv = newACMEChallengeServer(m, cfg.Server.ACMEHTTPPort, cfg.Server.HTTPPort)
+			srv.TLSConfig = acmeTLSConfig(m)
+		case cfg.Server.TLSCertFile != "" && cfg.Server.TLSKeyFile != "":
+			certs, err := newCertificateLoader(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			server.certs = certs
+			srv.TLSConfig = &tls.Config{
+				GetCertificate: certs.getCertificate,
+				MinVersion:     tls.VersionTLS12,
+			}
+		default:
+			cert, key, err := certificates.GenerateSelfSignedCertificate(time.Now().AddDate(1, 0, 0))
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate server's certificates: %w", err)
+			}
+
+			tlsConfig, err := getTLSConfig(cert, key)
+			if err != nil {
+				return nil, err
+			}
+
+			srv.TLSConfig = tlsConfig
+		}
+
+		if cfg.Server.ClientCAFile != "" {
+			clientCAs, err := loadClientCAs(cfg.Server.ClientCAFile)
+			if err != nil {
+				return nil, err
+			}
+
+			// static files stay reachable without a certificate, /api routes require one
+			srv.TLSConfig.ClientCAs = clientCAs
+			srv.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
 		}
+	}
 
-		tlsConfig, err := getTLSConfig(cert, key)
+	loggerMiddleware := middlewares.Logger()
+	if cfg.Server.AccessLogFile != "" {
+		accessLog, err := logger.NewRotatingFile(cfg.Server.AccessLogFile, int64(cfg.Server.AccessLogMaxSize), cfg.Server.AccessLogMaxAge, cfg.Server.AccessLogMaxBackups)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to open access log: %w", err)
 		}
+		server.accessLog = accessLog
+		loggerMiddleware = middlewares.LoggerWithAccessLog(logger.NewAccessLogger(accessLog))
+	}
 
-		srv.TLSConfig = tlsConfig
+	apiMiddlewares := []gin.HandlerFunc{
+		middlewares.RequestID(),
+		loggerMiddleware,
+		ginzap.RecoveryWithZap(zap.S().Desugar(), true),
+		middlewares.ReportPanics(server.reportPanic),
+	}
+
+	// the span covers the authentication and the other middlewares
+	if cfg.Tracing.Endpoint != "" {
+		apiMiddlewares = append(apiMiddlewares, middlewares.Tracing(tracing.Tracer()))
+	}
+
+	if srv.TLSConfig != nil && srv.TLSConfig.ClientCAs != nil {
+		apiMiddlewares = append(apiMiddlewares, middlewares.RequireClientCertificate())
 	}
 
-	router := engine.Group(apiV1)
+	creds := middlewares.Credentials{
+		Token:    cfg.Auth.LocalToken,
+		APIKeys:  server.validateAPIKey,
+		Throttle: loginThrottle{server: server},
+	}
+	if cfg.Auth.JWKSURL != "" {
+		creds.BearerTokens = middlewares.NewJWTValidator(cfg.Auth.JWKSURL, cfg.Auth.Issuer, cfg.Auth.Audience, cfg.Auth.RoleClaim, cfg.Proxy.ProxyFunc(config.ProxyTargetConsole)).Validate
+	}
+	// without a local token nor JWTs the local API is left open until an API
+	// key is created
+	if creds.Token == "" && creds.BearerTokens == nil {
+		creds.Optional = server.credentialsOptional
+	}
+	apiMiddlewares = append(apiMiddlewares, middlewares.RequireCredentials(creds, cfg.Auth.ExemptPaths))
+	apiMiddlewares = append(apiMiddlewares, middlewares.Audit(server.recordAudit))
+	// the rejected requests are audited, they may come from a client unaware of the failover
+	apiMiddlewares = append(apiMiddlewares, middlewares.RejectOnStandby(server.isStandby))
 
-	router.Use(
-		middlewares.Logger(),
+	if cfg.Server.MaxRequestBodySize > 0 {
+		apiMiddlewares = append(apiMiddlewares, middlewares.MaxBodySize(int64(cfg.Server.MaxRequestBodySize)))
+	}
+
+	// a single limiter, clients share their budget across API versions
+	if cfg.Server.RateLimitRPS > 0 {
+		apiMiddlewares = append(apiMiddlewares, middlewares.RateLimit(cfg.Server.RateLimitRPS, cfg.Server.RateLimitBurst, cfg.Server.RateLimitExemptPaths))
+	}
+
+	if cfg.Server.CompressionEnabled {
+		apiMiddlewares = append(apiMiddlewares, middlewares.Gzip(cfg.Server.CompressionMinSize))
+	}
+
+	server.apiMiddlewares = apiMiddlewares
+	if err := server.MountAPI(apiV1, registerHandlerFn); err != nil {
+		return nil, err
+	}
+
+	// admin endpoints stay on the API engine unless they have their own listener
+	adminEngine := engine
+	if cfg.Server.AdminPort != 0 || cfg.Server.AdminUnixSocketPath != "" {
+		adminEngine = gin.New()
+		_ = adminEngine.SetTrustedProxies(cfg.Server.TrustedProxies)
+		server.admin = newAdminListener(cfg.Server, adminEngine)
+		if server.admin.srv != nil && srv.TLSConfig != nil {
+			server.admin.srv.TLSConfig = srv.TLSConfig.Clone()
+		}
+	}
+
+	server.adminRouter = adminEngine.Group(adminPrefix,
+		middlewares.RequestID(),
+		loggerMiddleware,
 		ginzap.RecoveryWithZap(zap.S().Desugar(), true),
+		middlewares.ReportPanics(server.reportPanic),
 	)
+	if cfg.Tracing.Endpoint != "" {
+		server.adminRouter.Use(middlewares.Tracing(tracing.Tracer()))
+	}
+	if srv.TLSConfig != nil && srv.TLSConfig.ClientCAs != nil {
+		server.adminRouter.Use(middlewares.RequireClientCertificate())
+	}
+	server.adminRouter.Use(middlewares.RequireCredentials(creds, cfg.Auth.ExemptPaths))
+	server.adminRouter.Use(middlewares.Audit(server.recordAudit))
+
+	if cfg.Server.MetricsEnabled {
+		metricsRouter := adminEngine.Group(metricsPath, middlewares.RequestID())
+		if srv.TLSConfig != nil && srv.TLSConfig.ClientCAs != nil {
+			metricsRouter.Use(middlewares.RequireClientCertificate())
+		}
+		// open by default through the exempt paths, so it can be protected
+		metricsRouter.Use(middlewares.RequireCredentials(creds, cfg.Auth.ExemptPaths))
+		metricsRouter.GET("", gin.WrapH(metrics.Handler()))
+	}
+
+	if cfg.Server.PprofEnabled {
+		pprofRouter := adminEngine.Group(debugPprof, middlewares.RequestID(), loggerMiddleware)
+		if srv.TLSConfig != nil && srv.TLSConfig.ClientCAs != nil {
+			pprofRouter.Use(middlewares.RequireClientCertificate())
+		}
+		pprofRouter.Use(middlewares.RequireCredentials(creds, cfg.Auth.ExemptPaths))
+		registerPprof(pprofRouter)
+	}
+
+	return server, nil
+}
+
+// newHTTPServer returns an http.Server serving handler with the timeouts of cfg.
+func newHTTPServer(cfg config.Server, handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// WithAPIKeys sets the API keys accepted in place of the local token or a JWT.
+// Once one exists, the local API requires credentials even without a local
+// token nor JWTs. It must be called before Start.
+func (r *Server) WithAPIKeys(keys APIKeys) *Server {
+	r.apiKeys = keys
+	return r
+}
+
+// validateAPIKey rejects every key until WithAPIKeys is called.
+func (r *Server) validateAPIKey(ctx context.Context, key string) (*models.Principal, error) {
+	if r.apiKeys == nil {
+		return nil, nil
+	}
+	return r.apiKeys.Validate(ctx, key)
+}
+
+// credentialsOptional reports whether the requests may go without
+// credentials, while no API key exists.
+func (r *Server) credentialsOptional(ctx context.Context) (bool, error) {
+	if r.apiKeys == nil {
+		return true, nil
+	}
+	exist, err := r.apiKeys.Exist(ctx)
+	return !exist, err
+}
+
+// WithAuditLog sets the recorder of the mutating requests of the API and admin
+// groups. It must be called before Start.
+func (r *Server) WithAuditLog(record middlewares.AuditRecorder) *Server {
+	r.audit = record
+	return r
+}
+
+// recordAudit drops the entries until WithAuditLog is called.
+func (r *Server) recordAudit(ctx context.Context, entry models.AuditEntry) {
+	if r.audit == nil {
+		return
+	}
+	r.audit(ctx, entry)
+}
+
+// WithErrorReporter sets the reporter of the panics of the handlers. It must
+// be called before Start.
+func (r *Server) WithErrorReporter(reporter models.ErrorReporter) *Server {
+	r.errorReporter = reporter
+	return r
+}
+
+// reportPanic drops the panics until WithErrorReporter is called.
+func (r *Server) reportPanic(c *gin.Context, recovered any, stack []byte) {
+	if r.errorReporter == nil {
+		return
+	}
+
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	r.errorReporter.Report(c.Request.Context(), models.ErrorReport{
+		Kind:    models.ErrorReportPanic,
+		Time:    time.Now().UTC(),
+		Message: fmt.Sprintf("handler panicked: %v", recovered),
+		Details: map[string]string{
+			"method":    c.Request.Method,
+			"path":      path,
+			"requestId": logger.RequestID(c.Request.Context()),
+			"stack":     string(stack),
+		},
+	})
+}
+
+// WithStandby sets the role of the agent in a high availability pair, the API
+// rejecting the mutating requests while it stands by. It must be called before
+// Start.
+func (r *Server) WithStandby(standby middlewares.Standby) *Server {
+	r.standby = standby
+	return r
+}
 
-	registerHandlerFn(router)
+// isStandby leads until WithStandby is called.
+func (r *Server) isStandby() (bool, string) {
+	if r.standby == nil {
+		return false, ""
+	}
+	return r.standby()
+}
+
+// WithLoginThrottle sets the throttle of the clients sending invalid
+// credentials. It must be called before Start.
+func (r *Server) WithLoginThrottle(throttle middlewares.LoginThrottle) *Server {
+	r.throttle = throttle
+	return r
+}
+
+// loginThrottle forwards to the throttle set with WithLoginThrottle, letting
+// every client through until it is called.
+type loginThrottle struct {
+	server *Server
+}
+
+func (t loginThrottle) Wait(ctx context.Context, ip string) (time.Duration, error) {
+	if t.server.throttle == nil {
+		return 0, nil
+	}
+	return t.server.throttle.Wait(ctx, ip)
+}
+
+func (t loginThrottle) Failed(ctx context.Context, ip string) {
+	if t.server.throttle != nil {
+		t.server.throttle.Failed(ctx, ip)
+	}
+}
 
-	return &Server{srv: srv}, nil
+func (t loginThrottle) Succeeded(ctx context.Context, ip string) {
+	if t.server.throttle != nil {
+		t.server.throttle.Succeeded(ctx, ip)
+	}
+}
+
+func (t loginThrottle) Aborted(ip string) {
+	if t.server.throttle != nil {
+		t.server.throttle.Aborted(ip)
+	}
 }
 
-// Start starts the HTTP or HTTPS server based on TLS configuration.
+// AdminRouter returns the group mounted at /admin, served by the admin listener
+// when one is configured and by the API server otherwise.
+func (r *Server) AdminRouter() *gin.RouterGroup {
+	return r.adminRouter
+}
+
+// Start starts the HTTP or HTTPS server based on TLS configuration,
+// the plain HTTP server on the unix socket and the admin listener when configured.
+// It blocks on the TCP server, or on the unix socket server when TCP is disabled.
 func (r *Server) Start(ctx context.Context) error {
+	if r.acmeSrv != nil {
+		go func() {
+			if err := r.acmeSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				zap.S().Errorw("acme challenge server", "error", err)
+			}
+		}()
+	}
+	if r.admin != nil {
+		if err := r.admin.start(); err != nil {
+			return err
+		}
+	}
+	if r.unixSrv != nil {
+		l, err := listenUnix(r.unixSocketPath)
+		if err != nil {
+			return err
+		}
+		if r.tcpDisabled {
+			return r.unixSrv.Serve(l)
+		}
+		go func() {
+			if err := r.unixSrv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				zap.S().Errorw("unix socket server", "error", err)
+			}
+		}()
+	}
 	if r.srv.TLSConfig != nil {
 		return r.srv.ListenAndServeTLS("", "")
 	}
 	return r.srv.ListenAndServe()
 }
 
+// Ping tells whether the server accepts connections, on its TCP port unless
+// disabled and on its unix socket when it has one.
+func (r *Server) Ping(ctx context.Context) error {
+	var d net.Dialer
+	if !r.tcpDisabled {
+		_, port, err := net.SplitHostPort(r.srv.Addr)
+		if err != nil {
+			return err
+		}
+		conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort("localhost", port))
+		if err != nil {
+			return err
+		}
+		_ = conn.Close()
+	}
+	if r.unixSrv != nil {
+		conn, err := d.DialContext(ctx, "unix", r.unixSocketPath)
+		if err != nil {
+			return err
+		}
+		_ = conn.Close()
+	}
+	return nil
+}
+
+// ReloadCertificates reloads the certificate and key from TLSCertFile and TLSKeyFile.
+// New connections use the reloaded certificate. It is a no-op when the server
+// does not serve a certificate from files.
+func (r *Server) ReloadCertificates() error {
+	if r.certs == nil {
+		return nil
+	}
+	return r.certs.load()
+}
+
 func (r *Server) Stop(ctx context.Context) {
+	if r.acmeSrv != nil {
+		if err := r.acmeSrv.Shutdown(ctx); err != nil {
+			zap.S().Errorw("acme challenge server shutdown", "error", err)
+		}
+	}
+	if r.unixSrv != nil {
+		if err := r.unixSrv.Shutdown(ctx); err != nil {
+			zap.S().Errorw("unix socket server shutdown", "error", err)
+		}
+	}
+	if r.admin != nil {
+		r.admin.stop(ctx)
+	}
 	if err := r.srv.Shutdown(ctx); err != nil {
 		zap.S().Errorw("server shutdown", "error", err)
 	}
+	if r.accessLog != nil {
+		if err := r.accessLog.Close(); err != nil {
+			zap.S().Errorw("access log close", "error", err)
+		}
+	}
 }
 
 func getTLSConfig(cert *x509.Certificate, privateKey *rsa.PrivateKey) (*tls.Config, error) {
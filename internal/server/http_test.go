@@ -1,22 +1,105 @@
 package server_test
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/server"
+	"github.com/kubev2v/assisted-migration-agent/pkg/certificates"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
 )
 
+type fakeLoginThrottle struct {
+	mu        sync.Mutex
+	wait      time.Duration
+	failed    []string
+	succeeded []string
+}
+
+func (f *fakeLoginThrottle) Wait(ctx context.Context, ip string) (time.Duration, error) {
+	return f.wait, nil
+}
+
+func (f *fakeLoginThrottle) Failed(ctx context.Context, ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed = append(f.failed, ip)
+}
+
+func (f *fakeLoginThrottle) Succeeded(ctx context.Context, ip string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.succeeded = append(f.succeeded, ip)
+}
+
+func (f *fakeLoginThrottle) Aborted(ip string) {}
+
+// fakeAPIKeys accepts the keys of principals.
+type fakeAPIKeys map[string]*models.Principal
+
+func (f fakeAPIKeys) Validate(ctx context.Context, key string) (*models.Principal, error) {
+	return f[key], nil
+}
+
+func (f fakeAPIKeys) Exist(ctx context.Context) (bool, error) {
+	return len(f) > 0, nil
+}
+
+type fakeErrorReporter struct {
+	mu      sync.Mutex
+	reports []models.ErrorReport
+}
+
+func (f *fakeErrorReporter) Report(ctx context.Context, report models.ErrorReport) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reports = append(f.reports, report)
+}
+
+func (f *fakeErrorReporter) Reports() []models.ErrorReport {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.ErrorReport(nil), f.reports...)
+}
+
 var _ = Describe("HTTP Server", func() {
 	var (
 		cfg               *config.Configuration
@@ -228,4 +311,2463 @@ var _ = Describe("HTTP Server", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Context("production server mode with certificate files", func() {
+		var certFile, keyFile string
+
+		// writeCertificate generates a self-signed certificate, writes it to certFile and keyFile and returns it.
+		writeCertificate := func() *x509.Certificate {
+			cert, key, err := certificates.GenerateSelfSignedCertificate(time.Now().AddDate(0, 0, 1))
+			Expect(err).ToNot(HaveOccurred())
+
+			certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+			Expect(os.WriteFile(certFile, certPEM, 0o600)).To(Succeed())
+
+			keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+			Expect(os.WriteFile(keyFile, keyPEM, 0o600)).To(Succeed())
+
+			return cert
+		}
+
+		// servedCertificate opens a new TLS connection and returns the certificate presented by the server.
+		servedCertificate := func() *x509.Certificate {
+			conn, err := tls.Dial("tcp", fmt.Sprintf("localhost:%d", cfg.Server.HTTPPort), &tls.Config{InsecureSkipVerify: true})
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.Close()
+
+			return conn.ConnectionState().PeerCertificates[0]
+		}
+
+		BeforeEach(func() {
+			certFile = filepath.Join(tempDir, "tls.crt")
+			keyFile = filepath.Join(tempDir, "tls.key")
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:    server.ProductionServer,
+					HTTPPort:      18444,
+					StaticsFolder: tempDir,
+					TLSCertFile:   certFile,
+					TLSKeyFile:    keyFile,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		// Given certificate and key files
+		// When we connect to the production server
+		// Then it should present the certificate from the files
+		It("serves the certificate from the files", func() {
+			// Arrange
+			cert := writeCertificate()
+
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			served := servedCertificate()
+
+			// Assert
+			Expect(served.Raw).To(Equal(cert.Raw))
+		})
+
+		// Given a running server serving a certificate from files
+		// When the files are replaced and the certificates are reloaded
+		// Then new connections should get the new certificate
+		It("serves the new certificate after reload", func() {
+			// Arrange
+			writeCertificate()
+
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			renewed := writeCertificate()
+
+			// Act
+			err = srv.ReloadCertificates()
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(servedCertificate().Raw).To(Equal(renewed.Raw))
+		})
+
+		// Given a running server serving a certificate from files
+		// When the files become invalid and the certificates are reloaded
+		// Then the reload should fail and the previous certificate should still be served
+		It("keeps the previous certificate when reload fails", func() {
+			// Arrange
+			cert := writeCertificate()
+
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			Expect(os.WriteFile(keyFile, []byte("garbage"), 0o600)).To(Succeed())
+
+			// Act
+			err = srv.ReloadCertificates()
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			Expect(servedCertificate().Raw).To(Equal(cert.Raw))
+		})
+
+		// Given missing certificate files
+		// When we create the server
+		// Then it should fail
+		It("fails when the certificate files cannot be loaded", func() {
+			// Act
+			_, err := server.NewServer(cfg, registerHandlerFn)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("production server mode with ACME", func() {
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:    server.ProductionServer,
+					HTTPPort:      18445,
+					StaticsFolder: tempDir,
+					ACMEDomains:   []string{"agent.example.com"},
+					ACMEHTTPPort:  18081,
+				},
+				Agent: config.Agent{
+					DataFolder: tempDir,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		// Given a production server provisioning its certificate through ACME
+		// When we send a plain HTTP request to the challenge port
+		// Then it should be redirected to HTTPS
+		It("redirects plain HTTP requests to HTTPS on the challenge port", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			client := &http.Client{
+				CheckRedirect: func(*http.Request, []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			}
+
+			// Act
+			resp, err := client.Get(fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.ACMEHTTPPort))
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusFound))
+			Expect(resp.Header.Get("Location")).To(Equal(fmt.Sprintf("https://localhost:%d/api/v1/health", cfg.Server.HTTPPort)))
+		})
+
+		// Given a production server provisioning its certificate through ACME
+		// When we request an unknown HTTP-01 challenge token for a domain not in the allow list
+		// Then it should be refused
+		It("refuses HTTP-01 challenges for unknown domains", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			resp, err := http.Get(fmt.Sprintf("http://localhost:%d/.well-known/acme-challenge/unknown", cfg.Server.ACMEHTTPPort))
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("production server mode with client certificates", func() {
+		var clientCert tls.Certificate
+
+		BeforeEach(func() {
+			// the CA signing the client certificates
+			ca, caKey, err := certificates.GenerateSelfSignedCertificate(time.Now().AddDate(0, 0, 1))
+			Expect(err).ToNot(HaveOccurred())
+
+			caFile := filepath.Join(tempDir, "client-ca.crt")
+			Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0o600)).To(Succeed())
+
+			clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(2),
+				NotBefore:    time.Now(),
+				NotAfter:     time.Now().AddDate(0, 0, 1),
+				ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				KeyUsage:     x509.KeyUsageDigitalSignature,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, ca, &clientKey.PublicKey, caKey)
+			Expect(err).ToNot(HaveOccurred())
+
+			clientCert = tls.Certificate{Certificate: [][]byte{der}, PrivateKey: clientKey}
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:    server.ProductionServer,
+					HTTPPort:      18446,
+					StaticsFolder: tempDir,
+					ClientCAFile:  caFile,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		newClient := func(certs ...tls.Certificate) *http.Client {
+			return &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true, Certificates: certs},
+				},
+			}
+		}
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// Given a server requiring client certificates
+		// When we call the API with a certificate signed by the configured CA
+		// Then the request should succeed
+		It("accepts API requests with a trusted client certificate", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp, err := newClient(clientCert).Get(fmt.Sprintf("https://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		// Given a server requiring client certificates
+		// When we call the API without a certificate
+		// Then the request should be rejected with 401
+		It("rejects API requests without a client certificate", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp, err := newClient().Get(fmt.Sprintf("https://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server requiring client certificates
+		// When we call the API with a self-signed certificate
+		// Then the TLS handshake should fail
+		It("rejects client certificates from unknown CAs", func() {
+			// Arrange
+			startServer()
+			other, otherKey, err := certificates.GenerateSelfSignedCertificate(time.Now().AddDate(0, 0, 1))
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			_, err = newClient(tls.Certificate{Certificate: [][]byte{other.Raw}, PrivateKey: otherKey}).
+				Get(fmt.Sprintf("https://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+
+		// Given a server requiring client certificates
+		// When we request the UI without a certificate
+		// Then the static files should still be served
+		It("serves static files without a client certificate", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp, err := newClient().Get(fmt.Sprintf("https://localhost:%d/", cfg.Server.HTTPPort))
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("CORS", func() {
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:         server.DevServer,
+					HTTPPort:           18082,
+					CORSAllowedOrigins: []string{"http://ui.example.com"},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		preflight := func(origin string) *http.Response {
+			req, err := http.NewRequest(http.MethodOptions, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Origin", origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		// Given a server allowing one origin
+		// When a browser sends a preflight request from that origin
+		// Then it should be allowed
+		It("answers preflight requests from allowed origins", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := preflight("http://ui.example.com")
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(resp.Header.Get("Access-Control-Allow-Origin")).To(Equal("http://ui.example.com"))
+			Expect(resp.Header.Get("Access-Control-Allow-Methods")).To(ContainSubstring("GET"))
+		})
+
+		// Given a server allowing one origin with the default headers
+		// When a browser sends a preflight request with an API key and a request id
+		// Then both headers should be allowed
+		It("allows the api key and request id headers by default", func() {
+			// Arrange
+			startServer()
+			req, err := http.NewRequest(http.MethodOptions, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Origin", "http://ui.example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			req.Header.Set("Access-Control-Request-Headers", "X-API-Key, X-Request-ID")
+
+			// Act
+			resp, err := http.DefaultClient.Do(req)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(resp.Header.Get("Access-Control-Allow-Headers")).To(And(ContainSubstring("X-Api-Key"), ContainSubstring("X-Request-Id")))
+		})
+
+		// Given a server allowing one origin
+		// When a browser sends a preflight request from another origin
+		// Then it should be refused
+		It("refuses preflight requests from other origins", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := preflight("http://evil.example.com")
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			Expect(resp.Header.Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+
+		// Given a server allowing one origin
+		// When a browser calls the API from that origin
+		// Then the response should carry the CORS headers
+		It("adds CORS headers to API responses", func() {
+			// Arrange
+			startServer()
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Origin", "http://ui.example.com")
+
+			// Act
+			resp, err := http.DefaultClient.Do(req)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Access-Control-Allow-Origin")).To(Equal("http://ui.example.com"))
+			Expect(resp.Header.Get("Access-Control-Expose-Headers")).To(ContainSubstring("X-Request-Id"))
+		})
+
+		// Given an origin without scheme
+		// When we create the server
+		// Then it should fail
+		It("fails with an invalid origin", func() {
+			// Arrange
+			cfg.Server.CORSAllowedOrigins = []string{"ui.example.com"}
+
+			// Act
+			_, err := server.NewServer(cfg, registerHandlerFn)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid cors configuration"))
+		})
+	})
+
+	Context("rate limiting", func() {
+		BeforeEach(func() {
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/health", func(c *gin.Context) {
+					c.JSON(200, gin.H{"status": "ok"})
+				})
+				router.GET("/vms", func(c *gin.Context) {
+					c.JSON(200, gin.H{"vms": []string{}})
+				})
+			}
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:           server.DevServer,
+					HTTPPort:             18083,
+					RateLimitRPS:         0.1,
+					RateLimitBurst:       2,
+					RateLimitExemptPaths: []string{"/api/v1/health"},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		get := func(path string) *http.Response {
+			resp, err := http.Get(fmt.Sprintf("http://localhost:%d%s", cfg.Server.HTTPPort, path))
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			return resp
+		}
+
+		// Given a server allowing bursts of 2 requests
+		// When a client sends 3 requests in a row
+		// Then the third one should be rejected with 429
+		It("rejects requests above the burst", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			first := get("/api/v1/vms")
+			second := get("/api/v1/vms")
+			third := get("/api/v1/vms")
+
+			// Assert
+			Expect(first.StatusCode).To(Equal(http.StatusOK))
+			Expect(second.StatusCode).To(Equal(http.StatusOK))
+			Expect(third.StatusCode).To(Equal(http.StatusTooManyRequests))
+			Expect(third.Header.Get("Retry-After")).To(Equal("10"))
+		})
+
+		// Given a server allowing bursts of 2 requests, behind no trusted proxy
+		// When a client sends 3 requests, each under a new X-Forwarded-For
+		// Then the third one should still be rejected with 429
+		It("ignores spoofed X-Forwarded-For headers", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			var statuses []int
+			for _, forwarded := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+				req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/v1/vms", cfg.Server.HTTPPort), nil)
+				Expect(err).ToNot(HaveOccurred())
+				req.Header.Set("X-Forwarded-For", forwarded)
+				req.Header.Set("X-Real-IP", forwarded)
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+				statuses = append(statuses, resp.StatusCode)
+			}
+
+			// Assert
+			Expect(statuses).To(Equal([]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}))
+		})
+
+		// Given a server with an exempt health path
+		// When a client polls it more than the burst allows
+		// Then every request should succeed
+		It("never limits exempt paths", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act & Assert
+			for i := 0; i < 5; i++ {
+				Expect(get("/api/v1/health").StatusCode).To(Equal(http.StatusOK))
+			}
+		})
+	})
+
+	Context("compression", func() {
+		largeBody := strings.Repeat("vm-", 1000)
+
+		BeforeEach(func() {
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/health", func(c *gin.Context) {
+					c.JSON(200, gin.H{"status": "ok"})
+				})
+				router.GET("/vms", func(c *gin.Context) {
+					c.JSON(200, gin.H{"vms": largeBody})
+				})
+				router.GET("/text", func(c *gin.Context) {
+					c.String(200, largeBody)
+				})
+			}
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:         server.DevServer,
+					HTTPPort:           18084,
+					CompressionEnabled: true,
+					CompressionMinSize: 1024,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		// get sends the request without the transparent decompression of the http client.
+		get := func(path, acceptEncoding string) *http.Response {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d%s", cfg.Server.HTTPPort, path), nil)
+			Expect(err).ToNot(HaveOccurred())
+			if acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", acceptEncoding)
+			}
+
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// Given a client accepting gzip
+		// When it requests a large JSON response
+		// Then the response should be gzip compressed
+		It("compresses large JSON responses", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("/api/v1/vms", "gzip, deflate")
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(resp.Header.Get("Vary")).To(Equal("Accept-Encoding"))
+
+			gz, err := gzip.NewReader(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := io.ReadAll(gz)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring(largeBody))
+		})
+
+		// Given a client accepting gzip
+		// When it requests a JSON response below the threshold
+		// Then the response should not be compressed
+		It("does not compress small responses", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("/api/v1/health", "gzip")
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"status":"ok"}`))
+		})
+
+		// Given a client not accepting gzip
+		// When it requests a large JSON response
+		// Then the response should not be compressed
+		It("does not compress for clients not accepting gzip", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("/api/v1/vms", "")
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring(largeBody))
+		})
+
+		// Given a client accepting gzip
+		// When it requests a large non-JSON response
+		// Then the response should not be compressed
+		It("does not compress non-JSON responses", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("/api/v1/text", "gzip")
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal(largeBody))
+		})
+	})
+
+	Context("unix socket", func() {
+		var socketPath string
+
+		BeforeEach(func() {
+			socketPath = filepath.Join(tempDir, "agent.sock")
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:     server.DevServer,
+					HTTPPort:       18085,
+					UnixSocketPath: socketPath,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		unixClient := func() *http.Client {
+			return &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+					},
+				},
+			}
+		}
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// Given a server with a unix socket
+		// When we call the API over the socket and over TCP
+		// Then both should be served
+		It("serves on the unix socket in addition to the TCP port", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			unixResp, unixErr := unixClient().Get("http://agent/api/v1/health")
+			tcpResp, tcpErr := http.Get(fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+
+			// Assert
+			Expect(unixErr).ToNot(HaveOccurred())
+			defer unixResp.Body.Close()
+			Expect(unixResp.StatusCode).To(Equal(http.StatusOK))
+
+			Expect(tcpErr).ToNot(HaveOccurred())
+			defer tcpResp.Body.Close()
+			Expect(tcpResp.StatusCode).To(Equal(http.StatusOK))
+
+			info, err := os.Stat(socketPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o660)))
+		})
+
+		// Given a server listening on the unix socket only
+		// When we call the API over TCP
+		// Then the connection should be refused
+		It("does not open the TCP port in unix socket only mode", func() {
+			// Arrange
+			cfg.Server.UnixSocketOnly = true
+			startServer()
+
+			// Act
+			resp, err := unixClient().Get("http://agent/api/v1/health")
+			_, tcpErr := http.Get(fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(tcpErr).To(HaveOccurred())
+		})
+
+		// Given a stale socket file left by a previous run
+		// When the server starts
+		// Then it should replace it and serve requests
+		It("replaces a stale socket file", func() {
+			// Arrange
+			Expect(os.WriteFile(socketPath, nil, 0o600)).To(Succeed())
+			startServer()
+
+			// Act
+			resp, err := unixClient().Get("http://agent/api/v1/health")
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("timeouts and body size", func() {
+		BeforeEach(func() {
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.POST("/upload", func(c *gin.Context) {
+					data, err := io.ReadAll(c.Request.Body)
+					if err != nil {
+						c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+						return
+					}
+					c.JSON(http.StatusOK, gin.H{"bytes": len(data)})
+				})
+			}
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:         server.DevServer,
+					HTTPPort:           18086,
+					ReadHeaderTimeout:  200 * time.Millisecond,
+					MaxRequestBodySize: 1024,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		upload := func(body io.Reader) *http.Response {
+			resp, err := http.Post(fmt.Sprintf("http://localhost:%d/api/v1/upload", cfg.Server.HTTPPort), "application/octet-stream", body)
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		// Given a server limiting request bodies to 1KiB
+		// When we upload a body within the limit
+		// Then it should be accepted
+		It("accepts bodies within the limit", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := upload(bytes.NewReader(make([]byte, 1024)))
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		// Given a server limiting request bodies to 1KiB
+		// When we upload a larger body with its Content-Length
+		// Then it should be rejected with 413
+		It("rejects bodies announced larger than the limit", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := upload(bytes.NewReader(make([]byte, 2048)))
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring("request body larger than 1.0 KiB"))
+		})
+
+		// Given a server limiting request bodies to 1KiB
+		// When we stream a larger body without Content-Length
+		// Then reading it should fail past the limit
+		It("stops reading streamed bodies past the limit", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := upload(io.MultiReader(bytes.NewReader(make([]byte, 2048))))
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+
+		// Given a server with a short read header timeout
+		// When a client sends its headers too slowly
+		// Then the server should close the connection
+		It("closes connections of slow clients", func() {
+			// Arrange
+			startServer()
+			conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", cfg.Server.HTTPPort))
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.Close()
+
+			// Act
+			_, err = conn.Write([]byte("GET /api/v1/upload HTTP/1.1\r\nHost: localhost\r\n"))
+			Expect(err).ToNot(HaveOccurred())
+			time.Sleep(400 * time.Millisecond)
+
+			// Assert
+			Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+			_, err = bufio.NewReader(conn).ReadByte()
+			Expect(err).To(MatchError(io.EOF))
+		})
+	})
+
+	Context("pprof", func() {
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:   server.DevServer,
+					HTTPPort:     18087,
+					PprofEnabled: true,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		get := func(path string) (int, string) {
+			resp, err := http.Get(fmt.Sprintf("http://localhost:%d%s", cfg.Server.HTTPPort, path))
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			return resp.StatusCode, string(body)
+		}
+
+		// Given a server with pprof enabled
+		// When we request the index and a named profile
+		// Then both should be served
+		It("serves the pprof endpoints when enabled", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			indexStatus, _ := get("/debug/pprof/")
+			goroutineStatus, goroutines := get("/debug/pprof/goroutine?debug=1")
+
+			// Assert
+			Expect(indexStatus).To(Equal(http.StatusOK))
+			Expect(goroutineStatus).To(Equal(http.StatusOK))
+			Expect(goroutines).To(ContainSubstring("goroutine profile"))
+		})
+
+		// Given a server with pprof disabled
+		// When we request the pprof index
+		// Then it should not be found
+		It("does not expose pprof when disabled", func() {
+			// Arrange
+			cfg.Server.PprofEnabled = false
+			startServer()
+
+			// Act
+			status, _ := get("/debug/pprof/")
+
+			// Assert
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("metrics", func() {
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:     server.DevServer,
+					HTTPPort:       18095,
+					MetricsEnabled: true,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		get := func(path string) (int, string) {
+			resp, err := http.Get(fmt.Sprintf("http://localhost:%d%s", cfg.Server.HTTPPort, path))
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			return resp.StatusCode, string(body)
+		}
+
+		// Given a server with metrics enabled
+		// When we request the metrics
+		// Then the agent and runtime metrics should be served
+		It("serves the metrics when enabled", func() {
+			// Arrange
+			metrics.ConsoleConsecutiveErrors.Set(0)
+			startServer()
+
+			// Act
+			status, body := get("/metrics")
+
+			// Assert
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(ContainSubstring("ama_console_consecutive_errors 0"))
+			Expect(body).To(ContainSubstring("go_goroutines"))
+		})
+
+		// Given a server with metrics enabled
+		// When we send an API request
+		// Then its latency and response size should be recorded under its route
+		It("records the latency and size of the requests by route", func() {
+			// Arrange
+			metrics.HTTPRequestDuration.Reset()
+			metrics.HTTPResponseSize.Reset()
+			startServer()
+
+			// Act
+			status, _ := get("/api/v1/health")
+
+			// Assert
+			Expect(status).To(Equal(http.StatusOK))
+			_, body := get("/metrics")
+			Expect(body).To(ContainSubstring(`ama_http_request_duration_seconds_count{code="2xx",method="GET",route="/api/v1/health"} 1`))
+			Expect(body).To(ContainSubstring(`ama_http_response_size_bytes_sum{method="GET",route="/api/v1/health"} 15`))
+			routes := metrics.Routes()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].Route).To(Equal("/api/v1/health"))
+			Expect(routes[0].Count).To(Equal(uint64(1)))
+			Expect(routes[0].MeanSize).To(Equal(15.0))
+		})
+
+		// Given a server with metrics disabled
+		// When we request the metrics
+		// Then they should not be found
+		It("does not expose the metrics when disabled", func() {
+			// Arrange
+			cfg.Server.MetricsEnabled = false
+			startServer()
+
+			// Act
+			status, _ := get("/metrics")
+
+			// Assert
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("tracing", func() {
+		var recorder *tracetest.SpanRecorder
+
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode: server.DevServer,
+					HTTPPort:   18096,
+				},
+				Tracing: config.Tracing{Endpoint: "http://localhost:4318"},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+			otel.SetTracerProvider(noop.NewTracerProvider())
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// Given a server exporting traces
+		// When a request carrying a traceparent header is served
+		// Then a server span of the route should continue the trace of the client
+		It("records a span per request in the trace of the client", func() {
+			// Arrange
+			startServer()
+			traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+			// Act
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name()).To(Equal("GET /api/v1/health"))
+			Expect(spans[0].SpanContext().TraceID().String()).To(Equal(traceID))
+			Expect(spans[0].Attributes()).To(ContainElement(attribute.Int("http.response.status_code", http.StatusOK)))
+		})
+
+		// Given a server without a tracing endpoint
+		// When a request is served
+		// Then no span should be recorded
+		It("does not record spans when tracing is off", func() {
+			// Arrange
+			cfg.Tracing.Endpoint = ""
+			startServer()
+
+			// Act
+			resp, err := http.Get(fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(recorder.Ended()).To(BeEmpty())
+		})
+	})
+
+	Context("request id", func() {
+		var seenRequestID string
+
+		BeforeEach(func() {
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/health", func(c *gin.Context) {
+					seenRequestID = logger.RequestID(c.Request.Context())
+					c.JSON(200, gin.H{"status": "ok"})
+				})
+			}
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode: server.DevServer,
+					HTTPPort:   18088,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		get := func(requestID string) *http.Response {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			if requestID != "" {
+				req.Header.Set("X-Request-ID", requestID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			return resp
+		}
+
+		// Given a request without X-Request-ID
+		// When the server handles it
+		// Then a new id should be generated, returned and passed to the handler context
+		It("generates a request id", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("")
+
+			// Assert
+			id := resp.Header.Get("X-Request-ID")
+			Expect(uuid.Validate(id)).To(Succeed())
+			Expect(seenRequestID).To(Equal(id))
+		})
+
+		// Given a request with a valid X-Request-ID
+		// When the server handles it
+		// Then the same id should be used
+		It("reuses the request id sent by the client", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("ui-action-42")
+
+			// Assert
+			Expect(resp.Header.Get("X-Request-ID")).To(Equal("ui-action-42"))
+			Expect(seenRequestID).To(Equal("ui-action-42"))
+		})
+
+		// Given a request with an X-Request-ID containing unexpected characters
+		// When the server handles it
+		// Then it should be replaced by a generated id
+		It("replaces invalid request ids", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := get("bad id\twith spaces")
+
+			// Assert
+			Expect(uuid.Validate(resp.Header.Get("X-Request-ID"))).To(Succeed())
+		})
+	})
+
+	Context("standby", func() {
+		BeforeEach(func() {
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/collector", func(c *gin.Context) {
+					c.JSON(http.StatusOK, gin.H{"status": "ready"})
+				})
+				router.POST("/collector", func(c *gin.Context) {
+					c.Status(http.StatusAccepted)
+				})
+			}
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode: server.DevServer,
+					HTTPPort:   18098,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		// startServer starts a server standing by while standby is set
+		startServer := func(standby *atomic.Bool) {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithStandby(func() (bool, string) { return standby.Load(), "agent-a" })
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		do := func(method string) *http.Response {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			return resp
+		}
+
+		// Given an agent standing by
+		// When we read and start a collection
+		// Then the read should be served and the collection rejected with 503, naming the leader
+		It("serves the reads only while standing by", func() {
+			// Arrange
+			var standby atomic.Bool
+			standby.Store(true)
+			startServer(&standby)
+
+			// Act
+			read := do(http.MethodGet)
+			defer read.Body.Close()
+			write := do(http.MethodPost)
+			defer write.Body.Close()
+
+			// Assert
+			Expect(read.StatusCode).To(Equal(http.StatusOK))
+			Expect(write.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			body, err := io.ReadAll(write.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring("STANDBY"))
+			Expect(string(body)).To(ContainSubstring("agent-a"))
+		})
+
+		// Given an agent which stood by
+		// When it becomes the leader
+		// Then the mutating requests should be served
+		It("serves the mutating requests once leading", func() {
+			// Arrange
+			var standby atomic.Bool
+			standby.Store(true)
+			startServer(&standby)
+
+			// Act
+			standby.Store(false)
+			resp := do(http.MethodPost)
+			defer resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		})
+	})
+
+	Context("static files in production mode", func() {
+		var client *http.Client
+
+		BeforeEach(func() {
+			assetsDir := filepath.Join(tempDir, "assets")
+			Expect(os.MkdirAll(assetsDir, 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(assetsDir, "app-1a2b.js"), []byte("console.log('app')"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(assetsDir, "app-1a2b.js.br"), []byte("brotli"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(assetsDir, "app-1a2b.js.gz"), []byte("gzip"), 0o644)).To(Succeed())
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:    server.ProductionServer,
+					HTTPPort:      18447,
+					StaticsFolder: tempDir,
+				},
+			}
+
+			// no transparent decompression, we check the served encoding
+			client = &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig:    &tls.Config{InsecureSkipVerify: true},
+					DisableCompression: true,
+				},
+			}
+
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		get := func(path string, headers map[string]string) (*http.Response, string) {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://localhost:%d%s", cfg.Server.HTTPPort, path), nil)
+			Expect(err).ToNot(HaveOccurred())
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := client.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			return resp, string(body)
+		}
+
+		// Given a fingerprinted asset
+		// When we request it
+		// Then it should be cacheable forever and carry an ETag
+		It("serves assets as immutable with an ETag", func() {
+			// Act
+			resp, body := get("/assets/app-1a2b.js", nil)
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body).To(Equal("console.log('app')"))
+			Expect(resp.Header.Get("Cache-Control")).To(Equal("public, max-age=31536000, immutable"))
+			Expect(resp.Header.Get("ETag")).ToNot(BeEmpty())
+			Expect(resp.Header.Get("Content-Type")).To(ContainSubstring("javascript"))
+		})
+
+		// Given the ETag of a file
+		// When we revalidate it with If-None-Match
+		// Then it should answer 304
+		It("answers 304 for a matching ETag", func() {
+			// Arrange
+			first, _ := get("/", nil)
+
+			// Act
+			resp, _ := get("/", map[string]string{"If-None-Match": first.Header.Get("ETag")})
+
+			// Assert
+			Expect(first.Header.Get("Cache-Control")).To(Equal("no-cache"))
+			Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+		})
+
+		// Given brotli and gzip variants of an asset
+		// When the client accepts them
+		// Then the preferred pre-compressed variant should be served
+		It("serves pre-compressed variants", func() {
+			// Act
+			brResp, brBody := get("/assets/app-1a2b.js", map[string]string{"Accept-Encoding": "gzip, br"})
+			gzResp, gzBody := get("/assets/app-1a2b.js", map[string]string{"Accept-Encoding": "gzip"})
+
+			// Assert
+			Expect(brResp.Header.Get("Content-Encoding")).To(Equal("br"))
+			Expect(brBody).To(Equal("brotli"))
+			Expect(brResp.Header.Get("Content-Type")).To(ContainSubstring("javascript"))
+			Expect(gzResp.Header.Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(gzBody).To(Equal("gzip"))
+			Expect(gzResp.Header.Get("ETag")).ToNot(Equal(brResp.Header.Get("ETag")))
+		})
+
+		// Given a path escaping the statics folder
+		// When we request it
+		// Then it should not be served
+		It("does not serve files outside the statics folder", func() {
+			// Act
+			resp, _ := get("/assets/../../etc/passwd", nil)
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("admin listener", func() {
+		var socketPath string
+
+		BeforeEach(func() {
+			socketPath = filepath.Join(tempDir, "admin.sock")
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:   server.DevServer,
+					HTTPPort:     18089,
+					AdminPort:    18090,
+					PprofEnabled: true,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.AdminRouter().GET("/ping", func(c *gin.Context) {
+				c.String(http.StatusOK, "pong")
+			})
+
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		get := func(client *http.Client, url string) int {
+			resp, err := client.Get(url)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		// Given a server with an admin port
+		// When we request the admin endpoints and pprof on both ports
+		// Then they should only be served on the admin port, which does not serve the API
+		It("serves the admin endpoints on the admin port only", func() {
+			// Arrange
+			startServer()
+			apiURL := fmt.Sprintf("http://localhost:%d", cfg.Server.HTTPPort)
+			adminURL := fmt.Sprintf("http://localhost:%d", cfg.Server.AdminPort)
+
+			// Act & Assert
+			Expect(get(http.DefaultClient, adminURL+"/admin/ping")).To(Equal(http.StatusOK))
+			Expect(get(http.DefaultClient, adminURL+"/debug/pprof/")).To(Equal(http.StatusOK))
+			Expect(get(http.DefaultClient, adminURL+"/api/v1/health")).To(Equal(http.StatusNotFound))
+			Expect(get(http.DefaultClient, apiURL+"/admin/ping")).To(Equal(http.StatusNotFound))
+			Expect(get(http.DefaultClient, apiURL+"/debug/pprof/")).To(Equal(http.StatusNotFound))
+			Expect(get(http.DefaultClient, apiURL+"/api/v1/health")).To(Equal(http.StatusOK))
+		})
+
+		// Given a server with an admin unix socket and no admin port
+		// When we request the admin endpoints over the socket
+		// Then they should be served
+		It("serves the admin endpoints on the admin unix socket", func() {
+			// Arrange
+			cfg.Server.AdminPort = 0
+			cfg.Server.AdminUnixSocketPath = socketPath
+			startServer()
+			client := &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+					},
+				},
+			}
+
+			// Act & Assert
+			Expect(get(client, "http://admin/admin/ping")).To(Equal(http.StatusOK))
+			Expect(get(http.DefaultClient, fmt.Sprintf("http://localhost:%d/admin/ping", cfg.Server.HTTPPort))).To(Equal(http.StatusNotFound))
+		})
+
+		// Given a server without an admin listener
+		// When we request the admin endpoints on the API port
+		// Then they should be served there
+		It("keeps the admin endpoints on the API port when no admin listener is set", func() {
+			// Arrange
+			cfg.Server.AdminPort = 0
+			startServer()
+
+			// Act & Assert
+			Expect(get(http.DefaultClient, fmt.Sprintf("http://localhost:%d/admin/ping", cfg.Server.HTTPPort))).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("API versions", func() {
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:         server.DevServer,
+					HTTPPort:           18091,
+					CompressionEnabled: true,
+					CompressionMinSize: 1,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// Given a server with /api/v2 mounted next to /api/v1
+		// When we call both versions
+		// Then each should be served by its own handlers with the API middlewares
+		It("serves /api/v2 alongside /api/v1", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(srv.MountAPI("/api/v2", func(router *gin.RouterGroup) {
+				router.GET("/health", func(c *gin.Context) {
+					c.JSON(http.StatusOK, gin.H{"version": "v2"})
+				})
+			})).To(Succeed())
+			startServer()
+
+			// Act
+			v1Resp, err := http.Get(fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort))
+			Expect(err).ToNot(HaveOccurred())
+			defer v1Resp.Body.Close()
+			v2Resp, err := http.Get(fmt.Sprintf("http://localhost:%d/api/v2/health", cfg.Server.HTTPPort))
+			Expect(err).ToNot(HaveOccurred())
+			defer v2Resp.Body.Close()
+			v2Body, err := io.ReadAll(v2Resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Assert
+			Expect(v1Resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(v2Resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(string(v2Body)).To(ContainSubstring(`"v2"`))
+			Expect(v2Resp.Header.Get("X-Request-ID")).ToNot(BeEmpty())
+		})
+
+		// Given a server with /api/v1 mounted
+		// When we mount /api/v1 again or an invalid prefix
+		// Then both should be rejected
+		It("rejects duplicated and invalid prefixes", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			dupErr := srv.MountAPI("/api/v1", registerHandlerFn)
+			invalidErr := srv.MountAPI("/v2", registerHandlerFn)
+
+			// Assert
+			Expect(dupErr).To(MatchError(ContainSubstring("already mounted")))
+			Expect(invalidErr).To(MatchError(ContainSubstring("invalid api prefix")))
+		})
+	})
+
+	Context("access log", func() {
+		var accessLogPath string
+
+		BeforeEach(func() {
+			accessLogPath = filepath.Join(tempDir, "logs", "access.log")
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode:       server.DevServer,
+					HTTPPort:         18092,
+					AccessLogFile:    accessLogPath,
+					AccessLogMaxSize: 1 << 20,
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		// Given a server with an access log file
+		// When we call the API
+		// Then a JSON line describing the request should be written to the file
+		It("writes a JSON line per request to the access log file", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("X-Request-ID", "audit-1")
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+
+			// Assert
+			var entry map[string]any
+			Eventually(func() error {
+				data, err := os.ReadFile(accessLogPath)
+				if err != nil {
+					return err
+				}
+				return json.Unmarshal(bytes.TrimSpace(data), &entry)
+			}).Should(Succeed())
+			Expect(entry["message"]).To(Equal("access"))
+			Expect(entry["request_id"]).To(Equal("audit-1"))
+			Expect(entry["path"]).To(Equal("/api/v1/health"))
+			Expect(entry["status"]).To(BeEquivalentTo(http.StatusOK))
+		})
+	})
+
+	Context("local token", func() {
+		const token = "0123456789abcdef"
+
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode: server.DevServer,
+					HTTPPort:   18093,
+				},
+				Auth: config.Authentication{
+					LocalToken: token,
+				},
+			}
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/health", func(c *gin.Context) {
+					c.JSON(200, gin.H{"status": "ok"})
+				})
+				router.POST("/collector", func(c *gin.Context) {
+					c.JSON(202, gin.H{"status": "started"})
+				})
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		do := func(method, path, authorization string) *http.Response {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%d%s", cfg.Server.HTTPPort, path), nil)
+			Expect(err).ToNot(HaveOccurred())
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			return resp
+		}
+
+		// Given a server with a local token
+		// When we send a mutating request without the token or with a wrong one
+		// Then it should be rejected with 401
+		It("rejects mutating requests without the token", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			missing := do(http.MethodPost, "/api/v1/collector", "")
+			wrong := do(http.MethodPost, "/api/v1/collector", "Bearer fedcba9876543210")
+
+			// Assert
+			Expect(missing.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(missing.Header.Get("WWW-Authenticate")).To(Equal("Bearer"))
+			Expect(wrong.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server with a local token
+		// When we send a mutating request with the token
+		// Then it should be served
+		It("accepts mutating requests with the token", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := do(http.MethodPost, "/api/v1/collector", "Bearer "+token)
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		})
+
+		// Given a server with a local token
+		// When we send a GET request with and without the token
+		// Then only the request with the token should be served
+		It("requires the token on read requests", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			missing := do(http.MethodGet, "/api/v1/health", "")
+			given := do(http.MethodGet, "/api/v1/health", "Bearer "+token)
+
+			// Assert
+			Expect(missing.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(given.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		// Given a server with a local token exempting a path and a prefix
+		// When we send requests to them without the token
+		// Then they should be served while the other paths are rejected
+		It("lets exempt paths through", func() {
+			// Arrange
+			cfg.Auth.ExemptPaths = []string{"/api/v1/health", "/api/v1/status/*"}
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/health", func(c *gin.Context) {
+					c.JSON(200, gin.H{"status": "ok"})
+				})
+				router.GET("/status/collector", func(c *gin.Context) {
+					c.JSON(200, gin.H{"status": "ready"})
+				})
+				router.POST("/collector", func(c *gin.Context) {
+					c.JSON(202, gin.H{"status": "started"})
+				})
+			}
+			startServer()
+
+			// Act
+			health := do(http.MethodGet, "/api/v1/health", "")
+			status := do(http.MethodGet, "/api/v1/status/collector", "")
+			collector := do(http.MethodPost, "/api/v1/collector", "")
+
+			// Assert
+			Expect(health.StatusCode).To(Equal(http.StatusOK))
+			Expect(status.StatusCode).To(Equal(http.StatusOK))
+			Expect(collector.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server with a local token
+		// When we send a CORS preflight without the token
+		// Then it should not be rejected
+		It("lets CORS preflights through", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			resp := do(http.MethodOptions, "/api/v1/collector", "")
+
+			// Assert
+			Expect(resp.StatusCode).ToNot(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server with a local token and a unix socket
+		// When we send a mutating request on the socket without the token
+		// Then it should be served
+		It("does not require the token on the unix socket", func() {
+			// Arrange
+			socketPath := filepath.Join(tempDir, "agent.sock")
+			cfg.Server.UnixSocketPath = socketPath
+			startServer()
+			client := &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+				},
+			}}
+
+			// Act
+			resp, err := client.Post("http://unix/api/v1/collector", "application/json", nil)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		})
+
+		// Given a server with a local token and an API key validator
+		// When we send requests with operator, viewer and invalid keys
+		// Then the viewer key should only be allowed to read
+		It("accepts valid API keys in place of the token", func() {
+			// Arrange
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithAPIKeys(fakeAPIKeys{
+				"ama_operator": {Subject: "apikey:operator", Role: models.RoleOperator},
+				"ama_viewer":   {Subject: "apikey:viewer", Role: models.RoleViewer},
+			})
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			post := func(key string) int {
+				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+				Expect(err).ToNot(HaveOccurred())
+				req.Header.Set("X-API-Key", key)
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+				return resp.StatusCode
+			}
+
+			// Act
+			operator := post("ama_operator")
+			viewer := post("ama_viewer")
+			invalid := post("ama_invalid")
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://localhost:%d/api/v1/health", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("X-API-Key", "ama_viewer")
+			read, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			read.Body.Close()
+
+			// Assert
+			Expect(operator).To(Equal(http.StatusAccepted))
+			Expect(viewer).To(Equal(http.StatusForbidden))
+			Expect(read.StatusCode).To(Equal(http.StatusOK))
+			Expect(invalid).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server without a local token nor JWTs, but with an API key
+		// When we send mutating requests without credentials and with the key
+		// Then only the one with the key should be served
+		It("requires credentials once an API key exists", func() {
+			// Arrange
+			cfg.Auth.LocalToken = ""
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithAPIKeys(fakeAPIKeys{"ama_operator": {Subject: "apikey:operator", Role: models.RoleOperator}})
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("X-API-Key", "ama_operator")
+
+			// Act
+			anonymous := do(http.MethodPost, "/api/v1/collector", "")
+			withKey, err := http.DefaultClient.Do(req)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			withKey.Body.Close()
+			Expect(anonymous.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(withKey.StatusCode).To(Equal(http.StatusAccepted))
+		})
+
+		// Given a server without a local token, JWTs nor API keys
+		// When we send a mutating request without credentials
+		// Then it should be served
+		It("leaves the local API open without any credentials", func() {
+			// Arrange
+			cfg.Auth.LocalToken = ""
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithAPIKeys(fakeAPIKeys{})
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			resp := do(http.MethodPost, "/api/v1/collector", "")
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		})
+
+		// Given a server with a local token and no API key validator
+		// When we send a mutating request with an X-API-Key
+		// Then it should be rejected
+		It("rejects API keys without a validator", func() {
+			// Arrange
+			startServer()
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("X-API-Key", "ama_valid")
+
+			// Act
+			resp, err := http.DefaultClient.Do(req)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server with a local token and a login throttle
+		// When we send requests with invalid, valid and no credentials
+		// Then only the requests with credentials should be counted
+		It("reports the credentials to the login throttle", func() {
+			// Arrange
+			throttle := &fakeLoginThrottle{}
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithLoginThrottle(throttle)
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			do(http.MethodPost, "/api/v1/collector", "Bearer fedcba9876543210")
+			do(http.MethodPost, "/api/v1/collector", "Bearer "+token)
+			do(http.MethodPost, "/api/v1/collector", "")
+
+			// Assert
+			throttle.mu.Lock()
+			defer throttle.mu.Unlock()
+			Expect(throttle.failed).To(Equal([]string{"127.0.0.1"}))
+			Expect(throttle.succeeded).To(Equal([]string{"127.0.0.1"}))
+		})
+
+		// Given a server with a local token, metrics and pprof
+		// When we request them without and with the token
+		// Then they should only be served with it
+		It("protects the metrics and pprof endpoints", func() {
+			// Arrange
+			cfg.Server.MetricsEnabled = true
+			cfg.Server.PprofEnabled = true
+			startServer()
+
+			// Act
+			metricsAnonymous := do(http.MethodGet, "/metrics", "")
+			pprofAnonymous := do(http.MethodGet, "/debug/pprof/", "")
+			metricsAuthenticated := do(http.MethodGet, "/metrics", "Bearer "+token)
+			pprofAuthenticated := do(http.MethodGet, "/debug/pprof/", "Bearer "+token)
+
+			// Assert
+			Expect(metricsAnonymous.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(pprofAnonymous.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(metricsAuthenticated.StatusCode).To(Equal(http.StatusOK))
+			Expect(pprofAuthenticated.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		// Given a server with a local token and /metrics among the exempt paths
+		// When we request the metrics without the token
+		// Then they should be served
+		It("serves the metrics without credentials when exempt", func() {
+			// Arrange
+			cfg.Server.MetricsEnabled = true
+			cfg.Auth.ExemptPaths = []string{"/metrics"}
+			startServer()
+
+			// Act
+			resp := do(http.MethodGet, "/metrics", "")
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		// Given a server with a local token and a login throttle, behind no trusted proxy
+		// When we send invalid credentials under spoofed X-Forwarded-For headers
+		// Then the failures should be reported for the peer address
+		It("ignores the X-Forwarded-For of untrusted peers", func() {
+			// Arrange
+			throttle := &fakeLoginThrottle{}
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithLoginThrottle(throttle)
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			for _, forwarded := range []string{"203.0.113.7", "203.0.113.8"} {
+				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+				Expect(err).ToNot(HaveOccurred())
+				req.Header.Set("Authorization", "Bearer fedcba9876543210")
+				req.Header.Set("X-Forwarded-For", forwarded)
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+			}
+
+			// Assert
+			throttle.mu.Lock()
+			defer throttle.mu.Unlock()
+			Expect(throttle.failed).To(Equal([]string{"127.0.0.1", "127.0.0.1"}))
+		})
+
+		// Given a server with a local token and a login throttle, behind a trusted proxy
+		// When the proxy forwards invalid credentials of a client
+		// Then the failure should be reported for the forwarded client
+		It("reads the X-Forwarded-For of trusted proxies", func() {
+			// Arrange
+			cfg.Server.TrustedProxies = []string{"127.0.0.1"}
+			throttle := &fakeLoginThrottle{}
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithLoginThrottle(throttle)
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer fedcba9876543210")
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+			// Act
+			resp, err := http.DefaultClient.Do(req)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			throttle.mu.Lock()
+			defer throttle.mu.Unlock()
+			Expect(throttle.failed).To(Equal([]string{"203.0.113.7"}))
+		})
+
+		// Given a server with a local token and a throttle making the client wait
+		// When we send the valid token
+		// Then it should be rejected with 429 and a Retry-After header
+		It("rejects throttled clients", func() {
+			// Arrange
+			throttle := &fakeLoginThrottle{wait: 2500 * time.Millisecond}
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithLoginThrottle(throttle)
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			resp := do(http.MethodPost, "/api/v1/collector", "Bearer "+token)
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+			Expect(resp.Header.Get("Retry-After")).To(Equal("3"))
+			throttle.mu.Lock()
+			defer throttle.mu.Unlock()
+			Expect(throttle.succeeded).To(BeEmpty())
+		})
+
+		// Given a server with a local token and an audit log
+		// When we send a read, a rejected and an authenticated mutating request
+		// Then only the authenticated one should be recorded, with its principal
+		It("records the authenticated mutating requests", func() {
+			// Arrange
+			var (
+				mu      sync.Mutex
+				entries []models.AuditEntry
+			)
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithAuditLog(func(ctx context.Context, entry models.AuditEntry) {
+				mu.Lock()
+				defer mu.Unlock()
+				entries = append(entries, entry)
+			})
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+			recorded := func() []models.AuditEntry {
+				mu.Lock()
+				defer mu.Unlock()
+				return append([]models.AuditEntry(nil), entries...)
+			}
+
+			// Act
+			do(http.MethodGet, "/api/v1/health", "Bearer "+token)
+			do(http.MethodPost, "/api/v1/collector", "")
+			resp := do(http.MethodPost, "/api/v1/collector", "Bearer "+token)
+
+			// Assert
+			Eventually(recorded).Should(HaveLen(1))
+			Consistently(recorded, 200*time.Millisecond).Should(HaveLen(1))
+			entry := recorded()[0]
+			Expect(entry.Subject).To(Equal("token"))
+			Expect(entry.Role).To(Equal(models.RoleOperator))
+			Expect(entry.Method).To(Equal(http.MethodPost))
+			Expect(entry.Path).To(Equal("/api/v1/collector"))
+			Expect(entry.Status).To(Equal(http.StatusAccepted))
+			Expect(entry.RequestID).To(Equal(resp.Header.Get("X-Request-ID")))
+		})
+
+		// Given a server with /api/graphql mounted, an API key validator and an audit log
+		// When a viewer posts a query and sends no credentials
+		// Then the query should be served without being recorded and the other rejected
+		It("serves the graphql queries to viewers", func() {
+			// Arrange
+			var (
+				mu      sync.Mutex
+				entries []models.AuditEntry
+			)
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithAPIKeys(fakeAPIKeys{"ama_viewer": {Subject: "apikey:viewer", Role: models.RoleViewer}})
+			srv.WithAuditLog(func(ctx context.Context, entry models.AuditEntry) {
+				mu.Lock()
+				defer mu.Unlock()
+				entries = append(entries, entry)
+			})
+			srv.MountGraphQL(func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"data": gin.H{}})
+			})
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/graphql", cfg.Server.HTTPPort), strings.NewReader(`{"query": "{ networks { name } }"}`))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("X-API-Key", "ama_viewer")
+			viewer, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			viewer.Body.Close()
+			anonymous := do(http.MethodPost, "/api/graphql", "")
+
+			// Assert
+			Expect(viewer.StatusCode).To(Equal(http.StatusOK))
+			Expect(anonymous.StatusCode).To(Equal(http.StatusUnauthorized))
+			Consistently(func() int {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(entries)
+			}, 200*time.Millisecond).Should(BeZero())
+		})
+	})
+
+	Context("jwt", func() {
+		var (
+			key  *rsa.PrivateKey
+			jwks *httptest.Server
+		)
+
+		BeforeEach(func() {
+			var err error
+			key, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+
+			jwks = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"keys": []map[string]string{{
+						"kty": "RSA",
+						"alg": "RS256",
+						"kid": "key-1",
+						"use": "sig",
+						"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+						"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+					}},
+				})
+			}))
+
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode: server.DevServer,
+					HTTPPort:   18094,
+				},
+				Auth: config.Authentication{
+					JWKSURL:   jwks.URL,
+					Issuer:    "https://sso.example.com",
+					Audience:  "migration-planner",
+					RoleClaim: "realm_access.roles",
+				},
+			}
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.POST("/collector", func(c *gin.Context) {
+					c.JSON(202, gin.H{"status": "started"})
+				})
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+			jwks.Close()
+		})
+
+		sign := func(signer *rsa.PrivateKey, audience string, roles ...any) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+				"iss":          "https://sso.example.com",
+				"aud":          audience,
+				"sub":          "user",
+				"exp":          time.Now().Add(time.Hour).Unix(),
+				"realm_access": map[string]any{"roles": roles},
+			})
+			token.Header["kid"] = "key-1"
+			signed, err := token.SignedString(signer)
+			Expect(err).ToNot(HaveOccurred())
+			return signed
+		}
+
+		post := func(token string) int {
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d/api/v1/collector", cfg.Server.HTTPPort), nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		startServer := func() {
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		// Given a server validating JWTs against a JWKS URL
+		// When we send mutating requests with operator and viewer tokens
+		// Then the operator should be served and the viewer forbidden
+		It("maps the role claim of valid tokens", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			operator := post(sign(key, "migration-planner", "offline_access", "operator"))
+			viewer := post(sign(key, "migration-planner", "offline_access"))
+
+			// Assert
+			Expect(operator).To(Equal(http.StatusAccepted))
+			Expect(viewer).To(Equal(http.StatusForbidden))
+		})
+
+		// Given a server validating JWTs against a JWKS URL
+		// When we send tokens for another audience or signed by another key
+		// Then they should be rejected
+		It("rejects invalid tokens", func() {
+			// Arrange
+			startServer()
+			other, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			wrongAudience := post(sign(key, "another-service", "operator"))
+			wrongKey := post(sign(other, "migration-planner", "operator"))
+			garbage := post("not-a-jwt")
+
+			// Assert
+			Expect(wrongAudience).To(Equal(http.StatusUnauthorized))
+			Expect(wrongKey).To(Equal(http.StatusUnauthorized))
+			Expect(garbage).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server validating JWTs against the JWKS URL of a shared provider
+		// When we send a token the provider issued for another of its clients
+		// Then it should be rejected
+		It("rejects tokens with a foreign audience", func() {
+			// Arrange
+			startServer()
+
+			// Act
+			status := post(sign(key, "cloud-services", "operator"))
+
+			// Assert
+			Expect(status).To(Equal(http.StatusUnauthorized))
+		})
+
+		// Given a server validating JWTs against a JWKS URL that fails
+		// When we send several tokens
+		// Then the key set should be fetched once within the refresh interval
+		It("backs off after a failed fetch of the key set", func() {
+			// Arrange
+			var fetches atomic.Int32
+			jwks.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetches.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			startServer()
+
+			// Act
+			first := post(sign(key, "migration-planner", "operator"))
+			second := post(sign(key, "migration-planner", "operator"))
+			third := post(sign(key, "migration-planner", "operator"))
+
+			// Assert
+			Expect(first).To(Equal(http.StatusInternalServerError))
+			Expect(second).To(Equal(http.StatusInternalServerError))
+			Expect(third).To(Equal(http.StatusInternalServerError))
+			Expect(fetches.Load()).To(BeEquivalentTo(1))
+		})
+
+		// Given a server validating JWTs against an unreachable JWKS URL
+		// When we send a token
+		// Then 500 should be returned
+		It("fails when the key set cannot be fetched", func() {
+			// Arrange
+			jwks.Close()
+			startServer()
+
+			// Act
+			status := post(sign(key, "migration-planner", "operator"))
+
+			// Assert
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("error reporting", func() {
+		BeforeEach(func() {
+			cfg = &config.Configuration{
+				Server: config.Server{
+					ServerMode: server.DevServer,
+					HTTPPort:   18097,
+				},
+			}
+			registerHandlerFn = func(router *gin.RouterGroup) {
+				router.GET("/vms/:id", func(c *gin.Context) {
+					panic("nil inventory")
+				})
+			}
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				srv.Stop(context.TODO())
+			}
+		})
+
+		// Given a server with an error reporter
+		// When a handler panics
+		// Then the panic should be reported with its route and the request answered 500
+		It("reports the panics of the handlers", func() {
+			// Arrange
+			reporter := &fakeErrorReporter{}
+			var err error
+			srv, err = server.NewServer(cfg, registerHandlerFn)
+			Expect(err).ToNot(HaveOccurred())
+			srv.WithErrorReporter(reporter)
+			go func() {
+				_ = srv.Start(context.TODO())
+			}()
+			time.Sleep(100 * time.Millisecond)
+
+			// Act
+			resp, err := http.Get(fmt.Sprintf("http://localhost:%d/api/v1/vms/vm-1", cfg.Server.HTTPPort))
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+
+			// Assert
+			Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+			reports := reporter.Reports()
+			Expect(reports).To(HaveLen(1))
+			Expect(reports[0].Kind).To(Equal(models.ErrorReportPanic))
+			Expect(reports[0].Message).To(Equal("handler panicked: nil inventory"))
+			Expect(reports[0].Details).To(HaveKeyWithValue("path", "/api/v1/vms/:id"))
+			Expect(reports[0].Details).To(HaveKeyWithValue("requestId", resp.Header.Get("X-Request-ID")))
+			Expect(reports[0].Details["stack"]).To(ContainSubstring("http_test.go"))
+		})
+	})
 })
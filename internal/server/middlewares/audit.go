@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// AuditRecorder records an entry of the audit log.
+type AuditRecorder func(ctx context.Context, entry models.AuditEntry)
+
+// Audit returns a gin middleware passing every mutating request that went
+// through the credentials check to record once it is served, with the
+// principal set by RequireCredentials, or anonymous when there is none.
+// Requests rejected by RequireCredentials are not recorded.
+func Audit(record AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutating(c) {
+			c.Next()
+			return
+		}
+
+		at := time.Now()
+		c.Next()
+
+		entry := models.AuditEntry{
+			Time:      at,
+			Subject:   "anonymous",
+			Method:    c.Request.Method,
+			Path:      c.FullPath(),
+			Status:    c.Writer.Status(),
+			RequestID: logger.RequestID(c.Request.Context()),
+		}
+		if entry.Path == "" {
+			entry.Path = c.Request.URL.Path
+		}
+		if principal := PrincipalFromContext(c); principal != nil {
+			entry.Subject = principal.Subject
+			entry.Role = principal.Role
+		}
+		record(c.Request.Context(), entry)
+	}
+}
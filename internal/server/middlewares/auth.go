@@ -0,0 +1,235 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// principalKey is the gin context key of the principal of an authenticated request.
+const principalKey = "principal"
+
+// readOnlyKey is the gin context key marking the requests of a ReadOnly route.
+const readOnlyKey = "readOnly"
+
+var (
+	// tokenPrincipal is the principal of the requests sending the local token.
+	tokenPrincipal = models.Principal{Subject: "token", Role: models.RoleOperator}
+	// unixSocketPrincipal is the principal of the requests received on a unix socket.
+	unixSocketPrincipal = models.Principal{Subject: "unix-socket", Role: models.RoleOperator}
+)
+
+// APIKeyValidator returns the principal of an API key, nil when it is not valid.
+type APIKeyValidator func(ctx context.Context, key string) (*models.Principal, error)
+
+// TokenValidator returns the principal of a bearer token, nil when it is not valid.
+type TokenValidator func(ctx context.Context, token string) (*models.Principal, error)
+
+// LoginThrottle slows down the clients sending invalid credentials.
+type LoginThrottle interface {
+	// Wait returns how long ip must wait before its credentials are checked, 0
+	// when they can be checked now. It reserves that attempt until Failed,
+	// Succeeded or Aborted, so parallel attempts cannot pass it together.
+	Wait(ctx context.Context, ip string) (time.Duration, error)
+	// Failed records invalid credentials sent by ip.
+	Failed(ctx context.Context, ip string)
+	// Succeeded records valid credentials sent by ip.
+	Succeeded(ctx context.Context, ip string)
+	// Aborted releases the attempt of ip whose credentials could not be checked.
+	Aborted(ip string)
+}
+
+// Credentials are the credentials accepted by RequireCredentials.
+type Credentials struct {
+	// Token is the local token, granting the operator role. Empty disables it.
+	Token string
+	// APIKeys validates the X-API-Key header, nil when API keys are not accepted.
+	APIKeys APIKeyValidator
+	// BearerTokens validates the bearer tokens other than Token, such as JWTs,
+	// nil when they are not accepted.
+	BearerTokens TokenValidator
+	// Throttle delays the clients sending invalid credentials, nil when they
+	// are not delayed.
+	Throttle LoginThrottle
+	// Optional reports whether the requests may go without credentials, none
+	// being configured yet. nil when they are always required.
+	Optional func(ctx context.Context) (bool, error)
+}
+
+// RequireCredentials returns a gin middleware rejecting the requests that do
+// not send valid credentials: a bearer token in the Authorization header,
+// either the local token or one accepted by BearerTokens, or an API key in the
+// X-API-Key header.
+//
+// Reads (GET and HEAD) require the viewer role and the other methods the
+// operator role: the local token grants operator, an API key or a JWT grants
+// its own role and a viewer is answered 403 on a mutating request. The
+// principal of the request is then available with PrincipalFromContext.
+//
+// A client IP sending credentials while Throttle makes it wait is answered 429
+// with a Retry-After header, without checking them.
+//
+// Requests to exemptPaths, exact paths or prefixes ending with *, and CORS
+// preflights (OPTIONS) are let through, as are the requests received on a unix
+// socket: access to the socket is controlled by its file permissions. Every
+// request is let through while Optional reports so.
+func RequireCredentials(creds Credentials, exemptPaths []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodOptions || matchPath(exemptPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		if creds.Optional != nil {
+			optional, err := creds.Optional(c.Request.Context())
+			if err != nil {
+				zap.S().Named("http").Errorw("failed to validate credentials", "error", err)
+				abortWithError(c, srvErrors.CodeInternal, "failed to validate credentials")
+				return
+			}
+			if optional {
+				c.Next()
+				return
+			}
+		}
+		if addr, ok := c.Request.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == "unix" {
+			c.Set(principalKey, &unixSocketPrincipal)
+			c.Next()
+			return
+		}
+
+		// only the clients sending credentials may be guessing them
+		throttle := creds.Throttle
+		if c.GetHeader("Authorization") == "" && c.GetHeader("X-API-Key") == "" {
+			throttle = nil
+		}
+		if throttle != nil {
+			wait, err := throttle.Wait(c.Request.Context(), c.ClientIP())
+			if err != nil {
+				zap.S().Named("http").Errorw("failed to validate credentials", "error", err)
+				abortWithError(c, srvErrors.CodeInternal, "failed to validate credentials")
+				return
+			}
+			if wait > 0 {
+				zap.S().Named("http").Debugw("credentials throttled", "path", c.Request.URL.Path, "ip", c.ClientIP(), "wait", wait)
+				c.Header("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+				abortWithError(c, srvErrors.CodeRateLimited, "too many invalid credentials, retry later")
+				return
+			}
+		}
+
+		principal, err := authenticate(c, creds)
+		if err != nil {
+			if throttle != nil {
+				throttle.Aborted(c.ClientIP())
+			}
+			zap.S().Named("http").Errorw("failed to validate credentials", "error", err)
+			abortWithError(c, srvErrors.CodeInternal, "failed to validate credentials")
+			return
+		}
+		if throttle != nil {
+			if principal == nil {
+				throttle.Failed(c.Request.Context(), c.ClientIP())
+			} else {
+				throttle.Succeeded(c.Request.Context(), c.ClientIP())
+			}
+		}
+		if principal == nil {
+			zap.S().Named("http").Debugw("request without valid credentials", "method", c.Request.Method, "path", c.Request.URL.Path, "ip", c.ClientIP())
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithError(c, srvErrors.CodeUnauthorized, "a valid bearer token or api key is required")
+			return
+		}
+		required := models.RoleViewer
+		if isMutating(c) {
+			required = models.RoleOperator
+		}
+		if !principal.Role.Allows(required) {
+			abortWithError(c, srvErrors.CodeForbidden, fmt.Sprintf("role %s cannot %s %s", principal.Role, c.Request.Method, c.Request.URL.Path))
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// PrincipalFromContext returns the principal authenticated by
+// RequireCredentials, nil when the request was not authenticated.
+func PrincipalFromContext(c *gin.Context) *models.Principal {
+	principal, _ := c.Value(principalKey).(*models.Principal)
+	return principal
+}
+
+// matchPath reports whether path is one of patterns, or starts with one of the
+// patterns ending with * without it.
+func matchPath(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadOnly returns a gin middleware marking the requests of a route as not
+// changing the state of the agent whatever their method, such as the POSTed
+// GraphQL queries: they need the viewer role and are not audited. It must come
+// before RequireCredentials and Audit.
+func ReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(readOnlyKey, true)
+		c.Next()
+	}
+}
+
+// isMutating reports whether the request may change the state of the agent.
+func isMutating(c *gin.Context) bool {
+	if c.GetBool(readOnlyKey) {
+		return false
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// authenticate returns the principal of the credentials of the request, nil
+// when it has none valid. An API key is tried before the bearer token.
+func authenticate(c *gin.Context, creds Credentials) (*models.Principal, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" && creds.APIKeys != nil {
+		principal, err := creds.APIKeys(c.Request.Context(), key)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+	}
+
+	given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || given == "" {
+		return nil, nil
+	}
+	if creds.Token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(creds.Token)) == 1 {
+		return &tokenPrincipal, nil
+	}
+	if creds.BearerTokens != nil {
+		return creds.BearerTokens(c.Request.Context(), given)
+	}
+	return nil, nil
+}
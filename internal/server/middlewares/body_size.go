@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/util"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// MaxBodySize returns a gin middleware limiting request bodies to maxBytes.
+// Requests announcing a larger Content-Length are rejected with 413 right away,
+// others fail with *http.MaxBytesError when reading past the limit.
+func MaxBodySize(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			abortWithError(c, srvErrors.CodePayloadTooLarge, "request body larger than "+util.FormatSize(maxBytes))
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
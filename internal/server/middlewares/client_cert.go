@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// RequireClientCertificate returns a gin middleware rejecting requests that did not
+// present a client certificate verified against the server's client CAs.
+// Verification itself is done during the TLS handshake, see tls.VerifyClientCertIfGiven.
+// Requests received on a unix socket are let through: access to the socket is
+// controlled by its file permissions.
+func RequireClientCertificate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if addr, ok := c.Request.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == "unix" {
+			c.Next()
+			return
+		}
+
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			zap.S().Named("http").Debugw("request without a verified client certificate", "path", c.Request.URL.Path, "ip", c.ClientIP())
+			abortWithError(c, srvErrors.CodeUnauthorized, "a valid client certificate is required")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS returns a gin middleware answering CORS requests for paths under prefix.
+// It must be installed on the engine rather than on a router group so that preflight
+// OPTIONS requests, which match no route, are answered too.
+// An origin "*" allows any origin. Empty methods or headers fall back to sensible defaults,
+// the credentials and request id headers included. The X-Request-ID response header is exposed.
+func CORS(prefix string, origins, methods, headers []string) (gin.HandlerFunc, error) {
+	config := cors.DefaultConfig()
+	config.AllowHeaders = append(config.AllowHeaders, "Authorization", "X-API-Key", RequestIDHeader)
+	config.ExposeHeaders = append(config.ExposeHeaders, RequestIDHeader)
+
+	if slices.Contains(origins, "*") {
+		config.AllowAllOrigins = true
+	} else {
+		config.AllowOrigins = origins
+	}
+	if len(methods) > 0 {
+		config.AllowMethods = methods
+	}
+	if len(headers) > 0 {
+		config.AllowHeaders = headers
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	handler := cors.New(config)
+
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, prefix) {
+			c.Next()
+			return
+		}
+		handler(c)
+	}, nil
+}
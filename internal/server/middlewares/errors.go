@@ -0,0 +1,13 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// abortWithError aborts the request with the APIError of code and message.
+func abortWithError(c *gin.Context, code srvErrors.Code, message string) {
+	apiErr := srvErrors.NewAPIError(code, message)
+	c.AbortWithStatusJSON(apiErr.HTTPStatus(), apiErr.Response())
+}
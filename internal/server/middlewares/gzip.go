@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// gzipResponseWriter buffers the body until minSize bytes were written, then decides
+// whether to compress it. Bodies smaller than minSize and non-JSON bodies are sent as is.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	// passthrough is set once the body is known not to be compressed
+	passthrough bool
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	switch {
+	case w.gz != nil:
+		return w.gz.Write(data)
+	case w.passthrough:
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() < w.minSize {
+		return len(data), nil
+	}
+
+	if err := w.decide(); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush sends the buffered body, deciding on compression first if not done yet.
+func (w *gzipResponseWriter) Flush() {
+	if w.gz == nil && !w.passthrough {
+		_ = w.decide()
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide starts compressing when the body is JSON, otherwise switches to passthrough,
+// and writes out the buffered bytes.
+func (w *gzipResponseWriter) decide() error {
+	header := w.Header()
+	if strings.HasPrefix(header.Get("Content-Type"), "application/json") && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+
+		w.gz = gzipWriterPool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	} else {
+		w.passthrough = true
+	}
+
+	data := w.buf.Bytes()
+	w.buf.Reset()
+	if len(data) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(data)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+// close sends what is left of the body once the handler returned.
+func (w *gzipResponseWriter) close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+		gzipWriterPool.Put(w.gz)
+		return
+	}
+	if !w.passthrough && w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+}
+
+// Gzip returns a gin middleware compressing JSON responses of at least minSize bytes
+// for clients accepting gzip.
+func Gzip(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		w := &gzipResponseWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = w
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,239 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+const (
+	// jwksRefreshInterval is the minimum time between two fetches of the key set,
+	// so tokens signed by unknown keys or an unreachable provider cannot make the
+	// agent hammer the provider
+	jwksRefreshInterval = time.Minute
+	jwksFetchTimeout    = 10 * time.Second
+)
+
+var errJWKSUnavailable = errors.New("jwks unavailable")
+
+// JWTValidator validates JWTs against the keys served by a JWKS URL and maps
+// a claim of the token to its role.
+type JWTValidator struct {
+	jwksURL   string
+	roleClaim string
+	client    *http.Client
+	parser    *jwt.Parser
+
+	mu        sync.Mutex // protects keys, fetchedAt and fetchErr
+	keys      map[string]any
+	fetchedAt time.Time
+	fetchErr  error // error of the last fetch
+}
+
+// NewJWTValidator returns a validator of the JWTs signed by a key of jwksURL,
+// fetched through proxy, issued by issuer for audience. Both are required: a
+// shared identity provider issues tokens for many clients. roleClaim is the claim holding the role, a dotted path for
+// nested claims such as realm_access.roles.
+func NewJWTValidator(jwksURL, issuer, audience, roleClaim string, proxy func(*http.Request) (*url.URL, error)) *JWTValidator {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+	}
+
+	return &JWTValidator{
+		jwksURL:   jwksURL,
+		roleClaim: roleClaim,
+		client: &http.Client{
+			Timeout:   jwksFetchTimeout,
+			Transport: &http.Transport{Proxy: proxy},
+		},
+		parser: jwt.NewParser(opts...),
+	}
+}
+
+// Validate returns the principal of token, its sub claim with the role of its
+// role claim: operator when it names operator, viewer otherwise. It returns nil
+// when token is not a valid JWT and fails when the key set cannot be fetched.
+func (v *JWTValidator) Validate(ctx context.Context, token string) (*models.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if errors.Is(err, errJWKSUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		zap.S().Named("http").Debugw("invalid jwt", "error", err)
+		return nil, nil
+	}
+	subject, _ := claims.GetSubject()
+	return &models.Principal{Subject: subject, Role: roleFromClaims(claims, v.roleClaim)}, nil
+}
+
+// key returns the key kid of the key set, fetched again when kid is unknown
+// and the last fetch, successful or not, is older than jwksRefreshInterval.
+// Until then a failed fetch fails the unknown keys without waiting for the
+// provider again.
+func (v *JWTValidator) key(ctx context.Context, kid string) (any, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if !v.fetchedAt.IsZero() && time.Since(v.fetchedAt) < jwksRefreshInterval {
+		if v.fetchErr != nil {
+			return nil, fmt.Errorf("%w: %w", errJWKSUnavailable, v.fetchErr)
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	keys, err := v.fetch(ctx)
+	v.fetchedAt = time.Now()
+	v.fetchErr = err
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errJWKSUnavailable, err)
+	}
+	v.keys = keys
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// jwk is a key of a JWKS document, RSA or EC.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch returns the signing keys of the key set by kid. Keys of other types
+// or uses are skipped.
+func (v *JWTValidator) fetch(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, v.jwksURL)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			zap.S().Named("http").Warnw("skipping jwks key", "kid", k.Kid, "error", err)
+			continue
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key of k, nil for an unsupported key type.
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, nil
+}
+
+// roleFromClaims returns operator when the claim at path, a string or a list
+// of strings, names it, and viewer otherwise.
+func roleFromClaims(claims jwt.MapClaims, path string) models.Role {
+	var value any = map[string]any(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return models.RoleViewer
+		}
+		value = m[part]
+	}
+
+	switch v := value.(type) {
+	case string:
+		if models.Role(v) == models.RoleOperator {
+			return models.RoleOperator
+		}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && models.Role(s) == models.RoleOperator {
+				return models.RoleOperator
+			}
+		}
+	}
+	return models.RoleViewer
+}
@@ -1,16 +1,28 @@
 package middlewares
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
 )
 
 // Logger returns a gin middleware that logs HTTP requests using zap logger.
 // It logs request start with requestId and all fields except status, then request end with requestId and status.
 func Logger() gin.HandlerFunc {
+	return LoggerWithAccessLog(nil)
+}
+
+// LoggerWithAccessLog works like Logger and, when accessLog is not nil, also
+// writes one entry per completed request to accessLog for auditing. Both record
+// the latency and response size of the request in the per-route histograms of
+// the metrics package.
+func LoggerWithAccessLog(accessLog *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		// some evil middlewares modify this values
@@ -18,7 +30,10 @@ func Logger() gin.HandlerFunc {
 		query := c.Request.URL.RawQuery
 
 		// Log request start with requestId and current fields (except status)
+		requestID := logger.RequestID(c.Request.Context())
+
 		startFields := []zapcore.Field{
+			zap.String(logger.RequestIDKey, requestID),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
 			zap.String("query", query),
@@ -34,8 +49,10 @@ func Logger() gin.HandlerFunc {
 		// Log request end with requestId and status
 		end := time.Now()
 		latency := end.Sub(start)
+		observeRequest(c, latency)
 
 		endFields := []zapcore.Field{
+			zap.String(logger.RequestIDKey, requestID),
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
@@ -54,5 +71,23 @@ func Logger() gin.HandlerFunc {
 		} else {
 			zap.S().Named("http").Desugar().Info("Request completed", endFields...)
 		}
+
+		if accessLog != nil {
+			accessLog.Info("access", append(endFields, zap.Int("size", c.Writer.Size()))...)
+		}
 	}
 }
+
+// observeRequest records the latency and response size of the request by route,
+// the matched pattern rather than the path so the labels stay bounded.
+func observeRequest(c *gin.Context, latency time.Duration) {
+	route := c.FullPath()
+	if route == "" {
+		route = metrics.UnmatchedRoute
+	}
+	method := c.Request.Method
+
+	metrics.HTTPRequestDuration.WithLabelValues(method, route, fmt.Sprintf("%dxx", c.Writer.Status()/100)).Observe(latency.Seconds())
+	// Size is -1 when nothing was written
+	metrics.HTTPResponseSize.WithLabelValues(method, route).Observe(float64(max(c.Writer.Size(), 0)))
+}
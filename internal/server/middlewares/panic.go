@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PanicReporter reports a panic of a handler, with its stack.
+type PanicReporter func(c *gin.Context, recovered any, stack []byte)
+
+// ReportPanics returns a gin middleware passing the panics of the next
+// handlers to report, then panicking again for the recovery middleware
+// before it to log them and answer 500.
+func ReportPanics(report PanicReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				report(c, rec, debug.Stack())
+				panic(rec)
+			}
+		}()
+		c.Next()
+	}
+}
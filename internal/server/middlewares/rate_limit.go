@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+const (
+	// limiterIdleTimeout is how long the bucket of a silent client is kept.
+	limiterIdleTimeout = 3 * time.Minute
+	// limiterSweepInterval is how often idle buckets are dropped.
+	limiterSweepInterval = time.Minute
+)
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipRateLimiter keeps one token bucket per client IP.
+type ipRateLimiter struct {
+	rps   rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+func (l *ipRateLimiter) allow(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > limiterSweepInterval {
+		for k, c := range l.clients {
+			if now.Sub(c.lastSeen) > limiterIdleTimeout {
+				delete(l.clients, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[ip]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[ip] = c
+	}
+	c.lastSeen = now
+
+	return c.limiter.AllowN(now, 1)
+}
+
+// RateLimit returns a gin middleware limiting each client IP to rps requests per second
+// with bursts of up to burst requests. Requests to exemptPaths are never limited.
+// Limited requests get 429 with a Retry-After header.
+func RateLimit(rps float64, burst int, exemptPaths []string) gin.HandlerFunc {
+	l := &ipRateLimiter{
+		rps:       rate.Limit(rps),
+		burst:     burst,
+		clients:   make(map[string]*clientLimiter),
+		lastSweep: time.Now(),
+	}
+	retryAfter := strconv.Itoa(max(1, int(1/rps)))
+
+	return func(c *gin.Context) {
+		if slices.Contains(exemptPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		if !l.allow(c.ClientIP(), time.Now()) {
+			zap.S().Named("http").Debugw("rate limit exceeded", "path", c.Request.URL.Path, "ip", c.ClientIP())
+			c.Header("Retry-After", retryAfter)
+			abortWithError(c, srvErrors.CodeRateLimited, "rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
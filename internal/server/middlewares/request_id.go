@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+// RequestIDHeader is the header carrying the request id in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID restricts the ids accepted from clients, since they end up in the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID returns a gin middleware assigning an id to every request. A valid X-Request-ID
+// sent by the client is reused, otherwise a new UUID is generated. The id is returned in the
+// X-Request-ID response header and carried by the request context, see logger.FromContext.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
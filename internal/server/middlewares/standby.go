@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Standby reports whether the agent stands by in a high availability pair and
+// the identity of the leader, empty when none holds the lease.
+type Standby func() (standby bool, leader string)
+
+// RejectOnStandby returns a gin middleware answering the mutating requests
+// with 503 STANDBY while the agent stands by. The reads, and the routes marked
+// ReadOnly, are served from its own store.
+func RejectOnStandby(standby Standby) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutating(c) {
+			c.Next()
+			return
+		}
+		if ok, leader := standby(); ok {
+			message := "the agent is the standby of its pair, no agent leads"
+			if leader != "" {
+				message = "the agent is the standby of its pair, send the request to the leader " + leader
+			}
+			abortWithError(c, srvErrors.CodeStandby, message)
+			return
+		}
+		c.Next()
+	}
+}
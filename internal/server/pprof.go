@@ -0,0 +1,22 @@
+package server
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+const debugPprof string = "/debug/pprof"
+
+// registerPprof mounts the net/http/pprof handlers on router.
+// router is expected to be the group mounted at debugPprof, pprof.Index relies on that prefix.
+func registerPprof(router *gin.RouterGroup) {
+	router.GET("/", gin.WrapF(pprof.Index))
+	router.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	router.GET("/profile", gin.WrapF(pprof.Profile))
+	router.GET("/symbol", gin.WrapF(pprof.Symbol))
+	router.POST("/symbol", gin.WrapF(pprof.Symbol))
+	router.GET("/trace", gin.WrapF(pprof.Trace))
+	// named profiles: heap, goroutine, allocs, block, mutex, threadcreate
+	router.GET("/:name", gin.WrapF(pprof.Index))
+}
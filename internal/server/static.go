@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// cacheImmutable is used for the fingerprinted build assets, which never change under the same name.
+	cacheImmutable = "public, max-age=31536000, immutable"
+	// cacheRevalidate makes browsers revalidate with the ETag before reusing their copy.
+	cacheRevalidate = "no-cache"
+)
+
+// precompressed lists the encodings looked up next to a static file, in order of preference.
+var precompressed = []struct {
+	encoding  string
+	extension string
+}{
+	{encoding: "br", extension: ".br"},
+	{encoding: "gzip", extension: ".gz"},
+}
+
+// staticFiles serves the UI files from a folder with cache headers, ETags and
+// pre-compressed variants (name.br, name.gz) when the client accepts them.
+type staticFiles struct {
+	root http.FileSystem
+}
+
+func newStaticFiles(folder string) *staticFiles {
+	return &staticFiles{root: http.Dir(folder)}
+}
+
+// handler serves the file named by the route's filepath parameter under prefix.
+func (s *staticFiles) handler(prefix, cacheControl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.serve(c, path.Join(prefix, c.Param("filepath")), cacheControl)
+	}
+}
+
+// file serves always the same file.
+func (s *staticFiles) file(name, cacheControl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.serve(c, name, cacheControl)
+	}
+}
+
+func (s *staticFiles) serve(c *gin.Context, name, cacheControl string) {
+	name = path.Clean("/" + name)
+
+	served, encoding := name, ""
+	accepted := c.GetHeader("Accept-Encoding")
+	for _, p := range precompressed {
+		if strings.Contains(accepted, p.encoding) && s.isFile(name+p.extension) {
+			served, encoding = name+p.extension, p.encoding
+			break
+		}
+	}
+
+	f, err := s.root.Open(served)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Cache-Control", cacheControl)
+	header.Set("ETag", fmt.Sprintf(`"%x-%x%s"`, info.ModTime().UnixNano(), info.Size(), encoding))
+	header.Add("Vary", "Accept-Encoding")
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+
+	// the content type is detected from the original name, not the .br/.gz one
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), f)
+}
+
+func (s *staticFiles) isFile(name string) bool {
+	f, err := s.root.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	return err == nil && !info.IsDir()
+}
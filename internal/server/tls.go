@@ -0,0 +1,63 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// certificateLoader serves a certificate loaded from a pair of PEM files
+// and allows swapping it without restarting the server.
+type certificateLoader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertificateLoader(certFile, keyFile string) (*certificateLoader, error) {
+	l := &certificateLoader{certFile: certFile, keyFile: keyFile}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// load reads the key pair from disk. The served certificate is left untouched on error.
+func (l *certificateLoader) load() error {
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate from %s and %s: %w", l.certFile, l.keyFile, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cert = &cert
+
+	return nil
+}
+
+func (l *certificateLoader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.cert, nil
+}
+
+// loadClientCAs returns the pool of CAs read from the PEM file caFile.
+func loadClientCAs(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file %s: %w", caFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no PEM certificate found in client CA file " + caFile)
+	}
+
+	return pool, nil
+}
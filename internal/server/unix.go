@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+)
+
+// unixSocketMode restricts the socket to the agent's user and group:
+// access control on the socket is delegated to file permissions.
+const unixSocketMode fs.FileMode = 0o660
+
+// listenUnix listens on the unix socket at path, replacing a stale socket left by a previous run.
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", path, err)
+	}
+
+	if err := os.Chmod(path, unixSocketMode); err != nil {
+		_ = l.Close()
+		return nil, fmt.Errorf("failed to set permissions of unix socket %s: %w", path, err)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+// recentGCPauses is the number of garbage collection pauses in RuntimeStats.
+const recentGCPauses = 10
+
+type AdminService struct {
+	store     *store.Store
+	scheduler *scheduler.Scheduler
+}
+
+func NewAdminService(st *store.Store) *AdminService {
+	return &AdminService{store: st}
+}
+
+// WithScheduler adds the load of sched to the runtime stats.
+func (s *AdminService) WithScheduler(sched *scheduler.Scheduler) *AdminService {
+	s.scheduler = sched
+	return s
+}
+
+// Migrations returns the status of the schema migrations of the agent database.
+func (s *AdminService) Migrations(ctx context.Context) ([]models.Migration, error) {
+	return s.store.Migrations(ctx)
+}
+
+// Runtime returns the goroutines, memory, garbage collector, database
+// connections, scheduler and HTTP routes stats of the agent.
+func (s *AdminService) Runtime() models.RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := models.RuntimeStats{
+		Goroutines: runtime.NumGoroutine(),
+		Memory: models.MemoryStats{
+			HeapAlloc:   mem.HeapAlloc,
+			HeapInuse:   mem.HeapInuse,
+			HeapObjects: mem.HeapObjects,
+			TotalAlloc:  mem.TotalAlloc,
+			Sys:         mem.Sys,
+		},
+		GC: models.GCStats{
+			NumGC:        mem.NumGC,
+			PauseTotal:   time.Duration(mem.PauseTotalNs),
+			RecentPauses: []time.Duration{},
+		},
+	}
+
+	if mem.NumGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		stats.GC.LastGC = &lastGC
+	}
+	// PauseNs is a circular buffer, the last pause being at (NumGC+255)%256
+	for i := uint32(0); i < min(mem.NumGC, recentGCPauses); i++ {
+		stats.GC.RecentPauses = append(stats.GC.RecentPauses, time.Duration(mem.PauseNs[(mem.NumGC-1-i)%uint32(len(mem.PauseNs))]))
+	}
+
+	db := s.store.DB().Stats()
+	stats.Database = models.DatabaseConnections{
+		Open:         db.OpenConnections,
+		InUse:        db.InUse,
+		Idle:         db.Idle,
+		WaitCount:    db.WaitCount,
+		WaitDuration: db.WaitDuration,
+	}
+
+	if s.scheduler != nil {
+		sched := s.scheduler.Stats()
+		stats.Scheduler = models.SchedulerStats{Workers: sched.Workers, Busy: sched.Busy, Queued: sched.Queued}
+	}
+
+	stats.Routes = []models.RouteStats{}
+	for _, r := range metrics.Routes() {
+		stats.Routes = append(stats.Routes, models.RouteStats(r))
+	}
+
+	return stats
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"database/sql"
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("AdminService", func() {
+	var (
+		db    *sql.DB
+		sched *scheduler.Scheduler
+		srv   *services.AdminService
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		sched = scheduler.NewScheduler(2)
+		srv = services.NewAdminService(store.NewStore(db, test.NewMockValidator())).WithScheduler(sched)
+	})
+
+	AfterEach(func() {
+		sched.Close()
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a garbage collection
+	// When we get the runtime stats
+	// Then they should describe the process, its database connections and its scheduler
+	It("should return the runtime stats", func() {
+		// Arrange
+		runtime.GC()
+
+		// Act
+		stats := srv.Runtime()
+
+		// Assert
+		Expect(stats.Goroutines).To(BeNumerically(">", 0))
+		Expect(stats.Memory.HeapAlloc).To(BeNumerically(">", 0))
+		Expect(stats.GC.NumGC).To(BeNumerically(">", 0))
+		Expect(stats.GC.LastGC).NotTo(BeNil())
+		Expect(stats.GC.RecentPauses).NotTo(BeEmpty())
+		Expect(len(stats.GC.RecentPauses)).To(BeNumerically("<=", 10))
+		Expect(stats.Database.Open).To(Equal(1))
+		Expect(stats.Scheduler.Workers).To(Equal(2))
+	})
+})
@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("APIKeyService", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		st  *store.Store
+		srv *services.APIKeyService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, test.NewMockValidator())
+		srv = services.NewAPIKeyService(st)
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a new key
+	// When we validate it and a made up key
+	// Then only the created key should be valid
+	It("validates the keys it created", func() {
+		// Act
+		key, secret, err := srv.Create(ctx, "backup", models.RoleViewer)
+		Expect(err).NotTo(HaveOccurred())
+		valid, validErr := srv.Validate(ctx, secret)
+		invalid, invalidErr := srv.Validate(ctx, secret+"x")
+
+		// Assert
+		Expect(validErr).NotTo(HaveOccurred())
+		Expect(valid).To(Equal(&models.Principal{Subject: "apikey:backup", Role: models.RoleViewer}))
+		Expect(invalidErr).NotTo(HaveOccurred())
+		Expect(invalid).To(BeNil())
+		Expect(strings.HasPrefix(secret, key.Prefix)).To(BeTrue())
+		Expect(key.Prefix).To(HavePrefix("ama_"))
+	})
+
+	// Given a new key
+	// When we list the keys
+	// Then only its hash should be stored
+	It("does not store the key", func() {
+		// Act
+		_, secret, err := srv.Create(ctx, "backup", models.RoleViewer)
+		Expect(err).NotTo(HaveOccurred())
+		keys, err := srv.List(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(1))
+		Expect(keys[0].Hash).NotTo(BeEmpty())
+		Expect(keys[0].Hash).NotTo(ContainSubstring(secret))
+	})
+
+	// Given a revoked key
+	// When we validate it
+	// Then it should be rejected
+	It("rejects revoked keys", func() {
+		// Arrange
+		key, secret, err := srv.Create(ctx, "backup", models.RoleViewer)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		Expect(srv.Revoke(ctx, key.ID)).To(Succeed())
+		principal, err := srv.Validate(ctx, secret)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(principal).To(BeNil())
+	})
+
+	// Given keys created and revoked in turn
+	// When we check whether a key exists
+	// Then only the keys not revoked should count
+	It("reports whether a key exists", func() {
+		// Arrange
+		before, err := srv.Exist(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		key, _, err := srv.Create(ctx, "backup", models.RoleViewer)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		created, err := srv.Exist(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(srv.Revoke(ctx, key.ID)).To(Succeed())
+		revoked, err := srv.Exist(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Assert
+		Expect(before).To(BeFalse())
+		Expect(created).To(BeTrue())
+		Expect(revoked).To(BeFalse())
+	})
+
+	// Given an empty name
+	// When we create a key
+	// Then it should fail
+	It("requires a name", func() {
+		// Act
+		_, _, err := srv.Create(ctx, "", models.RoleViewer)
+
+		// Assert
+		Expect(err).To(MatchError("api key name is required"))
+	})
+
+	// Given an unknown role
+	// When we create a key
+	// Then it should fail
+	It("requires a known role", func() {
+		// Act
+		_, _, err := srv.Create(ctx, "backup", models.Role("admin"))
+
+		// Assert
+		Expect(err).To(MatchError(`invalid api key role "admin": must be viewer or operator`))
+	})
+})
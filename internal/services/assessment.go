@@ -0,0 +1,402 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// The files of an assessment bundle.
+const (
+	assessmentManifestFile    = "manifest.json"
+	assessmentSignatureFile   = "manifest.sig"
+	assessmentInventoryFile   = "inventory.json"
+	assessmentInspectionsFile = "inspections.json"
+	assessmentEventsFile      = "events.json"
+)
+
+// The uncompressed size limits of a bundle, read in memory: the compressed
+// size of the bundles says nothing of the size of their content.
+const (
+	maxAssessmentFileSize  = 512 << 20 // 512Mb
+	maxAssessmentTotalSize = 1 << 30   // 1Gb
+)
+
+// AssessmentConsoleClient sends the inventory of an imported assessment to the
+// console.
+type AssessmentConsoleClient interface {
+	UpdateSourceStatus(ctx context.Context, sourceID, agentID uuid.UUID, inventory *models.Inventory) error
+}
+
+// bundleInspection is an entry of inspections.json.
+type bundleInspection struct {
+	VMID  string `json:"vmId"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// AssessmentService exports the assessment of the agent, its inventory,
+// inspections and events, as a signed archive carried to a connected agent,
+// or to the console, from an air-gapped environment. It imports the archives
+// of the other agents, sending their inventory to the console.
+type AssessmentService struct {
+	store    *store.Store
+	key      ed25519.PrivateKey
+	agentID  string
+	sourceID string
+	version  string
+	console  consoleStatus
+	trusted  []string
+	jobs     *JobService
+
+	clientMu sync.RWMutex // protects client, replaced by a configuration reload
+	client   AssessmentConsoleClient
+}
+
+// NewAssessmentService returns the service exporting the assessment of the
+// agent agentID, of the source sourceID, signed with key. The bundles are only
+// imported while console is in connected mode.
+func NewAssessmentService(st *store.Store, key ed25519.PrivateKey, agentID, sourceID, version string, console consoleStatus, client AssessmentConsoleClient) *AssessmentService {
+	return &AssessmentService{
+		store:    st,
+		key:      key,
+		agentID:  agentID,
+		sourceID: sourceID,
+		version:  version,
+		console:  console,
+		client:   client,
+	}
+}
+
+// WithJobService follows each export as a job of jobs.
+func (s *AssessmentService) WithJobService(jobs *JobService) *AssessmentService {
+	s.jobs = jobs
+	return s
+}
+
+// WithTrustedKeys sets the fingerprints of the keys whose bundles are
+// imported. No bundle is imported without one.
+func (s *AssessmentService) WithTrustedKeys(fingerprints []string) *AssessmentService {
+	s.trusted = fingerprints
+	return s
+}
+
+// SetClient replaces the client sending the imported inventories.
+func (s *AssessmentService) SetClient(client AssessmentConsoleClient) {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+	s.client = client
+}
+
+// Fingerprint returns the fingerprint of the key signing the bundles, given to
+// the importers to check the bundles come from this agent.
+func (s *AssessmentService) Fingerprint() string {
+	return keyFingerprint(s.key.Public().(ed25519.PublicKey))
+}
+
+// Export writes the assessment bundle of the agent to w as a tar.gz archive.
+// It fails with a ResourceNotFoundError before the first collection.
+func (s *AssessmentService) Export(ctx context.Context, w io.Writer) error {
+	job := s.jobs.Start(ctx, models.JobKindAssessmentExport)
+	if err := s.export(ctx, w); err != nil {
+		s.jobs.Finish(ctx, job, models.JobStateFailed, err)
+		return err
+	}
+	s.jobs.Finish(ctx, job, models.JobStateCompleted, nil)
+	return nil
+}
+
+func (s *AssessmentService) export(ctx context.Context, w io.Writer) error {
+	inventory, err := s.store.Inventory().Get(ctx)
+	if err != nil {
+		return err
+	}
+	inspections, err := s.inspections(ctx)
+	if err != nil {
+		return err
+	}
+	events, err := s.store.AgentEvent().List(ctx, models.AgentEventFilter{}, models.Cursor{})
+	if err != nil {
+		return fmt.Errorf("listing the events: %w", err)
+	}
+	eventsData, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{assessmentInventoryFile, inventory.Data},
+		{assessmentInspectionsFile, inspections},
+		{assessmentEventsFile, eventsData},
+	}
+
+	manifest := models.AssessmentManifest{
+		Version:      models.AssessmentBundleVersion,
+		AgentID:      s.agentID,
+		SourceID:     s.sourceID,
+		AgentVersion: s.version,
+		CreatedAt:    time.Now().UTC(),
+		CollectedAt:  inventory.UpdatedAt.UTC(),
+		PublicKey:    base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey)),
+	}
+	for _, f := range files {
+		sum := sha256.Sum256(f.content)
+		manifest.Files = append(manifest.Files, models.AssessmentFile{
+			Name:   f.name,
+			Size:   int64(len(f.content)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, manifestData))
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeBundleFile(tw, assessmentManifestFile, manifestData, manifest.CreatedAt); err != nil {
+		return err
+	}
+	if err := writeBundleFile(tw, assessmentSignatureFile, []byte(signature+"\n"), manifest.CreatedAt); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := writeBundleFile(tw, f.name, f.content, manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing the assessment archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// inspections returns inspections.json, the inspection state of the VMs.
+func (s *AssessmentService) inspections(ctx context.Context) ([]byte, error) {
+	statuses, err := s.store.Inspection().List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing the inspections: %w", err)
+	}
+	inspections := make([]bundleInspection, 0, len(statuses))
+	for vmID, status := range statuses {
+		inspections = append(inspections, bundleInspection{VMID: vmID, State: string(status.State), Error: errorString(status.Error)})
+	}
+	slices.SortFunc(inspections, func(a, b bundleInspection) int { return strings.Compare(a.VMID, b.VMID) })
+	return json.MarshalIndent(inspections, "", "  ")
+}
+
+// Import verifies the bundle read from r, signed by a trusted key, the one of
+// fingerprint when it is not empty, and sends its inventory to the console as
+// the one of the agent and source which exported it.
+func (s *AssessmentService) Import(ctx context.Context, r io.Reader, fingerprint string) (*models.AssessmentBundle, error) {
+	if s.console.Status().Target != models.ConsoleStatusConnected {
+		return nil, srvErrors.NewModeConflictError("the agent must be in connected mode to import an assessment")
+	}
+
+	trusted := s.trusted
+	if fingerprint != "" {
+		if !containsFingerprint(trusted, fingerprint) {
+			return nil, srvErrors.NewInvalidBundleError("%s is not a trusted key", fingerprint)
+		}
+		trusted = []string{fingerprint}
+	}
+	bundle, err := ReadAssessmentBundle(r, trusted)
+	if err != nil {
+		return nil, err
+	}
+
+	s.clientMu.RLock()
+	client := s.client
+	s.clientMu.RUnlock()
+	if err := SendAssessment(ctx, client, bundle); err != nil {
+		return nil, err
+	}
+
+	zap.S().Named("assessment_service").Infow("assessment imported", "agent_id", bundle.Manifest.AgentID,
+		"source_id", bundle.Manifest.SourceID, "fingerprint", bundle.Fingerprint)
+	return bundle, nil
+}
+
+// SendAssessment sends the inventory of bundle to the console with client, as
+// the one of the agent and source which exported it.
+func SendAssessment(ctx context.Context, client AssessmentConsoleClient, bundle *models.AssessmentBundle) error {
+	agentID, err := uuid.Parse(bundle.Manifest.AgentID)
+	if err != nil {
+		return srvErrors.NewInvalidBundleError("invalid agent id %q", bundle.Manifest.AgentID)
+	}
+	sourceID, err := uuid.Parse(bundle.Manifest.SourceID)
+	if err != nil {
+		return srvErrors.NewInvalidBundleError("invalid source id %q", bundle.Manifest.SourceID)
+	}
+	return client.UpdateSourceStatus(ctx, sourceID, agentID, bundle.Inventory)
+}
+
+// ReadAssessmentBundle reads the tar.gz archive of r and verifies it: its
+// manifest must be signed by the key it holds, whose fingerprint is one of
+// trusted, and list exactly the other files with their digest. It fails with
+// an InvalidBundleError.
+func ReadAssessmentBundle(r io.Reader, trusted []string) (*models.AssessmentBundle, error) {
+	if len(trusted) == 0 {
+		return nil, srvErrors.NewInvalidBundleError("no trusted key to verify the bundle with")
+	}
+
+	files, err := readBundleFiles(r)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, ok := files[assessmentManifestFile]
+	if !ok {
+		return nil, srvErrors.NewInvalidBundleError("no %s", assessmentManifestFile)
+	}
+	signatureData, ok := files[assessmentSignatureFile]
+	if !ok {
+		return nil, srvErrors.NewInvalidBundleError("no %s", assessmentSignatureFile)
+	}
+
+	var manifest models.AssessmentManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, srvErrors.NewInvalidBundleError("invalid %s: %v", assessmentManifestFile, err)
+	}
+	if manifest.Version != models.AssessmentBundleVersion {
+		return nil, srvErrors.NewInvalidBundleError("unsupported version %d", manifest.Version)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(manifest.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, srvErrors.NewInvalidBundleError("invalid public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signatureData)))
+	if err != nil || !ed25519.Verify(key, manifestData, signature) {
+		return nil, srvErrors.NewInvalidBundleError("the signature of %s does not match", assessmentManifestFile)
+	}
+	signer := keyFingerprint(key)
+	if !containsFingerprint(trusted, signer) {
+		return nil, srvErrors.NewInvalidBundleError("signed by %s, not a trusted key", signer)
+	}
+
+	listed := map[string]bool{assessmentManifestFile: true, assessmentSignatureFile: true}
+	for _, f := range manifest.Files {
+		if listed[f.Name] {
+			return nil, srvErrors.NewInvalidBundleError("%s is listed twice", f.Name)
+		}
+		content, ok := files[f.Name]
+		if !ok {
+			return nil, srvErrors.NewInvalidBundleError("missing %s", f.Name)
+		}
+		sum := sha256.Sum256(content)
+		if int64(len(content)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, srvErrors.NewInvalidBundleError("%s does not match the manifest", f.Name)
+		}
+		listed[f.Name] = true
+	}
+	for name := range files {
+		if !listed[name] {
+			return nil, srvErrors.NewInvalidBundleError("%s is not in the manifest", name)
+		}
+	}
+	inventory, ok := files[assessmentInventoryFile]
+	if !ok {
+		return nil, srvErrors.NewInvalidBundleError("no %s", assessmentInventoryFile)
+	}
+
+	return &models.AssessmentBundle{
+		Manifest:    manifest,
+		Fingerprint: signer,
+		Inventory:   &models.Inventory{Data: inventory, CreatedAt: manifest.CollectedAt, UpdatedAt: manifest.CollectedAt},
+	}, nil
+}
+
+// readBundleFiles returns the regular files of the tar.gz archive of r by name,
+// failing once a file or all of them exceed their size limit.
+func readBundleFiles(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, bundleReadError(err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, bundleReadError(err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, srvErrors.NewInvalidBundleError("%s is not a regular file", header.Name)
+		}
+		if _, ok := files[header.Name]; ok {
+			return nil, srvErrors.NewInvalidBundleError("%s is duplicated", header.Name)
+		}
+		// the sizes are checked on the headers before inflating the files, then
+		// on what is read
+		if header.Size > maxAssessmentFileSize {
+			return nil, srvErrors.NewInvalidBundleError("%s is larger than %d bytes", header.Name, maxAssessmentFileSize)
+		}
+		if total+header.Size > maxAssessmentTotalSize {
+			return nil, srvErrors.NewInvalidBundleError("the bundle is larger than %d bytes", maxAssessmentTotalSize)
+		}
+		var content bytes.Buffer
+		n, err := io.Copy(&content, io.LimitReader(tr, maxAssessmentFileSize+1))
+		if err != nil {
+			return nil, bundleReadError(err)
+		}
+		if n > maxAssessmentFileSize {
+			return nil, srvErrors.NewInvalidBundleError("%s is larger than %d bytes", header.Name, maxAssessmentFileSize)
+		}
+		if total += n; total > maxAssessmentTotalSize {
+			return nil, srvErrors.NewInvalidBundleError("the bundle is larger than %d bytes", maxAssessmentTotalSize)
+		}
+		files[header.Name] = content.Bytes()
+	}
+}
+
+// bundleReadError returns the error of a bundle failing to be read: an
+// InvalidBundleError, but for the errors of the reader such as a body too large.
+func bundleReadError(err error) error {
+	if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) || errors.Is(err, tar.ErrHeader) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return srvErrors.NewInvalidBundleError("not a tar.gz archive: %v", err)
+	}
+	return err
+}
+
+// containsFingerprint reports whether fingerprint is one of fingerprints.
+func containsFingerprint(fingerprints []string, fingerprint string) bool {
+	return slices.ContainsFunc(fingerprints, func(f string) bool { return strings.EqualFold(f, fingerprint) })
+}
+
+// keyFingerprint returns the hex encoded SHA-256 of key.
+func keyFingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,299 @@
+package services_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+// recordingStatusClient records the inventories sent to the console.
+type recordingStatusClient struct {
+	sourceID, agentID uuid.UUID
+	inventory         *models.Inventory
+	err               error
+}
+
+func (c *recordingStatusClient) UpdateSourceStatus(_ context.Context, sourceID, agentID uuid.UUID, inventory *models.Inventory) error {
+	c.sourceID, c.agentID, c.inventory = sourceID, agentID, inventory
+	return c.err
+}
+
+var _ = Describe("AssessmentService", func() {
+	const (
+		agentID  = "550e8400-e29b-41d4-a716-446655440000"
+		sourceID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	)
+
+	var (
+		ctx    context.Context
+		db     *sql.DB
+		st     *store.Store
+		client *recordingStatusClient
+		srv    *services.AssessmentService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+
+		_, key, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		client = &recordingStatusClient{}
+		srv = services.NewAssessmentService(st, key, agentID, sourceID, "v1.2.0",
+			stubConsole{status: models.ConsoleStatus{Current: models.ConsoleStatusConnected, Target: models.ConsoleStatusConnected}},
+			client)
+		srv.WithTrustedKeys([]string{srv.Fingerprint()})
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// export returns the bundle of an agent with an inventory, an inspection and an event.
+	export := func() []byte {
+		Expect(st.Inventory().Save(ctx, []byte(`{"vms": [{"name": "vm1"}]}`))).To(Succeed())
+		Expect(fixtures.Insert(ctx, db, fixtures.NewVM("vm-1").WithName("vm1").WithPowerState("poweredOn"))).To(Succeed())
+		Expect(st.Inspection().Add(ctx, []string{"vm-1"}, models.InspectionStatePending)).To(Succeed())
+		services.NewEventService(st).Publish(ctx, models.AgentEventCollectionStarted, "collection started", nil)
+
+		var out bytes.Buffer
+		Expect(srv.Export(ctx, &out)).To(Succeed())
+		return out.Bytes()
+	}
+
+	// rewrite returns the bundle data with its files changed by edit.
+	rewrite := func(data []byte, edit func(files map[string][]byte)) []byte {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		tr := tar.NewReader(gz)
+		files := map[string][]byte{}
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			content, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			files[hdr.Name] = content
+			names = append(names, hdr.Name)
+		}
+		edit(files)
+
+		var out bytes.Buffer
+		gw := gzip.NewWriter(&out)
+		tw := tar.NewWriter(gw)
+		for _, name := range names {
+			if content, ok := files[name]; ok {
+				Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content))})).To(Succeed())
+				_, err := tw.Write(content)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+		Expect(tw.Close()).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+		return out.Bytes()
+	}
+
+	// Given an agent with a collected inventory
+	// When its assessment is exported and imported with the fingerprint of its key
+	// Then the inventory should be sent to the console as the one of the exporting agent
+	It("should import an exported assessment", func() {
+		// Arrange
+		data := export()
+
+		// Act
+		bundle, err := srv.Import(ctx, bytes.NewReader(data), srv.Fingerprint())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bundle.Manifest.AgentID).To(Equal(agentID))
+		Expect(bundle.Manifest.AgentVersion).To(Equal("v1.2.0"))
+		Expect(bundle.Manifest.Files).To(HaveLen(3))
+		Expect(bundle.Fingerprint).To(Equal(srv.Fingerprint()))
+		Expect(bundle.Manifest.Files[1].Name).To(Equal("inspections.json"))
+		Expect(client.agentID.String()).To(Equal(agentID))
+		Expect(client.sourceID.String()).To(Equal(sourceID))
+		Expect(string(client.inventory.Data)).To(MatchJSON(`{"vms": [{"name": "vm1"}]}`))
+	})
+
+	// Given an agent without inventory
+	// When its assessment is exported
+	// Then a ResourceNotFoundError should be returned
+	It("should not export before the first collection", func() {
+		// Act
+		err := srv.Export(ctx, io.Discard)
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+
+	// Given an AssessmentService following its exports as jobs
+	// When an assessment is exported before and after the first collection
+	// Then a failed then a completed assessment-export job should be recorded
+	It("should follow the exports as jobs", func() {
+		// Arrange
+		jobs := services.NewJobService(st)
+		srv.WithJobService(jobs)
+		Expect(srv.Export(ctx, io.Discard)).NotTo(Succeed())
+
+		// Act
+		export()
+
+		// Assert
+		page, err := jobs.List(ctx, models.JobFilter{Kinds: []models.JobKind{models.JobKindAssessmentExport}}, models.NewCursor(1, 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Items).To(HaveLen(2))
+		states := []models.JobState{page.Items[0].State, page.Items[1].State}
+		Expect(states).To(ConsistOf(models.JobStateCompleted, models.JobStateFailed))
+		for _, job := range page.Items {
+			Expect(job.FinishedAt).NotTo(BeNil())
+		}
+	})
+
+	// Given exported bundles altered after their export
+	// When they are read
+	// Then an InvalidBundleError should be returned and nothing sent
+	It("should reject the tampered bundles", func() {
+		// Arrange
+		data := export()
+		tampered := map[string][]byte{
+			"modified inventory": rewrite(data, func(files map[string][]byte) {
+				files["inventory.json"] = []byte(`{"vms": []}`)
+			}),
+			"missing events": rewrite(data, func(files map[string][]byte) {
+				delete(files, "events.json")
+			}),
+			"modified manifest": rewrite(data, func(files map[string][]byte) {
+				files["manifest.json"] = bytes.Replace(files["manifest.json"], []byte("v1.2.0"), []byte("v1.3.0"), 1)
+			}),
+			"not an archive": []byte("inventory"),
+		}
+
+		for name, bundle := range tampered {
+			// Act
+			_, err := srv.Import(ctx, bytes.NewReader(bundle), "")
+
+			// Assert
+			Expect(srvErrors.IsInvalidBundleError(err)).To(BeTrue(), name)
+		}
+		Expect(client.inventory).To(BeNil())
+	})
+
+	// Given a bundle signed by another key
+	// When it is read with the fingerprint of the expected key
+	// Then an InvalidBundleError should be returned
+	It("should reject the bundles of another key", func() {
+		// Arrange
+		data := export()
+
+		// Act
+		_, err := services.ReadAssessmentBundle(bytes.NewReader(data), []string{"0123abcd"})
+
+		// Assert
+		Expect(srvErrors.IsInvalidBundleError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("not a trusted key"))
+	})
+
+	// Given a bundle validly signed by a key the agent does not trust
+	// When it is imported, with or without the fingerprint of that key
+	// Then an InvalidBundleError should be returned and nothing sent
+	It("should reject the bundles signed by an untrusted key", func() {
+		// Arrange
+		export()
+		_, key, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		other := services.NewAssessmentService(st, key, agentID, sourceID, "v1.2.0", stubConsole{}, client)
+		var data bytes.Buffer
+		Expect(other.Export(ctx, &data)).To(Succeed())
+
+		// Act
+		_, err = srv.Import(ctx, bytes.NewReader(data.Bytes()), "")
+		_, errWithFingerprint := srv.Import(ctx, bytes.NewReader(data.Bytes()), other.Fingerprint())
+
+		// Assert
+		Expect(srvErrors.IsInvalidBundleError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("not a trusted key"))
+		Expect(srvErrors.IsInvalidBundleError(errWithFingerprint)).To(BeTrue())
+		Expect(client.inventory).To(BeNil())
+	})
+
+	// Given an agent trusting no key
+	// When one of its own bundles is imported
+	// Then an InvalidBundleError should be returned
+	It("should not import without trusted keys", func() {
+		// Arrange
+		data := export()
+		srv.WithTrustedKeys(nil)
+
+		// Act
+		_, err := srv.Import(ctx, bytes.NewReader(data), "")
+
+		// Assert
+		Expect(srvErrors.IsInvalidBundleError(err)).To(BeTrue())
+		Expect(client.inventory).To(BeNil())
+	})
+
+	// Given a small archive holding a file inflating past the size limit
+	// When it is read
+	// Then an InvalidBundleError should be returned without inflating it
+	It("should reject the files too large once inflated", func() {
+		// Arrange
+		var data bytes.Buffer
+		gw := gzip.NewWriter(&data)
+		tw := tar.NewWriter(gw)
+		Expect(tw.WriteHeader(&tar.Header{Name: "inventory.json", Mode: 0o600, Size: 1 << 40})).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+
+		// Act
+		_, err := services.ReadAssessmentBundle(&data, []string{srv.Fingerprint()})
+
+		// Assert
+		Expect(srvErrors.IsInvalidBundleError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("inventory.json is larger than"))
+	})
+
+	// Given an agent in disconnected mode
+	// When a bundle is imported
+	// Then a ModeConflictError should be returned
+	It("should only import in connected mode", func() {
+		// Arrange
+		data := export()
+		_, key, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewAssessmentService(st, key, agentID, sourceID, "v1.2.0",
+			stubConsole{status: models.ConsoleStatus{Current: models.ConsoleStatusDisconnected, Target: models.ConsoleStatusDisconnected}},
+			client)
+
+		// Act
+		_, err = srv.Import(ctx, bytes.NewReader(data), "")
+
+		// Assert
+		Expect(srvErrors.IsModeConflictError(err)).To(BeTrue())
+		Expect(client.inventory).To(BeNil())
+	})
+})
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+type AuditService struct {
+	store *store.Store
+}
+
+func NewAuditService(st *store.Store) *AuditService {
+	return &AuditService{store: st}
+}
+
+// Record stores entry. A failure is logged and does not fail the request
+// being audited, already served.
+func (s *AuditService) Record(ctx context.Context, entry models.AuditEntry) {
+	// the entry is stored even when the client went away
+	if err := s.store.Audit().Insert(context.WithoutCancel(ctx), entry); err != nil {
+		zap.S().Named("audit_service").Errorw("failed to record audit entry",
+			"subject", entry.Subject,
+			"method", entry.Method,
+			"path", entry.Path,
+			"error", err,
+		)
+	}
+}
+
+// List returns the page at cursor of the entries, newest first.
+func (s *AuditService) List(ctx context.Context, cursor models.Cursor) (models.Page[models.AuditEntry], error) {
+	entries, err := s.store.Audit().List(ctx, cursor)
+	if err != nil {
+		return models.Page[models.AuditEntry]{}, err
+	}
+	total, err := s.store.Audit().Count(ctx)
+	if err != nil {
+		return models.Page[models.AuditEntry]{}, err
+	}
+	return models.NewPage(entries, cursor, total), nil
+}
@@ -0,0 +1,58 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("AuditService", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		srv *services.AuditService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st := store.NewStore(db, test.NewMockValidator())
+		srv = services.NewAuditService(st)
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a request recorded with a canceled context
+	// When we list the audit log
+	// Then the entry should have been stored
+	It("records entries after the request is done", func() {
+		// Arrange
+		reqCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		// Act
+		srv.Record(reqCtx, models.AuditEntry{Time: time.Now(), Subject: "token", Role: models.RoleOperator, Method: "POST", Path: "/api/v1/collector", Status: 202})
+		entries, err := srv.List(ctx, models.NewCursor(1, 10))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries.Total).To(Equal(1))
+		Expect(entries.Items).To(HaveLen(1))
+		Expect(entries.Items[0].Subject).To(Equal("token"))
+	})
+})
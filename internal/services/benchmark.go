@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/benchmark"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+)
+
+const (
+	// DefaultBenchmarkDuration bounds each measure of a benchmark when the
+	// request does not.
+	DefaultBenchmarkDuration = 10 * time.Second
+	// benchmarkMaxBytes bounds the bytes of each measure, so a fast datastore
+	// is not read for the whole duration.
+	benchmarkMaxBytes = 4 << 30
+)
+
+// BenchmarkConnector connects to the datastores of the vCenter of creds.
+type BenchmarkConnector func(ctx context.Context, creds *models.Credentials) (models.DatastoreReader, error)
+
+// BenchmarkService runs the benchmark jobs, which measure the read throughput
+// of the source datastores and the network throughput toward a target
+// endpoint. The latest benchmark gives the transfer rate of the estimates of
+// the migration plans. A single benchmark runs at a time, canceled through its
+// job.
+type BenchmarkService struct {
+	store   *store.Store
+	proxy   func(*http.Request) (*url.URL, error)
+	connect BenchmarkConnector
+	// jobs follows each benchmark as a job, nil when they are not followed
+	jobs *JobService
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan any
+}
+
+func NewBenchmarkService(st *store.Store) *BenchmarkService {
+	s := &BenchmarkService{store: st}
+	s.connect = s.connectVSphere
+	return s
+}
+
+// WithProxy sets the proxy of the requests to the vCenter.
+func (s *BenchmarkService) WithProxy(proxy func(*http.Request) (*url.URL, error)) *BenchmarkService {
+	s.proxy = proxy
+	return s
+}
+
+// WithJobService follows each benchmark as a job of jobs.
+func (s *BenchmarkService) WithJobService(jobs *JobService) *BenchmarkService {
+	s.jobs = jobs
+	return s
+}
+
+// WithConnector replaces the connection to the vCenter datastores.
+func (s *BenchmarkService) WithConnector(connect BenchmarkConnector) *BenchmarkService {
+	s.connect = connect
+	return s
+}
+
+// Start connects to the vCenter of req and runs the benchmark in the
+// background, returning the id of its job. It fails with a
+// BenchmarkInProgressError while another one runs, with a VCenterError when
+// the vCenter cannot be reached.
+func (s *BenchmarkService) Start(ctx context.Context, req models.BenchmarkRequest) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		return "", srvErrors.NewBenchmarkInProgressError()
+	}
+
+	reader, err := s.connect(ctx, req.Credentials)
+	if err != nil {
+		return "", srvErrors.NewVCenterError(err)
+	}
+
+	if req.Duration <= 0 {
+		req.Duration = DefaultBenchmarkDuration
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan any)
+	s.cancel, s.done = cancel, done
+
+	job := s.jobs.Start(ctx, models.JobKindBenchmark)
+	go func() {
+		defer close(done)
+		s.run(runCtx, job, reader, req)
+
+		s.mu.Lock()
+		s.cancel, s.done = nil, nil
+		s.mu.Unlock()
+	}()
+
+	return job, nil
+}
+
+// Stop cancels the running benchmark, whose measures are dropped, and returns
+// once it stopped. It does nothing when none runs.
+func (s *BenchmarkService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Latest returns the newest benchmark, a ResourceNotFoundError when none ran.
+func (s *BenchmarkService) Latest(ctx context.Context) (*models.Benchmark, error) {
+	return s.store.Benchmark().Latest(ctx)
+}
+
+func (s *BenchmarkService) run(ctx context.Context, job string, reader models.DatastoreReader, req models.BenchmarkRequest) {
+	log := zap.S().Named("benchmark_service")
+	defer func() {
+		if err := reader.Close(context.WithoutCancel(ctx)); err != nil {
+			log.Warnw("failed to close the vCenter session", "error", err)
+		}
+	}()
+
+	result := models.Benchmark{ID: job, CreatedAt: time.Now().UTC()}
+	if result.ID == "" {
+		result.ID = uuid.New().String()
+	}
+	limits := benchmark.Limits{Bytes: benchmarkMaxBytes, Duration: req.Duration}
+
+	datastores := req.Datastores
+	if len(datastores) == 0 {
+		var err error
+		if datastores, err = reader.Datastores(ctx); err != nil {
+			s.finish(ctx, job, fmt.Errorf("failed to list the datastores: %w", err))
+			return
+		}
+	}
+
+	steps := len(datastores)
+	if req.Target != "" {
+		steps++
+	}
+	for i, name := range datastores {
+		s.jobs.Progress(ctx, job, "datastore "+name, i*100/steps)
+		measure := readDatastore(ctx, reader, name, limits)
+		if ctx.Err() != nil {
+			s.finish(ctx, job, ctx.Err())
+			return
+		}
+		result.Datastores = append(result.Datastores, measure)
+	}
+
+	if req.Target != "" {
+		s.jobs.Progress(ctx, job, "network", len(datastores)*100/steps)
+		// zeros are sent, the certificate of an https target is not verified
+		res, err := benchmark.Send(ctx, req.Target, limits, true, nil)
+		if ctx.Err() != nil {
+			s.finish(ctx, job, ctx.Err())
+			return
+		}
+		measure := newThroughputMeasure(req.Target, res, err)
+		result.Network = &measure
+	}
+
+	if err := s.store.Benchmark().Create(context.WithoutCancel(ctx), result); err != nil {
+		s.finish(ctx, job, fmt.Errorf("failed to store the benchmark: %w", err))
+		return
+	}
+	log.Infow("benchmark completed", "throughput_mbps", result.ThroughputMBps())
+	s.finish(ctx, job, nil)
+}
+
+// finish ends the job, canceled when ctx was.
+func (s *BenchmarkService) finish(ctx context.Context, job string, err error) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		s.jobs.Finish(ctx, job, models.JobStateCanceled, nil)
+	case err != nil:
+		zap.S().Named("benchmark_service").Errorw("benchmark failed", "error", err)
+		s.jobs.Finish(ctx, job, models.JobStateFailed, err)
+	default:
+		s.jobs.Finish(ctx, job, models.JobStateCompleted, nil)
+	}
+}
+
+func (s *BenchmarkService) connectVSphere(ctx context.Context, creds *models.Credentials) (models.DatastoreReader, error) {
+	client, err := vmware.NewVsphereClient(ctx, creds.URL, creds.Username, creds.Password, true, s.proxy)
+	if err != nil {
+		return nil, err
+	}
+	return vmware.NewDatastoreReader(client), nil
+}
+
+// readDatastore measures reading the largest disk of the datastore name. A
+// failure is recorded in the measure.
+func readDatastore(ctx context.Context, reader models.DatastoreReader, name string, limits benchmark.Limits) models.ThroughputMeasure {
+	rc, err := reader.Open(ctx, name)
+	if err != nil {
+		return newThroughputMeasure(name, benchmark.Result{}, err)
+	}
+	defer rc.Close()
+
+	res, err := benchmark.Read(ctx, rc, limits)
+	return newThroughputMeasure(name, res, err)
+}
+
+func newThroughputMeasure(name string, res benchmark.Result, err error) models.ThroughputMeasure {
+	m := models.ThroughputMeasure{Name: name, Bytes: res.Bytes, Duration: res.Duration, MBps: res.MBps()}
+	if err != nil {
+		m.Error = err.Error()
+	}
+	return m
+}
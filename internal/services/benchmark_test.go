@@ -0,0 +1,163 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+// fakeDatastoreReader serves a disk of size bytes per datastore, blocking
+// its reads while block is set.
+type fakeDatastoreReader struct {
+	datastores []string
+	size       int
+	block      bool
+	closed     bool
+}
+
+func (r *fakeDatastoreReader) Datastores(ctx context.Context) ([]string, error) {
+	return r.datastores, nil
+}
+
+func (r *fakeDatastoreReader) Open(ctx context.Context, datastore string) (io.ReadCloser, error) {
+	if datastore == "locked" {
+		return nil, errors.New("file is locked")
+	}
+	if r.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return io.NopCloser(bytes.NewReader(make([]byte, r.size))), nil
+}
+
+func (r *fakeDatastoreReader) Close(ctx context.Context) error {
+	r.closed = true
+	return nil
+}
+
+var _ = Describe("BenchmarkService", func() {
+	var (
+		ctx    context.Context
+		db     *sql.DB
+		st     *store.Store
+		jobs   *services.JobService
+		reader *fakeDatastoreReader
+		srv    *services.BenchmarkService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+		jobs = services.NewJobService(st)
+		reader = &fakeDatastoreReader{datastores: []string{"ds-1", "locked"}, size: 8 << 20}
+		srv = services.NewBenchmarkService(st).
+			WithJobService(jobs).
+			WithConnector(func(context.Context, *models.Credentials) (models.DatastoreReader, error) {
+				return reader, nil
+			})
+		jobs.WithCanceler(models.JobKindBenchmark, srv.Stop)
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	// jobState returns the state of the job id
+	jobState := func(id string) models.JobState {
+		job, err := jobs.Get(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		return job.State
+	}
+
+	// Given two datastores, one of them holding a locked disk
+	// When a benchmark runs
+	// Then the readable one should be measured, the failure of the other recorded
+	It("should measure the datastores", func() {
+		// Act
+		id, err := srv.Start(ctx, models.BenchmarkRequest{Credentials: &models.Credentials{}, Duration: time.Minute})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() models.JobState { return jobState(id) }).Should(Equal(models.JobStateCompleted))
+		bench, err := srv.Latest(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bench.ID).To(Equal(id))
+		Expect(bench.Datastores).To(HaveLen(2))
+		Expect(bench.Datastores[0].Name).To(Equal("ds-1"))
+		Expect(bench.Datastores[0].Bytes).To(BeEquivalentTo(8 << 20))
+		Expect(bench.Datastores[0].MBps).To(BeNumerically(">", 0))
+		Expect(bench.Datastores[1].Error).To(Equal("file is locked"))
+		Expect(bench.Network).To(BeNil())
+		Expect(bench.ThroughputMBps()).To(Equal(bench.Datastores[0].MBps))
+		Expect(reader.closed).To(BeTrue())
+	})
+
+	// Given a running benchmark
+	// When another one is started
+	// Then it should fail with a benchmark in progress error
+	It("should run a single benchmark at a time", func() {
+		// Arrange
+		reader.block = true
+		id, err := srv.Start(ctx, models.BenchmarkRequest{Credentials: &models.Credentials{}})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		_, err = srv.Start(ctx, models.BenchmarkRequest{Credentials: &models.Credentials{}})
+
+		// Assert
+		Expect(srvErrors.IsBenchmarkInProgressError(err)).To(BeTrue())
+		_, err = jobs.Cancel(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// Given a running benchmark
+	// When its job is canceled
+	// Then the job should be canceled and no measure stored
+	It("should cancel a running benchmark", func() {
+		// Arrange
+		reader.block = true
+		id, err := srv.Start(ctx, models.BenchmarkRequest{Credentials: &models.Credentials{}})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		job, err := jobs.Cancel(ctx, id)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.State).To(Equal(models.JobStateCanceled))
+		_, err = srv.Latest(ctx)
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+
+	// Given a vCenter rejecting the credentials
+	// When a benchmark is started
+	// Then it should fail with a vCenter error
+	It("should fail when the vCenter cannot be reached", func() {
+		// Arrange
+		srv.WithConnector(func(context.Context, *models.Credentials) (models.DatastoreReader, error) {
+			return nil, errors.New("Login failure: incorrect user name or password")
+		})
+
+		// Act
+		_, err := srv.Start(ctx, models.BenchmarkRequest{Credentials: &models.Credentials{}})
+
+		// Assert
+		Expect(srvErrors.IsVCenterError(err)).To(BeTrue())
+	})
+})
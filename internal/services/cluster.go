@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+type ClusterService struct {
+	store *store.Store
+}
+
+func NewClusterService(st *store.Store) *ClusterService {
+	return &ClusterService{store: st}
+}
+
+// ListRules returns the DRS rules collected for the cluster.
+func (s *ClusterService) ListRules(ctx context.Context, cluster string) ([]models.ClusterRule, error) {
+	return s.store.Cluster().ListRules(ctx, cluster)
+}
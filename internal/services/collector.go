@@ -3,11 +3,15 @@ package services
 import (
 	"context"
 	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 )
@@ -15,6 +19,12 @@ import (
 type CollectorService struct {
 	scheduler *scheduler.Scheduler
 	builder   models.WorkBuilder
+	// credentials keeps the credentials of the running collection, nil when they only live in memory
+	credentials *CredentialsService
+	// events receives the collection events, nil when they are not recorded
+	events *EventService
+	// jobs follows each collection as a job, nil when they are not followed
+	jobs *JobService
 
 	state models.CollectorStatus
 	mu    sync.Mutex
@@ -40,6 +50,26 @@ func NewCollectorService(s *scheduler.Scheduler, store *store.Store, builder mod
 	return srv
 }
 
+// WithCredentialsService keeps the credentials of each collection in creds
+// until it completes or fails, for Resume to start it again after a restart.
+// Without it the credentials only live in memory for the collection.
+func (c *CollectorService) WithCredentialsService(creds *CredentialsService) *CollectorService {
+	c.credentials = creds
+	return c
+}
+
+// WithEventService publishes the collection events to events.
+func (c *CollectorService) WithEventService(events *EventService) *CollectorService {
+	c.events = events
+	return c
+}
+
+// WithJobService follows each collection as a job of jobs.
+func (c *CollectorService) WithJobService(jobs *JobService) *CollectorService {
+	c.jobs = jobs
+	return c
+}
+
 // GetStatus returns the current collector status.
 func (c *CollectorService) GetStatus() models.CollectorStatus {
 	c.mu.Lock()
@@ -61,17 +91,26 @@ func (c *CollectorService) Start(ctx context.Context, creds *models.Credentials)
 		return nil
 	}
 
-	runCtx, cancel := context.WithCancel(context.Background())
+	if c.credentials != nil {
+		if err := c.credentials.Save(ctx, creds); err != nil {
+			zap.S().Named("collector_service").Errorw("failed to store the vcenter credentials", "error", err)
+		}
+	}
+
+	// the collection outlives the request but stays in its trace
+	runCtx, cancel := context.WithCancel(trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx)))
 	c.cancel = cancel
 	c.done = make(chan any)
 
 	c.state = models.CollectorStatus{State: models.CollectorStateConnecting}
-	go c.run(runCtx, c.done, c.builder.WithCredentials(creds).Build())
+	c.events.Publish(ctx, models.AgentEventCollectionStarted, "inventory collection started", map[string]string{"url": creds.URL})
+	job := c.jobs.Start(ctx, models.JobKindCollection)
+	go c.run(runCtx, c.done, job, c.builder.WithCredentials(creds).Build())
 
 	return nil
 }
 
-func (c *CollectorService) run(ctx context.Context, done chan any, work []models.WorkUnit) {
+func (c *CollectorService) run(ctx context.Context, done chan any, job string, work []models.WorkUnit) {
 	defer close(done)
 	defer func() {
 		c.mu.Lock()
@@ -83,16 +122,18 @@ func (c *CollectorService) run(ctx context.Context, done chan any, work []models
 		zap.S().Debug("collector finished work")
 	}()
 
-	for len(work) > 0 {
-		unit := work[0]
-		work = work[1:]
-
+	for i, unit := range work {
 		workFn := unit.Work()
 
-		c.setState(unit.Status())
+		status := unit.Status()
+		c.setState(status)
+		phase := string(status.State)
+		c.jobs.Progress(ctx, job, phase, i*100/len(work))
+		started := time.Now()
+		_, span := tracing.Tracer().Start(ctx, "collector."+phase)
 
 		future := c.scheduler.AddWork(func(ctx context.Context) (any, error) {
-			return workFn(ctx)
+			return workFn(trace.ContextWithSpan(ctx, span))
 		})
 
 		zap.S().Debugw("collector changed state", "state", c.GetStatus().State)
@@ -100,17 +141,65 @@ func (c *CollectorService) run(ctx context.Context, done chan any, work []models
 		select {
 		case <-ctx.Done():
 			future.Stop()
+			tracing.End(span, ctx.Err())
+			metrics.CollectorPhaseDuration.WithLabelValues(phase, metrics.ResultCanceled).Observe(time.Since(started).Seconds())
 
 			c.setState(models.CollectorStatus{State: models.CollectorStateReady})
+			c.events.Publish(ctx, models.AgentEventCollectionCanceled, "inventory collection canceled", map[string]string{"phase": phase})
+			c.jobs.Finish(ctx, job, models.JobStateCanceled, nil)
 
 			return
 		case result := <-future.C():
+			tracing.End(span, result.Err)
 			if result.Err != nil {
+				metrics.CollectorPhaseDuration.WithLabelValues(phase, metrics.ResultError).Observe(time.Since(started).Seconds())
+				metrics.CollectorFailures.WithLabelValues(phase).Inc()
 				c.setState(models.CollectorStatus{State: models.CollectorStateError, Error: result.Err})
+				c.events.Publish(ctx, models.AgentEventCollectionFailed, "inventory collection failed", map[string]string{"phase": phase, "error": result.Err.Error()})
+				c.jobs.Finish(ctx, job, models.JobStateFailed, result.Err)
+				c.forgetCredentials(ctx)
 				return
 			}
+			metrics.CollectorPhaseDuration.WithLabelValues(phase, metrics.ResultSuccess).Observe(time.Since(started).Seconds())
+			zap.S().Named("collector_service").Debugw("collection phase completed", "phase", phase,
+				"wait", result.Execution.Wait(), "duration", result.Execution.Duration())
 		}
 	}
+
+	c.events.Publish(ctx, models.AgentEventCollectionCompleted, "inventory collection completed", nil)
+	c.jobs.Finish(ctx, job, models.JobStateCompleted, nil)
+	c.forgetCredentials(ctx)
+}
+
+// Resume starts again with the stored credentials the collection the previous
+// run left unfinished, doing nothing without a CredentialsService or stored
+// credentials.
+func (c *CollectorService) Resume(ctx context.Context) {
+	if c.credentials == nil {
+		return
+	}
+	creds, err := c.credentials.Get(ctx)
+	if err != nil {
+		if !srvErrors.IsResourceNotFoundError(err) {
+			zap.S().Named("collector_service").Errorw("failed to read the stored vcenter credentials", "error", err)
+		}
+		return
+	}
+	zap.S().Named("collector_service").Infow("resuming the unfinished collection", "url", creds.URL)
+	if err := c.Start(ctx, creds); err != nil {
+		zap.S().Named("collector_service").Errorw("failed to resume the collection", "error", err)
+	}
+}
+
+// forgetCredentials deletes the stored credentials of a collection that ended,
+// a canceled one keeping them to be resumed.
+func (c *CollectorService) forgetCredentials(ctx context.Context) {
+	if c.credentials == nil {
+		return
+	}
+	if err := c.credentials.Delete(context.WithoutCancel(ctx)); err != nil {
+		zap.S().Named("collector_service").Errorw("failed to delete the stored vcenter credentials", "error", err)
+	}
 }
 
 func (c *CollectorService) Stop() {
@@ -128,6 +217,13 @@ func (c *CollectorService) Stop() {
 	}
 }
 
+// IsBusy tells whether a collection is running.
+func (c *CollectorService) IsBusy() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.isBusy()
+}
+
 func (c *CollectorService) setState(s models.CollectorStatus) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
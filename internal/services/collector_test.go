@@ -7,13 +7,17 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
-	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/keyring"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 type mockWorkBuilder struct {
@@ -95,10 +99,7 @@ var _ = Describe("CollectorService", func() {
 		ctx = context.Background()
 
 		var err error
-		db, err = store.NewDB(":memory:")
-		Expect(err).NotTo(HaveOccurred())
-
-		err = migrations.Run(ctx, db)
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		st = store.NewStore(db, test.NewMockValidator())
@@ -155,6 +156,50 @@ var _ = Describe("CollectorService", func() {
 		})
 	})
 
+	Context("Resume", func() {
+		// Given a collector service keeping the credentials of an unfinished collection
+		// When we resume it
+		// Then the collection should run again with those credentials
+		It("should start the collection with the stored credentials", func() {
+			// Arrange
+			kr, err := keyring.NewFile(GinkgoT().TempDir())
+			Expect(err).NotTo(HaveOccurred())
+			credsSrv := services.NewCredentialsService(kr)
+			Expect(credsSrv.Save(ctx, &models.Credentials{
+				URL:      "https://vcenter.example.com",
+				Username: "admin",
+				Password: "secret",
+			})).To(Succeed())
+			srv.WithCredentialsService(credsSrv)
+
+			// Act
+			srv.Resume(ctx)
+
+			// Assert
+			Eventually(func() models.CollectorStateType {
+				return srv.GetStatus().State
+			}).Should(Equal(models.CollectorStateCollected))
+		})
+
+		// Given a collector service without stored credentials
+		// When we resume it
+		// Then no collection should start
+		It("should do nothing without stored credentials", func() {
+			// Arrange
+			kr, err := keyring.NewFile(GinkgoT().TempDir())
+			Expect(err).NotTo(HaveOccurred())
+			srv.WithCredentialsService(services.NewCredentialsService(kr))
+
+			// Act
+			srv.Resume(ctx)
+
+			// Assert
+			Consistently(func() models.CollectorStateType {
+				return srv.GetStatus().State
+			}, "200ms").Should(Equal(models.CollectorStateReady))
+		})
+	})
+
 	Context("Start", func() {
 		// Given a collector service with valid credentials
 		// When we start the collector
@@ -181,6 +226,93 @@ var _ = Describe("CollectorService", func() {
 			Expect(inv).ToNot(BeNil())
 		})
 
+		// Given a collector service keeping the credentials in a file keyring
+		// When a collection completes
+		// Then its credentials should be deleted from the keyring
+		It("should forget the credentials of a completed collection", func() {
+			// Arrange
+			kr, err := keyring.NewFile(GinkgoT().TempDir())
+			Expect(err).NotTo(HaveOccurred())
+			credsSrv := services.NewCredentialsService(kr)
+			srv.WithCredentialsService(credsSrv)
+			creds := &models.Credentials{
+				URL:      "https://vcenter.example.com",
+				Username: "admin",
+				Password: "secret",
+			}
+
+			// Act
+			err = srv.Start(ctx, creds)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() models.CollectorStateType {
+				return srv.GetStatus().State
+			}).Should(Equal(models.CollectorStateCollected))
+			Eventually(func() bool {
+				_, err := credsSrv.Get(ctx)
+				return srvErrors.IsResourceNotFoundError(err)
+			}).Should(BeTrue())
+		})
+
+		// Given a collector service publishing its events
+		// When a collection completes
+		// Then the started and completed events should be recorded
+		It("should record the events of a collection", func() {
+			// Arrange
+			srv.WithEventService(services.NewEventService(st))
+			creds := &models.Credentials{
+				URL:      "https://vcenter.example.com",
+				Username: "admin",
+				Password: "secret",
+			}
+
+			// Act
+			err := srv.Start(ctx, creds)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Eventually(func() []models.AgentEventType {
+				events, err := st.AgentEvent().List(ctx, models.AgentEventFilter{}, models.Cursor{Limit: 10})
+				Expect(err).NotTo(HaveOccurred())
+				types := []models.AgentEventType{}
+				for _, e := range events {
+					types = append(types, e.Type)
+				}
+				return types
+			}).Should(Equal([]models.AgentEventType{models.AgentEventCollectionCompleted, models.AgentEventCollectionStarted}))
+		})
+
+		// Given a collector service publishing its events and failing verification
+		// When we start the collector
+		// Then a failed event should record the phase and the error
+		It("should record the failure of a collection", func() {
+			// Arrange
+			srv = services.NewCollectorService(sched, st, &mockWorkBuilder{
+				store:     st,
+				verifyErr: errors.New("connection refused"),
+			}).WithEventService(services.NewEventService(st))
+			creds := &models.Credentials{
+				URL:      "https://vcenter.example.com",
+				Username: "admin",
+				Password: "secret",
+			}
+
+			// Act
+			err := srv.Start(ctx, creds)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Eventually(func() []models.AgentEvent {
+				events, err := st.AgentEvent().List(ctx, models.AgentEventFilter{Types: []models.AgentEventType{models.AgentEventCollectionFailed}}, models.Cursor{Limit: 10})
+				Expect(err).NotTo(HaveOccurred())
+				return events
+			}).Should(HaveLen(1))
+			events, err := st.AgentEvent().List(ctx, models.AgentEventFilter{Types: []models.AgentEventType{models.AgentEventCollectionFailed}}, models.Cursor{Limit: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events[0].Details).To(Equal(map[string]string{"phase": "connecting", "error": "connection refused"}))
+		})
+
 		// Given a collector service with a work builder that fails verification
 		// When we start the collector
 		// Then it should reach error state
@@ -196,6 +328,8 @@ var _ = Describe("CollectorService", func() {
 				Password: "secret",
 			}
 
+			failures := testutil.ToFloat64(metrics.CollectorFailures.WithLabelValues(string(models.CollectorStateConnecting)))
+
 			// Act
 			err := srv.Start(ctx, creds)
 			Expect(err).ToNot(HaveOccurred())
@@ -204,6 +338,7 @@ var _ = Describe("CollectorService", func() {
 			Eventually(func() models.CollectorStateType {
 				return srv.GetStatus().State
 			}).Should(Equal(models.CollectorStateError))
+			Expect(testutil.ToFloat64(metrics.CollectorFailures.WithLabelValues(string(models.CollectorStateConnecting)))).To(Equal(failures + 1))
 		})
 
 		// Given a collector service with a work builder that fails collection
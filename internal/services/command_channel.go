@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+const (
+	// channelStatusInterval is the interval between two checks of the status
+	// of the agent, sent on the channel when it changed
+	channelStatusInterval = time.Second
+	// channelMaxBackoff bounds the wait between two attempts to open the channel
+	channelMaxBackoff = 60 * time.Second
+)
+
+// CommandChannelClient opens the command channel of an agent.
+type CommandChannelClient interface {
+	OpenCommandChannel(ctx context.Context, agentID uuid.UUID) (*console.Channel, error)
+}
+
+// CommandHandler runs a command of the console with its arguments.
+type CommandHandler func(ctx context.Context, args json.RawMessage) error
+
+// CommandChannel keeps a channel open to the console while the agent is in
+// connected mode, through which the console pushes commands, run by the
+// handlers of their type, and receives the status of the agent as soon as it
+// changes, instead of waiting for the next update interval. The channel is
+// opened again, with a backoff, when it breaks; the console loop keeps
+// reporting meanwhile.
+type CommandChannel struct {
+	agentID uuid.UUID
+	status  func() console.ChannelStatus
+
+	mu       sync.Mutex // protects client and handlers
+	client   CommandChannelClient
+	handlers map[string]CommandHandler
+}
+
+// NewCommandChannelService creates the channel of agentID, status returning
+// the status of the agent sent to the console.
+func NewCommandChannelService(client CommandChannelClient, agentID uuid.UUID, status func() console.ChannelStatus) *CommandChannel {
+	return &CommandChannel{
+		agentID:  agentID,
+		status:   status,
+		client:   client,
+		handlers: make(map[string]CommandHandler),
+	}
+}
+
+// WithHandler runs the commands of type command with handler. A command
+// without handler fails.
+func (s *CommandChannel) WithHandler(command string, handler CommandHandler) *CommandChannel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = handler
+	return s
+}
+
+// SetClient replaces the client, for a console URL changed by a configuration
+// reload. The channel opened with the previous client is kept until it breaks.
+func (s *CommandChannel) SetClient(client CommandChannelClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+// Run keeps the channel open while the agent is in connected mode, until ctx
+// is done.
+func (s *CommandChannel) Run(ctx context.Context) {
+	log := zap.S().Named("command_channel_service")
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = time.Second
+	b.MaxInterval = channelMaxBackoff
+
+	for {
+		wait := channelStatusInterval
+		if s.connected() {
+			s.mu.Lock()
+			client := s.client
+			s.mu.Unlock()
+
+			ch, err := client.OpenCommandChannel(ctx, s.agentID)
+			if err == nil {
+				log.Info("command channel opened")
+				b.Reset()
+				err = s.serve(ctx, ch)
+				_ = ch.Close()
+				log.Infow("command channel closed", "error", err)
+			}
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil && !errors.IsRetryable(err):
+				// e.g. a console without command channel, the agent keeps polling it
+				log.Warnw("failed to open command channel", "error", err)
+				wait = channelMaxBackoff
+			case err != nil:
+				log.Debugw("failed to open command channel", "error", err)
+				wait = b.NextBackOff()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// serve runs the commands received on ch and sends the status of the agent
+// when it changes, until the channel breaks, the agent leaves connected mode
+// or ctx is done.
+func (s *CommandChannel) serve(ctx context.Context, ch *console.Channel) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	commands := make(chan console.Command)
+	received := make(chan error, 1)
+	go func() {
+		for {
+			cmd, err := ch.Receive()
+			if err != nil {
+				received <- err
+				return
+			}
+			select {
+			case commands <- cmd:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	status := s.status()
+	if err := ch.Send(console.ChannelMessage{Type: console.ChannelMessageStatus, Status: &status}); err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(console.ChannelPingInterval)
+	defer ping.Stop()
+	poll := time.NewTicker(channelStatusInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-received:
+			return err
+		case cmd := <-commands:
+			// a command may wait for an operation to stop, the channel keeps reading meanwhile
+			go s.execute(ctx, ch, cmd)
+		case <-ping.C:
+			if err := ch.Ping(); err != nil {
+				return err
+			}
+		case <-poll.C:
+			next := s.status()
+			if next == status {
+				continue
+			}
+			status = next
+			if status.Mode != string(models.AgentModeConnected) {
+				return nil
+			}
+			if err := ch.Send(console.ChannelMessage{Type: console.ChannelMessageStatus, Status: &status}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// execute runs cmd and sends its result on ch.
+func (s *CommandChannel) execute(ctx context.Context, ch *console.Channel, cmd console.Command) {
+	log := zap.S().Named("command_channel_service")
+
+	s.mu.Lock()
+	handler, ok := s.handlers[cmd.Type]
+	s.mu.Unlock()
+
+	var err error
+	if ok {
+		err = handler(ctx, cmd.Args)
+	} else {
+		err = fmt.Errorf("unknown command %q", cmd.Type)
+	}
+
+	result := console.ChannelMessage{Type: console.ChannelMessageResult, ID: cmd.ID}
+	if err != nil {
+		log.Warnw("command failed", "command_id", cmd.ID, "type", cmd.Type, "error", err)
+		result.Error = err.Error()
+	} else {
+		log.Infow("command executed", "command_id", cmd.ID, "type", cmd.Type)
+	}
+	if err := ch.Send(result); err != nil {
+		log.Debugw("failed to send command result", "command_id", cmd.ID, "error", err)
+	}
+}
+
+// connected tells whether the agent is in connected mode, the channel being
+// closed otherwise.
+func (s *CommandChannel) connected() bool {
+	return s.status().Mode == string(models.AgentModeConnected)
+}
@@ -0,0 +1,217 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
+)
+
+var _ = Describe("CommandChannel", func() {
+	const agentID = "7c6f0f2e-5f3a-4d8e-9b1a-2f0e8d6c4a10"
+
+	var (
+		server *mockconsole.Server
+		srv    *services.CommandChannel
+		cancel context.CancelFunc
+		done   chan struct{}
+
+		mu     sync.Mutex
+		status console.ChannelStatus
+	)
+
+	setStatus := func(next console.ChannelStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = next
+	}
+
+	run := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			srv.Run(ctx)
+		}()
+	}
+
+	// messages returns the messages of type sent by the agent
+	messages := func(kind string) []console.ChannelMessage {
+		var msgs []console.ChannelMessage
+		for _, raw := range server.ChannelMessages() {
+			var msg console.ChannelMessage
+			Expect(json.Unmarshal(raw, &msg)).To(Succeed())
+			if msg.Type == kind {
+				msgs = append(msgs, msg)
+			}
+		}
+		return msgs
+	}
+
+	BeforeEach(func() {
+		server = mockconsole.NewServer()
+		setStatus(console.ChannelStatus{Mode: string(models.AgentModeConnected), CollectorStatus: string(models.CollectorStateReady)})
+
+		client, err := console.NewConsoleClient(server.URL(), "agent-jwt")
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewCommandChannelService(client, uuid.MustParse(agentID), func() console.ChannelStatus {
+			mu.Lock()
+			defer mu.Unlock()
+			return status
+		})
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+			Eventually(done).Should(BeClosed())
+			cancel = nil
+		}
+		server.Close()
+	})
+
+	// Given an agent in connected mode
+	// When the channel runs
+	// Then it should be opened with the agent token and the status sent first
+	It("should open the channel and send the status", func() {
+		// Act
+		run()
+
+		// Assert
+		Eventually(func() []console.ChannelMessage { return messages(console.ChannelMessageStatus) }).Should(HaveLen(1))
+		Expect(messages(console.ChannelMessageStatus)[0].Status.CollectorStatus).To(Equal(string(models.CollectorStateReady)))
+		requests := server.Requests(mockconsole.CommandChannel)
+		Expect(requests[0].ID).To(Equal(agentID))
+		Expect(requests[0].Header.Get("X-Agent-Token")).To(Equal("agent-jwt"))
+	})
+
+	// Given a handler for a command
+	// When the console pushes the command
+	// Then the handler should run with its arguments and the result be sent
+	It("should run a pushed command", func() {
+		// Arrange
+		received := make(chan string, 1)
+		srv.WithHandler(console.CommandCancelJob, func(_ context.Context, args json.RawMessage) error {
+			received <- string(args)
+			return nil
+		})
+		run()
+		Eventually(server.ChannelOpen).Should(BeTrue())
+
+		// Act
+		err := server.Push(console.Command{ID: "42", Type: console.CommandCancelJob, Args: json.RawMessage(`{"id":"job-1"}`)})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(received).Should(Receive(Equal(`{"id":"job-1"}`)))
+		Eventually(func() []console.ChannelMessage { return messages(console.ChannelMessageResult) }).Should(ConsistOf(
+			console.ChannelMessage{Type: console.ChannelMessageResult, ID: "42"},
+		))
+	})
+
+	// Given no handler for a command
+	// When the console pushes the command
+	// Then a failed result should be sent
+	It("should fail an unknown command", func() {
+		// Arrange
+		run()
+		Eventually(server.ChannelOpen).Should(BeTrue())
+
+		// Act
+		err := server.Push(console.Command{ID: "43", Type: "reboot"})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() []console.ChannelMessage { return messages(console.ChannelMessageResult) }).Should(HaveLen(1))
+		result := messages(console.ChannelMessageResult)[0]
+		Expect(result.ID).To(Equal("43"))
+		Expect(result.Error).To(ContainSubstring("unknown command"))
+	})
+
+	// Given an open channel
+	// When the status of the agent changes
+	// Then the new status should be sent without waiting for the update interval
+	It("should send the status when it changes", func() {
+		// Arrange
+		run()
+		Eventually(func() []console.ChannelMessage { return messages(console.ChannelMessageStatus) }).Should(HaveLen(1))
+
+		// Act
+		setStatus(console.ChannelStatus{Mode: string(models.AgentModeConnected), CollectorStatus: string(models.CollectorStateCollecting)})
+
+		// Assert
+		Eventually(func() []console.ChannelMessage { return messages(console.ChannelMessageStatus) }, 5*time.Second).Should(HaveLen(2))
+		Expect(messages(console.ChannelMessageStatus)[1].Status.CollectorStatus).To(Equal(string(models.CollectorStateCollecting)))
+	})
+
+	// Given an agent in disconnected mode
+	// When the channel runs
+	// Then the console should not be contacted
+	It("should not open the channel in disconnected mode", func() {
+		// Arrange
+		setStatus(console.ChannelStatus{Mode: string(models.AgentModeDisconnected)})
+
+		// Act
+		run()
+
+		// Assert
+		Consistently(func() int { return server.Count(mockconsole.CommandChannel) }, 1500*time.Millisecond).Should(BeZero())
+	})
+
+	// Given an open channel
+	// When the agent leaves connected mode
+	// Then the channel should be closed
+	It("should close the channel in disconnected mode", func() {
+		// Arrange
+		run()
+		Eventually(server.ChannelOpen).Should(BeTrue())
+
+		// Act
+		setStatus(console.ChannelStatus{Mode: string(models.AgentModeDisconnected)})
+
+		// Assert
+		Eventually(server.ChannelOpen, 5*time.Second).Should(BeFalse())
+	})
+
+	// Given an open channel
+	// When the console breaks it
+	// Then it should be opened again
+	It("should open a broken channel again", func() {
+		// Arrange
+		run()
+		Eventually(server.ChannelOpen).Should(BeTrue())
+
+		// Act
+		server.CloseChannel()
+
+		// Assert
+		Eventually(func() int { return server.Count(mockconsole.CommandChannel) }, 5*time.Second).Should(Equal(2))
+		Eventually(server.ChannelOpen).Should(BeTrue())
+	})
+
+	// Given a console without command channel
+	// When the channel runs
+	// Then it should not be retried before the maximum backoff
+	It("should wait before retrying a console refusing the channel", func() {
+		// Arrange
+		server.RespondWithStatus(mockconsole.CommandChannel, http.StatusNotFound)
+
+		// Act
+		run()
+
+		// Assert
+		Eventually(func() int { return server.Count(mockconsole.CommandChannel) }).Should(Equal(1))
+		Consistently(func() int { return server.Count(mockconsole.CommandChannel) }, 2*time.Second).Should(Equal(1))
+	})
+})
@@ -2,10 +2,8 @@ package services
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/json"
-	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -14,6 +12,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/pkg/console"
@@ -26,7 +25,10 @@ type Collector interface {
 }
 
 type Console struct {
+	settingsMu          sync.RWMutex // protects updateInterval and client, changed by a configuration reload
 	updateInterval      time.Duration
+	intervalChanged     chan struct{}
+	dispatchNow         chan struct{}
 	agentID             uuid.UUID
 	sourceID            uuid.UUID
 	version             string
@@ -39,6 +41,12 @@ type Console struct {
 	inventoryLastHash   string // holds the hash of the last sent inventory
 	store               *store.Store
 	legacyStatusEnabled bool
+	// events receives the mode changes and dispatch results, nil when they are not
+	// recorded. Set after the run loop may have started, hence atomic
+	events atomic.Pointer[EventService]
+	// leader tells whether the agent leads its high availability pair, only the
+	// leader dispatching. nil for a single agent, atomic as events
+	leader atomic.Pointer[LeaderElection]
 }
 
 func NewConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console.Client, collector Collector, st *store.Store) (*Console, error) {
@@ -74,11 +82,13 @@ func NewConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console
 
 func newConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console.Client, collector Collector, store *store.Store, defaultStatus models.ConsoleStatus) *Console {
 	return &Console{
-		updateInterval: cfg.UpdateInterval,
-		agentID:        uuid.MustParse(cfg.ID),
-		sourceID:       uuid.MustParse(cfg.SourceID),
-		version:        cfg.Version,
-		scheduler:      s,
+		updateInterval:  cfg.UpdateInterval,
+		intervalChanged: make(chan struct{}, 1),
+		dispatchNow:     make(chan struct{}, 1),
+		agentID:         uuid.MustParse(cfg.ID),
+		sourceID:        uuid.MustParse(cfg.SourceID),
+		version:         cfg.Version,
+		scheduler:       s,
 		state: &consoleState{
 			current: defaultStatus.Current,
 			target:  defaultStatus.Target,
@@ -90,6 +100,20 @@ func newConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console
 	}
 }
 
+// WithEventService publishes the mode changes and the changes of the dispatch
+// results to events.
+func (c *Console) WithEventService(events *EventService) *Console {
+	c.events.Store(events)
+	return c
+}
+
+// WithLeaderElection makes the loop dispatch only while the agent leads its
+// high availability pair, the standby keeping its mode.
+func (c *Console) WithLeaderElection(leader *LeaderElection) *Console {
+	c.leader.Store(leader)
+	return c
+}
+
 func (c *Console) GetMode(ctx context.Context) (models.AgentMode, error) {
 	config, err := c.store.Configuration().Get(ctx)
 	if err != nil {
@@ -129,6 +153,7 @@ func (c *Console) SetMode(ctx context.Context, mode models.AgentMode) error {
 	}
 
 	zap.S().Named("console_service").Infow("agent mode changed", "mode", mode)
+	c.events.Load().Publish(ctx, models.AgentEventModeChanged, "agent mode changed", map[string]string{"from": string(prevMode), "to": string(mode)})
 	return nil
 }
 
@@ -136,15 +161,52 @@ func (c *Console) Status() models.ConsoleStatus {
 	return c.state.Status()
 }
 
+// SetUpdateInterval changes the interval between two console updates.
+// A running loop picks it up on its next iteration.
+func (c *Console) SetUpdateInterval(interval time.Duration) {
+	c.settingsMu.Lock()
+	c.updateInterval = interval
+	c.settingsMu.Unlock()
+
+	select {
+	case c.intervalChanged <- struct{}{}:
+	default:
+	}
+}
+
+// DispatchNow makes a running loop send the status and the inventory without
+// waiting for the next tick nor the backoff, e.g. on a command of the console.
+// Nothing is sent in disconnected mode.
+func (c *Console) DispatchNow() {
+	select {
+	case c.dispatchNow <- struct{}{}:
+	default:
+	}
+}
+
+// SetClient replaces the client used to reach the console, e.g. after its URL changed.
+// Requests already sent complete with the previous client.
+func (c *Console) SetClient(client *console.Client) {
+	c.settingsMu.Lock()
+	defer c.settingsMu.Unlock()
+	c.client = client
+}
+
+func (c *Console) settings() (time.Duration, *console.Client) {
+	c.settingsMu.RLock()
+	defer c.settingsMu.RUnlock()
+	return c.updateInterval, c.client
+}
+
 // run is the main loop that sends status and inventory updates to the console.
 //
 // On each iteration:
 //  1. Dispatch status and inventory updates (combined in single call) and block until complete.
 //  2. Handle errors (fatal errors stop the loop, transient errors trigger backoff).
-//  3. Wait for next tick or close signal.
+//  3. Wait for next tick, a DispatchNow or close signal.
 //
-// Fatal errors (stop the loop, no retry):
-//   - ConsoleClientError (4xx): Client errors from console cannot be recovered.
+// Fatal errors (stop the loop, no retry), the ones errors.IsRetryable rejects:
+//   - ConsoleClientError (4xx but 408 and 429): Client errors from console cannot be recovered.
 //
 // Transient errors are logged and stored in status.Error, but the loop continues.
 // If inventory hasn't changed, the error state is preserved (not cleared or set).
@@ -156,7 +218,8 @@ func (c *Console) Status() models.ConsoleStatus {
 // the backoff resets to allow immediate requests on the next tick.
 func (c *Console) run() {
 	c.state.SetCurrent(models.ConsoleStatusConnected)
-	tick := time.NewTicker(c.updateInterval)
+	interval, _ := c.settings()
+	tick := time.NewTicker(interval)
 	c.close = make(chan any, 1)
 	defer func() {
 		tick.Stop()
@@ -168,12 +231,23 @@ func (c *Console) run() {
 	// use exponential backoff if server is unreachable.
 	nextAllowedTime := time.Time{}
 	b := backoff.NewExponentialBackOff()
-	b.InitialInterval = c.updateInterval
+	b.InitialInterval = interval
 	b.MaxInterval = 60 * time.Second // Don't wait longer than 60s
 
+	// result of the last dispatch, an event is published when it changes
+	lastResult := ""
+
 	for {
 		select {
 		case <-tick.C:
+		case <-c.intervalChanged:
+			interval, _ = c.settings()
+			tick.Reset(interval)
+			b.InitialInterval = interval
+			b.Reset()
+			continue
+		case <-c.dispatchNow:
+			nextAllowedTime = time.Time{}
 		case <-c.close:
 			return
 		}
@@ -184,22 +258,41 @@ func (c *Console) run() {
 			continue
 		}
 
+		// the standby of a pair waits for the lease of the leader to expire
+		if !c.leader.Load().IsLeader() {
+			continue
+		}
+
 		future := c.dispatch()
 
 		select {
 		case result := <-future.C():
 			if result.Err != nil {
+				metrics.ConsoleDispatchDuration.WithLabelValues(metrics.ResultError).Observe(time.Since(now).Seconds())
+				metrics.ConsoleConsecutiveErrors.Inc()
 				c.state.SetError(result.Err)
-				// If the error from console.rh.com is 4xx stop the service
-				// 4xx errors cannot be recovered and it is useless to keep sending requests
-				if errors.IsConsoleClientError(result.Err) {
+				// Stop the service on the errors retrying cannot recover from, such as
+				// the 4xx of console.rh.com: it is useless to keep sending requests
+				if !errors.IsRetryable(result.Err) {
 					zap.S().Named("console_service").Errorw("failed to send request to console. console service stopped", "error", result.Err.Error())
 					c.state.SetFatalStopped()
+					c.events.Load().Publish(context.Background(), models.AgentEventConsoleStopped, "console reporting stopped", map[string]string{"error": result.Err.Error()})
 					return
 				}
-				zap.S().Named("console_service").Errorw("failed to dispatch to console", "error", result.Err)
+				if lastResult != metrics.ResultError {
+					c.events.Load().Publish(context.Background(), models.AgentEventConsoleDispatchFailed, "failed to dispatch to console", map[string]string{"error": result.Err.Error()})
+				}
+				lastResult = metrics.ResultError
+				zap.S().Named("console_service").Errorw("failed to dispatch to console", "error", result.Err,
+					"wait", result.Execution.Wait(), "duration", result.Execution.Duration())
 			} else {
+				metrics.ConsoleDispatchDuration.WithLabelValues(metrics.ResultSuccess).Observe(time.Since(now).Seconds())
+				metrics.ConsoleConsecutiveErrors.Set(0)
 				c.state.ClearError()
+				if lastResult != metrics.ResultSuccess {
+					c.events.Load().Publish(context.Background(), models.AgentEventConsoleDispatchSucceeded, "dispatched to console", nil)
+				}
+				lastResult = metrics.ResultSuccess
 			}
 		case <-c.close:
 			future.Stop()
@@ -208,11 +301,14 @@ func (c *Console) run() {
 
 		// if there's an error activate backoff, otherwise reset it
 		if c.state.GetError() != nil {
-			nextAllowedTime = now.Add(b.NextBackOff())
+			wait := b.NextBackOff()
+			nextAllowedTime = now.Add(wait)
+			metrics.ConsoleBackoff.Set(wait.Seconds())
 			zap.S().Debugw("set backoff", "next-allowed-time", nextAllowedTime)
 		} else {
 			b.Reset()
 			nextAllowedTime = time.Time{}
+			metrics.ConsoleBackoff.Set(0)
 		}
 	}
 }
@@ -228,6 +324,7 @@ func (c *Console) Stop() {
 }
 
 func (c *Console) dispatch() *scheduler.Future[scheduler.Result[any]] {
+	_, client := c.settings()
 	return c.scheduler.AddWork(func(ctx context.Context) (any, error) {
 		collectorStatus := c.collector.GetStatus()
 		status := string(collectorStatus.State)
@@ -239,7 +336,7 @@ func (c *Console) dispatch() *scheduler.Future[scheduler.Result[any]] {
 			statusInfo = collectorStatus.Error.Error()
 		}
 
-		if err := c.client.UpdateAgentStatus(ctx, c.agentID, c.sourceID, c.version, status, statusInfo); err != nil {
+		if err := client.UpdateAgentStatus(ctx, c.agentID, c.sourceID, c.version, status, statusInfo); err != nil {
 			return nil, err
 		}
 
@@ -251,16 +348,11 @@ func (c *Console) dispatch() *scheduler.Future[scheduler.Result[any]] {
 			return nil, err
 		}
 
-		changed, err := c.isInventoryChanged(inventory)
-		if err != nil {
-			return nil, err
-		}
-
-		if !changed {
+		if !c.isInventoryChanged(inventory) {
 			return struct{}{}, nil
 		}
 
-		if err := c.client.UpdateSourceStatus(ctx, c.sourceID, c.agentID, *inventory); err != nil {
+		if err := client.UpdateSourceStatus(ctx, c.sourceID, c.agentID, inventory); err != nil {
 			return nil, err
 		}
 
@@ -270,19 +362,14 @@ func (c *Console) dispatch() *scheduler.Future[scheduler.Result[any]] {
 	})
 }
 
-func (c *Console) isInventoryChanged(inventory *models.Inventory) (bool, error) {
-	data, err := json.Marshal(inventory)
-	if err != nil {
-		return false, fmt.Errorf("failed to marshal inventory: %w", err)
-	}
-
-	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+func (c *Console) isInventoryChanged(inventory *models.Inventory) bool {
+	hash := inventory.Hash()
 	if hash == c.inventoryLastHash {
-		return false, nil
+		return false
 	}
 
 	c.inventoryLastHash = hash
-	return true, nil
+	return true
 }
 
 // consoleState holds the console status with its own mutex for thread-safe access.
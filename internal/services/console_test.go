@@ -7,9 +7,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -18,11 +21,13 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
-	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 	"github.com/kubev2v/assisted-migration-agent/pkg/console"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/lease"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 // MockCollector implements Collector interface for testing
@@ -71,10 +76,7 @@ var _ = Describe("Console Service", func() {
 		collector = NewMockCollector(models.CollectorStateReady)
 
 		var err error
-		db, err = store.NewDB(":memory:")
-		Expect(err).NotTo(HaveOccurred())
-
-		err = migrations.Run(context.Background(), db)
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		st = store.NewStore(db, test.NewMockValidator())
@@ -292,6 +294,37 @@ var _ = Describe("Console Service", func() {
 		})
 	})
 
+	Context("High availability", func() {
+		// Given the console service of the standby of a pair
+		// When it is switched to connected mode
+		// Then it should not send updates
+		It("should not send updates while standing by", func() {
+			// Arrange
+			requestReceived := make(chan bool, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestReceived <- true
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+			standby := services.NewLeaderElection(lease.NewFile(filepath.Join(GinkgoT().TempDir(), "agent.lease")), "agent-b", time.Second)
+			consoleSrv.WithLeaderElection(standby)
+
+			// Act
+			err = consoleSrv.SetMode(context.Background(), models.AgentModeConnected)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Consistently(requestReceived, 300*time.Millisecond).ShouldNot(Receive())
+			Expect(consoleSrv.Status().Target).To(Equal(models.ConsoleStatusConnected))
+		})
+	})
+
 	Context("SetMode", func() {
 		// Given a console service in disconnected mode
 		// When we switch to connected mode
@@ -391,19 +424,12 @@ var _ = Describe("Console Service", func() {
 		// Then it should stop sending all requests
 		It("should stop sending requests when source is gone (410)", func() {
 			// Arrange
-			statusReceived := make(chan bool, 10)
-			inventoryReceived := make(chan bool, 10)
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if strings.Contains(r.URL.Path, "agents") {
-					statusReceived <- true
-				} else if strings.Contains(r.URL.Path, "sources") {
-					inventoryReceived <- true
-				}
-				w.WriteHeader(http.StatusGone)
-			}))
+			server := mockconsole.NewServer().
+				RespondWithStatus(mockconsole.AgentStatus, http.StatusGone).
+				RespondWithStatus(mockconsole.SourceStatus, http.StatusGone)
 			defer server.Close()
 
-			client, err := console.NewConsoleClient(server.URL, "")
+			client, err := console.NewConsoleClient(server.URL(), "")
 			Expect(err).NotTo(HaveOccurred())
 
 			collector.SetState(models.CollectorStateCollected)
@@ -413,12 +439,11 @@ var _ = Describe("Console Service", func() {
 			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
 
 			// Act
-			Eventually(statusReceived, 500*time.Millisecond).Should(Receive())
-			time.Sleep(200 * time.Millisecond)
+			Eventually(server.Requests, 500*time.Millisecond).Should(HaveLen(1))
 
 			// Assert
-			Consistently(inventoryReceived, 300*time.Millisecond).ShouldNot(Receive())
-			Consistently(statusReceived, 300*time.Millisecond).ShouldNot(Receive())
+			Consistently(server.Requests, 500*time.Millisecond).Should(HaveLen(1))
+			Expect(server.Count(mockconsole.AgentStatus)).To(Equal(1))
 		})
 
 		// Given a console service in connected mode receiving 401 Unauthorized responses
@@ -426,19 +451,12 @@ var _ = Describe("Console Service", func() {
 		// Then it should stop sending all requests
 		It("should stop sending requests when agent is unauthorized (401)", func() {
 			// Arrange
-			statusReceived := make(chan bool, 10)
-			inventoryReceived := make(chan bool, 10)
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if strings.Contains(r.URL.Path, "agents") {
-					statusReceived <- true
-				} else if strings.Contains(r.URL.Path, "sources") {
-					inventoryReceived <- true
-				}
-				w.WriteHeader(http.StatusUnauthorized)
-			}))
+			server := mockconsole.NewServer().
+				RespondWithStatus(mockconsole.AgentStatus, http.StatusUnauthorized).
+				RespondWithStatus(mockconsole.SourceStatus, http.StatusUnauthorized)
 			defer server.Close()
 
-			client, err := console.NewConsoleClient(server.URL, "")
+			client, err := console.NewConsoleClient(server.URL(), "")
 			Expect(err).NotTo(HaveOccurred())
 
 			collector.SetState(models.CollectorStateCollected)
@@ -448,12 +466,11 @@ var _ = Describe("Console Service", func() {
 			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
 
 			// Act
-			Eventually(statusReceived, 500*time.Millisecond).Should(Receive())
-			time.Sleep(200 * time.Millisecond)
+			Eventually(server.Requests, 500*time.Millisecond).Should(HaveLen(1))
 
 			// Assert
-			Consistently(inventoryReceived, 300*time.Millisecond).ShouldNot(Receive())
-			Consistently(statusReceived, 300*time.Millisecond).ShouldNot(Receive())
+			Consistently(server.Requests, 500*time.Millisecond).Should(HaveLen(1))
+			Expect(server.Count(mockconsole.AgentStatus)).To(Equal(1))
 		})
 
 		// Given a console service in connected mode receiving transient errors
@@ -461,14 +478,11 @@ var _ = Describe("Console Service", func() {
 		// Then it should continue sending requests
 		It("should continue sending requests on transient errors", func() {
 			// Arrange
-			requestReceived := make(chan bool, 10)
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				requestReceived <- true
-				w.WriteHeader(http.StatusInternalServerError)
-			}))
+			server := mockconsole.NewServer().
+				RespondWithStatus(mockconsole.AgentStatus, http.StatusInternalServerError)
 			defer server.Close()
 
-			client, err := console.NewConsoleClient(server.URL, "")
+			client, err := console.NewConsoleClient(server.URL(), "")
 			Expect(err).NotTo(HaveOccurred())
 
 			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
@@ -478,7 +492,37 @@ var _ = Describe("Console Service", func() {
 			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
 
 			// Assert
-			Eventually(requestReceived, 500*time.Millisecond).Should(Receive())
+			Eventually(func() int {
+				return server.Count(mockconsole.AgentStatus)
+			}, time.Second).Should(BeNumerically(">=", 2))
+		})
+
+		// Given a console service in connected mode behind a slow console
+		// When the console answers after a delay
+		// Then the status updates should still go through
+		It("should keep sending status updates to a slow console", func() {
+			// Arrange
+			server := mockconsole.NewServer().
+				Respond(mockconsole.AgentStatus, mockconsole.Response{Status: http.StatusOK, Latency: 100 * time.Millisecond})
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL(), "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
+
+			// Assert
+			Eventually(func() int {
+				return server.Count(mockconsole.AgentStatus)
+			}, time.Second).Should(BeNumerically(">=", 2))
+			Expect(consoleSrv.Status().Current).To(Equal(models.ConsoleStatusConnected))
+			for _, r := range server.Requests(mockconsole.AgentStatus) {
+				Expect(r.ID).To(Equal(agentID))
+			}
 		})
 	})
 
@@ -581,27 +625,15 @@ var _ = Describe("Console Service", func() {
 			Expect(inventoryCount).To(Equal(1))
 		})
 
-		// Given a console service that receives 410 Gone after first successful request
-		// When the inventory changes
-		// Then no more inventory requests should be sent
-		It("should not send more inventory after source gone error (410)", func() {
+		// Given an inventory saved again with the same document
+		// When the update loop runs after the second save
+		// Then inventory should only be sent once
+		It("should not resend inventory saved again unchanged", func() {
 			// Arrange
-			statusCount := 0
-			inventoryCount := 0
+			var inventoryCount atomic.Int32
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if strings.Contains(r.URL.Path, "agents") {
-					statusCount++
-					if statusCount == 1 {
-						w.WriteHeader(http.StatusOK)
-						return
-					}
-					w.WriteHeader(http.StatusGone)
-					return
-				}
 				if strings.Contains(r.URL.Path, "sources") {
-					inventoryCount++
-					w.WriteHeader(http.StatusOK)
-					return
+					inventoryCount.Add(1)
 				}
 				w.WriteHeader(http.StatusOK)
 			}))
@@ -614,20 +646,51 @@ var _ = Describe("Console Service", func() {
 			err = st.Inventory().Save(context.Background(), []byte(`{"vms": [{"name": "vm1"}]}`))
 			Expect(err).NotTo(HaveOccurred())
 
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
+			Eventually(inventoryCount.Load, 500*time.Millisecond).Should(BeEquivalentTo(1))
+
+			// Act
+			err = st.Inventory().Save(context.Background(), []byte(`{"vms": [{"name": "vm1"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+			time.Sleep(300 * time.Millisecond)
+
+			// Assert
+			Expect(inventoryCount.Load()).To(BeEquivalentTo(1))
+		})
+
+		// Given a console service that receives 410 Gone after first successful request
+		// When the inventory changes
+		// Then no more inventory requests should be sent
+		It("should not send more inventory after source gone error (410)", func() {
+			// Arrange
+			server := mockconsole.NewServer().
+				RespondOnce(mockconsole.AgentStatus, mockconsole.Response{Status: http.StatusOK}).
+				RespondWithStatus(mockconsole.AgentStatus, http.StatusGone)
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL(), "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetState(models.CollectorStateCollected)
+			err = st.Inventory().Save(context.Background(), []byte(`{"vms": [{"name": "vm1"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+
 			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
 			Expect(err).NotTo(HaveOccurred())
 
 			// Act
 			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
 			time.Sleep(300 * time.Millisecond)
-			Expect(inventoryCount).To(Equal(1))
+			Expect(server.Count(mockconsole.SourceStatus)).To(Equal(1))
 
 			err = st.Inventory().Save(context.Background(), []byte(`{"vms": [{"name": "vm2"}]}`))
 			Expect(err).NotTo(HaveOccurred())
 			time.Sleep(300 * time.Millisecond)
 
 			// Assert
-			Expect(inventoryCount).To(Equal(1))
+			Expect(server.Count(mockconsole.SourceStatus)).To(Equal(1))
 			status := consoleSrv.Status()
 			Expect(status.Error).NotTo(BeNil())
 		})
@@ -637,22 +700,11 @@ var _ = Describe("Console Service", func() {
 		// Then the error should be stored in the service status
 		It("should store error in status when inventory update fails", func() {
 			// Arrange
-			inventoryReceived := make(chan bool, 1)
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				if strings.Contains(r.URL.Path, "agents") {
-					w.WriteHeader(http.StatusOK)
-					return
-				}
-				if strings.Contains(r.URL.Path, "sources") {
-					inventoryReceived <- true
-					w.WriteHeader(http.StatusBadRequest)
-					return
-				}
-				w.WriteHeader(http.StatusOK)
-			}))
+			server := mockconsole.NewServer().
+				RespondWithStatus(mockconsole.SourceStatus, http.StatusBadRequest)
 			defer server.Close()
 
-			client, err := console.NewConsoleClient(server.URL, "")
+			client, err := console.NewConsoleClient(server.URL(), "")
 			Expect(err).NotTo(HaveOccurred())
 
 			collector.SetState(models.CollectorStateCollected)
@@ -664,12 +716,114 @@ var _ = Describe("Console Service", func() {
 
 			// Act
 			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(BeNil())
-			Eventually(inventoryReceived, 200*time.Millisecond).Should(Receive())
+			Eventually(func() int {
+				return server.Count(mockconsole.SourceStatus)
+			}, 200*time.Millisecond).Should(BeNumerically(">=", 1))
 
 			// Assert
 			Eventually(func() error {
 				return consoleSrv.Status().Error
 			}, 100*time.Millisecond).ShouldNot(BeNil())
+			request := server.Requests(mockconsole.SourceStatus)[0]
+			Expect(request.ID).To(Equal(sourceID))
+			var body map[string]any
+			Expect(request.Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("agentId", agentID))
+		})
+	})
+
+	Context("Reload", func() {
+		// Given a connected console service with a long update interval
+		// When we shorten the update interval
+		// Then it should send status updates at the new interval
+		It("should apply a new update interval to the running loop", func() {
+			// Arrange
+			requestReceived := make(chan bool, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestReceived <- true
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+			cfg.UpdateInterval = time.Hour
+
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+			defer consoleSrv.Stop()
+			err = consoleSrv.SetMode(context.Background(), models.AgentModeConnected)
+			Expect(err).NotTo(HaveOccurred())
+			Consistently(requestReceived, 200*time.Millisecond).ShouldNot(Receive())
+
+			// Act
+			consoleSrv.SetUpdateInterval(50 * time.Millisecond)
+
+			// Assert
+			Eventually(requestReceived, 500*time.Millisecond).Should(Receive())
+		})
+
+		// Given a connected console service with a long update interval
+		// When a dispatch is requested
+		// Then the status should be sent without waiting for the interval
+		It("should dispatch on request", func() {
+			// Arrange
+			server := mockconsole.NewServer()
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL(), "")
+			Expect(err).NotTo(HaveOccurred())
+			cfg.UpdateInterval = time.Hour
+
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+			defer consoleSrv.Stop()
+			Expect(consoleSrv.SetMode(context.Background(), models.AgentModeConnected)).To(Succeed())
+			Consistently(func() int { return server.Count(mockconsole.AgentStatus) }, 200*time.Millisecond).Should(BeZero())
+
+			// Act
+			consoleSrv.DispatchNow()
+
+			// Assert
+			Eventually(func() int { return server.Count(mockconsole.AgentStatus) }, 500*time.Millisecond).Should(Equal(1))
+		})
+
+		// Given a connected console service
+		// When we replace its client
+		// Then the next status updates should go through the new client
+		It("should send updates with the new client", func() {
+			// Arrange
+			oldReceived := make(chan bool, 10)
+			oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				oldReceived <- true
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer oldServer.Close()
+
+			newReceived := make(chan bool, 10)
+			newServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				newReceived <- true
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer newServer.Close()
+
+			client, err := console.NewConsoleClient(oldServer.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+			newClient, err := console.NewConsoleClient(newServer.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+			defer consoleSrv.Stop()
+			err = consoleSrv.SetMode(context.Background(), models.AgentModeConnected)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(oldReceived, 500*time.Millisecond).Should(Receive())
+
+			// Act
+			consoleSrv.SetClient(newClient)
+
+			// Assert
+			Eventually(newReceived, 500*time.Millisecond).Should(Receive())
 		})
 	})
 
@@ -951,6 +1105,35 @@ var _ = Describe("Console Service", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(srvErrors.IsModeConflictError(err)).To(BeTrue())
 		})
+		// Given a console answering the status updates with 429
+		// When the loop dispatches to it
+		// Then the error should be retried and the mode still be changeable
+		It("should not fatally stop on a retryable client error", func() {
+			// Arrange
+			server := mockconsole.NewServer().
+				RespondWithStatus(mockconsole.AgentStatus, http.StatusTooManyRequests)
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL(), "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv, err := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = consoleSrv.SetMode(context.Background(), models.AgentModeConnected)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() int {
+				return server.Count(mockconsole.AgentStatus)
+			}, 500*time.Millisecond).Should(BeNumerically(">=", 1))
+			time.Sleep(200 * time.Millisecond)
+
+			// Act
+			err = consoleSrv.SetMode(context.Background(), models.AgentModeDisconnected)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(consoleSrv.Status().Error).To(HaveOccurred())
+		})
 	})
 
 	Context("GetMode", func() {
@@ -1161,4 +1344,57 @@ var _ = Describe("Console Service", func() {
 			Expect(receivedStatusInfo).To(Equal("collected"))
 		})
 	})
+
+	Context("Token", func() {
+		// Given a console client sharing a token
+		// When the token is rotated between two requests
+		// Then the second request should carry the new token
+		It("should send the rotated token", func() {
+			// Arrange
+			received := make(chan string, 2)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received <- r.Header.Get("X-Agent-Token")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			token := console.NewToken("first-token")
+			client, err := console.NewConsoleClient(server.URL, "first-token")
+			Expect(err).NotTo(HaveOccurred())
+			client.WithToken(token)
+
+			// Act
+			_, firstErr := client.GetAgentConfiguration(context.Background(), uuid.New())
+			token.Set("second-token")
+			_, secondErr := client.GetAgentConfiguration(context.Background(), uuid.New())
+
+			// Assert
+			Expect(firstErr).NotTo(HaveOccurred())
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(<-received).To(Equal("first-token"))
+			Expect(<-received).To(Equal("second-token"))
+		})
+
+		// Given a JWT with an expiration time and a token that is not a JWT
+		// When we read their expiry
+		// Then only the JWT should have one
+		It("should read the expiry of a JWT", func() {
+			// Arrange
+			expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+			signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+			}).SignedString([]byte("key"))
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			expiry, ok := console.NewToken(signed).Expiry()
+			_, opaqueOk := console.NewToken("opaque").Expiry()
+
+			// Assert
+			Expect(ok).To(BeTrue())
+			Expect(expiry.Equal(expiresAt)).To(BeTrue())
+			Expect(opaqueOk).To(BeFalse())
+		})
+	})
 })
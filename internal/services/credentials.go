@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// credentialsSecret is the name of the vCenter credentials in the secret store.
+const credentialsSecret = "vcenter-credentials"
+
+// CredentialsService keeps the last vCenter credentials given to the collector
+// in a secret store: the encrypted secrets table of the database, or a file
+// keyring keeping the password out of the database.
+type CredentialsService struct {
+	secrets models.SecretStore
+}
+
+func NewCredentialsService(secrets models.SecretStore) *CredentialsService {
+	return &CredentialsService{secrets: secrets}
+}
+
+// Save stores creds, replacing the previous ones.
+func (s *CredentialsService) Save(ctx context.Context, creds *models.Credentials) error {
+	value, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return s.secrets.Put(ctx, credentialsSecret, value)
+}
+
+// Get returns the stored credentials, or a ResourceNotFoundError when there
+// are none.
+func (s *CredentialsService) Get(ctx context.Context) (*models.Credentials, error) {
+	value, err := s.secrets.Get(ctx, credentialsSecret)
+	if err != nil {
+		return nil, err
+	}
+	var creds models.Credentials
+	if err := json.Unmarshal(value, &creds); err != nil {
+		return nil, fmt.Errorf("decoding stored credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+// Delete forgets the stored credentials.
+func (s *CredentialsService) Delete(ctx context.Context) error {
+	return s.secrets.Delete(ctx, credentialsSecret)
+}
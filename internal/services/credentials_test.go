@@ -0,0 +1,73 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("CredentialsService", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		srv *services.CredentialsService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st := store.NewStore(db, test.NewMockValidator())
+		secrets, err := st.Secrets(bytes.Repeat([]byte{1}, store.SecretKeySize))
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewCredentialsService(secrets)
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given saved credentials
+	// When we get them
+	// Then the same credentials should be returned
+	It("returns the saved credentials", func() {
+		// Arrange
+		creds := &models.Credentials{URL: "https://vcenter.example.com", Username: "admin", Password: "secret"}
+		Expect(srv.Save(ctx, creds)).To(Succeed())
+
+		// Act
+		stored, err := srv.Get(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored).To(Equal(creds))
+	})
+
+	// Given deleted credentials
+	// When we get them
+	// Then a not found error should be returned
+	It("forgets the deleted credentials", func() {
+		// Arrange
+		Expect(srv.Save(ctx, &models.Credentials{URL: "https://vcenter.example.com", Username: "admin", Password: "secret"})).To(Succeed())
+		Expect(srv.Delete(ctx)).To(Succeed())
+
+		// Act
+		_, err := srv.Get(ctx)
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+type DatastoreService struct {
+	store *store.Store
+}
+
+func NewDatastoreService(st *store.Store) *DatastoreService {
+	return &DatastoreService{store: st}
+}
+
+// ListStats returns the performance statistics sampled for every datastore.
+func (s *DatastoreService) ListStats(ctx context.Context) ([]models.DatastoreStats, error) {
+	return s.store.Datastore().ListStats(ctx)
+}
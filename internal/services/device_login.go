@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/sso"
+)
+
+const (
+	// slowDownStep is added to the polling interval when the identity provider asks to slow down (RFC 8628)
+	slowDownStep = 5 * time.Second
+	// minRefreshDelay keeps a token with a very short expiry from being refreshed in a loop
+	minRefreshDelay = time.Second
+)
+
+// DeviceLoginClient runs the OAuth device authorization flow.
+type DeviceLoginClient interface {
+	AuthorizeDevice(ctx context.Context) (*sso.DeviceAuthorization, error)
+	DeviceToken(ctx context.Context, deviceCode string) (*sso.TokenSet, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*sso.TokenSet, error)
+}
+
+// DeviceLogin obtains the console JWT with the OAuth device flow: Start returns
+// the code the user enters at the identity provider while the service polls for
+// the token. The token is written to path and passed to onToken, then refreshed
+// before it expires for as long as the identity provider allows.
+type DeviceLogin struct {
+	client  DeviceLoginClient
+	path    string
+	onToken func(jwt string)
+
+	mu     sync.Mutex // protects status and cancel
+	status models.DeviceLogin
+	cancel context.CancelFunc
+}
+
+func NewDeviceLoginService(client DeviceLoginClient, path string, onToken func(jwt string)) *DeviceLogin {
+	return &DeviceLogin{
+		client:  client,
+		path:    path,
+		onToken: onToken,
+		status:  models.DeviceLogin{State: models.DeviceLoginStateIdle},
+	}
+}
+
+// Status returns the status of the last login.
+func (d *DeviceLogin) Status() models.DeviceLogin {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status
+}
+
+// Start starts a device login and returns its user code. A pending login whose
+// code has not expired is returned as is, so the code stays the same for every
+// client. Starting a login stops refreshing the token of the previous one.
+func (d *DeviceLogin) Start(ctx context.Context) (models.DeviceLogin, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.status.State == models.DeviceLoginStatePending && time.Now().Before(d.status.ExpiresAt) {
+		return d.status, nil
+	}
+
+	auth, err := d.client.AuthorizeDevice(ctx)
+	if err != nil {
+		return models.DeviceLogin{}, err
+	}
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.status = models.DeviceLogin{
+		State:                   models.DeviceLoginStatePending,
+		UserCode:                auth.UserCode,
+		VerificationURI:         auth.VerificationURI,
+		VerificationURIComplete: auth.VerificationURIComplete,
+		ExpiresAt:               time.Now().Add(auth.ExpiresIn),
+	}
+	go d.run(runCtx, auth)
+
+	zap.S().Named("device_login_service").Infow("device login started", "verification_uri", auth.VerificationURI, "expires_in", auth.ExpiresIn)
+	return d.status, nil
+}
+
+// Stop stops the pending login or the refresh of the token.
+func (d *DeviceLogin) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// run polls the token of auth until the user enters the code, then keeps it
+// refreshed until ctx is done.
+func (d *DeviceLogin) run(ctx context.Context, auth *sso.DeviceAuthorization) {
+	tokens, err := d.poll(ctx, auth)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		d.fail(ctx, err)
+		return
+	}
+	if err := d.save(ctx, tokens); err != nil {
+		d.fail(ctx, err)
+		return
+	}
+
+	// a token without expiry is kept as is
+	for tokens.RefreshToken != "" && tokens.ExpiresIn > 0 {
+		refreshToken := tokens.RefreshToken
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(max(tokens.ExpiresIn*3/4, minRefreshDelay)):
+		}
+
+		tokens, err = d.client.RefreshToken(ctx, refreshToken)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			d.fail(ctx, fmt.Errorf("failed to refresh the console jwt: %w", err))
+			return
+		}
+		if tokens.RefreshToken == "" {
+			tokens.RefreshToken = refreshToken
+		}
+		if err := d.save(ctx, tokens); err != nil {
+			d.fail(ctx, err)
+			return
+		}
+	}
+}
+
+// poll returns the tokens of auth once the user entered the code.
+func (d *DeviceLogin) poll(ctx context.Context, auth *sso.DeviceAuthorization) (*sso.TokenSet, error) {
+	interval := auth.Interval
+	deadline := time.After(auth.ExpiresIn)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, sso.ErrExpiredToken
+		case <-time.After(interval):
+		}
+
+		tokens, err := d.client.DeviceToken(ctx, auth.DeviceCode)
+		switch {
+		case errors.Is(err, sso.ErrAuthorizationPending):
+			continue
+		case errors.Is(err, sso.ErrSlowDown):
+			interval += slowDownStep
+			continue
+		case err != nil:
+			return nil, err
+		}
+		return tokens, nil
+	}
+}
+
+// save persists the access token of tokens, makes the console clients send it
+// and marks the login succeeded.
+func (d *DeviceLogin) save(ctx context.Context, tokens *sso.TokenSet) error {
+	if err := writeSecretFile(d.path, tokens.AccessToken); err != nil {
+		return fmt.Errorf("failed to persist the console jwt: %w", err)
+	}
+	d.onToken(tokens.AccessToken)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if ctx.Err() != nil {
+		return nil
+	}
+	d.status = models.DeviceLogin{
+		State:     models.DeviceLoginStateSucceeded,
+		ExpiresAt: time.Now().Add(tokens.ExpiresIn),
+	}
+	return nil
+}
+
+// fail marks the login of ctx failed with err, unless another login replaced it.
+func (d *DeviceLogin) fail(ctx context.Context, err error) {
+	zap.S().Named("device_login_service").Errorw("device login failed", "error", err)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	d.status = models.DeviceLogin{State: models.DeviceLoginStateFailed, Error: err}
+}
+
+// writeSecretFile replaces the file at path with secret, readable by the
+// agent only. The file is renamed into place so readers never see it partly
+// written.
+func writeSecretFile(path, secret string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(secret + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
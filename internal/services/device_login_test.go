@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/sso"
+)
+
+type fakeDeviceLoginClient struct {
+	mu         sync.Mutex
+	authorized int
+	// polls are answered in order, the last one repeated
+	polls      []error
+	tokens     *sso.TokenSet
+	pollCount  int
+	refreshed  []string
+	refreshErr error
+}
+
+func (f *fakeDeviceLoginClient) AuthorizeDevice(ctx context.Context) (*sso.DeviceAuthorization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.authorized++
+	return &sso.DeviceAuthorization{
+		DeviceCode:      "device-1",
+		UserCode:        "ABCD-EFGH",
+		VerificationURI: "https://sso.example.com/device",
+		ExpiresIn:       time.Minute,
+		Interval:        10 * time.Millisecond,
+	}, nil
+}
+
+func (f *fakeDeviceLoginClient) DeviceToken(ctx context.Context, deviceCode string) (*sso.TokenSet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.polls[min(f.pollCount, len(f.polls)-1)]
+	f.pollCount++
+	if err != nil {
+		return nil, err
+	}
+	return f.tokens, nil
+}
+
+func (f *fakeDeviceLoginClient) RefreshToken(ctx context.Context, refreshToken string) (*sso.TokenSet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refreshed = append(f.refreshed, refreshToken)
+	if f.refreshErr != nil {
+		return nil, f.refreshErr
+	}
+	return &sso.TokenSet{AccessToken: "jwt-refreshed", ExpiresIn: time.Hour}, nil
+}
+
+var _ = Describe("DeviceLogin", func() {
+	var (
+		ctx     context.Context
+		client  *fakeDeviceLoginClient
+		path    string
+		mu      sync.Mutex
+		applied []string
+		srv     *services.DeviceLogin
+	)
+
+	appliedTokens := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), applied...)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = &fakeDeviceLoginClient{}
+		path = filepath.Join(GinkgoT().TempDir(), "agent.jwt")
+		applied = nil
+		srv = services.NewDeviceLoginService(client, path, func(jwt string) {
+			mu.Lock()
+			defer mu.Unlock()
+			applied = append(applied, jwt)
+		})
+	})
+
+	AfterEach(func() {
+		srv.Stop()
+	})
+
+	// Given a user entering the code after two polls
+	// When we start a login
+	// Then the code should be returned and the token persisted and applied
+	It("persists the token once the code is entered", func() {
+		// Arrange
+		client.polls = []error{sso.ErrAuthorizationPending, sso.ErrAuthorizationPending, nil}
+		client.tokens = &sso.TokenSet{AccessToken: "jwt", ExpiresIn: time.Hour}
+
+		// Act
+		login, err := srv.Start(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(login.State).To(Equal(models.DeviceLoginStatePending))
+		Expect(login.UserCode).To(Equal("ABCD-EFGH"))
+		Expect(login.VerificationURI).To(Equal("https://sso.example.com/device"))
+		Eventually(func() models.DeviceLoginStateType { return srv.Status().State }).Should(Equal(models.DeviceLoginStateSucceeded))
+		Expect(appliedTokens()).To(Equal([]string{"jwt"}))
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("jwt\n"))
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+	})
+
+	// Given a pending login
+	// When we start a login again
+	// Then the same code should be returned without a new authorization
+	It("returns the pending login", func() {
+		// Arrange
+		client.polls = []error{sso.ErrAuthorizationPending}
+		first, err := srv.Start(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		second, err := srv.Start(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(Equal(first))
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		Expect(client.authorized).To(Equal(1))
+	})
+
+	// Given a user denying the login
+	// When we start a login
+	// Then the login should fail without a token
+	It("fails when the login is denied", func() {
+		// Arrange
+		client.polls = []error{sso.ErrAccessDenied}
+
+		// Act
+		_, err := srv.Start(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() models.DeviceLoginStateType { return srv.Status().State }).Should(Equal(models.DeviceLoginStateFailed))
+		Expect(srv.Status().Error).To(MatchError(sso.ErrAccessDenied))
+		Expect(appliedTokens()).To(BeEmpty())
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+
+	// Given a token with a refresh token and a short expiry
+	// When the login succeeds
+	// Then the token should be refreshed and the new one persisted
+	It("refreshes the token before it expires", func() {
+		// Arrange
+		client.polls = []error{nil}
+		client.tokens = &sso.TokenSet{AccessToken: "jwt", RefreshToken: "refresh", ExpiresIn: time.Millisecond}
+
+		// Act
+		_, err := srv.Start(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(appliedTokens, 5*time.Second).Should(Equal([]string{"jwt", "jwt-refreshed"}))
+		client.mu.Lock()
+		Expect(client.refreshed).To(HaveExactElements("refresh"))
+		client.mu.Unlock()
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("jwt-refreshed\n"))
+	})
+
+	// Given a refresh token rejected by the identity provider
+	// When the token is refreshed
+	// Then the login should be marked failed
+	It("fails when the token cannot be refreshed", func() {
+		// Arrange
+		client.polls = []error{nil}
+		client.tokens = &sso.TokenSet{AccessToken: "jwt", RefreshToken: "refresh", ExpiresIn: time.Millisecond}
+		client.refreshErr = errors.New("invalid_grant")
+
+		// Act
+		_, err := srv.Start(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() models.DeviceLoginStateType { return srv.Status().State }, 5*time.Second).Should(Equal(models.DeviceLoginStateFailed))
+		Expect(srv.Status().Error).To(MatchError(ContainSubstring("invalid_grant")))
+	})
+})
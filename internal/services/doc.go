@@ -18,10 +18,28 @@
 //	    │
 //	    ▼
 //	Services Layer
-//	    ├── CollectorService ──► Store, Scheduler, WorkBuilder
-//	    ├── Console ──────────► Store, Scheduler, Console Client, Collector
+//	    ├── CollectorService ──► Store, Scheduler, WorkBuilder, EventService, JobService
+//	    ├── Console ──────────► Store, Scheduler, Console Client, Collector, EventService, LeaderElection
+//	    ├── RemoteConfig ─────► RemoteConfigClient (Console Client)
+//	    ├── CommandChannel ───► CommandChannelClient (Console Client), command handlers
+//	    ├── PolicyBundle ─────► PolicyBundleClient (Console Client), PolicyService
+//	    ├── Updater ──────────► UpdateClient (Console Client)
+//	    ├── Registration ─────► RegistrationClient (Console Client), Store
+//	    ├── Sources ──────────► Store, SourceFactory (per source Collector, Console, Inventory)
+//	    ├── EventService ─────► Store
+//	    ├── LeaderElection ───► Lease (shared file), EventService
+//	    ├── JobService ───────► Store, cancelers (CollectorService, InspectorService, BenchmarkService)
+//	    ├── PlanService ──────► Store
+//	    ├── BenchmarkService ─► Store, DatastoreReader (vCenter), JobService
+//	    ├── ErrorReporting ───► EventService, ErrorReporter
 //	    ├── InventoryService ─► Store
-//	    └── VMService ────────► Store
+//	    ├── PolicyService ────► Store, Policies, CollectorService
+//	    ├── PolicyDecision ───► Store
+//	    ├── Watchdog ─────────► HealthChecks (Store, Server)
+//	    ├── VMService ────────► Store
+//	    ├── InventoryGraph ───► Store, VMService
+//	    ├── ClusterService ───► Store
+//	    └── DatastoreService ─► Store
 //
 // # CollectorService
 //
@@ -57,6 +75,8 @@
 //   - Collection can be cancelled mid-execution via Stop, returning to Ready state
 //   - Work units are executed sequentially through the scheduler
 //   - On service initialization, if inventory exists in store, state starts as Collected
+//   - The WorkBuilder collects vCenter, a Hyper-V host and its cluster, or an oVirt
+//     engine, by the Provider of the credentials; all end in the same parsing
 //
 // Usage:
 //
@@ -97,9 +117,9 @@
 //
 // The service implements:
 //   - Periodic status and inventory dispatching on a configurable interval
-//   - SHA256 hash-based deduplication to avoid sending unchanged inventory
+//   - Deduplication on Inventory.Hash (SHA256 of the document) to avoid sending unchanged inventory
 //   - Exponential backoff (up to 60s) for transient errors (5xx, network issues)
-//   - Immediate termination on fatal errors (4xx client errors)
+//   - Immediate termination on errors errors.IsRetryable rejects (4xx client errors but 408 and 429)
 //   - Legacy status mode compatibility for older console versions
 //
 // Data sent to console:
@@ -143,7 +163,7 @@
 //
 // Error handling:
 //   - Transient errors: Logged, stored in status.Error, loop continues with backoff
-//   - Fatal errors (4xx): Sets fatalStopped flag, exits run loop permanently
+//   - Fatal errors (not errors.IsRetryable, e.g. 4xx): Sets fatalStopped flag, exits run loop permanently
 //   - Mode changes blocked after fatal stop to prevent retry loops
 //
 // Usage:
@@ -152,6 +172,316 @@
 //	mode, err := console.GetMode(ctx)
 //	err = console.SetMode(ctx, models.AgentModeConnected)
 //	status := console.Status()
+//	console.DispatchNow() // send without waiting for the interval
+//
+// # RemoteConfig
+//
+// RemoteConfig pulls the config.Remote of the agent from the console at every
+// interval and calls its onChange callback when it differs from the previous
+// one. A Remote rejected by the callback is offered again on the next pull.
+//
+// Usage:
+//
+//	remote := services.NewRemoteConfigService(client, agentID, 15*time.Minute, onChange)
+//	err := remote.Fetch(ctx) // at startup
+//	go remote.Run(ctx)
+//
+// # CommandChannel
+//
+// CommandChannel keeps a console.Channel, a websocket, open to the console
+// while the agent is in connected mode. The console pushes console.Commands on
+// it, each run by the CommandHandler registered for its type and answered
+// with a result; the status of the agent is sent when the channel opens and
+// whenever it changes, checked every second. A broken channel is opened again
+// with an exponential backoff up to a minute, and a console refusing it with a
+// 4xx is retried every minute: the Console loop keeps reporting meanwhile.
+//
+// Usage:
+//
+//	channel := services.NewCommandChannelService(client, agentID, status).
+//		WithHandler(console.CommandDispatch, func(context.Context, json.RawMessage) error {
+//			consoleSrv.DispatchNow()
+//			return nil
+//		})
+//	go channel.Run(ctx)
+//
+// # PolicyBundle
+//
+// PolicyBundle downloads the OPA policy bundle at Agent.PolicyBundleURL, usually
+// set by the remote configuration, at startup and every interval. The request
+// carries the ETag of the installed bundle in If-None-Match, so an unchanged
+// bundle is answered 304 and not downloaded again. A new bundle replaces the
+// .rego files of the policies folder once all its policies compile, its state
+// (URL, ETag, manifest revision) being kept in the folder, then onInstall,
+// PolicyService.Reload, re-evaluates the concerns at once.
+//
+// Usage:
+//
+//	bundle := services.NewPolicyBundleService(client, policiesDir, bundleURL, 15*time.Minute, policySrv.Reload)
+//	go bundle.Run(ctx)
+//
+// # Updater
+//
+// Updater keeps the agent up to date with the update channel at
+// Agent.UpdateChannelURL, usually set by the remote configuration, checked at
+// startup and every interval. The channel serves a models.UpdateManifest: the
+// latest version, the URL of its binary and the SHA-256 of the binary, signed
+// with ed25519 by the key of Agent.UpdatePublicKey. A manifest that is not
+// signed with it is rejected before anything is downloaded. A version newer
+// than the running one is downloaded next to the executable, checked against
+// the digest, then renamed over it, the replaced binary being kept with the
+// .previous suffix to roll back by hand. onInstalled then stops the agent,
+// which restarts into the new binary, see Agent.UpdateRestart. The state of
+// the update is reported by GET /agent.
+//
+// Only binaries are installed: a containerized agent is updated by pulling
+// its image, not by replacing a file of the container.
+//
+// Usage:
+//
+//	updater := services.NewUpdateService(client, channelURL, publicKey, executable, version, 15*time.Minute, stop)
+//	go updater.Run(ctx)
+//
+// # Registration
+//
+// Registration gives its identity to an agent deployed without Agent.ID and
+// Agent.SourceID. At startup it sends the provisioning token of
+// Auth.ProvisioningToken to the registration endpoint of the console, which
+// answers with the agent and source UUIDs, and keeps them in the store: the
+// next starts read them from there without calling the console. The attempts
+// are retried with a backoff while the console cannot be reached; a rejected
+// token fails the startup.
+//
+// Usage:
+//
+//	registration, err := services.NewRegistrationService(client, store, token, version).Register(ctx)
+//
+// # Sources
+//
+// Sources manages the sources of the agent, one per vCenter. The primary one,
+// Agent.SourceID, is served by the top level endpoints; the sources added
+// through /sources are each collected into a database of their own and
+// dispatched to the console by a loop of their own, reporting their source id.
+// The added sources follow the mode of the agent and are kept in the store of
+// the agent, Start building them again at the next runs. Removing a source
+// deletes its database.
+//
+// Usage:
+//
+//	sources := services.NewSourcesService(store, primary, primaryServices, factory)
+//	err := sources.Start(ctx)
+//	status, err := sources.Add(ctx, models.Source{ID: id, Name: "vcenter-2"})
+//
+// # APIKeyService
+//
+// APIKeyService manages the keys local integrations present in the X-API-Key
+// header. A key is returned once by Create; the store only keeps its SHA-256
+// and a short prefix to tell keys apart. Revoked keys stay listed. Validate
+// returns the models.Principal of the key, apikey:<name> with its role, viewer
+// or operator.
+//
+// Usage:
+//
+//	apiKeys := services.NewAPIKeyService(store)
+//	key, secret, err := apiKeys.Create(ctx, "backup-script", models.RoleViewer)
+//	principal, err := apiKeys.Validate(ctx, secret) // nil when not valid
+//	exist, err := apiKeys.Exist(ctx)                  // a key not revoked exists
+//	err = apiKeys.Revoke(ctx, key.ID)
+//
+// # DeviceLogin
+//
+// DeviceLogin obtains the console JWT with the OAuth device flow (RFC 8628) of
+// an sso.Client. Start returns the user code while a goroutine polls for the
+// token; the token is then written to the JWT file, passed to onToken and
+// refreshed at three quarters of its lifetime. Status reports the state of
+// the last login.
+//
+// Usage:
+//
+//	login := services.NewDeviceLoginService(ssoClient, cfg.Auth.JWTFilePath, onToken)
+//	status, err := login.Start(ctx) // status.UserCode, status.VerificationURI
+//	defer login.Stop()
+//
+// # AuditService
+//
+// AuditService keeps the audit log of the mutating requests of the local API,
+// recorded by the server's audit middleware. Record logs its failures instead
+// of returning them, the request being already served. The log keeps the most
+// recent 10000 entries.
+//
+// Usage:
+//
+//	audit := services.NewAuditService(store)
+//	srv.WithAuditLog(audit.Record)
+//	entries, err := audit.List(ctx, 100) // newest first
+//
+// # EventService
+//
+// EventService is the event bus of the agent. CollectorService, Console and
+// InspectorService publish their lifecycle events to it when given one
+// (WithEventService): collection started, completed, failed or canceled,
+// console dispatch results when they change, mode changes, and inspections.
+// Each event is stored in the agent_events table, which keeps the most recent
+// 10000, and passed to the subscribers; a subscriber lagging 64 events behind
+// misses the next ones. Publish on a nil EventService does nothing.
+//
+// Usage:
+//
+//	events := services.NewEventService(store)
+//	collector.WithEventService(events)
+//	ch, unsubscribe := events.Subscribe()
+//	defer unsubscribe()
+//	failed, err := events.List(ctx, models.AgentEventFilter{
+//	    Types: []models.AgentEventType{models.AgentEventCollectionFailed},
+//	}, models.NewCursor(1, 100))
+//
+// # JobService
+//
+// JobService follows the collections, the inspections, the benchmarks and the
+// exports of the support and assessment bundles as jobs, with a state
+// (running, completed, failed, canceled), a phase, a progress in percent and
+// timestamps, so the clients poll a single model. The CollectorService
+// reports a job per collection, its progress being the share of the work
+// units done; the InspectorService a job per inspection, its progress being
+// the share of the VMs inspected. The SupportBundleService and the
+// AssessmentService report a job per bundle written, completed or failed with
+// the download; they have no canceler, the request that streams the bundle
+// being the one to cancel. A job is canceled through the canceler of its kind,
+// which stops the operation. At startup Recover fails the jobs the
+// previous run left running. A nil JobService follows nothing.
+//
+// Usage:
+//
+//	jobs := services.NewJobService(store).
+//	    WithCanceler(models.JobKindInspection, inspector.Stop)
+//	collector.WithJobService(jobs)
+//	job, err := jobs.Cancel(ctx, id)
+//
+// # PlanService
+//
+// PlanService generates the migration plans of VMs selected in waves. The
+// waves keep their order and the VMs of a wave go from the smallest disks to
+// the largest, numbered across the plan. Each VM gets the time its disks take
+// to transfer at the throughput of the request, else the one of the latest
+// benchmark, else models.DefaultPlanThroughputMBps, and the number of its
+// critical concerns; each wave and the plan
+// get the total time and the resources the target needs. The plans are kept in
+// the store until deleted. Forklift converts a plan into the CRs of Forklift
+// (pkg/forklift), reading the networks and datastores of its VMs.
+//
+// Usage:
+//
+//	plans := services.NewPlanService(store)
+//	plan, err := plans.Generate(ctx, models.PlanRequest{
+//	    Waves: []models.PlanWaveRequest{{Name: "apps", VMIDs: []string{"vm-1", "vm-2"}}},
+//	})
+//
+// # BenchmarkService
+//
+// BenchmarkService runs the benchmark jobs measuring the throughput a migration
+// can expect: the read rate of the largest disk of each datastore of a vCenter,
+// and the rate of sending toward a target endpoint, each bounded by the
+// duration of the request and 4 GiB. A failed measure records its error
+// without failing the job. The benchmarks are kept in the store and the latest
+// one gives the throughput of the plans. A single benchmark runs at a time
+// (BenchmarkInProgressError); Stop, the canceler of its job, drops its
+// measures.
+//
+// Usage:
+//
+//	bench := services.NewBenchmarkService(store).WithJobService(jobs)
+//	jobs.WithCanceler(models.JobKindBenchmark, bench.Stop)
+//	id, err := bench.Start(ctx, models.BenchmarkRequest{Credentials: creds, Target: "tcp://10.0.0.12:9000"})
+//
+// # LeaderElection
+//
+// LeaderElection pairs two agents of the same source in high availability:
+// they share a lease, a file on a storage both mount such as an NFS export
+// (pkg/lease), each keeping its own database. The pair needs that shared
+// storage: there is no lease in the database. The leader renews the lease every third of its duration;
+// the standby takes over a lease that did not change for a whole duration,
+// measured on its own clock, and leads from its next attempt if no other agent
+// wrote the lease meanwhile. The leader steps down when it cannot renew the
+// lease for two thirds of the duration, before the standby may take over.
+//
+// A term runs the OnElected callbacks with a context canceled at its end, then
+// the OnDemoted ones; ha.elected and ha.demoted are published. The Console
+// dispatches only while IsLeader, a nil LeaderElection always leading. Run
+// releases the lease as it returns, so the standby takes over at once.
+//
+// Usage:
+//
+//	election := services.NewLeaderElection(lease.NewFile(path), "agent-a", 15*time.Second).
+//	    WithEventService(events).
+//	    OnElected(channel.Run).
+//	    OnDemoted(stopCollections)
+//	console.WithLeaderElection(election)
+//	go election.Run(ctx)
+//
+// # ErrorReportingService
+//
+// ErrorReportingService passes the collection.failed and console.stopped
+// events to a models.ErrorReporter, as collection_failed and console_fatal
+// reports, such as the reporting.Webhook of --error-webhook-url. It subscribes
+// on creation so the errors published before Run are reported too. The panics
+// are reported by the server (WithErrorReporter) and the scheduler
+// (scheduler.WithPanicHandler) where they are recovered.
+//
+// Usage:
+//
+//	reporting := services.NewErrorReportingService(events, reporter)
+//	go reporting.Run(ctx)
+//
+// # CredentialsService
+//
+// CredentialsService keeps the vCenter credentials of the running collection in
+// a models.SecretStore: the encrypted secrets table of the store
+// (store.EncryptedSecretStore) or a keyring.File keeping them out of the
+// database. CollectorService, when given one, saves them on Start and deletes
+// them once the collection completes or fails; a canceled collection keeps
+// them. Resume starts the collection again with the stored credentials after
+// a restart. A failure to save or delete them is logged and does not fail the
+// collection. Without one the credentials only live in memory.
+//
+// Usage:
+//
+//	secrets, err := store.Secrets(key) // or keyring.NewFile(cfg.Agent.KeyringFolder)
+//	creds := services.NewCredentialsService(secrets)
+//	collector := services.NewCollectorService(sched, store, builder).WithCredentialsService(creds)
+//	collector.Resume(ctx)
+//
+// # LoginThrottle
+//
+// LoginThrottle counts the invalid credentials sent to the local API per client
+// IP, checked by the server's credentials middleware. A client waits one second
+// after its first failure, doubled by each following one, and is locked out for
+// the lockout duration after maxFailures. The failures are loaded from the store
+// on first use and saved on each failure, so a lockout survives a restart;
+// valid credentials forget them. Wait reserves each attempt until Failed,
+// Succeeded or Aborted: a client with failures is checked one attempt at a
+// time and any client at most maxFailures at once, so parallel requests cannot
+// pass before their failures count.
+//
+// Usage:
+//
+//	throttle := services.NewLoginThrottleService(store, cfg.Auth.MaxLoginFailures, cfg.Auth.LoginLockout)
+//	srv.WithLoginThrottle(throttle)
+//
+// # SupportBundleService
+//
+// SupportBundleService writes the tar.gz served by GET /admin/support-bundle:
+// the configuration through its DebugMap, so without the secrets, the states
+// of the collector, inspector and console, the migrations and database stats
+// of the store, the scheduler stats, the last console.dispatch_failed events
+// and, when given a logger.LogBuffer (WithLogs), the recent logs. A part that
+// fails is listed in errors.txt and the others are still written.
+//
+// Usage:
+//
+//	bundle := services.NewSupportBundleService(cfg, store, sched, console, collector, inspector).
+//	    WithLogs(recentLogs).
+//	    WithJobService(jobs) // a support-bundle job per bundle
+//	err := bundle.Write(ctx, w)
 //
 // # InventoryService
 //
@@ -162,6 +492,93 @@
 //
 //	inventoryService := services.NewInventoryService(store)
 //	inventory, err := inventoryService.GetInventory(ctx)
+//	clusters, err := inventory.Clusters()
+//
+// The returned models.Inventory decodes its document once, on the first call
+// to Decoded, VMCount or Clusters, so callers do not unmarshal Data themselves.
+//
+// # PolicyService
+//
+// PolicyService re-evaluates the concerns of the stored inventory when the OPA
+// policies change. The store evaluates the concerns with a policy.Policies,
+// whose validator is swapped when its folder is reloaded; Run watches the
+// folder and Reload, called on SIGHUP, reloads it at once. After a reload that
+// changed the policies, the concerns are replaced and the inventory is built
+// and stored again, so the console receives it at its next update.
+//
+// Nothing is re-evaluated without an inventory or while a collection runs,
+// the collection evaluating the reloaded policies itself. A policy that does
+// not compile keeps the previous ones in use.
+//
+// Evaluate runs the policies in use against a VM of the inventory or a VM
+// document and returns the concerns they raise, without storing them, to tell
+// why a VM is flagged or not.
+//
+// The custom policies, uploaded by the users, are kept in the custom subfolder
+// of the policies folder, which the bundles do not replace. They are compiled
+// apart from the built-in policies and the IDs of their concerns are prefixed
+// with policy.CustomConcernPrefix. PutCustomPolicy rejects a policy that does
+// not compile with the other custom ones; it and DeleteCustomPolicy reload the
+// policies at once.
+//
+// The metadata of the concerns, read from the METADATA annotations of the
+// rules (title, severity, remediation, docs link), is stored by SyncMetadata at
+// startup and after each reload. GetConcern returns it with the number of VMs
+// the concern flags, and Evaluate attaches it to the concerns it returns.
+//
+// ListPolicies returns the policy files in use with their hash and source,
+// built-in, from the bundle of the console or custom, and the error of the
+// last reload when it failed, so a rule that does not compile is not missed.
+//
+// RunTests runs the rego tests, the test_ rules of the _test.rego files, of
+// the policies folder against its policies, and the ones of the custom
+// subfolder against the custom policies. Candidate custom policies and tests
+// join the custom ones for the run without being written, so an author can
+// check a rule against sample VM documents before uploading it.
+//
+// Usage:
+//
+//	policySrv := services.NewPolicyService(store, policies, collectorSrv)
+//	go policySrv.Run(ctx)
+//
+// # Watchdog
+//
+// Watchdog reports the state of the agent to systemd through sd_notify. Its
+// health checks, the database answering a query and the server accepting
+// connections in the agent, run every second until they pass, then READY=1 is
+// sent. When the unit sets WatchdogSec, the checks run at half of it and each
+// pass pings WATCHDOG=1, so a hung agent misses its pings and systemd restarts
+// it; without a watchdog they run every 30 seconds. The failing check is shown
+// in the STATUS of the unit, and STOPPING=1 is sent on shutdown. Outside a
+// Type=notify unit nothing is sent and the failures are only logged.
+//
+// Usage:
+//
+//	watchdog := services.NewWatchdogService(
+//	    services.HealthCheck{Name: "database", Check: store.Ping},
+//	    services.HealthCheck{Name: "server", Check: srv.Ping},
+//	)
+//	go watchdog.Run(ctx)
+//
+// # PolicyDecisionService
+//
+// PolicyDecisionService logs the decisions of the policy evaluations, the
+// collections, the re-evaluations and POST /policies/evaluate alike. Given to
+// policy.Policies.OnDecision, Record receives each evaluation of a VM and
+// buffers a flagged decision per concern raised, or a passed one, with the
+// duration of the evaluation; the rules being evaluated together, it is the
+// same for all of them. Run stores the buffered decisions every 2 seconds and
+// once more when it stops; beyond 10000 pending, the next ones are dropped.
+// The policy_decisions table keeps the most recent 50000.
+//
+// Usage:
+//
+//	decisionSrv := services.NewPolicyDecisionService(store)
+//	policies.OnDecision(decisionSrv.Record)
+//	go decisionSrv.Run(ctx)
+//	flagged, err := decisionSrv.List(ctx, models.PolicyDecisionFilter{
+//	    Decision: models.PolicyDecisionFlagged,
+//	}, models.NewCursor(1, 20))
 //
 // # VMService
 //
@@ -174,6 +591,7 @@
 //   - By minimum issue count
 //   - By disk size range (min/max in MB)
 //   - By memory size range (min/max in MB)
+//   - By concern severity (multiple severities supported)
 //
 // Sorting:
 //   - Multiple sort fields with direction control (ascending/descending)
@@ -190,10 +608,48 @@
 //	    Statuses:  []string{"poweredOn"},
 //	    MinIssues: 1,
 //	    Sort:      []services.SortField{{Field: "name", Desc: false}},
-//	    Limit:     50,
-//	    Offset:    0,
+//	    Cursor:    models.NewCursor(1, 50),
 //	}
-//	vms, total, err := vmService.List(ctx, params)
+//	page, err := vmService.List(ctx, params) // page.Items, page.Total, page.Count()
+//
+// # InventoryGraph
+//
+// InventoryGraph executes the GraphQL queries of the inventory schema of
+// api/graphql with pkg/graphql. The VMs are listed by VMService with the
+// filters of the REST listing, by name, and their details are read with
+// VMStore.Get only when a query selects one of them. The hosts, datastores and
+// concern metadata are read once per query for the relations:
+//
+//	graph, err := services.NewInventoryGraphService(store)
+//	resp := graph.Execute(ctx, graphql.Request{
+//	    Query: `{ hosts { id datastores { id hosts { id } } } }`,
+//	})
+//
+// An unknown VM or concern is null. An unknown cluster fails its field with
+// the not found error; the store errors are logged and reported as "internal
+// error".
+//
+// # AssessmentService
+//
+// AssessmentService exports the assessment of an air-gapped agent as a tar.gz
+// bundle: inventory.json, inspections.json, events.json and manifest.json,
+// which lists the agent, the source and the SHA-256 of each file and is signed
+// in manifest.sig with the ed25519 key of the agent. Import verifies a bundle
+// with ReadAssessmentBundle and sends its inventory to the console with
+// SendAssessment, both shared with the assessment command of the agent. Only
+// the bundles signed by a trusted key are imported, the key given in the
+// bundle proving nothing by itself:
+//
+//	srv := services.NewAssessmentService(store, key, agentID, sourceID, version, consoleSrv, consoleClient).
+//		WithTrustedKeys(fingerprints).
+//		WithJobService(jobs) // an assessment-export job per export
+//	err := srv.Export(ctx, w)
+//	bundle, err := srv.Import(ctx, r, fingerprint) // one of the trusted keys, optional
+//
+// A bundle not matching its signature, a trusted key or its manifest, or
+// whose files exceed 512MB or 1GB in total once inflated, fails with an
+// InvalidBundleError; Import fails with a ModeConflictError unless the
+// agent is in connected mode.
 //
 // # Thread Safety
 //
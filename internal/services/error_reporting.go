@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// reportedEvents are the events of the agent passed to the error reporter.
+var reportedEvents = map[models.AgentEventType]models.ErrorReportKind{
+	models.AgentEventCollectionFailed: models.ErrorReportCollectionFailed,
+	models.AgentEventConsoleStopped:   models.ErrorReportConsoleFatal,
+}
+
+// ErrorReportingService passes the failed collections and the fatal console
+// errors published on the event bus to an error reporter. The panics are
+// reported by the server and the scheduler directly.
+type ErrorReportingService struct {
+	reporter    models.ErrorReporter
+	events      <-chan models.AgentEvent
+	unsubscribe func()
+}
+
+// NewErrorReportingService subscribes to events right away, so the errors
+// published before Run are not missed.
+func NewErrorReportingService(events *EventService, reporter models.ErrorReporter) *ErrorReportingService {
+	ch, unsubscribe := events.Subscribe()
+	return &ErrorReportingService{
+		reporter:    reporter,
+		events:      ch,
+		unsubscribe: unsubscribe,
+	}
+}
+
+// Run reports the errors until ctx is done.
+func (s *ErrorReportingService) Run(ctx context.Context) {
+	defer s.unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.events:
+			kind, ok := reportedEvents[event.Type]
+			if !ok {
+				continue
+			}
+			s.reporter.Report(ctx, models.ErrorReport{
+				Kind:    kind,
+				Time:    event.Time,
+				Message: event.Message,
+				Details: event.Details,
+			})
+		}
+	}
+}
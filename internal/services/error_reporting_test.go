@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+type recordingReporter struct {
+	mu      sync.Mutex
+	reports []models.ErrorReport
+}
+
+func (r *recordingReporter) Report(ctx context.Context, report models.ErrorReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+}
+
+func (r *recordingReporter) Reports() []models.ErrorReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.ErrorReport(nil), r.reports...)
+}
+
+var _ = Describe("ErrorReportingService", func() {
+	var (
+		ctx      context.Context
+		cancel   context.CancelFunc
+		db       *sql.DB
+		events   *services.EventService
+		reporter *recordingReporter
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		events = services.NewEventService(store.NewStore(db, test.NewMockValidator()))
+		reporter = &recordingReporter{}
+	})
+
+	AfterEach(func() {
+		cancel()
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given an error reporting service
+	// When a collection fails, the console stops and the mode changes
+	// Then only the failed collection and the stopped console should be reported
+	It("should report the failed collections and the fatal console errors", func() {
+		// Arrange
+		srv := services.NewErrorReportingService(events, reporter)
+		go srv.Run(ctx)
+
+		// Act
+		events.Publish(ctx, models.AgentEventCollectionFailed, "inventory collection failed", map[string]string{"phase": "collecting", "error": "timeout"})
+		events.Publish(ctx, models.AgentEventModeChanged, "agent mode changed", nil)
+		events.Publish(ctx, models.AgentEventConsoleStopped, "console reporting stopped", map[string]string{"error": "source gone"})
+
+		// Assert
+		Eventually(reporter.Reports).Should(HaveLen(2))
+		reports := reporter.Reports()
+		Expect(reports[0].Kind).To(Equal(models.ErrorReportCollectionFailed))
+		Expect(reports[0].Message).To(Equal("inventory collection failed"))
+		Expect(reports[0].Details).To(HaveKeyWithValue("phase", "collecting"))
+		Expect(reports[0].Time).NotTo(BeZero())
+		Expect(reports[1].Kind).To(Equal(models.ErrorReportConsoleFatal))
+		Expect(reports[1].Details).To(HaveKeyWithValue("error", "source gone"))
+	})
+
+	// Given an error reporting service that is not running yet
+	// When a collection fails
+	// Then the failure should be reported once it runs
+	It("should report the errors published before Run", func() {
+		// Arrange
+		srv := services.NewErrorReportingService(events, reporter)
+		events.Publish(ctx, models.AgentEventCollectionFailed, "inventory collection failed", nil)
+
+		// Act
+		go srv.Run(ctx)
+
+		// Assert
+		Eventually(reporter.Reports).Should(HaveLen(1))
+	})
+})
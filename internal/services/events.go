@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+// eventSubscriberBuffer is the number of events a subscriber can lag behind
+// before the next ones are dropped for it.
+const eventSubscriberBuffer = 64
+
+// EventService is the event bus of the agent: the services publish their
+// lifecycle events to it, it stores them and passes them to the subscribers.
+type EventService struct {
+	store *store.Store
+
+	mu          sync.Mutex
+	subscribers map[int]chan models.AgentEvent
+	nextID      int
+}
+
+func NewEventService(st *store.Store) *EventService {
+	return &EventService{
+		store:       st,
+		subscribers: make(map[int]chan models.AgentEvent),
+	}
+}
+
+// Publish records an event of eventType and passes it to the subscribers. A
+// failure to store it is logged, the operation it describes being already
+// done. Publishing to a nil EventService does nothing, so the services can
+// run without one.
+func (s *EventService) Publish(ctx context.Context, eventType models.AgentEventType, message string, details map[string]string) {
+	if s == nil {
+		return
+	}
+
+	event := models.AgentEvent{
+		Type:    eventType,
+		Time:    time.Now().UTC(),
+		Message: message,
+		Details: details,
+	}
+
+	// the event is stored even when the request that caused it went away
+	if err := s.store.AgentEvent().Insert(context.WithoutCancel(ctx), event); err != nil {
+		zap.S().Named("event_service").Errorw("failed to record event", "type", eventType, "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.subscribers {
+		// a slow subscriber must not block the publishing service
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published from now on and
+// the function ending the subscription, which closes the channel.
+func (s *EventService) Subscribe() (<-chan models.AgentEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	ch := make(chan models.AgentEvent, eventSubscriberBuffer)
+	s.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.subscribers, id)
+			close(ch)
+		})
+	}
+}
+
+// List returns the page at cursor of the stored events matching filter, newest first.
+func (s *EventService) List(ctx context.Context, filter models.AgentEventFilter, cursor models.Cursor) (models.Page[models.AgentEvent], error) {
+	events, err := s.store.AgentEvent().List(ctx, filter, cursor)
+	if err != nil {
+		return models.Page[models.AgentEvent]{}, err
+	}
+	total, err := s.store.AgentEvent().Count(ctx, filter)
+	if err != nil {
+		return models.Page[models.AgentEvent]{}, err
+	}
+	return models.NewPage(events, cursor, total), nil
+}
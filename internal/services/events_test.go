@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("EventService", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		st  *store.Store
+		srv *services.EventService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, test.NewMockValidator())
+		srv = services.NewEventService(st)
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given an event service
+	// When an event is published
+	// Then it should be stored and listed
+	It("should store the published events", func() {
+		// Act
+		srv.Publish(ctx, models.AgentEventModeChanged, "agent mode changed", map[string]string{"from": "disconnected", "to": "connected"})
+
+		// Assert
+		page, err := srv.List(ctx, models.AgentEventFilter{}, models.NewCursor(1, 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Total).To(Equal(1))
+		events := page.Items
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(models.AgentEventModeChanged))
+		Expect(events[0].Message).To(Equal("agent mode changed"))
+		Expect(events[0].Details).To(HaveKeyWithValue("to", "connected"))
+		Expect(events[0].Time).NotTo(BeZero())
+	})
+
+	// Given a subscriber of the event service
+	// When an event is published
+	// Then the subscriber should receive it
+	It("should pass the published events to the subscribers", func() {
+		// Arrange
+		events, unsubscribe := srv.Subscribe()
+		defer unsubscribe()
+
+		// Act
+		srv.Publish(ctx, models.AgentEventCollectionStarted, "inventory collection started", nil)
+
+		// Assert
+		var event models.AgentEvent
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(models.AgentEventCollectionStarted))
+	})
+
+	// Given a subscription that ended
+	// When an event is published
+	// Then the channel should be closed and the publisher not blocked
+	It("should close the channel of an ended subscription", func() {
+		// Arrange
+		events, unsubscribe := srv.Subscribe()
+		unsubscribe()
+
+		// Act
+		srv.Publish(ctx, models.AgentEventCollectionStarted, "inventory collection started", nil)
+
+		// Assert
+		Eventually(events).Should(BeClosed())
+		Expect(unsubscribe).NotTo(Panic())
+	})
+
+	// Given no event service
+	// When an event is published
+	// Then it should be dropped without error
+	It("should drop the events published to a nil service", func() {
+		// Arrange
+		var nilSrv *services.EventService
+
+		// Act & Assert
+		Expect(func() {
+			nilSrv.Publish(ctx, models.AgentEventCollectionStarted, "inventory collection started", nil)
+		}).NotTo(Panic())
+	})
+})
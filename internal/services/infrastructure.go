@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+type InfrastructureService struct {
+	store *store.Store
+}
+
+func NewInfrastructureService(st *store.Store) *InfrastructureService {
+	return &InfrastructureService{store: st}
+}
+
+// ListHosts returns the page at cursor of the hosts of cluster, or of all of
+// them when cluster is empty.
+func (s *InfrastructureService) ListHosts(ctx context.Context, cluster string, cursor models.Cursor) (models.Page[models.Host], error) {
+	hosts, err := s.store.Infrastructure().ListHosts(ctx, cluster)
+	if err != nil {
+		return models.Page[models.Host]{}, err
+	}
+	return models.Paginate(hosts, cursor), nil
+}
+
+// ListDatastores returns the page at cursor of the datastores of cluster, or
+// of all of them when cluster is empty.
+func (s *InfrastructureService) ListDatastores(ctx context.Context, cluster string, cursor models.Cursor) (models.Page[models.Datastore], error) {
+	datastores, err := s.store.Infrastructure().ListDatastores(ctx, cluster)
+	if err != nil {
+		return models.Page[models.Datastore]{}, err
+	}
+	return models.Paginate(datastores, cursor), nil
+}
+
+// ListNetworks returns the page at cursor of the networks of the inventory.
+func (s *InfrastructureService) ListNetworks(ctx context.Context, cursor models.Cursor) (models.Page[models.Network], error) {
+	networks, err := s.store.Infrastructure().ListNetworks(ctx)
+	if err != nil {
+		return models.Page[models.Network]{}, err
+	}
+	return models.Paginate(networks, cursor), nil
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
@@ -13,9 +16,13 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/pkg/vmware"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 )
@@ -34,6 +41,11 @@ type InspectorService struct {
 	vsphereClient *govmomi.Client
 	cancel        context.CancelFunc
 	cred          *models.Credentials
+	proxy         func(*http.Request) (*url.URL, error)
+	// events receives the inspection events, nil when they are not recorded
+	events *EventService
+	// jobs follows each inspection as a job, nil when they are not followed
+	jobs *JobService
 }
 
 // NewInspectorService creates a new InspectorService with the default vmware builder.
@@ -70,7 +82,7 @@ func (c *InspectorService) Start(ctx context.Context, vmIDs []string, cred *mode
 	c.setState(models.InspectorStateInitiating)
 	zap.S().Infow("starting inspector", "vmCount", len(vmIDs))
 
-	vClient, err := vmware.NewVsphereClient(ctx, cred.URL, cred.Username, cred.Password, true)
+	vClient, err := vmware.NewVsphereClient(ctx, cred.URL, cred.Username, cred.Password, true, c.proxy)
 	if err != nil {
 		zap.S().Named("inspector_service").Errorw("failed to connect to vSphere", "error", err)
 		c.setErrorStatus(err)
@@ -95,11 +107,14 @@ func (c *InspectorService) Start(ctx context.Context, vmIDs []string, cred *mode
 		return fmt.Errorf("failed to init inspection table: %w", err)
 	}
 
-	runCtx, cancel := context.WithCancel(context.Background())
+	// the inspection outlives the request but stays in its trace
+	runCtx, cancel := context.WithCancel(trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx)))
 	c.cancel = cancel
 	c.done = make(chan any)
 
-	go c.run(runCtx, c.done)
+	c.events.Publish(ctx, models.AgentEventInspectionStarted, "inspection started", map[string]string{"vms": strconv.Itoa(len(vmIDs))})
+	job := c.jobs.Start(ctx, models.JobKindInspection)
+	go c.run(runCtx, c.done, job)
 
 	return nil
 }
@@ -191,7 +206,26 @@ func (c *InspectorService) WithBuilder(builder models.InspectorWorkBuilder) *Ins
 	return c
 }
 
-func (c *InspectorService) run(ctx context.Context, done chan any) {
+// WithEventService publishes the inspection events to events.
+func (c *InspectorService) WithEventService(events *EventService) *InspectorService {
+	c.events = events
+	return c
+}
+
+// WithJobService follows each inspection as a job of jobs.
+func (c *InspectorService) WithJobService(jobs *JobService) *InspectorService {
+	c.jobs = jobs
+	return c
+}
+
+// WithProxy sets the proxy of the requests to vCenter, the HTTP(S)_PROXY
+// environment variables being used when nil.
+func (c *InspectorService) WithProxy(proxy func(*http.Request) (*url.URL, error)) *InspectorService {
+	c.proxy = proxy
+	return c
+}
+
+func (c *InspectorService) run(ctx context.Context, done chan any, job string) {
 	defer close(done)
 	defer func() {
 		cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -205,6 +239,7 @@ func (c *InspectorService) run(ctx context.Context, done chan any) {
 		c.mu.Unlock()
 
 		c.closeVsphereClient(cleanupCtx)
+		c.publishResult(cleanupCtx, job)
 	}()
 
 	c.setState(models.InspectorStateRunning)
@@ -227,29 +262,37 @@ func (c *InspectorService) run(ctx context.Context, done chan any) {
 			return
 		}
 
+		started := time.Now()
 		if err := c.runVMWork(ctx, id, c.builder.Build(id)); err != nil {
 			var e *srvErrors.InspectorWorkError
 			switch {
 			case errors.As(err, &e):
+				metrics.InspectorVMDuration.WithLabelValues(metrics.ResultError).Observe(time.Since(started).Seconds())
+				c.events.Publish(ctx, models.AgentEventVMInspectionFailed, "VM inspection failed", map[string]string{"vmId": id, "error": err.Error()})
 				if setError := c.setVmErrorStatus(ctx, id, err); setError != nil {
 					c.setErrorStatus(err)
 					return
 				}
+				c.reportProgress(ctx, job)
 				continue // VM failed, move to next VM
 			case errors.Is(err, context.Canceled):
+				metrics.InspectorVMDuration.WithLabelValues(metrics.ResultCanceled).Observe(time.Since(started).Seconds())
 				c.setState(models.InspectorStateCanceled)
 				return
 			default:
+				metrics.InspectorVMDuration.WithLabelValues(metrics.ResultError).Observe(time.Since(started).Seconds())
 				c.setErrorStatus(err)
 				return
 			}
 		}
 
+		metrics.InspectorVMDuration.WithLabelValues(metrics.ResultSuccess).Observe(time.Since(started).Seconds())
 		if err := c.setVmState(ctx, id, models.InspectionStateCompleted); err != nil {
 			zap.S().Errorf("failed to set vm status to completed: %v", err)
 			c.setErrorStatus(err)
 			return
 		}
+		c.reportProgress(ctx, job)
 
 		zap.S().Debugw("VM inspection completed", "vmID", id)
 	}
@@ -258,11 +301,14 @@ func (c *InspectorService) run(ctx context.Context, done chan any) {
 	zap.S().Info("inspector finished work")
 }
 
-func (c *InspectorService) runVMWork(ctx context.Context, id string, units []models.InspectorWorkUnit) error {
+func (c *InspectorService) runVMWork(ctx context.Context, id string, units []models.InspectorWorkUnit) (err error) {
+	_, span := tracing.Tracer().Start(ctx, "inspector.vm", trace.WithAttributes(attribute.String("vm.id", id)))
+	defer func() { tracing.End(span, err) }()
+
 	for _, unit := range units {
 
 		future := c.scheduler.AddWork(func(ctx context.Context) (any, error) {
-			return unit.Work()(ctx)
+			return unit.Work()(trace.ContextWithSpan(ctx, span))
 		})
 
 		select {
@@ -273,7 +319,8 @@ func (c *InspectorService) runVMWork(ctx context.Context, id string, units []mod
 
 		case result := <-future.C():
 			if result.Err != nil {
-				zap.S().Errorw("VM inspection failed", "vmID", id, "error", result.Err)
+				zap.S().Errorw("VM inspection failed", "vmID", id, "error", result.Err,
+					"wait", result.Execution.Wait(), "duration", result.Execution.Duration())
 				return srvErrors.NewInspectorWorkError("work finished with error: %s", result.Err.Error())
 			}
 		}
@@ -297,6 +344,47 @@ func (c *InspectorService) setState(s models.InspectorState) {
 	c.status.Error = nil
 }
 
+// publishResult publishes the event of the end of an inspection, from the
+// state it ended in, and ends its job.
+func (c *InspectorService) publishResult(ctx context.Context, job string) {
+	status := c.GetStatus()
+	switch status.State {
+	case models.InspectorStateCompleted:
+		c.events.Publish(ctx, models.AgentEventInspectionCompleted, "inspection completed", nil)
+		c.jobs.Finish(ctx, job, models.JobStateCompleted, nil)
+	case models.InspectorStateCanceling, models.InspectorStateCanceled:
+		c.events.Publish(ctx, models.AgentEventInspectionCanceled, "inspection canceled", nil)
+		c.jobs.Finish(ctx, job, models.JobStateCanceled, nil)
+	case models.InspectorStateError:
+		details := map[string]string{}
+		if status.Error != nil {
+			details["error"] = status.Error.Error()
+		}
+		c.events.Publish(ctx, models.AgentEventInspectionFailed, "inspection failed", details)
+		c.jobs.Finish(ctx, job, models.JobStateFailed, status.Error)
+	}
+}
+
+// reportProgress reports to the job of the inspection the share of its VMs
+// already inspected, VMs being added while it runs.
+func (c *InspectorService) reportProgress(ctx context.Context, job string) {
+	if c.jobs == nil || job == "" {
+		return
+	}
+
+	statuses, err := c.store.Inspection().List(ctx, store.NewInspectionQueryFilter())
+	if err != nil || len(statuses) == 0 {
+		return
+	}
+	inspected := 0
+	for _, s := range statuses {
+		if s.State != models.InspectionStatePending && s.State != models.InspectionStateRunning {
+			inspected++
+		}
+	}
+	c.jobs.Progress(ctx, job, string(models.InspectorStateRunning), inspected*100/len(statuses))
+}
+
 func (c *InspectorService) setErrorStatus(err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -13,10 +13,11 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
-	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 // getVCenterCredentials returns test credentials for vCenter.
@@ -101,21 +102,15 @@ var _ = Describe("InspectorService", func() {
 
 	// Helper to insert test VMs into vinfo table
 	insertVM := func(id, name string) {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vinfo ("VM ID", "VM", "Powerstate", "Cluster", "Memory")
-			VALUES (?, ?, 'poweredOn', 'cluster-a', 4096)
-		`, id, name)
-		Expect(err).NotTo(HaveOccurred())
+		vm := fixtures.NewVM(id).WithName(name).WithPowerState("poweredOn").WithCluster("cluster-a").WithMemory(4096)
+		Expect(vm.Insert(ctx, db)).To(Succeed())
 	}
 
 	BeforeEach(func() {
 		ctx = context.Background()
 
 		var err error
-		db, err = store.NewDB(":memory:")
-		Expect(err).NotTo(HaveOccurred())
-
-		err = migrations.Run(ctx, db)
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		// Insert test VMs into vinfo (required for foreign key constraint)
@@ -565,21 +560,15 @@ var _ = Describe("InspectionStore", func() {
 
 	// Helper to insert test VMs into vinfo table
 	insertVM := func(id, name string) {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vinfo ("VM ID", "VM", "Powerstate", "Cluster", "Memory")
-			VALUES (?, ?, 'poweredOn', 'cluster-a', 4096)
-		`, id, name)
-		Expect(err).NotTo(HaveOccurred())
+		vm := fixtures.NewVM(id).WithName(name).WithPowerState("poweredOn").WithCluster("cluster-a").WithMemory(4096)
+		Expect(vm.Insert(ctx, db)).To(Succeed())
 	}
 
 	BeforeEach(func() {
 		ctx = context.Background()
 
 		var err error
-		db, err = store.NewDB(":memory:")
-		Expect(err).NotTo(HaveOccurred())
-
-		err = migrations.Run(ctx, db)
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		// Insert test VMs into vinfo (required for foreign key constraint)
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.uber.org/zap"
+
+	graphqlapi "github.com/kubev2v/assisted-migration-agent/api/graphql"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+)
+
+// graphMaxPageSize bounds the VMs of a page, like the REST listing.
+const graphMaxPageSize = 100
+
+// errGraphInternal is the error of a field failing on the store, logged with
+// its cause.
+var errGraphInternal = errors.New("internal error")
+
+// InventoryGraph answers the GraphQL queries of the inventory schema of
+// api/graphql. Only the fields a query selects are read, so the clients fetch
+// the VMs with their disks and concerns, the hosts with their datastores, in
+// a single request.
+type InventoryGraph struct {
+	store  *store.Store
+	schema *graphql.Schema
+}
+
+func NewInventoryGraphService(st *store.Store) (*InventoryGraph, error) {
+	schema, err := graphql.NewSchema(graphqlapi.Schema)
+	if err != nil {
+		return nil, err
+	}
+	return &InventoryGraph{store: st, schema: schema}, nil
+}
+
+// Execute runs the query of req.
+func (s *InventoryGraph) Execute(ctx context.Context, req graphql.Request) graphql.Response {
+	r := &graphResolver{store: s.store, concerns: make(map[string]*models.ConcernDetail)}
+	return s.schema.Execute(ctx, r.query(), req)
+}
+
+// graphResolver resolves the fields of a query. The hosts, datastores and
+// concern metadata are read once per query.
+type graphResolver struct {
+	store *store.Store
+
+	hosts      []models.Host
+	datastores []models.Datastore
+	concerns   map[string]*models.ConcernDetail
+}
+
+func (r *graphResolver) query() graphql.Object {
+	return graphql.Fields{
+		"vms": r.vms,
+		"vm": func(ctx context.Context, args map[string]any) (any, error) {
+			id, _ := graphql.String(args, "id")
+			vm, err := r.store.VM().Get(ctx, id)
+			if err != nil {
+				if srvErrors.IsResourceNotFoundError(err) {
+					return nil, nil
+				}
+				return nil, r.internal("vm", err)
+			}
+			return &graphVM{r: r, id: id, details: vm}, nil
+		},
+		"hosts": func(ctx context.Context, args map[string]any) (any, error) {
+			cluster, _ := graphql.String(args, "cluster")
+			hosts, err := r.store.Infrastructure().ListHosts(ctx, cluster)
+			if err != nil {
+				return nil, r.clusterError("hosts", err)
+			}
+			return r.hostObjects(hosts), nil
+		},
+		"datastores": func(ctx context.Context, args map[string]any) (any, error) {
+			cluster, _ := graphql.String(args, "cluster")
+			datastores, err := r.store.Infrastructure().ListDatastores(ctx, cluster)
+			if err != nil {
+				return nil, r.clusterError("datastores", err)
+			}
+			return r.datastoreObjects(datastores), nil
+		},
+		"networks": func(ctx context.Context, _ map[string]any) (any, error) {
+			networks, err := r.store.Infrastructure().ListNetworks(ctx)
+			if err != nil {
+				return nil, r.internal("networks", err)
+			}
+			objects := make([]graphql.Object, 0, len(networks))
+			for _, n := range networks {
+				objects = append(objects, graphql.Fields{
+					"name":     graphql.Value(n.Name),
+					"type":     graphql.Value(string(n.Type)),
+					"dvSwitch": graphql.Value(n.DVSwitch),
+					"vlanId":   graphql.Value(n.VlanID),
+					"vmCount":  graphql.Value(n.VMCount),
+				})
+			}
+			return objects, nil
+		},
+		"concern": func(ctx context.Context, args map[string]any) (any, error) {
+			id, _ := graphql.String(args, "id")
+			detail, err := r.concern(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if detail == nil {
+				return nil, nil
+			}
+			return &graphConcern{r: r, concern: models.Concern{ID: id, Label: detail.Label, Category: detail.Category}}, nil
+		},
+	}
+}
+
+// vms resolves the page of the VMs matching the arguments, by name.
+func (r *graphResolver) vms(ctx context.Context, args map[string]any) (any, error) {
+	page, _ := graphql.Int(args, "page")
+	pageSize, _ := graphql.Int(args, "pageSize")
+	pageSize = min(max(pageSize, 1), graphMaxPageSize)
+
+	params := VMListParams{
+		Clusters:   graphql.Strings(args, "clusters"),
+		Statuses:   graphql.Strings(args, "statuses"),
+		Severities: graphql.Strings(args, "severities"),
+		Sort:       []SortField{{Field: "name"}},
+		Cursor:     models.NewCursor(page, pageSize),
+	}
+	if minIssues, ok := graphql.Int(args, "minIssues"); ok {
+		params.MinIssues = minIssues
+	}
+	if encrypted, ok := graphql.Bool(args, "encrypted"); ok {
+		params.Encrypted = &encrypted
+	}
+
+	result, err := NewVMService(r.store).List(ctx, params)
+	if err != nil {
+		return nil, r.internal("vms", err)
+	}
+
+	items := make([]graphql.Object, 0, len(result.Items))
+	for _, vm := range result.Items {
+		items = append(items, &graphVM{r: r, id: vm.ID, summary: &vm})
+	}
+	return graphql.Fields{
+		"total":    graphql.Value(result.Total),
+		"page":     graphql.Value(result.Number()),
+		"pageSize": graphql.Value(pageSize),
+		"items":    graphql.Value(items),
+	}, nil
+}
+
+// allHosts returns the hosts of every cluster, read once.
+func (r *graphResolver) allHosts(ctx context.Context) ([]models.Host, error) {
+	if r.hosts == nil {
+		hosts, err := r.store.Infrastructure().ListHosts(ctx, "")
+		if err != nil {
+			return nil, r.internal("hosts", err)
+		}
+		r.hosts = hosts
+	}
+	return r.hosts, nil
+}
+
+// allDatastores returns the datastores of every cluster, read once.
+func (r *graphResolver) allDatastores(ctx context.Context) ([]models.Datastore, error) {
+	if r.datastores == nil {
+		datastores, err := r.store.Infrastructure().ListDatastores(ctx, "")
+		if err != nil {
+			return nil, r.internal("datastores", err)
+		}
+		r.datastores = datastores
+	}
+	return r.datastores, nil
+}
+
+// concern returns the concern id with its metadata, read once, and nil when
+// it is unknown.
+func (r *graphResolver) concern(ctx context.Context, id string) (*models.ConcernDetail, error) {
+	if detail, ok := r.concerns[id]; ok {
+		return detail, nil
+	}
+	detail, err := r.store.ConcernMetadata().Get(ctx, id)
+	if err != nil && !srvErrors.IsResourceNotFoundError(err) {
+		return nil, r.internal("concern", err)
+	}
+	r.concerns[id] = detail
+	return detail, nil
+}
+
+func (r *graphResolver) hostObjects(hosts []models.Host) []graphql.Object {
+	objects := make([]graphql.Object, 0, len(hosts))
+	for _, h := range hosts {
+		objects = append(objects, graphql.Fields{
+			"id":         graphql.Value(h.ID),
+			"cluster":    graphql.Value(h.Cluster),
+			"cpuCores":   graphql.Value(h.CPUCores),
+			"cpuSockets": graphql.Value(h.CPUSockets),
+			"memoryMB":   graphql.Value(h.MemoryMB),
+			"vendor":     graphql.Value(h.Vendor),
+			"model":      graphql.Value(h.Model),
+			"datastores": func(ctx context.Context, _ map[string]any) (any, error) {
+				all, err := r.allDatastores(ctx)
+				if err != nil {
+					return nil, err
+				}
+				var mounted []models.Datastore
+				for _, d := range all {
+					if slices.Contains(d.HostIDs, h.ID) {
+						mounted = append(mounted, d)
+					}
+				}
+				return r.datastoreObjects(mounted), nil
+			},
+		})
+	}
+	return objects
+}
+
+func (r *graphResolver) datastoreObjects(datastores []models.Datastore) []graphql.Object {
+	objects := make([]graphql.Object, 0, len(datastores))
+	for _, d := range datastores {
+		objects = append(objects, graphql.Fields{
+			"id":                      graphql.Value(d.ID),
+			"type":                    graphql.Value(d.Type),
+			"protocolType":            graphql.Value(d.ProtocolType),
+			"totalCapacityGB":         graphql.Value(d.TotalCapacityGB),
+			"freeCapacityGB":          graphql.Value(d.FreeCapacityGB),
+			"hardwareAcceleratedMove": graphql.Value(d.HardwareAcceleratedMove),
+			"hosts": func(ctx context.Context, _ map[string]any) (any, error) {
+				all, err := r.allHosts(ctx)
+				if err != nil {
+					return nil, err
+				}
+				var mounting []models.Host
+				for _, h := range all {
+					if slices.Contains(d.HostIDs, h.ID) {
+						mounting = append(mounting, h)
+					}
+				}
+				return r.hostObjects(mounting), nil
+			},
+		})
+	}
+	return objects
+}
+
+// clusterError returns the error of a listing of an unknown cluster as is,
+// the others as internal errors.
+func (r *graphResolver) clusterError(field string, err error) error {
+	if srvErrors.IsResourceNotFoundError(err) {
+		return err
+	}
+	return r.internal(field, err)
+}
+
+// internal logs the error err of field and returns errGraphInternal, the
+// store errors not being shown to the clients.
+func (r *graphResolver) internal(field string, err error) error {
+	zap.S().Named("inventory_graph_service").Errorw("failed to resolve field", "field", field, "error", err)
+	return errGraphInternal
+}
+
+// graphVM is a VM of a query, from its summary when it was listed. Its
+// details are read the first time one of their fields is selected.
+type graphVM struct {
+	r       *graphResolver
+	id      string
+	summary *models.VMSummary
+	details *models.VM
+}
+
+func (v *graphVM) Field(ctx context.Context, name string, _ map[string]any) (any, error) {
+	if v.summary != nil {
+		switch name {
+		case "id":
+			return v.summary.ID, nil
+		case "name":
+			return v.summary.Name, nil
+		case "powerState":
+			return v.summary.PowerState, nil
+		case "cluster":
+			return v.summary.Cluster, nil
+		case "memoryMB":
+			return v.summary.Memory, nil
+		case "diskSizeMB":
+			return v.summary.DiskSize, nil
+		case "issueCount":
+			return v.summary.IssueCount, nil
+		case "encrypted":
+			return v.summary.Encrypted, nil
+		case "inspectionState":
+			return string(v.summary.Status.State), nil
+		}
+	}
+
+	if v.details == nil {
+		details, err := v.r.store.VM().Get(ctx, v.id)
+		if err != nil {
+			return nil, v.r.internal("vm", err)
+		}
+		v.details = details
+	}
+	vm := v.details
+	switch name {
+	case "id":
+		return vm.ID, nil
+	case "name":
+		return vm.Name, nil
+	case "powerState":
+		return vm.PowerState, nil
+	case "cluster":
+		return vm.Cluster, nil
+	case "memoryMB":
+		return vm.MemoryMB, nil
+	case "diskSizeMB":
+		return vm.DiskSize, nil
+	case "issueCount":
+		return len(vm.Concerns), nil
+	case "encrypted":
+		return vm.Encrypted, nil
+	case "inspectionState":
+		return vm.InspectionState, nil
+	case "uuid":
+		return vm.UUID, nil
+	case "firmware":
+		return vm.Firmware, nil
+	case "datacenter":
+		return vm.Datacenter, nil
+	case "folder":
+		return vm.Folder, nil
+	case "hostName":
+		return vm.Host, nil
+	case "cpuCount":
+		return vm.CpuCount, nil
+	case "coresPerSocket":
+		return vm.CoresPerSocket, nil
+	case "guestName":
+		return vm.GuestName, nil
+	case "ipAddress":
+		return vm.IPAddress, nil
+	case "hardwareVersion":
+		return vm.HardwareVersion, nil
+	case "template":
+		return vm.IsTemplate, nil
+	case "tpmEnabled":
+		return vm.TpmEnabled, nil
+	case "secureBoot":
+		return vm.SecureBoot, nil
+	case "disks":
+		disks := make([]graphql.Object, 0, len(vm.Disks))
+		for _, d := range vm.Disks {
+			disks = append(disks, graphql.Fields{
+				"file":        graphql.Value(d.File),
+				"capacityMB":  graphql.Value(d.Capacity),
+				"bus":         graphql.Value(d.Bus),
+				"mode":        graphql.Value(d.Mode),
+				"shared":      graphql.Value(d.Shared),
+				"rdm":         graphql.Value(d.RDM),
+				"chainDepth":  graphql.Value(d.ChainDepth),
+				"linkedClone": graphql.Value(d.LinkedClone),
+			})
+		}
+		return disks, nil
+	case "nics":
+		nics := make([]graphql.Object, 0, len(vm.NICs))
+		for _, n := range vm.NICs {
+			nics = append(nics, graphql.Fields{
+				"mac":     graphql.Value(n.MAC),
+				"network": graphql.Value(n.Network),
+			})
+		}
+		return nics, nil
+	case "concerns":
+		concerns := make([]graphql.Object, 0, len(vm.Concerns))
+		for _, c := range vm.Concerns {
+			concerns = append(concerns, &graphConcern{r: v.r, concern: c})
+		}
+		return concerns, nil
+	}
+	return nil, errors.New("unknown field " + name)
+}
+
+// graphConcern is a concern of a query. Its metadata and number of VMs are
+// read the first time one of them is selected.
+type graphConcern struct {
+	r       *graphResolver
+	concern models.Concern
+}
+
+func (c *graphConcern) Field(ctx context.Context, name string, _ map[string]any) (any, error) {
+	switch name {
+	case "id":
+		return c.concern.ID, nil
+	case "label":
+		return c.concern.Label, nil
+	case "category":
+		return c.concern.Category, nil
+	}
+
+	detail, err := c.r.concern(ctx, c.concern.ID)
+	if err != nil {
+		return nil, err
+	}
+	if detail == nil {
+		detail = &models.ConcernDetail{ConcernMetadata: models.ConcernMetadata{Severity: models.SeverityOf(c.concern.Category)}}
+	}
+	switch name {
+	case "severity":
+		return detail.Severity, nil
+	case "title":
+		return detail.Title, nil
+	case "remediation":
+		return detail.Remediation, nil
+	case "docsURL":
+		return detail.DocsURL, nil
+	case "vmCount":
+		return detail.VMCount, nil
+	}
+	return nil, errors.New("unknown field " + name)
+}
@@ -0,0 +1,147 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("InventoryGraph", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		st  *store.Store
+		srv *services.InventoryGraph
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+
+		Expect(fixtures.Insert(ctx, db,
+			fixtures.NewVM("vm-1").WithName("web").WithPowerState("poweredOn").WithCluster("cluster-a").WithMemory(4096).WithDisk(100).
+				WithNIC("pg-100", "00:00:00:00:00:01"),
+			fixtures.NewVM("vm-2").WithName("db").WithPowerState("poweredOn").WithCluster("cluster-b").WithMemory(8192).WithDisk(500).
+				WithConcern("concern-1", "Outdated OS"),
+			fixtures.NewVM("vm-3").WithName("app").WithPowerState("poweredOn").WithCluster("cluster-a").WithMemory(2048).WithDisk(50).
+				WithConcern("concern-1", "Outdated OS"),
+		)).To(Succeed())
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO vhost ("Cluster", "# Cores", "# CPU", "Object ID", "# Memory", "Model", "Vendor", "Host") VALUES
+				('cluster-a', 16, 2, 'host-1', 65536, 'R740', 'Dell', '10.0.0.1'),
+				('cluster-b', 8, 1, 'host-2', 32768, NULL, NULL, '10.0.0.2')`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO vdatastore ("Hosts", "Address", "Name", "Free MiB", "MHA", "Capacity MiB", "Type") VALUES
+				('10.0.0.1, 10.0.0.2', NULL, 'shared-01', 40960, true, 102400, 'VMFS'),
+				('10.0.0.2', NULL, 'local-02', 10240, false, 20480, 'VMFS')`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(st.ConcernMetadata().Replace(ctx, []models.ConcernMetadata{
+			{ID: "concern-1", Title: "Unsupported guest", Severity: "critical", Remediation: "Upgrade the guest"},
+		})).To(Succeed())
+
+		srv, err = services.NewInventoryGraphService(st)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// execute runs query and returns the JSON of its response.
+	execute := func(query string, variables map[string]any) string {
+		data, err := json.Marshal(srv.Execute(ctx, graphql.Request{Query: query, Variables: variables}))
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	// Given VMs in two clusters
+	// When we query a page of the VMs of a cluster
+	// Then the page should list them by name with the selected fields
+	It("should page the VMs", func() {
+		// Act
+		result := execute(`query($clusters: [String!]) {
+			vms(clusters: $clusters, pageSize: 1) { total page pageSize items { id name memoryMB } }
+		}`, map[string]any{"clusters": []any{"cluster-a"}})
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {"vms": {
+			"total": 2, "page": 1, "pageSize": 1,
+			"items": [{"id": "vm-3", "name": "app", "memoryMB": 2048}]
+		}}}`))
+	})
+
+	// Given VMs flagged by a concern with metadata
+	// When we query the VMs with their NICs and concerns
+	// Then their details and the concern metadata should be returned in one response
+	It("should resolve the details of the VMs and their concerns", func() {
+		// Act
+		result := execute(`{
+			vms(clusters: ["cluster-b"]) { items { name nics { network } concerns { id label severity title vmCount } } }
+			vm(id: "vm-1") { name nics { mac network } concerns { id } }
+		}`, nil)
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {
+			"vms": {"items": [{"name": "db", "nics": [], "concerns": [
+				{"id": "concern-1", "label": "Outdated OS", "severity": "critical", "title": "Unsupported guest", "vmCount": 2}
+			]}]},
+			"vm": {"name": "web", "nics": [{"mac": "00:00:00:00:00:01", "network": "pg-100"}], "concerns": []}
+		}}`))
+	})
+
+	// Given hosts sharing a datastore
+	// When we query the hosts with their datastores and their hosts
+	// Then the relations should be resolved
+	It("should resolve the hosts and datastores relations", func() {
+		// Act
+		result := execute(`{ hosts(cluster: "cluster-a") { id datastores { id hosts { id } } } }`, nil)
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {"hosts": [
+			{"id": "host-1", "datastores": [{"id": "shared-01", "hosts": [{"id": "host-1"}, {"id": "host-2"}]}]}
+		]}}`))
+	})
+
+	// Given an unknown VM and an unknown concern
+	// When we query them
+	// Then they should be null without errors
+	It("should return null for unknown VMs and concerns", func() {
+		// Act
+		result := execute(`{ vm(id: "vm-9") { name } concern(id: "concern-9") { title } }`, nil)
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {"vm": null, "concern": null}}`))
+	})
+
+	// Given an unknown cluster
+	// When we query its hosts
+	// Then the data should be null with the not found error of the field
+	It("should report the unknown clusters", func() {
+		// Act
+		resp := srv.Execute(ctx, graphql.Request{Query: `{ hosts(cluster: "cluster-z") { id } }`})
+
+		// Assert
+		Expect(resp.Data).To(BeNil())
+		Expect(resp.Errors).To(HaveLen(1))
+		Expect(resp.Errors[0].Path.String()).To(Equal("hosts"))
+		Expect(resp.Errors[0].Message).To(Equal("cluster 'cluster-z' not found"))
+	})
+})
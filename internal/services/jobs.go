@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// jobInterrupted is the error of the jobs a previous run of the agent left running.
+const jobInterrupted = "interrupted by a restart of the agent"
+
+// JobService follows the long-running operations of the agent, the
+// collections, the inspections, the benchmarks and the bundle exports, as jobs with a state and a progress, so
+// the clients poll one model whatever the operation. The services report to it
+// as they run; a job is canceled through the canceler of its kind.
+type JobService struct {
+	store *store.Store
+
+	mu        sync.Mutex
+	cancelers map[models.JobKind]func(ctx context.Context) error
+}
+
+func NewJobService(st *store.Store) *JobService {
+	return &JobService{
+		store:     st,
+		cancelers: make(map[models.JobKind]func(ctx context.Context) error),
+	}
+}
+
+// WithCanceler cancels the running job of kind with cancel, which returns once
+// the operation stopped. A single operation of a kind runs at a time.
+func (s *JobService) WithCanceler(kind models.JobKind, cancel func(ctx context.Context) error) *JobService {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelers[kind] = cancel
+	return s
+}
+
+// Recover fails the jobs a previous run of the agent left running, their
+// operations having stopped with it.
+func (s *JobService) Recover(ctx context.Context) error {
+	n, err := s.store.Job().FailRunning(ctx, jobInterrupted, time.Now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		zap.S().Named("job_service").Warnw("failed the jobs interrupted by the previous run", "count", n)
+	}
+	return nil
+}
+
+// Start records a running job of kind and returns its id, to report its
+// progress with. A failure to store it is logged and an empty id returned, the
+// operation running anyway. Starting on a nil JobService does nothing, so the
+// services can run without one.
+func (s *JobService) Start(ctx context.Context, kind models.JobKind) string {
+	if s == nil {
+		return ""
+	}
+
+	now := time.Now().UTC()
+	job := models.Job{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		State:     models.JobStateRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// the job is stored even when the request that started it went away
+	if err := s.store.Job().Create(context.WithoutCancel(ctx), job); err != nil {
+		zap.S().Named("job_service").Errorw("failed to record job", "kind", kind, "error", err)
+		return ""
+	}
+	return job.ID
+}
+
+// Progress records the phase and progress, in percent, of the running job id.
+func (s *JobService) Progress(ctx context.Context, id, phase string, progress int) {
+	s.update(ctx, id, func(job *models.Job) {
+		job.Phase = phase
+		job.Progress = min(max(progress, 0), 100)
+	})
+}
+
+// Finish ends the job id in state, with the error err of a failed job.
+func (s *JobService) Finish(ctx context.Context, id string, state models.JobState, err error) {
+	s.update(ctx, id, func(job *models.Job) {
+		job.State = state
+		if state == models.JobStateCompleted {
+			job.Progress = 100
+		}
+		if err != nil {
+			job.Error = err.Error()
+		}
+		finished := time.Now().UTC()
+		job.FinishedAt = &finished
+	})
+}
+
+// List returns the page at cursor of the jobs matching filter, newest first.
+func (s *JobService) List(ctx context.Context, filter models.JobFilter, cursor models.Cursor) (models.Page[models.Job], error) {
+	jobs, err := s.store.Job().List(ctx, filter, cursor)
+	if err != nil {
+		return models.Page[models.Job]{}, err
+	}
+	total, err := s.store.Job().Count(ctx, filter)
+	if err != nil {
+		return models.Page[models.Job]{}, err
+	}
+	return models.NewPage(jobs, cursor, total), nil
+}
+
+// Get returns the job id, a ResourceNotFoundError when there is no such job.
+func (s *JobService) Get(ctx context.Context, id string) (*models.Job, error) {
+	return s.store.Job().Get(ctx, id)
+}
+
+// Cancel stops the operation of the job id and returns the job once it ended.
+// It fails with a JobNotRunningError when the job already ended.
+func (s *JobService) Cancel(ctx context.Context, id string) (*models.Job, error) {
+	job, err := s.store.Job().Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State.IsFinal() {
+		return nil, srvErrors.NewJobNotRunningError(id, string(job.State))
+	}
+
+	s.mu.Lock()
+	cancel, ok := s.cancelers[job.Kind]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("jobs of kind %s cannot be canceled", job.Kind)
+	}
+	if err := cancel(ctx); err != nil {
+		// the operation may have ended meanwhile
+		if ended, getErr := s.store.Job().Get(ctx, id); getErr == nil && ended.State.IsFinal() {
+			return nil, srvErrors.NewJobNotRunningError(id, string(ended.State))
+		}
+		return nil, err
+	}
+
+	return s.store.Job().Get(ctx, id)
+}
+
+// update applies change to the running job id. Nothing is done for a nil
+// JobService or an empty id, a failure being logged.
+func (s *JobService) update(ctx context.Context, id string, change func(job *models.Job)) {
+	if s == nil || id == "" {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	log := zap.S().Named("job_service")
+	job, err := s.store.Job().Get(ctx, id)
+	if err != nil {
+		log.Errorw("failed to get job", "job_id", id, "error", err)
+		return
+	}
+	if job.State.IsFinal() {
+		return
+	}
+
+	change(job)
+	job.UpdatedAt = time.Now().UTC()
+	if err := s.store.Job().Update(ctx, *job); err != nil {
+		log.Errorw("failed to update job", "job_id", id, "error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+// blockingWorkBuilder builds a collection connecting until it is canceled.
+type blockingWorkBuilder struct{}
+
+func (b *blockingWorkBuilder) WithCredentials(creds *models.Credentials) models.WorkBuilder {
+	return b
+}
+
+func (b *blockingWorkBuilder) Build() []models.WorkUnit {
+	return []models.WorkUnit{{
+		Status: func() models.CollectorStatus {
+			return models.CollectorStatus{State: models.CollectorStateConnecting}
+		},
+		Work: func() func(ctx context.Context) (any, error) {
+			return func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+		},
+	}}
+}
+
+var _ = Describe("JobService", func() {
+	var (
+		ctx   context.Context
+		db    *sql.DB
+		st    *store.Store
+		sched *scheduler.Scheduler
+		jobs  *services.JobService
+		creds *models.Credentials
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+		sched = scheduler.NewScheduler(1)
+		jobs = services.NewJobService(st)
+		creds = &models.Credentials{URL: "https://vcenter.example.com", Username: "admin", Password: "secret"}
+	})
+
+	AfterEach(func() {
+		sched.Close()
+		db.Close()
+	})
+
+	// listJobs returns the jobs of kind, newest first
+	listJobs := func(kind models.JobKind) []models.Job {
+		page, err := jobs.List(ctx, models.JobFilter{Kinds: []models.JobKind{kind}}, models.Cursor{Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		return page.Items
+	}
+
+	// Given a collector following its collections as jobs
+	// When a collection completes
+	// Then its job should be completed at 100%
+	It("should follow a collection to its end", func() {
+		// Arrange
+		collector := services.NewCollectorService(sched, st, &mockWorkBuilder{store: st}).WithJobService(jobs)
+
+		// Act
+		Expect(collector.Start(ctx, creds)).To(Succeed())
+
+		// Assert
+		Eventually(func() models.JobState {
+			jobs := listJobs(models.JobKindCollection)
+			if len(jobs) == 0 {
+				return ""
+			}
+			return jobs[0].State
+		}).Should(Equal(models.JobStateCompleted))
+		job := listJobs(models.JobKindCollection)[0]
+		Expect(job.Progress).To(Equal(100))
+		Expect(job.Phase).To(Equal(string(models.CollectorStateCollected)))
+		Expect(job.FinishedAt).NotTo(BeNil())
+	})
+
+	// Given a collector following its collections as jobs
+	// When a collection fails
+	// Then its job should be failed with the error
+	It("should fail the job of a failed collection", func() {
+		// Arrange
+		collector := services.NewCollectorService(sched, st, &mockWorkBuilder{store: st, collectErr: errors.New("collection failed")}).
+			WithJobService(jobs)
+
+		// Act
+		Expect(collector.Start(ctx, creds)).To(Succeed())
+
+		// Assert
+		Eventually(func() models.JobState {
+			jobs := listJobs(models.JobKindCollection)
+			if len(jobs) == 0 {
+				return ""
+			}
+			return jobs[0].State
+		}).Should(Equal(models.JobStateFailed))
+		job := listJobs(models.JobKindCollection)[0]
+		Expect(job.Error).To(Equal("collection failed"))
+		Expect(job.Phase).To(Equal(string(models.CollectorStateCollecting)))
+	})
+
+	// Given a running collection
+	// When its job is canceled
+	// Then the collection should stop and the job be canceled
+	It("should cancel a running collection", func() {
+		// Arrange
+		collector := services.NewCollectorService(sched, st, &blockingWorkBuilder{}).WithJobService(jobs)
+		jobs.WithCanceler(models.JobKindCollection, func(context.Context) error {
+			collector.Stop()
+			return nil
+		})
+		Expect(collector.Start(ctx, creds)).To(Succeed())
+		Eventually(func() []models.Job { return listJobs(models.JobKindCollection) }).Should(HaveLen(1))
+		id := listJobs(models.JobKindCollection)[0].ID
+
+		// Act
+		job, err := jobs.Cancel(ctx, id)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.State).To(Equal(models.JobStateCanceled))
+		Expect(collector.GetStatus().State).To(Equal(models.CollectorStateReady))
+	})
+
+	// Given a completed job
+	// When it is canceled
+	// Then it should fail with JobNotRunningError
+	It("should not cancel a finished job", func() {
+		// Arrange
+		id := jobs.Start(ctx, models.JobKindCollection)
+		jobs.Finish(ctx, id, models.JobStateCompleted, nil)
+
+		// Act
+		_, err := jobs.Cancel(ctx, id)
+
+		// Assert
+		Expect(srvErrors.IsJobNotRunningError(err)).To(BeTrue())
+	})
+
+	// Given a job left running by a previous run of the agent
+	// When the jobs are recovered at the start
+	// Then it should be failed
+	It("should fail the jobs interrupted by a restart", func() {
+		// Arrange
+		id := jobs.Start(ctx, models.JobKindInspection)
+		jobs.Progress(ctx, id, "running", 40)
+
+		// Act
+		err := jobs.Recover(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		job, err := jobs.Get(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.State).To(Equal(models.JobStateFailed))
+		Expect(job.Progress).To(Equal(40))
+		Expect(job.FinishedAt).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// LeaderElection elects the leader of a high availability pair: two agents of
+// the same source sharing a lease. The leader renews the lease every third of
+// its duration; the standby takes it over once the lease did not change for a
+// whole duration, measured on its own clock so the clocks of the pair need not
+// agree. A lease taken over leads from the next attempt, once no other agent
+// overwrote it, so two agents racing for a free lease do not both lead.
+//
+// The callbacks of OnElected run for each term, with a context canceled when it
+// ends: the leader steps down when another agent holds the lease, when it could
+// not renew it for two thirds of its duration, before the standby may take it
+// over, and when Run returns.
+type LeaderElection struct {
+	lease    models.Lease
+	identity string
+	ttl      time.Duration
+	// events receives the start and the end of the terms, nil when they are not
+	// recorded
+	events    *EventService
+	onElected []func(ctx context.Context)
+	onDemoted []func()
+
+	mu     sync.RWMutex
+	leader bool
+	status models.HAStatus
+
+	// the last record read and when it changed, only used by Run
+	observed   models.LeaseRecord
+	observedAt time.Time
+	// renewedAt is the last write of the lease by this agent, only used by Run
+	renewedAt time.Time
+}
+
+// NewLeaderElection returns the election of identity, which must differ
+// between the agents of the pair, for the lease of duration ttl.
+func NewLeaderElection(lease models.Lease, identity string, ttl time.Duration) *LeaderElection {
+	return &LeaderElection{
+		lease:    lease,
+		identity: identity,
+		ttl:      ttl,
+		status:   models.HAStatus{Role: models.HARoleStandby, Identity: identity},
+	}
+}
+
+// OnElected runs f in a goroutine at the start of each term, with a context
+// canceled when the term ends. The term ends once f returned.
+func (e *LeaderElection) OnElected(f func(ctx context.Context)) *LeaderElection {
+	e.onElected = append(e.onElected, f)
+	return e
+}
+
+// OnDemoted runs f at the end of each term, once the callbacks of OnElected
+// returned.
+func (e *LeaderElection) OnDemoted(f func()) *LeaderElection {
+	e.onDemoted = append(e.onDemoted, f)
+	return e
+}
+
+// WithEventService publishes the start and the end of the terms to events.
+func (e *LeaderElection) WithEventService(events *EventService) *LeaderElection {
+	e.events = events
+	return e
+}
+
+// IsLeader reports whether the agent leads. An agent without election, e nil,
+// always does.
+func (e *LeaderElection) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Status returns the role of the agent and the holder of the lease.
+func (e *LeaderElection) Status() models.HAStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.status
+}
+
+// Run campaigns for the lease until ctx is done, then ends the term and
+// releases the lease when it leads.
+func (e *LeaderElection) Run(ctx context.Context) {
+	log := zap.S().Named("leader_election")
+	tick := time.NewTicker(e.ttl / 3)
+	defer tick.Stop()
+
+	var (
+		endTerm context.CancelFunc
+		term    sync.WaitGroup
+	)
+	demote := func() {
+		endTerm()
+		term.Wait()
+		endTerm = nil
+		e.setLeader(false)
+		for _, f := range e.onDemoted {
+			f()
+		}
+		log.Warnw("stepped down", "identity", e.identity)
+		e.events.Publish(context.WithoutCancel(ctx), models.AgentEventHADemoted, "agent stepped down", map[string]string{"identity": e.identity})
+	}
+
+	for {
+		leads, err := e.attempt(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Warnw("failed to renew the lease", "identity", e.identity, "error", err)
+		}
+
+		switch {
+		case leads && endTerm == nil:
+			var termCtx context.Context
+			termCtx, endTerm = context.WithCancel(ctx)
+			e.setLeader(true)
+			for _, f := range e.onElected {
+				term.Add(1)
+				go func() {
+					defer term.Done()
+					f(termCtx)
+				}()
+			}
+			log.Infow("elected leader", "identity", e.identity)
+			e.events.Publish(ctx, models.AgentEventHAElected, "agent elected leader", map[string]string{"identity": e.identity})
+		case !leads && endTerm != nil:
+			demote()
+		}
+
+		select {
+		case <-ctx.Done():
+			if endTerm != nil {
+				demote()
+				e.release()
+			}
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// attempt takes or renews the lease and reports whether the agent leads.
+func (e *LeaderElection) attempt(ctx context.Context) (bool, error) {
+	now := time.Now()
+	r, err := e.lease.Get(ctx)
+	if err != nil {
+		return e.canKeep(now), err
+	}
+	if r.Holder != e.observed.Holder || !r.RenewedAt.Equal(e.observed.RenewedAt) {
+		e.observed, e.observedAt = r, now
+	}
+
+	if r.Holder != "" && r.Holder != e.identity && now.Before(e.observedAt.Add(e.ttl)) {
+		e.setHolder(r)
+		return false, nil
+	}
+
+	next := models.LeaseRecord{Holder: e.identity, AcquiredAt: r.AcquiredAt, RenewedAt: now.UTC()}
+	if r.Holder != e.identity {
+		next.AcquiredAt = next.RenewedAt
+	}
+	if err := e.lease.Put(ctx, next); err != nil {
+		return e.canKeep(now), err
+	}
+	e.observed, e.observedAt, e.renewedAt = next, now, now
+	e.setHolder(next)
+	return r.Holder == e.identity, nil
+}
+
+// canKeep reports whether a leader failing to renew the lease at now still
+// leads: until two thirds of its duration, the standby waiting a whole one.
+func (e *LeaderElection) canKeep(now time.Time) bool {
+	return e.IsLeader() && now.Sub(e.renewedAt) < e.ttl*2/3
+}
+
+// release frees the lease so the standby takes it over without waiting for it
+// to expire.
+func (e *LeaderElection) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl/3)
+	defer cancel()
+	if err := e.lease.Put(ctx, models.LeaseRecord{}); err != nil {
+		zap.S().Named("leader_election").Warnw("failed to release the lease", "identity", e.identity, "error", err)
+	}
+}
+
+func (e *LeaderElection) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = leader
+	e.status.Role = models.HARoleStandby
+	if leader {
+		e.status.Role = models.HARoleLeader
+	}
+}
+
+func (e *LeaderElection) setHolder(r models.LeaseRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.Leader = r.Holder
+	e.status.RenewedAt = r.RenewedAt
+}
@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/lease"
+)
+
+var _ = Describe("LeaderElection", func() {
+	const ttl = 300 * time.Millisecond
+
+	var (
+		ctx  context.Context
+		file *lease.File
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		file = lease.NewFile(filepath.Join(GinkgoT().TempDir(), "agent.lease"))
+	})
+
+	// run campaigns with election until the returned function stops it
+	run := func(election *services.LeaderElection) func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan any)
+		go func() {
+			defer close(done)
+			election.Run(runCtx)
+		}()
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+
+	// Given a single agent
+	// When it campaigns
+	// Then it should be elected, its term ending with the campaign and the lease released
+	It("should elect a single agent", func() {
+		// Arrange
+		var term context.Context
+		elected := make(chan any)
+		election := services.NewLeaderElection(file, "agent-a", ttl).OnElected(func(ctx context.Context) {
+			term = ctx
+			close(elected)
+			<-ctx.Done()
+		})
+
+		// Act
+		stop := run(election)
+
+		// Assert
+		Eventually(elected).Should(BeClosed())
+		Expect(election.IsLeader()).To(BeTrue())
+		status := election.Status()
+		Expect(status.Role).To(Equal(models.HARoleLeader))
+		Expect(status.Leader).To(Equal("agent-a"))
+		stop()
+		Expect(term.Err()).To(HaveOccurred())
+		Expect(election.IsLeader()).To(BeFalse())
+		r, err := file.Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Holder).To(BeEmpty())
+	})
+
+	// Given two agents sharing a lease
+	// When the leader stops
+	// Then the standby should take over
+	It("should fail over to the standby", func() {
+		// Arrange
+		a := services.NewLeaderElection(file, "agent-a", ttl)
+		stopA := run(a)
+		Eventually(a.IsLeader).Should(BeTrue())
+		b := services.NewLeaderElection(file, "agent-b", ttl)
+		stopB := run(b)
+		defer stopB()
+		Consistently(b.IsLeader, ttl*2).Should(BeFalse())
+		status := b.Status()
+		Expect(status.Role).To(Equal(models.HARoleStandby))
+		Expect(status.Leader).To(Equal("agent-a"))
+
+		// Act
+		stopA()
+
+		// Assert
+		Eventually(b.IsLeader).Should(BeTrue())
+	})
+
+	// Given a leader whose lease another agent holds
+	// When it attempts to renew the lease
+	// Then it should step down
+	It("should step down when another agent holds the lease", func() {
+		// Arrange
+		var demoted atomic.Bool
+		election := services.NewLeaderElection(file, "agent-a", ttl).OnDemoted(func() { demoted.Store(true) })
+		stop := run(election)
+		defer stop()
+		Eventually(election.IsLeader).Should(BeTrue())
+
+		// Act
+		now := time.Now().UTC()
+		Expect(file.Put(ctx, models.LeaseRecord{Holder: "agent-b", AcquiredAt: now, RenewedAt: now})).To(Succeed())
+
+		// Assert
+		Eventually(demoted.Load).Should(BeTrue())
+		Expect(election.IsLeader()).To(BeFalse())
+	})
+
+	// Given an agent without election
+	// When we ask whether it leads
+	// Then it should
+	It("should lead without election", func() {
+		// Arrange
+		var election *services.LeaderElection
+
+		// Act & Assert
+		Expect(election.IsLeader()).To(BeTrue())
+	})
+})
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+// baseLoginDelay is the wait after a first failed login, doubled by each following one.
+const baseLoginDelay = time.Second
+
+// LoginThrottle slows down the clients guessing the credentials of the local
+// API: each failure of a client IP doubles the time it must wait before its
+// credentials are checked again, up to a lockout after maxFailures. The
+// failures are persisted so a restart does not lift a lockout, and forgotten
+// after a valid login or lockout without failure. Wait reserves the attempts
+// until Failed, Succeeded or Aborted, so parallel requests cannot all be
+// checked before their failures count.
+type LoginThrottle struct {
+	store       *store.Store
+	maxFailures int
+	lockout     time.Duration
+
+	mu       sync.Mutex // protects loaded, failures and pending
+	loaded   bool
+	failures map[string]models.LoginFailures
+	// pending counts the attempts of each IP reserved by Wait and not yet checked
+	pending map[string]int
+}
+
+func NewLoginThrottleService(st *store.Store, maxFailures int, lockout time.Duration) *LoginThrottle {
+	return &LoginThrottle{
+		store:       st,
+		maxFailures: maxFailures,
+		lockout:     lockout,
+		failures:    make(map[string]models.LoginFailures),
+		pending:     make(map[string]int),
+	}
+}
+
+// Wait returns how long ip must wait before its credentials are checked, 0
+// when they can be checked now, reserving the attempt until Failed, Succeeded
+// or Aborted. An IP with failures has one attempt checked at a time, others up
+// to maxFailures, the next ones waiting baseLoginDelay.
+func (t *LoginThrottle) Wait(ctx context.Context, ip string) (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.load(ctx); err != nil {
+		return 0, err
+	}
+	f := t.failures[ip]
+	if wait := time.Until(f.BlockedUntil); wait > 0 {
+		return wait, nil
+	}
+	if pending := t.pending[ip]; pending > 0 && (f.Count > 0 || pending >= t.maxFailures) {
+		return baseLoginDelay, nil
+	}
+	t.pending[ip]++
+	return 0, nil
+}
+
+// Failed records invalid credentials sent by ip. A failure to persist it is
+// logged, the failure still counts until the agent restarts.
+func (t *LoginThrottle) Failed(ctx context.Context, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.release(ip)
+	log := zap.S().Named("login_throttle_service")
+	// the failure is recorded even when the client went away
+	ctx = context.WithoutCancel(ctx)
+	if err := t.load(ctx); err != nil {
+		log.Errorw("failed to load login failures", "error", err)
+	}
+
+	now := time.Now()
+	t.forget(ctx, now)
+
+	f, ok := t.failures[ip]
+	if !ok {
+		f = models.LoginFailures{IP: ip}
+	}
+	f.Count++
+	f.LastFailure = now
+	f.BlockedUntil = now.Add(t.delay(f.Count))
+	t.failures[ip] = f
+
+	if f.Count >= t.maxFailures {
+		log.Warnw("client locked out after failed logins", "ip", ip, "failures", f.Count, "until", f.BlockedUntil)
+	}
+	if err := t.store.LoginFailure().Save(ctx, f); err != nil {
+		log.Errorw("failed to persist login failure", "ip", ip, "error", err)
+	}
+}
+
+// Succeeded records valid credentials sent by ip, forgetting its failures.
+func (t *LoginThrottle) Succeeded(ctx context.Context, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.release(ip)
+	if _, ok := t.failures[ip]; !ok {
+		return
+	}
+	delete(t.failures, ip)
+	if err := t.store.LoginFailure().Delete(context.WithoutCancel(ctx), ip); err != nil {
+		zap.S().Named("login_throttle_service").Errorw("failed to delete login failures", "ip", ip, "error", err)
+	}
+}
+
+// Aborted releases the attempt of ip whose credentials could not be checked.
+func (t *LoginThrottle) Aborted(ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.release(ip)
+}
+
+// release gives back an attempt of ip reserved by Wait. The caller must hold mu.
+func (t *LoginThrottle) release(ip string) {
+	if t.pending[ip] > 1 {
+		t.pending[ip]--
+	} else {
+		delete(t.pending, ip)
+	}
+}
+
+// delay returns the wait after count failures.
+func (t *LoginThrottle) delay(count int) time.Duration {
+	if count >= t.maxFailures {
+		return t.lockout
+	}
+	delay := baseLoginDelay
+	for i := 1; i < count && delay < t.lockout; i++ {
+		delay *= 2
+	}
+	return min(delay, t.lockout)
+}
+
+// forget drops the failures of the IPs that had none during the last lockout
+// and are no longer blocked.
+func (t *LoginThrottle) forget(ctx context.Context, now time.Time) {
+	expired := now.Add(-t.lockout)
+	for ip, f := range t.failures {
+		if f.LastFailure.Before(expired) && f.BlockedUntil.Before(expired) {
+			delete(t.failures, ip)
+		}
+	}
+	if err := t.store.LoginFailure().DeleteBefore(ctx, expired); err != nil {
+		zap.S().Named("login_throttle_service").Errorw("failed to delete expired login failures", "error", err)
+	}
+}
+
+// load reads the failures persisted by the previous runs once.
+func (t *LoginThrottle) load(ctx context.Context) error {
+	if t.loaded {
+		return nil
+	}
+	failures, err := t.store.LoginFailure().List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range failures {
+		t.failures[f.IP] = f
+	}
+	t.loaded = true
+	return nil
+}
@@ -0,0 +1,169 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("LoginThrottle", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		st  *store.Store
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	wait := func(srv *services.LoginThrottle, ip string) time.Duration {
+		d, err := srv.Wait(ctx, ip)
+		Expect(err).NotTo(HaveOccurred())
+		return d
+	}
+
+	// Given a client failing three logins
+	// When we ask how long it must wait
+	// Then the wait should have doubled after each failure, other clients not waiting
+	It("doubles the wait after each failure", func() {
+		// Arrange
+		srv := services.NewLoginThrottleService(st, 10, time.Hour)
+
+		// Act
+		srv.Failed(ctx, "10.0.0.1")
+		first := wait(srv, "10.0.0.1")
+		srv.Failed(ctx, "10.0.0.1")
+		srv.Failed(ctx, "10.0.0.1")
+		third := wait(srv, "10.0.0.1")
+
+		// Assert
+		Expect(first).To(BeNumerically("~", time.Second, 500*time.Millisecond))
+		Expect(third).To(BeNumerically("~", 4*time.Second, 500*time.Millisecond))
+		Expect(wait(srv, "10.0.0.2")).To(BeZero())
+	})
+
+	// Given a client reaching the maximum of failures
+	// When the agent restarts
+	// Then the client should still be locked out
+	It("keeps the lockout across restarts", func() {
+		// Arrange
+		srv := services.NewLoginThrottleService(st, 3, time.Hour)
+		for range 3 {
+			srv.Failed(ctx, "10.0.0.1")
+		}
+
+		// Act
+		restarted := services.NewLoginThrottleService(st, 3, time.Hour)
+
+		// Assert
+		Expect(wait(srv, "10.0.0.1")).To(BeNumerically("~", time.Hour, time.Second))
+		Expect(wait(restarted, "10.0.0.1")).To(BeNumerically("~", time.Hour, time.Second))
+	})
+
+	// Given a client sending many attempts in parallel
+	// When they all ask to be checked before the first failure is recorded
+	// Then only the maximum of failures should pass, then the client should be locked out
+	It("reserves the parallel attempts", func() {
+		// Arrange
+		srv := services.NewLoginThrottleService(st, 5, time.Hour)
+		var (
+			wg     sync.WaitGroup
+			passed atomic.Int32
+		)
+
+		// Act
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if wait(srv, "10.0.0.1") == 0 {
+					passed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		for range passed.Load() {
+			srv.Failed(ctx, "10.0.0.1")
+		}
+
+		// Assert
+		Expect(passed.Load()).To(BeEquivalentTo(5))
+		Expect(wait(srv, "10.0.0.1")).To(BeNumerically("~", time.Hour, time.Second))
+	})
+
+	// Given a client with a failure whose wait is over
+	// When it sends two attempts in parallel
+	// Then only one should be checked until it is released
+	It("checks one attempt at a time after a failure", func() {
+		// Arrange
+		srv := services.NewLoginThrottleService(st, 5, time.Hour)
+		srv.Failed(ctx, "10.0.0.1")
+		time.Sleep(1100 * time.Millisecond)
+
+		// Act
+		first := wait(srv, "10.0.0.1")
+		second := wait(srv, "10.0.0.1")
+		srv.Aborted("10.0.0.1")
+		third := wait(srv, "10.0.0.1")
+
+		// Assert
+		Expect(first).To(BeZero())
+		Expect(second).To(Equal(time.Second))
+		Expect(third).To(BeZero())
+	})
+
+	// Given a client failing many logins before the maximum
+	// When we ask how long it must wait
+	// Then the wait should not exceed the lockout
+	It("caps the wait to the lockout", func() {
+		// Arrange
+		srv := services.NewLoginThrottleService(st, 10, 3*time.Second)
+
+		// Act
+		for range 5 {
+			srv.Failed(ctx, "10.0.0.1")
+		}
+
+		// Assert
+		Expect(wait(srv, "10.0.0.1")).To(BeNumerically("~", 3*time.Second, 500*time.Millisecond))
+	})
+
+	// Given a client with failed logins
+	// When it sends valid credentials
+	// Then its failures should be forgotten, also after a restart
+	It("forgets the failures after a valid login", func() {
+		// Arrange
+		srv := services.NewLoginThrottleService(st, 10, time.Hour)
+		srv.Failed(ctx, "10.0.0.1")
+		srv.Failed(ctx, "10.0.0.1")
+
+		// Act
+		srv.Succeeded(ctx, "10.0.0.1")
+
+		// Assert
+		Expect(wait(srv, "10.0.0.1")).To(BeZero())
+		restarted := services.NewLoginThrottleService(st, 10, time.Hour)
+		Expect(wait(restarted, "10.0.0.1")).To(BeZero())
+	})
+})
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/forklift"
+)
+
+// criticalConcern is the category of the concerns blocking the migration of a VM.
+const criticalConcern = "Critical"
+
+// PlanService generates the migration plans of the VMs selected in waves: the
+// order the VMs are migrated in, the time their disks take to transfer and the
+// resources the target needs for them. The plans are kept until deleted, to be
+// exported as JSON or as the CRs of Forklift.
+type PlanService struct {
+	store *store.Store
+}
+
+func NewPlanService(st *store.Store) *PlanService {
+	return &PlanService{store: st}
+}
+
+// Generate generates and stores the plan of req, whose waves and VMs were
+// validated by the caller. The waves keep their order; the VMs of a wave are
+// migrated from the smallest disks to the largest, so most of the VMs are
+// migrated early. The transfer times are estimated at the throughput of req,
+// else the one measured by the latest benchmark, else the default one. It
+// fails with a ResourceNotFoundError when a VM is not in the inventory.
+func (s *PlanService) Generate(ctx context.Context, req models.PlanRequest) (*models.Plan, error) {
+	plan := &models.Plan{
+		ID:               uuid.New().String(),
+		Name:             req.Name,
+		CreatedAt:        time.Now().UTC(),
+		ThroughputMBps:   models.DefaultPlanThroughputMBps,
+		ThroughputSource: models.PlanThroughputDefault,
+	}
+	if req.ThroughputMBps > 0 {
+		plan.ThroughputMBps = req.ThroughputMBps
+		plan.ThroughputSource = models.PlanThroughputRequest
+	} else {
+		bench, err := s.store.Benchmark().Latest(ctx)
+		switch {
+		case err == nil && bench.ThroughputMBps() > 0:
+			plan.ThroughputMBps = bench.ThroughputMBps()
+			plan.ThroughputSource = models.PlanThroughputBenchmark
+		case err != nil && !srvErrors.IsResourceNotFoundError(err):
+			return nil, err
+		}
+	}
+
+	order := 0
+	for i, w := range req.Waves {
+		wave := models.PlanWave{Name: w.Name}
+		if wave.Name == "" {
+			wave.Name = fmt.Sprintf("wave-%d", i+1)
+		}
+		for _, id := range w.VMIDs {
+			vm, err := s.store.VM().Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			wave.VMs = append(wave.VMs, planVM(vm, plan.ThroughputMBps))
+		}
+
+		slices.SortStableFunc(wave.VMs, func(a, b models.PlanVM) int {
+			return cmp.Or(cmp.Compare(a.DiskMB, b.DiskMB), strings.Compare(a.Name, b.Name))
+		})
+		for j := range wave.VMs {
+			order++
+			wave.VMs[j].Order = order
+			wave.Resources.Add(wave.VMs[j])
+			plan.Resources.Add(wave.VMs[j])
+		}
+		wave.EstimatedTransfer = transferTime(wave.Resources.StorageMB, plan.ThroughputMBps)
+		plan.EstimatedTransfer += wave.EstimatedTransfer
+		plan.Waves = append(plan.Waves, wave)
+	}
+
+	if err := s.store.Plan().Create(ctx, *plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Get returns the plan id, a ResourceNotFoundError when there is no such plan.
+func (s *PlanService) Get(ctx context.Context, id string) (*models.Plan, error) {
+	return s.store.Plan().Get(ctx, id)
+}
+
+// List returns the plans, newest first.
+func (s *PlanService) List(ctx context.Context) ([]models.Plan, error) {
+	return s.store.Plan().List(ctx)
+}
+
+// Forklift returns the Forklift CRs migrating the plan id to target, see
+// forklift.Manifests. It fails with a ResourceNotFoundError when there is no
+// such plan or a VM of the plan left the inventory.
+func (s *PlanService) Forklift(ctx context.Context, id string, target models.ForkliftTarget) ([]byte, error) {
+	plan, err := s.store.Plan().Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	vms := make(map[string]*models.VM)
+	for _, w := range plan.Waves {
+		for _, pvm := range w.VMs {
+			vm, err := s.store.VM().Get(ctx, pvm.ID)
+			if err != nil {
+				return nil, err
+			}
+			vms[pvm.ID] = vm
+		}
+	}
+	return forklift.Manifests(*plan, vms, target)
+}
+
+// Delete deletes the plan id, failing with a ResourceNotFoundError when there is
+// no such plan.
+func (s *PlanService) Delete(ctx context.Context, id string) error {
+	return s.store.Plan().Delete(ctx, id)
+}
+
+func planVM(vm *models.VM, throughputMBps float64) models.PlanVM {
+	p := models.PlanVM{
+		ID:                vm.ID,
+		Name:              vm.Name,
+		Cluster:           vm.Cluster,
+		PowerState:        vm.PowerState,
+		CPUs:              vm.CpuCount,
+		MemoryMB:          vm.MemoryMB,
+		DiskMB:            vm.DiskSize,
+		EstimatedTransfer: transferTime(vm.DiskSize, throughputMBps),
+	}
+	for _, c := range vm.Concerns {
+		if c.Category == criticalConcern {
+			p.CriticalConcerns++
+		}
+	}
+	return p
+}
+
+// transferTime is the time sizeMB takes to transfer at throughputMBps, to the
+// second.
+func transferTime(sizeMB int64, throughputMBps float64) time.Duration {
+	return time.Duration(float64(sizeMB) / throughputMBps * float64(time.Second)).Round(time.Second)
+}
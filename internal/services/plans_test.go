@@ -0,0 +1,184 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("PlanService", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		st  *store.Store
+		srv *services.PlanService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+		srv = services.NewPlanService(st)
+
+		Expect(fixtures.Insert(ctx, db,
+			fixtures.NewVM("vm-1").WithName("db").WithCluster("c1").WithCPUs(4).WithMemory(8192).WithDisk(4000).WithDisk(2000),
+			fixtures.NewVM("vm-2").WithName("web").WithCluster("c1").WithCPUs(2).WithMemory(4096).WithDisk(1000).
+				WithConcernOf(fixtures.Concern{ID: "disk.rdm", Label: "RDM disk", Category: "Critical", Assessment: "..."}),
+			fixtures.NewVM("vm-3").WithName("cache").WithCluster("c2").WithCPUs(1).WithMemory(2048).WithDisk(500),
+		)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given VMs selected in two waves at 100 MB/s
+	// When we generate the plan
+	// Then the waves should keep their order, their VMs sorted by disk size,
+	// with the transfer times and the resources of the VMs
+	It("should order the VMs and estimate their transfer", func() {
+		// Arrange
+		req := models.PlanRequest{
+			Name: "cutover",
+			Waves: []models.PlanWaveRequest{
+				{Name: "apps", VMIDs: []string{"vm-1", "vm-2"}},
+				{VMIDs: []string{"vm-3"}},
+			},
+		}
+
+		// Act
+		plan, err := srv.Generate(ctx, req)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.ThroughputMBps).To(BeNumerically("==", models.DefaultPlanThroughputMBps))
+		Expect(plan.ThroughputSource).To(Equal(models.PlanThroughputDefault))
+		Expect(plan.Waves).To(HaveLen(2))
+
+		apps := plan.Waves[0]
+		Expect(apps.Name).To(Equal("apps"))
+		Expect(apps.VMs).To(HaveLen(2))
+		Expect(apps.VMs[0].ID).To(Equal("vm-2"))
+		Expect(apps.VMs[0].Order).To(Equal(1))
+		Expect(apps.VMs[0].CriticalConcerns).To(Equal(1))
+		Expect(apps.VMs[0].EstimatedTransfer).To(Equal(10 * time.Second))
+		Expect(apps.VMs[1].ID).To(Equal("vm-1"))
+		Expect(apps.VMs[1].Order).To(Equal(2))
+		Expect(apps.VMs[1].DiskMB).To(BeEquivalentTo(6000))
+		Expect(apps.VMs[1].EstimatedTransfer).To(Equal(time.Minute))
+		Expect(apps.Resources).To(Equal(models.PlanResources{VMs: 2, CPUs: 6, MemoryMB: 12288, StorageMB: 7000}))
+		Expect(apps.EstimatedTransfer).To(Equal(70 * time.Second))
+
+		Expect(plan.Waves[1].Name).To(Equal("wave-2"))
+		Expect(plan.Waves[1].VMs[0].Order).To(Equal(3))
+		Expect(plan.Resources).To(Equal(models.PlanResources{VMs: 3, CPUs: 7, MemoryMB: 14336, StorageMB: 7500}))
+		Expect(plan.EstimatedTransfer).To(Equal(75 * time.Second))
+
+		stored, err := srv.Get(ctx, plan.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Waves).To(Equal(plan.Waves))
+	})
+
+	// Given a requested throughput
+	// When we generate the plan
+	// Then the estimates should use it
+	It("should use the requested throughput", func() {
+		// Act
+		plan, err := srv.Generate(ctx, models.PlanRequest{
+			Waves:          []models.PlanWaveRequest{{VMIDs: []string{"vm-1"}}},
+			ThroughputMBps: 1000,
+		})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.ThroughputSource).To(Equal(models.PlanThroughputRequest))
+		Expect(plan.EstimatedTransfer).To(Equal(6 * time.Second))
+	})
+
+	// Given a benchmark measuring 250 MB/s
+	// When we generate a plan without throughput
+	// Then the estimates should use the measured one
+	It("should use the throughput of the latest benchmark", func() {
+		// Arrange
+		Expect(st.Benchmark().Create(ctx, models.Benchmark{
+			ID:         "bench-1",
+			CreatedAt:  time.Now(),
+			Datastores: []models.ThroughputMeasure{{Name: "ds-1", MBps: 250}, {Name: "ds-2", Error: "file is locked"}},
+		})).To(Succeed())
+
+		// Act
+		plan, err := srv.Generate(ctx, models.PlanRequest{Waves: []models.PlanWaveRequest{{VMIDs: []string{"vm-1"}}}})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan.ThroughputMBps).To(BeNumerically("==", 250))
+		Expect(plan.ThroughputSource).To(Equal(models.PlanThroughputBenchmark))
+		Expect(plan.EstimatedTransfer).To(Equal(24 * time.Second))
+	})
+
+	// Given a VM missing from the inventory
+	// When we generate a plan with it
+	// Then it should fail with a not found error and store no plan
+	It("should reject an unknown VM", func() {
+		// Act
+		_, err := srv.Generate(ctx, models.PlanRequest{Waves: []models.PlanWaveRequest{{VMIDs: []string{"vm-1", "vm-9"}}}})
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		plans, err := srv.List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plans).To(BeEmpty())
+	})
+
+	// Given a plan of a VM with a NIC and a disk on a datastore
+	// When we export it as Forklift CRs
+	// Then the maps should hold the network and the datastore and the plan of the wave the VM
+	It("should export the plan as Forklift CRs", func() {
+		// Arrange
+		Expect(fixtures.Insert(ctx, db, fixtures.NewVM("vm-4").WithName("mail").
+			WithNIC("VM Network", "00:50:56:00:00:01").
+			WithDiskOf(fixtures.Disk{CapacityMiB: 100, Path: "[ds1] mail/mail.vmdk"}))).To(Succeed())
+		plan, err := srv.Generate(ctx, models.PlanRequest{Name: "Mail", Waves: []models.PlanWaveRequest{{Name: "first", VMIDs: []string{"vm-4"}}}})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		data, err := srv.Forklift(ctx, plan.ID, models.ForkliftTarget{SourceProvider: "vcenter", TargetNamespace: "mail", StorageClass: "standard"})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		docs := strings.Split(string(data), "---\n")
+		Expect(docs).To(HaveLen(3))
+		Expect(docs[0]).To(ContainSubstring("kind: NetworkMap"))
+		Expect(docs[1]).To(ContainSubstring("name: ds1"))
+		Expect(docs[1]).To(ContainSubstring("storageClass: standard"))
+		Expect(docs[2]).To(ContainSubstring("name: mail-first"))
+		Expect(docs[2]).To(ContainSubstring("id: vm-4"))
+	})
+
+	// Given no plan
+	// When we export an unknown plan as Forklift CRs
+	// Then it should fail with a not found error
+	It("should not export an unknown plan as Forklift CRs", func() {
+		// Act
+		_, err := srv.Forklift(ctx, "p-9", models.ForkliftTarget{SourceProvider: "vcenter", TargetNamespace: "mail", StorageClass: "standard"})
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+})
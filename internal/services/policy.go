@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+// Policies are the OPA policies the store evaluates the concerns with,
+// reloaded from their folder.
+type Policies interface {
+	Dir() string
+	Validate(ctx context.Context, vm parsermodels.VM) ([]parsermodels.Concern, error)
+	Metadata() []models.ConcernMetadata
+	Status() (models.PolicySet, error)
+	Reload() (bool, error)
+	Watch(ctx context.Context, onReload func(ctx context.Context))
+}
+
+// PolicyService re-evaluates the concerns of the stored inventory when the
+// policies change, so updated rules take effect without collecting again.
+type PolicyService struct {
+	store     *store.Store
+	policies  Policies
+	collector *CollectorService
+
+	customMu sync.Mutex // serializes the changes of the custom policies
+}
+
+func NewPolicyService(st *store.Store, policies Policies, collector *CollectorService) *PolicyService {
+	return &PolicyService{
+		store:     st,
+		policies:  policies,
+		collector: collector,
+	}
+}
+
+// Run stores the metadata of the concerns of the policies, then reloads the
+// policies whenever their files change, until ctx is done.
+func (p *PolicyService) Run(ctx context.Context) {
+	if err := p.SyncMetadata(ctx); err != nil {
+		zap.S().Named("policy_service").Errorw("failed to store concern metadata", "error", err)
+	}
+	p.policies.Watch(ctx, func(ctx context.Context) {
+		if err := p.reloaded(ctx); err != nil {
+			zap.S().Named("policy_service").Errorw("failed to re-evaluate concerns", "error", err)
+		}
+	})
+}
+
+// Reload reloads the policies, typically on SIGHUP, and re-evaluates the
+// concerns when they changed. The policies in use are kept when one of them
+// does not compile.
+func (p *PolicyService) Reload(ctx context.Context) error {
+	changed, err := p.policies.Reload()
+	if err != nil || !changed {
+		return err
+	}
+	return p.reloaded(ctx)
+}
+
+// reloaded stores the metadata of the reloaded policies and re-evaluates the
+// concerns with them.
+func (p *PolicyService) reloaded(ctx context.Context) error {
+	if err := p.SyncMetadata(ctx); err != nil {
+		return err
+	}
+	return p.Reevaluate(ctx)
+}
+
+// SyncMetadata replaces the stored metadata of the concerns with the one of
+// the annotations of the policies in use.
+func (p *PolicyService) SyncMetadata(ctx context.Context) error {
+	return p.store.ConcernMetadata().Replace(ctx, p.policies.Metadata())
+}
+
+// GetConcern returns the concern id of the inventory with its metadata.
+func (p *PolicyService) GetConcern(ctx context.Context, id string) (*models.ConcernDetail, error) {
+	return p.store.ConcernMetadata().Get(ctx, id)
+}
+
+// Reevaluate replaces the concerns of the stored inventory with the ones of the
+// policies in use, and stores the inventory built from them. Nothing is done
+// without an inventory or while a collection runs, the collection evaluating
+// the policies in use itself.
+func (p *PolicyService) Reevaluate(ctx context.Context) error {
+	if p.collector.IsBusy() {
+		zap.S().Named("policy_service").Info("collection running, concerns not re-evaluated")
+		return nil
+	}
+
+	if _, err := p.store.Inventory().Get(ctx); err != nil {
+		if srvErrors.IsResourceNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := p.store.ReevaluateConcerns(ctx); err != nil {
+		return err
+	}
+	inventory, err := p.store.BuildInventory(ctx)
+	if err != nil {
+		return err
+	}
+	if err := p.store.Inventory().Save(ctx, inventory); err != nil {
+		return err
+	}
+
+	zap.S().Named("policy_service").Info("concerns re-evaluated with the reloaded policies")
+	return nil
+}
+
+// Evaluate runs the policies in use against the VM of the inventory vmID or,
+// when vmID is empty, against document, a VM as the policies receive it. The
+// concerns are returned, not stored. A document that is not a VM is rejected
+// with a validation error of its vm field.
+func (p *PolicyService) Evaluate(ctx context.Context, vmID string, document map[string]any) (*models.PolicyEvaluation, error) {
+	var vm *parsermodels.VM
+	if vmID != "" {
+		input, err := p.store.PolicyInput(ctx, vmID)
+		if err != nil {
+			return nil, err
+		}
+		vm = input
+	} else {
+		input, err := decodeVM(document)
+		if err != nil {
+			return nil, validation.Errors{{Field: "vm", Message: fmt.Sprintf("invalid vm: %v", err)}}
+		}
+		vm = input
+	}
+
+	concerns, err := p.policies.Validate(ctx, *vm)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]models.ConcernMetadata)
+	for _, m := range p.policies.Metadata() {
+		metadata[m.ID] = m
+	}
+
+	evaluation := &models.PolicyEvaluation{VMID: vm.ID, VMName: vm.Name, Concerns: make([]models.Concern, 0, len(concerns))}
+	for _, c := range concerns {
+		m := metadata[c.Id]
+		severity := m.Severity
+		if severity == "" {
+			severity = models.SeverityOf(c.Category)
+		}
+		evaluation.Concerns = append(evaluation.Concerns, models.Concern{
+			ID:          c.Id,
+			Label:       c.Label,
+			Category:    c.Category,
+			Assessment:  c.Assessment,
+			Severity:    severity,
+			Remediation: m.Remediation,
+			DocsURL:     m.DocsURL,
+		})
+	}
+	return evaluation, nil
+}
+
+// decodeVM returns the VM of document, decoded through its JSON encoding like
+// the policies read it.
+func decodeVM(document map[string]any) (*parsermodels.VM, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var vm parsermodels.VM
+	if err := json.Unmarshal(data, &vm); err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
+
+// ListPolicies returns the policies in use: their files and sources, and the
+// error of the last reload when it failed.
+func (p *PolicyService) ListPolicies(ctx context.Context) (*models.PolicySet, error) {
+	set, err := p.policies.Status()
+	if err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// ListCustomPolicies returns the custom policies, uploaded by the users.
+func (p *PolicyService) ListCustomPolicies(ctx context.Context) ([]models.CustomPolicy, error) {
+	return policy.ListCustom(p.policies.Dir())
+}
+
+// PutCustomPolicy adds the custom policy name, or replaces it, and reloads the
+// policies so its concerns are evaluated at once. A policy that does not
+// compile with the other custom policies is rejected with a validation error
+// of its policy field.
+func (p *PolicyService) PutCustomPolicy(ctx context.Context, name string, content []byte) error {
+	if err := validation.New().
+		Check("name", policy.ValidCustomName(name), fmt.Sprintf("invalid name %q: must be a .rego file name, not a test", name)).
+		Required("policy", string(content)).
+		Err(); err != nil {
+		return err
+	}
+
+	p.customMu.Lock()
+	defer p.customMu.Unlock()
+
+	if err := policy.WriteCustom(p.policies.Dir(), name, content); err != nil {
+		if errors.Is(err, policy.ErrInvalidCustomPolicy) {
+			return validation.Errors{{Field: "policy", Message: err.Error()}}
+		}
+		return err
+	}
+	zap.S().Named("policy_service").Infow("custom policy uploaded", "name", name)
+	return p.Reload(ctx)
+}
+
+// DeleteCustomPolicy removes the custom policy name and reloads the policies,
+// dropping its concerns.
+func (p *PolicyService) DeleteCustomPolicy(ctx context.Context, name string) error {
+	p.customMu.Lock()
+	defer p.customMu.Unlock()
+
+	if err := policy.DeleteCustom(p.policies.Dir(), name); err != nil {
+		return err
+	}
+	zap.S().Named("policy_service").Infow("custom policy deleted", "name", name)
+	return p.Reload(ctx)
+}
+
+// RunTests runs the rego tests of the policies folder and of its custom
+// subfolder, the custom ones along candidates, custom policies and tests by
+// file name that are not uploaded. Tests or candidates that do not compile
+// are rejected with a validation error of the policies field.
+func (p *PolicyService) RunTests(ctx context.Context, candidates map[string]string) (*models.PolicyTestReport, error) {
+	v := validation.New()
+	for name := range candidates {
+		v.Check("policies", policy.ValidCandidateName(name), fmt.Sprintf("invalid name %q: must be a .rego file name", name))
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	report, err := policy.RunTests(ctx, p.policies.Dir(), candidates)
+	if err != nil {
+		if errors.Is(err, policy.ErrInvalidPolicyTests) {
+			return nil, validation.Errors{{Field: "policies", Message: err.Error()}}
+		}
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+)
+
+// PolicyBundleClient downloads the OPA policy bundle of the agent.
+type PolicyBundleClient interface {
+	GetPolicyBundle(ctx context.Context, bundleURL, etag string) (*models.PolicyBundle, error)
+}
+
+// PolicyBundle downloads the OPA policy bundle from the console into the
+// policies folder whenever it changes, and calls onInstall after installing it
+// so the policies are reloaded. Nothing is downloaded while the bundle URL is
+// empty.
+type PolicyBundle struct {
+	dir       string
+	interval  time.Duration
+	onInstall func(ctx context.Context) error
+
+	mu     sync.Mutex // protects client and url
+	client PolicyBundleClient
+	url    string
+}
+
+func NewPolicyBundleService(client PolicyBundleClient, dir, bundleURL string, interval time.Duration, onInstall func(ctx context.Context) error) *PolicyBundle {
+	return &PolicyBundle{
+		dir:       dir,
+		interval:  interval,
+		onInstall: onInstall,
+		client:    client,
+		url:       bundleURL,
+	}
+}
+
+// SetClient replaces the client, for a console URL changed by a configuration reload.
+func (b *PolicyBundle) SetClient(client PolicyBundleClient) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.client = client
+}
+
+// SetURL replaces the bundle URL, for a URL changed by a configuration reload.
+// The bundle at the new URL is downloaded at the next Fetch.
+func (b *PolicyBundle) SetURL(bundleURL string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.url = bundleURL
+}
+
+// Fetch downloads the bundle and installs it when it differs from the one
+// installed, known by its URL and ETag. A bundle that does not compile is not
+// installed.
+func (b *PolicyBundle) Fetch(ctx context.Context) error {
+	b.mu.Lock()
+	client := b.client
+	bundleURL := b.url
+	b.mu.Unlock()
+
+	if bundleURL == "" {
+		return nil
+	}
+
+	state, err := policy.ReadBundleState(b.dir)
+	if err != nil {
+		return err
+	}
+	etag := ""
+	if state.URL == bundleURL {
+		etag = state.ETag
+	}
+
+	bundle, err := client.GetPolicyBundle(ctx, bundleURL, etag)
+	if err != nil {
+		return err
+	}
+	if bundle == nil {
+		return nil
+	}
+
+	state, err = policy.InstallBundle(b.dir, bundle.Data, models.PolicyBundleState{
+		URL:         bundleURL,
+		ETag:        bundle.ETag,
+		Revision:    bundle.ETag,
+		InstalledAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	zap.S().Named("policy_bundle_service").Infow("policy bundle installed", "url", bundleURL, "revision", state.Revision)
+
+	return b.onInstall(ctx)
+}
+
+// Run downloads the bundle at once, then every interval until ctx is done.
+func (b *PolicyBundle) Run(ctx context.Context) {
+	tick := time.NewTicker(b.interval)
+	defer tick.Stop()
+
+	for {
+		if err := b.Fetch(ctx); err != nil {
+			zap.S().Named("policy_bundle_service").Warnw("failed to download policy bundle", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
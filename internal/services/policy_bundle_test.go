@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
+)
+
+var _ = Describe("PolicyBundle", func() {
+	const bundleURL = "/api/v1/agents/7c6f0f2e-5f3a-4d8e-9b1a-2f0e8d6c4a10/policy-bundle"
+
+	var (
+		ctx       context.Context
+		dir       string
+		server    *mockconsole.Server
+		installed int
+		srv       *services.PolicyBundle
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		server = mockconsole.NewServer()
+		installed = 0
+
+		client, err := console.NewConsoleClient(server.URL(), "")
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewPolicyBundleService(client, dir, bundleURL, time.Minute, func(context.Context) error {
+			installed++
+			return nil
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	bundleResponse := func(etag, vmName string) mockconsole.Response {
+		return mockconsole.Response{
+			Status: http.StatusOK,
+			Header: http.Header{"Etag": []string{etag}},
+			Body:   test.NewPolicyBundle(map[string]string{"rule.rego": test.PolicyRule("test.rule", vmName)}),
+		}
+	}
+
+	// Given a bundle served by the console
+	// When the bundle is fetched
+	// Then its policies should be installed and the policies reloaded
+	It("should install the bundle of the console", func() {
+		// Arrange
+		server.Respond(mockconsole.PolicyBundle, bundleResponse(`"v1"`, "db-1"))
+
+		// Act
+		err := srv.Fetch(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed).To(Equal(1))
+		Expect(filepath.Join(dir, "rule.rego")).To(BeAnExistingFile())
+		state, err := policy.ReadBundleState(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.URL).To(Equal(bundleURL))
+		Expect(state.ETag).To(Equal(`"v1"`))
+	})
+
+	// Given an installed bundle the console did not change
+	// When the bundle is fetched again
+	// Then the request should carry its ETag and nothing be installed
+	It("should send the ETag of the installed bundle", func() {
+		// Arrange
+		server.RespondOnce(mockconsole.PolicyBundle, bundleResponse(`"v1"`, "db-1"), mockconsole.Response{Status: http.StatusNotModified})
+		Expect(srv.Fetch(ctx)).To(Succeed())
+
+		// Act
+		err := srv.Fetch(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed).To(Equal(1))
+		requests := server.Requests(mockconsole.PolicyBundle)
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].Header.Get("If-None-Match")).To(BeEmpty())
+		Expect(requests[1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+	})
+
+	// Given a bundle whose policy does not compile
+	// When the bundle is fetched
+	// Then it should fail and the policies in use be kept
+	It("should not install a bundle that does not compile", func() {
+		// Arrange
+		Expect(os.WriteFile(filepath.Join(dir, "shipped.rego"), []byte(test.PolicyRule("test.shipped", "db-1")), 0o600)).To(Succeed())
+		server.Respond(mockconsole.PolicyBundle, mockconsole.Response{
+			Status: http.StatusOK,
+			Body:   test.NewPolicyBundle(map[string]string{"rule.rego": "package io.konveyor.forklift.vmware\n\nconcerns contains flag if {"}),
+		})
+
+		// Act
+		err := srv.Fetch(ctx)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(installed).To(BeZero())
+		Expect(filepath.Join(dir, "shipped.rego")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "rule.rego")).NotTo(BeAnExistingFile())
+	})
+
+	// Given no bundle URL
+	// When the bundle is fetched
+	// Then the console should not be called
+	It("should not download without bundle URL", func() {
+		// Arrange
+		srv.SetURL("")
+
+		// Act
+		err := srv.Fetch(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Count(mockconsole.PolicyBundle)).To(BeZero())
+	})
+
+	// Given a failing reload of the installed policies
+	// When the bundle is fetched
+	// Then the error should be returned
+	It("should return the error of the reload", func() {
+		// Arrange
+		server.Respond(mockconsole.PolicyBundle, bundleResponse(`"v1"`, "db-1"))
+		client, err := console.NewConsoleClient(server.URL(), "")
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewPolicyBundleService(client, dir, bundleURL, time.Minute, func(context.Context) error {
+			return errors.New("reload failed")
+		})
+
+		// Act
+		err = srv.Fetch(ctx)
+
+		// Assert
+		Expect(err).To(MatchError("reload failed"))
+	})
+})
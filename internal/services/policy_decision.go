@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+const (
+	// policyDecisionFlushInterval is how often the recorded decisions are stored.
+	policyDecisionFlushInterval = 2 * time.Second
+	// maxPendingPolicyDecisions bounds the decisions waiting to be stored, the
+	// next ones being dropped until they are.
+	maxPendingPolicyDecisions = 10000
+)
+
+// PolicyDecisionService logs the decisions of the policy evaluations. Record
+// is called by the policies for each VM they evaluate, during the collections
+// as well, so the decisions are buffered and stored in batches by Run.
+type PolicyDecisionService struct {
+	store *store.Store
+
+	mu      sync.Mutex
+	pending []models.PolicyDecision
+	dropped int
+}
+
+func NewPolicyDecisionService(st *store.Store) *PolicyDecisionService {
+	return &PolicyDecisionService{store: st}
+}
+
+// Record buffers the decisions of an evaluation of the policies for vm: one
+// per concern raised, or a pass. It implements policy.DecisionFunc.
+func (s *PolicyDecisionService) Record(vm parsermodels.VM, concerns []parsermodels.Concern, duration time.Duration) {
+	now := time.Now().UTC()
+	decisions := make([]models.PolicyDecision, 0, max(len(concerns), 1))
+	for _, c := range concerns {
+		decisions = append(decisions, models.PolicyDecision{
+			Time:     now,
+			VMID:     vm.ID,
+			VMName:   vm.Name,
+			Rule:     c.Id,
+			Decision: models.PolicyDecisionFlagged,
+			Duration: duration,
+		})
+	}
+	if len(decisions) == 0 {
+		decisions = append(decisions, models.PolicyDecision{
+			Time:     now,
+			VMID:     vm.ID,
+			VMName:   vm.Name,
+			Decision: models.PolicyDecisionPassed,
+			Duration: duration,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending)+len(decisions) > maxPendingPolicyDecisions {
+		s.dropped += len(decisions)
+		return
+	}
+	s.pending = append(s.pending, decisions...)
+}
+
+// Run stores the recorded decisions every policyDecisionFlushInterval until
+// ctx is done, then stores the last ones.
+func (s *PolicyDecisionService) Run(ctx context.Context) {
+	tick := time.NewTicker(policyDecisionFlushInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Flush(context.WithoutCancel(ctx)); err != nil {
+				zap.S().Named("policy_decision_service").Errorw("failed to store policy decisions", "error", err)
+			}
+			return
+		case <-tick.C:
+		}
+
+		if err := s.Flush(ctx); err != nil {
+			zap.S().Named("policy_decision_service").Errorw("failed to store policy decisions", "error", err)
+		}
+	}
+}
+
+// Flush stores the recorded decisions. The decisions that cannot be stored
+// are dropped, the log being a diagnostic aid.
+func (s *PolicyDecisionService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending, dropped := s.pending, s.dropped
+	s.pending, s.dropped = nil, 0
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		zap.S().Named("policy_decision_service").Warnw("policy decisions dropped, too many pending", "count", dropped)
+	}
+	return s.store.PolicyDecision().Insert(ctx, pending...)
+}
+
+// List returns the page at cursor of the stored decisions matching filter,
+// newest first.
+func (s *PolicyDecisionService) List(ctx context.Context, filter models.PolicyDecisionFilter, cursor models.Cursor) (models.Page[models.PolicyDecision], error) {
+	decisions, err := s.store.PolicyDecision().List(ctx, filter, cursor)
+	if err != nil {
+		return models.Page[models.PolicyDecision]{}, err
+	}
+	total, err := s.store.PolicyDecision().Count(ctx, filter)
+	if err != nil {
+		return models.Page[models.PolicyDecision]{}, err
+	}
+	return models.NewPage(decisions, cursor, total), nil
+}
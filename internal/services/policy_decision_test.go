@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("PolicyDecisionService", func() {
+	var (
+		ctx context.Context
+		db  *sql.DB
+		st  *store.Store
+		srv *services.PolicyDecisionService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, test.NewMockValidator())
+		srv = services.NewPolicyDecisionService(st)
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given an evaluation raising two concerns and one raising none
+	// When they are recorded and flushed
+	// Then a flagged decision per concern and a passed one should be listed
+	It("should store a decision per concern or a pass", func() {
+		// Arrange
+		srv.Record(parsermodels.VM{ID: "vm-1", Name: "db-1"}, []parsermodels.Concern{{Id: "test.cpu"}, {Id: "test.disk"}}, 2*time.Millisecond)
+		srv.Record(parsermodels.VM{ID: "vm-2", Name: "web-1"}, nil, time.Millisecond)
+
+		// Act
+		err := srv.Flush(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		page, err := srv.List(ctx, models.PolicyDecisionFilter{VMID: "vm-1"}, models.NewCursor(1, 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Total).To(Equal(2))
+		Expect(page.Items[0].Decision).To(Equal(models.PolicyDecisionFlagged))
+		Expect(page.Items[0].Duration).To(Equal(2 * time.Millisecond))
+		Expect([]string{page.Items[0].Rule, page.Items[1].Rule}).To(ConsistOf("test.cpu", "test.disk"))
+
+		passed, err := srv.List(ctx, models.PolicyDecisionFilter{Decision: models.PolicyDecisionPassed}, models.NewCursor(1, 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(passed.Total).To(Equal(1))
+		Expect(passed.Items[0].VMName).To(Equal("web-1"))
+	})
+
+	// Given recorded decisions
+	// When the service stops
+	// Then the last decisions should be stored
+	It("should store the recorded decisions when it stops", func() {
+		// Arrange
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			srv.Run(runCtx)
+		}()
+		srv.Record(parsermodels.VM{ID: "vm-1"}, nil, time.Millisecond)
+
+		// Act
+		cancel()
+		Eventually(done).Should(BeClosed())
+
+		// Assert
+		count, err := st.PolicyDecision().Count(ctx, models.PolicyDecisionFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+	})
+})
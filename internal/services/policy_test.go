@@ -0,0 +1,334 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+type fakePolicies struct {
+	dir      string
+	changed  bool
+	err      error
+	concerns []parsermodels.Concern
+	metadata []models.ConcernMetadata
+	status   models.PolicySet
+}
+
+func (f *fakePolicies) Dir() string {
+	return f.dir
+}
+
+func (f *fakePolicies) Validate(ctx context.Context, vm parsermodels.VM) ([]parsermodels.Concern, error) {
+	return f.concerns, nil
+}
+
+func (f *fakePolicies) Metadata() []models.ConcernMetadata {
+	return f.metadata
+}
+
+func (f *fakePolicies) Status() (models.PolicySet, error) {
+	return f.status, nil
+}
+
+func (f *fakePolicies) Reload() (bool, error) {
+	return f.changed, f.err
+}
+
+func (f *fakePolicies) Watch(ctx context.Context, onReload func(ctx context.Context)) {}
+
+var _ = Describe("PolicyService", func() {
+	var (
+		ctx       context.Context
+		db        *sql.DB
+		st        *store.Store
+		sched     *scheduler.Scheduler
+		validator *test.MockValidator
+		policies  *fakePolicies
+		srv       *services.PolicyService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		validator = test.NewMockValidator()
+		st = store.NewStore(db, validator)
+		sched = scheduler.NewScheduler(1)
+		policies = &fakePolicies{dir: GinkgoT().TempDir(), changed: true}
+
+		Expect(fixtures.Insert(ctx, db, fixtures.NewVM("vm-1"))).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		collector := services.NewCollectorService(sched, st, &mockWorkBuilder{store: st})
+		srv = services.NewPolicyService(st, policies, collector)
+	})
+
+	AfterEach(func() {
+		if sched != nil {
+			sched.Close()
+		}
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	concernCount := func() int {
+		var count int
+		Expect(db.QueryRowContext(ctx, `SELECT COUNT(*) FROM concerns WHERE "Concern_ID" = 'new.rule'`).Scan(&count)).To(Succeed())
+		return count
+	}
+
+	Context("with an inventory", func() {
+		BeforeEach(func() {
+			Expect(st.Inventory().Save(ctx, []byte(`{"vms":[]}`))).To(Succeed())
+			validator.Concerns = []parsermodels.Concern{{Id: "new.rule", Label: "New", Category: "Warning", Assessment: "New rule"}}
+		})
+
+		// Given changed policies and a stored inventory
+		// When the policies are reloaded
+		// Then the concerns and the inventory should be rebuilt
+		It("should re-evaluate the concerns and store the inventory", func() {
+			// Act
+			err := srv.Reload(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernCount()).To(Equal(1))
+			inv, err := st.Inventory().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(inv.Data)).NotTo(Equal(`{"vms":[]}`))
+		})
+
+		// Given unchanged policies
+		// When the policies are reloaded
+		// Then the concerns should not be re-evaluated
+		It("should not re-evaluate unchanged policies", func() {
+			// Arrange
+			policies.changed = false
+
+			// Act
+			err := srv.Reload(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernCount()).To(BeZero())
+		})
+
+		// Given policies that fail to compile
+		// When the policies are reloaded
+		// Then the error should be returned without re-evaluating
+		It("should return the reload error", func() {
+			// Arrange
+			policies.changed = false
+			policies.err = errors.New("policy compilation failed")
+
+			// Act
+			err := srv.Reload(ctx)
+
+			// Assert
+			Expect(err).To(MatchError("policy compilation failed"))
+			Expect(concernCount()).To(BeZero())
+		})
+	})
+
+	Context("Evaluate", func() {
+		BeforeEach(func() {
+			policies.concerns = []parsermodels.Concern{{Id: "new.rule", Label: "New", Category: "Warning", Assessment: "New rule"}}
+		})
+
+		// Given a VM of the inventory
+		// When we evaluate the policies against its ID
+		// Then the concerns should be returned without being stored
+		It("should evaluate a VM of the inventory", func() {
+			// Act
+			evaluation, err := srv.Evaluate(ctx, "vm-1", nil)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.VMID).To(Equal("vm-1"))
+			Expect(evaluation.Concerns).To(HaveLen(1))
+			Expect(evaluation.Concerns[0].ID).To(Equal("new.rule"))
+			Expect(concernCount()).To(BeZero())
+		})
+
+		// Given a VM document
+		// When we evaluate the policies against it
+		// Then the concerns should be returned
+		It("should evaluate a VM document", func() {
+			// Act
+			evaluation, err := srv.Evaluate(ctx, "", map[string]any{"id": "vm-x", "name": "web-1"})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.VMName).To(Equal("web-1"))
+			Expect(evaluation.Concerns).To(HaveLen(1))
+		})
+
+		// Given an unknown VM ID
+		// When we evaluate the policies against it
+		// Then a not found error should be returned
+		It("should fail for an unknown VM", func() {
+			// Act
+			_, err := srv.Evaluate(ctx, "vm-9", nil)
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+
+		// Given a document that is not a VM
+		// When we evaluate the policies against it
+		// Then a field error of vm should be returned
+		It("should reject a document that is not a VM", func() {
+			// Act
+			_, err := srv.Evaluate(ctx, "", map[string]any{"name": 5})
+
+			// Assert
+			var fields validation.Errors
+			Expect(errors.As(err, &fields)).To(BeTrue())
+			Expect(fields[0].Field).To(Equal("vm"))
+		})
+	})
+
+	Context("custom policies", func() {
+		BeforeEach(func() {
+			Expect(st.Inventory().Save(ctx, []byte(`{"vms":[]}`))).To(Succeed())
+			validator.Concerns = []parsermodels.Concern{{Id: "new.rule", Label: "New", Category: "Warning", Assessment: "New rule"}}
+		})
+
+		// Given a custom policy
+		// When we upload it
+		// Then it should be listed and the concerns re-evaluated
+		It("should upload a custom policy and re-evaluate the concerns", func() {
+			// Act
+			err := srv.PutCustomPolicy(ctx, "naming.rego", []byte(test.PolicyRule("test.naming", "db-1")))
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernCount()).To(Equal(1))
+			custom, err := srv.ListCustomPolicies(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(custom).To(HaveLen(1))
+			Expect(custom[0].Name).To(Equal("naming.rego"))
+		})
+
+		// Given an invalid name and a policy that does not compile
+		// When we upload them
+		// Then field errors of name and policy should be returned
+		It("should reject an invalid custom policy", func() {
+			// Act
+			nameErr := srv.PutCustomPolicy(ctx, "../naming.rego", []byte(test.PolicyRule("test.naming", "db-1")))
+			policyErr := srv.PutCustomPolicy(ctx, "naming.rego", []byte("package io.konveyor.forklift.vmware\n\nconcerns contains flag if {"))
+
+			// Assert
+			var fields validation.Errors
+			Expect(errors.As(nameErr, &fields)).To(BeTrue())
+			Expect(fields[0].Field).To(Equal("name"))
+			Expect(errors.As(policyErr, &fields)).To(BeTrue())
+			Expect(fields[0].Field).To(Equal("policy"))
+			Expect(concernCount()).To(BeZero())
+		})
+
+		// Given an unknown custom policy
+		// When we delete it
+		// Then a not found error should be returned
+		It("should fail to delete an unknown custom policy", func() {
+			// Act
+			err := srv.DeleteCustomPolicy(ctx, "naming.rego")
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("concern metadata", func() {
+		BeforeEach(func() {
+			Expect(st.Inventory().Save(ctx, []byte(`{"vms":[]}`))).To(Succeed())
+			validator.Concerns = []parsermodels.Concern{{Id: "new.rule", Label: "New", Category: "Warning", Assessment: "New rule"}}
+			policies.concerns = validator.Concerns
+			policies.metadata = []models.ConcernMetadata{
+				{ID: "new.rule", Title: "New rule", Severity: "critical", Remediation: "Fix it"},
+			}
+		})
+
+		// Given policies with metadata flagging a VM
+		// When the policies are reloaded and we get the concern
+		// Then its metadata and the number of VMs flagged should be returned
+		It("should return the concern with its metadata", func() {
+			// Arrange
+			Expect(srv.Reload(ctx)).To(Succeed())
+
+			// Act
+			concern, err := srv.GetConcern(ctx, "new.rule")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concern.Title).To(Equal("New rule"))
+			Expect(concern.Severity).To(Equal("critical"))
+			Expect(concern.Category).To(Equal("Warning"))
+			Expect(concern.VMCount).To(Equal(1))
+		})
+
+		// Given a concern without metadata
+		// When we evaluate the policies
+		// Then its severity should be its category in lowercase
+		It("should attach the metadata to the evaluated concerns", func() {
+			// Arrange
+			policies.concerns = append(policies.concerns, parsermodels.Concern{Id: "other.rule", Category: "Information"})
+
+			// Act
+			evaluation, err := srv.Evaluate(ctx, "vm-1", nil)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evaluation.Concerns).To(HaveLen(2))
+			Expect(evaluation.Concerns[0].Severity).To(Equal("critical"))
+			Expect(evaluation.Concerns[0].Remediation).To(Equal("Fix it"))
+			Expect(evaluation.Concerns[1].Severity).To(Equal("information"))
+		})
+
+		// Given a concern neither raised nor annotated
+		// When we get it
+		// Then a not found error should be returned
+		It("should fail for an unknown concern", func() {
+			// Act
+			_, err := srv.GetConcern(ctx, "unknown.rule")
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	// Given no collected inventory
+	// When the policies are reloaded
+	// Then nothing should be re-evaluated
+	It("should do nothing without an inventory", func() {
+		// Arrange
+		validator.Concerns = []parsermodels.Concern{{Id: "new.rule", Label: "New", Category: "Warning", Assessment: "New rule"}}
+
+		// Act
+		err := srv.Reload(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(concernCount()).To(BeZero())
+	})
+})
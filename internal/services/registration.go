@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// registrationMaxInterval bounds the wait between the registration attempts
+// while the console cannot be reached.
+const registrationMaxInterval = time.Minute
+
+// RegistrationClient registers the agent with the console.
+type RegistrationClient interface {
+	Register(ctx context.Context, provisioningToken string, body models.RegistrationRequest) (*models.Registration, error)
+}
+
+// Registration gives its identity to an agent deployed without agent and
+// source ids: it registers once with the console using a provisioning token,
+// and keeps the ids the console answers with in the store for the next starts.
+type Registration struct {
+	client  RegistrationClient
+	store   *store.Store
+	token   string
+	version string
+}
+
+func NewRegistrationService(client RegistrationClient, st *store.Store, provisioningToken, version string) *Registration {
+	return &Registration{
+		client:  client,
+		store:   st,
+		token:   provisioningToken,
+		version: version,
+	}
+}
+
+// Register returns the stored registration of the agent, or registers it.
+// The attempts are retried with a backoff while the console cannot be reached,
+// until ctx is done; a rejected token fails at once.
+func (r *Registration) Register(ctx context.Context) (models.Registration, error) {
+	stored, err := r.store.Registration().Get(ctx)
+	if err == nil {
+		return *stored, nil
+	}
+	if !errors.IsResourceNotFoundError(err) {
+		return models.Registration{}, err
+	}
+
+	log := zap.S().Named("registration_service")
+	hostname, _ := os.Hostname()
+	body := models.RegistrationRequest{Version: r.version, Hostname: hostname}
+
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = registrationMaxInterval
+	registration, err := backoff.Retry(ctx, func() (*models.Registration, error) {
+		registration, err := r.client.Register(ctx, r.token, body)
+		if err != nil && !errors.IsRetryable(err) {
+			return nil, backoff.Permanent(err)
+		}
+		return registration, err
+	}, backoff.WithBackOff(b), backoff.WithMaxElapsedTime(0), backoff.WithNotify(func(err error, next time.Duration) {
+		log.Warnw("failed to register the agent, retrying", "error", err, "retry_in", next)
+	}))
+	if err != nil {
+		return models.Registration{}, err
+	}
+
+	for _, id := range []string{registration.AgentID, registration.SourceID} {
+		if _, err := uuid.Parse(id); err != nil {
+			return models.Registration{}, fmt.Errorf("invalid id %q given by the console: %w", id, err)
+		}
+	}
+
+	registration.RegisteredAt = time.Now().UTC()
+	if err := r.store.Registration().Save(ctx, *registration); err != nil {
+		return models.Registration{}, err
+	}
+	log.Infow("agent registered", "agent_id", registration.AgentID, "source_id", registration.SourceID)
+	return *registration, nil
+}
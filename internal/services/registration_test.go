@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("Registration", func() {
+	const (
+		agentID  = "7c6f0f2e-5f3a-4d8e-9b1a-2f0e8d6c4a10"
+		sourceID = "0d1c2b3a-4f5e-4a6b-8c7d-9e0f1a2b3c4d"
+	)
+
+	var (
+		ctx    context.Context
+		db     *sql.DB
+		st     *store.Store
+		server *mockconsole.Server
+		srv    *services.Registration
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+		server = mockconsole.NewServer()
+
+		client, err := console.NewConsoleClient(server.URL(), "")
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewRegistrationService(client, st, "provisioning-token", "v2.0.0")
+	})
+
+	AfterEach(func() {
+		server.Close()
+		db.Close()
+	})
+
+	registered := mockconsole.Response{
+		Status: http.StatusOK,
+		Body:   map[string]string{"agentId": agentID, "sourceId": sourceID},
+	}
+
+	// Given an agent which never registered
+	// When it registers
+	// Then the ids of the console should be returned and stored
+	It("should register with the provisioning token", func() {
+		// Arrange
+		server.Respond(mockconsole.Register, registered)
+
+		// Act
+		r, err := srv.Register(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.AgentID).To(Equal(agentID))
+		Expect(r.SourceID).To(Equal(sourceID))
+		requests := server.Requests(mockconsole.Register)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("X-Provisioning-Token")).To(Equal("provisioning-token"))
+		var body models.RegistrationRequest
+		Expect(requests[0].Decode(&body)).To(Succeed())
+		Expect(body.Version).To(Equal("v2.0.0"))
+		stored, err := st.Registration().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.AgentID).To(Equal(agentID))
+	})
+
+	// Given an agent which already registered
+	// When it starts again
+	// Then the stored ids should be used without calling the console
+	It("should use the stored registration", func() {
+		// Arrange
+		server.Respond(mockconsole.Register, registered)
+		_, err := srv.Register(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		r, err := srv.Register(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.AgentID).To(Equal(agentID))
+		Expect(server.Count(mockconsole.Register)).To(Equal(1))
+	})
+
+	// Given a console failing once
+	// When the agent registers
+	// Then it should retry until it succeeds
+	It("should retry while the console is unavailable", func() {
+		// Arrange
+		server.RespondOnce(mockconsole.Register, mockconsole.Response{Status: http.StatusServiceUnavailable})
+		server.Respond(mockconsole.Register, registered)
+
+		// Act
+		r, err := srv.Register(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.AgentID).To(Equal(agentID))
+		Expect(server.Count(mockconsole.Register)).To(Equal(2))
+	})
+
+	// Given a provisioning token rejected by the console
+	// When the agent registers
+	// Then it should fail at once and store nothing
+	It("should fail at once for a rejected token", func() {
+		// Arrange
+		server.RespondWithStatus(mockconsole.Register, http.StatusUnauthorized)
+
+		// Act
+		_, err := srv.Register(ctx)
+
+		// Assert
+		Expect(srvErrors.IsConsoleClientError(err)).To(BeTrue())
+		Expect(server.Count(mockconsole.Register)).To(Equal(1))
+		_, err = st.Registration().Get(ctx)
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+// RemoteConfigClient pulls the remote configuration of an agent.
+type RemoteConfigClient interface {
+	GetAgentConfiguration(ctx context.Context, agentID uuid.UUID) (*config.Remote, error)
+}
+
+// RemoteConfig pulls the configuration of the agent from the console and calls
+// onChange with it whenever it differs from the last one pulled.
+type RemoteConfig struct {
+	agentID  uuid.UUID
+	interval time.Duration
+	onChange func(config.Remote) error
+
+	mu      sync.Mutex // protects client and current
+	client  RemoteConfigClient
+	current *config.Remote
+}
+
+func NewRemoteConfigService(client RemoteConfigClient, agentID uuid.UUID, interval time.Duration, onChange func(config.Remote) error) *RemoteConfig {
+	return &RemoteConfig{
+		agentID:  agentID,
+		interval: interval,
+		onChange: onChange,
+		client:   client,
+	}
+}
+
+// SetClient replaces the client, for a console URL changed by a configuration reload.
+func (r *RemoteConfig) SetClient(client RemoteConfigClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.client = client
+}
+
+// Current returns the last remote configuration pulled, and false before the
+// first successful pull.
+func (r *RemoteConfig) Current() (config.Remote, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return config.Remote{}, false
+	}
+	return *r.current, true
+}
+
+// Fetch pulls the remote configuration and calls onChange when it changed. A
+// configuration rejected by onChange is pulled and offered again next time.
+func (r *RemoteConfig) Fetch(ctx context.Context) error {
+	r.mu.Lock()
+	client := r.client
+	r.mu.Unlock()
+
+	remote, err := client.GetAgentConfiguration(ctx, r.agentID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	changed := r.current == nil || !reflect.DeepEqual(*r.current, *remote)
+	prev := r.current
+	r.current = remote
+	r.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	if err := r.onChange(*remote); err != nil {
+		r.mu.Lock()
+		r.current = prev
+		r.mu.Unlock()
+		return err
+	}
+	zap.S().Named("remote_config_service").Infow("remote configuration applied",
+		"update_interval", remote.UpdateInterval,
+		"features", remote.Features,
+		"policy_bundle_url", remote.PolicyBundleURL,
+		"update_channel_url", remote.UpdateChannelURL,
+	)
+	return nil
+}
+
+// Run pulls the remote configuration every interval until ctx is done.
+func (r *RemoteConfig) Run(ctx context.Context) {
+	tick := time.NewTicker(r.interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+
+		if err := r.Fetch(ctx); err != nil {
+			zap.S().Named("remote_config_service").Warnw("failed to pull remote configuration", "error", err)
+		}
+	}
+}
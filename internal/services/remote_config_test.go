@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+type fakeRemoteConfigClient struct {
+	mu     sync.Mutex
+	remote *config.Remote
+	err    error
+	calls  int
+}
+
+func (f *fakeRemoteConfigClient) GetAgentConfiguration(ctx context.Context, agentID uuid.UUID) (*config.Remote, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	remote := *f.remote
+	return &remote, nil
+}
+
+func (f *fakeRemoteConfigClient) set(remote config.Remote) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.remote = &remote
+}
+
+func (f *fakeRemoteConfigClient) pulls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+var _ = Describe("RemoteConfig", func() {
+	var (
+		ctx       context.Context
+		client    *fakeRemoteConfigClient
+		mu        sync.Mutex
+		applied   []config.Remote
+		changeErr error
+		srv       *services.RemoteConfig
+	)
+
+	appliedRemotes := func() []config.Remote {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]config.Remote(nil), applied...)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = &fakeRemoteConfigClient{}
+		client.set(config.Remote{UpdateInterval: "30s"})
+		applied = nil
+		changeErr = nil
+		srv = services.NewRemoteConfigService(client, uuid.New(), 50*time.Millisecond, func(remote config.Remote) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if changeErr != nil {
+				return changeErr
+			}
+			applied = append(applied, remote)
+			return nil
+		})
+	})
+
+	// Given a console returning the same configuration twice
+	// When we pull it twice
+	// Then it should be applied once
+	It("applies the configuration when it changes", func() {
+		// Act
+		Expect(srv.Fetch(ctx)).To(Succeed())
+		Expect(srv.Fetch(ctx)).To(Succeed())
+		client.set(config.Remote{UpdateInterval: "1m"})
+		Expect(srv.Fetch(ctx)).To(Succeed())
+
+		// Assert
+		Expect(appliedRemotes()).To(Equal([]config.Remote{{UpdateInterval: "30s"}, {UpdateInterval: "1m"}}))
+		current, ok := srv.Current()
+		Expect(ok).To(BeTrue())
+		Expect(current.UpdateInterval).To(Equal("1m"))
+	})
+
+	// Given a configuration rejected when applied
+	// When we pull it again
+	// Then it should be offered again
+	It("retries a rejected configuration", func() {
+		// Arrange
+		changeErr = errors.New("invalid remote configuration")
+
+		// Act
+		firstErr := srv.Fetch(ctx)
+		_, okAfterReject := srv.Current()
+		changeErr = nil
+		err := srv.Fetch(ctx)
+
+		// Assert
+		Expect(firstErr).To(MatchError("invalid remote configuration"))
+		Expect(okAfterReject).To(BeFalse())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(appliedRemotes()).To(HaveLen(1))
+	})
+
+	// Given an unreachable console
+	// When we pull the configuration
+	// Then the error should be returned and nothing applied
+	It("returns the errors of the client", func() {
+		// Arrange
+		client.err = errors.New("connection refused")
+
+		// Act
+		err := srv.Fetch(ctx)
+
+		// Assert
+		Expect(err).To(MatchError("connection refused"))
+		Expect(appliedRemotes()).To(BeEmpty())
+		_, ok := srv.Current()
+		Expect(ok).To(BeFalse())
+	})
+
+	// Given a running service
+	// When its client is replaced
+	// Then the next pulls should use the new client
+	It("pulls on schedule with the current client", func() {
+		// Arrange
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		next := &fakeRemoteConfigClient{}
+		next.set(config.Remote{PolicyBundleURL: "https://console.example.com/bundle"})
+
+		// Act
+		go srv.Run(runCtx)
+		Eventually(client.pulls).Should(BeNumerically(">=", 1))
+		srv.SetClient(next)
+
+		// Assert
+		Eventually(appliedRemotes).Should(ContainElement(config.Remote{PolicyBundleURL: "https://console.example.com/bundle"}))
+	})
+})
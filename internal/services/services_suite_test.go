@@ -5,9 +5,16 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 func TestServices(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Services Suite")
 }
+
+// the store snapshot is built by the first spec opening it
+var _ = AfterSuite(func() {
+	Expect(storetest.Migrated.Remove()).To(Succeed())
+})
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// SourceServices are the services of a source: each source is collected into
+// its own database and dispatched to the console by its own loop.
+type SourceServices struct {
+	Store     *store.Store
+	Collector *CollectorService
+	Console   *Console
+	Inventory *InventoryService
+	// Close stops the collection and the console loop of the source, then closes
+	// its database, deleting it when remove is set. nil for the primary source
+	Close func(remove bool) error
+}
+
+// SourceFactory builds the services of an added source.
+type SourceFactory func(source models.Source) (*SourceServices, error)
+
+// Sources manages the sources of the agent: its primary source, the one of
+// Agent.SourceID served by the top level endpoints, and the sources added
+// through the API, one per vCenter, so a single agent collects several
+// vCenters. The added sources are kept in the store of the agent and built
+// again by Start at the next runs.
+type Sources struct {
+	store   *store.Store
+	primary models.Source
+	factory SourceFactory
+
+	mu       sync.RWMutex
+	sources  []models.Source
+	services map[string]*SourceServices
+	// client and updateInterval are the reloaded console settings, given as well
+	// to the sources added after the reload. nil and zero until a reload
+	client         *console.Client
+	updateInterval time.Duration
+}
+
+func NewSourcesService(st *store.Store, primary models.Source, primaryServices *SourceServices, factory SourceFactory) *Sources {
+	return &Sources{
+		store:    st,
+		primary:  primary,
+		factory:  factory,
+		sources:  []models.Source{primary},
+		services: map[string]*SourceServices{primary.ID: primaryServices},
+	}
+}
+
+// Start builds the services of the sources added by the previous runs. A
+// source whose services cannot be built is logged and left out.
+func (s *Sources) Start(ctx context.Context) error {
+	stored, err := s.store.Source().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	log := zap.S().Named("sources_service")
+	for _, source := range stored {
+		srv, err := s.factory(source)
+		if err != nil {
+			log.Errorw("failed to start source", "source_id", source.ID, "error", err)
+			continue
+		}
+
+		s.mu.Lock()
+		s.configure(srv)
+		s.sources = append(s.sources, source)
+		s.services[source.ID] = srv
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// List returns the sources, the primary one first, with their status.
+func (s *Sources) List() []models.SourceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]models.SourceStatus, 0, len(s.sources))
+	for _, source := range s.sources {
+		statuses = append(statuses, s.status(source))
+	}
+	return statuses
+}
+
+// Get returns the services of the source id, a ResourceNotFoundError when the
+// agent does not manage it.
+func (s *Sources) Get(id string) (*SourceServices, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	srv, ok := s.services[id]
+	if !ok {
+		return nil, srvErrors.NewResourceNotFoundError("source", id)
+	}
+	return srv, nil
+}
+
+// CollectorStatus returns the collector status of the source id.
+func (s *Sources) CollectorStatus(id string) (models.CollectorStatus, error) {
+	srv, err := s.Get(id)
+	if err != nil {
+		return models.CollectorStatus{}, err
+	}
+	return srv.Collector.GetStatus(), nil
+}
+
+// StartCollector starts the collection of the source id from the vCenter of creds.
+func (s *Sources) StartCollector(ctx context.Context, id string, creds *models.Credentials) error {
+	srv, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	return srv.Collector.Start(ctx, creds)
+}
+
+// StopCollector stops the collection of the source id.
+func (s *Sources) StopCollector(id string) error {
+	srv, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	srv.Collector.Stop()
+	return nil
+}
+
+// Inventory returns the inventory collected for the source id.
+func (s *Sources) Inventory(ctx context.Context, id string) (*models.Inventory, error) {
+	srv, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Inventory.GetInventory(ctx)
+}
+
+// Add builds the services of source and keeps it for the next runs. It fails
+// with a SourceConflictError when the agent already manages the source.
+func (s *Sources) Add(ctx context.Context, source models.Source) (models.SourceStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.services[source.ID]; ok {
+		return models.SourceStatus{}, srvErrors.NewSourceConflictError(source.ID, "already managed by the agent")
+	}
+
+	source.CreatedAt = time.Now().UTC()
+	srv, err := s.factory(source)
+	if err != nil {
+		return models.SourceStatus{}, fmt.Errorf("failed to start source %s: %w", source.ID, err)
+	}
+	if err := s.store.Source().Create(ctx, source); err != nil {
+		return models.SourceStatus{}, errors.Join(err, srv.Close(true))
+	}
+
+	s.configure(srv)
+	s.sources = append(s.sources, source)
+	s.services[source.ID] = srv
+	zap.S().Named("sources_service").Infow("source added", "source_id", source.ID, "name", source.Name)
+	return s.status(source), nil
+}
+
+// Remove stops the source id and deletes its inventory. The primary source
+// cannot be removed.
+func (s *Sources) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == s.primary.ID {
+		return srvErrors.NewSourceConflictError(id, "the primary source cannot be removed")
+	}
+	srv, ok := s.services[id]
+	if !ok {
+		return srvErrors.NewResourceNotFoundError("source", id)
+	}
+
+	if err := s.store.Source().Delete(ctx, id); err != nil {
+		return err
+	}
+	delete(s.services, id)
+	for i, source := range s.sources {
+		if source.ID == id {
+			s.sources = append(s.sources[:i], s.sources[i+1:]...)
+			break
+		}
+	}
+
+	zap.S().Named("sources_service").Infow("source removed", "source_id", id)
+	return srv.Close(true)
+}
+
+// SetMode sets mode on the console loops of the added sources, following the
+// mode of the agent.
+func (s *Sources) SetMode(ctx context.Context, mode models.AgentMode) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var errs []error
+	for id, srv := range s.services {
+		if id == s.primary.ID {
+			continue
+		}
+		if err := srv.Console.SetMode(ctx, mode); err != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DispatchNow makes the console loops of the added sources send their
+// inventory without waiting for the update interval.
+func (s *Sources) DispatchNow() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for id, srv := range s.services {
+		if id != s.primary.ID {
+			srv.Console.DispatchNow()
+		}
+	}
+}
+
+// SetClient replaces the client the added sources reach the console with.
+func (s *Sources) SetClient(client *console.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.client = client
+	for id, srv := range s.services {
+		if id != s.primary.ID {
+			srv.Console.SetClient(client)
+		}
+	}
+}
+
+// SetUpdateInterval changes the interval between two console updates of the
+// added sources.
+func (s *Sources) SetUpdateInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updateInterval = interval
+	for id, srv := range s.services {
+		if id != s.primary.ID {
+			srv.Console.SetUpdateInterval(interval)
+		}
+	}
+}
+
+// Stop stops the added sources and closes their databases.
+func (s *Sources) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for id, srv := range s.services {
+		if id == s.primary.ID {
+			continue
+		}
+		if err := srv.Close(false); err != nil {
+			errs = append(errs, fmt.Errorf("source %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configure gives the reloaded console settings to the services of an added
+// source. Called with mu held
+func (s *Sources) configure(srv *SourceServices) {
+	if s.client != nil {
+		srv.Console.SetClient(s.client)
+	}
+	if s.updateInterval > 0 {
+		srv.Console.SetUpdateInterval(s.updateInterval)
+	}
+}
+
+func (s *Sources) status(source models.Source) models.SourceStatus {
+	srv := s.services[source.ID]
+	return models.SourceStatus{
+		Source:    source,
+		Primary:   source.ID == s.primary.ID,
+		Collector: srv.Collector.GetStatus(),
+		Console:   srv.Console.Status(),
+	}
+}
@@ -0,0 +1,211 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("Sources", func() {
+	var (
+		ctx     context.Context
+		agentID string
+		sched   *scheduler.Scheduler
+		server  *mockconsole.Server
+		client  *console.Client
+		db      *sql.DB
+		st      *store.Store
+		primary models.Source
+		closed  map[string]bool
+		srv     *services.Sources
+	)
+
+	// newSourceServices builds the services of source on a database of its own
+	newSourceServices := func(source models.Source) (*services.SourceServices, error) {
+		sourceDB, err := storetest.Migrated.Open(GinkgoT().TempDir())
+		if err != nil {
+			return nil, err
+		}
+		sourceStore := store.NewStore(sourceDB, test.NewMockValidator())
+		collector := services.NewCollectorService(sched, sourceStore, &mockWorkBuilder{store: sourceStore})
+		consoleSrv, err := services.NewConsoleService(config.Agent{
+			Mode:           string(models.AgentModeDisconnected),
+			ID:             agentID,
+			SourceID:       source.ID,
+			UpdateInterval: 50 * time.Millisecond,
+		}, sched, client, collector, sourceStore)
+		if err != nil {
+			return nil, err
+		}
+		return &services.SourceServices{
+			Store:     sourceStore,
+			Collector: collector,
+			Console:   consoleSrv,
+			Inventory: services.NewInventoryService(sourceStore),
+			Close: func(remove bool) error {
+				consoleSrv.Stop()
+				collector.Stop()
+				closed[source.ID] = remove
+				return sourceStore.Close()
+			},
+		}, nil
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		agentID = uuid.New().String()
+		sched = scheduler.NewScheduler(1)
+		server = mockconsole.NewServer()
+		closed = map[string]bool{}
+
+		var err error
+		client, err = console.NewConsoleClient(server.URL(), "")
+		Expect(err).NotTo(HaveOccurred())
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, test.NewMockValidator())
+
+		primary = models.Source{ID: uuid.New().String(), Name: "primary"}
+		primaryServices, err := newSourceServices(primary)
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewSourcesService(st, primary, primaryServices, newSourceServices)
+	})
+
+	AfterEach(func() {
+		Expect(srv.Stop()).To(Succeed())
+		sched.Close()
+		server.Close()
+		db.Close()
+	})
+
+	// Given an agent with its primary source only
+	// When a source is added
+	// Then both should be listed, the primary one first
+	It("should add a source", func() {
+		// Arrange
+		id := uuid.New().String()
+
+		// Act
+		status, err := srv.Add(ctx, models.Source{ID: id, Name: "vcenter-2"})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Primary).To(BeFalse())
+		Expect(status.Collector.State).To(Equal(models.CollectorStateReady))
+		sources := srv.List()
+		Expect(sources).To(HaveLen(2))
+		Expect(sources[0].ID).To(Equal(primary.ID))
+		Expect(sources[0].Primary).To(BeTrue())
+		Expect(sources[1].ID).To(Equal(id))
+		Expect(sources[1].Name).To(Equal("vcenter-2"))
+	})
+
+	// Given a source already managed by the agent
+	// When it is added again
+	// Then it should fail with a conflict
+	It("should reject a source managed by the agent", func() {
+		// Act
+		_, err := srv.Add(ctx, models.Source{ID: primary.ID})
+
+		// Assert
+		Expect(srvErrors.IsSourceConflictError(err)).To(BeTrue())
+	})
+
+	// Given a source added in a previous run
+	// When the sources of a new run start
+	// Then the source should be managed again
+	It("should start the sources added before", func() {
+		// Arrange
+		id := uuid.New().String()
+		_, err := srv.Add(ctx, models.Source{ID: id, Name: "vcenter-2"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(srv.Stop()).To(Succeed())
+		primaryServices, err := srv.Get(primary.ID)
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewSourcesService(st, primary, primaryServices, newSourceServices)
+
+		// Act
+		err = srv.Start(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		added, err := srv.Get(id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(added.Collector.GetStatus().State).To(Equal(models.CollectorStateReady))
+		Expect(srv.List()).To(HaveLen(2))
+	})
+
+	// Given an added source
+	// When it is removed
+	// Then its services should be closed and its database deleted
+	It("should remove a source", func() {
+		// Arrange
+		id := uuid.New().String()
+		_, err := srv.Add(ctx, models.Source{ID: id})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		err = srv.Remove(ctx, id)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(closed).To(HaveKeyWithValue(id, true))
+		_, err = srv.Get(id)
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		Expect(srv.List()).To(HaveLen(1))
+	})
+
+	// Given the primary source
+	// When it is removed
+	// Then it should fail with a conflict
+	It("should not remove the primary source", func() {
+		// Act
+		err := srv.Remove(ctx, primary.ID)
+
+		// Assert
+		Expect(srvErrors.IsSourceConflictError(err)).To(BeTrue())
+	})
+
+	// Given an added source
+	// When the agent switches to connected mode
+	// Then the source should report to the console with its own source id
+	It("should dispatch the added sources in connected mode", func() {
+		// Arrange
+		id := uuid.New().String()
+		_, err := srv.Add(ctx, models.Source{ID: id})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		err = srv.SetMode(ctx, models.AgentModeConnected)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() []string {
+			ids := []string{}
+			for _, r := range server.Requests(mockconsole.AgentStatus) {
+				var body struct {
+					SourceID string `json:"sourceId"`
+				}
+				if r.Decode(&body) == nil {
+					ids = append(ids, body.SourceID)
+				}
+			}
+			return ids
+		}).Should(ContainElement(id))
+	})
+})
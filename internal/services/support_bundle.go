@@ -0,0 +1,226 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+// supportBundleConsoleErrors is the number of failed console dispatches in a
+// support bundle.
+const supportBundleConsoleErrors = 20
+
+type inspectorStatus interface {
+	GetStatus() models.InspectorStatus
+}
+
+type consoleStatus interface {
+	Status() models.ConsoleStatus
+}
+
+// SupportBundleService assembles the state of the agent a support engineer
+// asks for into a tar.gz archive.
+type SupportBundleService struct {
+	cfg       config.Configuration
+	store     *store.Store
+	scheduler *scheduler.Scheduler
+	collector Collector
+	inspector inspectorStatus
+	console   consoleStatus
+	logs      io.WriterTo
+	jobs      *JobService
+}
+
+func NewSupportBundleService(cfg config.Configuration, st *store.Store, sched *scheduler.Scheduler, console consoleStatus, collector Collector, inspector inspectorStatus) *SupportBundleService {
+	return &SupportBundleService{
+		cfg:       cfg,
+		store:     st,
+		scheduler: sched,
+		collector: collector,
+		inspector: inspector,
+		console:   console,
+	}
+}
+
+// WithLogs adds the recent logs written by logs to the bundles, e.g. a
+// logger.LogBuffer.
+func (s *SupportBundleService) WithLogs(logs io.WriterTo) *SupportBundleService {
+	s.logs = logs
+	return s
+}
+
+// WithJobService follows each support bundle as a job of jobs.
+func (s *SupportBundleService) WithJobService(jobs *JobService) *SupportBundleService {
+	s.jobs = jobs
+	return s
+}
+
+// bundleStatus is status.json, the state of the services.
+type bundleStatus struct {
+	Collector bundleServiceStatus `json:"collector"`
+	Inspector bundleServiceStatus `json:"inspector"`
+	Console   bundleConsoleStatus `json:"console"`
+}
+
+type bundleServiceStatus struct {
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+type bundleConsoleStatus struct {
+	Current string `json:"current"`
+	Target  string `json:"target"`
+	Error   string `json:"error,omitempty"`
+}
+
+type bundleFile struct {
+	name    string
+	content func(ctx context.Context) ([]byte, error)
+}
+
+// Write writes the support bundle to w as a tar.gz archive. A part that cannot
+// be collected is reported in errors.txt rather than failing the bundle, which
+// is most needed when the agent is not healthy.
+func (s *SupportBundleService) Write(ctx context.Context, w io.Writer) error {
+	job := s.jobs.Start(ctx, models.JobKindSupportBundle)
+	if err := s.write(ctx, w); err != nil {
+		s.jobs.Finish(ctx, job, models.JobStateFailed, err)
+		return err
+	}
+	s.jobs.Finish(ctx, job, models.JobStateCompleted, nil)
+	return nil
+}
+
+func (s *SupportBundleService) write(ctx context.Context, w io.Writer) error {
+	files := []bundleFile{
+		{"config.json", s.configuration},
+		{"status.json", s.status},
+		{"migrations.json", s.migrations},
+		{"database.json", s.database},
+		{"scheduler.json", s.schedulerStats},
+		{"console-errors.json", s.consoleErrors},
+	}
+	if s.logs != nil {
+		files = append(files, bundleFile{"logs.txt", s.recentLogs})
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	var failures []string
+	for _, f := range files {
+		content, err := f.content(ctx)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", f.name, err))
+			continue
+		}
+		if err := writeBundleFile(tw, f.name, content, now); err != nil {
+			return err
+		}
+	}
+	if len(failures) > 0 {
+		if err := writeBundleFile(tw, "errors.txt", []byte(strings.Join(failures, "\n")+"\n"), now); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing the support bundle archive: %w", err)
+	}
+	return gz.Close()
+}
+
+func writeBundleFile(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	}); err != nil {
+		return fmt.Errorf("writing %s header: %w", name, err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// configuration is the resolved configuration, its secrets and the passwords
+// of its URLs being redacted by it.
+func (s *SupportBundleService) configuration(context.Context) ([]byte, error) {
+	return json.MarshalIndent(s.cfg.ResolvedConfig(), "", "  ")
+}
+
+func (s *SupportBundleService) status(context.Context) ([]byte, error) {
+	collector := s.collector.GetStatus()
+	inspector := s.inspector.GetStatus()
+	console := s.console.Status()
+
+	return json.MarshalIndent(bundleStatus{
+		Collector: bundleServiceStatus{State: string(collector.State), Error: errorString(collector.Error)},
+		Inspector: bundleServiceStatus{State: string(inspector.State), Error: errorString(inspector.Error)},
+		Console: bundleConsoleStatus{
+			Current: string(console.Current),
+			Target:  string(console.Target),
+			Error:   errorString(console.Error),
+		},
+	}, "", "  ")
+}
+
+func (s *SupportBundleService) migrations(ctx context.Context) ([]byte, error) {
+	migrations, err := s.store.Migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(migrations, "", "  ")
+}
+
+func (s *SupportBundleService) database(ctx context.Context) ([]byte, error) {
+	stats, err := s.store.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(stats, "", "  ")
+}
+
+func (s *SupportBundleService) schedulerStats(context.Context) ([]byte, error) {
+	return json.MarshalIndent(s.scheduler.Stats(), "", "  ")
+}
+
+// consoleErrors are the last failed dispatches to the console, newest first.
+func (s *SupportBundleService) consoleErrors(ctx context.Context) ([]byte, error) {
+	events, err := s.store.AgentEvent().List(ctx, models.AgentEventFilter{
+		Types: []models.AgentEventType{models.AgentEventConsoleDispatchFailed},
+	}, models.Cursor{Limit: supportBundleConsoleErrors})
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(events, "", "  ")
+}
+
+func (s *SupportBundleService) recentLogs(context.Context) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := s.logs.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,169 @@
+package services_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+type stubInspector struct {
+	status models.InspectorStatus
+}
+
+func (s stubInspector) GetStatus() models.InspectorStatus {
+	return s.status
+}
+
+type stubConsole struct {
+	status models.ConsoleStatus
+}
+
+func (s stubConsole) Status() models.ConsoleStatus {
+	return s.status
+}
+
+var _ = Describe("SupportBundleService", func() {
+	var (
+		ctx   context.Context
+		db    *sql.DB
+		st    *store.Store
+		sched *scheduler.Scheduler
+		srv   *services.SupportBundleService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, test.NewMockValidator())
+		sched = scheduler.NewScheduler(2)
+
+		cfg := config.NewConfigurationWithOptionsAndDefaults()
+		cfg.Auth.JWT = "secret-jwt"
+
+		srv = services.NewSupportBundleService(*cfg, st, sched,
+			stubConsole{status: models.ConsoleStatus{
+				Current: models.ConsoleStatusDisconnected,
+				Target:  models.ConsoleStatusConnected,
+				Error:   errors.New("console unreachable"),
+			}},
+			NewMockCollector(models.CollectorStateReady),
+			stubInspector{status: models.InspectorStatus{State: models.InspectorStateReady}},
+		)
+	})
+
+	AfterEach(func() {
+		sched.Close()
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// readBundle returns the files of a tar.gz archive by name.
+	readBundle := func(data []byte) map[string]string {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		tr := tar.NewReader(gz)
+
+		files := map[string]string{}
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			content, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			files[hdr.Name] = string(content)
+		}
+		return files
+	}
+
+	// Given an agent with a failed console dispatch and recent logs
+	// When the support bundle is written
+	// Then it should contain the configuration, the state of the services, the database, the scheduler and the logs
+	It("should write the state of the agent", func() {
+		// Arrange
+		events := services.NewEventService(st)
+		events.Publish(ctx, models.AgentEventConsoleDispatchFailed, "failed to dispatch to the console", map[string]string{"error": "503"})
+		logs := logger.NewLogBuffer(10)
+		_, _ = logs.Write([]byte("collector started\n"))
+		srv.WithLogs(logs)
+
+		// Act
+		var out bytes.Buffer
+		err := srv.Write(ctx, &out)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		files := readBundle(out.Bytes())
+		Expect(files).To(HaveKey("config.json"))
+		Expect(files["config.json"]).NotTo(ContainSubstring("secret-jwt"))
+		Expect(files["status.json"]).To(ContainSubstring(`"error": "console unreachable"`))
+		Expect(files["status.json"]).To(ContainSubstring(`"state": "ready"`))
+		Expect(files["migrations.json"]).To(ContainSubstring(`"AppliedAt"`))
+		Expect(files["database.json"]).To(ContainSubstring("agent_events"))
+		Expect(files["scheduler.json"]).To(ContainSubstring(`"workers": 2`))
+		Expect(files["console-errors.json"]).To(ContainSubstring("failed to dispatch to the console"))
+		Expect(files["logs.txt"]).To(Equal("collector started\n"))
+		Expect(files).NotTo(HaveKey("errors.txt"))
+	})
+
+	// Given a closed database
+	// When the support bundle is written
+	// Then the parts read from it should be reported in errors.txt and the others kept
+	It("should report the parts that cannot be collected", func() {
+		// Arrange
+		Expect(db.Close()).To(Succeed())
+
+		// Act
+		var out bytes.Buffer
+		err := srv.Write(ctx, &out)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		files := readBundle(out.Bytes())
+		Expect(files).To(HaveKey("status.json"))
+		Expect(files).NotTo(HaveKey("database.json"))
+		Expect(files["errors.txt"]).To(ContainSubstring("database.json: "))
+	})
+
+	// Given a SupportBundleService following its bundles as jobs
+	// When a support bundle is written
+	// Then a completed support-bundle job should be recorded
+	It("should follow the bundles as jobs", func() {
+		// Arrange
+		jobs := services.NewJobService(st)
+		srv.WithJobService(jobs)
+
+		// Act
+		err := srv.Write(ctx, io.Discard)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		page, err := jobs.List(ctx, models.JobFilter{Kinds: []models.JobKind{models.JobKindSupportBundle}}, models.NewCursor(1, 10))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Items).To(HaveLen(1))
+		Expect(page.Items[0].State).To(Equal(models.JobStateCompleted))
+		Expect(page.Items[0].Progress).To(Equal(100))
+	})
+})
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/update"
+)
+
+// UpdateClient pulls the agent updates from the console.
+type UpdateClient interface {
+	GetUpdateManifest(ctx context.Context, channelURL string) (*models.UpdateManifest, error)
+	DownloadUpdate(ctx context.Context, binaryURL string) (io.ReadCloser, error)
+}
+
+// Updater keeps the agent up to date with its update channel on the console.
+// It installs a newer binary over the executable once its manifest is signed
+// with the key of the channel and its digest matches, then calls onInstalled
+// so the agent restarts into it. Nothing is checked while the channel URL is
+// empty.
+type Updater struct {
+	executable  string
+	key         ed25519.PublicKey
+	interval    time.Duration
+	onInstalled func()
+
+	mu     sync.Mutex // protects the fields below
+	client UpdateClient
+	url    string
+	status models.UpdateStatus
+}
+
+// NewUpdateService returns the updater of the executable at executable,
+// currently at version, verifying the updates with key.
+func NewUpdateService(client UpdateClient, channelURL string, key ed25519.PublicKey, executable, version string, interval time.Duration, onInstalled func()) *Updater {
+	u := &Updater{
+		executable:  executable,
+		key:         key,
+		interval:    interval,
+		onInstalled: onInstalled,
+		client:      client,
+		url:         channelURL,
+		status:      models.UpdateStatus{State: models.UpdateStateUpToDate, CurrentVersion: version},
+	}
+	if channelURL == "" {
+		u.status.State = models.UpdateStateDisabled
+	}
+	return u
+}
+
+// SetClient replaces the client, for a console URL changed by a configuration reload.
+func (u *Updater) SetClient(client UpdateClient) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.client = client
+}
+
+// SetURL replaces the channel URL, for a URL changed by a configuration reload.
+// The new channel is checked at the next Check.
+func (u *Updater) SetURL(channelURL string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.url = channelURL
+	if u.status.State == models.UpdateStateInstalled {
+		return
+	}
+	if channelURL == "" {
+		u.status = models.UpdateStatus{State: models.UpdateStateDisabled, CurrentVersion: u.status.CurrentVersion}
+	}
+}
+
+// Status returns the state of the self-update.
+func (u *Updater) Status() models.UpdateStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status
+}
+
+// Check pulls the manifest of the channel and installs its version when newer
+// than the current one. Once an update is installed, nothing more is checked
+// until the agent restarts.
+func (u *Updater) Check(ctx context.Context) error {
+	u.mu.Lock()
+	client, channelURL, status := u.client, u.url, u.status
+	u.mu.Unlock()
+
+	if channelURL == "" || status.State == models.UpdateStateInstalled {
+		return nil
+	}
+
+	status.CheckedAt = time.Now().UTC()
+	status.AvailableVersion = ""
+	status.Error = nil
+
+	manifest, err := u.fetch(ctx, client, channelURL)
+	if err != nil {
+		return u.fail(status, err)
+	}
+	if !update.Newer(manifest.Version, status.CurrentVersion) {
+		status.State = models.UpdateStateUpToDate
+		u.setStatus(status)
+		return nil
+	}
+
+	status.State = models.UpdateStateDownloading
+	status.AvailableVersion = manifest.Version
+	u.setStatus(status)
+
+	log := zap.S().Named("update_service")
+	log.Infow("downloading update", "version", manifest.Version, "url", manifest.URL)
+	body, err := client.DownloadUpdate(ctx, manifest.URL)
+	if err != nil {
+		return u.fail(status, err)
+	}
+	err = update.Install(u.executable, body, manifest.SHA256)
+	body.Close()
+	if err != nil {
+		return u.fail(status, err)
+	}
+
+	status.State = models.UpdateStateInstalled
+	u.setStatus(status)
+	log.Infow("update installed, restarting", "version", manifest.Version, "previous", u.executable+update.PreviousSuffix)
+	u.onInstalled()
+	return nil
+}
+
+// Run checks the channel at once, then every interval until ctx is done.
+func (u *Updater) Run(ctx context.Context) {
+	tick := time.NewTicker(u.interval)
+	defer tick.Stop()
+
+	for {
+		if err := u.Check(ctx); err != nil && ctx.Err() == nil {
+			zap.S().Named("update_service").Warnw("failed to update the agent", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// fetch returns the manifest of the channel, verified with the key.
+func (u *Updater) fetch(ctx context.Context, client UpdateClient, channelURL string) (*models.UpdateManifest, error) {
+	if u.key == nil {
+		return nil, errors.New("no public key to verify the updates")
+	}
+	manifest, err := client.GetUpdateManifest(ctx, channelURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := update.Verify(*manifest, u.key); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (u *Updater) fail(status models.UpdateStatus, err error) error {
+	status.State = models.UpdateStateFailed
+	status.Error = err
+	u.setStatus(status)
+	return err
+}
+
+func (u *Updater) setStatus(status models.UpdateStatus) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	// the channel was removed by a reload during the check
+	if u.url == "" && status.State != models.UpdateStateInstalled {
+		status = models.UpdateStatus{State: models.UpdateStateDisabled, CurrentVersion: status.CurrentVersion}
+	}
+	u.status = status
+}
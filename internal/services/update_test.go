@@ -0,0 +1,151 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
+)
+
+var _ = Describe("Updater", func() {
+	const (
+		channelURL = "/api/v1/agents/7c6f0f2e-5f3a-4d8e-9b1a-2f0e8d6c4a10/update"
+		binaryURL  = channelURL + "/binary"
+	)
+
+	var (
+		ctx        context.Context
+		executable string
+		private    ed25519.PrivateKey
+		server     *mockconsole.Server
+		installed  int
+		srv        *services.Updater
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		executable = filepath.Join(GinkgoT().TempDir(), "agent")
+		Expect(os.WriteFile(executable, []byte("v2.0.0"), 0o755)).To(Succeed())
+		server = mockconsole.NewServer()
+		installed = 0
+
+		public, key, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		private = key
+
+		client, err := console.NewConsoleClient(server.URL(), "")
+		Expect(err).NotTo(HaveOccurred())
+		srv = services.NewUpdateService(client, channelURL, public, executable, "v2.0.0", time.Minute, func() {
+			installed++
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	serve := func(manifest models.UpdateManifest, binary []byte) {
+		server.Respond(mockconsole.UpdateManifest, mockconsole.Response{Status: http.StatusOK, Body: manifest})
+		server.Respond(mockconsole.UpdateBinary, mockconsole.Response{Status: http.StatusOK, Body: binary})
+	}
+
+	// Given a newer version on the channel
+	// When the channel is checked
+	// Then the binary should be installed and the agent restarted
+	It("should install a newer version", func() {
+		// Arrange
+		serve(test.NewUpdateManifest(private, "v2.1.0", binaryURL, []byte("v2.1.0")), []byte("v2.1.0"))
+
+		// Act
+		err := srv.Check(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed).To(Equal(1))
+		Expect(os.ReadFile(executable)).To(Equal([]byte("v2.1.0")))
+		status := srv.Status()
+		Expect(status.State).To(Equal(models.UpdateStateInstalled))
+		Expect(status.AvailableVersion).To(Equal("v2.1.0"))
+	})
+
+	// Given the current version on the channel
+	// When the channel is checked
+	// Then nothing should be downloaded
+	It("should stay up to date", func() {
+		// Arrange
+		serve(test.NewUpdateManifest(private, "v2.0.0", binaryURL, []byte("v2.0.0")), []byte("v2.0.0"))
+
+		// Act
+		err := srv.Check(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(installed).To(BeZero())
+		Expect(server.Count(mockconsole.UpdateBinary)).To(BeZero())
+		Expect(srv.Status().State).To(Equal(models.UpdateStateUpToDate))
+	})
+
+	// Given a newer version signed with another key
+	// When the channel is checked
+	// Then nothing should be downloaded and the failure reported
+	It("should reject a manifest not signed with the key of the channel", func() {
+		// Arrange
+		_, other, err := ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+		serve(test.NewUpdateManifest(other, "v2.1.0", binaryURL, []byte("v2.1.0")), []byte("v2.1.0"))
+
+		// Act
+		err = srv.Check(ctx)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(server.Count(mockconsole.UpdateBinary)).To(BeZero())
+		status := srv.Status()
+		Expect(status.State).To(Equal(models.UpdateStateFailed))
+		Expect(status.Error).To(HaveOccurred())
+	})
+
+	// Given a binary differing from the manifest
+	// When the channel is checked
+	// Then the executable should be left untouched
+	It("should not install a corrupted binary", func() {
+		// Arrange
+		serve(test.NewUpdateManifest(private, "v2.1.0", binaryURL, []byte("v2.1.0")), []byte("corrupted"))
+
+		// Act
+		err := srv.Check(ctx)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(installed).To(BeZero())
+		Expect(os.ReadFile(executable)).To(Equal([]byte("v2.0.0")))
+		Expect(srv.Status().State).To(Equal(models.UpdateStateFailed))
+	})
+
+	// Given the channel URL removed by a reload
+	// When the channel is checked
+	// Then nothing should be requested and the updates be disabled
+	It("should not check without a channel", func() {
+		// Arrange
+		srv.SetURL("")
+
+		// Act
+		err := srv.Check(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Count(mockconsole.UpdateManifest)).To(BeZero())
+		Expect(srv.Status().State).To(Equal(models.UpdateStateDisabled))
+	})
+})
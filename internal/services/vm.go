@@ -28,16 +28,27 @@ type VMListParams struct {
 	DiskSizeMax   *int64
 	MemorySizeMin *int64
 	MemorySizeMax *int64
+	Encrypted     *bool
+	Severities    []string
 	Sort          []SortField
-	Limit         uint64
-	Offset        uint64
+	// Cursor is the page to list, every VM when its limit is 0
+	Cursor models.Cursor
 }
 
 func (s *VMService) Get(ctx context.Context, id string) (*models.VM, error) {
 	return s.store.VM().Get(ctx, id)
 }
 
-func (s *VMService) List(ctx context.Context, params VMListParams) ([]models.VMSummary, int, error) {
+// Events returns the recent vCenter events and triggered alarms of the VM and its host.
+func (s *VMService) Events(ctx context.Context, id string) ([]models.VCenterEvent, error) {
+	if _, err := s.store.VM().Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.store.Event().ListByVM(ctx, id)
+}
+
+// List returns the page of the VMs matching params at params.Cursor.
+func (s *VMService) List(ctx context.Context, params VMListParams) (models.Page[models.VMSummary], error) {
 	opts := s.buildListOptions(params)
 
 	if len(params.Sort) == 0 {
@@ -46,7 +57,7 @@ func (s *VMService) List(ctx context.Context, params VMListParams) ([]models.VMS
 
 	vms, err := s.store.VM().List(ctx, opts...)
 	if err != nil {
-		return nil, 0, err
+		return models.Page[models.VMSummary]{}, err
 	}
 
 	// Get total count without pagination
@@ -58,13 +69,15 @@ func (s *VMService) List(ctx context.Context, params VMListParams) ([]models.VMS
 		DiskSizeMax:   params.DiskSizeMax,
 		MemorySizeMin: params.MemorySizeMin,
 		MemorySizeMax: params.MemorySizeMax,
+		Encrypted:     params.Encrypted,
+		Severities:    params.Severities,
 	})
 	total, err := s.store.VM().Count(ctx, countOpts...)
 	if err != nil {
-		return nil, 0, err
+		return models.Page[models.VMSummary]{}, err
 	}
 
-	return vms, total, nil
+	return models.NewPage(vms, params.Cursor, total), nil
 }
 
 func (s *VMService) buildListOptions(params VMListParams) []store.ListOption {
@@ -106,6 +119,14 @@ func (s *VMService) buildListOptions(params VMListParams) []store.ListOption {
 		opts = append(opts, store.ByMemorySizeRange(min, max))
 	}
 
+	if params.Encrypted != nil {
+		opts = append(opts, store.ByEncrypted(*params.Encrypted))
+	}
+
+	if len(params.Severities) > 0 {
+		opts = append(opts, store.ByConcernSeverity(params.Severities...))
+	}
+
 	if len(params.Sort) > 0 {
 		sortParams := make([]store.SortParam, len(params.Sort))
 		for i, s := range params.Sort {
@@ -114,12 +135,7 @@ func (s *VMService) buildListOptions(params VMListParams) []store.ListOption {
 		opts = append(opts, store.WithSort(sortParams))
 	}
 
-	if params.Limit > 0 {
-		opts = append(opts, store.WithLimit(params.Limit))
-	}
-	if params.Offset > 0 {
-		opts = append(opts, store.WithOffset(params.Offset))
-	}
+	opts = append(opts, store.WithCursor(params.Cursor))
 
 	return opts
 }
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+	"go.uber.org/zap"
+)
+
+const (
+	// healthCheckInterval is how often the health checks run without a
+	// systemd watchdog.
+	healthCheckInterval = 30 * time.Second
+	// startupCheckInterval is how often the health checks run until they
+	// first pass.
+	startupCheckInterval = time.Second
+	// sdNotifyStatus prefixes the status of the unit shown by systemctl status.
+	sdNotifyStatus = "STATUS="
+)
+
+// HealthCheck is a self-check of the agent, failing when a part it depends on
+// does not answer.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Watchdog reports the state of the agent to systemd through sd_notify: READY
+// once the health checks first pass, then WATCHDOG pings while they pass, so
+// systemd restarts a hung agent after WatchdogSec, and STOPPING on shutdown.
+// The failing check is reported in the STATUS of the unit. Outside systemd,
+// without NOTIFY_SOCKET, the checks still run and their failures are logged.
+type Watchdog struct {
+	checks   []HealthCheck
+	notify   func(state string) error
+	interval time.Duration
+}
+
+// NewWatchdogService returns the watchdog running checks, pinging systemd at
+// half of its WatchdogSec when the unit sets one.
+func NewWatchdogService(checks ...HealthCheck) *Watchdog {
+	w := &Watchdog{
+		checks:   checks,
+		notify:   sdNotify,
+		interval: healthCheckInterval,
+	}
+	if timeout, err := daemon.SdWatchdogEnabled(false); err != nil {
+		zap.S().Named("watchdog_service").Warnw("invalid systemd watchdog settings", "error", err)
+	} else if timeout > 0 {
+		w.interval = timeout / 2
+	}
+	return w
+}
+
+// WithNotifier replaces sd_notify, for the tests.
+func (w *Watchdog) WithNotifier(notify func(state string) error) *Watchdog {
+	w.notify = notify
+	return w
+}
+
+// WithInterval replaces the interval of the health checks and pings.
+func (w *Watchdog) WithInterval(interval time.Duration) *Watchdog {
+	w.interval = interval
+	return w
+}
+
+// Run notifies READY once the checks pass, then runs them every interval,
+// pinging the watchdog while they pass, until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) {
+	log := zap.S().Named("watchdog_service")
+	w.send(sdNotifyStatus + "starting")
+
+	startup := time.NewTicker(startupCheckInterval)
+	for err := w.Check(ctx); err != nil; err = w.Check(ctx) {
+		w.send(sdNotifyStatus + "starting: " + err.Error())
+		select {
+		case <-ctx.Done():
+			startup.Stop()
+			w.send(daemon.SdNotifyStopping)
+			return
+		case <-startup.C:
+		}
+	}
+	startup.Stop()
+	w.send(daemon.SdNotifyReady + "\n" + sdNotifyStatus + "running")
+	log.Info("agent ready")
+
+	tick := time.NewTicker(w.interval)
+	defer tick.Stop()
+	healthy := true
+	for {
+		select {
+		case <-ctx.Done():
+			w.send(daemon.SdNotifyStopping)
+			return
+		case <-tick.C:
+		}
+
+		if err := w.Check(ctx); err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			// no ping: systemd restarts the agent once WatchdogSec elapses
+			log.Errorw("health check failed", "error", err)
+			w.send(sdNotifyStatus + "unhealthy: " + err.Error())
+			healthy = false
+			continue
+		}
+		if !healthy {
+			log.Info("health checks pass again")
+			w.send(sdNotifyStatus + "running")
+			healthy = true
+		}
+		w.send(daemon.SdNotifyWatchdog)
+	}
+}
+
+// Check runs the health checks, each bounded by the interval, and returns the
+// error of the first failing one.
+func (w *Watchdog) Check(ctx context.Context) error {
+	for _, c := range w.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, w.interval)
+		err := c.Check(checkCtx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func (w *Watchdog) send(state string) {
+	if err := w.notify(state); err != nil {
+		zap.S().Named("watchdog_service").Warnw("failed to notify systemd", "state", state, "error", err)
+	}
+}
+
+// sdNotify sends state to systemd, doing nothing outside a notify unit.
+func sdNotify(state string) error {
+	_, err := daemon.SdNotify(false, state)
+	return err
+}
@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+// notifications records the states sent to systemd.
+type notifications struct {
+	mu     sync.Mutex
+	states []string
+}
+
+func (n *notifications) notify(state string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.states = append(n.states, state)
+	return nil
+}
+
+func (n *notifications) get() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.states...)
+}
+
+var _ = Describe("Watchdog", func() {
+	var (
+		ctx     context.Context
+		cancel  context.CancelFunc
+		sent    *notifications
+		healthy atomic.Bool
+		done    chan struct{}
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		sent = &notifications{}
+		healthy.Store(true)
+		done = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	run := func() {
+		w := services.NewWatchdogService(services.HealthCheck{
+			Name: "database",
+			Check: func(ctx context.Context) error {
+				if !healthy.Load() {
+					return errors.New("no answer")
+				}
+				return nil
+			},
+		}).WithNotifier(sent.notify).WithInterval(20 * time.Millisecond)
+		go func() {
+			defer close(done)
+			w.Run(ctx)
+		}()
+	}
+
+	// Given passing health checks
+	// When the watchdog runs
+	// Then it should notify READY, then ping the watchdog
+	It("should notify ready and ping while healthy", func() {
+		// Act
+		run()
+
+		// Assert
+		Eventually(sent.get).Should(ContainElement("READY=1\nSTATUS=running"))
+		Eventually(sent.get).Should(ContainElement("WATCHDOG=1"))
+	})
+
+	// Given a health check failing once the agent is ready
+	// When the watchdog runs
+	// Then it should stop pinging and report the failing check
+	It("should stop pinging while a check fails", func() {
+		// Arrange
+		run()
+		Eventually(sent.get).Should(ContainElement("WATCHDOG=1"))
+
+		// Act
+		healthy.Store(false)
+
+		// Assert
+		Eventually(sent.get).Should(ContainElement("STATUS=unhealthy: database: no answer"))
+		pings := len(sent.get())
+		Consistently(func() []string { return sent.get()[pings:] }, "100ms").ShouldNot(ContainElement("WATCHDOG=1"))
+	})
+
+	// Given a health check failing at startup
+	// When the watchdog runs
+	// Then READY should wait for the check to pass
+	It("should not notify ready before the checks pass", func() {
+		// Arrange
+		healthy.Store(false)
+
+		// Act
+		run()
+
+		// Assert
+		Eventually(sent.get).Should(ContainElement("STATUS=starting: database: no answer"))
+		Expect(sent.get()).NotTo(ContainElement(HavePrefix("READY=1")))
+		healthy.Store(true)
+		Eventually(sent.get, "3s").Should(ContainElement(HavePrefix("READY=1")))
+	})
+
+	// Given a running watchdog
+	// When the agent stops
+	// Then STOPPING should be notified
+	It("should notify stopping on shutdown", func() {
+		// Arrange
+		run()
+		Eventually(sent.get).Should(ContainElement(HavePrefix("READY=1")))
+
+		// Act
+		cancel()
+
+		// Assert
+		Eventually(done).Should(BeClosed())
+		states := sent.get()
+		Expect(states[len(states)-1]).To(Equal("STOPPING=1"))
+	})
+})
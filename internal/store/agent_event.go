@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for agent_events table
+const (
+	agentEventsTable        = "agent_events"
+	agentEventsColCreatedAt = "created_at"
+	agentEventsColType      = "type"
+	agentEventsColMessage   = "message"
+	agentEventsColDetails   = "details"
+)
+
+// maxAgentEvents bounds the number of events kept.
+const maxAgentEvents = 10000
+
+type AgentEventStore struct {
+	db QueryInterceptor
+}
+
+func NewAgentEventStore(db QueryInterceptor) *AgentEventStore {
+	return &AgentEventStore{db: db}
+}
+
+// Insert appends event, keeping only the most recent maxAgentEvents.
+func (s *AgentEventStore) Insert(ctx context.Context, event models.AgentEvent) error {
+	details := event.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("marshaling event details: %w", err)
+	}
+
+	query, args, err := sq.Insert(agentEventsTable).
+		Columns(agentEventsColCreatedAt, agentEventsColType, agentEventsColMessage, agentEventsColDetails).
+		Values(event.Time.UTC(), string(event.Type), event.Message, string(detailsJSON)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building event insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting event: %w", err)
+	}
+
+	// keep the log bounded: drop everything older than the newest maxAgentEvents
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE %[2]s < (
+			SELECT MIN(%[2]s) FROM (SELECT %[2]s FROM %[1]s ORDER BY %[2]s DESC LIMIT %[3]d)
+		)`, agentEventsTable, agentEventsColCreatedAt, maxAgentEvents)); err != nil {
+		return fmt.Errorf("trimming events: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the page at cursor of the events matching filter, newest first.
+func (s *AgentEventStore) List(ctx context.Context, filter models.AgentEventFilter, cursor models.Cursor) ([]models.AgentEvent, error) {
+	builder := sq.Select(
+		agentEventsColCreatedAt,
+		agentEventsColType,
+		agentEventsColMessage,
+		agentEventsColDetails,
+	).From(agentEventsTable).
+		OrderBy(agentEventsColCreatedAt + " DESC")
+
+	query, args, err := WithCursor(cursor)(applyAgentEventFilter(builder, filter)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building events query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AgentEvent{}
+	for rows.Next() {
+		var (
+			e       models.AgentEvent
+			details string
+		)
+		if err := rows.Scan(&e.Time, &e.Type, &e.Message, &details); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(details), &e.Details); err != nil {
+			return nil, fmt.Errorf("unmarshaling event details: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Count returns the number of events matching filter.
+func (s *AgentEventStore) Count(ctx context.Context, filter models.AgentEventFilter) (int, error) {
+	query, args, err := applyAgentEventFilter(sq.Select("COUNT(*)").From(agentEventsTable), filter).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("building events count query: %w", err)
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func applyAgentEventFilter(builder sq.SelectBuilder, filter models.AgentEventFilter) sq.SelectBuilder {
+	if len(filter.Types) > 0 {
+		types := make([]string, 0, len(filter.Types))
+		for _, t := range filter.Types {
+			types = append(types, string(t))
+		}
+		builder = builder.Where(sq.Eq{agentEventsColType: types})
+	}
+	if !filter.Since.IsZero() {
+		builder = builder.Where(sq.GtOrEq{agentEventsColCreatedAt: filter.Since.UTC()})
+	}
+	return builder
+}
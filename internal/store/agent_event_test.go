@@ -0,0 +1,133 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("AgentEventStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+		at  time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		at = time.Now().UTC().Truncate(time.Second)
+		Expect(s.AgentEvent().Insert(ctx, models.AgentEvent{Type: models.AgentEventCollectionStarted, Time: at, Message: "collection started"})).To(Succeed())
+		Expect(s.AgentEvent().Insert(ctx, models.AgentEvent{Type: models.AgentEventCollectionFailed, Time: at.Add(time.Minute), Message: "collection failed", Details: map[string]string{"phase": "connecting", "error": "invalid credentials"}})).To(Succeed())
+		Expect(s.AgentEvent().Insert(ctx, models.AgentEvent{Type: models.AgentEventModeChanged, Time: at.Add(2 * time.Minute), Message: "agent mode changed", Details: map[string]string{"mode": "connected"}})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given three recorded events
+	// When we list them
+	// Then they should be returned newest first with their details
+	It("should list the events newest first", func() {
+		// Act
+		events, err := s.AgentEvent().List(ctx, models.AgentEventFilter{}, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].Type).To(Equal(models.AgentEventModeChanged))
+		Expect(events[1].Type).To(Equal(models.AgentEventCollectionFailed))
+		Expect(events[1].Message).To(Equal("collection failed"))
+		Expect(events[1].Details).To(Equal(map[string]string{"phase": "connecting", "error": "invalid credentials"}))
+		Expect(events[1].Time.Equal(at.Add(time.Minute))).To(BeTrue())
+		Expect(events[2].Details).To(BeEmpty())
+	})
+
+	// Given three recorded events of different types
+	// When we filter them by type
+	// Then only the events of those types should be returned
+	It("should filter the events by type", func() {
+		// Act
+		events, err := s.AgentEvent().List(ctx, models.AgentEventFilter{
+			Types: []models.AgentEventType{models.AgentEventCollectionStarted, models.AgentEventCollectionFailed},
+		}, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Type).To(Equal(models.AgentEventCollectionFailed))
+		Expect(events[1].Type).To(Equal(models.AgentEventCollectionStarted))
+	})
+
+	// Given three recorded events a minute apart
+	// When we list the events since the second one
+	// Then the first event should be left out
+	It("should filter the events by time", func() {
+		// Act
+		events, err := s.AgentEvent().List(ctx, models.AgentEventFilter{Since: at.Add(time.Minute)}, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[1].Type).To(Equal(models.AgentEventCollectionFailed))
+	})
+
+	// Given three recorded events
+	// When we list them with a limit of one
+	// Then only the most recent event should be returned
+	It("should limit the number of events", func() {
+		// Act
+		events, err := s.AgentEvent().List(ctx, models.AgentEventFilter{}, models.Cursor{Limit: 1})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(models.AgentEventModeChanged))
+	})
+
+	// Given three recorded events
+	// When we list the second page of one event
+	// Then the second most recent event should be returned
+	It("should list the page at the cursor", func() {
+		// Act
+		events, err := s.AgentEvent().List(ctx, models.AgentEventFilter{}, models.NewCursor(2, 1))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(models.AgentEventCollectionFailed))
+	})
+
+	// Given three recorded events of different types
+	// When we count the events of a type
+	// Then only the events of that type should be counted
+	It("should count the events matching the filter", func() {
+		// Act
+		all, err := s.AgentEvent().Count(ctx, models.AgentEventFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		failed, err := s.AgentEvent().Count(ctx, models.AgentEventFilter{Types: []models.AgentEventType{models.AgentEventCollectionFailed}})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(Equal(3))
+		Expect(failed).To(Equal(1))
+	})
+})
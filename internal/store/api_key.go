@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for api_keys table
+const (
+	apiKeysTable        = "api_keys"
+	apiKeysColID        = "id"
+	apiKeysColName      = "name"
+	apiKeysColPrefix    = "prefix"
+	apiKeysColRole      = "role"
+	apiKeysColHash      = "hash"
+	apiKeysColCreatedAt = "created_at"
+	apiKeysColRevokedAt = "revoked_at"
+)
+
+type APIKeyStore struct {
+	db QueryInterceptor
+}
+
+func NewAPIKeyStore(db QueryInterceptor) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+// Create stores key.
+func (s *APIKeyStore) Create(ctx context.Context, key models.APIKey) error {
+	query, args, err := sq.Insert(apiKeysTable).
+		Columns(apiKeysColID, apiKeysColName, apiKeysColPrefix, apiKeysColRole, apiKeysColHash, apiKeysColCreatedAt).
+		Values(key.ID, key.Name, key.Prefix, string(key.Role), key.Hash, key.CreatedAt.UTC()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building api key insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting api key: %w", err)
+	}
+	return nil
+}
+
+// List returns the keys, revoked ones included, ordered by creation time.
+func (s *APIKeyStore) List(ctx context.Context) ([]models.APIKey, error) {
+	query, args, err := s.selectKeys().OrderBy(apiKeysColCreatedAt).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building api keys query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetByHash returns the key whose hash is hash.
+func (s *APIKeyStore) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query, args, err := s.selectKeys().Where(sq.Eq{apiKeysColHash: hash}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building api key query: %w", err)
+	}
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, srvErrors.NewResourceNotFoundError("api key", "")
+	}
+	return key, err
+}
+
+// Revoke marks the key id revoked at revokedAt. It fails with a
+// ResourceNotFoundError when there is no such key or it is already revoked.
+func (s *APIKeyStore) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	query, args, err := sq.Update(apiKeysTable).
+		Set(apiKeysColRevokedAt, revokedAt.UTC()).
+		Where(sq.Eq{apiKeysColID: id, apiKeysColRevokedAt: nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building api key update: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("revoking api key: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return srvErrors.NewResourceNotFoundError("api key", id)
+	}
+	return nil
+}
+
+// CountActive returns the number of keys not revoked.
+func (s *APIKeyStore) CountActive(ctx context.Context) (int, error) {
+	query, args, err := sq.Select("COUNT(*)").From(apiKeysTable).Where(sq.Eq{apiKeysColRevokedAt: nil}).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("building api keys count: %w", err)
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (s *APIKeyStore) selectKeys() sq.SelectBuilder {
+	return sq.Select(
+		apiKeysColID,
+		apiKeysColName,
+		apiKeysColPrefix,
+		apiKeysColRole,
+		apiKeysColHash,
+		apiKeysColCreatedAt,
+		apiKeysColRevokedAt,
+	).From(apiKeysTable)
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*models.APIKey, error) {
+	var (
+		key       models.APIKey
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &key.Hash, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
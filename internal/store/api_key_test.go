@@ -0,0 +1,168 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("APIKeyStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	Context("Create", func() {
+		// Given two keys
+		// When we list them
+		// Then they should be returned ordered by creation time
+		It("should store and list keys", func() {
+			// Arrange
+			createdAt := time.Now().UTC().Truncate(time.Second)
+			Expect(s.APIKey().Create(ctx, models.APIKey{ID: "key-2", Name: "second", Prefix: "ama_222222", Hash: "hash-2", CreatedAt: createdAt.Add(time.Minute)})).To(Succeed())
+			Expect(s.APIKey().Create(ctx, models.APIKey{ID: "key-1", Name: "first", Prefix: "ama_111111", Role: models.RoleViewer, Hash: "hash-1", CreatedAt: createdAt})).To(Succeed())
+
+			// Act
+			keys, err := s.APIKey().List(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(2))
+			Expect(keys[0].ID).To(Equal("key-1"))
+			Expect(keys[0].Name).To(Equal("first"))
+			Expect(keys[0].Prefix).To(Equal("ama_111111"))
+			Expect(keys[0].Role).To(Equal(models.RoleViewer))
+			Expect(keys[0].CreatedAt.Equal(createdAt)).To(BeTrue())
+			Expect(keys[0].RevokedAt).To(BeNil())
+			Expect(keys[1].ID).To(Equal("key-2"))
+		})
+
+		// Given no key
+		// When we list them
+		// Then an empty list should be returned
+		It("should return an empty list", func() {
+			// Act
+			keys, err := s.APIKey().List(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(BeEmpty())
+		})
+	})
+
+	Context("GetByHash", func() {
+		// Given a stored key
+		// When we get it by its hash and by an unknown hash
+		// Then the key should be found and the unknown hash not
+		It("should find a key by hash", func() {
+			// Arrange
+			Expect(s.APIKey().Create(ctx, models.APIKey{ID: "key-1", Name: "first", Prefix: "ama_111111", Hash: "hash-1", CreatedAt: time.Now()})).To(Succeed())
+
+			// Act
+			key, err := s.APIKey().GetByHash(ctx, "hash-1")
+			_, missingErr := s.APIKey().GetByHash(ctx, "hash-9")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key.ID).To(Equal("key-1"))
+			Expect(srvErrors.IsResourceNotFoundError(missingErr)).To(BeTrue())
+		})
+	})
+
+	Context("Revoke", func() {
+		// Given a stored key
+		// When we revoke it twice
+		// Then it should be marked revoked and the second revoke should fail
+		It("should revoke a key once", func() {
+			// Arrange
+			revokedAt := time.Now().UTC().Truncate(time.Second)
+			Expect(s.APIKey().Create(ctx, models.APIKey{ID: "key-1", Name: "first", Prefix: "ama_111111", Hash: "hash-1", CreatedAt: time.Now()})).To(Succeed())
+
+			// Act
+			err := s.APIKey().Revoke(ctx, "key-1", revokedAt)
+			againErr := s.APIKey().Revoke(ctx, "key-1", revokedAt)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(srvErrors.IsResourceNotFoundError(againErr)).To(BeTrue())
+			key, err := s.APIKey().GetByHash(ctx, "hash-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key.RevokedAt).NotTo(BeNil())
+			Expect(key.RevokedAt.Equal(revokedAt)).To(BeTrue())
+		})
+
+		// Given no key
+		// When we revoke an unknown id
+		// Then a not found error should be returned
+		It("should fail for an unknown key", func() {
+			// Act
+			err := s.APIKey().Revoke(ctx, "key-9", time.Now())
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("CountActive", func() {
+		// Given a revoked and an active key
+		// When we count the active keys
+		// Then only the active one should be counted
+		It("should not count the revoked keys", func() {
+			// Arrange
+			Expect(s.APIKey().Create(ctx, models.APIKey{ID: "key-1", Name: "first", Prefix: "ama_111111", Hash: "hash-1", CreatedAt: time.Now()})).To(Succeed())
+			Expect(s.APIKey().Create(ctx, models.APIKey{ID: "key-2", Name: "second", Prefix: "ama_222222", Hash: "hash-2", CreatedAt: time.Now()})).To(Succeed())
+			Expect(s.APIKey().Revoke(ctx, "key-1", time.Now())).To(Succeed())
+
+			// Act
+			count, err := s.APIKey().CountActive(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+	})
+
+	Context("Role", func() {
+		// Given a key stored before roles were added
+		// When we read it
+		// Then it should keep the operator role
+		It("gives the keys predating roles the operator role", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO api_keys (id, name, prefix, hash, created_at) VALUES ('key-1', 'old', 'ama_111111', 'hash-1', now())`)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			key, err := s.APIKey().GetByHash(ctx, "hash-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key.Role).To(Equal(models.RoleOperator))
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for audit_log table
+const (
+	auditTable        = "audit_log"
+	auditColCreatedAt = "created_at"
+	auditColSubject   = "subject"
+	auditColRole      = "role"
+	auditColMethod    = "method"
+	auditColPath      = "path"
+	auditColStatus    = "status"
+	auditColRequestID = "request_id"
+)
+
+// maxAuditEntries bounds the number of entries kept in the audit log.
+const maxAuditEntries = 10000
+
+type AuditStore struct {
+	db QueryInterceptor
+}
+
+func NewAuditStore(db QueryInterceptor) *AuditStore {
+	return &AuditStore{db: db}
+}
+
+// Insert appends entry to the log, keeping only the most recent maxAuditEntries.
+func (s *AuditStore) Insert(ctx context.Context, entry models.AuditEntry) error {
+	query, args, err := sq.Insert(auditTable).
+		Columns(auditColCreatedAt, auditColSubject, auditColRole, auditColMethod, auditColPath, auditColStatus, auditColRequestID).
+		Values(entry.Time.UTC(), entry.Subject, string(entry.Role), entry.Method, entry.Path, entry.Status, entry.RequestID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building audit insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+
+	// keep the log bounded: drop everything older than the newest maxAuditEntries
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE %[2]s < (
+			SELECT MIN(%[2]s) FROM (SELECT %[2]s FROM %[1]s ORDER BY %[2]s DESC LIMIT %[3]d)
+		)`, auditTable, auditColCreatedAt, maxAuditEntries)); err != nil {
+		return fmt.Errorf("trimming audit log: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the page at cursor of the entries, newest first.
+func (s *AuditStore) List(ctx context.Context, cursor models.Cursor) ([]models.AuditEntry, error) {
+	builder := sq.Select(
+		auditColCreatedAt,
+		auditColSubject,
+		auditColRole,
+		auditColMethod,
+		auditColPath,
+		auditColStatus,
+		auditColRequestID,
+	).From(auditTable).
+		OrderBy(auditColCreatedAt + " DESC")
+
+	query, args, err := WithCursor(cursor)(builder).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building audit query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.Time, &e.Subject, &e.Role, &e.Method, &e.Path, &e.Status, &e.RequestID); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Count returns the number of entries.
+func (s *AuditStore) Count(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", auditTable)).Scan(&count)
+	return count, err
+}
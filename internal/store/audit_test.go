@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("AuditStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given three recorded requests
+	// When we list the two most recent ones
+	// Then they should be returned newest first
+	It("should list the most recent entries first", func() {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+		Expect(s.Audit().Insert(ctx, models.AuditEntry{Time: at, Subject: "token", Role: models.RoleOperator, Method: "POST", Path: "/api/v1/collector", Status: 202, RequestID: "req-1"})).To(Succeed())
+		Expect(s.Audit().Insert(ctx, models.AuditEntry{Time: at.Add(time.Minute), Subject: "apikey:backup", Role: models.RoleOperator, Method: "DELETE", Path: "/api/v1/collector", Status: 204, RequestID: "req-2"})).To(Succeed())
+		Expect(s.Audit().Insert(ctx, models.AuditEntry{Time: at.Add(2 * time.Minute), Subject: "anonymous", Method: "POST", Path: "/admin/apikeys", Status: 201})).To(Succeed())
+
+		// Act
+		entries, err := s.Audit().List(ctx, models.Cursor{Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		total, err := s.Audit().Count(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(3))
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].Subject).To(Equal("anonymous"))
+		Expect(entries[0].Role).To(BeEmpty())
+		Expect(entries[0].Path).To(Equal("/admin/apikeys"))
+		Expect(entries[1].Subject).To(Equal("apikey:backup"))
+		Expect(entries[1].Role).To(Equal(models.RoleOperator))
+		Expect(entries[1].Method).To(Equal("DELETE"))
+		Expect(entries[1].Status).To(Equal(204))
+		Expect(entries[1].RequestID).To(Equal("req-2"))
+		Expect(entries[1].Time.Equal(at.Add(time.Minute))).To(BeTrue())
+	})
+
+	// Given an empty audit log
+	// When we list it
+	// Then an empty list should be returned
+	It("should return an empty list", func() {
+		// Act
+		entries, err := s.Audit().List(ctx, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+})
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for benchmarks table
+const (
+	benchmarksTable        = "benchmarks"
+	benchmarksColID        = "id"
+	benchmarksColCreatedAt = "created_at"
+	benchmarksColBenchmark = "benchmark"
+)
+
+type BenchmarkStore struct {
+	db QueryInterceptor
+}
+
+func NewBenchmarkStore(db QueryInterceptor) *BenchmarkStore {
+	return &BenchmarkStore{db: db}
+}
+
+// Create stores benchmark.
+func (s *BenchmarkStore) Create(ctx context.Context, benchmark models.Benchmark) error {
+	data, err := json.Marshal(benchmark)
+	if err != nil {
+		return fmt.Errorf("marshaling benchmark: %w", err)
+	}
+
+	query, args, err := sq.Insert(benchmarksTable).
+		Columns(benchmarksColID, benchmarksColCreatedAt, benchmarksColBenchmark).
+		Values(benchmark.ID, benchmark.CreatedAt.UTC(), string(data)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building benchmark insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting benchmark: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the newest benchmark, a ResourceNotFoundError when none ran.
+func (s *BenchmarkStore) Latest(ctx context.Context) (*models.Benchmark, error) {
+	query, args, err := sq.Select(benchmarksColBenchmark).
+		From(benchmarksTable).
+		OrderBy(benchmarksColCreatedAt + " DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building benchmark query: %w", err)
+	}
+
+	var data string
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, srvErrors.NewResourceNotFoundError("benchmark", "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var benchmark models.Benchmark
+	if err := json.Unmarshal([]byte(data), &benchmark); err != nil {
+		return nil, fmt.Errorf("unmarshaling benchmark: %w", err)
+	}
+	return &benchmark, nil
+}
@@ -0,0 +1,74 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("BenchmarkStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given no benchmark
+	// When we get the latest one
+	// Then it should fail with a not found error
+	It("should not find a benchmark before the first", func() {
+		// Act
+		_, err := s.Benchmark().Latest(ctx)
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+
+	// Given two benchmarks
+	// When we get the latest one
+	// Then the newest should be returned with its measures
+	It("should return the newest benchmark", func() {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+		newest := models.Benchmark{
+			ID:         "b-2",
+			CreatedAt:  at.Add(time.Minute),
+			Datastores: []models.ThroughputMeasure{{Name: "ds-1", Bytes: 1 << 30, Duration: 5 * time.Second, MBps: 214.7}},
+			Network:    &models.ThroughputMeasure{Name: "tcp://target:9000", Error: "connection refused"},
+		}
+		Expect(s.Benchmark().Create(ctx, newest)).To(Succeed())
+		Expect(s.Benchmark().Create(ctx, models.Benchmark{ID: "b-1", CreatedAt: at})).To(Succeed())
+
+		// Act
+		got, err := s.Benchmark().Latest(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*got).To(Equal(newest))
+	})
+})
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/kubev2v/migration-planner/pkg/duckdb_parser"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for cluster_rules table
+const (
+	clusterRulesTable        = "cluster_rules"
+	clusterRulesColClusterID = "cluster_id"
+	clusterRulesColCluster   = "cluster"
+	clusterRulesColName      = "name"
+	clusterRulesColType      = "type"
+	clusterRulesColEnabled   = "enabled"
+	clusterRulesColMandatory = "mandatory"
+	clusterRulesColVMs       = "vms"
+	clusterRulesColVMGroup   = "vm_group"
+	clusterRulesColHosts     = "hosts"
+	clusterRulesColHostGroup = "host_group"
+)
+
+type ClusterStore struct {
+	db     QueryInterceptor
+	parser *duckdb_parser.Parser
+}
+
+func NewClusterStore(db QueryInterceptor, parser *duckdb_parser.Parser) *ClusterStore {
+	return &ClusterStore{db: db, parser: parser}
+}
+
+// ReplaceRules replaces all the stored DRS rules with rules.
+func (s *ClusterStore) ReplaceRules(ctx context.Context, rules []models.ClusterRule) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", clusterRulesTable)); err != nil {
+		return fmt.Errorf("clearing cluster rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(clusterRulesTable).Columns(
+		clusterRulesColClusterID,
+		clusterRulesColCluster,
+		clusterRulesColName,
+		clusterRulesColType,
+		clusterRulesColEnabled,
+		clusterRulesColMandatory,
+		clusterRulesColVMs,
+		clusterRulesColVMGroup,
+		clusterRulesColHosts,
+		clusterRulesColHostGroup,
+	)
+
+	for _, r := range rules {
+		vms, err := json.Marshal(nonNil(r.VMs))
+		if err != nil {
+			return fmt.Errorf("marshaling vms of rule %s: %w", r.Name, err)
+		}
+		hosts, err := json.Marshal(nonNil(r.Hosts))
+		if err != nil {
+			return fmt.Errorf("marshaling hosts of rule %s: %w", r.Name, err)
+		}
+		builder = builder.Values(r.ClusterID, r.Cluster, r.Name, string(r.Type), r.Enabled, r.Mandatory, string(vms), r.VMGroup, string(hosts), r.HostGroup)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("building cluster rules insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting cluster rules: %w", err)
+	}
+
+	return nil
+}
+
+// ListRules returns the DRS rules of the clusters named cluster ordered by name,
+// those of same-named clusters of other datacenters included.
+// It returns ResourceNotFoundError when the cluster is not part of the inventory.
+func (s *ClusterStore) ListRules(ctx context.Context, cluster string) ([]models.ClusterRule, error) {
+	clusters, err := s.parser.Clusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(clusters, cluster) {
+		return nil, srvErrors.NewResourceNotFoundError("cluster", cluster)
+	}
+
+	query, args, err := sq.Select(
+		clusterRulesColClusterID,
+		clusterRulesColCluster,
+		clusterRulesColName,
+		clusterRulesColType,
+		clusterRulesColEnabled,
+		clusterRulesColMandatory,
+		clusterRulesColVMs,
+		clusterRulesColVMGroup,
+		clusterRulesColHosts,
+		clusterRulesColHostGroup,
+	).From(clusterRulesTable).
+		Where(sq.Eq{clusterRulesColCluster: cluster}).
+		OrderBy(clusterRulesColName, clusterRulesColClusterID).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query for cluster %s: %w", cluster, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.ClusterRule{}
+	for rows.Next() {
+		var r models.ClusterRule
+		var ruleType, vms, hosts string
+		if err := rows.Scan(&r.ClusterID, &r.Cluster, &r.Name, &ruleType, &r.Enabled, &r.Mandatory, &vms, &r.VMGroup, &hosts, &r.HostGroup); err != nil {
+			return nil, fmt.Errorf("scanning rule for cluster %s: %w", cluster, err)
+		}
+		r.Type = models.ClusterRuleType(ruleType)
+		if err := json.Unmarshal([]byte(vms), &r.VMs); err != nil {
+			return nil, fmt.Errorf("unmarshaling vms of rule %s: %w", r.Name, err)
+		}
+		if err := json.Unmarshal([]byte(hosts), &r.Hosts); err != nil {
+			return nil, fmt.Errorf("unmarshaling hosts of rule %s: %w", r.Name, err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
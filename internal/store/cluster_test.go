@@ -0,0 +1,128 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("ClusterStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		Expect(fixtures.Insert(ctx, db,
+			fixtures.NewVM("vm-1").WithCluster("cluster-a"),
+			fixtures.NewVM("vm-2").WithCluster("cluster-b"),
+		)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	Context("ReplaceRules", func() {
+		// Given rules for two clusters
+		// When we list the rules of one cluster
+		// Then only its rules should be returned, ordered by name
+		It("should store and list rules per cluster", func() {
+			// Arrange
+			rules := []models.ClusterRule{
+				{ClusterID: "domain-c1", Cluster: "cluster-a", Name: "z-rule", Type: models.ClusterRuleTypeVMAffinity, Enabled: true, VMs: []string{"vm-1"}},
+				{ClusterID: "domain-c1", Cluster: "cluster-a", Name: "a-rule", Type: models.ClusterRuleTypeVMHostAntiAffinity, Mandatory: true,
+					VMs: []string{"vm-1"}, VMGroup: "vms", Hosts: []string{"host-1", "host-2"}, HostGroup: "hosts"},
+				{ClusterID: "domain-c2", Cluster: "cluster-b", Name: "other", Type: models.ClusterRuleTypeVMAntiAffinity, VMs: []string{"vm-2"}},
+			}
+
+			// Act
+			err := s.Cluster().ReplaceRules(ctx, rules)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := s.Cluster().ListRules(ctx, "cluster-a")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(2))
+			Expect(result[0]).To(Equal(rules[1]))
+			Expect(result[1].Name).To(Equal("z-rule"))
+			Expect(result[1].Hosts).To(BeEmpty())
+		})
+
+		// Given same-named clusters of two datacenters carrying same-named rules
+		// When we store and list their rules
+		// Then both rules should be kept
+		It("should keep the rules of same-named clusters", func() {
+			// Arrange
+			rules := []models.ClusterRule{
+				{ClusterID: "domain-c1", Cluster: "cluster-a", Name: "keep-apart", Type: models.ClusterRuleTypeVMAntiAffinity, VMs: []string{"vm-1"}},
+				{ClusterID: "domain-c7", Cluster: "cluster-a", Name: "keep-apart", Type: models.ClusterRuleTypeVMAffinity, VMs: []string{"vm-3"}},
+			}
+
+			// Act
+			err := s.Cluster().ReplaceRules(ctx, rules)
+			Expect(err).NotTo(HaveOccurred())
+			result, err := s.Cluster().ListRules(ctx, "cluster-a")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(2))
+			Expect(result[0].ClusterID).To(Equal("domain-c1"))
+			Expect(result[0].Type).To(Equal(models.ClusterRuleTypeVMAntiAffinity))
+			Expect(result[1].ClusterID).To(Equal("domain-c7"))
+			Expect(result[1].Type).To(Equal(models.ClusterRuleTypeVMAffinity))
+		})
+
+		// Given previously stored rules
+		// When we replace them with a new set
+		// Then the old rules should be gone
+		It("should replace existing rules", func() {
+			// Arrange
+			Expect(s.Cluster().ReplaceRules(ctx, []models.ClusterRule{
+				{ClusterID: "domain-c1", Cluster: "cluster-a", Name: "old", Type: models.ClusterRuleTypeVMAffinity},
+			})).To(Succeed())
+
+			// Act
+			Expect(s.Cluster().ReplaceRules(ctx, nil)).To(Succeed())
+			result, err := s.Cluster().ListRules(ctx, "cluster-a")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeEmpty())
+		})
+	})
+
+	Context("ListRules", func() {
+		// Given a cluster that is not in the inventory
+		// When we list its rules
+		// Then a ResourceNotFoundError should be returned
+		It("should return ResourceNotFoundError for unknown cluster", func() {
+			// Act
+			_, err := s.Cluster().ListRules(ctx, "missing")
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubev2v/migration-planner/pkg/duckdb_parser"
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	"github.com/kubev2v/migration-planner/pkg/inventory/converters"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// ReevaluateConcerns replaces the concerns of the stored VMs with the ones the
+// validator finds now, followed by the concerns the agent adds itself from the
+// events and the disk chains. A VM the validator fails on is left without
+// policy concerns, like at ingestion.
+func (s *Store) ReevaluateConcerns(ctx context.Context) error {
+	vms, err := s.parser.VMs(ctx, duckdb_parser.Filters{}, duckdb_parser.Options{})
+	if err != nil {
+		return fmt.Errorf("getting VMs for validation: %w", err)
+	}
+
+	builder := duckdb_parser.NewConcernValuesBuilder()
+	if s.validator != nil {
+		for _, vm := range vms {
+			concerns, err := s.validator.Validate(ctx, vm)
+			if err != nil {
+				continue
+			}
+			builder.Append(vm.ID, concerns...)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM concerns"); err != nil {
+		return fmt.Errorf("deleting concerns: %w", err)
+	}
+	if err := duckdb_parser.InsertConcerns(ctx, s.db, builder); err != nil {
+		return fmt.Errorf("inserting concerns: %w", err)
+	}
+	if err := s.event.AddConcerns(ctx); err != nil {
+		return err
+	}
+	return s.diskChain.AddConcerns(ctx)
+}
+
+// PolicyInput returns the VM of the inventory as the policies receive it.
+func (s *Store) PolicyInput(ctx context.Context, vmID string) (*parsermodels.VM, error) {
+	vms, err := s.parser.VMs(ctx, duckdb_parser.Filters{VmId: vmID}, duckdb_parser.Options{Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("getting VM %s: %w", vmID, err)
+	}
+	if len(vms) == 0 {
+		return nil, srvErrors.NewResourceNotFoundError("vm", vmID)
+	}
+	return &vms[0], nil
+}
+
+// BuildInventory returns the inventory document of the parsed VMware data, as
+// stored by InventoryStore.Save.
+func (s *Store) BuildInventory(ctx context.Context) ([]byte, error) {
+	inv, err := s.parser.BuildInventory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error building inventory: %w", err)
+	}
+
+	data, err := json.Marshal(converters.ToAPI(inv))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the inventory: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for concern_metadata table
+const (
+	concernMetadataTable          = "concern_metadata"
+	concernMetadataColID          = "concern_id"
+	concernMetadataColTitle       = "title"
+	concernMetadataColSeverity    = "severity"
+	concernMetadataColCategory    = "category"
+	concernMetadataColRemediation = "remediation"
+	concernMetadataColDocsURL     = "docs_url"
+)
+
+// concernSeverity is the severity of the concern c, the one of its metadata m,
+// else its category in lowercase.
+const concernSeverity = `COALESCE(NULLIF(m.severity, ''), LOWER(c."Category"))`
+
+type ConcernMetadataStore struct {
+	db QueryInterceptor
+}
+
+func NewConcernMetadataStore(db QueryInterceptor) *ConcernMetadataStore {
+	return &ConcernMetadataStore{db: db}
+}
+
+// Replace replaces the metadata of the concerns with metadata.
+func (s *ConcernMetadataStore) Replace(ctx context.Context, metadata []models.ConcernMetadata) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM "+concernMetadataTable); err != nil {
+		return fmt.Errorf("deleting concern metadata: %w", err)
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(concernMetadataTable).
+		Columns(concernMetadataColID, concernMetadataColTitle, concernMetadataColSeverity,
+			concernMetadataColCategory, concernMetadataColRemediation, concernMetadataColDocsURL)
+	for _, m := range metadata {
+		builder = builder.Values(m.ID, m.Title, m.Severity, m.Category, m.Remediation, m.DocsURL)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("building concern metadata insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting concern metadata: %w", err)
+	}
+	return nil
+}
+
+// Get returns the concern id of the inventory with its metadata. A concern
+// flagging no VM is returned when it has metadata, with a VMCount of 0.
+func (s *ConcernMetadataStore) Get(ctx context.Context, id string) (*models.ConcernDetail, error) {
+	query, args, err := sq.Select(
+		`COALESCE(MAX(c."Label"), '')`,
+		`COALESCE(MAX(c."Category"), '')`,
+		`COUNT(DISTINCT c."VM_ID")`,
+	).From("concerns c").
+		Where(sq.Eq{`c."Concern_ID"`: id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building concern query: %w", err)
+	}
+
+	detail := models.ConcernDetail{ConcernMetadata: models.ConcernMetadata{ID: id}}
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&detail.Label, &detail.Category, &detail.VMCount); err != nil {
+		return nil, fmt.Errorf("getting concern %s: %w", id, err)
+	}
+
+	query, args, err = sq.Select(
+		concernMetadataColTitle,
+		concernMetadataColSeverity,
+		concernMetadataColCategory,
+		concernMetadataColRemediation,
+		concernMetadataColDocsURL,
+	).From(concernMetadataTable).
+		Where(sq.Eq{concernMetadataColID: id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building concern metadata query: %w", err)
+	}
+
+	var category string
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&detail.Title, &detail.Severity, &category, &detail.Remediation, &detail.DocsURL)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if detail.VMCount == 0 {
+			return nil, srvErrors.NewResourceNotFoundError("concern", id)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("getting concern metadata %s: %w", id, err)
+	case category != "":
+		detail.Category = category
+	}
+
+	if detail.Severity == "" {
+		detail.Severity = models.SeverityOf(detail.Category)
+	}
+	return &detail, nil
+}
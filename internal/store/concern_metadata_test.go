@@ -0,0 +1,129 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("ConcernMetadataStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		Expect(fixtures.Insert(ctx, db,
+			fixtures.NewVM("vm-1").WithConcernOf(fixtures.Concern{ID: "cpu.hotadd", Label: "CPU hot add", Category: "Warning", Assessment: "..."}),
+			fixtures.NewVM("vm-2").
+				WithConcernOf(fixtures.Concern{ID: "cpu.hotadd", Label: "CPU hot add", Category: "Warning", Assessment: "..."}).
+				WithConcernOf(fixtures.Concern{ID: "disk.rdm", Label: "RDM disk", Category: "Critical", Assessment: "..."}),
+			fixtures.NewVM("vm-3"),
+		)).To(Succeed())
+
+		Expect(s.ConcernMetadata().Replace(ctx, []models.ConcernMetadata{
+			{ID: "cpu.hotadd", Title: "CPU hot add", Severity: "critical", Remediation: "Disable CPU hot add"},
+			{ID: "unraised.rule", Title: "Unraised", Severity: "information"},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	Context("Get", func() {
+		// Given a concern with metadata flagging two VMs
+		// When we get it
+		// Then its metadata and VM count should be returned
+		It("should return the concern with its metadata", func() {
+			// Act
+			concern, err := s.ConcernMetadata().Get(ctx, "cpu.hotadd")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concern.Title).To(Equal("CPU hot add"))
+			Expect(concern.Label).To(Equal("CPU hot add"))
+			Expect(concern.Severity).To(Equal("critical"))
+			Expect(concern.Category).To(Equal("Warning"))
+			Expect(concern.Remediation).To(Equal("Disable CPU hot add"))
+			Expect(concern.VMCount).To(Equal(2))
+		})
+
+		// Given a concern without metadata
+		// When we get it
+		// Then its severity should be its category in lowercase
+		It("should fall back to the category without metadata", func() {
+			// Act
+			concern, err := s.ConcernMetadata().Get(ctx, "disk.rdm")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concern.Severity).To(Equal("critical"))
+			Expect(concern.VMCount).To(Equal(1))
+		})
+
+		// Given a concern with metadata flagging no VM
+		// When we get it
+		// Then it should be returned with no VM
+		It("should return a concern with metadata flagging no VM", func() {
+			// Act
+			concern, err := s.ConcernMetadata().Get(ctx, "unraised.rule")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concern.Severity).To(Equal("information"))
+			Expect(concern.VMCount).To(BeZero())
+		})
+
+		// Given a concern neither raised nor annotated
+		// When we get it
+		// Then a not found error should be returned
+		It("should return not found for an unknown concern", func() {
+			// Act
+			_, err := s.ConcernMetadata().Get(ctx, "unknown.rule")
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("ByConcernSeverity", func() {
+		// Given concerns whose severity comes from their metadata or category
+		// When we filter the VMs by severity
+		// Then the VMs with a concern of the severities should be returned
+		It("should filter the VMs by concern severity", func() {
+			// Act
+			critical, err := s.VM().List(ctx, store.ByConcernSeverity("critical"), store.WithDefaultSort())
+			Expect(err).NotTo(HaveOccurred())
+			warning, err := s.VM().Count(ctx, store.ByConcernSeverity("warning"))
+			Expect(err).NotTo(HaveOccurred())
+			either, err := s.VM().Count(ctx, store.ByConcernSeverity("information", "critical"))
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(critical).To(HaveLen(2))
+			Expect(warning).To(BeZero())
+			Expect(either).To(Equal(2))
+		})
+	})
+})
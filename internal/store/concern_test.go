@@ -0,0 +1,132 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("Concerns", func() {
+	var (
+		ctx       context.Context
+		s         *store.Store
+		db        *sql.DB
+		validator *test.MockValidator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		validator = test.NewMockValidator()
+		s = store.NewStore(db, validator)
+
+		Expect(fixtures.Insert(ctx, db, fixtures.NewVM("vm-1"), fixtures.NewVM("vm-2"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	concernIDs := func(vmID string) []string {
+		rows, err := db.QueryContext(ctx, `SELECT "Concern_ID" FROM concerns WHERE "VM_ID" = ? ORDER BY "Concern_ID"`, vmID)
+		Expect(err).NotTo(HaveOccurred())
+		defer rows.Close()
+
+		ids := []string{}
+		for rows.Next() {
+			var id string
+			Expect(rows.Scan(&id)).To(Succeed())
+			ids = append(ids, id)
+		}
+		Expect(rows.Err()).NotTo(HaveOccurred())
+		return ids
+	}
+
+	Context("ReevaluateConcerns", func() {
+		// Given VMs having concerns of previous policies
+		// When the concerns are re-evaluated with updated policies
+		// Then the VMs should only have the concerns of the updated policies
+		It("should replace the concerns with the ones of the validator", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO concerns ("VM_ID", "Concern_ID", "Label", "Category", "Assessment") VALUES ('vm-1', 'old.rule', 'Old', 'Warning', 'Old rule')`)
+			Expect(err).NotTo(HaveOccurred())
+			validator.Concerns = []parsermodels.Concern{{Id: "new.rule", Label: "New", Category: "Critical", Assessment: "New rule"}}
+
+			// Act
+			err = s.ReevaluateConcerns(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernIDs("vm-1")).To(Equal([]string{"new.rule"}))
+			Expect(concernIDs("vm-2")).To(Equal([]string{"new.rule"}))
+		})
+
+		// Given a VM with a deep disk chain
+		// When the concerns are re-evaluated
+		// Then the disk chain concern added by the agent should be kept
+		It("should add the concerns of the agent again", func() {
+			// Arrange
+			Expect(s.DiskChain().Replace(ctx, []models.DiskChain{
+				{VMID: "vm-1", DiskKey: 2000, File: "[ds1] vm-1/vm-1-000004.vmdk", BaseFile: "[ds1] vm-1/vm-1.vmdk", Depth: store.DeepDiskChainDepth},
+			})).To(Succeed())
+
+			// Act
+			err := s.ReevaluateConcerns(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernIDs("vm-1")).To(Equal([]string{store.ConcernDeepDiskChain}))
+			Expect(concernIDs("vm-2")).To(BeEmpty())
+		})
+
+		// Given a validator failing on the VMs
+		// When the concerns are re-evaluated
+		// Then the VMs should be left without policy concerns
+		It("should skip the VMs the validator fails on", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO concerns ("VM_ID", "Concern_ID", "Label", "Category", "Assessment") VALUES ('vm-1', 'old.rule', 'Old', 'Warning', 'Old rule')`)
+			Expect(err).NotTo(HaveOccurred())
+			validator.Err = errors.New("evaluation failed")
+
+			// Act
+			err = s.ReevaluateConcerns(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernIDs("vm-1")).To(BeEmpty())
+		})
+	})
+
+	Context("BuildInventory", func() {
+		// Given parsed VMs
+		// When we build the inventory
+		// Then it should be an inventory document the store accepts
+		It("should build an inventory document", func() {
+			// Act
+			data, err := s.BuildInventory(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Inventory().Save(ctx, data)).To(Succeed())
+			count, err := models.NewInventory(data).VMCount()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+	})
+})
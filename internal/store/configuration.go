@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
 
@@ -11,6 +13,22 @@ import (
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 )
 
+// ConfigurationVersion is the version of the configuration document written
+// by Save. Bump it with an entry in configurationUpgrades when the document
+// changes.
+const ConfigurationVersion = 2
+
+// configurationUpgrades turn the configuration document of a version into the
+// next one. Version 1 is the agent_mode column of the agents predating the
+// document, read as {"agent_mode": ...}.
+var configurationUpgrades = map[int]func(doc map[string]any) error{
+	1: func(doc map[string]any) error {
+		doc["agentMode"] = doc["agent_mode"]
+		delete(doc, "agent_mode")
+		return nil
+	},
+}
+
 type ConfigurationStore struct {
 	db QueryInterceptor
 }
@@ -20,37 +38,105 @@ func NewConfigurationStore(db QueryInterceptor) *ConfigurationStore {
 }
 
 func (s *ConfigurationStore) Get(ctx context.Context) (*models.Configuration, error) {
-	query, args, err := sq.Select("agent_mode").
+	cfg, _, err := s.get(ctx)
+	return cfg, err
+}
+
+func (s *ConfigurationStore) Save(ctx context.Context, cfg *models.Configuration) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	query, args, err := sq.Insert("configuration").
+		Columns("id", "agent_mode", "version", "data").
+		Values(1, string(cfg.AgentMode), ConfigurationVersion, string(data)).
+		Suffix("ON CONFLICT (id) DO UPDATE SET agent_mode = EXCLUDED.agent_mode, version = EXCLUDED.version, data = EXCLUDED.data").
+		ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Upgrade rewrites a configuration saved by an older agent at
+// ConfigurationVersion. It does nothing when no configuration is saved.
+func (s *ConfigurationStore) Upgrade(ctx context.Context) error {
+	cfg, version, err := s.get(ctx)
+	if srvErrors.IsResourceNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if version == ConfigurationVersion {
+		return nil
+	}
+	return s.Save(ctx, cfg)
+}
+
+// get reads the configuration, upgraded to ConfigurationVersion, and the
+// version it was saved with.
+func (s *ConfigurationStore) get(ctx context.Context) (*models.Configuration, int, error) {
+	query, args, err := sq.Select("version", "agent_mode", "data").
 		From("configuration").
 		Where(sq.Eq{"id": 1}).
 		ToSql()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	row := s.db.QueryRowContext(ctx, query, args...)
-	var agentMode string
-	err = row.Scan(&agentMode)
+	var (
+		version   int
+		agentMode sql.NullString
+		data      sql.NullString
+	)
+	err = row.Scan(&version, &agentMode, &data)
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, srvErrors.NewConfigurationNotFoundError()
+		return nil, 0, srvErrors.NewConfigurationNotFoundError()
 	}
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return &models.Configuration{
-		AgentMode: models.AgentMode(agentMode),
-	}, nil
-}
 
-func (s *ConfigurationStore) Save(ctx context.Context, cfg *models.Configuration) error {
-	query, args, err := sq.Insert("configuration").
-		Columns("id", "agent_mode").
-		Values(1, string(cfg.AgentMode)).
-		Suffix("ON CONFLICT (id) DO UPDATE SET agent_mode = EXCLUDED.agent_mode").
-		ToSql()
+	doc := map[string]any{"agent_mode": agentMode.String}
+	if version > 1 {
+		doc = make(map[string]any)
+		if err := json.Unmarshal([]byte(data.String), &doc); err != nil {
+			return nil, 0, fmt.Errorf("invalid configuration version %d: %w", version, err)
+		}
+	}
+	if err := upgradeConfiguration(doc, version); err != nil {
+		return nil, 0, err
+	}
+
+	upgraded, err := json.Marshal(doc)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
-	_, err = s.db.ExecContext(ctx, query, args...)
-	return err
+	var cfg models.Configuration
+	if err := json.Unmarshal(upgraded, &cfg); err != nil {
+		return nil, 0, fmt.Errorf("invalid configuration version %d: %w", version, err)
+	}
+	return &cfg, version, nil
+}
+
+// upgradeConfiguration applies the upgrades from version to
+// ConfigurationVersion to doc.
+func upgradeConfiguration(doc map[string]any, version int) error {
+	if version > ConfigurationVersion {
+		return fmt.Errorf("configuration version %d is newer than the supported version %d", version, ConfigurationVersion)
+	}
+	for v := version; v < ConfigurationVersion; v++ {
+		upgrade, ok := configurationUpgrades[v]
+		if !ok {
+			return fmt.Errorf("no upgrade from configuration version %d", v)
+		}
+		if err := upgrade(doc); err != nil {
+			return fmt.Errorf("upgrading configuration version %d: %w", v, err)
+		}
+	}
+	return nil
 }
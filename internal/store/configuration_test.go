@@ -11,9 +11,9 @@ import (
 
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
-	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 var _ = Describe("ConfigurationStore", func() {
@@ -27,10 +27,7 @@ var _ = Describe("ConfigurationStore", func() {
 		ctx = context.Background()
 
 		var err error
-		db, err = store.NewDB(":memory:")
-		Expect(err).NotTo(HaveOccurred())
-
-		err = migrations.Run(ctx, db)
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		s = store.NewStore(db, test.NewMockValidator())
@@ -132,6 +129,78 @@ var _ = Describe("ConfigurationStore", func() {
 		})
 	})
 
+	Context("Upgrade", func() {
+		// Given the configuration row of an agent predating the versioned document
+		// When we get the configuration
+		// Then the agent mode should be read from the legacy column
+		It("should read the configuration of an older agent", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO configuration (id, agent_mode) VALUES (1, 'connected')`)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			retrieved, err := s.Configuration().Get(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.AgentMode).To(Equal(models.AgentModeConnected))
+		})
+
+		// Given the configuration row of an agent predating the versioned document
+		// When we upgrade the configuration
+		// Then it should be rewritten as a document of the current version
+		It("should rewrite the configuration of an older agent", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO configuration (id, agent_mode) VALUES (1, 'connected')`)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			err = s.Configuration().Upgrade(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			var (
+				version int
+				data    string
+			)
+			Expect(db.QueryRowContext(ctx, `SELECT version, data FROM configuration`).Scan(&version, &data)).To(Succeed())
+			Expect(version).To(Equal(store.ConfigurationVersion))
+			Expect(data).To(MatchJSON(`{"agentMode":"connected"}`))
+
+			retrieved, err := s.Configuration().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.AgentMode).To(Equal(models.AgentModeConnected))
+		})
+
+		// Given no saved configuration
+		// When we upgrade the configuration
+		// Then it should succeed without saving one
+		It("should do nothing without configuration", func() {
+			// Act
+			err := s.Configuration().Upgrade(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			_, err = s.Configuration().Get(ctx)
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+
+		// Given a configuration saved by a newer agent
+		// When we get the configuration
+		// Then it should fail instead of misreading it
+		It("should reject a configuration of a newer version", func() {
+			// Arrange
+			_, err := db.ExecContext(ctx, `INSERT INTO configuration (id, agent_mode, version, data) VALUES (1, 'connected', ?, '{}')`, store.ConfigurationVersion+1)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			_, err = s.Configuration().Get(ctx)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("is newer than the supported version")))
+		})
+	})
+
 	Context("Concurrent writes", func() {
 		// Given multiple goroutines writing to the same configuration
 		// When all goroutines attempt to save configuration simultaneously
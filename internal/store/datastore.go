@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for datastore_stats table
+const (
+	datastoreStatsTable           = "datastore_stats"
+	datastoreStatsColID           = "datastore_id"
+	datastoreStatsColName         = "name"
+	datastoreStatsColReadKBps     = "read_kbps"
+	datastoreStatsColWriteKBps    = "write_kbps"
+	datastoreStatsColReadLatency  = "read_latency_ms"
+	datastoreStatsColWriteLatency = "write_latency_ms"
+	datastoreStatsColSampledAt    = "sampled_at"
+)
+
+type DatastoreStore struct {
+	db QueryInterceptor
+}
+
+func NewDatastoreStore(db QueryInterceptor) *DatastoreStore {
+	return &DatastoreStore{db: db}
+}
+
+// ReplaceStats replaces all the stored datastore statistics with stats.
+func (s *DatastoreStore) ReplaceStats(ctx context.Context, stats []models.DatastoreStats) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", datastoreStatsTable)); err != nil {
+		return fmt.Errorf("clearing datastore stats: %w", err)
+	}
+
+	if len(stats) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(datastoreStatsTable).Columns(
+		datastoreStatsColID,
+		datastoreStatsColName,
+		datastoreStatsColReadKBps,
+		datastoreStatsColWriteKBps,
+		datastoreStatsColReadLatency,
+		datastoreStatsColWriteLatency,
+		datastoreStatsColSampledAt,
+	)
+	for _, st := range stats {
+		builder = builder.Values(st.ID, st.Name, st.ReadKBps, st.WriteKBps, st.ReadLatencyMs, st.WriteLatencyMs, st.SampledAt.UTC())
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("building datastore stats insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting datastore stats: %w", err)
+	}
+
+	return nil
+}
+
+// ListStats returns the statistics of every datastore ordered by name.
+func (s *DatastoreStore) ListStats(ctx context.Context) ([]models.DatastoreStats, error) {
+	query, args, err := sq.Select(
+		datastoreStatsColID,
+		datastoreStatsColName,
+		datastoreStatsColReadKBps,
+		datastoreStatsColWriteKBps,
+		datastoreStatsColReadLatency,
+		datastoreStatsColWriteLatency,
+		datastoreStatsColSampledAt,
+	).From(datastoreStatsTable).
+		OrderBy(datastoreStatsColName).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building datastore stats query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.DatastoreStats{}
+	for rows.Next() {
+		var st models.DatastoreStats
+		if err := rows.Scan(&st.ID, &st.Name, &st.ReadKBps, &st.WriteKBps, &st.ReadLatencyMs, &st.WriteLatencyMs, &st.SampledAt); err != nil {
+			return nil, fmt.Errorf("scanning datastore stats: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
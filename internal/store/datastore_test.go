@@ -0,0 +1,82 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("DatastoreStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	Context("ReplaceStats", func() {
+		// Given statistics for two datastores
+		// When we list them
+		// Then they should be returned ordered by name
+		It("should store and list stats", func() {
+			// Arrange
+			sampledAt := time.Now().UTC().Truncate(time.Second)
+			stats := []models.DatastoreStats{
+				{ID: "datastore-2", Name: "nfs-01", ReadKBps: 2048, WriteKBps: 512, ReadLatencyMs: 4, WriteLatencyMs: 9, SampledAt: sampledAt},
+				{ID: "datastore-1", Name: "iscsi-01", ReadKBps: 1024, WriteKBps: 256, ReadLatencyMs: 2, WriteLatencyMs: 3, SampledAt: sampledAt},
+			}
+
+			// Act
+			Expect(s.Datastore().ReplaceStats(ctx, stats)).To(Succeed())
+			result, err := s.Datastore().ListStats(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(2))
+			Expect(result[0].Name).To(Equal("iscsi-01"))
+			Expect(result[1].ID).To(Equal("datastore-2"))
+			Expect(result[1].ReadKBps).To(Equal(int64(2048)))
+			Expect(result[1].WriteLatencyMs).To(Equal(int64(9)))
+			Expect(result[1].SampledAt.Equal(sampledAt)).To(BeTrue())
+		})
+
+		// Given previously stored stats
+		// When we replace them with an empty set
+		// Then no stats should remain
+		It("should replace existing stats", func() {
+			// Arrange
+			Expect(s.Datastore().ReplaceStats(ctx, []models.DatastoreStats{{ID: "datastore-1", Name: "ds", SampledAt: time.Now()}})).To(Succeed())
+
+			// Act
+			Expect(s.Datastore().ReplaceStats(ctx, nil)).To(Succeed())
+			result, err := s.Datastore().ListStats(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for vm_disk_chains table
+const (
+	diskChainsTable          = "vm_disk_chains"
+	diskChainsColVmID        = `"VM ID"`
+	diskChainsColDiskKey     = "disk_key"
+	diskChainsColFile        = "file"
+	diskChainsColBaseFile    = "base_file"
+	diskChainsColDepth       = "depth"
+	diskChainsColLinkedClone = "linked_clone"
+)
+
+const (
+	// ConcernDeepDiskChain flags VMs with at least one deep delta-disk chain.
+	ConcernDeepDiskChain = "vmware.disk.deep_chain"
+	// ConcernLinkedClone flags VMs whose disks are rooted on another VM's base disk.
+	ConcernLinkedClone = "vmware.vm.linked_clone"
+	// DeepDiskChainDepth is the chain depth (base disk included) from which a disk chain is considered deep.
+	DeepDiskChainDepth = 4
+)
+
+type DiskChainStore struct {
+	db QueryInterceptor
+}
+
+func NewDiskChainStore(db QueryInterceptor) *DiskChainStore {
+	return &DiskChainStore{db: db}
+}
+
+// Replace replaces all the stored disk chains with chains.
+func (s *DiskChainStore) Replace(ctx context.Context, chains []models.DiskChain) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", diskChainsTable)); err != nil {
+		return fmt.Errorf("clearing disk chains: %w", err)
+	}
+
+	if len(chains) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(diskChainsTable).Columns(
+		diskChainsColVmID,
+		diskChainsColDiskKey,
+		diskChainsColFile,
+		diskChainsColBaseFile,
+		diskChainsColDepth,
+		diskChainsColLinkedClone,
+	)
+	for _, c := range chains {
+		builder = builder.Values(c.VMID, c.DiskKey, c.File, c.BaseFile, c.Depth, c.LinkedClone)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("building disk chains insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting disk chains: %w", err)
+	}
+
+	return nil
+}
+
+// ListByVM returns the disk chains of the VM ordered by disk key.
+func (s *DiskChainStore) ListByVM(ctx context.Context, vmID string) ([]models.DiskChain, error) {
+	query, args, err := sq.Select(
+		diskChainsColVmID,
+		diskChainsColDiskKey,
+		diskChainsColFile,
+		diskChainsColBaseFile,
+		diskChainsColDepth,
+		diskChainsColLinkedClone,
+	).From(diskChainsTable).
+		Where(sq.Eq{diskChainsColVmID: vmID}).
+		OrderBy(diskChainsColDiskKey).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building disk chains query for vm %s: %w", vmID, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chains []models.DiskChain
+	for rows.Next() {
+		var c models.DiskChain
+		if err := rows.Scan(&c.VMID, &c.DiskKey, &c.File, &c.BaseFile, &c.Depth, &c.LinkedClone); err != nil {
+			return nil, fmt.Errorf("scanning disk chain for vm %s: %w", vmID, err)
+		}
+		chains = append(chains, c)
+	}
+
+	return chains, rows.Err()
+}
+
+// AddConcerns adds one concern per VM having a disk chain at least DeepDiskChainDepth deep
+// and one per VM that is a linked clone.
+func (s *DiskChainStore) AddConcerns(ctx context.Context) error {
+	concerns := []struct {
+		id         string
+		label      string
+		category   string
+		assessment string
+		where      sq.Sqlizer
+	}{
+		{
+			id:         ConcernDeepDiskChain,
+			label:      "Deep disk chain",
+			category:   "Warning",
+			assessment: fmt.Sprintf("The VM has a disk backed by a chain of %d or more delta disks. Consolidate the snapshots before migration, deep chains slow down conversion and copy significantly.", DeepDiskChainDepth-1),
+			where:      sq.GtOrEq{diskChainsColDepth: DeepDiskChainDepth},
+		},
+		{
+			id:         ConcernLinkedClone,
+			label:      "Linked clone",
+			category:   "Warning",
+			assessment: "The VM is a linked clone sharing its base disk with other VMs. The whole chain is copied for every clone, which increases the transferred data.",
+			where:      sq.Eq{diskChainsColLinkedClone: true},
+		},
+	}
+
+	for _, c := range concerns {
+		query, args, err := sq.Insert("concerns").
+			Columns(`"VM_ID"`, `"Concern_ID"`, `"Label"`, `"Category"`, `"Assessment"`).
+			Select(sq.Select(diskChainsColVmID).
+				Distinct().
+				Column("?", c.id).
+				Column("?", c.label).
+				Column("?", c.category).
+				Column("?", c.assessment).
+				From(diskChainsTable).
+				Where(c.where).
+				// chains may have been collected for VMs created after the inventory snapshot
+				Where(fmt.Sprintf(`%s IN (SELECT "VM ID" FROM vinfo)`, diskChainsColVmID))).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("building %s concerns: %w", c.id, err)
+		}
+
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("inserting %s concerns: %w", c.id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("DiskChainStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		Expect(fixtures.Insert(ctx, db, fixtures.NewVM("vm-1"), fixtures.NewVM("vm-2"), fixtures.NewVM("vm-3"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	concernCount := func(vmID, concernID string) int {
+		var count int
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM concerns WHERE "VM_ID" = ? AND "Concern_ID" = ?`, vmID, concernID).Scan(&count)
+		Expect(err).NotTo(HaveOccurred())
+		return count
+	}
+
+	Context("Replace and ListByVM", func() {
+		// Given chains for two VMs
+		// When we list the chains of one VM
+		// Then only its chains should be returned, ordered by disk key
+		It("should store and list chains per VM", func() {
+			// Arrange
+			chains := []models.DiskChain{
+				{VMID: "vm-1", DiskKey: 2001, File: "[ds1] vm-1/vm-1_1-000002.vmdk", BaseFile: "[ds1] vm-1/vm-1_1.vmdk", Depth: 3},
+				{VMID: "vm-1", DiskKey: 2000, File: "[ds1] vm-1/vm-1.vmdk", BaseFile: "[ds1] vm-1/vm-1.vmdk", Depth: 1},
+				{VMID: "vm-2", DiskKey: 2000, File: "[ds1] vm-2/vm-2-000001.vmdk", BaseFile: "[ds1] base/base.vmdk", Depth: 2, LinkedClone: true},
+			}
+
+			// Act
+			Expect(s.DiskChain().Replace(ctx, chains)).To(Succeed())
+			result, err := s.DiskChain().ListByVM(ctx, "vm-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(2))
+			Expect(result[0]).To(Equal(chains[1]))
+			Expect(result[1]).To(Equal(chains[0]))
+		})
+
+		// Given previously stored chains
+		// When we replace them with an empty set
+		// Then no chains should remain
+		It("should replace existing chains", func() {
+			// Arrange
+			Expect(s.DiskChain().Replace(ctx, []models.DiskChain{
+				{VMID: "vm-1", DiskKey: 2000, File: "[ds1] vm-1/vm-1.vmdk", Depth: 1},
+			})).To(Succeed())
+
+			// Act
+			Expect(s.DiskChain().Replace(ctx, nil)).To(Succeed())
+			result, err := s.DiskChain().ListByVM(ctx, "vm-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeEmpty())
+		})
+	})
+
+	Context("AddConcerns", func() {
+		// Given a VM with a deep chain on two disks, a linked clone and a VM with a shallow chain
+		// When we add the disk chain concerns
+		// Then each flagged VM should get a single concern of the matching kind
+		It("should flag deep chains and linked clones", func() {
+			// Arrange
+			Expect(s.DiskChain().Replace(ctx, []models.DiskChain{
+				{VMID: "vm-1", DiskKey: 2000, Depth: store.DeepDiskChainDepth},
+				{VMID: "vm-1", DiskKey: 2001, Depth: store.DeepDiskChainDepth + 2},
+				{VMID: "vm-2", DiskKey: 2000, Depth: 2, LinkedClone: true},
+				{VMID: "vm-3", DiskKey: 2000, Depth: store.DeepDiskChainDepth - 1},
+			})).To(Succeed())
+
+			// Act
+			err := s.DiskChain().AddConcerns(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernCount("vm-1", store.ConcernDeepDiskChain)).To(Equal(1))
+			Expect(concernCount("vm-1", store.ConcernLinkedClone)).To(Equal(0))
+			Expect(concernCount("vm-2", store.ConcernDeepDiskChain)).To(Equal(0))
+			Expect(concernCount("vm-2", store.ConcernLinkedClone)).To(Equal(1))
+			Expect(concernCount("vm-3", store.ConcernDeepDiskChain)).To(Equal(0))
+		})
+	})
+})
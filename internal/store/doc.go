@@ -28,6 +28,23 @@
 //	├────────────────────┼─────────────────────────────────────────────┤
 //	│  configuration     │  Agent runtime config (agent_mode)          │
 //	│  inventory         │  Raw inventory JSON blob with timestamps    │
+//	│  vm_security       │  Encryption, vTPM and secure boot per VM    │
+//	│  cluster_rules     │  DRS affinity/anti-affinity rules           │
+//	│  vcenter_events    │  Recent vCenter events and triggered alarms │
+//	│  datastore_stats   │  Datastore throughput and latency           │
+//	│  vm_disk_chains    │  Delta-disk chain depth and linked clones   │
+//	│  api_keys          │  Hashed API keys of local integrations      │
+//	│  audit_log         │  Mutating API requests and who sent them    │
+//	│  login_failures    │  Failed local API logins per client IP      │
+//	│  secrets           │  AES-256-GCM encrypted agent secrets        │
+//	│  agent_events      │  Lifecycle events of the agent              │
+//	│  concern_metadata  │  Metadata of the policy concerns            │
+//	│  policy_decisions  │  Decision log of the policy evaluations     │
+//	│  registration      │  Agent and source ids given by the console  │
+//	│  sources           │  Sources added to the agent through the API │
+//	│  jobs              │  Collections and inspections with progress  │
+//	│  plans             │  Generated migration plans as JSON          │
+//	│  benchmarks        │  Measured datastore and network throughput  │
 //	│  schema_migrations │  Migration version tracking                 │
 //	└────────────────────┴─────────────────────────────────────────────┘
 //
@@ -56,24 +73,43 @@
 //
 //	Store.Migrate(ctx)
 //	    ├── parser.Init()     → Creates vinfo, vdisk, concerns, etc.
-//	    └── migrations.Run()  → Creates configuration, inventory
+//	    ├── migrations.Run()  → Creates configuration, inventory
+//	    └── Configuration().Upgrade() → Rewrites an older configuration
 //
 // # Store Components
 //
 // # ConfigurationStore
 //
-// Persists agent runtime configuration in a single-row table.
+// Persists agent runtime configuration in a single-row table, as a JSON
+// document of models.Configuration tagged with its version.
 //
 // Schema:
 //
 //	configuration (
 //	    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
-//	    agent_mode VARCHAR DEFAULT 'disconnected'
+//	    agent_mode VARCHAR DEFAULT 'disconnected',  -- kept for older agents
+//	    version INTEGER DEFAULT 1,
+//	    data VARCHAR                                -- JSON document
 //	)
 //
 // Methods:
-//   - Get(ctx) → *models.Configuration
-//   - Save(ctx, cfg) → error (uses UPSERT)
+//   - Get(ctx) → *models.Configuration, upgraded to ConfigurationVersion
+//   - Save(ctx, cfg) → error (uses UPSERT, writes ConfigurationVersion)
+//   - Upgrade(ctx) → error (rewrites an older version, run by Store.Migrate)
+//
+// Versions:
+//
+//	┌─────────┬──────────────────────────────────────────────┐
+//	│ Version │ Content                                      │
+//	├─────────┼──────────────────────────────────────────────┤
+//	│ 1       │ agent_mode column only (data is NULL)        │
+//	│ 2       │ {"agentMode": ...} in data                   │
+//	└─────────┴──────────────────────────────────────────────┘
+//
+// A change to the document bumps ConfigurationVersion and adds the upgrade of
+// the previous version to configurationUpgrades. Get fails on a version newer
+// than ConfigurationVersion, written by a newer agent, instead of dropping
+// the settings it does not know.
 //
 // # InventoryStore
 //
@@ -91,13 +127,17 @@
 //
 // Methods:
 //   - Get(ctx) → *models.Inventory
-//   - Save(ctx, data []byte) → error (uses UPSERT, updates updated_at)
+//   - Save(ctx, data []byte) → error (uses UPSERT, updates updated_at; rejects data
+//     that does not decode as an inventory document)
 //
 // # VMStore
 //
 // Provides read access to VM inventory data. Uses a hybrid approach:
 //   - List/Count: Direct SQL queries against duckdb_parser tables (vinfo, vdisk, concerns)
-//   - Get: Uses parser.VMs() for full VM details with all relationships
+//   - Get: Uses parser.VMs() for full VM details with all relationships, the
+//     CPU topology and hot add flags coming from vcpu and vmemory, the firmware
+//     and hardware version from vinfo; the disk chain depths come from the
+//     DiskChainStore the Store injects into the VMStore
 //
 // List Query Structure:
 //
@@ -144,6 +184,15 @@
 //     Filters VMs by memory in MB. Range is [min, max).
 //     SQL: WHERE v."Memory" >= min AND v."Memory" < max
 //
+//   - ByEncrypted(encrypted bool)
+//     Filters VMs by vSphere VM encryption (joins vm_security).
+//     SQL: WHERE COALESCE(sec.encrypted, false) = encrypted
+//
+//   - ByConcernSeverity(severities ...string)
+//     Filters VMs with a concern of the severities (joins concern_metadata),
+//     the severity of a concern without metadata being its category.
+//     SQL: WHERE v."VM ID" IN (SELECT c."VM_ID" FROM concerns c ...)
+//
 // Pagination Options:
 //
 //   - WithLimit(limit uint64)
@@ -154,6 +203,10 @@
 //     Skips the first N results (for pagination).
 //     SQL: OFFSET offset
 //
+//   - WithCursor(cursor models.Cursor)
+//     Both of the above from the page at cursor, skipping the zero ones. Also
+//     used by AgentEventStore.List, AuditStore.List and PolicyDecisionStore.List.
+//
 // Sorting Options:
 //
 //   - WithSort(sorts []SortParam)
@@ -176,6 +229,135 @@
 //	│  issues      │  issue_count                │
 //	└──────────────┴─────────────────────────────┘
 //
+// # VMSecurityStore
+//
+// Keeps the security settings the parser schema does not carry. Filled during
+// the parsing step of a collection, right after the parser ingested the sqlite file.
+//
+// Methods:
+//   - IngestSqlite(ctx, sqlitePath, encrypted []string) → error (replaces all rows)
+//   - AddConcerns(ctx) → error (appends encryption, vTPM and secure boot concerns)
+//
+// # ClusterStore
+//
+// Keeps the DRS rules collected from vCenter, keyed by the managed object ID of
+// their cluster and their name. VM and host members are stored as JSON arrays
+// of managed object IDs.
+//
+// Methods:
+//   - ReplaceRules(ctx, rules) → error (replaces all rows)
+//   - ListRules(ctx, cluster) → []models.ClusterRule (404 if cluster not in vinfo)
+//
+// # InfrastructureStore
+//
+// Reads the hosts, datastores and networks from the parser tables (vhost,
+// vdatastore, dvswitch, dvport) and converts the parser structs to models.
+// The cluster is checked against the inventory before it reaches the parser
+// queries.
+//
+// Methods:
+//   - ListHosts(ctx, cluster) → []models.Host (404 if cluster not in vinfo)
+//   - ListDatastores(ctx, cluster) → []models.Datastore (404 if cluster not in vinfo)
+//   - ListNetworks(ctx) → []models.Network (VM NIC count per network)
+//
+// # EventStore
+//
+// Keeps a bounded window (newest 1000) of vCenter events and triggered alarms
+// related to VMs and hosts.
+//
+// Methods:
+//   - Replace(ctx, events) → error (replaces all rows)
+//   - ListByVM(ctx, vmID) → []models.VCenterEvent (VM and host events, newest first)
+//   - AddConcerns(ctx) → error (flags VMs with 3+ error events or an active alarm)
+//
+// # DatastoreStore
+//
+// Keeps the datastore performance sampled from the hosts during collection.
+//
+// Methods:
+//   - ReplaceStats(ctx, stats) → error (replaces all rows)
+//   - ListStats(ctx) → []models.DatastoreStats (ordered by name)
+//
+// # DiskChainStore
+//
+// Keeps the backing chain of each virtual disk as read from the VM layout.
+// VMStore.Get uses it to fill Disk.ChainDepth and Disk.LinkedClone.
+//
+// Methods:
+//   - Replace(ctx, chains) → error (replaces all rows)
+//   - ListByVM(ctx, vmID) → []models.DiskChain (ordered by disk key)
+//   - AddConcerns(ctx) → error (flags deep chains and linked clones)
+//
+// # Concerns
+//
+// Store.ReevaluateConcerns replaces the concerns of the stored VMs with the
+// ones of the validator given to NewStore, then adds the event and disk chain
+// concerns again, for policies reloaded after the collection.
+// Store.BuildInventory builds the inventory document of the parsed data, saved
+// with InventoryStore.Save after a collection or a re-evaluation.
+//
+// ConcernMetadataStore keeps the metadata of the concerns read from the
+// annotations of the policy rules (title, severity, remediation, docs link).
+//
+// Methods:
+//   - Replace(ctx, metadata) → error (replaces all rows)
+//   - Get(ctx, id) → *models.ConcernDetail (metadata and number of VMs flagged)
+//
+// PolicyDecisionStore keeps the decision log of the policy evaluations, a row
+// per rule that flagged a VM or per VM that passed, bounded to the newest 50000.
+//
+// Methods:
+//   - Insert(ctx, decisions...) → error (appends, then drops the oldest)
+//   - List(ctx, filter, cursor) → []models.PolicyDecision (newest first)
+//   - Count(ctx, filter) → int
+//
+// RegistrationStore keeps the agent and source ids the console gave to an
+// agent registered with a provisioning token, a single row.
+//
+// Methods:
+//   - Get(ctx) → *models.Registration (ResourceNotFoundError before the registration)
+//   - Save(ctx, registration) → error (replaces the row)
+//
+// SourceStore keeps the sources added to the agent through the API, built
+// again at each start. Their inventories are in databases of their own.
+//
+// Methods:
+//   - Create(ctx, source) → error
+//   - List(ctx) → []models.Source (oldest first)
+//   - Delete(ctx, id) → error (ResourceNotFoundError for an unknown source)
+//
+// JobStore keeps the jobs following the long-running operations of the
+// agent, bounded to the newest 1000.
+//
+// Methods:
+//   - Create(ctx, job) → error (appends, then drops the oldest)
+//   - Update(ctx, job) → error (ResourceNotFoundError for an unknown job)
+//   - Get(ctx, id) → *models.Job
+//   - List(ctx, filter, cursor) → []models.Job (newest first)
+//   - Count(ctx, filter) → int
+//   - FailRunning(ctx, reason, at) → int64 (the jobs of a previous run)
+//
+// PlanStore keeps the generated migration plans, each as the JSON document it
+// was generated as.
+//
+// Methods:
+//   - Create(ctx, plan) → error
+//   - Get(ctx, id) → *models.Plan (ResourceNotFoundError for an unknown plan)
+//   - List(ctx) → []models.Plan (newest first)
+//   - Delete(ctx, id) → error (ResourceNotFoundError for an unknown plan)
+//
+// BenchmarkStore keeps the throughput measured by the benchmark jobs, each as
+// the JSON document of its measures.
+//
+// Methods:
+//   - Create(ctx, benchmark) → error
+//   - Latest(ctx) → *models.Benchmark (ResourceNotFoundError before the first)
+//
+// # Stats
+//
+// Store.Stats returns the database and WAL sizes reported by DuckDB and the
+// estimated number of rows of each table, for the support bundle.
+//
 // # QueryInterceptor
 //
 // All database operations are wrapped with a QueryInterceptor that provides
@@ -187,6 +369,13 @@
 //   - QueryContext
 //   - ExecContext
 //
+// Queries issued on behalf of an API request are logged with its request_id
+// (see pkg/logger.WithContext), so SQL logs can be matched to the request.
+//
+// Store.ExplainSlowQueries sets a threshold above which QueryContext runs the
+// query again with EXPLAIN ANALYZE, once the caller released the connection,
+// and logs its plan on the store.explain logger.
+//
 // # Design Patterns
 //
 // Single-Row Tables:
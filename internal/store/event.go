@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for vcenter_events table
+const (
+	eventsTable        = "vcenter_events"
+	eventsColKind      = "kind"
+	eventsColType      = "type"
+	eventsColSeverity  = "severity"
+	eventsColVmID      = "vm_id"
+	eventsColHostID    = "host_id"
+	eventsColMessage   = "message"
+	eventsColCreatedAt = "created_at"
+)
+
+const (
+	// ConcernUnhealthy flags VMs with repeated failures in the event window.
+	ConcernUnhealthy = "vmware.vm.unhealthy"
+	// UnhealthyEventThreshold is the number of error events (or active red alarms) after which a VM is unhealthy.
+	UnhealthyEventThreshold = 3
+	// maxStoredEvents bounds the number of events kept in the store.
+	maxStoredEvents = 1000
+)
+
+type EventStore struct {
+	db QueryInterceptor
+}
+
+func NewEventStore(db QueryInterceptor) *EventStore {
+	return &EventStore{db: db}
+}
+
+// Replace replaces the stored events with events, keeping only the most recent maxStoredEvents.
+func (s *EventStore) Replace(ctx context.Context, events []models.VCenterEvent) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", eventsTable)); err != nil {
+		return fmt.Errorf("clearing events: %w", err)
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(eventsTable).Columns(
+		eventsColKind,
+		eventsColType,
+		eventsColSeverity,
+		eventsColVmID,
+		eventsColHostID,
+		eventsColMessage,
+		eventsColCreatedAt,
+	)
+	for _, e := range events {
+		builder = builder.Values(string(e.Kind), e.Type, e.Severity, e.VMID, e.HostID, e.Message, e.CreatedAt.UTC())
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("building events insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting events: %w", err)
+	}
+
+	// keep the window bounded: drop everything older than the newest maxStoredEvents
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE %[2]s < (
+			SELECT MIN(%[2]s) FROM (SELECT %[2]s FROM %[1]s ORDER BY %[2]s DESC LIMIT %[3]d)
+		)`, eventsTable, eventsColCreatedAt, maxStoredEvents)); err != nil {
+		return fmt.Errorf("trimming events: %w", err)
+	}
+
+	return nil
+}
+
+// ListByVM returns the events of the VM and of the host it runs on, newest first.
+func (s *EventStore) ListByVM(ctx context.Context, vmID string) ([]models.VCenterEvent, error) {
+	query, args, err := sq.Select(
+		eventsColKind,
+		eventsColType,
+		eventsColSeverity,
+		eventsColVmID,
+		eventsColHostID,
+		eventsColMessage,
+		eventsColCreatedAt,
+	).From(eventsTable).
+		Where(sq.Or{
+			sq.Eq{eventsColVmID: vmID},
+			sq.And{
+				sq.Eq{eventsColVmID: ""},
+				sq.Expr(fmt.Sprintf(`%s IN (SELECT "Host" FROM vinfo WHERE "VM ID" = ?)`, eventsColHostID), vmID),
+			},
+		}).
+		OrderBy(eventsColCreatedAt + " DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building events query for vm %s: %w", vmID, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.VCenterEvent{}
+	for rows.Next() {
+		var e models.VCenterEvent
+		var kind string
+		var createdAt time.Time
+		if err := rows.Scan(&kind, &e.Type, &e.Severity, &e.VMID, &e.HostID, &e.Message, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning event for vm %s: %w", vmID, err)
+		}
+		e.Kind = models.VCenterEventKind(kind)
+		e.CreatedAt = createdAt
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// AddConcerns flags the VMs with at least UnhealthyEventThreshold error events or with an
+// active red alarm as chronically unhealthy.
+func (s *EventStore) AddConcerns(ctx context.Context) error {
+	unhealthy := sq.Select(eventsColVmID).
+		From(eventsTable).
+		Where(sq.And{
+			sq.NotEq{eventsColVmID: ""},
+			sq.Eq{eventsColSeverity: "error"},
+		}).
+		GroupBy(eventsColVmID).
+		Having(sq.Or{
+			sq.GtOrEq{"COUNT(*)": UnhealthyEventThreshold},
+			sq.Expr(fmt.Sprintf("bool_or(%s = ?)", eventsColKind), string(models.VCenterEventKindAlarm)),
+		})
+
+	query, args, err := sq.Insert("concerns").
+		Columns(`"VM_ID"`, `"Concern_ID"`, `"Label"`, `"Category"`, `"Assessment"`).
+		Select(sq.Select(eventsColVmID).
+			Column("?", ConcernUnhealthy).
+			Column("?", "Chronically unhealthy VM").
+			Column("?", "Warning").
+			Column("?", "vCenter reported repeated failures (HA restarts, resets, power-on or disk failures) or an active critical alarm for this VM. Investigate its health before migrating it.").
+			FromSelect(unhealthy, "u")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building unhealthy concerns: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting unhealthy concerns: %w", err)
+	}
+
+	return nil
+}
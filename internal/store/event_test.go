@@ -0,0 +1,163 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("EventStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now().UTC().Truncate(time.Second)
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		Expect(fixtures.Insert(ctx, db,
+			fixtures.NewVM("vm-1").WithHost("host-1"),
+			fixtures.NewVM("vm-2").WithHost("host-2"),
+			fixtures.NewVM("vm-3").WithHost("host-2"),
+		)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	vmEvent := func(vmID, severity string, age time.Duration) models.VCenterEvent {
+		return models.VCenterEvent{
+			Kind:      models.VCenterEventKindEvent,
+			Type:      "VmRestartedOnAlternateHostEvent",
+			Severity:  severity,
+			VMID:      vmID,
+			Message:   "restarted",
+			CreatedAt: now.Add(-age),
+		}
+	}
+
+	concernCount := func(vmID string) int {
+		var count int
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM concerns WHERE "VM_ID" = ? AND "Concern_ID" = ?`, vmID, store.ConcernUnhealthy).Scan(&count)
+		Expect(err).NotTo(HaveOccurred())
+		return count
+	}
+
+	Context("Replace and ListByVM", func() {
+		// Given events for a VM and for its host
+		// When we list the events of the VM
+		// Then both should be returned, newest first
+		It("should return VM and host events newest first", func() {
+			// Arrange
+			hostEvent := models.VCenterEvent{
+				Kind:      models.VCenterEventKindEvent,
+				Type:      "HostConnectionLostEvent",
+				Severity:  "warning",
+				HostID:    "host-1",
+				CreatedAt: now.Add(-time.Minute),
+			}
+			events := []models.VCenterEvent{
+				vmEvent("vm-1", "error", time.Hour),
+				hostEvent,
+				vmEvent("vm-2", "error", time.Hour),
+			}
+
+			// Act
+			Expect(s.Event().Replace(ctx, events)).To(Succeed())
+			result, err := s.Event().ListByVM(ctx, "vm-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(2))
+			Expect(result[0].Type).To(Equal("HostConnectionLostEvent"))
+			Expect(result[0].HostID).To(Equal("host-1"))
+			Expect(result[1].VMID).To(Equal("vm-1"))
+			Expect(result[1].CreatedAt.Equal(now.Add(-time.Hour))).To(BeTrue())
+		})
+
+		// Given previously stored events
+		// When we replace them with an empty set
+		// Then no events should remain
+		It("should replace existing events", func() {
+			// Arrange
+			Expect(s.Event().Replace(ctx, []models.VCenterEvent{vmEvent("vm-1", "error", time.Hour)})).To(Succeed())
+
+			// Act
+			Expect(s.Event().Replace(ctx, nil)).To(Succeed())
+			result, err := s.Event().ListByVM(ctx, "vm-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeEmpty())
+		})
+
+		// Given more events than the store keeps
+		// When we replace the events
+		// Then only the newest ones should be kept
+		It("should keep a bounded window of events", func() {
+			// Arrange
+			events := make([]models.VCenterEvent, 0, 1100)
+			for i := 0; i < 1100; i++ {
+				events = append(events, vmEvent("vm-1", "warning", time.Duration(i)*time.Minute))
+			}
+
+			// Act
+			Expect(s.Event().Replace(ctx, events)).To(Succeed())
+			result, err := s.Event().ListByVM(ctx, "vm-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(1000))
+			Expect(result[999].CreatedAt.Equal(now.Add(-999 * time.Minute))).To(BeTrue())
+		})
+	})
+
+	Context("AddConcerns", func() {
+		// Given one VM with repeated failures, one with a single failure and one with a red alarm
+		// When we add the unhealthy concerns
+		// Then only the first and the last VM should be flagged
+		It("should flag chronically unhealthy VMs", func() {
+			// Arrange
+			events := []models.VCenterEvent{
+				vmEvent("vm-1", "error", time.Hour),
+				vmEvent("vm-1", "error", 2*time.Hour),
+				vmEvent("vm-1", "error", 3*time.Hour),
+				vmEvent("vm-2", "error", time.Hour),
+				vmEvent("vm-2", "warning", time.Hour),
+				vmEvent("vm-2", "warning", time.Hour),
+				{Kind: models.VCenterEventKindAlarm, Type: "Virtual machine error", Severity: "error", VMID: "vm-3", CreatedAt: now},
+			}
+			Expect(s.Event().Replace(ctx, events)).To(Succeed())
+
+			// Act
+			err := s.Event().AddConcerns(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernCount("vm-1")).To(Equal(1))
+			Expect(concernCount("vm-2")).To(Equal(0))
+			Expect(concernCount("vm-3")).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/kubev2v/migration-planner/pkg/duckdb_parser"
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// notAvailable is the value the parser fills the unknown columns with.
+const notAvailable = "N/A"
+
+// InfrastructureStore reads the hosts, datastores and networks of the
+// inventory from the parser tables.
+type InfrastructureStore struct {
+	parser *duckdb_parser.Parser
+}
+
+func NewInfrastructureStore(parser *duckdb_parser.Parser) *InfrastructureStore {
+	return &InfrastructureStore{parser: parser}
+}
+
+// ListHosts returns the hosts of cluster, or of every cluster when cluster is
+// empty, ordered by ID. It returns ResourceNotFoundError when the cluster is
+// not part of the inventory.
+func (s *InfrastructureStore) ListHosts(ctx context.Context, cluster string) ([]models.Host, error) {
+	if err := s.checkCluster(ctx, cluster); err != nil {
+		return nil, err
+	}
+
+	phosts, err := s.parser.Hosts(ctx, duckdb_parser.Filters{Cluster: cluster}, duckdb_parser.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]models.Host, 0, len(phosts))
+	for _, h := range phosts {
+		hosts = append(hosts, hostFromParser(h))
+	}
+	slices.SortFunc(hosts, func(a, b models.Host) int { return cmp.Compare(a.ID, b.ID) })
+
+	return hosts, nil
+}
+
+// ListDatastores returns the datastores mounted by the hosts of cluster, or
+// every datastore when cluster is empty, ordered by ID. It returns
+// ResourceNotFoundError when the cluster is not part of the inventory.
+func (s *InfrastructureStore) ListDatastores(ctx context.Context, cluster string) ([]models.Datastore, error) {
+	if err := s.checkCluster(ctx, cluster); err != nil {
+		return nil, err
+	}
+
+	pdatastores, err := s.parser.Datastores(ctx, duckdb_parser.Filters{Cluster: cluster}, duckdb_parser.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	datastores := make([]models.Datastore, 0, len(pdatastores))
+	for _, d := range pdatastores {
+		datastores = append(datastores, datastoreFromParser(d))
+	}
+	slices.SortFunc(datastores, func(a, b models.Datastore) int { return cmp.Compare(a.ID, b.ID) })
+
+	return datastores, nil
+}
+
+// ListNetworks returns the distributed switches and port groups ordered by
+// name. The networks are not bound to a cluster.
+func (s *InfrastructureStore) ListNetworks(ctx context.Context) ([]models.Network, error) {
+	pnetworks, err := s.parser.Networks(ctx, duckdb_parser.Filters{}, duckdb_parser.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.parser.VMCountByNetwork(ctx, duckdb_parser.Filters{})
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make([]models.Network, 0, len(pnetworks))
+	for _, n := range pnetworks {
+		network := networkFromParser(n)
+		network.VMCount = counts[n.Name]
+		networks = append(networks, network)
+	}
+	slices.SortFunc(networks, func(a, b models.Network) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Type, b.Type))
+	})
+
+	return networks, nil
+}
+
+// checkCluster returns ResourceNotFoundError unless cluster is empty or part
+// of the inventory. The parser inlines the cluster filter in its queries, so
+// only the known names get there.
+func (s *InfrastructureStore) checkCluster(ctx context.Context, cluster string) error {
+	if cluster == "" {
+		return nil
+	}
+
+	clusters, err := s.parser.Clusters(ctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(clusters, cluster) {
+		return srvErrors.NewResourceNotFoundError("cluster", cluster)
+	}
+	return nil
+}
+
+func hostFromParser(h parsermodels.Host) models.Host {
+	return models.Host{
+		ID:         h.Id,
+		Cluster:    h.Cluster,
+		CPUCores:   h.CpuCores,
+		CPUSockets: h.CpuSockets,
+		MemoryMB:   h.MemoryMB,
+		Vendor:     available(h.Vendor),
+		Model:      available(h.Model),
+	}
+}
+
+func datastoreFromParser(d parsermodels.Datastore) models.Datastore {
+	hostIDs := []string{}
+	if ids := available(d.HostId); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			hostIDs = append(hostIDs, strings.TrimSpace(id))
+		}
+	}
+
+	return models.Datastore{
+		ID:                      d.DiskId,
+		Type:                    available(d.Type),
+		ProtocolType:            available(d.ProtocolType),
+		TotalCapacityGB:         d.TotalCapacityGB,
+		FreeCapacityGB:          d.FreeCapacityGB,
+		HardwareAcceleratedMove: d.HardwareAcceleratedMove,
+		HostIDs:                 hostIDs,
+	}
+}
+
+func networkFromParser(n parsermodels.Network) models.Network {
+	return models.Network{
+		Name:     n.Name,
+		Type:     models.NetworkType(n.Type),
+		DVSwitch: n.Dvswitch,
+		VlanID:   n.VlanId,
+	}
+}
+
+// available returns s, or an empty string for the parser's unknown values.
+func available(s string) string {
+	if s == notAvailable {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,164 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("InfrastructureStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		Expect(fixtures.Insert(ctx, db,
+			fixtures.NewVM("vm-1").WithCluster("cluster-a").WithHost("10.0.0.1").WithNIC("pg-100", "00:00:00:00:00:01"),
+			fixtures.NewVM("vm-2").WithCluster("cluster-b").WithHost("10.0.0.2").WithNIC("pg-100", "00:00:00:00:00:02"),
+		)).To(Succeed())
+
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO vhost ("Cluster", "# Cores", "# CPU", "Object ID", "# Memory", "Model", "Vendor", "Host") VALUES
+				('cluster-b', 8, 1, 'host-2', 32768, NULL, NULL, '10.0.0.2'),
+				('cluster-a', 16, 2, 'host-1', 65536, 'R740', 'Dell', '10.0.0.1')`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO vdatastore ("Hosts", "Address", "Name", "Free MiB", "MHA", "Capacity MiB", "Type") VALUES
+				('10.0.0.1, 10.0.0.2', NULL, 'shared-01', 40960, true, 102400, 'VMFS'),
+				('10.0.0.2', NULL, 'local-02', 10240, false, 20480, 'VMFS')`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO dvswitch ("Name") VALUES ('dvs-1');
+			INSERT INTO dvport ("Port", "VLAN", "Switch") VALUES ('pg-100', '100', 'dvs-1')`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	Context("ListHosts", func() {
+		// Given hosts in two clusters
+		// When we list the hosts without a cluster
+		// Then all of them should be returned ordered by ID, without the unknown values
+		It("should list all the hosts", func() {
+			// Act
+			hosts, err := s.Infrastructure().ListHosts(ctx, "")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hosts).To(Equal([]models.Host{
+				{ID: "host-1", Cluster: "cluster-a", CPUCores: 16, CPUSockets: 2, MemoryMB: 65536, Vendor: "Dell", Model: "R740"},
+				{ID: "host-2", Cluster: "cluster-b", CPUCores: 8, CPUSockets: 1, MemoryMB: 32768},
+			}))
+		})
+
+		// Given hosts in two clusters
+		// When we list the hosts of one cluster
+		// Then only its hosts should be returned
+		It("should filter the hosts by cluster", func() {
+			// Act
+			hosts, err := s.Infrastructure().ListHosts(ctx, "cluster-b")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hosts).To(HaveLen(1))
+			Expect(hosts[0].ID).To(Equal("host-2"))
+		})
+
+		// Given a cluster missing from the inventory
+		// When we list its hosts
+		// Then a not found error should be returned
+		It("should return not found for an unknown cluster", func() {
+			// Act
+			_, err := s.Infrastructure().ListHosts(ctx, "cluster-x' OR '1'='1")
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("ListDatastores", func() {
+		// Given a datastore shared by two hosts and a local one
+		// When we list the datastores
+		// Then both should be returned ordered by ID with the hosts mounting them
+		It("should list all the datastores", func() {
+			// Act
+			datastores, err := s.Infrastructure().ListDatastores(ctx, "")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(datastores).To(HaveLen(2))
+			Expect(datastores[0].ID).To(Equal("local-02"))
+			Expect(datastores[0].HostIDs).To(Equal([]string{"host-2"}))
+			Expect(datastores[1].ID).To(Equal("shared-01"))
+			Expect(datastores[1].Type).To(Equal("VMFS"))
+			Expect(datastores[1].ProtocolType).To(BeEmpty())
+			Expect(datastores[1].TotalCapacityGB).To(Equal(100.0))
+			Expect(datastores[1].FreeCapacityGB).To(Equal(40.0))
+			Expect(datastores[1].HostIDs).To(ConsistOf("host-1", "host-2"))
+		})
+
+		// Given a datastore mounted only by the hosts of cluster-b
+		// When we list the datastores of cluster-a
+		// Then only the shared datastore should be returned
+		It("should filter the datastores by cluster", func() {
+			// Act
+			datastores, err := s.Infrastructure().ListDatastores(ctx, "cluster-a")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(datastores).To(HaveLen(1))
+			Expect(datastores[0].ID).To(Equal("shared-01"))
+		})
+
+		// Given a cluster missing from the inventory
+		// When we list its datastores
+		// Then a not found error should be returned
+		It("should return not found for an unknown cluster", func() {
+			// Act
+			_, err := s.Infrastructure().ListDatastores(ctx, "cluster-x")
+
+			// Assert
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("ListNetworks", func() {
+		// Given a distributed switch with a port group used by two VMs
+		// When we list the networks
+		// Then the switch and the port group should be returned with their VM counts
+		It("should list the switches and port groups", func() {
+			// Act
+			networks, err := s.Infrastructure().ListNetworks(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networks).To(Equal([]models.Network{
+				{Name: "dvs-1", Type: models.NetworkTypeDVSwitch},
+				{Name: "pg-100", Type: models.NetworkTypeDistributed, DVSwitch: "dvs-1", VlanID: "100", VMCount: 2},
+			}))
+		})
+	})
+})
@@ -3,15 +3,27 @@ package store
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
 )
 
 type queryInterceptor struct {
 	db     *sql.DB
 	logger *zap.SugaredLogger
 	mu     sync.Mutex
+	// explainThreshold is the duration above which the plan of a list query is
+	// logged, 0 disabling it
+	explainThreshold atomic.Int64
 }
 
 func newQueryInterceptor(db *sql.DB) *queryInterceptor {
@@ -22,21 +34,41 @@ func newQueryInterceptor(db *sql.DB) *queryInterceptor {
 }
 
 func (q *queryInterceptor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
-	q.logger.Debugw("query_row", "query", query, "args", args)
-	return q.db.QueryRowContext(ctx, query, args...)
+	logger.WithContext(ctx, q.logger).Debugw("query_row", "query", query, "args", args)
+	defer observeQuery("query_row", query, time.Now())
+	ctx, span := startQuerySpan(ctx, "query_row", query)
+	row := q.db.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
 }
 
 func (q *queryInterceptor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	q.logger.Debugw("query", "query", query, "args", args)
-	return q.db.QueryContext(ctx, query, args...)
+	logger.WithContext(ctx, q.logger).Debugw("query", "query", query, "args", args)
+	started := time.Now()
+	defer observeQuery("query", query, started)
+	spanCtx, span := startQuerySpan(ctx, "query", query)
+	rows, err := q.db.QueryContext(spanCtx, query, args...)
+	tracing.End(span, err)
+
+	if threshold := time.Duration(q.explainThreshold.Load()); err == nil && threshold > 0 {
+		if elapsed := time.Since(started); elapsed > threshold {
+			// the connection is held by rows until the caller closes them
+			go q.explain(context.WithoutCancel(ctx), query, args, elapsed)
+		}
+	}
+	return rows, err
 }
 
 func (q *queryInterceptor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.logger.Debugw("exec", "query", query, "args", args)
-	result, err := q.db.ExecContext(ctx, query, args...)
+	logger.WithContext(ctx, q.logger).Debugw("exec", "query", query, "args", args)
+	started := time.Now()
+	spanCtx, span := startQuerySpan(ctx, "exec", query)
+	result, err := q.db.ExecContext(spanCtx, query, args...)
+	tracing.End(span, err)
+	observeQuery("exec", query, started)
 	if err != nil {
 		return result, err
 	}
@@ -45,3 +77,63 @@ func (q *queryInterceptor) ExecContext(ctx context.Context, query string, args .
 	}
 	return result, nil
 }
+
+// explain logs the plan of query, run again with EXPLAIN ANALYZE to get the
+// time spent by each operator.
+func (q *queryInterceptor) explain(ctx context.Context, query string, args []any, elapsed time.Duration) {
+	log := logger.WithContext(ctx, q.logger.Named("explain"))
+
+	rows, err := q.db.QueryContext(ctx, "EXPLAIN ANALYZE "+query, args...)
+	if err != nil {
+		log.Warnw("failed to explain slow query", "query", query, "error", err)
+		return
+	}
+	defer rows.Close()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			log.Warnw("failed to read slow query plan", "query", query, "error", err)
+			return
+		}
+		plan.WriteString(value)
+	}
+	if err := rows.Err(); err != nil {
+		log.Warnw("failed to read slow query plan", "query", query, "error", err)
+		return
+	}
+
+	log.Infow("slow query", "query", query, "args", args, "duration", elapsed, "plan", plan.String())
+}
+
+// observeQuery records the duration of query since started. The statement is
+// the first keyword of the query, so the labels stay bounded.
+func observeQuery(operation, query string, started time.Time) {
+	metrics.StoreQueryDuration.WithLabelValues(operation, statement(query)).Observe(time.Since(started).Seconds())
+}
+
+// startQuerySpan starts the client span of query, a child of the span carried
+// by ctx, if any.
+func startQuerySpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "store."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "duckdb"),
+			attribute.String("db.operation", statement(query)),
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+func statement(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other"
+	}
+	switch keyword := strings.ToLower(fields[0]); keyword {
+	case "select", "insert", "update", "delete", "create", "alter", "drop", "with":
+		return keyword
+	}
+	return "other"
+}
@@ -0,0 +1,135 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/metrics"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("Query instrumentation", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a migrated store
+	// When we run a select and a delete
+	// Then their durations should be recorded by operation and statement
+	It("should record the query durations", func() {
+		// Arrange
+		metrics.StoreQueryDuration.Reset()
+
+		// Act
+		_, err := s.LoginFailure().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.LoginFailure().Delete(ctx, "10.0.0.1")).To(Succeed())
+
+		// Assert
+		Expect(testutil.CollectAndCount(metrics.StoreQueryDuration)).To(Equal(2))
+		_, err = metrics.StoreQueryDuration.GetMetricWithLabelValues("query", "select")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = metrics.StoreQueryDuration.GetMetricWithLabelValues("exec", "delete")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// Given a tracer provider recording the spans
+	// When we run a query in the context of a span
+	// Then a child span should describe the query
+	It("should record a span per query", func() {
+		// Arrange
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		otel.SetTracerProvider(provider)
+		defer otel.SetTracerProvider(noop.NewTracerProvider())
+		parentCtx, parent := provider.Tracer("test").Start(ctx, "request")
+
+		// Act
+		_, err := s.LoginFailure().List(parentCtx)
+		parent.End()
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name()).To(Equal("store.query"))
+		Expect(spans[0].Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(spans[0].Attributes()).To(ContainElement(attribute.String("db.operation", "select")))
+	})
+
+	Context("ExplainSlowQueries", func() {
+		var logs *observer.ObservedLogs
+
+		BeforeEach(func() {
+			// the interceptor takes its logger when the store is created
+			var core zapcore.Core
+			core, logs = observer.New(zapcore.InfoLevel)
+			DeferCleanup(zap.ReplaceGlobals(zap.New(core)))
+			s = store.NewStore(db, test.NewMockValidator())
+		})
+
+		// Given a threshold every query exceeds
+		// When we run a list query
+		// Then its plan should be logged
+		It("should log the plan of the slow list queries", func() {
+			// Arrange
+			s.ExplainSlowQueries(time.Nanosecond)
+
+			// Act
+			_, err := s.LoginFailure().List(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() int { return logs.FilterMessage("slow query").Len() }).Should(Equal(1))
+			entry := logs.FilterMessage("slow query").All()[0]
+			Expect(entry.LoggerName).To(Equal("store.explain"))
+			Expect(entry.ContextMap()["query"]).To(ContainSubstring("login_failures"))
+			Expect(entry.ContextMap()["plan"]).To(ContainSubstring("Total Time"))
+		})
+
+		// Given no threshold
+		// When we run a list query
+		// Then no plan should be logged
+		It("should not explain the queries by default", func() {
+			// Act
+			_, err := s.LoginFailure().List(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Consistently(func() int { return logs.FilterMessage("slow query").Len() }, 200*time.Millisecond).Should(BeZero())
+		})
+	})
+})
@@ -40,7 +40,13 @@ func (s *InventoryStore) Get(ctx context.Context) (*models.Inventory, error) {
 	return &inv, nil
 }
 
+// Save stores data as the inventory, replacing the previous one. It returns an
+// error without storing anything when data is not an inventory document.
 func (s *InventoryStore) Save(ctx context.Context, data []byte) error {
+	if err := models.NewInventory(data).Validate(); err != nil {
+		return err
+	}
+
 	query, args, err := sq.Insert("inventory").
 		Columns("id", "data", "updated_at").
 		Values(1, data, sq.Expr("now()")).
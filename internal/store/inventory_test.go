@@ -7,10 +7,11 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
-	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 var _ = Describe("InventoryStore", func() {
@@ -24,10 +25,7 @@ var _ = Describe("InventoryStore", func() {
 		ctx = context.Background()
 
 		var err error
-		db, err = store.NewDB(":memory:")
-		Expect(err).NotTo(HaveOccurred())
-
-		err = migrations.Run(ctx, db)
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		s = store.NewStore(db, test.NewMockValidator())
@@ -73,6 +71,19 @@ var _ = Describe("InventoryStore", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(retrieved.Data).To(Equal(data2))
 		})
+
+		// Given data that is not an inventory document
+		// When we save it
+		// Then it should be rejected and nothing stored
+		It("should reject malformed inventory", func() {
+			// Act
+			err := s.Inventory().Save(ctx, []byte(`{"clusters": [`))
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			_, err = s.Inventory().Get(ctx)
+			Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		})
 	})
 
 	Describe("Get", func() {
@@ -121,5 +132,30 @@ var _ = Describe("InventoryStore", func() {
 			Expect(retrieved.CreatedAt).NotTo(BeZero())
 			Expect(retrieved.UpdatedAt).NotTo(BeZero())
 		})
+
+		// Given a saved inventory with two clusters
+		// When we read it through the typed accessors
+		// Then the clusters, VM count and hash should come from the document
+		It("should decode the saved inventory", func() {
+			// Arrange
+			data := []byte(`{"vcenter_id": "vc-1", "clusters": {
+				"cluster-b": {"vms": {"total": 2}},
+				"cluster-a": {"vms": {"total": 3}}
+			}}`)
+			Expect(s.Inventory().Save(ctx, data)).To(Succeed())
+
+			// Act
+			retrieved, err := s.Inventory().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			clusters, clustersErr := retrieved.Clusters()
+			count, countErr := retrieved.VMCount()
+
+			// Assert
+			Expect(clustersErr).NotTo(HaveOccurred())
+			Expect(clusters).To(Equal([]string{"cluster-a", "cluster-b"}))
+			Expect(countErr).NotTo(HaveOccurred())
+			Expect(count).To(Equal(5))
+			Expect(retrieved.Hash()).To(Equal(models.NewInventory(data).Hash()))
+		})
 	})
 })
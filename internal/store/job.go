@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for jobs table
+const (
+	jobsTable         = "jobs"
+	jobsColID         = "id"
+	jobsColKind       = "kind"
+	jobsColState      = "state"
+	jobsColPhase      = "phase"
+	jobsColProgress   = "progress"
+	jobsColError      = "error"
+	jobsColCreatedAt  = "created_at"
+	jobsColUpdatedAt  = "updated_at"
+	jobsColFinishedAt = "finished_at"
+)
+
+// maxJobs bounds the number of jobs kept.
+const maxJobs = 1000
+
+type JobStore struct {
+	db QueryInterceptor
+}
+
+func NewJobStore(db QueryInterceptor) *JobStore {
+	return &JobStore{db: db}
+}
+
+// Create stores job, keeping only the most recent maxJobs.
+func (s *JobStore) Create(ctx context.Context, job models.Job) error {
+	query, args, err := sq.Insert(jobsTable).
+		Columns(jobsColID, jobsColKind, jobsColState, jobsColPhase, jobsColProgress, jobsColError,
+			jobsColCreatedAt, jobsColUpdatedAt, jobsColFinishedAt).
+		Values(job.ID, string(job.Kind), string(job.State), job.Phase, job.Progress, job.Error,
+			job.CreatedAt.UTC(), job.UpdatedAt.UTC(), utcOrNil(job.FinishedAt)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building job insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting job: %w", err)
+	}
+
+	// keep the table bounded: drop everything older than the newest maxJobs
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE %[2]s < (
+			SELECT MIN(%[2]s) FROM (SELECT %[2]s FROM %[1]s ORDER BY %[2]s DESC LIMIT %[3]d)
+		)`, jobsTable, jobsColCreatedAt, maxJobs)); err != nil {
+		return fmt.Errorf("trimming jobs: %w", err)
+	}
+
+	return nil
+}
+
+// Update saves the state, phase, progress, error and timestamps of job. It
+// fails with a ResourceNotFoundError when there is no such job.
+func (s *JobStore) Update(ctx context.Context, job models.Job) error {
+	query, args, err := sq.Update(jobsTable).
+		Set(jobsColState, string(job.State)).
+		Set(jobsColPhase, job.Phase).
+		Set(jobsColProgress, job.Progress).
+		Set(jobsColError, job.Error).
+		Set(jobsColUpdatedAt, job.UpdatedAt.UTC()).
+		Set(jobsColFinishedAt, utcOrNil(job.FinishedAt)).
+		Where(sq.Eq{jobsColID: job.ID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building job update: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("updating job: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return srvErrors.NewResourceNotFoundError("job", job.ID)
+	}
+	return nil
+}
+
+// Get returns the job id, a ResourceNotFoundError when there is no such job.
+func (s *JobStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	query, args, err := selectJobs().Where(sq.Eq{jobsColID: id}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building job query: %w", err)
+	}
+
+	job, err := scanJob(s.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, srvErrors.NewResourceNotFoundError("job", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List returns the page at cursor of the jobs matching filter, newest first.
+func (s *JobStore) List(ctx context.Context, filter models.JobFilter, cursor models.Cursor) ([]models.Job, error) {
+	builder := selectJobs().OrderBy(jobsColCreatedAt+" DESC", jobsColID)
+
+	query, args, err := WithCursor(cursor)(applyJobFilter(builder, filter)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building jobs query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Count returns the number of jobs matching filter.
+func (s *JobStore) Count(ctx context.Context, filter models.JobFilter) (int, error) {
+	query, args, err := applyJobFilter(sq.Select("COUNT(*)").From(jobsTable), filter).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("building jobs count query: %w", err)
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// FailRunning marks the jobs still running as failed at with reason, for the
+// jobs a previous run of the agent did not finish. It returns their number.
+func (s *JobStore) FailRunning(ctx context.Context, reason string, at time.Time) (int64, error) {
+	query, args, err := sq.Update(jobsTable).
+		Set(jobsColState, string(models.JobStateFailed)).
+		Set(jobsColError, reason).
+		Set(jobsColUpdatedAt, at.UTC()).
+		Set(jobsColFinishedAt, at.UTC()).
+		Where(sq.Eq{jobsColState: string(models.JobStateRunning)}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("building jobs update: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failing running jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func selectJobs() sq.SelectBuilder {
+	return sq.Select(jobsColID, jobsColKind, jobsColState, jobsColPhase, jobsColProgress, jobsColError,
+		jobsColCreatedAt, jobsColUpdatedAt, jobsColFinishedAt).
+		From(jobsTable)
+}
+
+func scanJob(row interface{ Scan(...any) error }) (models.Job, error) {
+	var (
+		job      models.Job
+		finished sql.NullTime
+	)
+	if err := row.Scan(&job.ID, &job.Kind, &job.State, &job.Phase, &job.Progress, &job.Error,
+		&job.CreatedAt, &job.UpdatedAt, &finished); err != nil {
+		return models.Job{}, err
+	}
+	if finished.Valid {
+		job.FinishedAt = &finished.Time
+	}
+	return job, nil
+}
+
+// utcOrNil returns t in UTC, nil for a nil t.
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func applyJobFilter(builder sq.SelectBuilder, filter models.JobFilter) sq.SelectBuilder {
+	if len(filter.Kinds) > 0 {
+		kinds := make([]string, 0, len(filter.Kinds))
+		for _, k := range filter.Kinds {
+			kinds = append(kinds, string(k))
+		}
+		builder = builder.Where(sq.Eq{jobsColKind: kinds})
+	}
+	if len(filter.States) > 0 {
+		states := make([]string, 0, len(filter.States))
+		for _, st := range filter.States {
+			states = append(states, string(st))
+		}
+		builder = builder.Where(sq.Eq{jobsColState: states})
+	}
+	return builder
+}
@@ -0,0 +1,133 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("JobStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+		at  time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		at = time.Now().UTC().Truncate(time.Second)
+		Expect(s.Job().Create(ctx, models.Job{ID: "job-1", Kind: models.JobKindCollection, State: models.JobStateRunning, CreatedAt: at, UpdatedAt: at})).To(Succeed())
+		Expect(s.Job().Create(ctx, models.Job{ID: "job-2", Kind: models.JobKindInspection, State: models.JobStateRunning, CreatedAt: at.Add(time.Minute), UpdatedAt: at.Add(time.Minute)})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given two running jobs
+	// When we list them
+	// Then they should be returned newest first
+	It("should list the jobs newest first", func() {
+		// Act
+		jobs, err := s.Job().List(ctx, models.JobFilter{}, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(2))
+		Expect(jobs[0].ID).To(Equal("job-2"))
+		Expect(jobs[0].Kind).To(Equal(models.JobKindInspection))
+		Expect(jobs[1].ID).To(Equal("job-1"))
+		Expect(jobs[1].FinishedAt).To(BeNil())
+	})
+
+	// Given a running job
+	// When it is updated as failed
+	// Then its state, progress, error and end should be returned
+	It("should update a job", func() {
+		// Arrange
+		finished := at.Add(2 * time.Minute)
+		job := models.Job{ID: "job-1", State: models.JobStateFailed, Phase: "parsing", Progress: 75, Error: "parse error", UpdatedAt: finished, FinishedAt: &finished}
+
+		// Act
+		err := s.Job().Update(ctx, job)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		got, err := s.Job().Get(ctx, "job-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.State).To(Equal(models.JobStateFailed))
+		Expect(got.Phase).To(Equal("parsing"))
+		Expect(got.Progress).To(Equal(75))
+		Expect(got.Error).To(Equal("parse error"))
+		Expect(got.FinishedAt.Equal(finished)).To(BeTrue())
+		Expect(got.CreatedAt.Equal(at)).To(BeTrue())
+	})
+
+	// Given two jobs of different kinds
+	// When we filter them by kind and state
+	// Then only the matching jobs should be returned and counted
+	It("should filter the jobs", func() {
+		// Arrange
+		filter := models.JobFilter{Kinds: []models.JobKind{models.JobKindCollection}, States: []models.JobState{models.JobStateRunning}}
+
+		// Act
+		jobs, err := s.Job().List(ctx, filter, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].ID).To(Equal("job-1"))
+		count, err := s.Job().Count(ctx, filter)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+	})
+
+	// Given jobs left running by a previous run
+	// When they are failed
+	// Then none should be running anymore
+	It("should fail the running jobs", func() {
+		// Act
+		n, err := s.Job().FailRunning(ctx, "interrupted", at.Add(time.Hour))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(2)))
+		count, err := s.Job().Count(ctx, models.JobFilter{States: []models.JobState{models.JobStateRunning}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeZero())
+		job, err := s.Job().Get(ctx, "job-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Error).To(Equal("interrupted"))
+		Expect(job.FinishedAt).NotTo(BeNil())
+	})
+
+	// Given no job with that id
+	// When we get it
+	// Then ResourceNotFoundError should be returned
+	It("should return not found for an unknown job", func() {
+		// Act
+		_, err := s.Job().Get(ctx, "job-3")
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+})
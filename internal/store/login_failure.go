@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for login_failures table
+const (
+	loginFailuresTable           = "login_failures"
+	loginFailuresColIP           = "ip"
+	loginFailuresColFailures     = "failures"
+	loginFailuresColLastFailure  = "last_failure"
+	loginFailuresColBlockedUntil = "blocked_until"
+)
+
+type LoginFailureStore struct {
+	db QueryInterceptor
+}
+
+func NewLoginFailureStore(db QueryInterceptor) *LoginFailureStore {
+	return &LoginFailureStore{db: db}
+}
+
+// Save stores failures, replacing the previous ones of its IP.
+func (s *LoginFailureStore) Save(ctx context.Context, failures models.LoginFailures) error {
+	query, args, err := sq.Insert(loginFailuresTable).
+		Columns(loginFailuresColIP, loginFailuresColFailures, loginFailuresColLastFailure, loginFailuresColBlockedUntil).
+		Values(failures.IP, failures.Count, failures.LastFailure.UTC(), failures.BlockedUntil.UTC()).
+		Suffix("ON CONFLICT (" + loginFailuresColIP + ") DO UPDATE SET " +
+			loginFailuresColFailures + " = EXCLUDED." + loginFailuresColFailures + ", " +
+			loginFailuresColLastFailure + " = EXCLUDED." + loginFailuresColLastFailure + ", " +
+			loginFailuresColBlockedUntil + " = EXCLUDED." + loginFailuresColBlockedUntil).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building login failures upsert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("saving login failures: %w", err)
+	}
+	return nil
+}
+
+// List returns the failures of every IP.
+func (s *LoginFailureStore) List(ctx context.Context) ([]models.LoginFailures, error) {
+	query, args, err := sq.Select(
+		loginFailuresColIP,
+		loginFailuresColFailures,
+		loginFailuresColLastFailure,
+		loginFailuresColBlockedUntil,
+	).From(loginFailuresTable).
+		OrderBy(loginFailuresColIP).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building login failures query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := []models.LoginFailures{}
+	for rows.Next() {
+		var f models.LoginFailures
+		if err := rows.Scan(&f.IP, &f.Count, &f.LastFailure, &f.BlockedUntil); err != nil {
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
+
+// Delete removes the failures of ip. Deleting an IP without failures is not an error.
+func (s *LoginFailureStore) Delete(ctx context.Context, ip string) error {
+	query, args, err := sq.Delete(loginFailuresTable).Where(sq.Eq{loginFailuresColIP: ip}).ToSql()
+	if err != nil {
+		return fmt.Errorf("building login failures delete: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("deleting login failures: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore removes the failures of the IPs whose last failure and block
+// both ended before t.
+func (s *LoginFailureStore) DeleteBefore(ctx context.Context, t time.Time) error {
+	query, args, err := sq.Delete(loginFailuresTable).
+		Where(sq.Lt{loginFailuresColLastFailure: t.UTC()}).
+		Where(sq.Lt{loginFailuresColBlockedUntil: t.UTC()}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building login failures delete: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("deleting expired login failures: %w", err)
+	}
+	return nil
+}
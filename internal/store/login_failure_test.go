@@ -0,0 +1,102 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("LoginFailureStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+		at  time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		at = time.Now().UTC().Truncate(time.Second)
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given the failures of an IP saved twice
+	// When we list the failures
+	// Then only the last ones should be returned
+	It("should replace the failures of an IP", func() {
+		// Arrange
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.1", Count: 1, LastFailure: at, BlockedUntil: at.Add(time.Second)})).To(Succeed())
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.1", Count: 2, LastFailure: at.Add(time.Minute), BlockedUntil: at.Add(time.Minute + 2*time.Second)})).To(Succeed())
+
+		// Act
+		failures, err := s.LoginFailure().List(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0].IP).To(Equal("10.0.0.1"))
+		Expect(failures[0].Count).To(Equal(2))
+		Expect(failures[0].LastFailure.Equal(at.Add(time.Minute))).To(BeTrue())
+		Expect(failures[0].BlockedUntil.Equal(at.Add(time.Minute + 2*time.Second))).To(BeTrue())
+	})
+
+	// Given the failures of two IPs
+	// When we delete those of one IP
+	// Then only the other ones should remain
+	It("should delete the failures of an IP", func() {
+		// Arrange
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.1", Count: 1, LastFailure: at, BlockedUntil: at})).To(Succeed())
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.2", Count: 3, LastFailure: at, BlockedUntil: at})).To(Succeed())
+
+		// Act
+		err := s.LoginFailure().Delete(ctx, "10.0.0.1")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		failures, err := s.LoginFailure().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0].IP).To(Equal("10.0.0.2"))
+	})
+
+	// Given an expired failure, a recent one and an expired failure still blocked
+	// When we delete the failures before a time
+	// Then only the expired one should be removed
+	It("should delete the expired failures", func() {
+		// Arrange
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.1", Count: 1, LastFailure: at.Add(-time.Hour), BlockedUntil: at.Add(-time.Hour)})).To(Succeed())
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.2", Count: 1, LastFailure: at, BlockedUntil: at})).To(Succeed())
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.3", Count: 10, LastFailure: at.Add(-time.Hour), BlockedUntil: at.Add(time.Hour)})).To(Succeed())
+
+		// Act
+		err := s.LoginFailure().DeleteBefore(ctx, at.Add(-time.Minute))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		failures, err := s.LoginFailure().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(HaveLen(2))
+		Expect(failures[0].IP).To(Equal("10.0.0.2"))
+		Expect(failures[1].IP).To(Equal("10.0.0.3"))
+	})
+})
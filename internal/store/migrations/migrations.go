@@ -10,10 +10,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kubev2v/migration-planner/pkg/duckdb_parser"
 
 	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
 )
 
 //go:embed sql/*.sql
@@ -64,6 +67,54 @@ func Run(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
+// Status returns every embedded migration ordered by version, with the time it
+// was applied. Migrations not applied yet have a nil AppliedAt.
+func Status(ctx context.Context, db *sql.DB) ([]models.Migration, error) {
+	if err := createMigrationsTable(ctx, db); err != nil {
+		return nil, fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("getting applied versions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	appliedAt := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			v  int
+			at time.Time
+		)
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[v] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	files, err := getMigrationFiles()
+	if err != nil {
+		return nil, fmt.Errorf("getting migration files: %w", err)
+	}
+
+	status := make([]models.Migration, 0, len(files))
+	for _, file := range files {
+		version := extractVersion(file)
+		if version == 0 {
+			continue
+		}
+		m := models.Migration{Version: version, Name: strings.TrimSuffix(filepath.Base(file), ".sql")}
+		if at, ok := appliedAt[version]; ok {
+			m.AppliedAt = &at
+		}
+		status = append(status, m)
+	}
+	return status, nil
+}
+
 func createMigrationsTable(ctx context.Context, db *sql.DB) error {
 	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
@@ -142,4 +142,42 @@ var _ = Describe("Migrations", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	Context("Status", func() {
+		// Given migrations have been applied
+		// When we get the migration status
+		// Then every embedded migration should be reported as applied, in order
+		It("should report applied migrations", func() {
+			// Arrange
+			Expect(migrations.Run(ctx, db)).To(Succeed())
+
+			// Act
+			status, err := migrations.Status(ctx, db)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).NotTo(BeEmpty())
+			Expect(status[0].Version).To(Equal(1))
+			Expect(status[0].Name).To(Equal("001_initial"))
+			for i, m := range status {
+				Expect(m.Version).To(Equal(i + 1))
+				Expect(m.AppliedAt).NotTo(BeNil())
+			}
+		})
+
+		// Given a database where no migration was applied
+		// When we get the migration status
+		// Then every embedded migration should be reported as pending
+		It("should report pending migrations", func() {
+			// Act
+			status, err := migrations.Status(ctx, db)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).NotTo(BeEmpty())
+			for _, m := range status {
+				Expect(m.AppliedAt).To(BeNil())
+			}
+		})
+	})
 })
@@ -0,0 +1,8 @@
+-- VM security settings that the parser schema does not carry.
+-- Rows are keyed by the vCenter VM ID and replaced on every collection.
+CREATE TABLE IF NOT EXISTS vm_security (
+    "VM ID" VARCHAR PRIMARY KEY,
+    encrypted BOOLEAN DEFAULT false,
+    tpm_enabled BOOLEAN DEFAULT false,
+    secure_boot BOOLEAN DEFAULT false
+);
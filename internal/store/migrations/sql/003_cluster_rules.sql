@@ -0,0 +1,14 @@
+-- DRS affinity/anti-affinity rules collected from vCenter.
+-- vms and hosts hold JSON arrays of managed object IDs.
+CREATE TABLE IF NOT EXISTS cluster_rules (
+    cluster VARCHAR NOT NULL,
+    name VARCHAR NOT NULL,
+    type VARCHAR NOT NULL,
+    enabled BOOLEAN DEFAULT false,
+    mandatory BOOLEAN DEFAULT false,
+    vms VARCHAR DEFAULT '[]',
+    vm_group VARCHAR DEFAULT '',
+    hosts VARCHAR DEFAULT '[]',
+    host_group VARCHAR DEFAULT '',
+    PRIMARY KEY (cluster, name)
+);
@@ -0,0 +1,10 @@
+-- Bounded window of vCenter events and triggered alarms related to VMs and hosts.
+CREATE TABLE IF NOT EXISTS vcenter_events (
+    kind VARCHAR NOT NULL,
+    type VARCHAR NOT NULL,
+    severity VARCHAR NOT NULL,
+    vm_id VARCHAR DEFAULT '',
+    host_id VARCHAR DEFAULT '',
+    message VARCHAR DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,10 @@
+-- Recent datastore performance sampled from the hosts during collection.
+CREATE TABLE IF NOT EXISTS datastore_stats (
+    datastore_id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    read_kbps BIGINT DEFAULT 0,
+    write_kbps BIGINT DEFAULT 0,
+    read_latency_ms BIGINT DEFAULT 0,
+    write_latency_ms BIGINT DEFAULT 0,
+    sampled_at TIMESTAMP NOT NULL
+);
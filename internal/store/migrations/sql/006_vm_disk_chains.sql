@@ -0,0 +1,10 @@
+-- Backing chain of every virtual disk, read from the VM file layout.
+CREATE TABLE IF NOT EXISTS vm_disk_chains (
+    "VM ID" VARCHAR NOT NULL,
+    disk_key INTEGER NOT NULL,
+    file VARCHAR NOT NULL,
+    base_file VARCHAR NOT NULL,
+    depth INTEGER NOT NULL,
+    linked_clone BOOLEAN DEFAULT false,
+    PRIMARY KEY ("VM ID", disk_key)
+);
@@ -0,0 +1,4 @@
+-- Versioned configuration document. agent_mode is still written for the
+-- agents predating it; rows of those agents keep version 1 until upgraded.
+ALTER TABLE configuration ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 1;
+ALTER TABLE configuration ADD COLUMN IF NOT EXISTS data VARCHAR;
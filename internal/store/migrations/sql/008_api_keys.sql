@@ -0,0 +1,10 @@
+-- API keys of the local integrations. Only the SHA-256 of a key is stored,
+-- revoked keys are kept with their revocation time.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    prefix VARCHAR NOT NULL,
+    hash VARCHAR NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
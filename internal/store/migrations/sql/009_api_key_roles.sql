@@ -0,0 +1,2 @@
+-- Role of each API key. Keys created before roles keep the full access they had.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR DEFAULT 'operator';
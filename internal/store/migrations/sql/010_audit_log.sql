@@ -0,0 +1,10 @@
+-- Bounded log of the mutating requests of the local API and who sent them.
+CREATE TABLE IF NOT EXISTS audit_log (
+    created_at TIMESTAMP NOT NULL,
+    subject VARCHAR NOT NULL,
+    role VARCHAR DEFAULT '',
+    method VARCHAR NOT NULL,
+    path VARCHAR NOT NULL,
+    status INTEGER NOT NULL,
+    request_id VARCHAR DEFAULT ''
+);
@@ -0,0 +1,8 @@
+-- Failed logins of the local API per client IP, kept across restarts so a
+-- restart does not lift a lockout.
+CREATE TABLE IF NOT EXISTS login_failures (
+    ip VARCHAR PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failure TIMESTAMP NOT NULL,
+    blocked_until TIMESTAMP NOT NULL
+);
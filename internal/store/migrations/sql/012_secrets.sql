@@ -0,0 +1,8 @@
+-- Secrets of the agent, such as the vCenter credentials, encrypted with
+-- AES-256-GCM by the agent: the key is never stored in the database.
+CREATE TABLE IF NOT EXISTS secrets (
+    name VARCHAR PRIMARY KEY,
+    nonce BLOB NOT NULL,
+    ciphertext BLOB NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Bounded log of the lifecycle events of the agent (collections, console
+-- dispatch results, mode changes, inspections).
+CREATE TABLE IF NOT EXISTS agent_events (
+    created_at TIMESTAMP NOT NULL,
+    type VARCHAR NOT NULL,
+    message VARCHAR NOT NULL,
+    details VARCHAR DEFAULT '{}'
+);
@@ -0,0 +1,11 @@
+-- Metadata of the concerns, read from the annotations of the policy rules and
+-- replaced whenever the policies are loaded. Rows are keyed by the concern ID
+-- of the concerns table.
+CREATE TABLE IF NOT EXISTS concern_metadata (
+    concern_id VARCHAR PRIMARY KEY,
+    title VARCHAR DEFAULT '',
+    severity VARCHAR DEFAULT '',
+    category VARCHAR DEFAULT '',
+    remediation VARCHAR DEFAULT '',
+    docs_url VARCHAR DEFAULT ''
+);
@@ -0,0 +1,10 @@
+-- Bounded log of the decisions of the policy evaluations: the rules that
+-- flagged a VM, or its pass, with the duration of the evaluation.
+CREATE TABLE IF NOT EXISTS policy_decisions (
+    created_at TIMESTAMP NOT NULL,
+    vm_id VARCHAR NOT NULL,
+    vm_name VARCHAR,
+    rule VARCHAR,
+    decision VARCHAR NOT NULL,
+    duration_us BIGINT NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Identity given by the console to an agent registered with a provisioning
+-- token, in place of a configured agent and source id. At most one row.
+CREATE TABLE IF NOT EXISTS registration (
+    id INTEGER PRIMARY KEY,
+    agent_id VARCHAR NOT NULL,
+    source_id VARCHAR NOT NULL,
+    registered_at TIMESTAMP NOT NULL
+);
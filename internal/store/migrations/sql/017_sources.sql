@@ -0,0 +1,7 @@
+-- Sources added to the agent besides its primary source, each collected from
+-- its own vCenter into its own database.
+CREATE TABLE IF NOT EXISTS sources (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
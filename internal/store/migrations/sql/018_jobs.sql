@@ -0,0 +1,13 @@
+-- Long-running operations of the agent (collections, inspections) with their
+-- state and progress, bounded to the newest ones.
+CREATE TABLE IF NOT EXISTS jobs (
+    id VARCHAR PRIMARY KEY,
+    kind VARCHAR NOT NULL,
+    state VARCHAR NOT NULL,
+    phase VARCHAR NOT NULL DEFAULT '',
+    progress INTEGER NOT NULL DEFAULT 0,
+    error VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
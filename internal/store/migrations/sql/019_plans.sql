@@ -0,0 +1,8 @@
+-- Migration plans generated by the agent, each kept as the JSON document it
+-- was generated as.
+CREATE TABLE IF NOT EXISTS plans (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    plan VARCHAR NOT NULL
+);
@@ -0,0 +1,7 @@
+-- Throughput measured by the benchmark jobs, each kept as the JSON document of
+-- its measures.
+CREATE TABLE IF NOT EXISTS benchmarks (
+    id VARCHAR PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    benchmark VARCHAR NOT NULL
+);
@@ -0,0 +1,20 @@
+-- Key the DRS rules by the managed object ID of their cluster: clusters of
+-- different datacenters may share a name, and so may their rules. The rules
+-- stored until the next collection keep their cluster name as id.
+CREATE TABLE cluster_rules_by_id (
+    cluster_id VARCHAR NOT NULL,
+    cluster VARCHAR NOT NULL,
+    name VARCHAR NOT NULL,
+    type VARCHAR NOT NULL,
+    enabled BOOLEAN DEFAULT false,
+    mandatory BOOLEAN DEFAULT false,
+    vms VARCHAR DEFAULT '[]',
+    vm_group VARCHAR DEFAULT '',
+    hosts VARCHAR DEFAULT '[]',
+    host_group VARCHAR DEFAULT '',
+    PRIMARY KEY (cluster_id, name)
+);
+INSERT INTO cluster_rules_by_id
+    SELECT cluster, cluster, name, type, enabled, mandatory, vms, vm_group, hosts, host_group FROM cluster_rules;
+DROP TABLE cluster_rules;
+ALTER TABLE cluster_rules_by_id RENAME TO cluster_rules;
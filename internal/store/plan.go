@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for plans table
+const (
+	plansTable        = "plans"
+	plansColID        = "id"
+	plansColName      = "name"
+	plansColCreatedAt = "created_at"
+	plansColPlan      = "plan"
+)
+
+type PlanStore struct {
+	db QueryInterceptor
+}
+
+func NewPlanStore(db QueryInterceptor) *PlanStore {
+	return &PlanStore{db: db}
+}
+
+// Create stores plan.
+func (s *PlanStore) Create(ctx context.Context, plan models.Plan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("marshaling plan: %w", err)
+	}
+
+	query, args, err := sq.Insert(plansTable).
+		Columns(plansColID, plansColName, plansColCreatedAt, plansColPlan).
+		Values(plan.ID, plan.Name, plan.CreatedAt.UTC(), string(data)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building plan insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting plan: %w", err)
+	}
+	return nil
+}
+
+// Get returns the plan id, a ResourceNotFoundError when there is no such plan.
+func (s *PlanStore) Get(ctx context.Context, id string) (*models.Plan, error) {
+	query, args, err := sq.Select(plansColPlan).From(plansTable).Where(sq.Eq{plansColID: id}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building plan query: %w", err)
+	}
+
+	plan, err := scanPlan(s.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, srvErrors.NewResourceNotFoundError("plan", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// List returns the plans, newest first.
+func (s *PlanStore) List(ctx context.Context) ([]models.Plan, error) {
+	query, args, err := sq.Select(plansColPlan).
+		From(plansTable).
+		OrderBy(plansColCreatedAt+" DESC", plansColID).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building plans query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []models.Plan{}
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	return plans, rows.Err()
+}
+
+// Delete removes the plan id. It fails with a ResourceNotFoundError when there
+// is no such plan.
+func (s *PlanStore) Delete(ctx context.Context, id string) error {
+	query, args, err := sq.Delete(plansTable).Where(sq.Eq{plansColID: id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("building plan delete: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("deleting plan: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return srvErrors.NewResourceNotFoundError("plan", id)
+	}
+	return nil
+}
+
+func scanPlan(row interface{ Scan(...any) error }) (models.Plan, error) {
+	var (
+		plan models.Plan
+		data string
+	)
+	if err := row.Scan(&data); err != nil {
+		return models.Plan{}, err
+	}
+	if err := json.Unmarshal([]byte(data), &plan); err != nil {
+		return models.Plan{}, fmt.Errorf("unmarshaling plan: %w", err)
+	}
+	return plan, nil
+}
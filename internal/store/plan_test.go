@@ -0,0 +1,106 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("PlanStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a stored plan
+	// When we get it
+	// Then its waves and estimates should be the stored ones
+	It("should get a stored plan", func() {
+		// Arrange
+		plan := models.Plan{
+			ID:               "p-1",
+			Name:             "first",
+			CreatedAt:        time.Now().UTC().Truncate(time.Second),
+			ThroughputMBps:   250,
+			ThroughputSource: models.PlanThroughputRequest,
+			Waves: []models.PlanWave{{
+				Name:              "wave-1",
+				VMs:               []models.PlanVM{{Order: 1, ID: "vm-1", DiskMB: 1000, EstimatedTransfer: 4 * time.Second}},
+				Resources:         models.PlanResources{VMs: 1, StorageMB: 1000},
+				EstimatedTransfer: 4 * time.Second,
+			}},
+			Resources:         models.PlanResources{VMs: 1, StorageMB: 1000},
+			EstimatedTransfer: 4 * time.Second,
+		}
+		Expect(s.Plan().Create(ctx, plan)).To(Succeed())
+
+		// Act
+		got, err := s.Plan().Get(ctx, "p-1")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*got).To(Equal(plan))
+	})
+
+	// Given two plans created one after the other
+	// When we list the plans
+	// Then the newest should come first
+	It("should list the plans newest first", func() {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+		Expect(s.Plan().Create(ctx, models.Plan{ID: "p-1", CreatedAt: at})).To(Succeed())
+		Expect(s.Plan().Create(ctx, models.Plan{ID: "p-2", CreatedAt: at.Add(time.Minute)})).To(Succeed())
+
+		// Act
+		plans, err := s.Plan().List(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plans).To(HaveLen(2))
+		Expect(plans[0].ID).To(Equal("p-2"))
+		Expect(plans[1].ID).To(Equal("p-1"))
+	})
+
+	// Given a deleted plan
+	// When we get or delete it again
+	// Then both should fail with a not found error
+	It("should delete a plan once", func() {
+		// Arrange
+		Expect(s.Plan().Create(ctx, models.Plan{ID: "p-1", CreatedAt: time.Now()})).To(Succeed())
+		Expect(s.Plan().Delete(ctx, "p-1")).To(Succeed())
+
+		// Act
+		_, getErr := s.Plan().Get(ctx, "p-1")
+		deleteErr := s.Plan().Delete(ctx, "p-1")
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(getErr)).To(BeTrue())
+		Expect(srvErrors.IsResourceNotFoundError(deleteErr)).To(BeTrue())
+	})
+})
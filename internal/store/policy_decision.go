@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Column name constants for policy_decisions table
+const (
+	policyDecisionsTable         = "policy_decisions"
+	policyDecisionsColCreatedAt  = "created_at"
+	policyDecisionsColVMID       = "vm_id"
+	policyDecisionsColVMName     = "vm_name"
+	policyDecisionsColRule       = "rule"
+	policyDecisionsColDecision   = "decision"
+	policyDecisionsColDurationUs = "duration_us"
+)
+
+// maxPolicyDecisions bounds the number of decisions kept.
+const maxPolicyDecisions = 50000
+
+type PolicyDecisionStore struct {
+	db QueryInterceptor
+}
+
+func NewPolicyDecisionStore(db QueryInterceptor) *PolicyDecisionStore {
+	return &PolicyDecisionStore{db: db}
+}
+
+// Insert appends decisions, keeping only the most recent maxPolicyDecisions.
+func (s *PolicyDecisionStore) Insert(ctx context.Context, decisions ...models.PolicyDecision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(policyDecisionsTable).
+		Columns(policyDecisionsColCreatedAt, policyDecisionsColVMID, policyDecisionsColVMName,
+			policyDecisionsColRule, policyDecisionsColDecision, policyDecisionsColDurationUs)
+	for _, d := range decisions {
+		builder = builder.Values(d.Time.UTC(), d.VMID, d.VMName, d.Rule, string(d.Decision), d.Duration.Microseconds())
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("building policy decision insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting policy decisions: %w", err)
+	}
+
+	// keep the log bounded: drop everything older than the newest maxPolicyDecisions
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE %[2]s < (
+			SELECT MIN(%[2]s) FROM (SELECT %[2]s FROM %[1]s ORDER BY %[2]s DESC LIMIT %[3]d)
+		)`, policyDecisionsTable, policyDecisionsColCreatedAt, maxPolicyDecisions)); err != nil {
+		return fmt.Errorf("trimming policy decisions: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the page at cursor of the decisions matching filter, newest first.
+func (s *PolicyDecisionStore) List(ctx context.Context, filter models.PolicyDecisionFilter, cursor models.Cursor) ([]models.PolicyDecision, error) {
+	builder := sq.Select(
+		policyDecisionsColCreatedAt,
+		policyDecisionsColVMID,
+		`COALESCE(`+policyDecisionsColVMName+`, '')`,
+		`COALESCE(`+policyDecisionsColRule+`, '')`,
+		policyDecisionsColDecision,
+		policyDecisionsColDurationUs,
+	).From(policyDecisionsTable).
+		OrderBy(policyDecisionsColCreatedAt + " DESC")
+
+	query, args, err := WithCursor(cursor)(applyPolicyDecisionFilter(builder, filter)).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building policy decisions query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decisions := []models.PolicyDecision{}
+	for rows.Next() {
+		var (
+			d          models.PolicyDecision
+			durationUs int64
+		)
+		if err := rows.Scan(&d.Time, &d.VMID, &d.VMName, &d.Rule, &d.Decision, &durationUs); err != nil {
+			return nil, err
+		}
+		d.Duration = time.Duration(durationUs) * time.Microsecond
+		decisions = append(decisions, d)
+	}
+
+	return decisions, rows.Err()
+}
+
+// Count returns the number of decisions matching filter.
+func (s *PolicyDecisionStore) Count(ctx context.Context, filter models.PolicyDecisionFilter) (int, error) {
+	query, args, err := applyPolicyDecisionFilter(sq.Select("COUNT(*)").From(policyDecisionsTable), filter).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("building policy decisions count query: %w", err)
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func applyPolicyDecisionFilter(builder sq.SelectBuilder, filter models.PolicyDecisionFilter) sq.SelectBuilder {
+	if filter.VMID != "" {
+		builder = builder.Where(sq.Eq{policyDecisionsColVMID: filter.VMID})
+	}
+	if filter.Rule != "" {
+		builder = builder.Where(sq.Eq{policyDecisionsColRule: filter.Rule})
+	}
+	if filter.Decision != "" {
+		builder = builder.Where(sq.Eq{policyDecisionsColDecision: string(filter.Decision)})
+	}
+	if !filter.Since.IsZero() {
+		builder = builder.Where(sq.GtOrEq{policyDecisionsColCreatedAt: filter.Since.UTC()})
+	}
+	return builder
+}
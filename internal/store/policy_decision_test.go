@@ -0,0 +1,112 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("PolicyDecisionStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+		at  time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		at = time.Now().UTC().Truncate(time.Second)
+		Expect(s.PolicyDecision().Insert(ctx,
+			models.PolicyDecision{Time: at, VMID: "vm-1", VMName: "db-1", Rule: "test.cpu", Decision: models.PolicyDecisionFlagged, Duration: 1500 * time.Microsecond},
+			models.PolicyDecision{Time: at.Add(time.Minute), VMID: "vm-2", VMName: "web-1", Decision: models.PolicyDecisionPassed, Duration: 800 * time.Microsecond},
+			models.PolicyDecision{Time: at.Add(2 * time.Minute), VMID: "vm-1", VMName: "db-1", Decision: models.PolicyDecisionPassed, Duration: time.Millisecond},
+		)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given three recorded decisions
+	// When we list them
+	// Then they should be returned newest first with their duration
+	It("should list the decisions newest first", func() {
+		// Act
+		decisions, err := s.PolicyDecision().List(ctx, models.PolicyDecisionFilter{}, models.Cursor{Limit: 100})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decisions).To(HaveLen(3))
+		Expect(decisions[0].VMID).To(Equal("vm-1"))
+		Expect(decisions[0].Decision).To(Equal(models.PolicyDecisionPassed))
+		Expect(decisions[2].Rule).To(Equal("test.cpu"))
+		Expect(decisions[2].VMName).To(Equal("db-1"))
+		Expect(decisions[2].Duration).To(Equal(1500 * time.Microsecond))
+		Expect(decisions[2].Time.Equal(at)).To(BeTrue())
+	})
+
+	// Given decisions of two VMs
+	// When we filter them by VM and decision
+	// Then only the matching decisions should be returned and counted
+	It("should filter the decisions", func() {
+		// Arrange
+		filter := models.PolicyDecisionFilter{VMID: "vm-1", Decision: models.PolicyDecisionFlagged}
+
+		// Act
+		decisions, err := s.PolicyDecision().List(ctx, filter, models.Cursor{Limit: 100})
+		Expect(err).NotTo(HaveOccurred())
+		count, err := s.PolicyDecision().Count(ctx, filter)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+		Expect(decisions).To(HaveLen(1))
+		Expect(decisions[0].Rule).To(Equal("test.cpu"))
+	})
+
+	// Given decisions of two rules
+	// When we filter them by rule and time
+	// Then the decisions of other rules or older should be left out
+	It("should filter the decisions by rule and time", func() {
+		// Act
+		byRule, err := s.PolicyDecision().Count(ctx, models.PolicyDecisionFilter{Rule: "test.cpu"})
+		Expect(err).NotTo(HaveOccurred())
+		since, err := s.PolicyDecision().Count(ctx, models.PolicyDecisionFilter{Since: at.Add(time.Minute)})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(byRule).To(Equal(1))
+		Expect(since).To(Equal(2))
+	})
+
+	// Given three recorded decisions
+	// When we list the second page of one decision
+	// Then the second most recent decision should be returned
+	It("should list the page at the cursor", func() {
+		// Act
+		decisions, err := s.PolicyDecision().List(ctx, models.PolicyDecisionFilter{}, models.NewCursor(2, 1))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decisions).To(HaveLen(1))
+		Expect(decisions[0].VMID).To(Equal("vm-2"))
+	})
+})
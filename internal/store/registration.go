@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for registration table
+const (
+	registrationTable           = "registration"
+	registrationColID           = "id"
+	registrationColAgentID      = "agent_id"
+	registrationColSourceID     = "source_id"
+	registrationColRegisteredAt = "registered_at"
+)
+
+type RegistrationStore struct {
+	db QueryInterceptor
+}
+
+func NewRegistrationStore(db QueryInterceptor) *RegistrationStore {
+	return &RegistrationStore{db: db}
+}
+
+// Get returns the registration of the agent, a ResourceNotFoundError when it
+// did not register.
+func (s *RegistrationStore) Get(ctx context.Context) (*models.Registration, error) {
+	query, args, err := sq.Select(registrationColAgentID, registrationColSourceID, registrationColRegisteredAt).
+		From(registrationTable).
+		Where(sq.Eq{registrationColID: 1}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building registration query: %w", err)
+	}
+
+	var r models.Registration
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&r.AgentID, &r.SourceID, &r.RegisteredAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, srvErrors.NewResourceNotFoundError("registration", "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Save stores the registration of the agent, replacing the previous one.
+func (s *RegistrationStore) Save(ctx context.Context, r models.Registration) error {
+	query, args, err := sq.Insert(registrationTable).
+		Columns(registrationColID, registrationColAgentID, registrationColSourceID, registrationColRegisteredAt).
+		Values(1, r.AgentID, r.SourceID, r.RegisteredAt.UTC()).
+		Suffix("ON CONFLICT (" + registrationColID + ") DO UPDATE SET " +
+			registrationColAgentID + " = EXCLUDED." + registrationColAgentID + ", " +
+			registrationColSourceID + " = EXCLUDED." + registrationColSourceID + ", " +
+			registrationColRegisteredAt + " = EXCLUDED." + registrationColRegisteredAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building registration upsert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("saving registration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("RegistrationStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given an agent which never registered
+	// When we get its registration
+	// Then it should fail with a not found error
+	It("should return not found before the registration", func() {
+		// Act
+		_, err := s.Registration().Get(ctx)
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+
+	// Given a registration saved twice
+	// When we get the registration
+	// Then the last one should be returned
+	It("should replace the registration", func() {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+		Expect(s.Registration().Save(ctx, models.Registration{AgentID: "a-1", SourceID: "s-1", RegisteredAt: at})).To(Succeed())
+		Expect(s.Registration().Save(ctx, models.Registration{AgentID: "a-2", SourceID: "s-2", RegisteredAt: at.Add(time.Minute)})).To(Succeed())
+
+		// Act
+		r, err := s.Registration().Get(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.AgentID).To(Equal("a-2"))
+		Expect(r.SourceID).To(Equal("s-2"))
+		Expect(r.RegisteredAt.Equal(at.Add(time.Minute))).To(BeTrue())
+	})
+})
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for secrets table
+const (
+	secretsTable         = "secrets"
+	secretsColName       = "name"
+	secretsColNonce      = "nonce"
+	secretsColCiphertext = "ciphertext"
+	secretsColUpdatedAt  = "updated_at"
+)
+
+// SecretKeySize is the size of the keys of EncryptedSecretStore, AES-256.
+const SecretKeySize = 32
+
+// EncryptedSecretStore keeps the secrets in the database, encrypted with
+// AES-256-GCM. The name of a secret is authenticated with it, so a value
+// cannot be moved to another name.
+type EncryptedSecretStore struct {
+	db   QueryInterceptor
+	aead cipher.AEAD
+}
+
+// NewEncryptedSecretStore returns a store encrypting the secrets with key, of
+// SecretKeySize bytes.
+func NewEncryptedSecretStore(db QueryInterceptor, key []byte) (*EncryptedSecretStore, error) {
+	if len(key) != SecretKeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", SecretKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedSecretStore{db: db, aead: aead}, nil
+}
+
+// Get returns the secret name, or a ResourceNotFoundError when there is none.
+// It fails when the secret was encrypted with another key.
+func (s *EncryptedSecretStore) Get(ctx context.Context, name string) ([]byte, error) {
+	query, args, err := sq.Select(secretsColNonce, secretsColCiphertext).
+		From(secretsTable).
+		Where(sq.Eq{secretsColName: name}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building secret query: %w", err)
+	}
+
+	var nonce, ciphertext []byte
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&nonce, &ciphertext); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, srvErrors.NewResourceNotFoundError("secret", name)
+		}
+		return nil, err
+	}
+
+	value, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting secret %q: %w", name, err)
+	}
+	return value, nil
+}
+
+// Put stores value as the secret name, replacing the previous one.
+func (s *EncryptedSecretStore) Put(ctx context.Context, name string, value []byte) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	ciphertext := s.aead.Seal(nil, nonce, value, []byte(name))
+
+	query, args, err := sq.Insert(secretsTable).
+		Columns(secretsColName, secretsColNonce, secretsColCiphertext, secretsColUpdatedAt).
+		Values(name, nonce, ciphertext, time.Now().UTC()).
+		Suffix("ON CONFLICT (" + secretsColName + ") DO UPDATE SET " +
+			secretsColNonce + " = EXCLUDED." + secretsColNonce + ", " +
+			secretsColCiphertext + " = EXCLUDED." + secretsColCiphertext + ", " +
+			secretsColUpdatedAt + " = EXCLUDED." + secretsColUpdatedAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building secret upsert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("saving secret: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the secret name. Deleting an unknown name is not an error.
+func (s *EncryptedSecretStore) Delete(ctx context.Context, name string) error {
+	query, args, err := sq.Delete(secretsTable).Where(sq.Eq{secretsColName: name}).ToSql()
+	if err != nil {
+		return fmt.Errorf("building secret delete: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("deleting secret: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("EncryptedSecretStore", func() {
+	var (
+		ctx     context.Context
+		s       *store.Store
+		db      *sql.DB
+		key     []byte
+		secrets *store.EncryptedSecretStore
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		key = bytes.Repeat([]byte{1}, store.SecretKeySize)
+		secrets, err = s.Secrets(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a secret put twice
+	// When we get it
+	// Then the last value should be returned while only its ciphertext is stored
+	It("should store the secrets encrypted", func() {
+		// Arrange
+		Expect(secrets.Put(ctx, "vcenter-credentials", []byte("first-password"))).To(Succeed())
+		Expect(secrets.Put(ctx, "vcenter-credentials", []byte("second-password"))).To(Succeed())
+
+		// Act
+		value, err := secrets.Get(ctx, "vcenter-credentials")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(value)).To(Equal("second-password"))
+		var ciphertext []byte
+		Expect(db.QueryRow("SELECT ciphertext FROM secrets WHERE name = 'vcenter-credentials'").Scan(&ciphertext)).To(Succeed())
+		Expect(string(ciphertext)).NotTo(ContainSubstring("password"))
+	})
+
+	// Given a secret encrypted with another key
+	// When we get it
+	// Then it should fail to decrypt
+	It("should fail to decrypt with another key", func() {
+		// Arrange
+		Expect(secrets.Put(ctx, "vcenter-credentials", []byte("password"))).To(Succeed())
+		other, err := s.Secrets(bytes.Repeat([]byte{2}, store.SecretKeySize))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		_, err = other.Get(ctx, "vcenter-credentials")
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("decrypting secret")))
+	})
+
+	// Given a deleted secret
+	// When we get it
+	// Then a not found error should be returned
+	It("should delete secrets", func() {
+		// Arrange
+		Expect(secrets.Put(ctx, "vcenter-credentials", []byte("password"))).To(Succeed())
+		Expect(secrets.Delete(ctx, "vcenter-credentials")).To(Succeed())
+
+		// Act
+		_, err := secrets.Get(ctx, "vcenter-credentials")
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+	})
+
+	// Given a key of the wrong size
+	// When we open the secrets
+	// Then it should fail
+	It("should reject keys of the wrong size", func() {
+		// Act
+		_, err := s.Secrets([]byte("short"))
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("secret key must be 32 bytes")))
+	})
+})
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// Column name constants for sources table
+const (
+	sourcesTable        = "sources"
+	sourcesColID        = "id"
+	sourcesColName      = "name"
+	sourcesColCreatedAt = "created_at"
+)
+
+type SourceStore struct {
+	db QueryInterceptor
+}
+
+func NewSourceStore(db QueryInterceptor) *SourceStore {
+	return &SourceStore{db: db}
+}
+
+// Create stores source.
+func (s *SourceStore) Create(ctx context.Context, source models.Source) error {
+	query, args, err := sq.Insert(sourcesTable).
+		Columns(sourcesColID, sourcesColName, sourcesColCreatedAt).
+		Values(source.ID, source.Name, source.CreatedAt.UTC()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building source insert: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("inserting source: %w", err)
+	}
+	return nil
+}
+
+// List returns the sources ordered by creation time.
+func (s *SourceStore) List(ctx context.Context) ([]models.Source, error) {
+	query, args, err := sq.Select(sourcesColID, sourcesColName, sourcesColCreatedAt).
+		From(sourcesTable).
+		OrderBy(sourcesColCreatedAt, sourcesColID).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building sources query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := []models.Source{}
+	for rows.Next() {
+		var source models.Source
+		if err := rows.Scan(&source.ID, &source.Name, &source.CreatedAt); err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, rows.Err()
+}
+
+// Delete removes the source id. It fails with a ResourceNotFoundError when
+// there is no such source.
+func (s *SourceStore) Delete(ctx context.Context, id string) error {
+	query, args, err := sq.Delete(sourcesTable).Where(sq.Eq{sourcesColID: id}).ToSql()
+	if err != nil {
+		return fmt.Errorf("building source delete: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("deleting source: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return srvErrors.NewResourceNotFoundError("source", id)
+	}
+	return nil
+}
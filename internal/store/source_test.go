@@ -0,0 +1,92 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("SourceStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given two sources created one after the other
+	// When we list the sources
+	// Then both should be returned in creation order
+	It("should list the sources in creation order", func() {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+		Expect(s.Source().Create(ctx, models.Source{ID: "s-2", Name: "second", CreatedAt: at.Add(time.Minute)})).To(Succeed())
+		Expect(s.Source().Create(ctx, models.Source{ID: "s-1", Name: "first", CreatedAt: at})).To(Succeed())
+
+		// Act
+		sources, err := s.Source().List(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sources).To(HaveLen(2))
+		Expect(sources[0].ID).To(Equal("s-1"))
+		Expect(sources[0].Name).To(Equal("first"))
+		Expect(sources[1].ID).To(Equal("s-2"))
+	})
+
+	// Given a source
+	// When we create it again
+	// Then it should fail
+	It("should reject a duplicate source", func() {
+		// Arrange
+		Expect(s.Source().Create(ctx, models.Source{ID: "s-1", CreatedAt: time.Now()})).To(Succeed())
+
+		// Act
+		err := s.Source().Create(ctx, models.Source{ID: "s-1", CreatedAt: time.Now()})
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+
+	// Given a deleted source
+	// When we delete it again
+	// Then it should fail with a not found error
+	It("should delete a source once", func() {
+		// Arrange
+		Expect(s.Source().Create(ctx, models.Source{ID: "s-1", CreatedAt: time.Now()})).To(Succeed())
+		Expect(s.Source().Delete(ctx, "s-1")).To(Succeed())
+
+		// Act
+		err := s.Source().Delete(ctx, "s-1")
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		sources, err := s.Source().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sources).To(BeEmpty())
+	})
+})
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// Stats returns the size of the database and the estimated number of rows of
+// each of its tables, ordered by name.
+func (s *Store) Stats(ctx context.Context) (*models.DatabaseStats, error) {
+	query, args, err := sq.Select("database_size", "wal_size").
+		From("pragma_database_size()").
+		Where("database_name = current_database()").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building database size query: %w", err)
+	}
+
+	var stats models.DatabaseStats
+	if err := s.qi.QueryRowContext(ctx, query, args...).Scan(&stats.Size, &stats.WALSize); err != nil {
+		return nil, fmt.Errorf("getting database size: %w", err)
+	}
+
+	query, args, err = sq.Select("table_name", "estimated_size").
+		From("duckdb_tables()").
+		Where("database_name = current_database()").
+		OrderBy("table_name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building tables query: %w", err)
+	}
+
+	rows, err := s.qi.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	defer rows.Close()
+
+	stats.Tables = []models.TableStats{}
+	for rows.Next() {
+		var t models.TableStats
+		if err := rows.Scan(&t.Name, &t.Rows); err != nil {
+			return nil, err
+		}
+		stats.Tables = append(stats.Tables, t)
+	}
+
+	return &stats, rows.Err()
+}
@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("Stats", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	// Given a migrated database with a stored login failure
+	// When we get the database stats
+	// Then it should return its size and its tables with their rows
+	It("should return the size and the tables of the database", func() {
+		// Arrange
+		at := time.Now().UTC()
+		Expect(s.LoginFailure().Save(ctx, models.LoginFailures{IP: "10.0.0.1", Count: 1, LastFailure: at, BlockedUntil: at})).To(Succeed())
+
+		// Act
+		stats, err := s.Stats(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Size).NotTo(BeEmpty())
+		Expect(stats.Tables).To(ContainElement(models.TableStats{Name: "login_failures", Rows: 1}))
+		Expect(stats.Tables).To(ContainElement(HaveField("Name", "agent_events")))
+	})
+})
@@ -3,31 +3,73 @@ package store
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/kubev2v/migration-planner/pkg/duckdb_parser"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 )
 
 type Store struct {
 	db            *sql.DB
+	qi            QueryInterceptor
 	parser        *duckdb_parser.Parser
+	validator     duckdb_parser.Validator
 	configuration *ConfigurationStore
 	inventory     *InventoryStore
 	vm            *VMStore
 	inspection    *InspectionStore
+	vmSecurity    *VMSecurityStore
+	cluster       *ClusterStore
+	event         *EventStore
+	datastore     *DatastoreStore
+	infra         *InfrastructureStore
+	diskChain     *DiskChainStore
+	apiKey        *APIKeyStore
+	audit         *AuditStore
+	loginFailure  *LoginFailureStore
+	agentEvent    *AgentEventStore
+	concernMeta   *ConcernMetadataStore
+	decision      *PolicyDecisionStore
+	registration  *RegistrationStore
+	source        *SourceStore
+	job           *JobStore
+	plan          *PlanStore
+	benchmark     *BenchmarkStore
 }
 
 func NewStore(db *sql.DB, validator duckdb_parser.Validator) *Store {
 	qi := newQueryInterceptor(db)
 	parser := duckdb_parser.New(db, validator)
+	diskChain := NewDiskChainStore(qi)
 	return &Store{
 		db:            db,
+		qi:            qi,
 		parser:        parser,
+		validator:     validator,
 		configuration: NewConfigurationStore(qi),
 		inventory:     NewInventoryStore(qi),
-		vm:            NewVMStore(qi, parser),
+		vm:            NewVMStore(qi, parser, diskChain),
 		inspection:    NewInspectionStore(qi),
+		vmSecurity:    NewVMSecurityStore(qi),
+		cluster:       NewClusterStore(qi, parser),
+		event:         NewEventStore(qi),
+		datastore:     NewDatastoreStore(qi),
+		infra:         NewInfrastructureStore(parser),
+		diskChain:     diskChain,
+		apiKey:        NewAPIKeyStore(qi),
+		audit:         NewAuditStore(qi),
+		loginFailure:  NewLoginFailureStore(qi),
+		agentEvent:    NewAgentEventStore(qi),
+		concernMeta:   NewConcernMetadataStore(qi),
+		decision:      NewPolicyDecisionStore(qi),
+		registration:  NewRegistrationStore(qi),
+		source:        NewSourceStore(qi),
+		job:           NewJobStore(qi),
+		plan:          NewPlanStore(qi),
+		benchmark:     NewBenchmarkStore(qi),
 	}
 }
 
@@ -40,9 +82,19 @@ func (s *Store) Migrate(ctx context.Context) error {
 		return err
 	}
 
+	// rewrite the configuration of an older agent once the schema is migrated
+	if err := s.configuration.Upgrade(ctx); err != nil {
+		return fmt.Errorf("upgrading configuration: %w", err)
+	}
+
 	return nil
 }
 
+// Migrations returns the status of the local schema migrations.
+func (s *Store) Migrations(ctx context.Context) ([]models.Migration, error) {
+	return migrations.Status(ctx, s.db)
+}
+
 func (s *Store) Parser() *duckdb_parser.Parser {
 	return s.parser
 }
@@ -63,12 +115,101 @@ func (s *Store) Inspection() *InspectionStore {
 	return s.inspection
 }
 
+func (s *Store) VMSecurity() *VMSecurityStore {
+	return s.vmSecurity
+}
+
+func (s *Store) Cluster() *ClusterStore {
+	return s.cluster
+}
+
+func (s *Store) Event() *EventStore {
+	return s.event
+}
+
+func (s *Store) Datastore() *DatastoreStore {
+	return s.datastore
+}
+
+func (s *Store) Infrastructure() *InfrastructureStore {
+	return s.infra
+}
+
+func (s *Store) DiskChain() *DiskChainStore {
+	return s.diskChain
+}
+
+func (s *Store) APIKey() *APIKeyStore {
+	return s.apiKey
+}
+
+func (s *Store) Audit() *AuditStore {
+	return s.audit
+}
+
+func (s *Store) LoginFailure() *LoginFailureStore {
+	return s.loginFailure
+}
+
+func (s *Store) AgentEvent() *AgentEventStore {
+	return s.agentEvent
+}
+
+func (s *Store) ConcernMetadata() *ConcernMetadataStore {
+	return s.concernMeta
+}
+
+func (s *Store) PolicyDecision() *PolicyDecisionStore {
+	return s.decision
+}
+
+func (s *Store) Registration() *RegistrationStore {
+	return s.registration
+}
+
+func (s *Store) Source() *SourceStore {
+	return s.source
+}
+
+func (s *Store) Job() *JobStore {
+	return s.job
+}
+
+func (s *Store) Plan() *PlanStore {
+	return s.plan
+}
+
+func (s *Store) Benchmark() *BenchmarkStore {
+	return s.benchmark
+}
+
+// ExplainSlowQueries logs the EXPLAIN ANALYZE plan of the list queries taking
+// longer than threshold, 0 disabling it. The query runs a second time to be
+// explained, so it is meant to diagnose slow queries rather than to stay on.
+func (s *Store) ExplainSlowQueries(threshold time.Duration) {
+	if qi, ok := s.qi.(*queryInterceptor); ok {
+		qi.explainThreshold.Store(int64(threshold))
+	}
+}
+
+// Secrets returns the secrets of the database, encrypted with key of
+// SecretKeySize bytes.
+func (s *Store) Secrets(key []byte) (*EncryptedSecretStore, error) {
+	return NewEncryptedSecretStore(s.qi, key)
+}
+
 // Checkpoint forces a WAL flush to the main database file.
 func (s *Store) Checkpoint() error {
 	_, err := s.db.Exec("FORCE CHECKPOINT")
 	return err
 }
 
+// Ping tells whether the database answers a query.
+func (s *Store) Ping(ctx context.Context) error {
+	var one int
+	return s.db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
 func (s *Store) Close() error {
 	return s.db.Close()
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 
 	sq "github.com/Masterminds/squirrel"
@@ -13,12 +14,13 @@ import (
 )
 
 type VMStore struct {
-	db     QueryInterceptor
-	parser *duckdb_parser.Parser
+	db        QueryInterceptor
+	parser    *duckdb_parser.Parser
+	diskChain *DiskChainStore
 }
 
-func NewVMStore(db QueryInterceptor, parser *duckdb_parser.Parser) *VMStore {
-	return &VMStore{db: db, parser: parser}
+func NewVMStore(db QueryInterceptor, parser *duckdb_parser.Parser, diskChain *DiskChainStore) *VMStore {
+	return &VMStore{db: db, parser: parser, diskChain: diskChain}
 }
 
 // List returns VM summaries with filters, sorting, and pagination.
@@ -26,17 +28,19 @@ func (s *VMStore) List(ctx context.Context, opts ...ListOption) ([]models.VMSumm
 	builder := sq.Select(
 		`v."VM ID" AS id`,
 		`v."VM" AS name`,
-		`v."Powerstate" AS power_state`,
+		`COALESCE(v."Powerstate", '') AS power_state`,
 		`COALESCE(v."Cluster", '') AS cluster`,
 		`v."Memory" AS memory`,
 		`COALESCE(d.total_disk, 0) AS disk_size`,
 		`COALESCE(c.issue_count, 0) AS issue_count`,
 		`COALESCE(i.status, 'not_found') AS status`,
 		`COALESCE(i.error, '') AS error`,
+		`COALESCE(sec.encrypted, false) AS encrypted`,
 	).From("vinfo v").
 		LeftJoin(`(SELECT "VM_ID", COUNT(*) AS issue_count FROM concerns GROUP BY "VM_ID") c ON v."VM ID" = c."VM_ID"`).
 		LeftJoin(`(SELECT "VM ID", SUM("Capacity MiB") AS total_disk FROM vdisk GROUP BY "VM ID") d ON v."VM ID" = d."VM ID"`).
-		LeftJoin(`vm_inspection_status i ON v."VM ID" = i."VM ID"`)
+		LeftJoin(`vm_inspection_status i ON v."VM ID" = i."VM ID"`).
+		LeftJoin(`vm_security sec ON v."VM ID" = sec."VM ID"`)
 
 	for _, opt := range opts {
 		builder = opt(builder)
@@ -67,6 +71,7 @@ func (s *VMStore) List(ctx context.Context, opts ...ListOption) ([]models.VMSumm
 			&vm.IssueCount,
 			&vm.Status.State,
 			&sqlErr,
+			&vm.Encrypted,
 		)
 		if err != nil {
 			return nil, err
@@ -83,7 +88,8 @@ func (s *VMStore) Count(ctx context.Context, opts ...ListOption) (int, error) {
 	builder := sq.Select("COUNT(*)").
 		From("vinfo v").
 		LeftJoin(`(SELECT "VM_ID", COUNT(*) AS issue_count FROM concerns GROUP BY "VM_ID") c ON v."VM ID" = c."VM_ID"`).
-		LeftJoin(`(SELECT "VM ID", SUM("Capacity MiB") AS total_disk FROM vdisk GROUP BY "VM ID") d ON v."VM ID" = d."VM ID"`)
+		LeftJoin(`(SELECT "VM ID", SUM("Capacity MiB") AS total_disk FROM vdisk GROUP BY "VM ID") d ON v."VM ID" = d."VM ID"`).
+		LeftJoin(`vm_security sec ON v."VM ID" = sec."VM ID"`)
 
 	// Apply only WHERE filters, skip ORDER BY/LIMIT/OFFSET
 	for _, opt := range opts {
@@ -113,13 +119,41 @@ func (s *VMStore) Get(ctx context.Context, id string) (*models.VM, error) {
 
 	result := vmFromParser(vms[0])
 
+	query, args, err := sq.Select(securityColEncrypted, securityColTpmEnabled, securityColSecureBoot).
+		From(securityTable).
+		Where(sq.Eq{securityColVmID: id}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&result.Encrypted, &result.TpmEnabled, &result.SecureBoot)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	chains, err := s.diskChain.ListByVM(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chains {
+		for i := range result.Disks {
+			if result.Disks[i].File == c.File {
+				result.Disks[i].ChainDepth = c.Depth
+				result.Disks[i].LinkedClone = c.LinkedClone
+			}
+		}
+	}
+
 	return &result, nil
 }
 
 func vmFromParser(pvm parsermodels.VM) models.VM {
 	issues := make([]string, 0, len(pvm.Concerns))
+	concerns := make([]models.Concern, 0, len(pvm.Concerns))
 	for _, c := range pvm.Concerns {
 		issues = append(issues, c.Label)
+		concerns = append(concerns, models.Concern{ID: c.Id, Label: c.Label, Category: c.Category, Assessment: c.Assessment})
 	}
 
 	disks := make([]models.Disk, 0, len(pvm.Disks))
@@ -157,8 +191,14 @@ func vmFromParser(pvm parsermodels.VM) models.VM {
 		Datacenter:            pvm.Datacenter,
 		Cluster:               pvm.Cluster,
 		CpuCount:              pvm.CpuCount,
+		CpuSockets:            pvm.CpuSockets,
 		CoresPerSocket:        pvm.CoresPerSocket,
+		CpuHotAddEnabled:      pvm.CpuHotAddEnabled,
+		CpuHotRemoveEnabled:   pvm.CpuHotRemoveEnabled,
 		MemoryMB:              pvm.MemoryMB,
+		MemoryHotAddEnabled:   pvm.MemoryHotAddEnabled,
+		NumaNodeAffinity:      pvm.NumaNodeAffinity,
+		HardwareVersion:       pvm.HWVersion,
 		GuestName:             pvm.GuestName,
 		HostName:              pvm.HostName,
 		IPAddress:             pvm.IpAddress,
@@ -169,6 +209,7 @@ func vmFromParser(pvm parsermodels.VM) models.VM {
 		Disks:                 disks,
 		NICs:                  nics,
 		Issues:                issues,
+		Concerns:              concerns,
 	}
 }
 
@@ -231,6 +272,32 @@ func ByMemorySizeRange(min, max int64) ListOption {
 	}
 }
 
+// ByEncrypted filters VMs by whether vSphere VM encryption is enabled.
+func ByEncrypted(encrypted bool) ListOption {
+	return func(b sq.SelectBuilder) sq.SelectBuilder {
+		return b.Where(sq.Eq{`COALESCE(sec.encrypted, false)`: encrypted})
+	}
+}
+
+// ByConcernSeverity filters VMs with at least one concern of the severities
+// (OR logic), the severity of a concern without metadata being its category.
+func ByConcernSeverity(severities ...string) ListOption {
+	return func(b sq.SelectBuilder) sq.SelectBuilder {
+		if len(severities) == 0 {
+			return b
+		}
+		query, args, err := sq.Select(`c."VM_ID"`).
+			From("concerns c").
+			LeftJoin(concernMetadataTable + ` m ON c."Concern_ID" = m.` + concernMetadataColID).
+			Where(sq.Eq{concernSeverity: severities}).
+			ToSql()
+		if err != nil {
+			return b
+		}
+		return b.Where(sq.Expr(`v."VM ID" IN (`+query+`)`, args...))
+	}
+}
+
 // WithLimit sets the LIMIT clause.
 func WithLimit(limit uint64) ListOption {
 	return func(b sq.SelectBuilder) sq.SelectBuilder {
@@ -245,6 +312,20 @@ func WithOffset(offset uint64) ListOption {
 	}
 }
 
+// WithCursor sets the LIMIT and OFFSET clauses of the page at cursor, none
+// for the parts of it which are 0.
+func WithCursor(cursor models.Cursor) ListOption {
+	return func(b sq.SelectBuilder) sq.SelectBuilder {
+		if cursor.Limit > 0 {
+			b = b.Limit(cursor.Limit)
+		}
+		if cursor.Offset > 0 {
+			b = b.Offset(cursor.Offset)
+		}
+		return b
+	}
+}
+
 // apiFieldToDBColumn maps API field names to database column expressions.
 // WithDefaultSort applies default sorting by VM ID.
 func WithDefaultSort() ListOption {
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Column name constants for vm_security table
+const (
+	securityTable         = "vm_security"
+	securityColVmID       = `"VM ID"`
+	securityColEncrypted  = "encrypted"
+	securityColTpmEnabled = "tpm_enabled"
+	securityColSecureBoot = "secure_boot"
+)
+
+// Concern identifiers for the security settings that need special handling on the target.
+const (
+	ConcernEncryption = "vmware.vm.encryption"
+	ConcernVTPM       = "vmware.vm.vtpm"
+	ConcernSecureBoot = "vmware.vm.secure_boot"
+)
+
+type VMSecurityStore struct {
+	db QueryInterceptor
+}
+
+func NewVMSecurityStore(db QueryInterceptor) *VMSecurityStore {
+	return &VMSecurityStore{db: db}
+}
+
+// IngestSqlite replaces the security flags with the vTPM and secure boot settings found
+// in the forklift sqlite database and marks the VMs in encrypted as encrypted.
+func (s *VMSecurityStore) IngestSqlite(ctx context.Context, sqlitePath string, encrypted []string) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", securityTable)); err != nil {
+		return fmt.Errorf("clearing vm security: %w", err)
+	}
+
+	attach := fmt.Sprintf("ATTACH '%s' AS security_src (TYPE sqlite)", strings.ReplaceAll(sqlitePath, "'", "''"))
+	if _, err := s.db.ExecContext(ctx, attach); err != nil {
+		return fmt.Errorf("attaching sqlite database: %w", err)
+	}
+	defer func() { _, _ = s.db.ExecContext(ctx, "DETACH security_src") }()
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s (%s, %s, %s, %s)
+		SELECT v.ID, false, v.TpmEnabled = 1, v.SecureBoot = 1 FROM security_src.VM v
+	`, securityTable, securityColVmID, securityColEncrypted, securityColTpmEnabled, securityColSecureBoot)); err != nil {
+		return fmt.Errorf("inserting vm security: %w", err)
+	}
+
+	if len(encrypted) == 0 {
+		return nil
+	}
+
+	query, args, err := sq.Update(securityTable).
+		Set(securityColEncrypted, true).
+		Where(sq.Eq{securityColVmID: encrypted}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("building encrypted update: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("marking encrypted vms: %w", err)
+	}
+
+	return nil
+}
+
+// AddConcerns appends a concern for every VM that uses encryption, vTPM or secure boot.
+// It must run after the parser filled the concerns table because ingestion appends to it.
+func (s *VMSecurityStore) AddConcerns(ctx context.Context) error {
+	concerns := []struct {
+		column     string
+		id         string
+		label      string
+		category   string
+		assessment string
+	}{
+		{
+			column:     securityColEncrypted,
+			id:         ConcernEncryption,
+			label:      "VM encryption enabled",
+			category:   "Critical",
+			assessment: "The VM is encrypted by vSphere. Decrypt the VM before migration, the disks cannot be read by the migration tooling.",
+		},
+		{
+			column:     securityColTpmEnabled,
+			id:         ConcernVTPM,
+			label:      "vTPM device present",
+			category:   "Warning",
+			assessment: "The VM has a virtual TPM. The TPM state is not migrated and secrets sealed by it (e.g. BitLocker keys) must be recovered on the target.",
+		},
+		{
+			column:     securityColSecureBoot,
+			id:         ConcernSecureBoot,
+			label:      "Secure boot enabled",
+			category:   "Information",
+			assessment: "The VM uses UEFI secure boot. The target VM must be created with EFI firmware and secure boot enabled.",
+		},
+	}
+
+	for _, c := range concerns {
+		query, args, err := sq.Insert("concerns").
+			Columns(`"VM_ID"`, `"Concern_ID"`, `"Label"`, `"Category"`, `"Assessment"`).
+			Select(sq.Select(securityColVmID).
+				Column("?", c.id).
+				Column("?", c.label).
+				Column("?", c.category).
+				Column("?", c.assessment).
+				From(securityTable).
+				Where(c.column)).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("building %s concerns: %w", c.id, err)
+		}
+
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("inserting %s concerns: %w", c.id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
+)
+
+var _ = Describe("VMSecurityStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, test.NewMockValidator())
+
+		for _, id := range []string{"vm-1", "vm-2", "vm-3"} {
+			vm := fixtures.NewVM(id).WithPowerState("poweredOn").WithCluster("cluster-a").WithMemory(1024)
+			Expect(vm.Insert(ctx, db)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	insertSecurity := func(id string, encrypted, tpm, secureBoot bool) {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO vm_security ("VM ID", encrypted, tpm_enabled, secure_boot)
+			VALUES (?, ?, ?, ?)
+		`, id, encrypted, tpm, secureBoot)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	concernIDs := func(vmID string) []string {
+		rows, err := db.QueryContext(ctx, `SELECT "Concern_ID" FROM concerns WHERE "VM_ID" = ? ORDER BY "Concern_ID"`, vmID)
+		Expect(err).NotTo(HaveOccurred())
+		defer rows.Close()
+
+		ids := []string{}
+		for rows.Next() {
+			var id string
+			Expect(rows.Scan(&id)).To(Succeed())
+			ids = append(ids, id)
+		}
+		Expect(rows.Err()).NotTo(HaveOccurred())
+		return ids
+	}
+
+	Context("AddConcerns", func() {
+		// Given VMs with different security settings
+		// When we add the security concerns
+		// Then each enabled setting should produce one concern
+		It("should add a concern per enabled setting", func() {
+			// Arrange
+			insertSecurity("vm-1", true, true, true)
+			insertSecurity("vm-2", false, true, false)
+			insertSecurity("vm-3", false, false, false)
+
+			// Act
+			err := s.VMSecurity().AddConcerns(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(concernIDs("vm-1")).To(ConsistOf(store.ConcernEncryption, store.ConcernVTPM, store.ConcernSecureBoot))
+			Expect(concernIDs("vm-2")).To(ConsistOf(store.ConcernVTPM))
+			Expect(concernIDs("vm-3")).To(BeEmpty())
+		})
+
+		// Given an encrypted VM
+		// When we add the security concerns
+		// Then the encryption concern should be critical
+		It("should mark encryption as critical", func() {
+			// Arrange
+			insertSecurity("vm-1", true, false, false)
+
+			// Act
+			Expect(s.VMSecurity().AddConcerns(ctx)).To(Succeed())
+
+			// Assert
+			var category string
+			err := db.QueryRowContext(ctx, `SELECT "Category" FROM concerns WHERE "VM_ID" = 'vm-1'`).Scan(&category)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(category).To(Equal("Critical"))
+		})
+	})
+
+	Context("VMStore integration", func() {
+		// Given one encrypted VM
+		// When we list VMs filtered by encryption
+		// Then only the encrypted VM should be returned
+		It("should filter VMs by encryption", func() {
+			// Arrange
+			insertSecurity("vm-1", true, false, false)
+			insertSecurity("vm-2", false, true, false)
+
+			// Act
+			encrypted, err := s.VM().List(ctx, store.ByEncrypted(true))
+			Expect(err).NotTo(HaveOccurred())
+			plain, err := s.VM().List(ctx, store.ByEncrypted(false))
+			Expect(err).NotTo(HaveOccurred())
+			count, err := s.VM().Count(ctx, store.ByEncrypted(true))
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(encrypted).To(HaveLen(1))
+			Expect(encrypted[0].ID).To(Equal("vm-1"))
+			Expect(encrypted[0].Encrypted).To(BeTrue())
+			Expect(plain).To(HaveLen(2))
+			Expect(count).To(Equal(1))
+		})
+
+		// Given a VM with vTPM and secure boot
+		// When we get the VM details
+		// Then the security flags should be set
+		It("should return security flags with VM details", func() {
+			// Arrange
+			insertSecurity("vm-2", false, true, true)
+
+			// Act
+			vm, err := s.VM().Get(ctx, "vm-2")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vm.Encrypted).To(BeFalse())
+			Expect(vm.TpmEnabled).To(BeTrue())
+			Expect(vm.SecureBoot).To(BeTrue())
+		})
+
+		// Given a VM without security row
+		// When we get the VM details
+		// Then all security flags should be false
+		It("should default security flags to false", func() {
+			// Act
+			vm, err := s.VM().Get(ctx, "vm-3")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vm.Encrypted).To(BeFalse())
+			Expect(vm.TpmEnabled).To(BeFalse())
+			Expect(vm.SecureBoot).To(BeFalse())
+		})
+	})
+})
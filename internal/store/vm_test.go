@@ -10,6 +10,8 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/test"
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
+	"github.com/kubev2v/assisted-migration-agent/test/storetest"
 )
 
 var _ = Describe("VMStore", func() {
@@ -23,13 +25,10 @@ var _ = Describe("VMStore", func() {
 		ctx = context.Background()
 		var err error
 
-		db, err = store.NewDB(":memory:")
+		db, err = storetest.Migrated.Open(GinkgoT().TempDir())
 		Expect(err).NotTo(HaveOccurred())
 
 		s = store.NewStore(db, test.NewMockValidator())
-
-		err = s.Migrate(ctx)
-		Expect(err).NotTo(HaveOccurred())
 	})
 
 	AfterEach(func() {
@@ -38,53 +37,24 @@ var _ = Describe("VMStore", func() {
 		}
 	})
 
-	// Helper to insert test data into vinfo table
-	insertVM := func(id, name, powerState, cluster string, memory int32) {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vinfo ("VM ID", "VM", "Powerstate", "Cluster", "Memory")
-			VALUES (?, ?, ?, ?, ?)
-		`, id, name, powerState, cluster, memory)
-		Expect(err).NotTo(HaveOccurred())
-	}
-
-	// Helper to insert disk data into vdisk table
-	insertDisk := func(vmID string, capacityMiB int64) {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vdisk ("VM ID", "Capacity MiB")
-			VALUES (?, ?)
-		`, vmID, capacityMiB)
-		Expect(err).NotTo(HaveOccurred())
-	}
-
-	// Helper to insert concerns for a VM
-	insertConcern := func(vmID, concernID, label string) {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO concerns ("VM_ID", "Concern_ID", "Label", "Category", "Assessment")
-			VALUES (?, ?, ?, 'Warning', 'Needs attention')
-		`, vmID, concernID, label)
-		Expect(err).NotTo(HaveOccurred())
+	// Helper to build a VM of the vinfo table
+	newVM := func(id, name, powerState, cluster string, memory int32) *fixtures.VMBuilder {
+		return fixtures.NewVM(id).WithName(name).WithPowerState(powerState).WithCluster(cluster).WithMemory(memory)
 	}
 
 	Context("List", func() {
 		BeforeEach(func() {
-			// Insert test VMs
-			insertVM("vm-1", "web-server-1", "poweredOn", "cluster-a", 4096)
-			insertVM("vm-2", "web-server-2", "poweredOn", "cluster-a", 8192)
-			insertVM("vm-3", "db-server-1", "poweredOff", "cluster-b", 16384)
-			insertVM("vm-4", "app-server-1", "poweredOn", "cluster-c", 8192)
-			insertVM("vm-5", "app-server-2", "suspended", "cluster-c", 32768)
-
-			// Insert disk data
-			insertDisk("vm-1", 100)
-			insertDisk("vm-2", 200)
-			insertDisk("vm-3", 500)
-			insertDisk("vm-4", 150)
-			insertDisk("vm-5", 150)
-
-			// Insert some concerns
-			insertConcern("vm-3", "concern-1", "High CPU usage")
-			insertConcern("vm-3", "concern-2", "Outdated OS")
-			insertConcern("vm-5", "concern-3", "Network issue")
+			// Insert test VMs with their disks and some concerns
+			Expect(fixtures.Insert(ctx, db,
+				newVM("vm-1", "web-server-1", "poweredOn", "cluster-a", 4096).WithDisk(100),
+				newVM("vm-2", "web-server-2", "poweredOn", "cluster-a", 8192).WithDisk(200),
+				newVM("vm-3", "db-server-1", "poweredOff", "cluster-b", 16384).WithDisk(500).
+					WithConcern("concern-1", "High CPU usage").
+					WithConcern("concern-2", "Outdated OS"),
+				newVM("vm-4", "app-server-1", "poweredOn", "cluster-c", 8192).WithDisk(150),
+				newVM("vm-5", "app-server-2", "suspended", "cluster-c", 32768).WithDisk(150).
+					WithConcern("concern-3", "Network issue"),
+			)).To(Succeed())
 		})
 
 		// Given VMs in the database
@@ -365,13 +335,11 @@ var _ = Describe("VMStore", func() {
 
 	Context("Count", func() {
 		BeforeEach(func() {
-			insertVM("vm-1", "vm1", "poweredOn", "cluster-a", 4096)
-			insertVM("vm-2", "vm2", "poweredOn", "cluster-a", 8192)
-			insertVM("vm-3", "vm3", "poweredOff", "cluster-b", 16384)
-
-			insertDisk("vm-1", 100)
-			insertDisk("vm-2", 200)
-			insertDisk("vm-3", 500)
+			Expect(fixtures.Insert(ctx, db,
+				newVM("vm-1", "vm1", "poweredOn", "cluster-a", 4096).WithDisk(100),
+				newVM("vm-2", "vm2", "poweredOn", "cluster-a", 8192).WithDisk(200),
+				newVM("vm-3", "vm3", "poweredOff", "cluster-b", 16384).WithDisk(500),
+			)).To(Succeed())
 		})
 
 		// Given VMs in the database
@@ -423,6 +391,31 @@ var _ = Describe("VMStore", func() {
 			Expect(vm.Firmware).To(Equal("efi"))
 		})
 
+		// Given a VM with two sockets, hot add enabled and a hardware version
+		// When we get it by ID
+		// Then its CPU topology, hot add flags and hardware version should be returned
+		It("should return the CPU topology, hot add and hardware version", func() {
+			// Arrange
+			Expect(fixtures.NewVM("vm-topology").
+				WithCPUTopology(2, 4).
+				WithHotAdd(true, true).
+				WithHWVersion("vmx-19").
+				Insert(ctx, db)).To(Succeed())
+
+			// Act
+			vm, err := s.VM().Get(ctx, "vm-topology")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vm.CpuCount).To(Equal(int32(8)))
+			Expect(vm.CpuSockets).To(Equal(int32(2)))
+			Expect(vm.CoresPerSocket).To(Equal(int32(4)))
+			Expect(vm.CpuHotAddEnabled).To(BeTrue())
+			Expect(vm.CpuHotRemoveEnabled).To(BeFalse())
+			Expect(vm.MemoryHotAddEnabled).To(BeTrue())
+			Expect(vm.HardwareVersion).To(Equal("vmx-19"))
+		})
+
 		// Given a VM ID that does not exist
 		// When we get it by ID
 		// Then it should return ResourceNotFoundError
@@ -450,6 +443,8 @@ var _ = Describe("VMStore", func() {
 			Expect(vm.Issues).To(HaveLen(2))
 			Expect(vm.Issues).To(ContainElement("High memory usage"))
 			Expect(vm.Issues).To(ContainElement("Outdated VMware Tools"))
+			Expect(vm.Concerns).To(HaveLen(2))
+			Expect(vm.Concerns[0].ID).NotTo(BeEmpty())
 		})
 	})
 })
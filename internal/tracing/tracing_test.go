@@ -0,0 +1,104 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
+)
+
+var _ = Describe("Tracing", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	Context("Setup", func() {
+		// Given a configuration without an endpoint
+		// When we set up tracing
+		// Then the tracer provider should be kept and the shutdown be a no-op
+		It("should not export the spans without an endpoint", func() {
+			// Arrange
+			provider := otel.GetTracerProvider()
+
+			// Act
+			shutdown, err := tracing.Setup(context.Background(), config.Tracing{SampleRatio: 1}, "v1.0.0")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otel.GetTracerProvider()).To(BeIdenticalTo(provider))
+			Expect(shutdown(context.Background())).To(Succeed())
+		})
+
+		// Given a configuration with an OTLP/HTTP endpoint
+		// When we set up tracing
+		// Then an exporting tracer provider should be installed
+		It("should install an exporting tracer provider", func() {
+			// Arrange
+			provider := otel.GetTracerProvider()
+
+			// Act
+			shutdown, err := tracing.Setup(context.Background(), config.Tracing{
+				Endpoint:    "http://localhost:4318",
+				SampleRatio: 1,
+				ServiceName: "assisted-migration-agent",
+			}, "v1.0.0")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otel.GetTracerProvider()).NotTo(BeIdenticalTo(provider))
+			Expect(shutdown(context.Background())).To(Succeed())
+		})
+	})
+
+	Context("End", func() {
+		// Given a span of a failed operation
+		// When we end it with the error
+		// Then the span should be ended with an error status
+		It("should record the error", func() {
+			// Arrange
+			_, span := tracing.Tracer().Start(context.Background(), "operation")
+
+			// Act
+			tracing.End(span, errors.New("connection refused"))
+
+			// Assert
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Status().Code).To(Equal(codes.Error))
+			Expect(spans[0].Status().Description).To(Equal("connection refused"))
+			Expect(spans[0].Events()).To(HaveLen(1))
+		})
+
+		// Given a span of a successful operation
+		// When we end it without error
+		// Then the span should be ended without status
+		It("should end the span without error", func() {
+			// Arrange
+			_, span := tracing.Tracer().Start(context.Background(), "operation")
+
+			// Act
+			tracing.End(span, nil)
+
+			// Assert
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+		})
+	})
+})
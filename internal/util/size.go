@@ -0,0 +1,60 @@
+package util
+
+import (
+	"strconv"
+)
+
+// BytesPerMiB is the number of bytes of a MiB, the unit of the capacities
+// found in the parser tables.
+const BytesPerMiB = 1024 * 1024
+
+var (
+	binaryUnits  = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	decimalUnits = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// FormatSize formats bytes with the largest binary unit keeping the value at
+// or above 1, with one decimal: "512 B", "1.5 GiB".
+func FormatSize(bytes int64) string {
+	return formatSize(bytes, 1024, binaryUnits)
+}
+
+// FormatSizeDecimal formats bytes like FormatSize with the decimal units,
+// powers of 1000: "1.6 GB".
+func FormatSizeDecimal(bytes int64) string {
+	return formatSize(bytes, 1000, decimalUnits)
+}
+
+// FormatMiB formats a size given in MiB, as read from the parser tables, like
+// FormatSize.
+func FormatMiB(mib int64) string {
+	return FormatSize(MiBToBytes(mib))
+}
+
+// MiBToBytes converts a size in MiB to bytes.
+func MiBToBytes(mib int64) int64 {
+	return mib * BytesPerMiB
+}
+
+func formatSize(bytes int64, base float64, units []string) string {
+	sign := ""
+	value := float64(bytes)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	if value < base {
+		return sign + strconv.FormatInt(int64(value), 10) + " " + units[0]
+	}
+
+	unit := 0
+	for value >= base && unit < len(units)-1 {
+		value /= base
+		unit++
+	}
+	// 1023.96 KiB rounds up to the next unit rather than to "1024.0 KiB"
+	if value >= base-0.05 && unit < len(units)-1 {
+		value /= base
+		unit++
+	}
+	return sign + strconv.FormatFloat(value, 'f', 1, 64) + " " + units[unit]
+}
@@ -0,0 +1,68 @@
+package util_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/util"
+)
+
+var _ = Describe("Size", func() {
+	Context("FormatSize", func() {
+		// Given sizes below 1KiB
+		// When we format them
+		// Then they should be formatted in bytes
+		It("should format small sizes in bytes", func() {
+			// Act & Assert
+			Expect(util.FormatSize(0)).To(Equal("0 B"))
+			Expect(util.FormatSize(512)).To(Equal("512 B"))
+		})
+
+		// Given sizes across the binary units
+		// When we format them
+		// Then the largest unit keeping the value at or above 1 should be used
+		It("should pick the largest binary unit", func() {
+			// Act & Assert
+			Expect(util.FormatSize(1024)).To(Equal("1.0 KiB"))
+			Expect(util.FormatSize(1536 * 1024)).To(Equal("1.5 MiB"))
+			Expect(util.FormatSize(3 * 1024 * 1024 * 1024 * 1024)).To(Equal("3.0 TiB"))
+			Expect(util.FormatSize(-2048)).To(Equal("-2.0 KiB"))
+		})
+
+		// Given a size just below a unit
+		// When we format it
+		// Then it should round up to that unit instead of "1024.0"
+		It("should round up to the next unit", func() {
+			// Act
+			formatted := util.FormatSize(1024*1024 - 1)
+
+			// Assert
+			Expect(formatted).To(Equal("1.0 MiB"))
+		})
+	})
+
+	Context("FormatSizeDecimal", func() {
+		// Given sizes across the decimal units
+		// When we format them
+		// Then powers of 1000 should be used
+		It("should pick the largest decimal unit", func() {
+			// Act & Assert
+			Expect(util.FormatSizeDecimal(999)).To(Equal("999 B"))
+			Expect(util.FormatSizeDecimal(1000)).To(Equal("1.0 kB"))
+			Expect(util.FormatSizeDecimal(1_610_612_736)).To(Equal("1.6 GB"))
+		})
+	})
+
+	Context("FormatMiB", func() {
+		// Given a disk capacity read from the parser tables in MiB
+		// When we format it
+		// Then it should be formatted from its size in bytes
+		It("should format sizes given in MiB", func() {
+			// Act
+			formatted := util.FormatMiB(102400)
+
+			// Assert
+			Expect(formatted).To(Equal("100.0 GiB"))
+		})
+	})
+})
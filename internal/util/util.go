@@ -6,7 +6,7 @@ import (
 
 // ConvertBytesToMB converts bytes to megabytes safely
 func ConvertBytesToMB(bytes int64) int64 {
-	return bytes / (1024 * 1024)
+	return bytes / BytesPerMiB
 }
 
 // IntPtr returns a pointer to the given int
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -26,23 +25,13 @@ func main() {
 	}
 
 	// default configuration
+	// the struct defaults are kept, only the values differing from them are set
 	cfg := config.NewConfigurationWithOptionsAndDefaults(
-		config.WithServer(config.Server{
-			HTTPPort:   8000,
-			ServerMode: "dev",
-		}),
-		config.WithAgent(config.Agent{
-			Version:             "v0.0.0",
-			GitCommit:           gitCommit,
-			NumWorkers:          3,
-			Mode:                "disconnected",
-			UpdateInterval:      5 * time.Second,
-			LegacyStatusEnabled: true,
-		}),
 		config.WithAuth(config.Authentication{Enabled: false}),
 		config.WithLogFormat("console"),
 		config.WithLogLevel("debug"),
 	)
+	cfg.Agent.GitCommit = gitCommit
 	registerLoggingFlags(rootCmd, cfg)
 
 	if err := validateConfig(cfg); err != nil {
@@ -57,6 +46,7 @@ func main() {
 	defer undo()
 
 	rootCmd.AddCommand(cmd.NewRunCommand(cfg))
+	rootCmd.AddCommand(cmd.NewAssessmentCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Printf("%s", err)
@@ -82,4 +72,8 @@ func validateConfig(cfg *config.Configuration) error {
 func registerLoggingFlags(cmd *cobra.Command, config *config.Configuration) {
 	cmd.PersistentFlags().StringVar(&config.LogFormat, "log-format", config.LogFormat, "format of the logs: console or json")
 	cmd.PersistentFlags().StringVar(&config.LogLevel, "log-level", config.LogLevel, "log level")
+	cmd.PersistentFlags().StringVar(&config.LogFile, "log-file", config.LogFile, "file also receiving the logs, rotated by size and age. Disabled when empty")
+	cmd.PersistentFlags().Var(&config.LogFileMaxSize, "log-file-max-size", "size at which the log file is rotated, e.g. 100MiB. 0 disables rotation")
+	cmd.PersistentFlags().DurationVar(&config.LogFileMaxAge, "log-file-max-age", config.LogFileMaxAge, "age after which rotated log files are removed. 0 keeps them")
+	cmd.PersistentFlags().IntVar(&config.LogFileMaxBackups, "log-file-max-backups", config.LogFileMaxBackups, "number of rotated log files to keep. 0 keeps them all")
 }
@@ -0,0 +1,162 @@
+// Package benchmark measures the throughput of the transfers of a migration:
+// reading the disks from the source datastores and sending them over the
+// network toward the target.
+package benchmark
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// chunkSize is the size of the reads and writes of a measure.
+const chunkSize = 1 << 20
+
+// Limits bound a measure, which stops at the first reached.
+type Limits struct {
+	Bytes    int64
+	Duration time.Duration
+}
+
+// Result is a measured transfer.
+type Result struct {
+	Bytes    int64
+	Duration time.Duration
+}
+
+// MBps returns the throughput of the transfer in MB/s, 0 when nothing was
+// transferred.
+func (r Result) MBps() float64 {
+	if r.Bytes == 0 || r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / 1e6 / r.Duration.Seconds()
+}
+
+// Read measures reading r until the limits or its end.
+func Read(ctx context.Context, r io.Reader, limits Limits) (Result, error) {
+	buf := make([]byte, chunkSize)
+	start := time.Now()
+	var res Result
+	for res.Bytes < limits.Bytes && time.Since(start) < limits.Duration {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		n, err := r.Read(buf[:min(int64(len(buf)), limits.Bytes-res.Bytes)])
+		res.Bytes += int64(n)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			res.Duration = time.Since(start)
+			return res, err
+		}
+	}
+	res.Duration = time.Since(start)
+	return res, nil
+}
+
+// Send measures sending data toward target until the limits: tcp://host:port
+// writes a stream to the port, e.g. of nc -l > /dev/null, and an http(s) URL
+// posts it. insecure skips the verification of the certificate of an https
+// target; proxy is the proxy of the http(s) requests, the HTTP(S)_PROXY
+// environment variables when nil.
+func Send(ctx context.Context, target string, limits Limits, insecure bool, proxy func(*http.Request) (*url.URL, error)) (Result, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid target: %w", err)
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		return sendTCP(ctx, u.Host, limits)
+	case "http", "https":
+		return post(ctx, target, limits, insecure, proxy)
+	default:
+		return Result{}, fmt.Errorf("invalid target %q: the scheme must be tcp, http or https", target)
+	}
+}
+
+func sendTCP(ctx context.Context, address string, limits Limits) (Result, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, chunkSize)
+	start := time.Now()
+	_ = conn.SetWriteDeadline(start.Add(limits.Duration))
+	var res Result
+	for res.Bytes < limits.Bytes {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		n, err := conn.Write(buf[:min(int64(len(buf)), limits.Bytes-res.Bytes)])
+		res.Bytes += int64(n)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			res.Duration = time.Since(start)
+			return res, err
+		}
+	}
+	res.Duration = time.Since(start)
+	return res, nil
+}
+
+func post(ctx context.Context, target string, limits Limits, insecure bool, proxy func(*http.Request) (*url.URL, error)) (Result, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure} //nolint:gosec
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+	client := &http.Client{Transport: transport}
+
+	body := &zeros{limits: limits}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	body.start = time.Now()
+	resp, err := client.Do(req)
+	res := Result{Bytes: body.sent, Duration: time.Since(body.start)}
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return res, fmt.Errorf("the target answered %s", resp.Status)
+	}
+	return res, nil
+}
+
+// zeros is a body of zeros ending at the limits.
+type zeros struct {
+	limits Limits
+	start  time.Time
+	sent   int64
+}
+
+func (z *zeros) Read(p []byte) (int, error) {
+	if z.sent >= z.limits.Bytes || time.Since(z.start) >= z.limits.Duration {
+		return 0, io.EOF
+	}
+	n := min(int64(len(p)), z.limits.Bytes-z.sent, chunkSize)
+	clear(p[:n])
+	z.sent += n
+	return int(n), nil
+}
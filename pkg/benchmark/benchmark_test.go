@@ -0,0 +1,132 @@
+package benchmark_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/benchmark"
+)
+
+var _ = Describe("Benchmark", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("Read", func() {
+		// Given a reader longer than the byte limit
+		// When we measure reading it
+		// Then the read should stop at the limit
+		It("should stop at the byte limit", func() {
+			// Arrange
+			r := bytes.NewReader(make([]byte, 5<<20))
+
+			// Act
+			res, err := benchmark.Read(ctx, r, benchmark.Limits{Bytes: 3 << 20, Duration: time.Minute})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Bytes).To(BeEquivalentTo(3 << 20))
+			Expect(res.MBps()).To(BeNumerically(">", 0))
+		})
+
+		// Given a reader shorter than the limits
+		// When we measure reading it
+		// Then the whole reader should be measured
+		It("should stop at the end of the reader", func() {
+			// Act
+			res, err := benchmark.Read(ctx, bytes.NewReader(make([]byte, 1000)), benchmark.Limits{Bytes: 1 << 30, Duration: time.Minute})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Bytes).To(BeEquivalentTo(1000))
+		})
+	})
+
+	Context("Send", func() {
+		// Given an HTTP target discarding what it receives
+		// When we measure sending to it
+		// Then the bytes posted should be measured
+		It("should post to an http target", func() {
+			// Arrange
+			var received atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n, _ := io.Copy(io.Discard, r.Body)
+				received.Store(n)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			// Act
+			res, err := benchmark.Send(ctx, server.URL, benchmark.Limits{Bytes: 4 << 20, Duration: time.Minute}, false, nil)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Bytes).To(BeEquivalentTo(4 << 20))
+			Expect(received.Load()).To(BeEquivalentTo(4 << 20))
+		})
+
+		// Given an HTTP target rejecting the data
+		// When we measure sending to it
+		// Then it should fail with its status
+		It("should fail when the target rejects the data", func() {
+			// Arrange
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+
+			// Act
+			_, err := benchmark.Send(ctx, server.URL, benchmark.Limits{Bytes: 1 << 20, Duration: time.Minute}, false, nil)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("403")))
+		})
+
+		// Given a TCP port discarding what it receives
+		// When we measure sending to it
+		// Then the bytes written should be measured
+		It("should stream to a tcp target", func() {
+			// Arrange
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer l.Close()
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+
+			// Act
+			res, err := benchmark.Send(ctx, "tcp://"+l.Addr().String(), benchmark.Limits{Bytes: 4 << 20, Duration: time.Minute}, false, nil)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Bytes).To(BeEquivalentTo(4 << 20))
+		})
+
+		// Given a target of an unknown scheme
+		// When we measure sending to it
+		// Then it should fail
+		It("should reject an unknown scheme", func() {
+			// Act
+			_, err := benchmark.Send(ctx, "ftp://target", benchmark.Limits{Bytes: 1, Duration: time.Second}, false, nil)
+
+			// Assert
+			Expect(err).To(MatchError(ContainSubstring("scheme")))
+		})
+	})
+})
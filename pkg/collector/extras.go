@@ -0,0 +1,462 @@
+package collector
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/event"
+	"github.com/vmware/govmomi/performance"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+)
+
+const (
+	// eventsWindow is how far back the vCenter events are read.
+	eventsWindow = 7 * 24 * time.Hour
+	// maxEvents bounds the number of events read from vCenter.
+	maxEvents = 1000
+	// perfSamples is the number of realtime (20s) samples averaged per datastore, 5 minutes.
+	perfSamples = 15
+)
+
+// datastore counters reported by the hosts, one instance per datastore UUID
+const (
+	counterDatastoreRead         = "datastore.read.average"
+	counterDatastoreWrite        = "datastore.write.average"
+	counterDatastoreReadLatency  = "datastore.totalReadLatency.average"
+	counterDatastoreWriteLatency = "datastore.totalWriteLatency.average"
+)
+
+// relevantEventTypes are the events hinting at an unhealthy VM or host.
+var relevantEventTypes = map[string]string{
+	"VmRestartedOnAlternateHostEvent":    "error",
+	"VmDasBeingResetEvent":               "error",
+	"VmDasBeingResetWithScreenshotEvent": "error",
+	"VmFailoverFailed":                   "error",
+	"VmFailedToPowerOnEvent":             "error",
+	"VmDiskFailedEvent":                  "error",
+	"DasHostFailedEvent":                 "error",
+	"HostConnectionLostEvent":            "warning",
+	"VmOrphanedEvent":                    "warning",
+}
+
+// Extras holds the vCenter data that the forklift model does not carry.
+type Extras struct {
+	// EncryptedVMs are the IDs of the VMs encrypted by vSphere VM encryption.
+	EncryptedVMs []string
+	// ClusterRules are the DRS rules of every cluster.
+	ClusterRules []models.ClusterRule
+	// Events are the recent relevant events and the currently triggered alarms of VMs and hosts.
+	Events []models.VCenterEvent
+	// DatastoreStats are the recent read/write throughput and latency of every datastore.
+	DatastoreStats []models.DatastoreStats
+	// DiskChains are the backing chains of every virtual disk.
+	DiskChains []models.DiskChain
+}
+
+// extrasCollector is a Collector reading the data missing from the forklift
+// model, only vCenter has it.
+type extrasCollector interface {
+	CollectExtras(ctx context.Context, creds *models.Credentials) (*Extras, error)
+}
+
+// CollectExtras reads the data missing from the forklift model directly from vCenter.
+// Each part is optional: a failing query is logged and leaves its part empty.
+func (c *VSphereCollector) CollectExtras(ctx context.Context, creds *models.Credentials) (*Extras, error) {
+	client, err := vmware.NewVsphereClient(ctx, creds.URL, creds.Username, creds.Password, true, c.proxy)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Logout(ctx) }()
+
+	logger := zap.S().Named("collector")
+	extras := &Extras{}
+
+	if extras.EncryptedVMs, err = encryptedVMs(ctx, client); err != nil {
+		logger.Warnw("failed to collect vm encryption", "error", err)
+	}
+
+	if extras.ClusterRules, err = clusterRules(ctx, client); err != nil {
+		logger.Warnw("failed to collect cluster rules", "error", err)
+	}
+
+	if extras.Events, err = recentEvents(ctx, client); err != nil {
+		logger.Warnw("failed to collect vCenter events", "error", err)
+	}
+
+	alarms, err := triggeredAlarms(ctx, client)
+	if err != nil {
+		logger.Warnw("failed to collect triggered alarms", "error", err)
+	}
+	extras.Events = append(extras.Events, alarms...)
+
+	if extras.DatastoreStats, err = datastoreStats(ctx, client); err != nil {
+		logger.Warnw("failed to collect datastore statistics", "error", err)
+	}
+
+	if extras.DiskChains, err = diskChains(ctx, client); err != nil {
+		logger.Warnw("failed to collect disk chains", "error", err)
+	}
+
+	logger.Debugw("vCenter extras collected",
+		"encrypted_vms", len(extras.EncryptedVMs),
+		"cluster_rules", len(extras.ClusterRules),
+		"events", len(extras.Events),
+		"datastore_stats", len(extras.DatastoreStats),
+		"disk_chains", len(extras.DiskChains))
+
+	return extras, nil
+}
+
+// retrieve loads the properties of all the objects of kind into dst.
+func retrieve(ctx context.Context, client *govmomi.Client, kind string, props []string, dst any) error {
+	v, err := view.NewManager(client.Client).CreateContainerView(ctx, client.ServiceContent.RootFolder, []string{kind}, true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = v.Destroy(ctx) }()
+
+	return v.Retrieve(ctx, []string{kind}, props, dst)
+}
+
+func encryptedVMs(ctx context.Context, client *govmomi.Client) ([]string, error) {
+	var vms []mo.VirtualMachine
+	if err := retrieve(ctx, client, "VirtualMachine", []string{"config.keyId"}, &vms); err != nil {
+		return nil, err
+	}
+
+	var encrypted []string
+	for _, vm := range vms {
+		if vm.Config != nil && vm.Config.KeyId != nil {
+			encrypted = append(encrypted, vm.Self.Value)
+		}
+	}
+
+	return encrypted, nil
+}
+
+func clusterRules(ctx context.Context, client *govmomi.Client) ([]models.ClusterRule, error) {
+	var clusters []mo.ClusterComputeResource
+	if err := retrieve(ctx, client, "ClusterComputeResource", []string{"name", "configurationEx"}, &clusters); err != nil {
+		return nil, err
+	}
+
+	var rules []models.ClusterRule
+	for _, cluster := range clusters {
+		cfg, ok := cluster.ConfigurationEx.(*types.ClusterConfigInfoEx)
+		if !ok {
+			continue
+		}
+
+		vmGroups := make(map[string][]string)
+		hostGroups := make(map[string][]string)
+		for _, g := range cfg.Group {
+			switch group := g.(type) {
+			case *types.ClusterVmGroup:
+				vmGroups[group.Name] = refValues(group.Vm)
+			case *types.ClusterHostGroup:
+				hostGroups[group.Name] = refValues(group.Host)
+			}
+		}
+
+		for _, r := range cfg.Rule {
+			info := r.GetClusterRuleInfo()
+			rule := models.ClusterRule{
+				ClusterID: cluster.Self.Value,
+				Cluster:   cluster.Name,
+				Name:      info.Name,
+				Enabled:   info.Enabled != nil && *info.Enabled,
+				Mandatory: info.Mandatory != nil && *info.Mandatory,
+			}
+
+			switch spec := r.(type) {
+			case *types.ClusterAffinityRuleSpec:
+				rule.Type = models.ClusterRuleTypeVMAffinity
+				rule.VMs = refValues(spec.Vm)
+			case *types.ClusterAntiAffinityRuleSpec:
+				rule.Type = models.ClusterRuleTypeVMAntiAffinity
+				rule.VMs = refValues(spec.Vm)
+			case *types.ClusterVmHostRuleInfo:
+				rule.VMGroup = spec.VmGroupName
+				rule.VMs = vmGroups[spec.VmGroupName]
+				if spec.AffineHostGroupName != "" {
+					rule.Type = models.ClusterRuleTypeVMHostAffinity
+					rule.HostGroup = spec.AffineHostGroupName
+				} else {
+					rule.Type = models.ClusterRuleTypeVMHostAntiAffinity
+					rule.HostGroup = spec.AntiAffineHostGroupName
+				}
+				rule.Hosts = hostGroups[rule.HostGroup]
+			default:
+				// dependency and other rule kinds have no scheduling equivalent on the target
+				continue
+			}
+
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+func recentEvents(ctx context.Context, client *govmomi.Client) ([]models.VCenterEvent, error) {
+	eventTypes := make([]string, 0, len(relevantEventTypes))
+	for t := range relevantEventTypes {
+		eventTypes = append(eventTypes, t)
+	}
+
+	begin := time.Now().Add(-eventsWindow)
+	baseEvents, err := event.NewManager(client.Client).QueryEvents(ctx, types.EventFilterSpec{
+		Time:        &types.EventFilterSpecByTime{BeginTime: &begin},
+		EventTypeId: eventTypes,
+		MaxCount:    maxEvents,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.VCenterEvent, 0, len(baseEvents))
+	for _, be := range baseEvents {
+		e := be.GetEvent()
+		eventType := eventTypeName(be)
+		ev := models.VCenterEvent{
+			Kind:      models.VCenterEventKindEvent,
+			Type:      eventType,
+			Severity:  relevantEventTypes[eventType],
+			Message:   e.FullFormattedMessage,
+			CreatedAt: e.CreatedTime,
+		}
+		if e.Vm != nil {
+			ev.VMID = e.Vm.Vm.Value
+		}
+		if e.Host != nil {
+			ev.HostID = e.Host.Host.Value
+		}
+		events = append(events, ev)
+	}
+
+	return events, nil
+}
+
+func triggeredAlarms(ctx context.Context, client *govmomi.Client) ([]models.VCenterEvent, error) {
+	var states []types.AlarmState
+
+	var vms []mo.VirtualMachine
+	if err := retrieve(ctx, client, "VirtualMachine", []string{"triggeredAlarmState"}, &vms); err != nil {
+		return nil, err
+	}
+	for _, vm := range vms {
+		states = append(states, vm.TriggeredAlarmState...)
+	}
+
+	var hosts []mo.HostSystem
+	if err := retrieve(ctx, client, "HostSystem", []string{"triggeredAlarmState"}, &hosts); err != nil {
+		return nil, err
+	}
+	for _, h := range hosts {
+		states = append(states, h.TriggeredAlarmState...)
+	}
+
+	if len(states) == 0 {
+		return nil, nil
+	}
+
+	refs := make([]types.ManagedObjectReference, 0, len(states))
+	for _, st := range states {
+		refs = append(refs, st.Alarm)
+	}
+
+	var alarms []mo.Alarm
+	if err := property.DefaultCollector(client.Client).Retrieve(ctx, refs, []string{"info.name"}, &alarms); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(alarms))
+	for _, a := range alarms {
+		names[a.Self.Value] = a.Info.Name
+	}
+
+	events := make([]models.VCenterEvent, 0, len(states))
+	for _, st := range states {
+		severity := "warning"
+		if st.OverallStatus == types.ManagedEntityStatusRed {
+			severity = "error"
+		}
+		ev := models.VCenterEvent{
+			Kind:      models.VCenterEventKindAlarm,
+			Type:      names[st.Alarm.Value],
+			Severity:  severity,
+			Message:   names[st.Alarm.Value],
+			CreatedAt: st.Time,
+		}
+		switch st.Entity.Type {
+		case "VirtualMachine":
+			ev.VMID = st.Entity.Value
+		case "HostSystem":
+			ev.HostID = st.Entity.Value
+		}
+		events = append(events, ev)
+	}
+
+	return events, nil
+}
+
+func datastoreStats(ctx context.Context, client *govmomi.Client) ([]models.DatastoreStats, error) {
+	var datastores []mo.Datastore
+	if err := retrieve(ctx, client, "Datastore", []string{"summary"}, &datastores); err != nil {
+		return nil, err
+	}
+
+	// host counters are reported per datastore UUID, the last element of the datastore url
+	byUUID := make(map[string]*models.DatastoreStats, len(datastores))
+	for _, ds := range datastores {
+		byUUID[path.Base(strings.TrimSuffix(ds.Summary.Url, "/"))] = &models.DatastoreStats{
+			ID:   ds.Self.Value,
+			Name: ds.Summary.Name,
+		}
+	}
+
+	var hosts []mo.HostSystem
+	if err := retrieve(ctx, client, "HostSystem", []string{"name"}, &hosts); err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	refs := make([]types.ManagedObjectReference, 0, len(hosts))
+	for _, h := range hosts {
+		refs = append(refs, h.Self)
+	}
+
+	pm := performance.NewManager(client.Client)
+	sample, err := pm.SampleByName(ctx, types.PerfQuerySpec{
+		MaxSample:  perfSamples,
+		IntervalId: 20,
+		MetricId:   []types.PerfMetricId{{Instance: "*"}},
+	}, []string{counterDatastoreRead, counterDatastoreWrite, counterDatastoreReadLatency, counterDatastoreWriteLatency}, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := pm.ToMetricSeries(ctx, sample)
+	if err != nil {
+		return nil, err
+	}
+
+	sampledAt := time.Now()
+	for _, entity := range series {
+		for _, v := range entity.Value {
+			st, ok := byUUID[v.Instance]
+			if !ok || len(v.Value) == 0 {
+				continue
+			}
+			avg := average(v.Value)
+			switch v.Name {
+			case counterDatastoreRead:
+				st.ReadKBps += avg
+			case counterDatastoreWrite:
+				st.WriteKBps += avg
+			case counterDatastoreReadLatency:
+				st.ReadLatencyMs = max(st.ReadLatencyMs, avg)
+			case counterDatastoreWriteLatency:
+				st.WriteLatencyMs = max(st.WriteLatencyMs, avg)
+			}
+		}
+	}
+
+	stats := make([]models.DatastoreStats, 0, len(byUUID))
+	for _, st := range byUUID {
+		st.SampledAt = sampledAt
+		stats = append(stats, *st)
+	}
+
+	return stats, nil
+}
+
+func diskChains(ctx context.Context, client *govmomi.Client) ([]models.DiskChain, error) {
+	var vms []mo.VirtualMachine
+	if err := retrieve(ctx, client, "VirtualMachine", []string{"layoutEx", "config.files.vmPathName"}, &vms); err != nil {
+		return nil, err
+	}
+
+	var chains []models.DiskChain
+	for _, vm := range vms {
+		if vm.LayoutEx == nil || vm.Config == nil {
+			continue
+		}
+
+		descriptors := make(map[int32]string, len(vm.LayoutEx.File))
+		for _, f := range vm.LayoutEx.File {
+			if f.Type == string(types.VirtualMachineFileLayoutExFileTypeDiskDescriptor) {
+				descriptors[f.Key] = f.Name
+			}
+		}
+
+		vmDir := datastoreDir(vm.Config.Files.VmPathName)
+		for _, d := range vm.LayoutEx.Disk {
+			var files []string
+			for _, unit := range d.Chain {
+				for _, key := range unit.FileKey {
+					if name, ok := descriptors[key]; ok {
+						files = append(files, name)
+					}
+				}
+			}
+			if len(files) == 0 {
+				continue
+			}
+
+			// the chain is ordered from the base disk to the running point
+			chains = append(chains, models.DiskChain{
+				VMID:        vm.Self.Value,
+				DiskKey:     d.Key,
+				File:        files[len(files)-1],
+				BaseFile:    files[0],
+				Depth:       len(files),
+				LinkedClone: len(files) > 1 && datastoreDir(files[0]) != vmDir,
+			})
+		}
+	}
+
+	return chains, nil
+}
+
+// datastoreDir returns the directory of a datastore path like "[ds1] vm/vm.vmx".
+func datastoreDir(p string) string {
+	return path.Dir(p)
+}
+
+func average(values []int64) int64 {
+	var sum int64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / int64(len(values))
+}
+
+// eventTypeName returns the vim type name of the event (e.g. VmFailedToPowerOnEvent).
+func eventTypeName(e types.BaseEvent) string {
+	t := reflect.TypeOf(e)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func refValues(refs []types.ManagedObjectReference) []string {
+	values := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		values = append(values, ref.Value)
+	}
+	return values
+}
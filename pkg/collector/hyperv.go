@@ -0,0 +1,577 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	vspheremodel "github.com/kubev2v/forklift/pkg/controller/provider/model/vsphere"
+	libmodel "github.com/kubev2v/forklift/pkg/lib/inventory/model"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/hyperv"
+)
+
+// WMI namespaces read by the Hyper-V collector.
+const (
+	namespaceCIMv2          = "root/cimv2"
+	namespaceVirtualization = "root/virtualization/v2"
+	namespaceCluster        = "root/MSCluster"
+)
+
+// Values of the Hyper-V settings mapped to the vSphere ones.
+const (
+	// vmSubTypeGen2 is the VirtualSystemSubType of the generation 2 VMs, booting with UEFI.
+	vmSubTypeGen2 = "Microsoft:Hyper-V:SubType:2"
+	// realizedSystemType is the VirtualSystemType of the settings of the running configuration, not of a checkpoint.
+	realizedSystemType = "Microsoft:Hyper-V:System:Realized"
+	// virtualHardDiskType is the ResourceSubType of the VHD(X) disks.
+	virtualHardDiskType = "Microsoft:Hyper-V:Virtual Hard Disk"
+
+	enabledStateOn  = 2
+	enabledStateOff = 3
+)
+
+// switchNamePattern extracts the name, a GUID, of the switch from the WMI path
+// of the HostResource of a port allocation.
+var switchNamePattern = regexp.MustCompile(`[.,]Name="([^"]+)"`)
+
+// HyperVCollector collects the inventory of a Hyper-V host through WinRM, or of
+// every node of its failover cluster. It writes the inventory in the sqlite
+// database of the forklift vSphere model, so it is parsed and evaluated like a
+// vCenter inventory:
+//
+//	failover cluster, or the host alone → Cluster
+//	Hyper-V host                        → Host
+//	volume of a host                    → Datastore
+//	virtual switch                      → Network
+//	virtual machine                     → VM
+//
+// Hosts managed by SCVMM are collected from the hosts themselves, the VMM
+// server has no WMI provider of its inventory.
+type HyperVCollector struct {
+	db     libmodel.DB
+	dbPath string
+	// proxy of the requests to the hosts, the HTTP(S)_PROXY environment variables when nil
+	proxy func(*http.Request) (*url.URL, error)
+}
+
+func NewHyperVCollector(dbPath string) *HyperVCollector {
+	return &HyperVCollector{
+		dbPath: dbPath,
+	}
+}
+
+func (c *HyperVCollector) VerifyCredentials(ctx context.Context, creds *models.Credentials) error {
+	client, err := hyperv.NewClient(creds.URL, creds.Username, creds.Password, true, c.proxy)
+	if err != nil {
+		return err
+	}
+
+	verifyCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	zap.S().Named("collector").Info("verifying Hyper-V credentials")
+	if _, err := client.Query(verifyCtx, namespaceCIMv2, "SELECT Name FROM Win32_ComputerSystem"); err != nil {
+		if errors.Is(err, hyperv.ErrUnauthorized) {
+			return srvErrors.NewVCenterError(err)
+		}
+		return srvErrors.NewTransientError(err)
+	}
+	if _, err := client.Query(verifyCtx, namespaceVirtualization, "SELECT Name FROM Msvm_VirtualSystemManagementService"); err != nil {
+		return fmt.Errorf("%s is not a Hyper-V host: %w", client.Host(), err)
+	}
+
+	zap.S().Named("collector").Info("Hyper-V credentials verified successfully")
+	return nil
+}
+
+func (c *HyperVCollector) Collect(ctx context.Context, creds *models.Credentials) error {
+	client, err := hyperv.NewClient(creds.URL, creds.Username, creds.Password, true, c.proxy)
+	if err != nil {
+		return err
+	}
+
+	computer, err := queryOne(ctx, client, namespaceCIMv2, "SELECT Name, Domain FROM Win32_ComputerSystem")
+	if err != nil {
+		return err
+	}
+
+	// The nodes of the failover cluster of the host, if any. The namespace
+	// only exists on the nodes of a cluster.
+	clusterName, nodes := computer.String("Name"), []string{computer.String("Name")}
+	if cluster, err := queryOne(ctx, client, namespaceCluster, "SELECT Name FROM MSCluster_Cluster"); err == nil {
+		clusterName = cluster.String("Name")
+		if instances, err := client.Query(ctx, namespaceCluster, "SELECT Name FROM MSCluster_Node"); err == nil && len(instances) > 0 {
+			nodes = nodes[:0]
+			for _, node := range instances {
+				nodes = append(nodes, node.String("Name"))
+			}
+		}
+	}
+
+	zap.S().Infow("starting Hyper-V collection", "cluster", clusterName, "hosts", nodes)
+
+	inventory := newHyperVInventory(computer.String("Domain"), clusterName)
+	for _, node := range nodes {
+		nodeClient := client
+		if !strings.EqualFold(node, computer.String("Name")) {
+			nodeClient = client.ForHost(node)
+		}
+		if err := inventory.collectHost(ctx, nodeClient); err != nil {
+			// A node may be down, the VMs it owned are failed over to the others.
+			if len(nodes) == 1 || errors.Is(err, hyperv.ErrUnauthorized) {
+				return err
+			}
+			zap.S().Warnw("failed to collect a Hyper-V cluster node", "host", node, "error", err)
+		}
+	}
+	if len(inventory.hosts) == 0 {
+		return fmt.Errorf("no node of the Hyper-V cluster %s could be collected", clusterName)
+	}
+
+	db, err := createDB(createProvider(creds), c.dbPath)
+	if err != nil {
+		return err
+	}
+	c.db = db
+
+	if err := inventory.write(db); err != nil {
+		return err
+	}
+
+	zap.S().Infow("Hyper-V collection completed", "hosts", len(inventory.hosts), "vms", len(inventory.vms))
+	return nil
+}
+
+func (c *HyperVCollector) DB() libmodel.DB {
+	return c.db
+}
+
+func (c *HyperVCollector) DBPath() string {
+	return c.dbPath
+}
+
+// Close cleans up collector resources.
+func (c *HyperVCollector) Close() {
+	if c.db != nil {
+		_ = c.db.Close(false)
+	}
+}
+
+// hyperVInventory is the inventory of the hosts of a Hyper-V cluster, in the
+// forklift vSphere model.
+type hyperVInventory struct {
+	datacenter string
+	cluster    string
+	hosts      []*vspheremodel.Host
+	vms        []*vspheremodel.VM
+	networks   []*vspheremodel.Network
+	// datastores by ID, the cluster shared volumes being seen by every node
+	datastores map[string]*vspheremodel.Datastore
+}
+
+func newHyperVInventory(domain, cluster string) *hyperVInventory {
+	if domain == "" {
+		domain = "WORKGROUP"
+	}
+	return &hyperVInventory{
+		datacenter: domain,
+		cluster:    cluster,
+		datastores: map[string]*vspheremodel.Datastore{},
+	}
+}
+
+// collectHost adds the host of client, with its volumes, switches and VMs.
+func (inv *hyperVInventory) collectHost(ctx context.Context, client *hyperv.Client) error {
+	computer, err := queryOne(ctx, client, namespaceCIMv2,
+		"SELECT Name, DNSHostName, Domain, Manufacturer, Model, TotalPhysicalMemory FROM Win32_ComputerSystem")
+	if err != nil {
+		return err
+	}
+	system, err := queryOne(ctx, client, namespaceCIMv2, "SELECT Caption, Version FROM Win32_OperatingSystem")
+	if err != nil {
+		return err
+	}
+	processors, err := client.Query(ctx, namespaceCIMv2, "SELECT NumberOfCores FROM Win32_Processor")
+	if err != nil {
+		return err
+	}
+	volumes, err := client.Query(ctx, namespaceCIMv2,
+		"SELECT DeviceID, Name, Label, FileSystem, Capacity, FreeSpace FROM Win32_Volume WHERE DriveType = 3")
+	if err != nil {
+		return err
+	}
+	switches, err := client.Query(ctx, namespaceVirtualization, "SELECT Name, ElementName FROM Msvm_VirtualEthernetSwitch")
+	if err != nil {
+		return err
+	}
+
+	name := computer.String("DNSHostName")
+	if domain := computer.String("Domain"); domain != "" && !strings.EqualFold(domain, "WORKGROUP") {
+		name += "." + domain
+	}
+	host := &vspheremodel.Host{
+		Base: vspheremodel.Base{
+			ID:     strings.ToLower(name),
+			Name:   strings.ToLower(name),
+			Parent: vspheremodel.Ref{Kind: "Cluster", ID: inv.cluster},
+		},
+		Cluster:        inv.cluster,
+		Status:         "green",
+		CpuSockets:     int16(len(processors)),
+		MemoryBytes:    computer.Int("TotalPhysicalMemory"),
+		ProductName:    system.String("Caption"),
+		ProductVersion: system.String("Version"),
+		Model:          computer.String("Model"),
+		Vendor:         computer.String("Manufacturer"),
+	}
+	for _, p := range processors {
+		host.CpuCores += int16(p.Int("NumberOfCores"))
+	}
+
+	var hostVolumes []*vspheremodel.Datastore
+	for _, v := range volumes {
+		ds := &vspheremodel.Datastore{
+			Base: vspheremodel.Base{
+				ID:   volumeID(v.String("DeviceID")),
+				Name: strings.TrimSuffix(v.String("Name"), `\`),
+			},
+			Type:            v.String("FileSystem"),
+			Capacity:        v.Int("Capacity"),
+			Free:            v.Int("FreeSpace"),
+			MaintenanceMode: "normal",
+		}
+		if ds.Name == "" || ds.Capacity == 0 {
+			continue
+		}
+		if existing, ok := inv.datastores[ds.ID]; ok {
+			ds = existing
+		} else {
+			inv.datastores[ds.ID] = ds
+		}
+		hostVolumes = append(hostVolumes, ds)
+		host.Datastores = append(host.Datastores, vspheremodel.Ref{Kind: "Datastore", ID: ds.ID})
+	}
+
+	networks := map[string]*vspheremodel.Network{}
+	for _, s := range switches {
+		network := &vspheremodel.Network{
+			Base: vspheremodel.Base{
+				ID:   strings.ToLower(s.String("Name")),
+				Name: s.String("ElementName"),
+			},
+		}
+		networks[network.ID] = network
+		inv.networks = append(inv.networks, network)
+		host.Networks = append(host.Networks, vspheremodel.Ref{Kind: "Network", ID: network.ID})
+	}
+
+	vms, err := collectVMs(ctx, client, host.ID, hostVolumes, networks)
+	if err != nil {
+		return err
+	}
+
+	inv.hosts = append(inv.hosts, host)
+	inv.vms = append(inv.vms, vms...)
+	return nil
+}
+
+// collectVMs returns the VMs of the host of client.
+func collectVMs(ctx context.Context, client *hyperv.Client, hostID string, volumes []*vspheremodel.Datastore, networks map[string]*vspheremodel.Network) ([]*vspheremodel.VM, error) {
+	systems, err := client.Query(ctx, namespaceVirtualization,
+		"SELECT Name, ElementName, EnabledState FROM Msvm_ComputerSystem WHERE Caption = 'Virtual Machine'")
+	if err != nil {
+		return nil, err
+	}
+	if len(systems) == 0 {
+		return nil, nil
+	}
+
+	settings, err := client.Query(ctx, namespaceVirtualization,
+		"SELECT ConfigurationID, BIOSGUID, VirtualSystemSubType, SecureBootEnabled FROM Msvm_VirtualSystemSettingData WHERE VirtualSystemType = '"+realizedSystemType+"'")
+	if err != nil {
+		return nil, err
+	}
+	processors, err := client.Query(ctx, namespaceVirtualization, "SELECT InstanceID, VirtualQuantity FROM Msvm_ProcessorSettingData")
+	if err != nil {
+		return nil, err
+	}
+	memory, err := client.Query(ctx, namespaceVirtualization, "SELECT InstanceID, VirtualQuantity FROM Msvm_MemorySettingData")
+	if err != nil {
+		return nil, err
+	}
+	disks, err := client.Query(ctx, namespaceVirtualization,
+		"SELECT InstanceID, HostResource FROM Msvm_StorageAllocationSettingData WHERE ResourceSubType = '"+virtualHardDiskType+"'")
+	if err != nil {
+		return nil, err
+	}
+	ports, err := client.Query(ctx, namespaceVirtualization, "SELECT InstanceID, Address FROM Msvm_SyntheticEthernetPortSettingData")
+	if err != nil {
+		return nil, err
+	}
+	connections, err := client.Query(ctx, namespaceVirtualization, "SELECT InstanceID, HostResource FROM Msvm_EthernetPortAllocationSettingData")
+	if err != nil {
+		return nil, err
+	}
+	// The guest data is only known when the integration services run.
+	kvps, err := client.Query(ctx, namespaceVirtualization, "SELECT SystemName, GuestIntrinsicExchangeItems FROM Msvm_KvpExchangeComponent")
+	if err != nil {
+		zap.S().Warnw("failed to read the guest data of the Hyper-V VMs", "host", hostID, "error", err)
+	}
+	// The virtual TPM only exists since Windows Server 2016.
+	security, err := client.Query(ctx, namespaceVirtualization, "SELECT InstanceID, TpmEnabled FROM Msvm_SecuritySettingData")
+	if err != nil {
+		zap.S().Debugw("failed to read the security settings of the Hyper-V VMs", "host", hostID, "error", err)
+	}
+
+	vms := make(map[string]*vspheremodel.VM, len(systems))
+	ordered := make([]*vspheremodel.VM, 0, len(systems))
+	for _, s := range systems {
+		vm := &vspheremodel.VM{
+			Base: vspheremodel.Base{
+				ID:     strings.ToLower(s.String("Name")),
+				Name:   s.String("ElementName"),
+				Parent: vspheremodel.Ref{Kind: "Host", ID: hostID},
+			},
+			Host:            hostID,
+			Firmware:        "bios",
+			PowerState:      powerState(s.Int("EnabledState")),
+			ConnectionState: "connected",
+		}
+		vms[vm.ID] = vm
+		ordered = append(ordered, vm)
+	}
+
+	for _, s := range settings {
+		if vm := vms[strings.ToLower(s.String("ConfigurationID"))]; vm != nil {
+			vm.UUID = strings.ToLower(strings.Trim(s.String("BIOSGUID"), "{}"))
+			if s.String("VirtualSystemSubType") == vmSubTypeGen2 {
+				vm.Firmware = "efi"
+				vm.SecureBoot = s.Bool("SecureBootEnabled")
+			}
+		}
+	}
+	for _, p := range processors {
+		if vm := vms[settingVM(p.String("InstanceID"))]; vm != nil {
+			// Hyper-V presents the virtual processors as the cores of one socket.
+			vm.CpuCount = int32(p.Int("VirtualQuantity"))
+			vm.CoresPerSocket = vm.CpuCount
+		}
+	}
+	for _, m := range memory {
+		if vm := vms[settingVM(m.String("InstanceID"))]; vm != nil {
+			vm.MemoryMB = int32(m.Int("VirtualQuantity"))
+		}
+	}
+	for _, s := range security {
+		if vm := vms[settingVM(s.String("InstanceID"))]; vm != nil {
+			vm.TpmEnabled = s.Bool("TpmEnabled")
+		}
+	}
+
+	for i, d := range disks {
+		vm := vms[settingVM(d.String("InstanceID"))]
+		if vm == nil {
+			continue
+		}
+		file := d.String("HostResource")
+		disk := vspheremodel.Disk{
+			Key:        int32(2000 + i),
+			UnitNumber: int32(len(vm.Disks)),
+			File:       file,
+			Capacity:   fileSize(ctx, client, file),
+		}
+		if ds := volumeOf(volumes, file); ds != nil {
+			disk.Datastore = vspheremodel.Ref{Kind: "Datastore", ID: ds.ID}
+		}
+		vm.Disks = append(vm.Disks, disk)
+		vm.StorageUsed += disk.Capacity
+	}
+
+	for i, p := range ports {
+		vm := vms[settingVM(p.String("InstanceID"))]
+		if vm == nil {
+			continue
+		}
+		nic := vspheremodel.NIC{
+			MAC:       macAddress(p.String("Address")),
+			Index:     len(vm.NICs),
+			DeviceKey: int32(4000 + i),
+		}
+		for _, conn := range connections {
+			if !strings.HasPrefix(conn.String("InstanceID"), p.String("InstanceID")+`\`) {
+				continue
+			}
+			if m := switchNamePattern.FindStringSubmatch(conn.String("HostResource")); m != nil {
+				if network := networks[strings.ToLower(m[1])]; network != nil {
+					nic.Network = vspheremodel.Ref{Kind: "Network", ID: network.ID}
+					vm.Networks = append(vm.Networks, nic.Network)
+				}
+			}
+		}
+		vm.NICs = append(vm.NICs, nic)
+	}
+
+	for _, k := range kvps {
+		vm := vms[strings.ToLower(k.String("SystemName"))]
+		if vm == nil {
+			continue
+		}
+		items := hyperv.KvpItems(k.Strings("GuestIntrinsicExchangeItems"))
+		vm.GuestName = items["OSName"]
+		vm.GuestNameFromVmwareTools = items["OSName"]
+		vm.HostName = items["FullyQualifiedDomainName"]
+		if addresses := items["NetworkAddressIPv4"]; addresses != "" {
+			vm.IpAddress = strings.Split(addresses, ";")[0]
+		}
+	}
+
+	return ordered, nil
+}
+
+// write inserts the inventory in db, under a datacenter named by the domain of
+// the hosts.
+func (inv *hyperVInventory) write(db libmodel.DB) error {
+	const folderID = "group-h1"
+
+	cluster := &vspheremodel.Cluster{
+		Base: vspheremodel.Base{
+			ID:     inv.cluster,
+			Name:   inv.cluster,
+			Parent: vspheremodel.Ref{Kind: "Folder", ID: folderID},
+		},
+		Folder: folderID,
+	}
+	for _, h := range inv.hosts {
+		cluster.Hosts = append(cluster.Hosts, vspheremodel.Ref{Kind: "Host", ID: h.ID})
+	}
+
+	datastoreIDs := make([]string, 0, len(inv.datastores))
+	for id := range inv.datastores {
+		datastoreIDs = append(datastoreIDs, id)
+	}
+	sort.Strings(datastoreIDs)
+
+	items := []libmodel.Model{
+		&vspheremodel.About{
+			Base:       vspheremodel.Base{ID: "about"},
+			APIVersion: "v2",
+			Product:    "Microsoft Hyper-V",
+			// A stable identifier of the cluster, in place of the one of a vCenter.
+			InstanceUuid: uuid.NewSHA1(uuid.NameSpaceDNS, []byte(strings.ToLower(inv.cluster))).String(),
+		},
+		&vspheremodel.Datacenter{
+			Base: vspheremodel.Base{ID: inv.datacenter, Name: inv.datacenter},
+		},
+		&vspheremodel.Folder{
+			Base:       vspheremodel.Base{ID: folderID, Name: "host"},
+			Datacenter: inv.datacenter,
+			Children:   []vspheremodel.Ref{{Kind: "Cluster", ID: cluster.ID}},
+		},
+		cluster,
+	}
+	for _, h := range inv.hosts {
+		items = append(items, h)
+	}
+	for _, id := range datastoreIDs {
+		items = append(items, inv.datastores[id])
+	}
+	for _, n := range inv.networks {
+		items = append(items, n)
+	}
+	for _, vm := range inv.vms {
+		items = append(items, vm)
+	}
+
+	for _, m := range items {
+		if err := db.Insert(m); err != nil {
+			return fmt.Errorf("writing the Hyper-V inventory: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryOne returns the first instance selected by the query.
+func queryOne(ctx context.Context, client *hyperv.Client, namespace, wql string) (hyperv.Instance, error) {
+	instances, err := client.Query(ctx, namespace, wql)
+	if err != nil {
+		return hyperv.Instance{}, err
+	}
+	if len(instances) == 0 {
+		return hyperv.Instance{}, fmt.Errorf("%s: no instance", wql)
+	}
+	return instances[0], nil
+}
+
+// fileSize returns the size of the file of a virtual disk, the allocated size
+// for the dynamically expanding disks; 0 when it cannot be read.
+func fileSize(ctx context.Context, client *hyperv.Client, file string) int64 {
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(file)
+	instance, err := queryOne(ctx, client, namespaceCIMv2, "SELECT FileSize FROM CIM_DataFile WHERE Name = '"+escaped+"'")
+	if err != nil {
+		zap.S().Debugw("failed to read the size of a Hyper-V disk", "file", file, "error", err)
+		return 0
+	}
+	return instance.Int("FileSize")
+}
+
+// settingVM returns the ID of the VM of a setting, whose InstanceID is
+// Microsoft:<VM ID>\<setting>.
+func settingVM(instanceID string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(instanceID, "Microsoft:"), `\`)
+	return strings.ToLower(id)
+}
+
+// volumeID returns the GUID of the volume \\?\Volume{<GUID>}\.
+func volumeID(deviceID string) string {
+	id := strings.TrimPrefix(deviceID, `\\?\Volume`)
+	return strings.ToLower(strings.Trim(id, `{}\`))
+}
+
+// volumeOf returns the volume holding file, the one with the longest path
+// prefixing it.
+func volumeOf(volumes []*vspheremodel.Datastore, file string) *vspheremodel.Datastore {
+	var found *vspheremodel.Datastore
+	for _, v := range volumes {
+		prefix := strings.ToLower(v.Name) + `\`
+		if strings.HasPrefix(strings.ToLower(file), prefix) && (found == nil || len(v.Name) > len(found.Name)) {
+			found = v
+		}
+	}
+	return found
+}
+
+// powerState returns the vSphere power state of an EnabledState.
+func powerState(enabledState int64) string {
+	switch enabledState {
+	case enabledStateOn:
+		return "poweredOn"
+	case enabledStateOff:
+		return "poweredOff"
+	default:
+		// paused, saved
+		return "suspended"
+	}
+}
+
+// macAddress formats the 12 hexadecimal digits of a Hyper-V MAC address.
+func macAddress(address string) string {
+	if len(address) != 12 {
+		return strings.ToLower(address)
+	}
+	parts := make([]string, 0, 6)
+	for i := 0; i < 12; i += 2 {
+		parts = append(parts, address[i:i+2])
+	}
+	return strings.ToLower(strings.Join(parts, ":"))
+}
@@ -0,0 +1,379 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	vspheremodel "github.com/kubev2v/forklift/pkg/controller/provider/model/vsphere"
+	libmodel "github.com/kubev2v/forklift/pkg/lib/inventory/model"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/ovirt"
+)
+
+// OVirtCollector collects the inventory of an oVirt or RHV engine through its
+// REST API. Like HyperVCollector, it writes the inventory in the sqlite
+// database of the forklift vSphere model, so it is parsed and evaluated like a
+// vCenter inventory:
+//
+//	data center         → Datacenter
+//	cluster             → Cluster
+//	host                → Host
+//	data storage domain → Datastore
+//	logical network     → Network
+//	virtual machine     → VM
+type OVirtCollector struct {
+	db     libmodel.DB
+	dbPath string
+	// proxy of the requests to the engine, the HTTP(S)_PROXY environment variables when nil
+	proxy func(*http.Request) (*url.URL, error)
+}
+
+func NewOVirtCollector(dbPath string) *OVirtCollector {
+	return &OVirtCollector{
+		dbPath: dbPath,
+	}
+}
+
+func (c *OVirtCollector) VerifyCredentials(ctx context.Context, creds *models.Credentials) error {
+	client, err := ovirt.NewClient(creds.URL, creds.Username, creds.Password, true, c.proxy)
+	if err != nil {
+		return err
+	}
+
+	verifyCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	zap.S().Named("collector").Info("verifying oVirt credentials")
+	if _, err := client.ProductInfo(verifyCtx); err != nil {
+		if errors.Is(err, ovirt.ErrUnauthorized) {
+			return srvErrors.NewVCenterError(err)
+		}
+		return srvErrors.NewTransientError(err)
+	}
+
+	zap.S().Named("collector").Info("oVirt credentials verified successfully")
+	return nil
+}
+
+func (c *OVirtCollector) Collect(ctx context.Context, creds *models.Credentials) error {
+	client, err := ovirt.NewClient(creds.URL, creds.Username, creds.Password, true, c.proxy)
+	if err != nil {
+		return err
+	}
+
+	zap.S().Infow("starting oVirt collection", "url", client.Endpoint())
+
+	inventory, err := readOVirtInventory(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	db, err := createDB(createProvider(creds), c.dbPath)
+	if err != nil {
+		return err
+	}
+	c.db = db
+
+	for _, m := range inventory {
+		if err := db.Insert(m); err != nil {
+			return fmt.Errorf("writing the oVirt inventory: %w", err)
+		}
+	}
+
+	zap.S().Infow("oVirt collection completed", "objects", len(inventory))
+	return nil
+}
+
+func (c *OVirtCollector) DB() libmodel.DB {
+	return c.db
+}
+
+func (c *OVirtCollector) DBPath() string {
+	return c.dbPath
+}
+
+// Close cleans up collector resources.
+func (c *OVirtCollector) Close() {
+	if c.db != nil {
+		_ = c.db.Close(false)
+	}
+}
+
+// readOVirtInventory returns the inventory of the engine of client in the
+// forklift vSphere model.
+func readOVirtInventory(ctx context.Context, client *ovirt.Client) ([]libmodel.Model, error) {
+	product, err := client.ProductInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dataCenters, err := client.DataCenters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := client.Clusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := client.Hosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := client.StorageDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := client.Networks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := client.VnicProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vms, err := client.VMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []libmodel.Model{
+		&vspheremodel.About{
+			Base:       vspheremodel.Base{ID: "about"},
+			APIVersion: product.Version.FullVersion,
+			Product:    product.Name,
+			// A stable identifier of the engine, in place of the one of a vCenter.
+			InstanceUuid: uuid.NewSHA1(uuid.NameSpaceURL, []byte(client.Endpoint())).String(),
+		},
+	}
+
+	// The hosts see the datastores and networks of their data center.
+	dcDatastores := map[string][]vspheremodel.Ref{}
+	dcNetworks := map[string][]vspheremodel.Ref{}
+	domainNames := map[string]string{}
+	for _, sd := range domains {
+		if sd.Type != "data" {
+			continue
+		}
+		domainNames[sd.ID] = sd.Name
+		ds := &vspheremodel.Datastore{
+			Base:            vspheremodel.Base{ID: sd.ID, Name: sd.Name},
+			Type:            strings.ToUpper(sd.Storage.Type),
+			Capacity:        int64(sd.Available + sd.Used),
+			Free:            int64(sd.Available),
+			MaintenanceMode: "normal",
+		}
+		if sd.Status == "maintenance" {
+			ds.MaintenanceMode = "inMaintenance"
+		}
+		items = append(items, ds)
+		for _, dc := range sd.DataCenters.Items {
+			dcDatastores[dc.ID] = append(dcDatastores[dc.ID], vspheremodel.Ref{Kind: "Datastore", ID: sd.ID})
+		}
+	}
+	for _, n := range networks {
+		network := &vspheremodel.Network{Base: vspheremodel.Base{ID: n.ID, Name: n.Name}}
+		if n.VLAN != nil {
+			network.VlanId = strconv.FormatInt(int64(n.VLAN.ID), 10)
+		}
+		items = append(items, network)
+		dcNetworks[n.DataCenter.ID] = append(dcNetworks[n.DataCenter.ID], vspheremodel.Ref{Kind: "Network", ID: n.ID})
+	}
+
+	for _, dc := range dataCenters {
+		items = append(items,
+			&vspheremodel.Datacenter{Base: vspheremodel.Base{ID: dc.ID, Name: dc.Name}},
+			&vspheremodel.Folder{
+				Base:       vspheremodel.Base{ID: hostFolder(dc.ID), Name: "host"},
+				Datacenter: dc.ID,
+			})
+	}
+
+	clusterDC := map[string]string{}
+	clusterHosts := map[string][]vspheremodel.Ref{}
+	for _, h := range hosts {
+		clusterHosts[h.Cluster.ID] = append(clusterHosts[h.Cluster.ID], vspheremodel.Ref{Kind: "Host", ID: h.ID})
+	}
+	for _, cl := range clusters {
+		clusterDC[cl.ID] = cl.DataCenter.ID
+		items = append(items, &vspheremodel.Cluster{
+			Base: vspheremodel.Base{
+				ID:     cl.ID,
+				Name:   cl.Name,
+				Parent: vspheremodel.Ref{Kind: "Folder", ID: hostFolder(cl.DataCenter.ID)},
+			},
+			Folder:     hostFolder(cl.DataCenter.ID),
+			Hosts:      clusterHosts[cl.ID],
+			Networks:   dcNetworks[cl.DataCenter.ID],
+			Datastores: dcDatastores[cl.DataCenter.ID],
+		})
+	}
+
+	for _, h := range hosts {
+		topology := h.CPU.Topology
+		host := &vspheremodel.Host{
+			Base: vspheremodel.Base{
+				ID:     h.ID,
+				Name:   h.Name,
+				Parent: vspheremodel.Ref{Kind: "Cluster", ID: h.Cluster.ID},
+			},
+			Cluster:        h.Cluster.ID,
+			Status:         "green",
+			CpuSockets:     int16(topology.Sockets),
+			CpuCores:       int16(topology.Sockets * topology.Cores),
+			MemoryBytes:    int64(h.Memory),
+			ProductName:    h.OS.Type,
+			ProductVersion: h.OS.Version.FullVersion,
+			Model:          h.HardwareInformation.ProductName,
+			Vendor:         h.HardwareInformation.Manufacturer,
+			ManagementIPs:  []string{h.Address},
+			Networks:       dcNetworks[clusterDC[h.Cluster.ID]],
+			Datastores:     dcDatastores[clusterDC[h.Cluster.ID]],
+		}
+		if h.Status != "up" {
+			host.Status = "red"
+		}
+		items = append(items, host)
+	}
+
+	profileNetworks := map[string]string{}
+	for _, p := range profiles {
+		profileNetworks[p.ID] = p.Network.ID
+	}
+	for _, v := range vms {
+		items = append(items, oVirtVM(v, clusterHosts, domainNames, profileNetworks))
+	}
+
+	return items, nil
+}
+
+// oVirtVM returns the VM v in the vSphere model.
+func oVirtVM(v ovirt.VM, clusterHosts map[string][]vspheremodel.Ref, domainNames, profileNetworks map[string]string) *vspheremodel.VM {
+	// A vSphere VM is always registered on a host, an oVirt VM only runs on
+	// one. A VM that is down is placed on the first host of its cluster, so its
+	// cluster and data center are known.
+	var hostID string
+	if v.Host != nil {
+		hostID = v.Host.ID
+	} else if hosts := clusterHosts[v.Cluster.ID]; len(hosts) > 0 {
+		hostID = hosts[0].ID
+	}
+
+	topology := v.CPU.Topology
+	threads := max(topology.Threads, 1)
+	vm := &vspheremodel.VM{
+		Base: vspheremodel.Base{
+			ID:     v.ID,
+			Name:   v.Name,
+			Parent: vspheremodel.Ref{Kind: "Host", ID: hostID},
+		},
+		Host:            hostID,
+		UUID:            v.ID,
+		Firmware:        "bios",
+		PowerState:      oVirtPowerState(v.Status),
+		ConnectionState: "connected",
+		CpuCount:        int32(topology.Sockets * topology.Cores * threads),
+		CoresPerSocket:  int32(topology.Cores * threads),
+		MemoryMB:        int32(v.Memory / (1 << 20)),
+		GuestName:       v.OS.Type,
+		HostName:        v.FQDN,
+		TpmEnabled:      bool(v.TPMEnabled),
+	}
+	switch v.BIOS.Type {
+	case "q35_ovmf":
+		vm.Firmware = "efi"
+	case "q35_secure_boot":
+		vm.Firmware = "efi"
+		vm.SecureBoot = true
+	}
+	if v.Status == "not_responding" || v.Status == "unknown" {
+		vm.ConnectionState = "disconnected"
+	}
+	if guest := v.GuestOperatingSystem; guest.Distribution != "" {
+		vm.GuestName = strings.TrimSpace(guest.Distribution + " " + guest.Version.FullVersion)
+		vm.GuestNameFromVmwareTools = vm.GuestName
+	}
+
+	for _, device := range v.ReportedDevices.Items {
+		for _, ip := range device.IPs.Items {
+			if vm.IpAddress == "" && ip.Version == "v4" {
+				vm.IpAddress = ip.Address
+			}
+		}
+	}
+
+	for i, a := range v.DiskAttachments.Items {
+		disk := vspheremodel.Disk{
+			Key:        int32(2000 + i),
+			UnitNumber: int32(i),
+			File:       a.Disk.Alias,
+			Capacity:   int64(a.Disk.ProvisionedSize),
+			Shared:     bool(a.Disk.Shareable),
+			RDM:        a.Disk.StorageType == "lun",
+			Bus:        diskBus(a.Interface),
+		}
+		if domains := a.Disk.StorageDomains.Items; len(domains) > 0 {
+			disk.Datastore = vspheremodel.Ref{Kind: "Datastore", ID: domains[0].ID}
+			disk.File = fmt.Sprintf("[%s] %s", domainNames[domains[0].ID], a.Disk.Alias)
+		}
+		vm.Disks = append(vm.Disks, disk)
+		vm.StorageUsed += int64(a.Disk.ActualSize)
+	}
+
+	for i, n := range v.NICs.Items {
+		nic := vspheremodel.NIC{
+			MAC:       strings.ToLower(n.MAC.Address),
+			Index:     i,
+			DeviceKey: int32(4000 + i),
+		}
+		if n.VnicProfile != nil {
+			if network := profileNetworks[n.VnicProfile.ID]; network != "" {
+				nic.Network = vspheremodel.Ref{Kind: "Network", ID: network}
+				vm.Networks = append(vm.Networks, nic.Network)
+			}
+		}
+		vm.NICs = append(vm.NICs, nic)
+	}
+
+	return vm
+}
+
+// hostFolder returns the ID of the host folder of a data center, the parent of
+// its clusters.
+func hostFolder(dataCenterID string) string {
+	return "group-h-" + dataCenterID
+}
+
+// oVirtPowerState returns the vSphere power state of the status of a VM.
+func oVirtPowerState(status string) string {
+	switch status {
+	case "down", "image_locked":
+		return "poweredOff"
+	case "suspended", "paused", "saving_state", "restoring_state":
+		return "suspended"
+	default:
+		// up, powering up or down, migrating, rebooting...
+		return "poweredOn"
+	}
+}
+
+// diskBus returns the vSphere bus of the interface of a disk attachment.
+func diskBus(iface string) string {
+	switch iface {
+	case "virtio_scsi", "spapr_vscsi":
+		return "scsi"
+	case "virtio":
+		return "virtio"
+	default:
+		// ide, sata
+		return iface
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/vmware"
 )
 
 type Collector interface {
@@ -41,6 +43,8 @@ type VSphereCollector struct {
 	container *libcontainer.Container
 	db        libmodel.DB
 	dbPath    string
+	// proxy of the requests to vCenter, the HTTP(S)_PROXY environment variables when nil
+	proxy func(*http.Request) (*url.URL, error)
 }
 
 func NewVSphereCollector(dbPath string) *VSphereCollector {
@@ -62,10 +66,16 @@ func (c *VSphereCollector) VerifyCredentials(ctx context.Context, creds *models.
 	verifyCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	vimClient, err := vim25.NewClient(verifyCtx, soap.NewClient(u, true))
+	soapClient := soap.NewClient(u, true)
+	if c.proxy != nil {
+		soapClient.DefaultTransport().Proxy = c.proxy
+	}
+
+	vimClient, err := vim25.NewClient(verifyCtx, soapClient)
 	if err != nil {
-		return err
+		return srvErrors.NewTransientError(err)
 	}
+	vmware.TraceCalls(vimClient)
 
 	client := &govmomi.Client{
 		SessionManager: session.NewManager(vimClient),
@@ -2,8 +2,9 @@ package collector
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path"
 
@@ -11,19 +12,19 @@ import (
 
 	"github.com/google/uuid"
 
-	"github.com/kubev2v/migration-planner/pkg/inventory/converters"
-
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 )
 
 // WorkBuilder builds a sequence of WorkUnits for the v1 collector workflow.
 type WorkBuilder struct {
-	collector      *VSphereCollector
+	collector      Collector
 	store          *store.Store
 	opaPoliciesDir string
 	dataDir        string
 	creds          *models.Credentials
+	extras         *Extras
+	proxy          func(*http.Request) (*url.URL, error)
 }
 
 // NewWorkBuilder creates a new v1 work builder.
@@ -35,6 +36,14 @@ func NewWorkBuilder(s *store.Store, dataDir, opaPoliciesDir string) *WorkBuilder
 	}
 }
 
+// WithProxy sets the proxy of the requests to vCenter, the Hyper-V hosts or the
+// oVirt engine. The forklift collector reading the vSphere inventory only
+// follows the HTTP(S)_PROXY environment variables.
+func (b *WorkBuilder) WithProxy(proxy func(*http.Request) (*url.URL, error)) *WorkBuilder {
+	b.proxy = proxy
+	return b
+}
+
 // WithCredentials sets the credentials for the workflow.
 func (b *WorkBuilder) WithCredentials(creds *models.Credentials) models.WorkBuilder {
 	b.creds = creds
@@ -49,7 +58,26 @@ func (b *WorkBuilder) Build() []models.WorkUnit {
 	// It panics when the user stop and collect again but, because the collection step cannot be
 	// stoped, it can happen that db can be full when the process stops.
 
-	b.collector = NewVSphereCollector(path.Join(b.dataDir, fmt.Sprintf("%s.db", uuid.New())))
+	dbPath := path.Join(b.dataDir, fmt.Sprintf("%s.db", uuid.New()))
+	var provider models.Provider
+	if b.creds != nil {
+		provider = b.creds.Provider
+	}
+	switch provider {
+	case models.ProviderHyperV:
+		collector := NewHyperVCollector(dbPath)
+		collector.proxy = b.proxy
+		b.collector = collector
+	case models.ProviderOVirt:
+		collector := NewOVirtCollector(dbPath)
+		collector.proxy = b.proxy
+		b.collector = collector
+	default:
+		collector := NewVSphereCollector(dbPath)
+		collector.proxy = b.proxy
+		b.collector = collector
+	}
+	b.extras = &Extras{}
 	return []models.WorkUnit{
 		b.connecting(),
 		b.collecting(),
@@ -65,12 +93,12 @@ func (b *WorkBuilder) connecting() models.WorkUnit {
 		},
 		Work: func() func(ctx context.Context) (any, error) {
 			return func(ctx context.Context) (any, error) {
-				zap.S().Named("collector_service").Info("verifying vCenter credentials")
+				zap.S().Named("collector_service").Info("verifying credentials")
 				if err := b.collector.VerifyCredentials(ctx, b.creds); err != nil {
 					zap.S().Named("collector_service").Errorw("credential verification failed", "error", err)
 					return nil, err
 				}
-				zap.S().Named("collector_service").Info("vCenter credentials verified")
+				zap.S().Named("collector_service").Info("credentials verified")
 				return nil, nil
 			}
 		},
@@ -85,13 +113,23 @@ func (b *WorkBuilder) collecting() models.WorkUnit {
 		Work: func() func(ctx context.Context) (any, error) {
 			return func(ctx context.Context) (any, error) {
 				defer b.collector.Close()
-				zap.S().Named("collector_service").Info("starting vSphere inventory collection")
+				zap.S().Named("collector_service").Info("starting inventory collection")
 
 				if err := b.collector.Collect(ctx, b.creds); err != nil {
-					zap.S().Named("collector_service").Errorw("vSphere collection failed", "error", err)
+					zap.S().Named("collector_service").Errorw("inventory collection failed", "error", err)
 					return nil, err
 				}
-				zap.S().Named("collector_service").Info("vSphere inventory collection completed")
+				zap.S().Named("collector_service").Info("inventory collection completed")
+
+				// Extras are not part of the forklift model. A failure here should not fail the whole collection.
+				if collector, ok := b.collector.(extrasCollector); ok {
+					extras, err := collector.CollectExtras(ctx, b.creds)
+					if err != nil {
+						zap.S().Named("collector_service").Warnw("failed to collect vCenter extras", "error", err)
+						extras = &Extras{}
+					}
+					b.extras = extras
+				}
 
 				return nil, nil
 			}
@@ -122,6 +160,46 @@ func (b *WorkBuilder) parsing() models.WorkUnit {
 					return nil, err
 				}
 
+				if err := b.store.VMSecurity().IngestSqlite(ctx, sqlitePath, b.extras.EncryptedVMs); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to ingest vm security settings", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.VMSecurity().AddConcerns(ctx); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to add vm security concerns", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.Cluster().ReplaceRules(ctx, b.extras.ClusterRules); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to save cluster rules", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.Event().Replace(ctx, b.extras.Events); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to save vCenter events", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.Event().AddConcerns(ctx); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to add unhealthy vm concerns", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.Datastore().ReplaceStats(ctx, b.extras.DatastoreStats); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to save datastore stats", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.DiskChain().Replace(ctx, b.extras.DiskChains); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to save disk chains", "error", err)
+					return nil, err
+				}
+
+				if err := b.store.DiskChain().AddConcerns(ctx); err != nil {
+					zap.S().Named("collector_service").Errorw("failed to add disk chain concerns", "error", err)
+					return nil, err
+				}
+
 				if err := b.store.Checkpoint(); err != nil {
 					zap.S().Named("collector_service").Warnw("checkpoint after ingest failed", "error", err)
 				}
@@ -141,16 +219,12 @@ func (b *WorkBuilder) parsing() models.WorkUnit {
 					zap.S().Named("collector_service").Warnw("failed to remove sqlite file", "path", sqlitePath, "error", err)
 				}
 
-				inv, err := b.store.Parser().BuildInventory(ctx)
+				inventory, err := b.store.BuildInventory(ctx)
 				if err != nil {
-					return nil, fmt.Errorf("error building inventory: %w", err)
+					return nil, err
 				}
 
 				// Store the inventory
-				inventory, err := json.Marshal(converters.ToAPI(inv))
-				if err != nil {
-					return nil, fmt.Errorf("failed to marshal the inventory: %w", err)
-				}
 
 				if err := b.store.Inventory().Save(ctx, inventory); err != nil {
 					return nil, err
@@ -0,0 +1,162 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	serviceErrs "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// The commands the console pushes on the command channel of an agent.
+const (
+	// CommandSetMode sets the agent mode, Args being {"mode": "connected"|"disconnected"}
+	CommandSetMode = "set_mode"
+	// CommandDispatch sends the status and the inventory to the console without waiting for the update interval
+	CommandDispatch = "dispatch"
+	// CommandStopCollection stops the running collection
+	CommandStopCollection = "stop_collection"
+	// CommandCancelJob cancels a running job, Args being {"id": "<job id>"}
+	CommandCancelJob = "cancel_job"
+	// CommandPullConfiguration pulls the remote configuration without waiting for its interval
+	CommandPullConfiguration = "pull_configuration"
+)
+
+// The types of the messages the agent sends on its command channel.
+const (
+	ChannelMessageResult = "result"
+	ChannelMessageStatus = "status"
+)
+
+const (
+	channelHandshakeTimeout = 30 * time.Second
+	channelWriteWait        = 10 * time.Second
+	// channelPongWait bounds the silence of the console, which answers the pings
+	// of the agent sent every ChannelPingInterval
+	channelPongWait = 60 * time.Second
+)
+
+// ChannelPingInterval is the interval between two pings of the command channel.
+const ChannelPingInterval = 30 * time.Second
+
+// Command is pushed by the console on the command channel of an agent.
+type Command struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// ChannelStatus is the status of the agent sent on its command channel.
+type ChannelStatus struct {
+	Mode            string `json:"mode"`
+	ConsoleStatus   string `json:"consoleStatus"`
+	CollectorStatus string `json:"collectorStatus"`
+	CollectorError  string `json:"collectorError,omitempty"`
+	Version         string `json:"version"`
+}
+
+// ChannelMessage is sent by the agent on its command channel: the result of the
+// command ID, with the Error it failed with, or the Status of the agent.
+type ChannelMessage struct {
+	Type   string         `json:"type"`
+	ID     string         `json:"id,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Status *ChannelStatus `json:"status,omitempty"`
+}
+
+// Channel is the command channel of an agent, a websocket through which the
+// console pushes commands and receives the status of the agent as it changes.
+// Receive is called by a single goroutine, Send and Ping by any.
+type Channel struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// Receive waits for the next command of the console. It fails once the
+// console closed the channel or stopped answering the pings.
+func (ch *Channel) Receive() (Command, error) {
+	var cmd Command
+	if err := ch.conn.ReadJSON(&cmd); err != nil {
+		return Command{}, serviceErrs.NewTransientError(err)
+	}
+	return cmd, nil
+}
+
+// Send sends msg to the console.
+func (ch *Channel) Send(msg ChannelMessage) error {
+	ch.writeMu.Lock()
+	defer ch.writeMu.Unlock()
+
+	if err := ch.conn.SetWriteDeadline(time.Now().Add(channelWriteWait)); err != nil {
+		return serviceErrs.NewTransientError(err)
+	}
+	if err := ch.conn.WriteJSON(msg); err != nil {
+		return serviceErrs.NewTransientError(err)
+	}
+	return nil
+}
+
+// Ping checks the console is still there, Receive failing when it does not
+// answer in time.
+func (ch *Channel) Ping() error {
+	if err := ch.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(channelWriteWait)); err != nil {
+		return serviceErrs.NewTransientError(err)
+	}
+	return nil
+}
+
+// Close closes the channel, unblocking Receive.
+func (ch *Channel) Close() error {
+	return ch.conn.Close()
+}
+
+// OpenCommandChannel opens the command channel of the agent, a websocket
+// upgraded from the request.
+// GET /api/v1/agents/{id}/channel
+func (c *Client) OpenCommandChannel(ctx context.Context, agentID uuid.UUID) (*Channel, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u = u.JoinPath("api/v1/agents", agentID.String(), "channel")
+
+	header := http.Header{}
+	if jwt := c.token.Get(); jwt != "" {
+		header.Set("X-Agent-Token", jwt)
+	}
+	dialer := websocket.Dialer{
+		Proxy:            c.proxy,
+		HandshakeTimeout: channelHandshakeTimeout,
+	}
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		// a console refusing the upgrade answers like to the other requests
+		if resp != nil {
+			defer resp.Body.Close()
+			if statusErr := statusError(resp, "open command channel"); statusErr != nil {
+				return nil, statusErr
+			}
+		}
+		return nil, serviceErrs.NewTransientError(fmt.Errorf("failed to open command channel: %w", err))
+	}
+
+	// the pings of the agent keep the channel open, their pongs pushing the deadline
+	_ = conn.SetReadDeadline(time.Now().Add(channelPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(channelPongWait))
+	})
+	return &Channel{conn: conn}, nil
+}
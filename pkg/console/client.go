@@ -1,16 +1,20 @@
 package console
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/google/uuid"
-	externalRef0 "github.com/kubev2v/migration-planner/api/v1alpha1"
 	apiAgent "github.com/kubev2v/migration-planner/api/v1alpha1/agent"
 	agentClient "github.com/kubev2v/migration-planner/pkg/client"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	serviceErrs "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 )
@@ -18,25 +22,253 @@ import (
 type Client struct {
 	baseURL    string
 	httpClient *agentClient.Client
-	jwt        string
+	// doer sends the requests of the endpoints missing from the generated client
+	doer  *http.Client
+	token *Token
+	// proxy routes the command channel like the requests
+	proxy func(*http.Request) (*url.URL, error)
 }
 
-func NewConsoleClient(baseURL string, jwt string) (*Client, error) {
-	httpClient, err := agentClient.NewClient(baseURL, agentClient.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
-		if jwt == "" {
+// ClientOption configures the transport of the console client.
+type ClientOption func(t *http.Transport)
+
+// WithProxy routes the requests through the proxy returned by proxy, a nil URL
+// meaning a direct connection. Without it the HTTP(S)_PROXY environment
+// variables are used.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) ClientOption {
+	return func(t *http.Transport) {
+		t.Proxy = proxy
+	}
+}
+
+func NewConsoleClient(baseURL string, jwt string, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		baseURL: baseURL,
+		token:   NewToken(jwt),
+		proxy:   http.ProxyFromEnvironment,
+	}
+	clientOpts := []agentClient.ClientOption{
+		agentClient.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			c.setToken(req)
 			return nil
+		}),
+	}
+	var transport http.RoundTripper = http.DefaultTransport
+	if len(opts) > 0 {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		for _, o := range opts {
+			o(t)
 		}
-		req.Header.Add("X-Agent-Token", jwt)
-		return nil
-	}))
+		transport = t
+		c.proxy = t.Proxy
+	}
+	// a client span per request, propagating the trace to the console
+	doer := &http.Client{Transport: otelhttp.NewTransport(transport,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "console " + r.Method + " " + r.URL.Path
+		}),
+	)}
+	clientOpts = append(clientOpts, agentClient.WithHTTPClient(doer))
+
+	httpClient, err := agentClient.NewClient(baseURL, clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize console client: %w", err)
 	}
-	return &Client{
-		baseURL:    baseURL,
-		httpClient: httpClient,
-		jwt:        jwt,
-	}, nil
+	c.httpClient = httpClient
+	c.doer = doer
+	return c, nil
+}
+
+// WithToken makes the client send the JWT of token, shared with other clients
+// and swapped on rotation, in place of the one it was created with.
+func (c *Client) WithToken(token *Token) *Client {
+	c.token = token
+	return c
+}
+
+func (c *Client) setToken(req *http.Request) {
+	if jwt := c.token.Get(); jwt != "" {
+		req.Header.Set("X-Agent-Token", jwt)
+	}
+}
+
+// GetAgentConfiguration pulls the remote configuration of the agent
+// GET /api/v1/agents/{id}/configuration
+func (c *Client) GetAgentConfiguration(ctx context.Context, agentID uuid.UUID) (*config.Remote, error) {
+	u, err := url.JoinPath(c.baseURL, "api/v1/agents", agentID.String(), "configuration")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setToken(req)
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, serviceErrs.NewTransientError(err)
+	}
+	defer resp.Body.Close()
+
+	if err := statusError(resp, "get agent configuration"); err != nil {
+		return nil, err
+	}
+
+	var remote config.Remote
+	if err := json.NewDecoder(resp.Body).Decode(&remote); err != nil {
+		return nil, fmt.Errorf("failed to decode agent configuration: %w", err)
+	}
+	return &remote, nil
+}
+
+// Register registers the agent with the provisioning token, before it has
+// an agent id, and returns the agent and source ids the console gave it.
+// POST /api/v1/agents/register
+func (c *Client) Register(ctx context.Context, provisioningToken string, body models.RegistrationRequest) (*models.Registration, error) {
+	u, err := url.JoinPath(c.baseURL, "api/v1/agents/register")
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Provisioning-Token", provisioningToken)
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, serviceErrs.NewTransientError(err)
+	}
+	defer resp.Body.Close()
+
+	if err := statusError(resp, "register agent"); err != nil {
+		return nil, err
+	}
+
+	var registration models.Registration
+	if err := json.NewDecoder(resp.Body).Decode(&registration); err != nil {
+		return nil, fmt.Errorf("failed to decode agent registration: %w", err)
+	}
+	return &registration, nil
+}
+
+// maxPolicyBundleSize bounds the size of a downloaded policy bundle.
+const maxPolicyBundleSize = 16 << 20
+
+// GetPolicyBundle downloads the OPA policy bundle at bundleURL, resolved
+// against the console URL when relative. etag, the ETag of the bundle in use,
+// makes the request conditional: nil is returned when the bundle did not change.
+// GET {bundleURL}
+func (c *Client) GetPolicyBundle(ctx context.Context, bundleURL, etag string) (*models.PolicyBundle, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(bundleURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.ResolveReference(ref).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setToken(req)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, serviceErrs.NewTransientError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if err := statusError(resp, "get policy bundle"); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicyBundleSize+1))
+	if err != nil {
+		return nil, serviceErrs.NewTransientError(fmt.Errorf("failed to read policy bundle: %w", err))
+	}
+	if len(data) > maxPolicyBundleSize {
+		return nil, fmt.Errorf("policy bundle larger than %d bytes", maxPolicyBundleSize)
+	}
+
+	return &models.PolicyBundle{ETag: resp.Header.Get("ETag"), Data: data}, nil
+}
+
+// maxUpdateManifestSize bounds the size of an update manifest.
+const maxUpdateManifestSize = 64 << 10
+
+// GetUpdateManifest pulls the manifest of the latest agent version on the
+// update channel at channelURL, resolved against the console URL when relative.
+// GET {channelURL}
+func (c *Client) GetUpdateManifest(ctx context.Context, channelURL string) (*models.UpdateManifest, error) {
+	resp, err := c.get(ctx, channelURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := statusError(resp, "get update manifest"); err != nil {
+		return nil, err
+	}
+
+	var manifest models.UpdateManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxUpdateManifestSize)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode update manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// DownloadUpdate streams the agent binary at binaryURL, resolved against the
+// console URL when relative. The caller closes the returned body.
+// GET {binaryURL}
+func (c *Client) DownloadUpdate(ctx context.Context, binaryURL string) (io.ReadCloser, error) {
+	resp, err := c.get(ctx, binaryURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := statusError(resp, "download update"); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// get sends an authenticated GET request to ref, resolved against the console URL.
+func (c *Client) get(ctx context.Context, ref string) (*http.Response, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.ResolveReference(u).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setToken(req)
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, serviceErrs.NewTransientError(err)
+	}
+	return resp, nil
 }
 
 // UpdateAgentStatus sends agent status to console.redhat.com
@@ -52,49 +284,48 @@ func (c *Client) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, sourc
 
 	resp, err := c.httpClient.UpdateAgentStatus(ctx, agentID, body)
 	if err != nil {
-		return err
+		return serviceErrs.NewTransientError(err)
 	}
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 
-	switch {
-	case resp.StatusCode >= 200 && resp.StatusCode < 300:
-		return nil
-	case resp.StatusCode >= 400 && resp.StatusCode < 500:
-		return serviceErrs.NewConsoleClientError(resp.StatusCode, resp.Status)
-	default:
-		return fmt.Errorf("failed to update agent status: %s", resp.Status)
-	}
+	return statusError(resp, "update agent status")
 }
 
 // UpdateSourceStatus sends source inventory to console.redhat.com
 // PUT /api/v1/sources/{id}/status
-func (c *Client) UpdateSourceStatus(ctx context.Context, sourceID, agentID uuid.UUID, inventory models.Inventory) error {
-	inv := externalRef0.Inventory{}
-	if err := json.Unmarshal(inventory.Data, &inv); err != nil {
-		return fmt.Errorf("failed to unmarshal inventory: %w", err)
+func (c *Client) UpdateSourceStatus(ctx context.Context, sourceID, agentID uuid.UUID, inventory *models.Inventory) error {
+	inv, err := inventory.Decoded()
+	if err != nil {
+		return err
 	}
 
 	body := apiAgent.SourceStatusUpdate{
 		AgentId:   agentID,
-		Inventory: inv,
+		Inventory: *inv,
 	}
 
 	resp, err := c.httpClient.UpdateSourceInventory(ctx, sourceID, body)
 	if err != nil {
-		return err
+		return serviceErrs.NewTransientError(err)
 	}
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 
+	return statusError(resp, "update source inventory")
+}
+
+// statusError returns nil for a 2xx response, a ConsoleClientError for a 4xx
+// one and a TransientError for the others, action naming the request.
+func statusError(resp *http.Response, action string) error {
 	switch {
 	case resp.StatusCode >= 200 && resp.StatusCode < 300:
 		return nil
 	case resp.StatusCode >= 400 && resp.StatusCode < 500:
 		return serviceErrs.NewConsoleClientError(resp.StatusCode, resp.Status)
 	default:
-		return fmt.Errorf("failed to update source inventory: %s", resp.Status)
+		return serviceErrs.NewTransientError(fmt.Errorf("failed to %s: %s", action, resp.Status))
 	}
 }
@@ -0,0 +1,40 @@
+package console
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token holds the agent's JWT sent to the console. It is shared by the clients
+// created on a configuration reload and swapped when the JWT is rotated.
+type Token struct {
+	v atomic.Value // string
+}
+
+func NewToken(jwt string) *Token {
+	t := &Token{}
+	t.v.Store(jwt)
+	return t
+}
+
+// Get returns the current JWT, empty when authentication is disabled.
+func (t *Token) Get() string {
+	return t.v.Load().(string)
+}
+
+// Set replaces the JWT sent by the next requests.
+func (t *Token) Set(jwt string) {
+	t.v.Store(jwt)
+}
+
+// Expiry returns the expiration time of the JWT, read without verifying its
+// signature, and false when the token has none or is not a JWT.
+func (t *Token) Expiry() (time.Time, bool) {
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(t.Get(), &claims); err != nil || claims.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+	return claims.ExpiresAt.Time, true
+}
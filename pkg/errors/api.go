@@ -0,0 +1,160 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code is the machine-readable code of an API error. The codes are stable, so
+// clients branch on them rather than on the messages.
+type Code string
+
+const (
+	CodeInvalidRequest       Code = "INVALID_REQUEST"
+	CodeUnauthorized         Code = "UNAUTHORIZED"
+	CodeForbidden            Code = "FORBIDDEN"
+	CodeNotFound             Code = "NOT_FOUND"
+	CodeFeatureDisabled      Code = "FEATURE_DISABLED"
+	CodeCollectionInProgress Code = "COLLECTION_IN_PROGRESS"
+	CodeBenchmarkInProgress  Code = "BENCHMARK_IN_PROGRESS"
+	CodeInspectorNotRunning  Code = "INSPECTOR_NOT_RUNNING"
+	CodeInvalidState         Code = "INVALID_STATE"
+	CodeModeConflict         Code = "MODE_CONFLICT"
+	CodeSourceConflict       Code = "SOURCE_CONFLICT"
+	CodeJobNotRunning        Code = "JOB_NOT_RUNNING"
+	CodeInvalidBundle        Code = "INVALID_BUNDLE"
+	CodePayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+	CodeRateLimited          Code = "RATE_LIMITED"
+	CodeStandby              Code = "STANDBY"
+	CodeVCenterError         Code = "VCENTER_ERROR"
+	CodeConsoleError         Code = "CONSOLE_ERROR"
+	CodeUpstreamError        Code = "UPSTREAM_ERROR"
+	CodeInternal             Code = "INTERNAL_ERROR"
+)
+
+// codeSpec is the HTTP status of the responses of a code and whether the same
+// request may succeed later.
+type codeSpec struct {
+	status    int
+	retryable bool
+}
+
+var codeSpecs = map[Code]codeSpec{
+	CodeInvalidRequest:       {http.StatusBadRequest, false},
+	CodeUnauthorized:         {http.StatusUnauthorized, false},
+	CodeForbidden:            {http.StatusForbidden, false},
+	CodeNotFound:             {http.StatusNotFound, false},
+	CodeFeatureDisabled:      {http.StatusNotFound, false},
+	CodeCollectionInProgress: {http.StatusConflict, true},
+	CodeBenchmarkInProgress:  {http.StatusConflict, true},
+	CodeInspectorNotRunning:  {http.StatusNotFound, false},
+	CodeInvalidState:         {http.StatusBadRequest, true},
+	CodeModeConflict:         {http.StatusConflict, false},
+	CodeSourceConflict:       {http.StatusConflict, false},
+	CodeJobNotRunning:        {http.StatusConflict, false},
+	CodeInvalidBundle:        {http.StatusBadRequest, false},
+	CodePayloadTooLarge:      {http.StatusRequestEntityTooLarge, false},
+	CodeRateLimited:          {http.StatusTooManyRequests, true},
+	CodeStandby:              {http.StatusServiceUnavailable, true},
+	CodeVCenterError:         {http.StatusBadGateway, true},
+	CodeConsoleError:         {http.StatusBadGateway, true},
+	CodeUpstreamError:        {http.StatusBadGateway, true},
+	CodeInternal:             {http.StatusInternalServerError, true},
+}
+
+// APIError is the error of an API response, sent as the error field of an
+// ErrorResponse.
+type APIError struct {
+	Code    Code           `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+	// Retryable tells whether the same request may succeed later
+	Retryable bool `json:"retryable"`
+}
+
+// ErrorResponse is the body of the API error responses:
+//
+//	{"error": {"code": "COLLECTION_IN_PROGRESS", "message": "...", "retryable": true}}
+type ErrorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+// NewAPIError returns the error of code with message, retryable when the code is.
+func NewAPIError(code Code, message string) *APIError {
+	return &APIError{Code: code, Message: message, Retryable: codeSpecs[code].retryable}
+}
+
+// WithDetails sets the details of the error, such as the id of a resource.
+func (e *APIError) WithDetails(details map[string]any) *APIError {
+	e.Details = details
+	return e
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// HTTPStatus returns the HTTP status of the responses of the error code, 500
+// for an unknown code.
+func (e *APIError) HTTPStatus() int {
+	if spec, ok := codeSpecs[e.Code]; ok {
+		return spec.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Response returns the body of the response of the error.
+func (e *APIError) Response() ErrorResponse {
+	return ErrorResponse{Error: e}
+}
+
+func IsAPIError(err error) bool {
+	var e *APIError
+	return errors.As(err, &e)
+}
+
+// ToAPIError returns the APIError of err: the one err wraps, else the error of
+// the code of the error types of this package, else an internal error.
+func ToAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var notFound *ResourceNotFoundError
+	if errors.As(err, &notFound) {
+		details := map[string]any{"kind": notFound.Kind}
+		if notFound.ID != "" {
+			details["id"] = notFound.ID
+		}
+		return NewAPIError(CodeNotFound, err.Error()).WithDetails(details)
+	}
+
+	var consoleErr *ConsoleClientError
+	if errors.As(err, &consoleErr) {
+		return NewAPIError(CodeConsoleError, err.Error()).
+			WithDetails(map[string]any{"statusCode": consoleErr.StatusCode})
+	}
+
+	switch {
+	case IsCollectionInProgressError(err):
+		return NewAPIError(CodeCollectionInProgress, err.Error())
+	case IsBenchmarkInProgressError(err):
+		return NewAPIError(CodeBenchmarkInProgress, err.Error())
+	case IsInspectorNotRunningError(err):
+		return NewAPIError(CodeInspectorNotRunning, err.Error())
+	case IsInvalidStateError(err):
+		return NewAPIError(CodeInvalidState, err.Error())
+	case IsModeConflictError(err):
+		return NewAPIError(CodeModeConflict, err.Error())
+	case IsSourceConflictError(err):
+		return NewAPIError(CodeSourceConflict, err.Error())
+	case IsJobNotRunningError(err):
+		return NewAPIError(CodeJobNotRunning, err.Error())
+	case IsInvalidBundleError(err):
+		return NewAPIError(CodeInvalidBundle, err.Error())
+	case IsVCenterError(err):
+		return NewAPIError(CodeVCenterError, err.Error())
+	}
+	return NewAPIError(CodeInternal, err.Error())
+}
@@ -10,10 +10,14 @@
 //	├──────────────────────────┼────────┼─────────────────────────────────────┤
 //	│ ResourceNotFoundError    │ 404    │ Requested resource doesn't exist    │
 //	│ CollectionInProgressError│ 409    │ Collection already running          │
-//	│ InvalidStateError        │ 500    │ Invalid state for operation         │
+//	│ BenchmarkInProgressError │ 409    │ Benchmark already running           │
+//	│ InvalidStateError        │ 400    │ Invalid state for operation         │
 //	│ ModeConflictError        │ 409    │ Mode change blocked by fatal error  │
-//	│ VCenterError             │ 500    │ vCenter connection/auth failure     │
-//	│ ConsoleClientError       │ 4xx    │ HTTP error from console.redhat.com  │
+//	│ SourceConflictError      │ 409    │ Source already or always managed    │
+//	│ JobNotRunningError       │ 409    │ Job canceled after it finished      │
+//	│ InvalidBundleError       │ 400    │ Assessment bundle failing checks    │
+//	│ VCenterError             │ 502    │ vCenter connection/auth failure     │
+//	│ ConsoleClientError       │ 502    │ HTTP 4xx from console.redhat.com    │
 //	└──────────────────────────┴────────┴─────────────────────────────────────┘
 //
 // # ResourceNotFoundError
@@ -28,7 +32,8 @@
 // Usage:
 //
 //	if errors.IsResourceNotFoundError(err) {
-//	    c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+//	    apiErr := errors.ToAPIError(err)
+//	    c.JSON(apiErr.HTTPStatus(), apiErr.Response())
 //	}
 //
 // # CollectionInProgressError
@@ -42,9 +47,17 @@
 // Usage:
 //
 //	if errors.IsCollectionInProgressError(err) {
-//	    c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+//	    apiErr := errors.ToAPIError(err)
+//	    c.JSON(apiErr.HTTPStatus(), apiErr.Response())
 //	}
 //
+// # BenchmarkInProgressError
+//
+// Indicates an attempt to start a benchmark while one is already running.
+//
+// Constructor:
+//   - NewBenchmarkInProgressError()
+//
 // # InvalidStateError
 //
 // Indicates the operation cannot be performed in the current state.
@@ -55,7 +68,8 @@
 // Usage:
 //
 //	if errors.IsInvalidStateError(err) {
-//	    c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+//	    apiErr := errors.ToAPIError(err)
+//	    c.JSON(apiErr.HTTPStatus(), apiErr.Response())
 //	}
 //
 // # ModeConflictError
@@ -70,9 +84,34 @@
 // Usage:
 //
 //	if errors.IsModeConflictError(err) {
-//	    c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+//	    apiErr := errors.ToAPIError(err)
+//	    c.JSON(apiErr.HTTPStatus(), apiErr.Response())
 //	}
 //
+// # SourceConflictError
+//
+// Indicates a source the agent cannot add, as it already manages it, or
+// cannot remove, as it is the primary source of the agent.
+//
+// Constructor:
+//   - NewSourceConflictError(id, reason string)
+//
+// # JobNotRunningError
+//
+// Indicates a job which cannot be canceled, as it already completed, failed or
+// was canceled.
+//
+// Constructor:
+//   - NewJobNotRunningError(id, state string)
+//
+// # InvalidBundleError
+//
+// Indicates an assessment bundle which is not a valid archive, whose files do
+// not match its signed manifest, or which is not signed by the expected key.
+//
+// Constructor:
+//   - NewInvalidBundleError(format string, args ...any)
+//
 // # VCenterError
 //
 // Wraps errors from vCenter connections with user-friendly messages.
@@ -109,6 +148,35 @@
 //	    // Fatal error - console service should stop
 //	}
 //
+// # Retryable Errors
+//
+// IsRetryable tells whether the operation that failed with an error may succeed
+// if tried again, so callers stop retrying without switching on status codes.
+// The clients classify their errors by wrapping them:
+//
+//   - NewTransientError(err) - e.g. connection failures and 5xx responses
+//   - NewFatalError(err) - errors that will happen again until something changes
+//
+// Error() and Unwrap() pass through to the wrapped error, so IsConsoleClientError
+// and the other helpers still see it. The unwrapped errors are classified by type:
+//
+//	┌──────────────────────┬──────────────────────────────────────┐
+//	│ Error                │ Retryable                            │
+//	├──────────────────────┼──────────────────────────────────────┤
+//	│ FatalError           │ no                                   │
+//	│ TransientError       │ yes                                  │
+//	│ ConsoleClientError   │ only for 408 and 429                 │
+//	│ VCenterError         │ unless the credentials are invalid   │
+//	│ APIError             │ as its code                          │
+//	│ any other error      │ yes                                  │
+//	└──────────────────────┴──────────────────────────────────────┘
+//
+// Usage:
+//
+//	if !errors.IsRetryable(err) {
+//	    // stop retrying
+//	}
+//
 // # Type Checking Pattern
 //
 // All error types provide Is* helper functions that use errors.As
@@ -124,18 +192,51 @@
 //	wrapped := fmt.Errorf("operation failed: %w", errors.NewInventoryNotFoundError())
 //	errors.IsResourceNotFoundError(wrapped) // returns true
 //
+// # API Errors
+//
+// APIError is the error of the API responses: a machine-readable Code, a
+// message, optional details and whether the request is retryable. The code
+// sets the HTTP status of the response and whether it is retryable:
+//
+//	┌────────────────────────┬────────┬───────────┐
+//	│ Code                   │ HTTP   │ Retryable │
+//	├────────────────────────┼────────┼───────────┤
+//	│ INVALID_REQUEST        │ 400    │ no        │
+//	│ INVALID_STATE          │ 400    │ yes       │
+//	│ INVALID_BUNDLE         │ 400    │ no        │
+//	│ UNAUTHORIZED           │ 401    │ no        │
+//	│ FORBIDDEN              │ 403    │ no        │
+//	│ NOT_FOUND              │ 404    │ no        │
+//	│ FEATURE_DISABLED       │ 404    │ no        │
+//	│ INSPECTOR_NOT_RUNNING  │ 404    │ no        │
+//	│ COLLECTION_IN_PROGRESS │ 409    │ yes       │
+//	│ BENCHMARK_IN_PROGRESS  │ 409    │ yes       │
+//	│ MODE_CONFLICT          │ 409    │ no        │
+//	│ SOURCE_CONFLICT        │ 409    │ no        │
+//	│ JOB_NOT_RUNNING        │ 409    │ no        │
+//	│ PAYLOAD_TOO_LARGE      │ 413    │ no        │
+//	│ RATE_LIMITED           │ 429    │ yes       │
+//	│ INTERNAL_ERROR         │ 500    │ yes       │
+//	│ VCENTER_ERROR          │ 502    │ yes       │
+//	│ CONSOLE_ERROR          │ 502    │ yes       │
+//	│ UPSTREAM_ERROR         │ 502    │ yes       │
+//	│ STANDBY                │ 503    │ yes       │
+//	└────────────────────────┴────────┴───────────┘
+//
+// ToAPIError maps the error types of this package to their code, wrapped or
+// not, and any other error to INTERNAL_ERROR. It is sent as the error field of
+// an ErrorResponse:
+//
+//	{"error": {"code": "NOT_FOUND", "message": "inventory not found", "details": {"kind": "inventory"}, "retryable": false}}
+//
 // # Handler Error Mapping
 //
-// Handlers typically map errors to HTTP status codes:
-//
-//	switch {
-//	case errors.IsResourceNotFoundError(err):
-//	    c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-//	case errors.IsCollectionInProgressError(err):
-//	    c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-//	case errors.IsModeConflictError(err):
-//	    c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-//	default:
-//	    c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
-//	}
+// Handlers respond with the APIError of the errors of the services:
+//
+//	apiErr := errors.ToAPIError(err)
+//	c.JSON(apiErr.HTTPStatus(), apiErr.Response())
+//
+// and build the others from their code:
+//
+//	apiErr := errors.NewAPIError(errors.CodeInvalidRequest, "invalid url format")
 package errors
@@ -52,6 +52,22 @@ func IsCollectionInProgressError(err error) bool {
 	return errors.As(err, &e)
 }
 
+// BenchmarkInProgressError indicates a benchmark is already running.
+type BenchmarkInProgressError struct{}
+
+func NewBenchmarkInProgressError() *BenchmarkInProgressError {
+	return &BenchmarkInProgressError{}
+}
+
+func (e *BenchmarkInProgressError) Error() string {
+	return "benchmark already in progress"
+}
+
+func IsBenchmarkInProgressError(err error) bool {
+	var e *BenchmarkInProgressError
+	return errors.As(err, &e)
+}
+
 // InvalidStateError indicates an invalid state for the requested operation.
 type InvalidStateError struct{}
 
@@ -89,11 +105,73 @@ func IsModeConflictError(err error) bool {
 	return errors.As(err, &e)
 }
 
+// SourceConflictError indicates a source which cannot be added or removed,
+// such as a source the agent already manages or its primary source.
+type SourceConflictError struct {
+	ID     string
+	Reason string
+}
+
+func NewSourceConflictError(id, reason string) *SourceConflictError {
+	return &SourceConflictError{ID: id, Reason: reason}
+}
+
+func (e *SourceConflictError) Error() string {
+	return fmt.Sprintf("source %s: %s", e.ID, e.Reason)
+}
+
+func IsSourceConflictError(err error) bool {
+	var e *SourceConflictError
+	return errors.As(err, &e)
+}
+
+// JobNotRunningError indicates a job which cannot be canceled as it already
+// finished.
+type JobNotRunningError struct {
+	ID    string
+	State string
+}
+
+func NewJobNotRunningError(id, state string) *JobNotRunningError {
+	return &JobNotRunningError{ID: id, State: state}
+}
+
+func (e *JobNotRunningError) Error() string {
+	return fmt.Sprintf("job %s is not running, it is %s", e.ID, e.State)
+}
+
+func IsJobNotRunningError(err error) bool {
+	var e *JobNotRunningError
+	return errors.As(err, &e)
+}
+
+// InvalidBundleError indicates an assessment bundle which is malformed, altered
+// or not signed by the expected key.
+type InvalidBundleError struct {
+	Reason string
+}
+
+func NewInvalidBundleError(format string, args ...any) *InvalidBundleError {
+	return &InvalidBundleError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *InvalidBundleError) Error() string {
+	return "invalid assessment bundle: " + e.Reason
+}
+
+func IsInvalidBundleError(err error) bool {
+	var e *InvalidBundleError
+	return errors.As(err, &e)
+}
+
+// invalidCredentials is the message of the VCenterError of a login failure.
+const invalidCredentials = "invalid credentials"
+
 func NewVCenterError(err error) *VCenterError {
 	vErr := &VCenterError{msg: "unknown error"}
 	if strings.Contains(err.Error(), "Login failure") ||
 		(strings.Contains(err.Error(), "incorrect") && strings.Contains(err.Error(), "password")) {
-		vErr.msg = "invalid credentials"
+		vErr.msg = invalidCredentials
 	} else {
 		vErr.msg = err.Error()
 	}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// TransientError wraps an error the same operation may not hit again, such as
+// a connection failure or a 5xx response.
+type TransientError struct {
+	err error
+}
+
+func NewTransientError(err error) *TransientError {
+	return &TransientError{err: err}
+}
+
+func (e *TransientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.err
+}
+
+func IsTransientError(err error) bool {
+	var e *TransientError
+	return errors.As(err, &e)
+}
+
+// FatalError wraps an error the same operation will hit again until something
+// changes, such as rejected credentials.
+type FatalError struct {
+	err error
+}
+
+func NewFatalError(err error) *FatalError {
+	return &FatalError{err: err}
+}
+
+func (e *FatalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *FatalError) Unwrap() error {
+	return e.err
+}
+
+func IsFatalError(err error) bool {
+	var e *FatalError
+	return errors.As(err, &e)
+}
+
+// IsRetryable tells whether the operation that failed with err may succeed if
+// tried again. A FatalError is not retryable and a TransientError is, wherever
+// they are in the chain, the fatal one first. Otherwise:
+//   - ConsoleClientError is retryable only for 408 and 429
+//   - VCenterError is not retryable when the credentials are invalid
+//   - APIError is retryable when its code is
+//
+// Any other error is retryable, as the console loop treats it today.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsFatalError(err) {
+		return false
+	}
+	if IsTransientError(err) {
+		return true
+	}
+
+	var consoleErr *ConsoleClientError
+	if errors.As(err, &consoleErr) {
+		return consoleErr.StatusCode == http.StatusRequestTimeout || consoleErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var vcenterErr *VCenterError
+	if errors.As(err, &vcenterErr) {
+		return vcenterErr.msg != invalidCredentials
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable
+	}
+
+	return true
+}
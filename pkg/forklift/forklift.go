@@ -0,0 +1,190 @@
+// Package forklift converts the migration plans of the agent into the custom
+// resources of Forklift, the Migration Toolkit for Virtualization (MTV), so a
+// plan assessed by the agent is migrated by MTV without being entered again.
+package forklift
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	api "github.com/kubev2v/forklift/pkg/apis/forklift/v1beta1"
+	"github.com/kubev2v/forklift/pkg/apis/forklift/v1beta1/plan"
+	"github.com/kubev2v/forklift/pkg/apis/forklift/v1beta1/provider"
+	"github.com/kubev2v/forklift/pkg/apis/forklift/v1beta1/ref"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+const (
+	// DefaultNamespace is the namespace MTV is installed in.
+	DefaultNamespace = "openshift-mtv"
+	// DefaultDestinationProvider is the provider MTV creates for the cluster
+	// it runs on.
+	DefaultDestinationProvider = "host"
+
+	apiVersion = "forklift.konveyor.io/v1beta1"
+	// maxNameLength keeps the names valid as label values, Forklift labelling
+	// the migrated VMs with the name of their plan.
+	maxNameLength = 63
+)
+
+// datastoreOfFile extracts the datastore of a vSphere disk file,
+// "[datastore1] vm/vm.vmdk".
+var datastoreOfFile = regexp.MustCompile(`^\[([^\]]+)\]`)
+
+// Manifests returns the CRs migrating p with Forklift as a multi-document
+// YAML: a NetworkMap of the networks of its VMs, a StorageMap of their
+// datastores and a Plan per wave, to be migrated in order. vms are the VMs of
+// p by ID, whose NICs and disks give the networks and datastores; the disks
+// outside of a vSphere datastore are not mapped.
+func Manifests(p models.Plan, vms map[string]*models.VM, target models.ForkliftTarget) ([]byte, error) {
+	namespace := target.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	destination := target.DestinationProvider
+	if destination == "" {
+		destination = DefaultDestinationProvider
+	}
+	providers := provider.Pair{
+		Source:      core.ObjectReference{Namespace: namespace, Name: target.SourceProvider},
+		Destination: core.ObjectReference{Namespace: namespace, Name: destination},
+	}
+	base := planName(p)
+
+	var networks, datastores []string
+	for _, w := range p.Waves {
+		for _, pvm := range w.VMs {
+			vm, ok := vms[pvm.ID]
+			if !ok {
+				return nil, fmt.Errorf("the details of VM %s are missing", pvm.ID)
+			}
+			for _, nic := range vm.NICs {
+				if nic.Network != "" && !slices.Contains(networks, nic.Network) {
+					networks = append(networks, nic.Network)
+				}
+			}
+			for _, d := range vm.Disks {
+				m := datastoreOfFile.FindStringSubmatch(d.File)
+				if m != nil && !slices.Contains(datastores, m[1]) {
+					datastores = append(datastores, m[1])
+				}
+			}
+		}
+	}
+
+	networkMap := api.NetworkMap{
+		TypeMeta:   meta.TypeMeta{APIVersion: apiVersion, Kind: "NetworkMap"},
+		ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: truncate(base, maxNameLength-len("-network")) + "-network"},
+		Spec:       api.NetworkMapSpec{Provider: providers, Map: []api.NetworkPair{}},
+	}
+	for _, id := range networks {
+		dest := api.DestinationNetwork{Type: string(models.ForkliftNetworkPod)}
+		if n, ok := target.Networks[id]; ok {
+			dest = api.DestinationNetwork{Type: string(n.Type), Namespace: n.Namespace, Name: n.Name}
+		}
+		networkMap.Spec.Map = append(networkMap.Spec.Map, api.NetworkPair{Source: ref.Ref{ID: id}, Destination: dest})
+	}
+
+	storageMap := api.StorageMap{
+		TypeMeta:   meta.TypeMeta{APIVersion: apiVersion, Kind: "StorageMap"},
+		ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: truncate(base, maxNameLength-len("-storage")) + "-storage"},
+		Spec:       api.StorageMapSpec{Provider: providers, Map: []api.StoragePair{}},
+	}
+	for _, name := range datastores {
+		class := target.StorageClass
+		if c, ok := target.StorageClasses[name]; ok {
+			class = c
+		}
+		storageMap.Spec.Map = append(storageMap.Spec.Map, api.StoragePair{
+			Source:      ref.Ref{Name: name},
+			Destination: api.DestinationStorage{StorageClass: class},
+		})
+	}
+
+	objects := []any{networkMap, storageMap}
+	migration := api.MigrationCold
+	if target.Warm {
+		migration = api.MigrationWarm
+	}
+	for i, w := range p.Waves {
+		suffix := "-" + dnsLabel(w.Name)
+		if suffix == "-" {
+			suffix = fmt.Sprintf("-wave-%d", i+1)
+		}
+		suffix = truncate(suffix, maxNameLength/2)
+		crPlan := api.Plan{
+			TypeMeta:   meta.TypeMeta{APIVersion: apiVersion, Kind: "Plan"},
+			ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: truncate(base, maxNameLength-len(suffix)) + suffix},
+			Spec: api.PlanSpec{
+				Description:     fmt.Sprintf("Wave %d of %d of the migration plan %s", i+1, len(p.Waves), p.ID),
+				TargetNamespace: target.TargetNamespace,
+				Provider:        providers,
+				Map: plan.Map{
+					Network: core.ObjectReference{Namespace: namespace, Name: networkMap.Name},
+					Storage: core.ObjectReference{Namespace: namespace, Name: storageMap.Name},
+				},
+				Type: migration,
+				// the MTV releases before type only know warm
+				Warm: target.Warm,
+			},
+		}
+		for _, vm := range w.VMs {
+			crPlan.Spec.VMs = append(crPlan.Spec.VMs, plan.VM{Ref: ref.Ref{ID: vm.ID, Name: vm.Name}})
+		}
+		objects = append(objects, crPlan)
+	}
+
+	var out bytes.Buffer
+	for i, o := range objects {
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
+
+// planName is the base name of the CRs of p: its name as a DNS label, else
+// plan- and the start of its ID.
+func planName(p models.Plan) string {
+	if name := dnsLabel(p.Name); name != "" {
+		return name
+	}
+	return dnsLabel("plan-" + truncate(p.ID, 8))
+}
+
+// dnsLabel lowers s and replaces the runs of characters invalid in a DNS
+// label with a dash.
+func dnsLabel(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// truncate shortens s to n bytes, without a trailing dash.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		s = strings.TrimRight(s[:n], "-")
+	}
+	return s
+}
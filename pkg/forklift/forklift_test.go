@@ -0,0 +1,164 @@
+package forklift_test
+
+import (
+	"strings"
+
+	api "github.com/kubev2v/forklift/pkg/apis/forklift/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/forklift"
+)
+
+var _ = Describe("Manifests", func() {
+	var (
+		plan models.Plan
+		vms  map[string]*models.VM
+	)
+
+	BeforeEach(func() {
+		plan = models.Plan{
+			ID:   "3f2a9c1e-0000-4000-8000-000000000000",
+			Name: "Finance apps",
+			Waves: []models.PlanWave{
+				{Name: "Wave #1", VMs: []models.PlanVM{{ID: "vm-1", Name: "db"}, {ID: "vm-2", Name: "web"}}},
+				{VMs: []models.PlanVM{{ID: "vm-3", Name: "cache"}}},
+			},
+		}
+		vms = map[string]*models.VM{
+			"vm-1": {
+				NICs:  []models.NIC{{Network: "network-10"}, {Network: "dvportgroup-20"}},
+				Disks: []models.Disk{{File: "[ds1] db/db.vmdk"}, {File: "[ds2] db/db_1.vmdk"}},
+			},
+			"vm-2": {
+				NICs:  []models.NIC{{Network: "network-10"}},
+				Disks: []models.Disk{{File: "[ds1] web/web.vmdk"}},
+			},
+			"vm-3": {
+				Disks: []models.Disk{{File: "cache.vhdx"}},
+			},
+		}
+	})
+
+	// decode splits the documents of data into the maps and the plans.
+	decode := func(data []byte) (api.NetworkMap, api.StorageMap, []api.Plan) {
+		docs := strings.Split(string(data), "---\n")
+		Expect(len(docs)).To(BeNumerically(">=", 2))
+
+		var networkMap api.NetworkMap
+		Expect(yaml.Unmarshal([]byte(docs[0]), &networkMap)).To(Succeed())
+		var storageMap api.StorageMap
+		Expect(yaml.Unmarshal([]byte(docs[1]), &storageMap)).To(Succeed())
+		plans := make([]api.Plan, len(docs)-2)
+		for i, doc := range docs[2:] {
+			Expect(yaml.Unmarshal([]byte(doc), &plans[i])).To(Succeed())
+		}
+		return networkMap, storageMap, plans
+	}
+
+	// Given a plan of two waves and the defaults of the target
+	// When we convert it
+	// Then the maps should cover the networks and datastores once, and a plan per wave should reference them
+	It("should convert the waves into plans sharing the maps", func() {
+		// Act
+		data, err := forklift.Manifests(plan, vms, models.ForkliftTarget{
+			SourceProvider:  "vcenter",
+			TargetNamespace: "finance",
+			StorageClass:    "standard",
+		})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		networkMap, storageMap, plans := decode(data)
+
+		Expect(networkMap.Kind).To(Equal("NetworkMap"))
+		Expect(networkMap.Namespace).To(Equal(forklift.DefaultNamespace))
+		Expect(networkMap.Name).To(Equal("finance-apps-network"))
+		Expect(networkMap.Spec.Provider.Source.Name).To(Equal("vcenter"))
+		Expect(networkMap.Spec.Provider.Destination.Name).To(Equal(forklift.DefaultDestinationProvider))
+		Expect(networkMap.Spec.Map).To(HaveLen(2))
+		Expect(networkMap.Spec.Map[0].Source.ID).To(Equal("network-10"))
+		Expect(networkMap.Spec.Map[0].Destination.Type).To(Equal("pod"))
+
+		Expect(storageMap.Name).To(Equal("finance-apps-storage"))
+		Expect(storageMap.Spec.Map).To(HaveLen(2))
+		Expect(storageMap.Spec.Map[0].Source.Name).To(Equal("ds1"))
+		Expect(storageMap.Spec.Map[1].Source.Name).To(Equal("ds2"))
+		Expect(storageMap.Spec.Map[1].Destination.StorageClass).To(Equal("standard"))
+
+		Expect(plans).To(HaveLen(2))
+		Expect(plans[0].Name).To(Equal("finance-apps-wave-1"))
+		Expect(plans[0].Spec.TargetNamespace).To(Equal("finance"))
+		Expect(plans[0].Spec.Map.Network.Name).To(Equal(networkMap.Name))
+		Expect(plans[0].Spec.Map.Storage.Name).To(Equal(storageMap.Name))
+		Expect(plans[0].Spec.Type).To(Equal(api.MigrationCold))
+		Expect(plans[0].Spec.VMs).To(HaveLen(2))
+		Expect(plans[0].Spec.VMs[0].ID).To(Equal("vm-1"))
+		Expect(plans[0].Spec.VMs[0].Name).To(Equal("db"))
+		Expect(plans[1].Name).To(Equal("finance-apps-wave-2"))
+		Expect(plans[1].Spec.VMs[0].ID).To(Equal("vm-3"))
+	})
+
+	// Given mappings of a network and a datastore and a warm migration
+	// When we convert the plan
+	// Then the mapped ones should get their destination and the plans be warm
+	It("should apply the mappings of the target", func() {
+		// Act
+		data, err := forklift.Manifests(plan, vms, models.ForkliftTarget{
+			Namespace:           "mtv",
+			SourceProvider:      "vcenter",
+			DestinationProvider: "ocp-east",
+			TargetNamespace:     "finance",
+			StorageClass:        "standard",
+			StorageClasses:      map[string]string{"ds2": "fast"},
+			Networks: map[string]models.ForkliftNetwork{
+				"dvportgroup-20": {Type: models.ForkliftNetworkMultus, Namespace: "finance", Name: "vlan-20"},
+			},
+			Warm: true,
+		})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		networkMap, storageMap, plans := decode(data)
+		Expect(networkMap.Namespace).To(Equal("mtv"))
+		Expect(networkMap.Spec.Provider.Destination.Name).To(Equal("ocp-east"))
+		Expect(networkMap.Spec.Map[1].Destination).To(Equal(api.DestinationNetwork{Type: "multus", Namespace: "finance", Name: "vlan-20"}))
+		Expect(storageMap.Spec.Map[0].Destination.StorageClass).To(Equal("standard"))
+		Expect(storageMap.Spec.Map[1].Destination.StorageClass).To(Equal("fast"))
+		Expect(plans[0].Spec.Type).To(Equal(api.MigrationWarm))
+		Expect(plans[0].Spec.Warm).To(BeTrue())
+	})
+
+	// Given a plan without name
+	// When we convert it
+	// Then the CRs should be named after its ID
+	It("should name the CRs after the plan ID without name", func() {
+		// Arrange
+		plan.Name = ""
+
+		// Act
+		data, err := forklift.Manifests(plan, vms, models.ForkliftTarget{SourceProvider: "vcenter", TargetNamespace: "finance", StorageClass: "standard"})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		networkMap, _, plans := decode(data)
+		Expect(networkMap.Name).To(Equal("plan-3f2a9c1e-network"))
+		Expect(plans[1].Name).To(Equal("plan-3f2a9c1e-wave-2"))
+	})
+
+	// Given a VM of the plan without details
+	// When we convert the plan
+	// Then it should fail
+	It("should fail without the details of a VM", func() {
+		// Arrange
+		delete(vms, "vm-2")
+
+		// Act
+		_, err := forklift.Manifests(plan, vms, models.ForkliftTarget{SourceProvider: "vcenter", TargetNamespace: "finance", StorageClass: "standard"})
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("vm-2")))
+	})
+})
@@ -0,0 +1,51 @@
+package graphql
+
+import "encoding/json"
+
+// Int returns the argument name as an int, and false when it is not set or
+// null. The literals of a query are int64, the variables float64 or
+// json.Number depending on how the request was decoded.
+func Int(args map[string]any, name string) (int, bool) {
+	switch v := args[name].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	}
+	return 0, false
+}
+
+// String returns the argument name, and false when it is not set or null.
+func String(args map[string]any, name string) (string, bool) {
+	v, ok := args[name].(string)
+	return v, ok
+}
+
+// Bool returns the argument name, and false when it is not set or null.
+func Bool(args map[string]any, name string) (bool, bool) {
+	v, ok := args[name].(bool)
+	return v, ok
+}
+
+// Strings returns the list of strings argument name, nil when it is not set
+// or null.
+func Strings(args map[string]any, name string) []string {
+	switch v := args[name].(type) {
+	case []string:
+		return v
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
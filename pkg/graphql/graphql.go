@@ -0,0 +1,309 @@
+// Package graphql executes GraphQL queries over objects resolved by the caller.
+//
+// The queries are parsed and validated against the schema by gqlparser, then
+// executed field by field: each object of the result is an Object, resolving
+// its fields with their arguments, and only the fields a query selects are
+// resolved. Lists are slices of any element type, scalars and enums are
+// returned as resolved. Only queries are executed, without introspection but
+// for __typename; the schema is the reference of the clients.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// Object resolves the fields of a GraphQL object.
+type Object interface {
+	Field(ctx context.Context, name string, args map[string]any) (any, error)
+}
+
+// Resolver resolves a field from its arguments.
+type Resolver func(ctx context.Context, args map[string]any) (any, error)
+
+// Fields is an Object resolving each field with its Resolver.
+type Fields map[string]Resolver
+
+func (f Fields) Field(ctx context.Context, name string, args map[string]any) (any, error) {
+	resolve, ok := f[name]
+	if !ok {
+		return nil, fmt.Errorf("field %s is not resolved", name)
+	}
+	return resolve(ctx, args)
+}
+
+// Value resolves a field to v.
+func Value(v any) Resolver {
+	return func(context.Context, map[string]any) (any, error) {
+		return v, nil
+	}
+}
+
+// Request is a GraphQL request, as posted by the clients.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of a Request: its data, null when the request could
+// not be executed, and the errors met.
+type Response struct {
+	Data   any           `json:"data"`
+	Errors gqlerror.List `json:"errors,omitempty"`
+}
+
+// Schema executes the queries of its GraphQL schema.
+type Schema struct {
+	schema *ast.Schema
+}
+
+// NewSchema parses the schema sdl, whose query type is Query.
+func NewSchema(sdl string) (*Schema, error) {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, fmt.Errorf("invalid graphql schema: %w", err)
+	}
+	if schema.Query == nil {
+		return nil, fmt.Errorf("invalid graphql schema: no query type")
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// Execute runs the query of req, its fields resolved from query, the object of
+// the query type. A request failing validation returns its errors without
+// data; a field failing to resolve is null, with an error at its path.
+func (s *Schema) Execute(ctx context.Context, query Object, req Request) Response {
+	doc, err := parser.ParseQuery(&ast.Source{Input: req.Query})
+	if err != nil {
+		return Response{Errors: gqlerror.List{toError(err)}}
+	}
+	if errs := validator.ValidateWithRules(s.schema, doc, nil); len(errs) > 0 {
+		return Response{Errors: errs}
+	}
+
+	op := doc.Operations.ForName(req.OperationName)
+	if op == nil {
+		return Response{Errors: gqlerror.List{gqlerror.Errorf("unknown operation %q", req.OperationName)}}
+	}
+	if op.Operation != ast.Query {
+		return Response{Errors: gqlerror.List{gqlerror.Errorf("%s operations are not supported", op.Operation)}}
+	}
+	vars, err := validator.VariableValues(s.schema, op, req.Variables)
+	if err != nil {
+		return Response{Errors: gqlerror.List{toError(err)}}
+	}
+
+	e := &execution{schema: s.schema, vars: vars}
+	data, ok := e.object(ctx, s.schema.Query, op.SelectionSet, query, nil)
+	if !ok {
+		return Response{Errors: e.errs}
+	}
+	return Response{Data: data, Errors: e.errs}
+}
+
+// execution is the state of a running query.
+type execution struct {
+	schema *ast.Schema
+	vars   map[string]any
+	errs   gqlerror.List
+}
+
+// object resolves the fields of obj of type def selected by sel. It returns
+// false when a non null field is null, the object being null then.
+func (e *execution) object(ctx context.Context, def *ast.Definition, sel ast.SelectionSet, obj Object, path ast.Path) (*orderedMap, bool) {
+	result := &orderedMap{}
+	for _, field := range e.collect(def, sel) {
+		fieldPath := append(append(ast.Path{}, path...), ast.PathName(field.Alias))
+		if field.Name == "__typename" {
+			result.set(field.Alias, def.Name)
+			continue
+		}
+
+		v, err := obj.Field(ctx, field.Name, field.ArgumentMap(e.vars))
+		if err != nil {
+			e.fail(field, fieldPath, err)
+			if field.Definition.Type.NonNull {
+				return nil, false
+			}
+			result.set(field.Alias, nil)
+			continue
+		}
+		value, ok := e.complete(ctx, field, field.Definition.Type, v, fieldPath)
+		if !ok {
+			return nil, false
+		}
+		result.set(field.Alias, value)
+	}
+	return result, true
+}
+
+// complete returns v, resolved for field, as a value of typ. It returns false
+// when v is null while typ is not null.
+func (e *execution) complete(ctx context.Context, field *ast.Field, typ *ast.Type, v any, path ast.Path) (any, bool) {
+	if isNil(v) {
+		if typ.NonNull {
+			e.fail(field, path, fmt.Errorf("must not be null"))
+			return nil, false
+		}
+		return nil, true
+	}
+
+	if typ.Elem != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			e.fail(field, path, fmt.Errorf("resolved to %T, not a list", v))
+			return nil, !typ.NonNull
+		}
+		list := make([]any, 0, rv.Len())
+		for i := range rv.Len() {
+			elem, ok := e.complete(ctx, field, typ.Elem, rv.Index(i).Interface(), append(append(ast.Path{}, path...), ast.PathIndex(i)))
+			if !ok {
+				return nil, !typ.NonNull
+			}
+			list = append(list, elem)
+		}
+		return list, true
+	}
+
+	def := e.schema.Types[typ.NamedType]
+	if def.Kind != ast.Object {
+		return v, true
+	}
+	obj, ok := v.(Object)
+	if !ok {
+		e.fail(field, path, fmt.Errorf("resolved to %T, not an object", v))
+		return nil, !typ.NonNull
+	}
+	result, ok := e.object(ctx, def, field.SelectionSet, obj, path)
+	if !ok {
+		return nil, !typ.NonNull
+	}
+	return result, true
+}
+
+// collect returns the fields of sel selected for an object of type def, the
+// fields of the same response name merged, in the order of the query.
+func (e *execution) collect(def *ast.Definition, sel ast.SelectionSet) []*ast.Field {
+	var fields []*ast.Field
+	byAlias := make(map[string]*ast.Field)
+
+	var walk func(sel ast.SelectionSet)
+	walk = func(sel ast.SelectionSet) {
+		for _, s := range sel {
+			switch s := s.(type) {
+			case *ast.Field:
+				if !e.included(s.Directives) {
+					continue
+				}
+				if f, ok := byAlias[s.Alias]; ok {
+					merged := *f
+					merged.SelectionSet = append(append(ast.SelectionSet{}, f.SelectionSet...), s.SelectionSet...)
+					*f = merged
+					continue
+				}
+				f := *s
+				byAlias[s.Alias] = &f
+				fields = append(fields, &f)
+			case *ast.InlineFragment:
+				if e.included(s.Directives) && (s.TypeCondition == "" || s.TypeCondition == def.Name) {
+					walk(s.SelectionSet)
+				}
+			case *ast.FragmentSpread:
+				if e.included(s.Directives) && s.Definition != nil && s.Definition.TypeCondition == def.Name {
+					walk(s.Definition.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(sel)
+	return fields
+}
+
+// included applies the @skip and @include directives.
+func (e *execution) included(directives ast.DirectiveList) bool {
+	if d := directives.ForName("skip"); d != nil && d.ArgumentMap(e.vars)["if"] == true {
+		return false
+	}
+	if d := directives.ForName("include"); d != nil && d.ArgumentMap(e.vars)["if"] == false {
+		return false
+	}
+	return true
+}
+
+// fail records the error err of field at path.
+func (e *execution) fail(field *ast.Field, path ast.Path, err error) {
+	gqlErr := gqlerror.WrapPath(path, err)
+	if field.Position != nil {
+		gqlErr.Locations = []gqlerror.Location{{Line: field.Position.Line, Column: field.Position.Column}}
+	}
+	e.errs = append(e.errs, gqlErr)
+}
+
+func toError(err error) *gqlerror.Error {
+	if gqlErr, ok := err.(*gqlerror.Error); ok {
+		return gqlErr
+	}
+	return gqlerror.Wrap(err)
+}
+
+// isNil tells whether v is null, a nil slice being an empty list.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// orderedMap is a result object, its fields encoded in the order of the query.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, v any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
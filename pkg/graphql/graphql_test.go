@@ -0,0 +1,268 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/graphql"
+)
+
+const testSchema = `
+type Query {
+  books(author: String, limit: Int = 10): [Book!]!
+  book(id: ID!): Book
+}
+
+type Book {
+  id: ID!
+  title: String!
+  pages: Int
+  author: Author!
+}
+
+type Author {
+  name: String!
+  books: [Book!]!
+}
+`
+
+type book struct {
+	id, title, author string
+	pages             int
+}
+
+var library = []book{
+	{id: "1", title: "Dune", author: "Herbert", pages: 412},
+	{id: "2", title: "Children of Dune", author: "Herbert", pages: 444},
+	{id: "3", title: "Solaris", author: "Lem", pages: 204},
+	{id: "4", title: "Anonymous"},
+}
+
+// query returns the Query object of testSchema over library, counting the
+// resolved authors in resolved.
+func query(resolved *int) graphql.Object {
+	var bookObject func(b book) graphql.Object
+	authorObject := func(name string) graphql.Object {
+		*resolved++
+		return graphql.Fields{
+			"name": graphql.Value(name),
+			"books": func(context.Context, map[string]any) (any, error) {
+				var books []graphql.Object
+				for _, b := range library {
+					if b.author == name {
+						books = append(books, bookObject(b))
+					}
+				}
+				return books, nil
+			},
+		}
+	}
+	bookObject = func(b book) graphql.Object {
+		return graphql.Fields{
+			"id":    graphql.Value(b.id),
+			"title": graphql.Value(b.title),
+			"pages": graphql.Value(b.pages),
+			"author": func(context.Context, map[string]any) (any, error) {
+				if b.author == "" {
+					return nil, errors.New("unknown author")
+				}
+				return authorObject(b.author), nil
+			},
+		}
+	}
+
+	return graphql.Fields{
+		"books": func(_ context.Context, args map[string]any) (any, error) {
+			author, _ := graphql.String(args, "author")
+			limit, _ := graphql.Int(args, "limit")
+			var books []graphql.Object
+			for _, b := range library {
+				if (author == "" || b.author == author) && len(books) < limit {
+					books = append(books, bookObject(b))
+				}
+			}
+			return books, nil
+		},
+		"book": func(_ context.Context, args map[string]any) (any, error) {
+			id, _ := graphql.String(args, "id")
+			if id == "0" {
+				return nil, errors.New("book 0 is lost")
+			}
+			for _, b := range library {
+				if b.id == id {
+					return bookObject(b), nil
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+// execute runs req against testSchema and returns the JSON of its response.
+func execute(req graphql.Request) string {
+	schema, err := graphql.NewSchema(testSchema)
+	Expect(err).NotTo(HaveOccurred())
+	var resolved int
+	data, err := json.Marshal(schema.Execute(context.Background(), query(&resolved), req))
+	Expect(err).NotTo(HaveOccurred())
+	return string(data)
+}
+
+var _ = Describe("Schema", func() {
+	// Given a query selecting some fields of nested objects
+	// When we execute it
+	// Then only the selected fields should be returned, in the order of the query
+	It("should return the selected fields", func() {
+		// Act
+		result := execute(graphql.Request{Query: `{ books(author: "Herbert") { title author { name } } }`})
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {"books": [
+			{"title": "Dune", "author": {"name": "Herbert"}},
+			{"title": "Children of Dune", "author": {"name": "Herbert"}}
+		]}}`))
+		Expect(result).To(HavePrefix(`{"data":{"books":[{"title":"Dune","author"`))
+	})
+
+	// Given an unselected field resolving an object
+	// When we execute the query
+	// Then its resolver should not be called
+	It("should not resolve the fields not selected", func() {
+		// Arrange
+		schema, err := graphql.NewSchema(testSchema)
+		Expect(err).NotTo(HaveOccurred())
+		var resolved int
+
+		// Act
+		schema.Execute(context.Background(), query(&resolved), graphql.Request{Query: `{ books { title } }`})
+
+		// Assert
+		Expect(resolved).To(BeZero())
+	})
+
+	// Given a query with variables, aliases, fragments and directives
+	// When we execute it
+	// Then they should be applied
+	It("should apply variables, aliases, fragments and directives", func() {
+		// Act
+		result := execute(graphql.Request{
+			Query: `query Books($author: String, $limit: Int, $withPages: Boolean!) {
+				first: books(author: $author, limit: $limit) { ...Fields pages @include(if: $withPages) }
+				all: books { id ... on Book { __typename } pages @skip(if: true) }
+			}
+			fragment Fields on Book { id title }`,
+			OperationName: "Books",
+			Variables:     map[string]any{"author": "Lem", "limit": json.Number("1"), "withPages": false},
+		})
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {
+			"first": [{"id": "3", "title": "Solaris"}],
+			"all": [
+				{"id": "1", "__typename": "Book"},
+				{"id": "2", "__typename": "Book"},
+				{"id": "3", "__typename": "Book"},
+				{"id": "4", "__typename": "Book"}
+			]
+		}}`))
+	})
+
+	// Given a query of an unknown field
+	// When we execute it
+	// Then the validation error should be returned without data
+	It("should return the validation errors", func() {
+		// Act
+		result := execute(graphql.Request{Query: `{ books { isbn } }`})
+
+		// Assert
+		Expect(result).To(ContainSubstring(`"data":null`))
+		Expect(result).To(ContainSubstring(`Cannot query field \"isbn\" on type \"Book\".`))
+	})
+
+	// Given a variable of the wrong type
+	// When we execute the query
+	// Then the coercion error should be returned
+	It("should reject invalid variables", func() {
+		// Act
+		result := execute(graphql.Request{
+			Query:     `query($limit: Int) { books(limit: $limit) { id } }`,
+			Variables: map[string]any{"limit": "ten"},
+		})
+
+		// Assert
+		Expect(result).To(ContainSubstring(`"data":null`))
+		Expect(result).To(ContainSubstring(`"limit"`))
+	})
+
+	// Given a mutation
+	// When we execute it
+	// Then it should be rejected
+	It("should only execute queries", func() {
+		// Arrange
+		schema, err := graphql.NewSchema(testSchema + `type Mutation { drop(id: ID!): Boolean }`)
+		Expect(err).NotTo(HaveOccurred())
+		var resolved int
+
+		// Act
+		resp := schema.Execute(context.Background(), query(&resolved), graphql.Request{Query: `mutation { drop(id: "1") }`})
+
+		// Assert
+		Expect(resp.Data).To(BeNil())
+		Expect(resp.Errors).To(HaveLen(1))
+		Expect(resp.Errors[0].Message).To(Equal("mutation operations are not supported"))
+	})
+
+	// Given a nullable field failing to resolve next to a field resolving
+	// When we execute the query
+	// Then the failing field should be null with an error at its path
+	It("should null the failing fields", func() {
+		// Act
+		result := execute(graphql.Request{Query: `{ lost: book(id: "0") { title } found: book(id: "3") { title } }`})
+
+		// Assert
+		Expect(result).To(MatchJSON(`{
+			"data": {"lost": null, "found": {"title": "Solaris"}},
+			"errors": [{"message": "book 0 is lost", "path": ["lost"], "locations": [{"line": 1, "column": 3}]}]
+		}`))
+	})
+
+	// Given a non null field failing to resolve
+	// When we execute the query
+	// Then its parent should be null with a single error at the field
+	It("should propagate the nulls of the non null fields", func() {
+		// Act
+		result := execute(graphql.Request{Query: `{ book(id: "4") { title author { name } } }`})
+
+		// Assert
+		Expect(result).To(MatchJSON(`{
+			"data": {"book": null},
+			"errors": [{"message": "unknown author", "path": ["book", "author"], "locations": [{"line": 1, "column": 25}]}]
+		}`))
+	})
+
+	// Given an unknown book
+	// When we query it
+	// Then it should be null without an error
+	It("should return null for a missing object", func() {
+		// Act
+		result := execute(graphql.Request{Query: `{ book(id: "9") { title } }`})
+
+		// Assert
+		Expect(result).To(MatchJSON(`{"data": {"book": null}}`))
+	})
+
+	// Given an invalid schema
+	// When we load it
+	// Then an error should be returned
+	It("should reject an invalid schema", func() {
+		// Act
+		_, err := graphql.NewSchema(`type Query { book: Missing }`)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("invalid graphql schema")))
+	})
+})
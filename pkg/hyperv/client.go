@@ -0,0 +1,237 @@
+package hyperv
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnauthorized is returned when WinRM rejects the credentials. Its message
+// is the one classified as invalid credentials by srvErrors.NewVCenterError.
+var ErrUnauthorized = errors.New("Login failure: incorrect user name or password")
+
+const (
+	// wqlDialect is the filter dialect of the WQL queries.
+	wqlDialect = "http://schemas.microsoft.com/wbem/wsman/1/WQL"
+	// wmiResourceURI prefixes the namespace of the WMI queries.
+	wmiResourceURI = "http://schemas.microsoft.com/wbem/wsman/1/wmi/"
+
+	actionEnumerate = "http://schemas.xmlsoap.org/ws/2004/09/enumeration/Enumerate"
+	actionPull      = "http://schemas.xmlsoap.org/ws/2004/09/enumeration/Pull"
+
+	// maxElements is the number of instances asked per Enumerate or Pull.
+	maxElements = 100
+	// maxEnvelopeSize is the largest response WinRM may send, in bytes.
+	maxEnvelopeSize = 512000
+	// operationTimeout bounds each request on the server side.
+	operationTimeout = 60 * time.Second
+)
+
+// Client queries the WMI classes of a Windows host through WinRM, the
+// WS-Management service listening on 5985 (http) and 5986 (https). It only
+// enumerates instances: nothing is run on the host.
+//
+// The requests are authenticated with Basic, which WinRM accepts for local
+// accounts when its Basic authentication is enabled. Over http WinRM also
+// requires AllowUnencrypted.
+type Client struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+// NewClient returns the client of the WinRM endpoint of rawURL: a host name,
+// or a URL whose port defaults to 5986 for https and 5985 for http and path to
+// /wsman. insecure skips the verification of the certificate of the host, often
+// self-signed. proxy is the proxy of the requests, the HTTP(S)_PROXY
+// environment variables when nil.
+func NewClient(rawURL, username, password string, insecure bool, proxy func(*http.Request) (*url.URL, error)) (*Client, error) {
+	endpoint, err := Endpoint(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure} //nolint:gosec
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		username: username,
+		password: password,
+		http:     &http.Client{Transport: transport, Timeout: operationTimeout + 30*time.Second},
+	}, nil
+}
+
+// Endpoint returns the WinRM URL of rawURL, see NewClient.
+func Endpoint(rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid WinRM URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid WinRM URL %q: the scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid WinRM URL %q: no host", rawURL)
+	}
+	if u.Port() == "" {
+		port := "5986"
+		if u.Scheme == "http" {
+			port = "5985"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/wsman"
+	}
+	u.User = nil
+	return u.String(), nil
+}
+
+// Host returns the host name of the endpoint of the client.
+func (c *Client) Host() string {
+	u, _ := url.Parse(c.endpoint)
+	return u.Hostname()
+}
+
+// ForHost returns a client of the same WinRM endpoint on another host, e.g. a
+// node of the failover cluster of the host.
+func (c *Client) ForHost(host string) *Client {
+	u, _ := url.Parse(c.endpoint)
+	u.Host = net.JoinHostPort(host, u.Port())
+	other := *c
+	other.endpoint = u.String()
+	return &other
+}
+
+// Query returns the instances selected by the WQL query in the WMI namespace,
+// e.g. root/virtualization/v2.
+func (c *Client) Query(ctx context.Context, namespace, wql string) ([]Instance, error) {
+	resourceURI := wmiResourceURI + strings.Trim(namespace, "/") + "/*"
+
+	var body bytes.Buffer
+	body.WriteString(`<n:Enumerate><w:OptimizeEnumeration/>`)
+	fmt.Fprintf(&body, `<w:MaxElements>%d</w:MaxElements>`, maxElements)
+	fmt.Fprintf(&body, `<w:Filter Dialect="%s">`, wqlDialect)
+	_ = xml.EscapeText(&body, []byte(wql))
+	body.WriteString(`</w:Filter></n:Enumerate>`)
+
+	var resp enumerationResponse
+	if err := c.do(ctx, actionEnumerate, resourceURI, body.String(), &resp); err != nil {
+		return nil, fmt.Errorf("querying %s: %w", namespace, err)
+	}
+	instances := resp.Body.Enumerate.Items.Instances
+	enumCtx, end := resp.Body.Enumerate.Context, resp.Body.Enumerate.EndOfSequence != nil
+
+	for !end && enumCtx != "" {
+		body.Reset()
+		body.WriteString(`<n:Pull><n:EnumerationContext>`)
+		_ = xml.EscapeText(&body, []byte(enumCtx))
+		fmt.Fprintf(&body, `</n:EnumerationContext><n:MaxElements>%d</n:MaxElements></n:Pull>`, maxElements)
+
+		resp = enumerationResponse{}
+		if err := c.do(ctx, actionPull, resourceURI, body.String(), &resp); err != nil {
+			return nil, fmt.Errorf("querying %s: %w", namespace, err)
+		}
+		instances = append(instances, resp.Body.Pull.Items.Instances...)
+		enumCtx, end = resp.Body.Pull.Context, resp.Body.Pull.EndOfSequence != nil
+	}
+
+	return instances, nil
+}
+
+// do sends the request of action on resourceURI with body and decodes the
+// response into dst.
+func (c *Client) do(ctx context.Context, action, resourceURI, body string, dst any) error {
+	var envelope bytes.Buffer
+	envelope.WriteString(`<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"` +
+		` xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing"` +
+		` xmlns:n="http://schemas.xmlsoap.org/ws/2004/09/enumeration"` +
+		` xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd"><s:Header>`)
+	envelope.WriteString(`<a:To>`)
+	_ = xml.EscapeText(&envelope, []byte(c.endpoint))
+	envelope.WriteString(`</a:To><w:ResourceURI s:mustUnderstand="true">`)
+	_ = xml.EscapeText(&envelope, []byte(resourceURI))
+	envelope.WriteString(`</w:ResourceURI>`)
+	envelope.WriteString(`<a:ReplyTo><a:Address s:mustUnderstand="true">http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</a:Address></a:ReplyTo>`)
+	fmt.Fprintf(&envelope, `<a:Action s:mustUnderstand="true">%s</a:Action>`, action)
+	fmt.Fprintf(&envelope, `<w:MaxEnvelopeSize s:mustUnderstand="true">%d</w:MaxEnvelopeSize>`, maxEnvelopeSize)
+	fmt.Fprintf(&envelope, `<a:MessageID>uuid:%s</a:MessageID>`, uuid.NewString())
+	fmt.Fprintf(&envelope, `<w:OperationTimeout>PT%dS</w:OperationTimeout>`, int(operationTimeout.Seconds()))
+	envelope.WriteString(`</s:Header><s:Body>`)
+	envelope.WriteString(body)
+	envelope.WriteString(`</s:Body></s:Envelope>`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, &envelope)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/soap+xml;charset=UTF-8")
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		var fault faultResponse
+		if xml.Unmarshal(data, &fault) == nil && fault.Body.Fault.Reason != "" {
+			return fmt.Errorf("WinRM fault: %s", strings.TrimSpace(fault.Body.Fault.Reason))
+		}
+		return fmt.Errorf("WinRM answered %s", resp.Status)
+	}
+
+	if err := xml.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("decoding the WinRM response: %w", err)
+	}
+	return nil
+}
+
+type enumerationResponse struct {
+	Body struct {
+		Enumerate enumerationResult `xml:"EnumerateResponse"`
+		Pull      enumerationResult `xml:"PullResponse"`
+	} `xml:"Body"`
+}
+
+type enumerationResult struct {
+	Context       string    `xml:"EnumerationContext"`
+	Items         itemsList `xml:"Items"`
+	EndOfSequence *struct{} `xml:"EndOfSequence"`
+}
+
+type faultResponse struct {
+	Body struct {
+		Fault struct {
+			Reason string `xml:"Reason>Text"`
+		} `xml:"Fault"`
+	} `xml:"Body"`
+}
@@ -0,0 +1,199 @@
+package hyperv_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/hyperv"
+)
+
+// envelope wraps body in the SOAP envelope of a WinRM response.
+func envelope(body string) string {
+	return `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"` +
+		` xmlns:n="http://schemas.xmlsoap.org/ws/2004/09/enumeration"` +
+		` xmlns:w="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd"` +
+		` xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"` +
+		` xmlns:p="http://schemas.microsoft.com/wbem/wsman/1/wmi/root/virtualization/v2/Msvm_ComputerSystem">` +
+		`<s:Header/><s:Body>` + body + `</s:Body></s:Envelope>`
+}
+
+var _ = Describe("Client", func() {
+	var (
+		ctx      context.Context
+		handler  http.HandlerFunc
+		server   *httptest.Server
+		client   *hyperv.Client
+		requests []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, string(body))
+			handler(w, r)
+		}))
+
+		var err error
+		client, err = hyperv.NewClient(server.URL, "administrator", "secret", true, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// Given a host returning the instances in an enumeration and a pull
+	// When we query a class
+	// Then every instance should be returned with its properties
+	It("enumerates the instances of a query", func() {
+		// Arrange
+		var username, password, path string
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			username, password, _ = r.BasicAuth()
+			path = r.URL.Path
+			if len(requests) == 1 {
+				_, _ = fmt.Fprint(w, envelope(`<n:EnumerateResponse><n:EnumerationContext>uuid:ctx-1</n:EnumerationContext><w:Items>`+
+					`<p:Msvm_ComputerSystem><p:Name>A1B2</p:Name><p:ElementName>web &amp; db</p:ElementName><p:EnabledState>2</p:EnabledState>`+
+					`<p:Description xsi:nil="true"/></p:Msvm_ComputerSystem>`+
+					`</w:Items></n:EnumerateResponse>`))
+				return
+			}
+			_, _ = fmt.Fprint(w, envelope(`<n:PullResponse><n:Items>`+
+				`<p:Msvm_ComputerSystem><p:Name>C3D4</p:Name><p:ElementName>build</p:ElementName><p:EnabledState>3</p:EnabledState>`+
+				`<p:Dedicated>30</p:Dedicated><p:Dedicated>31</p:Dedicated></p:Msvm_ComputerSystem>`+
+				`</n:Items><n:EndOfSequence/></n:PullResponse>`))
+		}
+
+		// Act
+		instances, err := client.Query(ctx, "root/virtualization/v2", "SELECT * FROM Msvm_ComputerSystem WHERE Caption = 'Virtual Machine'")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(username).To(Equal("administrator"))
+		Expect(password).To(Equal("secret"))
+		Expect(path).To(Equal("/wsman"))
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0]).To(ContainSubstring("http://schemas.microsoft.com/wbem/wsman/1/wmi/root/virtualization/v2/*"))
+		Expect(requests[0]).To(ContainSubstring("WHERE Caption = &#39;Virtual Machine&#39;"))
+		Expect(requests[1]).To(ContainSubstring("<n:EnumerationContext>uuid:ctx-1</n:EnumerationContext>"))
+
+		Expect(instances).To(HaveLen(2))
+		Expect(instances[0].Class).To(Equal("Msvm_ComputerSystem"))
+		Expect(instances[0].String("ElementName")).To(Equal("web & db"))
+		Expect(instances[0].Int("EnabledState")).To(Equal(int64(2)))
+		Expect(instances[0].Properties).NotTo(HaveKey("Description"))
+		Expect(instances[1].String("Name")).To(Equal("C3D4"))
+		Expect(instances[1].Strings("Dedicated")).To(Equal([]string{"30", "31"}))
+	})
+
+	// Given a host rejecting the credentials
+	// When we query a class
+	// Then ErrUnauthorized should be returned
+	It("returns ErrUnauthorized when the credentials are rejected", func() {
+		// Arrange
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		// Act
+		_, err := client.Query(ctx, "root/cimv2", "SELECT Name FROM Win32_ComputerSystem")
+
+		// Assert
+		Expect(err).To(MatchError(hyperv.ErrUnauthorized))
+	})
+
+	// Given a host answering with a SOAP fault
+	// When we query a class
+	// Then the reason of the fault should be returned
+	It("returns the reason of the faults", func() {
+		// Arrange
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprint(w, envelope(`<s:Fault><s:Code><s:Value>s:Sender</s:Value></s:Code>`+
+				`<s:Reason><s:Text xml:lang="en-US">The WS-Management service cannot process the request. The WMI namespace was not found.</s:Text></s:Reason></s:Fault>`))
+		}
+
+		// Act
+		_, err := client.Query(ctx, "root/MSCluster", "SELECT Name FROM MSCluster_Cluster")
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("The WMI namespace was not found."))
+	})
+
+	// Given the URLs of WinRM endpoints
+	// When they are completed
+	// Then the default scheme, port and path should be used
+	DescribeTable("completes the WinRM URLs",
+		func(rawURL, expected string) {
+			// Act
+			endpoint, err := hyperv.Endpoint(rawURL)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(endpoint).To(Equal(expected))
+		},
+		Entry("host name", "hv01.example.com", "https://hv01.example.com:5986/wsman"),
+		Entry("http", "http://hv01.example.com", "http://hv01.example.com:5985/wsman"),
+		Entry("port and path", "https://hv01.example.com:443/custom", "https://hv01.example.com:443/custom"),
+	)
+
+	// Given a URL of another scheme
+	// When it is completed
+	// Then an error should be returned
+	It("rejects the URLs of other schemes", func() {
+		// Act
+		_, err := hyperv.Endpoint("ftp://hv01.example.com")
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+
+	// Given a cluster node
+	// When we get the client of another node
+	// Then it should use the same endpoint on the other host
+	It("targets the other nodes of a cluster", func() {
+		// Act
+		other := client.ForHost("hv02")
+
+		// Assert
+		Expect(other.Host()).To(Equal("hv02"))
+		Expect(client.Host()).To(Equal("127.0.0.1"))
+	})
+})
+
+var _ = Describe("KvpItems", func() {
+	// Given the intrinsic items of a guest
+	// When they are decoded
+	// Then their data should be returned by name
+	It("decodes the guest data items", func() {
+		// Arrange
+		item := func(name, data string) string {
+			return `<INSTANCE CLASSNAME="Msvm_KvpExchangeDataItem">` +
+				`<PROPERTY NAME="Data" TYPE="string"><VALUE>` + data + `</VALUE></PROPERTY>` +
+				`<PROPERTY NAME="Name" TYPE="string"><VALUE>` + name + `</VALUE></PROPERTY>` +
+				`<PROPERTY NAME="Source" TYPE="uint16"><VALUE>2</VALUE></PROPERTY></INSTANCE>`
+		}
+
+		// Act
+		items := hyperv.KvpItems([]string{
+			item("FullyQualifiedDomainName", "web01.example.com"),
+			item("OSName", "Windows Server 2019 Datacenter"),
+			"not xml",
+		})
+
+		// Assert
+		Expect(items).To(Equal(map[string]string{
+			"FullyQualifiedDomainName": "web01.example.com",
+			"OSName":                   "Windows Server 2019 Datacenter",
+		}))
+	})
+})
@@ -0,0 +1,13 @@
+package hyperv_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHyperV(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hyper-V Suite")
+}
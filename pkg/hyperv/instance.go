@@ -0,0 +1,158 @@
+package hyperv
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// xsiNamespace is the namespace of the nil attribute of the properties
+// without value.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// Instance is a WMI instance: its class and the values of its properties.
+// An array property has a value per element, a property without value has
+// none.
+type Instance struct {
+	Class      string
+	Properties map[string][]string
+}
+
+// String returns the value of the property, empty when it has none.
+func (i Instance) String(name string) string {
+	if values := i.Properties[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Strings returns the values of the array property.
+func (i Instance) Strings(name string) []string {
+	return i.Properties[name]
+}
+
+// Int returns the value of the integer property, 0 when it has none.
+func (i Instance) Int(name string) int64 {
+	n, _ := strconv.ParseInt(i.String(name), 10, 64)
+	return n
+}
+
+// Bool returns the value of the boolean property, false when it has none.
+func (i Instance) Bool(name string) bool {
+	b, _ := strconv.ParseBool(i.String(name))
+	return b
+}
+
+// itemsList decodes the Items of an enumeration, one element per instance.
+type itemsList struct {
+	Instances []Instance
+}
+
+func (l *itemsList) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			instance, err := decodeInstance(d, t)
+			if err != nil {
+				return err
+			}
+			l.Instances = append(l.Instances, instance)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeInstance decodes the properties of the instance element start.
+func decodeInstance(d *xml.Decoder, start xml.StartElement) (Instance, error) {
+	instance := Instance{Class: start.Name.Local, Properties: map[string][]string{}}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return instance, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := decodeValue(d)
+			if err != nil {
+				return instance, err
+			}
+			if isNil(t) {
+				continue
+			}
+			instance.Properties[t.Name.Local] = append(instance.Properties[t.Name.Local], value)
+		case xml.EndElement:
+			return instance, nil
+		}
+	}
+}
+
+// decodeValue returns the text of the current element, including the one of
+// its children, e.g. the cim:Datetime of a date.
+func decodeValue(d *xml.Decoder) (string, error) {
+	var b strings.Builder
+	depth := 1
+	for depth > 0 {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			b.Write(t)
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func isNil(e xml.StartElement) bool {
+	for _, attr := range e.Attr {
+		if attr.Name.Space == xsiNamespace && attr.Name.Local == "nil" {
+			return attr.Value == "true"
+		}
+	}
+	return false
+}
+
+// kvpItem is a Msvm_KvpExchangeDataItem embedded in CIM-XML.
+type kvpItem struct {
+	Properties []struct {
+		Name  string `xml:"NAME,attr"`
+		Value string `xml:"VALUE"`
+	} `xml:"PROPERTY"`
+}
+
+// KvpItems returns the data items exchanged with the integration services of a
+// guest, e.g. the GuestIntrinsicExchangeItems of Msvm_KvpExchangeComponent,
+// by name: FullyQualifiedDomainName, OSName, NetworkAddressIPv4... The items
+// which are not valid CIM-XML are skipped.
+func KvpItems(items []string) map[string]string {
+	values := make(map[string]string, len(items))
+	for _, raw := range items {
+		var item kvpItem
+		if err := xml.Unmarshal([]byte(raw), &item); err != nil {
+			continue
+		}
+		var name, data string
+		for _, p := range item.Properties {
+			switch p.Name {
+			case "Name":
+				name = p.Value
+			case "Data":
+				data = p.Value
+			}
+		}
+		if name != "" {
+			values[name] = data
+		}
+	}
+	return values
+}
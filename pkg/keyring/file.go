@@ -0,0 +1,121 @@
+package keyring
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// validName keeps the secret names to plain file names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// File keeps each secret in its own file of a directory, readable by the agent
+// only, so the secrets stay out of the database. The directory can be a
+// mounted Kubernetes secret or a tmpfs managed by an external keyring.
+type File struct {
+	dir string
+}
+
+// NewFile returns a keyring of the files of dir, created when missing.
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating keyring directory: %w", err)
+	}
+	return &File{dir: dir}, nil
+}
+
+// Get returns the content of the file name, or a ResourceNotFoundError when
+// there is none.
+func (f *File) Get(ctx context.Context, name string) ([]byte, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, srvErrors.NewResourceNotFoundError("secret", name)
+	}
+	return value, err
+}
+
+// Put replaces the file name with value. The file is renamed into place so
+// readers never see it partly written.
+func (f *File) Put(ctx context.Context, name string, value []byte) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(f.dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes the file name. Deleting an unknown name is not an error.
+func (f *File) Delete(ctx context.Context, name string) error {
+	path, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f *File) path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	return filepath.Join(f.dir, name), nil
+}
+
+// LoadOrCreateKey returns the key of size random bytes in the file at path,
+// generating it on first use. The file is created readable by the agent only.
+func LoadOrCreateKey(path string, size int) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != size {
+			return nil, fmt.Errorf("key file %s must hold %d bytes, got %d", path, size, len(key))
+		}
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("creating key file: %w", err)
+	}
+	if _, err := file.Write(key); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,113 @@
+package keyring_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/keyring"
+)
+
+var _ = Describe("File", func() {
+	var (
+		ctx context.Context
+		dir string
+		kr  *keyring.File
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = filepath.Join(GinkgoT().TempDir(), "keyring")
+		var err error
+		kr, err = keyring.NewFile(dir)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// Given a secret put twice
+	// When we get it
+	// Then the last value should be returned from a file readable by the agent only
+	It("stores each secret in its own file", func() {
+		// Arrange
+		Expect(kr.Put(ctx, "vcenter-credentials", []byte("first"))).To(Succeed())
+		Expect(kr.Put(ctx, "vcenter-credentials", []byte("second"))).To(Succeed())
+
+		// Act
+		value, err := kr.Get(ctx, "vcenter-credentials")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(value)).To(Equal("second"))
+		info, err := os.Stat(filepath.Join(dir, "vcenter-credentials"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+	})
+
+	// Given a deleted secret
+	// When we get it
+	// Then a not found error should be returned, deleting it again succeeding
+	It("deletes secrets", func() {
+		// Arrange
+		Expect(kr.Put(ctx, "vcenter-credentials", []byte("value"))).To(Succeed())
+		Expect(kr.Delete(ctx, "vcenter-credentials")).To(Succeed())
+
+		// Act
+		_, err := kr.Get(ctx, "vcenter-credentials")
+
+		// Assert
+		Expect(srvErrors.IsResourceNotFoundError(err)).To(BeTrue())
+		Expect(kr.Delete(ctx, "vcenter-credentials")).To(Succeed())
+	})
+
+	// Given a name escaping the keyring directory
+	// When we put it
+	// Then it should be rejected
+	It("rejects names that are not file names", func() {
+		// Act
+		err := kr.Put(ctx, "../agent.duckdb", []byte("value"))
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("invalid secret name")))
+	})
+})
+
+var _ = Describe("LoadOrCreateKey", func() {
+	// Given no key file
+	// When we load the key twice
+	// Then it should be generated once and read back
+	It("generates the key on first use", func() {
+		// Arrange
+		path := filepath.Join(GinkgoT().TempDir(), "secrets.key")
+
+		// Act
+		first, err := keyring.LoadOrCreateKey(path, 32)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := keyring.LoadOrCreateKey(path, 32)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first).To(HaveLen(32))
+		Expect(second).To(Equal(first))
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+	})
+
+	// Given a key file of the wrong size
+	// When we load the key
+	// Then it should fail
+	It("fails with a key of the wrong size", func() {
+		// Arrange
+		path := filepath.Join(GinkgoT().TempDir(), "secrets.key")
+		Expect(os.WriteFile(path, []byte("short"), 0o600)).To(Succeed())
+
+		// Act
+		_, err := keyring.LoadOrCreateKey(path, 32)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("must hold 32 bytes")))
+	})
+})
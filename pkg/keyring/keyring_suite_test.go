@@ -0,0 +1,13 @@
+package keyring_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKeyring(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Keyring Suite")
+}
@@ -0,0 +1,73 @@
+// Package lease keeps the lease electing the leader of a high availability
+// pair of agents. The lease is a file on a storage both agents mount: the
+// database of an agent cannot hold it, DuckDB not being shared between
+// processes.
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// File is a lease kept in a JSON file on a storage shared by the agents of a
+// pair, such as an NFS export. The record is written to a temporary file
+// renamed over the lease, so a reader never sees a partial one.
+type File struct {
+	path string
+}
+
+// NewFile returns the lease kept at path. Its folder must exist.
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Get returns the record of the file, the zero one when it does not exist.
+func (f *File) Get(ctx context.Context) (models.LeaseRecord, error) {
+	var r models.LeaseRecord
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("invalid lease %s: %w", f.path, err)
+	}
+	return r, nil
+}
+
+// Put replaces the record of the file.
+func (f *File) Put(ctx context.Context, r models.LeaseRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	// the rename must not publish a record still in the cache of the client
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
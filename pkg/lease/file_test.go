@@ -0,0 +1,75 @@
+package lease_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/lease"
+)
+
+var _ = Describe("File", func() {
+	var (
+		ctx  context.Context
+		dir  string
+		file *lease.File
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		file = lease.NewFile(filepath.Join(dir, "agent.lease"))
+	})
+
+	// Given no lease file
+	// When we get the lease
+	// Then it should be held by nobody
+	It("should return the zero record without file", func() {
+		// Act
+		r, err := file.Get(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(models.LeaseRecord{}))
+	})
+
+	// Given a record put over another
+	// When we get the lease
+	// Then the last record should be returned, no temporary file left
+	It("should replace the record", func() {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+		Expect(file.Put(ctx, models.LeaseRecord{Holder: "agent-a", AcquiredAt: at, RenewedAt: at})).To(Succeed())
+		next := models.LeaseRecord{Holder: "agent-b", AcquiredAt: at.Add(time.Minute), RenewedAt: at.Add(time.Minute)}
+
+		// Act
+		Expect(file.Put(ctx, next)).To(Succeed())
+		r, err := file.Get(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(next))
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	// Given a lease file which is not JSON
+	// When we get the lease
+	// Then it should fail
+	It("should fail on an invalid file", func() {
+		// Arrange
+		Expect(os.WriteFile(filepath.Join(dir, "agent.lease"), []byte("holder=agent-a"), 0o600)).To(Succeed())
+
+		// Act
+		_, err := file.Get(ctx)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("invalid lease")))
+	})
+})
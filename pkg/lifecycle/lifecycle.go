@@ -0,0 +1,128 @@
+// Package lifecycle shuts the components of the agent down in dependency order
+// within a deadline.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lateStageTimeout bounds each stage reached once the deadline has passed, so
+// the last ones, such as closing the database, still run after a hung stage.
+const lateStageTimeout = 5 * time.Second
+
+// StopFunc stops a component, returning once it is stopped or ctx is done.
+type StopFunc func(ctx context.Context) error
+
+type stage struct {
+	name string
+	stop StopFunc
+}
+
+// Manager stops the components of the agent in the order their stages were
+// added, the first ones being those the others depend on to stop cleanly:
+// the API first, so no new work comes in, the database last. It also tracks
+// the background goroutines of the services, waited for by the stage added
+// with Wait.
+type Manager struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	stages  []stage
+	running sync.WaitGroup
+}
+
+// NewManager returns a manager whose shutdown lasts at most timeout.
+func NewManager(timeout time.Duration) *Manager {
+	return &Manager{timeout: timeout}
+}
+
+// Add appends the stage name, stopped after the stages added before it.
+func (m *Manager) Add(name string, stop StopFunc) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stages = append(m.stages, stage{name: name, stop: stop})
+	return m
+}
+
+// Go runs fn in a goroutine tracked by Wait. fn returns once the context it
+// runs with is done.
+func (m *Manager) Go(fn func()) {
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		fn()
+	}()
+}
+
+// Wait returns once the goroutines started with Go returned, or ctx is done.
+// It is the StopFunc of the stage stopping them.
+func (m *Manager) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown runs the stages in order within the timeout of the manager. A stage
+// still running at the deadline is abandoned; the next ones still run, each
+// for at most lateStageTimeout. The errors of the stages are returned joined.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	stages := append([]stage(nil), m.stages...)
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	log := zap.S().Named("lifecycle")
+	started := time.Now()
+	var errs []error
+	for _, s := range stages {
+		if err := m.run(ctx, s); err != nil {
+			log.Errorw("failed to stop", "stage", s.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	log.Infow("shutdown completed", "duration", time.Since(started), "failed_stages", len(errs))
+	return errors.Join(errs...)
+}
+
+// run runs the stage s and waits for it until ctx is done, or for
+// lateStageTimeout when it already is.
+func (m *Manager) run(ctx context.Context, s stage) error {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), lateStageTimeout)
+		defer cancel()
+	}
+
+	log := zap.S().Named("lifecycle")
+	log.Debugw("stopping", "stage", s.name)
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		log.Infow("stopped", "stage", s.name, "duration", time.Since(started))
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("abandoned after %s: %w", time.Since(started).Round(time.Millisecond), ctx.Err())
+	}
+}
@@ -0,0 +1,120 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/lifecycle"
+)
+
+var _ = Describe("Manager", func() {
+	var (
+		mu      sync.Mutex
+		stopped []string
+	)
+
+	BeforeEach(func() {
+		stopped = nil
+	})
+
+	record := func(name string) lifecycle.StopFunc {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped = append(stopped, name)
+			return nil
+		}
+	}
+
+	get := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), stopped...)
+	}
+
+	// Given stages added in dependency order
+	// When the agent shuts down
+	// Then they should stop in that order
+	It("should stop the stages in order", func() {
+		// Arrange
+		m := lifecycle.NewManager(time.Second).
+			Add("server", record("server")).
+			Add("scheduler", record("scheduler")).
+			Add("database", record("database"))
+
+		// Act
+		err := m.Shutdown(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(get()).To(Equal([]string{"server", "scheduler", "database"}))
+	})
+
+	// Given a failing stage
+	// When the agent shuts down
+	// Then the next stages should still stop and the failure be returned
+	It("should keep stopping after a failing stage", func() {
+		// Arrange
+		m := lifecycle.NewManager(time.Second).
+			Add("console", func(ctx context.Context) error { return errors.New("boom") }).
+			Add("database", record("database"))
+
+		// Act
+		err := m.Shutdown(context.Background())
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("console: boom")))
+		Expect(get()).To(Equal([]string{"database"}))
+	})
+
+	// Given a stage which does not stop before the deadline
+	// When the agent shuts down
+	// Then it should be abandoned and the next stages still stop
+	It("should abandon a stage running past the deadline", func() {
+		// Arrange
+		hang := make(chan struct{})
+		defer close(hang)
+		m := lifecycle.NewManager(50*time.Millisecond).
+			Add("collection", func(ctx context.Context) error {
+				<-hang
+				return nil
+			}).
+			Add("database", record("database"))
+
+		// Act
+		err := m.Shutdown(context.Background())
+
+		// Assert
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("collection: abandoned")))
+		Expect(get()).To(Equal([]string{"database"}))
+	})
+
+	// Given background goroutines stopping with the agent context
+	// When the agent shuts down
+	// Then the Wait stage should return once they returned
+	It("should wait for the background goroutines", func() {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		m := lifecycle.NewManager(time.Second)
+		m.Go(func() {
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			_ = record("flush")(ctx)
+		})
+		m.Add("background services", m.Wait).Add("database", record("database"))
+
+		// Act
+		cancel()
+		err := m.Shutdown(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(get()).To(Equal([]string{"flush", "database"}))
+	})
+})
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+// LogBuffer is an io.Writer keeping the last entries written to it, one per
+// Write as zap writes them, for the logs to be read back without a log file.
+type LogBuffer struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+}
+
+// NewLogBuffer returns a LogBuffer keeping the last size entries.
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{entries: make([][]byte, size)}
+}
+
+// Write keeps a copy of p, dropping the oldest entry when the buffer is full.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) == 0 {
+		return len(p), nil
+	}
+
+	b.entries[b.next] = append(b.entries[b.next][:0], p...)
+	b.next++
+	if b.next == len(b.entries) {
+		b.next = 0
+		b.full = true
+	}
+	return len(p), nil
+}
+
+// WriteTo writes the kept entries to w, oldest first.
+func (b *LogBuffer) WriteTo(w io.Writer) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var n int64
+	write := func(entries [][]byte) error {
+		for _, e := range entries {
+			m, err := w.Write(e)
+			n += int64(m)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if b.full {
+		if err := write(b.entries[b.next:]); err != nil {
+			return n, err
+		}
+	}
+	return n, write(b.entries[:b.next])
+}
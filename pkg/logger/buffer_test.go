@@ -0,0 +1,68 @@
+package logger_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+var _ = Describe("LogBuffer", func() {
+	// Given a buffer of three entries with fewer entries written
+	// When we read it back
+	// Then it should return the entries in order
+	It("returns the entries written", func() {
+		// Arrange
+		b := logger.NewLogBuffer(3)
+		_, _ = b.Write([]byte("first\n"))
+		_, _ = b.Write([]byte("second\n"))
+
+		// Act
+		var out bytes.Buffer
+		_, err := b.WriteTo(&out)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("first\nsecond\n"))
+	})
+
+	// Given a buffer of three entries with five entries written
+	// When we read it back
+	// Then it should return the last three, oldest first
+	It("keeps the last entries", func() {
+		// Arrange
+		b := logger.NewLogBuffer(3)
+		for _, e := range []string{"1\n", "2\n", "3\n", "4\n", "5\n"} {
+			_, _ = b.Write([]byte(e))
+		}
+
+		// Act
+		var out bytes.Buffer
+		_, err := b.WriteTo(&out)
+
+		// Assert
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("3\n4\n5\n"))
+	})
+
+	// Given an entry written from a reused slice
+	// When the slice changes
+	// Then the buffer should keep the entry as written
+	It("copies the entries", func() {
+		// Arrange
+		b := logger.NewLogBuffer(2)
+		p := []byte("entry\n")
+		_, _ = b.Write(p)
+
+		// Act
+		copy(p, "xxxxx")
+
+		// Assert
+		var out bytes.Buffer
+		_, err := b.WriteTo(&out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("entry\n"))
+	})
+})
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// RequestIDKey is the log field carrying the request id.
+const RequestIDKey = "request_id"
+
+type requestIDCtxKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestID returns the request id carried by ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// WithContext returns l with the request id carried by ctx, if any, added as a field.
+func WithContext(ctx context.Context, l *zap.SugaredLogger) *zap.SugaredLogger {
+	if id := RequestID(ctx); id != "" {
+		return l.With(RequestIDKey, id)
+	}
+	return l
+}
+
+// FromContext returns the global logger with the request id carried by ctx, if any.
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	return WithContext(ctx, zap.S())
+}
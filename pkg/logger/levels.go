@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// componentLevels overrides the level of the named loggers, keyed by component.
+var componentLevels = struct {
+	sync.RWMutex
+	levels map[string]zapcore.Level
+}{}
+
+// SetComponentLevels replaces the levels of the named loggers. A component
+// applies to the logger with that name and to the loggers whose name starts
+// with it followed by "_" or ".": store applies to the store logger, console
+// to console_service. The exact name wins over the prefix. Loggers without a
+// component level use the level of SetLevel. Nothing changes when a level is
+// invalid.
+func SetComponentLevels(levels map[string]string) error {
+	parsed := make(map[string]zapcore.Level, len(levels))
+	for component, logLevel := range levels {
+		l, err := zapcore.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("invalid log level %q for %q: %w", logLevel, component, err)
+		}
+		parsed[component] = l
+	}
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+	componentLevels.levels = parsed
+	return nil
+}
+
+// SetComponentLevel sets the level of the named loggers of component, keeping
+// the levels of the other components.
+func SetComponentLevel(component, logLevel string) error {
+	l, err := zapcore.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q for %q: %w", logLevel, component, err)
+	}
+
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+	levels := make(map[string]zapcore.Level, len(componentLevels.levels)+1)
+	for c, cl := range componentLevels.levels {
+		levels[c] = cl
+	}
+	levels[component] = l
+	componentLevels.levels = levels
+	return nil
+}
+
+// ResetComponentLevel removes the level of component, its loggers using the
+// level of SetLevel again. It reports whether component had a level.
+func ResetComponentLevel(component string) bool {
+	componentLevels.Lock()
+	defer componentLevels.Unlock()
+
+	if _, ok := componentLevels.levels[component]; !ok {
+		return false
+	}
+	levels := make(map[string]zapcore.Level, len(componentLevels.levels))
+	for c, cl := range componentLevels.levels {
+		if c != component {
+			levels[c] = cl
+		}
+	}
+	componentLevels.levels = levels
+	return true
+}
+
+// ComponentLevels returns the levels of the named loggers, by component.
+func ComponentLevels() map[string]string {
+	componentLevels.RLock()
+	defer componentLevels.RUnlock()
+
+	levels := make(map[string]string, len(componentLevels.levels))
+	for c, l := range componentLevels.levels {
+		levels[c] = l.String()
+	}
+	return levels
+}
+
+// levelOf returns the level of the logger named name.
+func levelOf(name string) zapcore.Level {
+	componentLevels.RLock()
+	defer componentLevels.RUnlock()
+
+	if l, ok := componentLevels.levels[name]; ok {
+		return l
+	}
+	if i := strings.IndexAny(name, "_."); i > 0 {
+		if l, ok := componentLevels.levels[name[:i]]; ok {
+			return l
+		}
+	}
+	return level.Level()
+}
+
+// minLevel returns the lowest level enabled by any logger.
+func minLevel() zapcore.Level {
+	componentLevels.RLock()
+	defer componentLevels.RUnlock()
+
+	lowest := level.Level()
+	for _, l := range componentLevels.levels {
+		if l < lowest {
+			lowest = l
+		}
+	}
+	return lowest
+}
+
+// componentCore filters the entries of a core with the level of their logger.
+type componentCore struct {
+	zapcore.Core
+}
+
+func (c componentCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= minLevel()
+}
+
+func (c componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return componentCore{c.Core.With(fields)}
+}
+
+func (c componentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < levelOf(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
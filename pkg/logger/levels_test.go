@@ -0,0 +1,152 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+var _ = Describe("Component levels", func() {
+	var (
+		out *os.File
+		log *zap.SugaredLogger
+	)
+
+	BeforeEach(func() {
+		// Init writes to stdout, swapped for a file while the logger is built
+		var err error
+		out, err = os.Create(filepath.Join(GinkgoT().TempDir(), "stdout"))
+		Expect(err).ToNot(HaveOccurred())
+		stdout := os.Stdout
+		os.Stdout = out
+		log = logger.Init("json", "info").Sugar()
+		os.Stdout = stdout
+
+		DeferCleanup(func() {
+			Expect(logger.SetComponentLevels(nil)).To(Succeed())
+			Expect(logger.SetLevel("info")).To(Succeed())
+			out.Close()
+		})
+	})
+
+	written := func() string {
+		Expect(log.Sync()).To(Succeed())
+		data, err := os.ReadFile(out.Name())
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	// Given a debug level for the store and a warn level for the console
+	// When the named loggers log
+	// Then each should be filtered with its own level and the others with the global one
+	It("filters each named logger with its component level", func() {
+		// Arrange
+		Expect(logger.SetComponentLevels(map[string]string{"store": "debug", "console": "warn"})).To(Succeed())
+
+		// Act
+		log.Named("store").Debug("store debug")
+		log.Named("store").Named("query").Debug("store query debug")
+		log.Named("console_service").Info("console info")
+		log.Named("console_service").Warn("console warn")
+		log.Named("http").Debug("http debug")
+		log.Named("http").Info("http info")
+
+		// Assert
+		logs := written()
+		Expect(logs).To(ContainSubstring("store debug"))
+		Expect(logs).To(ContainSubstring("store query debug"))
+		Expect(logs).ToNot(ContainSubstring("console info"))
+		Expect(logs).To(ContainSubstring("console warn"))
+		Expect(logs).ToNot(ContainSubstring("http debug"))
+		Expect(logs).To(ContainSubstring("http info"))
+	})
+
+	// Given a level for a component and another for one of its loggers
+	// When that logger logs
+	// Then its exact name should win over the component
+	It("prefers the exact logger name", func() {
+		// Arrange
+		Expect(logger.SetComponentLevels(map[string]string{"collector": "error", "collector_service": "debug"})).To(Succeed())
+
+		// Act
+		log.Named("collector_service").Debug("service debug")
+		log.Named("collector_handler").Warn("handler warn")
+
+		// Assert
+		logs := written()
+		Expect(logs).To(ContainSubstring("service debug"))
+		Expect(logs).ToNot(ContainSubstring("handler warn"))
+	})
+
+	// Given an invalid level among valid ones
+	// When we set the component levels
+	// Then it should fail and keep the previous levels
+	It("rejects invalid levels", func() {
+		// Arrange
+		Expect(logger.SetComponentLevels(map[string]string{"store": "debug"})).To(Succeed())
+
+		// Act
+		err := logger.SetComponentLevels(map[string]string{"http": "info", "store": "verbose"})
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring(`invalid log level "verbose" for "store"`)))
+		log.Named("store").Debug("store debug")
+		Expect(written()).To(ContainSubstring("store debug"))
+	})
+
+	// Given component levels
+	// When the global level changes
+	// Then the loggers without a component level should follow it
+	It("keeps the global level for the other loggers", func() {
+		// Arrange
+		Expect(logger.SetComponentLevels(map[string]string{"store": "error"})).To(Succeed())
+
+		// Act
+		Expect(logger.SetLevel("debug")).To(Succeed())
+		log.Named("inventory").Debug("inventory debug")
+		log.Named("store").Info("store info")
+
+		// Assert
+		logs := written()
+		Expect(logs).To(ContainSubstring("inventory debug"))
+		Expect(logs).ToNot(ContainSubstring("store info"))
+	})
+
+	// Given component levels
+	// When the level of one component is set and another one reset
+	// Then only those should change
+	It("sets and resets the level of a single component", func() {
+		// Arrange
+		Expect(logger.SetComponentLevels(map[string]string{"store": "debug", "console": "warn"})).To(Succeed())
+
+		// Act
+		Expect(logger.SetComponentLevel("collector", "debug")).To(Succeed())
+		Expect(logger.ResetComponentLevel("console")).To(BeTrue())
+		log.Named("collector_service").Debug("collector debug")
+		log.Named("console_service").Info("console info")
+
+		// Assert
+		Expect(logger.ComponentLevels()).To(Equal(map[string]string{"store": "debug", "collector": "debug"}))
+		Expect(logger.ResetComponentLevel("console")).To(BeFalse())
+		logs := written()
+		Expect(logs).To(ContainSubstring("collector debug"))
+		Expect(logs).To(ContainSubstring("console info"))
+	})
+
+	// Given an invalid level
+	// When we set it on a component
+	// Then it should fail and keep the component levels
+	It("rejects an invalid component level", func() {
+		// Act
+		err := logger.SetComponentLevel("store", "verbose")
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring(`invalid log level "verbose" for "store"`)))
+		Expect(logger.ComponentLevels()).To(BeEmpty())
+	})
+})
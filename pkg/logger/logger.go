@@ -1,42 +1,92 @@
 package logger
 
 import (
+	"io"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// level is shared by the loggers built by Init so SetLevel applies to them at runtime.
+// The loggers with a component level (see SetComponentLevels) do not use it.
+var level = zap.NewAtomicLevel()
+
 // Init initializes and configures a zap logger based on the provided configuration.
 // It sets up the appropriate log level and format according to the config settings.
 func Init(format string, logLevel string) *zap.Logger {
 	lvl := zapcore.InfoLevel
-	level, err := zapcore.ParseLevel(logLevel)
-	if err == nil {
-		lvl = level
+	if l, err := zapcore.ParseLevel(logLevel); err == nil {
+		lvl = l
 	}
+	level.SetLevel(lvl)
 
 	loggerCfg := &zap.Config{
-		Level:    zap.NewAtomicLevelAt(lvl),
-		Encoding: format,
-		EncoderConfig: zapcore.EncoderConfig{
-			TimeKey:        "time",
-			LevelKey:       "severity",
-			NameKey:        "logger",
-			CallerKey:      "caller",
-			MessageKey:     "message",
-			StacktraceKey:  "stacktrace",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeTime:     zapcore.RFC3339TimeEncoder,
-			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeDuration: zapcore.MillisDurationEncoder, EncodeCaller: zapcore.ShortCallerEncoder,
-		},
+		// entries are filtered by componentCore
+		Level:            zap.NewAtomicLevelAt(zapcore.DebugLevel),
+		Encoding:         format,
+		EncoderConfig:    encoderConfig(),
 		OutputPaths:      []string{"stdout"},
 		ErrorOutputPaths: []string{"stderr"},
 	}
 
-	plain, err := loggerCfg.Build(zap.AddStacktrace(zap.DPanicLevel))
+	plain, err := loggerCfg.Build(
+		zap.AddStacktrace(zap.DPanicLevel),
+		zap.WrapCore(func(c zapcore.Core) zapcore.Core { return componentCore{c} }),
+	)
 	if err != nil {
 		panic(err)
 	}
 
 	return plain
 }
+
+// SetLevel changes the level of the loggers built by Init.
+func SetLevel(logLevel string) error {
+	l, err := zapcore.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+// Level returns the level of the loggers built by Init.
+func Level() string {
+	return level.Level().String()
+}
+
+// WithFile returns a logger writing the entries of l to w too, encoded in
+// format (console or json) and filtered by the same levels as l.
+func WithFile(l *zap.Logger, format string, w io.Writer) *zap.Logger {
+	enc := zapcore.NewConsoleEncoder(encoderConfig())
+	if format == "json" {
+		enc = zapcore.NewJSONEncoder(encoderConfig())
+	}
+	file := componentCore{zapcore.NewCore(enc, zapcore.AddSync(w), zapcore.DebugLevel)}
+
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, file)
+	}))
+}
+
+// NewAccessLogger returns a logger writing one JSON line per entry to w,
+// separate from the application logs.
+func NewAccessLogger(w io.Writer) *zap.Logger {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig()), zapcore.AddSync(w), zapcore.InfoLevel)
+	return zap.New(core)
+}
+
+func encoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "severity",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "message",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeTime:     zapcore.RFC3339TimeEncoder,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeDuration: zapcore.MillisDurationEncoder, EncodeCaller: zapcore.ShortCallerEncoder,
+	}
+}
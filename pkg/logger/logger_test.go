@@ -0,0 +1,84 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+var _ = Describe("WithFile", func() {
+	var (
+		dir    string
+		stdout *os.File
+		file   *logger.RotatingFile
+		log    *zap.SugaredLogger
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		// Init writes to stdout, swapped for a file while the logger is built
+		var err error
+		stdout, err = os.Create(filepath.Join(dir, "stdout"))
+		Expect(err).ToNot(HaveOccurred())
+		realStdout := os.Stdout
+		os.Stdout = stdout
+		base := logger.Init("json", "info")
+		os.Stdout = realStdout
+
+		file, err = logger.NewRotatingFile(filepath.Join(dir, "agent.log"), 0, 0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		log = logger.WithFile(base, "json", file).Sugar()
+
+		DeferCleanup(func() {
+			Expect(logger.SetComponentLevels(nil)).To(Succeed())
+			Expect(logger.SetLevel("info")).To(Succeed())
+			Expect(file.Close()).To(Succeed())
+			stdout.Close()
+		})
+	})
+
+	read := func(name string) string {
+		Expect(log.Sync()).To(Succeed())
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	// Given a logger also writing to a file
+	// When it logs
+	// Then the entry should be written to stdout and to the file
+	It("writes the entries to stdout and to the file", func() {
+		// Act
+		log.Named("collector").Infow("collection started", "phase", "connecting")
+
+		// Assert
+		Expect(read("stdout")).To(ContainSubstring(`"message":"collection started"`))
+		logs := read("agent.log")
+		Expect(logs).To(ContainSubstring(`"message":"collection started"`))
+		Expect(logs).To(ContainSubstring(`"logger":"collector"`))
+		Expect(logs).To(ContainSubstring(`"phase":"connecting"`))
+	})
+
+	// Given a logger also writing to a file and component levels
+	// When entries below their level are logged
+	// Then the file should filter them like stdout
+	It("filters the file entries with the same levels", func() {
+		// Arrange
+		Expect(logger.SetComponentLevels(map[string]string{"store": "debug"})).To(Succeed())
+
+		// Act
+		log.Named("store").Debug("store debug")
+		log.Named("http").Debug("http debug")
+
+		// Assert
+		logs := read("agent.log")
+		Expect(logs).To(ContainSubstring("store debug"))
+		Expect(logs).ToNot(ContainSubstring("http debug"))
+	})
+})
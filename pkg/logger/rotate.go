@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat sorts lexically in chronological order.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile is an io.WriteCloser appending to a file that is rotated once it
+// reaches maxSize bytes. Rotated files are renamed to <path>.<timestamp> and
+// removed once older than maxAge or beyond the maxBackups newest ones.
+// A zero maxSize disables rotation, zero maxAge and maxBackups keep every backup.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFile opens, or creates, the file at path for appending.
+func NewRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends p to the file, rotating it first when p would exceed maxSize.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the file to disk.
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the file. Writes after Close fail.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory of %s: %w", r.path, err)
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", r.path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat %s: %w", r.path, err)
+	}
+
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", r.path, err)
+	}
+	r.file = nil
+
+	backup := fmt.Sprintf("%s.%s", r.path, time.Now().UTC().Format(backupTimeFormat))
+	if err := os.Rename(r.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", r.path, err)
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	r.prune()
+	return nil
+}
+
+// prune removes the backups beyond maxBackups or older than maxAge. Failures
+// are ignored: a leftover backup must not stop logging.
+func (r *RotatingFile) prune() {
+	if r.maxAge <= 0 && r.maxBackups <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return
+	}
+
+	prefix := filepath.Base(r.path) + "."
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
+			backups = append(backups, filepath.Join(filepath.Dir(r.path), e.Name()))
+		}
+	}
+	// newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, b := range backups {
+		expired := false
+		if r.maxAge > 0 {
+			if info, err := os.Stat(b); err == nil && time.Since(info.ModTime()) > r.maxAge {
+				expired = true
+			}
+		}
+		if expired || (r.maxBackups > 0 && i >= r.maxBackups) {
+			_ = os.Remove(b)
+		}
+	}
+}
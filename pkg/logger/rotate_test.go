@@ -0,0 +1,164 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/logger"
+)
+
+var _ = Describe("RotatingFile", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		path = filepath.Join(dir, "access.log")
+	})
+
+	backups := func() []string {
+		matches, err := filepath.Glob(path + ".*")
+		Expect(err).ToNot(HaveOccurred())
+		return matches
+	}
+
+	// Given a file below its max size
+	// When we write to it
+	// Then the lines should be appended without rotation
+	It("appends without rotating below the max size", func() {
+		// Arrange
+		f, err := logger.NewRotatingFile(path, 1024, 0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		// Act
+		_, err = f.Write([]byte("one\n"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte("two\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Assert
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("one\ntwo\n"))
+		Expect(backups()).To(BeEmpty())
+	})
+
+	// Given a file reaching its max size
+	// When we write past it
+	// Then the file should be rotated and the new line written to a fresh file
+	It("rotates when the max size is reached", func() {
+		// Arrange
+		f, err := logger.NewRotatingFile(path, 8, 0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+		_, err = f.Write([]byte("first\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Act
+		_, err = f.Write([]byte("second\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Assert
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("second\n"))
+		Expect(backups()).To(HaveLen(1))
+		rotated, err := os.ReadFile(backups()[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(rotated)).To(Equal("first\n"))
+	})
+
+	// Given an existing file
+	// When we reopen it
+	// Then its size should count towards the max size
+	It("accounts for the size of an existing file", func() {
+		// Arrange
+		Expect(os.WriteFile(path, []byte("existing\n"), 0o640)).To(Succeed())
+		f, err := logger.NewRotatingFile(path, 10, 0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		// Act
+		_, err = f.Write([]byte("next\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Assert
+		Expect(backups()).To(HaveLen(1))
+	})
+
+	// Given a limit of two backups
+	// When the file is rotated several times
+	// Then only the two newest backups should be kept
+	It("keeps at most max backups", func() {
+		// Arrange
+		f, err := logger.NewRotatingFile(path, 4, 0, 2)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		// Act
+		for _, line := range []string{"aaa\n", "bbb\n", "ccc\n", "ddd\n", "eee\n"} {
+			_, err = f.Write([]byte(line))
+			Expect(err).ToNot(HaveOccurred())
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		// Assert
+		kept := backups()
+		Expect(kept).To(HaveLen(2))
+		var contents []string
+		for _, b := range kept {
+			data, err := os.ReadFile(b)
+			Expect(err).ToNot(HaveOccurred())
+			contents = append(contents, string(data))
+		}
+		Expect(strings.Join(contents, "")).To(Equal("ccc\nddd\n"))
+	})
+
+	// Given a backup older than the max age
+	// When the file is rotated
+	// Then the old backup should be removed
+	It("removes backups older than max age", func() {
+		// Arrange
+		old := path + ".20000101T000000.000"
+		Expect(os.WriteFile(old, []byte("old\n"), 0o640)).To(Succeed())
+		past := time.Now().Add(-48 * time.Hour)
+		Expect(os.Chtimes(old, past, past)).To(Succeed())
+		f, err := logger.NewRotatingFile(path, 4, 24*time.Hour, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+
+		// Act
+		_, err = f.Write([]byte("aaa\n"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte("bbb\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Assert
+		Expect(old).ToNot(BeAnExistingFile())
+		Expect(backups()).To(HaveLen(1))
+	})
+
+	// Given a closed file
+	// When we write to it
+	// Then the write should fail
+	It("fails to write after close", func() {
+		// Arrange
+		f, err := logger.NewRotatingFile(path, 0, 0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		// Act
+		_, err = f.Write([]byte("late\n"))
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+})
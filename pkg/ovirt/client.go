@@ -0,0 +1,199 @@
+package ovirt
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrUnauthorized is returned when the engine rejects the credentials. Its
+// message is the one classified as invalid credentials by
+// srvErrors.NewVCenterError.
+var ErrUnauthorized = errors.New("Login failure: incorrect user name or password")
+
+// apiPath is the path of the REST API on the engine.
+const apiPath = "/ovirt-engine/api"
+
+// Client reads the inventory of an oVirt or RHV engine through its REST API,
+// version 4. It only reads: nothing is changed on the engine.
+type Client struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+// NewClient returns the client of the engine API at rawURL: the URL of the
+// engine, whose path defaults to /ovirt-engine/api. username includes the
+// authorization domain, e.g. admin@internal. insecure skips the verification of
+// the certificate of the engine. proxy is the proxy of the requests, the
+// HTTP(S)_PROXY environment variables when nil.
+func NewClient(rawURL, username, password string, insecure bool, proxy func(*http.Request) (*url.URL, error)) (*Client, error) {
+	endpoint, err := Endpoint(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure} //nolint:gosec
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		username: username,
+		password: password,
+		http:     &http.Client{Transport: transport, Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Endpoint returns the API URL of the engine at rawURL, see NewClient.
+func Endpoint(rawURL string) (string, error) {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid oVirt URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid oVirt URL %q: the scheme must be http or https", rawURL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = apiPath
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.User = nil
+	return u.String(), nil
+}
+
+// Endpoint returns the API URL of the client.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// ProductInfo returns the product and version of the engine. It is the
+// cheapest authenticated request, verifying the credentials.
+func (c *Client) ProductInfo(ctx context.Context) (ProductInfo, error) {
+	var api struct {
+		ProductInfo ProductInfo `json:"product_info"`
+	}
+	err := c.get(ctx, "", nil, &api)
+	return api.ProductInfo, err
+}
+
+// DataCenters returns the data centers of the engine.
+func (c *Client) DataCenters(ctx context.Context) ([]DataCenter, error) {
+	var list struct {
+		Items []DataCenter `json:"data_center"`
+	}
+	err := c.get(ctx, "/datacenters", nil, &list)
+	return list.Items, err
+}
+
+// Clusters returns the clusters of the engine.
+func (c *Client) Clusters(ctx context.Context) ([]Cluster, error) {
+	var list struct {
+		Items []Cluster `json:"cluster"`
+	}
+	err := c.get(ctx, "/clusters", nil, &list)
+	return list.Items, err
+}
+
+// Hosts returns the hosts of the engine.
+func (c *Client) Hosts(ctx context.Context) ([]Host, error) {
+	var list struct {
+		Items []Host `json:"host"`
+	}
+	err := c.get(ctx, "/hosts", nil, &list)
+	return list.Items, err
+}
+
+// StorageDomains returns the storage domains of the engine, with the data
+// centers they are attached to.
+func (c *Client) StorageDomains(ctx context.Context) ([]StorageDomain, error) {
+	var list struct {
+		Items []StorageDomain `json:"storage_domain"`
+	}
+	err := c.get(ctx, "/storagedomains", nil, &list)
+	return list.Items, err
+}
+
+// Networks returns the logical networks of the engine.
+func (c *Client) Networks(ctx context.Context) ([]Network, error) {
+	var list struct {
+		Items []Network `json:"network"`
+	}
+	err := c.get(ctx, "/networks", nil, &list)
+	return list.Items, err
+}
+
+// VnicProfiles returns the vNIC profiles of the engine, binding the NICs of the
+// VMs to the networks.
+func (c *Client) VnicProfiles(ctx context.Context) ([]VnicProfile, error) {
+	var list struct {
+		Items []VnicProfile `json:"vnic_profile"`
+	}
+	err := c.get(ctx, "/vnicprofiles", nil, &list)
+	return list.Items, err
+}
+
+// VMs returns the VMs of the engine with their disks, NICs and the devices
+// reported by their guest agent.
+func (c *Client) VMs(ctx context.Context) ([]VM, error) {
+	var list struct {
+		Items []VM `json:"vm"`
+	}
+	query := url.Values{"follow": {"disk_attachments.disk,nics,reported_devices"}}
+	err := c.get(ctx, "/vms", query, &list)
+	return list.Items, err
+}
+
+// get decodes the JSON of the API resource at path into dst.
+func (c *Client) get(ctx context.Context, path string, query url.Values, dst any) error {
+	u := c.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Version", "4")
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var f fault
+		if json.Unmarshal(data, &f) == nil && f.Reason != "" {
+			return fmt.Errorf("GET %s: %s: %s", path, f.Reason, f.Detail)
+		}
+		return fmt.Errorf("GET %s: the engine answered %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("GET %s: decoding the response: %w", path, err)
+	}
+	return nil
+}
+
+// fault is the body of the errors of the API.
+type fault struct {
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
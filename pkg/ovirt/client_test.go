@@ -0,0 +1,133 @@
+package ovirt_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/ovirt"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx     context.Context
+		handler http.HandlerFunc
+		server  *httptest.Server
+		client  *ovirt.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+
+		var err error
+		client, err = ovirt.NewClient(server.URL, "admin@internal", "secret", true, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// Given an engine listing its VMs with their disks and NICs
+	// When we read the VMs
+	// Then they should be decoded, the numbers and booleans written as strings included
+	It("reads the VMs with their devices", func() {
+		// Arrange
+		var username, password, path, follow, accept string
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			username, password, _ = r.BasicAuth()
+			path, follow, accept = r.URL.Path, r.URL.Query().Get("follow"), r.Header.Get("Accept")
+			_, _ = fmt.Fprint(w, `{"vm": [{
+				"id": "vm-1", "name": "web01", "status": "up",
+				"cluster": {"id": "cl-1"}, "host": {"id": "host-1"},
+				"cpu": {"topology": {"sockets": "2", "cores": "2", "threads": "1"}},
+				"memory": "4294967296",
+				"bios": {"type": "q35_secure_boot"},
+				"tpm_enabled": "true",
+				"nics": {"nic": [{"id": "nic-1", "mac": {"address": "56:6F:00:00:00:01"}, "vnic_profile": {"id": "prof-1"}}]},
+				"disk_attachments": {"disk_attachment": [{"id": "d-1", "interface": "virtio_scsi", "bootable": "true",
+					"disk": {"id": "d-1", "alias": "web01_Disk1", "provisioned_size": "21474836480", "actual_size": "3221225472",
+						"shareable": "false", "storage_type": "image", "storage_domains": {"storage_domain": [{"id": "sd-1"}]}}}]}
+			}]}`)
+		}
+
+		// Act
+		vms, err := client.VMs(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(username).To(Equal("admin@internal"))
+		Expect(password).To(Equal("secret"))
+		Expect(path).To(Equal("/ovirt-engine/api/vms"))
+		Expect(follow).To(Equal("disk_attachments.disk,nics,reported_devices"))
+		Expect(accept).To(Equal("application/json"))
+
+		Expect(vms).To(HaveLen(1))
+		vm := vms[0]
+		Expect(vm.Host.ID).To(Equal("host-1"))
+		Expect(vm.CPU.Topology.Sockets).To(Equal(ovirt.Int(2)))
+		Expect(vm.Memory).To(Equal(ovirt.Int(4294967296)))
+		Expect(bool(vm.TPMEnabled)).To(BeTrue())
+		Expect(vm.NICs.Items[0].VnicProfile.ID).To(Equal("prof-1"))
+		disk := vm.DiskAttachments.Items[0]
+		Expect(bool(disk.Bootable)).To(BeTrue())
+		Expect(disk.Disk.ProvisionedSize).To(Equal(ovirt.Int(21474836480)))
+		Expect(disk.Disk.StorageDomains.Items[0].ID).To(Equal("sd-1"))
+	})
+
+	// Given an engine rejecting the credentials
+	// When we read its product info
+	// Then ErrUnauthorized should be returned
+	It("returns ErrUnauthorized when the credentials are rejected", func() {
+		// Arrange
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		// Act
+		_, err := client.ProductInfo(ctx)
+
+		// Assert
+		Expect(err).To(MatchError(ovirt.ErrUnauthorized))
+	})
+
+	// Given an engine answering with a fault
+	// When we read a collection
+	// Then the reason and detail of the fault should be returned
+	It("returns the faults of the engine", func() {
+		// Arrange
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"reason": "Operation Failed", "detail": "[Cannot list hosts]"}`)
+		}
+
+		// Act
+		_, err := client.Hosts(ctx)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("Operation Failed: [Cannot list hosts]")))
+	})
+
+	// Given the URLs of engines
+	// When they are completed
+	// Then the API path should be used unless one is given
+	DescribeTable("completes the engine URLs",
+		func(rawURL, expected string) {
+			// Act
+			endpoint, err := ovirt.Endpoint(rawURL)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(endpoint).To(Equal(expected))
+		},
+		Entry("engine", "https://engine.example.com", "https://engine.example.com/ovirt-engine/api"),
+		Entry("api path", "https://engine.example.com/ovirt-engine/api/", "https://engine.example.com/ovirt-engine/api"),
+	)
+})
@@ -0,0 +1,13 @@
+package ovirt_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOVirt(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "oVirt Suite")
+}
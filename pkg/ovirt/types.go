@@ -0,0 +1,194 @@
+package ovirt
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Int is an integer of the API, which writes the numbers as strings.
+type Int int64
+
+func (i *Int) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseInt(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = Int(n)
+	return nil
+}
+
+// Bool is a boolean of the API, which writes the booleans as strings.
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseBool(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+	*b = Bool(v)
+	return nil
+}
+
+// Link references another resource by its ID.
+type Link struct {
+	ID string `json:"id"`
+}
+
+// ProductInfo is the product_info of the API root.
+type ProductInfo struct {
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`
+	Version struct {
+		FullVersion string `json:"full_version"`
+	} `json:"version"`
+}
+
+type DataCenter struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Cluster struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	DataCenter Link   `json:"data_center"`
+}
+
+// Topology is the CPU topology of a host or a VM.
+type Topology struct {
+	Sockets Int `json:"sockets"`
+	Cores   Int `json:"cores"`
+	Threads Int `json:"threads"`
+}
+
+type Host struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	Cluster Link   `json:"cluster"`
+	CPU     struct {
+		Name     string   `json:"name"`
+		Topology Topology `json:"topology"`
+	} `json:"cpu"`
+	// Memory is in bytes.
+	Memory              Int `json:"memory"`
+	HardwareInformation struct {
+		Manufacturer string `json:"manufacturer"`
+		ProductName  string `json:"product_name"`
+	} `json:"hardware_information"`
+	OS struct {
+		Type    string `json:"type"`
+		Version struct {
+			FullVersion string `json:"full_version"`
+		} `json:"version"`
+	} `json:"os"`
+}
+
+type StorageDomain struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// Available and Used are in bytes.
+	Available Int `json:"available"`
+	Used      Int `json:"used"`
+	Storage   struct {
+		Type string `json:"type"`
+	} `json:"storage"`
+	DataCenters struct {
+		Items []Link `json:"data_center"`
+	} `json:"data_centers"`
+}
+
+type Network struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	DataCenter Link   `json:"data_center"`
+	VLAN       *struct {
+		ID Int `json:"id"`
+	} `json:"vlan"`
+}
+
+type VnicProfile struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Network Link   `json:"network"`
+}
+
+type VM struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Cluster Link   `json:"cluster"`
+	// Host is the host running the VM, none when it is down.
+	Host *Link `json:"host"`
+	CPU  struct {
+		Topology Topology `json:"topology"`
+	} `json:"cpu"`
+	// Memory is in bytes.
+	Memory Int `json:"memory"`
+	BIOS   struct {
+		Type string `json:"type"`
+	} `json:"bios"`
+	OS struct {
+		Type string `json:"type"`
+	} `json:"os"`
+	GuestOperatingSystem struct {
+		Distribution string `json:"distribution"`
+		Version      struct {
+			FullVersion string `json:"full_version"`
+		} `json:"version"`
+	} `json:"guest_operating_system"`
+	FQDN       string `json:"fqdn"`
+	TPMEnabled Bool   `json:"tpm_enabled"`
+	NICs       struct {
+		Items []NIC `json:"nic"`
+	} `json:"nics"`
+	DiskAttachments struct {
+		Items []DiskAttachment `json:"disk_attachment"`
+	} `json:"disk_attachments"`
+	ReportedDevices struct {
+		Items []ReportedDevice `json:"reported_device"`
+	} `json:"reported_devices"`
+}
+
+type NIC struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	MAC  struct {
+		Address string `json:"address"`
+	} `json:"mac"`
+	VnicProfile *Link `json:"vnic_profile"`
+}
+
+type DiskAttachment struct {
+	ID        string `json:"id"`
+	Interface string `json:"interface"`
+	Bootable  Bool   `json:"bootable"`
+	Disk      Disk   `json:"disk"`
+}
+
+type Disk struct {
+	ID    string `json:"id"`
+	Alias string `json:"alias"`
+	// ProvisionedSize and ActualSize are in bytes.
+	ProvisionedSize Int  `json:"provisioned_size"`
+	ActualSize      Int  `json:"actual_size"`
+	Shareable       Bool `json:"shareable"`
+	// StorageType is image, or lun for a direct LUN.
+	StorageType    string `json:"storage_type"`
+	StorageDomains struct {
+		Items []Link `json:"storage_domain"`
+	} `json:"storage_domains"`
+}
+
+// ReportedDevice is a network device reported by the guest agent.
+type ReportedDevice struct {
+	IPs struct {
+		Items []struct {
+			Address string `json:"address"`
+			Version string `json:"version"`
+		} `json:"ip"`
+	} `json:"ips"`
+}
@@ -0,0 +1,174 @@
+package policy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubev2v/migration-planner/pkg/opa"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+const (
+	// bundleStateFile keeps the state of the installed bundle in the policies
+	// folder. It is not a .rego file, so it is not read as a policy.
+	bundleStateFile = ".bundle.json"
+	// maxBundleSize bounds the size of the extracted files of a bundle.
+	maxBundleSize = 16 << 20
+)
+
+// ReadBundleState returns the state of the bundle installed in dir, the zero
+// state when none was installed.
+func ReadBundleState(dir string) (models.PolicyBundleState, error) {
+	var state models.PolicyBundleState
+
+	data, err := os.ReadFile(filepath.Join(dir, bundleStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to decode policy bundle state: %w", err)
+	}
+	return state, nil
+}
+
+// InstallBundle replaces the policies of dir with the ones of the gzipped
+// tarball data and records state, its Revision set from the bundle manifest
+// when it has one. The folder is left untouched when the bundle cannot be
+// read or one of its policies does not compile.
+func InstallBundle(dir string, data []byte, state models.PolicyBundleState) (models.PolicyBundleState, error) {
+	policies, revision, err := readBundle(data)
+	if err != nil {
+		return state, err
+	}
+	if _, err := opa.NewValidator(policies); err != nil {
+		return state, fmt.Errorf("invalid policy bundle: %w", err)
+	}
+	if revision != "" {
+		state.Revision = revision
+	}
+
+	for name, content := range policies {
+		if err := writeFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			return state, err
+		}
+	}
+
+	// the bundle is the whole set of policies, the ones it dropped are removed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return state, err
+	}
+	for _, entry := range entries {
+		if _, ok := policies[entry.Name()]; ok || entry.IsDir() || !strings.HasSuffix(entry.Name(), ".rego") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return state, err
+		}
+	}
+
+	stateData, err := json.Marshal(state)
+	if err != nil {
+		return state, err
+	}
+	return state, writeFile(filepath.Join(dir, bundleStateFile), stateData)
+}
+
+// readBundle returns the policies of the bundle by file name, flattening their
+// paths, and the revision of its manifest.
+func readBundle(data []byte) (map[string]string, string, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid policy bundle: %w", err)
+	}
+	defer gz.Close()
+
+	policies := make(map[string]string)
+	revision := ""
+	remaining := int64(maxBundleSize)
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid policy bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Base(hdr.Name)
+		isManifest := name == ".manifest"
+		if !isManifest && (!strings.HasSuffix(name, ".rego") || strings.HasSuffix(name, "_test.rego")) {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tr, remaining+1))
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid policy bundle: %w", err)
+		}
+		remaining -= int64(len(content))
+		if remaining < 0 {
+			return nil, "", fmt.Errorf("policy bundle larger than %d bytes", maxBundleSize)
+		}
+
+		if isManifest {
+			var manifest struct {
+				Revision string `json:"revision"`
+			}
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				return nil, "", fmt.Errorf("invalid policy bundle manifest: %w", err)
+			}
+			revision = manifest.Revision
+			continue
+		}
+
+		if _, ok := policies[name]; ok {
+			return nil, "", fmt.Errorf("invalid policy bundle: duplicate policy file %s", name)
+		}
+		policies[name] = string(content)
+	}
+
+	if len(policies) == 0 {
+		return nil, "", errors.New("invalid policy bundle: no .rego policy files")
+	}
+	return policies, revision, nil
+}
+
+// writeFile writes data to path through a temporary file renamed over it, so
+// the watcher never reads a partly written policy.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,129 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+var _ = Describe("InstallBundle", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "shipped.rego"), []byte(test.PolicyRule("test.shipped", "db-1")), 0o600)).To(Succeed())
+	})
+
+	files := func() []string {
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	// Given a bundle with nested policies and a manifest
+	// When we install it
+	// Then its policies should replace the ones of the folder and its revision be recorded
+	It("should replace the policies of the folder", func() {
+		// Arrange
+		bundle := test.NewPolicyBundle(map[string]string{
+			".manifest":                     `{"revision": "2026.10.1"}`,
+			"policies/vmware/cpu.rego":      test.PolicyRule("test.cpu", "db-1"),
+			"policies/vmware/cpu_test.rego": "package io.konveyor.forklift.vmware_test",
+			"data.json":                     "{}",
+		})
+
+		// Act
+		state, err := policy.InstallBundle(dir, bundle, models.PolicyBundleState{URL: "/bundle", ETag: `"abc"`, Revision: `"abc"`})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Revision).To(Equal("2026.10.1"))
+		Expect(files()).To(ConsistOf(".bundle.json", "cpu.rego"))
+
+		saved, err := policy.ReadBundleState(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved).To(Equal(state))
+	})
+
+	// Given a bundle without manifest
+	// When we install it
+	// Then the revision given should be kept
+	It("should keep the revision given without manifest", func() {
+		// Act
+		state, err := policy.InstallBundle(dir, test.NewPolicyBundle(map[string]string{
+			"cpu.rego": test.PolicyRule("test.cpu", "db-1"),
+		}), models.PolicyBundleState{Revision: `"abc"`})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Revision).To(Equal(`"abc"`))
+	})
+
+	// Given a bundle with a policy that does not compile
+	// When we install it
+	// Then it should fail and leave the folder untouched
+	It("should not install a bundle that does not compile", func() {
+		// Arrange
+		bundle := test.NewPolicyBundle(map[string]string{
+			"cpu.rego": "package io.konveyor.forklift.vmware\n\nconcerns contains flag if {",
+		})
+
+		// Act
+		_, err := policy.InstallBundle(dir, bundle, models.PolicyBundleState{})
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(files()).To(ConsistOf("shipped.rego"))
+	})
+
+	// Given data that is not a gzipped tarball
+	// When we install it
+	// Then it should fail
+	It("should reject data that is not a bundle", func() {
+		// Act
+		_, err := policy.InstallBundle(dir, []byte("not a bundle"), models.PolicyBundleState{})
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(files()).To(ConsistOf("shipped.rego"))
+	})
+
+	// Given a bundle having two policies of the same file name
+	// When we install it
+	// Then it should fail
+	It("should reject duplicate policy file names", func() {
+		// Arrange
+		bundle := test.NewPolicyBundle(map[string]string{
+			"a/cpu.rego": test.PolicyRule("test.a", "db-1"),
+			"b/cpu.rego": test.PolicyRule("test.b", "db-1"),
+		})
+
+		// Act
+		_, err := policy.InstallBundle(dir, bundle, models.PolicyBundleState{})
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("duplicate policy file cpu.rego")))
+	})
+
+	// Given a folder where no bundle was installed
+	// When we read the bundle state
+	// Then the zero state should be returned
+	It("should return the zero state without bundle", func() {
+		// Act
+		state, err := policy.ReadBundleState(dir)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(models.PolicyBundleState{}))
+	})
+})
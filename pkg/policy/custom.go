@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubev2v/migration-planner/pkg/opa"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+const (
+	// CustomDir is the subfolder of the policies folder holding the policies
+	// uploaded by the users. Bundles only replace the policies of the folder
+	// itself, so the custom ones are kept.
+	CustomDir = "custom"
+	// CustomConcernPrefix prefixes the IDs of the concerns raised by the custom
+	// policies, telling them apart from the built-in ones.
+	CustomConcernPrefix = "custom."
+)
+
+// ErrInvalidCustomPolicy is the error of a custom policy that does not compile.
+var ErrInvalidCustomPolicy = errors.New("invalid custom policy")
+
+// customNameRe matches the file names of the custom policies.
+var customNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*\.rego$`)
+
+// ValidCustomName tells whether name can be the file name of a custom policy:
+// a .rego file, not a test, without path.
+func ValidCustomName(name string) bool {
+	return customNameRe.MatchString(name) && !strings.HasSuffix(name, "_test.rego")
+}
+
+// ListCustom returns the custom policies of the policies folder dir, by name.
+func ListCustom(dir string) ([]models.CustomPolicy, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, CustomDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []models.CustomPolicy{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]models.CustomPolicy, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !ValidCustomName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, models.CustomPolicy{
+			Name:       entry.Name(),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	return policies, nil
+}
+
+// WriteCustom adds the custom policy name to the policies folder dir, or
+// replaces it. It fails, leaving the folder untouched, when the custom
+// policies do not compile with it.
+func WriteCustom(dir, name string, content []byte) error {
+	if !ValidCustomName(name) {
+		return fmt.Errorf("invalid custom policy name %q", name)
+	}
+
+	customDir := filepath.Join(dir, CustomDir)
+	policies, err := readPolicies(customDir)
+	if err != nil {
+		return err
+	}
+	policies[name] = string(content)
+	if _, err := opa.NewValidator(policies); err != nil {
+		return fmt.Errorf("%w %s: %w", ErrInvalidCustomPolicy, name, err)
+	}
+
+	if err := os.MkdirAll(customDir, 0o755); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(customDir, name), content)
+}
+
+// DeleteCustom removes the custom policy name from the policies folder dir.
+func DeleteCustom(dir, name string) error {
+	if !ValidCustomName(name) {
+		return srvErrors.NewResourceNotFoundError("custom policy", name)
+	}
+	err := os.Remove(filepath.Join(dir, CustomDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return srvErrors.NewResourceNotFoundError("custom policy", name)
+	}
+	return err
+}
+
+// readPolicies returns the policies of dir by file name, none when dir does
+// not exist. Unlike opa.PolicyReader, a folder without policies is no error.
+func readPolicies(dir string) (map[string]string, error) {
+	return readRego(dir, ValidCustomName)
+}
+
+// readTests returns the rego tests of dir by file name, none when dir does not
+// exist.
+func readTests(dir string) (map[string]string, error) {
+	return readRego(dir, validTestName)
+}
+
+// validTestName tells whether name can be the file name of a rego test.
+func validTestName(name string) bool {
+	return customNameRe.MatchString(name) && strings.HasSuffix(name, "_test.rego")
+}
+
+// readRego returns the content of the files of dir whose name is kept, by
+// name.
+func readRego(dir string, keep func(name string) bool) (map[string]string, error) {
+	policies := make(map[string]string)
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return policies, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !keep(entry.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		policies[entry.Name()] = string(content)
+	}
+	return policies, nil
+}
@@ -0,0 +1,132 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	srvErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+var _ = Describe("Custom policies", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "shipped.rego"), []byte(test.PolicyRule("test.shipped", "db-1")), 0o600)).To(Succeed())
+	})
+
+	names := func() []string {
+		policies, err := policy.ListCustom(dir)
+		Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, p := range policies {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	// Given a folder without custom policies
+	// When we list them
+	// Then none should be returned
+	It("should list no custom policies", func() {
+		// Act & Assert
+		Expect(names()).To(BeEmpty())
+	})
+
+	// Given two custom policies
+	// When we write them
+	// Then they should be listed by name
+	It("should write the custom policies", func() {
+		// Act
+		Expect(policy.WriteCustom(dir, "b.rego", []byte(test.PolicyRule("test.b", "db-1")))).To(Succeed())
+		Expect(policy.WriteCustom(dir, "a.rego", []byte(test.PolicyRule("test.a", "db-1")))).To(Succeed())
+
+		// Assert
+		Expect(names()).To(Equal([]string{"a.rego", "b.rego"}))
+	})
+
+	// Given a custom policy that does not compile
+	// When we write it
+	// Then it should fail and not be written
+	It("should not write a custom policy that does not compile", func() {
+		// Act
+		err := policy.WriteCustom(dir, "a.rego", []byte("package io.konveyor.forklift.vmware\n\nconcerns contains flag if {"))
+
+		// Assert
+		Expect(errors.Is(err, policy.ErrInvalidCustomPolicy)).To(BeTrue())
+		Expect(names()).To(BeEmpty())
+	})
+
+	// Given file names that are not custom policies
+	// When we check them
+	// Then they should be rejected
+	It("should only accept .rego file names", func() {
+		// Act & Assert
+		Expect(policy.ValidCustomName("naming.rego")).To(BeTrue())
+		Expect(policy.ValidCustomName("naming_test.rego")).To(BeFalse())
+		Expect(policy.ValidCustomName("../naming.rego")).To(BeFalse())
+		Expect(policy.ValidCustomName(".naming.rego")).To(BeFalse())
+		Expect(policy.ValidCustomName("naming.txt")).To(BeFalse())
+	})
+
+	// Given a custom policy
+	// When we delete it, then delete it again
+	// Then the second delete should not find it
+	It("should delete a custom policy", func() {
+		// Arrange
+		Expect(policy.WriteCustom(dir, "a.rego", []byte(test.PolicyRule("test.a", "db-1")))).To(Succeed())
+
+		// Act & Assert
+		Expect(policy.DeleteCustom(dir, "a.rego")).To(Succeed())
+		Expect(names()).To(BeEmpty())
+		Expect(srvErrors.IsResourceNotFoundError(policy.DeleteCustom(dir, "a.rego"))).To(BeTrue())
+	})
+
+	// Given a custom policy flagging a VM
+	// When the policies are reloaded and validate the VM
+	// Then its concern should be tagged as custom, after the built-in ones
+	It("should evaluate the custom policies along the built-in ones", func() {
+		// Arrange
+		p, err := policy.NewFromDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.WriteCustom(dir, "a.rego", []byte(test.PolicyRule("test.a", "db-1")))).To(Succeed())
+
+		// Act
+		changed, err := p.Reload()
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		concerns, err := p.Validate(context.Background(), parsermodels.VM{ID: "vm-1", Name: "db-1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(concerns).To(HaveLen(2))
+		Expect(concerns[0].Id).To(Equal("test.shipped"))
+		Expect(concerns[1].Id).To(Equal(policy.CustomConcernPrefix + "test.a"))
+	})
+
+	// Given a custom policy
+	// When a bundle is installed
+	// Then the custom policy should be kept
+	It("should keep the custom policies when a bundle is installed", func() {
+		// Arrange
+		Expect(policy.WriteCustom(dir, "a.rego", []byte(test.PolicyRule("test.a", "db-1")))).To(Succeed())
+
+		// Act
+		_, err := policy.InstallBundle(dir, test.NewPolicyBundle(map[string]string{
+			"cpu.rego": test.PolicyRule("test.cpu", "db-1"),
+		}), models.PolicyBundleState{})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names()).To(Equal([]string{"a.rego"}))
+	})
+})
@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// readMetadata returns the metadata of the concerns of the policies, read from
+// the METADATA annotations of their rules. The concern is the one of the id of
+// the custom annotations, the others are ignored:
+//
+//	# METADATA
+//	# title: CPU hot add
+//	# related_resources:
+//	#   - ref: https://docs.example.com/cpu-hot-add
+//	# custom:
+//	#   id: vmware.cpu.hotadd
+//	#   severity: warning
+//	#   category: Warning
+//	#   remediation: Disable CPU hot add before the migration
+//	concerns contains flag if { ... }
+//
+// The IDs are prefixed with prefix, like the ones of the concerns.
+func readMetadata(policies map[string]string, prefix string) (map[string]models.ConcernMetadata, error) {
+	metadata := make(map[string]models.ConcernMetadata)
+	for name, content := range policies {
+		module, err := ast.ParseModuleWithOpts(name, content, ast.ParserOptions{
+			RegoVersion:       ast.RegoV1,
+			ProcessAnnotation: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy %s: %w", name, err)
+		}
+
+		for _, a := range module.Annotations {
+			id, _ := a.Custom["id"].(string)
+			if id == "" {
+				continue
+			}
+			m := models.ConcernMetadata{
+				ID:          prefix + id,
+				Title:       a.Title,
+				Severity:    strings.ToLower(customString(a, "severity")),
+				Category:    customString(a, "category"),
+				Remediation: customString(a, "remediation"),
+				DocsURL:     customString(a, "docs"),
+			}
+			if m.DocsURL == "" && len(a.RelatedResources) > 0 {
+				m.DocsURL = a.RelatedResources[0].Ref.String()
+			}
+			metadata[m.ID] = m
+		}
+	}
+	return metadata, nil
+}
+
+// customString returns the custom annotation key when it is a string.
+func customString(a *ast.Annotations, key string) string {
+	s, _ := a.Custom[key].(string)
+	return s
+}
+
+// sortedMetadata returns the metadata by ID.
+func sortedMetadata(metadata map[string]models.ConcernMetadata) []models.ConcernMetadata {
+	sorted := make([]models.ConcernMetadata, 0, len(metadata))
+	for _, m := range metadata {
+		sorted = append(sorted, m)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
@@ -0,0 +1,77 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+const annotatedPolicy = `package io.konveyor.forklift.vmware
+
+# METADATA
+# title: CPU hot add
+# related_resources:
+#   - ref: https://docs.example.com/cpu-hot-add
+# custom:
+#   id: test.cpu
+#   severity: Critical
+#   remediation: Disable CPU hot add before the migration
+concerns contains flag if {
+	input.cpuHotAddEnabled
+	flag := {"id": "test.cpu", "category": "Warning", "label": "CPU hot add", "assessment": "Not supported"}
+}
+`
+
+var _ = Describe("Policy metadata", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "cpu.rego"), []byte(annotatedPolicy), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "plain.rego"), []byte(test.PolicyRule("test.plain", "db-1")), 0o600)).To(Succeed())
+	})
+
+	// Given a policy with a METADATA annotation and one without
+	// When the policies are compiled
+	// Then the metadata of the annotated concern should be read
+	It("should read the metadata of the annotated rules", func() {
+		// Act
+		p, err := policy.NewFromDir(dir)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Metadata()).To(Equal([]models.ConcernMetadata{{
+			ID:          "test.cpu",
+			Title:       "CPU hot add",
+			Severity:    "critical",
+			Remediation: "Disable CPU hot add before the migration",
+			DocsURL:     "https://docs.example.com/cpu-hot-add",
+		}}))
+	})
+
+	// Given an annotated custom policy
+	// When the policies are reloaded
+	// Then its metadata should have the custom prefix
+	It("should prefix the metadata of the custom policies", func() {
+		// Arrange
+		p, err := policy.NewFromDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.WriteCustom(dir, "cpu.rego", []byte(annotatedPolicy))).To(Succeed())
+
+		// Act
+		_, err = p.Reload()
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		metadata := p.Metadata()
+		Expect(metadata).To(HaveLen(2))
+		Expect(metadata[0].ID).To(Equal(policy.CustomConcernPrefix + "test.cpu"))
+		Expect(metadata[1].ID).To(Equal("test.cpu"))
+	})
+})
@@ -0,0 +1,287 @@
+// Package policy holds the OPA policies evaluating the migration concerns of
+// the VMs, compiled from the .rego files of a folder and recompiled when they
+// change, so updated rules take effect without restarting the agent. The
+// policies uploaded by the users live in its custom subfolder.
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	parsermodels "github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	"github.com/kubev2v/migration-planner/pkg/opa"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// watchInterval is how often the policies folder is checked for changes.
+// Polling follows symlinks, so policies swapped by a ConfigMap update are seen.
+const watchInterval = 2 * time.Second
+
+// osUpgradeConcernID is the concern every opa.Validator adds for an upgradable
+// guest OS, already raised along the built-in policies.
+const osUpgradeConcernID = "vmware.os.upgrade.recommendation"
+
+// DecisionFunc receives each evaluation of the policies: the VM, the concerns
+// the policies raised for it and how long the evaluation took.
+type DecisionFunc func(vm parsermodels.VM, concerns []parsermodels.Concern, duration time.Duration)
+
+// Policies implements duckdb_parser.Validator with the policies of a folder.
+// The validator in use is swapped on Reload, the evaluations running keep the
+// one they started with.
+//
+// The custom policies are compiled apart from the built-in ones, so a custom
+// rule cannot break them, and their concerns have CustomConcernPrefix.
+type Policies struct {
+	dir string
+
+	mu          sync.RWMutex
+	validator   *opa.Validator
+	custom      *opa.Validator
+	metadata    []models.ConcernMetadata
+	fingerprint string
+	files       []models.PolicyFile // without source
+	loadedAt    time.Time
+	loadErr     error // of the last reload, the policies in use being kept
+	onDecision  DecisionFunc
+}
+
+// NewFromDir compiles the policies of dir. It fails when dir has no policy or
+// one of them does not compile.
+func NewFromDir(dir string) (*Policies, error) {
+	p := &Policies{dir: dir}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Dir returns the folder of the policies.
+func (p *Policies) Dir() string {
+	return p.dir
+}
+
+// OnDecision makes the evaluations that succeed call fn, to log them.
+func (p *Policies) OnDecision(fn DecisionFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onDecision = fn
+}
+
+// Validate returns the concerns of vm with the policies in use, the built-in
+// ones first.
+func (p *Policies) Validate(ctx context.Context, vm parsermodels.VM) ([]parsermodels.Concern, error) {
+	p.mu.RLock()
+	onDecision := p.onDecision
+	p.mu.RUnlock()
+
+	start := time.Now()
+	concerns, err := p.validate(ctx, vm)
+	if err == nil && onDecision != nil {
+		onDecision(vm, concerns, time.Since(start))
+	}
+	return concerns, err
+}
+
+func (p *Policies) validate(ctx context.Context, vm parsermodels.VM) ([]parsermodels.Concern, error) {
+	p.mu.RLock()
+	validator, custom := p.validator, p.custom
+	p.mu.RUnlock()
+
+	concerns, err := validator.Validate(ctx, vm)
+	if err != nil || custom == nil {
+		return concerns, err
+	}
+
+	customConcerns, err := custom.Validate(ctx, vm)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range customConcerns {
+		if c.Id == osUpgradeConcernID {
+			continue
+		}
+		c.Id = CustomConcernPrefix + c.Id
+		concerns = append(concerns, c)
+	}
+	return concerns, nil
+}
+
+// Metadata returns the metadata of the concerns, read from the annotations of
+// the rules of the policies in use.
+func (p *Policies) Metadata() []models.ConcernMetadata {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.metadata
+}
+
+// Status returns the policies in use: their files, the custom ones being in
+// the custom subfolder and the others from the bundle of the console when one
+// is installed, and the error of the last reload when it failed.
+func (p *Policies) Status() (models.PolicySet, error) {
+	state, err := ReadBundleState(p.dir)
+	if err != nil {
+		return models.PolicySet{}, err
+	}
+
+	p.mu.RLock()
+	set := models.PolicySet{
+		Fingerprint: p.fingerprint,
+		LoadedAt:    p.loadedAt,
+		Files:       make([]models.PolicyFile, 0, len(p.files)),
+	}
+	for _, f := range p.files {
+		switch {
+		case strings.HasPrefix(f.Name, CustomDir+"/"):
+			f.Source = models.PolicySourceCustom
+		case state.URL != "":
+			f.Source = models.PolicySourceConsole
+		default:
+			f.Source = models.PolicySourceBuiltin
+		}
+		set.Files = append(set.Files, f)
+	}
+	if p.loadErr != nil {
+		set.LoadError = p.loadErr.Error()
+	}
+	p.mu.RUnlock()
+
+	if state.URL != "" {
+		set.Bundle = &state
+	}
+	return set, nil
+}
+
+// Reload compiles the policies of the folder again when they changed since the
+// last reload, and tells whether they did. The policies in use are kept when
+// the folder cannot be read or a policy does not compile.
+func (p *Policies) Reload() (bool, error) {
+	changed, err := p.reload()
+
+	p.mu.Lock()
+	p.loadErr = err
+	p.mu.Unlock()
+
+	return changed, err
+}
+
+func (p *Policies) reload() (bool, error) {
+	policies, err := opa.NewPolicyReader().ReadPolicies(p.dir)
+	if err != nil {
+		return false, err
+	}
+	customPolicies, err := readPolicies(filepath.Join(p.dir, CustomDir))
+	if err != nil {
+		return false, err
+	}
+
+	fingerprint := fingerprintOf(policies, customPolicies)
+	p.mu.RLock()
+	unchanged := fingerprint == p.fingerprint
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	validator, err := opa.NewValidator(policies)
+	if err != nil {
+		return false, err
+	}
+	var custom *opa.Validator
+	if len(customPolicies) > 0 {
+		if custom, err = opa.NewValidator(customPolicies); err != nil {
+			return false, fmt.Errorf("invalid custom policies: %w", err)
+		}
+	}
+
+	metadata, err := readMetadata(policies, "")
+	if err != nil {
+		return false, err
+	}
+	customMetadata, err := readMetadata(customPolicies, CustomConcernPrefix)
+	if err != nil {
+		return false, err
+	}
+	for id, m := range customMetadata {
+		metadata[id] = m
+	}
+
+	p.mu.Lock()
+	p.validator = validator
+	p.custom = custom
+	p.metadata = sortedMetadata(metadata)
+	p.fingerprint = fingerprint
+	p.files = filesOf(policies, customPolicies)
+	p.loadedAt = time.Now().UTC()
+	p.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch reloads the policies whenever the files of the folder change, until
+// ctx is done, and calls onReload after each reload that changed them. A
+// policy that does not compile is logged and the previous ones stay in use.
+func (p *Policies) Watch(ctx context.Context, onReload func(ctx context.Context)) {
+	tick := time.NewTicker(watchInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+
+		changed, err := p.Reload()
+		if err != nil {
+			zap.S().Named("policy").Errorw("failed to reload policies, keeping the previous ones", "dir", p.dir, "error", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		zap.S().Named("policy").Infow("policies reloaded", "dir", p.dir)
+		onReload(ctx)
+	}
+}
+
+// fingerprintOf returns the hash of the built-in and custom policies, by file
+// name and content.
+func fingerprintOf(policies, customPolicies map[string]string) string {
+	h := sha256.New()
+	for kind, set := range []map[string]string{policies, customPolicies} {
+		names := make([]string, 0, len(set))
+		for name := range set {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			_, _ = fmt.Fprintf(h, "%d\x00%s\x00%s\x00", kind, name, set[name])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// filesOf returns the built-in and custom policy files, by name, with the hash
+// of their content. The names of the custom ones are relative to the policies
+// folder.
+func filesOf(policies, customPolicies map[string]string) []models.PolicyFile {
+	files := make([]models.PolicyFile, 0, len(policies)+len(customPolicies))
+	for prefix, set := range map[string]map[string]string{"": policies, CustomDir + "/": customPolicies} {
+		for name, content := range set {
+			sum := sha256.Sum256([]byte(content))
+			files = append(files, models.PolicyFile{Name: prefix + name, Hash: hex.EncodeToString(sum[:])})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files
+}
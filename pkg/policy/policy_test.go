@@ -0,0 +1,210 @@
+package policy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/kubev2v/migration-planner/pkg/duckdb_parser/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	internalmodels "github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+var _ = Describe("Policies", func() {
+	var (
+		ctx context.Context
+		dir string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+	})
+
+	write := func(file, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600)).To(Succeed())
+	}
+
+	concernIDs := func(p *policy.Policies, vmName string) []string {
+		concerns, err := p.Validate(ctx, models.VM{ID: "vm-1", Name: vmName})
+		Expect(err).NotTo(HaveOccurred())
+		ids := []string{}
+		for _, c := range concerns {
+			ids = append(ids, c.Id)
+		}
+		return ids
+	}
+
+	Context("NewFromDir", func() {
+		// Given a folder without policies
+		// When we load its policies
+		// Then it should fail
+		It("should fail without policies", func() {
+			// Act
+			_, err := policy.NewFromDir(dir)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+
+		// Given a folder with a policy
+		// When we validate a VM it flags
+		// Then its concern should be returned
+		It("should evaluate the policies of the folder", func() {
+			// Arrange
+			write("rule.rego", test.PolicyRule("test.first", "db-1"))
+
+			// Act
+			p, err := policy.NewFromDir(dir)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Dir()).To(Equal(dir))
+			Expect(concernIDs(p, "db-1")).To(Equal([]string{"test.first"}))
+			Expect(concernIDs(p, "web-1")).To(BeEmpty())
+		})
+	})
+
+	Context("Reload", func() {
+		var p *policy.Policies
+
+		BeforeEach(func() {
+			write("rule.rego", test.PolicyRule("test.first", "db-1"))
+			var err error
+			p, err = policy.NewFromDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// Given policies that did not change
+		// When we reload them
+		// Then the reload should report no change
+		It("should not report unchanged policies", func() {
+			// Act
+			changed, err := p.Reload()
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+
+		// Given a policy edited and one added
+		// When we reload the policies
+		// Then the VMs should be evaluated with the new rules
+		It("should evaluate the updated policies", func() {
+			// Arrange
+			write("rule.rego", test.PolicyRule("test.first", "web-1"))
+			write("other.rego", test.PolicyRule("test.second", "web-1"))
+
+			// Act
+			changed, err := p.Reload()
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(concernIDs(p, "db-1")).To(BeEmpty())
+			Expect(concernIDs(p, "web-1")).To(ConsistOf("test.first", "test.second"))
+		})
+
+		// Given a policy that no longer compiles
+		// When we reload the policies
+		// Then the previous policies should stay in use
+		It("should keep the previous policies when one does not compile", func() {
+			// Arrange
+			write("rule.rego", "package io.konveyor.forklift.vmware\n\nconcerns contains flag if {")
+
+			// Act
+			changed, err := p.Reload()
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(concernIDs(p, "db-1")).To(Equal([]string{"test.first"}))
+		})
+	})
+
+	Context("Watch", func() {
+		// Given watched policies
+		// When a policy file changes
+		// Then the policies should be reloaded and onReload called
+		It("should reload the policies when their files change", func() {
+			// Arrange
+			write("rule.rego", test.PolicyRule("test.first", "db-1"))
+			p, err := policy.NewFromDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			watchCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			reloaded := make(chan struct{}, 1)
+			go p.Watch(watchCtx, func(context.Context) { reloaded <- struct{}{} })
+
+			// Act
+			write("rule.rego", test.PolicyRule("test.first", "web-1"))
+
+			// Assert
+			Eventually(reloaded, "5s").Should(Receive())
+			Expect(concernIDs(p, "web-1")).To(Equal([]string{"test.first"}))
+		})
+	})
+
+	Context("Status", func() {
+		var p *policy.Policies
+
+		BeforeEach(func() {
+			write("rule.rego", test.PolicyRule("test.first", "db-1"))
+			var err error
+			p, err = policy.NewFromDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// Given a built-in and a custom policy
+		// When we get the status of the policies
+		// Then both files should be listed with their source and hash
+		It("should list the files of the policies in use", func() {
+			// Arrange
+			Expect(policy.WriteCustom(dir, "naming.rego", []byte(test.PolicyRule("test.naming", "web-1")))).To(Succeed())
+			_, err := p.Reload()
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			set, err := p.Status()
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(set.Fingerprint).NotTo(BeEmpty())
+			Expect(set.LoadedAt).NotTo(BeZero())
+			Expect(set.Bundle).To(BeNil())
+			Expect(set.LoadError).To(BeEmpty())
+			Expect(set.Files).To(HaveLen(2))
+			Expect(set.Files[0].Name).To(Equal("custom/naming.rego"))
+			Expect(set.Files[0].Source).To(Equal(internalmodels.PolicySourceCustom))
+			Expect(set.Files[1].Name).To(Equal("rule.rego"))
+			Expect(set.Files[1].Source).To(Equal(internalmodels.PolicySourceBuiltin))
+			Expect(set.Files[1].Hash).To(HaveLen(64))
+		})
+
+		// Given a policy that does not compile
+		// When the policies are reloaded
+		// Then the status should report the error, the previous files in use
+		It("should report the error of the last reload", func() {
+			// Arrange
+			before, err := p.Status()
+			Expect(err).NotTo(HaveOccurred())
+			write("broken.rego", "package io.konveyor.forklift.vmware\n\nconcerns contains")
+
+			// Act
+			_, reloadErr := p.Reload()
+			set, err := p.Status()
+
+			// Assert
+			Expect(reloadErr).To(HaveOccurred())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(set.LoadError).NotTo(BeEmpty())
+			Expect(set.Fingerprint).To(Equal(before.Fingerprint))
+			Expect(set.Files).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/kubev2v/migration-planner/pkg/opa"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/tester"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// testTimeout bounds the run of each rego test.
+const testTimeout = 5 * time.Second
+
+// ErrInvalidPolicyTests is the error of rego tests, or of the policies they
+// test, that do not compile.
+var ErrInvalidPolicyTests = errors.New("invalid policy tests")
+
+// ValidCandidateName tells whether name can be the file name of a candidate
+// of RunTests: a custom policy or a rego test.
+func ValidCandidateName(name string) bool {
+	return ValidCustomName(name) || validTestName(name)
+}
+
+// RunTests runs the rego tests of the policies folder dir, the test_ rules of
+// its _test.rego files, against its policies. The tests of the custom
+// subfolder run apart against the custom policies, like they are compiled,
+// along candidates: custom policies and tests by file name, not written, so a
+// custom policy can be tested before it is uploaded.
+func RunTests(ctx context.Context, dir string, candidates map[string]string) (models.PolicyTestReport, error) {
+	for name := range candidates {
+		if !ValidCandidateName(name) {
+			return models.PolicyTestReport{}, fmt.Errorf("invalid policy file name %q", name)
+		}
+	}
+
+	policies, err := opa.NewPolicyReader().ReadPolicies(dir)
+	if err != nil {
+		return models.PolicyTestReport{}, err
+	}
+	tests, err := readTests(dir)
+	if err != nil {
+		return models.PolicyTestReport{}, err
+	}
+	customDir := filepath.Join(dir, CustomDir)
+	customPolicies, err := readPolicies(customDir)
+	if err != nil {
+		return models.PolicyTestReport{}, err
+	}
+	customTests, err := readTests(customDir)
+	if err != nil {
+		return models.PolicyTestReport{}, err
+	}
+	for name, content := range candidates {
+		if validTestName(name) {
+			customTests[name] = content
+		} else {
+			customPolicies[name] = content
+		}
+	}
+
+	report := models.PolicyTestReport{Results: []models.PolicyTestResult{}}
+	if err := runTests(ctx, &report, "", policies, tests); err != nil {
+		return models.PolicyTestReport{}, err
+	}
+	if err := runTests(ctx, &report, CustomDir+"/", customPolicies, customTests); err != nil {
+		return models.PolicyTestReport{}, err
+	}
+	return report, nil
+}
+
+// runTests runs tests against policies and adds their results to report, the
+// files being prefixed with prefix.
+func runTests(ctx context.Context, report *models.PolicyTestReport, prefix string, policies, tests map[string]string) error {
+	if len(tests) == 0 {
+		return nil
+	}
+
+	modules := make(map[string]*ast.Module, len(policies)+len(tests))
+	for _, set := range []map[string]string{policies, tests} {
+		for name, content := range set {
+			module, err := ast.ParseModuleWithOpts(prefix+name, content, ast.ParserOptions{RegoVersion: ast.RegoV1})
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidPolicyTests, err)
+			}
+			modules[prefix+name] = module
+		}
+	}
+
+	ch, err := tester.NewRunner().
+		SetDefaultRegoVersion(ast.RegoV1).
+		SetTimeout(testTimeout).
+		SetModules(modules).
+		RunTests(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPolicyTests, err)
+	}
+
+	for r := range ch {
+		result := models.PolicyTestResult{
+			Package:  r.Package,
+			Name:     r.Name,
+			Duration: r.Duration,
+		}
+		if r.Location != nil {
+			result.File = r.Location.File
+		}
+
+		switch {
+		case r.Skip:
+			result.Status = models.PolicyTestSkipped
+			report.Skipped++
+		case r.Error != nil:
+			result.Status = models.PolicyTestError
+			result.Error = r.Error.Error()
+			report.Failed++
+		case r.Fail:
+			result.Status = models.PolicyTestFailed
+			report.Failed++
+		default:
+			result.Status = models.PolicyTestPassed
+			report.Passed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return nil
+}
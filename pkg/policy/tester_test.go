@@ -0,0 +1,104 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/policy"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+// policyTest returns a rego test named name expecting a concern for the VM
+// named vmName.
+func policyTest(name, vmName string) string {
+	return `package io.konveyor.forklift.vmware
+
+` + name + ` if {
+	count(concerns) == 1 with input as {"name": "` + vmName + `"}
+}
+`
+}
+
+var _ = Describe("Policy tests", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "shipped.rego"), []byte(test.PolicyRule("test.shipped", "db-1")), 0o600)).To(Succeed())
+	})
+
+	writeTest := func(name, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)).To(Succeed())
+	}
+
+	// Given a folder without tests
+	// When we run the tests
+	// Then the report should be empty
+	It("should report no tests", func() {
+		// Act
+		report, err := policy.RunTests(context.Background(), dir, nil)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Results).To(BeEmpty())
+	})
+
+	// Given a passing and a failing test
+	// When we run the tests
+	// Then each should be reported with its status
+	It("should report the passed and failed tests", func() {
+		// Arrange
+		writeTest("shipped_test.rego", policyTest("test_db", "db-1")+"\ntest_web if {\n\tcount(concerns) == 1 with input as {\"name\": \"web-1\"}\n}\n")
+
+		// Act
+		report, err := policy.RunTests(context.Background(), dir, nil)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Passed).To(Equal(1))
+		Expect(report.Failed).To(Equal(1))
+		Expect(report.Results).To(ConsistOf(
+			And(HaveField("File", "shipped_test.rego"), HaveField("Name", "test_db"), HaveField("Status", models.PolicyTestPassed)),
+			And(HaveField("Name", "test_web"), HaveField("Status", models.PolicyTestFailed)),
+		))
+	})
+
+	// Given a candidate custom policy and its test
+	// When we run the tests with them
+	// Then the test should run against the custom policy, nothing being written
+	It("should run the tests of the candidate custom policies", func() {
+		// Act
+		report, err := policy.RunTests(context.Background(), dir, map[string]string{
+			"naming.rego":      test.PolicyRule("test.naming", "web-1"),
+			"naming_test.rego": policyTest("test_naming", "web-1"),
+		})
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Passed).To(Equal(1))
+		Expect(report.Results[0].File).To(Equal(policy.CustomDir + "/naming_test.rego"))
+		custom, err := policy.ListCustom(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(custom).To(BeEmpty())
+	})
+
+	// Given a test that does not compile
+	// When we run the tests
+	// Then it should fail with ErrInvalidPolicyTests
+	It("should fail for tests that do not compile", func() {
+		// Arrange
+		writeTest("shipped_test.rego", "package io.konveyor.forklift.vmware\n\ntest_db if {")
+
+		// Act
+		_, err := policy.RunTests(context.Background(), dir, nil)
+
+		// Assert
+		Expect(errors.Is(err, policy.ErrInvalidPolicyTests)).To(BeTrue())
+	})
+})
@@ -0,0 +1,138 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+const (
+	// webhookQueueSize is the number of reports waiting to be sent before the
+	// next ones are dropped.
+	webhookQueueSize = 100
+	webhookTimeout   = 10 * time.Second
+)
+
+// Payload is the JSON body posted by a Webhook for each report.
+type Payload struct {
+	AgentID  string            `json:"agentId"`
+	SourceID string            `json:"sourceId"`
+	Version  string            `json:"version"`
+	Kind     string            `json:"kind"`
+	Time     time.Time         `json:"time"`
+	Message  string            `json:"message"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// Webhook is a models.ErrorReporter posting each report as a Payload to a URL.
+// The reports are sent in the background, one at a time, so Report never
+// blocks; a report that cannot be sent is logged and dropped.
+type Webhook struct {
+	url    string
+	agent  config.Agent
+	client *http.Client
+	queue  chan Payload
+	done   chan struct{}
+}
+
+// WebhookOption configures the transport of a Webhook.
+type WebhookOption func(t *http.Transport)
+
+// WithProxy routes the reports through the proxy returned by proxy, a nil URL
+// meaning a direct connection. Without it the HTTP(S)_PROXY environment
+// variables are used.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) WebhookOption {
+	return func(t *http.Transport) {
+		t.Proxy = proxy
+	}
+}
+
+// NewWebhook returns a Webhook posting the reports of the agent to u and
+// starts sending them. Close stops it.
+func NewWebhook(u string, agent config.Agent, opts ...WebhookOption) *Webhook {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	for _, o := range opts {
+		o(transport)
+	}
+
+	w := &Webhook{
+		url:    u,
+		agent:  agent,
+		client: &http.Client{Transport: otelhttp.NewTransport(transport), Timeout: webhookTimeout},
+		queue:  make(chan Payload, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Report queues report to be posted.
+func (w *Webhook) Report(_ context.Context, report models.ErrorReport) {
+	payload := Payload{
+		AgentID:  w.agent.ID,
+		SourceID: w.agent.SourceID,
+		Version:  w.agent.Version,
+		Kind:     string(report.Kind),
+		Time:     report.Time,
+		Message:  report.Message,
+		Details:  report.Details,
+	}
+
+	select {
+	case w.queue <- payload:
+	default:
+		zap.S().Named("error_webhook").Warnw("error report queue full, dropping report", "kind", report.Kind, "message", report.Message)
+	}
+}
+
+// Close sends the queued reports and stops the webhook. Report must not be
+// called afterwards.
+func (w *Webhook) Close() {
+	close(w.queue)
+	<-w.done
+}
+
+func (w *Webhook) run() {
+	defer close(w.done)
+	for payload := range w.queue {
+		if err := w.send(payload); err != nil {
+			zap.S().Named("error_webhook").Warnw("failed to send error report", "kind", payload.Kind, "error", err)
+		}
+	}
+}
+
+func (w *Webhook) send(payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package reporting_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/reporting"
+)
+
+var _ = Describe("Webhook", func() {
+	var (
+		mu       sync.Mutex
+		received []reporting.Payload
+		status   int
+		hook     *httptest.Server
+		agent    config.Agent
+	)
+
+	BeforeEach(func() {
+		received = nil
+		status = http.StatusNoContent
+		hook = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload reporting.Payload
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, payload)
+			w.WriteHeader(status)
+		}))
+		agent = config.Agent{
+			ID:       "5c8f3a52-8f44-4d3a-9c1b-0a6f1d2e7b90",
+			SourceID: "0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b",
+			Version:  "v1.2.3",
+		}
+	})
+
+	AfterEach(func() {
+		hook.Close()
+	})
+
+	payloads := func() []reporting.Payload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]reporting.Payload(nil), received...)
+	}
+
+	// Given a webhook
+	// When an error is reported
+	// Then it should be posted with the identity of the agent
+	It("posts the reports with the agent identity", func() {
+		// Arrange
+		w := reporting.NewWebhook(hook.URL, agent)
+		at := time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC)
+
+		// Act
+		w.Report(context.Background(), models.ErrorReport{
+			Kind:    models.ErrorReportCollectionFailed,
+			Time:    at,
+			Message: "inventory collection failed",
+			Details: map[string]string{"phase": "collecting"},
+		})
+		w.Close()
+
+		// Assert
+		Expect(payloads()).To(HaveLen(1))
+		payload := payloads()[0]
+		Expect(payload.AgentID).To(Equal(agent.ID))
+		Expect(payload.SourceID).To(Equal(agent.SourceID))
+		Expect(payload.Version).To(Equal("v1.2.3"))
+		Expect(payload.Kind).To(Equal("collection_failed"))
+		Expect(payload.Time).To(Equal(at))
+		Expect(payload.Message).To(Equal("inventory collection failed"))
+		Expect(payload.Details).To(HaveKeyWithValue("phase", "collecting"))
+	})
+
+	// Given a webhook answering errors
+	// When errors are reported
+	// Then each report should still be tried once and the next ones sent
+	It("keeps sending after a failed report", func() {
+		// Arrange
+		status = http.StatusServiceUnavailable
+		w := reporting.NewWebhook(hook.URL, agent)
+
+		// Act
+		w.Report(context.Background(), models.ErrorReport{Kind: models.ErrorReportPanic, Message: "first"})
+		w.Report(context.Background(), models.ErrorReport{Kind: models.ErrorReportPanic, Message: "second"})
+		w.Close()
+
+		// Assert
+		Expect(payloads()).To(HaveLen(2))
+		Expect(payloads()[1].Message).To(Equal("second"))
+	})
+})
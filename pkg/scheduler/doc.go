@@ -36,6 +36,7 @@
 //   - Maintains a work queue for pending work requests
 //   - Runs an event loop dispatching work to available workers
 //   - Supports graceful shutdown via Close()
+//   - Reports its workers, busy workers and queued work via Stats()
 //
 // Worker:
 //   - Executes a single work function
@@ -75,7 +76,7 @@
 //     ▼
 //  6. Worker executes work function:
 //     - Calls fn(ctx) with cancellable context
-//     - Sends Result{Data, Err} to result channel
+//     - Sends Result{Data, Err, Execution} to result channel
 //     - Signals completion via done channel
 //     - Returns to worker pool
 //     │
@@ -111,6 +112,22 @@
 //	    future.Stop()  // Cancel the work
 //	}
 //
+// # Execution Metadata
+//
+// Each Result carries an Execution with the time the work was queued by
+// AddWork, picked by a worker and finished, and the number of times a worker
+// ran it. Services log how long the work waited and ran without timing the
+// work functions themselves:
+//
+//	result := <-future.C()
+//	zap.S().Debugw("work finished",
+//	    "wait", result.Execution.Wait(),
+//	    "duration", result.Execution.Duration(),
+//	    "attempts", result.Execution.Attempts)
+//
+// Work rejected by a closing scheduler is finished at once, with no attempts
+// and a zero StartedAt.
+//
 // # Worker Pool Mechanism
 //
 // The scheduler maintains two queues:
@@ -180,6 +197,13 @@
 //   - The future receives an error result
 //   - The worker returns to the pool
 //
+// The panics can also be passed to a handler, e.g. to report them, with an
+// option of NewScheduler:
+//
+//	sched := scheduler.NewScheduler(4, scheduler.WithPanicHandler(func(recovered any, stack []byte) {
+//	    reporter.Report(ctx, ...)
+//	}))
+//
 // # Cancellation
 //
 // Each work request gets a context derived from the scheduler's main context:
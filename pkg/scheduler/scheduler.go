@@ -3,7 +3,10 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type queue[T any] []T
@@ -22,31 +25,54 @@ func (wq *queue[T]) Push(t T) {
 }
 
 type workRequest struct {
-	fn  Work[any]
-	c   chan Result[any]
-	ctx context.Context
+	fn       Work[any]
+	c        chan Result[any]
+	ctx      context.Context
+	queuedAt time.Time
 }
 
 type worker struct {
-	done chan any
-	wg   *sync.WaitGroup
+	done    chan any
+	wg      *sync.WaitGroup
+	onPanic PanicHandler
 }
 
 func (w worker) Work(r workRequest) {
+	exec := Execution{QueuedAt: r.queuedAt, StartedAt: time.Now(), Attempts: 1}
 	defer func() {
 		if rec := recover(); rec != nil {
-			r.c <- Result[any]{Err: fmt.Errorf("worker panicked: %v", rec)}
+			if w.onPanic != nil {
+				w.onPanic(rec, debug.Stack())
+			}
+			exec.FinishedAt = time.Now()
+			r.c <- Result[any]{Err: fmt.Errorf("worker panicked: %v", rec), Execution: exec}
 		}
 		w.done <- struct{}{}
 		w.wg.Done()
 	}()
 
 	v, err := r.fn(r.ctx)
-	r.c <- Result[any]{Data: v, Err: err}
+	exec.FinishedAt = time.Now()
+	r.c <- Result[any]{Data: v, Err: err, Execution: exec}
 }
 
-func newWorker(done chan any, wg *sync.WaitGroup) worker {
-	return worker{done: done, wg: wg}
+func newWorker(done chan any, wg *sync.WaitGroup, onPanic PanicHandler) worker {
+	return worker{done: done, wg: wg, onPanic: onPanic}
+}
+
+// PanicHandler is called with the value and the stack of a panic recovered
+// in a work function, before the panic is returned as the error of its result.
+type PanicHandler func(recovered any, stack []byte)
+
+// Option configures a Scheduler.
+type Option func(s *Scheduler)
+
+// WithPanicHandler calls handler on each panic of a work function, e.g. to
+// report it.
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(s *Scheduler) {
+		s.onPanic = handler
+	}
 }
 
 type Scheduler struct {
@@ -59,9 +85,22 @@ type Scheduler struct {
 	mainCancel context.CancelFunc
 	wg         sync.WaitGroup
 	once       sync.Once
+	onPanic    PanicHandler
+
+	// counts published by dispatch for Stats, the queues being owned by run
+	nbWorkers int
+	busy      atomic.Int64
+	queued    atomic.Int64
 }
 
-func NewScheduler(nbWorkers int) *Scheduler {
+// Stats is a snapshot of the load of a Scheduler.
+type Stats struct {
+	Workers int `json:"workers"`
+	Busy    int `json:"busy"`
+	Queued  int `json:"queued"`
+}
+
+func NewScheduler(nbWorkers int, opts ...Option) *Scheduler {
 	done := make(chan any, nbWorkers)
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &Scheduler{
@@ -72,9 +111,13 @@ func NewScheduler(nbWorkers int) *Scheduler {
 		work:       make(chan workRequest),
 		mainCtx:    ctx,
 		mainCancel: cancel,
+		nbWorkers:  nbWorkers,
+	}
+	for _, o := range opts {
+		o(s)
 	}
 	for range nbWorkers {
-		s.workers.Push(newWorker(done, &s.wg))
+		s.workers.Push(newWorker(done, &s.wg, s.onPanic))
 	}
 	go s.run()
 	return s
@@ -83,12 +126,13 @@ func NewScheduler(nbWorkers int) *Scheduler {
 func (s *Scheduler) AddWork(w Work[any]) *Future[Result[any]] {
 	c := make(chan Result[any], 1)
 	ctx, cancel := context.WithCancel(s.mainCtx)
+	queuedAt := time.Now()
 
 	select {
 	case <-s.mainCtx.Done():
 		// we're closing here so send a result with an error
-		c <- Result[any]{Err: context.Canceled}
-	case s.work <- workRequest{w, c, ctx}:
+		c <- Result[any]{Err: context.Canceled, Execution: Execution{QueuedAt: queuedAt, FinishedAt: time.Now()}}
+	case s.work <- workRequest{fn: w, c: c, ctx: ctx, queuedAt: queuedAt}:
 	}
 
 	return NewFuture(c, cancel)
@@ -110,7 +154,7 @@ func (s *Scheduler) run() {
 			s.workQueue.Push(w)
 			s.dispatch()
 		case <-s.done:
-			s.workers.Push(newWorker(s.done, &s.wg))
+			s.workers.Push(newWorker(s.done, &s.wg, s.onPanic))
 			s.dispatch()
 		case <-s.close:
 			s.wg.Wait()
@@ -119,6 +163,16 @@ func (s *Scheduler) run() {
 	}
 }
 
+// Stats returns the number of workers, of the busy ones and of the work
+// requests waiting for one.
+func (s *Scheduler) Stats() Stats {
+	return Stats{
+		Workers: s.nbWorkers,
+		Busy:    int(s.busy.Load()),
+		Queued:  int(s.queued.Load()),
+	}
+}
+
 // dispatch drains the workQueue as much as possible
 // based on available workers
 func (s *Scheduler) dispatch() {
@@ -128,4 +182,6 @@ func (s *Scheduler) dispatch() {
 		s.wg.Add(1)
 		go worker.Work(r)
 	}
+	s.busy.Store(int64(s.nbWorkers - s.workers.Len()))
+	s.queued.Store(int64(s.workQueue.Len()))
 }
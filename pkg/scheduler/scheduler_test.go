@@ -202,6 +202,105 @@ var _ = Describe("Scheduler", func() {
 		})
 	})
 
+	Context("Stats", func() {
+		// Given a scheduler with one worker running work and another waiting
+		// When we get its stats
+		// Then it should count the busy worker and the queued work, then none once done
+		It("should count the busy workers and the queued work", func() {
+			// Arrange
+			s = scheduler.NewScheduler(1)
+			unblock := make(chan struct{})
+			work := func(ctx context.Context) (any, error) {
+				<-unblock
+				return "done", nil
+			}
+			first := s.AddWork(work)
+			second := s.AddWork(work)
+
+			// Act
+			Eventually(s.Stats, 1*time.Second).Should(Equal(scheduler.Stats{Workers: 1, Busy: 1, Queued: 1}))
+			close(unblock)
+
+			// Assert
+			Eventually(first.C(), 1*time.Second).Should(Receive())
+			Eventually(second.C(), 1*time.Second).Should(Receive())
+			Eventually(s.Stats, 1*time.Second).Should(Equal(scheduler.Stats{Workers: 1}))
+		})
+	})
+
+	Context("Execution", func() {
+		// Given a scheduler with one worker busy with a first work
+		// When a second work is queued behind it
+		// Then its result should tell how long it waited and ran
+		It("should time the wait and the run of the work", func() {
+			// Arrange
+			s = scheduler.NewScheduler(1)
+			unblock := make(chan struct{})
+			s.AddWork(func(ctx context.Context) (any, error) {
+				<-unblock
+				return nil, nil
+			})
+
+			// Act
+			future := s.AddWork(func(ctx context.Context) (any, error) {
+				time.Sleep(50 * time.Millisecond)
+				return "done", nil
+			})
+			time.Sleep(100 * time.Millisecond)
+			close(unblock)
+
+			// Assert
+			var result scheduler.Result[any]
+			Eventually(future.C(), 2*time.Second).Should(Receive(&result))
+			Expect(result.Execution.Attempts).To(Equal(1))
+			Expect(result.Execution.Wait()).To(BeNumerically(">=", 100*time.Millisecond))
+			Expect(result.Execution.Duration()).To(BeNumerically(">=", 50*time.Millisecond))
+			Expect(result.Execution.FinishedAt).To(BeTemporally(">=", result.Execution.StartedAt))
+		})
+
+		// Given a work function that panics
+		// When it is executed
+		// Then its result should still carry the execution
+		It("should time panicking work", func() {
+			// Arrange
+			s = scheduler.NewScheduler(1)
+
+			// Act
+			future := s.AddWork(func(ctx context.Context) (any, error) {
+				panic("boom")
+			})
+
+			// Assert
+			var result scheduler.Result[any]
+			Eventually(future.C(), 2*time.Second).Should(Receive(&result))
+			Expect(result.Execution.Attempts).To(Equal(1))
+			Expect(result.Execution.StartedAt).NotTo(BeZero())
+			Expect(result.Execution.FinishedAt).NotTo(BeZero())
+		})
+
+		// Given a closed scheduler
+		// When we add work
+		// Then its result should have no attempts and no run time
+		It("should not count attempts of rejected work", func() {
+			// Arrange
+			s = scheduler.NewScheduler(1)
+			s.Close()
+
+			// Act
+			future := s.AddWork(func(ctx context.Context) (any, error) {
+				return "done", nil
+			})
+
+			// Assert
+			var result scheduler.Result[any]
+			Eventually(future.C(), 1*time.Second).Should(Receive(&result))
+			Expect(result.Execution.Attempts).To(BeZero())
+			Expect(result.Execution.StartedAt).To(BeZero())
+			Expect(result.Execution.Duration()).To(BeZero())
+			Expect(result.Execution.QueuedAt).NotTo(BeZero())
+		})
+	})
+
 	Context("Panic recovery", func() {
 		// Given a work function that panics
 		// When the scheduler executes it
@@ -251,6 +350,29 @@ var _ = Describe("Scheduler", func() {
 			Expect(result2.Err).NotTo(HaveOccurred())
 			Expect(result2.Data).To(Equal("recovered"))
 		})
+
+		// Given a scheduler with a panic handler
+		// When a work function panics
+		// Then the handler should receive the panic and its stack
+		It("should pass the panic to the panic handler", func() {
+			// Arrange
+			handled := make(chan any, 1)
+			var stack []byte
+			s = scheduler.NewScheduler(1, scheduler.WithPanicHandler(func(recovered any, st []byte) {
+				stack = st
+				handled <- recovered
+			}))
+
+			// Act
+			future := s.AddWork(func(ctx context.Context) (any, error) {
+				panic("boom")
+			})
+
+			// Assert
+			Eventually(future.C(), 2*time.Second).Should(Receive())
+			Eventually(handled).Should(Receive(Equal("boom")))
+			Expect(string(stack)).To(ContainSubstring("scheduler"))
+		})
 	})
 
 	Context("FIFO ordering", func() {
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"time"
 )
 
 type Work[T any] func(ctx context.Context) (T, error)
@@ -9,6 +10,36 @@ type Work[T any] func(ctx context.Context) (T, error)
 type Result[T any] struct {
 	Data T
 	Err  error
+	// Execution tells how long the work waited for a worker and ran
+	Execution Execution
+}
+
+// Execution is the execution metadata of a work request: when it was queued,
+// picked by a worker and finished, and how many times a worker ran it. A
+// request rejected by a closing Scheduler is finished as soon as it is
+// queued, without attempts.
+type Execution struct {
+	QueuedAt   time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Attempts   int
+}
+
+// Wait returns how long the work waited for a worker, or until it was
+// rejected when it never ran.
+func (e Execution) Wait() time.Duration {
+	if e.StartedAt.IsZero() {
+		return e.FinishedAt.Sub(e.QueuedAt)
+	}
+	return e.StartedAt.Sub(e.QueuedAt)
+}
+
+// Duration returns how long the work ran, 0 when it never ran.
+func (e Execution) Duration() time.Duration {
+	if e.StartedAt.IsZero() {
+		return 0
+	}
+	return e.FinishedAt.Sub(e.StartedAt)
 }
 
 type Future[T any] struct {
@@ -0,0 +1,211 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// deviceGrantType is the grant of the token requests of the device flow (RFC 8628)
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultInterval is the polling interval when the server does not give one
+	defaultInterval = 5 * time.Second
+	// scope requested by the device authorization
+	scope = "openid"
+	// requestTimeout bounds each request to the identity provider
+	requestTimeout = 30 * time.Second
+)
+
+// Errors of the token endpoint while a device authorization is not complete.
+var (
+	// ErrAuthorizationPending means the user has not entered the code yet.
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// ErrSlowDown means the client polls too often and must wait longer.
+	ErrSlowDown = errors.New("slow down")
+	// ErrExpiredToken means the device code expired before the user entered it.
+	ErrExpiredToken = errors.New("device code expired")
+	// ErrAccessDenied means the user denied the authorization.
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// DeviceAuthorization is a pending device authorization: the user enters
+// UserCode at VerificationURI, or opens VerificationURIComplete, while the
+// client polls the token endpoint with DeviceCode every Interval.
+type DeviceAuthorization struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresIn               time.Duration
+	Interval                time.Duration
+}
+
+// TokenSet is the response of the token endpoint. RefreshToken is empty when
+// the identity provider does not issue one.
+type TokenSet struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+// Client runs the OAuth device authorization flow against a Keycloak realm,
+// such as https://sso.redhat.com/auth/realms/redhat-external.
+type Client struct {
+	realmURL   string
+	clientID   string
+	httpClient *http.Client
+}
+
+// ClientOption configures the transport of the client.
+type ClientOption func(t *http.Transport)
+
+// WithProxy routes the requests through the proxy returned by proxy, a nil URL
+// meaning a direct connection. Without it the HTTP(S)_PROXY environment
+// variables are used.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) ClientOption {
+	return func(t *http.Transport) {
+		t.Proxy = proxy
+	}
+}
+
+// NewClient returns a client of the public OAuth client clientID of the realm
+// at realmURL.
+func NewClient(realmURL, clientID string, opts ...ClientOption) *Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	for _, o := range opts {
+		o(t)
+	}
+	return &Client{
+		realmURL:   strings.TrimSuffix(realmURL, "/"),
+		clientID:   clientID,
+		httpClient: &http.Client{Transport: t, Timeout: requestTimeout},
+	}
+}
+
+// AuthorizeDevice starts a device authorization.
+func (c *Client) AuthorizeDevice(ctx context.Context) (*DeviceAuthorization, error) {
+	var resp struct {
+		DeviceCode              string `json:"device_code"`
+		UserCode                string `json:"user_code"`
+		VerificationURI         string `json:"verification_uri"`
+		VerificationURIComplete string `json:"verification_uri_complete"`
+		ExpiresIn               int    `json:"expires_in"`
+		Interval                int    `json:"interval"`
+	}
+	if err := c.post(ctx, "/protocol/openid-connect/auth/device", url.Values{
+		"client_id": {c.clientID},
+		"scope":     {scope},
+	}, &resp); err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+
+	auth := &DeviceAuthorization{
+		DeviceCode:              resp.DeviceCode,
+		UserCode:                resp.UserCode,
+		VerificationURI:         resp.VerificationURI,
+		VerificationURIComplete: resp.VerificationURIComplete,
+		ExpiresIn:               time.Duration(resp.ExpiresIn) * time.Second,
+		Interval:                time.Duration(resp.Interval) * time.Second,
+	}
+	if auth.Interval <= 0 {
+		auth.Interval = defaultInterval
+	}
+	return auth, nil
+}
+
+// DeviceToken polls the token of the device authorization deviceCode. It fails
+// with ErrAuthorizationPending, ErrSlowDown, ErrExpiredToken or ErrAccessDenied
+// while the authorization is not granted.
+func (c *Client) DeviceToken(ctx context.Context, deviceCode string) (*TokenSet, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":  {deviceGrantType},
+		"client_id":   {c.clientID},
+		"device_code": {deviceCode},
+	})
+}
+
+// RefreshToken returns a new access token for refreshToken.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*TokenSet, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {c.clientID},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *Client) token(ctx context.Context, form url.Values) (*TokenSet, error) {
+	var resp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := c.post(ctx, "/protocol/openid-connect/token", form, &resp); err != nil {
+		return nil, err
+	}
+	if resp.AccessToken == "" {
+		return nil, errors.New("token response without access token")
+	}
+	return &TokenSet{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    time.Duration(resp.ExpiresIn) * time.Second,
+	}, nil
+}
+
+// post sends form to the endpoint path of the realm and decodes the response
+// into out, mapping the OAuth errors of the device flow to their sentinel.
+func (c *Client) post(ctx context.Context, path string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.realmURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &oauthErr)
+		switch oauthErr.Error {
+		case "authorization_pending":
+			return ErrAuthorizationPending
+		case "slow_down":
+			return ErrSlowDown
+		case "expired_token":
+			return ErrExpiredToken
+		case "access_denied":
+			return ErrAccessDenied
+		case "":
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		if oauthErr.ErrorDescription != "" {
+			return fmt.Errorf("%s: %s", oauthErr.Error, oauthErr.ErrorDescription)
+		}
+		return errors.New(oauthErr.Error)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
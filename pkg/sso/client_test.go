@@ -0,0 +1,150 @@
+package sso_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/sso"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		ctx     context.Context
+		handler http.HandlerFunc
+		server  *httptest.Server
+		client  *sso.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+		client = sso.NewClient(server.URL+"/auth/realms/test/", "agent")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	reply := func(w http.ResponseWriter, status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	// Given an identity provider supporting the device flow
+	// When we start a device authorization
+	// Then the user code should be returned with its durations
+	It("starts a device authorization", func() {
+		// Arrange
+		var clientID, path string
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			clientID = r.PostFormValue("client_id")
+			reply(w, http.StatusOK, map[string]any{
+				"device_code":               "device-1",
+				"user_code":                 "ABCD-EFGH",
+				"verification_uri":          "https://sso.example.com/device",
+				"verification_uri_complete": "https://sso.example.com/device?user_code=ABCD-EFGH",
+				"expires_in":                600,
+			})
+		}
+
+		// Act
+		auth, err := client.AuthorizeDevice(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal("/auth/realms/test/protocol/openid-connect/auth/device"))
+		Expect(clientID).To(Equal("agent"))
+		Expect(auth.DeviceCode).To(Equal("device-1"))
+		Expect(auth.UserCode).To(Equal("ABCD-EFGH"))
+		Expect(auth.VerificationURIComplete).To(Equal("https://sso.example.com/device?user_code=ABCD-EFGH"))
+		Expect(auth.ExpiresIn).To(Equal(10 * time.Minute))
+		Expect(auth.Interval).To(Equal(5 * time.Second))
+	})
+
+	// Given a device authorization not granted yet, then granted
+	// When we poll its token
+	// Then the pending error and then the token should be returned
+	It("polls the device token", func() {
+		// Arrange
+		granted := false
+		var grantType, deviceCode string
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			grantType = r.PostFormValue("grant_type")
+			deviceCode = r.PostFormValue("device_code")
+			if !granted {
+				reply(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+				return
+			}
+			reply(w, http.StatusOK, map[string]any{"access_token": "jwt", "refresh_token": "refresh", "expires_in": 900})
+		}
+
+		// Act
+		_, pendingErr := client.DeviceToken(ctx, "device-1")
+		granted = true
+		tokens, err := client.DeviceToken(ctx, "device-1")
+
+		// Assert
+		Expect(pendingErr).To(MatchError(sso.ErrAuthorizationPending))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grantType).To(Equal("urn:ietf:params:oauth:grant-type:device_code"))
+		Expect(deviceCode).To(Equal("device-1"))
+		Expect(tokens.AccessToken).To(Equal("jwt"))
+		Expect(tokens.RefreshToken).To(Equal("refresh"))
+		Expect(tokens.ExpiresIn).To(Equal(15 * time.Minute))
+	})
+
+	// Given the OAuth errors of the device flow
+	// When we poll the device token
+	// Then each should be mapped to its sentinel error
+	It("maps the device flow errors", func() {
+		// Arrange
+		var oauthErr string
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			reply(w, http.StatusBadRequest, map[string]string{"error": oauthErr, "error_description": "details"})
+		}
+		poll := func(e string) error {
+			oauthErr = e
+			_, err := client.DeviceToken(ctx, "device-1")
+			return err
+		}
+
+		// Act & Assert
+		Expect(poll("slow_down")).To(MatchError(sso.ErrSlowDown))
+		Expect(poll("expired_token")).To(MatchError(sso.ErrExpiredToken))
+		Expect(poll("access_denied")).To(MatchError(sso.ErrAccessDenied))
+		Expect(poll("invalid_client")).To(MatchError("invalid_client: details"))
+	})
+
+	// Given a refresh token
+	// When we refresh it
+	// Then a new access token should be returned
+	It("refreshes a token", func() {
+		// Arrange
+		var grantType, refreshToken string
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			grantType = r.PostFormValue("grant_type")
+			refreshToken = r.PostFormValue("refresh_token")
+			reply(w, http.StatusOK, map[string]any{"access_token": "jwt-2", "expires_in": 900})
+		}
+
+		// Act
+		tokens, err := client.RefreshToken(ctx, "refresh")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grantType).To(Equal("refresh_token"))
+		Expect(refreshToken).To(Equal("refresh"))
+		Expect(tokens.AccessToken).To(Equal("jwt-2"))
+		Expect(tokens.RefreshToken).To(BeEmpty())
+	})
+})
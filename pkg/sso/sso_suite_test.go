@@ -0,0 +1,13 @@
+package sso_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSSO(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SSO Suite")
+}
@@ -0,0 +1,125 @@
+// Package update verifies and installs the agent binaries published on the
+// update channels of the console.
+package update
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+const (
+	// MaxBinarySize bounds the size of a downloaded agent binary.
+	MaxBinarySize = 512 << 20
+	// PreviousSuffix names the binary replaced by the last update, kept to
+	// roll back by hand.
+	PreviousSuffix = ".previous"
+)
+
+var (
+	// ErrInvalidSignature is returned for a manifest not signed by the key of the channel.
+	ErrInvalidSignature = errors.New("invalid update signature")
+	// ErrChecksumMismatch is returned for a binary whose digest differs from the manifest.
+	ErrChecksumMismatch = errors.New("update checksum mismatch")
+)
+
+// ParsePublicKey decodes the base64 encoded ed25519 public key of a channel.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid update public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid update public key: %d bytes, expected %d", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// SignedData returns the data signed for the manifest m: its version, a new
+// line and the digest of its binary.
+func SignedData(m models.UpdateManifest) []byte {
+	return []byte(m.Version + "\n" + strings.ToLower(m.SHA256))
+}
+
+// Verify checks that the manifest m is signed with key and that its version
+// is a semantic version.
+func Verify(m models.UpdateManifest, key ed25519.PublicKey) error {
+	if !semver.IsValid(m.Version) {
+		return fmt.Errorf("invalid update version %q", m.Version)
+	}
+	if digest, err := hex.DecodeString(m.SHA256); err != nil || len(digest) != sha256.Size {
+		return fmt.Errorf("invalid update checksum %q", m.SHA256)
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil || !ed25519.Verify(key, SignedData(m), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Newer reports whether version is newer than current. A current version that
+// is not a semantic version, such as the one of a development build, is older
+// than any.
+func Newer(version, current string) bool {
+	if !semver.IsValid(version) {
+		return false
+	}
+	return !semver.IsValid(current) || semver.Compare(version, current) > 0
+}
+
+// Install writes the binary read from r over the executable at path once its
+// digest matches sha256Hex. The executable in place is kept with the
+// PreviousSuffix, and is left untouched when the binary cannot be written or
+// verified. The running agent keeps its binary until it restarts.
+func Install(path string, r io.Reader, sha256Hex string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, MaxBinarySize+1))
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if n > MaxBinarySize {
+		return fmt.Errorf("update larger than %d bytes", MaxBinarySize)
+	}
+	if !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), sha256Hex) {
+		return ErrChecksumMismatch
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o100); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, path+PreviousSuffix); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		// put the executable back, the agent must still start
+		_ = os.Rename(path+PreviousSuffix, path)
+		return err
+	}
+	return nil
+}
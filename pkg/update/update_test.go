@@ -0,0 +1,168 @@
+package update_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/update"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+var _ = Describe("Update", func() {
+	var (
+		public  ed25519.PublicKey
+		private ed25519.PrivateKey
+	)
+
+	BeforeEach(func() {
+		var err error
+		public, private, err = ed25519.GenerateKey(nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("ParsePublicKey", func() {
+		// Given a base64 encoded ed25519 public key
+		// When we parse it
+		// Then the key should be returned
+		It("should parse a base64 key", func() {
+			// Act
+			key, err := update.ParsePublicKey(base64.StdEncoding.EncodeToString(public) + "\n")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key).To(Equal(public))
+		})
+
+		// Given a key of the wrong size
+		// When we parse it
+		// Then it should fail
+		It("should reject a key of the wrong size", func() {
+			// Act
+			_, err := update.ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short")))
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Verify", func() {
+		// Given a manifest signed with the key of the channel
+		// When we verify it
+		// Then it should pass
+		It("should accept a signed manifest", func() {
+			// Arrange
+			m := test.NewUpdateManifest(private, "v2.1.0", "/binary", []byte("agent"))
+
+			// Act
+			err := update.Verify(m, public)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// Given a signed manifest whose version was changed
+		// When we verify it
+		// Then it should fail with ErrInvalidSignature
+		It("should reject a tampered manifest", func() {
+			// Arrange
+			m := test.NewUpdateManifest(private, "v2.1.0", "/binary", []byte("agent"))
+			m.Version = "v9.0.0"
+
+			// Act
+			err := update.Verify(m, public)
+
+			// Assert
+			Expect(errors.Is(err, update.ErrInvalidSignature)).To(BeTrue())
+		})
+
+		// Given a manifest signed with another key
+		// When we verify it
+		// Then it should fail with ErrInvalidSignature
+		It("should reject a manifest signed with another key", func() {
+			// Arrange
+			_, other, err := ed25519.GenerateKey(nil)
+			Expect(err).NotTo(HaveOccurred())
+			m := test.NewUpdateManifest(other, "v2.1.0", "/binary", []byte("agent"))
+
+			// Act
+			err = update.Verify(m, public)
+
+			// Assert
+			Expect(errors.Is(err, update.ErrInvalidSignature)).To(BeTrue())
+		})
+	})
+
+	Context("Newer", func() {
+		// Given versions of the channel newer than the current one
+		// When we compare them
+		// Then they should be newer
+		It("should report the newer versions", func() {
+			Expect(update.Newer("v2.1.0", "v2.0.0")).To(BeTrue())
+			Expect(update.Newer("v2.1.0", "v2.1.0-rc1")).To(BeTrue())
+			Expect(update.Newer("v2.0.0", "unknown")).To(BeTrue())
+		})
+
+		// Given versions of the channel not newer than the current one
+		// When we compare them
+		// Then they should not be newer
+		It("should not report the same, older or invalid versions", func() {
+			Expect(update.Newer("v2.0.0", "v2.0.0")).To(BeFalse())
+			Expect(update.Newer("v1.9.0", "v2.0.0")).To(BeFalse())
+			Expect(update.Newer("latest", "v2.0.0")).To(BeFalse())
+		})
+	})
+
+	Context("Install", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "agent")
+			Expect(os.WriteFile(path, []byte("old"), 0o755)).To(Succeed())
+		})
+
+		// Given a binary matching its digest
+		// When we install it
+		// Then it should replace the executable, the old one being kept
+		It("should replace the executable", func() {
+			// Arrange
+			m := test.NewUpdateManifest(private, "v2.1.0", "/binary", []byte("new"))
+
+			// Act
+			err := update.Install(path, bytes.NewReader([]byte("new")), m.SHA256)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(path)).To(Equal([]byte("new")))
+			Expect(os.ReadFile(path + update.PreviousSuffix)).To(Equal([]byte("old")))
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o755)))
+		})
+
+		// Given a binary not matching its digest
+		// When we install it
+		// Then it should fail with ErrChecksumMismatch, the executable left untouched
+		It("should reject a corrupted binary", func() {
+			// Arrange
+			m := test.NewUpdateManifest(private, "v2.1.0", "/binary", []byte("new"))
+
+			// Act
+			err := update.Install(path, bytes.NewReader([]byte("corrupted")), m.SHA256)
+
+			// Assert
+			Expect(errors.Is(err, update.ErrChecksumMismatch)).To(BeTrue())
+			Expect(os.ReadFile(path)).To(Equal([]byte("old")))
+			Expect(path + update.PreviousSuffix).NotTo(BeAnExistingFile())
+			entries, err := os.ReadDir(filepath.Dir(path))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+	})
+})
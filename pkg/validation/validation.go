@@ -0,0 +1,121 @@
+// Package validation checks the fields of the API requests, collecting an
+// error per invalid field for the 400 responses of the handlers.
+package validation
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// FieldError is the error of a request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// Errors are the field errors of a request, in the order they were found.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fe := range e {
+		messages = append(messages, fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects the field errors of a request. Its checks are chained
+// and Err returns what they found:
+//
+//	err := validation.New().
+//	    Required("url", req.Url).
+//	    URL("url", req.Url).
+//	    Err()
+type Validator struct {
+	errs Errors
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Check records message for field unless ok.
+func (v *Validator) Check(field string, ok bool, message string) *Validator {
+	if !ok {
+		v.errs = append(v.errs, FieldError{Field: field, Message: message})
+	}
+	return v
+}
+
+// Required checks that value is not empty.
+func (v *Validator) Required(field, value string) *Validator {
+	return v.Check(field, value != "", field+" is required")
+}
+
+// NotEmpty checks that the list field has items, n being its length.
+func (v *Validator) NotEmpty(field string, n int) *Validator {
+	return v.Check(field, n > 0, field+" must not be empty")
+}
+
+// URL checks that value is an absolute URL with a host. An empty value is left
+// to Required.
+func (v *Validator) URL(field, value string) *Validator {
+	if value == "" {
+		return v
+	}
+	u, err := url.Parse(value)
+	return v.Check(field, err == nil && u.Scheme != "" && u.Host != "", fmt.Sprintf("invalid %s %q: must be an absolute url", field, value))
+}
+
+// UUID checks that value is a UUID. An empty value is left to Required.
+func (v *Validator) UUID(field, value string) *Validator {
+	if value == "" {
+		return v
+	}
+	_, err := uuid.Parse(value)
+	return v.Check(field, err == nil, fmt.Sprintf("invalid %s %q: must be a uuid", field, value))
+}
+
+// OneOf checks that value is one of allowed. An empty value is left to
+// Required.
+func (v *Validator) OneOf(field, value string, allowed ...string) *Validator {
+	if value == "" {
+		return v
+	}
+	for _, a := range allowed {
+		if value == a {
+			return v
+		}
+	}
+	return v.Check(field, false, fmt.Sprintf("invalid %s %q: must be one of %s", field, value, strings.Join(allowed, ", ")))
+}
+
+// Err returns the field errors found, as Errors, or nil.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Range checks that the bounds of a range, each of them optional, are in
+// order. The error is recorded for minField.
+func Range[T cmp.Ordered](v *Validator, minField, maxField string, min, max *T) *Validator {
+	if min == nil || max == nil {
+		return v
+	}
+	return v.Check(minField, *min <= *max, fmt.Sprintf("%s cannot be greater than %s", minField, maxField))
+}
+
+// Between checks that value is within [min, max].
+func Between[T cmp.Ordered](v *Validator, field string, value, min, max T) *Validator {
+	return v.Check(field, value >= min && value <= max, fmt.Sprintf("invalid %s %v: must be between %v and %v", field, value, min, max))
+}
@@ -0,0 +1,99 @@
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/validation"
+)
+
+var _ = Describe("Validator", func() {
+	// Given valid values for every check
+	// When we validate them
+	// Then no error should be returned
+	It("should return nil when every check passes", func() {
+		// Act
+		v := validation.New().
+			Required("url", "https://vcenter.example.com").
+			URL("url", "https://vcenter.example.com").
+			UUID("id", "6f8b8a9e-1c2d-4e5f-8a9b-0c1d2e3f4a5b").
+			OneOf("mode", "connected", "connected", "disconnected").
+			NotEmpty("vmIds", 1)
+		min, max := 1, 2
+		validation.Range(v, "min", "max", &min, &max)
+		validation.Between(v, "pageSize", 20, 1, 100)
+
+		// Assert
+		Expect(v.Err()).To(BeNil())
+	})
+
+	// Given invalid values for several fields
+	// When we validate them
+	// Then an error per field should be returned in order
+	It("should collect the errors of every field", func() {
+		// Act
+		v := validation.New().
+			Required("url", "").
+			UUID("id", "not-a-uuid").
+			OneOf("mode", "offline", "connected", "disconnected").
+			NotEmpty("vmIds", 0)
+		validation.Between(v, "pageSize", 500, 1, 100)
+		err := v.Err()
+
+		// Assert
+		var errs validation.Errors
+		Expect(err).To(BeAssignableToTypeOf(errs))
+		Expect(err.(validation.Errors)).To(Equal(validation.Errors{
+			{Field: "url", Message: "url is required"},
+			{Field: "id", Message: `invalid id "not-a-uuid": must be a uuid`},
+			{Field: "mode", Message: `invalid mode "offline": must be one of connected, disconnected`},
+			{Field: "vmIds", Message: "vmIds must not be empty"},
+			{Field: "pageSize", Message: "invalid pageSize 500: must be between 1 and 100"},
+		}))
+	})
+
+	// Given an empty optional value
+	// When we check its format
+	// Then it should be left to Required
+	It("should not check the format of empty values", func() {
+		// Act
+		err := validation.New().
+			URL("url", "").
+			UUID("id", "").
+			OneOf("mode", "", "connected").
+			Err()
+
+		// Assert
+		Expect(err).To(BeNil())
+	})
+
+	// Given URLs without a scheme or a host
+	// When we check them
+	// Then they should be rejected
+	It("should reject relative urls", func() {
+		// Act
+		err := validation.New().
+			URL("url", "vcenter.example.com").
+			URL("proxy", "https://").
+			Err()
+
+		// Assert
+		Expect(err).To(MatchError(`invalid url "vcenter.example.com": must be an absolute url; invalid proxy "https://": must be an absolute url`))
+	})
+
+	// Given range bounds out of order, and bounds with one side unset
+	// When we check the ranges
+	// Then only the ordered pair should be rejected
+	It("should check the order of set range bounds", func() {
+		// Arrange
+		low, high := int64(500), int64(1000)
+		v := validation.New()
+
+		// Act
+		validation.Range(v, "diskSizeMin", "diskSizeMax", &high, &low)
+		validation.Range(v, "memorySizeMin", "memorySizeMax", &high, nil)
+
+		// Assert
+		Expect(v.Err()).To(MatchError("diskSizeMin cannot be greater than diskSizeMax"))
+	})
+})
@@ -3,6 +3,7 @@ package vmware
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 
 	"github.com/vmware/govmomi"
@@ -19,12 +20,13 @@ import (
 //   - username: the username for authentication.
 //   - password: the password for authentication.
 //   - insecure: if true, skips TLS certificate verification (use only for testing).
+//   - proxy: the proxy of the requests, the HTTP(S)_PROXY environment variables when nil.
 //
 // Returns an error if:
 //   - the vCenter URL cannot be parsed,
 //   - the vim25 client creation fails,
 //   - or authentication to vCenter fails.
-func NewVsphereClient(ctx context.Context, vcenterUrl, username, password string, insecure bool) (*govmomi.Client, error) {
+func NewVsphereClient(ctx context.Context, vcenterUrl, username, password string, insecure bool, proxy func(*http.Request) (*url.URL, error)) (*govmomi.Client, error) {
 	u, err := soap.ParseURL(vcenterUrl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse vCenter URL: %w", err)
@@ -33,11 +35,15 @@ func NewVsphereClient(ctx context.Context, vcenterUrl, username, password string
 	u.User = url.UserPassword(username, password)
 
 	soapClient := soap.NewClient(u, insecure)
+	if proxy != nil {
+		soapClient.DefaultTransport().Proxy = proxy
+	}
 
 	vimClient, err := vim25.NewClient(ctx, soapClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vim25 client: %w", err)
 	}
+	TraceCalls(vimClient)
 
 	client := &govmomi.Client{
 		Client:         vimClient,
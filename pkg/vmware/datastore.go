@@ -0,0 +1,157 @@
+package vmware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	gopath "path"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// DatastoreReader opens the disks stored on the datastores of a vCenter, to
+// measure the read throughput of their transfer. It only reads: nothing is
+// changed on the datastores.
+type DatastoreReader struct {
+	gc *govmomi.Client
+}
+
+// NewDatastoreReader creates a reader of the datastores of the vCenter of gc,
+// which it logs out of on Close.
+//
+// Parameters:
+//   - gc: an authenticated govmomi client.
+func NewDatastoreReader(gc *govmomi.Client) *DatastoreReader {
+	return &DatastoreReader{gc: gc}
+}
+
+// Datastores returns the names of the datastores of every datacenter.
+//
+// Returns an error if:
+//   - the datacenters or their datastores cannot be listed.
+func (r *DatastoreReader) Datastores(ctx context.Context) ([]string, error) {
+	datastores, err := r.datastores(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(datastores))
+	for _, ds := range datastores {
+		names = append(names, ds.Name())
+	}
+	return names, nil
+}
+
+// Open opens the largest disk of a datastore, its flat extent being read
+// sequentially like during its transfer.
+//
+// Parameters:
+//   - ctx: the context for the API requests and the download.
+//   - name: the name of the datastore.
+//
+// Returns an error if:
+//   - the datastore cannot be found or holds no disk,
+//   - the datastore cannot be searched,
+//   - or the download of the disk fails to start, e.g. on a disk locked by its
+//     running VM.
+func (r *DatastoreReader) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	datastores, err := r.datastores(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ds *object.Datastore
+	for _, d := range datastores {
+		if d.Name() == name {
+			ds = d
+			break
+		}
+	}
+	if ds == nil {
+		return nil, fmt.Errorf("datastore %s not found", name)
+	}
+
+	path, err := largestDisk(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, _, err := ds.Download(ctx, path, &soap.DefaultDownload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ds.Path(path), err)
+	}
+	return rc, nil
+}
+
+// Close logs out of the vCenter.
+func (r *DatastoreReader) Close(ctx context.Context) error {
+	return r.gc.Logout(ctx)
+}
+
+func (r *DatastoreReader) datastores(ctx context.Context) ([]*object.Datastore, error) {
+	finder := find.NewFinder(r.gc.Client, true)
+	dcs, err := finder.DatacenterList(ctx, "*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datacenters: %w", err)
+	}
+
+	var datastores []*object.Datastore
+	for _, dc := range dcs {
+		finder.SetDatacenter(dc)
+		list, err := finder.DatastoreList(ctx, "*")
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the datastores of %s: %w", dc.Name(), err)
+		}
+		datastores = append(datastores, list...)
+	}
+	return datastores, nil
+}
+
+// largestDisk returns the path, in ds, of its largest flat disk extent.
+func largestDisk(ctx context.Context, ds *object.Datastore) (string, error) {
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	spec := types.HostDatastoreBrowserSearchSpec{
+		MatchPattern: []string{"*-flat.vmdk"},
+		Details:      &types.FileQueryFlags{FileSize: true, FileType: true},
+	}
+	task, err := browser.SearchDatastoreSubFolders(ctx, ds.Path(""), &spec)
+	if err != nil {
+		return "", err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to search %s: %w", ds.Name(), err)
+	}
+
+	results, _ := info.Result.(types.ArrayOfHostDatastoreBrowserSearchResults)
+	var (
+		path string
+		size int64 = -1
+	)
+	for _, res := range results.HostDatastoreBrowserSearchResults {
+		var folder object.DatastorePath
+		if !folder.FromString(res.FolderPath) {
+			continue
+		}
+		for _, f := range res.File {
+			if fi := f.GetFileInfo(); fi.FileSize > size {
+				path, size = gopath.Join(folder.Path, fi.Path), fi.FileSize
+			}
+		}
+	}
+	if path == "" {
+		return "", fmt.Errorf("no disk found on datastore %s", ds.Name())
+	}
+	return path, nil
+}
@@ -0,0 +1,46 @@
+package vmware
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/tracing"
+)
+
+// TraceCalls makes each vSphere API call of c start a client span, a child of
+// the span carried by the context of the call.
+func TraceCalls(c *vim25.Client) {
+	c.RoundTripper = &tracingRoundTripper{next: c.RoundTripper, host: c.URL().Host}
+}
+
+// tracingRoundTripper starts a client span per vSphere API call, named after
+// the called method (vsphere.RetrievePropertiesEx).
+type tracingRoundTripper struct {
+	next soap.RoundTripper
+	host string
+}
+
+func (t *tracingRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	ctx, span := tracing.Tracer().Start(ctx, "vsphere."+method(req),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("server.address", t.host)),
+	)
+	err := t.next.RoundTrip(ctx, req, res)
+	tracing.End(span, err)
+	return err
+}
+
+// method returns the vSphere method of req, a *methods.<Method>Body.
+func method(req soap.HasFault) string {
+	typ := reflect.TypeOf(req)
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return strings.TrimSuffix(typ.Name(), "Body")
+}
@@ -5,22 +5,33 @@ Package main provides end-to-end testing infrastructure for the assisted-migrati
 
 	test/e2e/
 	├── main.go          Entry point: flags, config, InfraManager setup, Ginkgo runner
-	├── tests.go         Ginkgo test specs (disconnected env, connected env, collector)
+	├── tests.go         Ginkgo test specs (disconnected env, connected env, collector, inspector)
+	├── report.go        JUnit and HTML reports, agent logs attached to the specs
 	├── doc.go           This file
 	├── infra/           Infrastructure management
 	│   ├── infra.go     InfraManager interface + AgentConfig + vcsim constants
-	│   ├── container.go ContainerInfraManager (Podman-based)
+	│   ├── container.go ContainerInfraManager + ContainerRunner (Podman or Docker)
+	│   ├── kind.go      KindInfraManager (Deployments on a Kind cluster)
 	│   ├── vm.go        VMInfraManager (no-op, externally managed)
 	│   ├── podman.go    PodmanRunner + ContainerConfig (low-level Podman API)
-	│   ├── proxy.go     Reverse proxy (sits between agent and backend)
+	│   ├── docker.go    DockerRunner (low-level Docker engine API)
+	│   ├── suite.go     Suite (ports and names of a parallel Ginkgo process)
+	│   ├── vcsim.go     VcsimModel (generated or recorded vcsim inventory)
+	│   ├── oidc.go      OIDCServer (mock OIDC provider: JWKS, tokens, key rotation)
+	│   ├── proxy.go     Reverse proxy (sits between agent and backend), optionally over TLS
+	│   ├── certs.go     Self-signed certificates for the TLS proxies
 	│   └── observer.go  Request observer (collects proxy traffic for assertions)
+	├── matchers/
+	│   └── requests.go  Gomega matchers on the requests seen by an Observer
 	├── model/
 	│   └── auth.go      User type for JWT auth
 	├── service/
 	│   ├── agent.go         AgentSvc — HTTP client for the agent's local API
+	│   ├── agent_api.go     AgentSvc methods on the client generated from api/v1
 	│   ├── interfaces.go    PlannerService interface
 	│   ├── service_api.go   ServiceApi — HTTP client with JWT auth
 	│   ├── service.go       PlannerSvc constructor
+	│   ├── inspector.go     Inspector and events API methods on AgentSvc
 	│   ├── source.go        Source API methods on PlannerSvc
 	│   └── assessment.go    Assessment API methods on PlannerSvc
 	└── utils/
@@ -36,15 +47,92 @@ InfraManager is the central abstraction for infrastructure lifecycle:
 	type InfraManager interface {
 	    StartPostgres() / StopPostgres()
 	    StartBackend()  / StopBackend()
-	    StartVcsim()    / StopVcsim()
+	    StartVcsim(model) / StopVcsim()
 	    StartAgent(cfg) / StopAgent() / RestartAgent() / RemoveAgent()
 	}
 
-Two implementations:
-  - ContainerInfraManager — uses Podman to start/stop containers (default).
-  - VMInfraManager — no-op; infrastructure is managed externally (Kind + deploy/e2e.mk).
+Three implementations:
+  - ContainerInfraManager — uses Podman or Docker to start/stop containers (default).
+  - KindInfraManager — deploys the same containers to a Kubernetes cluster.
+  - VMInfraManager — no-op; infrastructure is managed externally.
 
-Selected via the -infra-mode flag ("container" or "vm").
+Selected via the -infra-mode flag ("container", "kind" or "vm").
+
+# OIDC
+
+Every mode runs the OIDCServer in-process, returned by InfraManager.OIDC once
+started. Besides the tokens of GenerateToken, valid for 24 hours, MintToken
+signs tokens with any expiry and not-before time, e.g. an expired one.
+RotateKey switches to a new signing key while the JWKS keeps serving the
+previous ones, until RevokeKey removes one of them, so the tokens it signed
+are rejected once the JWKS is fetched again.
+
+# Container Runtimes
+
+ContainerInfraManager runs its containers through a ContainerRunner selected
+via the -container-runtime flag:
+  - podman (default) — PodmanRunner, on the -podman-socket socket.
+  - docker — DockerRunner, on -docker-host, else DOCKER_HOST or the default
+    socket. It pulls the missing images itself. The containers share the host
+    network, so Docker Desktop needs host networking enabled (Settings >
+    Resources > Network).
+
+# Kind
+
+KindInfraManager creates a Deployment and a Service per container in the
+assisted-migration-e2e namespace (suffixed with -n on process n) of the
+-kubeconfig cluster, -kube-context selecting the context, and waits for their
+pods to be ready. The images are expected in the cluster: make e2e.kind loads
+them with "kind load docker-image" first.
+
+The tests still reach Postgres, the backend, vcsim and the agent on localhost
+through port-forwards of the suite ports. RestartAgent deletes the agent pod
+and forwards to its replacement, which keeps the agent volume (a
+PersistentVolumeClaim) until RemoveAgent. In the agent pod, a socat sidecar
+serves the ports the agent expects on localhost: vcsim through its Service and
+the in-process proxies through -kind-host-address, the host as seen from the
+pods, e.g. the gateway of the kind network. The backend gets the JWKS URL of
+the OIDC server on that address. Recorded vcsim inventories and the ISO are
+not mounted in this mode.
+
+# vcsim Inventories
+
+StartVcsim serves an infra.VcsimModel: DefaultVcsimModel, the 6 VMs most specs
+need, a model with other datacenter, cluster, host, VM and datastore counts,
+or RecordedVcsimModel(dir), the inventory saved in dir with
+"govc object.save", mounted into the container and loaded with vcsim -load.
+
+The "large inventory" specs collect 500 VMs and page through them to cover
+pagination, deduplication and the collection time on a sizeable inventory;
+-vcsim-recording replaces their model with a recorded inventory.
+
+# Parallel Runs
+
+The disconnected and connected envs are ordered containers of their own, so
+the ginkgo CLI can run them on parallel processes (make e2e.container.parallel).
+Each process gets an infra.Suite from GinkgoParallelProcess(): the ports of
+process n are the defaults shifted by (n-1)*100 (Postgres 5432, backend
+3443/7443/11443, vcsim 8989, agent 8000, OIDC 9090, the proxies
+8080/8081/8082/8083 and the mock console 8084) and its container and volume names are suffixed with -n.
+Process 1 keeps the defaults of a serial run. The containers share the host
+network, the agent reaching the in-process proxies on localhost, so the suites
+are kept apart by their ports rather than by container networks.
+
+The endpoint flags (-backend-agent-endpoint, -agent-api-url...) default to the
+ports of the suite; setting them pins every process to the same endpoint, which
+only suits serial runs, as does the vm mode.
+
+# Reports
+
+The infra managers follow the logs of the agent from StartAgent on, across
+RestartAgent, and a failed spec gets the lines logged during its run as an
+"agent logs" report entry, shown by the console output, so a failure can be
+diagnosed without -keep-containers. The vm mode has no agent logs.
+
+With -artifacts-dir (E2E_ARTIFACTS_DIR in the Makefile), the suite writes
+junit.xml and report.html to that directory once every process is done. Every
+spec then gets its agent logs: the JUnit report shows them for the failed
+specs, the HTML summary collapsed under every spec.
 
 # Proxy & Observer
 
@@ -64,11 +152,51 @@ The Observer reads from that channel and accumulates requests for test assertion
 In disconnected mode, the Proxy is used to verify that the agent does NOT contact
 the backend. In connected mode, it is used for logging.
 
+The disconnected env runs no backend: its Proxy forwards to a
+test/mockconsole.Server, which answers the agent and source status endpoints
+with programmable responses (status, latency), e.g. 410 Gone to check that the
+agent stops reporting. The unit tests of the console service use it too.
+
+The matchers package asserts on an Observer, or on a []Request taken from it:
+
+	Expect(obs).To(HaveReceivedPUT("/api/v1/agents/"+agentID+"/status").WithJSONField("status", "collected"))
+	Expect(obs).ToNot(HaveReceivedAnyRequest())
+	Expect(obs).To(HaveReceivedAnyRequest().Times(n))
+	Expect(obs).To(HaveReceivedInOrder(
+		HaveReceivedPUT("/api/v1/agents/"+agentID+"/status"),
+		HaveReceivedPUT("/api/v1/sources/"+sourceID+"/status"),
+	))
+
+A "*" segment in a path matches any id, WithJSONField takes a dot-separated path
+into the request body, WithBodyContaining matches a substring of it, e.g. a SOAP
+method, and WithStatus filters on the response status. Failures list the
+observed requests.
+
+# Inspector
+
+The "inspector" specs start the agent with AgentConfig.Features set to
+"inspector" (AMA_FEATURES) and give the inspector the vcsim SDK behind an
+observable proxy (Suite.VcsimProxyURL, plain HTTP to the HTTPS vcsim), so the
+CreateSnapshot_Task and RemoveSnapshot_Task calls of each inspected VM are
+asserted in order, and their absence for the VMs left out. The results are
+read back from /vms/inspector, /vms/{id}/inspector and the inspection events.
+
+# Real vCenter
+
+The specs labelled "vcenter" collect the inventory of the -vcenter-url vCenter
+(-vcenter-user, -vcenter-password) instead of vcsim, to catch the govmomi
+behaviors vcsim does not simulate, and inspect the -vcenter-inspect-vms VMs,
+which snapshots them. They are skipped when the flags are not set, and
+--ginkgo.label-filter=vcenter (make e2e.vcenter) runs them alone.
+
 # Makefile Targets
 
-	make e2e                  Run e2e tests (default: container mode)
-	make e2e.container        Run e2e tests in container mode (Podman)
-	make e2e.vm               Run e2e tests in VM mode (externally managed infra)
-	make e2e.container.clean  Remove all e2e test containers and volumes
+	make e2e                     Run e2e tests (default: container mode)
+	make e2e.container           Run e2e tests in container mode (E2E_CONTAINER_RUNTIME=docker for Docker)
+	make e2e.container.parallel  Run e2e tests in container mode on E2E_PROCS processes
+	make e2e.kind                Run e2e tests in kind mode (KIND_CLUSTER, E2E_KIND_HOST_ADDRESS)
+	make e2e.vcenter             Run the "vcenter" e2e tests against E2E_VCENTER_URL
+	make e2e.vm                  Run e2e tests in VM mode (externally managed infra)
+	make e2e.container.clean     Remove all e2e test containers and volumes
 */
 package main
@@ -1,6 +1,13 @@
 package infra
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
 
 const (
 	dbContainerName      = "test-planner-db"
@@ -8,39 +15,71 @@ const (
 	backendContainerName = "test-planner"
 	vcsimContainerName   = "test-vcsim"
 	vcsimImage           = "docker.io/vmware/vcsim:latest"
-	vcsimPort            = 8989
 	agentVolumeName      = "test-agent-data"
+	agentDataFolder      = "/var/lib/agent"
 
 	// Database configuration
 	dbType     = "pgsql"
 	dbHost     = "localhost"
-	dbPort     = "5432"
 	dbName     = "planner"
 	dbUser     = "planner"
 	dbPassword = "adminpass"
 )
 
-// ContainerInfraManager implements InfraManager using Podman containers.
+// Container runtimes of ContainerInfraManager.
+const (
+	RuntimePodman = "podman"
+	RuntimeDocker = "docker"
+)
+
+// ContainerRunner starts and stops the containers of ContainerInfraManager.
+// PodmanRunner and DockerRunner implement it.
+type ContainerRunner interface {
+	StartContainer(cfg *ContainerConfig) (string, error)
+	StopContainer(id string) error
+	RestartContainer(id string) error
+	RemoveContainer(id string) error
+	RemoveVolume(name string) error
+	// FollowLogs writes the logs of the container from since, or from its
+	// start when since is zero, to w until ctx is done or the container stops.
+	FollowLogs(ctx context.Context, id string, since time.Time, w io.Writer) error
+}
+
+// NewContainerRunner returns the runner of runtime, podman or docker,
+// connected to its socket. An empty docker socket means DOCKER_HOST or the
+// default socket.
+func NewContainerRunner(runtime, socket string) (ContainerRunner, error) {
+	switch runtime {
+	case RuntimePodman:
+		return NewPodmanRunner(socket)
+	case RuntimeDocker:
+		return NewDockerRunner(socket)
+	default:
+		return nil, fmt.Errorf("unknown container runtime %q", runtime)
+	}
+}
+
+// ContainerInfraManager implements InfraManager using Podman or Docker containers.
 type ContainerInfraManager struct {
-	runner       *PodmanRunner
+	runner       ContainerRunner
+	suite        Suite
 	backendImage string
 	agentImage   string
 	isoPath      string
 	oidc         *OIDCServer
+	agentLogs    agentLogs
 }
 
-// NewContainerInfraManager creates a new ContainerInfraManager.
-func NewContainerInfraManager(podmanSocket, backendImage, agentImage, isoPath string) (*ContainerInfraManager, error) {
-	runner, err := NewPodmanRunner(podmanSocket)
-	if err != nil {
-		return nil, err
-	}
+// NewContainerInfraManager creates a new ContainerInfraManager running the
+// containers of suite with runner.
+func NewContainerInfraManager(runner ContainerRunner, suite Suite, backendImage, agentImage, isoPath string) *ContainerInfraManager {
 	return &ContainerInfraManager{
 		runner:       runner,
+		suite:        suite,
 		backendImage: backendImage,
 		agentImage:   agentImage,
 		isoPath:      isoPath,
-	}, nil
+	}
 }
 
 func (c *ContainerInfraManager) StartOIDC(addr string) error {
@@ -66,45 +105,29 @@ func (c *ContainerInfraManager) GenerateToken(username, orgID, email string) (st
 	return c.oidc.GenerateToken(username, orgID, email)
 }
 
+func (c *ContainerInfraManager) OIDC() *OIDCServer {
+	return c.oidc
+}
+
 func (c *ContainerInfraManager) StartPostgres() error {
-	_, err := c.runner.StartContainer(
-		NewContainerConfig(dbContainerName, "docker.io/library/postgres:17").
-			WithPort(5432, 5432).
-			WithEnvVar("POSTGRES_USER", dbUser).
-			WithEnvVar("POSTGRES_PASSWORD", dbPassword).
-			WithEnvVar("POSTGRES_DB", dbName),
-	)
+	_, err := c.runner.StartContainer(postgresContainerConfig(c.suite))
 	return err
 }
 
 func (c *ContainerInfraManager) StopPostgres() error {
-	if err := c.runner.StopContainer(dbContainerName); err != nil {
+	name := c.suite.Name(dbContainerName)
+	if err := c.runner.StopContainer(name); err != nil {
 		return err
 	}
-	return c.runner.RemoveContainer(dbContainerName)
+	return c.runner.RemoveContainer(name)
 }
 
 func (c *ContainerInfraManager) StartBackend() error {
-	cfg := NewContainerConfig(backendContainerName, c.backendImage).
-		WithPort(7443, 7443).
-		WithPort(3443, 3443).
-		WithEnvVar("DB_TYPE", dbType).
-		WithEnvVar("DB_HOST", dbHost).
-		WithEnvVar("DB_PORT", dbPort).
-		WithEnvVar("DB_NAME", dbName).
-		WithEnvVar("DB_USER", dbUser).
-		WithEnvVar("DB_PASS", dbPassword).
-		WithEnvVar("MIGRATION_PLANNER_MIGRATIONS_FOLDER", "/app/migrations").
-		WithEnvVar("MIGRATION_PLANNER_AGENT_AUTH_ENABLED", "false").
-		WithEnvVar("MIGRATION_PLANNER_LOG_LEVEL", "debug")
-
+	jwksURL := ""
 	if c.oidc != nil {
-		cfg = cfg.
-			WithEnvVar("MIGRATION_PLANNER_AUTH", "rhsso").
-			WithEnvVar("MIGRATION_PLANNER_JWK_URL", c.oidc.JWKSURL())
-	} else {
-		cfg = cfg.WithEnvVar("MIGRATION_PLANNER_AUTH", "none")
+		jwksURL = c.oidc.JWKSURL()
 	}
+	cfg := backendContainerConfig(c.suite, c.backendImage, dbHost, jwksURL)
 
 	if c.isoPath != "" {
 		cfg = cfg.WithBindMount(c.isoPath, "/iso").
@@ -116,69 +139,151 @@ func (c *ContainerInfraManager) StartBackend() error {
 }
 
 func (c *ContainerInfraManager) StopBackend() error {
-	if err := c.runner.StopContainer(backendContainerName); err != nil {
+	name := c.suite.Name(backendContainerName)
+	if err := c.runner.StopContainer(name); err != nil {
 		return err
 	}
-	return c.runner.RemoveContainer(backendContainerName)
-}
-
-func (c *ContainerInfraManager) StartVcsim() error {
-	_, err := c.runner.StartContainer(
-		NewContainerConfig(vcsimContainerName, vcsimImage).
-			WithPort(vcsimPort, vcsimPort).
-			WithCmd(
-				"-l", ":8989",
-				"-username", VcsimUsername,
-				"-password", VcsimPassword,
-				"-dc", "1",
-				"-cluster", "1",
-				"-ds", "1",
-				"-host", "1",
-				"-vm", "3",
-			),
-	)
+	return c.runner.RemoveContainer(name)
+}
+
+func (c *ContainerInfraManager) StartVcsim(model VcsimModel) error {
+	cfg := vcsimContainerConfig(c.suite, model)
+	if model.RecordingDir != "" {
+		cfg = cfg.WithBindMount(model.RecordingDir, vcsimRecordingPath)
+	}
+
+	_, err := c.runner.StartContainer(cfg)
 	return err
 }
 
 func (c *ContainerInfraManager) StopVcsim() error {
-	if err := c.runner.StopContainer(vcsimContainerName); err != nil {
+	name := c.suite.Name(vcsimContainerName)
+	if err := c.runner.StopContainer(name); err != nil {
 		return err
 	}
-	return c.runner.RemoveContainer(vcsimContainerName)
+	return c.runner.RemoveContainer(name)
 }
 
 func (c *ContainerInfraManager) StartAgent(cfg AgentConfig) (string, error) {
+	id, err := c.runner.StartContainer(agentContainerConfig(c.suite, c.agentImage, cfg))
+	if err != nil {
+		return "", err
+	}
+	c.followAgentLogs(time.Time{})
+	return id, nil
+}
+
+func (c *ContainerInfraManager) StopAgent() error {
+	defer c.agentLogs.stop()
+	return c.runner.StopContainer(c.suite.Name(agentContainerName))
+}
+
+func (c *ContainerInfraManager) RestartAgent() error {
+	// the stream of the stopped container ends, the new one starts at the restart
+	c.agentLogs.stop()
+	restarted := time.Now()
+	if err := c.runner.RestartContainer(c.suite.Name(agentContainerName)); err != nil {
+		return err
+	}
+	c.followAgentLogs(restarted)
+	return nil
+}
+
+func (c *ContainerInfraManager) RemoveAgent() error {
+	name := c.suite.Name(agentContainerName)
+	c.agentLogs.stop()
+	c.agentLogs.reset()
+	_ = c.runner.StopContainer(name)
+	_ = c.runner.RemoveContainer(name)
+	return c.runner.RemoveVolume(c.suite.Name(agentVolumeName))
+}
+
+func (c *ContainerInfraManager) AgentLogs(since time.Time) string {
+	return c.agentLogs.since(since)
+}
+
+func (c *ContainerInfraManager) followAgentLogs(since time.Time) {
+	name := c.suite.Name(agentContainerName)
+	c.agentLogs.follow(func(ctx context.Context, w io.Writer) error {
+		return c.runner.FollowLogs(ctx, name, since, w)
+	})
+}
+
+// The containers are described once for the container and the Kind managers,
+// the latter turning them into Deployments.
+
+func postgresContainerConfig(suite Suite) *ContainerConfig {
+	return NewContainerConfig(suite.Name(dbContainerName), "docker.io/library/postgres:17").
+		WithPort(suite.Postgres, suite.Postgres).
+		WithEnvVar("POSTGRES_USER", dbUser).
+		WithEnvVar("POSTGRES_PASSWORD", dbPassword).
+		WithEnvVar("POSTGRES_DB", dbName).
+		WithCmd("-p", strconv.Itoa(suite.Postgres))
+}
+
+// backendContainerConfig returns the backend reaching Postgres on dbHost,
+// authenticating the users against jwksURL when it is not empty.
+func backendContainerConfig(suite Suite, image, dbHost, jwksURL string) *ContainerConfig {
+	cfg := NewContainerConfig(suite.Name(backendContainerName), image).
+		WithPort(suite.BackendAgent, suite.BackendAgent).
+		WithPort(suite.BackendUser, suite.BackendUser).
+		WithEnvVar("MIGRATION_PLANNER_ADDRESS", Addr(suite.BackendUser)).
+		WithEnvVar("MIGRATION_PLANNER_AGENT_ENDPOINT_ADDRESS", Addr(suite.BackendAgent)).
+		WithEnvVar("MIGRATION_PLANNER_IMAGE_ENDPOINT_ADDRESS", Addr(suite.BackendImage)).
+		WithEnvVar("DB_TYPE", dbType).
+		WithEnvVar("DB_HOST", dbHost).
+		WithEnvVar("DB_PORT", strconv.Itoa(suite.Postgres)).
+		WithEnvVar("DB_NAME", dbName).
+		WithEnvVar("DB_USER", dbUser).
+		WithEnvVar("DB_PASS", dbPassword).
+		WithEnvVar("MIGRATION_PLANNER_MIGRATIONS_FOLDER", "/app/migrations").
+		WithEnvVar("MIGRATION_PLANNER_AGENT_AUTH_ENABLED", "false").
+		WithEnvVar("MIGRATION_PLANNER_LOG_LEVEL", "debug")
+
+	if jwksURL != "" {
+		return cfg.
+			WithEnvVar("MIGRATION_PLANNER_AUTH", "rhsso").
+			WithEnvVar("MIGRATION_PLANNER_JWK_URL", jwksURL)
+	}
+	return cfg.WithEnvVar("MIGRATION_PLANNER_AUTH", "none")
+}
+
+func vcsimContainerConfig(suite Suite, model VcsimModel) *ContainerConfig {
+	return NewContainerConfig(suite.Name(vcsimContainerName), vcsimImage).
+		WithPort(suite.Vcsim, suite.Vcsim).
+		WithCmd(append([]string{
+			"-l", Addr(suite.Vcsim),
+			"-username", VcsimUsername,
+			"-password", VcsimPassword,
+		}, model.args()...)...)
+}
+
+func agentContainerConfig(suite Suite, image string, cfg AgentConfig) *ContainerConfig {
 	updateInterval := cfg.UpdateInterval
 	if updateInterval == "" {
 		updateInterval = "1s"
 	}
 
-	containerCfg := NewContainerConfig(agentContainerName, c.agentImage).
-		WithPort(8000, 8000).
-		WithVolume(agentVolumeName, "/var/lib/agent").
+	containerCfg := NewContainerConfig(suite.Name(agentContainerName), image).
+		WithPort(suite.Agent, suite.Agent).
+		WithVolume(suite.Name(agentVolumeName), agentDataFolder).
 		WithEnvVar("AGENT_SERVER_MODE", "prod").
+		WithEnvVar("AGENT_SERVER_HTTP_PORT", strconv.Itoa(suite.Agent)).
 		WithEnvVar("AGENT_SERVER_STATICS_FOLDER", "/app/static").
 		WithEnvVar("AGENT_OPA_POLICIES_FOLDER", "/app/policies").
 		WithEnvVar("AGENT_MODE", cfg.Mode).
 		WithEnvVar("AGENT_AGENT_ID", cfg.AgentID).
 		WithEnvVar("AGENT_SOURCE_ID", cfg.SourceID).
-		WithEnvVar("AGENT_DATA_FOLDER", "/var/lib/agent").
+		WithEnvVar("AGENT_DATA_FOLDER", agentDataFolder).
 		WithEnvVar("AGENT_CONSOLE_URL", cfg.ConsoleURL).
 		WithEnvVar("AGENT_CONSOLE_UPDATE_INTERVAL", updateInterval)
 
-	return c.runner.StartContainer(containerCfg)
-}
-
-func (c *ContainerInfraManager) StopAgent() error {
-	return c.runner.StopContainer(agentContainerName)
-}
-
-func (c *ContainerInfraManager) RestartAgent() error {
-	return c.runner.RestartContainer(agentContainerName)
-}
-
-func (c *ContainerInfraManager) RemoveAgent() error {
-	_ = c.runner.StopContainer(agentContainerName)
-	_ = c.runner.RemoveContainer(agentContainerName)
-	return c.runner.RemoveVolume(agentVolumeName)
+	if len(cfg.Features) > 0 {
+		features := make([]string, 0, len(cfg.Features))
+		for _, f := range cfg.Features {
+			features = append(features, f+"=true")
+		}
+		containerCfg.WithEnvVar("AMA_FEATURES", strings.Join(features, ","))
+	}
+	return containerCfg
 }
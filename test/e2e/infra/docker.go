@@ -0,0 +1,147 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DockerRunner runs the e2e containers with the Docker engine, for
+// contributors on Docker Desktop. Docker Desktop must have host networking
+// enabled, the containers reaching each other on localhost like with Podman.
+type DockerRunner struct {
+	client *client.Client
+}
+
+// NewDockerRunner connects to the Docker engine at host, e.g.
+// unix:///var/run/docker.sock, or at DOCKER_HOST when host is empty.
+func NewDockerRunner(host string) (*DockerRunner, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to docker: %w", err)
+	}
+	return &DockerRunner{client: cli}, nil
+}
+
+func (d *DockerRunner) StartContainer(cfg *ContainerConfig) (string, error) {
+	ctx := context.Background()
+
+	// unlike Podman, Docker does not pull the missing images on create
+	if err := d.pullImage(ctx, cfg.image); err != nil {
+		return "", err
+	}
+
+	env := make([]string, 0, len(cfg.envVars))
+	for k, v := range cfg.envVars {
+		env = append(env, k+"="+v)
+	}
+
+	mounts := make([]mount.Mount, 0, len(cfg.volumes)+len(cfg.bindMounts))
+	for volumeName, containerPath := range cfg.volumes {
+		mounts = append(mounts, mount.Mount{Type: mount.TypeVolume, Source: volumeName, Target: containerPath})
+	}
+	for hostPath, containerPath := range cfg.bindMounts {
+		mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: hostPath, Target: containerPath, ReadOnly: true})
+	}
+
+	createResponse, err := d.client.ContainerCreate(ctx,
+		&container.Config{
+			Image: cfg.image,
+			Cmd:   cfg.cmd,
+			Env:   env,
+		},
+		// the ports are those of the host network, Docker discards the
+		// published ones with it
+		&container.HostConfig{
+			NetworkMode: container.NetworkMode(network.NetworkHost),
+			Mounts:      mounts,
+		},
+		nil, nil, cfg.name,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	if err := d.client.ContainerStart(ctx, createResponse.ID, container.StartOptions{}); err != nil {
+		return "", fmt.Errorf("failed to start container: %w", err)
+	}
+
+	return createResponse.ID, nil
+}
+
+func (d *DockerRunner) pullImage(ctx context.Context, ref string) error {
+	if _, err := d.client.ImageInspect(ctx, ref); err == nil {
+		return nil
+	} else if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect image: %w", err)
+	}
+
+	progress, err := d.client.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer progress.Close()
+	// the pull completes once its progress is read
+	if _, err := io.Copy(io.Discard, progress); err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	return nil
+}
+
+func (d *DockerRunner) StopContainer(id string) error {
+	if err := d.client.ContainerStop(context.Background(), id, container.StopOptions{}); err != nil {
+		return fmt.Errorf("failed to stop container: %w", err)
+	}
+	return nil
+}
+
+func (d *DockerRunner) RestartContainer(id string) error {
+	if err := d.client.ContainerRestart(context.Background(), id, container.StopOptions{}); err != nil {
+		return fmt.Errorf("failed to restart container: %w", err)
+	}
+	return nil
+}
+
+func (d *DockerRunner) RemoveContainer(id string) error {
+	if err := d.client.ContainerRemove(context.Background(), id, container.RemoveOptions{}); err != nil {
+		return fmt.Errorf("failed to remove container: %w", err)
+	}
+	return nil
+}
+
+// Logs returns the stdout and stderr of the container, interleaved.
+func (d *DockerRunner) FollowLogs(ctx context.Context, id string, since time.Time, w io.Writer) error {
+	opts := container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true}
+	if !since.IsZero() {
+		opts.Since = since.Format(time.RFC3339Nano)
+	}
+	rc, err := d.client.ContainerLogs(ctx, id, opts)
+	if err != nil {
+		return fmt.Errorf("failed to follow logs: %w", err)
+	}
+	defer rc.Close()
+
+	if _, err := stdcopy.StdCopy(w, w, rc); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	return nil
+}
+
+func (d *DockerRunner) RemoveVolume(name string) error {
+	if err := d.client.VolumeRemove(context.Background(), name, false); err != nil {
+		return fmt.Errorf("failed to remove volume: %w", err)
+	}
+	return nil
+}
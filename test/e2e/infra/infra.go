@@ -1,22 +1,32 @@
 package infra
 
+import "time"
+
 // InfraManager abstracts infrastructure lifecycle for e2e tests.
-// Container-based: starts/stops containers via Podman.
-// VM-based: no-op, infrastructure is managed externally (kind + deploy/e2e.mk).
+// Container-based: starts/stops containers via Podman or Docker.
+// Kind-based: deploys the same containers to a Kubernetes cluster.
+// VM-based: no-op, infrastructure is managed externally.
 type InfraManager interface {
 	StartOIDC(addr string) error
 	StopOIDC() error
 	GenerateToken(username, orgID, email string) (string, error)
+	// OIDC returns the OIDC server, to mint tokens with other claims or rotate
+	// its keys, nil before StartOIDC.
+	OIDC() *OIDCServer
 	StartPostgres() error
 	StopPostgres() error
 	StartBackend() error
 	StopBackend() error
-	StartVcsim() error
+	StartVcsim(model VcsimModel) error
 	StopVcsim() error
 	StartAgent(cfg AgentConfig) (string, error)
 	StopAgent() error
 	RestartAgent() error
 	RemoveAgent() error
+	// AgentLogs returns the lines the agent logged since since, followed from
+	// its start across its restarts, empty when they are not reachable, e.g.
+	// for an externally managed agent.
+	AgentLogs(since time.Time) string
 }
 
 // AgentConfig holds configuration for starting an agent instance.
@@ -25,8 +35,9 @@ type AgentConfig struct {
 	SourceID       string
 	Mode           string // "connected" or "disconnected"
 	ConsoleURL     string
-	UpdateInterval string // e.g. "1s"
-	ISOPath        string // Path to the bootable ISO on disk (VM mode: booted via libvirt)
+	UpdateInterval string   // e.g. "1s"
+	ISOPath        string   // Path to the bootable ISO on disk (VM mode: booted via libvirt)
+	Features       []string // Experimental features to enable, e.g. "inspector"
 }
 
 const (
@@ -0,0 +1,543 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	kindNamespace    = "assisted-migration-e2e"
+	kindAppLabel     = "app"
+	kindReadyTimeout = 3 * time.Minute
+	kindPollInterval = time.Second
+
+	// forwarderImage runs the sidecar of the agent forwarding its localhost
+	// ports, see KindInfraManager.
+	forwarderImage         = "docker.io/alpine/socat:latest"
+	forwarderContainerName = "localhost-forwarder"
+	agentVolumeSize        = "1Gi"
+)
+
+// KindInfraManager implements InfraManager on a Kubernetes cluster, typically
+// created with Kind. Postgres, the backend, vcsim and the agent run as
+// Deployments with a Service in a namespace of the suite, from the same
+// containers as ContainerInfraManager.
+//
+// The tests keep reaching them on localhost: the ports of each Deployment are
+// port-forwarded from the host, the forwards of the agent being re-established
+// when it restarts. The other way round, the agent expects vcsim and the
+// in-process proxies on its localhost, so a sidecar forwards the vcsim port to
+// the vcsim Service and the proxy ports to hostAddress, the address of the
+// host as seen from the pods.
+type KindInfraManager struct {
+	client       kubernetes.Interface
+	restConfig   *rest.Config
+	namespace    string
+	suite        Suite
+	backendImage string
+	agentImage   string
+	hostAddress  string
+	oidc         *OIDCServer
+	agentLogs    agentLogs
+
+	mu sync.Mutex
+	// forwards stops the port-forwards by Deployment name
+	forwards map[string]chan struct{}
+}
+
+// NewKindInfraManager creates a KindInfraManager deploying the suite to the
+// cluster of kubeconfig and kubeContext, the defaults of kubectl when empty,
+// and creates the namespace of the suite.
+func NewKindInfraManager(kubeconfig, kubeContext string, suite Suite, backendImage, agentImage, hostAddress string) (*KindInfraManager, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	k := &KindInfraManager{
+		client:       client,
+		restConfig:   restConfig,
+		namespace:    suite.Name(kindNamespace),
+		suite:        suite,
+		backendImage: backendImage,
+		agentImage:   agentImage,
+		hostAddress:  hostAddress,
+		forwards:     make(map[string]chan struct{}),
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: k.namespace}}
+	if _, err := client.CoreV1().Namespaces().Create(context.Background(), ns, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("failed to create namespace %s: %w", k.namespace, err)
+	}
+	return k, nil
+}
+
+func (k *KindInfraManager) StartOIDC(addr string) error {
+	oidc, err := NewOIDCServer(addr)
+	if err != nil {
+		return err
+	}
+	k.oidc = oidc
+	return nil
+}
+
+func (k *KindInfraManager) StopOIDC() error {
+	if k.oidc != nil {
+		return k.oidc.Stop()
+	}
+	return nil
+}
+
+func (k *KindInfraManager) GenerateToken(username, orgID, email string) (string, error) {
+	if k.oidc == nil {
+		return "", fmt.Errorf("OIDC server not started")
+	}
+	return k.oidc.GenerateToken(username, orgID, email)
+}
+
+func (k *KindInfraManager) OIDC() *OIDCServer {
+	return k.oidc
+}
+
+func (k *KindInfraManager) StartPostgres() error {
+	_, err := k.deploy(postgresContainerConfig(k.suite), nil)
+	return err
+}
+
+func (k *KindInfraManager) StopPostgres() error {
+	return k.remove(k.suite.Name(dbContainerName))
+}
+
+func (k *KindInfraManager) StartBackend() error {
+	jwksURL := ""
+	if k.oidc != nil {
+		// the backend reaches the in-process OIDC server on the host
+		u, err := url.Parse(k.oidc.JWKSURL())
+		if err != nil {
+			return fmt.Errorf("failed to parse JWKS url: %w", err)
+		}
+		u.Host = net.JoinHostPort(k.hostAddress, u.Port())
+		jwksURL = u.String()
+	}
+
+	cfg := backendContainerConfig(k.suite, k.backendImage, k.suite.Name(dbContainerName), jwksURL)
+	_, err := k.deploy(cfg, nil)
+	return err
+}
+
+func (k *KindInfraManager) StopBackend() error {
+	return k.remove(k.suite.Name(backendContainerName))
+}
+
+func (k *KindInfraManager) StartVcsim(model VcsimModel) error {
+	if model.RecordingDir != "" {
+		return fmt.Errorf("recorded vcsim inventories are not supported on kind")
+	}
+	_, err := k.deploy(vcsimContainerConfig(k.suite, model), nil)
+	return err
+}
+
+func (k *KindInfraManager) StopVcsim() error {
+	return k.remove(k.suite.Name(vcsimContainerName))
+}
+
+func (k *KindInfraManager) StartAgent(cfg AgentConfig) (string, error) {
+	pod, err := k.deploy(agentContainerConfig(k.suite, k.agentImage, cfg), k.forwarder())
+	if err != nil {
+		return "", err
+	}
+	k.followAgentLogs(pod)
+	return pod, nil
+}
+
+// StopAgent scales the agent down, keeping its volume.
+func (k *KindInfraManager) StopAgent() error {
+	name := k.suite.Name(agentContainerName)
+	k.stopForward(name)
+	k.agentLogs.stop()
+
+	scale, err := k.client.AppsV1().Deployments(k.namespace).GetScale(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the scale of %s: %w", name, err)
+	}
+	scale.Spec.Replicas = 0
+	if _, err := k.client.AppsV1().Deployments(k.namespace).UpdateScale(context.Background(), name, scale, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to scale down %s: %w", name, err)
+	}
+	return k.waitGone(name)
+}
+
+// RestartAgent deletes the pod of the agent, its Deployment starting a new
+// one on the same volume.
+func (k *KindInfraManager) RestartAgent() error {
+	name := k.suite.Name(agentContainerName)
+	k.stopForward(name)
+	k.agentLogs.stop()
+
+	old, err := k.readyPod(name, "")
+	if err != nil {
+		return err
+	}
+	if err := k.client.CoreV1().Pods(k.namespace).Delete(context.Background(), old, metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete pod %s: %w", old, err)
+	}
+
+	pod, err := k.readyPod(name, old)
+	if err != nil {
+		return err
+	}
+	k.followAgentLogs(pod)
+	return k.forward(name, pod, []int{k.suite.Agent})
+}
+
+func (k *KindInfraManager) RemoveAgent() error {
+	k.agentLogs.stop()
+	k.agentLogs.reset()
+	_ = k.remove(k.suite.Name(agentContainerName))
+	err := k.client.CoreV1().PersistentVolumeClaims(k.namespace).Delete(context.Background(), k.suite.Name(agentVolumeName), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the agent volume: %w", err)
+	}
+	return nil
+}
+
+func (k *KindInfraManager) AgentLogs(since time.Time) string {
+	return k.agentLogs.since(since)
+}
+
+// followAgentLogs follows the logs of the agent in pod, which are lost with
+// the pod on a restart.
+func (k *KindInfraManager) followAgentLogs(pod string) {
+	name := k.suite.Name(agentContainerName)
+	k.agentLogs.follow(func(ctx context.Context, w io.Writer) error {
+		rc, err := k.client.CoreV1().Pods(k.namespace).
+			GetLogs(pod, &corev1.PodLogOptions{Container: name, Follow: true}).
+			Stream(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to follow the logs of %s: %w", pod, err)
+		}
+		defer rc.Close()
+		_, err = io.Copy(w, rc)
+		return err
+	})
+}
+
+// forwarder returns the sidecar of the agent listening on the localhost ports
+// it expects vcsim and the in-process proxies on.
+func (k *KindInfraManager) forwarder() *corev1.Container {
+	vcsim := fmt.Sprintf("%s:%d", k.suite.Name(vcsimContainerName), k.suite.Vcsim)
+	targets := map[int]string{k.suite.Vcsim: vcsim}
+	for _, port := range []int{k.suite.AgentProxy, k.suite.ConnectedAgentProxy, k.suite.VcsimProxy} {
+		targets[port] = net.JoinHostPort(k.hostAddress, strconv.Itoa(port))
+	}
+
+	ports := make([]int, 0, len(targets))
+	for port := range targets {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	var script strings.Builder
+	for _, port := range ports {
+		fmt.Fprintf(&script, "socat TCP-LISTEN:%d,fork,reuseaddr TCP:%s &\n", port, targets[port])
+	}
+	script.WriteString("wait\n")
+
+	return &corev1.Container{
+		Name:    forwarderContainerName,
+		Image:   forwarderImage,
+		Command: []string{"/bin/sh", "-c", script.String()},
+	}
+}
+
+// deploy creates the Deployment and the Service of cfg, with an optional
+// sidecar, waits for its pod to be ready and port-forwards its ports. It
+// returns the name of the pod.
+func (k *KindInfraManager) deploy(cfg *ContainerConfig, sidecar *corev1.Container) (string, error) {
+	ctx := context.Background()
+	if len(cfg.bindMounts) > 0 {
+		return "", fmt.Errorf("bind mounts of %s are not supported on kind", cfg.name)
+	}
+
+	labels := map[string]string{kindAppLabel: cfg.name}
+	container := corev1.Container{
+		Name:            cfg.name,
+		Image:           cfg.image,
+		Args:            cfg.cmd,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+	}
+
+	envNames := make([]string, 0, len(cfg.envVars))
+	for name := range cfg.envVars {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: cfg.envVars[name]})
+	}
+
+	// the containers publish their ports unchanged, see Suite
+	ports := make([]int, 0, len(cfg.ports))
+	for _, port := range cfg.ports {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.name, Labels: labels},
+		Spec:       corev1.ServiceSpec{Selector: labels},
+	}
+	for _, port := range ports {
+		container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: int32(port)})
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:       strconv.Itoa(port),
+			Port:       int32(port),
+			TargetPort: intstr.FromInt(port),
+		})
+	}
+	if len(ports) > 0 {
+		container.ReadinessProbe = &corev1.Probe{
+			ProbeHandler:  corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(ports[0])}},
+			PeriodSeconds: 1,
+		}
+	}
+
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{container}}
+	for volume, path := range cfg.volumes {
+		if err := k.ensureVolume(ctx, volume); err != nil {
+			return "", err
+		}
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: volume, MountPath: path})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: volume},
+			},
+		})
+	}
+	if sidecar != nil {
+		podSpec.Containers = append(podSpec.Containers, *sidecar)
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			// a single pod at a time owns the volume
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+
+	if len(ports) > 0 {
+		if _, err := k.client.CoreV1().Services(k.namespace).Create(ctx, service, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create service %s: %w", cfg.name, err)
+		}
+	}
+	if _, err := k.client.AppsV1().Deployments(k.namespace).Create(ctx, deployment, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create deployment %s: %w", cfg.name, err)
+	}
+
+	pod, err := k.readyPod(cfg.name, "")
+	if err != nil {
+		return "", err
+	}
+	if err := k.forward(cfg.name, pod, ports); err != nil {
+		return "", err
+	}
+	return pod, nil
+}
+
+// remove deletes the Deployment and the Service of name and waits for its pod
+// to be gone.
+func (k *KindInfraManager) remove(name string) error {
+	ctx := context.Background()
+	k.stopForward(name)
+
+	err := k.client.AppsV1().Deployments(k.namespace).Delete(ctx, name, metav1.DeleteOptions{
+		PropagationPolicy: ptr.To(metav1.DeletePropagationForeground),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete deployment %s: %w", name, err)
+	}
+	err = k.client.CoreV1().Services(k.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete service %s: %w", name, err)
+	}
+	return k.waitGone(name)
+}
+
+func (k *KindInfraManager) ensureVolume(ctx context.Context, name string) error {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(agentVolumeSize)},
+			},
+		},
+	}
+	_, err := k.client.CoreV1().PersistentVolumeClaims(k.namespace).Create(ctx, pvc, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// pods returns the pods of the Deployment name which are not being deleted.
+func (k *KindInfraManager) pods(name string) ([]corev1.Pod, error) {
+	list, err := k.client.CoreV1().Pods(k.namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: kindAppLabel + "=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of %s: %w", name, err)
+	}
+
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for _, p := range list.Items {
+		if p.DeletionTimestamp == nil {
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
+
+// readyPod waits for a ready pod of the Deployment name other than exclude
+// and returns its name.
+func (k *KindInfraManager) readyPod(name, exclude string) (string, error) {
+	var ready string
+	err := wait.PollUntilContextTimeout(context.Background(), kindPollInterval, kindReadyTimeout, true, func(context.Context) (bool, error) {
+		pods, err := k.pods(name)
+		if err != nil {
+			return false, err
+		}
+		for _, p := range pods {
+			if p.Name != exclude && isPodReady(p) {
+				ready = p.Name
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("timed out waiting for %s to be ready: %w", name, err)
+	}
+	return ready, nil
+}
+
+// waitGone waits for the pods of the Deployment name to be deleted.
+func (k *KindInfraManager) waitGone(name string) error {
+	err := wait.PollUntilContextTimeout(context.Background(), kindPollInterval, kindReadyTimeout, true, func(context.Context) (bool, error) {
+		list, err := k.client.CoreV1().Pods(k.namespace).List(context.Background(), metav1.ListOptions{
+			LabelSelector: kindAppLabel + "=" + name,
+		})
+		if err != nil {
+			return false, err
+		}
+		return len(list.Items) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("timed out waiting for %s to be removed: %w", name, err)
+	}
+	return nil
+}
+
+func isPodReady(p corev1.Pod) bool {
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// forward port-forwards ports of the host to the same ports of pod until
+// stopForward is called or the pod goes away.
+func (k *KindInfraManager) forward(name, pod string, ports []int) error {
+	if len(ports) == 0 {
+		return nil
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(k.restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create port-forward transport: %w", err)
+	}
+	req := k.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(k.namespace).
+		Name(pod).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	specs := make([]string, 0, len(ports))
+	for _, port := range ports {
+		specs = append(specs, fmt.Sprintf("%d:%d", port, port))
+	}
+
+	stop, ready := make(chan struct{}), make(chan struct{})
+	fw, err := portforward.New(dialer, specs, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return fmt.Errorf("failed to port-forward %s: %w", pod, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+	select {
+	case <-ready:
+	case err := <-errCh:
+		return fmt.Errorf("failed to port-forward %s: %w", pod, err)
+	}
+
+	k.mu.Lock()
+	k.forwards[name] = stop
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KindInfraManager) stopForward(name string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if stop, ok := k.forwards[name]; ok {
+		close(stop)
+		delete(k.forwards, name)
+	}
+}
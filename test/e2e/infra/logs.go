@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// agentLogs records the log lines of the agent, with the time they were read,
+// while the infra managers follow them. The recording outlives the restarts
+// and the removal of the agent container, so each spec gets the lines logged
+// during its run, see InfraManager.AgentLogs.
+type agentLogs struct {
+	mu      sync.Mutex
+	lines   []logLine
+	partial []byte
+
+	// cancel stops the current stream, done is closed once it returned
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type logLine struct {
+	at   time.Time
+	text string
+}
+
+// follow stops the current stream and records the lines stream writes until
+// its context is cancelled or the agent stops.
+func (l *agentLogs) follow(stream func(ctx context.Context, w io.Writer) error) {
+	l.stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	l.mu.Lock()
+	l.cancel, l.done = cancel, done
+	l.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		// the stream ends with the agent, there is nothing to report
+		_ = stream(ctx, l)
+	}()
+}
+
+// stop stops the current stream, if any, and waits for it to return.
+func (l *agentLogs) stop() {
+	l.mu.Lock()
+	cancel, done := l.cancel, l.done
+	l.cancel, l.done = nil, nil
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// reset drops the recorded lines.
+func (l *agentLogs) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = nil
+	l.partial = nil
+}
+
+func (l *agentLogs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	data := append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		l.lines = append(l.lines, logLine{at: now, text: string(data[:i+1])})
+		data = data[i+1:]
+	}
+	l.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// since returns the lines read at or after t.
+func (l *agentLogs) since(t time.Time) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b strings.Builder
+	for _, line := range l.lines {
+		if !line.at.Before(t) {
+			b.WriteString(line.text)
+		}
+	}
+	return b.String()
+}
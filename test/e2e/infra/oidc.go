@@ -10,6 +10,8 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -20,11 +22,34 @@ import (
 // OIDCServer is an in-process mock OIDC provider that generates an RSA key pair
 // at startup and serves JWKS and token endpoints. It enables e2e tests to run
 // with the production RHSSOAuthenticator instead of MIGRATION_PLANNER_AUTH=none.
+//
+// The signing key can be rotated and the previous keys revoked during a test,
+// and MintToken signs tokens with any expiry, so the tests can cover expired
+// tokens and tokens of a key the JWKS no longer serves.
 type OIDCServer struct {
-	server     *http.Server
-	privateKey *rsa.PrivateKey
+	server  *http.Server
+	baseURL string
+
+	mu sync.Mutex
+	// keys are the keys served by the JWKS, the last one signing the tokens
+	keys []signingKey
+}
+
+// signingKey is an RSA key of the JWKS with its kid.
+type signingKey struct {
 	kid        string
-	baseURL    string
+	privateKey *rsa.PrivateKey
+}
+
+// TokenOptions are the claims of a token minted by MintToken.
+type TokenOptions struct {
+	Username string
+	OrgID    string
+	Email    string
+	// ExpiresAt defaults to 24 hours from now, a past time mints an expired token
+	ExpiresAt time.Time
+	// NotBefore defaults to now, a future time mints a token not valid yet
+	NotBefore time.Time
 }
 
 // tokenRequest is the JSON body for POST /token.
@@ -32,6 +57,11 @@ type tokenRequest struct {
 	Username string `json:"username"`
 	OrgID    string `json:"org_id"`
 	Email    string `json:"email,omitempty"`
+	// ExpiresIn is the lifetime of the token in seconds, negative for an
+	// expired token, 24 hours when 0
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+	// NotBefore is the nbf claim as a Unix time, now when 0
+	NotBefore int64 `json:"not_before,omitempty"`
 }
 
 // tokenResponse is the JSON response for POST /token.
@@ -62,9 +92,9 @@ type oidcDiscovery struct {
 // NewOIDCServer creates a new mock OIDC server listening on the given address.
 // It generates a 2048-bit RSA key pair and assigns a random kid.
 func NewOIDCServer(addr string) (*OIDCServer, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	key, err := newSigningKey()
 	if err != nil {
-		return nil, fmt.Errorf("generating RSA key: %w", err)
+		return nil, err
 	}
 
 	// Resolve the actual port (useful when addr uses ":0")
@@ -77,9 +107,8 @@ func NewOIDCServer(addr string) (*OIDCServer, error) {
 	baseURL := fmt.Sprintf("http://%s", actualAddr)
 
 	o := &OIDCServer{
-		privateKey: privateKey,
-		kid:        uuid.NewString(),
-		baseURL:    baseURL,
+		baseURL: baseURL,
+		keys:    []signingKey{key},
 	}
 
 	mux := http.NewServeMux()
@@ -119,8 +148,55 @@ func (o *OIDCServer) BaseURL() string {
 	return o.baseURL
 }
 
-// GenerateToken creates a signed RS256 JWT with the given user claims.
+// KeyID returns the kid of the key signing the tokens.
+func (o *OIDCServer) KeyID() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.keys[len(o.keys)-1].kid
+}
+
+// RotateKey generates a new key signing the tokens from now on and returns its
+// kid. The previous keys are still served by the JWKS, so the tokens they
+// signed stay valid until they are revoked with RevokeKey.
+func (o *OIDCServer) RotateKey() (string, error) {
+	key, err := newSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.keys = append(o.keys, key)
+	return key.kid, nil
+}
+
+// RevokeKey removes the key kid from the JWKS, so the tokens it signed are
+// rejected once the JWKS is fetched again. The signing key cannot be revoked,
+// rotate it first.
+func (o *OIDCServer) RevokeKey(kid string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	i := slices.IndexFunc(o.keys, func(k signingKey) bool { return k.kid == kid })
+	switch {
+	case i < 0:
+		return fmt.Errorf("unknown key %q", kid)
+	case i == len(o.keys)-1:
+		return fmt.Errorf("key %q signs the tokens, rotate it before revoking it", kid)
+	}
+	o.keys = slices.Delete(o.keys, i, i+1)
+	return nil
+}
+
+// GenerateToken creates a signed RS256 JWT with the given user claims, valid
+// for 24 hours.
 func (o *OIDCServer) GenerateToken(username, orgID, email string) (string, error) {
+	return o.MintToken(TokenOptions{Username: username, OrgID: orgID, Email: email})
+}
+
+// MintToken creates a RS256 JWT with the claims of opts, signed by the current
+// key.
+func (o *OIDCServer) MintToken(opts TokenOptions) (string, error) {
 	type tokenClaims struct {
 		Username string `json:"username"`
 		OrgID    string `json:"org_id"`
@@ -128,25 +204,39 @@ func (o *OIDCServer) GenerateToken(username, orgID, email string) (string, error
 		jwt.RegisteredClaims
 	}
 
+	now := time.Now()
+	expiresAt := opts.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(24 * time.Hour)
+	}
+	notBefore := opts.NotBefore
+	if notBefore.IsZero() {
+		notBefore = now
+	}
+
 	claims := tokenClaims{
-		Username: username,
-		OrgID:    orgID,
-		Email:    email,
+		Username: opts.Username,
+		OrgID:    opts.OrgID,
+		Email:    opts.Email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(notBefore),
 			Issuer:    o.baseURL,
-			Subject:   username,
+			Subject:   opts.Username,
 			ID:        uuid.NewString(),
 			Audience:  jwt.ClaimStrings{"migration-planner"},
 		},
 	}
 
+	o.mu.Lock()
+	key := o.keys[len(o.keys)-1]
+	o.mu.Unlock()
+
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	token.Header["kid"] = o.kid
+	token.Header["kid"] = key.kid
 
-	signed, err := token.SignedString(o.privateKey)
+	signed, err := token.SignedString(key.privateKey)
 	if err != nil {
 		return "", fmt.Errorf("signing token: %w", err)
 	}
@@ -154,6 +244,14 @@ func (o *OIDCServer) GenerateToken(username, orgID, email string) (string, error
 	return signed, nil
 }
 
+func newSigningKey() (signingKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("generating RSA key: %w", err)
+	}
+	return signingKey{kid: uuid.NewString(), privateKey: privateKey}, nil
+}
+
 // handleDiscovery serves the OIDC discovery document.
 func (o *OIDCServer) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
 	doc := oidcDiscovery{
@@ -164,22 +262,23 @@ func (o *OIDCServer) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
 	json.NewEncoder(w).Encode(doc)
 }
 
-// handleJWKS serves the JSON Web Key Set containing the RSA public key.
+// handleJWKS serves the JSON Web Key Set containing the RSA public keys not
+// revoked.
 func (o *OIDCServer) handleJWKS(w http.ResponseWriter, _ *http.Request) {
-	pub := &o.privateKey.PublicKey
-
-	resp := jwksResponse{
-		Keys: []jwkKey{
-			{
-				Kty: "RSA",
-				Alg: "RS256",
-				Kid: o.kid,
-				Use: "sig",
-				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
-				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
-			},
-		},
+	o.mu.Lock()
+	resp := jwksResponse{Keys: make([]jwkKey, 0, len(o.keys))}
+	for _, k := range o.keys {
+		pub := &k.privateKey.PublicKey
+		resp.Keys = append(resp.Keys, jwkKey{
+			Kty: "RSA",
+			Alg: "RS256",
+			Kid: k.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
 	}
+	o.mu.Unlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
@@ -203,7 +302,15 @@ func (o *OIDCServer) handleToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	signed, err := o.GenerateToken(req.Username, req.OrgID, req.Email)
+	opts := TokenOptions{Username: req.Username, OrgID: req.OrgID, Email: req.Email}
+	if req.ExpiresIn != 0 {
+		opts.ExpiresAt = time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+	}
+	if req.NotBefore != 0 {
+		opts.NotBefore = time.Unix(req.NotBefore, 0)
+	}
+
+	signed, err := o.MintToken(opts)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to generate token: %v", err), http.StatusInternalServerError)
 		return
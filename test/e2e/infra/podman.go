@@ -3,6 +3,7 @@ package infra
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/containers/podman/v5/pkg/bindings"
@@ -196,27 +197,35 @@ func (p *PodmanRunner) WaitForRunning(id string, timeout time.Duration) error {
 	return fmt.Errorf("container %s did not start within %v", id, timeout)
 }
 
-func (p *PodmanRunner) Logs(id string) (string, error) {
-	var stdout, stderr []string
-	stdoutChan := make(chan string)
-	stderrChan := make(chan string)
+// Logs returns the stdout and stderr of the container, interleaved.
+func (p *PodmanRunner) FollowLogs(ctx context.Context, id string, since time.Time, w io.Writer) error {
+	// the bindings read the connection from their context
+	conn, cancel := context.WithCancel(p.conn)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
 
+	// the frames of both streams are sent to lines in the order they are read
+	lines := make(chan string)
+	done := make(chan struct{})
 	go func() {
-		for line := range stdoutChan {
-			stdout = append(stdout, line)
-		}
-	}()
-	go func() {
-		for line := range stderrChan {
-			stderr = append(stderr, line)
+		defer close(done)
+		for line := range lines {
+			_, _ = io.WriteString(w, line)
 		}
 	}()
 
-	opts := &containers.LogOptions{}
-	if err := containers.Logs(p.conn, id, opts, stdoutChan, stderrChan); err != nil {
-		return "", fmt.Errorf("failed to get logs: %w", err)
+	opts := new(containers.LogOptions).WithStdout(true).WithStderr(true).WithFollow(true)
+	if !since.IsZero() {
+		opts = opts.WithSince(since.Format(time.RFC3339Nano))
 	}
-	return fmt.Sprintf("stdout: %v\nstderr: %v", stdout, stderr), nil
+	err := containers.Logs(conn, id, opts, lines, lines)
+	close(lines)
+	<-done
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to follow logs: %w", err)
+	}
+	return nil
 }
 
 func (p *PodmanRunner) CreateNetwork() error {
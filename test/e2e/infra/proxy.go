@@ -3,6 +3,7 @@ package infra
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"io"
 	"net/http"
 	"net/http/httputil"
@@ -25,46 +26,44 @@ type Proxy struct {
 	requests   chan Request
 	proxy      *httputil.ReverseProxy
 	server     *http.Server
+	// certificate, when set, makes the proxy terminate TLS toward its clients
+	certificate *tls.Certificate
 }
 
-func NewObservableProxy(name, targetName string, target *url.URL, port string) (*Proxy, chan Request) {
-	proxy := &httputil.ReverseProxy{
-		Director: func(req *http.Request) {
-			req.URL.Scheme = target.Scheme
-			req.URL.Host = target.Host
-			req.Host = target.Host
-		},
-	}
-
-	p := &Proxy{
-		name:       name,
-		targetName: targetName,
-		requests:   make(chan Request),
-		proxy:      proxy,
-	}
+// ProxyOption configures a Proxy before it starts listening.
+type ProxyOption func(*Proxy)
 
-	p.server = &http.Server{
-		Addr:    port,
-		Handler: p.handler(),
+// WithTLS makes the proxy serve HTTPS with certificate, e.g. one of
+// NewSelfSignedCertificate or of tls.LoadX509KeyPair, while still forwarding
+// to its target with the scheme of the target URL. It lets the tests point the
+// agent at an HTTPS console in front of the plaintext backend or mock console.
+func WithTLS(certificate tls.Certificate) ProxyOption {
+	return func(p *Proxy) {
+		p.certificate = &certificate
 	}
+}
 
-	go func() {
-		zap.S().Infow("starting observable proxy", "name", name, "target", targetName, "port", port)
-		if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			zap.S().Errorw("proxy server error", "name", name, "error", err)
-		}
-	}()
-
+func NewObservableProxy(name, targetName string, target *url.URL, port string, opts ...ProxyOption) (*Proxy, chan Request) {
+	p := newProxy(name, targetName, target, port, opts)
+	p.requests = make(chan Request)
+	p.start("starting observable proxy")
 	return p, p.requests
 }
 
-func NewProxy(name, targetName string, target *url.URL, port string) *Proxy {
+func NewProxy(name, targetName string, target *url.URL, port string, opts ...ProxyOption) *Proxy {
+	p := newProxy(name, targetName, target, port, opts)
+	p.start("starting proxy")
+	return p
+}
+
+func newProxy(name, targetName string, target *url.URL, port string, opts []ProxyOption) *Proxy {
 	proxy := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			req.URL.Scheme = target.Scheme
 			req.URL.Host = target.Host
 			req.Host = target.Host
 		},
+		Transport: targetTransport(target),
 	}
 
 	p := &Proxy{
@@ -72,20 +71,52 @@ func NewProxy(name, targetName string, target *url.URL, port string) *Proxy {
 		targetName: targetName,
 		proxy:      proxy,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
 
 	p.server = &http.Server{
 		Addr:    port,
 		Handler: p.handler(),
 	}
+	if p.certificate != nil {
+		p.server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{*p.certificate}}
+	}
+	return p
+}
 
+func (p *Proxy) start(msg string) {
 	go func() {
-		zap.S().Infow("starting proxy", "name", name, "target", targetName, "port", port)
-		if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			zap.S().Errorw("proxy server error", "name", name, "error", err)
+		zap.S().Infow(msg, "name", p.name, "target", p.targetName, "port", p.server.Addr, "tls", p.certificate != nil)
+		var err error
+		if p.certificate != nil {
+			err = p.server.ListenAndServeTLS("", "")
+		} else {
+			err = p.server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			zap.S().Errorw("proxy server error", "name", p.name, "error", err)
 		}
 	}()
+}
 
-	return p
+// Scheme returns the scheme the proxy serves, https when it terminates TLS.
+func (p *Proxy) Scheme() string {
+	if p.certificate != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// targetTransport skips the verification of the HTTPS targets, vcsim serving a
+// self-signed certificate.
+func targetTransport(target *url.URL) http.RoundTripper {
+	if target.Scheme != "https" {
+		return http.DefaultTransport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return transport
 }
 
 func (p *Proxy) handler() http.Handler {
@@ -0,0 +1,88 @@
+package infra
+
+import "fmt"
+
+// suitePortSpacing separates the ports of the suites of two Ginkgo processes.
+const suitePortSpacing = 100
+
+// Suite is the share of the host of a Ginkgo process: the ports and the
+// container and volume names of its infrastructure. The ports of process n
+// are those of process 1 shifted by (n-1)*100 and its names are suffixed with
+// -n, so parallel processes run their suites side by side.
+//
+// The containers share the host network, the agent reaching the in-process
+// proxies and OIDC server on localhost, so the suites are kept apart by their
+// ports rather than by container networks.
+type Suite struct {
+	Process int
+
+	Postgres     int
+	BackendUser  int
+	BackendAgent int
+	BackendImage int
+	Vcsim        int
+	Agent        int
+	OIDC         int
+	OIDCProxy    int
+	// AgentProxy sits between the agent and the backend in the disconnected
+	// env, ConnectedAgentProxy in the connected env.
+	AgentProxy          int
+	ConnectedAgentProxy int
+	// VcsimProxy sits between the agent and vcsim in the inspector specs.
+	VcsimProxy int
+	// Console is the mock console behind the AgentProxy in the disconnected env.
+	Console int
+}
+
+// NewSuite returns the Suite of the Ginkgo process, GinkgoParallelProcess().
+// Process 1 keeps the default ports and names of a serial run.
+func NewSuite(process int) Suite {
+	if process < 1 {
+		process = 1
+	}
+	offset := (process - 1) * suitePortSpacing
+	return Suite{
+		Process:             process,
+		Postgres:            5432 + offset,
+		BackendUser:         3443 + offset,
+		BackendAgent:        7443 + offset,
+		BackendImage:        11443 + offset,
+		Vcsim:               8989 + offset,
+		Agent:               8000 + offset,
+		OIDC:                9090 + offset,
+		OIDCProxy:           8082 + offset,
+		AgentProxy:          8080 + offset,
+		ConnectedAgentProxy: 8081 + offset,
+		VcsimProxy:          8083 + offset,
+		Console:             8084 + offset,
+	}
+}
+
+// Name returns the name of a container or volume of the suite.
+func (s Suite) Name(base string) string {
+	if s.Process <= 1 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, s.Process)
+}
+
+// Addr returns the listen address of port on all the interfaces, e.g. ":8080".
+func Addr(port int) string {
+	return fmt.Sprintf(":%d", port)
+}
+
+// LocalURL returns the URL of port on localhost with scheme.
+func LocalURL(scheme string, port int) string {
+	return fmt.Sprintf("%s://localhost:%d", scheme, port)
+}
+
+// VcsimURL returns the SDK URL of the vcsim of the suite.
+func (s Suite) VcsimURL() string {
+	return LocalURL("https", s.Vcsim) + "/sdk"
+}
+
+// VcsimProxyURL returns the SDK URL of vcsim through the VcsimProxy, plain
+// HTTP so the proxy can observe the SOAP calls.
+func (s Suite) VcsimProxyURL() string {
+	return LocalURL("http", s.VcsimProxy) + "/sdk"
+}
@@ -0,0 +1,63 @@
+package infra
+
+import "strconv"
+
+// vcsimRecordingPath is where a recorded inventory is mounted in the vcsim container.
+const vcsimRecordingPath = "/recording"
+
+// VcsimModel is the inventory served by vcsim: either generated from its
+// counts, or loaded from RecordingDir, a directory saved with
+// "govc object.save" from a real vCenter or another vcsim.
+type VcsimModel struct {
+	Datacenters int
+	// Clusters and StandaloneHosts are per datacenter, Hosts per cluster.
+	Clusters        int
+	Hosts           int
+	StandaloneHosts int
+	// VMs are per cluster and per standalone host.
+	VMs        int
+	Datastores int
+
+	RecordingDir string
+}
+
+// DefaultVcsimModel is the small inventory of most specs: 6 VMs on 1 cluster
+// and 1 standalone host.
+func DefaultVcsimModel() VcsimModel {
+	return VcsimModel{
+		Datacenters:     1,
+		Clusters:        1,
+		Hosts:           1,
+		StandaloneHosts: 1,
+		VMs:             3,
+		Datastores:      1,
+	}
+}
+
+// RecordedVcsimModel loads the inventory recorded in dir.
+func RecordedVcsimModel(dir string) VcsimModel {
+	return VcsimModel{RecordingDir: dir}
+}
+
+// VMCount is the number of VMs of a generated model, 0 for a recorded one.
+func (m VcsimModel) VMCount() int {
+	if m.RecordingDir != "" {
+		return 0
+	}
+	return m.Datacenters * (m.Clusters + m.StandaloneHosts) * m.VMs
+}
+
+// args are the vcsim flags serving the model.
+func (m VcsimModel) args() []string {
+	if m.RecordingDir != "" {
+		return []string{"-load", vcsimRecordingPath}
+	}
+	return []string{
+		"-dc", strconv.Itoa(m.Datacenters),
+		"-cluster", strconv.Itoa(m.Clusters),
+		"-host", strconv.Itoa(m.Hosts),
+		"-standalone-host", strconv.Itoa(m.StandaloneHosts),
+		"-vm", strconv.Itoa(m.VMs),
+		"-ds", strconv.Itoa(m.Datastores),
+	}
+}
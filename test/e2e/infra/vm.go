@@ -1,6 +1,9 @@
 package infra
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // VMInfraManager implements InfraManager for VM-based deployments.
 // Infrastructure (Postgres, backend, vcsim, agent) is managed externally
@@ -38,15 +41,21 @@ func (v *VMInfraManager) GenerateToken(username, orgID, email string) (string, e
 	return v.oidc.GenerateToken(username, orgID, email)
 }
 
-func (v *VMInfraManager) StartPostgres() error { return nil }
-func (v *VMInfraManager) StopPostgres() error  { return nil }
-func (v *VMInfraManager) StartBackend() error  { return nil }
-func (v *VMInfraManager) StopBackend() error   { return nil }
-func (v *VMInfraManager) StartVcsim() error    { return nil }
-func (v *VMInfraManager) StopVcsim() error     { return nil }
-func (v *VMInfraManager) StopAgent() error     { return nil }
-func (v *VMInfraManager) RestartAgent() error  { return nil }
-func (v *VMInfraManager) RemoveAgent() error   { return nil }
+func (v *VMInfraManager) OIDC() *OIDCServer {
+	return v.oidc
+}
+
+func (v *VMInfraManager) StartPostgres() error        { return nil }
+func (v *VMInfraManager) StopPostgres() error         { return nil }
+func (v *VMInfraManager) StartBackend() error         { return nil }
+func (v *VMInfraManager) StopBackend() error          { return nil }
+func (v *VMInfraManager) StartVcsim(VcsimModel) error { return nil }
+func (v *VMInfraManager) StopVcsim() error            { return nil }
+func (v *VMInfraManager) StopAgent() error            { return nil }
+func (v *VMInfraManager) RestartAgent() error         { return nil }
+func (v *VMInfraManager) RemoveAgent() error          { return nil }
+
+func (v *VMInfraManager) AgentLogs(time.Time) string { return "" }
 
 func (v *VMInfraManager) StartAgent(_ AgentConfig) (string, error) {
 	return "", nil
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/kubev2v/assisted-migration-agent/test/e2e/infra"
@@ -23,22 +24,35 @@ type configuration struct {
 	AgentProxyUrl        string
 	AgentAPIUrl          string
 	AgentImage           string
+	ContainerRuntime     string // "podman" or "docker"
 	PodmanSocket         string
+	DockerHost           string
 	KeepContainers       bool
 	IsoPath              string
-	InfraMode            string // "container" or "vm"
+	VcsimRecording       string
+	VcenterURL           string
+	VcenterUser          string
+	VcenterPassword      string
+	VcenterInspectVMs    []string
+	ArtifactsDir         string
+	Kubeconfig           string
+	KubeContext          string
+	KindHostAddress      string
+	InfraMode            string // "container", "kind" or "vm"
 }
 
 var (
 	cfg          configuration
 	infraManager infra.InfraManager
+	// suite are the ports and names of the infrastructure of this Ginkgo process
+	suite infra.Suite
 )
 
 func (c configuration) Validate() error {
-	if c.InfraMode != "container" && c.InfraMode != "vm" {
-		return fmt.Errorf("invalid infra-mode %q: must be 'container' or 'vm'", c.InfraMode)
+	if c.InfraMode != "container" && c.InfraMode != "kind" && c.InfraMode != "vm" {
+		return fmt.Errorf("invalid infra-mode %q: must be 'container', 'kind' or 'vm'", c.InfraMode)
 	}
-	if c.InfraMode == "container" {
+	if c.InfraMode == "container" || c.InfraMode == "kind" {
 		if c.BackendImage == "" {
 			return errors.New("backend container image is empty")
 		}
@@ -46,6 +60,27 @@ func (c configuration) Validate() error {
 			return errors.New("agent container image is empty")
 		}
 	}
+	if c.InfraMode == "container" {
+		if c.ContainerRuntime != infra.RuntimePodman && c.ContainerRuntime != infra.RuntimeDocker {
+			return fmt.Errorf("invalid container-runtime %q: must be 'podman' or 'docker'", c.ContainerRuntime)
+		}
+	}
+	if c.InfraMode == "kind" {
+		if c.KindHostAddress == "" {
+			return errors.New("kind host address is empty")
+		}
+		if c.VcsimRecording != "" {
+			return errors.New("vcsim recordings are not supported in kind mode")
+		}
+	}
+	if c.VcenterURL != "" {
+		if _, err := url.Parse(c.VcenterURL); err != nil {
+			return fmt.Errorf("failed to parse vcenter url: %v", err)
+		}
+		if c.VcenterUser == "" || c.VcenterPassword == "" {
+			return errors.New("vcenter credentials are empty")
+		}
+	}
 	if _, err := url.Parse(c.BackendAgentEndpoint); err != nil {
 		return fmt.Errorf("failed to parse agent endpoint: %v", err)
 	}
@@ -55,19 +90,58 @@ func (c configuration) Validate() error {
 	return nil
 }
 
+// setSuiteDefaults points the endpoints left unset at the ports of suite.
+func (c *configuration) setSuiteDefaults(suite infra.Suite) {
+	defaults := []struct {
+		value *string
+		url   string
+	}{
+		{&c.BackendAgentEndpoint, infra.LocalURL("http", suite.BackendAgent)},
+		{&c.BackendUserEndpoint, infra.LocalURL("http", suite.BackendUser)},
+		{&c.AgentProxyUrl, infra.LocalURL("http", suite.AgentProxy)},
+		{&c.AgentAPIUrl, infra.LocalURL("https", suite.Agent)},
+	}
+	for _, d := range defaults {
+		if *d.value == "" {
+			*d.value = d.url
+		}
+	}
+}
+
 func main() {
-	flag.StringVar(&cfg.InfraMode, "infra-mode", "container", "Infrastructure mode: 'container' (Podman) or 'vm' (externally managed)")
+	flag.StringVar(&cfg.InfraMode, "infra-mode", "container", "Infrastructure mode: 'container' (Podman or Docker), 'kind' (Kubernetes deployments) or 'vm' (externally managed)")
+	flag.StringVar(&cfg.ContainerRuntime, "container-runtime", infra.RuntimePodman, "Container runtime of the container mode: 'podman' or 'docker'")
 	flag.StringVar(&cfg.AgentImage, "agent-image", "", "Agent container image")
 	flag.StringVar(&cfg.BackendImage, "backend-image", "", "Backend container image")
-	flag.StringVar(&cfg.BackendAgentEndpoint, "backend-agent-endpoint", "http://localhost:7443", "Agent endpoint on backend (port 7443)")
-	flag.StringVar(&cfg.BackendUserEndpoint, "backend-user-endpoint", "http://localhost:3443", "User endpoint on backend (port 3443)")
-	flag.StringVar(&cfg.AgentProxyUrl, "agent-proxy-url", "http://localhost:8080", "Agent proxy url")
-	flag.StringVar(&cfg.AgentAPIUrl, "agent-api-url", "https://localhost:8000", "Agent local API url")
+	flag.StringVar(&cfg.BackendAgentEndpoint, "backend-agent-endpoint", "", "Agent endpoint on backend. Defaults to http://localhost:7443, shifted by 100 per parallel process")
+	flag.StringVar(&cfg.BackendUserEndpoint, "backend-user-endpoint", "", "User endpoint on backend. Defaults to http://localhost:3443, shifted by 100 per parallel process")
+	flag.StringVar(&cfg.AgentProxyUrl, "agent-proxy-url", "", "Agent proxy url. Defaults to http://localhost:8080, shifted by 100 per parallel process")
+	flag.StringVar(&cfg.AgentAPIUrl, "agent-api-url", "", "Agent local API url. Defaults to https://localhost:8000, shifted by 100 per parallel process")
 	flag.StringVar(&cfg.PodmanSocket, "podman-socket", "unix:///run/user/1000/podman/podman.sock", "Podman socket path")
+	flag.StringVar(&cfg.DockerHost, "docker-host", "", "Docker engine socket, e.g. unix:///var/run/docker.sock. Defaults to DOCKER_HOST or the default socket")
+	flag.StringVar(&cfg.Kubeconfig, "kubeconfig", "", "Kubeconfig of the cluster of the kind mode. Defaults to KUBECONFIG or ~/.kube/config")
+	flag.StringVar(&cfg.KubeContext, "kube-context", "", "Context of the kubeconfig of the kind mode, e.g. kind-kind. Defaults to the current context")
+	flag.StringVar(&cfg.KindHostAddress, "kind-host-address", "", "Address of the host as seen from the pods of the kind mode, e.g. the gateway of the kind network, on which the agent reaches the test proxies")
 	flag.StringVar(&cfg.IsoPath, "iso-path", "", "Path to directory containing rhcos-live-iso.x86_64.iso")
+	flag.StringVar(&cfg.VcsimRecording, "vcsim-recording", "", "Directory of an inventory recorded with 'govc object.save', served by vcsim in the large inventory specs instead of the generated one")
+	flag.StringVar(&cfg.VcenterURL, "vcenter-url", "", "SDK URL of a real vCenter, e.g. https://vcenter.example.com/sdk, the \"vcenter\" labelled specs run against. They are skipped when empty")
+	flag.StringVar(&cfg.VcenterUser, "vcenter-user", "", "Username of the -vcenter-url vCenter")
+	flag.StringVar(&cfg.VcenterPassword, "vcenter-password", "", "Password of the -vcenter-url vCenter")
+	flag.Func("vcenter-inspect-vms", "Comma-separated ids of the -vcenter-url VMs to inspect, snapshotting them. The inspection spec is skipped when empty", func(v string) error {
+		if v != "" {
+			cfg.VcenterInspectVMs = strings.Split(v, ",")
+		}
+		return nil
+	})
+	flag.StringVar(&cfg.ArtifactsDir, "artifacts-dir", "", "Directory the JUnit (junit.xml) and HTML (report.html) reports are written to, with the agent logs of each spec. Disabled when empty")
 	flag.BoolVar(&cfg.KeepContainers, "keep-containers", false, "Keep containers running after test completion (useful for debugging)")
+	// accept the go test flags the ginkgo CLI passes to a precompiled suite
+	testing.Init()
 	flag.Parse()
 
+	suite = infra.NewSuite(GinkgoParallelProcess())
+	cfg.setSuiteDefaults(suite)
+
 	logger, err := zap.NewDevelopment()
 	if err != nil {
 		log.Fatalf("failed to initialize logger: %v", err)
@@ -81,11 +155,20 @@ func main() {
 
 	switch cfg.InfraMode {
 	case "container":
-		im, err := infra.NewContainerInfraManager(cfg.PodmanSocket, cfg.BackendImage, cfg.AgentImage, cfg.IsoPath)
+		socket := cfg.PodmanSocket
+		if cfg.ContainerRuntime == infra.RuntimeDocker {
+			socket = cfg.DockerHost
+		}
+		runner, err := infra.NewContainerRunner(cfg.ContainerRuntime, socket)
 		if err != nil {
 			log.Fatalf("failed to create container infra manager: %v", err)
 		}
-		infraManager = im
+		infraManager = infra.NewContainerInfraManager(runner, suite, cfg.BackendImage, cfg.AgentImage, cfg.IsoPath)
+	case "kind":
+		infraManager, err = infra.NewKindInfraManager(cfg.Kubeconfig, cfg.KubeContext, suite, cfg.BackendImage, cfg.AgentImage, cfg.KindHostAddress)
+		if err != nil {
+			log.Fatalf("failed to create kind infra manager: %v", err)
+		}
 	case "vm":
 		infraManager = infra.NewVMInfraManager()
 	}
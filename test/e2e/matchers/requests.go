@@ -0,0 +1,301 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+
+	"github.com/kubev2v/assisted-migration-agent/test/e2e/infra"
+)
+
+// RequestMatcher matches the requests seen by an infra.Observer, the actual
+// value being the *infra.Observer or a []infra.Request taken from it. By
+// default it succeeds when at least one request matches; Times and AtLeast
+// change the number of matching requests it expects.
+type RequestMatcher struct {
+	method   string // empty for any method
+	pattern  string // path.Match pattern, empty for any path
+	fields   []jsonField
+	contains []string
+	status   int
+
+	count   int
+	atLeast bool
+
+	// matched is the number of matching requests of the last Match
+	matched  int
+	observed []infra.Request
+}
+
+type jsonField struct {
+	name  string
+	value any
+}
+
+// HaveReceivedRequest matches the requests with method and a path matching
+// pattern, where "*" stands for a path segment, e.g.
+// "/api/v1/agents/*/status".
+func HaveReceivedRequest(method, pattern string) *RequestMatcher {
+	return &RequestMatcher{method: method, pattern: pattern, count: 1, atLeast: true}
+}
+
+// HaveReceivedAnyRequest matches every request.
+func HaveReceivedAnyRequest() *RequestMatcher {
+	return HaveReceivedRequest("", "")
+}
+
+func HaveReceivedGET(pattern string) *RequestMatcher {
+	return HaveReceivedRequest(http.MethodGet, pattern)
+}
+
+func HaveReceivedPOST(pattern string) *RequestMatcher {
+	return HaveReceivedRequest(http.MethodPost, pattern)
+}
+
+func HaveReceivedPUT(pattern string) *RequestMatcher {
+	return HaveReceivedRequest(http.MethodPut, pattern)
+}
+
+// WithJSONField keeps the requests whose JSON body has value at field, a
+// dot-separated path of object keys, e.g. "inventory.vcenterId". value is
+// compared with its JSON form, so an int matches the decoded number.
+func (m *RequestMatcher) WithJSONField(field string, value any) *RequestMatcher {
+	m.fields = append(m.fields, jsonField{name: field, value: value})
+	return m
+}
+
+// WithBodyContaining keeps the requests whose body contains substr, e.g. the
+// SOAP method of a vSphere call.
+func (m *RequestMatcher) WithBodyContaining(substr string) *RequestMatcher {
+	m.contains = append(m.contains, substr)
+	return m
+}
+
+// WithStatus keeps the requests the target answered with status.
+func (m *RequestMatcher) WithStatus(status int) *RequestMatcher {
+	m.status = status
+	return m
+}
+
+// Times expects exactly n matching requests, 0 meaning none.
+func (m *RequestMatcher) Times(n int) *RequestMatcher {
+	m.count = n
+	m.atLeast = false
+	return m
+}
+
+// AtLeast expects n matching requests or more.
+func (m *RequestMatcher) AtLeast(n int) *RequestMatcher {
+	m.count = n
+	m.atLeast = true
+	return m
+}
+
+func (m *RequestMatcher) Match(actual any) (bool, error) {
+	reqs, err := requests(actual)
+	if err != nil {
+		return false, err
+	}
+	m.observed = reqs
+
+	m.matched = 0
+	for _, r := range reqs {
+		if m.matches(r) {
+			m.matched++
+		}
+	}
+	if m.atLeast {
+		return m.matched >= m.count, nil
+	}
+	return m.matched == m.count, nil
+}
+
+func (m *RequestMatcher) FailureMessage(any) string {
+	return fmt.Sprintf("Expected %s, got %d\n%s", m.expectation(), m.matched, describe(m.observed))
+}
+
+func (m *RequestMatcher) NegatedFailureMessage(any) string {
+	return fmt.Sprintf("Expected not %s, got %d\n%s", m.expectation(), m.matched, describe(m.observed))
+}
+
+func (m *RequestMatcher) matches(r infra.Request) bool {
+	if m.method != "" && r.Request.Method != m.method {
+		return false
+	}
+	if m.pattern != "" {
+		if ok, _ := path.Match(m.pattern, r.Request.URL.Path); !ok {
+			return false
+		}
+	}
+	if m.status != 0 && (r.Response == nil || r.Response.StatusCode != m.status) {
+		return false
+	}
+	for _, substr := range m.contains {
+		if !bytes.Contains(r.RequestBody, []byte(substr)) {
+			return false
+		}
+	}
+	if len(m.fields) == 0 {
+		return true
+	}
+
+	var body any
+	if err := json.Unmarshal(r.RequestBody, &body); err != nil {
+		return false
+	}
+	for _, f := range m.fields {
+		got, ok := lookup(body, f.name)
+		if !ok || !jsonEqual(got, f.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the requests the matcher keeps, e.g.
+// `PUT /api/v1/agents/*/status with status="collected"`.
+func (m *RequestMatcher) String() string {
+	method, pattern := m.method, m.pattern
+	if method == "" {
+		method = "any method"
+	}
+	if pattern == "" {
+		pattern = "any path"
+	}
+	s := method + " " + pattern
+	for _, f := range m.fields {
+		v, _ := json.Marshal(f.value)
+		s += fmt.Sprintf(" with %s=%s", f.name, v)
+	}
+	for _, substr := range m.contains {
+		s += fmt.Sprintf(" containing %q", substr)
+	}
+	if m.status != 0 {
+		s += fmt.Sprintf(" answered %d", m.status)
+	}
+	return s
+}
+
+func (m *RequestMatcher) expectation() string {
+	if m.atLeast {
+		return fmt.Sprintf("at least %d request(s) %s", m.count, m)
+	}
+	return fmt.Sprintf("exactly %d request(s) %s", m.count, m)
+}
+
+// HaveReceivedInOrder succeeds when the observer has seen a request matching
+// each of matchers, in their order. Only the filters of the matchers are used,
+// their counts are ignored.
+func HaveReceivedInOrder(matchers ...*RequestMatcher) types.GomegaMatcher {
+	return &orderMatcher{matchers: matchers}
+}
+
+type orderMatcher struct {
+	matchers []*RequestMatcher
+
+	// missing is the index of the first matcher without a request after the
+	// previous one
+	missing  int
+	observed []infra.Request
+}
+
+func (o *orderMatcher) Match(actual any) (bool, error) {
+	reqs, err := requests(actual)
+	if err != nil {
+		return false, err
+	}
+	o.observed = reqs
+
+	next := 0
+	for i, m := range o.matchers {
+		found := false
+		for ; next < len(reqs); next++ {
+			if m.matches(reqs[next]) {
+				found = true
+				next++
+				break
+			}
+		}
+		if !found {
+			o.missing = i
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (o *orderMatcher) FailureMessage(any) string {
+	return fmt.Sprintf("Expected the requests in order:\n%s\nmissing %s\n%s",
+		o.sequence(), o.matchers[o.missing], describe(o.observed))
+}
+
+func (o *orderMatcher) NegatedFailureMessage(any) string {
+	return fmt.Sprintf("Expected not the requests in order:\n%s\n%s", o.sequence(), describe(o.observed))
+}
+
+func (o *orderMatcher) sequence() string {
+	lines := make([]string, 0, len(o.matchers))
+	for i, m := range o.matchers {
+		lines = append(lines, fmt.Sprintf("  %d. %s", i+1, m))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func requests(actual any) ([]infra.Request, error) {
+	switch a := actual.(type) {
+	case *infra.Observer:
+		return a.Requests(), nil
+	case []infra.Request:
+		return a, nil
+	default:
+		return nil, fmt.Errorf("request matchers expect an *infra.Observer or a []infra.Request, got %T", actual)
+	}
+}
+
+// describe lists the observed requests for the failure messages.
+func describe(reqs []infra.Request) string {
+	if len(reqs) == 0 {
+		return "no request observed"
+	}
+	lines := []string{fmt.Sprintf("%d request(s) observed:", len(reqs))}
+	for _, r := range reqs {
+		status := "no response"
+		if r.Response != nil {
+			status = fmt.Sprint(r.Response.StatusCode)
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s -> %s", r.Request.Method, r.Request.URL.Path, status))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func lookup(body any, field string) (any, bool) {
+	v := body
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func jsonEqual(got, want any) bool {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, decoded)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/reporters"
+)
+
+// agentLogsEntry is the report entry holding the agent logs of a spec.
+const agentLogsEntry = "agent logs"
+
+// attach the agent logs of the spec to the failed ones, and to every spec for
+// the HTML summary of the artifacts
+var _ = JustAfterEach(func() {
+	spec := CurrentSpecReport()
+	if !spec.Failed() && cfg.ArtifactsDir == "" {
+		return
+	}
+	// the spec starts with the BeforeAll of its container, if it runs first;
+	// no agent is running in the specs that do not start one
+	logs := infraManager.AgentLogs(spec.StartTime)
+	if logs == "" {
+		return
+	}
+	AddReportEntry(agentLogsEntry, logs, ReportEntryVisibilityFailureOrVerbose)
+})
+
+// ReportAfterSuite runs on the first process once all of them are done, with
+// the specs of every process.
+var _ = ReportAfterSuite("e2e reports", func(report Report) {
+	if cfg.ArtifactsDir == "" {
+		return
+	}
+	if err := os.MkdirAll(cfg.ArtifactsDir, 0o755); err != nil {
+		Fail(fmt.Sprintf("failed to create the artifacts directory: %v", err))
+	}
+	if err := reporters.GenerateJUnitReport(report, filepath.Join(cfg.ArtifactsDir, "junit.xml")); err != nil {
+		Fail(fmt.Sprintf("failed to write the JUnit report: %v", err))
+	}
+	if err := writeHTMLReport(report, filepath.Join(cfg.ArtifactsDir, "report.html")); err != nil {
+		Fail(fmt.Sprintf("failed to write the HTML report: %v", err))
+	}
+})
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.SuiteDescription}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.passed { color: #2a7d2a; }
+.failed, .panicked, .timedout, .interrupted, .aborted { color: #b22222; }
+.skipped, .pending { color: #888; }
+pre { max-height: 40em; overflow: auto; background: #f6f6f6; padding: 8px; }
+</style>
+</head>
+<body>
+<h1>{{.SuiteDescription}}</h1>
+<p>{{if .SuiteSucceeded}}Passed{{else}}Failed{{end}} in {{.RunTime}}, started {{.StartTime.Format "2006-01-02 15:04:05 MST"}}</p>
+<table>
+<tr><th>State</th><th>Spec</th><th>Duration</th><th>Failure</th></tr>
+{{range .SpecReports}}{{if .LeafNodeText}}<tr>
+<td class="{{.State}}">{{.State}}</td>
+<td>{{.FullText}}</td>
+<td>{{.RunTime}}</td>
+<td>{{if .Failed}}{{.Failure.Message}}<br>{{.Failure.Location}}{{end}}
+{{range .ReportEntries}}<details><summary>{{.Name}}</summary><pre>{{.StringRepresentation}}</pre></details>
+{{end}}</td>
+</tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
+
+// writeHTMLReport writes a summary of the specs to dst, the report entries of
+// a spec, e.g. the agent logs, being collapsed under it.
+func writeHTMLReport(report Report, dst string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := htmlReport.Execute(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/kubev2v/migration-planner/api/v1alpha1"
 	"go.uber.org/zap"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
 )
 
 // --- Agent API request/response types ---
@@ -34,12 +36,28 @@ type CollectorStatus struct {
 	Error  string `json:"error,omitempty"`
 }
 
+type VMList struct {
+	Page      int         `json:"page"`
+	PageCount int         `json:"pageCount"`
+	Total     int         `json:"total"`
+	Vms       []VMSummary `json:"vms"`
+}
+
+type VMSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 // --- AgentSvc client ---
 
 // AgentSvc provides a client to interact with the Planner Agent API
 type AgentSvc struct {
 	baseURL    string
 	httpClient *http.Client
+	// api is the client generated from the OpenAPI spec, apiErr the error of
+	// its creation, returned by the methods using it
+	api    *v1.ClientWithResponses
+	apiErr error
 }
 
 // DefaultAgentSvc creates an AgentSvc client with a default HTTP client that skips TLS verification
@@ -55,9 +73,12 @@ func DefaultAgentSvc(agentApiBaseUrl string) *AgentSvc {
 
 // NewAgentSvc creates an AgentSvc client with a custom HTTP client
 func NewAgentSvc(agentApiBaseUrl string, customHttpClient *http.Client) *AgentSvc {
+	api, err := v1.NewClientWithResponses(agentApiBaseUrl+"/api/v1", v1.WithHTTPClient(customHttpClient))
 	return &AgentSvc{
 		baseURL:    agentApiBaseUrl,
 		httpClient: customHttpClient,
+		api:        api,
+		apiErr:     err,
 	}
 }
 
@@ -180,6 +201,31 @@ func (a *AgentSvc) GetCollectorStatus() (*CollectorStatus, error) {
 	return &status, nil
 }
 
+// ListVMs retrieves a page of the VMs collected by the agent
+func (a *AgentSvc) ListVMs(page, pageSize int) (*VMList, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/vms?page=%d&pageSize=%d", a.baseURL, page, pageSize), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var list VMList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &list, nil
+}
+
 // Inventory retrieves the inventory data collected by the agent
 func (a *AgentSvc) Inventory() (*v1alpha1.Inventory, error) {
 	req, err := http.NewRequest(http.MethodGet, a.baseURL+"/api/v1/inventory", nil)
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+)
+
+// The methods of this file call the agent API through the client generated
+// from api/v1/openapi.yaml and return its types. Endpoints without a method
+// here can be called through API.
+
+// API returns the client generated from the OpenAPI spec of the agent, for the
+// endpoints and the responses the methods of AgentSvc do not cover.
+func (a *AgentSvc) API() (*v1.ClientWithResponses, error) {
+	return a.api, a.apiErr
+}
+
+// StopCollector stops the running collection
+func (a *AgentSvc) StopCollector() (*v1.CollectorStatus, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.StopCollectorWithResponse(context.Background())
+	// the agent answers 200 with the collector status, the spec 204
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	var status v1.CollectorStatus
+	if err := json.Unmarshal(resp.Body, &status); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &status, nil
+}
+
+// GetVM retrieves the details of a VM collected by the agent
+func (a *AgentSvc) GetVM(vmID string) (*v1.VMDetails, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.GetVMWithResponse(context.Background(), vmID)
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.JSON200, nil
+}
+
+// GetVMEvents retrieves the vCenter events of a VM
+func (a *AgentSvc) GetVMEvents(vmID string) ([]v1.VCenterEvent, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.GetVMEventsWithResponse(context.Background(), vmID)
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return *resp.JSON200, nil
+}
+
+// AddVMsToInspection queues more VMs to the running inspection
+func (a *AgentSvc) AddVMsToInspection(vmIDs []string) (*v1.InspectorStatus, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.AddVMsToInspectionWithResponse(context.Background(), vmIDs)
+	if err := checkResponse(resp, err, http.StatusAccepted); err != nil {
+		return nil, err
+	}
+	return resp.JSON202, nil
+}
+
+// RemoveVMFromInspection removes a VM from the inspection queue
+func (a *AgentSvc) RemoveVMFromInspection(vmID string) (*v1.VmInspectionStatus, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.RemoveVMFromInspectionWithResponse(context.Background(), vmID)
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.JSON200, nil
+}
+
+// StopInspection stops the inspector
+func (a *AgentSvc) StopInspection() (*v1.InspectorStatus, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.StopInspectionWithResponse(context.Background())
+	// the agent answers 202, the spec 200
+	if err := checkResponse(resp, err, http.StatusAccepted); err != nil {
+		return nil, err
+	}
+	var status v1.InspectorStatus
+	if err := json.Unmarshal(resp.Body, &status); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &status, nil
+}
+
+// ClusterRules retrieves the DRS rules of a cluster
+func (a *AgentSvc) ClusterRules(cluster string) ([]v1.ClusterRule, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.GetClusterRulesWithResponse(context.Background(), cluster)
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return *resp.JSON200, nil
+}
+
+// DatastoreStats retrieves the usage of the collected datastores
+func (a *AgentSvc) DatastoreStats() ([]v1.DatastoreStats, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.GetDatastoreStatsWithResponse(context.Background())
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return *resp.JSON200, nil
+}
+
+// maxPageSize is the largest page the list endpoints of the agent serve.
+const maxPageSize = 100
+
+// Hosts retrieves all the hosts of cluster, or of every cluster when cluster
+// is empty, walking the pages of the listing
+func (a *AgentSvc) Hosts(cluster string) ([]v1.Host, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	var hosts []v1.Host
+	pageSize := maxPageSize
+	for page := 1; ; page++ {
+		params := v1.GetHostsParams{Page: &page, PageSize: &pageSize}
+		if cluster != "" {
+			params.Cluster = &cluster
+		}
+		resp, err := a.api.GetHostsWithResponse(context.Background(), &params)
+		if err := checkResponse(resp, err, http.StatusOK); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, resp.JSON200.Hosts...)
+		if page >= resp.JSON200.PageCount {
+			return hosts, nil
+		}
+	}
+}
+
+// Datastores retrieves all the datastores of cluster, or every datastore when
+// cluster is empty, walking the pages of the listing
+func (a *AgentSvc) Datastores(cluster string) ([]v1.Datastore, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	var datastores []v1.Datastore
+	pageSize := maxPageSize
+	for page := 1; ; page++ {
+		params := v1.GetDatastoresParams{Page: &page, PageSize: &pageSize}
+		if cluster != "" {
+			params.Cluster = &cluster
+		}
+		resp, err := a.api.GetDatastoresWithResponse(context.Background(), &params)
+		if err := checkResponse(resp, err, http.StatusOK); err != nil {
+			return nil, err
+		}
+		datastores = append(datastores, resp.JSON200.Datastores...)
+		if page >= resp.JSON200.PageCount {
+			return datastores, nil
+		}
+	}
+}
+
+// Networks retrieves all the distributed switches and port groups, walking
+// the pages of the listing
+func (a *AgentSvc) Networks() ([]v1.Network, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	var networks []v1.Network
+	pageSize := maxPageSize
+	for page := 1; ; page++ {
+		params := v1.GetNetworksParams{Page: &page, PageSize: &pageSize}
+		resp, err := a.api.GetNetworksWithResponse(context.Background(), &params)
+		if err := checkResponse(resp, err, http.StatusOK); err != nil {
+			return nil, err
+		}
+		networks = append(networks, resp.JSON200.Networks...)
+		if page >= resp.JSON200.PageCount {
+			return networks, nil
+		}
+	}
+}
+
+// Version retrieves the version of the agent
+func (a *AgentSvc) Version() (*v1.VersionInfo, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.GetVersionWithResponse(context.Background())
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.JSON200, nil
+}
+
+// ConsoleLogin retrieves the state of the device login to the console
+func (a *AgentSvc) ConsoleLogin() (*v1.ConsoleLogin, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.GetConsoleLoginWithResponse(context.Background())
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.JSON200, nil
+}
+
+// StartConsoleLogin starts a device login to the console
+func (a *AgentSvc) StartConsoleLogin() (*v1.ConsoleLogin, error) {
+	if a.apiErr != nil {
+		return nil, a.apiErr
+	}
+	resp, err := a.api.StartConsoleLoginWithResponse(context.Background())
+	if err := checkResponse(resp, err, http.StatusAccepted); err != nil {
+		return nil, err
+	}
+	return resp.JSON202, nil
+}
+
+// UploadVDDK uploads a VDDK tarball and returns its md5 sum
+func (a *AgentSvc) UploadVDDK(tarball io.Reader) (string, error) {
+	if a.apiErr != nil {
+		return "", a.apiErr
+	}
+	// the agent reads the tarball from the body as is, not from a form
+	resp, err := a.api.PostVddkWithBodyWithResponse(context.Background(), "application/octet-stream", tarball)
+	if err := checkResponse(resp, err, http.StatusOK); err != nil {
+		return "", err
+	}
+	return resp.JSON200.Md5, nil
+}
+
+// checkResponse returns the error of a request of the generated client, or
+// an error when its response does not have the expected status code.
+func checkResponse(resp interface{ StatusCode() int }, err error, expected int) error {
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	if resp.StatusCode() != expected {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	}
+	return nil
+}
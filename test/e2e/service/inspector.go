@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// --- Inspector API request/response types ---
+
+type VcenterCredentials struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type InspectorStartRequest struct {
+	VcenterCredentials VcenterCredentials `json:"VcenterCredentials"`
+	VMIDs              []string           `json:"vmIds"`
+}
+
+type InspectorStatus struct {
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+type VMInspectionStatus struct {
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+type AgentEvent struct {
+	Type    string            `json:"type"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// StartInspection starts the inspection of vmIDs with the vCenter credentials
+func (a *AgentSvc) StartInspection(vmIDs []string, vcenterURL, username, password string) (*InspectorStatus, error) {
+	body := InspectorStartRequest{
+		VcenterCredentials: VcenterCredentials{URL: vcenterURL, Username: username, Password: password},
+		VMIDs:              vmIDs,
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	var status InspectorStatus
+	if err := a.do(http.MethodPost, "/api/v1/vms/inspector", bytes.NewReader(data), http.StatusAccepted, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetInspectorStatus retrieves the state of the inspector
+func (a *AgentSvc) GetInspectorStatus() (*InspectorStatus, error) {
+	var status InspectorStatus
+	if err := a.do(http.MethodGet, "/api/v1/vms/inspector", nil, http.StatusOK, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetVMInspectionStatus retrieves the inspection state of a VM
+func (a *AgentSvc) GetVMInspectionStatus(vmID string) (*VMInspectionStatus, error) {
+	var status VMInspectionStatus
+	if err := a.do(http.MethodGet, "/api/v1/vms/"+url.PathEscape(vmID)+"/inspector", nil, http.StatusOK, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Events retrieves the lifecycle events of the agent of the given types, newest first
+func (a *AgentSvc) Events(types ...string) ([]AgentEvent, error) {
+	query := url.Values{}
+	for _, t := range types {
+		query.Add("type", t)
+	}
+
+	var events []AgentEvent
+	if err := a.do(http.MethodGet, "/api/v1/events?"+query.Encode(), nil, http.StatusOK, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// do sends a request to the agent API and decodes the response into out when
+// it has the expected status code.
+func (a *AgentSvc) do(method, path string, body io.Reader, expected int, out any) error {
+	req, err := http.NewRequest(method, a.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
@@ -10,7 +10,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/kubev2v/assisted-migration-agent/test/e2e/infra"
+	. "github.com/kubev2v/assisted-migration-agent/test/e2e/matchers"
 	"github.com/kubev2v/assisted-migration-agent/test/e2e/service"
+	"github.com/kubev2v/assisted-migration-agent/test/mockconsole"
 
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
@@ -19,36 +21,39 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
-var _ = Describe("Agent e2e tests", Ordered, func() {
-	BeforeAll(func() {
-		GinkgoWriter.Println("Starting postgres...")
-		err := infraManager.StartPostgres()
-		Expect(err).ToNot(HaveOccurred(), "failed to start postgres")
-		time.Sleep(2 * time.Second) // wait for postgres to be ready
-	})
-
-	AfterAll(func() {
-		_ = infraManager.StopPostgres()
-	})
-
-	Context("disconnected env", func() {
+// The envs are ordered containers of their own so parallel Ginkgo processes
+// can run them side by side, each on the ports of its infra.Suite.
+var _ = Describe("Agent e2e tests", func() {
+	Context("disconnected env", Ordered, func() {
 		var (
 			proxy    *infra.Proxy
 			requests chan infra.Request
 			obs      *infra.Observer
+			console  *mockconsole.Server
 		)
 
+		// no backend runs in this env, the agents switched to connected mode
+		// talk to a mock console
 		BeforeAll(func() {
-			target, err := url.Parse(cfg.BackendAgentEndpoint)
-			Expect(err).ToNot(HaveOccurred(), "failed to parse backend endpoint")
+			var err error
+			console, err = mockconsole.NewServerOn(infra.Addr(suite.Console))
+			Expect(err).ToNot(HaveOccurred(), "failed to start the mock console")
 
-			proxy, requests = infra.NewObservableProxy("agent-proxy", "backend", target, ":8080")
+			target, err := url.Parse(console.URL())
+			Expect(err).ToNot(HaveOccurred(), "failed to parse mock console url")
+
+			proxy, requests = infra.NewObservableProxy("agent-proxy", "console", target, infra.Addr(suite.AgentProxy))
 			time.Sleep(100 * time.Millisecond)
-			GinkgoWriter.Println("Proxy started on :8080")
+			GinkgoWriter.Printf("Proxy started on :%d\n", suite.AgentProxy)
 		})
 
 		AfterAll(func() {
 			proxy.Stop()
+			console.Close()
+		})
+
+		AfterEach(func() {
+			console.Reset()
 		})
 
 		Context("mode at startup", func() {
@@ -93,11 +98,9 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 
 				// Act
 				time.Sleep(5 * time.Second)
-				reqs := obs.Requests()
 
 				// Assert
-				GinkgoWriter.Printf("Observed %d requests\n", len(reqs))
-				Expect(reqs).To(BeEmpty(), "expected no requests in disconnected mode")
+				Expect(obs).ToNot(HaveReceivedAnyRequest(), "expected no requests in disconnected mode")
 			})
 
 			// Given an agent configured to start in connected mode
@@ -123,11 +126,39 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 
 				// Act
 				time.Sleep(5 * time.Second)
-				reqs := obs.Requests()
 
 				// Assert
-				GinkgoWriter.Printf("Observed %d requests\n", len(reqs))
-				Expect(reqs).ToNot(BeEmpty(), "expected requests in connected mode")
+				Expect(obs).To(HaveReceivedPUT("/api/v1/agents/"+agentID+"/status"), "expected status updates in connected mode")
+			})
+
+			// Given a console which no longer knows the source (410 Gone)
+			// When an agent starts in connected mode
+			// Then it should stop reporting after the first rejected status update
+			It("should stop making requests to console once the source is gone", func() {
+				// Arrange
+				console.RespondWithStatus(mockconsole.AgentStatus, http.StatusGone)
+				agentID := uuid.NewString()
+
+				// Act
+				_, err := infraManager.StartAgent(infra.AgentConfig{
+					AgentID:        agentID,
+					SourceID:       uuid.NewString(),
+					Mode:           "connected",
+					ConsoleURL:     cfg.AgentProxyUrl,
+					UpdateInterval: "1s",
+				})
+				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
+				GinkgoWriter.Printf("Agent started with ID: %s\n", agentID)
+
+				Eventually(func() int {
+					return console.Count(mockconsole.AgentStatus)
+				}, 30*time.Second, 1*time.Second).Should(BeNumerically(">=", 1))
+				time.Sleep(5 * time.Second)
+
+				// Assert
+				Expect(obs).To(HaveReceivedPUT("/api/v1/agents/"+agentID+"/status").WithStatus(http.StatusGone).Times(1),
+					"expected a single status update, rejected")
+				Expect(console.Count(mockconsole.SourceStatus)).To(BeZero(), "expected no inventory sent")
 			})
 		})
 
@@ -174,7 +205,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				time.Sleep(5 * time.Second)
 				initialReqs := obs.Requests()
 				GinkgoWriter.Printf("Observed %d requests before mode switch\n", len(initialReqs))
-				Expect(initialReqs).ToNot(BeEmpty(), "expected requests in connected mode")
+				Expect(initialReqs).To(HaveReceivedPUT("/api/v1/agents/"+agentID+"/status"), "expected status updates in connected mode")
 
 				// Act
 				status, err := agentSvc.SetAgentMode("disconnected")
@@ -182,11 +213,9 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				Expect(status.Mode).To(Equal("disconnected"), "expected mode to be disconnected")
 
 				time.Sleep(5 * time.Second)
-				afterSwitchReqs := obs.Requests()
 
 				// Assert
-				GinkgoWriter.Printf("Observed %d requests after mode switch\n", len(afterSwitchReqs))
-				Expect(len(afterSwitchReqs)).To(Equal(len(initialReqs)), "expected no new requests after switching to disconnected")
+				Expect(obs).To(HaveReceivedAnyRequest().Times(len(initialReqs)), "expected no new requests after switching to disconnected")
 			})
 
 			// Given an agent running in disconnected mode making no requests
@@ -210,9 +239,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					return err
 				}, 30*time.Second, 1*time.Second).Should(BeNil())
 
-				initialReqs := obs.Requests()
-				GinkgoWriter.Printf("Observed %d requests before mode switch\n", len(initialReqs))
-				Expect(initialReqs).To(BeEmpty(), "expected no requests in disconnected mode")
+				Expect(obs).ToNot(HaveReceivedAnyRequest(), "expected no requests in disconnected mode")
 
 				// Act
 				status, err := agentSvc.SetAgentMode("connected")
@@ -220,11 +247,9 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				Expect(status.Mode).To(Equal("connected"), "expected mode to be connected")
 
 				time.Sleep(5 * time.Second)
-				afterSwitchReqs := obs.Requests()
 
 				// Assert
-				GinkgoWriter.Printf("Observed %d requests after mode switch\n", len(afterSwitchReqs))
-				Expect(afterSwitchReqs).ToNot(BeEmpty(), "expected requests after switching to connected mode")
+				Expect(obs).To(HaveReceivedPUT("/api/v1/agents/"+agentID+"/status"), "expected status updates after switching to connected mode")
 			})
 
 			// Given an agent that was switched from connected to disconnected mode
@@ -274,28 +299,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 			var agentSvc *service.AgentSvc
 
 			BeforeEach(func() {
-				GinkgoWriter.Println("Starting vcsim...")
-				err := infraManager.StartVcsim()
-				Expect(err).ToNot(HaveOccurred(), "failed to start vcsim")
-				time.Sleep(1 * time.Second) // allow vcsim to initialize
-
-				client := &http.Client{
-					Transport: &http.Transport{
-						TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-					},
-				}
-
-				Eventually(func() error {
-					resp, err := client.Get("https://localhost:8989/sdk")
-					if err != nil {
-						return err
-					}
-					defer resp.Body.Close()
-					if resp.StatusCode >= 500 {
-						return fmt.Errorf("server error: %d", resp.StatusCode)
-					}
-					return nil
-				}, 30*time.Second, 1*time.Second).Should(BeNil())
+				startVcsim(infra.DefaultVcsimModel())
 
 				obs = infra.NewObserver(requests)
 				agentSvc = service.DefaultAgentSvc(cfg.AgentAPIUrl)
@@ -337,7 +341,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				}, 30*time.Second, 1*time.Second).Should(BeNil())
 
 				// Act
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", infra.VcsimUsername, infra.VcsimPassword)
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -378,7 +382,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				}, 30*time.Second, 1*time.Second).Should(BeNil())
 
 				// Act
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", "baduser", "badpass")
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), "baduser", "badpass")
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				// Assert
@@ -449,7 +453,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					return err
 				}, 30*time.Second, 1*time.Second).Should(BeNil())
 
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", "baduser", "badpass")
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), "baduser", "badpass")
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -461,7 +465,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				}, 30*time.Second, 2*time.Second).Should(Equal("error"))
 
 				// Act
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", infra.VcsimUsername, infra.VcsimPassword)
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -501,7 +505,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					return err
 				}, 30*time.Second, 1*time.Second).Should(BeNil())
 
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", infra.VcsimUsername, infra.VcsimPassword)
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -537,9 +541,392 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				Expect(inventory).ToNot(BeNil(), "expected inventory to be available after restart")
 			})
 		})
+
+		Context("large inventory", func() {
+			var (
+				agentSvc *service.AgentSvc
+				model    infra.VcsimModel
+			)
+
+			BeforeEach(func() {
+				// 500 VMs over 2 datacenters, or the inventory recorded with -vcsim-recording
+				model = infra.VcsimModel{
+					Datacenters:     2,
+					Clusters:        3,
+					Hosts:           4,
+					StandaloneHosts: 2,
+					VMs:             50,
+					Datastores:      4,
+				}
+				if cfg.VcsimRecording != "" {
+					model = infra.RecordedVcsimModel(cfg.VcsimRecording)
+				}
+				startVcsim(model)
+
+				obs = infra.NewObserver(requests)
+				agentSvc = service.DefaultAgentSvc(cfg.AgentAPIUrl)
+			})
+
+			AfterEach(func() {
+				if cfg.KeepContainers {
+					GinkgoWriter.Println("Keeping containers running (--keep-containers flag set)")
+					obs.Close()
+					return
+				}
+				GinkgoWriter.Println("Stopping agent...")
+				_ = infraManager.RemoveAgent()
+				obs.Close()
+
+				GinkgoWriter.Println("Stopping vcsim...")
+				_ = infraManager.StopVcsim()
+			})
+
+			// Given an agent in disconnected mode with vcsim serving a large inventory
+			// When the inventory is collected and its VMs listed page by page
+			// Then every VM should be listed exactly once and match the inventory total
+			It("should collect and page through a large inventory", func() {
+				// Arrange
+				_, err := infraManager.StartAgent(infra.AgentConfig{
+					AgentID:        uuid.NewString(),
+					SourceID:       uuid.NewString(),
+					Mode:           "disconnected",
+					ConsoleURL:     cfg.AgentProxyUrl,
+					UpdateInterval: "1s",
+				})
+				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
+
+				Eventually(func() error {
+					_, err := agentSvc.Status()
+					return err
+				}, 30*time.Second, 1*time.Second).Should(BeNil())
+
+				start := time.Now()
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
+				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
+
+				Eventually(func() string {
+					status, err := agentSvc.GetCollectorStatus()
+					if err != nil {
+						return "error"
+					}
+					return status.Status
+				}, 5*time.Minute, 2*time.Second).Should(Equal("collected"))
+				GinkgoWriter.Printf("Collected the inventory in %s\n", time.Since(start))
+
+				inventory, err := agentSvc.Inventory()
+				Expect(err).ToNot(HaveOccurred(), "failed to get inventory")
+				Expect(inventory).ToNot(BeNil(), "expected inventory to be available")
+				Expect(inventory.Vcenter).ToNot(BeNil(), "expected vcenter inventory")
+
+				// Act
+				const pageSize = 100
+				seen := map[string]bool{}
+				var total int
+				for page := 1; ; page++ {
+					list, err := agentSvc.ListVMs(page, pageSize)
+					Expect(err).ToNot(HaveOccurred(), "failed to list vms")
+					total = list.Total
+					for _, vm := range list.Vms {
+						Expect(seen).ToNot(HaveKey(vm.ID), "vm %s listed twice", vm.ID)
+						seen[vm.ID] = true
+					}
+					if page >= list.PageCount {
+						break
+					}
+				}
+
+				// Assert
+				GinkgoWriter.Printf("Listed %d VMs\n", len(seen))
+				Expect(seen).To(HaveLen(total))
+				Expect(total).To(Equal(inventory.Vcenter.Vms.Total))
+				if count := model.VMCount(); count > 0 {
+					Expect(total).To(Equal(count))
+				}
+			})
+		})
+
+		Context("inspector", func() {
+			var (
+				agentSvc   *service.AgentSvc
+				vcsimProxy *infra.Proxy
+				vcsimObs   *infra.Observer
+			)
+
+			BeforeEach(func() {
+				startVcsim(infra.DefaultVcsimModel())
+
+				obs = infra.NewObserver(requests)
+				agentSvc = service.DefaultAgentSvc(cfg.AgentAPIUrl)
+
+				// the inspector reaches vcsim through a proxy observing its SOAP calls
+				target, err := url.Parse(infra.LocalURL("https", suite.Vcsim))
+				Expect(err).ToNot(HaveOccurred(), "failed to parse vcsim url")
+				var vcsimRequests chan infra.Request
+				vcsimProxy, vcsimRequests = infra.NewObservableProxy("vcsim-proxy", "vcsim", target, infra.Addr(suite.VcsimProxy))
+				vcsimObs = infra.NewObserver(vcsimRequests)
+			})
+
+			AfterEach(func() {
+				if !cfg.KeepContainers {
+					GinkgoWriter.Println("Stopping agent...")
+					_ = infraManager.RemoveAgent()
+				}
+				// the proxy blocks on its observer, stopped after it
+				vcsimProxy.Stop()
+				vcsimObs.Close()
+				obs.Close()
+
+				if cfg.KeepContainers {
+					GinkgoWriter.Println("Keeping agent and vcsim containers running (--keep-containers flag set)")
+					return
+				}
+				GinkgoWriter.Println("Stopping vcsim...")
+				_ = infraManager.StopVcsim()
+			})
+
+			startAgent := func(features ...string) {
+				_, err := infraManager.StartAgent(infra.AgentConfig{
+					AgentID:        uuid.NewString(),
+					SourceID:       uuid.NewString(),
+					Mode:           "disconnected",
+					ConsoleURL:     cfg.AgentProxyUrl,
+					UpdateInterval: "1s",
+					Features:       features,
+				})
+				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
+
+				Eventually(func() error {
+					_, err := agentSvc.Status()
+					return err
+				}, 30*time.Second, 1*time.Second).Should(BeNil())
+			}
+
+			// createSnapshot and removeSnapshot match the SOAP calls of the inspector
+			createSnapshot := func(vmID string) *RequestMatcher {
+				return HaveReceivedPOST("/sdk").WithBodyContaining("CreateSnapshot_Task").WithBodyContaining(">" + vmID + "<")
+			}
+			removeSnapshot := func() *RequestMatcher {
+				return HaveReceivedPOST("/sdk").WithBodyContaining("RemoveSnapshot_Task")
+			}
+
+			// Given an agent with the inspector enabled and a collected inventory
+			// When the inspection of a subset of the VMs is started
+			// Then each of them should be snapshotted, the snapshot removed and its inspection reported completed, the others left untouched
+			It("should snapshot and inspect the selected VMs only", func() {
+				// Arrange
+				startAgent("inspector")
+
+				_, err := agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
+				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
+				Eventually(func() string {
+					status, err := agentSvc.GetCollectorStatus()
+					if err != nil {
+						return "error"
+					}
+					return status.Status
+				}, 60*time.Second, 2*time.Second).Should(Equal("collected"))
+
+				list, err := agentSvc.ListVMs(1, 100)
+				Expect(err).ToNot(HaveOccurred(), "failed to list vms")
+				Expect(len(list.Vms)).To(BeNumerically(">=", 3), "expected at least 3 VMs in vcsim")
+				var selected, skipped []string
+				for i, vm := range list.Vms {
+					if i < 2 {
+						selected = append(selected, vm.ID)
+					} else {
+						skipped = append(skipped, vm.ID)
+					}
+				}
+				GinkgoWriter.Printf("Inspecting VMs %v\n", selected)
+
+				// Act
+				_, err = agentSvc.StartInspection(selected, suite.VcsimProxyURL(), infra.VcsimUsername, infra.VcsimPassword)
+				Expect(err).ToNot(HaveOccurred(), "failed to start inspection")
+
+				Eventually(func() string {
+					status, err := agentSvc.GetInspectorStatus()
+					if err != nil {
+						return "error"
+					}
+					GinkgoWriter.Printf("Inspector status: %s %s\n", status.State, status.Error)
+					return status.State
+				}, 2*time.Minute, 2*time.Second).Should(Equal("completed"))
+
+				// Assert
+				for _, id := range selected {
+					status, err := agentSvc.GetVMInspectionStatus(id)
+					Expect(err).ToNot(HaveOccurred(), "failed to get the inspection status of %s", id)
+					Expect(status.State).To(Equal("completed"), "expected the inspection of %s to be completed", id)
+					Expect(vcsimObs).To(HaveReceivedInOrder(createSnapshot(id), removeSnapshot()),
+						"expected the snapshot of %s to be created then removed", id)
+				}
+				Expect(vcsimObs).To(removeSnapshot().Times(len(selected)), "expected one snapshot removal per inspected VM")
+				for _, id := range skipped {
+					Expect(vcsimObs).ToNot(createSnapshot(id), "expected no snapshot of %s", id)
+				}
+
+				events, err := agentSvc.Events("inspection.started", "inspection.completed", "inspection.vm_failed")
+				Expect(err).ToNot(HaveOccurred(), "failed to list events")
+				Expect(events).To(ContainElement(HaveField("Type", "inspection.started")))
+				Expect(events).To(ContainElement(HaveField("Type", "inspection.completed")))
+				Expect(events).ToNot(ContainElement(HaveField("Type", "inspection.vm_failed")))
+			})
+
+			// Given an agent with the inspector enabled and vcsim running
+			// When the inspection is started with invalid credentials
+			// Then it should be rejected, the inspector report the error and no snapshot be taken
+			It("should report the error of an inspection with bad credentials", func() {
+				// Arrange
+				startAgent("inspector")
+
+				// Act
+				_, err := agentSvc.StartInspection([]string{"vm-1"}, suite.VcsimProxyURL(), "bad-user", "bad-password")
+
+				// Assert
+				Expect(err).To(HaveOccurred(), "expected the inspection to be rejected")
+				status, err := agentSvc.GetInspectorStatus()
+				Expect(err).ToNot(HaveOccurred(), "failed to get inspector status")
+				Expect(status.State).To(Equal("error"))
+				Expect(status.Error).ToNot(BeEmpty(), "expected the error of the inspector")
+				Expect(vcsimObs).ToNot(HaveReceivedPOST("/sdk").WithBodyContaining("CreateSnapshot_Task"))
+			})
+
+			// Given an agent without the inspector feature
+			// When the inspector is queried
+			// Then its endpoints should not be found
+			It("should not serve the inspector unless the feature is enabled", func() {
+				// Arrange
+				startAgent()
+
+				// Act
+				_, err := agentSvc.GetInspectorStatus()
+
+				// Assert
+				Expect(err).To(MatchError(ContainSubstring("404")))
+			})
+		})
+
+		// The "vcenter" specs run the collection and inspection flows against the
+		// -vcenter-url vCenter rather than vcsim, to catch the behaviors of a real
+		// one; they are skipped unless it is set.
+		Context("real vCenter", Label("vcenter"), func() {
+			var agentSvc *service.AgentSvc
+
+			BeforeEach(func() {
+				if cfg.VcenterURL == "" {
+					Skip("no vCenter to run against (-vcenter-url)")
+				}
+
+				obs = infra.NewObserver(requests)
+				agentSvc = service.DefaultAgentSvc(cfg.AgentAPIUrl)
+			})
+
+			AfterEach(func() {
+				if !cfg.KeepContainers {
+					GinkgoWriter.Println("Stopping agent...")
+					_ = infraManager.RemoveAgent()
+				}
+				obs.Close()
+			})
+
+			startAgent := func(features ...string) {
+				_, err := infraManager.StartAgent(infra.AgentConfig{
+					AgentID:        uuid.NewString(),
+					SourceID:       uuid.NewString(),
+					Mode:           "disconnected",
+					ConsoleURL:     cfg.AgentProxyUrl,
+					UpdateInterval: "1s",
+					Features:       features,
+				})
+				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
+
+				Eventually(func() error {
+					_, err := agentSvc.Status()
+					return err
+				}, 30*time.Second, 1*time.Second).Should(BeNil())
+			}
+
+			collect := func() {
+				_, err := agentSvc.StartCollector(cfg.VcenterURL, cfg.VcenterUser, cfg.VcenterPassword)
+				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
+
+				// a real inventory takes longer to collect than the vcsim one
+				Eventually(func() string {
+					status, err := agentSvc.GetCollectorStatus()
+					if err != nil {
+						return "error"
+					}
+					GinkgoWriter.Printf("Collector status: %s %s\n", status.Status, status.Error)
+					return status.Status
+				}, 10*time.Minute, 5*time.Second).Should(Equal("collected"))
+			}
+
+			// Given an agent in disconnected mode and a real vCenter
+			// When its credentials are provided to the collector
+			// Then the collector should reach "collected" status and its VMs be listed
+			It("should collect the inventory of a real vCenter", func() {
+				// Arrange
+				startAgent()
+
+				// Act
+				collect()
+
+				// Assert
+				inventory, err := agentSvc.Inventory()
+				Expect(err).ToNot(HaveOccurred(), "failed to get inventory")
+				Expect(inventory).ToNot(BeNil(), "expected inventory to be available")
+
+				list, err := agentSvc.ListVMs(1, 100)
+				Expect(err).ToNot(HaveOccurred(), "failed to list vms")
+				Expect(list.Total).To(BeNumerically(">", 0), "expected the VMs of the vCenter")
+				for _, vm := range list.Vms {
+					Expect(vm.ID).ToNot(BeEmpty())
+				}
+				Expect(obs).ToNot(HaveReceivedAnyRequest(), "disconnected agent should not contact the console")
+			})
+
+			// Given an agent with the inspector enabled and the inventory of a real vCenter
+			// When the inspection of the -vcenter-inspect-vms VMs is started
+			// Then each of them should be reported completed without failure events
+			It("should inspect VMs of a real vCenter", func() {
+				// snapshotting VMs is only done on the VMs given for it
+				if len(cfg.VcenterInspectVMs) == 0 {
+					Skip("no VM to inspect (-vcenter-inspect-vms)")
+				}
+
+				// Arrange
+				startAgent("inspector")
+				collect()
+
+				// Act
+				_, err := agentSvc.StartInspection(cfg.VcenterInspectVMs, cfg.VcenterURL, cfg.VcenterUser, cfg.VcenterPassword)
+				Expect(err).ToNot(HaveOccurred(), "failed to start inspection")
+
+				Eventually(func() string {
+					status, err := agentSvc.GetInspectorStatus()
+					if err != nil {
+						return "error"
+					}
+					GinkgoWriter.Printf("Inspector status: %s %s\n", status.State, status.Error)
+					return status.State
+				}, 30*time.Minute, 10*time.Second).Should(Equal("completed"))
+
+				// Assert
+				for _, id := range cfg.VcenterInspectVMs {
+					status, err := agentSvc.GetVMInspectionStatus(id)
+					Expect(err).ToNot(HaveOccurred(), "failed to get the inspection status of %s", id)
+					Expect(status.State).To(Equal("completed"), "expected the inspection of %s to be completed: %s", id, status.Error)
+				}
+
+				events, err := agentSvc.Events("inspection.completed", "inspection.vm_failed")
+				Expect(err).ToNot(HaveOccurred(), "failed to list events")
+				Expect(events).To(ContainElement(HaveField("Type", "inspection.completed")))
+				Expect(events).ToNot(ContainElement(HaveField("Type", "inspection.vm_failed")))
+			})
+		})
 	})
 
-	Context("connected env", func() {
+	Context("connected env", Ordered, func() {
 		var (
 			plannerSvc *service.PlannerSvc
 			proxy      *infra.Proxy
@@ -548,14 +935,19 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 		)
 
 		BeforeAll(func() {
+			GinkgoWriter.Println("Starting postgres...")
+			err := infraManager.StartPostgres()
+			Expect(err).ToNot(HaveOccurred(), "failed to start postgres")
+			time.Sleep(2 * time.Second) // wait for postgres to be ready
+
 			// Start OIDC server before the backend so JWKS URL is available
 			GinkgoWriter.Println("Starting OIDC server...")
-			err := infraManager.StartOIDC(":9090")
+			err = infraManager.StartOIDC(infra.Addr(suite.OIDC))
 			Expect(err).ToNot(HaveOccurred(), "failed to start OIDC server")
 
 			// Add a proxy between backend and OIDC
-			oidcUrl, _ := url.Parse("http://localhost:9090")
-			oidcProxy = infra.NewProxy("oidc-proxy", "oidc", oidcUrl, ":8082")
+			oidcUrl, _ := url.Parse(infra.LocalURL("http", suite.OIDC))
+			oidcProxy = infra.NewProxy("oidc-proxy", "oidc", oidcUrl, infra.Addr(suite.OIDCProxy))
 
 			GinkgoWriter.Println("Starting backend...")
 			err = infraManager.StartBackend()
@@ -581,11 +973,11 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 			Expect(err).ToNot(HaveOccurred(), "failed to parse backend endpoint")
 
 			var requests chan infra.Request
-			proxy, requests = infra.NewObservableProxy("agent-proxy", "backend", target, ":8081")
+			proxy, requests = infra.NewObservableProxy("agent-proxy", "backend", target, infra.Addr(suite.ConnectedAgentProxy))
 			obs = infra.NewObserver(requests)
 
 			time.Sleep(100 * time.Millisecond)
-			GinkgoWriter.Println("Proxy started on :8081")
+			GinkgoWriter.Printf("Proxy started on :%d\n", suite.ConnectedAgentProxy)
 		})
 
 		AfterAll(func() {
@@ -600,6 +992,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 			}
 			_ = infraManager.StopBackend()
 			_ = infraManager.StopOIDC()
+			_ = infraManager.StopPostgres()
 		})
 
 		Context("mode at startup", func() {
@@ -737,7 +1130,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					AgentID:        agentID,
 					SourceID:       sourceID.String(),
 					Mode:           "disconnected",
-					ConsoleURL:     "http://localhost:8081", // Use proxy to observe requests
+					ConsoleURL:     infra.LocalURL("http", suite.ConnectedAgentProxy), // Use proxy to observe requests
 					UpdateInterval: "1s",
 				})
 				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
@@ -765,9 +1158,8 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				Expect(status.Error).To(BeEmpty(), "expected no error in agent status")
 
 				// Assert - verify requests were made to backend via observer
-				reqs := obs.Requests()
-				GinkgoWriter.Printf("Observed %d requests to backend\n", len(reqs))
-				Expect(reqs).ToNot(BeEmpty(), "expected requests to be made to backend")
+				Expect(obs).To(HaveReceivedPUT("/api/v1/agents/"+agentID+"/status").WithJSONField("sourceId", sourceID.String()),
+					"expected status updates to be made to backend")
 			})
 		})
 
@@ -779,28 +1171,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 			)
 
 			BeforeEach(func() {
-				GinkgoWriter.Println("Starting vcsim...")
-				err := infraManager.StartVcsim()
-				Expect(err).ToNot(HaveOccurred(), "failed to start vcsim")
-				time.Sleep(1 * time.Second) // allow vcsim to initialize
-
-				// Wait for vcsim to be ready
-				client := &http.Client{
-					Transport: &http.Transport{
-						TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-					},
-				}
-				Eventually(func() error {
-					resp, err := client.Get("https://localhost:8989/sdk")
-					if err != nil {
-						return err
-					}
-					defer resp.Body.Close()
-					if resp.StatusCode >= 500 {
-						return fmt.Errorf("server error: %d", resp.StatusCode)
-					}
-					return nil
-				}, 30*time.Second, 1*time.Second).Should(BeNil())
+				startVcsim(infra.DefaultVcsimModel())
 
 				agentSvc = service.DefaultAgentSvc(cfg.AgentAPIUrl)
 				userSvc = plannerSvc.WithAuthUser("admin", "admin", "admin@example.com")
@@ -836,7 +1207,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					AgentID:        agentID,
 					SourceID:       sourceID.String(),
 					Mode:           "connected",
-					ConsoleURL:     "http://localhost:8081",
+					ConsoleURL:     infra.LocalURL("http", suite.ConnectedAgentProxy),
 					UpdateInterval: "1s",
 				})
 				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
@@ -849,7 +1220,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 
 				// Act
 				GinkgoWriter.Println("Starting collector with valid credentials...")
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", infra.VcsimUsername, infra.VcsimPassword)
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -871,6 +1242,10 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 				GinkgoWriter.Printf("Source inventory: %+v\n", source.Inventory)
 				Expect(source.Inventory).ToNot(BeNil(), "expected inventory to be populated")
 				Expect(source.Inventory.VcenterId).ToNot(BeEmpty(), "expected vcenter_id to be set")
+				Expect(obs).To(HaveReceivedInOrder(
+					HaveReceivedPUT("/api/v1/agents/"+agentID+"/status"),
+					HaveReceivedPUT("/api/v1/sources/"+sourceID.String()+"/status").WithJSONField("agentId", agentID),
+				), "expected the inventory to be pushed after the agent status")
 			})
 
 			// Given an agent that switches to disconnected mode before collecting
@@ -883,7 +1258,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					AgentID:        agentID,
 					SourceID:       sourceID.String(),
 					Mode:           "connected",
-					ConsoleURL:     "http://localhost:8081",
+					ConsoleURL:     infra.LocalURL("http", suite.ConnectedAgentProxy),
 					UpdateInterval: "1s",
 				})
 				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
@@ -902,7 +1277,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 
 				// Act - Collect inventory in disconnected mode
 				GinkgoWriter.Println("Starting collector with valid credentials...")
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", infra.VcsimUsername, infra.VcsimPassword)
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -943,7 +1318,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					AgentID:        agentID,
 					SourceID:       sourceID.String(),
 					Mode:           "connected",
-					ConsoleURL:     "http://localhost:8081",
+					ConsoleURL:     infra.LocalURL("http", suite.ConnectedAgentProxy),
 					UpdateInterval: "1s",
 				})
 				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
@@ -956,7 +1331,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 
 				// Act
 				GinkgoWriter.Println("Starting collector with invalid credentials...")
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", "baduser", "badpass")
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), "baduser", "badpass")
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				// Wait for collector to reach error state
@@ -992,7 +1367,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 					AgentID:        agentID,
 					SourceID:       sourceID.String(),
 					Mode:           "connected",
-					ConsoleURL:     "http://localhost:8081",
+					ConsoleURL:     infra.LocalURL("http", suite.ConnectedAgentProxy),
 					UpdateInterval: "1s",
 				})
 				Expect(err).ToNot(HaveOccurred(), "failed to start agent")
@@ -1005,7 +1380,7 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 
 				// Act
 				GinkgoWriter.Println("Starting collector with valid credentials...")
-				_, err = agentSvc.StartCollector("https://localhost:8989/sdk", infra.VcsimUsername, infra.VcsimPassword)
+				_, err = agentSvc.StartCollector(suite.VcsimURL(), infra.VcsimUsername, infra.VcsimPassword)
 				Expect(err).ToNot(HaveOccurred(), "failed to start collector")
 
 				Eventually(func() string {
@@ -1045,3 +1420,28 @@ var _ = Describe("Agent e2e tests", Ordered, func() {
 		})
 	})
 })
+
+// startVcsim starts vcsim serving model and waits for it to answer.
+func startVcsim(model infra.VcsimModel) {
+	GinkgoWriter.Println("Starting vcsim...")
+	err := infraManager.StartVcsim(model)
+	Expect(err).ToNot(HaveOccurred(), "failed to start vcsim")
+	time.Sleep(1 * time.Second) // allow vcsim to initialize
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	Eventually(func() error {
+		resp, err := client.Get(suite.VcsimURL())
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("server error: %d", resp.StatusCode)
+		}
+		return nil
+	}, 30*time.Second, 1*time.Second).Should(BeNil())
+}
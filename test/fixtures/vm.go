@@ -0,0 +1,261 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// VMBuilder builds a VM of the inventory tables created by the duckdb_parser
+// (vinfo, vcpu, vmemory, vdisk, vnetwork and concerns). Only the columns set on the
+// builder are inserted, the others being left NULL like in a sparse RVTools
+// export.
+type VMBuilder struct {
+	id       string
+	columns  map[string]any
+	cpus     int32
+	sockets  int32
+	cpuHot   bool
+	memHot   bool
+	disks    []Disk
+	nics     []NIC
+	concerns []Concern
+}
+
+// Disk is a vdisk row of a VM; the zero fields are not inserted.
+type Disk struct {
+	CapacityMiB int64
+	Path        string
+	DiskMode    string
+	Shared      bool
+	RDM         bool
+	Controller  string
+}
+
+// NIC is a vnetwork row of a VM.
+type NIC struct {
+	Network string
+	MAC     string
+}
+
+// Concern is a concerns row of a VM.
+type Concern struct {
+	ID         string
+	Label      string
+	Category   string
+	Assessment string
+}
+
+// NewVM returns the builder of a VM named after its id.
+func NewVM(id string) *VMBuilder {
+	return &VMBuilder{
+		id:      id,
+		columns: map[string]any{`"VM ID"`: id, `"VM"`: id},
+	}
+}
+
+// ID is the "VM ID" of the VM.
+func (b *VMBuilder) ID() string {
+	return b.id
+}
+
+func (b *VMBuilder) WithName(name string) *VMBuilder {
+	return b.WithColumn("VM", name)
+}
+
+func (b *VMBuilder) WithPowerState(state string) *VMBuilder {
+	return b.WithColumn("Powerstate", state)
+}
+
+func (b *VMBuilder) WithConnectionState(state string) *VMBuilder {
+	return b.WithColumn("Connection state", state)
+}
+
+func (b *VMBuilder) WithDatacenter(datacenter string) *VMBuilder {
+	return b.WithColumn("Datacenter", datacenter)
+}
+
+func (b *VMBuilder) WithCluster(cluster string) *VMBuilder {
+	return b.WithColumn("Cluster", cluster)
+}
+
+func (b *VMBuilder) WithHost(host string) *VMBuilder {
+	return b.WithColumn("Host", host)
+}
+
+func (b *VMBuilder) WithFolder(folderID string) *VMBuilder {
+	return b.WithColumn("Folder ID", folderID)
+}
+
+func (b *VMBuilder) WithFirmware(firmware string) *VMBuilder {
+	return b.WithColumn("Firmware", firmware)
+}
+
+func (b *VMBuilder) WithUUID(uuid string) *VMBuilder {
+	return b.WithColumn("SMBIOS UUID", uuid)
+}
+
+// WithMemory sets the memory of the VM in MiB.
+func (b *VMBuilder) WithMemory(mib int32) *VMBuilder {
+	return b.WithColumn("Memory", mib)
+}
+
+// WithCPUs sets the CPUs of the VM, also adding its vcpu row with a single
+// socket.
+func (b *VMBuilder) WithCPUs(cpus int32) *VMBuilder {
+	return b.WithCPUTopology(1, cpus)
+}
+
+// WithCPUTopology sets the CPUs of the VM as sockets of coresPerSocket cores.
+func (b *VMBuilder) WithCPUTopology(sockets, coresPerSocket int32) *VMBuilder {
+	b.cpus = sockets * coresPerSocket
+	b.sockets = sockets
+	return b.WithColumn("CPUs", b.cpus)
+}
+
+// WithHotAdd enables the hot add of CPUs and of memory, adding the vcpu and
+// vmemory rows of the VM.
+func (b *VMBuilder) WithHotAdd(cpu, memory bool) *VMBuilder {
+	b.cpuHot = cpu
+	b.memHot = memory
+	return b
+}
+
+func (b *VMBuilder) WithHWVersion(version string) *VMBuilder {
+	return b.WithColumn("HW version", version)
+}
+
+func (b *VMBuilder) WithGuestOS(name string) *VMBuilder {
+	return b.WithColumn("OS according to the configuration file", name)
+}
+
+func (b *VMBuilder) WithDNSName(name string) *VMBuilder {
+	return b.WithColumn("DNS Name", name)
+}
+
+func (b *VMBuilder) WithIPAddress(ip string) *VMBuilder {
+	return b.WithColumn("Primary IP Address", ip)
+}
+
+// WithStorageUsed sets the storage in use by the VM in MiB.
+func (b *VMBuilder) WithStorageUsed(mib int32) *VMBuilder {
+	return b.WithColumn("In Use MiB", mib)
+}
+
+func (b *VMBuilder) WithTemplate(template bool) *VMBuilder {
+	return b.WithColumn("Template", template)
+}
+
+func (b *VMBuilder) WithFaultTolerance(enabled bool) *VMBuilder {
+	return b.WithColumn("FT State", enabled)
+}
+
+// WithColumn sets a vinfo column the builder has no method for.
+func (b *VMBuilder) WithColumn(name string, value any) *VMBuilder {
+	b.columns[`"`+name+`"`] = value
+	return b
+}
+
+// WithDisk adds a disk of capacityMiB.
+func (b *VMBuilder) WithDisk(capacityMiB int64) *VMBuilder {
+	return b.WithDiskOf(Disk{CapacityMiB: capacityMiB})
+}
+
+// WithDisks adds n disks of 100 MiB.
+func (b *VMBuilder) WithDisks(n int) *VMBuilder {
+	for range n {
+		b.WithDisk(100)
+	}
+	return b
+}
+
+func (b *VMBuilder) WithDiskOf(disk Disk) *VMBuilder {
+	b.disks = append(b.disks, disk)
+	return b
+}
+
+func (b *VMBuilder) WithNIC(network, mac string) *VMBuilder {
+	b.nics = append(b.nics, NIC{Network: network, MAC: mac})
+	return b
+}
+
+// WithConcern adds a warning concern.
+func (b *VMBuilder) WithConcern(id, label string) *VMBuilder {
+	return b.WithConcernOf(Concern{ID: id, Label: label, Category: "Warning", Assessment: "Needs attention"})
+}
+
+func (b *VMBuilder) WithConcernOf(concern Concern) *VMBuilder {
+	b.concerns = append(b.concerns, concern)
+	return b
+}
+
+// Insert inserts vms into db, which must have the inventory tables.
+func Insert(ctx context.Context, db *sql.DB, vms ...*VMBuilder) error {
+	for _, vm := range vms {
+		if err := vm.Insert(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert inserts the VM into db, which must have the inventory tables.
+func (b *VMBuilder) Insert(ctx context.Context, db *sql.DB) error {
+	rows := []row{{"vinfo", b.columns}}
+
+	if b.cpus > 0 || b.cpuHot {
+		columns := map[string]any{`"VM ID"`: b.id, `"Hot Add"`: b.cpuHot}
+		setIf(columns, `"Sockets"`, b.sockets, b.sockets > 0)
+		setIf(columns, `"Cores p/s"`, b.cpus/max(b.sockets, 1), b.cpus > 0)
+		rows = append(rows, row{"vcpu", columns})
+	}
+	if b.memHot {
+		rows = append(rows, row{"vmemory", map[string]any{`"VM ID"`: b.id, `"Hot Add"`: true}})
+	}
+	for _, d := range b.disks {
+		columns := map[string]any{`"VM ID"`: b.id, `"Capacity MiB"`: d.CapacityMiB}
+		setIf(columns, `"Path"`, d.Path, d.Path != "")
+		setIf(columns, `"Disk Mode"`, d.DiskMode, d.DiskMode != "")
+		setIf(columns, `"Sharing mode"`, d.Shared, d.Shared)
+		setIf(columns, `"Raw"`, d.RDM, d.RDM)
+		setIf(columns, `"Controller"`, d.Controller, d.Controller != "")
+		rows = append(rows, row{"vdisk", columns})
+	}
+	for _, n := range b.nics {
+		rows = append(rows, row{"vnetwork", map[string]any{`"VM ID"`: b.id, `"Network"`: n.Network, `"Mac Address"`: n.MAC}})
+	}
+	for _, c := range b.concerns {
+		rows = append(rows, row{"concerns", map[string]any{
+			`"VM_ID"`:      b.id,
+			`"Concern_ID"`: c.ID,
+			`"Label"`:      c.Label,
+			`"Category"`:   c.Category,
+			`"Assessment"`: c.Assessment,
+		}})
+	}
+
+	for _, r := range rows {
+		query, args, err := sq.Insert(r.table).SetMap(r.columns).ToSql()
+		if err != nil {
+			return fmt.Errorf("building the %s row of %s: %w", r.table, b.id, err)
+		}
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("inserting the %s row of %s: %w", r.table, b.id, err)
+		}
+	}
+	return nil
+}
+
+// row is a row of an inventory table, by quoted column name.
+type row struct {
+	table   string
+	columns map[string]any
+}
+
+func setIf(columns map[string]any, name string, value any, ok bool) {
+	if ok {
+		columns[name] = value
+	}
+}
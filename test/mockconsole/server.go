@@ -0,0 +1,323 @@
+package mockconsole
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Endpoint is an endpoint of the console the agent calls.
+type Endpoint string
+
+const (
+	// AgentStatus is PUT /api/v1/agents/{id}/status
+	AgentStatus Endpoint = "agent status"
+	// SourceStatus is PUT /api/v1/sources/{id}/status, carrying the inventory
+	SourceStatus Endpoint = "source status"
+	// Register is POST /api/v1/agents/register, authenticated by a provisioning token
+	Register Endpoint = "register"
+	// AgentConfiguration is GET /api/v1/agents/{id}/configuration
+	AgentConfiguration Endpoint = "agent configuration"
+	// PolicyBundle is GET /api/v1/agents/{id}/policy-bundle, the usual policy bundle URL
+	PolicyBundle Endpoint = "policy bundle"
+	// UpdateManifest is GET /api/v1/agents/{id}/update, the usual update channel URL
+	UpdateManifest Endpoint = "update manifest"
+	// UpdateBinary is GET /api/v1/agents/{id}/update/binary, the usual binary URL of the manifest
+	UpdateBinary Endpoint = "update binary"
+	// CommandChannel is GET /api/v1/agents/{id}/channel, upgraded to a websocket when answered with 101
+	CommandChannel Endpoint = "command channel"
+)
+
+// Response is the answer of the Server to a request of an endpoint.
+type Response struct {
+	Status int
+	// Latency delays the answer, unless the request is cancelled first
+	Latency time.Duration
+	// Body is encoded to JSON, no body being sent when nil, except a []byte
+	// which is sent as is
+	Body any
+	// Header is added to the headers of the answer
+	Header http.Header
+}
+
+// Request is a request of an endpoint received by the Server.
+type Request struct {
+	Endpoint Endpoint
+	// ID is the agent or source id of the path
+	ID     string
+	Header http.Header
+	Body   []byte
+	// Status is the status the Server answered with
+	Status int
+}
+
+// Decode decodes the JSON body of the request into v.
+func (r Request) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Server is a mock console serving the endpoints the agent calls, for the
+// unit tests of the console service and the e2e tests which do not need the
+// whole backend. Each endpoint answers with its programmed responses: those
+// queued with RespondOnce first, then the one set with Respond, 200 by
+// default. The requests are recorded for the assertions.
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	responses map[Endpoint]Response
+	queued    map[Endpoint][]Response
+	requests  []Request
+	// channel is the open command channel, nil when there is none, and
+	// messages the ones the agent sent on it
+	channel  *websocket.Conn
+	messages []json.RawMessage
+}
+
+// NewServer starts a Server on a random port of localhost.
+func NewServer() *Server {
+	s := newServer()
+	s.server = httptest.NewServer(s.handler())
+	return s
+}
+
+// NewServerOn starts a Server listening on addr, e.g. ":8084", for the agents
+// which need a known port.
+func NewServerOn(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", addr, err)
+	}
+
+	s := newServer()
+	s.server = httptest.NewUnstartedServer(s.handler())
+	s.server.Listener.Close()
+	s.server.Listener = listener
+	s.server.Start()
+	return s, nil
+}
+
+func newServer() *Server {
+	s := &Server{}
+	s.Reset()
+	return s
+}
+
+// URL is the base URL of the Server, the console URL of the agent.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Respond sets the response of endpoint once the queued ones are consumed.
+func (s *Server) Respond(endpoint Endpoint, response Response) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[endpoint] = response
+	return s
+}
+
+// RespondWithStatus sets the status endpoint answers with, without latency.
+func (s *Server) RespondWithStatus(endpoint Endpoint, status int) *Server {
+	return s.Respond(endpoint, Response{Status: status})
+}
+
+// RespondOnce queues responses, each answering a single request of endpoint
+// before the one set with Respond.
+func (s *Server) RespondOnce(endpoint Endpoint, responses ...Response) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued[endpoint] = append(s.queued[endpoint], responses...)
+	return s
+}
+
+// Requests returns the requests received for endpoints, or for all of them
+// when none is given, in the order they were received.
+func (s *Server) Requests(endpoints ...Endpoint) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]Request, 0, len(s.requests))
+	for _, r := range s.requests {
+		if len(endpoints) == 0 || slices.Contains(endpoints, r.Endpoint) {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// Count returns the number of requests received for endpoint.
+func (s *Server) Count(endpoint Endpoint) int {
+	return len(s.Requests(endpoint))
+}
+
+// Reset drops the recorded requests and restores the default responses.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = map[Endpoint]Response{
+		AgentStatus:        {Status: http.StatusOK},
+		SourceStatus:       {Status: http.StatusOK},
+		AgentConfiguration: {Status: http.StatusOK, Body: map[string]any{}},
+		CommandChannel:     {Status: http.StatusSwitchingProtocols},
+	}
+	s.queued = make(map[Endpoint][]Response)
+	s.requests = nil
+	s.messages = nil
+}
+
+// Push sends command, encoded to JSON, on the open command channel.
+func (s *Server) Push(command any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.channel == nil {
+		return fmt.Errorf("no command channel open")
+	}
+	return s.channel.WriteJSON(command)
+}
+
+// ChannelOpen tells whether an agent holds the command channel open.
+func (s *Server) ChannelOpen() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channel != nil
+}
+
+// CloseChannel breaks the open command channel, if any.
+func (s *Server) CloseChannel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.channel != nil {
+		_ = s.channel.Close()
+	}
+}
+
+// ChannelMessages returns the messages the agents sent on the command
+// channel, in the order they were received.
+func (s *Server) ChannelMessages() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /api/v1/agents/{id}/status", s.handle(AgentStatus))
+	mux.HandleFunc("PUT /api/v1/sources/{id}/status", s.handle(SourceStatus))
+	mux.HandleFunc("POST /api/v1/agents/register", s.handle(Register))
+	mux.HandleFunc("GET /api/v1/agents/{id}/configuration", s.handle(AgentConfiguration))
+	mux.HandleFunc("GET /api/v1/agents/{id}/policy-bundle", s.handle(PolicyBundle))
+	mux.HandleFunc("GET /api/v1/agents/{id}/update", s.handle(UpdateManifest))
+	mux.HandleFunc("GET /api/v1/agents/{id}/update/binary", s.handle(UpdateBinary))
+	mux.HandleFunc("GET /api/v1/agents/{id}/channel", s.handleChannel)
+	return mux
+}
+
+func (s *Server) handle(endpoint Endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response := s.next(endpoint, Request{
+			Endpoint: endpoint,
+			ID:       r.PathValue("id"),
+			Header:   r.Header.Clone(),
+			Body:     body,
+		})
+
+		if response.Latency > 0 {
+			select {
+			case <-time.After(response.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		for name, values := range response.Header {
+			w.Header()[name] = values
+		}
+		if response.Body == nil {
+			w.WriteHeader(response.Status)
+			return
+		}
+		if raw, ok := response.Body.([]byte); ok {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(response.Status)
+			_, _ = w.Write(raw)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.Status)
+		_ = json.NewEncoder(w).Encode(response.Body)
+	}
+}
+
+// handleChannel upgrades the command channel when its response is 101, then
+// records the messages of the agent until the channel closes.
+func (s *Server) handleChannel(w http.ResponseWriter, r *http.Request) {
+	response := s.next(CommandChannel, Request{
+		Endpoint: CommandChannel,
+		ID:       r.PathValue("id"),
+		Header:   r.Header.Clone(),
+	})
+	if response.Status != http.StatusSwitchingProtocols {
+		w.WriteHeader(response.Status)
+		return
+	}
+
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.channel = conn
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if s.channel == conn {
+			s.channel = nil
+		}
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.messages = append(s.messages, msg)
+		s.mu.Unlock()
+	}
+}
+
+// next records req and returns the response to it.
+func (s *Server) next(endpoint Endpoint, req Request) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := s.responses[endpoint]
+	if queued := s.queued[endpoint]; len(queued) > 0 {
+		response, s.queued[endpoint] = queued[0], queued[1:]
+	}
+
+	req.Status = response.Status
+	s.requests = append(s.requests, req)
+	return response
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"sort"
+)
+
+// NewPolicyBundle returns a gzipped tarball of files, by path, like the OPA
+// policy bundles downloaded from the console.
+func NewPolicyBundle(files map[string]string) []byte {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, p := range paths {
+		content := []byte(files[p])
+		_ = tw.WriteHeader(&tar.Header{Name: p, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write(content)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// PolicyRule returns a policy flagging the VMs named vmName with the concern id.
+func PolicyRule(id, vmName string) string {
+	return `package io.konveyor.forklift.vmware
+
+concerns contains flag if {
+	input.name == "` + vmName + `"
+	flag := {"id": "` + id + `", "category": "Warning", "label": "Test", "assessment": "Test rule"}
+}
+`
+}
@@ -0,0 +1,114 @@
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/test"
+)
+
+// Seed inserts the data of a Snapshot into its migrated database.
+type Seed func(ctx context.Context, db *sql.DB) error
+
+// Snapshot is a DuckDB file built on its first Open, with the parser schema,
+// the migrations and its seed, then copied by each Open, so the tests of a
+// suite pay the schema setup once rather than in every spec. The snapshots of
+// a suite are removed with Remove once it is done, e.g. in its AfterSuite.
+type Snapshot struct {
+	seeds []Seed
+
+	once sync.Once
+	dir  string
+	path string
+	err  error
+}
+
+// Migrated is the snapshot of the migrated schema, without data.
+var Migrated = NewSnapshot()
+
+// NewSnapshot returns the snapshot of the migrated schema with the data of
+// seeds, inserted in their order.
+func NewSnapshot(seeds ...Seed) *Snapshot {
+	return &Snapshot{seeds: seeds}
+}
+
+// Open copies the snapshot into dir, e.g. GinkgoT().TempDir(), and opens the
+// copy.
+func (s *Snapshot) Open(dir string) (*sql.DB, error) {
+	s.once.Do(func() {
+		s.err = s.build()
+	})
+	if s.err != nil {
+		return nil, fmt.Errorf("building the store snapshot: %w", s.err)
+	}
+
+	path := filepath.Join(dir, "agent.duckdb")
+	if err := copyFile(s.path, path); err != nil {
+		return nil, fmt.Errorf("copying the store snapshot: %w", err)
+	}
+	return store.NewDB(path)
+}
+
+// Remove deletes the snapshot file. The next Open builds it again.
+func (s *Snapshot) Remove() error {
+	if s.dir == "" {
+		return nil
+	}
+	err := os.RemoveAll(s.dir)
+	s.once, s.dir, s.path, s.err = sync.Once{}, "", "", nil
+	return err
+}
+
+func (s *Snapshot) build() error {
+	dir, err := os.MkdirTemp("", "store-snapshot-")
+	if err != nil {
+		return err
+	}
+	s.dir, s.path = dir, filepath.Join(dir, "agent.duckdb")
+
+	db, err := store.NewDB(s.path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := store.NewStore(db, test.NewMockValidator()).Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating: %w", err)
+	}
+	for _, seed := range s.seeds {
+		if err := seed(ctx, db); err != nil {
+			return fmt.Errorf("seeding: %w", err)
+		}
+	}
+
+	// the copies are taken from the file alone, without its WAL
+	if _, err := db.ExecContext(ctx, "CHECKPOINT"); err != nil {
+		return fmt.Errorf("checkpointing: %w", err)
+	}
+	return db.Close()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,24 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/update"
+)
+
+// NewUpdateManifest returns the manifest of binary at version, signed with key
+// like the ones of the update channels of the console.
+func NewUpdateManifest(key ed25519.PrivateKey, version, binaryURL string, binary []byte) models.UpdateManifest {
+	digest := sha256.Sum256(binary)
+	m := models.UpdateManifest{
+		Version: version,
+		URL:     binaryURL,
+		SHA256:  hex.EncodeToString(digest[:]),
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, update.SignedData(m)))
+	return m
+}
@@ -3,6 +3,8 @@ package test
 import (
 	"context"
 	"database/sql"
+
+	"github.com/kubev2v/assisted-migration-agent/test/fixtures"
 )
 
 type VM struct {
@@ -94,61 +96,47 @@ var Concerns = []Concern{
 
 // InsertVMs inserts all test VM data into the database.
 func InsertVMs(ctx context.Context, db *sql.DB) error {
+	builders := make(map[string]*fixtures.VMBuilder, len(VMs))
+	vms := make([]*fixtures.VMBuilder, 0, len(VMs))
 	for _, vm := range VMs {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vinfo (
-				"VM ID", "VM", "Powerstate", "Connection state", "Cluster", "Datacenter",
-				"Host", "Folder ID", "Firmware", "SMBIOS UUID", "Memory", "CPUs",
-				"OS according to the configuration file", "DNS Name", "Primary IP Address",
-				"In Use MiB", "Template", "FT State"
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, vm.ID, vm.Name, vm.PowerState, vm.ConnectionState, vm.Cluster, vm.Datacenter,
-			vm.Host, vm.Folder, vm.Firmware, vm.UUID, vm.Memory, vm.CPUs,
-			vm.GuestName, vm.DNSName, vm.IPAddress, vm.StorageUsed, vm.IsTemplate, vm.FTEnabled)
-		if err != nil {
-			return err
-		}
-	}
-
-	for _, vm := range VMs {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vcpu ("VM ID", "Sockets", "Cores p/s")
-			VALUES (?, ?, ?)
-		`, vm.ID, 1, vm.CPUs)
-		if err != nil {
-			return err
-		}
+		b := fixtures.NewVM(vm.ID).
+			WithName(vm.Name).
+			WithPowerState(vm.PowerState).
+			WithConnectionState(vm.ConnectionState).
+			WithCluster(vm.Cluster).
+			WithDatacenter(vm.Datacenter).
+			WithHost(vm.Host).
+			WithFolder(vm.Folder).
+			WithFirmware(vm.Firmware).
+			WithUUID(vm.UUID).
+			WithMemory(vm.Memory).
+			WithCPUs(vm.CPUs).
+			WithGuestOS(vm.GuestName).
+			WithDNSName(vm.DNSName).
+			WithIPAddress(vm.IPAddress).
+			WithStorageUsed(vm.StorageUsed).
+			WithTemplate(vm.IsTemplate).
+			WithFaultTolerance(vm.FTEnabled)
+		builders[vm.ID] = b
+		vms = append(vms, b)
 	}
 
 	for _, disk := range Disks {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vdisk ("VM ID", "Capacity MiB", "Path", "Disk Mode", "Sharing mode", "Raw", "Controller")
-			VALUES (?, ?, ?, ?, ?, ?, ?)
-		`, disk.VMID, disk.CapacityMiB, disk.Path, disk.DiskMode, disk.Shared, disk.RDM, disk.Controller)
-		if err != nil {
-			return err
-		}
+		builders[disk.VMID].WithDiskOf(fixtures.Disk{
+			CapacityMiB: disk.CapacityMiB,
+			Path:        disk.Path,
+			DiskMode:    disk.DiskMode,
+			Shared:      disk.Shared,
+			RDM:         disk.RDM,
+			Controller:  disk.Controller,
+		})
 	}
-
 	for _, nic := range NICs {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO vnetwork ("VM ID", "Network", "Mac Address")
-			VALUES (?, ?, ?)
-		`, nic.VMID, nic.Network, nic.MAC)
-		if err != nil {
-			return err
-		}
+		builders[nic.VMID].WithNIC(nic.Network, nic.MAC)
 	}
-
 	for _, c := range Concerns {
-		_, err := db.ExecContext(ctx, `
-			INSERT INTO concerns ("VM_ID", "Concern_ID", "Label", "Category", "Assessment")
-			VALUES (?, ?, ?, 'Warning', 'Needs attention')
-		`, c.VMID, c.ConcernID, c.Label)
-		if err != nil {
-			return err
-		}
+		builders[c.VMID].WithConcern(c.ConcernID, c.Label)
 	}
 
-	return nil
+	return fixtures.Insert(ctx, db, vms...)
 }